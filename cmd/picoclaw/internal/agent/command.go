@@ -9,6 +9,7 @@ func NewAgentCommand() *cobra.Command {
 		message    string
 		sessionKey string
 		model      string
+		images     []string
 		debug      bool
 	)
 
@@ -17,7 +18,7 @@ func NewAgentCommand() *cobra.Command {
 		Short: "Interact with the agent directly",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return agentCmd(message, sessionKey, model, debug)
+			return agentCmd(message, sessionKey, model, images, debug)
 		},
 	}
 
@@ -25,6 +26,7 @@ func NewAgentCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Send a single message (non-interactive mode)")
 	cmd.Flags().StringVarP(&sessionKey, "session", "s", "cli:default", "Session key")
 	cmd.Flags().StringVarP(&model, "model", "", "", "Model to use")
+	cmd.Flags().StringArrayVar(&images, "image", nil, "Attach an image file or URL to the message (repeatable, requires -m)")
 
 	return cmd
 }
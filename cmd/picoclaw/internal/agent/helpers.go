@@ -16,13 +16,22 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
-func agentCmd(message, sessionKey, model string, debug bool) error {
+func agentCmd(message, sessionKey, model string, images []string, debug bool) error {
 	if sessionKey == "" {
 		sessionKey = "cli:default"
 	}
 
+	if len(images) > 0 && message == "" {
+		return fmt.Errorf("--image requires a message (-m)")
+	}
+	imageRefs, err := loadImages(images)
+	if err != nil {
+		return err
+	}
+
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 		fmt.Println("🔍 Debug mode enabled")
@@ -59,23 +68,110 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 			"skills_available": startupInfo["skills"].(map[string]any)["available"],
 		})
 
+	streaming := cfg.Agents.Defaults.Streaming
+
 	if message != "" {
+		agentLoop.SetApprovalPrompt(approvalPrompt(lineReader(bufio.NewReader(os.Stdin))))
 		ctx := context.Background()
-		response, err := agentLoop.ProcessDirect(ctx, message, sessionKey)
+		response, streamed, err := processInput(ctx, agentLoop, message, sessionKey, imageRefs, streaming)
 		if err != nil {
 			return fmt.Errorf("error processing message: %w", err)
 		}
-		fmt.Printf("\n%s %s\n", internal.Logo, response)
+		if streamed {
+			fmt.Println()
+		} else {
+			fmt.Printf("\n%s %s\n", internal.Logo, response)
+		}
 		return nil
 	}
 
 	fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", internal.Logo)
-	interactiveMode(agentLoop, sessionKey)
+	interactiveMode(agentLoop, sessionKey, streaming)
 
 	return nil
 }
 
-func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
+// loadImages turns --image arguments into references the agent accepts:
+// URLs are passed through, files are inlined as data: URLs.
+func loadImages(images []string) ([]string, error) {
+	refs := make([]string, 0, len(images))
+	for _, img := range images {
+		if strings.HasPrefix(img, "https://") || strings.HasPrefix(img, "http://") {
+			refs = append(refs, img)
+			continue
+		}
+		dataURL, err := utils.ImageDataURL(img)
+		if err != nil {
+			return nil, fmt.Errorf("error loading image: %w", err)
+		}
+		refs = append(refs, dataURL)
+	}
+	return refs, nil
+}
+
+// processInput runs one agent turn. When streaming is enabled, text is printed
+// as it is generated and streamed reports whether anything was printed, in
+// which case the caller must not print the response again.
+func processInput(
+	ctx context.Context,
+	agentLoop *agent.AgentLoop,
+	input, sessionKey string,
+	images []string,
+	streaming bool,
+) (response string, streamed bool, err error) {
+	var onDelta providers.StreamCallback
+	if streaming {
+		onDelta = func(delta string) {
+			if !streamed {
+				fmt.Printf("\n%s ", internal.Logo)
+				streamed = true
+			}
+			fmt.Print(delta)
+		}
+	}
+
+	switch {
+	case len(images) > 0:
+		response, err = agentLoop.ProcessDirectWithImages(ctx, input, sessionKey, images, onDelta)
+	case streaming:
+		response, err = agentLoop.ProcessDirectStream(ctx, input, sessionKey, onDelta)
+	default:
+		response, err = agentLoop.ProcessDirect(ctx, input, sessionKey)
+	}
+	return response, streamed, err
+}
+
+// approvalPrompt asks about tool calls that need approval (see
+// tools.approval in the config); readLine shows a prompt and returns the
+// line typed in answer.
+func approvalPrompt(readLine func(prompt string) (string, error)) func(context.Context, string) (string, error) {
+	return func(_ context.Context, question string) (string, error) {
+		fmt.Printf("\n⚠️  %s\n", question)
+		return readLine("Approve? [y/N] ")
+	}
+}
+
+// lineReader reads lines from reader for approvalPrompt.
+func lineReader(reader *bufio.Reader) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		fmt.Print(prompt)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return line, nil
+	}
+}
+
+func printResponse(response string, streamed bool) {
+	if streamed {
+		fmt.Print("\n\n")
+		return
+	}
+	fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+}
+
+func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string, streaming bool) {
 	prompt := fmt.Sprintf("%s You: ", internal.Logo)
 
 	rl, err := readline.NewEx(&readline.Config{
@@ -88,10 +184,15 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 	if err != nil {
 		fmt.Printf("Error initializing readline: %v\n", err)
 		fmt.Println("Falling back to simple input mode...")
-		simpleInteractiveMode(agentLoop, sessionKey)
+		simpleInteractiveMode(agentLoop, sessionKey, streaming)
 		return
 	}
 	defer rl.Close()
+	agentLoop.SetApprovalPrompt(approvalPrompt(func(p string) (string, error) {
+		rl.SetPrompt(p)
+		defer rl.SetPrompt(prompt)
+		return rl.Readline()
+	}))
 
 	for {
 		line, err := rl.Readline()
@@ -115,18 +216,19 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 		}
 
 		ctx := context.Background()
-		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
+		response, streamed, err := processInput(ctx, agentLoop, input, sessionKey, nil, streaming)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+		printResponse(response, streamed)
 	}
 }
 
-func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
+func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string, streaming bool) {
 	reader := bufio.NewReader(os.Stdin)
+	agentLoop.SetApprovalPrompt(approvalPrompt(lineReader(reader)))
 	for {
 		fmt.Print(fmt.Sprintf("%s You: ", internal.Logo))
 		line, err := reader.ReadString('\n')
@@ -150,12 +252,12 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 		}
 
 		ctx := context.Background()
-		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
+		response, streamed, err := processInput(ctx, agentLoop, input, sessionKey, nil, streaming)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+		printResponse(response, streamed)
 	}
 }
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/providers/copilot"
+)
+
+func TestNewAuthCommand(t *testing.T) {
+	cmd := NewAuthCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "auth", cmd.Name())
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasSubCommands())
+
+	sub, _, err := cmd.Find([]string{"github-copilot"})
+	require.NoError(t, err)
+	assert.Equal(t, "github-copilot", sub.Name())
+	assert.True(t, sub.HasAlias("copilot"))
+	assert.NotNil(t, sub.Flags().Lookup("logout"))
+
+	sub, _, err = cmd.Find([]string{"google-calendar"})
+	require.NoError(t, err)
+	assert.Equal(t, "google-calendar", sub.Name())
+	assert.NotNil(t, sub.Flags().Lookup("logout"))
+}
+
+func TestPrintDeviceCode(t *testing.T) {
+	var buf bytes.Buffer
+
+	printDeviceCode(&buf, copilot.DeviceCode{UserCode: "ABCD-1234", VerificationURI: "https://github.com/login/device"})
+
+	assert.Contains(t, buf.String(), "Open https://github.com/login/device and enter the code: ABCD-1234\n")
+}
+
+func TestPrintGoogleLogin(t *testing.T) {
+	var buf bytes.Buffer
+
+	printGoogleLogin(&buf, "https://accounts.google.com/o/oauth2/v2/auth?client_id=x")
+
+	assert.Contains(t, buf.String(), "https://accounts.google.com/o/oauth2/v2/auth?client_id=x\n")
+	assert.Contains(t, buf.String(), "paste its address here")
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newGitHubCopilotCommand() *cobra.Command {
+	var logout bool
+
+	cmd := &cobra.Command{
+		Use:     "github-copilot",
+		Aliases: []string{"copilot"},
+		Short:   "Log in to GitHub Copilot with a device code",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw auth github-copilot
+picoclaw auth github-copilot --logout`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if logout {
+				return copilotLogoutCmd()
+			}
+			return copilotLoginCmd(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&logout, "logout", false, "Remove the saved GitHub Copilot login")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newGoogleCalendarCommand() *cobra.Command {
+	var logout bool
+
+	cmd := &cobra.Command{
+		Use:     "google-calendar",
+		Aliases: []string{"google"},
+		Short:   "Let the calendar tool use your Google Calendar",
+		Long: `Log in to Google Calendar with the OAuth client set in tools.calendar.google.
+
+Open the address shown in a browser and allow access. If the browser runs on
+another machine, it then fails to load a http://127.0.0.1 page: paste that
+page's address here.`,
+		Args: cobra.NoArgs,
+		Example: `picoclaw auth google-calendar
+picoclaw auth google-calendar --logout`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if logout {
+				return googleCalendarLogoutCmd()
+			}
+			return googleCalendarLoginCmd(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&logout, "logout", false, "Remove the saved Google Calendar login")
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/calendar"
+	"github.com/sipeed/picoclaw/pkg/providers/copilot"
+)
+
+func copilotLoginCmd(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	store := copilot.NewTokenStore(copilot.DefaultTokenPath())
+	auth := copilot.NewAuth(store, "", nil)
+	if err := auth.Login(ctx, func(dc copilot.DeviceCode) { printDeviceCode(os.Stdout, dc) }); err != nil {
+		return fmt.Errorf("GitHub Copilot login failed: %w", err)
+	}
+
+	fmt.Printf("%s Logged in to GitHub Copilot. Credentials saved to %s\n", internal.Logo, store.Path())
+	return nil
+}
+
+func copilotLogoutCmd() error {
+	store := copilot.NewTokenStore(copilot.DefaultTokenPath())
+	if err := store.Delete(); err != nil {
+		return err
+	}
+	fmt.Println("✓ Logged out of GitHub Copilot")
+	return nil
+}
+
+func googleCalendarLoginCmd(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	google := cfg.Tools.Calendar.Google
+	if google.ClientID == "" || google.ClientSecret == "" {
+		return fmt.Errorf("set tools.calendar.google.client_id and client_secret first, " +
+			`from a "Desktop app" OAuth client created in the Google Cloud console`)
+	}
+
+	pasted := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			pasted <- scanner.Text()
+		}
+		close(pasted)
+	}()
+
+	auth := calendar.NewGoogleAuth(google.ClientID, google.ClientSecret, calendar.DefaultGoogleTokenPath(), nil)
+	if err := auth.Login(ctx, func(url string) { printGoogleLogin(os.Stdout, url) }, pasted); err != nil {
+		return fmt.Errorf("google Calendar login failed: %w", err)
+	}
+
+	fmt.Printf("%s Logged in to Google Calendar. Credentials saved to %s\n", internal.Logo, auth.Path())
+	return nil
+}
+
+func googleCalendarLogoutCmd() error {
+	auth := calendar.NewGoogleAuth("", "", calendar.DefaultGoogleTokenPath(), nil)
+	if err := auth.Logout(); err != nil {
+		return err
+	}
+	fmt.Println("✓ Logged out of Google Calendar")
+	return nil
+}
+
+func printGoogleLogin(w io.Writer, url string) {
+	fmt.Fprintf(w, "Open this address and allow access:\n\n%s\n\n", url)
+	fmt.Fprintln(w, "Waiting for authorization... "+
+		"If the browser ends on a page that doesn't load, paste its address here:")
+}
+
+func printDeviceCode(w io.Writer, dc copilot.DeviceCode) {
+	fmt.Fprintf(w, "Open %s and enter the code: %s\n", dc.VerificationURI, dc.UserCode)
+	fmt.Fprintln(w, "Waiting for authorization...")
+}
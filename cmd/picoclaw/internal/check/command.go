@@ -0,0 +1,27 @@
+package check
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCheckCommand() *cobra.Command {
+	var (
+		timeout    time.Duration
+		listModels bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check [model_name...]",
+		Short: "Verify configured models are reachable and their keys are valid",
+		RunE: func(_ *cobra.Command, args []string) error {
+			return checkCmd(args, timeout, listModels)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 15*time.Second, "Timeout per model")
+	cmd.Flags().BoolVarP(&listModels, "list", "l", false, "List the models each endpoint serves")
+
+	return cmd
+}
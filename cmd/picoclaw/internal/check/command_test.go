@@ -0,0 +1,72 @@
+package check
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestNewCheckCommand(t *testing.T) {
+	cmd := NewCheckCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "check", cmd.Name())
+	assert.Equal(t, "Verify configured models are reachable and their keys are valid", cmd.Short)
+
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	assert.NotNil(t, cmd.Flags().Lookup("timeout"))
+	assert.NotNil(t, cmd.Flags().Lookup("list"))
+}
+
+func TestSelectModels(t *testing.T) {
+	cfg := &config.Config{ModelList: []config.ModelConfig{
+		{ModelName: "fast", Model: "groq/llama"},
+		{ModelName: "smart", Model: "anthropic/claude"},
+	}}
+
+	all, err := selectModels(cfg, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	one, err := selectModels(cfg, []string{"smart"})
+	require.NoError(t, err)
+	require.Len(t, one, 1)
+	assert.Equal(t, "anthropic/claude", one[0].Model)
+
+	_, err = selectModels(cfg, []string{"missing"})
+	assert.Error(t, err)
+
+	_, err = selectModels(&config.Config{}, nil)
+	assert.Error(t, err)
+}
+
+func TestPrintCheck(t *testing.T) {
+	var buf bytes.Buffer
+
+	printCheck(&buf, providers.ModelCheck{
+		ModelName: "fast", Model: "groq/llama", Latency: 120 * time.Millisecond,
+		Models: []string{"llama", "mixtral"}, Listed: true,
+	}, true)
+	printCheck(&buf, providers.ModelCheck{
+		ModelName: "smart", Model: "anthropic/claude",
+		Err: errors.New("ping: API request failed:\n  Status: 401\n  Body:   invalid x-api-key"),
+	}, false)
+	printCheck(&buf, providers.ModelCheck{
+		ModelName: "cli", Model: "claude-cli/sonnet", Err: providers.ErrHealthCheckUnsupported,
+	}, false)
+
+	out := buf.String()
+	assert.Contains(t, out, "✓ fast (groq/llama): ok in 120ms\n    llama\n    mixtral\n")
+	assert.Contains(t, out, "✗ smart (anthropic/claude): ping: API request failed: Status: 401 Body:   invalid x-api-key\n")
+	assert.Contains(t, out, "- cli (claude-cli/sonnet): skipped")
+}
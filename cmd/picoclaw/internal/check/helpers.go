@@ -0,0 +1,102 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func checkCmd(names []string, timeout time.Duration, listModels bool) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	providers.MergeLegacyProviders(cfg)
+	entries, err := selectModels(cfg, names)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range entries {
+		mc := entries[i]
+		if mc.Workspace == "" {
+			mc.Workspace = cfg.WorkspacePath()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		result := providers.CheckModel(ctx, &mc)
+		cancel()
+
+		if result.Err != nil && !errors.Is(result.Err, providers.ErrHealthCheckUnsupported) {
+			failed++
+		}
+		printCheck(os.Stdout, result, listModels)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d models failed", failed, len(entries))
+	}
+	return nil
+}
+
+// selectModels returns the model_list entries named in names, or all of
+// them when names is empty.
+func selectModels(cfg *config.Config, names []string) ([]config.ModelConfig, error) {
+	if len(cfg.ModelList) == 0 {
+		return nil, fmt.Errorf("no models configured. Please add entries to model_list in your config")
+	}
+	if len(names) == 0 {
+		return cfg.ModelList, nil
+	}
+
+	var selected []config.ModelConfig
+	for _, name := range names {
+		i := slices.IndexFunc(cfg.ModelList, func(mc config.ModelConfig) bool { return mc.ModelName == name })
+		if i < 0 {
+			return nil, fmt.Errorf("model %q not found in model_list", name)
+		}
+		selected = append(selected, cfg.ModelList[i])
+	}
+	return selected, nil
+}
+
+func printCheck(w io.Writer, c providers.ModelCheck, listModels bool) {
+	switch {
+	case errors.Is(c.Err, providers.ErrHealthCheckUnsupported):
+		fmt.Fprintf(w, "- %s (%s): skipped, provider has no health check\n", c.ModelName, c.Model)
+		return
+	case c.Err != nil:
+		fmt.Fprintf(w, "✗ %s (%s): %s\n", c.ModelName, c.Model, firstLine(c.Err.Error()))
+		return
+	}
+
+	note := ""
+	if len(c.Models) > 0 && !c.Listed {
+		note = ", model not in endpoint's list"
+	}
+	fmt.Fprintf(w, "✓ %s (%s): ok in %s%s\n", c.ModelName, c.Model, c.Latency.Round(time.Millisecond), note)
+	if listModels {
+		for _, m := range c.Models {
+			fmt.Fprintf(w, "    %s\n", m)
+		}
+	}
+}
+
+// firstLine keeps multi-line API errors to the status and body lines.
+func firstLine(s string) string {
+	s = strings.ReplaceAll(s, "\n  ", " ")
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,6 +15,8 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		message string
 		every   int64
 		cronExp string
+		when    string
+		tz      string
 		deliver bool
 		channel string
 		to      string
@@ -24,16 +27,36 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		Short: "Add a new scheduled job",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			if every <= 0 && cronExp == "" {
-				return fmt.Errorf("either --every or --cron must be specified")
+			if every <= 0 && cronExp == "" && when == "" {
+				return fmt.Errorf("one of --every, --cron or --schedule must be specified")
+			}
+
+			loc := time.Local
+			if tz != "" {
+				var err error
+				if loc, err = time.LoadLocation(tz); err != nil {
+					return fmt.Errorf("unknown time zone %q", tz)
+				}
 			}
 
 			var schedule cron.CronSchedule
-			if every > 0 {
+			switch {
+			case every > 0:
 				everyMS := every * 1000
 				schedule = cron.CronSchedule{Kind: "every", EveryMS: &everyMS}
-			} else {
+			case cronExp != "":
 				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
+			default:
+				var err error
+				if schedule, err = cron.ParseSchedule(when, time.Now().In(loc)); err != nil {
+					return err
+				}
+			}
+			if schedule.Kind == "cron" {
+				schedule.TZ = tz
+			}
+			if err := cron.ValidateSchedule(schedule); err != nil {
+				return err
 			}
 
 			cs := cron.NewCronService(storePath(), nil)
@@ -42,7 +65,7 @@ func newAddCommand(storePath func() string) *cobra.Command {
 				return fmt.Errorf("error adding job: %w", err)
 			}
 
-			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
+			fmt.Printf("✓ Added job '%s' (%s, %s)\n", job.Name, job.ID, cron.DescribeSchedule(job.Schedule))
 
 			return nil
 		},
@@ -52,13 +75,15 @@ func newAddCommand(storePath func() string) *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message for agent")
 	cmd.Flags().Int64VarP(&every, "every", "e", 0, "Run every N seconds")
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
+	cmd.Flags().StringVarP(&when, "schedule", "s", "", "When to run, in plain English (e.g. 'every weekday at 8:30')")
+	cmd.Flags().StringVar(&tz, "tz", "", "Time zone of --cron and --schedule (e.g. Europe/Paris)")
 	cmd.Flags().BoolVarP(&deliver, "deliver", "d", false, "Deliver response to channel")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("message")
-	cmd.MarkFlagsMutuallyExclusive("every", "cron")
+	cmd.MarkFlagsMutuallyExclusive("every", "cron", "schedule")
 
 	return cmd
 }
@@ -19,14 +19,7 @@ func cronListCmd(storePath string) {
 	fmt.Println("\nScheduled Jobs:")
 	fmt.Println("----------------")
 	for _, job := range jobs {
-		var schedule string
-		if job.Schedule.Kind == "every" && job.Schedule.EveryMS != nil {
-			schedule = fmt.Sprintf("every %ds", *job.Schedule.EveryMS/1000)
-		} else if job.Schedule.Kind == "cron" {
-			schedule = job.Schedule.Expr
-		} else {
-			schedule = "one-time"
-		}
+		schedule := cron.DescribeSchedule(job.Schedule)
 
 		nextRun := "scheduled"
 		if job.State.NextRunAtMS != nil {
@@ -0,0 +1,30 @@
+package doctor
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func NewDoctorCommand() *cobra.Command {
+	var (
+		offline bool
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Find mistakes in the config and suggest fixes",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return doctorCmd(internal.GetConfigPath(), offline, timeout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "Don't check that api_base endpoints are reachable")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 5*time.Second, "Timeout per endpoint")
+
+	return cmd
+}
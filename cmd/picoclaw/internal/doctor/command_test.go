@@ -0,0 +1,84 @@
+package doctor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewDoctorCommand(t *testing.T) {
+	cmd := NewDoctorCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "doctor", cmd.Name())
+	assert.Equal(t, "Find mistakes in the config and suggest fixes", cmd.Short)
+
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	assert.NotNil(t, cmd.Flags().Lookup("offline"))
+	assert.NotNil(t, cmd.Flags().Lookup("timeout"))
+}
+
+func TestDiagnose(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "workspace")
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "cron"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "cron", "jobs.json"), []byte(`{
+  "version": 1,
+  "jobs": [{"id": "j1", "name": "daily", "enabled": true, "schedule": {"kind": "cron", "expr": "0 99 * * *"}}]
+}`), 0o644))
+
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "agents": {"defaults": {"workspace": "`+workspace+`", "model": "fast", "temprature": 0.5}},
+  "model_list": [
+    {"model_name": "fast", "model": "groq/llama-3.3-70b", "api_key": "gsk"},
+    {"model_name": "broken", "model": "nosuch/model", "api_key": "x"}
+  ]
+}`), 0o644))
+
+	issues := diagnose(path, true, 0)
+
+	var fields []string
+	for _, issue := range issues {
+		fields = append(fields, issue.Field)
+	}
+	assert.Contains(t, fields, "agents.defaults.temprature")
+	assert.Contains(t, fields, "model_list[1] (broken)")
+	assert.Contains(t, fields, `cron job "daily" (j1)`)
+	assert.Len(t, issues, 3)
+}
+
+func TestDiagnose_MissingFile(t *testing.T) {
+	issues := diagnose(filepath.Join(t.TempDir(), "config.json"), true, 0)
+
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Fix, "picoclaw onboard")
+}
+
+func TestPrintIssues(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Equal(t, 0, printIssues(&buf, "config.json", nil))
+	assert.Contains(t, buf.String(), "No problems found")
+
+	buf.Reset()
+	problems := printIssues(&buf, "config.json", []config.Issue{
+		{Field: "agents.defaults.modle", Problem: "unknown key, ignored", Fix: `did you mean "model"?`, Warning: true},
+		{Field: "agents.defaults.model", Problem: `model "gpt" is not in model_list`, Fix: "use one of: fast"},
+	})
+	out := buf.String()
+
+	assert.Equal(t, 1, problems)
+	assert.Contains(t, out, "⚠ agents.defaults.modle: unknown key, ignored\n    → did you mean \"model\"?")
+	assert.Contains(t, out, "✗ agents.defaults.model")
+	assert.True(t, strings.HasSuffix(out, "1 problems, 1 warnings in config.json\n"))
+}
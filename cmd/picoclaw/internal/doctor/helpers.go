@@ -0,0 +1,195 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func doctorCmd(path string, offline bool, timeout time.Duration) error {
+	issues := diagnose(path, offline, timeout)
+	problems := printIssues(os.Stdout, path, issues)
+	if problems > 0 {
+		return fmt.Errorf("%d problems found", problems)
+	}
+	return nil
+}
+
+// diagnose runs every check on the config file at path. It stops early
+// when the file can't be loaded, since later checks need the config.
+func diagnose(path string, offline bool, timeout time.Duration) []config.Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fix := ""
+		if os.IsNotExist(err) {
+			fix = "run picoclaw onboard to create it"
+		}
+		return []config.Issue{{Problem: err.Error(), Fix: fix}}
+	}
+
+	issues := config.LintJSON(data)
+	for _, issue := range issues {
+		if !issue.Warning {
+			return issues
+		}
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return append(issues, config.Issue{
+			Problem: err.Error(),
+			Fix:     "set the environment variable or create the file the value refers to",
+		})
+	}
+	providers.MergeLegacyProviders(cfg)
+
+	issues = append(issues, cfg.Lint()...)
+	issues = append(issues, checkModels(cfg)...)
+	if !offline {
+		issues = append(issues, checkEndpoints(cfg, timeout)...)
+	}
+	issues = append(issues, checkCronJobs(filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json"))...)
+	return issues
+}
+
+// checkModels builds the provider of every model_list entry, which catches
+// settings a protocol requires or doesn't support.
+func checkModels(cfg *config.Config) []config.Issue {
+	var issues []config.Issue
+	for i := range cfg.ModelList {
+		mc := cfg.ModelList[i]
+		if mc.Workspace == "" {
+			mc.Workspace = cfg.WorkspacePath()
+		}
+		provider, _, err := providers.CreateProviderFromConfig(&mc)
+		if err != nil {
+			issues = append(issues, config.Issue{
+				Field:   fmt.Sprintf("model_list[%d] (%s)", i, mc.ModelName),
+				Problem: err.Error(),
+				Fix:     modelFix(err),
+			})
+			continue
+		}
+		if sp, ok := provider.(providers.StatefulProvider); ok {
+			sp.Close()
+		}
+	}
+	return issues
+}
+
+func modelFix(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "api_key"):
+		return `set api_key, e.g. "${PROVIDER_API_KEY}" to read it from the environment`
+	case strings.Contains(msg, "unknown protocol"):
+		return "start model with a supported protocol (anthropic/, gemini/, groq/, ollama/, ...) " +
+			"or define the prefix in provider_aliases"
+	case strings.Contains(msg, "native_tools"):
+		return "remove native_tools from this entry"
+	}
+	return ""
+}
+
+// checkEndpoints connects to each configured api_base. Entries behind a
+// proxy are skipped: only the proxy could tell whether they are reachable.
+func checkEndpoints(cfg *config.Config, timeout time.Duration) []config.Issue {
+	var issues []config.Issue
+	seen := make(map[string]bool)
+	for i, mc := range cfg.ModelList {
+		if mc.APIBase == "" || mc.Proxy != "" && mc.Proxy != "direct" {
+			continue
+		}
+		u, err := url.Parse(mc.APIBase)
+		if err != nil || u.Host == "" {
+			issues = append(issues, config.Issue{
+				Field:   fmt.Sprintf("model_list[%d].api_base", i),
+				Problem: fmt.Sprintf("%q is not a URL", mc.APIBase),
+				Fix:     "use a full URL such as http://localhost:11434/v1",
+			})
+			continue
+		}
+		addr := u.Host
+		if u.Port() == "" {
+			port := "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+			addr = net.JoinHostPort(u.Hostname(), port)
+		}
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			issues = append(issues, config.Issue{
+				Field:   fmt.Sprintf("model_list[%d].api_base", i),
+				Problem: fmt.Sprintf("can't connect to %s: %v", addr, err),
+				Fix:     "check the URL, and that the server is running (start local servers such as ollama first)",
+			})
+			continue
+		}
+		conn.Close()
+	}
+	return issues
+}
+
+// checkCronJobs validates the schedules of the stored cron jobs.
+func checkCronJobs(storePath string) []config.Issue {
+	cs := cron.NewCronService(storePath, nil)
+	if err := cs.Load(); err != nil {
+		return []config.Issue{{
+			Field:   storePath,
+			Problem: "can't read scheduled jobs: " + err.Error(),
+			Fix:     "fix or delete the file; deleting it removes all scheduled jobs",
+		}}
+	}
+
+	var issues []config.Issue
+	for _, job := range cs.ListJobs(true) {
+		if err := cron.ValidateSchedule(job.Schedule); err != nil {
+			issues = append(issues, config.Issue{
+				Field:   fmt.Sprintf("cron job %q (%s)", job.Name, job.ID),
+				Problem: err.Error() + ", the job never runs",
+				Fix:     fmt.Sprintf("picoclaw cron remove %s, then add it again", job.ID),
+			})
+		}
+	}
+	return issues
+}
+
+// printIssues writes issues with their fixes and returns how many are
+// problems rather than warnings.
+func printIssues(w io.Writer, path string, issues []config.Issue) int {
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "✓ No problems found in %s\n", path)
+		return 0
+	}
+
+	problems := 0
+	for _, issue := range issues {
+		mark := "✗"
+		if issue.Warning {
+			mark = "⚠"
+		} else {
+			problems++
+		}
+		fmt.Fprintf(w, "%s %s\n", mark, issue)
+		if issue.Fix != "" {
+			fmt.Fprintf(w, "    → %s\n", issue.Fix)
+		}
+	}
+	fmt.Fprintf(w, "\n%d problems, %d warnings in %s\n", problems, len(issues)-problems, path)
+	return problems
+}
@@ -5,7 +5,10 @@ import (
 )
 
 func NewGatewayCommand() *cobra.Command {
-	var debug bool
+	var (
+		debug       bool
+		checkModels bool
+	)
 
 	cmd := &cobra.Command{
 		Use:     "gateway",
@@ -13,11 +16,12 @@ func NewGatewayCommand() *cobra.Command {
 		Short:   "Start picoclaw gateway",
 		Args:    cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return gatewayCmd(debug)
+			return gatewayCmd(debug, checkModels)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+	cmd.Flags().BoolVar(&checkModels, "check", false, "Verify the model is reachable before starting channels")
 
 	return cmd
 }
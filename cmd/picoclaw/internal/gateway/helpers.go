@@ -16,18 +16,25 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/cron"
 	"github.com/sipeed/picoclaw/pkg/devices"
+	"github.com/sipeed/picoclaw/pkg/feeds"
 	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mqtt"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/sysinfo"
+	"github.com/sipeed/picoclaw/pkg/tasks"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/triggers"
 	"github.com/sipeed/picoclaw/pkg/voice"
 )
 
-func gatewayCmd(debug bool) error {
+func gatewayCmd(debug, checkModels bool) error {
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 		fmt.Println("🔍 Debug mode enabled")
@@ -37,12 +44,23 @@ func gatewayCmd(debug bool) error {
 	if err != nil {
 		return fmt.Errorf("error loading config: %w", err)
 	}
+	modelKey := modelSettings(cfg)
 
 	provider, modelID, err := providers.CreateProvider(cfg)
 	if err != nil {
 		return fmt.Errorf("error creating provider: %w", err)
 	}
 
+	if checkModels {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := providers.Ping(ctx, provider)
+		cancel()
+		if err != nil && !errors.Is(err, providers.ErrHealthCheckUnsupported) {
+			return fmt.Errorf("model check failed: %w", err)
+		}
+		fmt.Println("✓ Model reachable")
+	}
+
 	// Use the resolved model ID from provider creation
 	if modelID != "" {
 		cfg.Agents.Defaults.ModelName = modelID
@@ -80,29 +98,48 @@ func gatewayCmd(debug bool) error {
 		cfg,
 	)
 
+	feedService := setupFeeds(agentLoop, msgBus, cfg)
+
 	heartbeatService := heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
 		cfg.Heartbeat.Interval,
 		cfg.Heartbeat.Enabled,
 	)
 	heartbeatService.SetBus(msgBus)
+	if err = heartbeatService.SetQuietHours(cfg.Heartbeat.QuietHours, cfg.Heartbeat.TZ); err != nil {
+		logger.WarnCF("heartbeat", "Ignoring heartbeat quiet hours", map[string]any{"error": err.Error()})
+	}
+	heartbeatService.SetMaxRunsPerDay(cfg.Heartbeat.MaxRunsPerDay)
+	if tasksCfg := cfg.Tools.Tasks; tasksCfg.Enabled && tasksCfg.NagHours > 0 {
+		taskStore := tasks.NewStore(filepath.Join(cfg.WorkspacePath(), "tasks.json"))
+		nagEvery := time.Duration(tasksCfg.NagHours) * time.Hour
+		heartbeatService.AddSection(func() string {
+			prompt, err := taskStore.NagPrompt(nagEvery)
+			if err != nil {
+				logger.WarnCF("tasks", "Can't check for overdue tasks", map[string]any{"error": err.Error()})
+			}
+			return prompt
+		})
+	}
 	heartbeatService.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
 		// Use cli:direct as fallback if no valid channel
 		if channel == "" || chatID == "" {
 			channel, chatID = "cli", "direct"
 		}
 		// Use ProcessHeartbeat - no session history, each heartbeat is independent
-		var response string
-		response, err = agentLoop.ProcessHeartbeat(context.Background(), prompt, channel, chatID)
+		response, err := agentLoop.ProcessHeartbeat(context.Background(), prompt, channel, chatID)
+		if errors.Is(err, agent.ErrHeartbeatBudget) {
+			return tools.SilentResult(err.Error())
+		}
 		if err != nil {
 			return tools.ErrorResult(fmt.Sprintf("Heartbeat error: %v", err))
 		}
-		if response == "HEARTBEAT_OK" {
+		if heartbeat.IsOK(response) {
 			return tools.SilentResult("Heartbeat OK")
 		}
-		// For heartbeat, always return silent - the subagent result will be
-		// sent to user via processSystemMessage when the async task completes
-		return tools.SilentResult(response)
+		// Anything else is a message for the user. Results of subagents the
+		// heartbeat spawned reach them via processSystemMessage.
+		return tools.UserResult(response)
 	})
 
 	channelManager, err := channels.NewManager(cfg, msgBus)
@@ -113,29 +150,16 @@ func gatewayCmd(debug bool) error {
 	// Inject channel manager into agent loop for command handling
 	agentLoop.SetChannelManager(channelManager)
 
-	var transcriber *voice.GroqTranscriber
-	groqAPIKey := cfg.Providers.Groq.APIKey
-	if groqAPIKey == "" {
-		for _, mc := range cfg.ModelList {
-			if strings.HasPrefix(mc.Model, "groq/") && mc.APIKey != "" {
-				groqAPIKey = mc.APIKey
-				break
-			}
-		}
-	}
-	if groqAPIKey != "" {
-		transcriber = voice.NewGroqTranscriber(groqAPIKey)
-		logger.InfoC("voice", "Groq voice transcription enabled")
+	transcriber, err := voice.NewTranscriptionProvider(cfg)
+	if err != nil {
+		logger.WarnCF("voice", "Voice transcription disabled", map[string]any{"error": err.Error()})
 	}
 
-	if transcriber != nil {
-		if telegramChannel, ok := channelManager.GetChannel("telegram"); ok {
-			if tc, ok := telegramChannel.(*channels.TelegramChannel); ok {
-				tc.SetTranscriber(transcriber)
-				logger.InfoC("voice", "Groq transcription attached to Telegram channel")
-			}
-		}
+	speaker, err := voice.NewSpeechProvider(cfg)
+	if err != nil {
+		logger.WarnCF("voice", "Voice replies disabled", map[string]any{"error": err.Error()})
 	}
+	attachVoice(channelManager, transcriber, speaker)
 
 	enabledChannels := channelManager.GetEnabledChannels()
 	if len(enabledChannels) > 0 {
@@ -172,11 +196,52 @@ func gatewayCmd(debug bool) error {
 		fmt.Println("✓ Device event service started")
 	}
 
+	sysMonitor := setupSysAlerts(msgBus, stateManager, cfg)
+	if err := sysMonitor.Start(ctx); err != nil {
+		fmt.Printf("Error starting system alerts: %v\n", err)
+	} else if n := len(cfg.Tools.SysInfo.Alerts); n > 0 {
+		fmt.Printf("✓ System alerts started: %d\n", n)
+	}
+
+	if feedService != nil {
+		if err := feedService.Start(ctx); err != nil {
+			fmt.Printf("Error starting feeds service: %v\n", err)
+		} else {
+			fmt.Println("✓ Feeds service started")
+		}
+	}
+
+	triggerService := triggers.NewService(cfg.Triggers, stateManager, func(ctx context.Context, ev triggers.Event) {
+		_, err := agentLoop.ProcessTrigger(ctx, ev.Trigger, ev.Message(), ev.Channel, ev.ChatID)
+		if err != nil {
+			logger.ErrorCF("triggers", "Trigger run failed", map[string]any{"trigger": ev.Trigger, "error": err.Error()})
+		}
+	})
+	if m := cfg.Tools.MQTT; m.Broker != "" {
+		triggerService.SetMQTT(mqtt.Options{
+			Broker:   m.Broker,
+			Username: m.Username,
+			Password: m.Password,
+			CAFile:   m.CAFile,
+			CertFile: m.CertFile,
+			KeyFile:  m.KeyFile,
+		})
+	}
+	if ha := cfg.Tools.HomeAssistant; ha.Enabled {
+		triggerService.SetHomeAssistant(homeassistant.NewClient(ha.URL, ha.Token, ha.Entities))
+	}
+	if err := triggerService.Start(ctx); err != nil {
+		fmt.Printf("Error starting triggers: %v\n", err)
+	} else if len(cfg.Triggers) > 0 {
+		fmt.Printf("✓ Triggers started: %d\n", len(cfg.Triggers))
+	}
+
 	if err := channelManager.StartAll(ctx); err != nil {
 		fmt.Printf("Error starting channels: %v\n", err)
 	}
 
 	healthServer := health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port)
+	healthServer.Handle(triggers.WebhookPath, triggerService)
 	go func() {
 		if err := healthServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.ErrorCF("health", "Health server error", map[string]any{"error": err.Error()})
@@ -186,16 +251,38 @@ func gatewayCmd(debug bool) error {
 
 	go agentLoop.Run(ctx)
 
+	reload := &reloader{
+		provider:    provider,
+		modelID:     modelID,
+		modelKey:    modelKey,
+		agentLoop:   agentLoop,
+		channels:    channelManager,
+		transcriber: transcriber,
+		speaker:     speaker,
+	}
+	watcher, err := config.NewWatcher(internal.GetConfigPath(), func(next *config.Config) error {
+		return reload.apply(ctx, next)
+	})
+	if err != nil {
+		logger.WarnCF("config", "Config hot reload disabled", map[string]any{"error": err.Error()})
+	} else {
+		go watcher.Run(ctx)
+		fmt.Println("✓ Watching config for changes")
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 	<-sigChan
 
 	fmt.Println("\nShutting down...")
-	if cp, ok := provider.(providers.StatefulProvider); ok {
-		cp.Close()
-	}
 	cancel()
+	reload.close()
 	healthServer.Stop(context.Background())
+	triggerService.Stop()
+	if feedService != nil {
+		feedService.Stop()
+	}
+	sysMonitor.Stop()
 	deviceService.Stop()
 	heartbeatService.Stop()
 	cronService.Stop()
@@ -206,6 +293,43 @@ func gatewayCmd(debug bool) error {
 	return nil
 }
 
+// setupSysAlerts returns the monitor of the system alert rules, which
+// notifies the configured chat, or the chat used last.
+func setupSysAlerts(msgBus *bus.MessageBus, stateManager *state.Manager, cfg *config.Config) *sysinfo.Monitor {
+	sysCfg := cfg.Tools.SysInfo
+	rules := make([]sysinfo.Rule, 0, len(sysCfg.Alerts))
+	for _, alert := range sysCfg.Alerts {
+		target := alert.Path
+		if alert.Metric == sysinfo.MetricTemperature {
+			target = alert.Zone
+		}
+		rules = append(rules, sysinfo.Rule{
+			Metric: alert.Metric,
+			Target: target,
+			Above:  alert.Above,
+			Below:  alert.Below,
+			For:    time.Duration(alert.ForMinutes) * time.Minute,
+		})
+	}
+	interval := time.Duration(sysCfg.CheckSeconds) * time.Second
+	return sysinfo.NewMonitor(rules, interval, func(alert sysinfo.Alert) {
+		channel, chatID := sysCfg.Channel, sysCfg.ChatID
+		if channel == "" || chatID == "" {
+			var ok bool
+			channel, chatID, ok = strings.Cut(stateManager.GetLastChannel(), ":")
+			if !ok || channel == "" || chatID == "" || constants.IsInternalChannel(channel) {
+				logger.WarnCF("sysinfo", "No chat to send the alert to", map[string]any{"alert": alert.Message()})
+				return
+			}
+		}
+		msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: alert.Message(),
+		})
+	})
+}
+
 func setupCronTool(
 	agentLoop *agent.AgentLoop,
 	msgBus *bus.MessageBus,
@@ -218,10 +342,12 @@ func setupCronTool(
 
 	// Create cron service
 	cronService := cron.NewCronService(cronStorePath, nil)
+	cronService.SetCatchUp(cfg.Tools.Cron.CatchUp)
 
 	// Create and register CronTool
 	cronTool := tools.NewCronTool(cronService, agentLoop, msgBus, workspace, restrict, execTimeout, cfg)
 	agentLoop.RegisterTool(cronTool)
+	agentLoop.RegisterTool(tools.NewRemindTool(cronService))
 
 	// Set the onJob handler
 	cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
@@ -231,3 +357,33 @@ func setupCronTool(
 
 	return cronService
 }
+
+// setupFeeds registers the feeds tool and returns the service delivering its
+// subscriptions, or nil when the tool is off. Alerts are sent as they are;
+// digests are written up by the agent when SummarizeDigests is on.
+func setupFeeds(agentLoop *agent.AgentLoop, msgBus *bus.MessageBus, cfg *config.Config) *feeds.Service {
+	feedsCfg := cfg.Tools.Feeds
+	if !feedsCfg.Enabled {
+		return nil
+	}
+	storePath := filepath.Join(cfg.WorkspacePath(), "feeds", "subscriptions.json")
+	feedService := feeds.NewService(storePath, feeds.Options{
+		PollInterval:   time.Duration(feedsCfg.PollMinutes) * time.Minute,
+		MinScore:       float32(feedsCfg.MinScore),
+		MaxDigestItems: feedsCfg.MaxDigestItems,
+		Embed:          agentLoop.Embed,
+	}, func(ctx context.Context, d feeds.Delivery) {
+		channel, chatID := d.Subscription.Channel, d.Subscription.ChatID
+		if d.Kind == feeds.ModeDigest && feedsCfg.SummarizeDigests {
+			_, err := agentLoop.ProcessTrigger(ctx, "feeds", d.Message(), channel, chatID)
+			if err == nil {
+				return
+			}
+			logger.WarnCF("feeds", "Failed to write digest; sending the list",
+				map[string]any{"subscription": d.Subscription.ID, "error": err.Error()})
+		}
+		msgBus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: d.Text()})
+	})
+	agentLoop.RegisterTool(tools.NewFeedsTool(feedService))
+	return feedService
+}
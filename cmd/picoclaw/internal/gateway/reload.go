@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/voice"
+)
+
+// reloader applies config file changes to the running gateway: model
+// settings, tool settings and channel toggles. Gateway, heartbeat, device,
+// trigger and voice settings still need a restart.
+type reloader struct {
+	mu          sync.Mutex
+	provider    providers.LLMProvider
+	modelID     string // Model ID provider was created for
+	modelKey    string // modelSettings of the config provider was created from
+	agentLoop   *agent.AgentLoop
+	channels    *channels.Manager
+	transcriber voice.TranscriptionProvider
+	speaker     voice.SpeechProvider
+}
+
+// apply switches to next. The provider is only recreated when model settings
+// changed, and is created before anything else changes, so a config whose
+// model can't be set up leaves the gateway as it was.
+func (r *reloader) apply(ctx context.Context, next *config.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := modelSettings(next)
+	provider, modelID := r.provider, r.modelID
+	if key != r.modelKey {
+		p, id, err := providers.CreateProvider(next)
+		if err != nil {
+			return fmt.Errorf("error creating provider: %w", err)
+		}
+		provider, modelID = p, id
+	} else {
+		providers.MergeLegacyProviders(next)
+	}
+	if modelID != "" {
+		next.Agents.Defaults.ModelName = modelID
+	}
+
+	r.agentLoop.ReloadConfig(next, provider)
+	if provider != r.provider {
+		if sp, ok := r.provider.(providers.StatefulProvider); ok {
+			sp.Close()
+		}
+		r.provider = provider
+		r.modelID = modelID
+		r.modelKey = key
+		logger.InfoC("gateway", "Model settings reloaded")
+	}
+
+	if started := r.channels.Reload(ctx, next); len(started) > 0 {
+		attachVoice(r.channels, r.transcriber, r.speaker)
+		logger.InfoCF("gateway", "Channels started by config reload", map[string]any{"channels": started})
+	}
+	return nil
+}
+
+// close releases the current provider if it holds resources.
+func (r *reloader) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sp, ok := r.provider.(providers.StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// modelSettings fingerprints the parts of cfg the default provider is
+// created from. It must be taken before CreateProvider, which fills in
+// model_list from legacy providers.
+func modelSettings(cfg *config.Config) string {
+	data, _ := json.Marshal([]any{
+		cfg.ModelList,
+		cfg.Providers,
+		cfg.ProviderAliases,
+		cfg.HTTP,
+		cfg.Agents.Defaults.GetModelName(),
+	})
+	return string(data)
+}
+
+// attachVoice gives the Telegram channel voice transcription and replies,
+// when they are configured.
+func attachVoice(cm *channels.Manager, transcriber voice.TranscriptionProvider, speaker voice.SpeechProvider) {
+	telegramChannel, ok := cm.GetChannel("telegram")
+	if !ok {
+		return
+	}
+	tc, ok := telegramChannel.(*channels.TelegramChannel)
+	if !ok {
+		return
+	}
+	if transcriber != nil {
+		tc.SetTranscriber(transcriber)
+		logger.InfoC("voice", "Voice transcription attached to Telegram channel")
+	}
+	if speaker != nil {
+		tc.SetSpeaker(speaker)
+		logger.InfoC("voice", "Voice replies enabled for Telegram channel")
+	}
+}
@@ -27,6 +27,17 @@ func LoadConfig() (*config.Config, error) {
 	return config.LoadConfig(GetConfigPath())
 }
 
+// ProfileValue is the value of the --profile flag. Setting it sets
+// PICOCLAW_PROFILE, which every config load reads, including the gateway's
+// reloads.
+type ProfileValue struct{}
+
+func (ProfileValue) String() string { return os.Getenv(config.ProfileEnv) }
+
+func (ProfileValue) Set(name string) error { return os.Setenv(config.ProfileEnv, name) }
+
+func (ProfileValue) Type() string { return "string" }
+
 // FormatVersion returns the version string with optional git commit
 func FormatVersion() string {
 	v := version
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewMCPCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Use PicoClaw from MCP clients",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newServeCommand())
+
+	return cmd
+}
+
+func newServeCommand() *cobra.Command {
+	var debug bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the agent's tools, memory and scheduler over MCP on stdio",
+		Long: "Serve the agent's tools, memory search and scheduler to an MCP client, such as a desktop " +
+			"assistant or an editor, which runs this command and talks to it over stdin and stdout.",
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return serveCmd(debug)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+
+	return cmd
+}
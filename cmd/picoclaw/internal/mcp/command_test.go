@@ -0,0 +1,29 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPCommand(t *testing.T) {
+	cmd := NewMCPCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "mcp", cmd.Use)
+	assert.Equal(t, "Use PicoClaw from MCP clients", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	require.True(t, cmd.HasSubCommands())
+	subcommands := cmd.Commands()
+	require.Len(t, subcommands, 1)
+
+	serve := subcommands[0]
+	assert.Equal(t, "serve", serve.Name())
+	assert.NotNil(t, serve.RunE)
+	assert.NotNil(t, serve.Flags().Lookup("debug"))
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+func serveCmd(debug bool) error {
+	// Stdout carries the protocol; anything else printed goes to stderr,
+	// which clients log.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	if debug {
+		logger.SetLevel(logger.DEBUG)
+	}
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	provider, modelID, err := providers.CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating provider: %w", err)
+	}
+	if modelID != "" {
+		cfg.Agents.Defaults.ModelName = modelID
+	}
+
+	msgBus := bus.NewMessageBus()
+	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+	defer agentLoop.Stop()
+
+	// Jobs are only stored here; the gateway runs them.
+	cronService := cron.NewCronService(filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json"), nil)
+	cronService.SetCatchUp(cfg.Tools.Cron.CatchUp)
+	execTimeout := time.Duration(cfg.Tools.Cron.ExecTimeoutMinutes) * time.Minute
+	agentLoop.RegisterTool(tools.NewCronTool(cronService, agentLoop, msgBus, cfg.WorkspacePath(),
+		cfg.Agents.Defaults.RestrictToWorkspace, execTimeout, cfg))
+	agentLoop.RegisterTool(tools.NewRemindTool(cronService))
+
+	served := agentLoop.MCPServerTools(cfg.Tools.MCP.Serve.Tools)
+	logger.InfoCF("mcp", "Serving over stdio", map[string]any{"tools": len(served)})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := mcp.NewServer("picoclaw", internal.GetVersion(), served)
+	if err := server.Serve(ctx, os.Stdin, stdout); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+)
+
+func NewServiceCommand() *cobra.Command {
+	var (
+		initName string
+		userUnit bool
+	)
+	getTarget := func() (target, error) { return newTarget(initName, userUnit) }
+
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Run the gateway as a system service that starts on boot",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&initName, "init", "auto", "Service manager: systemd or openrc")
+	cmd.PersistentFlags().BoolVar(&userUnit, "user", false, "Use a systemd user service (no root needed)")
+
+	cmd.AddCommand(
+		newInstallCommand(getTarget),
+		newUninstallCommand(getTarget),
+		newControlCommand(getTarget, "start", "Start the service"),
+		newControlCommand(getTarget, "stop", "Stop the service"),
+		newControlCommand(getTarget, "restart", "Restart the service"),
+		newControlCommand(getTarget, "status", "Show whether the service is running"),
+		newLogsCommand(getTarget),
+	)
+
+	return cmd
+}
+
+func newInstallCommand(getTarget func() (target, error)) *cobra.Command {
+	var (
+		noSandbox bool
+		printOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the service and enable it on boot",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			t, err := getTarget()
+			if err != nil {
+				return err
+			}
+			return installCmd(t, !noSandbox, printOnly, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noSandbox, "no-sandbox", false, "Leave out the sandboxing directives")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the unit instead of installing it")
+
+	return cmd
+}
+
+func newUninstallCommand(getTarget func() (target, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop the service and remove it",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			t, err := getTarget()
+			if err != nil {
+				return err
+			}
+			return uninstallCmd(t, os.Stdout)
+		},
+	}
+}
+
+func newControlCommand(getTarget func() (target, error), action, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   action,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			t, err := getTarget()
+			if err != nil {
+				return err
+			}
+			err = t.control(action)
+			// A stopped service makes status exit non-zero after printing
+			// its state, which is an answer rather than an error.
+			var exitErr *exec.ExitError
+			if action == "status" && errors.As(err, &exitErr) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+func newLogsCommand(getTarget func() (target, error)) *cobra.Command {
+	var (
+		lines  int
+		follow bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the service's log",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			t, err := getTarget()
+			if err != nil {
+				return err
+			}
+			return t.logs(lines, follow)
+		},
+	}
+
+	cmd.Flags().IntVarP(&lines, "lines", "n", 100, "Number of lines to show")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep showing new lines")
+
+	return cmd
+}
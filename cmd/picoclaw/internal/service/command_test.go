@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceCommand(t *testing.T) {
+	cmd := NewServiceCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "service", cmd.Name())
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("init"))
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("user"))
+
+	var names []string
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"install", "uninstall", "start", "stop", "restart", "status", "logs"}, names)
+}
+
+// fakeSystem points the service at temporary directories and records the
+// commands run instead of running them.
+func fakeSystem(t *testing.T) *[]string {
+	t.Helper()
+	dir := t.TempDir()
+	oldRun, oldSystemd, oldOpenRC := runCommand, systemdRunDir, openrcRunDir
+	oldUnits, oldScripts := systemUnitDir, initScriptDir
+	t.Cleanup(func() {
+		runCommand, systemdRunDir, openrcRunDir = oldRun, oldSystemd, oldOpenRC
+		systemUnitDir, initScriptDir = oldUnits, oldScripts
+	})
+
+	var ran []string
+	runCommand = func(name string, args ...string) error {
+		ran = append(ran, strings.Join(append([]string{name}, args...), " "))
+		return nil
+	}
+	systemdRunDir = filepath.Join(dir, "run", "systemd")
+	openrcRunDir = filepath.Join(dir, "run", "openrc")
+	systemUnitDir = filepath.Join(dir, "etc", "systemd")
+	initScriptDir = filepath.Join(dir, "etc", "init.d")
+	return &ran
+}
+
+func TestNewTarget(t *testing.T) {
+	fakeSystem(t)
+
+	_, err := newTarget("auto", false)
+	assert.Error(t, err, "no service manager")
+
+	require.NoError(t, os.MkdirAll(openrcRunDir, 0o755))
+	tgt, err := newTarget("auto", false)
+	require.NoError(t, err)
+	assert.Equal(t, initOpenRC, tgt.init)
+
+	_, err = newTarget("openrc", true)
+	assert.Error(t, err, "--user needs systemd")
+
+	require.NoError(t, os.MkdirAll(systemdRunDir, 0o755))
+	tgt, err = newTarget("auto", false)
+	require.NoError(t, err)
+	assert.Equal(t, initSystemd, tgt.init)
+
+	_, err = newTarget("runit", false)
+	assert.Error(t, err)
+}
+
+func TestInstallAndUninstall_Systemd(t *testing.T) {
+	ran := fakeSystem(t)
+	tgt := target{init: initSystemd}
+
+	var out bytes.Buffer
+	require.NoError(t, installCmd(tgt, true, false, &out))
+
+	unit, err := os.ReadFile(filepath.Join(systemUnitDir, "picoclaw.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(unit), " gateway\n")
+	assert.Contains(t, string(unit), "ProtectSystem=strict\n")
+	assert.Equal(t, []string{"systemctl daemon-reload", "systemctl enable picoclaw"}, *ran)
+	assert.Contains(t, out.String(), "picoclaw service start")
+
+	*ran = nil
+	require.NoError(t, uninstallCmd(tgt, &out))
+	assert.NoFileExists(t, filepath.Join(systemUnitDir, "picoclaw.service"))
+	assert.Equal(t, []string{
+		"systemctl stop picoclaw", "systemctl disable picoclaw", "systemctl daemon-reload",
+	}, *ran)
+}
+
+func TestInstall_OpenRC(t *testing.T) {
+	ran := fakeSystem(t)
+	tgt := target{init: initOpenRC}
+
+	require.NoError(t, installCmd(tgt, true, false, &bytes.Buffer{}))
+
+	info, err := os.Stat(filepath.Join(initScriptDir, "picoclaw"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o111, "init script must be executable")
+	assert.Equal(t, []string{"rc-update add picoclaw default"}, *ran)
+}
+
+func TestInstall_Print(t *testing.T) {
+	ran := fakeSystem(t)
+
+	var out bytes.Buffer
+	require.NoError(t, installCmd(target{init: initSystemd}, true, true, &out))
+
+	assert.True(t, strings.HasPrefix(out.String(), "[Unit]\n"))
+	assert.Empty(t, *ran)
+	assert.NoFileExists(t, filepath.Join(systemUnitDir, "picoclaw.service"))
+}
+
+func TestControlAndLogs(t *testing.T) {
+	ran := fakeSystem(t)
+
+	require.NoError(t, target{init: initSystemd, userUnit: true}.control("start"))
+	require.NoError(t, target{init: initSystemd}.logs(50, true))
+	require.NoError(t, target{init: initOpenRC}.control("status"))
+	require.NoError(t, target{init: initOpenRC}.logs(20, false))
+
+	assert.Equal(t, []string{
+		"systemctl --user start picoclaw",
+		"journalctl -u picoclaw -n 50 --no-pager -f",
+		"rc-service picoclaw status",
+		"tail -n 20 /var/log/picoclaw/picoclaw.log",
+	}, *ran)
+}
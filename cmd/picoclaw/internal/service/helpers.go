@@ -0,0 +1,263 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const serviceName = "picoclaw"
+
+const (
+	initSystemd = "systemd"
+	initOpenRC  = "openrc"
+)
+
+// Replaced by tests.
+var (
+	runCommand = func(name string, args ...string) error {
+		cmd := exec.Command(name, args...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return cmd.Run()
+	}
+	systemdRunDir = "/run/systemd/system"
+	openrcRunDir  = "/run/openrc"
+	systemUnitDir = "/etc/systemd/system"
+	initScriptDir = "/etc/init.d"
+)
+
+// target is the service manager commands go to.
+type target struct {
+	init     string // initSystemd or initOpenRC
+	userUnit bool   // systemd --user
+}
+
+// newTarget picks the service manager: initName if given, otherwise the one
+// the system booted with.
+func newTarget(initName string, userUnit bool) (target, error) {
+	switch initName {
+	case "", "auto":
+		if _, err := os.Stat(systemdRunDir); err == nil {
+			initName = initSystemd
+		} else if _, err := os.Stat(openrcRunDir); err == nil {
+			initName = initOpenRC
+		} else {
+			return target{}, errors.New("no supported service manager found (systemd or OpenRC); use --init to choose one")
+		}
+	case initSystemd, initOpenRC:
+	default:
+		return target{}, fmt.Errorf("unknown init system %q, use systemd or openrc", initName)
+	}
+	if userUnit && initName != initSystemd {
+		return target{}, errors.New("--user is only supported with systemd")
+	}
+	return target{init: initName, userUnit: userUnit}, nil
+}
+
+// path returns where the unit or init script is installed.
+func (t target) path() (string, error) {
+	switch {
+	case t.init == initOpenRC:
+		return filepath.Join(initScriptDir, serviceName), nil
+	case t.userUnit:
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "systemd", "user", serviceName+".service"), nil
+	default:
+		return filepath.Join(systemUnitDir, serviceName+".service"), nil
+	}
+}
+
+// systemctl runs systemctl, with --user for user units.
+func (t target) systemctl(args ...string) error {
+	if t.userUnit {
+		args = append([]string{"--user"}, args...)
+	}
+	return runCommand("systemctl", args...)
+}
+
+// control runs a start, stop or status action.
+func (t target) control(action string) error {
+	if t.init == initOpenRC {
+		return runCommand("rc-service", serviceName, action)
+	}
+	if action == "status" {
+		return t.systemctl("status", "--no-pager", serviceName)
+	}
+	return t.systemctl(action, serviceName)
+}
+
+// logs shows the service's recent output.
+func (t target) logs(lines int, follow bool) error {
+	if t.init == initOpenRC {
+		args := []string{"-n", strconv.Itoa(lines)}
+		if follow {
+			args = append(args, "-F")
+		}
+		return runCommand("tail", append(args, filepath.Join(openrcLogDir, "picoclaw.log"))...)
+	}
+	args := []string{"-u", serviceName, "-n", strconv.Itoa(lines), "--no-pager"}
+	if t.userUnit {
+		args = []string{"--user-unit", serviceName, "-n", strconv.Itoa(lines), "--no-pager"}
+	}
+	if follow {
+		args = append(args, "-f")
+	}
+	return runCommand("journalctl", args...)
+}
+
+// options describes the service for the current installation: this binary,
+// run as the invoking user (the one who ran sudo, when installed with sudo).
+func (t target) options(sandbox bool) (unitOptions, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return unitOptions{}, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	account, err := user.Current()
+	if err != nil {
+		return unitOptions{}, err
+	}
+	if name := os.Getenv("SUDO_USER"); name != "" && account.Uid == "0" && !t.userUnit {
+		if account, err = user.Lookup(name); err != nil {
+			return unitOptions{}, err
+		}
+	}
+
+	o := unitOptions{
+		Executable: exe,
+		User:       account.Username,
+		Home:       account.HomeDir,
+		UserUnit:   t.userUnit,
+		Sandbox:    sandbox,
+	}
+	configDir := filepath.Join(account.HomeDir, ".picoclaw")
+	o.Writable = []string{configDir}
+	if ws := workspaceFor(filepath.Join(configDir, "config.json"), account.HomeDir); ws != "" &&
+		!strings.HasPrefix(ws, configDir+string(filepath.Separator)) {
+		o.Writable = append(o.Writable, ws)
+	}
+	if profile := os.Getenv(config.ProfileEnv); profile != "" {
+		o.Env = append(o.Env, config.ProfileEnv+"="+profile)
+	}
+	return o, nil
+}
+
+// workspaceFor returns the workspace configured in the config file at path,
+// with "~" meaning home, or "" when it can't be read.
+func workspaceFor(path, home string) string {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return ""
+	}
+	ws := cfg.Agents.Defaults.Workspace
+	if ws == "~" || strings.HasPrefix(ws, "~/") {
+		ws = filepath.Join(home, ws[1:])
+	}
+	if !filepath.IsAbs(ws) {
+		return ""
+	}
+	return filepath.Clean(ws)
+}
+
+// render returns the unit or init script for o.
+func (t target) render(o unitOptions) string {
+	if t.init == initOpenRC {
+		return openrcScript(o)
+	}
+	return systemdUnit(o)
+}
+
+func installCmd(t target, sandbox, printOnly bool, out io.Writer) error {
+	o, err := t.options(sandbox)
+	if err != nil {
+		return err
+	}
+	content := t.render(o)
+	if printOnly {
+		fmt.Fprint(out, content)
+		return nil
+	}
+
+	path, err := t.path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return permissionHint(err)
+	}
+	mode := os.FileMode(0o644)
+	if t.init == initOpenRC {
+		mode = 0o755
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return permissionHint(err)
+	}
+	fmt.Fprintf(out, "✓ Wrote %s\n", path)
+
+	if t.init == initOpenRC {
+		err = runCommand("rc-update", "add", serviceName, "default")
+	} else {
+		if err = t.systemctl("daemon-reload"); err == nil {
+			err = t.systemctl("enable", serviceName)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("enabling the service: %w", err)
+	}
+
+	fmt.Fprintf(out, "✓ PicoClaw starts on boot as %s\n", o.User)
+	if t.userUnit {
+		fmt.Fprintf(out, "  User services only start at boot with lingering: sudo loginctl enable-linger %s\n", o.User)
+	}
+	fmt.Fprintln(out, "  Start it now with: picoclaw service start")
+	return nil
+}
+
+func uninstallCmd(t target, out io.Writer) error {
+	path, err := t.path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service is not installed: %w", err)
+	}
+
+	// Stopping fails when the service isn't running, which is fine.
+	_ = t.control("stop")
+	if t.init == initOpenRC {
+		_ = runCommand("rc-update", "del", serviceName, "default")
+	} else {
+		_ = t.systemctl("disable", serviceName)
+	}
+	if err := os.Remove(path); err != nil {
+		return permissionHint(err)
+	}
+	if t.init == initSystemd {
+		_ = t.systemctl("daemon-reload")
+	}
+	fmt.Fprintf(out, "✓ Removed %s\n", path)
+	return nil
+}
+
+// permissionHint explains how to install system services when writing the
+// unit fails for lack of permission.
+func permissionHint(err error) error {
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w; run with sudo, or use --user for a systemd user service", err)
+	}
+	return err
+}
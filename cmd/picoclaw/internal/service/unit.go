@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unitOptions describes the service to generate.
+type unitOptions struct {
+	Executable string   // Absolute path of the picoclaw binary
+	User       string   // Account to run as; empty for systemd user units
+	Home       string   // That account's home directory
+	Writable   []string // Directories the service writes to (config dir, workspace)
+	Env        []string // KEY=value pairs, e.g. PICOCLAW_PROFILE
+	UserUnit   bool     // systemd --user unit
+	Sandbox    bool
+}
+
+// systemdUnit returns a unit that runs the gateway and restarts it on
+// failure. The sandbox keeps the system read-only apart from the writable
+// directories; devices stay reachable so boards can use their hardware.
+func systemdUnit(o unitOptions) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=PicoClaw AI assistant gateway\n")
+	b.WriteString("Documentation=https://github.com/sipeed/picoclaw\n")
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s gateway\n", quoteSystemd(o.Executable))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	if !o.UserUnit {
+		fmt.Fprintf(&b, "User=%s\n", o.User)
+		fmt.Fprintf(&b, "Environment=%s\n", quoteSystemd("HOME="+o.Home))
+	}
+	for _, env := range o.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", quoteSystemd(env))
+	}
+
+	if o.Sandbox {
+		b.WriteString("\n# Sandboxing. Remove lines here if tools need more access.\n")
+		b.WriteString("NoNewPrivileges=yes\n")
+		if !o.UserUnit {
+			b.WriteString("PrivateTmp=yes\n")
+			b.WriteString("ProtectSystem=strict\n")
+			b.WriteString("ProtectHome=read-only\n")
+			for _, dir := range o.Writable {
+				fmt.Fprintf(&b, "ReadWritePaths=-%s\n", quoteSystemd(dir))
+			}
+			b.WriteString("ProtectKernelTunables=yes\n")
+			b.WriteString("ProtectKernelModules=yes\n")
+			b.WriteString("ProtectControlGroups=yes\n")
+			b.WriteString("RestrictSUIDSGID=yes\n")
+			b.WriteString("RestrictRealtime=yes\n")
+			b.WriteString("LockPersonality=yes\n")
+		}
+	}
+
+	b.WriteString("\n[Install]\n")
+	if o.UserUnit {
+		b.WriteString("WantedBy=default.target\n")
+	} else {
+		b.WriteString("WantedBy=multi-user.target\n")
+	}
+	return b.String()
+}
+
+// quoteSystemd quotes s for a unit file when it contains spaces or quotes.
+func quoteSystemd(s string) string {
+	if !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// openrcLogDir holds the gateway's output under OpenRC, which has no journal.
+const openrcLogDir = "/var/log/picoclaw"
+
+// openrcScript returns an init script that runs the gateway under
+// supervise-daemon, which restarts it when it exits.
+func openrcScript(o unitOptions) string {
+	var b strings.Builder
+	b.WriteString("#!/sbin/openrc-run\n\n")
+	b.WriteString("name=\"picoclaw\"\n")
+	b.WriteString("description=\"PicoClaw AI assistant gateway\"\n")
+	fmt.Fprintf(&b, "command=%s\n", quoteShell(o.Executable))
+	b.WriteString("command_args=\"gateway\"\n")
+	fmt.Fprintf(&b, "command_user=%s\n", quoteShell(o.User))
+	b.WriteString("supervisor=\"supervise-daemon\"\n")
+	b.WriteString("respawn_delay=5\n")
+	fmt.Fprintf(&b, "output_log=\"%s/picoclaw.log\"\n", openrcLogDir)
+	fmt.Fprintf(&b, "error_log=\"%s/picoclaw.log\"\n", openrcLogDir)
+	if o.Sandbox {
+		b.WriteString("no_new_privs=\"yes\"\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "export HOME=%s\n", quoteShell(o.Home))
+	for _, env := range o.Env {
+		key, value, _ := strings.Cut(env, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", key, quoteShell(value))
+	}
+	b.WriteString("\ndepend() {\n\tneed net\n\tafter firewall\n}\n")
+	b.WriteString("\nstart_pre() {\n")
+	fmt.Fprintf(&b, "\tcheckpath --directory --owner %s %s\n", quoteShell(o.User), openrcLogDir)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// quoteShell single-quotes s for a shell script.
+func quoteShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit(unitOptions{
+		Executable: "/usr/local/bin/picoclaw",
+		User:       "pi",
+		Home:       "/home/pi",
+		Writable:   []string{"/home/pi/.picoclaw", "/srv/my workspace"},
+		Env:        []string{"PICOCLAW_PROFILE=home"},
+		Sandbox:    true,
+	})
+
+	for _, line := range []string{
+		"ExecStart=/usr/local/bin/picoclaw gateway",
+		"User=pi",
+		"Environment=HOME=/home/pi",
+		"Environment=PICOCLAW_PROFILE=home",
+		"Restart=on-failure",
+		"ProtectHome=read-only",
+		"ReadWritePaths=-/home/pi/.picoclaw",
+		`ReadWritePaths=-"/srv/my workspace"`,
+		"WantedBy=multi-user.target",
+	} {
+		assert.Contains(t, unit, line+"\n")
+	}
+	assert.NotContains(t, unit, "PrivateDevices", "boards need their devices")
+}
+
+func TestSystemdUnit_UserNoSandbox(t *testing.T) {
+	unit := systemdUnit(unitOptions{Executable: "/opt/picoclaw", UserUnit: true})
+
+	assert.NotContains(t, unit, "User=")
+	assert.NotContains(t, unit, "ProtectSystem")
+	assert.Contains(t, unit, "WantedBy=default.target\n")
+}
+
+func TestOpenRCScript(t *testing.T) {
+	script := openrcScript(unitOptions{
+		Executable: "/usr/bin/picoclaw",
+		User:       "pi",
+		Home:       "/home/pi",
+		Env:        []string{"PICOCLAW_PROFILE=it's"},
+		Sandbox:    true,
+	})
+
+	assert.True(t, strings.HasPrefix(script, "#!/sbin/openrc-run\n"))
+	for _, line := range []string{
+		"command='/usr/bin/picoclaw'",
+		`command_args="gateway"`,
+		"command_user='pi'",
+		`supervisor="supervise-daemon"`,
+		"export HOME='/home/pi'",
+		`export PICOCLAW_PROFILE='it'\''s'`,
+		"\tcheckpath --directory --owner 'pi' /var/log/picoclaw",
+	} {
+		assert.Contains(t, script, line+"\n")
+	}
+}
@@ -67,6 +67,8 @@ func NewSkillsCommand() *cobra.Command {
 
 	cmd.AddCommand(
 		newListCommand(loaderFn),
+		newEnableCommand(loaderFn),
+		newDisableCommand(loaderFn),
 		newInstallCommand(installerFn),
 		newInstallBuiltinCommand(workspaceFn),
 		newListBuiltinCommand(),
@@ -0,0 +1,25 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newDisableCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "disable",
+		Short:   "Disable a skill without removing it",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw skills disable weather`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			loader, err := loaderFn()
+			if err != nil {
+				return err
+			}
+			return skillsSetEnabledCmd(loader, args[0], false)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,25 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newEnableCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "enable",
+		Short:   "Enable a disabled skill",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw skills enable weather`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			loader, err := loaderFn()
+			if err != nil {
+				return err
+			}
+			return skillsSetEnabledCmd(loader, args[0], true)
+		},
+	}
+
+	return cmd
+}
@@ -26,11 +26,33 @@ func skillsListCmd(loader *skills.SkillsLoader) {
 	fmt.Println("\nInstalled Skills:")
 	fmt.Println("------------------")
 	for _, skill := range allSkills {
-		fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		if skill.Disabled {
+			fmt.Printf("  ✗ %s (%s, disabled)\n", skill.Name, skill.Source)
+		} else {
+			fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		}
 		if skill.Description != "" {
 			fmt.Printf("    %s\n", skill.Description)
 		}
+		if len(skill.Keywords) > 0 {
+			fmt.Printf("    keywords: %s\n", strings.Join(skill.Keywords, ", "))
+		}
+		if len(skill.Tools) > 0 {
+			fmt.Printf("    tools: %s\n", strings.Join(skill.Tools, ", "))
+		}
+	}
+}
+
+func skillsSetEnabledCmd(loader *skills.SkillsLoader, name string, enabled bool) error {
+	if err := loader.SetEnabled(name, enabled); err != nil {
+		return fmt.Errorf("✗ %w", err)
 	}
+	if enabled {
+		fmt.Printf("✓ Skill '%s' enabled\n", name)
+	} else {
+		fmt.Printf("✓ Skill '%s' disabled\n", name)
+	}
+	return nil
 }
 
 func skillsInstallCmd(installer *skills.SkillInstaller, repo string) error {
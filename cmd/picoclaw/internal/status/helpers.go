@@ -29,6 +29,9 @@ func statusCmd() {
 	} else {
 		fmt.Println("Config:", configPath, "✗")
 	}
+	if cfg.Profile != "" {
+		fmt.Println("Profile:", cfg.Profile)
+	}
 
 	workspace := cfg.WorkspacePath()
 	if _, err := os.Stat(workspace); err == nil {
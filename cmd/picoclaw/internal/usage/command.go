@@ -0,0 +1,24 @@
+package usage
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewUsageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "usage [today|week|month|all]",
+		Aliases:   []string{"u"},
+		Short:     "Show token usage and estimated cost",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"today", "week", "month", "all"},
+		RunE: func(_ *cobra.Command, args []string) error {
+			period := "today"
+			if len(args) > 0 {
+				period = args[0]
+			}
+			return usageCmd(period)
+		},
+	}
+
+	return cmd
+}
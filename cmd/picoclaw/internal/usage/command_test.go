@@ -0,0 +1,25 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUsageCommand(t *testing.T) {
+	cmd := NewUsageCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "usage", cmd.Name())
+	assert.True(t, cmd.HasAlias("u"))
+	assert.Equal(t, "Show token usage and estimated cost", cmd.Short)
+
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	assert.NoError(t, cmd.Args(cmd, []string{"week"}))
+	assert.Error(t, cmd.Args(cmd, []string{"year"}))
+	assert.Error(t, cmd.Args(cmd, []string{"week", "month"}))
+}
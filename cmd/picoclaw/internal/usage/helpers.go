@@ -0,0 +1,25 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+func usageCmd(period string) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	since, err := usage.PeriodStart(period, time.Now())
+	if err != nil {
+		return err
+	}
+
+	tracker := usage.NewTracker(cfg.WorkspacePath())
+	fmt.Print(tracker.Summary(since).Format("Usage (" + period + ")"))
+	return nil
+}
@@ -14,11 +14,17 @@ import (
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/agent"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/auth"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/check"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cron"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/doctor"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/gateway"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/mcp"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/onboard"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/service"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/skills"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/status"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/usage"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/version"
 )
 
@@ -31,13 +37,22 @@ func NewPicoclawCommand() *cobra.Command {
 		Example: "picoclaw list",
 	}
 
+	cmd.PersistentFlags().Var(internal.ProfileValue{}, "profile",
+		"Config profile to use (or set PICOCLAW_PROFILE)")
+
 	cmd.AddCommand(
 		onboard.NewOnboardCommand(),
 		agent.NewAgentCommand(),
+		auth.NewAuthCommand(),
 		gateway.NewGatewayCommand(),
 		status.NewStatusCommand(),
+		service.NewServiceCommand(),
+		check.NewCheckCommand(),
+		doctor.NewDoctorCommand(),
 		cron.NewCronCommand(),
 		skills.NewSkillsCommand(),
+		mcp.NewMCPCommand(),
+		usage.NewUsageCommand(),
 		version.NewVersionCommand(),
 	)
 
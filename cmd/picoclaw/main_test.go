@@ -24,7 +24,8 @@ func TestNewPicoclawCommand(t *testing.T) {
 	assert.True(t, cmd.HasSubCommands())
 	assert.True(t, cmd.HasAvailableSubCommands())
 
-	assert.False(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("profile"))
+	assert.False(t, cmd.LocalNonPersistentFlags().HasFlags())
 
 	assert.Nil(t, cmd.Run)
 	assert.Nil(t, cmd.RunE)
@@ -35,12 +36,16 @@ func TestNewPicoclawCommand(t *testing.T) {
 	allowedCommands := []string{
 		"agent",
 		"auth",
+		"check",
 		"cron",
+		"doctor",
 		"gateway",
-		"migrate",
+		"mcp",
 		"onboard",
+		"service",
 		"skills",
 		"status",
+		"usage",
 		"version",
 	}
 
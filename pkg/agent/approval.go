@@ -0,0 +1,259 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const defaultApprovalTimeout = 2 * time.Minute
+
+// approvalButtons are offered with each approval question; parseApproval
+// understands both.
+var approvalButtons = []string{"Approve", "Deny"}
+
+// errApprovalDenied is returned for a tool call the user said no to.
+var errApprovalDenied = errors.New("the user denied it")
+
+// pendingApprovals holds the approval questions waiting for an answer, by
+// chat key. The zero value is ready to use.
+type pendingApprovals struct {
+	mu      sync.Mutex
+	waiting map[string]pendingApproval
+}
+
+// pendingApproval is a question waiting for user's answer; any user may
+// answer when user is "".
+type pendingApproval struct {
+	user   string
+	answer chan bool
+}
+
+// wait registers a question in chat key for user (see identifyUser) and
+// returns the channel its answer arrives on. done must be called when no
+// longer waiting.
+func (p *pendingApprovals) wait(key, user string) (answer <-chan bool, done func()) {
+	ch := make(chan bool, 1)
+	p.mu.Lock()
+	if p.waiting == nil {
+		p.waiting = make(map[string]pendingApproval)
+	}
+	p.waiting[key] = pendingApproval{user: user, answer: ch}
+	p.mu.Unlock()
+
+	return ch, func() {
+		p.mu.Lock()
+		if p.waiting[key].answer == ch {
+			delete(p.waiting, key)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// answer delivers content from user to the question waiting in chat key,
+// if there is one, it is user's to answer and content answers it. It
+// reports whether it did.
+func (p *pendingApprovals) answer(key, user, content string) bool {
+	approved, ok := parseApproval(content)
+	if !ok {
+		return false
+	}
+	p.mu.Lock()
+	pending, found := p.waiting[key]
+	if !found || pending.user != "" && pending.user != user {
+		p.mu.Unlock()
+		return false
+	}
+	delete(p.waiting, key)
+	p.mu.Unlock()
+	pending.answer <- approved
+	return true
+}
+
+// parseApproval reads a reply to an approval question. ok is false when
+// the reply is neither yes nor no.
+func parseApproval(content string) (approved, ok bool) {
+	switch strings.ToLower(strings.Trim(strings.TrimSpace(content), ".!")) {
+	case "yes", "y", "ok", "approve", "allow", "go ahead":
+		return true, true
+	case "no", "n", "deny", "cancel", "don't":
+		return false, true
+	}
+	return false, false
+}
+
+// SetApprovalPrompt sets how the CLI asks for tool call approval: prompt
+// shows question and returns the user's answer. Without it, calls that
+// need approval in the CLI get the configured default.
+func (al *AgentLoop) SetApprovalPrompt(prompt func(ctx context.Context, question string) (string, error)) {
+	al.approvalPrompt = prompt
+}
+
+// approverFor returns the approver for the tool calls of a turn, or nil
+// when tools.approval is off and the git tool can neither push nor open
+// pull requests. Questions are asked one at a time.
+func (al *AgentLoop) approverFor(agent *AgentInstance, opts processOptions) tools.Approver {
+	cfg := al.config().Tools.Approval
+	if !cfg.Enabled {
+		// Pushes and pull requests are put to the user even so.
+		if git := al.config().Tools.Git; !git.Enabled || (!git.AllowPush && len(git.Forges) == 0) {
+			return nil
+		}
+		cfg.Rules = []config.ApprovalRule{{Tool: "*", Action: tools.ApprovalAllow}}
+	}
+	policy, err := tools.NewApprovalPolicy(cfg)
+	if err != nil {
+		// Rather than let risky calls through, ask about every call.
+		logger.ErrorCF("agent", "Invalid tool approval rules, asking about every call",
+			map[string]any{"error": err.Error()})
+		policy, _ = tools.NewApprovalPolicy(config.ApprovalConfig{
+			Rules: []config.ApprovalRule{{Tool: "*", Action: tools.ApprovalAsk}},
+		})
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	allowByDefault := cfg.Default == tools.ApprovalAllow
+
+	scope := tools.ApprovalScope{
+		User:      opts.User,
+		Channel:   opts.Channel,
+		Agent:     agent.ID,
+		Workspace: agent.Workspace,
+	}
+
+	var mu sync.Mutex
+	return func(ctx context.Context, name string, args map[string]any) error {
+		action, rule := policy.DecideFor(scope, name, args)
+		mustAsk := tools.MustAsk(name, args)
+		if mustAsk && action == tools.ApprovalAllow {
+			action = tools.ApprovalAsk
+		}
+		// Every decision is logged, so the log shows which rule let each
+		// call through.
+		fields := map[string]any{
+			"agent_id": agent.ID, "user": opts.User, "channel": opts.Channel,
+			"tool": name, "action": action, "rule": rule,
+		}
+		logger.InfoCF("agent", "Tool call policy decision", fields)
+		switch action {
+		case tools.ApprovalAllow:
+			return nil
+		case tools.ApprovalDeny:
+			return errors.New("the tool approval rules don't allow this call")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return al.askApproval(ctx, agent, opts, name, args, timeout, allowByDefault && !mustAsk)
+	}
+}
+
+// askApproval asks the user whether the call to tool name with args may
+// run, and returns nil when it may. Without an answer within timeout, or
+// with nobody to ask, allowByDefault decides.
+func (al *AgentLoop) askApproval(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+	name string,
+	args map[string]any,
+	timeout time.Duration,
+	allowByDefault bool,
+) error {
+	call := describeToolCall(name, args)
+	fields := map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey, "tool": name}
+	byDefault := func(reason string) error {
+		if allowByDefault {
+			return nil
+		}
+		return errors.New(reason)
+	}
+
+	if opts.Channel == "cli" && al.approvalPrompt != nil {
+		answer, err := al.approvalPrompt(ctx, "Allow this tool call?\n"+call)
+		if err != nil {
+			return fmt.Errorf("no answer: %w", err)
+		}
+		if approved, _ := parseApproval(answer); !approved {
+			return errApprovalDenied
+		}
+		return nil
+	}
+	if opts.NoHistory || opts.ChatID == "" || constants.IsInternalChannel(opts.Channel) {
+		logger.WarnCF("agent", "Nobody to ask for tool approval, using the default", fields)
+		return byDefault("there was nobody to ask for approval")
+	}
+
+	// Only the user whose message started the turn may answer; turns no
+	// one started, such as triggers', are for anyone who may use the chat.
+	approver := opts.User
+	if approver == profileKey(opts.Channel, "", opts.ChatID) {
+		approver = ""
+	}
+	answer, done := al.approvals.wait(chatKey(opts.Channel, opts.ChatID), approver)
+	defer done()
+	outcome := "denied"
+	if allowByDefault {
+		outcome = "allowed"
+	}
+	al.bus.PublishOutbound(bus.OutboundMessage{
+		Channel: opts.Channel,
+		ChatID:  opts.ChatID,
+		Content: fmt.Sprintf("Allow this tool call?\n%s\nReply yes or no. Without an answer within %s, it is %s.",
+			call, describeDuration(timeout), outcome),
+		Buttons: approvalButtons,
+	})
+
+	// The buttons are removed once the question is settled, not when
+	// someone it isn't for presses one.
+	defer al.bus.PublishOutbound(bus.OutboundMessage{Channel: opts.Channel, ChatID: opts.ChatID, ClearButtons: true})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case approved := <-answer:
+		fields["approved"] = approved
+		logger.InfoCF("agent", "Tool call approval answered", fields)
+		if !approved {
+			return errApprovalDenied
+		}
+		return nil
+	case <-timer.C:
+		logger.InfoCF("agent", "Tool call approval timed out", fields)
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: opts.Channel,
+			ChatID:  opts.ChatID,
+			Content: fmt.Sprintf("No answer, so the tool call was %s.", outcome),
+		})
+		return byDefault(fmt.Sprintf("the user didn't answer within %s", describeDuration(timeout)))
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// describeToolCall shows a tool call to the user: the command for exec,
+// the arguments as JSON otherwise.
+func describeToolCall(name string, args map[string]any) string {
+	if command, ok := args["command"].(string); ok && name == "exec" {
+		return "exec: " + utils.Truncate(command, 500)
+	}
+	argsJSON, _ := json.Marshal(args)
+	return name + ": " + utils.Truncate(string(argsJSON), 500)
+}
@@ -0,0 +1,258 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// withApproval sets tools.approval, with turns of at most two tool
+// iterations.
+func withApproval(approval config.ApprovalConfig) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Agents.Defaults.MaxToolIterations = 2
+		cfg.Tools.Approval = approval
+	}
+}
+
+func TestApproval_AskInChat(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, withApproval(config.ApprovalConfig{
+		Enabled: true,
+		Rules:   []config.ApprovalRule{{Tool: "counting", Action: "ask"}},
+	}))
+	al.RegisterTool(&countingTool{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go al.Run(ctx)
+
+	approve := al.approverFor(al.registry.GetDefaultAgent(), processOptions{Channel: "telegram", ChatID: "42"})
+	for _, tt := range []struct {
+		answer string
+		want   error
+	}{
+		{"Approve", nil},
+		{"no", errApprovalDenied},
+	} {
+		result := make(chan error, 1)
+		go func() { result <- approve(ctx, "counting", map[string]any{}) }()
+
+		question, ok := al.bus.SubscribeOutbound(ctx)
+		if !ok {
+			t.Fatal("no approval question was sent")
+		}
+		if !strings.HasPrefix(question.Content, "Allow this tool call?\ncounting: {}") ||
+			!slices.Equal(question.Buttons, approvalButtons) {
+			t.Errorf("question = %+v", question)
+		}
+		al.bus.PublishInbound(bus.InboundMessage{Channel: "telegram", ChatID: "42", Content: tt.answer})
+		if err := <-result; !errors.Is(err, tt.want) {
+			t.Errorf("answer %q gave %v, want %v", tt.answer, err, tt.want)
+		}
+		if clear, ok := al.bus.SubscribeOutbound(ctx); !ok || !clear.ClearButtons {
+			t.Errorf("after the answer = %+v, want the buttons cleared", clear)
+		}
+	}
+}
+
+func TestApproval_OnlyTheWaitingUserAnswers(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, withApproval(config.ApprovalConfig{
+		Enabled: true,
+		Rules:   []config.ApprovalRule{{Tool: "counting", Action: "ask"}},
+	}))
+	al.RegisterTool(&countingTool{})
+	al.config().Users = map[string]config.UserConfig{
+		"alice": {IDs: []string{"telegram:1"}},
+		"bob":   {IDs: []string{"telegram:2"}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go al.Run(ctx)
+
+	approve := al.approverFor(al.registry.GetDefaultAgent(),
+		processOptions{Channel: "telegram", ChatID: "group", User: "alice"})
+	result := make(chan error, 1)
+	go func() { result <- approve(ctx, "counting", map[string]any{}) }()
+	if _, ok := al.bus.SubscribeOutbound(ctx); !ok {
+		t.Fatal("no approval question was sent")
+	}
+
+	// Neither a stranger in the group nor another user can approve
+	// alice's call; her own answer decides.
+	if al.approvals.answer(chatKey("telegram", "group"), "bob", "yes") {
+		t.Error("bob answered alice's question")
+	}
+	al.bus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "9", ChatID: "group", Content: "yes"})
+	// bob pressing a button neither answers nor starts a turn.
+	al.bus.PublishInbound(bus.InboundMessage{
+		Channel: "telegram", SenderID: "2", ChatID: "group", Content: "Approve",
+		Metadata: map[string]string{bus.MetadataButton: "true"},
+	})
+	al.bus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "1", ChatID: "group", Content: "no"})
+	if err := <-result; !errors.Is(err, errApprovalDenied) {
+		t.Errorf("approval = %v, want alice's denial", err)
+	}
+	if clear, ok := al.bus.SubscribeOutbound(ctx); !ok || !clear.ClearButtons {
+		t.Errorf("after alice's answer = %+v, want the buttons cleared", clear)
+	}
+	quiet, cancelQuiet := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelQuiet()
+	if out, ok := al.bus.SubscribeOutbound(quiet); ok {
+		t.Errorf("bob's button press was answered with %+v", out)
+	}
+}
+
+func TestApproval_TimeoutUsesDefault(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1},
+		withApproval(config.ApprovalConfig{Enabled: true, TimeoutSeconds: 1, Default: "allow"}))
+	al.RegisterTool(&countingTool{})
+	opts := processOptions{Channel: "telegram", ChatID: "42"}
+
+	err := al.approverFor(al.registry.GetDefaultAgent(), opts)(context.Background(), "exec", map[string]any{"command": "make"})
+	if err != nil {
+		t.Errorf("unanswered call with default allow: %v", err)
+	}
+	question, _ := al.bus.SubscribeOutbound(context.Background())
+	if !strings.Contains(question.Content, "exec: make\n") || !strings.Contains(question.Content, "within 1 second, it is allowed") {
+		t.Errorf("question = %q", question.Content)
+	}
+	if note, _ := al.bus.SubscribeOutbound(context.Background()); note.Content != "No answer, so the tool call was allowed." {
+		t.Errorf("timeout note = %q", note.Content)
+	}
+}
+
+func TestApproval_PolicyAndNobodyToAsk(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, withApproval(config.ApprovalConfig{
+		Enabled: true,
+		Rules: []config.ApprovalRule{
+			{Tool: "exec", Pattern: `\brm\b`, Action: "deny"},
+			{Tool: "exec", Pattern: `^ls\b`, Action: "allow"},
+			{Tool: "exec", Action: "ask"},
+		},
+	}))
+	al.RegisterTool(&countingTool{})
+	approve := al.approverFor(al.registry.GetDefaultAgent(), processOptions{Channel: "system", ChatID: "x"})
+	ctx := context.Background()
+
+	if err := approve(ctx, "exec", map[string]any{"command": "rm -rf build"}); err == nil {
+		t.Error("denied call was allowed")
+	}
+	if err := approve(ctx, "exec", map[string]any{"command": "ls -la"}); err != nil {
+		t.Errorf("allowed call: %v", err)
+	}
+	if err := approve(ctx, "exec", map[string]any{"command": "make"}); err == nil {
+		t.Error("call needing approval ran with nobody to ask")
+	}
+	if err := approve(ctx, "read_file", map[string]any{"path": "a"}); err != nil {
+		t.Errorf("unmatched call: %v", err)
+	}
+
+	al.config().Tools.Approval.Enabled = false
+	if al.approverFor(al.registry.GetDefaultAgent(), processOptions{}) != nil {
+		t.Error("approver while approval is off")
+	}
+}
+
+func TestApproval_PushesAskedWithApprovalOff(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, func(cfg *config.Config) {
+		cfg.Tools.Approval.TimeoutSeconds = 1
+		cfg.Tools.Approval.Default = "allow"
+		cfg.Tools.Git.Enabled = true
+		cfg.Tools.Git.AllowPush = true
+	})
+	approve := al.approverFor(al.registry.GetDefaultAgent(), processOptions{Channel: "telegram", ChatID: "42"})
+	if approve == nil {
+		t.Fatal("no approver while the git tool may push")
+	}
+	ctx := context.Background()
+
+	if err := approve(ctx, "exec", map[string]any{"command": "make"}); err != nil {
+		t.Errorf("exec with approval off: %v", err)
+	}
+	if err := approve(ctx, "git", map[string]any{"action": "push"}); err == nil {
+		t.Error("unanswered push was allowed by default")
+	}
+	if question, _ := al.bus.SubscribeOutbound(ctx); !strings.HasPrefix(question.Content, "Allow this tool call?\ngit:") {
+		t.Errorf("question = %q", question.Content)
+	}
+}
+
+func TestApproval_Scope(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, withApproval(config.ApprovalConfig{
+		Enabled: true,
+		Rules: []config.ApprovalRule{
+			{Tool: "*", Users: []string{"guest"}, Action: "deny"},
+			{Tool: "exec", Outside: []string{"/srv/projects"}, Action: "deny"},
+		},
+	}))
+	al.RegisterTool(&countingTool{})
+	agent := al.registry.GetDefaultAgent()
+	ctx := context.Background()
+
+	guest := al.approverFor(agent, processOptions{Channel: "telegram", ChatID: "42", User: "guest"})
+	if err := guest(ctx, "read_file", map[string]any{"path": "a"}); err == nil {
+		t.Error("a guest's call was allowed")
+	}
+	owner := al.approverFor(agent, processOptions{Channel: "telegram", ChatID: "42", User: "owner"})
+	if err := owner(ctx, "read_file", map[string]any{"path": "a"}); err != nil {
+		t.Errorf("the owner's call: %v", err)
+	}
+	if err := owner(ctx, "exec", map[string]any{"command": "make", "working_dir": "/srv/projects/app"}); err != nil {
+		t.Errorf("exec in a project: %v", err)
+	}
+	if err := owner(ctx, "exec", map[string]any{"command": "make"}); err == nil {
+		t.Error("exec in the workspace, outside the projects, was allowed")
+	}
+}
+
+func TestApproval_CLIPrompt(t *testing.T) {
+	al, _ := newTestLoop(t, &loopingMockProvider{perTurn: 1}, withApproval(config.ApprovalConfig{
+		Enabled: true,
+		Rules:   []config.ApprovalRule{{Tool: "counting", Action: "ask"}},
+	}))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+	var questions []string
+	al.SetApprovalPrompt(func(_ context.Context, question string) (string, error) {
+		questions = append(questions, question)
+		if len(questions) == 1 {
+			return "y", nil
+		}
+		return "", nil
+	})
+
+	if _, err := al.ProcessDirect(context.Background(), "count twice", "s"); err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) != 2 || questions[0] != "Allow this tool call?\ncounting: {}" {
+		t.Errorf("questions = %q", questions)
+	}
+	// An empty answer is a no.
+	if runs := tool.runs.Load(); runs != 1 {
+		t.Errorf("tool ran %d times, want 1", runs)
+	}
+}
+
+func TestParseApproval(t *testing.T) {
+	tests := []struct {
+		content      string
+		approved, ok bool
+	}{
+		{"yes", true, true},
+		{" Approve ", true, true},
+		{"OK!", true, true},
+		{"Deny", false, true},
+		{"n", false, true},
+		{"what does it do?", false, false},
+	}
+	for _, tt := range tests {
+		if approved, ok := parseApproval(tt.content); approved != tt.approved || ok != tt.ok {
+			t.Errorf("parseApproval(%q) = %v, %v", tt.content, approved, ok)
+		}
+	}
+}
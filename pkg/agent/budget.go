@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// defaultContextWindow is used for models missing from knownContextWindows.
+// It is deliberately conservative: overestimating the window turns into
+// context-length errors, underestimating only compacts history earlier.
+const defaultContextWindow = 32768
+
+// knownContextWindows maps model ID prefixes to context sizes in tokens.
+// The longest matching prefix wins.
+var knownContextWindows = map[string]int{
+	"gpt-4o":   128000,
+	"gpt-4.1":  1047576,
+	"gpt-5":    400000,
+	"o1":       200000,
+	"o3":       200000,
+	"o4-mini":  200000,
+	"claude":   200000,
+	"gemini":   1048576,
+	"deepseek": 128000,
+	"qwen":     131072,
+	"glm":      128000,
+	"kimi":     131072,
+	"moonshot": 131072,
+	"grok":     131072,
+	"llama-3":  131072,
+	"mistral":  32768,
+	"gpt-oss":  131072,
+	"minimax":  1000000,
+}
+
+// tokenizer estimates token counts without a model vocabulary. Text in
+// alphabetic scripts averages charsPerToken characters per token; ideographic
+// and other wide characters are close to one token each.
+type tokenizer struct {
+	charsPerToken float64
+}
+
+// messageOverhead approximates the per-message framing tokens (role markers,
+// separators) that chat templates add.
+const messageOverhead = 4
+
+// tokenizerFor picks the heuristic for a model family. Claude's tokenizer
+// splits English slightly finer than OpenAI's and Gemini's.
+func tokenizerFor(modelID string) tokenizer {
+	id := strings.ToLower(modelID)
+	if strings.Contains(id, "claude") {
+		return tokenizer{charsPerToken: 3.5}
+	}
+	return tokenizer{charsPerToken: 4}
+}
+
+// Count estimates the tokens in s.
+func (t tokenizer) Count(s string) int {
+	narrow, wide := 0, 0
+	for _, r := range s {
+		if r >= 0x2E80 && (unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)) {
+			wide++
+		} else if r < utf8.RuneSelf {
+			narrow++
+		} else {
+			// Other non-ASCII letters (accents, Cyrillic, emoji) split into
+			// more tokens than ASCII.
+			narrow += 2
+		}
+	}
+	return wide + int(float64(narrow)/t.charsPerToken+0.5)
+}
+
+// CountMessages estimates the prompt tokens of a message list.
+func (t tokenizer) CountMessages(messages []providers.Message) int {
+	total := 0
+	for _, m := range messages {
+		total += messageOverhead + t.Count(m.Content)
+		for _, tc := range m.ToolCalls {
+			total += messageOverhead + t.Count(tc.Name)
+			if tc.Function != nil {
+				total += t.Count(tc.Function.Name) + t.Count(tc.Function.Arguments)
+			}
+		}
+	}
+	return total
+}
+
+// CountTools estimates the tokens spent on tool definitions.
+func (t tokenizer) CountTools(tools []providers.ToolDefinition) int {
+	total := 0
+	for _, tool := range tools {
+		total += messageOverhead + t.Count(tool.Function.Name) + t.Count(tool.Function.Description)
+		params, _ := json.Marshal(tool.Function.Parameters)
+		total += t.Count(string(params))
+	}
+	return total
+}
+
+// resolveContextWindow determines the context size of an agent's model:
+// agents.defaults.context_window, then the model_list entry's
+// context_window, then the built-in table. It also returns the model ID
+// (without protocol) used to pick the tokenizer.
+func resolveContextWindow(cfg *config.Config, defaults *config.AgentDefaults, model string) (int, string) {
+	modelID := model
+	window := 0
+	if cfg != nil {
+		for i := range cfg.ModelList {
+			mc := &cfg.ModelList[i]
+			if mc.ModelName == model {
+				_, modelID = providers.ExtractProtocol(mc.Model)
+				window = mc.ContextWindow
+				break
+			}
+		}
+	}
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+
+	if defaults != nil && defaults.ContextWindow > 0 {
+		return defaults.ContextWindow, modelID
+	}
+	if window > 0 {
+		return window, modelID
+	}
+	return lookupContextWindow(modelID), modelID
+}
+
+func lookupContextWindow(modelID string) int {
+	id := strings.ToLower(modelID)
+	best, window := "", defaultContextWindow
+	for prefix, size := range knownContextWindows {
+		if len(prefix) > len(best) && strings.HasPrefix(id, prefix) {
+			best, window = prefix, size
+		}
+	}
+	return window
+}
+
+// compactionToolResultChars caps how much of each tool result is fed to the
+// summarizer; the gist of large outputs survives, the bulk doesn't.
+const compactionToolResultChars = 2000
+
+// promptBudget is the number of prompt tokens available to an agent: the
+// context window minus the room reserved for the reply.
+func promptBudget(agent *AgentInstance) int {
+	budget := agent.ContextWindow - agent.MaxTokens
+	if budget < agent.ContextWindow/4 {
+		// max_tokens is close to (or above) the window; it is a cap, not
+		// what replies actually use.
+		budget = agent.ContextWindow / 2
+	}
+	return budget
+}
+
+// fitsContext reports whether a request stays within 90% of the prompt
+// budget, leaving headroom for estimation error.
+func fitsContext(agent *AgentInstance, messages []providers.Message, tools []providers.ToolDefinition) bool {
+	used := agent.Tokenizer.CountMessages(messages) + agent.Tokenizer.CountTools(tools)
+	return used <= promptBudget(agent)*9/10
+}
+
+// compactionCut returns the index of the first history message to keep
+// verbatim: the earliest user turn after which at most keepTokens remain. The
+// latest user turn is always kept. Zero means nothing can be compacted.
+func compactionCut(t tokenizer, history []providers.Message, keepTokens int) int {
+	lastUser := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			lastUser = i
+			break
+		}
+	}
+	if lastUser <= 0 {
+		return 0
+	}
+
+	for i := 1; i < lastUser; i++ {
+		if history[i].Role == "user" && t.CountMessages(history[i:]) <= keepTokens {
+			return i
+		}
+	}
+	return lastUser
+}
+
+// compactionBatches renders messages for the summarizer and splits them into
+// batches of at most maxTokens each. Tool calls and results are folded into
+// text, since the summarizer is called without tools.
+func compactionBatches(t tokenizer, messages []providers.Message, maxTokens int) [][]providers.Message {
+	var batches [][]providers.Message
+	var batch []providers.Message
+	batchTokens := 0
+
+	for _, m := range messages {
+		content := m.Content
+		switch {
+		case m.Role == "tool":
+			content = "[tool result] " + utils.Truncate(content, compactionToolResultChars)
+		case len(m.ToolCalls) > 0:
+			names := make([]string, 0, len(m.ToolCalls))
+			for _, tc := range m.ToolCalls {
+				name := tc.Name
+				if name == "" && tc.Function != nil {
+					name = tc.Function.Name
+				}
+				names = append(names, name)
+			}
+			content = strings.TrimSpace(content + "\n[called tools: " + strings.Join(names, ", ") + "]")
+		}
+		if content == "" {
+			continue
+		}
+
+		msg := providers.Message{Role: m.Role, Content: content}
+		tokens := t.CountMessages([]providers.Message{msg})
+		if len(batch) > 0 && batchTokens+tokens > maxTokens {
+			batches = append(batches, batch)
+			batch, batchTokens = nil, 0
+		}
+		batch = append(batch, msg)
+		batchTokens += tokens
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestTokenizer_Count(t *testing.T) {
+	tok := tokenizer{charsPerToken: 4}
+
+	if got := tok.Count(strings.Repeat("a", 400)); got != 100 {
+		t.Errorf("Count(400 ASCII chars) = %d, want 100", got)
+	}
+	if got := tok.Count("你好世界"); got != 4 {
+		t.Errorf("Count(4 Han chars) = %d, want 4", got)
+	}
+	if got := tokenizerFor("claude-sonnet-4.6").Count(strings.Repeat("a", 350)); got != 100 {
+		t.Errorf("claude Count(350 chars) = %d, want 100", got)
+	}
+}
+
+func TestTokenizer_CountMessagesIncludesToolCalls(t *testing.T) {
+	tok := tokenizer{charsPerToken: 4}
+	plain := tok.CountMessages([]providers.Message{{Role: "assistant"}})
+	withCall := tok.CountMessages([]providers.Message{{
+		Role: "assistant",
+		ToolCalls: []providers.ToolCall{{
+			Name:     "read_file",
+			Function: &providers.FunctionCall{Name: "read_file", Arguments: `{"path":"` + strings.Repeat("x", 400) + `"}`},
+		}},
+	}})
+	if withCall-plain < 100 {
+		t.Errorf("tool call counted as %d tokens, want >= 100", withCall-plain)
+	}
+}
+
+func TestResolveContextWindow(t *testing.T) {
+	cfg := &config.Config{
+		ModelList: []config.ModelConfig{
+			{ModelName: "sonnet", Model: "anthropic/claude-sonnet-4.6"},
+			{ModelName: "local", Model: "openai/my-finetune", ContextWindow: 16384},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		defaults   config.AgentDefaults
+		model      string
+		wantWindow int
+		wantID     string
+	}{
+		{"table via model_list", config.AgentDefaults{}, "sonnet", 200000, "claude-sonnet-4.6"},
+		{"model_list override", config.AgentDefaults{}, "local", 16384, "my-finetune"},
+		{"defaults override", config.AgentDefaults{ContextWindow: 50000}, "sonnet", 50000, "claude-sonnet-4.6"},
+		{"unknown model", config.AgentDefaults{}, "ollama/tinyllama", defaultContextWindow, "tinyllama"},
+		{"longest prefix", config.AgentDefaults{}, "gpt-4o-mini", 128000, "gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, id := resolveContextWindow(cfg, &tt.defaults, tt.model)
+			if window != tt.wantWindow || id != tt.wantID {
+				t.Errorf("resolveContextWindow(%q) = %d, %q; want %d, %q", tt.model, window, id, tt.wantWindow, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCompactionCut(t *testing.T) {
+	tok := tokenizer{charsPerToken: 4}
+	long := strings.Repeat("a", 400) // ~100 tokens
+	history := []providers.Message{
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Name: "exec"}}},
+		{Role: "tool", Content: long, ToolCallID: "1"},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "latest"},
+	}
+
+	if got := compactionCut(tok, history, 1000); got != 2 {
+		t.Errorf("compactionCut(generous) = %d, want 2 (first user turn that fits)", got)
+	}
+	if got := compactionCut(tok, history, 10); got != 6 {
+		t.Errorf("compactionCut(tight) = %d, want 6 (latest user turn)", got)
+	}
+	if got := compactionCut(tok, history[:1], 10); got != 0 {
+		t.Errorf("compactionCut(single turn) = %d, want 0", got)
+	}
+}
+
+func TestCompactionBatches(t *testing.T) {
+	tok := tokenizer{charsPerToken: 4}
+	messages := []providers.Message{
+		{Role: "user", Content: strings.Repeat("a", 400)},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Function: &providers.FunctionCall{Name: "exec"}}}},
+		{Role: "tool", Content: strings.Repeat("b", 10000), ToolCallID: "1"},
+		{Role: "assistant", Content: strings.Repeat("c", 400)},
+	}
+
+	batches := compactionBatches(tok, messages, 300)
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3: %+v", len(batches), batches)
+	}
+	if !strings.Contains(batches[0][1].Content, "[called tools: exec]") {
+		t.Errorf("tool call rendered as %q", batches[0][1].Content)
+	}
+	if got := batches[1][0].Content; !strings.HasPrefix(got, "[tool result] ") || len(got) > compactionToolResultChars+50 {
+		t.Errorf("tool result not truncated: %d chars", len(got))
+	}
+}
+
+// compactingMockProvider answers summarization prompts with a fixed summary
+// and records the other requests.
+type compactingMockProvider struct {
+	mu             sync.Mutex
+	summaryCalls   int
+	requests       [][]providers.Message
+	summaryContent string
+}
+
+func (m *compactingMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(messages) == 1 && strings.HasPrefix(messages[0].Content, "Provide a concise summary") {
+		m.summaryCalls++
+		return &providers.LLMResponse{Content: m.summaryContent}, nil
+	}
+	m.requests = append(m.requests, messages)
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (m *compactingMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_CompactsHistoryBeforeOverflow(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         1024,
+				MaxToolIterations: 10,
+				ContextWindow:     20000,
+			},
+		},
+	}
+	provider := &compactingMockProvider{summaryContent: "User discussed forty topics."}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	agent := al.registry.GetDefaultAgent()
+
+	// ~40 messages of ~500 tokens each: well over the window.
+	sessionKey := "agent:main:compact"
+	var history []providers.Message
+	for i := 0; i < 20; i++ {
+		history = append(history,
+			providers.Message{Role: "user", Content: strings.Repeat("question ", 220)},
+			providers.Message{Role: "assistant", Content: strings.Repeat("answer ", 285)},
+		)
+	}
+	agent.Sessions.GetOrCreate(sessionKey)
+	agent.Sessions.SetHistory(sessionKey, history)
+
+	response, err := al.ProcessDirect(context.Background(), "latest question", sessionKey)
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "done" {
+		t.Errorf("response = %q, want %q", response, "done")
+	}
+
+	if provider.summaryCalls == 0 {
+		t.Fatal("expected history to be summarized")
+	}
+	if len(provider.requests) == 0 {
+		t.Fatal("no chat request recorded")
+	}
+	sent := provider.requests[0]
+	if !fitsContext(agent, sent, agent.Tools.ToProviderDefs()) {
+		t.Errorf("request of %d tokens does not fit the budget", agent.Tokenizer.CountMessages(sent))
+	}
+	if !strings.Contains(sent[0].Content, "User discussed forty topics.") {
+		t.Error("summary missing from system prompt")
+	}
+	if last := sent[len(sent)-1]; last.Role != "user" || last.Content != "latest question" {
+		t.Errorf("last message = %+v, want the latest question", last)
+	}
+	if got := agent.Sessions.GetSummary(sessionKey); got != "User discussed forty topics." {
+		t.Errorf("session summary = %q", got)
+	}
+}
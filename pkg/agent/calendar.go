@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/calendar"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// newCalendarTool returns the calendar tool on the configured calendar.
+func newCalendarTool(cfg config.CalendarToolConfig) (*tools.CalendarTool, error) {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", cfg.Timezone)
+	}
+	if cfg.Timezone == "" {
+		loc = time.Local
+	}
+
+	var cal calendar.Calendar
+	switch cfg.Provider {
+	case "caldav":
+		cal, err = calendar.NewCalDAV(cfg.CalDAV.URL, cfg.CalDAV.Username, cfg.CalDAV.Password, loc, nil)
+		if err != nil {
+			return nil, err
+		}
+	case "google":
+		auth := calendar.NewGoogleAuth(cfg.Google.ClientID, cfg.Google.ClientSecret,
+			calendar.DefaultGoogleTokenPath(), nil)
+		cal = calendar.NewGoogle(cfg.Google.CalendarID, auth, loc, nil)
+	default:
+		return nil, fmt.Errorf("unknown calendar provider %q", cfg.Provider)
+	}
+	return tools.NewCalendarTool(cal, loc), nil
+}
@@ -0,0 +1,282 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultMaxFacts = 500
+
+	// consolidationBatch is how many facts one consolidation call sees.
+	// Batches group similar facts, so duplicates end up in the same one.
+	consolidationBatch = 60
+
+	// consolidationCheck is how often the schedule is checked. The time of
+	// the last consolidation is stored with the facts, so restarts don't
+	// postpone it.
+	consolidationCheck = time.Hour
+	consolidationDelay = 5 * time.Minute
+
+	consolidationTimeout = 10 * time.Minute
+)
+
+var consolidationSchema = providers.ResponseSchema{
+	Name:        "consolidated_facts",
+	Description: "The facts to keep, each with the numbers of the facts it was made from.",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"facts": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"text": map[string]any{"type": "string"},
+						"from": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+					},
+					"required": []any{"text", "from"},
+				},
+			},
+		},
+		"required": []any{"facts"},
+	},
+}
+
+const consolidationPrompt = `You tidy up an assistant's long-term memory about its user. Below are remembered facts, each with its number and the date it was learned. Return the facts to keep:
+- Merge facts that say the same thing or belong together into one short sentence, listing the numbers of all facts it replaces in "from".
+- When facts contradict each other, keep the newer one.
+- Leave out facts that are outdated: plans and events in the past, temporary states, anything superseded.
+- Keep every other fact as it is, with its own number in "from".
+Never invent facts.`
+
+// consolidationInterval returns how often the schedule consolidates memory,
+// or 0 when it never does.
+func consolidationInterval(schedule string) time.Duration {
+	switch schedule {
+	case "", "daily":
+		return 24 * time.Hour
+	case "weekly":
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// consolidateMemoryPeriodically consolidates the agents' memories on the
+// configured schedule until ctx is done.
+func (al *AgentLoop) consolidateMemoryPeriodically(ctx context.Context) {
+	timer := time.NewTimer(consolidationDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		al.consolidateDueMemories(ctx)
+		timer.Reset(consolidationCheck)
+	}
+}
+
+func (al *AgentLoop) consolidateDueMemories(ctx context.Context) {
+	interval := consolidationInterval(al.config().Agents.Defaults.Memory.Consolidation)
+	if interval == 0 {
+		return
+	}
+	done := make(map[*memory.Store]bool)
+	for _, id := range al.registry.ListAgentIDs() {
+		agent, ok := al.registry.GetAgent(id)
+		if !ok || agent.Facts == nil {
+			continue
+		}
+		stores := map[string]*memory.Store{"": agent.Facts}
+		for name := range al.config().Users {
+			if facts := factsFor(agent, name); facts != nil && facts.Len() > 0 {
+				stores[name] = facts
+			}
+		}
+		for user, facts := range stores {
+			if done[facts] || time.Since(facts.LastConsolidated()) < interval {
+				continue
+			}
+			done[facts] = true
+			runCtx, cancel := context.WithTimeout(ctx, consolidationTimeout)
+			summary, err := al.consolidateMemory(runCtx, agent, facts)
+			cancel()
+			if err != nil {
+				logger.WarnCF("agent", "Memory consolidation failed",
+					map[string]any{"agent_id": agent.ID, "user": user, "error": err.Error()})
+				continue
+			}
+			logger.InfoCF("agent", "Consolidated long-term memory",
+				map[string]any{"agent_id": agent.ID, "user": user, "result": summary})
+		}
+	}
+}
+
+// consolidateMemory merges related facts in facts, one of agent's stores,
+// drops outdated ones and prunes the oldest beyond the configured maximum,
+// returning a summary for the user.
+func (al *AgentLoop) consolidateMemory(ctx context.Context, agent *AgentInstance, facts *memory.Store) (string, error) {
+	embedder, err := al.modelRoles().Embeddings()
+	if err != nil {
+		return "", err
+	}
+	before := facts.Len()
+
+	for _, batch := range similarityBatches(facts.List(), consolidationBatch) {
+		if len(batch) < 2 {
+			continue
+		}
+		if err := al.consolidateBatch(ctx, agent, facts, embedder, batch); err != nil {
+			return "", err
+		}
+	}
+
+	maxFacts := al.config().Agents.Defaults.Memory.MaxFacts
+	if maxFacts <= 0 {
+		maxFacts = defaultMaxFacts
+	}
+	pruned, err := facts.Prune(maxFacts)
+	if err != nil {
+		return "", err
+	}
+	after := facts.Len()
+	if after < before {
+		if err := facts.Compact(); err != nil {
+			logger.WarnCF("agent", "Can't compact memory database", map[string]any{"error": err.Error()})
+		}
+	}
+	if err := facts.SetLastConsolidated(time.Now()); err != nil {
+		return "", err
+	}
+
+	summary := fmt.Sprintf("%d facts are now %d.", before, after)
+	if pruned > 0 {
+		summary += fmt.Sprintf(" The %d oldest were dropped to stay within %d.", pruned, maxFacts)
+	}
+	return summary, nil
+}
+
+func (al *AgentLoop) consolidateBatch(
+	ctx context.Context,
+	agent *AgentInstance,
+	facts *memory.Store,
+	embedder providers.EmbeddingsProvider,
+	batch []memory.Fact,
+) error {
+	byID := make(map[int64]memory.Fact, len(batch))
+	var list strings.Builder
+	for _, f := range batch {
+		byID[f.ID] = f
+		fmt.Fprintf(&list, "#%d (%s) %s\n", f.ID, f.Created.Format(time.DateOnly), f.Text)
+	}
+
+	provider, model := al.modelFor(agent, providers.RoleCheap)
+	var out struct {
+		Facts []struct {
+			Text string  `json:"text"`
+			From []int64 `json:"from"`
+		} `json:"facts"`
+	}
+	err := providers.ChatJSON(ctx, provider, []providers.Message{
+		{Role: "system", Content: consolidationPrompt},
+		{Role: "user", Content: "Today is " + time.Now().Format(time.DateOnly) + ".\n\nFACTS:\n" + list.String()},
+	}, model, consolidationSchema, map[string]any{"max_tokens": 4096, "temperature": 0.2}, &out)
+	if err != nil {
+		return err
+	}
+	// An empty answer is far more likely a failure than a verdict that
+	// everything is outdated.
+	if len(out.Facts) == 0 {
+		return nil
+	}
+
+	type merge struct {
+		text string
+		from []int64
+	}
+	var merges []merge
+	kept := make(map[int64]bool)
+	for _, f := range out.Facts {
+		text := strings.TrimSpace(f.Text)
+		var from []int64
+		for _, id := range f.From {
+			if _, ok := byID[id]; ok && !kept[id] {
+				kept[id] = true
+				from = append(from, id)
+			}
+		}
+		// Facts with no sources would be invented.
+		if text == "" || len(from) == 0 {
+			continue
+		}
+		if len(from) == 1 && byID[from[0]].Text == text {
+			continue
+		}
+		merges = append(merges, merge{text: text, from: from})
+	}
+
+	if len(merges) > 0 {
+		texts := make([]string, len(merges))
+		for i, m := range merges {
+			texts[i] = m.text
+		}
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for i, m := range merges {
+			if i >= len(vectors) {
+				break
+			}
+			if err := facts.Merge(m.from, m.text, vectors[i], byID[m.from[0]].Source); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range batch {
+		if !kept[f.ID] {
+			if _, err := facts.Forget(f.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// similarityBatches splits facts into batches of up to size, each seeded
+// with a fact not batched yet and filled with the facts most similar to it.
+func similarityBatches(facts []memory.Fact, size int) [][]memory.Fact {
+	var batches [][]memory.Fact
+	for len(facts) > 0 {
+		seed := facts[0]
+		rest := slices.Clone(facts[1:])
+		slices.SortStableFunc(rest, func(a, b memory.Fact) int {
+			sa, sb := memory.Similarity(seed, a), memory.Similarity(seed, b)
+			switch {
+			case sa > sb:
+				return -1
+			case sa < sb:
+				return 1
+			}
+			return 0
+		})
+		n := min(size-1, len(rest))
+		batches = append(batches, append([]memory.Fact{seed}, rest[:n]...))
+		facts = rest[n:]
+	}
+	return batches
+}
@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/memory"
+)
+
+func TestConsolidateMemory(t *testing.T) {
+	provider := &memoryMockProvider{facts: `{"facts": []}`}
+	al, _ := newTestLoop(t, provider, withMemory(t, true))
+	provider.consolidated = `{"facts": [
+		{"text": "The user has a dog called Rex", "from": [1, 2]},
+		{"text": "The user prefers tea", "from": [4]},
+		{"text": "The user is a pilot", "from": []}
+	]}`
+	agent := al.registry.GetDefaultAgent()
+	agent.Facts.Add("The user has a dog", []float32{1, 0.5, 0}, "s1")
+	agent.Facts.Add("The user's dog is called Rex", []float32{1, 0, 0.5}, "s2")
+	agent.Facts.Add("The user flies to Berlin tomorrow", []float32{0, 1, 0}, "s3")
+	agent.Facts.Add("The user prefers tea", []float32{0, 0, 1}, "s4")
+
+	reply, _ := al.handleMemoryCommand(context.Background(), agent, agent.Facts, "/memory consolidate")
+	if reply != "Consolidated memory: 4 facts are now 2." {
+		t.Errorf("reply = %q", reply)
+	}
+	var texts []string
+	for _, f := range agent.Facts.List() {
+		texts = append(texts, f.Text)
+	}
+	if !slices.Equal(texts, []string{"The user prefers tea", "The user has a dog called Rex"}) {
+		t.Errorf("facts = %q", texts)
+	}
+	if agent.Facts.LastConsolidated().IsZero() {
+		t.Error("consolidation time was not recorded")
+	}
+	// The merged fact was embedded again.
+	if found := agent.Facts.Search([]float32{1, 0, 0}, 1, 0.99); len(found) != 1 || found[0].Source != "s1" {
+		t.Errorf("Search() = %+v", found)
+	}
+}
+
+func TestConsolidateMemory_PrunesToMaxFacts(t *testing.T) {
+	provider := &memoryMockProvider{facts: `{"facts": []}`}
+	al, _ := newTestLoop(t, provider, withMemory(t, true))
+	provider.consolidated = `{"facts": []}`
+	al.config().Agents.Defaults.Memory.MaxFacts = 2
+	agent := al.registry.GetDefaultAgent()
+	agent.Facts.Add("The user has a dog", []float32{1, 0, 0}, "s")
+	agent.Facts.Add("The user lives in Berlin", []float32{0, 1, 0}, "s")
+	agent.Facts.Add("The user prefers tea", []float32{0, 0, 1}, "s")
+
+	summary, err := al.consolidateMemory(context.Background(), agent, agent.Facts)
+	if err != nil {
+		t.Fatalf("consolidateMemory() error = %v", err)
+	}
+	if summary != "3 facts are now 2. The 1 oldest were dropped to stay within 2." {
+		t.Errorf("summary = %q", summary)
+	}
+	// An empty answer from the model drops nothing by itself.
+	if provider.consolidations != 1 || agent.Facts.Len() != 2 {
+		t.Errorf("consolidations = %d, facts = %d", provider.consolidations, agent.Facts.Len())
+	}
+}
+
+func TestConsolidateDueMemories(t *testing.T) {
+	provider := &memoryMockProvider{facts: `{"facts": []}`}
+	al, _ := newTestLoop(t, provider, withMemory(t, true))
+	provider.consolidated = `{"facts": [{"text": "The user has a dog", "from": [1]}]}`
+	agent := al.registry.GetDefaultAgent()
+	agent.Facts.Add("The user has a dog", []float32{1, 0, 0}, "s")
+	agent.Facts.Add("The user prefers tea", []float32{0, 0, 1}, "s")
+
+	al.consolidateDueMemories(context.Background())
+	al.consolidateDueMemories(context.Background())
+	if provider.consolidations != 1 {
+		t.Errorf("consolidations = %d, want 1 per day", provider.consolidations)
+	}
+	if agent.Facts.Len() != 1 {
+		t.Errorf("facts = %+v", agent.Facts.List())
+	}
+
+	al.config().Agents.Defaults.Memory.Consolidation = "off"
+	agent.Facts.SetLastConsolidated(agent.Facts.LastConsolidated().AddDate(0, 0, -30))
+	al.consolidateDueMemories(context.Background())
+	if provider.consolidations != 1 {
+		t.Error("consolidation ran while off")
+	}
+}
+
+func TestSimilarityBatches(t *testing.T) {
+	store, err := memory.Open(filepath.Join(t.TempDir(), "facts.db"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Add("a", []float32{1, 0, 0}, "s")
+	store.Add("b", []float32{0, 1, 0}, "s")
+	store.Add("a and c", []float32{1, 0, 1}, "s")
+	store.Add("b and c", []float32{0, 1, 1}, "s")
+
+	var got [][]string
+	for _, batch := range similarityBatches(store.List(), 2) {
+		var texts []string
+		for _, f := range batch {
+			texts = append(texts, f.Text)
+		}
+		got = append(got, texts)
+	}
+	if len(got) != 2 || !slices.Equal(got[0], []string{"a", "a and c"}) || !slices.Equal(got[1], []string{"b", "b and c"}) {
+		t.Errorf("batches = %q", got)
+	}
+}
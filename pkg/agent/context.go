@@ -7,19 +7,29 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/encrypt"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 type ContextBuilder struct {
 	workspace    string
 	skillsLoader *skills.SkillsLoader
 	memory       *MemoryStore
+	persona      string              // See SetPersona
+	tools        *tools.ToolRegistry // See SetTools
+	skills       []string            // See SetSkills
+
+	// System prompt templates (SYSTEM.md), by path
+	templatesMu sync.Mutex
+	templates   map[string]parsedTemplate
 
 	// Cache for system prompt to avoid rebuilding on every call.
 	// This fixes issue #607: repeated reprocessing of the entire context.
@@ -57,6 +67,28 @@ func NewContextBuilder(workspace string) *ContextBuilder {
 	}
 }
 
+// SetStorageKey makes the memory files encrypted with key.
+func (cb *ContextBuilder) SetStorageKey(key *encrypt.Key) {
+	cb.memory.key = key
+}
+
+// SetPersona adds an agent's own instructions to the system prompt, after
+// the identity section. Call it before the builder is used.
+func (cb *ContextBuilder) SetPersona(persona string) {
+	cb.persona = strings.TrimSpace(persona)
+}
+
+// SetTools gives system prompt templates the agent's tools, as .Tools.
+func (cb *ContextBuilder) SetTools(registry *tools.ToolRegistry) {
+	cb.tools = registry
+}
+
+// SetSkills gives system prompt templates the agent's skills, whose
+// documentation is .SkillDocs.
+func (cb *ContextBuilder) SetSkills(names []string) {
+	cb.skills = names
+}
+
 func (cb *ContextBuilder) getIdentity() string {
 	workspacePath, _ := filepath.Abs(filepath.Join(cb.workspace))
 
@@ -88,6 +120,10 @@ func (cb *ContextBuilder) BuildSystemPrompt() string {
 	// Core identity section
 	parts = append(parts, cb.getIdentity())
 
+	if cb.persona != "" {
+		parts = append(parts, "# Persona\n\n"+cb.persona)
+	}
+
 	// Bootstrap files
 	bootstrapContent := cb.LoadBootstrapFiles()
 	if bootstrapContent != "" {
@@ -398,6 +434,11 @@ func (cb *ContextBuilder) BuildMessages(
 	// Build short dynamic context (time, runtime, session) — changes per request
 	dynamicCtx := cb.buildDynamicContext(channel, chatID)
 
+	// A SYSTEM.md template in the workspace replaces both.
+	if rendered, ok := cb.renderSystemTemplate(staticPrompt, channel, chatID); ok {
+		staticPrompt, dynamicCtx = rendered, ""
+	}
+
 	// Compose a single system message: static (cached) + dynamic + optional summary.
 	// Keeping all system content in one message ensures every provider adapter can
 	// extract it correctly (Anthropic adapter -> top-level system param,
@@ -407,11 +448,14 @@ func (cb *ContextBuilder) BuildMessages(
 	// cache-aware adapters (Anthropic) can set per-block cache_control.
 	// The static block is marked "ephemeral" — its prefix hash is stable
 	// across requests, enabling LLM-side KV cache reuse.
-	stringParts := []string{staticPrompt, dynamicCtx}
+	stringParts := []string{staticPrompt}
 
 	contentBlocks := []providers.ContentBlock{
 		{Type: "text", Text: staticPrompt, CacheControl: &providers.CacheControl{Type: "ephemeral"}},
-		{Type: "text", Text: dynamicCtx},
+	}
+	if dynamicCtx != "" {
+		stringParts = append(stringParts, dynamicCtx)
+		contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: dynamicCtx})
 	}
 
 	if summary != "" {
@@ -538,7 +582,40 @@ func sanitizeHistoryForProvider(history []providers.Message) []providers.Message
 		}
 	}
 
-	return sanitized
+	return closeInterruptedToolCalls(sanitized)
+}
+
+// interruptedToolResult stands in for tool results lost when a turn was cut
+// short, e.g. by a restart while tools ran.
+const interruptedToolResult = "Interrupted: this tool call did not finish."
+
+// closeInterruptedToolCalls adds a result for every tool call that has none,
+// as providers reject tool calls without results.
+func closeInterruptedToolCalls(history []providers.Message) []providers.Message {
+	var out []providers.Message
+	var pending []string
+	flush := func() {
+		for _, id := range pending {
+			logger.DebugCF("agent", "Closing interrupted tool call", map[string]any{"tool_call_id": id})
+			out = append(out, providers.Message{Role: "tool", Content: interruptedToolResult, ToolCallID: id})
+		}
+		pending = nil
+	}
+	for _, msg := range history {
+		if msg.Role == "tool" {
+			pending = slices.DeleteFunc(pending, func(id string) bool { return id == msg.ToolCallID })
+		} else {
+			flush()
+		}
+		out = append(out, msg)
+		if msg.Role == "assistant" {
+			for _, tc := range msg.ToolCalls {
+				pending = append(pending, tc.ID)
+			}
+		}
+	}
+	flush()
+	return out
 }
 
 func (cb *ContextBuilder) AddToolResult(
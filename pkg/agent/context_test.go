@@ -188,6 +188,26 @@ func TestSanitizeHistoryForProvider_PlainConversation(t *testing.T) {
 	assertRoles(t, result, "user", "assistant", "user", "assistant")
 }
 
+func TestSanitizeHistoryForProvider_InterruptedToolCalls(t *testing.T) {
+	// A restart while tool B ran, then the user wrote again.
+	history := []providers.Message{
+		msg("user", "do two things"),
+		assistantWithTools("A", "B"),
+		toolResult("A"),
+		msg("user", "are you there?"),
+		assistantWithTools("C"),
+	}
+
+	result := sanitizeHistoryForProvider(history)
+	assertRoles(t, result, "user", "assistant", "tool", "tool", "user", "assistant", "tool")
+	if result[3].ToolCallID != "B" || result[3].Content != interruptedToolResult {
+		t.Errorf("missing result for B = %+v", result[3])
+	}
+	if result[6].ToolCallID != "C" {
+		t.Errorf("missing result for C = %+v", result[6])
+	}
+}
+
 func roles(msgs []providers.Message) []string {
 	r := make([]string, len(msgs))
 	for i, m := range msgs {
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// defaultMaxContinuations bounds the follow-up requests for one reply when
+// max_continuations is unset.
+const defaultMaxContinuations = 3
+
+// continuationPrompt asks the model to resume a reply cut off by max_tokens.
+const continuationPrompt = "Your reply was cut off by the output limit. Continue exactly where it " +
+	"stopped, without repeating anything or adding an introduction."
+
+// maxStitchOverlap bounds the repeated text looked for when joining pieces.
+const maxStitchOverlap = 200
+
+// continueTruncated asks for the rest of a reply that stopped at max_tokens,
+// up to limit times, and returns the response with the pieces stitched
+// together. call sends the conversation followed by extra. A failed
+// continuation keeps what arrived so far.
+func continueTruncated(
+	resp *providers.LLMResponse,
+	limit int,
+	call func(extra []providers.Message) (*providers.LLMResponse, error),
+) *providers.LLMResponse {
+	content := resp.Content
+	for n := 0; n < limit && resp.FinishReason == "length" && len(resp.ToolCalls) == 0; n++ {
+		logger.InfoCF("agent", "Reply hit max_tokens, requesting continuation",
+			map[string]any{
+				"continuation":  n + 1,
+				"content_chars": len(content),
+			})
+		next, err := call([]providers.Message{
+			{Role: "assistant", Content: content},
+			{Role: "user", Content: continuationPrompt},
+		})
+		if err != nil {
+			logger.WarnCF("agent", "Continuation failed, keeping the truncated reply",
+				map[string]any{"error": err.Error()})
+			break
+		}
+		content = stitch(content, next.Content)
+		resp = next
+	}
+
+	stitched := *resp
+	stitched.Content = content
+	return &stitched
+}
+
+// stitch joins a continuation to the text before it. Models often restate
+// the last few words before carrying on, so a repeated overlap is dropped.
+func stitch(prev, next string) string {
+	longest := min(len(prev), len(next), maxStitchOverlap)
+	for n := longest; n >= 8; n-- {
+		if strings.HasSuffix(prev, next[:n]) {
+			return prev + next[n:]
+		}
+	}
+	return prev + next
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestStitch(t *testing.T) {
+	tests := []struct {
+		prev, next, want string
+	}{
+		{"func main() {\n\tfmt.Println(", `"hi")` + "\n}", "func main() {\n\tfmt.Println(\"hi\")\n}"},
+		{"The quick brown fox jumps", "brown fox jumps over the dog", "The quick brown fox jumps over the dog"},
+		{"ends with a", "a new start", "ends with aa new start"}, // overlaps under 8 bytes are kept
+		{"", "all new", "all new"},
+	}
+	for _, tt := range tests {
+		if got := stitch(tt.prev, tt.next); got != tt.want {
+			t.Errorf("stitch(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+// truncatingMockProvider returns its pieces one per call, all but the last
+// cut off by max_tokens, and records what it was sent
+type truncatingMockProvider struct {
+	pieces []string
+	calls  [][]providers.Message
+}
+
+func (m *truncatingMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls = append(m.calls, messages)
+	i := len(m.calls) - 1
+	resp := &providers.LLMResponse{Content: m.pieces[i], FinishReason: "length"}
+	if i == len(m.pieces)-1 {
+		resp.FinishReason = "stop"
+	}
+	return resp, nil
+}
+
+func (m *truncatingMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func withMaxContinuations(n int) func(cfg *config.Config) {
+	return func(cfg *config.Config) { cfg.Agents.Defaults.MaxContinuations = n }
+}
+
+func TestProcessDirect_StitchesTruncatedReply(t *testing.T) {
+	provider := &truncatingMockProvider{pieces: []string{"part one, ", "part two, ", "the end."}}
+	al, _ := newTestLoop(t, provider, nil)
+
+	response, err := al.ProcessDirect(context.Background(), "write a lot", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "part one, part two, the end." {
+		t.Errorf("response = %q", response)
+	}
+	if len(provider.calls) != 3 {
+		t.Fatalf("provider called %d times, want 3", len(provider.calls))
+	}
+	last := provider.calls[2]
+	if prev := last[len(last)-2]; prev.Role != "assistant" || prev.Content != "part one, part two, " {
+		t.Errorf("continuation resent %+v, want the reply so far", prev)
+	}
+	if last[len(last)-1].Content != continuationPrompt {
+		t.Errorf("continuation ended with %+v", last[len(last)-1])
+	}
+
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main")
+	if n := len(history); n != 2 || history[1].Content != response {
+		t.Errorf("history = %+v, want the question and the stitched reply", history)
+	}
+}
+
+func TestProcessDirect_ContinuationLimit(t *testing.T) {
+	provider := &truncatingMockProvider{pieces: []string{"a", "b", "c", "d"}}
+	al, _ := newTestLoop(t, provider, withMaxContinuations(1))
+
+	response, err := al.ProcessDirect(context.Background(), "write a lot", "test-session")
+	if err != nil || response != "ab" || len(provider.calls) != 2 {
+		t.Errorf("response = %q, %v after %d calls; want \"ab\" after 2", response, err, len(provider.calls))
+	}
+
+	provider = &truncatingMockProvider{pieces: []string{"a", "b"}}
+	al, _ = newTestLoop(t, provider, withMaxContinuations(-1))
+	response, _ = al.ProcessDirect(context.Background(), "write a lot", "test-session")
+	if response != "a" || len(provider.calls) != 1 {
+		t.Errorf("disabled: response = %q after %d calls", response, len(provider.calls))
+	}
+}
@@ -0,0 +1,101 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/containers"
+	"github.com/sipeed/picoclaw/pkg/database"
+	"github.com/sipeed/picoclaw/pkg/forge"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerDevTools registers the tools for software work: containers,
+// other machines over ssh, git, databases and running code.
+func registerDevTools(cfg *config.Config, agent *AgentInstance, send tools.SendMediaCallback) {
+	// Docker/Podman containers, limited to those allowed
+	if ct := cfg.Tools.Containers; ct.Enabled && len(ct.Containers) > 0 {
+		client := containers.NewClient(ct.Socket, ct.Containers)
+		agent.Tools.Register(tools.NewContainersTool(client, ct.ReadOnly))
+	}
+
+	// Commands on other machines, over ssh with their command policies
+	if ssh := cfg.Tools.SSH; ssh.Enabled && len(ssh.Hosts) > 0 {
+		agent.Tools.Register(tools.NewSSHTool(ssh))
+	}
+
+	// git in the allowed repositories, with their forges' issues and pull requests
+	if git := cfg.Tools.Git; git.Enabled {
+		var forges []*forge.Client
+		for _, f := range git.Forges {
+			client, err := forge.NewClient(f.Type, f.URL, f.Token)
+			if err != nil {
+				logger.WarnCF("agent", "Git forge not available", map[string]any{"error": err.Error()})
+				continue
+			}
+			forges = append(forges, client)
+		}
+		agent.Tools.Register(tools.NewGitTool(tools.GitToolOptions{
+			Repos:       git.Repos,
+			Workspace:   agent.Workspace,
+			AuthorName:  git.AuthorName,
+			AuthorEmail: git.AuthorEmail,
+			AllowPush:   git.AllowPush,
+			Timeout:     time.Duration(git.TimeoutSeconds) * time.Second,
+			Forges:      forges,
+		}))
+	}
+
+	// The user's databases, read-only unless writes are allowed
+	if dbc := cfg.Tools.DB; dbc.Enabled {
+		var dbs []*database.DB
+		for _, d := range dbc.Databases {
+			db, err := database.Open(database.Options{
+				Name:        d.Name,
+				Type:        d.Type,
+				Path:        expandHome(d.Path),
+				URL:         d.URL,
+				Password:    d.Password,
+				AllowWrites: d.AllowWrites,
+			})
+			if err != nil {
+				logger.WarnCF("agent", "Database not available", map[string]any{"error": err.Error()})
+				continue
+			}
+			dbs = append(dbs, db)
+		}
+		if len(dbs) > 0 {
+			agent.Tools.Register(tools.NewDBTool(dbs, tools.DBToolOptions{
+				MaxRows:   dbc.MaxRows,
+				MaxOutput: dbc.MaxOutputChars,
+				Timeout:   time.Duration(dbc.TimeoutSeconds) * time.Second,
+			}))
+		}
+	}
+
+	// Python and JavaScript snippets, in the exec sandbox if one is set
+	if code := cfg.Tools.Code; code.Enabled {
+		sandbox := cfg.Tools.Exec.Sandbox
+		if code.Image != "" {
+			sandbox.Image = code.Image
+		}
+		sandbox.MemoryMB = code.MemoryMB
+		codeTool := tools.NewRunCodeTool(agent.Workspace, tools.RunCodeToolOptions{
+			Python:      code.Python,
+			Node:        code.Node,
+			Sandbox:     sandbox,
+			Timeout:     time.Duration(code.TimeoutSeconds) * time.Second,
+			CPUSeconds:  code.CPUSeconds,
+			MemoryMB:    code.MemoryMB,
+			MaxFileSize: int64(code.MaxFileMB) << 20,
+		})
+		codeTool.SetSendCallback(send)
+		agent.Tools.Register(codeTool)
+	}
+}
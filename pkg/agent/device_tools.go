@@ -0,0 +1,99 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/camera"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/desktop"
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerDeviceTools registers the tools on the hardware picoclaw runs on
+// and the devices around it: buses and pins, the camera, the desktop, MQTT
+// and Home Assistant.
+func registerDeviceTools(
+	cfg *config.Config,
+	agent *AgentInstance,
+	roles *providers.ModelRoles,
+	send tools.SendMediaCallback,
+) {
+	// Hardware tools (I2C, SPI, GPIO, serial) - Linux only, returns error on other platforms.
+	// I2C devices, GPIO pins and serial ports are limited to those the user allowed.
+	hw := cfg.Tools.Hardware
+	agent.Tools.Register(tools.NewI2CTool(hw.I2CDevices))
+	agent.Tools.Register(tools.NewSPITool())
+	if len(hw.GPIOInputs) > 0 || len(hw.GPIOOutputs) > 0 {
+		agent.Tools.Register(tools.NewGPIOTool(hw.GPIOInputs, hw.GPIOOutputs))
+	}
+	if len(hw.SerialPorts) > 0 {
+		agent.Tools.Register(tools.NewSerialTool(hw.SerialPorts, hw.SerialBaud))
+	}
+
+	if sys := cfg.Tools.SysInfo; sys.Enabled {
+		agent.Tools.Register(tools.NewSysInfoTool(sys.Disks))
+	}
+
+	// Camera, looked through by the vision model
+	if cam := cfg.Tools.Camera; cam.Enabled {
+		agentProvider, agentModel := agent.Provider, agent.Model
+		cameraTool := tools.NewCapturePhotoTool(tools.CapturePhotoToolOptions{
+			Camera: camera.Options{
+				Device:  cam.Device,
+				Width:   cam.Width,
+				Height:  cam.Height,
+				Command: cam.Command,
+			},
+			Workspace: agent.Workspace,
+			Vision: func() (providers.LLMProvider, string) {
+				provider, model, err := roles.Provider(providers.RoleVision)
+				if err != nil {
+					logger.WarnCF("agent", "Vision model unavailable, using the agent's model",
+						map[string]any{"error": err.Error()})
+				}
+				if provider == nil {
+					return agentProvider, agentModel
+				}
+				return provider, model
+			},
+		})
+		cameraTool.SetSendCallback(send)
+		agent.Tools.Register(cameraTool)
+	}
+
+	// The clipboard and notifications of the desktop picoclaw runs on, if any
+	if d := cfg.Tools.Desktop; d.Enabled && desktop.Available() {
+		if d.Clipboard {
+			agent.Tools.Register(tools.NewClipboardTool(d.MaxClipboardChars))
+		}
+		if d.Notifications {
+			agent.Tools.Register(tools.NewNotifyTool())
+		}
+	}
+
+	// MQTT devices, on the topics allowed
+	if m := cfg.Tools.MQTT; m.Enabled && m.Broker != "" {
+		opts := mqtt.Options{
+			Broker:   m.Broker,
+			Username: m.Username,
+			Password: m.Password,
+			CAFile:   m.CAFile,
+			CertFile: m.CertFile,
+			KeyFile:  m.KeyFile,
+		}
+		agent.Tools.Register(tools.NewMQTTTool(opts, m.Topics))
+	}
+
+	// Home Assistant, limited to the entities allowed
+	if ha := cfg.Tools.HomeAssistant; ha.Enabled && ha.URL != "" && ha.Token != "" {
+		client := homeassistant.NewClient(ha.URL, ha.Token, ha.Entities)
+		agent.Tools.Register(tools.NewHomeAssistantTool(client, ha.ReadOnly))
+	}
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerExternalTools registers the tools of the executables in the
+// external tools directory on every agent, running in its workspace. They
+// don't replace an agent's own tools of the same name. Reloading the config
+// reads the directory again.
+func registerExternalTools(cfg config.ExternalToolsConfig, registry *AgentRegistry) {
+	if !cfg.Enabled || cfg.Dir == "" {
+		return
+	}
+	dir := expandHome(cfg.Dir)
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	external, problems := tools.LoadExternalTools(dir, cfg.WASMMaxMemoryMB, timeout)
+	for _, err := range problems {
+		logger.WarnCF("tools", "Failed to load an external tool", map[string]any{
+			"dir": dir, "error": err.Error(),
+		})
+	}
+
+	var registered []string
+	for _, tool := range external {
+		for _, agentID := range registry.ListAgentIDs() {
+			agent, ok := registry.GetAgent(agentID)
+			if !ok {
+				continue
+			}
+			if _, taken := agent.Tools.Get(tool.Name()); taken {
+				logger.WarnCF("tools", "External tool has the name of a built-in tool", map[string]any{
+					"tool": tool.Name(), "agent": agentID,
+				})
+				continue
+			}
+			agent.Tools.Register(tool.ForWorkspace(agent.Workspace))
+		}
+		registered = append(registered, tool.Name())
+	}
+	if len(registered) > 0 {
+		logger.InfoCF("tools", "Loaded external tools", map[string]any{"dir": dir, "tools": registered})
+	}
+}
@@ -0,0 +1,54 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/archive"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerFileTools registers the tools that bring files in and out of the
+// paths the file tools may use: downloads, archives and copies to other
+// machines.
+func registerFileTools(cfg *config.Config, agent *AgentInstance) {
+	allowPaths := make([]string, 0, len(cfg.Tools.Files.AllowPaths))
+	for _, path := range cfg.Tools.Files.AllowPaths {
+		allowPaths = append(allowPaths, expandHome(path))
+	}
+	restrict := cfg.Agents.Defaults.RestrictToWorkspace
+	if dl := cfg.Tools.Download; dl.Enabled {
+		agent.Tools.Register(tools.NewDownloadTool(tools.DownloadToolOptions{
+			Workspace:  agent.Workspace,
+			Restrict:   restrict,
+			AllowPaths: allowPaths,
+			MaxSize:    int64(dl.MaxSizeMB) << 20,
+			AllowTypes: dl.AllowTypes,
+			Timeout:    time.Duration(dl.TimeoutSeconds) * time.Second,
+			Proxy:      cfg.Tools.Web.Proxy,
+		}))
+	}
+	if ar := cfg.Tools.Archive; ar.Enabled {
+		agent.Tools.Register(tools.NewArchiveTool(tools.ArchiveToolOptions{
+			Workspace:  agent.Workspace,
+			Restrict:   restrict,
+			AllowPaths: allowPaths,
+			Limits:     archive.Limits{MaxBytes: int64(ar.MaxExtractMB) << 20, MaxFiles: ar.MaxFiles},
+		}))
+	}
+	if ssh := cfg.Tools.SSH; ssh.Enabled && slices.ContainsFunc(ssh.Hosts, func(h config.SSHHostConfig) bool {
+		return len(h.TransferDirs) > 0
+	}) {
+		agent.Tools.Register(tools.NewCopyToHostTool(ssh, tools.CopyToHostToolOptions{
+			Workspace:  agent.Workspace,
+			Restrict:   restrict,
+			AllowPaths: allowPaths,
+		}))
+	}
+}
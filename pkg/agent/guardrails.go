@@ -0,0 +1,53 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// errTurnTimeLimit is the cancellation cause of a turn that ran longer than
+// its agent's MaxTurnTime.
+var errTurnTimeLimit = errors.New("turn time limit reached")
+
+// turnLimitNote logs that a turn hit the guardrail configured by setting
+// (max_tool_iterations, max_tool_calls or max_turn_minutes) and returns the
+// reply telling the user.
+func turnLimitNote(agent *AgentInstance, opts processOptions, setting string) string {
+	var limit string
+	switch setting {
+	case "max_tool_iterations":
+		limit = fmt.Sprintf("%d model calls", agent.MaxIterations)
+	case "max_tool_calls":
+		limit = fmt.Sprintf("%d tool calls", agent.MaxToolCalls)
+	case "max_turn_minutes":
+		limit = describeDuration(agent.MaxTurnTime) + " of work"
+	}
+	logger.WarnCF("agent", "Turn stopped by guardrail",
+		map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey, "limit": setting})
+	return fmt.Sprintf("I stopped before finishing: this request reached its limit of %s "+
+		"(agents.defaults.%s). Send \"continue\" to let me go on.", limit, setting)
+}
+
+// describeDuration writes d for a chat message: "30 minutes", "45 seconds",
+// or Go's notation when it isn't a whole number of either.
+func describeDuration(d time.Duration) string {
+	switch {
+	case d == time.Minute:
+		return "1 minute"
+	case d == time.Second:
+		return "1 second"
+	case d > 0 && d%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	case d > 0 && d%time.Second == 0:
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	}
+	return d.String()
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// loopingMockProvider never stops calling tools.
+type loopingMockProvider struct {
+	perTurn int           // Tool calls per response
+	delay   time.Duration // Time each call takes
+	calls   atomic.Int32
+}
+
+func (m *loopingMockProvider) Chat(
+	ctx context.Context,
+	_ []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	n := m.calls.Add(1)
+	select {
+	case <-time.After(m.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var calls []providers.ToolCall
+	for i := range m.perTurn {
+		calls = append(calls, providers.ToolCall{
+			ID:        fmt.Sprintf("call-%d-%d", n, i),
+			Name:      "counting",
+			Arguments: map[string]any{},
+		})
+	}
+	return &providers.LLMResponse{ToolCalls: calls}, nil
+}
+
+func (m *loopingMockProvider) GetDefaultModel() string { return "mock-model" }
+
+type countingTool struct{ runs atomic.Int32 }
+
+func (c *countingTool) Name() string               { return "counting" }
+func (c *countingTool) Description() string        { return "Counts its calls" }
+func (c *countingTool) Parameters() map[string]any { return map[string]any{"type": "object"} }
+func (c *countingTool) Execute(context.Context, map[string]any) *tools.ToolResult {
+	c.runs.Add(1)
+	return tools.SilentResult("counted")
+}
+
+// withToolLimits sets the tool iterations and tool calls a turn may take.
+func withToolLimits(iterations, calls int) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Agents.Defaults.MaxToolIterations = iterations
+		cfg.Agents.Defaults.MaxToolCalls = calls
+	}
+}
+
+func TestGuardrails_MaxIterations(t *testing.T) {
+	provider := &loopingMockProvider{perTurn: 1}
+	al, _ := newTestLoop(t, provider, withToolLimits(3, 0))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+
+	response, err := al.ProcessDirect(context.Background(), "loop forever", "s")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if !strings.Contains(response, "limit of 3 model calls (agents.defaults.max_tool_iterations)") {
+		t.Errorf("response = %q", response)
+	}
+	if provider.calls.Load() != 3 || tool.runs.Load() != 3 {
+		t.Errorf("LLM calls = %d, tool runs = %d", provider.calls.Load(), tool.runs.Load())
+	}
+}
+
+func TestGuardrails_MaxToolCalls(t *testing.T) {
+	provider := &loopingMockProvider{perTurn: 2}
+	al, _ := newTestLoop(t, provider, withToolLimits(10, 5))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+
+	response, _ := al.ProcessDirect(context.Background(), "loop forever", "s")
+	if !strings.Contains(response, "limit of 5 tool calls (agents.defaults.max_tool_calls)") {
+		t.Errorf("response = %q", response)
+	}
+	if provider.calls.Load() != 3 || tool.runs.Load() != 5 {
+		t.Errorf("LLM calls = %d, tool runs = %d", provider.calls.Load(), tool.runs.Load())
+	}
+
+	// The call that wasn't run still has a result, so the transcript stays valid.
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main")
+	if last := history[len(history)-2]; last.Role != "tool" || !strings.HasPrefix(last.Content, "Not run:") {
+		t.Errorf("last tool result = %+v", last)
+	}
+}
+
+func TestGuardrails_MaxTurnTime(t *testing.T) {
+	provider := &loopingMockProvider{perTurn: 1, delay: 20 * time.Millisecond}
+	al, _ := newTestLoop(t, provider, withToolLimits(1000, -1))
+	al.RegisterTool(&countingTool{})
+	al.registry.GetDefaultAgent().MaxTurnTime = 100 * time.Millisecond
+
+	start := time.Now()
+	response, err := al.ProcessDirect(context.Background(), "loop forever", "s")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if !strings.Contains(response, "limit of 100ms of work (agents.defaults.max_turn_minutes)") {
+		t.Errorf("response = %q", response)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("turn took %v", elapsed)
+	}
+}
+
+func TestNewAgentInstance_GuardrailDefaults(t *testing.T) {
+	for _, tt := range []struct {
+		calls, minutes int
+		wantCalls      int
+		wantTime       time.Duration
+	}{
+		{0, 0, defaultMaxToolCalls, defaultMaxTurnTime},
+		{7, 2, 7, 2 * time.Minute},
+		{-1, -1, 0, 0},
+	} {
+		defaults := &config.AgentDefaults{Workspace: t.TempDir(), MaxToolCalls: tt.calls, MaxTurnMinutes: tt.minutes}
+		agent := NewAgentInstance(nil, defaults, &config.Config{}, &mockProvider{})
+		if agent.MaxToolCalls != tt.wantCalls || agent.MaxTurnTime != tt.wantTime {
+			t.Errorf("(%d, %d) gave %d, %v", tt.calls, tt.minutes, agent.MaxToolCalls, agent.MaxTurnTime)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// heartbeatSession is the session key heartbeat turns run and record their
+// usage under.
+const heartbeatSession = "heartbeat"
+
+// heartbeatOK is the reply of a heartbeat that found nothing to tell the user.
+const heartbeatOK = "HEARTBEAT_OK"
+
+// ErrHeartbeatBudget is returned by ProcessHeartbeat when today's heartbeat
+// or overall budget is used up.
+var ErrHeartbeatBudget = errors.New("heartbeat budget used up for today")
+
+// heartbeatBudget returns ErrHeartbeatBudget, with the limit reached, once
+// heartbeats have spent heartbeat.daily_limit_usd today or all turns have
+// spent agents.defaults.budget.daily_limit_usd. A heartbeat runs unasked, so
+// it doesn't get the downshift chat turns do.
+func (al *AgentLoop) heartbeatBudget(now time.Time) error {
+	if al.usage == nil {
+		return nil
+	}
+	cfg := al.config()
+	if limit := cfg.Heartbeat.DailyLimitUSD; limit > 0 {
+		if spent := al.usage.SessionDay(heartbeatSession, now).Cost; spent >= limit {
+			return fmt.Errorf("%w: heartbeats spent $%.2f of heartbeat.daily_limit_usd $%.2f",
+				ErrHeartbeatBudget, spent, limit)
+		}
+	}
+	if limit := cfg.Agents.Defaults.Budget.DailyLimitUSD; limit > 0 {
+		if spent := al.usage.Day(now).Cost; spent >= limit {
+			return fmt.Errorf("%w: $%.2f spent of agents.defaults.budget.daily_limit_usd $%.2f",
+				ErrHeartbeatBudget, spent, limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+func TestProcessHeartbeat_StopsOverBudget(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "The basil needs water."}}
+	al, _ := newTestLoop(t, provider, nil)
+	al.config().Heartbeat.DailyLimitUSD = 0.5
+	ctx := context.Background()
+
+	if response, err := al.ProcessHeartbeat(ctx, "check", "telegram", "42"); err != nil || response != "The basil needs water." {
+		t.Fatalf("ProcessHeartbeat = %q, %v", response, err)
+	}
+
+	// Chat turns don't count against the heartbeat budget...
+	al.usage.Add(usage.Record{Session: "agent:main:main", Model: "openai/gpt-4o", Cost: 2})
+	if _, err := al.ProcessHeartbeat(ctx, "check", "telegram", "42"); err != nil {
+		t.Fatalf("chat spend stopped the heartbeat: %v", err)
+	}
+	// ...but heartbeats do.
+	al.usage.Add(usage.Record{Session: heartbeatSession, Model: "openai/gpt-4o", Cost: 0.5})
+	if _, err := al.ProcessHeartbeat(ctx, "check", "telegram", "42"); !errors.Is(err, ErrHeartbeatBudget) {
+		t.Errorf("over the heartbeat budget: err = %v", err)
+	}
+
+	// So does the overall daily budget.
+	al.config().Heartbeat.DailyLimitUSD = 0
+	al.config().Agents.Defaults.Budget.DailyLimitUSD = 1
+	if _, err := al.ProcessHeartbeat(ctx, "check", "telegram", "42"); !errors.Is(err, ErrHeartbeatBudget) {
+		t.Errorf("over the daily budget: err = %v", err)
+	}
+	if n := len(provider.calls); n != 2 {
+		t.Errorf("LLM calls = %d, want 2", n)
+	}
+}
+
+// messagingMockProvider sends text with the message tool, then answers done.
+type messagingMockProvider struct {
+	text string
+	done string
+}
+
+func (m *messagingMockProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	if messages[len(messages)-1].Role == "tool" {
+		return &providers.LLMResponse{Content: m.done}, nil
+	}
+	return &providers.LLMResponse{ToolCalls: []providers.ToolCall{
+		{ID: "call-1", Name: "message", Arguments: map[string]any{"content": m.text}},
+	}}, nil
+}
+
+func (m *messagingMockProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestProcessHeartbeat_MessageToolReplyIsNotRepeated(t *testing.T) {
+	al, msgBus := newTestLoop(t, &messagingMockProvider{text: "The basil needs water.", done: "I told the user."}, nil)
+
+	response, err := al.ProcessHeartbeat(context.Background(), "check", "telegram", "42")
+	if err != nil || response != heartbeatOK {
+		t.Errorf("ProcessHeartbeat = %q, %v; want %s", response, err, heartbeatOK)
+	}
+	if out := nextOutbound(t, msgBus); out.Content != "The basil needs water." || out.ChatID != "42" {
+		t.Errorf("message = %+v", out)
+	}
+}
@@ -4,14 +4,28 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
+// defaultMaxParallelTools bounds concurrent tool calls per turn when
+// max_parallel_tools is unset; kept small for embedded boards.
+const defaultMaxParallelTools = 4
+
+// Guardrails for one request, so a runaway tool loop can't run up the bill.
+const (
+	defaultMaxToolCalls = 100
+	defaultMaxTurnTime  = 30 * time.Minute
+)
+
 // AgentInstance represents a fully configured agent with its own workspace,
 // session manager, context builder, and tool registry.
 type AgentInstance struct {
@@ -21,15 +35,25 @@ type AgentInstance struct {
 	Fallbacks      []string
 	Workspace      string
 	MaxIterations  int
+	MaxToolCalls   int           // Tool calls per request; 0 means no limit
+	MaxTurnTime    time.Duration // Wall-clock time per request; 0 means no limit
+	MaxParallel    int           // Concurrent tool calls per model turn
 	MaxTokens      int
+	MaxContinue    int // Follow-up requests for a reply cut off by MaxTokens
 	Temperature    float64
 	ContextWindow  int
+	Tokenizer      tokenizer
 	Provider       providers.LLMProvider
 	Sessions       *session.SessionManager
 	ContextBuilder *ContextBuilder
 	Tools          *tools.ToolRegistry
 	Subagents      *config.SubagentsConfig
 	SkillsFilter   []string
+	Persona        string        // Agent's own system prompt
+	Facts          *memory.Store // Semantic long-term memory; nil when off
+	UserFacts      *userFacts    // Configured users' own long-term memory; nil when off
+	Profiles       *ProfileStore // Users' /prefs
+	Review         config.ReviewConfig
 	Candidates     []providers.FallbackCandidate
 }
 
@@ -46,19 +70,55 @@ func NewAgentInstance(
 	model := resolveAgentModel(agentCfg, defaults)
 	fallbacks := resolveAgentFallbacks(agentCfg, defaults)
 
+	storageKey := resolveStorageKey(cfg)
+	sessionsDir := filepath.Join(workspace, "sessions")
+	memoryDir := filepath.Join(workspace, "memory")
+
 	restrict := defaults.RestrictToWorkspace
+	readFile := tools.NewReadFileTool(workspace, restrict)
+	writeFile := tools.NewWriteFileTool(workspace, restrict)
+	editFile := tools.NewEditFileTool(workspace, restrict)
+	appendFile := tools.NewAppendFileTool(workspace, restrict)
+	patchFile := tools.NewPatchFileTool(workspace, restrict)
+	listDir := tools.NewListDirTool(workspace, restrict)
+	if cfg != nil {
+		files := cfg.Tools.Files
+		allowPaths := make([]string, 0, len(files.AllowPaths))
+		for _, path := range files.AllowPaths {
+			allowPaths = append(allowPaths, expandHome(path))
+		}
+		tools.GuardFiles(workspace, tools.FileGuardOptions{
+			AllowPaths: allowPaths,
+			MaxSize:    int64(files.MaxFileSizeKB) * 1024,
+			Backups:    files.Backups,
+		}, readFile, writeFile, editFile, appendFile, patchFile, listDir)
+	}
+	tools.EncryptFiles(storageKey, workspace, []string{memoryDir, sessionsDir},
+		readFile, writeFile, editFile, appendFile, patchFile)
+
 	toolsRegistry := tools.NewToolRegistry()
-	toolsRegistry.Register(tools.NewReadFileTool(workspace, restrict))
-	toolsRegistry.Register(tools.NewWriteFileTool(workspace, restrict))
-	toolsRegistry.Register(tools.NewListDirTool(workspace, restrict))
+	if agentCfg != nil {
+		toolsRegistry.SetAllowed(agentCfg.Tools)
+	}
+	toolsRegistry.Register(readFile)
+	toolsRegistry.Register(writeFile)
+	toolsRegistry.Register(listDir)
 	toolsRegistry.Register(tools.NewExecToolWithConfig(workspace, restrict, cfg))
-	toolsRegistry.Register(tools.NewEditFileTool(workspace, restrict))
-	toolsRegistry.Register(tools.NewAppendFileTool(workspace, restrict))
+	toolsRegistry.Register(editFile)
+	toolsRegistry.Register(patchFile)
+	toolsRegistry.Register(appendFile)
 
-	sessionsDir := filepath.Join(workspace, "sessions")
-	sessionsManager := session.NewSessionManager(sessionsDir)
+	sessionsManager := session.NewEncryptedSessionManager(sessionsDir, storageKey)
 
 	contextBuilder := NewContextBuilder(workspace)
+	contextBuilder.SetStorageKey(storageKey)
+
+	var persona string
+	if agentCfg != nil {
+		persona = agentCfg.SystemPrompt
+	}
+	contextBuilder.SetPersona(persona)
+	contextBuilder.SetTools(toolsRegistry)
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
@@ -71,17 +131,49 @@ func NewAgentInstance(
 		subagents = agentCfg.Subagents
 		skillsFilter = agentCfg.Skills
 	}
+	contextBuilder.SetSkills(skillsFilter)
+
+	review := defaults.Review
+	if agentCfg != nil && agentCfg.Review != nil {
+		review = *agentCfg.Review
+	}
 
 	maxIter := defaults.MaxToolIterations
 	if maxIter == 0 {
 		maxIter = 20
 	}
 
+	maxToolCalls := defaults.MaxToolCalls
+	switch {
+	case maxToolCalls == 0:
+		maxToolCalls = defaultMaxToolCalls
+	case maxToolCalls < 0:
+		maxToolCalls = 0
+	}
+
+	maxTurnTime := time.Duration(defaults.MaxTurnMinutes) * time.Minute
+	switch {
+	case maxTurnTime == 0:
+		maxTurnTime = defaultMaxTurnTime
+	case maxTurnTime < 0:
+		maxTurnTime = 0
+	}
+
+	maxParallel := defaults.MaxParallelTools
+	if maxParallel == 0 {
+		maxParallel = defaultMaxParallelTools
+	}
+
 	maxTokens := defaults.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = 8192
 	}
 
+	maxContinue := defaults.MaxContinuations
+	if maxContinue == 0 {
+		maxContinue = defaultMaxContinuations
+	}
+
 	temperature := 0.7
 	if defaults.Temperature != nil {
 		temperature = *defaults.Temperature
@@ -94,6 +186,21 @@ func NewAgentInstance(
 	}
 	candidates := providers.ResolveCandidates(modelCfg, defaults.Provider)
 
+	contextWindow, modelID := resolveContextWindow(cfg, defaults, model)
+
+	var facts *memory.Store
+	var usersFacts *userFacts
+	if defaults.Memory.Enabled {
+		path := filepath.Join(memoryDir, "facts.db")
+		var err error
+		if facts, err = memory.Open(path, storageKey); err != nil {
+			logger.ErrorCF("agent", "Can't open long-term memory, continuing without it",
+				map[string]any{"path": path, "error": err.Error()})
+		} else {
+			usersFacts = newUserFacts(memoryDir, storageKey)
+		}
+	}
+
 	return &AgentInstance{
 		ID:             agentID,
 		Name:           agentName,
@@ -101,19 +208,44 @@ func NewAgentInstance(
 		Fallbacks:      fallbacks,
 		Workspace:      workspace,
 		MaxIterations:  maxIter,
+		MaxToolCalls:   maxToolCalls,
+		MaxTurnTime:    maxTurnTime,
+		MaxParallel:    maxParallel,
 		MaxTokens:      maxTokens,
+		MaxContinue:    maxContinue,
 		Temperature:    temperature,
-		ContextWindow:  maxTokens,
+		ContextWindow:  contextWindow,
+		Tokenizer:      tokenizerFor(modelID),
 		Provider:       provider,
 		Sessions:       sessionsManager,
 		ContextBuilder: contextBuilder,
 		Tools:          toolsRegistry,
 		Subagents:      subagents,
 		SkillsFilter:   skillsFilter,
+		Persona:        persona,
+		Facts:          facts,
+		UserFacts:      usersFacts,
+		Profiles:       NewProfileStore(workspace, storageKey),
+		Review:         review,
 		Candidates:     candidates,
 	}
 }
 
+// resolveStorageKey returns the key for encrypting the workspace's sessions
+// and memory, or nil when storage isn't encrypted.
+func resolveStorageKey(cfg *config.Config) *encrypt.Key {
+	if cfg == nil {
+		return nil
+	}
+	key, err := encrypt.ParseKey(cfg.Storage.EncryptionKey)
+	if err != nil {
+		logger.ErrorCF("agent", "Invalid storage encryption key, storing unencrypted",
+			map[string]any{"error": err.Error()})
+		return nil
+	}
+	return key
+}
+
 // resolveAgentWorkspace determines the workspace directory for an agent.
 func resolveAgentWorkspace(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) string {
 	if agentCfg != nil && strings.TrimSpace(agentCfg.Workspace) != "" {
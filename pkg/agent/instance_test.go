@@ -1,10 +1,13 @@
 package agent
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/encrypt"
 )
 
 func TestNewAgentInstance_UsesDefaultsTemperatureAndMaxTokens(t *testing.T) {
@@ -93,3 +96,46 @@ func TestNewAgentInstance_DefaultsTemperatureWhenUnset(t *testing.T) {
 		t.Fatalf("Temperature = %f, want %f", agent.Temperature, 0.7)
 	}
 }
+
+func TestNewAgentInstance_EncryptsMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{Workspace: tmpDir, Model: "test-model"},
+		},
+		Storage: config.StorageConfig{EncryptionKey: "correct horse battery staple"},
+	}
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	memoryFile := filepath.Join(tmpDir, "memory", "MEMORY.md")
+
+	// The agent writes memory with its file tools...
+	result := agent.Tools.Execute(context.Background(), "write_file", map[string]any{
+		"path": memoryFile, "content": "User's birthday: May 3",
+	})
+	if result.IsError {
+		t.Fatalf("write_file: %s", result.ForLLM)
+	}
+	data, err := os.ReadFile(memoryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypt.IsSealed(data) {
+		t.Errorf("memory stored in plaintext: %q", data)
+	}
+
+	// ...and both the tools and the system prompt read it back.
+	result = agent.Tools.Execute(context.Background(), "read_file", map[string]any{"path": memoryFile})
+	if result.ForLLM != "User's birthday: May 3" {
+		t.Errorf("read_file = %q", result.ForLLM)
+	}
+	if got := agent.ContextBuilder.memory.ReadLongTerm(); got != "User's birthday: May 3" {
+		t.Errorf("ReadLongTerm = %q", got)
+	}
+
+	// Files outside memory and sessions are written as is.
+	notes := filepath.Join(tmpDir, "notes.txt")
+	agent.Tools.Execute(context.Background(), "write_file", map[string]any{"path": notes, "content": "plain"})
+	if data, _ := os.ReadFile(notes); string(data) != "plain" {
+		t.Errorf("notes.txt = %q, want plaintext", data)
+	}
+}
@@ -0,0 +1,114 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// What happens to a message sent while the agent is still working on the
+// chat's previous one, besides the default of answering it afterwards; see
+// config.AgentDefaults.OnNewMessage.
+const (
+	onNewMessageSteer   = "steer"   // Hand it to the running turn
+	onNewMessageRestart = "restart" // Stop the running turn and answer it instead
+)
+
+// errSuperseded is the cancellation cause of a turn stopped because a newer
+// message restarts it.
+var errSuperseded = fmt.Errorf("%w: superseded by a newer message", ErrStopped)
+
+// supersededNote is saved as the assistant reply of a turn stopped for a
+// newer message.
+const supersededNote = "[Interrupted by the user's next message before finishing.]"
+
+// steeringQueue holds the messages sent to running turns, by chat key,
+// until the turn's next model call. The zero value is ready to use.
+type steeringQueue struct {
+	mu   sync.Mutex
+	msgs map[string][]bus.InboundMessage
+}
+
+func (q *steeringQueue) add(key string, msg bus.InboundMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.msgs == nil {
+		q.msgs = make(map[string][]bus.InboundMessage)
+	}
+	q.msgs[key] = append(q.msgs[key], msg)
+}
+
+// take removes and returns the messages for chat key.
+func (q *steeringQueue) take(key string) []bus.InboundMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	msgs := q.msgs[key]
+	delete(q.msgs, key)
+	return msgs
+}
+
+// interrupt applies agents.defaults.on_new_message to msg when a turn of
+// its chat is running. It reports true when msg was handed to that turn
+// and mustn't be queued. Commands and messages with images are always
+// queued.
+func (al *AgentLoop) interrupt(msg bus.InboundMessage) bool {
+	key := chatKey(msg.Channel, msg.ChatID)
+	if strings.HasPrefix(strings.TrimSpace(msg.Content), "/") || len(msg.Images) > 0 || !al.active.running(key) {
+		return false
+	}
+	if _, _, ok := al.identifyUser(msg); !ok {
+		return false
+	}
+	switch al.config().Agents.Defaults.OnNewMessage {
+	case onNewMessageSteer:
+		al.steering.add(key, msg)
+		logger.InfoCF("agent", "Handing new message to the running turn",
+			map[string]any{"channel": msg.Channel, "chat_id": msg.ChatID})
+		return true
+	case onNewMessageRestart:
+		if al.active.stop(key, errSuperseded) > 0 {
+			logger.InfoCF("agent", "Restarting for new message",
+				map[string]any{"channel": msg.Channel, "chat_id": msg.ChatID})
+		}
+	}
+	return false
+}
+
+// startTurn registers a turn for the chat, like activeRuns.start. When it
+// ends, messages sent to it that it didn't get to are queued again.
+func (al *AgentLoop) startTurn(ctx context.Context, channel, chatID string) (context.Context, func()) {
+	key := chatKey(channel, chatID)
+	ctx, done := al.active.start(ctx, key)
+	return ctx, func() {
+		done()
+		if al.active.running(key) {
+			return
+		}
+		for _, msg := range al.steering.take(key) {
+			al.bus.PublishInbound(msg)
+		}
+	}
+}
+
+// steer adds the messages sent to the running turn since the last model
+// call to messages and the session, and returns the result.
+func (al *AgentLoop) steer(agent *AgentInstance, opts processOptions, messages []providers.Message) []providers.Message {
+	for _, msg := range al.steering.take(chatKey(opts.Channel, opts.ChatID)) {
+		m := providers.Message{Role: "user", Content: msg.Content}
+		messages = append(messages, m)
+		agent.Sessions.AddFullMessage(opts.SessionKey, m)
+		logger.InfoCF("agent", "Steered turn with new message",
+			map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey})
+	}
+	return messages
+}
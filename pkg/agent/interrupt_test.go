@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// gatedMockProvider blocks its first call until release is closed (or, with
+// untilCancel, until its context is cancelled) and answers later calls with
+// the last user message.
+type gatedMockProvider struct {
+	started     chan struct{}
+	release     chan struct{}
+	untilCancel bool
+
+	mu    sync.Mutex
+	calls [][]providers.Message
+}
+
+func newGatedMockProvider(untilCancel bool) *gatedMockProvider {
+	return &gatedMockProvider{started: make(chan struct{}, 1), release: make(chan struct{}), untilCancel: untilCancel}
+}
+
+func (m *gatedMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, append([]providers.Message(nil), messages...))
+	first := len(m.calls) == 1
+	m.mu.Unlock()
+	if first {
+		m.started <- struct{}{}
+		if m.untilCancel {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		<-m.release
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{
+			{ID: "call-1", Name: "counting", Arguments: map[string]any{}},
+		}}, nil
+	}
+	var last string
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			last = msg.Content
+		}
+	}
+	return &providers.LLMResponse{Content: "answer to: " + last}, nil
+}
+
+func (m *gatedMockProvider) GetDefaultModel() string { return "mock-model" }
+
+func (m *gatedMockProvider) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+func nextOutbound(t *testing.T, msgBus *bus.MessageBus) bus.OutboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("no outbound message")
+	}
+	return out
+}
+
+func TestRun_NewMessageSteersRunningTurn(t *testing.T) {
+	provider := newGatedMockProvider(false)
+	al, msgBus := newTestLoop(t, provider, nil)
+	al.config().Agents.Defaults.OnNewMessage = "steer"
+	al.RegisterTool(&countingTool{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "plan a trip"})
+	waitFor(t, provider.started)
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "by train"})
+	waitUntil(t, "the new message didn't reach the running turn", func() bool {
+		al.steering.mu.Lock()
+		defer al.steering.mu.Unlock()
+		return len(al.steering.msgs[chatKey("telegram", "42")]) == 1
+	})
+	close(provider.release)
+
+	if out := nextOutbound(t, msgBus); out.Content != "answer to: by train" {
+		t.Errorf("reply = %q", out.Content)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := provider.callCount(); n != 2 {
+		t.Errorf("LLM calls = %d, want 2 (the steering message isn't answered again)", n)
+	}
+}
+
+func TestRun_NewMessageRestartsRunningTurn(t *testing.T) {
+	provider := newGatedMockProvider(true)
+	al, msgBus := newTestLoop(t, provider, nil)
+	al.config().Agents.Defaults.OnNewMessage = "restart"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "plan a trip"})
+	waitFor(t, provider.started)
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "never mind, tell a joke"})
+
+	if out := nextOutbound(t, msgBus); out.Content != "answer to: never mind, tell a joke" {
+		t.Errorf("reply = %q", out.Content)
+	}
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main")
+	if len(history) != 4 || history[1].Content != supersededNote {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestStartTurn_RequeuesUnusedSteering(t *testing.T) {
+	al, msgBus := newTestLoop(t, &simpleMockProvider{response: "ok"}, nil)
+	msg := bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "one more thing"}
+
+	_, done := al.startTurn(context.Background(), "telegram", "42")
+	al.steering.add(chatKey("telegram", "42"), msg)
+	done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got, ok := msgBus.ConsumeInbound(ctx); !ok || got.Content != msg.Content {
+		t.Errorf("requeued message = %+v, %v", got, ok)
+	}
+}
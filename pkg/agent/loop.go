@@ -9,12 +9,12 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
-	"unicode/utf8"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
@@ -23,137 +23,193 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
-	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/usage"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
 type AgentLoop struct {
 	bus            *bus.MessageBus
+	mu             sync.RWMutex // guards cfg, roles and mcp, replaced by ReloadConfig, and registered
 	cfg            *config.Config
 	registry       *AgentRegistry
 	state          *state.Manager
+	usage          *usage.Tracker
 	running        atomic.Bool
 	summarizing    sync.Map
+	remembering    sync.Map // sessions with a fact extraction running
+	skillVectors   sync.Map // skill embeddings by skillText
 	fallback       *providers.FallbackChain
+	roles          *providers.ModelRoles
 	channelManager *channels.Manager
+	active         activeRuns
+	steering       steeringQueue // Messages for running turns; see interrupt
+	spendNotices   spendNotices
+	plans          pendingPlans
+	approvals      pendingApprovals
+	approvalPrompt func(ctx context.Context, question string) (string, error) // see SetApprovalPrompt
+	mcp            *mcpServers
+	registered     []tools.Tool // Tools added with RegisterTool, kept on reload
 }
 
 // processOptions configures how a message is processed
 type processOptions struct {
-	SessionKey      string // Session identifier for history/context
-	Channel         string // Target channel for tool execution
-	ChatID          string // Target chat ID for tool execution
-	UserMessage     string // User message content (may include prefix)
-	DefaultResponse string // Response when LLM returns empty
-	EnableSummary   bool   // Whether to trigger summarization
-	SendResponse    bool   // Whether to send response via bus
-	NoHistory       bool   // If true, don't load session history (for heartbeat)
+	SessionKey      string             // Session identifier for history/context
+	Channel         string             // Target channel for tool execution
+	ChatID          string             // Target chat ID for tool execution
+	UserMessage     string             // User message content (may include prefix)
+	DefaultResponse string             // Response when LLM returns empty
+	EnableSummary   bool               // Whether to trigger summarization
+	SendResponse    bool               // Whether to send response via bus
+	NoHistory       bool               // If true, don't load session history (for heartbeat)
+	Role            string             // Model role serving this message; empty uses the agent's model
+	Model           string             // model_list entry overriding the agent's model and Role
+	User            string             // Configured user the message is from, or the sender's profile key; see identifyUser
+	UserConfig      *config.UserConfig // Settings of User when it is a configured user
+	Recalled        string             // The user's profile, and memories and skills relevant to UserMessage, added to the system prompt
+	SkillTools      []string           // The only tools the matched skills use; empty offers every tool
+
+	// Images are attached to the current user message only; they are not
+	// persisted to the session.
+	Images []providers.ImagePart
+
+	// NewStream, if set, enables streaming. It is called once per LLM call and
+	// returns the callback that receives that call's text deltas.
+	NewStream func() providers.StreamCallback
 }
 
-func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers.LLMProvider) *AgentLoop {
-	registry := NewAgentRegistry(cfg, provider)
+// streamFlushInterval throttles partial replies sent to chat channels, which
+// typically rate-limit message edits.
+const streamFlushInterval = 700 * time.Millisecond
 
-	// Register shared tools to all agents
-	registerSharedTools(cfg, msgBus, registry, provider)
+// inboundQueueSize bounds the messages Run accepts ahead of the one being
+// processed.
+const inboundQueueSize = 100
 
-	// Set up shared fallback chain
-	cooldown := providers.NewCooldownTracker()
-	fallbackChain := providers.NewFallbackChain(cooldown)
+func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers.LLMProvider) *AgentLoop {
+	registry := NewAgentRegistry(cfg, provider)
 
-	// Create state manager using default agent's workspace for channel recording
+	// Create state manager and usage tracker using default agent's workspace
 	defaultAgent := registry.GetDefaultAgent()
 	var stateManager *state.Manager
+	var usageTracker *usage.Tracker
 	if defaultAgent != nil {
 		stateManager = state.NewManager(defaultAgent.Workspace)
+		usageTracker = usage.NewTracker(defaultAgent.Workspace)
 	}
 
+	roles := providers.NewModelRoles(cfg)
+
+	// Register shared tools to all agents
+	registerSharedTools(cfg, msgBus, registry, provider, roles, usageTracker)
+	servers := connectMCPServers(cfg.Tools.MCP.Servers)
+	servers.register(registry)
+	registerExternalTools(cfg.Tools.External, registry)
+
+	// Set up shared fallback chain
+	cooldown := providers.NewCooldownTracker()
+	fallbackChain := providers.NewFallbackChain(cooldown)
+
 	return &AgentLoop{
 		bus:         msgBus,
 		cfg:         cfg,
 		registry:    registry,
 		state:       stateManager,
+		usage:       usageTracker,
 		summarizing: sync.Map{},
 		fallback:    fallbackChain,
+		roles:       roles,
+		mcp:         servers,
 	}
 }
 
-// registerSharedTools registers tools that are shared across all agents (web, message, spawn).
+// registerSharedTools registers tools that are shared across all agents.
 func registerSharedTools(
 	cfg *config.Config,
 	msgBus *bus.MessageBus,
 	registry *AgentRegistry,
 	provider providers.LLMProvider,
+	roles *providers.ModelRoles,
+	usageTracker *usage.Tracker,
 ) {
+	send := mediaSender(msgBus)
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
 		if !ok {
 			continue
 		}
-
-		// Web tools
-		if searchTool := tools.NewWebSearchTool(tools.WebSearchToolOptions{
-			BraveAPIKey:          cfg.Tools.Web.Brave.APIKey,
-			BraveMaxResults:      cfg.Tools.Web.Brave.MaxResults,
-			BraveEnabled:         cfg.Tools.Web.Brave.Enabled,
-			TavilyAPIKey:         cfg.Tools.Web.Tavily.APIKey,
-			TavilyBaseURL:        cfg.Tools.Web.Tavily.BaseURL,
-			TavilyMaxResults:     cfg.Tools.Web.Tavily.MaxResults,
-			TavilyEnabled:        cfg.Tools.Web.Tavily.Enabled,
-			DuckDuckGoMaxResults: cfg.Tools.Web.DuckDuckGo.MaxResults,
-			DuckDuckGoEnabled:    cfg.Tools.Web.DuckDuckGo.Enabled,
-			PerplexityAPIKey:     cfg.Tools.Web.Perplexity.APIKey,
-			PerplexityMaxResults: cfg.Tools.Web.Perplexity.MaxResults,
-			PerplexityEnabled:    cfg.Tools.Web.Perplexity.Enabled,
-			Proxy:                cfg.Tools.Web.Proxy,
-		}); searchTool != nil {
-			agent.Tools.Register(searchTool)
+		registerWebTools(cfg, agent, send)
+		registerDeviceTools(cfg, agent, roles, send)
+		registerPersonalTools(cfg, agent, roles)
+		registerDevTools(cfg, agent, send)
+		registerFileTools(cfg, agent)
+		registerMessageTools(cfg, msgBus, agent, roles, send)
+		registerSkillTools(cfg, agent)
+		registerSubagentTools(cfg, msgBus, registry, provider, agent)
+		if usageTracker != nil {
+			agent.Tools.Register(tools.NewUsageTool(usageTracker))
 		}
-		agent.Tools.Register(tools.NewWebFetchToolWithProxy(50000, cfg.Tools.Web.Proxy))
-
-		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
-		agent.Tools.Register(tools.NewI2CTool())
-		agent.Tools.Register(tools.NewSPITool())
-
-		// Message tool
-		messageTool := tools.NewMessageTool()
-		messageTool.SetSendCallback(func(channel, chatID, content string) error {
-			msgBus.PublishOutbound(bus.OutboundMessage{
-				Channel: channel,
-				ChatID:  chatID,
-				Content: content,
-			})
-			return nil
-		})
-		agent.Tools.Register(messageTool)
+	}
+}
 
-		// Skill discovery and installation tools
-		registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-			MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-			ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+// registerMessageTools registers the tools that send to the chat: messages
+// and, when a model is assigned to the image_gen role, generated images.
+func registerMessageTools(
+	cfg *config.Config,
+	msgBus *bus.MessageBus,
+	agent *AgentInstance,
+	roles *providers.ModelRoles,
+	send tools.SendMediaCallback,
+) {
+	messageTool := tools.NewMessageTool()
+	messageTool.SetSendCallback(func(channel, chatID, content string) error {
+		msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: content,
 		})
-		searchCache := skills.NewSearchCache(
-			cfg.Tools.Skills.SearchCache.MaxSize,
-			time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
-		)
-		agent.Tools.Register(tools.NewFindSkillsTool(registryMgr, searchCache))
-		agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
-
-		// Spawn tool with allowlist checker
-		subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace, msgBus)
-		subagentManager.SetLLMOptions(agent.MaxTokens, agent.Temperature)
-		spawnTool := tools.NewSpawnTool(subagentManager)
-		currentAgentID := agentID
-		spawnTool.SetAllowlistChecker(func(targetAgentID string) bool {
-			return registry.CanSpawnSubagent(currentAgentID, targetAgentID)
+		return nil
+	})
+	agent.Tools.Register(messageTool)
+
+	// Image generation, when a model is assigned to the image_gen role
+	if cfg.Agents.Defaults.ModelForRole(providers.RoleImageGen) != "" {
+		imageTool := tools.NewImageGenerationTool(roles.ImageGenerator, agent.Workspace)
+		imageTool.SetSendCallback(send)
+		agent.Tools.Register(imageTool)
+	}
+}
+
+// mediaSender sends the files a tool made to the chat it was called from.
+func mediaSender(msgBus *bus.MessageBus) tools.SendMediaCallback {
+	return func(channel, chatID, caption string, paths []string) error {
+		msgBus.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: caption,
+			Media:   paths,
 		})
-		agent.Tools.Register(spawnTool)
+		return nil
 	}
 }
 
 func (al *AgentLoop) Run(ctx context.Context) error {
 	al.running.Store(true)
+	go al.consolidateMemoryPeriodically(ctx)
+
+	// Messages are processed one at a time by a worker, so this loop stays
+	// free to act on /stop while a turn is in flight.
+	queue := make(chan bus.InboundMessage, inboundQueueSize)
+	defer close(queue)
+	go func() {
+		for msg := range queue {
+			if ctx.Err() != nil {
+				continue
+			}
+			al.handleInbound(ctx, msg)
+		}
+	}()
 
 	for al.running.Load() {
 		select {
@@ -165,32 +221,43 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				continue
 			}
 
-			response, err := al.processMessage(ctx, msg)
-			if err != nil {
-				response = fmt.Sprintf("Error processing message: %v", err)
+			// Only configured users may stop turns or answer approval
+			// questions; other messages go on to be ignored in turn.
+			user, _, known := al.identifyUser(msg)
+			known = known && msg.Channel != "system"
+
+			if known && isStopCommand(msg.Content) {
+				al.bus.PublishOutbound(bus.OutboundMessage{
+					Channel: msg.Channel,
+					ChatID:  msg.ChatID,
+					Content: al.stopReply(msg.Channel, msg.ChatID),
+				})
+				continue
 			}
 
-			if response != "" {
-				// Check if the message tool already sent a response during this round.
-				// If so, skip publishing to avoid duplicate messages to the user.
-				// Use default agent's tools to check (message tool is shared).
-				alreadySent := false
-				defaultAgent := al.registry.GetDefaultAgent()
-				if defaultAgent != nil {
-					if tool, ok := defaultAgent.Tools.Get("message"); ok {
-						if mt, ok := tool.(*tools.MessageTool); ok {
-							alreadySent = mt.HasSentInRound()
-						}
-					}
-				}
+			// An answer to a tool approval question goes to the turn
+			// waiting for it, which is holding up the queue.
+			if known && al.approvals.answer(chatKey(msg.Channel, msg.ChatID), user, msg.Content) {
+				continue
+			}
+			// Any other pressed button, such as one pressed by someone the
+			// question isn't for, is no message to answer.
+			if msg.Metadata[bus.MetadataButton] == "true" {
+				logger.DebugCF("agent", "Ignoring a button press that answers nothing",
+					map[string]any{"channel": msg.Channel, "chat_id": msg.ChatID, "sender_id": msg.SenderID})
+				continue
+			}
 
-				if !alreadySent {
-					al.bus.PublishOutbound(bus.OutboundMessage{
-						Channel: msg.Channel,
-						ChatID:  msg.ChatID,
-						Content: response,
-					})
-				}
+			// A message for a chat with a turn in flight may steer or
+			// restart it, depending on agents.defaults.on_new_message.
+			if msg.Channel != "system" && al.interrupt(msg) {
+				continue
+			}
+
+			select {
+			case queue <- msg:
+			case <-ctx.Done():
+				return nil
 			}
 		}
 	}
@@ -198,11 +265,97 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 	return nil
 }
 
+// handleInbound processes a message from the bus and publishes the reply.
+func (al *AgentLoop) handleInbound(ctx context.Context, msg bus.InboundMessage) {
+	response, err := al.processMessage(ctx, msg)
+	if errors.Is(err, ErrStopped) {
+		// The /stop command has already been acknowledged.
+		return
+	}
+	if err != nil {
+		response = errorReply(err)
+	}
+
+	if response == "" {
+		return
+	}
+
+	// Check if the message tool already sent a response during this round.
+	// If so, skip publishing to avoid duplicate messages to the user.
+	if !al.messageSentInRound() {
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: msg.Channel,
+			ChatID:  msg.ChatID,
+			Content: response,
+			Voice:   err == nil && msg.Metadata[bus.MetadataReplyVoice] == "true",
+		})
+	}
+}
+
+// messageSentInRound reports whether the message tool sent a message during
+// the current round. The default agent's tool is checked, as the message
+// tool is shared.
+func (al *AgentLoop) messageSentInRound() bool {
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		return false
+	}
+	if tool, ok := defaultAgent.Tools.Get("message"); ok {
+		if mt, ok := tool.(*tools.MessageTool); ok {
+			return mt.HasSentInRound()
+		}
+	}
+	return false
+}
+
+// errorReply tells the user why a turn failed, with advice for the provider
+// errors they can do something about.
+func errorReply(err error) string {
+	switch {
+	case errors.Is(err, providers.ErrAuth):
+		return "The model provider rejected the API key. Check api_key in config.json."
+	case errors.Is(err, providers.ErrRateLimited):
+		return "The model provider is rate limiting requests. Please try again in a minute."
+	case errors.Is(err, providers.ErrOverloaded):
+		return "The model provider is overloaded right now. Please try again shortly."
+	case errors.Is(err, providers.ErrContentFiltered):
+		return "The model provider's content filter blocked this request."
+	case errors.Is(err, providers.ErrContextTooLong):
+		return "This conversation is too long for the model, even after compacting it. " +
+			"Start a new session or switch to a model with a larger context window."
+	}
+	return fmt.Sprintf("Error processing message: %v", err)
+}
+
 func (al *AgentLoop) Stop() {
 	al.running.Store(false)
+	if roles := al.modelRoles(); roles != nil {
+		roles.Close()
+	}
+	// Browsers run as separate processes, which would outlive us.
+	for _, agentID := range al.registry.ListAgentIDs() {
+		if agent, ok := al.registry.GetAgent(agentID); ok {
+			if browser, ok := agent.Tools.Get("browser"); ok {
+				if closer, ok := browser.(interface{ Close() }); ok {
+					closer.Close()
+				}
+			}
+		}
+	}
+	// As are MCP servers run over stdio.
+	al.mu.RLock()
+	servers := al.mcp
+	al.mu.RUnlock()
+	servers.close()
 }
 
+// RegisterTool registers tool on every agent. Unlike the tools the
+// configuration enables, it stays registered when the configuration is
+// reloaded.
 func (al *AgentLoop) RegisterTool(tool tools.Tool) {
+	al.mu.Lock()
+	al.registered = append(al.registered, tool)
+	al.mu.Unlock()
 	for _, agentID := range al.registry.ListAgentIDs() {
 		if agent, ok := al.registry.GetAgent(agentID); ok {
 			agent.Tools.Register(tool)
@@ -251,23 +404,120 @@ func (al *AgentLoop) ProcessDirectWithChannel(
 	return al.processMessage(ctx, msg)
 }
 
+// ProcessDirectStream is like ProcessDirect but streams text deltas of every
+// LLM call to onDelta as they are generated. Providers without streaming
+// support fall back to a regular call and onDelta is not invoked.
+func (al *AgentLoop) ProcessDirectStream(
+	ctx context.Context,
+	content, sessionKey string,
+	onDelta providers.StreamCallback,
+) (string, error) {
+	msg := bus.InboundMessage{
+		Channel:    "cli",
+		SenderID:   "cron",
+		ChatID:     "direct",
+		Content:    content,
+		SessionKey: sessionKey,
+	}
+
+	return al.processMessageWithStream(ctx, msg, func() providers.StreamCallback { return onDelta })
+}
+
+// ProcessDirectWithImages is like ProcessDirectStream with images attached to
+// the message, given as data: or http(s) URLs. onDelta may be nil to disable
+// streaming.
+func (al *AgentLoop) ProcessDirectWithImages(
+	ctx context.Context,
+	content, sessionKey string,
+	images []string,
+	onDelta providers.StreamCallback,
+) (string, error) {
+	msg := bus.InboundMessage{
+		Channel:    "cli",
+		SenderID:   "cron",
+		ChatID:     "direct",
+		Content:    content,
+		Images:     images,
+		SessionKey: sessionKey,
+	}
+
+	var newStream func() providers.StreamCallback
+	if onDelta != nil {
+		newStream = func() providers.StreamCallback { return onDelta }
+	}
+	return al.processMessageWithStream(ctx, msg, newStream)
+}
+
 // ProcessHeartbeat processes a heartbeat request without session history.
 // Each heartbeat is independent and doesn't accumulate context.
+// It returns ErrHeartbeatBudget without running once today's budget is used
+// up, and HEARTBEAT_OK when there's nothing left to tell the user, including
+// when the agent already messaged them.
 func (al *AgentLoop) ProcessHeartbeat(ctx context.Context, content, channel, chatID string) (string, error) {
+	if err := al.heartbeatBudget(time.Now()); err != nil {
+		return "", err
+	}
 	agent := al.registry.GetDefaultAgent()
-	return al.runAgentLoop(ctx, agent, processOptions{
-		SessionKey:      "heartbeat",
+	response, err := al.runAgentLoop(ctx, agent, processOptions{
+		SessionKey:      heartbeatSession,
 		Channel:         channel,
 		ChatID:          chatID,
+		User:            profileKey(channel, "", chatID),
 		UserMessage:     content,
-		DefaultResponse: "I've completed processing but have no response to give.",
+		DefaultResponse: heartbeatOK,
 		EnableSummary:   false,
 		SendResponse:    false,
 		NoHistory:       true, // Don't load session history for heartbeat
+		Role:            providers.RoleCheap,
+	})
+	if err == nil && al.messageSentInRound() {
+		return heartbeatOK, nil
+	}
+	return response, err
+}
+
+// ProcessTrigger runs the message of a trigger event in the trigger's own
+// session and sends the reply to channel and chatID.
+func (al *AgentLoop) ProcessTrigger(ctx context.Context, trigger, content, channel, chatID string) (string, error) {
+	agent := al.registry.GetDefaultAgent()
+	response, err := al.runAgentLoop(ctx, agent, processOptions{
+		SessionKey:      "trigger-" + trigger,
+		Channel:         channel,
+		ChatID:          chatID,
+		User:            profileKey(channel, "", chatID),
+		UserMessage:     content,
+		DefaultResponse: "I've completed processing but have no response to give.",
+		EnableSummary:   true,
+		SendResponse:    false,
 	})
+	if err != nil {
+		return "", err
+	}
+	if !al.messageSentInRound() && !constants.IsInternalChannel(channel) {
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: response,
+		})
+	}
+	return response, nil
 }
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	var newStream func() providers.StreamCallback
+	if al.config().Agents.Defaults.Streaming && !constants.IsInternalChannel(msg.Channel) {
+		newStream = func() providers.StreamCallback {
+			return al.newPartialPublisher(msg.Channel, msg.ChatID)
+		}
+	}
+	return al.processMessageWithStream(ctx, msg, newStream)
+}
+
+func (al *AgentLoop) processMessageWithStream(
+	ctx context.Context,
+	msg bus.InboundMessage,
+	newStream func() providers.StreamCallback,
+) (string, error) {
 	// Add message preview to log (show full content for error messages)
 	var logContent string
 	if strings.Contains(msg.Content, "Error:") || strings.Contains(msg.Content, "error") {
@@ -288,12 +538,21 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return al.processSystemMessage(ctx, msg)
 	}
 
+	user, userCfg, known := al.identifyUser(msg)
+	if !known {
+		logger.WarnCF("agent", "Ignoring message from a sender who isn't a configured user",
+			map[string]any{"channel": msg.Channel, "sender_id": msg.SenderID})
+		return "", nil
+	}
+
 	// Check for commands
 	if response, handled := al.handleCommand(ctx, msg); handled {
 		return response, nil
 	}
 
-	// Route to determine agent and session key
+	// Route to determine agent and session key. A leading "@<agent>" sends
+	// the message to that agent.
+	mention, content := al.splitAgentPrefix(msg.Content)
 	route := al.registry.ResolveRoute(routing.RouteInput{
 		Channel:    msg.Channel,
 		AccountID:  msg.Metadata["account_id"],
@@ -301,6 +560,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		ParentPeer: extractParentPeer(msg),
 		GuildID:    msg.Metadata["guild_id"],
 		TeamID:     msg.Metadata["team_id"],
+		AgentID:    mention,
 	})
 
 	agent, ok := al.registry.GetAgent(route.AgentID)
@@ -308,10 +568,16 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		agent = al.registry.GetDefaultAgent()
 	}
 
-	// Use routed session key, but honor pre-set agent-scoped keys (for ProcessDirect/cron)
+	// Use routed session key, but honor pre-set agent-scoped keys (for
+	// ProcessDirect/cron). A configured user's direct chats share one
+	// session across channels.
 	sessionKey := route.SessionKey
 	if msg.SessionKey != "" && strings.HasPrefix(msg.SessionKey, "agent:") {
 		sessionKey = msg.SessionKey
+	} else if userCfg != nil {
+		if peer := extractPeer(msg); peer == nil || peer.Kind == "direct" {
+			sessionKey = userSessionKey(agent.ID, user)
+		}
 	}
 
 	logger.InfoCF("agent", "Routed message",
@@ -321,15 +587,117 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			"matched_by":  route.MatchedBy,
 		})
 
-	return al.runAgentLoop(ctx, agent, processOptions{
+	if response, handled := al.handleModelCommand(agent, sessionKey, content); handled {
+		return response, nil
+	}
+	facts := agent.Facts
+	if userCfg != nil {
+		facts = factsFor(agent, user)
+	}
+	if response, handled := al.handleMemoryCommand(ctx, agent, facts, content); handled {
+		return response, nil
+	}
+	if response, handled := al.handleSessionCommand(agent, sessionKey, content); handled {
+		return response, nil
+	}
+	if response, handled := al.handlePrefsCommand(agent, user, content); handled {
+		return response, nil
+	}
+	if response := al.quotaReply(user, userCfg); response != "" {
+		return response, nil
+	}
+
+	// A leading "@<model_name>:" picks the model for this message only;
+	// otherwise the budget downshift, then the session's /model choice,
+	// applies.
+	model, content := al.splitModelPrefix(content)
+	if model == "" {
+		model = al.budgetModel(msg.Channel, msg.ChatID)
+	}
+	if model == "" {
+		model = agent.Sessions.GetModel(sessionKey)
+	}
+
+	opts := processOptions{
 		SessionKey:      sessionKey,
 		Channel:         msg.Channel,
 		ChatID:          msg.ChatID,
-		UserMessage:     msg.Content,
+		User:            user,
+		UserConfig:      userCfg,
+		UserMessage:     content,
+		Model:           model,
 		DefaultResponse: "I've completed processing but have no response to give.",
 		EnableSummary:   true,
 		SendResponse:    false,
-	})
+		NewStream:       newStream,
+		Images:          imageParts(msg.Images),
+	}
+	if response, handled, err := al.handleWorkflowCommand(ctx, agent, opts); handled {
+		return response, err
+	}
+	if response, handled, err := al.maybePlan(ctx, agent, opts); handled {
+		return response, err
+	}
+	return al.runAgentLoop(ctx, agent, opts)
+}
+
+// imageParts converts inbound image references (data: or http(s) URLs) to
+// message image parts, dropping anything else.
+func imageParts(refs []string) []providers.ImagePart {
+	var parts []providers.ImagePart
+	for _, ref := range refs {
+		switch {
+		case strings.HasPrefix(ref, "data:"):
+			meta, data, ok := strings.Cut(strings.TrimPrefix(ref, "data:"), ",")
+			mimeType, isBase64 := strings.CutSuffix(meta, ";base64")
+			if !ok || !isBase64 || !strings.HasPrefix(mimeType, "image/") {
+				logger.WarnCF("agent", "Ignoring malformed image data URL", nil)
+				continue
+			}
+			parts = append(parts, providers.ImagePart{MIMEType: mimeType, Data: data})
+		case strings.HasPrefix(ref, "https://"), strings.HasPrefix(ref, "http://"):
+			parts = append(parts, providers.ImagePart{URL: ref})
+		}
+	}
+	return parts
+}
+
+// chat calls provider.Chat, or StreamChat when onDelta is set and the
+// provider supports streaming.
+func chat(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta providers.StreamCallback,
+) (*providers.LLMResponse, error) {
+	if sp, ok := provider.(providers.StreamingProvider); ok && onDelta != nil {
+		return sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+	}
+	return provider.Chat(ctx, messages, tools, model, options)
+}
+
+// newPartialPublisher returns a StreamCallback that publishes the reply
+// accumulated so far as partial outbound messages, at most once per
+// streamFlushInterval. The final reply is still published normally.
+func (al *AgentLoop) newPartialPublisher(channel, chatID string) providers.StreamCallback {
+	var sb strings.Builder
+	var lastFlush time.Time
+	return func(delta string) {
+		sb.WriteString(delta)
+		if time.Since(lastFlush) < streamFlushInterval {
+			return
+		}
+		lastFlush = time.Now()
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: sb.String(),
+			Partial: true,
+		})
+	}
 }
 
 func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
@@ -388,8 +756,12 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 	})
 }
 
-// runAgentLoop is the core message processing logic.
+// runAgentLoop is the core message processing logic. It returns ErrStopped
+// when the turn is cancelled with /stop.
 func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opts processOptions) (string, error) {
+	ctx, done := al.startTurn(ctx, opts.Channel, opts.ChatID)
+	defer done()
+
 	// 0. Record last channel for heartbeat notifications (skip internal channels)
 	if opts.Channel != "" && opts.ChatID != "" {
 		// Don't record internal channels (cli, system, subagent)
@@ -420,12 +792,40 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 		opts.ChatID,
 	)
 
-	// 3. Save user message to session
+	facts := agent.Facts
+	if opts.UserConfig != nil {
+		facts = factsFor(agent, opts.User)
+	}
+	opts.Recalled = joinSections(userProfile(agent, opts.User), al.recall(ctx, facts, opts.UserMessage))
+	skillDocs, skillTools := al.activeSkills(ctx, agent, opts.UserMessage)
+	opts.Recalled = joinSections(opts.Recalled, skillDocs)
+	opts.SkillTools = skillTools
+	messages = addRecalled(messages, opts.Recalled)
+
+	if len(opts.Images) > 0 && len(messages) > 0 && messages[len(messages)-1].Role == "user" {
+		messages[len(messages)-1].Images = opts.Images
+	}
+
+	// 3. Save user message to session. The session is saved as the turn
+	// goes, so a crash or restart loses at most the step in progress.
 	agent.Sessions.AddMessage(opts.SessionKey, "user", opts.UserMessage)
+	agent.Sessions.Save(opts.SessionKey)
 
-	// 4. Run LLM iteration loop
+	// 4. Run LLM iteration loop. A reviewed answer may still change, so
+	// it isn't streamed.
+	if reviewApplies(agent, opts) {
+		opts.NewStream = nil
+	}
 	finalContent, iteration, err := al.runLLMIteration(ctx, agent, messages, opts)
 	if err != nil {
+		if errors.Is(context.Cause(ctx), ErrStopped) {
+			agent.Sessions.AddMessage(opts.SessionKey, "assistant", stopNote(ctx))
+			agent.Sessions.Save(opts.SessionKey)
+			logger.InfoCF("agent", "Turn stopped by user",
+				map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey})
+			return "", ErrStopped
+		}
+		agent.Sessions.Save(opts.SessionKey)
 		return "", err
 	}
 
@@ -445,6 +845,9 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 	if opts.EnableSummary {
 		al.maybeSummarize(agent, opts.SessionKey, opts.Channel, opts.ChatID)
 	}
+	if !opts.NoHistory {
+		al.maybeRemember(agent, facts, opts.SessionKey)
+	}
 
 	// 8. Optional: send response via bus
 	if opts.SendResponse {
@@ -478,8 +881,54 @@ func (al *AgentLoop) runLLMIteration(
 	iteration := 0
 	var finalContent string
 
+	// Relay slow provider setup steps, such as pulling a missing model, to
+	// the chat so the user isn't left waiting without feedback.
+	if !constants.IsInternalChannel(opts.Channel) {
+		ctx = providers.WithProgress(ctx, func(status string) {
+			al.bus.PublishOutbound(bus.OutboundMessage{
+				Channel: opts.Channel,
+				ChatID:  opts.ChatID,
+				Content: status,
+			})
+		})
+	}
+
+	role := opts.Role
+	if role == "" && len(opts.Images) > 0 {
+		role = providers.RoleVision
+	}
+	provider, model := al.modelFor(agent, role)
+	if opts.Model != "" {
+		provider, model = al.namedModel(agent, opts.Model)
+	}
+	// Fallback candidates are alternatives to the agent's own model only.
+	useFallbacks := provider == agent.Provider && model == agent.Model &&
+		len(agent.Candidates) > 1 && al.fallback != nil
+
+	if agent.MaxTurnTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, agent.MaxTurnTime, errTurnTimeLimit)
+		defer cancel()
+	}
+	if approve := al.turnApprover(agent, opts); approve != nil {
+		ctx = tools.WithApprover(ctx, approve)
+	}
+	toolCalls := 0
+	answered := false
+
 	for iteration < agent.MaxIterations {
+		if err := ctx.Err(); err != nil {
+			if errors.Is(context.Cause(ctx), errTurnTimeLimit) {
+				finalContent = turnLimitNote(agent, opts, "max_turn_minutes")
+				answered = true
+				break
+			}
+			return "", iteration, err
+		}
 		iteration++
+		if iteration > 1 {
+			messages = al.steer(agent, opts, messages)
+		}
 
 		logger.DebugCF("agent", "LLM iteration",
 			map[string]any{
@@ -489,14 +938,14 @@ func (al *AgentLoop) runLLMIteration(
 			})
 
 		// Build tool definitions
-		providerToolDefs := agent.Tools.ToProviderDefs()
+		providerToolDefs := turnToolDefs(agent, opts)
 
 		// Log LLM request details
 		logger.DebugCF("agent", "LLM request",
 			map[string]any{
 				"agent_id":          agent.ID,
 				"iteration":         iteration,
-				"model":             agent.Model,
+				"model":             model,
 				"messages_count":    len(messages),
 				"tools_count":       len(providerToolDefs),
 				"max_tokens":        agent.MaxTokens,
@@ -512,19 +961,31 @@ func (al *AgentLoop) runLLMIteration(
 				"tools_json":    formatToolsForLog(providerToolDefs),
 			})
 
+		// Compact history before the request would overflow the context window.
+		if !opts.NoHistory && !fitsContext(agent, messages, providerToolDefs) {
+			messages = al.compactContext(ctx, agent, providerToolDefs, opts)
+		}
+
 		// Call LLM with fallback chain if candidates are configured.
-		var response *providers.LLMResponse
-		var err error
+		var onDelta providers.StreamCallback
+		if opts.NewStream != nil {
+			onDelta = opts.NewStream()
+		}
 
+		servedModel := model
 		callLLM := func() (*providers.LLMResponse, error) {
-			if len(agent.Candidates) > 1 && al.fallback != nil {
+			ctx := ctx
+			if onDelta != nil {
+				ctx = providers.WithToolCallDeltas(ctx, newToolCallChecker(agent.Tools).check)
+			}
+			if useFallbacks {
 				fbResult, fbErr := al.fallback.Execute(ctx, agent.Candidates,
 					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
-						return agent.Provider.Chat(ctx, messages, providerToolDefs, model, map[string]any{
-							"max_tokens":       agent.MaxTokens,
-							"temperature":      agent.Temperature,
+						return chat(ctx, agent.Provider, messages, providerToolDefs, model, map[string]any{
+							"max_tokens":  agent.MaxTokens,
+							"temperature": agent.Temperature,
 							// "prompt_cache_key": agent.ID,
-						})
+						}, onDelta)
 					},
 				)
 				if fbErr != nil {
@@ -535,55 +996,23 @@ func (al *AgentLoop) runLLMIteration(
 						fbResult.Provider, fbResult.Model, len(fbResult.Attempts)+1),
 						map[string]any{"agent_id": agent.ID, "iteration": iteration})
 				}
+				servedModel = fbResult.Provider + "/" + fbResult.Model
 				return fbResult.Response, nil
 			}
-			return agent.Provider.Chat(ctx, messages, providerToolDefs, agent.Model, map[string]any{
-				"max_tokens":       agent.MaxTokens,
-				"temperature":      agent.Temperature,
+			return chat(ctx, provider, messages, providerToolDefs, model, map[string]any{
+				"max_tokens":  agent.MaxTokens,
+				"temperature": agent.Temperature,
 				//"prompt_cache_key": agent.ID,
-			})
+			}, onDelta)
 		}
 
-		// Retry loop for context/token errors
-		maxRetries := 2
-		for retry := 0; retry <= maxRetries; retry++ {
-			response, err = callLLM()
-			if err == nil {
-				break
-			}
-
-			errMsg := strings.ToLower(err.Error())
-			isContextError := strings.Contains(errMsg, "token") ||
-				strings.Contains(errMsg, "context") ||
-				strings.Contains(errMsg, "invalidparameter") ||
-				strings.Contains(errMsg, "length")
+		response, err := al.callWithRetries(ctx, agent, opts, &messages, callLLM)
 
-			if isContextError && retry < maxRetries {
-				logger.WarnCF("agent", "Context window error detected, attempting compression", map[string]any{
-					"error": err.Error(),
-					"retry": retry,
-				})
-
-				if retry == 0 && !constants.IsInternalChannel(opts.Channel) {
-					al.bus.PublishOutbound(bus.OutboundMessage{
-						Channel: opts.Channel,
-						ChatID:  opts.ChatID,
-						Content: "Context window exceeded. Compressing history and retrying...",
-					})
-				}
-
-				al.forceCompression(agent, opts.SessionKey)
-				newHistory := agent.Sessions.GetHistory(opts.SessionKey)
-				newSummary := agent.Sessions.GetSummary(opts.SessionKey)
-				messages = agent.ContextBuilder.BuildMessages(
-					newHistory, newSummary, "",
-					nil, opts.Channel, opts.ChatID,
-				)
-				continue
-			}
+		if err != nil && errors.Is(context.Cause(ctx), errTurnTimeLimit) {
+			finalContent = turnLimitNote(agent, opts, "max_turn_minutes")
+			answered = true
 			break
 		}
-
 		if err != nil {
 			logger.ErrorCF("agent", "LLM call failed",
 				map[string]any{
@@ -594,6 +1023,37 @@ func (al *AgentLoop) runLLMIteration(
 			return "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
 		}
 
+		al.recordUsage(agent, opts, servedModel, response.Usage)
+
+		// Stitch a reply cut off by max_tokens together from follow-ups.
+		// They aren't saved to the session; only the whole reply is.
+		if len(response.ToolCalls) == 0 && response.FinishReason == "length" && agent.MaxContinue > 0 {
+			base := messages
+			response = continueTruncated(response, agent.MaxContinue,
+				func(extra []providers.Message) (*providers.LLMResponse, error) {
+					messages = append(base[:len(base):len(base)], extra...)
+					defer func() { messages = base }()
+					resp, err := callLLM()
+					if err == nil {
+						al.recordUsage(agent, opts, servedModel, resp.Usage)
+					}
+					return resp, err
+				})
+		}
+
+		if response.Upstream != "" || (response.Usage != nil && response.Usage.Cost > 0) {
+			fields := map[string]any{
+				"agent_id":  agent.ID,
+				"iteration": iteration,
+				"upstream":  response.Upstream,
+			}
+			if response.Usage != nil {
+				fields["total_tokens"] = response.Usage.TotalTokens
+				fields["cost_usd"] = response.Usage.Cost
+			}
+			logger.DebugCF("agent", "LLM routing metadata", fields)
+		}
+
 		// Check if no tool calls - we're done
 		if len(response.ToolCalls) == 0 {
 			finalContent = response.Content
@@ -603,6 +1063,10 @@ func (al *AgentLoop) runLLMIteration(
 					"iteration":     iteration,
 					"content_chars": len(finalContent),
 				})
+			if reviewApplies(agent, opts) {
+				finalContent = al.reviewAnswer(ctx, agent, opts, messages, provider, model, finalContent)
+			}
+			answered = true
 			break
 		}
 
@@ -625,39 +1089,14 @@ func (al *AgentLoop) runLLMIteration(
 			})
 
 		// Build assistant message with tool calls
-		assistantMsg := providers.Message{
-			Role:             "assistant",
-			Content:          response.Content,
-			ReasoningContent: response.ReasoningContent,
-		}
-		for _, tc := range normalizedToolCalls {
-			argumentsJSON, _ := json.Marshal(tc.Arguments)
-			// Copy ExtraContent to ensure thought_signature is persisted for Gemini 3
-			extraContent := tc.ExtraContent
-			thoughtSignature := ""
-			if tc.Function != nil {
-				thoughtSignature = tc.Function.ThoughtSignature
-			}
-
-			assistantMsg.ToolCalls = append(assistantMsg.ToolCalls, providers.ToolCall{
-				ID:   tc.ID,
-				Type: "function",
-				Name: tc.Name,
-				Function: &providers.FunctionCall{
-					Name:             tc.Name,
-					Arguments:        string(argumentsJSON),
-					ThoughtSignature: thoughtSignature,
-				},
-				ExtraContent:     extraContent,
-				ThoughtSignature: thoughtSignature,
-			})
-		}
+		assistantMsg := toolCallMessage(response, normalizedToolCalls)
 		messages = append(messages, assistantMsg)
 
 		// Save assistant message with tool calls to session
 		agent.Sessions.AddFullMessage(opts.SessionKey, assistantMsg)
 
-		// Execute tool calls
+		// Execute tool calls, up to agent.MaxParallel at a time. Results are
+		// handled in call order so the transcript matches the assistant message.
 		for _, tc := range normalizedToolCalls {
 			argsJSON, _ := json.Marshal(tc.Arguments)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
@@ -667,12 +1106,14 @@ func (al *AgentLoop) runLLMIteration(
 					"tool":      tc.Name,
 					"iteration": iteration,
 				})
+		}
 
-			// Create async callback for tools that implement AsyncTool
-			// NOTE: Following openclaw's design, async tools do NOT send results directly to users.
-			// Instead, they notify the agent via PublishInbound, and the agent decides
-			// whether to forward the result to the user (in processSystemMessage).
-			asyncCallback := func(callbackCtx context.Context, result *tools.ToolResult) {
+		// Create async callback for tools that implement AsyncTool
+		// NOTE: Following openclaw's design, async tools do NOT send results directly to users.
+		// Instead, they notify the agent via PublishInbound, and the agent decides
+		// whether to forward the result to the user (in processSystemMessage).
+		asyncCallback := func(tc providers.ToolCall) tools.AsyncCallback {
+			return func(callbackCtx context.Context, result *tools.ToolResult) {
 				// Log the async completion but don't send directly to user
 				// The agent will handle user notification via processSystemMessage
 				if !result.Silent && result.ForUser != "" {
@@ -683,49 +1124,264 @@ func (al *AgentLoop) runLLMIteration(
 						})
 				}
 			}
+		}
 
-			toolResult := agent.Tools.ExecuteWithContext(
-				ctx,
-				tc.Name,
-				tc.Arguments,
-				opts.Channel,
-				opts.ChatID,
-				asyncCallback,
-			)
+		// Calls beyond max_tool_calls aren't run, but still get a result.
+		run := normalizedToolCalls
+		if agent.MaxToolCalls > 0 && toolCalls+len(run) > agent.MaxToolCalls {
+			run = run[:max(agent.MaxToolCalls-toolCalls, 0)]
+		}
+		toolCalls += len(run)
+		toolResults := agent.Tools.ExecuteAll(
+			ctx,
+			run,
+			opts.Channel,
+			opts.ChatID,
+			agent.MaxParallel,
+			asyncCallback,
+		)
+		for range normalizedToolCalls[len(run):] {
+			toolResults = append(toolResults, tools.ErrorResult(
+				"Not run: this request reached its tool call limit."))
+		}
+
+		messages = al.addToolResults(agent, opts, messages, normalizedToolCalls, toolResults)
+		agent.Sessions.Save(opts.SessionKey)
+
+		if len(run) < len(normalizedToolCalls) {
+			finalContent = turnLimitNote(agent, opts, "max_tool_calls")
+			answered = true
+			break
+		}
+	}
+
+	if !answered && iteration >= agent.MaxIterations {
+		finalContent = turnLimitNote(agent, opts, "max_tool_iterations")
+	}
+
+	return finalContent, iteration, nil
+}
+
+// turnApprover returns the approver for a turn's tool calls, limited to the
+// tools of the active skills and of the configured user, if any.
+func (al *AgentLoop) turnApprover(agent *AgentInstance, opts processOptions) tools.Approver {
+	approve := al.approverFor(agent, opts)
+	if len(opts.SkillTools) > 0 {
+		approve = restrictTools(opts.SkillTools, "while these skills are active", approve)
+	}
+	if u := opts.UserConfig; u != nil && len(u.Tools) > 0 {
+		approve = restrictTools(u.Tools, "to this user", approve)
+	}
+	return approve
+}
+
+// turnToolDefs returns the definitions of the tools offered to the model in
+// a turn, limited like turnApprover.
+func turnToolDefs(agent *AgentInstance, opts processOptions) []providers.ToolDefinition {
+	defs := agent.Tools.ToProviderDefs()
+	if len(opts.SkillTools) > 0 {
+		defs = onlyTools(defs, opts.SkillTools)
+	}
+	if u := opts.UserConfig; u != nil && len(u.Tools) > 0 {
+		defs = onlyTools(defs, u.Tools)
+	}
+	return defs
+}
+
+// callWithRetries calls callLLM, compressing the history in *messages and
+// retrying when the context window overflows, and asking again when a
+// streamed tool call is malformed.
+func (al *AgentLoop) callWithRetries(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+	messages *[]providers.Message,
+	callLLM func() (*providers.LLMResponse, error),
+) (*providers.LLMResponse, error) {
+	var response *providers.LLMResponse
+	var err error
+	maxRetries := 2
+	for retry := 0; retry <= maxRetries; retry++ {
+		response, err = callLLM()
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+
+		if errors.Is(err, providers.ErrContextTooLong) && retry < maxRetries {
+			logger.WarnCF("agent", "Context window error detected, attempting compression", map[string]any{
+				"error": err.Error(),
+				"retry": retry,
+			})
 
-			// Send ForUser content to user immediately if not Silent
-			if !toolResult.Silent && toolResult.ForUser != "" && opts.SendResponse {
+			if retry == 0 && !constants.IsInternalChannel(opts.Channel) {
 				al.bus.PublishOutbound(bus.OutboundMessage{
 					Channel: opts.Channel,
 					ChatID:  opts.ChatID,
-					Content: toolResult.ForUser,
+					Content: "Context window exceeded. Compressing history and retrying...",
 				})
-				logger.DebugCF("agent", "Sent tool result to user",
-					map[string]any{
-						"tool":        tc.Name,
-						"content_len": len(toolResult.ForUser),
-					})
 			}
 
-			// Determine content for LLM based on tool result
-			contentForLLM := toolResult.ForLLM
-			if contentForLLM == "" && toolResult.Err != nil {
-				contentForLLM = toolResult.Err.Error()
+			if !al.compactSession(ctx, agent, opts.SessionKey) {
+				al.forceCompression(agent, opts.SessionKey)
 			}
+			newHistory := agent.Sessions.GetHistory(opts.SessionKey)
+			newSummary := agent.Sessions.GetSummary(opts.SessionKey)
+			*messages = agent.ContextBuilder.BuildMessages(
+				newHistory, newSummary, "",
+				nil, opts.Channel, opts.ChatID,
+			)
+			*messages = addRecalled(*messages, opts.Recalled)
+			continue
+		}
 
-			toolResultMsg := providers.Message{
-				Role:       "tool",
-				Content:    contentForLLM,
-				ToolCallID: tc.ID,
-			}
-			messages = append(messages, toolResultMsg)
+		// A streamed tool call went wrong before it finished: ask again
+		// rather than waiting for (and executing) a call that can't work.
+		var malformed *malformedToolCallError
+		if errors.As(err, &malformed) && retry < maxRetries {
+			logger.WarnCF("agent", "Rejected malformed tool call, asking the model again", map[string]any{
+				"tool":  malformed.Name,
+				"error": malformed.Err.Error(),
+				"retry": retry,
+			})
+			*messages = append(*messages, providers.Message{
+				Role: "user",
+				Content: fmt.Sprintf("[System: your call to tool %q was rejected: %v. "+
+					"Call the tool again with valid JSON arguments matching its parameters.]",
+					malformed.Name, malformed.Err),
+			})
+			continue
+		}
+		break
+	}
+
+	return response, err
+}
 
-			// Save tool result message to session
-			agent.Sessions.AddFullMessage(opts.SessionKey, toolResultMsg)
+// toolCallMessage returns the assistant message that makes calls.
+func toolCallMessage(response *providers.LLMResponse, calls []providers.ToolCall) providers.Message {
+	assistantMsg := providers.Message{
+		Role:               "assistant",
+		Content:            response.Content,
+		ReasoningContent:   response.ReasoningContent,
+		ReasoningSignature: response.ReasoningSignature,
+	}
+	for _, tc := range calls {
+		argumentsJSON, _ := json.Marshal(tc.Arguments)
+		// Copy ExtraContent to ensure thought_signature is persisted for Gemini 3
+		extraContent := tc.ExtraContent
+		thoughtSignature := ""
+		if tc.Function != nil {
+			thoughtSignature = tc.Function.ThoughtSignature
 		}
+
+		assistantMsg.ToolCalls = append(assistantMsg.ToolCalls, providers.ToolCall{
+			ID:   tc.ID,
+			Type: "function",
+			Name: tc.Name,
+			Function: &providers.FunctionCall{
+				Name:             tc.Name,
+				Arguments:        string(argumentsJSON),
+				ThoughtSignature: thoughtSignature,
+			},
+			ExtraContent:     extraContent,
+			ThoughtSignature: thoughtSignature,
+		})
 	}
+	return assistantMsg
+}
 
-	return finalContent, iteration, nil
+// addToolResults appends the results of calls to messages and the session,
+// sending what they have for the user on the way.
+func (al *AgentLoop) addToolResults(
+	agent *AgentInstance,
+	opts processOptions,
+	messages []providers.Message,
+	calls []providers.ToolCall,
+	toolResults []*tools.ToolResult,
+) []providers.Message {
+	for i, tc := range calls {
+		toolResult := toolResults[i]
+
+		// Send ForUser content to user immediately if not Silent
+		if !toolResult.Silent && toolResult.ForUser != "" && opts.SendResponse {
+			al.bus.PublishOutbound(bus.OutboundMessage{
+				Channel: opts.Channel,
+				ChatID:  opts.ChatID,
+				Content: toolResult.ForUser,
+			})
+			logger.DebugCF("agent", "Sent tool result to user",
+				map[string]any{
+					"tool":        tc.Name,
+					"content_len": len(toolResult.ForUser),
+				})
+		}
+
+		// Determine content for LLM based on tool result
+		contentForLLM := toolResult.ForLLM
+		if contentForLLM == "" && toolResult.Err != nil {
+			contentForLLM = toolResult.Err.Error()
+		}
+
+		toolResultMsg := providers.Message{
+			Role:       "tool",
+			Content:    contentForLLM,
+			ToolCallID: tc.ID,
+		}
+		messages = append(messages, toolResultMsg)
+
+		// Save tool result message to session
+		agent.Sessions.AddFullMessage(opts.SessionKey, toolResultMsg)
+	}
+	return messages
+}
+
+// recordUsage adds an LLM call of a turn to the usage log, under the
+// configured user it serves, if any. model is the model_list alias
+// or protocol/model reference that served the call. Provider-reported cost is
+// preferred; otherwise it is estimated from the model's configured pricing or
+// the built-in price table.
+func (al *AgentLoop) recordUsage(agent *AgentInstance, opts processOptions, model string, u *providers.UsageInfo) {
+	if al.usage == nil || u == nil {
+		return
+	}
+
+	cfg := al.config()
+	var pricing *config.ModelPricing
+	for i := range cfg.ModelList {
+		mc := &cfg.ModelList[i]
+		if mc.ModelName == model || mc.Model == model {
+			model = mc.Model
+			pricing = mc.Pricing
+			break
+		}
+	}
+
+	cost := u.Cost
+	if cost == 0 {
+		if pricing != nil {
+			cost = usage.Price{Input: pricing.Input, Output: pricing.Output}.Cost(u.PromptTokens, u.CompletionTokens)
+		} else if price, ok := usage.LookupPrice(model); ok {
+			cost = price.Cost(u.PromptTokens, u.CompletionTokens)
+		}
+	}
+
+	var user string
+	if opts.UserConfig != nil {
+		user = opts.User
+	}
+	err := al.usage.Add(usage.Record{
+		Agent:            agent.ID,
+		Session:          opts.SessionKey,
+		User:             user,
+		Model:            model,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		ThinkingTokens:   u.ThinkingTokens,
+		Cost:             cost,
+	})
+	if err != nil {
+		logger.WarnCF("agent", "Failed to record usage", map[string]any{"error": err.Error()})
+	}
 }
 
 // updateToolContexts updates the context for tools that need channel/chatID info.
@@ -746,12 +1402,17 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 			st.SetContext(channel, chatID)
 		}
 	}
+	if tool, ok := agent.Tools.Get("workflow"); ok {
+		if wt, ok := tool.(tools.ContextualTool); ok {
+			wt.SetContext(channel, chatID)
+		}
+	}
 }
 
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
 func (al *AgentLoop) maybeSummarize(agent *AgentInstance, sessionKey, channel, chatID string) {
 	newHistory := agent.Sessions.GetHistory(sessionKey)
-	tokenEstimate := al.estimateTokens(newHistory)
+	tokenEstimate := agent.Tokenizer.CountMessages(newHistory)
 	threshold := agent.ContextWindow * 75 / 100
 
 	if len(newHistory) > 20 || tokenEstimate > threshold {
@@ -766,6 +1427,79 @@ func (al *AgentLoop) maybeSummarize(agent *AgentInstance, sessionKey, channel, c
 	}
 }
 
+// compactContext makes room in the context window by summarizing older
+// turns of the session (see compactSession) and rebuilds the request
+// messages from the compacted history. If that can't free enough room, the
+// oldest messages are dropped as a last resort.
+func (al *AgentLoop) compactContext(
+	ctx context.Context,
+	agent *AgentInstance,
+	toolDefs []providers.ToolDefinition,
+	opts processOptions,
+) []providers.Message {
+	rebuild := func() []providers.Message {
+		messages := agent.ContextBuilder.BuildMessages(
+			agent.Sessions.GetHistory(opts.SessionKey),
+			agent.Sessions.GetSummary(opts.SessionKey),
+			"", nil, opts.Channel, opts.ChatID,
+		)
+		return addRecalled(messages, opts.Recalled)
+	}
+
+	if al.compactSession(ctx, agent, opts.SessionKey) {
+		messages := rebuild()
+		if fitsContext(agent, messages, toolDefs) {
+			return messages
+		}
+	}
+
+	al.forceCompression(agent, opts.SessionKey)
+	return rebuild()
+}
+
+// compactSession folds the older turns of a session into its summary, keeping
+// the most recent turns verbatim. It returns false when there is nothing to
+// compact or summarization fails.
+func (al *AgentLoop) compactSession(ctx context.Context, agent *AgentInstance, sessionKey string) bool {
+	summarizeKey := agent.ID + ":" + sessionKey
+	if _, busy := al.summarizing.LoadOrStore(summarizeKey, true); busy {
+		return false
+	}
+	defer al.summarizing.Delete(summarizeKey)
+
+	history := agent.Sessions.GetHistory(sessionKey)
+	budget := promptBudget(agent)
+	cut := compactionCut(agent.Tokenizer, history, budget/4)
+	if cut == 0 {
+		return false
+	}
+
+	summary := agent.Sessions.GetSummary(sessionKey)
+	for _, batch := range compactionBatches(agent.Tokenizer, history[:cut], budget/2) {
+		s, err := al.summarizeBatch(ctx, agent, batch, summary)
+		if err != nil || s == "" {
+			logger.WarnCF("agent", "History compaction failed", map[string]any{
+				"session_key": sessionKey,
+				"error":       fmt.Sprint(err),
+			})
+			return false
+		}
+		summary = s
+	}
+
+	agent.Sessions.SetSummary(sessionKey, summary)
+	agent.Sessions.TruncateHistory(sessionKey, len(history)-cut)
+	agent.Sessions.Save(sessionKey)
+
+	logger.InfoCF("agent", "Compacted session history", map[string]any{
+		"session_key":    sessionKey,
+		"summarized":     cut,
+		"kept":           len(history) - cut,
+		"context_window": agent.ContextWindow,
+	})
+	return true
+}
+
 // forceCompression aggressively reduces context when the limit is hit.
 // It drops the oldest 50% of messages (keeping system prompt and last user message).
 func (al *AgentLoop) forceCompression(agent *AgentInstance, sessionKey string) {
@@ -949,14 +1683,15 @@ func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
 			s1,
 			s2,
 		)
-		resp, err := agent.Provider.Chat(
+		provider, model := al.modelFor(agent, providers.RoleSummarize)
+		resp, err := provider.Chat(
 			ctx,
 			[]providers.Message{{Role: "user", Content: mergePrompt}},
 			nil,
-			agent.Model,
+			model,
 			map[string]any{
-				"max_tokens":       1024,
-				"temperature":      0.3,
+				"max_tokens":  1024,
+				"temperature": 0.3,
 				// "prompt_cache_key": agent.ID,
 			},
 		)
@@ -980,6 +1715,26 @@ func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
 	}
 }
 
+// modelFor returns the provider and model serving role for agent. Roles
+// without an assigned model, and roles whose model can't be created, use the
+// agent's own model.
+func (al *AgentLoop) modelFor(agent *AgentInstance, role string) (providers.LLMProvider, string) {
+	roles := al.modelRoles()
+	if role == "" || roles == nil {
+		return agent.Provider, agent.Model
+	}
+	provider, model, err := roles.Provider(role)
+	if err != nil {
+		logger.WarnCF("agent", "Model role unavailable, using the agent's model",
+			map[string]any{"role": role, "error": err.Error()})
+		return agent.Provider, agent.Model
+	}
+	if provider == nil {
+		return agent.Provider, agent.Model
+	}
+	return provider, model
+}
+
 // summarizeBatch summarizes a batch of messages.
 func (al *AgentLoop) summarizeBatch(
 	ctx context.Context,
@@ -1000,14 +1755,15 @@ func (al *AgentLoop) summarizeBatch(
 	}
 	prompt := sb.String()
 
-	response, err := agent.Provider.Chat(
+	provider, model := al.modelFor(agent, providers.RoleSummarize)
+	response, err := provider.Chat(
 		ctx,
 		[]providers.Message{{Role: "user", Content: prompt}},
 		nil,
-		agent.Model,
+		model,
 		map[string]any{
-			"max_tokens":       1024,
-			"temperature":      0.3,
+			"max_tokens":  1024,
+			"temperature": 0.3,
 			// "prompt_cache_key": agent.ID,
 		},
 	)
@@ -1017,18 +1773,6 @@ func (al *AgentLoop) summarizeBatch(
 	return response.Content, nil
 }
 
-// estimateTokens estimates the number of tokens in a message list.
-// Uses a safe heuristic of 2.5 characters per token to account for CJK and other
-// overheads better than the previous 3 chars/token.
-func (al *AgentLoop) estimateTokens(messages []providers.Message) int {
-	totalChars := 0
-	for _, m := range messages {
-		totalChars += utf8.RuneCountInString(m.Content)
-	}
-	// 2.5 chars per token = totalChars * 2 / 5
-	return totalChars * 2 / 5
-}
-
 func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage) (string, bool) {
 	content := strings.TrimSpace(msg.Content)
 	if !strings.HasPrefix(content, "/") {
@@ -1044,6 +1788,9 @@ func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage)
 	args := parts[1:]
 
 	switch cmd {
+	case "/stop":
+		return al.stopReply(msg.Channel, msg.ChatID), true
+
 	case "/show":
 		if len(args) < 1 {
 			return "Usage: /show [model|channel|agents]", true
@@ -1070,7 +1817,11 @@ func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage)
 		}
 		switch args[0] {
 		case "models":
-			return "Available models: configured in config.json per agent", true
+			names := al.modelNames()
+			if len(names) == 0 {
+				return "Available models: configured in config.json per agent", true
+			}
+			return fmt.Sprintf("Available models: %s (use /model <name>)", strings.Join(names, ", ")), true
 		case "channels":
 			if al.channelManager == nil {
 				return "Channel manager not initialized", true
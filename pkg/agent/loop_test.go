@@ -2,9 +2,10 @@ package agent
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,38 @@ import (
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
+// newTestLoop returns an agent loop with provider on a temporary
+// workspace, and the bus it reads from. configure, if not nil, adjusts the
+// config first.
+func newTestLoop(
+	t *testing.T,
+	provider providers.LLMProvider,
+	configure func(cfg *config.Config),
+) (*AgentLoop, *bus.MessageBus) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, provider)
+	t.Cleanup(func() {
+		if facts := al.registry.GetDefaultAgent().Facts; facts != nil {
+			facts.Close()
+		}
+	})
+	return al, msgBus
+}
+
 func TestRecordLastChannel(t *testing.T) {
 	// Create temp workspace
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -141,6 +174,31 @@ func TestNewAgentLoop_StateInitialized(t *testing.T) {
 	}
 }
 
+func TestNewAgentLoop_ImageGenerationToolNeedsRole(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		ModelList: []config.ModelConfig{{ModelName: "sd", Model: "a1111/default"}},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	if _, ok := al.registry.GetDefaultAgent().Tools.Get("image_generation"); ok {
+		t.Error("image_generation registered without an image_gen model")
+	}
+
+	cfg.Agents.Defaults.ModelRoles = map[string]string{"image_gen": "sd"}
+	al = NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	if _, ok := al.registry.GetDefaultAgent().Tools.Get("image_generation"); !ok {
+		t.Error("image_generation not registered with an image_gen model")
+	}
+}
+
 // TestToolRegistry_ToolRegistration verifies tools can be registered and retrieved
 func TestToolRegistry_ToolRegistration(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -366,6 +424,122 @@ func (m *simpleMockProvider) GetDefaultModel() string {
 	return "mock-model"
 }
 
+// streamingMockProvider streams its response in fixed chunks
+type streamingMockProvider struct {
+	simpleMockProvider
+	chunks []string
+}
+
+func (m *streamingMockProvider) StreamChat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+	onDelta providers.StreamCallback,
+) (*providers.LLMResponse, error) {
+	for _, c := range m.chunks {
+		onDelta(c)
+	}
+	return m.Chat(ctx, messages, tools, model, opts)
+}
+
+// TestProcessDirectStream_ForwardsDeltas verifies deltas reach the callback
+// and the final response is still returned
+func TestProcessDirectStream_ForwardsDeltas(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &streamingMockProvider{
+		simpleMockProvider: simpleMockProvider{response: "Hello world"},
+		chunks:             []string{"Hello", " world"},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	var deltas []string
+	response, err := al.ProcessDirectStream(context.Background(), "hi", "test-session",
+		func(delta string) { deltas = append(deltas, delta) })
+	if err != nil {
+		t.Fatalf("ProcessDirectStream() error = %v", err)
+	}
+	if response != "Hello world" {
+		t.Errorf("response = %q, want %q", response, "Hello world")
+	}
+	if len(deltas) != 2 || deltas[0] != "Hello" || deltas[1] != " world" {
+		t.Errorf("deltas = %v", deltas)
+	}
+}
+
+// recordingMockProvider records the messages of every call
+type recordingMockProvider struct {
+	simpleMockProvider
+	calls [][]providers.Message
+}
+
+func (m *recordingMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls = append(m.calls, messages)
+	return m.simpleMockProvider.Chat(ctx, messages, tools, model, opts)
+}
+
+func TestProcessDirectWithImages_AttachesImagesToCurrentTurn(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "A cat."}}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	sessionKey := "agent:main:vision"
+	_, err := al.ProcessDirectWithImages(context.Background(), "what is this?", sessionKey, []string{
+		"data:image/png;base64,aGVsbG8=",
+		"https://example.com/cat.jpg",
+		"/etc/passwd",
+	}, nil)
+	if err != nil {
+		t.Fatalf("ProcessDirectWithImages() error = %v", err)
+	}
+
+	if len(provider.calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(provider.calls))
+	}
+	sent := provider.calls[0]
+	last := sent[len(sent)-1]
+	want := []providers.ImagePart{
+		{MIMEType: "image/png", Data: "aGVsbG8="},
+		{URL: "https://example.com/cat.jpg"},
+	}
+	if last.Content != "what is this?" || !reflect.DeepEqual(last.Images, want) {
+		t.Errorf("user message = %+v, want images %+v", last, want)
+	}
+
+	for _, m := range al.registry.GetDefaultAgent().Sessions.GetHistory(sessionKey) {
+		if len(m.Images) > 0 {
+			t.Errorf("images persisted to session: %+v", m)
+		}
+	}
+}
+
 // mockCustomTool is a simple mock tool for registration testing
 type mockCustomTool struct{}
 
@@ -572,7 +746,7 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 	msgBus := bus.NewMessageBus()
 
 	// Create a provider that fails once with a context error
-	contextErr := fmt.Errorf("InvalidParameter: Total tokens of image and text exceed max message tokens")
+	contextErr := providers.NewAPIError(400, "InvalidParameter: Total tokens of image and text exceed max message tokens")
 	provider := &failFirstMockProvider{
 		failures:    1,
 		failError:   contextErr,
@@ -631,3 +805,118 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 		t.Errorf("Expected history to be compressed (len < 8), got %d", len(finalHistory))
 	}
 }
+
+func TestProcessHeartbeat_UsesCheapModelRole(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "cheap.json")
+	if err := os.WriteFile(script, []byte(`{"default": "HEARTBEAT_OK from the cheap model"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				ModelRoles:        map[string]string{"cheap": "local"},
+			},
+		},
+		ModelList: []config.ModelConfig{{ModelName: "local", Model: "mock/" + script}},
+	}
+
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "from the chat model"}}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	response, err := al.ProcessHeartbeat(context.Background(), "check tasks", "cli", "direct")
+	if err != nil {
+		t.Fatalf("ProcessHeartbeat() error = %v", err)
+	}
+	if response != "HEARTBEAT_OK from the cheap model" || len(provider.calls) != 0 {
+		t.Errorf("response = %q, chat model calls = %d", response, len(provider.calls))
+	}
+
+	if _, err := al.ProcessDirect(context.Background(), "hi", "test-session"); err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if len(provider.calls) != 1 {
+		t.Errorf("chat model calls = %d, want 1", len(provider.calls))
+	}
+}
+
+func TestProcessTrigger_RepliesInTheTriggersChat(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "The door is open."}}
+	al, msgBus := newTestLoop(t, provider, nil)
+
+	if _, err := al.ProcessTrigger(context.Background(), "door", "[Trigger door (mqtt): home/door]", "telegram", "42"); err != nil {
+		t.Fatalf("ProcessTrigger() error = %v", err)
+	}
+	if out := nextOutbound(t, msgBus); out.Channel != "telegram" || out.ChatID != "42" || out.Content != "The door is open." {
+		t.Errorf("reply = %+v", out)
+	}
+	if history := al.registry.GetDefaultAgent().Sessions.GetHistory("trigger-door"); len(history) != 2 {
+		t.Errorf("trigger session = %+v", history)
+	}
+}
+
+func TestRun_MarksVoiceReplies(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "Here you go"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{
+		Channel: "telegram", SenderID: "7", ChatID: "42", Content: "[voice transcription: hi]",
+		Metadata: map[string]string{bus.MetadataReplyVoice: "true"},
+	})
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok || out.Content != "Here you go" || !out.Voice {
+		t.Errorf("outbound = %+v, %v; want a voice reply", out, ok)
+	}
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "hi"})
+	out, ok = msgBus.SubscribeOutbound(ctx)
+	if !ok || out.Voice {
+		t.Errorf("outbound = %+v, %v; want a text-only reply", out, ok)
+	}
+}
+
+func TestRun_ExplainsTypedProviderErrors(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	provider := &failFirstMockProvider{failures: 1, failError: providers.NewAPIError(401, "invalid key")}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "hi"})
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok || !strings.Contains(out.Content, "rejected the API key") {
+		t.Errorf("outbound = %+v, %v; want the auth explanation", out, ok)
+	}
+	if provider.currentCall != 1 {
+		t.Errorf("provider called %d times, want no retry on auth errors", provider.currentCall)
+	}
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mcp"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const defaultMCPTimeout = 60 * time.Second
+
+// mcpServers are the MCP servers connected to for a configuration, and
+// their tools.
+type mcpServers struct {
+	config  map[string]config.MCPServerConfig
+	clients []*mcp.Client
+	tools   []tools.Tool
+}
+
+// connectMCPServers connects to the enabled MCP servers and lists their
+// tools. A server that can't be reached is logged and left out.
+func connectMCPServers(servers map[string]config.MCPServerConfig) *mcpServers {
+	names := make([]string, 0, len(servers))
+	for name, server := range servers {
+		if server.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	type connected struct {
+		client *mcp.Client
+		tools  []mcp.Tool
+	}
+	results := make([]connected, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server := servers[name]
+			timeout := time.Duration(server.TimeoutSeconds) * time.Second
+			if timeout <= 0 {
+				timeout = defaultMCPTimeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			client, err := mcp.Connect(ctx, name, mcp.ServerConfig{
+				Command: server.Command,
+				Args:    server.Args,
+				Env:     server.Env,
+				Dir:     server.Dir,
+				URL:     server.URL,
+				Headers: server.Headers,
+			})
+			if err != nil {
+				logger.WarnCF("mcp", "Failed to connect to MCP server", map[string]any{
+					"server": name, "error": err.Error(),
+				})
+				return
+			}
+			list, err := client.ListTools(ctx)
+			if err != nil {
+				logger.WarnCF("mcp", "Failed to list the MCP server's tools", map[string]any{
+					"server": name, "error": err.Error(),
+				})
+				client.Close()
+				return
+			}
+			results[i] = connected{client: client, tools: list}
+		}()
+	}
+	wg.Wait()
+
+	connectedServers := &mcpServers{config: servers}
+	for i, name := range names {
+		client := results[i].client
+		if client == nil {
+			continue
+		}
+		connectedServers.clients = append(connectedServers.clients, client)
+		server := servers[name]
+		var registered []string
+		for _, tool := range results[i].tools {
+			if len(server.Tools) > 0 && !slices.Contains(server.Tools, tool.Name) {
+				continue
+			}
+			mcpTool := tools.NewMCPTool(name, tool, client, time.Duration(server.TimeoutSeconds)*time.Second)
+			connectedServers.tools = append(connectedServers.tools, mcpTool)
+			registered = append(registered, mcpTool.Name())
+		}
+		logger.InfoCF("mcp", "Connected to MCP server", map[string]any{
+			"server": name, "name": client.ServerName, "version": client.ServerVersion, "tools": registered,
+		})
+	}
+	return connectedServers
+}
+
+// register registers the servers' tools on every agent of registry.
+func (s *mcpServers) register(registry *AgentRegistry) {
+	for _, tool := range s.tools {
+		for _, agentID := range registry.ListAgentIDs() {
+			if agent, ok := registry.GetAgent(agentID); ok {
+				agent.Tools.Register(tool)
+			}
+		}
+	}
+}
+
+// close closes the connections to the servers, stopping those run over
+// stdio.
+func (s *mcpServers) close() {
+	for _, client := range s.clients {
+		client.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+	"github.com/sipeed/picoclaw/pkg/memory"
+)
+
+// chatOnlyTools only make sense within a conversation with the agent, so
+// they aren't served over MCP.
+var chatOnlyTools = []string{"message", "spawn", "subagent", "delegate", "workflow"}
+
+// MCPServerTools returns the default agent's tools for serving over MCP,
+// with a memory_search tool. If allow is set, only the tools it names are
+// returned. Tools that act on a chat, such as cron and remind, act on the
+// last one the user wrote from.
+func (al *AgentLoop) MCPServerTools(allow []string) []mcp.ServerTool {
+	agent := al.registry.GetDefaultAgent()
+	if agent == nil {
+		return nil
+	}
+	allowed := func(name string) bool {
+		return len(allow) == 0 || slices.Contains(allow, name)
+	}
+
+	var served []mcp.ServerTool
+	for _, name := range agent.Tools.List() {
+		if slices.Contains(chatOnlyTools, name) || !allowed(name) {
+			continue
+		}
+		tool, _ := agent.Tools.Get(name)
+		served = append(served, mcp.ServerTool{
+			Tool: mcp.Tool{Name: name, Description: tool.Description(), InputSchema: tool.Parameters()},
+			Call: func(ctx context.Context, args map[string]any) *mcp.CallResult {
+				var channel, chatID string
+				if al.state != nil {
+					channel, chatID, _ = strings.Cut(al.state.GetLastChannel(), ":")
+				}
+				result := agent.Tools.ExecuteWithContext(ctx, name, args, channel, chatID, nil)
+				if result.IsError {
+					return mcp.ErrorResult(result.ForLLM)
+				}
+				return mcp.TextResult(result.ForLLM)
+			},
+		})
+	}
+
+	if allowed("memory_search") {
+		served = append(served, mcp.ServerTool{
+			Tool: mcp.Tool{
+				Name:        "memory_search",
+				Description: "Search what the assistant remembers about the user: facts from past conversations and its notes.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{"type": "string", "description": "What to look for"},
+						"limit": map[string]any{"type": "integer", "description": "Most results to return (default 10)"},
+					},
+					"required": []string{"query"},
+				},
+			},
+			Call: func(ctx context.Context, args map[string]any) *mcp.CallResult {
+				query, _ := args["query"].(string)
+				limit := 10
+				if n, ok := args["limit"].(float64); ok && n > 0 {
+					limit = int(n)
+				}
+				return al.searchMemory(ctx, agent, query, limit)
+			},
+		})
+	}
+	return served
+}
+
+// searchMemory searches agent's facts by meaning when long-term memory and
+// embeddings are available, and otherwise its facts and MEMORY.md for the
+// query's words.
+func (al *AgentLoop) searchMemory(ctx context.Context, agent *AgentInstance, query string, limit int) *mcp.CallResult {
+	if strings.TrimSpace(query) == "" {
+		return mcp.ErrorResult("query is required")
+	}
+
+	var found []string
+	if agent.Facts != nil && agent.Facts.Len() > 0 {
+		if embedder, err := al.modelRoles().Embeddings(); err == nil {
+			if vectors, err := embedder.Embed(ctx, []string{query}); err == nil && len(vectors) == 1 {
+				for _, f := range agent.Facts.Search(vectors[0], limit, defaultRecallMinScore) {
+					found = append(found, fmt.Sprintf("%s (%s)", f.Text, f.Created.Format(time.DateOnly)))
+				}
+				return memoryResult(found)
+			}
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	matches := func(text string) bool {
+		text = strings.ToLower(text)
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				return false
+			}
+		}
+		return true
+	}
+	var facts []memory.Fact
+	if agent.Facts != nil {
+		facts = agent.Facts.List()
+	}
+	for i := len(facts) - 1; i >= 0 && len(found) < limit; i-- {
+		if matches(facts[i].Text) {
+			found = append(found, fmt.Sprintf("%s (%s)", facts[i].Text, facts[i].Created.Format(time.DateOnly)))
+		}
+	}
+	for _, line := range strings.Split(agent.ContextBuilder.memory.ReadLongTerm(), "\n") {
+		if len(found) >= limit {
+			break
+		}
+		if line = strings.TrimSpace(strings.TrimLeft(line, "-*# ")); line != "" && matches(line) {
+			found = append(found, line)
+		}
+	}
+	return memoryResult(found)
+}
+
+func memoryResult(found []string) *mcp.CallResult {
+	if len(found) == 0 {
+		return mcp.TextResult("Nothing found in memory.")
+	}
+	return mcp.TextResult("- " + strings.Join(found, "\n- "))
+}
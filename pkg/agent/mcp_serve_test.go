@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+func findServed(served []mcp.ServerTool, name string) (mcp.ServerTool, bool) {
+	for _, tool := range served {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return mcp.ServerTool{}, false
+}
+
+func TestMCPServerTools(t *testing.T) {
+	al, _ := newTestLoop(t, &memoryMockProvider{facts: ""}, withMemory(t, true))
+	facts := al.registry.GetDefaultAgent().Facts
+	if _, err := facts.Add("The user's dog is called Rex", []float32{1, 0, 0}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := facts.Add("The user lives in Berlin", []float32{0, 1, 0}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	served := al.MCPServerTools(nil)
+	for _, name := range []string{"read_file", "exec", "memory_search"} {
+		if _, ok := findServed(served, name); !ok {
+			t.Errorf("%s isn't served", name)
+		}
+	}
+	for _, name := range []string{"message", "spawn"} {
+		if _, ok := findServed(served, name); ok {
+			t.Errorf("%s is served, but only works in a chat", name)
+		}
+	}
+
+	search, _ := findServed(served, "memory_search")
+	result := search.Call(context.Background(), map[string]any{"query": "what's my dog's name?"})
+	if result.IsError || !strings.Contains(result.Text(), "Rex") || strings.Contains(result.Text(), "Berlin") {
+		t.Errorf("memory_search = %q, want the dog fact only", result.Text())
+	}
+
+	workspace := al.registry.GetDefaultAgent().Workspace
+	notes := filepath.Join(workspace, "notes.txt")
+	os.WriteFile(notes, []byte("hello"), 0o644)
+	read, _ := findServed(served, "read_file")
+	if result := read.Call(context.Background(), map[string]any{"path": notes}); result.Text() != "hello" {
+		t.Errorf("read_file = %q", result.Text())
+	}
+
+	if served := al.MCPServerTools([]string{"read_file"}); len(served) != 1 || served[0].Name != "read_file" {
+		t.Errorf("MCPServerTools(read_file) = %d tools, want read_file only", len(served))
+	}
+}
+
+func TestMCPServerTools_MemoryWithoutEmbeddings(t *testing.T) {
+	al, _ := newTestLoop(t, &memoryMockProvider{facts: ""}, withMemory(t, false))
+	agent := al.registry.GetDefaultAgent()
+	notes := "# Memory\n\n- Prefers green tea\n- Works night shifts\n"
+	if err := agent.ContextBuilder.memory.WriteLongTerm(notes); err != nil {
+		t.Fatal(err)
+	}
+
+	search, _ := findServed(al.MCPServerTools(nil), "memory_search")
+	ctx := context.Background()
+	if result := search.Call(ctx, map[string]any{"query": "Tea"}); result.Text() != "- Prefers green tea" {
+		t.Errorf("memory_search = %q", result.Text())
+	}
+	if result := search.Call(ctx, map[string]any{"query": "coffee"}); result.Text() != "Nothing found in memory." {
+		t.Errorf("memory_search = %q", result.Text())
+	}
+}
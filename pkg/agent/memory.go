@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
 )
 
 // MemoryStore manages persistent memory for the agent.
@@ -21,6 +23,7 @@ type MemoryStore struct {
 	workspace  string
 	memoryDir  string
 	memoryFile string
+	key        *encrypt.Key // Encrypts the files when set
 }
 
 // NewMemoryStore creates a new MemoryStore with the given workspace path.
@@ -50,7 +53,7 @@ func (ms *MemoryStore) getTodayFile() string {
 // ReadLongTerm reads the long-term memory (MEMORY.md).
 // Returns empty string if the file doesn't exist.
 func (ms *MemoryStore) ReadLongTerm() string {
-	if data, err := os.ReadFile(ms.memoryFile); err == nil {
+	if data, err := ms.key.ReadFile(ms.memoryFile); err == nil {
 		return string(data)
 	}
 	return ""
@@ -58,14 +61,14 @@ func (ms *MemoryStore) ReadLongTerm() string {
 
 // WriteLongTerm writes content to the long-term memory file (MEMORY.md).
 func (ms *MemoryStore) WriteLongTerm(content string) error {
-	return os.WriteFile(ms.memoryFile, []byte(content), 0o644)
+	return ms.key.WriteFile(ms.memoryFile, []byte(content), 0o644)
 }
 
 // ReadToday reads today's daily note.
 // Returns empty string if the file doesn't exist.
 func (ms *MemoryStore) ReadToday() string {
 	todayFile := ms.getTodayFile()
-	if data, err := os.ReadFile(todayFile); err == nil {
+	if data, err := ms.key.ReadFile(todayFile); err == nil {
 		return string(data)
 	}
 	return ""
@@ -81,7 +84,7 @@ func (ms *MemoryStore) AppendToday(content string) error {
 	os.MkdirAll(monthDir, 0o755)
 
 	var existingContent string
-	if data, err := os.ReadFile(todayFile); err == nil {
+	if data, err := ms.key.ReadFile(todayFile); err == nil {
 		existingContent = string(data)
 	}
 
@@ -95,7 +98,7 @@ func (ms *MemoryStore) AppendToday(content string) error {
 		newContent = existingContent + "\n" + content
 	}
 
-	return os.WriteFile(todayFile, []byte(newContent), 0o644)
+	return ms.key.WriteFile(todayFile, []byte(newContent), 0o644)
 }
 
 // GetRecentDailyNotes returns daily notes from the last N days.
@@ -110,7 +113,7 @@ func (ms *MemoryStore) GetRecentDailyNotes(days int) string {
 		monthDir := dateStr[:6]            // YYYYMM
 		filePath := filepath.Join(ms.memoryDir, monthDir, dateStr+".md")
 
-		if data, err := os.ReadFile(filePath); err == nil {
+		if data, err := ms.key.ReadFile(filePath); err == nil {
 			if !first {
 				sb.WriteString("\n\n---\n\n")
 			}
@@ -0,0 +1,135 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// reModelPrefix matches a message starting with "@<model_name>:".
+var reModelPrefix = regexp.MustCompile(`(?s)^\s*@([^\s:]+):\s*(\S.*)$`)
+
+// reAgentPrefix matches a message starting with "@<agent>", followed by a
+// space, comma or colon.
+var reAgentPrefix = regexp.MustCompile(`(?s)^\s*@([^\s:,]+)[\s:,]+(\S.*)$`)
+
+// splitAgentPrefix returns the agent a message addresses with a leading
+// "@<agent>", by ID or name, and the rest of the message. Other mentions,
+// including model names, are left alone.
+func (al *AgentLoop) splitAgentPrefix(content string) (string, string) {
+	m := reAgentPrefix.FindStringSubmatch(content)
+	if m == nil {
+		return "", content
+	}
+	id, ok := al.registry.FindAgent(m[1])
+	if !ok {
+		return "", content
+	}
+	return id, m[2]
+}
+
+// splitModelPrefix returns the model named by a leading "@<model_name>:"
+// and the rest of the message. Names not in model_list are left alone, so
+// "@alice: see above" is an ordinary message.
+func (al *AgentLoop) splitModelPrefix(content string) (string, string) {
+	m := reModelPrefix.FindStringSubmatch(content)
+	if m == nil || !al.hasModel(m[1]) {
+		return "", content
+	}
+	return m[1], m[2]
+}
+
+func (al *AgentLoop) hasModel(name string) bool {
+	for _, mc := range al.config().ModelList {
+		if mc.ModelName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// modelNames lists the model_list entries in config order, without the
+// duplicates used for load balancing.
+func (al *AgentLoop) modelNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, mc := range al.config().ModelList {
+		if mc.ModelName != "" && !seen[mc.ModelName] {
+			seen[mc.ModelName] = true
+			names = append(names, mc.ModelName)
+		}
+	}
+	return names
+}
+
+// namedModel returns the provider and model for the model_list entry name,
+// or the agent's own model if it can't be created.
+func (al *AgentLoop) namedModel(agent *AgentInstance, name string) (providers.LLMProvider, string) {
+	roles := al.modelRoles()
+	if roles == nil {
+		return agent.Provider, agent.Model
+	}
+	provider, model, err := roles.Model(name)
+	if err != nil {
+		logger.WarnCF("agent", "Model override unavailable, using the agent's model",
+			map[string]any{"model": name, "error": err.Error()})
+		return agent.Provider, agent.Model
+	}
+	return provider, model
+}
+
+// handleModelCommand handles /model, which shows or sets the model used for
+// the rest of a session:
+//
+//	/model            show the current model and the available ones
+//	/model <name>     use the model_list entry <name>
+//	/model default    go back to the agent's model
+func (al *AgentLoop) handleModelCommand(agent *AgentInstance, sessionKey, content string) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	if cmd, _, _ := strings.Cut(fields[0], "@"); cmd != "/model" {
+		return "", false
+	}
+
+	if len(fields) == 1 {
+		current := agent.Sessions.GetModel(sessionKey)
+		reply := fmt.Sprintf("Current model: %s (agent default)", agent.Model)
+		if current != "" {
+			reply = fmt.Sprintf("Current model: %s (set for this chat; /model default to reset)", current)
+		}
+		if names := al.modelNames(); len(names) > 0 {
+			reply += "\nAvailable: " + strings.Join(names, ", ")
+		}
+		return reply, true
+	}
+
+	name := fields[1]
+	switch name {
+	case "default", "reset":
+		agent.Sessions.SetModel(sessionKey, "")
+		agent.Sessions.Save(sessionKey)
+		return fmt.Sprintf("Model reset to the agent default (%s).", agent.Model), true
+	}
+
+	if !al.hasModel(name) {
+		return fmt.Sprintf("Unknown model %q. Available: %s", name, strings.Join(al.modelNames(), ", ")), true
+	}
+	if roles := al.modelRoles(); roles != nil {
+		if _, _, err := roles.Model(name); err != nil {
+			return fmt.Sprintf("Can't use model %q: %v", name, err), true
+		}
+	}
+	agent.Sessions.SetModel(sessionKey, name)
+	agent.Sessions.Save(sessionKey)
+	return fmt.Sprintf("This chat now uses %s. Send /model default to switch back.", name), true
+}
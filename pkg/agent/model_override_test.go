@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// withOtherModel adds a model named "other" that answers "from the other
+// model".
+func withOtherModel(t *testing.T) func(cfg *config.Config) {
+	script := filepath.Join(t.TempDir(), "other.json")
+	if err := os.WriteFile(script, []byte(`{"default": "from the other model"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return func(cfg *config.Config) {
+		cfg.ModelList = append(cfg.ModelList, config.ModelConfig{ModelName: "other", Model: "mock/" + script})
+	}
+}
+
+func TestProcessDirect_ModelPrefixOverridesOneMessage(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "from the chat model"}}
+	al, _ := newTestLoop(t, provider, withOtherModel(t))
+	ctx := context.Background()
+
+	response, err := al.ProcessDirect(ctx, "@other: what is 2+2?", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "from the other model" || len(provider.calls) != 0 {
+		t.Errorf("response = %q, chat model calls = %d", response, len(provider.calls))
+	}
+
+	// Unknown names are plain text, and the override doesn't stick.
+	for _, msg := range []string{"@alice: hi", "and now?"} {
+		if response, _ := al.ProcessDirect(ctx, msg, "test-session"); response != "from the chat model" {
+			t.Errorf("ProcessDirect(%q) = %q", msg, response)
+		}
+	}
+	if last := provider.calls[0]; last[len(last)-1].Content != "@alice: hi" {
+		t.Errorf("message = %q, want it unchanged", last[len(last)-1].Content)
+	}
+}
+
+func TestProcessDirect_ModelCommandPersistsPerSession(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "from the chat model"}}
+	al, _ := newTestLoop(t, provider, withOtherModel(t))
+	ctx := context.Background()
+
+	if reply, _ := al.ProcessDirect(ctx, "/model missing", "test-session"); !strings.Contains(reply, "Unknown model") {
+		t.Errorf("/model missing = %q", reply)
+	}
+	if reply, _ := al.ProcessDirect(ctx, "/model other", "test-session"); !strings.Contains(reply, "now uses other") {
+		t.Errorf("/model other = %q", reply)
+	}
+	if reply, _ := al.ProcessDirect(ctx, "/model", "test-session"); !strings.Contains(reply, "Current model: other") {
+		t.Errorf("/model = %q", reply)
+	}
+
+	for range 2 {
+		if response, _ := al.ProcessDirect(ctx, "hello", "test-session"); response != "from the other model" {
+			t.Errorf("response = %q, want the session model", response)
+		}
+	}
+	if response, _ := al.ProcessDirect(ctx, "hello", "agent:main:another-chat"); response != "from the chat model" {
+		t.Errorf("other chat response = %q, want the agent's model", response)
+	}
+
+	al.ProcessDirect(ctx, "/model default", "test-session")
+	if response, _ := al.ProcessDirect(ctx, "hello", "test-session"); response != "from the chat model" {
+		t.Errorf("response after reset = %q", response)
+	}
+	if len(provider.calls) != 2 {
+		t.Errorf("chat model calls = %d, want 2", len(provider.calls))
+	}
+}
+
+func TestProcessDirect_AgentPrefixRoutesToAgent(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true},
+				{ID: "coder", Name: "Coder", SystemPrompt: "You write Go."},
+			},
+		},
+	}
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "ok"}}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	ctx := context.Background()
+
+	for _, msg := range []string{"@coder fix the build", "hello"} {
+		if _, err := al.ProcessDirect(ctx, msg, "test-session"); err != nil {
+			t.Fatalf("ProcessDirect(%q) error = %v", msg, err)
+		}
+	}
+
+	coder, main := provider.calls[0], provider.calls[1]
+	if !strings.Contains(coder[0].Content, "You write Go.") {
+		t.Error("@coder message should use the coder's persona")
+	}
+	if got := coder[len(coder)-1].Content; got != "fix the build" {
+		t.Errorf("message = %q, want the mention stripped", got)
+	}
+	if strings.Contains(main[0].Content, "You write Go.") {
+		t.Error("other messages should go to the default agent")
+	}
+}
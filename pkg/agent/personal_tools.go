@@ -0,0 +1,78 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/email"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/notes"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tasks"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerPersonalTools registers the tools on the user's own data: their
+// calendar, mailbox, notes and to-do list.
+func registerPersonalTools(cfg *config.Config, agent *AgentInstance, roles *providers.ModelRoles) {
+	// The user's calendar
+	if cfg.Tools.Calendar.Enabled {
+		if calendarTool, err := newCalendarTool(cfg.Tools.Calendar); err != nil {
+			logger.WarnCF("agent", "Calendar tool not available", map[string]any{"error": err.Error()})
+		} else {
+			agent.Tools.Register(calendarTool)
+		}
+	}
+
+	// The user's mailbox
+	if em := cfg.Tools.Email; em.Enabled {
+		mailbox := email.NewClient(email.Account{
+			Address:  em.Address,
+			Username: em.Username,
+			Password: em.Password,
+			IMAPHost: em.IMAPHost,
+			IMAPPort: em.IMAPPort,
+			SMTPHost: em.SMTPHost,
+			SMTPPort: em.SMTPPort,
+		})
+		agent.Tools.Register(tools.NewEmailTool(mailbox, tools.EmailToolOptions{
+			Folders:   em.Folders,
+			ReadOnly:  em.ReadOnly,
+			Workspace: agent.Workspace,
+			Restrict:  cfg.Agents.Defaults.RestrictToWorkspace,
+		}))
+	}
+
+	// The knowledge base, searched by meaning when a model has the embed role
+	if n := cfg.Tools.Notes; n.Enabled {
+		var embed notes.EmbedFunc
+		if cfg.Agents.Defaults.ModelForRole(providers.RoleEmbed) != "" {
+			embed = func(ctx context.Context, inputs []string) ([][]float32, error) {
+				embedder, err := roles.Embeddings()
+				if err != nil {
+					return nil, err
+				}
+				return embedder.Embed(ctx, inputs)
+			}
+		}
+		dir := expandHome(n.Dir)
+		if dir == "" {
+			dir = "notes"
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(agent.Workspace, dir)
+		}
+		agent.Tools.Register(tools.NewNotesTool(notes.NewStore(dir, embed)))
+	}
+
+	// The user's to-do list; the gateway's heartbeat brings up overdue tasks
+	if cfg.Tools.Tasks.Enabled {
+		agent.Tools.Register(tools.NewTasksTool(tasks.NewStore(filepath.Join(agent.Workspace, "tasks.json"))))
+	}
+}
@@ -0,0 +1,402 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// Planning modes other than "off", see config.PlanningConfig.
+const (
+	planningAuto   = "auto"
+	planningAlways = "always"
+)
+
+const (
+	defaultMaxPlanSteps = 8
+
+	// planTTL is how long a plan waits for approval. Later messages are
+	// handled as usual.
+	planTTL = time.Hour
+
+	// maxStepResultChars bounds each earlier step's result in a later
+	// step's context.
+	maxStepResultChars = 2000
+)
+
+// planStep is one step of a plan, and once run, its outcome.
+type planStep struct {
+	Description string   `json:"description"`
+	Tools       []string `json:"tools"`
+	Status      string   `json:"-"` // "", "done" or "failed"
+	Result      string   `json:"-"`
+}
+
+// plan is a request broken into steps.
+type plan struct {
+	Request string
+	Steps   []planStep
+	created time.Time
+}
+
+var planSchema = providers.ResponseSchema{
+	Name:        "plan",
+	Description: "The steps to carry out the request, in order. No steps means the request doesn't need a plan.",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"steps": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"description": map[string]any{"type": "string"},
+						"tools":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+					"required": []any{"description", "tools"},
+				},
+			},
+		},
+		"required": []any{"steps"},
+	},
+}
+
+const plannerPrompt = `You plan how an assistant carries out a user's request. Break the request into a short list of steps, in order. Each step is a self-contained piece of work with a clear result, and lists the tools it needs (possibly none).
+Later steps see the results of earlier ones, so don't repeat work. The last step should produce what the final answer needs.`
+
+const plannerAutoPrompt = `
+If the request is simple enough to answer directly or needs at most one tool call, reply with no steps.`
+
+// pendingPlans holds plans waiting for approval, by session key. The zero
+// value is ready to use.
+type pendingPlans struct {
+	mu    sync.Mutex
+	plans map[string]*plan
+}
+
+func (p *pendingPlans) put(key string, pl *plan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.plans == nil {
+		p.plans = make(map[string]*plan)
+	}
+	p.plans[key] = pl
+}
+
+// take removes and returns the plan waiting in session key, if it hasn't
+// expired.
+func (p *pendingPlans) take(key string) *plan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pl := p.plans[key]
+	delete(p.plans, key)
+	if pl == nil || time.Since(pl.created) > planTTL {
+		return nil
+	}
+	return pl
+}
+
+// planReply is how a user answers a proposed plan.
+type planReply int
+
+const (
+	planRevise planReply = iota
+	planApprove
+	planCancel
+)
+
+func parsePlanReply(content string) planReply {
+	s := strings.ToLower(strings.Trim(strings.TrimSpace(content), ".!"))
+	switch s {
+	case "yes", "y", "ok", "okay", "sure", "go", "go ahead", "do it", "run it", "approve", "/plan approve":
+		return planApprove
+	case "no", "n", "cancel", "stop", "abort", "never mind", "/plan cancel":
+		return planCancel
+	}
+	return planRevise
+}
+
+// maybePlan handles a message with a plan when planning applies: a
+// message starting with /plan, any message in "always" mode, a request the
+// model decides needs one in "auto" mode, or the answer to a proposed plan.
+// It reports whether it handled the message.
+func (al *AgentLoop) maybePlan(ctx context.Context, agent *AgentInstance, opts processOptions) (string, bool, error) {
+	cfg := al.config().Agents.Defaults.Planning
+
+	if pending := al.plans.take(opts.SessionKey); pending != nil {
+		switch parsePlanReply(opts.UserMessage) {
+		case planApprove:
+			response, err := al.executePlan(ctx, agent, pending, opts)
+			return response, true, err
+		case planCancel:
+			response := "OK, I dropped the plan."
+			al.recordTurn(agent, opts.SessionKey, opts.UserMessage, response)
+			return response, true, nil
+		default:
+			revised, err := al.makePlan(ctx, agent, pending.Request, pending, opts.UserMessage, opts.Model, true)
+			if err != nil {
+				return "", true, err
+			}
+			return al.proposePlan(agent, revised, opts), true, nil
+		}
+	}
+
+	request, explicit := strings.CutPrefix(strings.TrimSpace(opts.UserMessage), "/plan")
+	if explicit && strings.TrimLeft(request, " \t\n") == request && request != "" {
+		explicit = false // e.g. "/planets"
+	}
+	request = strings.TrimSpace(request)
+	switch {
+	case explicit && request == "":
+		return "Usage: /plan <request>", true, nil
+	case explicit:
+	case (cfg.Mode == planningAlways || cfg.Mode == planningAuto) && len(opts.Images) == 0:
+		request = opts.UserMessage
+	default:
+		return "", false, nil
+	}
+
+	force := explicit || cfg.Mode == planningAlways
+	p, err := al.makePlan(ctx, agent, request, nil, "", opts.Model, force)
+	if err != nil {
+		if force {
+			return "", true, err
+		}
+		logger.WarnCF("agent", "Planning failed, answering directly",
+			map[string]any{"agent_id": agent.ID, "error": err.Error()})
+		return "", false, nil
+	}
+	if len(p.Steps) < 2 && !force {
+		return "", false, nil
+	}
+
+	logger.InfoCF("agent", "Planned request",
+		map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey, "steps": len(p.Steps)})
+	if cfg.RequireApproval {
+		return al.proposePlan(agent, p, opts), true, nil
+	}
+	response, err := al.executePlan(ctx, agent, p, opts)
+	return response, true, err
+}
+
+// makePlan asks the model for a plan for request. With previous, it revises
+// that plan according to the user's feedback. Unless force is set, the
+// model may decide the request needs no plan and return no steps.
+func (al *AgentLoop) makePlan(
+	ctx context.Context,
+	agent *AgentInstance,
+	request string,
+	previous *plan,
+	feedback, modelName string,
+	force bool,
+) (*plan, error) {
+	prompt := plannerPrompt
+	if !force {
+		prompt += plannerAutoPrompt
+	}
+	prompt += "\n\nAvailable tools:\n" + strings.Join(agent.Tools.GetSummaries(), "\n")
+
+	var user strings.Builder
+	fmt.Fprintf(&user, "Request:\n%s\n", request)
+	if previous != nil {
+		fmt.Fprintf(&user, "\nYour previous plan:\n%s\nThe user wants changes:\n%s\n",
+			formatPlanSteps(previous), feedback)
+	}
+
+	provider, model := agent.Provider, agent.Model
+	if modelName != "" {
+		provider, model = al.namedModel(agent, modelName)
+	}
+	var out struct {
+		Steps []planStep `json:"steps"`
+	}
+	err := providers.ChatJSON(ctx, provider, []providers.Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: user.String()},
+	}, model, planSchema, map[string]any{"max_tokens": 2048, "temperature": 0.2}, &out)
+	if err != nil {
+		return nil, fmt.Errorf("planning: %w", err)
+	}
+
+	p := &plan{Request: request, created: time.Now()}
+	maxSteps := al.config().Agents.Defaults.Planning.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxPlanSteps
+	}
+	known := agent.Tools.List()
+	for _, step := range out.Steps {
+		if strings.TrimSpace(step.Description) == "" {
+			continue
+		}
+		step.Tools = slices.DeleteFunc(step.Tools, func(name string) bool {
+			return !slices.Contains(known, name)
+		})
+		p.Steps = append(p.Steps, step)
+		if len(p.Steps) == maxSteps {
+			break
+		}
+	}
+	if force && len(p.Steps) == 0 {
+		return nil, errors.New("planning: the model returned no steps")
+	}
+	return p, nil
+}
+
+// proposePlan shows p to the user and keeps it until they answer.
+func (al *AgentLoop) proposePlan(agent *AgentInstance, p *plan, opts processOptions) string {
+	al.plans.put(opts.SessionKey, p)
+	response := "Here's my plan:\n" + formatPlanSteps(p) +
+		"\nReply \"yes\" to run it, \"no\" to drop it, or tell me what to change."
+	al.recordTurn(agent, opts.SessionKey, opts.UserMessage, response)
+	return response
+}
+
+// executePlan runs each step of p in its own tool loop, which sees the
+// request, the plan and the results so far, and has only the step's tools.
+// A final call writes the answer from the results.
+func (al *AgentLoop) executePlan(
+	ctx context.Context,
+	agent *AgentInstance,
+	p *plan,
+	opts processOptions,
+) (string, error) {
+	ctx, done := al.startTurn(ctx, opts.Channel, opts.ChatID)
+	defer done()
+	al.updateToolContexts(agent, opts.Channel, opts.ChatID)
+	approve := al.approverFor(agent, opts)
+	if u := opts.UserConfig; u != nil && len(u.Tools) > 0 {
+		approve = restrictTools(u.Tools, "to this user", approve)
+	}
+	if approve != nil {
+		ctx = tools.WithApprover(ctx, approve)
+	}
+
+	provider, model := agent.Provider, agent.Model
+	if opts.Model != "" {
+		provider, model = al.namedModel(agent, opts.Model)
+	}
+	llmOpts := map[string]any{"max_tokens": agent.MaxTokens, "temperature": agent.Temperature}
+
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		toolset := tools.NewToolRegistry()
+		for _, name := range step.Tools {
+			if tool, ok := agent.Tools.Get(name); ok {
+				toolset.Register(tool)
+			}
+		}
+
+		result, err := tools.RunToolLoop(ctx, tools.ToolLoopConfig{
+			Provider:         provider,
+			Model:            model,
+			Tools:            toolset,
+			MaxIterations:    agent.MaxIterations,
+			LLMOptions:       llmOpts,
+			MaxParallelTools: agent.MaxParallel,
+		}, stepMessages(p, i), opts.Channel, opts.ChatID)
+		if err != nil {
+			if errors.Is(context.Cause(ctx), ErrStopped) {
+				al.recordTurn(agent, opts.SessionKey, opts.UserMessage, stopNote(ctx))
+				return "", ErrStopped
+			}
+			step.Status, step.Result = "failed", err.Error()
+			logger.WarnCF("agent", "Plan step failed",
+				map[string]any{"agent_id": agent.ID, "step": i + 1, "error": err.Error()})
+			continue
+		}
+		step.Status, step.Result = "done", strings.TrimSpace(result.Content)
+		logger.InfoCF("agent", "Plan step done",
+			map[string]any{"agent_id": agent.ID, "step": i + 1, "iterations": result.Iterations})
+	}
+
+	response, err := provider.Chat(ctx, []providers.Message{
+		{Role: "system", Content: agent.ContextBuilder.BuildSystemPrompt()},
+		{Role: "user", Content: fmt.Sprintf(
+			"%s\n\n[The steps below were carried out for this request.]\n%s\n"+
+				"Reply to the request using these results. Mention any step that failed.",
+			p.Request, formatPlanResults(p, 0))},
+	}, nil, model, llmOpts)
+	if err != nil {
+		if errors.Is(context.Cause(ctx), ErrStopped) {
+			al.recordTurn(agent, opts.SessionKey, opts.UserMessage, stopNote(ctx))
+			return "", ErrStopped
+		}
+		return "", err
+	}
+
+	content := response.Content
+	if content == "" {
+		content = formatPlanResults(p, 0)
+	}
+	al.recordTurn(agent, opts.SessionKey, opts.UserMessage, content)
+	return content, nil
+}
+
+// stepMessages returns the context of step i: only the request, the plan
+// and the earlier steps' results, not the conversation.
+func stepMessages(p *plan, i int) []providers.Message {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "You are carrying out one step of a plan for this request:\n%s\n\nPlan:\n%s",
+		p.Request, formatPlanSteps(p))
+	if i > 0 {
+		sb.WriteString("\nResults so far:\n")
+		sb.WriteString(formatPlanResults(p, maxStepResultChars))
+	}
+	sb.WriteString("\nDo only your step, then reply with its result: the facts, data or " +
+		"file paths later steps need.")
+	return []providers.Message{
+		{Role: "system", Content: sb.String()},
+		{Role: "user", Content: fmt.Sprintf("Step %d: %s", i+1, p.Steps[i].Description)},
+	}
+}
+
+// formatPlanSteps lists the steps and their tools.
+func formatPlanSteps(p *plan) string {
+	var sb strings.Builder
+	for i, step := range p.Steps {
+		fmt.Fprintf(&sb, "%d. %s", i+1, step.Description)
+		if len(step.Tools) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(step.Tools, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatPlanResults lists the outcome of the steps run so far, each
+// truncated to limit characters when limit > 0.
+func formatPlanResults(p *plan, limit int) string {
+	var sb strings.Builder
+	for i, step := range p.Steps {
+		if step.Status == "" {
+			continue
+		}
+		result := step.Result
+		if limit > 0 {
+			result = utils.Truncate(result, limit)
+		}
+		fmt.Fprintf(&sb, "Step %d (%s): %s\n%s\n\n", i+1, step.Status, step.Description, result)
+	}
+	return sb.String()
+}
+
+// recordTurn saves a user message and the reply to the session.
+func (al *AgentLoop) recordTurn(agent *AgentInstance, sessionKey, userMessage, reply string) {
+	agent.Sessions.AddMessage(sessionKey, "user", userMessage)
+	agent.Sessions.AddMessage(sessionKey, "assistant", reply)
+	agent.Sessions.Save(sessionKey)
+}
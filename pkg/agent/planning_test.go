@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// planningMockProvider answers planner, step and final calls differently,
+// recording the system prompt and user message of each.
+type planningMockProvider struct {
+	plan  string // JSON reply to planner calls
+	calls []providers.Message
+	users []string
+}
+
+func (m *planningMockProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	system, user := messages[0], messages[len(messages)-1]
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			user = msg
+		}
+	}
+	m.calls = append(m.calls, system)
+	m.users = append(m.users, user.Content)
+	switch {
+	case strings.HasPrefix(system.Content, "You plan how"):
+		return &providers.LLMResponse{Content: m.plan}, nil
+	case strings.HasPrefix(system.Content, "You are carrying out one step"):
+		return &providers.LLMResponse{Content: "result of " + user.Content}, nil
+	default:
+		return &providers.LLMResponse{Content: "final answer"}, nil
+	}
+}
+
+func (m *planningMockProvider) GetDefaultModel() string { return "mock-model" }
+
+// count returns how many calls had a system prompt starting with prefix.
+func (m *planningMockProvider) count(prefix string) int {
+	n := 0
+	for _, c := range m.calls {
+		if strings.HasPrefix(c.Content, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+const twoStepPlan = `{"steps": [
+	{"description": "Look up A", "tools": ["web_fetch", "no_such_tool"]},
+	{"description": "Compare A with B", "tools": []}
+]}`
+
+func TestProcessDirect_PlanCommandRunsSteps(t *testing.T) {
+	provider := &planningMockProvider{plan: twoStepPlan}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Planning = config.PlanningConfig{}
+	})
+
+	response, err := al.ProcessDirect(context.Background(), "/plan compare A and B", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "final answer" {
+		t.Errorf("response = %q", response)
+	}
+	if n := provider.count("You are carrying out one step"); n != 2 {
+		t.Fatalf("step calls = %d, want 2", n)
+	}
+
+	// The second step sees the first one's result, and the final call all of them.
+	second := provider.calls[2].Content
+	if !strings.Contains(second, "result of Step 1: Look up A") {
+		t.Errorf("step 2 context is missing step 1's result:\n%s", second)
+	}
+	if strings.Contains(second, "no_such_tool") {
+		t.Error("unknown tools should be dropped from the plan")
+	}
+	if final := provider.users[3]; !strings.HasPrefix(final, "compare A and B") ||
+		!strings.Contains(final, "result of Step 2: Compare A with B") {
+		t.Errorf("final call = %q", final)
+	}
+
+	agent := al.registry.GetDefaultAgent()
+	history := agent.Sessions.GetHistory("agent:main:main")
+	if len(history) != 2 || history[0].Content != "/plan compare A and B" || history[1].Content != "final answer" {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestProcessDirect_PlanApproval(t *testing.T) {
+	provider := &planningMockProvider{plan: twoStepPlan}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Planning = config.PlanningConfig{Mode: "always", RequireApproval: true}
+	})
+	ctx := context.Background()
+
+	response, _ := al.ProcessDirect(ctx, "compare A and B", "test-session")
+	if !strings.HasPrefix(response, "Here's my plan:\n1. Look up A (web_fetch)\n2. Compare A with B\n") {
+		t.Errorf("proposal = %q", response)
+	}
+	if provider.count("You are carrying out one step") != 0 {
+		t.Fatal("steps ran before approval")
+	}
+
+	response, _ = al.ProcessDirect(ctx, "also look up B", "test-session")
+	if !strings.HasPrefix(response, "Here's my plan:") {
+		t.Errorf("revised proposal = %q", response)
+	}
+	if last := provider.users[len(provider.users)-1]; !strings.Contains(last, "The user wants changes:\nalso look up B") {
+		t.Errorf("revision request = %q", last)
+	}
+
+	if response, _ = al.ProcessDirect(ctx, "Yes!", "test-session"); response != "final answer" {
+		t.Errorf("response after approval = %q", response)
+	}
+	if n := provider.count("You are carrying out one step"); n != 2 {
+		t.Errorf("step calls = %d, want 2", n)
+	}
+
+	// The plan is used up, so "no" is a new request; then it drops the new plan.
+	provider.plan = `{"steps": [{"description": "Say hi", "tools": []}]}`
+	if response, _ = al.ProcessDirect(ctx, "no", "test-session"); !strings.HasPrefix(response, "Here's my plan:") {
+		t.Errorf("response = %q, want a new proposal", response)
+	}
+	if response, _ = al.ProcessDirect(ctx, "no", "test-session"); response != "OK, I dropped the plan." {
+		t.Errorf("response = %q", response)
+	}
+}
+
+func TestProcessDirect_AutoPlanningSkipsSimpleRequests(t *testing.T) {
+	provider := &planningMockProvider{plan: `{"steps": []}`}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Planning = config.PlanningConfig{Mode: "auto"}
+	})
+
+	response, err := al.ProcessDirect(context.Background(), "hi there", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "final answer" || provider.count("You plan how") != 1 ||
+		provider.count("You are carrying out one step") != 0 {
+		t.Errorf("response = %q, calls = %d", response, len(provider.calls))
+	}
+	if !strings.Contains(provider.calls[0].Content, "reply with no steps") {
+		t.Error("auto mode should let the planner skip planning")
+	}
+}
+
+func TestParsePlanReply(t *testing.T) {
+	for content, want := range map[string]planReply{
+		"yes":              planApprove,
+		" Go ahead. ":      planApprove,
+		"/plan approve":    planApprove,
+		"No":               planCancel,
+		"cancel!":          planCancel,
+		"yes, but skip 2":  planRevise,
+		"add a third step": planRevise,
+	} {
+		if got := parsePlanReply(content); got != want {
+			t.Errorf("parsePlanReply(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
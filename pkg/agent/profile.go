@@ -0,0 +1,299 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+// Profile holds what a user told the agent about themselves with /prefs.
+// It is added to the system prompt of their requests.
+type Profile struct {
+	Name     string    `json:"name,omitempty"`
+	Timezone string    `json:"timezone,omitempty"` // IANA name, e.g. Europe/Paris
+	Locale   string    `json:"locale,omitempty"`   // e.g. en-GB
+	Units    string    `json:"units,omitempty"`    // metric or imperial
+	Style    string    `json:"style,omitempty"`    // How they like answers, e.g. "short, no emoji"
+	Updated  time.Time `json:"updated"`
+}
+
+// profileFields are the fields /prefs sets, in display order.
+var profileFields = []string{"name", "timezone", "locale", "units", "style"}
+
+var reLocale = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+const maxProfileValue = 200
+
+// set validates value and stores it in field. An empty value clears it.
+func (p *Profile) set(field, value string) error {
+	value = strings.TrimSpace(value)
+	if len(value) > maxProfileValue {
+		return fmt.Errorf("%s is too long (at most %d characters)", field, maxProfileValue)
+	}
+	switch field {
+	case "name":
+		p.Name = value
+	case "timezone":
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil || strings.EqualFold(value, "local") {
+				return fmt.Errorf("unknown time zone %q; use a name like Europe/Paris or America/New_York", value)
+			}
+		}
+		p.Timezone = value
+	case "locale":
+		if value != "" && !reLocale.MatchString(value) {
+			return fmt.Errorf("invalid locale %q; use a language tag like en-GB", value)
+		}
+		p.Locale = value
+	case "units":
+		value = strings.ToLower(value)
+		if value != "" && value != "metric" && value != "imperial" {
+			return fmt.Errorf("units must be metric or imperial")
+		}
+		p.Units = value
+	case "style":
+		p.Style = value
+	default:
+		return fmt.Errorf("unknown preference %q; use one of %s", field, strings.Join(profileFields, ", "))
+	}
+	return nil
+}
+
+func (p Profile) isEmpty() bool {
+	return p.Name == "" && p.Timezone == "" && p.Locale == "" && p.Units == "" && p.Style == ""
+}
+
+// lines returns the set fields as "Label: value" lines.
+func (p Profile) lines(now time.Time) []string {
+	var lines []string
+	if p.Name != "" {
+		lines = append(lines, "Name: "+p.Name)
+	}
+	if p.Timezone != "" {
+		line := "Time zone: " + p.Timezone
+		if loc, err := time.LoadLocation(p.Timezone); err == nil {
+			line += " (local time " + now.In(loc).Format("Monday, 2 January 2006 15:04") + ")"
+		}
+		lines = append(lines, line)
+	}
+	if p.Locale != "" {
+		lines = append(lines, "Locale: "+p.Locale)
+	}
+	if p.Units != "" {
+		lines = append(lines, "Units: "+p.Units)
+	}
+	if p.Style != "" {
+		lines = append(lines, "Answer style: "+p.Style)
+	}
+	return lines
+}
+
+// promptSection formats the profile for the system prompt, or returns ""
+// when it is empty.
+func (p Profile) promptSection(now time.Time) string {
+	if p.isEmpty() {
+		return ""
+	}
+	return "## User Profile\nThe user's saved preferences. Follow them and don't ask for them again; " +
+		"the user changes them with /prefs.\n- " + strings.Join(p.lines(now), "\n- ")
+}
+
+// ProfileStore keeps the users' profiles in memory/profiles.json in the
+// workspace, by profile key (see profileKey).
+type ProfileStore struct {
+	path string
+	key  *encrypt.Key // Encrypts the file when set
+
+	mu       sync.Mutex
+	profiles map[string]Profile // nil until loaded
+}
+
+// NewProfileStore returns the profile store of workspace. The file is read
+// on first use.
+func NewProfileStore(workspace string, key *encrypt.Key) *ProfileStore {
+	return &ProfileStore{path: filepath.Join(workspace, "memory", "profiles.json"), key: key}
+}
+
+// load reads the file if it hasn't been read yet. s.mu must be held.
+func (s *ProfileStore) load() error {
+	if s.profiles != nil {
+		return nil
+	}
+	data, err := s.key.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.profiles = make(map[string]Profile)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	profiles := make(map[string]Profile)
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return fmt.Errorf("read %s: %w", s.path, err)
+	}
+	s.profiles = profiles
+	return nil
+}
+
+// Get returns the profile of user, which is empty when they haven't set
+// any preferences.
+func (s *ProfileStore) Get(user string) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Profile{}, err
+	}
+	return s.profiles[user], nil
+}
+
+// Set changes one field of user's profile and saves it. An empty value
+// clears the field.
+func (s *ProfileStore) Set(user, field, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	p := s.profiles[user]
+	if err := p.set(field, value); err != nil {
+		return err
+	}
+	p.Updated = time.Now()
+	if p.isEmpty() {
+		delete(s.profiles, user)
+	} else {
+		s.profiles[user] = p
+	}
+	return s.save()
+}
+
+// Reset deletes user's profile.
+func (s *ProfileStore) Reset(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.profiles[user]; !ok {
+		return nil
+	}
+	delete(s.profiles, user)
+	return s.save()
+}
+
+// save writes the profiles. s.mu must be held.
+func (s *ProfileStore) save() error {
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := s.key.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// profileKey identifies the user a message is from: the sender on its
+// channel, or the chat when there is no real sender (scheduled jobs and
+// heartbeats), which in a private chat is the same user.
+func profileKey(channel, senderID, chatID string) string {
+	id, _, _ := strings.Cut(senderID, "|") // Telegram adds "|username"
+	if id == "" || id == "cron" {
+		id = chatID
+	}
+	return channel + ":" + id
+}
+
+// userProfile returns the profile section of the system prompt for user,
+// or "" when they have none.
+func userProfile(agent *AgentInstance, user string) string {
+	if agent.Profiles == nil || user == "" {
+		return ""
+	}
+	p, err := agent.Profiles.Get(user)
+	if err != nil {
+		return ""
+	}
+	return p.promptSection(time.Now())
+}
+
+// handlePrefsCommand handles /prefs, which shows and changes the profile
+// of the user the message is from:
+//
+//	/prefs                        show the profile
+//	/prefs set <field> <value>    set name, timezone, locale, units or style
+//	/prefs unset <field>          clear a field
+//	/prefs reset                  delete the whole profile
+func (al *AgentLoop) handlePrefsCommand(agent *AgentInstance, user, content string) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	if cmd, _, _ := strings.Cut(fields[0], "@"); cmd != "/prefs" {
+		return "", false
+	}
+	if agent.Profiles == nil || user == "" {
+		return "Preferences aren't available here.", true
+	}
+
+	usage := "Usage: /prefs | /prefs set <field> <value> | /prefs unset <field> | /prefs reset\n" +
+		"Fields: " + strings.Join(profileFields, ", ") + ". Example: /prefs set timezone Europe/Paris"
+	if len(fields) == 1 {
+		p, err := agent.Profiles.Get(user)
+		if err != nil {
+			return fmt.Sprintf("Can't read your preferences: %v", err), true
+		}
+		if p.isEmpty() {
+			return "You haven't set any preferences.\n" + usage, true
+		}
+		return "Your preferences:\n" + strings.Join(p.lines(time.Now()), "\n"), true
+	}
+
+	switch fields[1] {
+	case "set":
+		if len(fields) < 4 {
+			return usage, true
+		}
+		// The value is the rest of the line, spaces included.
+		value := strings.TrimSpace(content)
+		for _, f := range fields[:3] {
+			value = strings.TrimSpace(strings.TrimPrefix(value, f))
+		}
+		if err := agent.Profiles.Set(user, strings.ToLower(fields[2]), value); err != nil {
+			return fmt.Sprintf("Can't save that: %v", err), true
+		}
+		return fmt.Sprintf("Saved your %s.", strings.ToLower(fields[2])), true
+	case "unset":
+		if len(fields) != 3 {
+			return usage, true
+		}
+		if err := agent.Profiles.Set(user, strings.ToLower(fields[2]), ""); err != nil {
+			return fmt.Sprintf("Can't change that: %v", err), true
+		}
+		return fmt.Sprintf("Cleared your %s.", strings.ToLower(fields[2])), true
+	case "reset":
+		if err := agent.Profiles.Reset(user); err != nil {
+			return fmt.Sprintf("Can't delete your preferences: %v", err), true
+		}
+		return "Deleted your preferences.", true
+	}
+	return usage, true
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+func TestProfileStore_SetAndReload(t *testing.T) {
+	workspace := t.TempDir()
+	key, err := encrypt.ParseKey("secret passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewProfileStore(workspace, key)
+	if err := store.Set("telegram:1", "timezone", "Europe/Paris"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("telegram:1", "units", "Metric"); err != nil {
+		t.Fatal(err)
+	}
+	for field, value := range map[string]string{
+		"timezone": "Mars/Olympus",
+		"units":    "furlongs",
+		"locale":   "not a locale",
+		"shoe":     "42",
+	} {
+		if err := store.Set("telegram:1", field, value); err == nil {
+			t.Errorf("Set(%s, %q) should fail", field, value)
+		}
+	}
+
+	reloaded, err := NewProfileStore(workspace, key).Get("telegram:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Timezone != "Europe/Paris" || reloaded.Units != "metric" {
+		t.Errorf("reloaded profile = %+v", reloaded)
+	}
+	if _, err := NewProfileStore(workspace, nil).Get("telegram:1"); err == nil {
+		t.Error("read an encrypted profile file without the key")
+	}
+
+	if err := store.Set("telegram:1", "timezone", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("telegram:1", "units", ""); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := store.Get("telegram:1"); !p.isEmpty() {
+		t.Errorf("profile after clearing every field = %+v", p)
+	}
+}
+
+func TestProfile_PromptSection(t *testing.T) {
+	p := Profile{Name: "Ada", Timezone: "Asia/Tokyo", Style: "short"}
+	now := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+	got := p.promptSection(now)
+	for _, want := range []string{
+		"## User Profile",
+		"- Name: Ada",
+		"- Time zone: Asia/Tokyo (local time Tuesday, 3 March 2026 08:30)",
+		"- Answer style: short",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt section lacks %q:\n%s", want, got)
+		}
+	}
+	if (Profile{}).promptSection(now) != "" {
+		t.Error("empty profile has a prompt section")
+	}
+}
+
+func TestPrefsCommand(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "ok"}}
+	al, _ := newTestLoop(t, provider, withToolLimits(2, 0))
+	al.RegisterTool(&countingTool{})
+	ctx := context.Background()
+	send := func(sender, content string) string {
+		t.Helper()
+		reply, err := al.processMessage(ctx, bus.InboundMessage{
+			Channel: "telegram", SenderID: sender, ChatID: "100", Content: content,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply
+	}
+
+	if got := send("7|ada", "/prefs set style short answers, no emoji"); got != "Saved your style." {
+		t.Errorf("set reply = %q", got)
+	}
+	if got := send("7|ada", "/prefs set timezone Nowhere/City"); !strings.Contains(got, "unknown time zone") {
+		t.Errorf("invalid set reply = %q", got)
+	}
+	if got := send("7", "/prefs"); got != "Your preferences:\nAnswer style: short answers, no emoji" {
+		t.Errorf("show reply = %q", got)
+	}
+	if got := send("8", "/prefs"); !strings.HasPrefix(got, "You haven't set any preferences.") {
+		t.Errorf("other user's prefs = %q", got)
+	}
+
+	send("7|ada", "hello")
+	system := provider.calls[len(provider.calls)-1][0].Content
+	if !strings.Contains(system, "## User Profile") || !strings.Contains(system, "- Answer style: short answers, no emoji") {
+		t.Errorf("system prompt lacks the profile:\n%s", system)
+	}
+
+	if got := send("7", "/prefs reset"); got != "Deleted your preferences." {
+		t.Errorf("reset reply = %q", got)
+	}
+	send("7", "hello again")
+	if system := provider.calls[len(provider.calls)-1][0].Content; strings.Contains(system, "## User Profile") {
+		t.Error("profile still in the system prompt after reset")
+	}
+}
+
+func TestProfileKey(t *testing.T) {
+	tests := []struct{ channel, sender, chat, want string }{
+		{"telegram", "7|ada", "-100", "telegram:7"},
+		{"telegram", "cron", "7", "telegram:7"},
+		{"discord", "", "42", "discord:42"},
+	}
+	for _, tt := range tests {
+		if got := profileKey(tt.channel, tt.sender, tt.chat); got != tt.want {
+			t.Errorf("profileKey(%q, %q, %q) = %q, want %q", tt.channel, tt.sender, tt.chat, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// systemTemplateFile is the workspace file holding the system prompt
+// template. SYSTEM.<channel>.md, when present, is used for that channel
+// instead.
+const systemTemplateFile = "SYSTEM.md"
+
+// parsedTemplate is a template file as parsed at its modification time.
+type parsedTemplate struct {
+	modTime time.Time
+	tmpl    *template.Template // nil when the file doesn't parse
+}
+
+// promptData is what a system prompt template can use. The methods read
+// files, so they run only when the template uses them.
+type promptData struct {
+	Time      time.Time // e.g. {{.Time.Format "Monday, 2 January 15:04"}}
+	Hostname  string
+	Runtime   string // OS, architecture and Go version
+	Workspace string
+	Channel   string
+	ChatID    string
+	Persona   string // The agent's system_prompt
+	Default   string // The built-in system prompt, for templates that add to it
+
+	cb *ContextBuilder
+}
+
+// User returns USER.md, the user's profile.
+func (d promptData) User() string {
+	data, _ := os.ReadFile(filepath.Join(d.cb.workspace, "USER.md"))
+	return strings.TrimSpace(string(data))
+}
+
+// Tools returns the names of the tools the agent can call.
+func (d promptData) Tools() []string {
+	if d.cb.tools == nil {
+		return nil
+	}
+	return d.cb.tools.List()
+}
+
+// Memory returns MEMORY.md and the recent daily notes.
+func (d promptData) Memory() string {
+	return d.cb.memory.GetMemoryContext()
+}
+
+// Skills returns the summary of the installed skills.
+func (d promptData) Skills() string {
+	return d.cb.skillsLoader.BuildSkillsSummary()
+}
+
+// SkillDocs returns the full documentation of the agent's skills (the
+// skills of its agents.list entry).
+func (d promptData) SkillDocs() string {
+	return d.cb.skillsLoader.LoadSkillsForContext(d.cb.skills)
+}
+
+// systemTemplate returns the system prompt template for channel, or nil
+// when the workspace has none or it doesn't parse.
+func (cb *ContextBuilder) systemTemplate(channel string) *template.Template {
+	names := []string{systemTemplateFile}
+	if channel != "" && !strings.ContainsAny(channel, `/\.`) {
+		names = []string{"SYSTEM." + channel + ".md", systemTemplateFile}
+	}
+	for _, name := range names {
+		path := filepath.Join(cb.workspace, name)
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil
+		}
+
+		cb.templatesMu.Lock()
+		cached, ok := cb.templates[path]
+		cb.templatesMu.Unlock()
+		if ok && cached.modTime.Equal(info.ModTime()) {
+			return cached.tmpl
+		}
+
+		cached = parsedTemplate{modTime: info.ModTime()}
+		if data, err := os.ReadFile(path); err != nil {
+			logger.WarnCF("agent", "Can't read system prompt template, using the default prompt",
+				map[string]any{"path": path, "error": err.Error()})
+		} else if cached.tmpl, err = cb.parseTemplate(name, string(data)); err != nil {
+			logger.WarnCF("agent", "Invalid system prompt template, using the default prompt",
+				map[string]any{"path": path, "error": err.Error()})
+		}
+		cb.templatesMu.Lock()
+		if cb.templates == nil {
+			cb.templates = make(map[string]parsedTemplate)
+		}
+		cb.templates[path] = cached
+		cb.templatesMu.Unlock()
+		return cached.tmpl
+	}
+	return nil
+}
+
+func (cb *ContextBuilder) parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"skill": func(name string) string {
+			content, _ := cb.skillsLoader.LoadSkill(name)
+			return content
+		},
+	}).Parse(text)
+}
+
+// renderSystemTemplate renders the system prompt template for channel, if
+// the workspace has one, with defaultPrompt as .Default. It reports false
+// when the default prompt should be used instead.
+func (cb *ContextBuilder) renderSystemTemplate(defaultPrompt, channel, chatID string) (string, bool) {
+	tmpl := cb.systemTemplate(channel)
+	if tmpl == nil {
+		return "", false
+	}
+	hostname, _ := os.Hostname()
+	workspace, _ := filepath.Abs(cb.workspace)
+	data := promptData{
+		Time:      time.Now(),
+		Hostname:  hostname,
+		Runtime:   fmt.Sprintf("%s %s, Go %s", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		Workspace: workspace,
+		Channel:   channel,
+		ChatID:    chatID,
+		Persona:   cb.persona,
+		Default:   defaultPrompt,
+		cb:        cb,
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		logger.WarnCF("agent", "System prompt template failed, using the default prompt",
+			map[string]any{"template": tmpl.Name(), "error": err.Error()})
+		return "", false
+	}
+	return strings.TrimSpace(out.String()), true
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+func writeWorkspaceFile(t *testing.T, workspace, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(workspace, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildMessages_SystemTemplate(t *testing.T) {
+	workspace := t.TempDir()
+	cb := NewContextBuilder(workspace)
+	cb.SetPersona("Be brief.")
+	registry := tools.NewToolRegistry()
+	registry.Register(&countingTool{})
+	cb.SetTools(registry)
+	writeWorkspaceFile(t, workspace, "USER.md", "Name: Ada\n")
+	writeWorkspaceFile(t, workspace, "SYSTEM.md",
+		"You help {{.User}} on {{.Channel}}/{{.ChatID}}. {{.Persona}}\n"+
+			"Tools: {{join .Tools \", \"}}. Year: {{.Time.Year}}.\n")
+
+	system := cb.BuildMessages(nil, "", "hi", nil, "telegram", "42")[0]
+	want := "You help Name: Ada on telegram/42. Be brief.\nTools: counting. Year: " +
+		time.Now().Format("2006") + "."
+	if system.Content != want {
+		t.Errorf("system prompt = %q, want %q", system.Content, want)
+	}
+	if len(system.SystemParts) != 1 || system.SystemParts[0].Text != want {
+		t.Errorf("system parts = %+v", system.SystemParts)
+	}
+}
+
+func TestBuildMessages_SystemTemplatePerChannel(t *testing.T) {
+	workspace := t.TempDir()
+	cb := NewContextBuilder(workspace)
+	writeWorkspaceFile(t, workspace, "SYSTEM.md", "general")
+	writeWorkspaceFile(t, workspace, "SYSTEM.telegram.md", "for telegram\n\n{{.Default}}")
+
+	if got := cb.BuildMessages(nil, "", "hi", nil, "discord", "1")[0].Content; got != "general" {
+		t.Errorf("discord prompt = %q", got)
+	}
+	got := cb.BuildMessages(nil, "", "hi", nil, "telegram", "1")[0].Content
+	if !strings.HasPrefix(got, "for telegram\n\n# picoclaw") {
+		t.Errorf("telegram prompt = %q", utils.Truncate(got, 200))
+	}
+
+	// Edits are picked up.
+	later := time.Now().Add(time.Second)
+	writeWorkspaceFile(t, workspace, "SYSTEM.md", "edited")
+	os.Chtimes(filepath.Join(workspace, "SYSTEM.md"), later, later)
+	if got := cb.BuildMessages(nil, "", "hi", nil, "discord", "1")[0].Content; got != "edited" {
+		t.Errorf("prompt after edit = %q", got)
+	}
+}
+
+func TestBuildMessages_BrokenTemplateUsesDefault(t *testing.T) {
+	for _, text := range []string{"{{.User", "{{.NoSuchField}}"} {
+		workspace := t.TempDir()
+		cb := NewContextBuilder(workspace)
+		writeWorkspaceFile(t, workspace, "SYSTEM.md", text)
+
+		got := cb.BuildMessages(nil, "", "hi", nil, "cli", "direct")[0].Content
+		if !strings.HasPrefix(got, "# picoclaw") || !strings.Contains(got, "## Current Time") {
+			t.Errorf("template %q gave %q", text, utils.Truncate(got, 200))
+		}
+	}
+}
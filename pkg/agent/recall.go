@@ -0,0 +1,290 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultRecallTopK     = 5
+	defaultRecallMinScore = 0.35
+
+	// rememberWindow is how many recent messages fact extraction reads, so
+	// a turn skipped while the previous extraction ran is still covered.
+	rememberWindow = 6
+
+	rememberTimeout = 2 * time.Minute
+)
+
+var factsSchema = providers.ResponseSchema{
+	Name:        "facts",
+	Description: "Facts worth remembering in later conversations; an empty list when there are none.",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"facts": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required": []any{"facts"},
+	},
+}
+
+const rememberPrompt = `You maintain an assistant's long-term memory. From the conversation below, extract facts worth remembering in future conversations: the user's preferences, personal details, plans, decisions and ongoing projects.
+Write each fact as one short, self-contained sentence in the third person ("The user's dog is called Rex"). Leave out small talk, one-off questions, things only relevant right now, and anything the assistant said that the user didn't confirm.
+Most conversations contain no such facts; then return an empty list.`
+
+// recall returns the facts in facts relevant to message, formatted for the
+// system prompt, or "" when there are none or semantic memory is off.
+func (al *AgentLoop) recall(ctx context.Context, facts *memory.Store, message string) string {
+	if facts == nil || facts.Len() == 0 || strings.TrimSpace(message) == "" {
+		return ""
+	}
+	embedder, err := al.modelRoles().Embeddings()
+	if err != nil {
+		return ""
+	}
+	vectors, err := embedder.Embed(ctx, []string{message})
+	if err != nil || len(vectors) != 1 {
+		logger.WarnCF("agent", "Can't embed message for memory recall", map[string]any{"error": fmt.Sprint(err)})
+		return ""
+	}
+
+	cfg := al.config().Agents.Defaults.Memory
+	topK, minScore := cfg.TopK, float32(cfg.MinScore)
+	if topK <= 0 {
+		topK = defaultRecallTopK
+	}
+	if minScore <= 0 {
+		minScore = defaultRecallMinScore
+	}
+	found := facts.Search(vectors[0], topK, minScore)
+	if len(found) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Relevant Memories\nFacts remembered from earlier conversations that may bear on this message:\n")
+	for _, f := range found {
+		fmt.Fprintf(&sb, "- %s (%s)\n", f.Text, f.Created.Format(time.DateOnly))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// addRecalled appends recalled memories to the system message built by
+// ContextBuilder.BuildMessages, after its cached part.
+func addRecalled(messages []providers.Message, recalled string) []providers.Message {
+	if recalled == "" || len(messages) == 0 || messages[0].Role != "system" {
+		return messages
+	}
+	system := messages[0]
+	system.Content += "\n\n---\n\n" + recalled
+	system.SystemParts = append(append([]providers.ContentBlock(nil), system.SystemParts...),
+		providers.ContentBlock{Type: "text", Text: recalled})
+	messages[0] = system
+	return messages
+}
+
+// joinSections joins system prompt sections, leaving out empty ones.
+func joinSections(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n\n---\n\n" + b
+}
+
+// maybeRemember extracts facts from the end of the session in the
+// background and stores them in facts, unless an extraction for the session
+// is still running.
+func (al *AgentLoop) maybeRemember(agent *AgentInstance, facts *memory.Store, sessionKey string) {
+	if facts == nil {
+		return
+	}
+	key := agent.ID + ":" + sessionKey
+	if _, busy := al.remembering.LoadOrStore(key, true); busy {
+		return
+	}
+	go func() {
+		defer al.remembering.Delete(key)
+		ctx, cancel := context.WithTimeout(context.Background(), rememberTimeout)
+		defer cancel()
+		if err := al.remember(ctx, agent, facts, sessionKey); err != nil {
+			logger.WarnCF("agent", "Memory extraction failed",
+				map[string]any{"agent_id": agent.ID, "session_key": sessionKey, "error": err.Error()})
+		}
+	}()
+}
+
+func (al *AgentLoop) remember(ctx context.Context, agent *AgentInstance, store *memory.Store, sessionKey string) error {
+	embedder, err := al.modelRoles().Embeddings()
+	if err != nil {
+		return err
+	}
+
+	// The last rememberWindow messages with text, leaving out tool calls.
+	history := agent.Sessions.GetHistory(sessionKey)
+	var recent []providers.Message
+	for i := len(history) - 1; i >= 0 && len(recent) < rememberWindow; i-- {
+		m := history[i]
+		if (m.Role == "user" || m.Role == "assistant") && strings.TrimSpace(m.Content) != "" {
+			recent = append(recent, m)
+		}
+	}
+	if len(recent) == 0 {
+		return nil
+	}
+	var transcript strings.Builder
+	for i := len(recent) - 1; i >= 0; i-- {
+		fmt.Fprintf(&transcript, "%s: %s\n", recent[i].Role, recent[i].Content)
+	}
+
+	provider, model := al.modelFor(agent, providers.RoleCheap)
+	var out struct {
+		Facts []string `json:"facts"`
+	}
+	err = providers.ChatJSON(ctx, provider, []providers.Message{
+		{Role: "system", Content: rememberPrompt},
+		{Role: "user", Content: "CONVERSATION:\n" + transcript.String()},
+	}, model, factsSchema, map[string]any{"max_tokens": 1024, "temperature": 0.2}, &out)
+	if err != nil {
+		return err
+	}
+
+	var facts []string
+	for _, f := range out.Facts {
+		if f = strings.TrimSpace(f); f != "" {
+			facts = append(facts, f)
+		}
+	}
+	if len(facts) == 0 {
+		return nil
+	}
+	vectors, err := embedder.Embed(ctx, facts)
+	if err != nil {
+		return err
+	}
+	added := 0
+	for i, f := range facts {
+		if i >= len(vectors) {
+			break
+		}
+		ok, err := store.Add(f, vectors[i], sessionKey)
+		if err != nil {
+			return err
+		}
+		if ok {
+			added++
+		}
+	}
+	logger.InfoCF("agent", "Updated long-term memory",
+		map[string]any{"agent_id": agent.ID, "facts": len(facts), "new": added})
+	return nil
+}
+
+// handleMemoryCommand handles /memory, which shows and deletes the facts
+// in facts, the sender's store of agent:
+//
+//	/memory list              list the facts with their numbers
+//	/memory forget <n> [...]  delete facts by number
+//	/memory forget all        delete every fact
+//	/memory consolidate       merge related facts and drop outdated ones now
+func (al *AgentLoop) handleMemoryCommand(
+	ctx context.Context,
+	agent *AgentInstance,
+	facts *memory.Store,
+	content string,
+) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	if cmd, _, _ := strings.Cut(fields[0], "@"); cmd != "/memory" {
+		return "", false
+	}
+	if facts == nil {
+		return "Long-term memory is off. Set agents.defaults.memory.enabled and assign an \"embed\" model role to turn it on.", true
+	}
+
+	usage := "Usage: /memory list | /memory forget <number>... | /memory forget all | /memory consolidate"
+	if len(fields) < 2 {
+		return usage, true
+	}
+	switch fields[1] {
+	case "list":
+		list := facts.List()
+		if len(list) == 0 {
+			return "I don't remember anything yet.", true
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "I remember %d facts:\n", len(list))
+		for _, f := range list {
+			fmt.Fprintf(&sb, "#%d %s (%s)\n", f.ID, f.Text, f.Created.Format(time.DateOnly))
+		}
+		sb.WriteString("Use /memory forget <number> to delete one.")
+		return sb.String(), true
+
+	case "forget":
+		if len(fields) < 3 {
+			return usage, true
+		}
+		if len(fields) == 3 && fields[2] == "all" {
+			n, err := facts.Clear()
+			if err != nil {
+				return fmt.Sprintf("Can't clear memory: %v", err), true
+			}
+			return fmt.Sprintf("Forgot all %d facts.", n), true
+		}
+		var forgotten, missing []string
+		for _, arg := range fields[2:] {
+			id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+			if err != nil {
+				return usage, true
+			}
+			ok, err := facts.Forget(id)
+			if err != nil {
+				return fmt.Sprintf("Can't forget #%d: %v", id, err), true
+			}
+			if ok {
+				forgotten = append(forgotten, "#"+strconv.FormatInt(id, 10))
+			} else {
+				missing = append(missing, "#"+strconv.FormatInt(id, 10))
+			}
+		}
+		var reply []string
+		if len(forgotten) > 0 {
+			reply = append(reply, "Forgot "+strings.Join(forgotten, ", ")+".")
+		}
+		if len(missing) > 0 {
+			reply = append(reply, "No such fact: "+strings.Join(missing, ", ")+".")
+		}
+		return strings.Join(reply, " "), true
+
+	case "consolidate":
+		summary, err := al.consolidateMemory(ctx, agent, facts)
+		if err != nil {
+			return fmt.Sprintf("Can't consolidate memory: %v", err), true
+		}
+		return "Consolidated memory: " + summary, true
+	}
+	return usage, true
+}
+
+// Embed embeds inputs with the model of the embed role, for services
+// outside the loop such as feed topic filters.
+func (al *AgentLoop) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	embedder, err := al.modelRoles().Embeddings()
+	if err != nil {
+		return nil, err
+	}
+	return embedder.Embed(ctx, inputs)
+}
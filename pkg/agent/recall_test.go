@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// memoryMockProvider answers fact extraction and consolidation with canned
+// JSON and records the system prompt of every other call.
+type memoryMockProvider struct {
+	facts        string // JSON reply to extraction calls
+	consolidated string // JSON reply to consolidation calls
+
+	mu             sync.Mutex
+	systems        []string
+	consolidations int
+}
+
+func (m *memoryMockProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	if strings.HasPrefix(messages[0].Content, "You maintain an assistant's long-term memory") {
+		return &providers.LLMResponse{Content: m.facts}, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if strings.HasPrefix(messages[0].Content, "You tidy up") {
+		m.consolidations++
+		return &providers.LLMResponse{Content: m.consolidated}, nil
+	}
+	m.systems = append(m.systems, messages[0].Content)
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (m *memoryMockProvider) GetDefaultModel() string { return "mock-model" }
+
+// newEmbeddingServer serves OpenAI-compatible embeddings that count a few
+// keywords, so texts about the same thing are similar.
+func newEmbeddingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	vocab := []string{"dog", "berlin", "tea"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i, input := range req.Input {
+			vector := make([]float32, len(vocab))
+			for j, word := range vocab {
+				vector[j] = float32(strings.Count(strings.ToLower(input), word))
+			}
+			data = append(data, item{Index: i, Embedding: vector})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// withMemory sets agents.defaults.memory, with embeddings from a test
+// server.
+func withMemory(t *testing.T, enabled bool) func(cfg *config.Config) {
+	srv := newEmbeddingServer(t)
+	return func(cfg *config.Config) {
+		cfg.Agents.Defaults.ModelRoles = map[string]string{"embed": "embedder"}
+		cfg.Agents.Defaults.Memory = config.MemoryConfig{Enabled: enabled}
+		cfg.ModelList = append(cfg.ModelList,
+			config.ModelConfig{ModelName: "embedder", Model: "ollama/nomic-embed-text", APIBase: srv.URL})
+	}
+}
+
+// waitRemembered waits for background fact extraction to finish.
+func waitRemembered(t *testing.T, al *AgentLoop) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		busy := false
+		al.remembering.Range(func(_, _ any) bool {
+			busy = true
+			return false
+		})
+		if !busy {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("fact extraction did not finish")
+}
+
+func TestProcessDirect_RemembersAndRecallsFacts(t *testing.T) {
+	provider := &memoryMockProvider{facts: `{"facts": ["The user's dog is called Rex", " "]}`}
+	al, _ := newTestLoop(t, provider, withMemory(t, true))
+	ctx := context.Background()
+	agent := al.registry.GetDefaultAgent()
+	if agent.Facts == nil {
+		t.Fatal("memory is enabled but the agent has no fact store")
+	}
+
+	if _, err := al.ProcessDirect(ctx, "My dog is called Rex", "test-session"); err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	waitRemembered(t, al)
+	facts := agent.Facts.List()
+	if len(facts) != 1 || facts[0].Text != "The user's dog is called Rex" || facts[0].Source != "agent:main:main" {
+		t.Fatalf("facts = %+v", facts)
+	}
+
+	if _, err := al.ProcessDirect(ctx, "What should my dog eat?", "test-session"); err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	waitRemembered(t, al)
+	if agent.Facts.Len() != 1 {
+		t.Errorf("restated fact was stored twice: %+v", agent.Facts.List())
+	}
+	if system := provider.systems[1]; !strings.Contains(system, "## Relevant Memories") ||
+		!strings.Contains(system, "- The user's dog is called Rex (") {
+		t.Errorf("system prompt is missing the recalled fact:\n%s", system)
+	}
+
+	if _, err := al.ProcessDirect(ctx, "Is tea healthy?", "test-session"); err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	waitRemembered(t, al)
+	if strings.Contains(provider.systems[2], "Relevant Memories") {
+		t.Error("unrelated facts should not be recalled")
+	}
+}
+
+func TestHandleMemoryCommand(t *testing.T) {
+	al, _ := newTestLoop(t, &memoryMockProvider{facts: `{"facts": []}`}, withMemory(t, true))
+	agent := al.registry.GetDefaultAgent()
+	ctx := context.Background()
+
+	if reply, _ := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memory list"); reply != "I don't remember anything yet." {
+		t.Errorf("empty list = %q", reply)
+	}
+	agent.Facts.Add("The user lives in Berlin", []float32{0, 1, 0}, "s")
+	agent.Facts.Add("The user prefers tea", []float32{0, 0, 1}, "s")
+
+	reply, handled := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memory list")
+	if !handled || !strings.Contains(reply, "I remember 2 facts:\n#1 The user lives in Berlin (") ||
+		!strings.Contains(reply, "#2 The user prefers tea (") {
+		t.Errorf("list = %q", reply)
+	}
+
+	if reply, _ := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memory forget #1 7"); reply != "Forgot #1. No such fact: #7." {
+		t.Errorf("forget = %q", reply)
+	}
+	if reply, _ := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memory forget one"); !strings.HasPrefix(reply, "Usage:") {
+		t.Errorf("forget with a bad number = %q", reply)
+	}
+	if reply, _ := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memory forget all"); reply != "Forgot all 1 facts." || agent.Facts.Len() != 0 {
+		t.Errorf("forget all = %q", reply)
+	}
+	if _, handled := al.handleMemoryCommand(ctx, agent, agent.Facts, "/memoryless"); handled {
+		t.Error("other commands should not be handled")
+	}
+}
+
+func TestHandleMemoryCommand_Disabled(t *testing.T) {
+	al, _ := newTestLoop(t, &memoryMockProvider{facts: ""}, withMemory(t, false))
+	response, err := al.ProcessDirect(context.Background(), "/memory list", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if !strings.HasPrefix(response, "Long-term memory is off.") {
+		t.Errorf("response = %q", response)
+	}
+}
+
+func TestAddRecalled(t *testing.T) {
+	messages := []providers.Message{
+		{Role: "system", Content: "base", SystemParts: []providers.ContentBlock{{Type: "text", Text: "base"}}},
+		{Role: "user", Content: "hi"},
+	}
+	if got := addRecalled(messages, ""); got[0].Content != "base" {
+		t.Errorf("nothing recalled changed the prompt: %q", got[0].Content)
+	}
+	got := addRecalled(messages, "## Relevant Memories\n- x")
+	if got[0].Content != "base\n\n---\n\n## Relevant Memories\n- x" {
+		t.Errorf("Content = %q", got[0].Content)
+	}
+	if len(got[0].SystemParts) != 2 || got[0].SystemParts[1].Text != "## Relevant Memories\n- x" {
+		t.Errorf("SystemParts = %+v", got[0].SystemParts)
+	}
+}
@@ -65,7 +65,31 @@ func (r *AgentRegistry) GetAgent(agentID string) (*AgentInstance, bool) {
 
 // ResolveRoute determines which agent handles the message.
 func (r *AgentRegistry) ResolveRoute(input routing.RouteInput) routing.ResolvedRoute {
-	return r.resolver.ResolveRoute(input)
+	r.mu.RLock()
+	resolver := r.resolver
+	r.mu.RUnlock()
+	return resolver.ResolveRoute(input)
+}
+
+// FindAgent returns the ID of the agent named name, by ID or name.
+func (r *AgentRegistry) FindAgent(name string) (string, bool) {
+	r.mu.RLock()
+	resolver := r.resolver
+	r.mu.RUnlock()
+	return resolver.FindAgent(name)
+}
+
+// replace swaps in the agents and routes of next. Callers holding an
+// instance from before keep using it until they look it up again.
+func (r *AgentRegistry) replace(next *AgentRegistry) {
+	next.mu.RLock()
+	agents, resolver := next.agents, next.resolver
+	next.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agents = agents
+	r.resolver = resolver
 }
 
 // ListAgentIDs returns all registered agent IDs.
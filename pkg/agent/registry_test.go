@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -97,6 +98,59 @@ func TestAgentRegistry_GetAgent_Normalize(t *testing.T) {
 	}
 }
 
+func TestAgentRegistry_FindAgent(t *testing.T) {
+	cfg := testCfg([]config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "reviewer", Name: "Code Reviewer"},
+	})
+	registry := NewAgentRegistry(cfg, &mockRegistryProvider{})
+
+	for name, want := range map[string]string{
+		"reviewer":      "reviewer",
+		"Reviewer":      "reviewer",
+		"code-reviewer": "reviewer",
+		"Code Reviewer": "reviewer",
+		"main":          "main",
+	} {
+		if id, ok := registry.FindAgent(name); !ok || id != want {
+			t.Errorf("FindAgent(%q) = %q, %v; want %q", name, id, ok, want)
+		}
+	}
+	for _, name := range []string{"", "nobody"} {
+		if id, ok := registry.FindAgent(name); ok {
+			t.Errorf("FindAgent(%q) = %q, want no match", name, id)
+		}
+	}
+}
+
+func TestNewAgentInstance_PersonaAndTools(t *testing.T) {
+	cfg := testCfg([]config.AgentConfig{
+		{
+			ID:           "researcher",
+			Default:      true,
+			SystemPrompt: "You research topics and cite sources.",
+			Tools:        []string{"web_fetch", "read_file"},
+		},
+	})
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	registry := NewAgentRegistry(cfg, &mockRegistryProvider{})
+	agent, _ := registry.GetAgent("researcher")
+
+	if _, ok := agent.Tools.Get("read_file"); !ok {
+		t.Error("read_file should be registered")
+	}
+	for _, name := range []string{"exec", "write_file", "edit_file"} {
+		if _, ok := agent.Tools.Get(name); ok {
+			t.Errorf("%s should not be registered", name)
+		}
+	}
+
+	prompt := agent.ContextBuilder.BuildSystemPrompt()
+	if !strings.Contains(prompt, "# Persona\n\nYou research topics and cite sources.") {
+		t.Errorf("system prompt is missing the persona:\n%s", prompt)
+	}
+}
+
 func TestAgentRegistry_GetDefaultAgent(t *testing.T) {
 	cfg := testCfg([]config.AgentConfig{
 		{ID: "alpha"},
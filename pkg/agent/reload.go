@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"reflect"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// config returns the configuration currently in effect.
+func (al *AgentLoop) config() *config.Config {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.cfg
+}
+
+// modelRoles returns the model role providers for the current configuration.
+func (al *AgentLoop) modelRoles() *providers.ModelRoles {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.roles
+}
+
+// ReloadConfig applies a changed configuration without restarting: agents
+// are rebuilt with the new model settings and tools, and provider serves
+// their default model. MCP servers are reconnected when their settings
+// changed, and the external tools directory is read again. Agents whose
+// workspace and storage encryption key are unchanged keep their sessions,
+// and tools registered with RegisterTool carry over; the tools of the
+// previous configuration don't. Turns already running finish with the
+// previous settings.
+func (al *AgentLoop) ReloadConfig(cfg *config.Config, provider providers.LLMProvider) {
+	registry := NewAgentRegistry(cfg, provider)
+	roles := providers.NewModelRoles(cfg)
+	registerSharedTools(cfg, al.bus, registry, provider, roles, al.usage)
+
+	al.mu.RLock()
+	servers, registered := al.mcp, al.registered
+	al.mu.RUnlock()
+	var staleServers *mcpServers
+	if !reflect.DeepEqual(servers.config, cfg.Tools.MCP.Servers) {
+		staleServers = servers
+		servers = connectMCPServers(cfg.Tools.MCP.Servers)
+	}
+	servers.register(registry)
+	registerExternalTools(cfg.Tools.External, registry)
+	for _, id := range registry.ListAgentIDs() {
+		agent, _ := registry.GetAgent(id)
+		for _, tool := range registered {
+			agent.Tools.Register(tool)
+		}
+	}
+
+	sameKey := al.config().Storage.EncryptionKey == cfg.Storage.EncryptionKey
+	for _, id := range registry.ListAgentIDs() {
+		next, _ := registry.GetAgent(id)
+		if prev, ok := al.registry.GetAgent(id); ok && prev.Workspace == next.Workspace && sameKey {
+			next.Sessions = prev.Sessions
+			if prev.Facts != nil && next.Facts != nil {
+				next.Facts.Close()
+				next.Facts = prev.Facts
+				next.UserFacts.close()
+				next.UserFacts = prev.UserFacts
+			}
+			if prev.Persona == next.Persona {
+				next.ContextBuilder = prev.ContextBuilder
+			}
+		}
+	}
+
+	al.mu.Lock()
+	prevRoles := al.roles
+	al.cfg = cfg
+	al.roles = roles
+	al.mcp = servers
+	al.mu.Unlock()
+	al.registry.replace(registry)
+
+	if prevRoles != nil {
+		prevRoles.Close()
+	}
+	if staleServers != nil {
+		staleServers.close()
+	}
+	logger.InfoCF("agent", "Configuration reloaded", map[string]any{
+		"agents": len(registry.ListAgentIDs()),
+	})
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestReloadConfig_KeepsSessionsAndTools(t *testing.T) {
+	workspace := t.TempDir()
+	newConfig := func(maxTokens int) *config.Config {
+		return &config.Config{
+			Agents: config.AgentsConfig{
+				Defaults: config.AgentDefaults{
+					Workspace:         workspace,
+					Model:             "test-model",
+					MaxTokens:         maxTokens,
+					MaxToolIterations: 10,
+				},
+			},
+		}
+	}
+
+	al := NewAgentLoop(newConfig(1024), bus.NewMessageBus(), &simpleMockProvider{response: "before"})
+	al.RegisterTool(&mockCustomTool{})
+	if _, err := al.ProcessDirect(context.Background(), "hello", "test-session"); err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+
+	al.ReloadConfig(newConfig(2048), &simpleMockProvider{response: "after"})
+
+	agent := al.registry.GetDefaultAgent()
+	if agent.MaxTokens != 2048 {
+		t.Errorf("MaxTokens = %d after reload, want 2048", agent.MaxTokens)
+	}
+	if _, ok := agent.Tools.Get("mock_custom"); !ok {
+		t.Error("registered tool lost on reload")
+	}
+	if al.config().Agents.Defaults.MaxTokens != 2048 {
+		t.Error("config not replaced")
+	}
+
+	response, err := al.ProcessDirect(context.Background(), "again", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	if response != "after" {
+		t.Errorf("response = %q, want the new provider's", response)
+	}
+	if n := len(agent.Sessions.GetHistory("agent:main:main")); n != 4 {
+		t.Errorf("history has %d messages after reload, want 4", n)
+	}
+}
+
+func TestReloadConfig_DropsDisabledTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the external tool is a shell script")
+	}
+	toolsDir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1\" = describe ] && echo '{\"name\": \"greet\"}' && exit\necho hi\n"
+	if err := os.WriteFile(filepath.Join(toolsDir, "greet"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	newConfig := func(enabled bool) *config.Config {
+		cfg := &config.Config{
+			Agents: config.AgentsConfig{
+				Defaults: config.AgentDefaults{
+					Workspace:         t.TempDir(),
+					Model:             "test-model",
+					MaxTokens:         1024,
+					MaxToolIterations: 10,
+				},
+			},
+		}
+		cfg.Tools.SysInfo.Enabled = enabled
+		cfg.Tools.External = config.ExternalToolsConfig{Enabled: enabled, Dir: toolsDir}
+		return cfg
+	}
+
+	al := NewAgentLoop(newConfig(true), bus.NewMessageBus(), &simpleMockProvider{response: "ok"})
+	al.RegisterTool(&mockCustomTool{})
+	for _, name := range []string{"sysinfo", "greet"} {
+		if _, ok := al.registry.GetDefaultAgent().Tools.Get(name); !ok {
+			t.Fatalf("%s not registered while enabled", name)
+		}
+	}
+
+	al.ReloadConfig(newConfig(false), &simpleMockProvider{response: "ok"})
+
+	agent := al.registry.GetDefaultAgent()
+	for _, name := range []string{"sysinfo", "greet"} {
+		if _, ok := agent.Tools.Get(name); ok {
+			t.Errorf("%s still registered after it was disabled", name)
+		}
+	}
+	if _, ok := agent.Tools.Get("mock_custom"); !ok {
+		t.Error("tool added with RegisterTool lost on reload")
+	}
+}
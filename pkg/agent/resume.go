@@ -0,0 +1,141 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// handleSessionCommand handles /reset and /resume, which put a session's
+// conversation aside and bring it back, and /checkpoint:
+//
+//	/reset                      start a new conversation, archiving the current one
+//	/resume                     list the archived conversations
+//	/resume <id>                continue an archived conversation
+//	/checkpoint save <name>     save the conversation so far
+//	/checkpoint list            list the checkpoints
+//	/checkpoint branch <name>   continue from a copy of a checkpoint
+//	/checkpoint delete <name>   delete a checkpoint
+func (al *AgentLoop) handleSessionCommand(agent *AgentInstance, sessionKey, content string) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	switch cmd {
+	case "/reset":
+		al.plans.take(sessionKey)
+		id, err := agent.Sessions.Reset(sessionKey)
+		if err != nil {
+			logger.WarnCF("agent", "Can't archive conversation",
+				map[string]any{"session_key": sessionKey, "error": err.Error()})
+			return fmt.Sprintf("Can't start a new conversation: %v", err), true
+		}
+		if id == "" {
+			return "Started a new conversation.", true
+		}
+		return fmt.Sprintf("Started a new conversation. Send /resume %s to go back to the previous one.", id), true
+
+	case "/resume":
+		if len(fields) < 2 {
+			archived := agent.Sessions.ListArchived(sessionKey)
+			if len(archived) == 0 {
+				return "There are no earlier conversations to resume.", true
+			}
+			var sb strings.Builder
+			sb.WriteString("Earlier conversations:\n")
+			for _, a := range archived {
+				fmt.Fprintf(&sb, "%s (%d messages) %s\n", a.ID, a.Messages,
+					utils.Truncate(strings.Join(strings.Fields(a.Preview), " "), 60))
+			}
+			sb.WriteString("Send /resume <id> to continue one.")
+			return sb.String(), true
+		}
+
+		al.plans.take(sessionKey)
+		previous, err := agent.Sessions.Resume(sessionKey, fields[1])
+		if errors.Is(err, session.ErrNotArchived) {
+			return fmt.Sprintf("There's no conversation %q. Send /resume to list them.", fields[1]), true
+		}
+		if err != nil {
+			return fmt.Sprintf("Can't resume conversation %s: %v", fields[1], err), true
+		}
+		reply := fmt.Sprintf("Resumed conversation %s.", fields[1])
+		if previous != "" {
+			reply += fmt.Sprintf(" The one you left is saved as %s.", previous)
+		}
+		return reply, true
+
+	case "/checkpoint":
+		return al.handleCheckpointCommand(agent, sessionKey, fields[1:]), true
+	}
+	return "", false
+}
+
+func (al *AgentLoop) handleCheckpointCommand(agent *AgentInstance, sessionKey string, args []string) string {
+	usage := "Usage: /checkpoint save <name> | /checkpoint list | /checkpoint branch <name> | /checkpoint delete <name>"
+	if len(args) == 1 && args[0] == "list" {
+		checkpoints := agent.Sessions.ListCheckpoints(sessionKey)
+		if len(checkpoints) == 0 {
+			return "There are no checkpoints. Send /checkpoint save <name> to make one."
+		}
+		var sb strings.Builder
+		sb.WriteString("Checkpoints:\n")
+		for _, c := range checkpoints {
+			fmt.Fprintf(&sb, "%s (%d messages, %s)\n", c.ID, c.Messages, c.Updated.Format("2006-01-02 15:04"))
+		}
+		sb.WriteString("Send /checkpoint branch <name> to continue from one.")
+		return sb.String()
+	}
+	if len(args) != 2 {
+		return usage
+	}
+
+	name := args[1]
+	switch args[0] {
+	case "save":
+		replaced, err := agent.Sessions.SaveCheckpoint(sessionKey, name)
+		if err != nil {
+			return fmt.Sprintf("Can't save checkpoint: %v", err)
+		}
+		if replaced {
+			return fmt.Sprintf("Checkpoint %s updated.", name)
+		}
+		return fmt.Sprintf("Saved checkpoint %s. Send /checkpoint branch %s to come back to this point.", name, name)
+
+	case "branch":
+		al.plans.take(sessionKey)
+		previous, err := agent.Sessions.Branch(sessionKey, name)
+		if errors.Is(err, session.ErrNoCheckpoint) {
+			return fmt.Sprintf("There's no checkpoint %q. Send /checkpoint list to see them.", name)
+		}
+		if err != nil {
+			return fmt.Sprintf("Can't branch from %s: %v", name, err)
+		}
+		reply := fmt.Sprintf("Continuing from checkpoint %s, which stays as it is.", name)
+		if previous != "" {
+			reply += fmt.Sprintf(" The conversation you left is saved as %s (see /resume).", previous)
+		}
+		return reply
+
+	case "delete":
+		err := agent.Sessions.DeleteCheckpoint(sessionKey, name)
+		if errors.Is(err, session.ErrNoCheckpoint) {
+			return fmt.Sprintf("There's no checkpoint %q.", name)
+		}
+		if err != nil {
+			return fmt.Sprintf("Can't delete checkpoint %s: %v", name, err)
+		}
+		return fmt.Sprintf("Deleted checkpoint %s.", name)
+	}
+	return usage
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProcessDirect_ResetAndResume(t *testing.T) {
+	al, _ := newTestLoop(t, &simpleMockProvider{response: "ok"}, nil)
+	ctx := context.Background()
+	history := func() int {
+		return len(al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main"))
+	}
+
+	if response, _ := al.ProcessDirect(ctx, "/resume", "s"); response != "There are no earlier conversations to resume." {
+		t.Errorf("/resume with nothing archived = %q", response)
+	}
+	al.ProcessDirect(ctx, "remember the milk", "s")
+
+	response, _ := al.ProcessDirect(ctx, "/reset", "s")
+	id, ok := strings.CutPrefix(response, "Started a new conversation. Send /resume ")
+	if !ok {
+		t.Fatalf("/reset = %q", response)
+	}
+	id = strings.TrimSuffix(id, " to go back to the previous one.")
+	if history() != 0 {
+		t.Errorf("history after /reset = %d messages", history())
+	}
+
+	al.ProcessDirect(ctx, "hello again", "s")
+	response, _ = al.ProcessDirect(ctx, "/resume", "s")
+	if !strings.Contains(response, id+" (2 messages) remember the milk\n") {
+		t.Errorf("/resume list = %q", response)
+	}
+
+	response, _ = al.ProcessDirect(ctx, "/resume "+id, "s")
+	if !strings.HasPrefix(response, "Resumed conversation "+id+". The one you left is saved as ") {
+		t.Errorf("/resume %s = %q", id, response)
+	}
+	if h := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main"); len(h) != 2 || h[0].Content != "remember the milk" {
+		t.Errorf("history after /resume = %+v", h)
+	}
+	if response, _ := al.ProcessDirect(ctx, "/resume nope", "s"); !strings.HasPrefix(response, `There's no conversation "nope".`) {
+		t.Errorf("/resume nope = %q", response)
+	}
+}
+
+func TestProcessDirect_Checkpoints(t *testing.T) {
+	al, _ := newTestLoop(t, &simpleMockProvider{response: "ok"}, nil)
+	ctx := context.Background()
+
+	al.ProcessDirect(ctx, "refactor the cache", "s")
+	if response, _ := al.ProcessDirect(ctx, "/checkpoint save x", "s"); !strings.HasPrefix(response, "Saved checkpoint x.") {
+		t.Errorf("/checkpoint save = %q", response)
+	}
+	al.ProcessDirect(ctx, "try an LRU", "s")
+
+	response, _ := al.ProcessDirect(ctx, "/checkpoint branch x", "s")
+	if !strings.HasPrefix(response, "Continuing from checkpoint x, which stays as it is. The conversation you left is saved as ") {
+		t.Errorf("/checkpoint branch = %q", response)
+	}
+	if h := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main"); len(h) != 2 {
+		t.Errorf("history after branching = %+v", h)
+	}
+	if response, _ := al.ProcessDirect(ctx, "/checkpoint list", "s"); !strings.Contains(response, "x (2 messages, ") {
+		t.Errorf("/checkpoint list = %q", response)
+	}
+	if response, _ := al.ProcessDirect(ctx, "/checkpoint branch y", "s"); !strings.HasPrefix(response, `There's no checkpoint "y".`) {
+		t.Errorf("/checkpoint branch y = %q", response)
+	}
+	if response, _ := al.ProcessDirect(ctx, "/checkpoint", "s"); !strings.HasPrefix(response, "Usage:") {
+		t.Errorf("/checkpoint = %q", response)
+	}
+}
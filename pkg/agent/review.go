@@ -0,0 +1,109 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+var reviewSchema = providers.ResponseSchema{
+	Name:        "review",
+	Description: "Whether the draft answer can be sent as it is, and if not, what is wrong with it.",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"approved": map[string]any{"type": "boolean"},
+			"problems": map[string]any{"type": "string"},
+		},
+		"required": []any{"approved", "problems"},
+	},
+}
+
+const reviewerPrompt = `You review an assistant's draft answer before it is sent to the user. Check it against the user's request: does it answer what was asked, completely and correctly, without made-up facts, in the form the user asked for?
+Approve answers that are good enough; wording and style are not problems. When you don't approve, list the problems briefly and concretely, so the assistant can fix them.`
+
+// reviewApplies reports whether answers in this turn go through agent's
+// review pass. Heartbeats and background task reports are never reviewed.
+func reviewApplies(agent *AgentInstance, opts processOptions) bool {
+	review := agent.Review
+	if !review.Enabled || opts.NoHistory || opts.Channel == "system" {
+		return false
+	}
+	return len(review.Channels) == 0 || slices.Contains(review.Channels, opts.Channel)
+}
+
+// reviewAnswer has the reviewer model check draft, the answer to the turn
+// whose messages are given, and when it finds problems, has the agent's
+// model (provider and model) revise it once. It returns the answer to send;
+// the draft when it is approved or the review fails.
+func (al *AgentLoop) reviewAnswer(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+	messages []providers.Message,
+	provider providers.LLMProvider,
+	model string,
+	draft string,
+) string {
+	if strings.TrimSpace(draft) == "" {
+		return draft
+	}
+
+	reviewer, reviewerModel := al.modelFor(agent, providers.RoleCheap)
+	if agent.Review.Model != "" {
+		reviewer, reviewerModel = al.namedModel(agent, agent.Review.Model)
+	}
+	var verdict struct {
+		Approved bool   `json:"approved"`
+		Problems string `json:"problems"`
+	}
+	err := providers.ChatJSON(ctx, reviewer, []providers.Message{
+		{Role: "system", Content: reviewerPrompt},
+		{Role: "user", Content: fmt.Sprintf("Request:\n%s\n\nDraft answer:\n%s", opts.UserMessage, draft)},
+	}, reviewerModel, reviewSchema, map[string]any{"max_tokens": 1024, "temperature": 0.2}, &verdict)
+	if err != nil {
+		logger.WarnCF("agent", "Review failed, sending the draft",
+			map[string]any{"agent_id": agent.ID, "error": err.Error()})
+		return draft
+	}
+	problems := strings.TrimSpace(verdict.Problems)
+	if verdict.Approved || problems == "" {
+		logger.DebugCF("agent", "Review approved the answer", map[string]any{"agent_id": agent.ID})
+		return draft
+	}
+
+	logger.InfoCF("agent", "Review found problems, revising the answer",
+		map[string]any{"agent_id": agent.ID, "problems": utils.Truncate(problems, 200)})
+	revise := append(messages[:len(messages):len(messages)],
+		providers.Message{Role: "assistant", Content: draft},
+		providers.Message{
+			Role: "user",
+			Content: fmt.Sprintf("[System: a reviewer found problems with your answer:\n%s\n"+
+				"Write the answer again, fixing them. Reply with the new answer only.]", problems),
+		},
+	)
+	response, err := chat(ctx, provider, revise, nil, model, map[string]any{
+		"max_tokens":  agent.MaxTokens,
+		"temperature": agent.Temperature,
+	}, nil)
+	if err != nil {
+		logger.WarnCF("agent", "Revision failed, sending the draft",
+			map[string]any{"agent_id": agent.ID, "error": err.Error()})
+		return draft
+	}
+	al.recordUsage(agent, opts, model, response.Usage)
+	if strings.TrimSpace(response.Content) == "" {
+		return draft
+	}
+	return response.Content
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type reviewMockProvider struct {
+	verdict string // JSON reply to reviewer calls
+	reviews int
+	revised []string // the last user message of each revision
+}
+
+func (m *reviewMockProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	last := messages[len(messages)-1]
+	switch {
+	case strings.HasPrefix(messages[0].Content, "You review"):
+		m.reviews++
+		return &providers.LLMResponse{Content: m.verdict}, nil
+	case strings.HasPrefix(last.Content, "[System: a reviewer found problems"):
+		m.revised = append(m.revised, last.Content)
+		return &providers.LLMResponse{Content: "revised answer"}, nil
+	default:
+		return &providers.LLMResponse{Content: "draft answer"}, nil
+	}
+}
+
+func (m *reviewMockProvider) GetDefaultModel() string { return "mock-model" }
+
+func TestReview_RevisesOnProblems(t *testing.T) {
+	provider := &reviewMockProvider{verdict: `{"approved": false, "problems": "It doesn't say which city."}`}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Review = config.ReviewConfig{Enabled: true}
+	})
+
+	response, err := al.ProcessDirect(context.Background(), "Where is the Eiffel Tower?", "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != "revised answer" {
+		t.Errorf("response = %q", response)
+	}
+	if provider.reviews != 1 || len(provider.revised) != 1 {
+		t.Fatalf("reviews = %d, revisions = %d", provider.reviews, len(provider.revised))
+	}
+	if !strings.Contains(provider.revised[0], "It doesn't say which city.") {
+		t.Errorf("revision request = %q", provider.revised[0])
+	}
+	// Only the answer that was sent is kept.
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory("agent:main:main")
+	if last := history[len(history)-1]; last.Content != "revised answer" {
+		t.Errorf("saved answer = %q", last.Content)
+	}
+}
+
+func TestReview_Approved(t *testing.T) {
+	provider := &reviewMockProvider{verdict: `{"approved": true, "problems": ""}`}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Review = config.ReviewConfig{Enabled: true}
+	})
+
+	response, _ := al.ProcessDirect(context.Background(), "hello", "s")
+	if response != "draft answer" || provider.reviews != 1 || len(provider.revised) != 0 {
+		t.Errorf("response = %q, reviews = %d, revisions = %d", response, provider.reviews, len(provider.revised))
+	}
+}
+
+func TestReview_FailedReviewSendsDraft(t *testing.T) {
+	provider := &reviewMockProvider{verdict: "not json"}
+	al, _ := newTestLoop(t, provider, func(cfg *config.Config) {
+		cfg.Agents.Defaults.Review = config.ReviewConfig{Enabled: true}
+	})
+
+	response, _ := al.ProcessDirect(context.Background(), "hello", "s")
+	if response != "draft answer" || len(provider.revised) != 0 {
+		t.Errorf("response = %q, revisions = %d", response, len(provider.revised))
+	}
+}
+
+func TestReviewApplies(t *testing.T) {
+	agent := &AgentInstance{Review: config.ReviewConfig{Enabled: true, Channels: []string{"telegram"}}}
+	tests := []struct {
+		opts processOptions
+		want bool
+	}{
+		{processOptions{Channel: "telegram"}, true},
+		{processOptions{Channel: "discord"}, false},
+		{processOptions{Channel: "telegram", NoHistory: true}, false},
+	}
+	for _, tt := range tests {
+		if got := reviewApplies(agent, tt.opts); got != tt.want {
+			t.Errorf("reviewApplies(%+v) = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+
+	agent.Review.Channels = nil
+	if !reviewApplies(agent, processOptions{Channel: "cli"}) {
+		t.Error("review should apply to all channels when none are listed")
+	}
+	if reviewApplies(agent, processOptions{Channel: "system"}) {
+		t.Error("background task reports should not be reviewed")
+	}
+	agent.Review.Enabled = false
+	if reviewApplies(agent, processOptions{Channel: "cli"}) {
+		t.Error("review applies while disabled")
+	}
+}
+
+func TestNewAgentInstance_ReviewOverride(t *testing.T) {
+	defaults := &config.AgentDefaults{Workspace: t.TempDir(), Review: config.ReviewConfig{Enabled: true}}
+	agent := NewAgentInstance(&config.AgentConfig{ID: "quick", Review: &config.ReviewConfig{}},
+		defaults, &config.Config{}, &simpleMockProvider{})
+	if agent.Review.Enabled {
+		t.Error("the agent's review setting should replace the default")
+	}
+	if agent := NewAgentInstance(nil, defaults, &config.Config{}, &simpleMockProvider{}); !agent.Review.Enabled {
+		t.Error("agents without a review setting should use the default")
+	}
+}
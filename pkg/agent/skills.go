@@ -0,0 +1,192 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const (
+	// maxActiveSkills caps the skills added to one request's context.
+	maxActiveSkills = 3
+
+	// skillMinScore is the similarity between a request and a skill's
+	// description above which the skill matches.
+	skillMinScore = 0.5
+)
+
+// activeSkills picks the skills matching message, by keyword and, when an
+// "embed" model role is assigned, by meaning. It returns their
+// instructions and examples for the system prompt, and the tools they
+// limit the request to (nil when they don't).
+func (al *AgentLoop) activeSkills(ctx context.Context, agent *AgentInstance, message string) (string, []string) {
+	if strings.TrimSpace(message) == "" {
+		return "", nil
+	}
+	loader := agent.ContextBuilder.skillsLoader
+	var candidates []skills.SkillInfo
+	for _, s := range loader.ListSkills() {
+		if !s.Disabled && (len(agent.SkillsFilter) == 0 || slices.Contains(agent.SkillsFilter, s.Name)) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	matched := skills.MatchKeywords(candidates, message)
+	if len(matched) < maxActiveSkills {
+		matched = append(matched, al.matchSkillsByMeaning(ctx, candidates, matched, message)...)
+	}
+	if len(matched) > maxActiveSkills {
+		matched = matched[:maxActiveSkills]
+	}
+	if len(matched) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Active Skills\nThese skills match this request. Follow their instructions.")
+	var names, allowed []string
+	for _, s := range matched {
+		content, ok := loader.LoadSkill(s.Name)
+		if !ok {
+			continue
+		}
+		names = append(names, s.Name)
+		fmt.Fprintf(&sb, "\n\n### Skill: %s\n\n%s", s.Name, strings.TrimSpace(content))
+		if examples, ok := loader.LoadExamples(s.Name); ok {
+			fmt.Fprintf(&sb, "\n\n#### Examples\n\n%s", examples)
+		}
+		for _, t := range s.Tools {
+			if !slices.Contains(allowed, t) {
+				allowed = append(allowed, t)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	logger.InfoCF("agent", "Skills matched the request",
+		map[string]any{"agent_id": agent.ID, "skills": names, "tools": allowed})
+	return sb.String(), allowed
+}
+
+// matchSkillsByMeaning returns the candidates not already matched whose
+// description is close to message, best first. It returns nil when no
+// "embed" model role is assigned.
+func (al *AgentLoop) matchSkillsByMeaning(
+	ctx context.Context,
+	candidates, matched []skills.SkillInfo,
+	message string,
+) []skills.SkillInfo {
+	roles := al.modelRoles()
+	if roles == nil {
+		return nil
+	}
+	embedder, err := roles.Embeddings()
+	if err != nil {
+		return nil
+	}
+	var rest []skills.SkillInfo
+	var missing []string // descriptions without a cached vector
+	for _, s := range candidates {
+		if slices.ContainsFunc(matched, func(m skills.SkillInfo) bool { return m.Name == s.Name }) {
+			continue
+		}
+		rest = append(rest, s)
+		if _, ok := al.skillVectors.Load(skillText(s)); !ok {
+			missing = append(missing, skillText(s))
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	vectors, err := embedder.Embed(ctx, append([]string{message}, missing...))
+	if err != nil || len(vectors) != len(missing)+1 {
+		logger.WarnCF("agent", "Can't embed message for skill matching", map[string]any{"error": fmt.Sprint(err)})
+		return nil
+	}
+	for i, text := range missing {
+		al.skillVectors.Store(text, vectors[i+1])
+	}
+
+	type scored struct {
+		skill skills.SkillInfo
+		score float32
+	}
+	var hits []scored
+	for _, s := range rest {
+		v, _ := al.skillVectors.Load(skillText(s))
+		if score := memory.Cosine(vectors[0], v.([]float32)); score >= skillMinScore {
+			hits = append(hits, scored{s, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	out := make([]skills.SkillInfo, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.skill)
+	}
+	return out
+}
+
+// skillText is what a skill's embedding is computed from.
+func skillText(s skills.SkillInfo) string {
+	return s.Name + ": " + s.Description
+}
+
+// onlyTools returns the definitions of the tools in allowed.
+func onlyTools(defs []providers.ToolDefinition, allowed []string) []providers.ToolDefinition {
+	var out []providers.ToolDefinition
+	for _, d := range defs {
+		if slices.Contains(allowed, d.Function.Name) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// restrictTools returns an approver that refuses tools outside allowed and
+// passes the other calls on to next, if any. why completes the refusal,
+// e.g. "to this user".
+func restrictTools(allowed []string, why string, next tools.Approver) tools.Approver {
+	return func(ctx context.Context, name string, args map[string]any) error {
+		if !slices.Contains(allowed, name) {
+			return fmt.Errorf("the %s tool isn't available %s", name, why)
+		}
+		if next != nil {
+			return next(ctx, name, args)
+		}
+		return nil
+	}
+}
+
+// registerSkillTools registers the tools that find and install skills from
+// the configured registries.
+func registerSkillTools(cfg *config.Config, agent *AgentInstance) {
+	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
+		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
+		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+	})
+	searchCache := skills.NewSearchCache(
+		cfg.Tools.Skills.SearchCache.MaxSize,
+		time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
+	)
+	agent.Tools.Register(tools.NewFindSkillsTool(registryMgr, searchCache))
+	agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
+}
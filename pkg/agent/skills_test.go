@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// skillMockProvider calls the counting tool once, then answers, recording
+// what it was offered.
+type skillMockProvider struct {
+	systems []string
+	tools   [][]string
+}
+
+func (m *skillMockProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	defs []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	m.systems = append(m.systems, messages[0].Content)
+	var names []string
+	for _, d := range defs {
+		names = append(names, d.Function.Name)
+	}
+	m.tools = append(m.tools, names)
+	if len(m.systems) == 1 {
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{
+			{ID: "call-1", Name: "counting", Arguments: map[string]any{}},
+		}}, nil
+	}
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (m *skillMockProvider) GetDefaultModel() string { return "mock-model" }
+
+func writeSkill(t *testing.T, workspace, name, frontmatter, body, examples string) {
+	t.Helper()
+	dir := filepath.Join(workspace, "skills", name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\n" + frontmatter + "---\n\n" + body
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if examples != "" {
+		if err := os.WriteFile(filepath.Join(dir, "EXAMPLES.md"), []byte(examples), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSkills_KeywordMatchInjectsAndRestricts(t *testing.T) {
+	provider := &skillMockProvider{}
+	al, _ := newTestLoop(t, provider, withToolLimits(5, 0))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+	workspace := al.registry.GetDefaultAgent().Workspace
+	writeSkill(t, workspace, "deploy",
+		"description: Deploys the site\nkeywords: [deploy, release]\ntools: [read_file]\n",
+		"Run the checklist first.", "User: ship it\nAssistant: Checklist done.")
+	writeSkill(t, workspace, "weather", "description: Weather forecasts\nkeywords: forecast\n",
+		"Use wttr.in.", "")
+
+	if _, err := al.ProcessDirect(context.Background(), "Please deploy the site", "s"); err != nil {
+		t.Fatal(err)
+	}
+	system := provider.systems[0]
+	for _, want := range []string{"### Skill: deploy", "Run the checklist first.", "#### Examples", "Checklist done."} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt lacks %q", want)
+		}
+	}
+	if strings.Contains(system, "Use wttr.in.") {
+		t.Error("unmatched skill was injected")
+	}
+	if got := strings.Join(provider.tools[0], ","); got != "read_file" {
+		t.Errorf("offered tools = %s, want read_file", got)
+	}
+	if runs := tool.runs.Load(); runs != 0 {
+		t.Errorf("restricted tool ran %d times", runs)
+	}
+}
+
+func TestSkills_DisabledAndUnmatched(t *testing.T) {
+	provider := &skillMockProvider{}
+	al, _ := newTestLoop(t, provider, withToolLimits(5, 0))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+	agent := al.registry.GetDefaultAgent()
+	writeSkill(t, agent.Workspace, "deploy",
+		"description: Deploys the site\nkeywords: deploy\ntools: [read_file]\n", "Run the checklist first.", "")
+	if err := agent.ContextBuilder.skillsLoader.SetEnabled("deploy", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := al.ProcessDirect(context.Background(), "deploy now", "s"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(provider.systems[0], "Run the checklist first.") {
+		t.Error("disabled skill was injected")
+	}
+	if tool.runs.Load() != 1 {
+		t.Errorf("tool runs = %d, want 1", tool.runs.Load())
+	}
+}
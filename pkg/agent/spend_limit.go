@@ -0,0 +1,83 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// spendNotices remembers which chats were told about today's downshift.
+// The zero value is ready to use.
+type spendNotices struct {
+	mu   sync.Mutex
+	sent map[string]string // chat key -> local day of the last notice
+}
+
+// first reports whether chat key hasn't been notified on day yet, and
+// records it as notified.
+func (n *spendNotices) first(key, day string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.sent == nil {
+		n.sent = make(map[string]string)
+	}
+	if n.sent[key] == day {
+		return false
+	}
+	n.sent[key] = day
+	return true
+}
+
+// budgetModel returns the model_list entry chat turns should use because
+// today's spend reached agents.defaults.budget.daily_limit_usd, or "" when
+// under budget or no budget is set. The chat is notified once per day.
+func (al *AgentLoop) budgetModel(channel, chatID string) string {
+	defaults := al.config().Agents.Defaults
+	budget := defaults.Budget
+	if budget.DailyLimitUSD <= 0 || al.usage == nil {
+		return ""
+	}
+
+	now := time.Now()
+	spent := al.usage.Day(now).Cost
+	if spent < budget.DailyLimitUSD {
+		return ""
+	}
+
+	model := budget.Model
+	if model == "" {
+		model = defaults.ModelForRole(providers.RoleCheap)
+	}
+	if model == "" {
+		if al.spendNotices.first("", now.Format(time.DateOnly)) {
+			logger.WarnCF("agent", "Daily budget reached but no budget model or cheap role is configured",
+				map[string]any{"limit_usd": budget.DailyLimitUSD, "spent_usd": spent})
+		}
+		return ""
+	}
+
+	if al.spendNotices.first(chatKey(channel, chatID), now.Format(time.DateOnly)) {
+		notice := fmt.Sprintf("Today's budget of $%.2f is used up ($%.2f spent). "+
+			"Switching to %s until midnight.", budget.DailyLimitUSD, spent, model)
+		logger.InfoCF("agent", "Daily budget reached, downshifting",
+			map[string]any{"limit_usd": budget.DailyLimitUSD, "spent_usd": spent, "model": model})
+		if !constants.IsInternalChannel(channel) {
+			al.bus.PublishOutbound(bus.OutboundMessage{
+				Channel: channel,
+				ChatID:  chatID,
+				Content: notice,
+			})
+		}
+	}
+	return model
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+func TestProcessMessage_DownshiftsOverDailyBudget(t *testing.T) {
+	dir := t.TempDir()
+	cheapScript, bigScript := filepath.Join(dir, "cheap.json"), filepath.Join(dir, "big.json")
+	if err := os.WriteFile(cheapScript, []byte(`{"default": "from the cheap model"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bigScript, []byte(`{"default": "from the big model"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				ModelRoles:        map[string]string{"cheap": "cheap"},
+				Budget:            config.BudgetConfig{DailyLimitUSD: 1},
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "cheap", Model: "mock/" + cheapScript},
+			{ModelName: "big", Model: "mock/" + bigScript},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "from the chat model"})
+	ctx := context.Background()
+	msg := bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "7", Content: "hi"}
+
+	if response, _ := al.processMessage(ctx, msg); response != "from the chat model" {
+		t.Fatalf("under budget response = %q", response)
+	}
+
+	al.usage.Add(usage.Record{Model: "openai/gpt-4o", Cost: 1.5})
+	for range 2 {
+		if response, _ := al.processMessage(ctx, msg); response != "from the cheap model" {
+			t.Errorf("over budget response = %q", response)
+		}
+	}
+
+	outCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	notice, ok := msgBus.SubscribeOutbound(outCtx)
+	if !ok || notice.ChatID != "7" || !strings.Contains(notice.Content, "$1.00") || !strings.Contains(notice.Content, "cheap") {
+		t.Fatalf("notice = %+v, %v", notice, ok)
+	}
+	if extra, ok := msgBus.SubscribeOutbound(outCtx); ok {
+		t.Errorf("second notice sent the same day: %+v", extra)
+	}
+
+	// An explicit per-message choice still wins.
+	msg.Content = "@big: hi"
+	if response, _ := al.processMessage(ctx, msg); response != "from the big model" {
+		t.Errorf("explicit model response = %q", response)
+	}
+}
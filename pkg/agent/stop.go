@@ -0,0 +1,124 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrStopped is returned for a turn that was cancelled with /stop.
+var ErrStopped = errors.New("stopped by user")
+
+// stoppedNote is saved as the assistant reply of a stopped turn, so the
+// session keeps alternating and the model knows the request was abandoned.
+const stoppedNote = "[Stopped by the user before finishing.]"
+
+// activeRuns tracks the in-flight turns of each chat so they can be
+// cancelled. The zero value is ready to use.
+type activeRuns struct {
+	mu   sync.Mutex
+	next uint64
+	runs map[string]map[uint64]activeRun
+}
+
+type activeRun struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// start registers a turn for chat key and returns its context, which /stop
+// cancels with ErrStopped. done must be called when the turn ends.
+func (r *activeRuns) start(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	if r.runs == nil {
+		r.runs = make(map[string]map[uint64]activeRun)
+	}
+	if r.runs[key] == nil {
+		r.runs[key] = make(map[uint64]activeRun)
+	}
+	r.next++
+	id := r.next
+	r.runs[key][id] = activeRun{ctx: ctx, cancel: cancel}
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.runs[key], id)
+		if len(r.runs[key]) == 0 {
+			delete(r.runs, key)
+		}
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// stop cancels the turns of chat key with cause, which wraps ErrStopped,
+// and returns how many were still running.
+func (r *activeRuns) stop(key string, cause error) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stopped := 0
+	for _, run := range r.runs[key] {
+		if run.ctx.Err() == nil {
+			run.cancel(cause)
+			stopped++
+		}
+	}
+	return stopped
+}
+
+// running reports whether a turn of chat key is in progress.
+func (r *activeRuns) running(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs[key] {
+		if run.ctx.Err() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// stopNote returns the note saved for the turn of ctx, which was stopped.
+func stopNote(ctx context.Context) string {
+	if errors.Is(context.Cause(ctx), errSuperseded) {
+		return supersededNote
+	}
+	return stoppedNote
+}
+
+func chatKey(channel, chatID string) string {
+	return channel + ":" + chatID
+}
+
+// StopChat cancels the turns in progress for a chat, including their LLM
+// requests and running tools, and returns how many were stopped.
+func (al *AgentLoop) StopChat(channel, chatID string) int {
+	return al.active.stop(chatKey(channel, chatID), ErrStopped)
+}
+
+// isStopCommand reports whether content is /stop, allowing the @botname
+// suffix Telegram adds in groups.
+func isStopCommand(content string) bool {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return false
+	}
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	return cmd == "/stop"
+}
+
+func (al *AgentLoop) stopReply(channel, chatID string) string {
+	if al.StopChat(channel, chatID) == 0 {
+		return "Nothing to stop."
+	}
+	return "Stopped."
+}
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// blockingMockProvider blocks every call until its context is cancelled.
+type blockingMockProvider struct {
+	started chan struct{}
+	ended   chan error
+}
+
+func newBlockingMockProvider() *blockingMockProvider {
+	return &blockingMockProvider{started: make(chan struct{}, 1), ended: make(chan error, 1)}
+}
+
+func (m *blockingMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.started <- struct{}{}
+	<-ctx.Done()
+	m.ended <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func (m *blockingMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func waitFor[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		var zero T
+		return zero
+	}
+}
+
+// waitUntil polls cond until it holds, failing the test with what after 5
+// seconds.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal(what)
+		}
+	}
+}
+
+// waitUntilRunning waits until chat key has a turn that hasn't ended, or,
+// when running is false, until all its turns have, stopped ones included.
+func waitUntilRunning(t *testing.T, al *AgentLoop, key string, running bool) {
+	t.Helper()
+	what := "the turn didn't start"
+	if !running {
+		what = "the turn didn't end"
+	}
+	waitUntil(t, what, func() bool {
+		al.active.mu.Lock()
+		defer al.active.mu.Unlock()
+		return (len(al.active.runs[key]) > 0) == running
+	})
+}
+
+func TestRun_StopCancelsInFlightTurn(t *testing.T) {
+	provider := newBlockingMockProvider()
+	al, msgBus := newTestLoop(t, provider, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "write a novel"})
+	waitFor(t, provider.started)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "/stop@picoclaw_bot"})
+	if err := waitFor(t, provider.ended); !errors.Is(err, context.Canceled) {
+		t.Errorf("provider context error = %v, want canceled", err)
+	}
+
+	outCtx, outCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer outCancel()
+	out, ok := msgBus.SubscribeOutbound(outCtx)
+	if !ok || out.Content != "Stopped." || out.ChatID != "42" {
+		t.Fatalf("outbound = %+v, %v", out, ok)
+	}
+
+	// The stopped turn must not report an error, so the next reply is for
+	// the next /stop.
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "42", Content: "/stop"})
+	out, ok = msgBus.SubscribeOutbound(outCtx)
+	if !ok || out.Content != "Nothing to stop." {
+		t.Errorf("outbound = %+v, %v", out, ok)
+	}
+
+	// The stopped turn may still be saving its session; let it finish
+	// before the workspace is removed.
+	waitUntilRunning(t, al, chatKey("telegram", "42"), false)
+}
+
+func TestProcessDirect_StopChatReturnsErrStopped(t *testing.T) {
+	provider := newBlockingMockProvider()
+	al, _ := newTestLoop(t, provider, nil)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := al.ProcessDirect(context.Background(), "hi", "test-session")
+		errc <- err
+	}()
+	waitFor(t, provider.started)
+
+	if n := al.StopChat("telegram", "direct"); n != 0 {
+		t.Errorf("StopChat() for another chat = %d, want 0", n)
+	}
+	if n := al.StopChat("cli", "direct"); n != 1 {
+		t.Errorf("StopChat() = %d, want 1", n)
+	}
+	if err := waitFor(t, errc); !errors.Is(err, ErrStopped) {
+		t.Errorf("ProcessDirect() error = %v, want ErrStopped", err)
+	}
+}
+
+func TestRun_StopNeedsAKnownUser(t *testing.T) {
+	provider := newBlockingMockProvider()
+	al, msgBus := newTestLoop(t, provider, nil)
+	al.config().Users = map[string]config.UserConfig{"alice": {IDs: []string{"telegram:7"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "group", Content: "write a novel"})
+	waitFor(t, provider.started)
+
+	// A stranger in the group can't stop alice's turn.
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "9", ChatID: "group", Content: "/stop"})
+	select {
+	case err := <-provider.ended:
+		t.Fatalf("the turn ended (%v) on a stranger's /stop", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	msgBus.PublishInbound(bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "group", Content: "/stop"})
+	if err := waitFor(t, provider.ended); !errors.Is(err, context.Canceled) {
+		t.Errorf("provider context error = %v, want canceled", err)
+	}
+	waitUntilRunning(t, al, chatKey("telegram", "group"), false)
+}
@@ -0,0 +1,48 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerSubagentTools registers the tools that hand work to other models
+// or agents: spawning subagents, delegating to one with the agent's own
+// tools, and running workflows.
+func registerSubagentTools(
+	cfg *config.Config,
+	msgBus *bus.MessageBus,
+	registry *AgentRegistry,
+	provider providers.LLMProvider,
+	agent *AgentInstance,
+) {
+	// Spawn tool with allowlist checker
+	subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace, msgBus)
+	subagentManager.SetLLMOptions(agent.MaxTokens, agent.Temperature)
+	subagentManager.SetMaxParallelTools(agent.MaxParallel)
+	spawnTool := tools.NewSpawnTool(subagentManager)
+	spawnTool.SetAllowlistChecker(func(targetAgentID string) bool {
+		return registry.CanSpawnSubagent(agent.ID, targetAgentID)
+	})
+	agent.Tools.Register(spawnTool)
+
+	// Delegate tool: synchronous subagents using this agent's tools
+	if d := cfg.Tools.Delegate; d.Enabled {
+		agent.Tools.Register(tools.NewDelegateTool(agent.Provider, agent.Model, agent.Tools, tools.DelegateOptions{
+			MaxDepth:      d.MaxDepth,
+			MaxIterations: d.MaxIterations,
+			TokenBudget:   d.TokenBudget,
+			LLMOptions:    map[string]any{"max_tokens": agent.MaxTokens, "temperature": agent.Temperature},
+			MaxParallel:   agent.MaxParallel,
+		}))
+	}
+
+	agent.Tools.Register(tools.NewWorkflowTool(agent.Workspace, agent.Tools, agent.Provider, agent.Model,
+		map[string]any{"max_tokens": agent.MaxTokens, "temperature": agent.Temperature}))
+}
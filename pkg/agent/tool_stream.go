@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// malformedToolCallError aborts a streamed completion whose tool call can't
+// succeed, so the model is asked again instead of finishing the call.
+type malformedToolCallError struct {
+	Name string
+	Err  error
+}
+
+func (e *malformedToolCallError) Error() string {
+	return fmt.Sprintf("malformed call to tool %q: %v", e.Name, e.Err)
+}
+
+// toolCallChecker follows tool calls as they stream and rejects one as soon
+// as it names an unknown tool or its arguments stop being a JSON object.
+// Schema checks need the complete arguments and happen in the registry.
+type toolCallChecker struct {
+	tools *tools.ToolRegistry
+	calls map[int]*streamedCall
+}
+
+type streamedCall struct {
+	name string
+	args strings.Builder
+}
+
+func newToolCallChecker(registry *tools.ToolRegistry) *toolCallChecker {
+	return &toolCallChecker{tools: registry, calls: map[int]*streamedCall{}}
+}
+
+// check is passed to providers.WithToolCallDeltas.
+func (c *toolCallChecker) check(d providers.ToolCallDelta) error {
+	call, ok := c.calls[d.Index]
+	if !ok {
+		call = &streamedCall{}
+		c.calls[d.Index] = call
+	}
+	call.name += d.Name
+	if d.Arguments == "" {
+		return nil
+	}
+	// Names may arrive in pieces too, but are complete once arguments start.
+	if call.args.Len() == 0 {
+		if _, ok := c.tools.Get(call.name); !ok {
+			return &malformedToolCallError{Name: call.name, Err: errors.New("no such tool")}
+		}
+	}
+	call.args.WriteString(d.Arguments)
+	if err := checkPartialObject(call.args.String()); err != nil {
+		return &malformedToolCallError{Name: call.name, Err: err}
+	}
+	return nil
+}
+
+// checkPartialObject returns an error if s can't be the start of a JSON
+// object. Truncated input is fine; it may still be completed.
+func checkPartialObject(s string) error {
+	dec := json.NewDecoder(strings.NewReader(s))
+	for first := true; ; first = false {
+		tok, err := dec.Token()
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+		if first && tok != json.Delim('{') {
+			return fmt.Errorf("arguments must be a JSON object, got %v", tok)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestCheckPartialObject(t *testing.T) {
+	valid := []string{`{`, `{"a`, `{"a": "hel`, `{"a": [1,`, `{"a": tru`, `{"a": 1}`}
+	for _, s := range valid {
+		if err := checkPartialObject(s); err != nil {
+			t.Errorf("checkPartialObject(%q) = %v, want nil", s, err)
+		}
+	}
+	invalid := []string{`{"a" 1`, `[1, 2]`, `{"a": 1}}`, `{"a":1,,`, `"text"`}
+	for _, s := range invalid {
+		if err := checkPartialObject(s); err == nil {
+			t.Errorf("checkPartialObject(%q) = nil, want an error", s)
+		}
+	}
+}
+
+// toolStreamMockProvider streams a tool call on the first request and
+// answers plainly afterwards, recording what it was sent
+type toolStreamMockProvider struct {
+	simpleMockProvider
+	deltas []providers.ToolCallDelta
+	calls  [][]providers.Message
+}
+
+func (m *toolStreamMockProvider) StreamChat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+	onDelta providers.StreamCallback,
+) (*providers.LLMResponse, error) {
+	m.calls = append(m.calls, messages)
+	if len(m.calls) == 1 {
+		onToolCall := protocoltypes.ToolCallDeltas(ctx)
+		for _, d := range m.deltas {
+			if err := onToolCall(d); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.Chat(ctx, messages, tools, model, opts)
+}
+
+func TestProcessDirectStream_ReasksMalformedToolCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		deltas  []providers.ToolCallDelta
+		wantErr string
+	}{
+		{
+			name: "bad json",
+			deltas: []providers.ToolCallDelta{
+				{Index: 0, ID: "call_1", Name: "mock_custom", Arguments: `{"path" `},
+				{Index: 0, Arguments: `"a.txt"}`},
+			},
+			wantErr: "invalid JSON arguments",
+		},
+		{
+			name: "unknown tool",
+			deltas: []providers.ToolCallDelta{
+				{Index: 0, ID: "call_1", Name: "mock_"},
+				{Index: 0, Name: "missing", Arguments: `{}`},
+			},
+			wantErr: "no such tool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:         t.TempDir(),
+						Model:             "test-model",
+						MaxTokens:         4096,
+						MaxToolIterations: 10,
+					},
+				},
+			}
+			provider := &toolStreamMockProvider{
+				simpleMockProvider: simpleMockProvider{response: "Done"},
+				deltas:             tt.deltas,
+			}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+			al.RegisterTool(&mockCustomTool{})
+
+			response, err := al.ProcessDirectStream(context.Background(), "hi", "test-session", func(string) {})
+			if err != nil || response != "Done" {
+				t.Fatalf("ProcessDirectStream() = %q, %v", response, err)
+			}
+			if len(provider.calls) != 2 {
+				t.Fatalf("provider called %d times, want 2", len(provider.calls))
+			}
+			retry := provider.calls[1]
+			last := retry[len(retry)-1]
+			if last.Role != "user" || !strings.Contains(last.Content, tt.wantErr) {
+				t.Errorf("retry ended with %+v, want a correction mentioning %q", last, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+)
+
+// identifyUser returns who msg is from. With users configured, the name of
+// the user whose ids include the sender and their settings; a sender that
+// matches none isn't served (ok is false), except on internal channels and
+// for scheduled jobs. Without users, or when the sender isn't a configured
+// user, the name is the sender's profile key and cfg is nil.
+func (al *AgentLoop) identifyUser(msg bus.InboundMessage) (name string, cfg *config.UserConfig, ok bool) {
+	key := profileKey(msg.Channel, msg.SenderID, msg.ChatID)
+	conf := al.config()
+	if len(conf.Users) == 0 || constants.IsInternalChannel(msg.Channel) {
+		return key, nil, true
+	}
+	if name, u, found := conf.FindUser(key); found {
+		return name, u, true
+	}
+	return key, nil, msg.SenderID == "cron"
+}
+
+// userSessionKey returns the session key of a configured user's direct
+// chats with agent, which they share across channels.
+func userSessionKey(agentID, user string) string {
+	return strings.ToLower("agent:" + agentID + ":user:" + user)
+}
+
+// quotaReply returns the reply to a configured user who has used up their
+// daily allowance, or "" when they may go on.
+func (al *AgentLoop) quotaReply(user string, u *config.UserConfig) string {
+	if al.usage == nil || u == nil || (u.DailyLimitUSD <= 0 && u.DailyTokens <= 0) {
+		return ""
+	}
+	today := al.usage.UserDay(user, time.Now())
+	over := u.DailyLimitUSD > 0 && today.Cost >= u.DailyLimitUSD ||
+		u.DailyTokens > 0 && today.PromptTokens+today.CompletionTokens >= u.DailyTokens
+	if !over {
+		return ""
+	}
+	logger.InfoCF("agent", "User reached their daily quota",
+		map[string]any{"user": user, "cost": today.Cost, "tokens": today.PromptTokens + today.CompletionTokens})
+	return fmt.Sprintf("You've used today's allowance (%s). It resets at midnight.", describeQuota(u))
+}
+
+func describeQuota(u *config.UserConfig) string {
+	var parts []string
+	if u.DailyLimitUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f", u.DailyLimitUSD))
+	}
+	if u.DailyTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", u.DailyTokens))
+	}
+	return strings.Join(parts, " or ")
+}
+
+// userFacts holds the long-term memory of each configured user, in
+// memory/users/<name>/facts.db, opened on first use.
+type userFacts struct {
+	dir string
+	key *encrypt.Key
+
+	mu     sync.Mutex
+	stores map[string]*memory.Store
+}
+
+func newUserFacts(memoryDir string, key *encrypt.Key) *userFacts {
+	return &userFacts{dir: filepath.Join(memoryDir, "users"), key: key, stores: make(map[string]*memory.Store)}
+}
+
+// get returns the store of user, or nil when it can't be opened.
+func (uf *userFacts) get(user string) *memory.Store {
+	user = strings.ToLower(user)
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+	if s, ok := uf.stores[user]; ok {
+		return s
+	}
+	path := filepath.Join(uf.dir, user, "facts.db")
+	s, err := memory.Open(path, uf.key)
+	if err != nil {
+		logger.ErrorCF("agent", "Can't open the user's long-term memory",
+			map[string]any{"user": user, "path": path, "error": err.Error()})
+		return nil
+	}
+	uf.stores[user] = s
+	return s
+}
+
+func (uf *userFacts) close() {
+	if uf == nil {
+		return
+	}
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+	for _, s := range uf.stores {
+		s.Close()
+	}
+	uf.stores = make(map[string]*memory.Store)
+}
+
+// factsFor returns the long-term memory for requests of user, a configured
+// user's name or "" for everyone else, or nil when memory is off.
+func factsFor(agent *AgentInstance, user string) *memory.Store {
+	if user == "" || agent.Facts == nil || agent.UserFacts == nil {
+		return agent.Facts
+	}
+	return agent.UserFacts.get(user)
+}
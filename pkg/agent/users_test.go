@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+func TestUsers_IgnoresUnknownSendersAndSharesSessions(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "ok"}}
+	al, _ := newTestLoop(t, provider, withToolLimits(2, 0))
+	al.RegisterTool(&countingTool{})
+	al.config().Users = map[string]config.UserConfig{
+		"ada": {IDs: []string{"telegram:7", "Discord:70"}},
+	}
+	ctx := context.Background()
+
+	reply, err := al.processMessage(ctx, bus.InboundMessage{Channel: "telegram", SenderID: "8", ChatID: "8", Content: "hi"})
+	if err != nil || reply != "" || len(provider.calls) != 0 {
+		t.Fatalf("unknown sender: reply = %q, err = %v, LLM calls = %d", reply, err, len(provider.calls))
+	}
+
+	al.processMessage(ctx, bus.InboundMessage{Channel: "telegram", SenderID: "7|ada", ChatID: "7", Content: "hi"})
+	al.processMessage(ctx, bus.InboundMessage{Channel: "discord", SenderID: "70", ChatID: "70", Content: "again"})
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory(userSessionKey("main", "ada"))
+	if len(history) != 4 || history[2].Content != "again" {
+		t.Errorf("shared session history = %+v", history)
+	}
+
+	// Scheduled jobs still run.
+	if reply, _ := al.ProcessDirect(ctx, "report", "cron-job"); reply != "ok" {
+		t.Errorf("cron reply = %q", reply)
+	}
+}
+
+func TestUsers_DailyQuota(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "ok"}}
+	al, _ := newTestLoop(t, provider, withToolLimits(2, 0))
+	al.RegisterTool(&countingTool{})
+	al.config().Users = map[string]config.UserConfig{
+		"ada": {IDs: []string{"telegram:7"}, DailyTokens: 100},
+		"bob": {IDs: []string{"telegram:8"}, DailyTokens: 100},
+	}
+	al.usage.Add(usage.Record{Time: time.Now(), User: "ada", PromptTokens: 90, CompletionTokens: 10})
+	ctx := context.Background()
+
+	reply, _ := al.processMessage(ctx, bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "7", Content: "hi"})
+	if !strings.Contains(reply, "today's allowance (100 tokens)") || len(provider.calls) != 0 {
+		t.Errorf("over quota reply = %q, LLM calls = %d", reply, len(provider.calls))
+	}
+	if reply, _ := al.processMessage(ctx, bus.InboundMessage{Channel: "telegram", SenderID: "8", ChatID: "8", Content: "hi"}); reply != "ok" {
+		t.Errorf("other user's reply = %q", reply)
+	}
+}
+
+func TestUsers_ToolPermissions(t *testing.T) {
+	provider := &skillMockProvider{}
+	al, _ := newTestLoop(t, provider, withToolLimits(5, 0))
+	tool := &countingTool{}
+	al.RegisterTool(tool)
+	al.config().Users = map[string]config.UserConfig{
+		"ada": {IDs: []string{"telegram:7"}, Tools: []string{"read_file"}},
+	}
+
+	reply, _ := al.processMessage(context.Background(),
+		bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "7", Content: "count"})
+	if reply != "done" {
+		t.Fatalf("reply = %q", reply)
+	}
+	if !slices.Equal(provider.tools[0], []string{"read_file"}) {
+		t.Errorf("offered tools = %v", provider.tools[0])
+	}
+	if tool.runs.Load() != 0 {
+		t.Error("a tool the user isn't allowed ran")
+	}
+}
+
+func TestUsers_SeparateMemory(t *testing.T) {
+	al, _ := newTestLoop(t, &memoryMockProvider{facts: `{"facts": []}`}, withMemory(t, true))
+	agent := al.registry.GetDefaultAgent()
+
+	ada, bob := factsFor(agent, "ada"), factsFor(agent, "bob")
+	if ada == nil || bob == nil || ada == bob || ada == agent.Facts {
+		t.Fatalf("stores: ada %p, bob %p, shared %p", ada, bob, agent.Facts)
+	}
+	if factsFor(agent, "Ada") != ada || factsFor(agent, "") != agent.Facts {
+		t.Error("factsFor didn't return the same store again")
+	}
+	if _, err := ada.Add("Ada's cat is called Tom", []float32{1, 0}, "s"); err != nil {
+		t.Fatal(err)
+	}
+	if bob.Len() != 0 || agent.Facts.Len() != 0 {
+		t.Error("a fact leaked to another user's memory")
+	}
+}
@@ -0,0 +1,79 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// registerWebTools registers the tools that reach the web: search, fetching
+// pages, and, for the allowed domains, the headless browser and the REST
+// client.
+func registerWebTools(cfg *config.Config, agent *AgentInstance, send tools.SendMediaCallback) {
+	web := cfg.Tools.Web
+	if searchTool := tools.NewWebSearchTool(tools.WebSearchToolOptions{
+		BraveAPIKey:                 web.Brave.APIKey,
+		BraveMaxResults:             web.Brave.MaxResults,
+		BraveEnabled:                web.Brave.Enabled,
+		BraveRequestsPerMinute:      web.Brave.RequestsPerMinute,
+		TavilyAPIKey:                web.Tavily.APIKey,
+		TavilyBaseURL:               web.Tavily.BaseURL,
+		TavilyMaxResults:            web.Tavily.MaxResults,
+		TavilyEnabled:               web.Tavily.Enabled,
+		TavilyRequestsPerMinute:     web.Tavily.RequestsPerMinute,
+		DuckDuckGoMaxResults:        web.DuckDuckGo.MaxResults,
+		DuckDuckGoEnabled:           web.DuckDuckGo.Enabled,
+		DuckDuckGoRequestsPerMinute: web.DuckDuckGo.RequestsPerMinute,
+		PerplexityAPIKey:            web.Perplexity.APIKey,
+		PerplexityMaxResults:        web.Perplexity.MaxResults,
+		PerplexityEnabled:           web.Perplexity.Enabled,
+		PerplexityRequestsPerMinute: web.Perplexity.RequestsPerMinute,
+		SearXNGBaseURL:              web.SearXNG.BaseURL,
+		SearXNGMaxResults:           web.SearXNG.MaxResults,
+		SearXNGEnabled:              web.SearXNG.Enabled,
+		SearXNGRequestsPerMinute:    web.SearXNG.RequestsPerMinute,
+		Proxy:                       web.Proxy,
+		Backend:                     web.SearchBackend,
+		CacheDir:                    agent.Workspace,
+		CacheTTL:                    time.Duration(web.SearchCacheMinutes) * time.Minute,
+	}); searchTool != nil {
+		agent.Tools.Register(searchTool)
+	}
+	agent.Tools.Register(tools.NewWebFetchToolWithProxy(50000, cfg.Tools.Web.Proxy))
+	agent.Tools.Register(tools.NewFetchURLTool(tools.FetchURLToolOptions{
+		Proxy:        web.Proxy,
+		Timeout:      time.Duration(web.Fetch.TimeoutSeconds) * time.Second,
+		MaxSize:      int64(web.Fetch.MaxSizeKB) * 1024,
+		PageChars:    web.Fetch.PageChars,
+		IgnoreRobots: web.Fetch.IgnoreRobots,
+	}))
+
+	// Headless browser, only for the allowed domains
+	if browser := cfg.Tools.Browser; browser.Enabled && len(browser.AllowDomains) > 0 {
+		browserTool := tools.NewBrowserTool(tools.BrowserToolOptions{
+			AllowDomains: browser.AllowDomains,
+			ChromePath:   browser.ChromePath,
+			Timeout:      time.Duration(browser.TimeoutSeconds) * time.Second,
+			Workspace:    agent.Workspace,
+		})
+		browserTool.SetSendCallback(send)
+		agent.Tools.Register(browserTool)
+	}
+
+	// Generic REST client, only for the allowed domains
+	if h := cfg.Tools.HTTP; h.Enabled && len(h.AllowDomains) > 0 {
+		agent.Tools.Register(tools.NewHTTPRequestTool(tools.HTTPRequestToolOptions{
+			AllowDomains:  h.AllowDomains,
+			SecretHeaders: h.SecretHeaders,
+			MaxResponse:   int64(h.MaxResponseKB) << 10,
+			Timeout:       time.Duration(h.TimeoutSeconds) * time.Second,
+			Proxy:         cfg.Tools.Web.Proxy,
+		}))
+	}
+}
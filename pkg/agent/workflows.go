@@ -0,0 +1,83 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// handleWorkflowCommand runs a saved workflow directly, without asking the
+// model, which also lets cron jobs run them:
+//
+//	/workflow                          list the workflows
+//	/workflow <name> [input=value ...] run one; a value runs to the next input=
+func (al *AgentLoop) handleWorkflowCommand(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts processOptions,
+) (string, bool, error) {
+	fields := strings.Fields(opts.UserMessage)
+	if len(fields) == 0 {
+		return "", false, nil
+	}
+	if cmd, _, _ := strings.Cut(fields[0], "@"); cmd != "/workflow" {
+		return "", false, nil
+	}
+	if _, ok := agent.Tools.Get("workflow"); !ok {
+		return "Workflows aren't available here.", true, nil
+	}
+
+	args := map[string]any{"action": "list"}
+	if len(fields) > 1 {
+		inputs, ok := parseWorkflowInputs(fields[2:])
+		if !ok {
+			return "Usage: /workflow [<name> input=value ...]", true, nil
+		}
+		args = map[string]any{"action": "run", "name": fields[1], "inputs": inputs}
+	}
+
+	ctx, done := al.startTurn(ctx, opts.Channel, opts.ChatID)
+	defer done()
+	al.updateToolContexts(agent, opts.Channel, opts.ChatID)
+	approve := al.approverFor(agent, opts)
+	if u := opts.UserConfig; u != nil && len(u.Tools) > 0 {
+		approve = restrictTools(u.Tools, "to this user", approve)
+	}
+	if approve != nil {
+		ctx = tools.WithApprover(ctx, approve)
+	}
+
+	result := agent.Tools.ExecuteWithContext(ctx, "workflow", args, opts.Channel, opts.ChatID, nil)
+	if errors.Is(context.Cause(ctx), ErrStopped) {
+		al.recordTurn(agent, opts.SessionKey, opts.UserMessage, stopNote(ctx))
+		return "", true, ErrStopped
+	}
+	al.recordTurn(agent, opts.SessionKey, opts.UserMessage, result.ForLLM)
+	return result.ForLLM, true, nil
+}
+
+// parseWorkflowInputs reads input=value pairs. Words without an "=" belong
+// to the value before them, so values may contain spaces.
+func parseWorkflowInputs(words []string) (map[string]any, bool) {
+	inputs := make(map[string]any)
+	var last string
+	for _, w := range words {
+		if name, value, ok := strings.Cut(w, "="); ok && name != "" {
+			inputs[name] = value
+			last = name
+			continue
+		}
+		if last == "" {
+			return nil, false
+		}
+		inputs[last] = inputs[last].(string) + " " + w
+	}
+	return inputs, true
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/workflows"
+)
+
+func TestWorkflowCommand(t *testing.T) {
+	provider := &recordingMockProvider{simpleMockProvider: simpleMockProvider{response: "Buy milk."}}
+	al, _ := newTestLoop(t, provider, nil)
+	workspace := al.registry.GetDefaultAgent().Workspace
+	if err := os.MkdirAll(filepath.Join(workspace, workflows.Dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "todo.txt"), []byte("milk\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	def := `
+description: Sum up a list
+inputs:
+  file: todo.txt
+  tone: plain
+steps:
+  - name: list
+    tool: read_file
+    args: {path: "{{file}}"}
+  - prompt: "In a {{tone}} tone, sum up: {{list}}"
+`
+	if err := os.WriteFile(filepath.Join(workspace, workflows.Dir, "todo.yaml"), []byte(def), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	send := func(content string) string {
+		t.Helper()
+		reply, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: "7", ChatID: "100", Content: content,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply
+	}
+
+	if got := send("/workflow"); !strings.Contains(got, "- todo [file=todo.txt] [tone=plain]: Sum up a list") {
+		t.Errorf("list reply = %q", got)
+	}
+	got := send("/workflow todo tone=very cheerful file=" + filepath.Join(workspace, "todo.txt"))
+	if !strings.HasPrefix(got, "Workflow todo finished (2 steps).") || !strings.HasSuffix(got, "Buy milk.") {
+		t.Errorf("run reply = %q", got)
+	}
+	if len(provider.calls) != 1 {
+		t.Fatalf("provider called %d times, want once for the prompt step", len(provider.calls))
+	}
+	if prompt := provider.calls[0][0].Content; !strings.Contains(prompt, "In a very cheerful tone, sum up: milk") {
+		t.Errorf("prompt = %q", prompt)
+	}
+	if got := send("/workflow todo cheerful"); !strings.HasPrefix(got, "Usage: /workflow") {
+		t.Errorf("bad inputs reply = %q", got)
+	}
+	if got := send("/workflow nope"); !strings.Contains(got, `no workflow named "nope"`) {
+		t.Errorf("unknown workflow reply = %q", got)
+	}
+}
+
+func TestParseWorkflowInputs(t *testing.T) {
+	inputs, ok := parseWorkflowInputs(strings.Fields("city=New York units=metric url=https://x.io/?a=b"))
+	if !ok || inputs["city"] != "New York" || inputs["units"] != "metric" || inputs["url"] != "https://x.io/?a=b" {
+		t.Errorf("parseWorkflowInputs() = %v, %v", inputs, ok)
+	}
+	if _, ok := parseWorkflowInputs([]string{"stray"}); ok {
+		t.Error("a value without a name was accepted")
+	}
+}
@@ -0,0 +1,352 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package archive lists, extracts and creates zip and tar archives, plain
+// or compressed with gzip, and single gzip files. tar.bz2 archives can be
+// listed and extracted. Extraction never writes outside its destination
+// and stops at the limits it is given.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Formats, named after their usual extension.
+const (
+	Zip    = "zip"
+	Tar    = "tar"
+	TarGz  = "tar.gz"
+	TarBz2 = "tar.bz2"
+	Gzip   = "gz"
+)
+
+// Default limits of an extraction.
+const (
+	DefaultMaxBytes = 1 << 30
+	DefaultMaxFiles = 10000
+)
+
+// Entry is a file, directory or symlink in an archive.
+type Entry struct {
+	Name     string // Slash-separated, as stored
+	Size     int64
+	Mode     fs.FileMode
+	Modified time.Time
+	Link     string // The target of a symlink or hard link
+}
+
+// Limits bound what an extraction writes; zero values take the defaults.
+type Limits struct {
+	MaxBytes int64
+	MaxFiles int
+}
+
+// Result sums up an extraction or the creation of an archive.
+type Result struct {
+	Files   int
+	Dirs    int
+	Links   int
+	Bytes   int64    // Uncompressed
+	Skipped []string // Entries not extracted, with why
+}
+
+// DetectFormat returns the format of the archive named name, from its
+// extension.
+func DetectFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return Zip, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return Tar, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return TarGz, nil
+	case strings.HasSuffix(lower, ".tar.bz2"), strings.HasSuffix(lower, ".tbz2"), strings.HasSuffix(lower, ".tbz"):
+		return TarBz2, nil
+	case strings.HasSuffix(lower, ".gz"):
+		return Gzip, nil
+	}
+	return "", fmt.Errorf("%s is not a supported archive (zip, tar, tar.gz, tgz, tar.bz2 or gz)", filepath.Base(name))
+}
+
+// TrimExtension returns name without the extension of its format.
+func TrimExtension(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tar.bz2", ".tgz", ".tbz2", ".tbz", ".zip", ".tar", ".gz"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// List returns the entries of the archive at src.
+func List(src string) ([]Entry, error) {
+	format, err := DetectFormat(src)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	err = walk(src, format, func(e Entry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// Extract extracts the archive at src into the directory dest, creating
+// it if needed. Entries whose path leaves dest, symlinks pointing outside
+// it and special files are skipped; existing files are replaced. It stops
+// with an error once limits are reached.
+func Extract(src, dest string, limits Limits) (*Result, error) {
+	format, err := DetectFormat(src)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultMaxBytes
+	}
+	if limits.MaxFiles <= 0 {
+		limits.MaxFiles = DefaultMaxFiles
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return nil, err
+	}
+	// The root keeps every write in dest, symlinks included.
+	root, err := os.OpenRoot(dest)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	if format == Gzip {
+		return extractGzip(src, root, limits)
+	}
+
+	result := &Result{}
+	skip := func(name, why string) {
+		result.Skipped = append(result.Skipped, name+": "+why)
+	}
+	err = walk(src, format, func(e Entry, r io.Reader) error {
+		name, ok := localName(e.Name)
+		if !ok {
+			skip(e.Name, "its path leaves the destination")
+			return nil
+		}
+		if name == "." {
+			return nil
+		}
+		if result.Files+result.Dirs+result.Links >= limits.MaxFiles {
+			return fmt.Errorf("the archive has more than %d entries", limits.MaxFiles)
+		}
+		if dir := filepath.Dir(name); dir != "." {
+			if err := root.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case e.Mode.IsDir():
+			if err := root.MkdirAll(name, 0o755); err != nil {
+				return err
+			}
+			result.Dirs++
+		case e.Mode&fs.ModeSymlink != 0:
+			if !linkInside(name, e.Link) {
+				skip(e.Name, "it links outside the destination")
+				return nil
+			}
+			root.Remove(name)
+			if err := root.Symlink(e.Link, name); err != nil {
+				return err
+			}
+			result.Links++
+		case e.Link != "": // A tar hard link, to an entry extracted before
+			target, ok := localName(e.Link)
+			if !ok {
+				skip(e.Name, "it links outside the destination")
+				return nil
+			}
+			root.Remove(name)
+			if err := root.Link(target, name); err != nil {
+				return err
+			}
+			result.Links++
+		case e.Mode.IsRegular():
+			n, err := writeFile(root, name, r, e.Mode.Perm(), limits.MaxBytes-result.Bytes)
+			result.Bytes += n
+			if err != nil {
+				return err
+			}
+			result.Files++
+		default:
+			skip(e.Name, "special files are not extracted")
+		}
+		return nil
+	})
+	return result, err
+}
+
+// localName returns the entry name as a path local to the destination.
+func localName(name string) (string, bool) {
+	name = path.Clean(strings.TrimLeft(strings.ReplaceAll(name, `\`, "/"), "/"))
+	local := filepath.FromSlash(name)
+	return local, name == "." || filepath.IsLocal(local)
+}
+
+// linkInside reports whether a symlink at name, local to the destination,
+// pointing to target stays in it.
+func linkInside(name, target string) bool {
+	if target == "" || path.IsAbs(target) || filepath.IsAbs(target) {
+		return false
+	}
+	resolved := filepath.Join(filepath.Dir(name), filepath.FromSlash(target))
+	return resolved == "." || filepath.IsLocal(resolved)
+}
+
+// writeFile writes r to name in root, failing once more than budget bytes
+// were written. It returns the bytes written.
+func writeFile(root *os.Root, name string, r io.Reader, perm fs.FileMode, budget int64) (int64, error) {
+	if perm == 0 {
+		perm = 0o644
+	}
+	root.Remove(name) // A symlink or file being replaced
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm|0o200)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, budget+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > budget {
+		err = errTooLarge
+	}
+	return n, err
+}
+
+var errTooLarge = errors.New("the extracted files would exceed the size limit")
+
+// extractGzip decompresses a single gzip file into root, named after the
+// name stored in it or else after src.
+func extractGzip(src string, root *os.Root, limits Limits) (*Result, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip file: %w", err)
+	}
+	name := filepath.Base(zr.Name)
+	if zr.Name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		name = TrimExtension(filepath.Base(src))
+	}
+	n, err := writeFile(root, name, zr, 0o644, limits.MaxBytes)
+	return &Result{Files: 1, Bytes: n}, err
+}
+
+// walk calls fn with each entry of the archive at src and, for files, a
+// reader of its content.
+func walk(src, format string, fn func(Entry, io.Reader) error) error {
+	if format == Zip {
+		return walkZip(src, fn)
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch format {
+	case TarGz, Gzip:
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("not a gzip file: %w", err)
+		}
+		defer zr.Close()
+		if format == Gzip {
+			name := zr.Name
+			if name == "" {
+				name = TrimExtension(filepath.Base(src))
+			}
+			n, err := io.Copy(io.Discard, zr)
+			if err != nil {
+				return err
+			}
+			return fn(Entry{Name: name, Size: n, Mode: 0o644, Modified: zr.ModTime}, nil)
+		}
+		r = zr
+	case TarBz2:
+		r = bzip2.NewReader(f)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading the archive: %w", err)
+		}
+		e := Entry{Name: h.Name, Size: h.Size, Mode: h.FileInfo().Mode(), Modified: h.ModTime}
+		switch h.Typeflag {
+		case tar.TypeSymlink:
+			e.Link = h.Linkname
+		case tar.TypeLink:
+			e.Link, e.Mode = h.Linkname, 0
+		case tar.TypeXGlobalHeader:
+			continue
+		}
+		if err := fn(e, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(src string, fn func(Entry, io.Reader) error) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("not a zip archive: %w", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		e := Entry{Name: f.Name, Size: int64(f.UncompressedSize64), Mode: f.Mode(), Modified: f.Modified}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		if e.Mode&fs.ModeSymlink != 0 {
+			// A zip symlink stores its target as its content.
+			target, err := io.ReadAll(io.LimitReader(rc, 4096))
+			if err != nil {
+				rc.Close()
+				return err
+			}
+			e.Link = string(target)
+		}
+		err = fn(e, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTree creates release/ with an executable, a nested file and a
+// symlink to the executable.
+func writeTree(t *testing.T, dir string) string {
+	t.Helper()
+	root := filepath.Join(dir, "release")
+	os.MkdirAll(filepath.Join(root, "docs"), 0o755)
+	os.WriteFile(filepath.Join(root, "tool"), []byte("#!/bin/sh\necho hi\n"), 0o755)
+	os.WriteFile(filepath.Join(root, "docs", "README"), []byte("read me"), 0o644)
+	if err := os.Symlink("tool", filepath.Join(root, "latest")); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestCreateAndExtract(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs symlinks and file modes")
+	}
+	for _, name := range []string{"release.tar.gz", "release.tar", "release.zip"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := writeTree(t, dir)
+			archive := filepath.Join(dir, name)
+			created, err := Create(archive, []string{src})
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if created.Files != 2 || created.Links != 1 || created.Dirs != 2 {
+				t.Errorf("created = %+v", created)
+			}
+
+			entries, err := List(archive)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, strings.TrimSuffix(e.Name, "/"))
+			}
+			slices.Sort(names)
+			want := []string{"release", "release/docs", "release/docs/README", "release/latest", "release/tool"}
+			if !slices.Equal(names, want) {
+				t.Errorf("entries = %v, want %v", names, want)
+			}
+
+			dest := filepath.Join(dir, "out")
+			result, err := Extract(archive, dest, Limits{})
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if result.Files != 2 || result.Links != 1 || len(result.Skipped) != 0 {
+				t.Errorf("result = %+v", result)
+			}
+			info, err := os.Stat(filepath.Join(dest, "release", "tool"))
+			if err != nil || info.Mode().Perm()&0o100 == 0 {
+				t.Errorf("tool = %v, %v, want it executable", info, err)
+			}
+			if target, err := os.Readlink(filepath.Join(dest, "release", "latest")); err != nil || target != "tool" {
+				t.Errorf("latest -> %q, %v", target, err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, "release", "docs", "README")); string(data) != "read me" {
+				t.Errorf("README = %q", data)
+			}
+		})
+	}
+}
+
+// writeTar writes a tar archive of the given headers, each file holding
+// its name.
+func writeTar(t *testing.T, path string, headers ...*tar.Header) {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range headers {
+		if h.Typeflag == tar.TypeReg {
+			h.Size, h.Mode = int64(len(h.Name)), 0o644
+		}
+		tw.WriteHeader(h)
+		if h.Typeflag == tar.TypeReg {
+			tw.Write([]byte(h.Name))
+		}
+	}
+	tw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract_StaysInDestination(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.tar")
+	writeTar(t, archive,
+		&tar.Header{Name: "../escaped", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "etc", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+		&tar.Header{Name: "up", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+		&tar.Header{Name: "up/escaped", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "/abs/ok", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "fifo", Typeflag: tar.TypeFifo},
+	)
+	dest := filepath.Join(dir, "out")
+	result, err := Extract(archive, dest, Limits{})
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	// up was skipped, so up/escaped is an ordinary directory and file.
+	if result.Files != 2 || len(result.Skipped) != 4 {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped")); err == nil {
+		t.Error("an entry was written outside the destination")
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "etc")); err == nil {
+		t.Error("a symlink to /etc was created")
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "abs", "ok")); err != nil || string(data) != "/abs/ok" {
+		t.Errorf("absolute entry = %q, %v, want it under the destination", data, err)
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "up")); err != nil || !info.IsDir() {
+		t.Errorf("up = %v, %v, want a directory", info, err)
+	}
+}
+
+func TestExtract_Limits(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "many.tar")
+	writeTar(t, archive,
+		&tar.Header{Name: "a", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "b", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "c", Typeflag: tar.TypeReg},
+	)
+	if _, err := Extract(archive, filepath.Join(dir, "files"), Limits{MaxFiles: 2}); err == nil {
+		t.Error("Extract ignored MaxFiles")
+	}
+	if _, err := Extract(archive, filepath.Join(dir, "bytes"), Limits{MaxBytes: 2}); err == nil {
+		t.Error("Extract ignored MaxBytes")
+	}
+}
+
+func TestGzipFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "log.txt")
+	os.WriteFile(src, []byte(strings.Repeat("line\n", 100)), 0o644)
+	archive := filepath.Join(dir, "log.txt.gz")
+	if _, err := Create(archive, []string{src}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	result, err := Extract(archive, filepath.Join(dir, "out"), Limits{})
+	if err != nil || result.Bytes != 500 {
+		t.Fatalf("Extract = %+v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out", "log.txt")); len(data) != 500 {
+		t.Errorf("log.txt has %d bytes", len(data))
+	}
+	if _, err := Create(filepath.Join(dir, "x.gz"), []string{src, src}); err == nil {
+		t.Error("Create made a gz file of two files")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for name, want := range map[string]string{
+		"a.zip": Zip, "a.TGZ": TarGz, "a.tar.gz": TarGz, "a.tar.bz2": TarBz2, "a.tar": Tar, "a.gz": Gzip,
+	} {
+		if got, err := DetectFormat(name); err != nil || got != want {
+			t.Errorf("DetectFormat(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := DetectFormat("a.rar"); err == nil {
+		t.Error("DetectFormat accepted a.rar")
+	}
+	if got := TrimExtension("picoclaw-1.2.tar.gz"); got != "picoclaw-1.2" {
+		t.Errorf("TrimExtension = %q", got)
+	}
+}
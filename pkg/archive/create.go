@@ -0,0 +1,208 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Create writes the archive dest, of the format its extension names,
+// holding sources: files, and directories with everything in them,
+// stored under their base names. Symlinks are stored as symlinks. A gz
+// file holds a single file.
+func Create(dest string, sources []string) (result *Result, err error) {
+	format, err := DetectFormat(dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("nothing to archive")
+	}
+	if format == TarBz2 {
+		return nil, errors.New("tar.bz2 archives can be extracted but not created; use tar.gz")
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+
+	if format == Gzip {
+		return createGzip(out, sources)
+	}
+
+	var add func(name string, info fs.FileInfo, path string) error
+	var finish func() error
+	switch format {
+	case Zip:
+		zw := zip.NewWriter(out)
+		add = func(name string, info fs.FileInfo, path string) error { return addZip(zw, name, info, path) }
+		finish = zw.Close
+	default:
+		var w io.Writer = out
+		var zw *gzip.Writer
+		if format == TarGz {
+			zw = gzip.NewWriter(out)
+			w = zw
+		}
+		tw := tar.NewWriter(w)
+		add = func(name string, info fs.FileInfo, path string) error { return addTar(tw, name, info, path) }
+		finish = func() error {
+			if err := tw.Close(); err != nil || zw == nil {
+				return err
+			}
+			return zw.Close()
+		}
+	}
+
+	result = &Result{}
+	absDest, _ := filepath.Abs(dest)
+	for _, src := range sources {
+		src = filepath.Clean(src)
+		base := filepath.Dir(src)
+		err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if abs, _ := filepath.Abs(path); abs == absDest {
+				return nil // The archive being written
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+			if err := add(filepath.ToSlash(rel), info, path); err != nil {
+				return fmt.Errorf("adding %s: %w", path, err)
+			}
+			switch {
+			case info.IsDir():
+				result.Dirs++
+			case info.Mode()&fs.ModeSymlink != 0:
+				result.Links++
+			case info.Mode().IsRegular():
+				result.Files++
+				result.Bytes += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, finish()
+}
+
+func addTar(tw *tar.Writer, name string, info fs.FileInfo, path string) error {
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		link = target
+	} else if !info.IsDir() && !info.Mode().IsRegular() {
+		return nil // Sockets, devices and pipes are left out
+	}
+	h, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	}
+	if err := tw.WriteHeader(h); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	return copyFile(tw, path)
+}
+
+func addZip(zw *zip.Writer, name string, info fs.FileInfo, path string) error {
+	if !info.IsDir() && !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+		return nil
+	}
+	h, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	} else if info.Mode().IsRegular() {
+		h.Method = zip.Deflate
+	}
+	w, err := zw.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, target)
+		return err
+	case info.Mode().IsRegular():
+		return copyFile(w, path)
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func createGzip(out *os.File, sources []string) (*Result, error) {
+	if len(sources) != 1 {
+		return nil, errors.New("a gz file holds a single file; use tar.gz for several")
+	}
+	info, err := os.Stat(sources[0])
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file; use tar.gz for directories", sources[0])
+	}
+	zw := gzip.NewWriter(out)
+	zw.Name, zw.ModTime = info.Name(), info.ModTime()
+	if err := copyFile(zw, sources[0]); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return &Result{Files: 1, Bytes: info.Size()}, nil
+}
@@ -6,6 +6,7 @@ type InboundMessage struct {
 	ChatID     string            `json:"chat_id"`
 	Content    string            `json:"content"`
 	Media      []string          `json:"media,omitempty"`
+	Images     []string          `json:"images,omitempty"` // Attached images as data: or http(s) URLs
 	SessionKey string            `json:"session_key"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
@@ -14,6 +15,30 @@ type OutboundMessage struct {
 	Channel string `json:"channel"`
 	ChatID  string `json:"chat_id"`
 	Content string `json:"content"`
+	// Partial marks an in-progress streamed reply. Content holds the full
+	// text generated so far; a final non-partial message always follows.
+	Partial bool `json:"partial,omitempty"`
+	// Voice asks the channel to also deliver the reply as speech, if it can.
+	Voice bool `json:"voice,omitempty"`
+	// Media lists local files to deliver as attachments, with Content as
+	// their caption.
+	Media []string `json:"media,omitempty"`
+	// Buttons offers quick replies. Channels that can show them as buttons
+	// do, and a pressed button arrives as a message with its label as
+	// Content; elsewhere Content should say what to reply.
+	Buttons []string `json:"buttons,omitempty"`
+	// ClearButtons, with no Content, asks the channel to remove the buttons
+	// it last offered in the chat, once they can no longer be answered.
+	ClearButtons bool `json:"clear_buttons,omitempty"`
 }
 
+// MetadataReplyVoice is set to "true" in InboundMessage.Metadata when the
+// reply should be spoken: the message was a voice note, or asked for the
+// answer to be read aloud.
+const MetadataReplyVoice = "reply_voice"
+
+// MetadataButton is set to "true" in InboundMessage.Metadata when the
+// message is a pressed button (see OutboundMessage.Buttons).
+const MetadataButton = "button"
+
 type MessageHandler func(InboundMessage) error
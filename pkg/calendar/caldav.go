@@ -0,0 +1,270 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package calendar
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalDAV is a calendar collection on a CalDAV server, such as Nextcloud,
+// Radicale, Fastmail or iCloud.
+type CalDAV struct {
+	url      *url.URL // The collection, ending in a slash
+	username string
+	password string
+	loc      *time.Location
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewCalDAV returns the calendar at collectionURL, logging in with username
+// and password. Times without a zone are taken in loc.
+func NewCalDAV(collectionURL, username, password string, loc *time.Location, client *http.Client) (*CalDAV, error) {
+	u, err := url.Parse(collectionURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid CalDAV URL %q", collectionURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &CalDAV{url: u, username: username, password: password, loc: loc, client: client, now: time.Now}, nil
+}
+
+// multistatus is the answer to a REPORT.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ETag string `xml:"getetag"`
+				Data string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data><C:expand start="%[1]s" end="%[2]s"/></C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT"><C:time-range start="%[1]s" end="%[2]s"/></C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+func (c *CalDAV) Events(ctx context.Context, from, to time.Time) ([]Event, error) {
+	const layout = "20060102T150405Z"
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format(layout), to.UTC().Format(layout))
+	resp, err := c.do(ctx, "REPORT", c.url.String(), strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("list events", resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("list events: invalid answer: %w", err)
+	}
+	var events []Event
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.Data == "" {
+				continue
+			}
+			parsed, err := parseICalEvents(ps.Prop.Data, c.loc)
+			if err != nil {
+				return nil, fmt.Errorf("list events: %s: %w", r.Href, err)
+			}
+			for _, e := range parsed {
+				// Servers that don't expand send repeating events whole.
+				if e.End.After(from) && e.Start.Before(to) || e.Recurring {
+					e.ID = c.resolve(r.Href).Path
+					events = append(events, e)
+				}
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+func (c *CalDAV) Event(ctx context.Context, id string) (*Event, error) {
+	data, _, err := c.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	events, err := parseICalEvents(data, c.loc)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	events[0].ID = id
+	return &events[0], nil
+}
+
+func (c *CalDAV) Create(ctx context.Context, event Event) (*Event, error) {
+	uid := uuid.NewString()
+	target := c.url.JoinPath(uid + ".ics")
+	resp, err := c.do(ctx, http.MethodPut, target.String(),
+		strings.NewReader(formatICalEvent(uid, event, c.now())), map[string]string{
+			"Content-Type":  "text/calendar; charset=utf-8",
+			"If-None-Match": "*",
+		})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent &&
+		resp.StatusCode != http.StatusOK {
+		return nil, statusError("create event", resp)
+	}
+	event.ID = target.Path
+	return &event, nil
+}
+
+func (c *CalDAV) Update(ctx context.Context, event Event) (*Event, error) {
+	data, etag, err := c.get(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := updateICalEvent(data, event, c.now())
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag != "" {
+		// Fail rather than overwrite a change made meanwhile.
+		headers["If-Match"] = etag
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.resolve(event.ID).String(), strings.NewReader(updated), headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, fmt.Errorf("update event: it was changed meanwhile; try again")
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, statusError("update event", resp)
+	}
+	return &event, nil
+}
+
+func (c *CalDAV) Delete(ctx context.Context, id string) error {
+	data, etag, err := c.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if events, err := parseICalEvents(data, c.loc); err == nil && len(events) > 0 && events[0].Recurring {
+		return ErrRecurring
+	}
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	resp, err := c.do(ctx, http.MethodDelete, c.resolve(id).String(), nil, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return statusError("delete event", resp)
+	}
+	return nil
+}
+
+// get fetches the calendar object id and its ETag.
+func (c *CalDAV) get(ctx context.Context, id string) (data, etag string, err error) {
+	if !strings.HasPrefix(id, c.url.Path) || strings.Contains(id, "..") {
+		return "", "", ErrNotFound
+	}
+	resp, err := c.do(ctx, http.MethodGet, c.resolve(id).String(), nil, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", statusError("get event", resp)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", "", err
+	}
+	return string(body), resp.Header.Get("ETag"), nil
+}
+
+// resolve returns the URL of href, a path on the server.
+func (c *CalDAV) resolve(href string) *url.URL {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return c.url
+	}
+	return c.url.ResolveReference(ref)
+}
+
+func (c *CalDAV) do(
+	ctx context.Context, method, target string, body io.Reader, headers map[string]string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CalDAV request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// statusError describes an unexpected answer to doing what.
+func statusError(what string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+	if msg := strings.TrimSpace(string(body)); msg != "" && !strings.HasPrefix(msg, "<") {
+		return fmt.Errorf("%s: %s: %s", what, resp.Status, msg)
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s: %s; check the credentials", what, resp.Status)
+	}
+	return fmt.Errorf("%s: %s", what, resp.Status)
+}
@@ -0,0 +1,162 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCalDAV is a CalDAV collection at /cal/ holding objects by path.
+type fakeCalDAV struct {
+	mu      sync.Mutex
+	objects map[string]string
+	etags   map[string]string
+	reports []string
+}
+
+func (f *fakeCalDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, pass, _ := r.BasicAuth(); user != "me" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case "REPORT":
+		body, _ := io.ReadAll(r.Body)
+		f.reports = append(f.reports, string(body))
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`)
+		for path, data := range f.objects {
+			io.WriteString(w, "<d:response><d:href>"+path+"</d:href><d:propstat><d:prop><d:getetag>"+
+				f.etags[path]+"</d:getetag><c:calendar-data>"+data+
+				"</c:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>")
+		}
+		io.WriteString(w, "</d:multistatus>")
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", f.etags[r.URL.Path])
+		io.WriteString(w, data)
+	case http.MethodPut:
+		_, exists := f.objects[r.URL.Path]
+		if r.Header.Get("If-None-Match") == "*" && exists ||
+			r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != f.etags[r.URL.Path] {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+		f.etags[r.URL.Path] = `"` + time.Now().Format(time.RFC3339Nano) + `"`
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestCalDAV(t *testing.T) (*CalDAV, *fakeCalDAV) {
+	t.Helper()
+	fake := &fakeCalDAV{objects: map[string]string{}, etags: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	cal, err := NewCalDAV(server.URL+"/cal", "me", "secret", time.UTC, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal, fake
+}
+
+func TestCalDAV_CreateListUpdateDelete(t *testing.T) {
+	cal, fake := newTestCalDAV(t)
+	ctx := context.Background()
+	start := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+
+	created, err := cal.Create(ctx, Event{Title: "Dentist", Start: start, End: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.ID, "/cal/") || !strings.HasSuffix(created.ID, ".ics") {
+		t.Fatalf("id = %q", created.ID)
+	}
+
+	events, err := cal.Events(ctx, start.Add(-time.Hour), start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != created.ID || events[0].Title != "Dentist" || !events[0].Start.Equal(start) {
+		t.Fatalf("events = %+v", events)
+	}
+	if !strings.Contains(fake.reports[0], `<C:time-range start="20261016T140000Z" end="20261017T150000Z"/>`) {
+		t.Errorf("report = %s", fake.reports[0])
+	}
+	// Events outside the period are left out.
+	if events, _ := cal.Events(ctx, start.Add(2*time.Hour), start.Add(3*time.Hour)); len(events) != 0 {
+		t.Errorf("events later = %+v", events)
+	}
+
+	moved := events[0]
+	moved.Start, moved.End = start.Add(time.Hour), start.Add(2*time.Hour)
+	if _, err := cal.Update(ctx, moved); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cal.Event(ctx, created.ID)
+	if err != nil || !got.Start.Equal(moved.Start) || got.Title != "Dentist" {
+		t.Fatalf("event after update = %+v, %v", got, err)
+	}
+	if !strings.Contains(fake.objects[created.ID], "SEQUENCE:1") {
+		t.Errorf("object = %s", fake.objects[created.ID])
+	}
+
+	if err := cal.Delete(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cal.Event(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("event after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCalDAV_RefusesOtherPaths(t *testing.T) {
+	cal, _ := newTestCalDAV(t)
+	for _, id := range []string{"/other/x.ics", "/cal/../other/x.ics"} {
+		if _, err := cal.Event(context.Background(), id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Event(%q) = %v, want ErrNotFound", id, err)
+		}
+	}
+}
+
+func TestCalDAV_DeleteRepeatingEvent(t *testing.T) {
+	cal, fake := newTestCalDAV(t)
+	fake.objects["/cal/r.ics"] = "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:r\r\nSUMMARY:Standup\r\n" +
+		"DTSTART:20261016T090000Z\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := cal.Delete(context.Background(), "/cal/r.ics"); !errors.Is(err, ErrRecurring) {
+		t.Errorf("Delete = %v, want ErrRecurring", err)
+	}
+	if _, ok := fake.objects["/cal/r.ics"]; !ok {
+		t.Error("repeating event was deleted")
+	}
+}
+
+func TestCalDAV_BadCredentials(t *testing.T) {
+	cal, _ := newTestCalDAV(t)
+	cal.password = "wrong"
+	_, err := cal.Events(context.Background(), time.Now(), time.Now().Add(time.Hour))
+	if err == nil || !strings.Contains(err.Error(), "check the credentials") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestNewCalDAV_InvalidURL(t *testing.T) {
+	if _, err := NewCalDAV("caldav.example.com/cal", "", "", time.UTC, nil); err == nil {
+		t.Error("expected an error for a URL without a scheme")
+	}
+}
@@ -0,0 +1,49 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package calendar reads and changes events in a CalDAV or Google calendar.
+package calendar
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Event is a calendar event. All-day events start and end at midnight, with
+// End the day after the last one.
+type Event struct {
+	ID          string // The calendar's own ID: the object's URL path for CalDAV
+	Title       string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Location    string
+	Description string
+	Recurring   bool // An occurrence of a repeating event
+}
+
+// Calendar is a calendar that can be read and changed.
+type Calendar interface {
+	// Events returns the events overlapping from to to, by start time, with
+	// repeating events expanded into their occurrences.
+	Events(ctx context.Context, from, to time.Time) ([]Event, error)
+	// Event returns the event with id.
+	Event(ctx context.Context, id string) (*Event, error)
+	// Create adds event, ignoring its ID, and returns it as saved.
+	Create(ctx context.Context, event Event) (*Event, error)
+	// Update replaces the event with event.ID by event.
+	Update(ctx context.Context, event Event) (*Event, error)
+	// Delete removes the event with id.
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	// ErrNotFound means there is no event with the ID given.
+	ErrNotFound = errors.New("no such event")
+	// ErrRecurring means a repeating event was to be changed where only
+	// single events can be.
+	ErrRecurring = errors.New("the event repeats; change it in your calendar app")
+)
@@ -0,0 +1,227 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const googleAPIURL = "https://www.googleapis.com/calendar/v3"
+
+// TokenSource gives the access token of a request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Google is a calendar of a Google account.
+type Google struct {
+	calendarID string
+	tokens     TokenSource
+	loc        *time.Location
+	client     *http.Client
+	apiURL     string
+}
+
+// NewGoogle returns the calendar calendarID ("primary" when empty),
+// reached with tokens. All-day events are placed in loc.
+func NewGoogle(calendarID string, tokens TokenSource, loc *time.Location, client *http.Client) *Google {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Google{calendarID: calendarID, tokens: tokens, loc: loc, client: client, apiURL: googleAPIURL}
+}
+
+// googleEvent is an event resource of the Calendar API.
+type googleEvent struct {
+	ID               string     `json:"id,omitempty"`
+	Summary          string     `json:"summary"`
+	Location         string     `json:"location"`
+	Description      string     `json:"description"`
+	Start            googleTime `json:"start"`
+	End              googleTime `json:"end"`
+	RecurringEventID string     `json:"recurringEventId,omitempty"`
+	Recurrence       []string   `json:"recurrence,omitempty"`
+}
+
+// googleTime is the start or end of an event: a date for all-day events,
+// a time otherwise. The other field is sent as null, so a change from one
+// kind to the other takes.
+type googleTime struct {
+	Date     *string `json:"date"`
+	DateTime *string `json:"dateTime"`
+}
+
+func (g *Google) toEvent(e googleEvent) (Event, error) {
+	event := Event{
+		ID:          e.ID,
+		Title:       e.Summary,
+		Location:    e.Location,
+		Description: e.Description,
+		Recurring:   e.RecurringEventID != "" || len(e.Recurrence) > 0,
+	}
+	var err error
+	if event.Start, event.AllDay, err = g.parseTime(e.Start); err != nil {
+		return event, err
+	}
+	event.End, _, err = g.parseTime(e.End)
+	return event, err
+}
+
+func (g *Google) parseTime(t googleTime) (time.Time, bool, error) {
+	switch {
+	case t.DateTime != nil:
+		at, err := time.Parse(time.RFC3339, *t.DateTime)
+		return at, false, err
+	case t.Date != nil:
+		at, err := time.ParseInLocation(time.DateOnly, *t.Date, g.loc)
+		return at, true, err
+	}
+	return time.Time{}, false, fmt.Errorf("event time missing")
+}
+
+func fromEvent(event Event) googleEvent {
+	e := googleEvent{Summary: event.Title, Location: event.Location, Description: event.Description}
+	if event.AllDay {
+		start, end := event.Start.Format(time.DateOnly), event.End.Format(time.DateOnly)
+		e.Start.Date, e.End.Date = &start, &end
+	} else {
+		start, end := event.Start.Format(time.RFC3339), event.End.Format(time.RFC3339)
+		e.Start.DateTime, e.End.DateTime = &start, &end
+	}
+	return e
+}
+
+func (g *Google) Events(ctx context.Context, from, to time.Time) ([]Event, error) {
+	query := url.Values{
+		"timeMin":      {from.Format(time.RFC3339)},
+		"timeMax":      {to.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {"250"},
+	}
+	var events []Event
+	for {
+		var page struct {
+			Items         []googleEvent `json:"items"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+		if err := g.call(ctx, http.MethodGet, g.eventsURL("")+"?"+query.Encode(), nil, &page); err != nil {
+			return nil, fmt.Errorf("list events: %w", err)
+		}
+		for _, item := range page.Items {
+			event, err := g.toEvent(item)
+			if err != nil {
+				return nil, fmt.Errorf("list events: %s: %w", item.ID, err)
+			}
+			events = append(events, event)
+		}
+		if page.NextPageToken == "" || len(events) >= 1000 {
+			return events, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+func (g *Google) Event(ctx context.Context, id string) (*Event, error) {
+	var item googleEvent
+	if err := g.call(ctx, http.MethodGet, g.eventsURL(id), nil, &item); err != nil {
+		return nil, err
+	}
+	event, err := g.toEvent(item)
+	return &event, err
+}
+
+func (g *Google) Create(ctx context.Context, event Event) (*Event, error) {
+	var item googleEvent
+	if err := g.call(ctx, http.MethodPost, g.eventsURL(""), fromEvent(event), &item); err != nil {
+		return nil, fmt.Errorf("create event: %w", err)
+	}
+	event.ID = item.ID
+	return &event, nil
+}
+
+// Update changes event; an occurrence of a repeating event is changed on
+// its own.
+func (g *Google) Update(ctx context.Context, event Event) (*Event, error) {
+	var item googleEvent
+	if err := g.call(ctx, http.MethodPatch, g.eventsURL(event.ID), fromEvent(event), &item); err != nil {
+		return nil, fmt.Errorf("update event: %w", err)
+	}
+	return &event, nil
+}
+
+func (g *Google) Delete(ctx context.Context, id string) error {
+	if err := g.call(ctx, http.MethodDelete, g.eventsURL(id), nil, nil); err != nil {
+		return fmt.Errorf("delete event: %w", err)
+	}
+	return nil
+}
+
+func (g *Google) eventsURL(id string) string {
+	u := g.apiURL + "/calendars/" + url.PathEscape(g.calendarID) + "/events"
+	if id != "" {
+		u += "/" + url.PathEscape(id)
+	}
+	return u
+}
+
+// call sends in as JSON and decodes the answer into out.
+func (g *Google) call(ctx context.Context, method, target string, in, out any) error {
+	token, err := g.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("google Calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case resp.StatusCode/100 != 2:
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	case out == nil:
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,334 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+
+	// GoogleScope lets PicoClaw read and change events, not calendars.
+	GoogleScope = "https://www.googleapis.com/auth/calendar.events"
+
+	// tokenRefreshMargin renews the access token this long before it
+	// expires.
+	tokenRefreshMargin = time.Minute
+)
+
+// ErrNotLoggedIn means there is no saved Google login.
+var ErrNotLoggedIn = errors.New(`not logged in to Google Calendar; run "picoclaw auth google-calendar"`)
+
+// GoogleCredentials are the saved tokens of a Google login.
+type GoogleCredentials struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// DefaultGoogleTokenPath is ~/.picoclaw/auth/google-calendar.json.
+func DefaultGoogleTokenPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".picoclaw", "auth", "google-calendar.json")
+}
+
+// GoogleAuth logs in to Google with the OAuth client of a "Desktop app"
+// the user created, and keeps the access token fresh. Thread-safe.
+type GoogleAuth struct {
+	clientID     string
+	clientSecret string
+	path         string
+	httpClient   *http.Client
+	authURL      string
+	tokenURL     string
+	now          func() time.Time
+
+	mu    sync.Mutex
+	creds *GoogleCredentials
+}
+
+// NewGoogleAuth keeps its credentials in the file at path.
+func NewGoogleAuth(clientID, clientSecret, path string, httpClient *http.Client) *GoogleAuth {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &GoogleAuth{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		path:         path,
+		httpClient:   httpClient,
+		authURL:      googleAuthURL,
+		tokenURL:     googleTokenURL,
+		now:          time.Now,
+	}
+}
+
+// Path is the file the credentials are kept in.
+func (a *GoogleAuth) Path() string {
+	return a.path
+}
+
+// Login runs the OAuth flow for installed apps. It listens for Google's
+// redirect on a loopback port and passes the URL to open to prompt. On a
+// machine without a browser, the user opens it elsewhere and, when the
+// browser fails to load the redirect, pastes its address (or just the
+// code) to pasted instead. The tokens are then saved.
+func (a *GoogleAuth) Login(ctx context.Context, prompt func(authURL string), pasted <-chan string) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("listen for the redirect: %w", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/", listener.Addr().(*net.TCPAddr).Port)
+
+	state, verifier := randomToken(), randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL := a.authURL + "?" + url.Values{
+		"client_id":             {a.clientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {GoogleScope},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"access_type":           {"offline"},
+		"prompt":                {"consent"},
+	}.Encode()
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			code, err := authCode(r.URL.Query(), state)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				select {
+				case errs <- err:
+				default:
+				}
+				return
+			}
+			fmt.Fprintln(w, "PicoClaw is now logged in to Google Calendar. You can close this page.")
+			select {
+			case codes <- code:
+			default:
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	prompt(authURL)
+
+	var code string
+	for code == "" {
+		select {
+		case code = <-codes:
+		case err := <-errs:
+			return err
+		case line, ok := <-pasted:
+			if !ok {
+				pasted = nil
+				continue
+			}
+			if code, err = pastedCode(line, state); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	creds, err := a.requestToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return err
+	}
+	if creds.RefreshToken == "" {
+		return errors.New("google didn't grant offline access; try again")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.creds = creds
+	return a.save()
+}
+
+// authCode returns the code of a redirect to us, checking its state.
+func authCode(query url.Values, state string) (string, error) {
+	if e := query.Get("error"); e != "" {
+		return "", fmt.Errorf("google refused: %s", e)
+	}
+	if query.Get("state") != state {
+		return "", errors.New("the login answer doesn't belong to this login; start again")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("the login answer has no code")
+	}
+	return code, nil
+}
+
+// pastedCode reads the code from what the user pasted: the address the
+// browser was redirected to, or the code alone.
+func pastedCode(line, state string) (string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", nil
+	}
+	if u, err := url.Parse(line); err == nil && u.RawQuery != "" {
+		return authCode(u.Query(), state)
+	}
+	return line, nil
+}
+
+// Token returns a valid access token, refreshing it when about to expire.
+func (a *GoogleAuth) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.creds == nil {
+		if err := a.load(); err != nil {
+			return "", err
+		}
+	}
+	if a.now().Add(tokenRefreshMargin).Before(a.creds.ExpiresAt) {
+		return a.creds.AccessToken, nil
+	}
+
+	fresh, err := a.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {a.creds.RefreshToken},
+	})
+	if err != nil {
+		return "", fmt.Errorf("refresh the Google login: %w", err)
+	}
+	a.creds.AccessToken, a.creds.ExpiresAt = fresh.AccessToken, fresh.ExpiresAt
+	if fresh.RefreshToken != "" {
+		a.creds.RefreshToken = fresh.RefreshToken
+	}
+	return a.creds.AccessToken, a.save()
+}
+
+// Logout removes the saved credentials. Removing nothing is not an error.
+func (a *GoogleAuth) Logout() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.creds = nil
+	if err := os.Remove(a.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete credentials: %w", err)
+	}
+	return nil
+}
+
+func (a *GoogleAuth) requestToken(ctx context.Context, form url.Values) (*GoogleCredentials, error) {
+	form.Set("client_id", a.clientID)
+	form.Set("client_secret", a.clientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		Error        string `json:"error"`
+		Description  string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("token request: %s", resp.Status)
+	}
+	if out.Error != "" {
+		if out.Error == "invalid_grant" {
+			return nil, fmt.Errorf("%w (the login expired or was revoked)", ErrNotLoggedIn)
+		}
+		return nil, fmt.Errorf("token request: %s: %s", out.Error, out.Description)
+	}
+	if out.AccessToken == "" {
+		return nil, fmt.Errorf("token request: %s: no token in the answer", resp.Status)
+	}
+	return &GoogleCredentials{
+		AccessToken:  out.AccessToken,
+		RefreshToken: out.RefreshToken,
+		ExpiresAt:    a.now().Add(time.Duration(out.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func (a *GoogleAuth) load() error {
+	data, err := os.ReadFile(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotLoggedIn
+	}
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	var creds GoogleCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return fmt.Errorf("parse credentials %s: %w", a.path, err)
+	}
+	if creds.RefreshToken == "" {
+		return ErrNotLoggedIn
+	}
+	a.creds = &creds
+	return nil
+}
+
+// save writes the credentials atomically, readable only by the owner.
+func (a *GoogleAuth) save() error {
+	data, err := json.MarshalIndent(a.creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode credentials: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	return nil
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
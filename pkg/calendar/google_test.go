@@ -0,0 +1,153 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type staticToken string
+
+func (s staticToken) Token(context.Context) (string, error) { return string(s), nil }
+
+func TestGoogle_EventsAndUpdate(t *testing.T) {
+	var patched map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/calendars/work@example.com/events":
+			if r.URL.Query().Get("singleEvents") != "true" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("pageToken") == "" {
+				json.NewEncoder(w).Encode(map[string]any{
+					"items": []map[string]any{{
+						"id": "a", "summary": "Dentist",
+						"start": map[string]any{"dateTime": "2026-10-16T15:00:00+02:00"},
+						"end":   map[string]any{"dateTime": "2026-10-16T16:00:00+02:00"},
+					}},
+					"nextPageToken": "p2",
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{
+				"id": "b_20261020", "summary": "Standup", "recurringEventId": "b",
+				"start": map[string]any{"date": "2026-10-20"}, "end": map[string]any{"date": "2026-10-21"},
+			}}})
+		case r.Method == http.MethodPatch && r.URL.Path == "/calendars/work@example.com/events/a":
+			json.NewDecoder(r.Body).Decode(&patched)
+			json.NewEncoder(w).Encode(map[string]any{"id": "a"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "Not Found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	cal := NewGoogle("work@example.com", staticToken("tok"), time.UTC, nil)
+	cal.apiURL = server.URL
+	ctx := context.Background()
+
+	events, err := cal.Events(ctx, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Title != "Dentist" || events[0].AllDay ||
+		!events[1].AllDay || !events[1].Recurring {
+		t.Fatalf("events = %+v", events)
+	}
+	if !events[0].Start.Equal(time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("start = %v", events[0].Start)
+	}
+
+	events[0].AllDay = true
+	events[0].Start = time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	events[0].End = events[0].Start.AddDate(0, 0, 1)
+	if _, err := cal.Update(ctx, events[0]); err != nil {
+		t.Fatal(err)
+	}
+	start := patched["start"].(map[string]any)
+	if start["date"] != "2026-10-17" || start["dateTime"] != nil {
+		t.Errorf("patched start = %v", start)
+	}
+
+	if _, err := cal.Event(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Event(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGoogleAuth_TokenRefreshes(t *testing.T) {
+	var refreshes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" ||
+			r.Form.Get("client_id") != "id" {
+			t.Errorf("form = %v", r.Form)
+		}
+		refreshes++
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "fresh", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "auth", "google-calendar.json")
+	auth := NewGoogleAuth("id", "secret", path, nil)
+	auth.tokenURL = server.URL
+	if _, err := auth.Token(context.Background()); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("Token without login = %v, want ErrNotLoggedIn", err)
+	}
+
+	auth.creds = &GoogleCredentials{AccessToken: "old", RefreshToken: "refresh", ExpiresAt: time.Now()}
+	for range 2 {
+		token, err := auth.Token(context.Background())
+		if err != nil || token != "fresh" {
+			t.Fatalf("Token = %q, %v", token, err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+
+	// The refreshed token is saved, keeping the refresh token.
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("saved credentials: %v, %v", info, err)
+	}
+	reloaded := NewGoogleAuth("id", "secret", path, nil)
+	if token, err := reloaded.Token(context.Background()); err != nil || token != "fresh" {
+		t.Errorf("Token after reload = %q, %v", token, err)
+	}
+
+	if err := reloaded.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("credentials left after logout: %v", err)
+	}
+}
+
+func TestPastedCode(t *testing.T) {
+	code, err := pastedCode(" http://127.0.0.1:4321/?state=s&code=4/abc&scope=x ", "s")
+	if err != nil || code != "4/abc" {
+		t.Errorf("pastedCode(url) = %q, %v", code, err)
+	}
+	if code, _ := pastedCode("4/abc", "s"); code != "4/abc" {
+		t.Errorf("pastedCode(code) = %q", code)
+	}
+	if _, err := pastedCode("http://127.0.0.1:4321/?state=other&code=x", "s"); err == nil {
+		t.Error("expected an error for another login's answer")
+	}
+	if _, err := pastedCode("http://127.0.0.1:4321/?error=access_denied", "s"); err == nil ||
+		!strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("pastedCode(error) = %v", err)
+	}
+}
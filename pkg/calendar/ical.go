@@ -0,0 +1,281 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icalProperty is a content line of an iCalendar object, unfolded.
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// unfoldICal splits data into content lines, joining folded ones.
+func unfoldICal(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseICalLine reads a content line: NAME;PARAM=value:VALUE.
+func parseICalLine(line string) icalProperty {
+	// The value starts at the first colon outside quoted parameter values.
+	quoted, colon := false, -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icalProperty{Name: strings.ToUpper(line)}
+	}
+	p := icalProperty{Value: line[colon+1:], Params: map[string]string{}}
+	parts := strings.Split(line[:colon], ";")
+	p.Name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p
+}
+
+var icalUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+
+// parseICalEvents returns the VEVENTs of an iCalendar object, with times
+// not tied to a zone taken in loc.
+func parseICalEvents(data string, loc *time.Location) ([]Event, error) {
+	var (
+		events  []Event
+		current *Event
+		hasEnd  bool
+		dur     time.Duration
+		depth   int // Components nested in the VEVENT, such as VALARM
+	)
+	for _, line := range unfoldICal(data) {
+		p := parseICalLine(line)
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VEVENT") && current == nil:
+			current, hasEnd, dur = &Event{}, false, 0
+			continue
+		case current == nil:
+			continue
+		case p.Name == "BEGIN":
+			depth++
+			continue
+		case p.Name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		var err error
+		switch p.Name {
+		case "END":
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no start", current.Title)
+			}
+			if !hasEnd {
+				switch {
+				case dur > 0:
+					current.End = current.Start.Add(dur)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				default:
+					current.End = current.Start
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case "SUMMARY":
+			current.Title = icalUnescaper.Replace(p.Value)
+		case "LOCATION":
+			current.Location = icalUnescaper.Replace(p.Value)
+		case "DESCRIPTION":
+			current.Description = icalUnescaper.Replace(p.Value)
+		case "DTSTART":
+			current.Start, current.AllDay, err = parseICalTime(p, loc)
+		case "DTEND":
+			current.End, _, err = parseICalTime(p, loc)
+			hasEnd = true
+		case "DURATION":
+			dur, err = parseICalDuration(p.Value)
+		case "RRULE", "RDATE", "RECURRENCE-ID":
+			current.Recurring = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+	}
+	return events, nil
+}
+
+// parseICalTime reads a DATE or DATE-TIME value, in UTC, in its TZID or,
+// floating, in loc.
+func parseICalTime(p icalProperty, loc *time.Location) (t time.Time, allDay bool, err error) {
+	if p.Params["VALUE"] == "DATE" || len(p.Value) == 8 {
+		t, err = time.ParseInLocation("20060102", p.Value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		t, err = time.Parse("20060102T150405Z", p.Value)
+		return t, false, err
+	}
+	if tzid := p.Params["TZID"]; tzid != "" {
+		// Servers often name zones the IANA way; others fall back to loc.
+		if zone, zerr := time.LoadLocation(tzid); zerr == nil {
+			loc = zone
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", p.Value, loc)
+	return t, false, err
+}
+
+var icalDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICalDuration reads a DURATION value such as PT1H30M or P1D.
+func parseICalDuration(s string) (time.Duration, error) {
+	m := icalDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if n, err := strconv.Atoi(m[i+2]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// eventProperties are the content lines for event's fields.
+func eventProperties(event Event) []string {
+	var lines []string
+	if event.AllDay {
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+event.Start.Format("20060102"),
+			"DTEND;VALUE=DATE:"+event.End.Format("20060102"))
+	} else {
+		lines = append(lines,
+			"DTSTART:"+event.Start.UTC().Format("20060102T150405Z"),
+			"DTEND:"+event.End.UTC().Format("20060102T150405Z"))
+	}
+	lines = append(lines, "SUMMARY:"+icalEscaper.Replace(event.Title))
+	if event.Location != "" {
+		lines = append(lines, "LOCATION:"+icalEscaper.Replace(event.Location))
+	}
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icalEscaper.Replace(event.Description))
+	}
+	return lines
+}
+
+// formatICalEvent returns a calendar object holding event alone.
+func formatICalEvent(uid string, event Event, now time.Time) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//PicoClaw//Calendar//EN",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + now.UTC().Format("20060102T150405Z"),
+	}
+	lines = append(lines, eventProperties(event)...)
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+	return foldICal(lines)
+}
+
+// replacedProperties are the properties updateICalEvent sets.
+var replacedProperties = map[string]bool{
+	"DTSTART": true, "DTEND": true, "DURATION": true, "SUMMARY": true,
+	"LOCATION": true, "DESCRIPTION": true, "DTSTAMP": true, "SEQUENCE": true,
+}
+
+// updateICalEvent rewrites the event in the calendar object data with the
+// fields of event, keeping its other properties and components, such as
+// alarms and attendees.
+func updateICalEvent(data string, event Event, now time.Time) (string, error) {
+	var (
+		out      []string
+		inEvent  bool
+		depth    int
+		sequence int
+		done     bool
+	)
+	for _, line := range unfoldICal(data) {
+		p := parseICalLine(line)
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VEVENT") && !done:
+			inEvent = true
+		case !inEvent:
+		case p.Name == "BEGIN":
+			depth++
+		case p.Name == "END" && depth > 0:
+			depth--
+		case depth > 0:
+		case p.Name == "RRULE" || p.Name == "RDATE":
+			return "", ErrRecurring
+		case p.Name == "SEQUENCE":
+			sequence, _ = strconv.Atoi(p.Value)
+			continue
+		case replacedProperties[p.Name]:
+			continue
+		case p.Name == "END":
+			out = append(out, "DTSTAMP:"+now.UTC().Format("20060102T150405Z"), fmt.Sprintf("SEQUENCE:%d", sequence+1))
+			out = append(out, eventProperties(event)...)
+			inEvent, done = false, true
+		}
+		out = append(out, line)
+	}
+	if !done {
+		return "", ErrNotFound
+	}
+	return foldICal(out), nil
+}
+
+// foldICal joins content lines with CRLF, folding those over 75 bytes.
+func foldICal(lines []string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		// Continuation lines start with a space, which counts too.
+		for limit := 75; len(line) > limit; limit = 74 {
+			cut := limit
+			// Don't split a UTF-8 character.
+			for cut > 1 && line[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			sb.WriteString(line[:cut] + "\r\n ")
+			line = line[cut:]
+		}
+		sb.WriteString(line + "\r\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleICal = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Dentist\\, check-up\r\n" +
+	"DTSTART;TZID=Europe/Paris:20261016T150000\r\nDURATION:PT45M\r\n" +
+	"DESCRIPTION:Bring the\r\n  card\\nand the form\r\n" +
+	"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\n" +
+	"ATTENDEE:mailto:a@example.com\r\nSEQUENCE:2\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:2\r\nSUMMARY:Holiday\r\nDTSTART;VALUE=DATE:20261020\r\n" +
+	"RRULE:FREQ=YEARLY\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestParseICalEvents(t *testing.T) {
+	events, err := parseICalEvents(sampleICal, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+
+	paris, _ := time.LoadLocation("Europe/Paris")
+	dentist := events[0]
+	if dentist.Title != "Dentist, check-up" || dentist.Description != "Bring the card\nand the form" {
+		t.Errorf("dentist = %+v", dentist)
+	}
+	if !dentist.Start.Equal(time.Date(2026, 10, 16, 15, 0, 0, 0, paris)) ||
+		dentist.End.Sub(dentist.Start) != 45*time.Minute || dentist.AllDay || dentist.Recurring {
+		t.Errorf("dentist times = %v to %v", dentist.Start, dentist.End)
+	}
+
+	holiday := events[1]
+	if !holiday.AllDay || !holiday.Recurring || !holiday.End.Equal(time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("holiday = %+v", holiday)
+	}
+}
+
+func TestParseICalEvents_NoStart(t *testing.T) {
+	_, err := parseICalEvents("BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n", time.UTC)
+	if err == nil {
+		t.Fatal("expected an error for an event without a start")
+	}
+}
+
+func TestParseICalDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+		"P1W":     7 * 24 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+	} {
+		if got, err := parseICalDuration(in); err != nil || got != want {
+			t.Errorf("parseICalDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseICalDuration("1 hour"); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestUpdateICalEvent_KeepsOtherProperties(t *testing.T) {
+	single := strings.SplitAfter(sampleICal, "END:VEVENT\r\n")[0] + "END:VCALENDAR\r\n"
+	start := time.Date(2026, 10, 16, 16, 0, 0, 0, time.UTC)
+	event := Event{Title: "Dentist", Start: start, End: start.Add(time.Hour), Location: "Rue X; 2nd floor"}
+
+	out, err := updateICalEvent(single, event, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"DTSTART:20261016T160000Z\r\n", "DTEND:20261016T170000Z\r\n", "SUMMARY:Dentist\r\n",
+		"LOCATION:Rue X\\; 2nd floor\r\n", "SEQUENCE:3\r\n", "ATTENDEE:mailto:a@example.com\r\n",
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("updated object lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DURATION") || strings.Contains(out, "Bring the") {
+		t.Errorf("old properties kept:\n%s", out)
+	}
+
+	if _, err := updateICalEvent(sampleICal, event, start); err != nil {
+		t.Errorf("update of the first event = %v", err)
+	}
+	repeating := "BEGIN:VCALENDAR\r\n" + strings.SplitAfter(sampleICal, "END:VEVENT\r\n")[1]
+	if _, err := updateICalEvent(repeating, event, start); !errors.Is(err, ErrRecurring) {
+		t.Errorf("update of a repeating event = %v, want ErrRecurring", err)
+	}
+}
+
+func TestFoldICal(t *testing.T) {
+	long := "DESCRIPTION:" + strings.Repeat("é", 60)
+	out := foldICal([]string{long})
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d bytes: %q", len(line), line)
+		}
+	}
+	if got := unfoldICal(out); len(got) != 1 || got[0] != long {
+		t.Errorf("unfolded = %q", got)
+	}
+}
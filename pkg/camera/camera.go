@@ -0,0 +1,99 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package camera takes photos with a V4L2 camera, such as a USB webcam, or
+// with a command for cameras V4L2 can't reach.
+package camera
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Photos from commands may be JPEG or PNG
+	_ "image/png"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDevice = "/dev/video0"
+	defaultWidth  = 1280
+	defaultHeight = 720
+	// captureTimeout bounds the time a capture takes, warm-up included.
+	captureTimeout = 30 * time.Second
+)
+
+// Options say how to take a photo.
+type Options struct {
+	Device string // V4L2 device; default /dev/video0
+	Width  int    // Size asked of the camera, which picks the nearest it can do; default 1280x720
+	Height int
+
+	// Command takes the photo instead, run by the shell with {path}
+	// replaced by the file to write the JPEG or PNG to, e.g.
+	// "fswebcam --no-banner -r 1280x720 {path}" or "libcamera-still -o {path}".
+	Command string
+}
+
+// Photo is a photo taken.
+type Photo struct {
+	Data     []byte
+	MIMEType string // image/jpeg or image/png
+	Width    int
+	Height   int
+}
+
+// Capture takes a photo.
+func Capture(ctx context.Context, opts Options) (*Photo, error) {
+	ctx, cancel := context.WithTimeout(ctx, captureTimeout)
+	defer cancel()
+	if opts.Command != "" {
+		return captureCommand(ctx, opts.Command)
+	}
+	if opts.Device == "" {
+		opts.Device = DefaultDevice
+	}
+	if opts.Width <= 0 || opts.Height <= 0 {
+		opts.Width, opts.Height = defaultWidth, defaultHeight
+	}
+	return captureV4L2(ctx, opts)
+}
+
+func captureCommand(ctx context.Context, command string) (*Photo, error) {
+	dir, err := os.MkdirTemp("", "picoclaw-photo-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "photo")
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", strings.ReplaceAll(command, "{path}", path))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("camera command failed: %w: %s", err, bytes.TrimSpace(out))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("the camera command wrote no photo to {path}: %w", err)
+	}
+	return newPhoto(data)
+}
+
+// newPhoto checks that data is a JPEG or PNG image and reads its size.
+func newPhoto(data []byte) (*Photo, error) {
+	mimeType := http.DetectContentType(data)
+	if mimeType != "image/jpeg" && mimeType != "image/png" {
+		return nil, fmt.Errorf("the photo is %s, not a JPEG or PNG image", mimeType)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unreadable photo: %w", err)
+	}
+	return &Photo{Data: data, MIMEType: mimeType, Width: cfg.Width, Height: cfg.Height}, nil
+}
@@ -0,0 +1,117 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// stripDHT removes the Huffman tables from a JPEG, as MJPEG cameras do.
+func stripDHT(data []byte) []byte {
+	out := append([]byte(nil), data[:2]...)
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xff && data[i+1] != 0xda {
+		n := 2 + (int(data[i+2])<<8 | int(data[i+3]))
+		if data[i+1] != 0xc4 {
+			out = append(out, data[i:i+n]...)
+		}
+		i += n
+	}
+	return append(out, data[i:]...)
+}
+
+func TestFixMJPEG(t *testing.T) {
+	frame := stripDHT(encodeJPEG(t, 32, 16))
+	if _, err := jpeg.Decode(bytes.NewReader(frame)); err == nil {
+		t.Fatal("a frame without Huffman tables decoded")
+	}
+	fixed, err := fixMJPEG(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(fixed))
+	if err != nil {
+		t.Fatalf("fixed frame doesn't decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 32 || b.Dy() != 16 {
+		t.Errorf("size = %v, want 32x16", b.Size())
+	}
+
+	whole := encodeJPEG(t, 8, 8)
+	if got, _ := fixMJPEG(whole); !bytes.Equal(got, whole) {
+		t.Error("a frame with Huffman tables was changed")
+	}
+	if _, err := fixMJPEG([]byte("not a jpeg")); err == nil {
+		t.Error("fixMJPEG accepted a frame that isn't JPEG")
+	}
+}
+
+func TestYUYVToJPEG(t *testing.T) {
+	const width, height, stride = 4, 2, 10
+	frame := make([]byte, stride*height)
+	for y := range height {
+		for x := 0; x < width; x += 2 {
+			copy(frame[y*stride+x*2:], []byte{235, 128, 235, 128}) // White
+		}
+	}
+	data, err := yuyvToJPEG(frame, width, height, stride)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := img.At(1, 1).RGBA(); r>>8 < 200 {
+		t.Errorf("pixel = %v, want white", img.At(1, 1))
+	}
+	if _, err := yuyvToJPEG(frame[:stride], width, height, stride); err == nil {
+		t.Error("yuyvToJPEG accepted a short frame")
+	}
+}
+
+func TestCaptureCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+	src := t.TempDir() + "/src.png"
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.White)
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	os.WriteFile(src, buf.Bytes(), 0o644)
+
+	photo, err := Capture(context.Background(), Options{Command: "cp " + src + " {path}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if photo.MIMEType != "image/png" || photo.Width != 3 || photo.Height != 2 {
+		t.Errorf("photo = %s %dx%d, want image/png 3x2", photo.MIMEType, photo.Width, photo.Height)
+	}
+
+	if _, err := Capture(context.Background(), Options{Command: "echo hello > {path}"}); err == nil {
+		t.Error("Capture accepted a file that isn't an image")
+	}
+	if _, err := Capture(context.Background(), Options{Command: "true"}); err == nil {
+		t.Error("Capture succeeded without a photo")
+	}
+	if _, err := Capture(context.Background(), Options{Command: "exit 3"}); err == nil {
+		t.Error("Capture succeeded with a failing command")
+	}
+}
@@ -0,0 +1,79 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package camera
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"sync"
+)
+
+const jpegQuality = 85
+
+// standardDHT is the DHT segment of the Huffman tables of the JPEG spec
+// (K.3), which Go's encoder writes and MJPEG frames assume.
+var standardDHT = sync.OnceValue(func() []byte {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewYCbCr(image.Rect(0, 0, 8, 8), image.YCbCrSubsampleRatio420), nil)
+	data := buf.Bytes()
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		n := int(data[i+2])<<8 | int(data[i+3])
+		if data[i+1] == 0xc4 {
+			return data[i : i+2+n]
+		}
+		i += 2 + n
+	}
+	panic("camera: no DHT segment in an encoded JPEG")
+})
+
+// fixMJPEG returns an MJPEG frame as a JPEG file: many cameras leave out
+// the Huffman tables, which are then the standard ones.
+func fixMJPEG(frame []byte) ([]byte, error) {
+	if len(frame) < 4 || frame[0] != 0xff || frame[1] != 0xd8 {
+		return nil, errors.New("the camera sent a frame that isn't JPEG")
+	}
+	for i := 2; i+4 <= len(frame) && frame[i] == 0xff; {
+		marker := frame[i+1]
+		if marker == 0xc4 {
+			return frame, nil
+		}
+		if marker == 0xda { // Start of scan: the header is over
+			break
+		}
+		i += 2 + (int(frame[i+2])<<8 | int(frame[i+3]))
+	}
+	fixed := make([]byte, 0, len(frame)+len(standardDHT()))
+	fixed = append(fixed, frame[:2]...)
+	fixed = append(fixed, standardDHT()...)
+	return append(fixed, frame[2:]...), nil
+}
+
+// yuyvToJPEG encodes a YUYV 4:2:2 frame, with lines of stride bytes, as a
+// JPEG file.
+func yuyvToJPEG(frame []byte, width, height, stride int) ([]byte, error) {
+	if stride < width*2 || len(frame) < stride*height || width%2 != 0 {
+		return nil, fmt.Errorf("short YUYV frame: %d bytes for %dx%d", len(frame), width, height)
+	}
+	img := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio422)
+	for y := range height {
+		line := frame[y*stride:]
+		for x := 0; x < width; x += 2 {
+			p := line[x*2 : x*2+4]
+			img.Y[y*img.YStride+x] = p[0]
+			img.Y[y*img.YStride+x+1] = p[2]
+			img.Cb[y*img.CStride+x/2] = p[1]
+			img.Cr[y*img.CStride+x/2] = p[3]
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,232 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package camera
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// V4L2 constants from <linux/videodev2.h>.
+const (
+	v4l2BufTypeVideoCapture = 1
+	v4l2MemoryMmap          = 1
+	v4l2FieldAny            = 0
+	v4l2CapVideoCapture     = 0x00000001
+	v4l2CapStreaming        = 0x04000000
+	v4l2CapDeviceCaps       = 0x80000000
+
+	v4l2BufferCount = 2
+	// warmupFrames are dropped while the camera's exposure settles.
+	warmupFrames = 5
+)
+
+var (
+	pixFmtMJPEG = fourcc("MJPG")
+	pixFmtYUYV  = fourcc("YUYV")
+)
+
+func fourcc(s string) uint32 {
+	return uint32(s[0]) | uint32(s[1])<<8 | uint32(s[2])<<16 | uint32(s[3])<<24
+}
+
+// Struct sizes and offsets depend on the size of pointers, which unions of
+// the kernel's structs hold.
+const ptrSize = int(unsafe.Sizeof(uintptr(0)))
+
+var (
+	// struct v4l2_format: a type, then a union of 200 bytes aligned to pointers.
+	formatSize    = ptrSize + 200
+	formatPixOffs = ptrSize
+	// struct v4l2_buffer, with a 32-bit time_t timeval on 32-bit systems.
+	bufferSize      = map[int]int{8: 88, 4: 68}[ptrSize]
+	bufferSeqOffs   = map[int]int{8: 56, 4: 44}[ptrSize]
+	bufferMOffs     = bufferSeqOffs + 8
+	bufferLenOffs   = bufferMOffs + ptrSize
+	vidiocQuerycap  = ioc(2, 0, 104)
+	vidiocSFmt      = ioc(3, 5, formatSize)
+	vidiocReqbufs   = ioc(3, 8, 20)
+	vidiocQuerybuf  = ioc(3, 9, bufferSize)
+	vidiocQbuf      = ioc(3, 15, bufferSize)
+	vidiocDqbuf     = ioc(3, 17, bufferSize)
+	vidiocStreamon  = ioc(1, 18, 4)
+	vidiocStreamoff = ioc(1, 19, 4)
+)
+
+// ioc encodes a V4L2 ioctl request: dir is 1 to write, 2 to read, 3 both.
+func ioc(dir, nr, size int) uintptr {
+	return uintptr(dir<<30 | size<<16 | 'V'<<8 | nr)
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+		if errno != syscall.EINTR {
+			if errno != 0 {
+				return errno
+			}
+			return nil
+		}
+	}
+}
+
+// captureV4L2 streams a few frames from the device, to let the exposure
+// settle, and returns the last as a JPEG.
+func captureV4L2(ctx context.Context, opts Options) (*Photo, error) {
+	fd, err := syscall.Open(opts.Device, syscall.O_RDWR|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", opts.Device, err)
+	}
+	defer syscall.Close(fd)
+
+	caps := make([]byte, 104)
+	if err := ioctl(fd, vidiocQuerycap, unsafe.Pointer(&caps[0])); err != nil {
+		return nil, fmt.Errorf("%s is not a V4L2 device: %w", opts.Device, err)
+	}
+	capabilities := binary.LittleEndian.Uint32(caps[84:])
+	if capabilities&v4l2CapDeviceCaps != 0 {
+		capabilities = binary.LittleEndian.Uint32(caps[88:])
+	}
+	if capabilities&v4l2CapVideoCapture == 0 || capabilities&v4l2CapStreaming == 0 {
+		return nil, fmt.Errorf("%s can't capture video (it may be the metadata node; try the next one)", opts.Device)
+	}
+
+	width, height, stride, pixFmt, err := setFormat(fd, opts.Width, opts.Height)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := stream(ctx, fd)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if pixFmt == pixFmtMJPEG {
+		data, err = fixMJPEG(frame)
+	} else {
+		data, err = yuyvToJPEG(frame, width, height, stride)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newPhoto(data)
+}
+
+// setFormat asks for MJPEG frames of about width x height, else YUYV, and
+// returns what the camera picked.
+func setFormat(fd, width, height int) (w, h, stride int, pixFmt uint32, err error) {
+	for _, want := range []uint32{pixFmtMJPEG, pixFmtYUYV} {
+		format := make([]byte, formatSize)
+		binary.LittleEndian.PutUint32(format, v4l2BufTypeVideoCapture)
+		pix := format[formatPixOffs:]
+		binary.LittleEndian.PutUint32(pix[0:], uint32(width))
+		binary.LittleEndian.PutUint32(pix[4:], uint32(height))
+		binary.LittleEndian.PutUint32(pix[8:], want)
+		binary.LittleEndian.PutUint32(pix[12:], v4l2FieldAny)
+		if err := ioctl(fd, vidiocSFmt, unsafe.Pointer(&format[0])); err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("set the camera's format: %w", err)
+		}
+		if got := binary.LittleEndian.Uint32(pix[8:]); got == want {
+			w, h = int(binary.LittleEndian.Uint32(pix[0:])), int(binary.LittleEndian.Uint32(pix[4:]))
+			stride = int(binary.LittleEndian.Uint32(pix[16:]))
+			if stride == 0 {
+				stride = w * 2
+			}
+			return w, h, stride, want, nil
+		}
+	}
+	return 0, 0, 0, 0, errors.New("the camera offers neither MJPEG nor YUYV frames")
+}
+
+// stream maps the driver's buffers, streams warmupFrames frames and
+// returns a copy of the last.
+func stream(ctx context.Context, fd int) ([]byte, error) {
+	req := make([]byte, 20)
+	binary.LittleEndian.PutUint32(req[0:], v4l2BufferCount)
+	binary.LittleEndian.PutUint32(req[4:], v4l2BufTypeVideoCapture)
+	binary.LittleEndian.PutUint32(req[8:], v4l2MemoryMmap)
+	if err := ioctl(fd, vidiocReqbufs, unsafe.Pointer(&req[0])); err != nil {
+		return nil, fmt.Errorf("request capture buffers: %w", err)
+	}
+	count := int(binary.LittleEndian.Uint32(req[0:]))
+	if count == 0 {
+		return nil, errors.New("the camera gave no capture buffers")
+	}
+
+	newBuffer := func(index int) []byte {
+		buf := make([]byte, bufferSize)
+		binary.LittleEndian.PutUint32(buf[0:], uint32(index))
+		binary.LittleEndian.PutUint32(buf[4:], v4l2BufTypeVideoCapture)
+		binary.LittleEndian.PutUint32(buf[bufferSeqOffs+4:], v4l2MemoryMmap)
+		return buf
+	}
+	maps := make([][]byte, count)
+	defer func() {
+		for _, m := range maps {
+			if m != nil {
+				syscall.Munmap(m)
+			}
+		}
+	}()
+	for i := range count {
+		buf := newBuffer(i)
+		if err := ioctl(fd, vidiocQuerybuf, unsafe.Pointer(&buf[0])); err != nil {
+			return nil, fmt.Errorf("query capture buffer: %w", err)
+		}
+		offset := int64(binary.LittleEndian.Uint32(buf[bufferMOffs:]))
+		length := int(binary.LittleEndian.Uint32(buf[bufferLenOffs:]))
+		m, err := syscall.Mmap(fd, offset, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+		if err != nil {
+			return nil, fmt.Errorf("map capture buffer: %w", err)
+		}
+		maps[i] = m
+		if err := ioctl(fd, vidiocQbuf, unsafe.Pointer(&buf[0])); err != nil {
+			return nil, fmt.Errorf("queue capture buffer: %w", err)
+		}
+	}
+
+	bufType := uint32(v4l2BufTypeVideoCapture)
+	if err := ioctl(fd, vidiocStreamon, unsafe.Pointer(&bufType)); err != nil {
+		return nil, fmt.Errorf("start the camera: %w", err)
+	}
+	defer ioctl(fd, vidiocStreamoff, unsafe.Pointer(&bufType))
+
+	var frame []byte
+	for range warmupFrames {
+		buf := newBuffer(0)
+		for {
+			err := ioctl(fd, vidiocDqbuf, unsafe.Pointer(&buf[0]))
+			if err == nil {
+				break
+			}
+			if err != syscall.EAGAIN {
+				return nil, fmt.Errorf("read a frame: %w", err)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("the camera sent no frame: %w", ctx.Err())
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		index := int(binary.LittleEndian.Uint32(buf[0:]))
+		used := int(binary.LittleEndian.Uint32(buf[8:]))
+		if index < count && used <= len(maps[index]) {
+			frame = append(frame[:0], maps[index][:used]...)
+		}
+		if err := ioctl(fd, vidiocQbuf, unsafe.Pointer(&buf[0])); err != nil {
+			return nil, fmt.Errorf("queue capture buffer: %w", err)
+		}
+	}
+	if len(frame) == 0 {
+		return nil, errors.New("the camera sent empty frames")
+	}
+	return frame, nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package camera
+
+import (
+	"context"
+	"errors"
+)
+
+// captureV4L2 is a stub for non-Linux platforms.
+func captureV4L2(ctx context.Context, opts Options) (*Photo, error) {
+	return nil, errors.New("V4L2 cameras are only supported on Linux; set a capture command instead")
+}
@@ -3,6 +3,7 @@ package channels
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 )
@@ -16,11 +17,33 @@ type Channel interface {
 	IsAllowed(senderID string) bool
 }
 
+// StreamingChannel is implemented by channels that can display a reply while
+// it is still being generated. Partial messages carry the full text so far.
+type StreamingChannel interface {
+	Channel
+	SendPartial(ctx context.Context, msg bus.OutboundMessage) error
+}
+
+// ButtonChannel is implemented by channels that show OutboundMessage.Buttons
+// as buttons, which ClearButtons removes from chatID.
+type ButtonChannel interface {
+	Channel
+	ClearButtons(ctx context.Context, chatID string) error
+}
+
+// MediaChannel is implemented by channels that can deliver files as
+// attachments. Other channels receive the file names in the message text.
+type MediaChannel interface {
+	Channel
+	SendMedia(ctx context.Context, msg bus.OutboundMessage) error
+}
+
 type BaseChannel struct {
 	config    any
 	bus       *bus.MessageBus
 	running   bool
 	name      string
+	mu        sync.RWMutex // guards allowList
 	allowList []string
 }
 
@@ -42,8 +65,18 @@ func (c *BaseChannel) IsRunning() bool {
 	return c.running
 }
 
+// SetAllowList replaces the senders the channel accepts messages from.
+func (c *BaseChannel) SetAllowList(allowList []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.allowList = allowList
+}
+
 func (c *BaseChannel) IsAllowed(senderID string) bool {
-	if len(c.allowList) == 0 {
+	c.mu.RLock()
+	allowList := c.allowList
+	c.mu.RUnlock()
+	if len(allowList) == 0 {
 		return true
 	}
 
@@ -55,7 +88,7 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 		userPart = senderID[idx+1:]
 	}
 
-	for _, allowed := range c.allowList {
+	for _, allowed := range allowList {
 		// Strip leading "@" from allowed value for username matching
 		trimmed := strings.TrimPrefix(allowed, "@")
 		allowedID := trimmed
@@ -82,6 +115,16 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 }
 
 func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []string, metadata map[string]string) {
+	c.HandleMessageWithImages(senderID, chatID, content, media, nil, metadata)
+}
+
+// HandleMessageWithImages is like HandleMessage with images the model should
+// see, as data: or http(s) URLs (see utils.ImageDataURL).
+func (c *BaseChannel) HandleMessageWithImages(
+	senderID, chatID, content string,
+	media, images []string,
+	metadata map[string]string,
+) {
 	if !c.IsAllowed(senderID) {
 		return
 	}
@@ -92,6 +135,7 @@ func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []st
 		ChatID:   chatID,
 		Content:  content,
 		Media:    media,
+		Images:   images,
 		Metadata: metadata,
 	}
 
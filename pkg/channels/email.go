@@ -0,0 +1,267 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/email"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultEmailPollInterval = time.Minute
+	// emailFirstPollWindow is how old the unread mail handled may be when
+	// the channel starts, so a backlog of old mail isn't answered.
+	emailFirstPollWindow = 24 * time.Hour
+)
+
+// emailThread is the last message from a sender, which replies answer.
+type emailThread struct {
+	subject    string
+	messageID  string
+	references string
+}
+
+// EmailChannel handles new mail to an address as messages and answers by
+// email. Each sender is a chat, whose ID is their address.
+type EmailChannel struct {
+	*BaseChannel
+	mailbox   email.Mailbox
+	address   string
+	folders   []string
+	interval  time.Duration
+	workspace string
+
+	mu      sync.Mutex
+	since   time.Time              // Older mail is left alone
+	lastUID map[string]uint32      // By folder, the highest UID handled
+	threads map[string]emailThread // By sender address
+	cancel  context.CancelFunc
+}
+
+func NewEmailChannel(cfg *config.Config, bus *bus.MessageBus) (*EmailChannel, error) {
+	emailCfg := cfg.Channels.Email
+	mailbox := email.NewClient(email.Account{
+		Address:  emailCfg.Address,
+		Username: emailCfg.Username,
+		Password: emailCfg.Password,
+		IMAPHost: emailCfg.IMAPHost,
+		IMAPPort: emailCfg.IMAPPort,
+		SMTPHost: emailCfg.SMTPHost,
+		SMTPPort: emailCfg.SMTPPort,
+	})
+	return newEmailChannel(emailCfg, mailbox, cfg.WorkspacePath(), bus), nil
+}
+
+func newEmailChannel(cfg config.EmailConfig, mailbox email.Mailbox, workspace string,
+	bus *bus.MessageBus,
+) *EmailChannel {
+	folders := cfg.Folders
+	if len(folders) == 0 {
+		folders = []string{"INBOX"}
+	}
+	interval := time.Duration(cfg.PollSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultEmailPollInterval
+	}
+	address := cfg.Address
+	if addr, err := mail.ParseAddress(cfg.Address); err == nil {
+		address = addr.Address
+	}
+	return &EmailChannel{
+		BaseChannel: NewBaseChannel("email", cfg, bus, cfg.AllowFrom),
+		mailbox:     mailbox,
+		address:     address,
+		folders:     folders,
+		interval:    interval,
+		workspace:   workspace,
+		lastUID:     make(map[string]uint32),
+		threads:     make(map[string]emailThread),
+	}
+}
+
+func (c *EmailChannel) Start(ctx context.Context) error {
+	logger.InfoCF("email", "Starting email channel", map[string]any{
+		"address": c.address,
+		"folders": c.folders,
+	})
+	pollCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancel = cancel
+	c.since = time.Now().Add(-emailFirstPollWindow)
+	c.mu.Unlock()
+	c.setRunning(true)
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			c.poll(pollCtx)
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *EmailChannel) Stop(ctx context.Context) error {
+	logger.InfoC("email", "Stopping email channel")
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.mu.Unlock()
+	c.setRunning(false)
+	return nil
+}
+
+// poll handles the new mail in each folder.
+func (c *EmailChannel) poll(ctx context.Context) {
+	for _, folder := range c.folders {
+		c.mu.Lock()
+		after, since := c.lastUID[folder], c.since
+		c.mu.Unlock()
+
+		messages, err := c.mailbox.Unseen(ctx, folder, after, since)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.WarnCF("email", "Failed to check for new mail", map[string]any{
+					"folder": folder,
+					"error":  err.Error(),
+				})
+			}
+			continue
+		}
+
+		var handled []uint32
+		highest := after
+		for _, m := range messages {
+			highest = max(highest, m.UID)
+			if c.handle(m) {
+				handled = append(handled, m.UID)
+			}
+		}
+		c.mu.Lock()
+		c.lastUID[folder] = highest
+		c.mu.Unlock()
+
+		// Only mail passed to the agent is marked read; the rest stays
+		// unread for the user.
+		if len(handled) > 0 {
+			if err := c.mailbox.MarkSeen(ctx, folder, handled); err != nil {
+				logger.WarnCF("email", "Failed to mark mail read", map[string]any{
+					"folder": folder,
+					"error":  err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// handle passes a new message to the agent, unless it is the channel's own
+// mail, automatic mail or from a sender not allowed. It reports whether the
+// message was passed on.
+func (c *EmailChannel) handle(m *email.Message) bool {
+	sender := m.FromAddress
+	if sender == "" || strings.EqualFold(sender, c.address) || m.Automatic {
+		return false
+	}
+	if !c.IsAllowed(sender) {
+		logger.DebugCF("email", "Message rejected by allowlist", map[string]any{"from": sender})
+		return false
+	}
+
+	c.mu.Lock()
+	c.threads[sender] = emailThread{subject: m.Subject, messageID: m.MessageID, references: m.References}
+	c.mu.Unlock()
+
+	content := email.StripQuoted(m.Text)
+	if m.Subject != "" {
+		content = "Subject: " + m.Subject + "\n\n" + content
+	}
+	var media, images []string
+	for _, a := range m.Attachments {
+		path, err := c.saveAttachment(m, a)
+		if err != nil {
+			logger.WarnCF("email", "Failed to save attachment", map[string]any{
+				"file":  a.Filename,
+				"error": err.Error(),
+			})
+			continue
+		}
+		media = append(media, path)
+		if strings.HasPrefix(a.ContentType, "image/") {
+			if dataURL, err := utils.ImageDataURL(path); err == nil {
+				images = append(images, dataURL)
+			}
+		}
+		content += fmt.Sprintf("\n[attachment: %s]", path)
+	}
+
+	c.HandleMessageWithImages(sender, sender, content, media, images, map[string]string{
+		"message_id": m.MessageID,
+		"user_name":  m.From,
+		"peer_kind":  "direct",
+		"peer_id":    sender,
+	})
+	return true
+}
+
+// saveAttachment saves an attachment to the workspace, where the agent's
+// tools can open it.
+func (c *EmailChannel) saveAttachment(m *email.Message, a email.Attachment) (string, error) {
+	folder := utils.SanitizeFilename(m.Folder)
+	dir := filepath.Join(c.workspace, "email", "received", fmt.Sprintf("%s-%d", folder, m.UID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, utils.SanitizeFilename(a.Filename))
+	return path, os.WriteFile(path, a.Data, 0o644)
+}
+
+// Send answers the last message of the chat's sender, in its thread.
+func (c *EmailChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	return c.send(ctx, msg, nil)
+}
+
+// SendMedia sends msg.Content with msg.Media attached.
+func (c *EmailChannel) SendMedia(ctx context.Context, msg bus.OutboundMessage) error {
+	return c.send(ctx, msg, msg.Media)
+}
+
+func (c *EmailChannel) send(ctx context.Context, msg bus.OutboundMessage, attachments []string) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("email channel not running")
+	}
+	c.mu.Lock()
+	thread, ok := c.threads[strings.ToLower(msg.ChatID)]
+	c.mu.Unlock()
+
+	out := email.Outgoing{
+		To:          []string{msg.ChatID},
+		Text:        msg.Content,
+		Attachments: attachments,
+		Subject:     "Message from PicoClaw",
+	}
+	if ok {
+		out.Subject = email.ReplySubject(thread.subject)
+		out.InReplyTo, out.References = thread.messageID, thread.references
+	}
+	if _, err := c.mailbox.Send(ctx, out); err != nil {
+		return fmt.Errorf("send email to %s: %w", msg.ChatID, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package channels
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/email"
+)
+
+// fakeMailbox is an email.Mailbox with a fixed inbox.
+type fakeMailbox struct {
+	email.Mailbox
+	inbox  []*email.Message
+	afters []uint32
+	seen   []uint32
+	sent   []email.Outgoing
+}
+
+func (f *fakeMailbox) Unseen(_ context.Context, _ string, after uint32, _ time.Time) ([]*email.Message, error) {
+	f.afters = append(f.afters, after)
+	var out []*email.Message
+	for _, m := range f.inbox {
+		if m.UID > after {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMailbox) MarkSeen(_ context.Context, _ string, uids []uint32) error {
+	f.seen = append(f.seen, uids...)
+	return nil
+}
+
+func (f *fakeMailbox) Send(_ context.Context, out email.Outgoing) (string, error) {
+	f.sent = append(f.sent, out)
+	return "<r@example.com>", nil
+}
+
+func TestEmailChannel_PollAndReply(t *testing.T) {
+	mailbox := &fakeMailbox{inbox: []*email.Message{
+		{
+			UID: 4, Folder: "INBOX", From: "Ann <ann@example.com>", FromAddress: "ann@example.com",
+			Subject: "Trip", MessageID: "<t@example.com>", References: "<s@example.com>",
+			Text: "Book the train.\n\nOn Mon, Bot wrote:\n> Where to?",
+			Attachments: []email.Attachment{
+				{Filename: "plan.txt", ContentType: "application/octet-stream", Data: []byte("Lyon")},
+			},
+		},
+		{UID: 5, Folder: "INBOX", FromAddress: "news@shop.example", Subject: "Sale", Automatic: true},
+		{UID: 6, Folder: "INBOX", FromAddress: "eve@example.com", Subject: "Hi"},
+		{UID: 7, Folder: "INBOX", FromAddress: "bot@example.com", Subject: "Re: Trip"},
+	}}
+	msgBus := bus.NewMessageBus()
+	workspace := t.TempDir()
+	c := newEmailChannel(config.EmailConfig{
+		Address:   "PicoClaw <bot@example.com>",
+		AllowFrom: config.FlexibleStringSlice{"ann@example.com"},
+	}, mailbox, workspace, msgBus)
+	c.setRunning(true)
+
+	ctx := context.Background()
+	c.poll(ctx)
+	c.poll(ctx)
+	if len(mailbox.afters) != 2 || mailbox.afters[1] != 7 {
+		t.Errorf("polled after UIDs %v", mailbox.afters)
+	}
+	if len(mailbox.seen) != 1 || mailbox.seen[0] != 4 {
+		t.Errorf("marked read %v, want only the message handled", mailbox.seen)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	if msg.SenderID != "ann@example.com" || msg.ChatID != "ann@example.com" ||
+		!strings.HasPrefix(msg.Content, "Subject: Trip\n\nBook the train.\n[attachment: ") || len(msg.Media) != 1 {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if data, _ := os.ReadFile(msg.Media[0]); string(data) != "Lyon" || !strings.HasPrefix(msg.Media[0], workspace) {
+		t.Errorf("attachment %s = %q", msg.Media[0], data)
+	}
+
+	err := c.SendMedia(ctx, bus.OutboundMessage{
+		ChatID: "ann@example.com", Content: "Booked.", Media: []string{"ticket.pdf"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := mailbox.sent[0]
+	if out.Subject != "Re: Trip" || out.InReplyTo != "<t@example.com>" || out.References != "<s@example.com>" ||
+		out.To[0] != "ann@example.com" || out.Text != "Booked." || out.Attachments[0] != "ticket.pdf" {
+		t.Errorf("sent = %+v", out)
+	}
+}
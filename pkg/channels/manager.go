@@ -9,6 +9,8 @@ package channels
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -43,32 +45,90 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus) (*Manager, error
 	return m, nil
 }
 
+// channelFactory builds one kind of channel from config. settings returns
+// the config the channel is built from, except allow_from, which running
+// channels pick up without a restart.
+type channelFactory struct {
+	name      string
+	title     string
+	enabled   func(cfg *config.Config) bool
+	settings  func(cfg *config.Config) any
+	allowFrom func(cfg *config.Config) []string
+	create    func(cfg *config.Config, bus *bus.MessageBus) (Channel, error)
+}
+
+var channelFactories = []channelFactory{
+	{
+		name:  "telegram",
+		title: "Telegram",
+		enabled: func(cfg *config.Config) bool {
+			return cfg.Channels.Telegram.Enabled && cfg.Channels.Telegram.Token != ""
+		},
+		settings: func(cfg *config.Config) any {
+			c := cfg.Channels.Telegram
+			c.AllowFrom = nil
+			return c
+		},
+		allowFrom: func(cfg *config.Config) []string { return cfg.Channels.Telegram.AllowFrom },
+		create: func(cfg *config.Config, bus *bus.MessageBus) (Channel, error) {
+			c, err := NewTelegramChannel(cfg, bus)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	},
+	{
+		name:  "whatsapp",
+		title: "WhatsApp",
+		enabled: func(cfg *config.Config) bool {
+			return cfg.Channels.WhatsApp.Enabled && cfg.Channels.WhatsApp.BridgeURL != ""
+		},
+		settings: func(cfg *config.Config) any {
+			c := cfg.Channels.WhatsApp
+			c.AllowFrom = nil
+			return c
+		},
+		allowFrom: func(cfg *config.Config) []string { return cfg.Channels.WhatsApp.AllowFrom },
+		create: func(cfg *config.Config, bus *bus.MessageBus) (Channel, error) {
+			c, err := NewWhatsAppChannel(cfg.Channels.WhatsApp, bus)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	},
+	{
+		name:  "email",
+		title: "Email",
+		enabled: func(cfg *config.Config) bool {
+			return cfg.Channels.Email.Enabled && cfg.Channels.Email.IMAPHost != ""
+		},
+		settings: func(cfg *config.Config) any {
+			c := cfg.Channels.Email
+			c.AllowFrom = nil
+			return c
+		},
+		allowFrom: func(cfg *config.Config) []string { return cfg.Channels.Email.AllowFrom },
+		create: func(cfg *config.Config, bus *bus.MessageBus) (Channel, error) {
+			c, err := NewEmailChannel(cfg, bus)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	},
+}
+
 func (m *Manager) initChannels() error {
 	logger.InfoC("channels", "Initializing channel manager")
 
-	if m.config.Channels.Telegram.Enabled && m.config.Channels.Telegram.Token != "" {
-		logger.DebugC("channels", "Attempting to initialize Telegram channel")
-		telegram, err := NewTelegramChannel(m.config, m.bus)
-		if err != nil {
-			logger.ErrorCF("channels", "Failed to initialize Telegram channel", map[string]any{
-				"error": err.Error(),
-			})
-		} else {
-			m.channels["telegram"] = telegram
-			logger.InfoC("channels", "Telegram channel enabled successfully")
+	for _, f := range channelFactories {
+		if !f.enabled(m.config) {
+			continue
 		}
-	}
-
-	if m.config.Channels.WhatsApp.Enabled && m.config.Channels.WhatsApp.BridgeURL != "" {
-		logger.DebugC("channels", "Attempting to initialize WhatsApp channel")
-		whatsapp, err := NewWhatsAppChannel(m.config.Channels.WhatsApp, m.bus)
-		if err != nil {
-			logger.ErrorCF("channels", "Failed to initialize WhatsApp channel", map[string]any{
-				"error": err.Error(),
-			})
-		} else {
-			m.channels["whatsapp"] = whatsapp
-			logger.InfoC("channels", "WhatsApp channel enabled successfully")
+		if channel, ok := m.createChannel(f); ok {
+			m.channels[f.name] = channel
 		}
 	}
 
@@ -79,6 +139,79 @@ func (m *Manager) initChannels() error {
 	return nil
 }
 
+func (m *Manager) createChannel(f channelFactory) (Channel, bool) {
+	logger.DebugC("channels", "Attempting to initialize "+f.title+" channel")
+	channel, err := f.create(m.config, m.bus)
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to initialize "+f.title+" channel", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	logger.InfoC("channels", f.title+" channel enabled successfully")
+	return channel, true
+}
+
+// Reload applies a changed configuration to running channels: channels that
+// were switched on are started, those switched off are stopped, and those
+// whose settings changed are restarted. Channels that only had allow_from
+// changed keep running, so Telegram keeps its update offset. It returns the
+// names of the channels it started.
+func (m *Manager) Reload(ctx context.Context, cfg *config.Config) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prev := m.config
+	m.config = cfg
+
+	var started []string
+	for _, f := range channelFactories {
+		channel, running := m.channels[f.name]
+		want := f.enabled(cfg)
+		if running && want && reflect.DeepEqual(f.settings(prev), f.settings(cfg)) {
+			if al, ok := channel.(interface{ SetAllowList([]string) }); ok {
+				al.SetAllowList(f.allowFrom(cfg))
+			}
+			continue
+		}
+
+		if running {
+			logger.InfoCF("channels", "Stopping channel", map[string]any{"channel": f.name})
+			if err := channel.Stop(ctx); err != nil {
+				logger.ErrorCF("channels", "Error stopping channel", map[string]any{
+					"channel": f.name,
+					"error":   err.Error(),
+				})
+			}
+			delete(m.channels, f.name)
+		}
+		if !want {
+			continue
+		}
+
+		channel, ok := m.createChannel(f)
+		if !ok {
+			continue
+		}
+		logger.InfoCF("channels", "Starting channel", map[string]any{"channel": f.name})
+		if err := channel.Start(ctx); err != nil {
+			logger.ErrorCF("channels", "Failed to start channel", map[string]any{
+				"channel": f.name,
+				"error":   err.Error(),
+			})
+		}
+		m.channels[f.name] = channel
+		started = append(started, f.name)
+	}
+
+	if m.dispatchTask == nil && len(m.channels) > 0 {
+		dispatchCtx, cancel := context.WithCancel(ctx)
+		m.dispatchTask = &asyncTask{cancel: cancel}
+		go m.dispatchOutbound(dispatchCtx)
+	}
+	return started
+}
+
 func (m *Manager) StartAll(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -168,6 +301,42 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
+			if msg.Partial {
+				// Partial replies are only for channels that can render them
+				if sc, ok := channel.(StreamingChannel); ok {
+					if err := sc.SendPartial(ctx, msg); err != nil {
+						logger.DebugCF("channels", "Error sending partial message to channel", map[string]any{
+							"channel": msg.Channel,
+							"error":   err.Error(),
+						})
+					}
+				}
+				continue
+			}
+
+			if msg.ClearButtons {
+				// Other channels show buttons as text, which stays
+				if bc, ok := channel.(ButtonChannel); ok {
+					if err := bc.ClearButtons(ctx, msg.ChatID); err != nil {
+						logger.DebugCF("channels", "Error removing buttons", map[string]any{
+							"channel": msg.Channel,
+							"error":   err.Error(),
+						})
+					}
+				}
+				continue
+			}
+
+			if len(msg.Media) > 0 {
+				if err := m.sendMedia(ctx, channel, msg); err != nil {
+					logger.ErrorCF("channels", "Error sending media to channel", map[string]any{
+						"channel": msg.Channel,
+						"error":   err.Error(),
+					})
+				}
+				continue
+			}
+
 			if err := channel.Send(ctx, msg); err != nil {
 				logger.ErrorCF("channels", "Error sending message to channel", map[string]any{
 					"channel": msg.Channel,
@@ -178,6 +347,29 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 	}
 }
 
+// sendMedia delivers msg's attachments, falling back to listing the files
+// in a text message on channels that can't send them.
+func (m *Manager) sendMedia(ctx context.Context, channel Channel, msg bus.OutboundMessage) error {
+	if mc, ok := channel.(MediaChannel); ok {
+		return mc.SendMedia(ctx, msg)
+	}
+	msg.Content = mediaFallbackText(msg.Content, msg.Media)
+	msg.Media = nil
+	return channel.Send(ctx, msg)
+}
+
+func mediaFallbackText(content string, media []string) string {
+	var sb strings.Builder
+	sb.WriteString(content)
+	for _, path := range media {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("[file: " + path + "]")
+	}
+	return sb.String()
+}
+
 func (m *Manager) GetChannel(name string) (Channel, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
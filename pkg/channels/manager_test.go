@@ -0,0 +1,123 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type recordingChannel struct {
+	*BaseChannel
+	sent []bus.OutboundMessage
+}
+
+func (c *recordingChannel) Start(ctx context.Context) error { return nil }
+func (c *recordingChannel) Stop(ctx context.Context) error  { return nil }
+
+func (c *recordingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+type recordingMediaChannel struct {
+	recordingChannel
+	media []bus.OutboundMessage
+}
+
+func (c *recordingMediaChannel) SendMedia(ctx context.Context, msg bus.OutboundMessage) error {
+	c.media = append(c.media, msg)
+	return nil
+}
+
+func TestManagerSendMedia(t *testing.T) {
+	m := &Manager{}
+	msg := bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Here you go", Media: []string{"/ws/images/a.png"}}
+
+	mc := &recordingMediaChannel{recordingChannel: recordingChannel{BaseChannel: NewBaseChannel("test", nil, nil, nil)}}
+	if err := m.sendMedia(context.Background(), mc, msg); err != nil {
+		t.Fatalf("sendMedia() error = %v", err)
+	}
+	if len(mc.media) != 1 || len(mc.sent) != 0 {
+		t.Errorf("media = %v, sent = %v", mc.media, mc.sent)
+	}
+
+	c := &recordingChannel{BaseChannel: NewBaseChannel("test", nil, nil, nil)}
+	if err := m.sendMedia(context.Background(), c, msg); err != nil {
+		t.Fatalf("sendMedia() error = %v", err)
+	}
+	if len(c.sent) != 1 || c.sent[0].Content != "Here you go\n[file: /ws/images/a.png]" || c.sent[0].Media != nil {
+		t.Errorf("sent = %+v", c.sent)
+	}
+}
+
+// countingChannel records how often it is started and stopped.
+type countingChannel struct {
+	*BaseChannel
+	starts, stops int
+}
+
+func (c *countingChannel) Start(ctx context.Context) error { c.starts++; return nil }
+func (c *countingChannel) Stop(ctx context.Context) error  { c.stops++; return nil }
+
+func (c *countingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error { return nil }
+
+func TestManagerReload(t *testing.T) {
+	var created []*countingChannel
+	saved := channelFactories
+	defer func() { channelFactories = saved }()
+	channelFactories = []channelFactory{{
+		name:    "telegram",
+		title:   "Telegram",
+		enabled: func(cfg *config.Config) bool { return cfg.Channels.Telegram.Enabled },
+		settings: func(cfg *config.Config) any {
+			c := cfg.Channels.Telegram
+			c.AllowFrom = nil
+			return c
+		},
+		allowFrom: func(cfg *config.Config) []string { return cfg.Channels.Telegram.AllowFrom },
+		create: func(cfg *config.Config, b *bus.MessageBus) (Channel, error) {
+			c := &countingChannel{BaseChannel: NewBaseChannel("telegram", nil, b, cfg.Channels.Telegram.AllowFrom)}
+			created = append(created, c)
+			return c, nil
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newConfig := func(enabled bool, token string, allow ...string) *config.Config {
+		cfg := &config.Config{}
+		cfg.Channels.Telegram = config.TelegramConfig{Enabled: enabled, Token: token, AllowFrom: allow}
+		return cfg
+	}
+
+	m, err := NewManager(newConfig(false, ""), bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	if started := m.Reload(ctx, newConfig(true, "a", "1")); len(started) != 1 || created[0].starts != 1 {
+		t.Fatalf("enabling: started %v, channels created %d", started, len(created))
+	}
+
+	// Only allow_from changed: the channel keeps running with the new list.
+	if started := m.Reload(ctx, newConfig(true, "a", "2")); len(started) != 0 {
+		t.Errorf("allow_from change restarted %v", started)
+	}
+	if created[0].IsAllowed("1") || !created[0].IsAllowed("2") {
+		t.Error("allow list not updated in place")
+	}
+
+	// A new token needs a new channel.
+	m.Reload(ctx, newConfig(true, "b", "2"))
+	if len(created) != 2 || created[0].stops != 1 || created[1].starts != 1 {
+		t.Errorf("token change: created %d, first stopped %d times", len(created), created[0].stops)
+	}
+
+	m.Reload(ctx, newConfig(false, "b"))
+	if created[1].stops != 1 || len(m.GetEnabledChannels()) != 0 {
+		t.Errorf("disabling: stops = %d, enabled = %v", created[1].stops, m.GetEnabledChannels())
+	}
+	m.StopAll(ctx)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
@@ -36,14 +37,22 @@ var (
 	reInlineCode = regexp.MustCompile("`([^`]+)`")
 )
 
+// defaultMaxSpokenChars caps voice replies when tts.max_chars is unset.
+const defaultMaxSpokenChars = 4000
+
+// maxCaptionChars is Telegram's limit on media captions.
+const maxCaptionChars = 1024
+
 type TelegramChannel struct {
 	*BaseChannel
 	bot          *telego.Bot
 	commands     TelegramCommander
 	config       *config.Config
 	chatIDs      map[string]int64
-	transcriber  *voice.GroqTranscriber
+	transcriber  voice.TranscriptionProvider
+	speaker      voice.SpeechProvider
 	placeholders sync.Map // chatID -> messageID
+	keyboards    sync.Map // chatID -> messageID of the last buttons offered
 	stopThinking sync.Map // chatID -> thinkingCancel
 }
 
@@ -99,10 +108,16 @@ func NewTelegramChannel(cfg *config.Config, bus *bus.MessageBus) (*TelegramChann
 	}, nil
 }
 
-func (c *TelegramChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
+func (c *TelegramChannel) SetTranscriber(transcriber voice.TranscriptionProvider) {
 	c.transcriber = transcriber
 }
 
+// SetSpeaker enables voice replies to voice messages and to requests to
+// read the answer aloud.
+func (c *TelegramChannel) SetSpeaker(speaker voice.SpeechProvider) {
+	c.speaker = speaker
+}
+
 func (c *TelegramChannel) Start(ctx context.Context) error {
 	logger.InfoC("telegram", "Starting Telegram bot (polling mode)...")
 
@@ -138,6 +153,10 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		return c.handleMessage(ctx, &message)
 	}, th.AnyMessage())
 
+	bh.HandleCallbackQuery(func(ctx *th.Context, query telego.CallbackQuery) error {
+		return c.handleButton(ctx, query)
+	})
+
 	c.setRunning(true)
 	logger.InfoCF("telegram", "Telegram bot connected", map[string]any{
 		"username": c.bot.Username(),
@@ -177,15 +196,37 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 		c.stopThinking.Delete(msg.ChatID)
 	}
 
+	if err := c.sendText(ctx, chatID, msg); err != nil {
+		return err
+	}
+
+	if msg.Voice && c.speaker != nil {
+		// The text is already delivered, so a failed voice note is only logged.
+		if err := c.sendVoice(ctx, chatID, msg.Content); err != nil {
+			logger.ErrorCF("telegram", "Failed to send voice reply", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (c *TelegramChannel) sendText(ctx context.Context, chatID int64, msg bus.OutboundMessage) error {
 	htmlContent := markdownToTelegramHTML(msg.Content)
+	keyboard := replyKeyboard(msg.Buttons)
 
 	// Try to edit placeholder
 	if pID, ok := c.placeholders.Load(msg.ChatID); ok {
 		c.placeholders.Delete(msg.ChatID)
 		editMsg := tu.EditMessageText(tu.ID(chatID), pID.(int), htmlContent)
 		editMsg.ParseMode = telego.ModeHTML
+		editMsg.ReplyMarkup = keyboard
 
-		if _, err = c.bot.EditMessageText(ctx, editMsg); err == nil {
+		if _, err := c.bot.EditMessageText(ctx, editMsg); err == nil {
+			if keyboard != nil {
+				c.keyboards.Store(msg.ChatID, pID.(int))
+			}
 			return nil
 		}
 		// Fallback to new message if edit fails
@@ -193,19 +234,156 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 
 	tgMsg := tu.Message(tu.ID(chatID), htmlContent)
 	tgMsg.ParseMode = telego.ModeHTML
+	if keyboard != nil {
+		tgMsg.ReplyMarkup = keyboard
+	}
 
-	if _, err = c.bot.SendMessage(ctx, tgMsg); err != nil {
+	sent, err := c.bot.SendMessage(ctx, tgMsg)
+	if err != nil {
 		logger.ErrorCF("telegram", "HTML parse failed, falling back to plain text", map[string]any{
 			"error": err.Error(),
 		})
 		tgMsg.ParseMode = ""
-		_, err = c.bot.SendMessage(ctx, tgMsg)
+		if sent, err = c.bot.SendMessage(ctx, tgMsg); err != nil {
+			return err
+		}
+	}
+	if keyboard != nil {
+		c.keyboards.Store(msg.ChatID, sent.MessageID)
+	}
+	return nil
+}
+
+// ClearButtons removes the buttons last offered in chatID, if any.
+func (c *TelegramChannel) ClearButtons(ctx context.Context, chatID string) error {
+	messageID, ok := c.keyboards.LoadAndDelete(chatID)
+	if !ok {
+		return nil
+	}
+	id, err := parseChatID(chatID)
+	if err != nil {
+		return err
+	}
+	_, err = c.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:    tu.ID(id),
+		MessageID: messageID.(int),
+	})
+	return err
+}
+
+// sendVoice speaks text and sends it as a voice message. Replies longer
+// than tts.max_chars are left as text only.
+func (c *TelegramChannel) sendVoice(ctx context.Context, chatID int64, text string) error {
+	text = voice.SpeakableText(text)
+	maxChars := c.config.TTS.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultMaxSpokenChars
+	}
+	if text == "" || len([]rune(text)) > maxChars {
+		return nil
+	}
+
+	if err := c.bot.SendChatAction(ctx, tu.ChatAction(tu.ID(chatID), telego.ChatActionRecordVoice)); err != nil {
+		logger.DebugCF("telegram", "Failed to send chat action", map[string]any{"error": err.Error()})
+	}
+
+	path, err := c.speaker.Synthesize(ctx, text)
+	if err != nil {
 		return err
 	}
+	defer os.Remove(path)
 
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = c.bot.SendVoice(ctx, tu.Voice(tu.ID(chatID), tu.File(f)))
+	return err
+}
+
+// SendMedia sends each file in msg.Media as a photo, or as a document when
+// it isn't an image Telegram can display. msg.Content captions the first.
+func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("telegram bot not running")
+	}
+
+	chatID, err := parseChatID(msg.ChatID)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+
+	caption := msg.Content
+	if len([]rune(caption)) > maxCaptionChars {
+		// Too long for a caption: send it as its own message.
+		if err := c.sendText(ctx, chatID, bus.OutboundMessage{ChatID: msg.ChatID, Content: caption}); err != nil {
+			return err
+		}
+		caption = ""
+	}
+
+	for _, path := range msg.Media {
+		if err := c.sendFile(ctx, chatID, path, caption); err != nil {
+			return fmt.Errorf("send %s: %w", filepath.Base(path), err)
+		}
+		caption = ""
+	}
 	return nil
 }
 
+func (c *TelegramChannel) sendFile(ctx context.Context, chatID int64, path, caption string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		photo := tu.Photo(tu.ID(chatID), tu.File(f))
+		photo.Caption = caption
+		_, err = c.bot.SendPhoto(ctx, photo)
+	default:
+		doc := tu.Document(tu.ID(chatID), tu.File(f))
+		doc.Caption = caption
+		_, err = c.bot.SendDocument(ctx, doc)
+	}
+	return err
+}
+
+// SendPartial updates the placeholder message with the reply generated so
+// far. Partial updates are best effort: without a placeholder nothing is sent,
+// and the final reply is always delivered by Send.
+func (c *TelegramChannel) SendPartial(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("telegram bot not running")
+	}
+
+	chatID, err := parseChatID(msg.ChatID)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+
+	pID, ok := c.placeholders.Load(msg.ChatID)
+	if !ok {
+		return nil
+	}
+
+	// Stop thinking animation so it doesn't overwrite the partial text
+	if stop, ok := c.stopThinking.Load(msg.ChatID); ok {
+		if cf, ok := stop.(*thinkingCancel); ok && cf != nil {
+			cf.Cancel()
+		}
+		c.stopThinking.Delete(msg.ChatID)
+	}
+
+	// Plain text: partial markdown is often unbalanced and fails HTML parsing
+	_, err = c.bot.EditMessageText(ctx, tu.EditMessageText(tu.ID(chatID), pID.(int), msg.Content))
+	return err
+}
+
 func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Message) error {
 	if message == nil {
 		return fmt.Errorf("message is nil")
@@ -216,10 +394,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		return fmt.Errorf("message sender (user) is nil")
 	}
 
-	senderID := fmt.Sprintf("%d", user.ID)
-	if user.Username != "" {
-		senderID = fmt.Sprintf("%d|%s", user.ID, user.Username)
-	}
+	senderID := telegramSenderID(user)
 
 	// check allowlist to avoid downloading attachments for rejected users
 	if !c.IsAllowed(senderID) {
@@ -234,6 +409,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 
 	content := ""
 	mediaPaths := []string{}
+	images := []string{}
 	localFiles := []string{} // track local files that need cleanup
 
 	// ensure temp files are cleaned up when function returns
@@ -259,12 +435,15 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		content += message.Caption
 	}
 
+	replyVoice := c.speaker != nil && (message.Voice != nil || voice.WantsSpokenReply(content))
+
 	if len(message.Photo) > 0 {
 		photo := message.Photo[len(message.Photo)-1]
 		photoPath := c.downloadPhoto(ctx, photo.FileID)
 		if photoPath != "" {
 			localFiles = append(localFiles, photoPath)
 			mediaPaths = append(mediaPaths, photoPath)
+			images = c.appendImage(images, photoPath)
 			if content != "" {
 				content += "\n"
 			}
@@ -280,10 +459,8 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 
 			var transcribedText string
 			if c.transcriber != nil && c.transcriber.IsAvailable() {
-				transcriberCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-				defer cancel()
-
-				result, err := c.transcriber.Transcribe(transcriberCtx, voicePath)
+				// Each backend bounds its own run time; local models need longer.
+				result, err := c.transcriber.Transcribe(ctx, voicePath)
 				if err != nil {
 					logger.ErrorCF("telegram", "Voice transcription failed", map[string]any{
 						"error": err.Error(),
@@ -324,6 +501,9 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		if docPath != "" {
 			localFiles = append(localFiles, docPath)
 			mediaPaths = append(mediaPaths, docPath)
+			if strings.HasPrefix(message.Document.MimeType, "image/") {
+				images = c.appendImage(images, docPath)
+			}
 			if content != "" {
 				content += "\n"
 			}
@@ -383,11 +563,87 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		"peer_kind":  peerKind,
 		"peer_id":    peerID,
 	}
+	if replyVoice {
+		metadata[bus.MetadataReplyVoice] = "true"
+	}
 
-	c.HandleMessage(senderID, fmt.Sprintf("%d", chatID), content, mediaPaths, metadata)
+	c.HandleMessageWithImages(senderID, fmt.Sprintf("%d", chatID), content, mediaPaths, images, metadata)
 	return nil
 }
 
+// handleButton handles a pressed reply button (see bus.OutboundMessage
+// Buttons): its label arrives as a message from the user who pressed it,
+// marked as a button. The buttons stay until the agent takes the answer and
+// clears them, so someone else pressing one doesn't take them away.
+func (c *TelegramChannel) handleButton(ctx context.Context, query telego.CallbackQuery) error {
+	if err := c.bot.AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID)); err != nil {
+		logger.DebugCF("telegram", "Failed to answer button press", map[string]any{"error": err.Error()})
+	}
+	if query.Message == nil || query.Data == "" {
+		return nil
+	}
+
+	senderID := telegramSenderID(&query.From)
+	if !c.IsAllowed(senderID) {
+		logger.DebugCF("telegram", "Button press rejected by allowlist", map[string]any{
+			"user_id": senderID,
+		})
+		return nil
+	}
+
+	chat := query.Message.GetChat()
+	peerKind := "direct"
+	peerID := fmt.Sprintf("%d", query.From.ID)
+	if chat.Type != "private" {
+		peerKind = "group"
+		peerID = fmt.Sprintf("%d", chat.ID)
+	}
+	c.HandleMessage(senderID, fmt.Sprintf("%d", chat.ID), query.Data, nil, map[string]string{
+		"user_id":          fmt.Sprintf("%d", query.From.ID),
+		"username":         query.From.Username,
+		"first_name":       query.From.FirstName,
+		"is_group":         fmt.Sprintf("%t", chat.Type != "private"),
+		"peer_kind":        peerKind,
+		"peer_id":          peerID,
+		bus.MetadataButton: "true",
+	})
+	return nil
+}
+
+// replyKeyboard shows buttons as an inline keyboard, one row, or returns
+// nil when there are none. Each button sends its label back.
+func replyKeyboard(buttons []string) *telego.InlineKeyboardMarkup {
+	if len(buttons) == 0 {
+		return nil
+	}
+	row := make([]telego.InlineKeyboardButton, 0, len(buttons))
+	for _, label := range buttons {
+		row = append(row, tu.InlineKeyboardButton(label).WithCallbackData(label))
+	}
+	return tu.InlineKeyboard(row)
+}
+
+func telegramSenderID(user *telego.User) string {
+	if user.Username != "" {
+		return fmt.Sprintf("%d|%s", user.ID, user.Username)
+	}
+	return fmt.Sprintf("%d", user.ID)
+}
+
+// appendImage inlines a downloaded image so the model can see it; the local
+// file is removed once the message is handled.
+func (c *TelegramChannel) appendImage(images []string, path string) []string {
+	dataURL, err := utils.ImageDataURL(path)
+	if err != nil {
+		logger.WarnCF("telegram", "Failed to attach image", map[string]any{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return images
+	}
+	return append(images, dataURL)
+}
+
 func (c *TelegramChannel) downloadPhoto(ctx context.Context, fileID string) string {
 	file, err := c.bot.GetFile(ctx, &telego.GetFileParams{FileID: fileID})
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 
 	"github.com/caarlos0/env/v11"
@@ -53,10 +54,35 @@ type Config struct {
 	Channels  ChannelsConfig  `json:"channels"`
 	Providers ProvidersConfig `json:"providers,omitempty"`
 	ModelList []ModelConfig   `json:"model_list"` // New model-centric provider configuration
+
+	// Custom protocol prefixes for model_list entries, e.g. "work" makes
+	// "work/gpt-4o" use the work gateway's endpoint and credentials
+	ProviderAliases map[string]ProviderAlias `json:"provider_aliases,omitempty"`
+
 	Gateway   GatewayConfig   `json:"gateway"`
 	Tools     ToolsConfig     `json:"tools"`
 	Heartbeat HeartbeatConfig `json:"heartbeat"`
 	Devices   DevicesConfig   `json:"devices"`
+
+	Transcription TranscriptionConfig `json:"transcription"`
+	TTS           TTSConfig           `json:"tts"`
+
+	// Connections to model APIs
+	HTTP HTTPConfig `json:"http"`
+
+	// Files kept in the workspace
+	Storage StorageConfig `json:"storage"`
+
+	// People the agent serves, by name. When set, only they are served,
+	// each with their own sessions, memory, quota and tools.
+	Users map[string]UserConfig `json:"users,omitempty"`
+
+	// Outside events that start an agent run, run by the gateway
+	Triggers []TriggerConfig `json:"triggers,omitempty"`
+
+	// Named sets of overrides, selected with --profile or PICOCLAW_PROFILE
+	Profiles map[string]json.RawMessage `json:"profiles,omitempty"`
+	Profile  string                     `json:"-"` // Active profile, if any
 }
 
 // MarshalJSON implements custom JSON marshaling for Config
@@ -136,6 +162,14 @@ type AgentConfig struct {
 	Model     *AgentModelConfig `json:"model,omitempty"`
 	Skills    []string          `json:"skills,omitempty"`
 	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+
+	// Persona added to the system prompt, and the tools the agent may use
+	// (all when empty)
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Tools        []string `json:"tools,omitempty"`
+
+	// Review pass for this agent's answers, replacing agents.defaults.review
+	Review *ReviewConfig `json:"review,omitempty"`
 }
 
 type SubagentsConfig struct {
@@ -161,6 +195,31 @@ type AgentBinding struct {
 	Match   BindingMatch `json:"match"`
 }
 
+// UserConfig is one person the agent serves. See Config.Users.
+type UserConfig struct {
+	// Their sender IDs, as "<channel>:<id>", e.g. "telegram:123456789"
+	IDs []string `json:"ids"`
+	// The tools their requests may use; empty allows every tool
+	Tools []string `json:"tools,omitempty"`
+	// Estimated LLM spend allowed per day; 0 means no limit
+	DailyLimitUSD float64 `json:"daily_limit_usd,omitempty"`
+	// Tokens allowed per day, prompt and completion together; 0 means no limit
+	DailyTokens int `json:"daily_tokens,omitempty"`
+}
+
+// FindUser returns the name and settings of the user with sender ID id
+// ("<channel>:<id>"), matched without regard to case.
+func (c *Config) FindUser(id string) (string, *UserConfig, bool) {
+	for name, u := range c.Users {
+		for _, uid := range u.IDs {
+			if strings.EqualFold(strings.TrimSpace(uid), id) {
+				return name, &u, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
 type SessionConfig struct {
 	DMScope       string              `json:"dm_scope,omitempty"`
 	IdentityLinks map[string][]string `json:"identity_links,omitempty"`
@@ -178,15 +237,124 @@ type AgentDefaults struct {
 	MaxTokens           int      `json:"max_tokens"                      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
 	Temperature         *float64 `json:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations   int      `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	MaxToolCalls        int      `json:"max_tool_calls,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_CALLS"`     // Tool calls per request; 0 uses the default, -1 means no limit
+	MaxTurnMinutes      int      `json:"max_turn_minutes,omitempty"      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TURN_MINUTES"`   // Wall-clock minutes per request; 0 uses the default, -1 means no limit
+	MaxParallelTools    int      `json:"max_parallel_tools,omitempty"    env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TOOLS"` // Concurrent tool calls per turn; 0 uses the default, 1 runs them one by one
+	Streaming           bool     `json:"streaming,omitempty"             env:"PICOCLAW_AGENTS_DEFAULTS_STREAMING"`
+	ContextWindow       int      `json:"context_window,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_WINDOW"`    // Tokens; 0 derives it from the model
+	MaxContinuations    int      `json:"max_continuations,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_CONTINUATIONS"` // Follow-up requests for a reply cut off by max_tokens; 0 uses the default, -1 disables
+
+	// OnNewMessage is what happens to a message sent while the agent is
+	// still working on the chat's previous one: "queue" (the default)
+	// answers it afterwards, "steer" adds it to the running turn before its
+	// next model call, and "restart" stops the running turn and answers the
+	// new message instead.
+	OnNewMessage string `json:"on_new_message,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_ON_NEW_MESSAGE"`
+
+	// ModelRoles assigns model_list entries (by model_name) to jobs: "chat",
+	// "summarize", "embed", "vision", "cheap" and "image_gen". See
+	// ModelForRole.
+	ModelRoles map[string]string `json:"model_roles,omitempty"`
+
+	// Budget moves chat to a cheaper model once the day's spend reaches a
+	// limit. See BudgetConfig.
+	Budget BudgetConfig `json:"budget,omitempty"`
+
+	// Planning runs complex requests as a plan of steps. See PlanningConfig.
+	Planning PlanningConfig `json:"planning,omitempty"`
+
+	// Memory keeps facts learned in conversations for later turns. See
+	// MemoryConfig.
+	Memory MemoryConfig `json:"memory,omitempty"`
+
+	// Review has a second model check answers before they are sent. See
+	// ReviewConfig.
+	Review ReviewConfig `json:"review,omitempty"`
+}
+
+// BudgetConfig caps daily LLM spend. Once the estimated cost of the current
+// local day reaches DailyLimitUSD, chat turns use Model (a model_list entry;
+// default: the "cheap" model role) until midnight, and each chat is told
+// once. Messages with an explicit "@<model_name>:" prefix are not affected.
+type BudgetConfig struct {
+	DailyLimitUSD float64 `json:"daily_limit_usd,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_DAILY_LIMIT_USD"`
+	Model         string  `json:"model,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_BUDGET_MODEL"`
+}
+
+// PlanningConfig controls plan-then-execute. With Mode "auto" the model
+// first decides whether a request needs a plan, with "always" every request
+// gets one, and with "off" (the default) only messages starting with /plan
+// do. With RequireApproval the plan is shown and runs once the user agrees.
+type PlanningConfig struct {
+	Mode            string `json:"mode,omitempty"             env:"PICOCLAW_AGENTS_DEFAULTS_PLANNING_MODE"`
+	RequireApproval bool   `json:"require_approval,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_PLANNING_REQUIRE_APPROVAL"`
+	MaxSteps        int    `json:"max_steps,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_PLANNING_MAX_STEPS"` // 0 uses the default
+}
+
+// MemoryConfig controls semantic long-term memory. When enabled, facts worth
+// keeping are extracted after each turn and stored with their embeddings in
+// the workspace (memory/facts.db); the TopK most relevant to a message, with
+// a similarity of at least MinScore, are added to its system prompt. It needs
+// a model for the "embed" role.
+//
+// Consolidation ("daily", "weekly" or "off") periodically has the cheap model
+// merge related facts and drop outdated ones; at most MaxFacts are kept, the
+// oldest going first.
+type MemoryConfig struct {
+	Enabled       bool    `json:"enabled,omitempty"       env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_ENABLED"`
+	TopK          int     `json:"top_k,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_TOP_K"`         // 0 uses the default
+	MinScore      float64 `json:"min_score,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_MIN_SCORE"`     // 0 uses the default
+	Consolidation string  `json:"consolidation,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_CONSOLIDATION"` // "" means "daily"
+	MaxFacts      int     `json:"max_facts,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_MAX_FACTS"`     // 0 uses the default
+}
+
+// ReviewConfig controls the review pass. When enabled, a reviewer model
+// (Model, a model_list entry; default: the "cheap" model role) checks each
+// answer against the user's request, and when it finds problems the agent
+// revises the answer once before it is sent. Channels limits the pass to
+// those channels (all when empty). Reviewed answers aren't streamed.
+type ReviewConfig struct {
+	Enabled  bool     `json:"enabled,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_REVIEW_ENABLED"`
+	Model    string   `json:"model,omitempty"    env:"PICOCLAW_AGENTS_DEFAULTS_REVIEW_MODEL"`
+	Channels []string `json:"channels,omitempty"`
 }
 
 // GetModelName returns the effective model name for the agent defaults.
-// It prefers the new "model_name" field but falls back to "model" for backward compatibility.
+// It prefers the new "model_name" field but falls back to "model" for backward compatibility,
+// then to the "chat" model role.
 func (d *AgentDefaults) GetModelName() string {
 	if d.ModelName != "" {
 		return d.ModelName
 	}
-	return d.Model
+	if d.Model != "" {
+		return d.Model
+	}
+	return d.ModelRoles["chat"]
+}
+
+// roleFallbacks is the role tried next when a role has no model assigned.
+// Summaries fall back to the cheap model; everything else ends at chat.
+var roleFallbacks = map[string]string{
+	"summarize": "cheap",
+	"cheap":     "chat",
+	"vision":    "chat",
+}
+
+// ModelForRole returns the model_name that serves role, following role
+// fallbacks (summarize -> cheap -> chat, vision -> image_model -> chat). It
+// returns "" when the job should use the agent's own chat model, and for
+// an unassigned "embed" or "image_gen", which have no fallback.
+func (d *AgentDefaults) ModelForRole(role string) string {
+	for role != "" && role != "chat" {
+		if name := d.ModelRoles[role]; name != "" {
+			return name
+		}
+		if role == "vision" && d.ImageModel != "" {
+			return d.ImageModel
+		}
+		role = roleFallbacks[role]
+	}
+	return ""
 }
 
 type ChannelsConfig struct {
@@ -202,6 +370,7 @@ type ChannelsConfig struct {
 	OneBot   OneBotConfig   `json:"onebot"`
 	WeCom    WeComConfig    `json:"wecom"`
 	WeComApp WeComAppConfig `json:"wecom_app"`
+	Email    EmailConfig    `json:"email"`
 }
 
 type WhatsAppConfig struct {
@@ -306,9 +475,101 @@ type WeComAppConfig struct {
 	ReplyTimeout   int                 `json:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
 }
 
+// EmailConfig lets people email the agent: new mail from AllowFrom in
+// Folders is checked for every PollSeconds, handled and marked read, and
+// answered by email. IMAP on port 993 and SMTP on port 465 use TLS from the
+// start; other ports must offer STARTTLS, unless the server is on this
+// machine.
+type EmailConfig struct {
+	Enabled     bool                `json:"enabled"      env:"PICOCLAW_CHANNELS_EMAIL_ENABLED"`
+	Address     string              `json:"address"      env:"PICOCLAW_CHANNELS_EMAIL_ADDRESS"`
+	Username    string              `json:"username"     env:"PICOCLAW_CHANNELS_EMAIL_USERNAME"` // Default: address
+	Password    string              `json:"password"     env:"PICOCLAW_CHANNELS_EMAIL_PASSWORD"`
+	IMAPHost    string              `json:"imap_host"    env:"PICOCLAW_CHANNELS_EMAIL_IMAP_HOST"`
+	IMAPPort    int                 `json:"imap_port"    env:"PICOCLAW_CHANNELS_EMAIL_IMAP_PORT"`
+	SMTPHost    string              `json:"smtp_host"    env:"PICOCLAW_CHANNELS_EMAIL_SMTP_HOST"`
+	SMTPPort    int                 `json:"smtp_port"    env:"PICOCLAW_CHANNELS_EMAIL_SMTP_PORT"`
+	Folders     []string            `json:"folders"      env:"PICOCLAW_CHANNELS_EMAIL_FOLDERS"`
+	PollSeconds int                 `json:"poll_seconds" env:"PICOCLAW_CHANNELS_EMAIL_POLL_SECONDS"`
+	AllowFrom   FlexibleStringSlice `json:"allow_from"   env:"PICOCLAW_CHANNELS_EMAIL_ALLOW_FROM"`
+}
+
+// HeartbeatConfig controls the periodic heartbeat, a turn in which the agent
+// reviews HEARTBEAT.md and acts or messages the user on its own.
 type HeartbeatConfig struct {
 	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
 	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
+
+	// QuietHours is a daily "HH:MM-HH:MM" window, in TZ or the server's
+	// time zone, in which no heartbeat runs, e.g. "22:00-07:00".
+	QuietHours string `json:"quiet_hours,omitempty" env:"PICOCLAW_HEARTBEAT_QUIET_HOURS"`
+	TZ         string `json:"tz,omitempty"          env:"PICOCLAW_HEARTBEAT_TZ"`
+
+	// MaxRunsPerDay and DailyLimitUSD stop heartbeats for the rest of the
+	// day once that many have run or heartbeats have spent that much.
+	// Heartbeats also stop when agents.defaults.budget.daily_limit_usd is
+	// reached. 0 means no limit.
+	MaxRunsPerDay int     `json:"max_runs_per_day,omitempty" env:"PICOCLAW_HEARTBEAT_MAX_RUNS_PER_DAY"`
+	DailyLimitUSD float64 `json:"daily_limit_usd,omitempty"  env:"PICOCLAW_HEARTBEAT_DAILY_LIMIT_USD"`
+}
+
+// Trigger types; see TriggerConfig.Type.
+const (
+	TriggerFile    = "file"
+	TriggerWebhook = "webhook"
+	TriggerMQTT    = "mqtt"
+	TriggerGPIO    = "gpio"
+	// TriggerHomeAssistant uses the instance of tools.homeassistant.
+	TriggerHomeAssistant = "homeassistant"
+)
+
+// TriggerConfig is an outside event that starts an agent run, with the
+// event's payload as the user message. See Config.Triggers.
+type TriggerConfig struct {
+	Name string `json:"name"`
+	// file, webhook, mqtt, gpio or homeassistant
+	Type string `json:"type"`
+	// Instructions put before the payload, e.g. "Summarize this report"
+	Prompt string `json:"prompt,omitempty"`
+	// Chat the run answers in; empty uses the chat used last
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	// Events arriving sooner than this after a run are dropped
+	CooldownSeconds int `json:"cooldown_seconds,omitempty"`
+
+	// file: the directory watched for new files, and the glob their
+	// names must match (default: all)
+	Path    string `json:"path,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+
+	// webhook: the secret callers must send, as a bearer token or the
+	// X-Trigger-Secret header. The hook is POST /triggers/<name> on the
+	// gateway port.
+	Secret string `json:"secret,omitempty"`
+
+	// mqtt: the broker, as tcp://host:1883 or tls://host:8883, and the
+	// topic filter to subscribe to. Without a broker, that of tools.mqtt
+	// is used, with its login. Over TLS, ca_file trusts a private CA and
+	// cert_file and key_file log in with a client certificate (PEM files).
+	Broker   string `json:"broker,omitempty"`
+	Topic    string `json:"topic,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	CAFile   string `json:"ca_file,omitempty"`
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+
+	// gpio: the sysfs GPIO number and the edge that fires: rising,
+	// falling or both (default)
+	Pin  int    `json:"pin,omitempty"`
+	Edge string `json:"edge,omitempty"`
+
+	// homeassistant: the event types that fire (default: state_changed,
+	// where only changes of state fire, not of attributes alone), and
+	// globs of the entities they must be about (default: all those
+	// tools.homeassistant.entities allows)
+	Events   []string `json:"events,omitempty"`
+	Entities []string `json:"entities,omitempty"`
 }
 
 type DevicesConfig struct {
@@ -316,6 +577,44 @@ type DevicesConfig struct {
 	MonitorUSB bool `json:"monitor_usb" env:"PICOCLAW_DEVICES_MONITOR_USB"`
 }
 
+// TranscriptionConfig selects the speech-to-text backend for voice messages.
+type TranscriptionConfig struct {
+	// Provider is "openai", "groq", "whisper-cpp" or "none". Empty uses Groq
+	// when a Groq API key is configured anywhere.
+	Provider string `json:"provider" env:"PICOCLAW_TRANSCRIPTION_PROVIDER"`
+	APIKey   string `json:"api_key"  env:"PICOCLAW_TRANSCRIPTION_API_KEY"`
+	APIBase  string `json:"api_base" env:"PICOCLAW_TRANSCRIPTION_API_BASE"`
+	// Model is the API model name, or the ggml model file for whisper-cpp.
+	Model string `json:"model" env:"PICOCLAW_TRANSCRIPTION_MODEL"`
+	// Language is an ISO-639-1 hint such as "en"; empty auto-detects.
+	Language string `json:"language" env:"PICOCLAW_TRANSCRIPTION_LANGUAGE"`
+	// Command and FFmpeg are the binaries used by whisper-cpp; they default
+	// to "whisper-cli" and "ffmpeg".
+	Command string `json:"command" env:"PICOCLAW_TRANSCRIPTION_COMMAND"`
+	FFmpeg  string `json:"ffmpeg"  env:"PICOCLAW_TRANSCRIPTION_FFMPEG"`
+	Timeout int    `json:"timeout" env:"PICOCLAW_TRANSCRIPTION_TIMEOUT"` // seconds, 0 for the backend default
+}
+
+// TTSConfig selects the text-to-speech backend used for voice replies.
+type TTSConfig struct {
+	// Provider is "openai", "elevenlabs" or "piper"; empty disables voice
+	// replies.
+	Provider string `json:"provider" env:"PICOCLAW_TTS_PROVIDER"`
+	APIKey   string `json:"api_key"  env:"PICOCLAW_TTS_API_KEY"`
+	APIBase  string `json:"api_base" env:"PICOCLAW_TTS_API_BASE"`
+	// Model is the API model, or the .onnx voice file for piper.
+	Model string `json:"model" env:"PICOCLAW_TTS_MODEL"`
+	// Voice is the OpenAI voice name or the ElevenLabs voice ID.
+	Voice string `json:"voice" env:"PICOCLAW_TTS_VOICE"`
+	// Command and FFmpeg are the binaries used by piper; they default to
+	// "piper" and "ffmpeg".
+	Command string `json:"command" env:"PICOCLAW_TTS_COMMAND"`
+	FFmpeg  string `json:"ffmpeg"  env:"PICOCLAW_TTS_FFMPEG"`
+	// MaxChars skips speaking longer replies; 0 uses 4000.
+	MaxChars int `json:"max_chars" env:"PICOCLAW_TTS_MAX_CHARS"`
+	Timeout  int `json:"timeout"   env:"PICOCLAW_TTS_TIMEOUT"` // seconds, 0 for the backend default
+}
+
 type ProvidersConfig struct {
 	Anthropic     ProviderConfig       `json:"anthropic"`
 	OpenAI        OpenAIProviderConfig `json:"openai"`
@@ -384,6 +683,12 @@ type OpenAIProviderConfig struct {
 	WebSearch bool `json:"web_search" env:"PICOCLAW_PROVIDERS_OPENAI_WEB_SEARCH"`
 }
 
+// ModelPricing is a model's price in USD per million tokens.
+type ModelPricing struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
 // ModelConfig represents a model-centric provider configuration.
 // It allows adding new providers (especially OpenAI-compatible ones) via configuration only.
 // The model field uses protocol prefix format: [protocol/]model-identifier
@@ -397,17 +702,180 @@ type ModelConfig struct {
 	// HTTP-based providers
 	APIBase string `json:"api_base,omitempty"` // API endpoint URL
 	APIKey  string `json:"api_key"`            // API authentication key
-	Proxy   string `json:"proxy,omitempty"`    // HTTP proxy URL
+	Proxy   string `json:"proxy,omitempty"`    // http(s):// or socks5:// proxy URL, or "direct" to ignore HTTPS_PROXY
+
+	// api_key may also be a list: requests rotate through the keys and a key
+	// answered with 429 or 401 is parked for key_cooldown seconds (default
+	// 60). APIKey holds the first key.
+	APIKeys     []string `json:"-"`
+	KeyCooldown int      `json:"key_cooldown,omitempty"`
+
+	// Hosts reached without the proxy, NO_PROXY style: names (matching
+	// subdomains too), IPs, CIDR ranges, optional ":port", or "*"
+	NoProxy []string `json:"no_proxy,omitempty"`
+
+	// OpenAI-compatible endpoints behind enterprise gateways: extra headers
+	// sent with every request, and PEM files for mutual TLS. tls_ca is
+	// trusted in addition to the system roots.
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+	TLSCert      string            `json:"tls_cert,omitempty"` // Client certificate
+	TLSKey       string            `json:"tls_key,omitempty"`  // Client certificate key
+	TLSCA        string            `json:"tls_ca,omitempty"`   // CA bundle for the server certificate
 
 	// Special providers (CLI-based, OAuth, etc.)
 	AuthMethod  string `json:"auth_method,omitempty"`  // Authentication method: oauth, token
 	ConnectMode string `json:"connect_mode,omitempty"` // Connection mode: stdio, grpc
 	Workspace   string `json:"workspace,omitempty"`    // Workspace path for CLI-based providers
 
+	// storage.encryption_key, injected like Workspace; encrypts what record
+	// and cache write
+	EncryptionKey string `json:"-"`
+
+	// CLI-based providers (claude-cli, codex-cli) run in workspace.
+	// allowed_tools lists the CLI's own tools it may use without asking
+	// (claude-cli only); cli_args are appended to every invocation.
+	AllowedTools []string `json:"allowed_tools,omitempty"`
+	CLIArgs      []string `json:"cli_args,omitempty"`
+
 	// Optional optimizations
-	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit
+	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit, shared by entries with the same protocol and api_base
+	TPM            int    `json:"tpm,omitempty"`              // Tokens per minute limit, shared the same way
 	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
 	RequestTimeout int    `json:"request_timeout,omitempty"`
+	MaxAttempts    int    `json:"max_attempts,omitempty"`   // Tries per request on 429/5xx/connection resets (default 3, 1 disables retries)
+	ContextWindow  int    `json:"context_window,omitempty"` // Model context size in tokens; 0 uses the built-in table
+
+	// Reasoning models: reasoning_effort is "none", "minimal", "low", "medium"
+	// or "high"; thinking_budget caps thinking tokens and takes precedence for
+	// protocols that budget tokens (anthropic, gemini). Each is derived from
+	// the other when only one is set.
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+	ThinkingBudget  int    `json:"thinking_budget,omitempty"`
+
+	// Scoring (OpenAI-compatible protocols): return token log probabilities
+	// with up to top_logprobs alternatives (0-20; implies logprobs), and
+	// bias sampling by token ID (-100 bans a token, 100 forces it)
+	Logprobs    bool               `json:"logprobs,omitempty"`
+	TopLogprobs int                `json:"top_logprobs,omitempty"`
+	LogitBias   map[string]float64 `json:"logit_bias,omitempty"`
+
+	// Tools the provider runs itself, offered with every request: "web_search"
+	// (anthropic and OpenAI-compatible), "web_fetch" and "code_execution"
+	// (anthropic), or a tool object passed through as-is
+	NativeTools []any `json:"native_tools,omitempty"`
+
+	// Prompt adjustments some (mostly local) models need to behave well
+	PromptProfile *PromptProfile `json:"prompt_profile,omitempty"`
+
+	// Response cache: requests identical to an earlier one are answered from
+	// this directory (relative to the workspace) instead of the API
+	Cache    string `json:"cache,omitempty"`
+	CacheTTL int    `json:"cache_ttl,omitempty"` // Seconds a cached response stays valid (default 86400)
+
+	// Debugging: append every request/response pair to this JSONL file
+	// (relative to the workspace), with secrets redacted. Replay it with
+	// model "replay/<file>".
+	Record string `json:"record,omitempty"`
+
+	// Usage accounting: overrides the built-in price table (USD per million tokens)
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+
+	// Azure OpenAI: model is "azure/<deployment>"; api_base overrides resource
+	Resource   string `json:"resource,omitempty"`    // Resource name, i.e. <resource>.openai.azure.com
+	APIVersion string `json:"api_version,omitempty"` // Defaults to a recent GA version
+
+	// AWS Bedrock: credentials from AWS_* env vars or the shared credentials file
+	Region  string `json:"region,omitempty"`  // Defaults to AWS_REGION, then us-east-1
+	Profile string `json:"profile,omitempty"` // Shared credentials profile
+
+	// Gemini
+	SafetyThreshold string `json:"safety_threshold,omitempty"` // Applied to all harm categories (e.g. "BLOCK_ONLY_HIGH")
+
+	// llama.cpp
+	Grammar string `json:"grammar,omitempty"` // GBNF grammar for every completion; overrides the tool-call grammar
+
+	// Ollama native API (protocol "ollama-native")
+	KeepAlive string `json:"keep_alive,omitempty"` // How long the model stays loaded: "30m", "-1" (forever), "0" (unload)
+	AutoPull  bool   `json:"auto_pull,omitempty"`  // Pull a missing model on first use, reporting progress to the chat
+
+	// Embeddings: used when this entry serves an embedding model
+	Dimensions         int `json:"dimensions,omitempty"`           // Output vector size for models that support shortening; 0 uses the model default
+	EmbeddingBatchSize int `json:"embedding_batch_size,omitempty"` // Inputs per embeddings request (default 64, at most 100 for gemini)
+
+	// Failover: models tried in order when this one is rate-limited, failing or
+	// timing out. Entries are model_name aliases from model_list, or
+	// protocol/model references that reuse this entry's credentials.
+	Fallbacks       []string       `json:"fallbacks,omitempty"`
+	FallbackConfigs []*ModelConfig `json:"-"` // Resolved Fallbacks, set by GetModelConfig
+}
+
+// UnmarshalJSON accepts api_key as a string or a list of strings.
+func (c *ModelConfig) UnmarshalJSON(data []byte) error {
+	type plain ModelConfig
+	aux := struct {
+		*plain
+		APIKey json.RawMessage `json:"api_key"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.APIKey) == 0 || string(aux.APIKey) == "null" {
+		return nil
+	}
+	c.APIKey, c.APIKeys = "", nil
+	if err := json.Unmarshal(aux.APIKey, &c.APIKey); err == nil {
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal(aux.APIKey, &keys); err != nil {
+		return fmt.Errorf("api_key: must be a string or a list of strings")
+	}
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); k != "" {
+			c.APIKeys = append(c.APIKeys, k)
+		}
+	}
+	if len(c.APIKeys) > 0 {
+		c.APIKey = c.APIKeys[0]
+	}
+	if len(c.APIKeys) == 1 {
+		c.APIKeys = nil
+	}
+	return nil
+}
+
+// MarshalJSON writes api_key as a list when the entry has several keys.
+func (c ModelConfig) MarshalJSON() ([]byte, error) {
+	type plain ModelConfig
+	if len(c.APIKeys) == 0 {
+		return json.Marshal(plain(c))
+	}
+	return json.Marshal(struct {
+		plain
+		APIKey []string `json:"api_key"`
+	}{plain(c), c.APIKeys})
+}
+
+// PromptProfile adapts requests to a model's expected prompt format. It is
+// applied to every request sent to the model, after the agent builds it.
+type PromptProfile struct {
+	SystemPrefix string   `json:"system_prefix,omitempty"` // Prepended to the system prompt
+	SystemSuffix string   `json:"system_suffix,omitempty"` // Appended to the system prompt
+	Stop         []string `json:"stop,omitempty"`          // Extra stop sequences
+	Temperature  *float64 `json:"temperature,omitempty"`   // Replaces the agent's temperature for this model
+}
+
+// ProviderAlias is a named endpoint that model_list entries address as a
+// protocol prefix. Its settings fill in those the entry leaves empty.
+type ProviderAlias struct {
+	Protocol     string            `json:"protocol,omitempty"` // Protocol the endpoint speaks (default "openai")
+	APIBase      string            `json:"api_base,omitempty"`
+	APIKey       string            `json:"api_key,omitempty"`
+	Proxy        string            `json:"proxy,omitempty"`
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"` // Merged with the entry's; the entry wins
+	TLSCert      string            `json:"tls_cert,omitempty"`
+	TLSKey       string            `json:"tls_key,omitempty"`
+	TLSCA        string            `json:"tls_ca,omitempty"`
 }
 
 // Validate checks if the ModelConfig has all required fields.
@@ -421,40 +889,101 @@ func (c *ModelConfig) Validate() error {
 	return nil
 }
 
+// HTTPConfig tunes the connection pool shared by the model providers. On
+// small boards, keeping connections alive avoids repeated TLS handshakes and
+// HTTP/1.1 uses less memory than HTTP/2. Zero values keep the defaults.
+type HTTPConfig struct {
+	MaxIdleConns        int  `json:"max_idle_conns,omitempty"          env:"PICOCLAW_HTTP_MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host,omitempty" env:"PICOCLAW_HTTP_MAX_IDLE_CONNS_PER_HOST"`
+	IdleConnTimeout     int  `json:"idle_conn_timeout,omitempty"       env:"PICOCLAW_HTTP_IDLE_CONN_TIMEOUT"` // Seconds
+	DisableHTTP2        bool `json:"disable_http2,omitempty"           env:"PICOCLAW_HTTP_DISABLE_HTTP2"`
+}
+
+// StorageConfig controls how conversations are kept on disk.
+type StorageConfig struct {
+	// Key for encrypting sessions, memory files, transcripts and cached
+	// responses with AES-256-GCM: a base64-encoded 32-byte key or a
+	// passphrase. Empty stores them unencrypted.
+	EncryptionKey string `json:"encryption_key,omitempty" env:"PICOCLAW_STORAGE_ENCRYPTION_KEY"`
+}
+
 type GatewayConfig struct {
 	Host string `json:"host" env:"PICOCLAW_GATEWAY_HOST"`
 	Port int    `json:"port" env:"PICOCLAW_GATEWAY_PORT"`
 }
 
 type BraveConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEY"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
+	Enabled           bool   `json:"enabled"                       env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
+	APIKey            string `json:"api_key"                       env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEY"`
+	MaxResults        int    `json:"max_results"                   env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty" env:"PICOCLAW_TOOLS_WEB_BRAVE_REQUESTS_PER_MINUTE"`
 }
 
 type TavilyConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_TAVILY_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_TAVILY_API_KEY"`
-	BaseURL    string `json:"base_url"    env:"PICOCLAW_TOOLS_WEB_TAVILY_BASE_URL"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_TAVILY_MAX_RESULTS"`
+	Enabled           bool   `json:"enabled"                       env:"PICOCLAW_TOOLS_WEB_TAVILY_ENABLED"`
+	APIKey            string `json:"api_key"                       env:"PICOCLAW_TOOLS_WEB_TAVILY_API_KEY"`
+	BaseURL           string `json:"base_url"                      env:"PICOCLAW_TOOLS_WEB_TAVILY_BASE_URL"`
+	MaxResults        int    `json:"max_results"                   env:"PICOCLAW_TOOLS_WEB_TAVILY_MAX_RESULTS"`
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty" env:"PICOCLAW_TOOLS_WEB_TAVILY_REQUESTS_PER_MINUTE"`
 }
 
 type DuckDuckGoConfig struct {
-	Enabled    bool `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_ENABLED"`
-	MaxResults int  `json:"max_results" env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_MAX_RESULTS"`
+	Enabled           bool `json:"enabled"                       env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_ENABLED"`
+	MaxResults        int  `json:"max_results"                   env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_MAX_RESULTS"`
+	RequestsPerMinute int  `json:"requests_per_minute,omitempty" env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_REQUESTS_PER_MINUTE"`
 }
 
 type PerplexityConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_API_KEY"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_MAX_RESULTS"`
+	Enabled           bool   `json:"enabled"                       env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_ENABLED"`
+	APIKey            string `json:"api_key"                       env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_API_KEY"`
+	MaxResults        int    `json:"max_results"                   env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_MAX_RESULTS"`
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty" env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_REQUESTS_PER_MINUTE"`
+}
+
+// SearXNGConfig points web search at a SearXNG instance, whose JSON output
+// (search.formats in its settings.yml) must be enabled.
+type SearXNGConfig struct {
+	Enabled           bool   `json:"enabled"                       env:"PICOCLAW_TOOLS_WEB_SEARXNG_ENABLED"`
+	BaseURL           string `json:"base_url"                      env:"PICOCLAW_TOOLS_WEB_SEARXNG_BASE_URL"`
+	MaxResults        int    `json:"max_results"                   env:"PICOCLAW_TOOLS_WEB_SEARXNG_MAX_RESULTS"`
+	RequestsPerMinute int    `json:"requests_per_minute,omitempty" env:"PICOCLAW_TOOLS_WEB_SEARXNG_REQUESTS_PER_MINUTE"`
+}
+
+// FetchURLConfig configures the fetch_url tool, which reads pages as
+// markdown. Pages are downloaded for at most TimeoutSeconds and up to
+// MaxSizeKB, and split into parts of PageChars characters. robots.txt is
+// obeyed unless IgnoreRobots is set.
+type FetchURLConfig struct {
+	TimeoutSeconds int  `json:"timeout_seconds"         env:"PICOCLAW_TOOLS_WEB_FETCH_TIMEOUT_SECONDS"`
+	MaxSizeKB      int  `json:"max_size_kb"             env:"PICOCLAW_TOOLS_WEB_FETCH_MAX_SIZE_KB"`
+	PageChars      int  `json:"page_chars"              env:"PICOCLAW_TOOLS_WEB_FETCH_PAGE_CHARS"`
+	IgnoreRobots   bool `json:"ignore_robots,omitempty" env:"PICOCLAW_TOOLS_WEB_FETCH_IGNORE_ROBOTS"`
 }
 
+// Web search backends, for WebToolsConfig.SearchBackend.
+const (
+	SearchBrave      = "brave"
+	SearchTavily     = "tavily"
+	SearchDuckDuckGo = "duckduckgo"
+	SearchPerplexity = "perplexity"
+	SearchSearXNG    = "searxng"
+)
+
+// WebToolsConfig configures web search and fetch. SearchBackend picks the
+// search backend; empty, the first enabled one of Perplexity, Brave,
+// Tavily, SearXNG and DuckDuckGo is used. A backend's RequestsPerMinute,
+// when set, caps the searches sent to it. Search results are cached in
+// the workspace for SearchCacheMinutes (0: no cache), and older ones are
+// used when the backend can't be reached.
 type WebToolsConfig struct {
-	Brave      BraveConfig      `json:"brave"`
-	Tavily     TavilyConfig     `json:"tavily"`
-	DuckDuckGo DuckDuckGoConfig `json:"duckduckgo"`
-	Perplexity PerplexityConfig `json:"perplexity"`
+	Brave              BraveConfig      `json:"brave"`
+	Tavily             TavilyConfig     `json:"tavily"`
+	DuckDuckGo         DuckDuckGoConfig `json:"duckduckgo"`
+	Perplexity         PerplexityConfig `json:"perplexity"`
+	SearXNG            SearXNGConfig    `json:"searxng"`
+	SearchBackend      string           `json:"search_backend,omitempty" env:"PICOCLAW_TOOLS_WEB_SEARCH_BACKEND"`
+	SearchCacheMinutes int              `json:"search_cache_minutes"     env:"PICOCLAW_TOOLS_WEB_SEARCH_CACHE_MINUTES"`
+	Fetch              FetchURLConfig   `json:"fetch"`
 	// Proxy is an optional proxy URL for web tools (http/https/socks5/socks5h).
 	// For authenticated proxies, prefer HTTP_PROXY/HTTPS_PROXY env vars instead of embedding credentials in config.
 	Proxy string `json:"proxy,omitempty" env:"PICOCLAW_TOOLS_WEB_PROXY"`
@@ -462,18 +991,504 @@ type WebToolsConfig struct {
 
 type CronToolsConfig struct {
 	ExecTimeoutMinutes int `json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	// CatchUp is what happens to runs missed while picoclaw was down:
+	// "once" (the default) runs each such job once on start, "skip" waits
+	// for its next run. A job can set its own.
+	CatchUp string `json:"catch_up,omitempty" env:"PICOCLAW_TOOLS_CRON_CATCH_UP"`
 }
 
+// ExecConfig limits the exec tool. With AllowPatterns set, only commands
+// that one of them matches in full run, and none that chain or substitute
+// other commands. TimeoutSeconds (default 60) and MaxOutputChars (default
+// 10000) bound each command.
 type ExecConfig struct {
-	EnableDenyPatterns bool     `json:"enable_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_ENABLE_DENY_PATTERNS"`
-	CustomDenyPatterns []string `json:"custom_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
+	EnableDenyPatterns bool              `json:"enable_deny_patterns"       env:"PICOCLAW_TOOLS_EXEC_ENABLE_DENY_PATTERNS"`
+	CustomDenyPatterns []string          `json:"custom_deny_patterns"       env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
+	AllowPatterns      []string          `json:"allow_patterns,omitempty"   env:"PICOCLAW_TOOLS_EXEC_ALLOW_PATTERNS"`
+	TimeoutSeconds     int               `json:"timeout_seconds,omitempty"  env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"`
+	MaxOutputChars     int               `json:"max_output_chars,omitempty" env:"PICOCLAW_TOOLS_EXEC_MAX_OUTPUT_CHARS"`
+	Sandbox            ExecSandboxConfig `json:"sandbox"`
+}
+
+// Exec sandbox modes.
+const (
+	SandboxBwrap    = "bwrap"
+	SandboxFirejail = "firejail"
+	SandboxDocker   = "docker"
+)
+
+// ExecSandboxConfig runs each exec command isolated from the host: in
+// bubblewrap or firejail, which leave the system read-only and the
+// workspace writable, or in a new Docker container with only the workspace
+// mounted. Commands get no network unless Network is set. An empty Mode
+// runs commands directly.
+type ExecSandboxConfig struct {
+	Mode     string `json:"mode,omitempty"      env:"PICOCLAW_TOOLS_EXEC_SANDBOX_MODE"`
+	Image    string `json:"image,omitempty"     env:"PICOCLAW_TOOLS_EXEC_SANDBOX_IMAGE"` // Docker only; default alpine:3
+	Network  bool   `json:"network,omitempty"   env:"PICOCLAW_TOOLS_EXEC_SANDBOX_NETWORK"`
+	MemoryMB int    `json:"memory_mb,omitempty" env:"PICOCLAW_TOOLS_EXEC_SANDBOX_MEMORY_MB"` // Docker only; 0 means no limit
+}
+
+// FileToolsConfig guards the file tools. AllowPaths are files and
+// directories outside the workspace they may use even with
+// restrict_to_workspace. Files over MaxFileSizeKB are neither read nor
+// written (0: no limit). Before a file is changed, its old version is
+// copied to .backups in the workspace, keeping the last Backups versions
+// of each file (0: no backups).
+type FileToolsConfig struct {
+	AllowPaths    []string `json:"allow_paths,omitempty" env:"PICOCLAW_TOOLS_FILES_ALLOW_PATHS"`
+	MaxFileSizeKB int      `json:"max_file_size_kb"      env:"PICOCLAW_TOOLS_FILES_MAX_FILE_SIZE_KB"`
+	Backups       int      `json:"backups"               env:"PICOCLAW_TOOLS_FILES_BACKUPS"`
+}
+
+// BrowserToolConfig enables the browser tool, which drives a headless
+// Chrome or Chromium for sites that need JavaScript. It only opens pages on
+// AllowDomains (a domain allows its subdomains too); ChromePath, if set,
+// is the browser to run instead of the first one found on the PATH.
+// TimeoutSeconds limits each action.
+type BrowserToolConfig struct {
+	Enabled        bool     `json:"enabled"                 env:"PICOCLAW_TOOLS_BROWSER_ENABLED"`
+	AllowDomains   []string `json:"allow_domains,omitempty" env:"PICOCLAW_TOOLS_BROWSER_ALLOW_DOMAINS"`
+	ChromePath     string   `json:"chrome_path,omitempty"   env:"PICOCLAW_TOOLS_BROWSER_CHROME_PATH"`
+	TimeoutSeconds int      `json:"timeout_seconds"         env:"PICOCLAW_TOOLS_BROWSER_TIMEOUT_SECONDS"`
+}
+
+// CodeToolConfig enables the run_code tool, which runs Python or JavaScript
+// snippets with Python and Node (commands on the PATH unless set) in the
+// exec sandbox, if one is set; Image is the Docker image to use instead of
+// the exec sandbox's. Each run is limited to TimeoutSeconds of real time,
+// CPUSeconds of CPU time and MemoryMB of memory. Files a snippet writes
+// are sent to the chat, up to MaxFileMB each.
+type CodeToolConfig struct {
+	Enabled        bool   `json:"enabled"          env:"PICOCLAW_TOOLS_CODE_ENABLED"`
+	Python         string `json:"python,omitempty" env:"PICOCLAW_TOOLS_CODE_PYTHON"`
+	Node           string `json:"node,omitempty"   env:"PICOCLAW_TOOLS_CODE_NODE"`
+	Image          string `json:"image,omitempty"  env:"PICOCLAW_TOOLS_CODE_IMAGE"`
+	TimeoutSeconds int    `json:"timeout_seconds"  env:"PICOCLAW_TOOLS_CODE_TIMEOUT_SECONDS"`
+	CPUSeconds     int    `json:"cpu_seconds"      env:"PICOCLAW_TOOLS_CODE_CPU_SECONDS"`
+	MemoryMB       int    `json:"memory_mb"        env:"PICOCLAW_TOOLS_CODE_MEMORY_MB"`
+	MaxFileMB      int    `json:"max_file_mb"      env:"PICOCLAW_TOOLS_CODE_MAX_FILE_MB"`
+}
+
+// CalendarToolConfig enables the calendar tool on one calendar: a CalDAV
+// collection, or a Google calendar after "picoclaw auth google-calendar".
+// Times given without a zone are in Timezone, an IANA name (default: the
+// server's).
+type CalendarToolConfig struct {
+	Enabled  bool                 `json:"enabled"            env:"PICOCLAW_TOOLS_CALENDAR_ENABLED"`
+	Provider string               `json:"provider"           env:"PICOCLAW_TOOLS_CALENDAR_PROVIDER"` // caldav or google
+	Timezone string               `json:"timezone,omitempty" env:"PICOCLAW_TOOLS_CALENDAR_TIMEZONE"`
+	CalDAV   CalDAVConfig         `json:"caldav"`
+	Google   GoogleCalendarConfig `json:"google"`
+}
+
+// CalDAVConfig is a calendar collection, such as
+// https://cloud.example.com/remote.php/dav/calendars/me/personal/.
+type CalDAVConfig struct {
+	URL      string `json:"url"      env:"PICOCLAW_TOOLS_CALENDAR_CALDAV_URL"`
+	Username string `json:"username" env:"PICOCLAW_TOOLS_CALENDAR_CALDAV_USERNAME"`
+	Password string `json:"password" env:"PICOCLAW_TOOLS_CALENDAR_CALDAV_PASSWORD"`
+}
+
+// GoogleCalendarConfig is the OAuth client ("Desktop app") created for
+// PicoClaw in the Google Cloud console, and the calendar to use (default
+// "primary").
+type GoogleCalendarConfig struct {
+	ClientID     string `json:"client_id"             env:"PICOCLAW_TOOLS_CALENDAR_GOOGLE_CLIENT_ID"`
+	ClientSecret string `json:"client_secret"         env:"PICOCLAW_TOOLS_CALENDAR_GOOGLE_CLIENT_SECRET"`
+	CalendarID   string `json:"calendar_id,omitempty" env:"PICOCLAW_TOOLS_CALENDAR_GOOGLE_CALENDAR_ID"`
+}
+
+// EmailToolConfig enables the email tool on a mailbox: searching and
+// reading the mail in Folders (all of them when empty) and sending mail.
+// Ports and TLS work as for the email channel.
+type EmailToolConfig struct {
+	Enabled  bool     `json:"enabled"   env:"PICOCLAW_TOOLS_EMAIL_ENABLED"`
+	Address  string   `json:"address"   env:"PICOCLAW_TOOLS_EMAIL_ADDRESS"`
+	Username string   `json:"username"  env:"PICOCLAW_TOOLS_EMAIL_USERNAME"` // Default: address
+	Password string   `json:"password"  env:"PICOCLAW_TOOLS_EMAIL_PASSWORD"`
+	IMAPHost string   `json:"imap_host" env:"PICOCLAW_TOOLS_EMAIL_IMAP_HOST"`
+	IMAPPort int      `json:"imap_port" env:"PICOCLAW_TOOLS_EMAIL_IMAP_PORT"`
+	SMTPHost string   `json:"smtp_host" env:"PICOCLAW_TOOLS_EMAIL_SMTP_HOST"`
+	SMTPPort int      `json:"smtp_port" env:"PICOCLAW_TOOLS_EMAIL_SMTP_PORT"`
+	Folders  []string `json:"folders"   env:"PICOCLAW_TOOLS_EMAIL_FOLDERS"`
+	// ReadOnly removes the send action.
+	ReadOnly bool `json:"read_only" env:"PICOCLAW_TOOLS_EMAIL_READ_ONLY"`
+}
+
+// FeedsToolConfig enables the feeds tool and the service behind it, which
+// follows RSS and Atom feeds subscribed to from chat. Feeds are fetched every
+// PollMinutes; digests hold up to MaxDigestItems items and, with
+// SummarizeDigests, are written up by the agent rather than sent as a list.
+// Topic filters need an embedding model (the embed role) and keep the items
+// at least MinScore similar to the topic.
+type FeedsToolConfig struct {
+	Enabled          bool    `json:"enabled"           env:"PICOCLAW_TOOLS_FEEDS_ENABLED"`
+	PollMinutes      int     `json:"poll_minutes"      env:"PICOCLAW_TOOLS_FEEDS_POLL_MINUTES"`
+	MaxDigestItems   int     `json:"max_digest_items"  env:"PICOCLAW_TOOLS_FEEDS_MAX_DIGEST_ITEMS"`
+	SummarizeDigests bool    `json:"summarize_digests" env:"PICOCLAW_TOOLS_FEEDS_SUMMARIZE_DIGESTS"`
+	MinScore         float64 `json:"min_score"         env:"PICOCLAW_TOOLS_FEEDS_MIN_SCORE"`
+}
+
+// NotesToolConfig enables the notes tool, a knowledge base of markdown files
+// in Dir: relative to the agent's workspace, or any folder, such as an
+// Obsidian vault. Notes are searched by meaning too when a model has the
+// embed role.
+type NotesToolConfig struct {
+	Enabled bool   `json:"enabled" env:"PICOCLAW_TOOLS_NOTES_ENABLED"`
+	Dir     string `json:"dir"     env:"PICOCLAW_TOOLS_NOTES_DIR"`
+}
+
+// TasksToolConfig enables the tasks tool, the user's to-do list, kept in
+// tasks.json in the workspace. While the heartbeat runs, it brings up the
+// overdue tasks, each at most once every NagHours; 0 turns that off.
+type TasksToolConfig struct {
+	Enabled  bool `json:"enabled"   env:"PICOCLAW_TOOLS_TASKS_ENABLED"`
+	NagHours int  `json:"nag_hours" env:"PICOCLAW_TOOLS_TASKS_NAG_HOURS"`
+}
+
+// HardwareToolsConfig lists the hardware of the board the agent may use.
+// The gpio tool reads GPIOInputs and GPIOOutputs (sysfs GPIO numbers) and
+// sets the outputs; the i2c tool reads and writes I2CDevices, as
+// "bus:address" such as "1:0x38", or "1:*" for a whole bus, and can scan
+// any bus; the serial tool talks to SerialPorts, at SerialBaud by default.
+// The gpio and serial tools are only there with pins or ports allowed.
+type HardwareToolsConfig struct {
+	GPIOInputs  []int    `json:"gpio_inputs"  env:"PICOCLAW_TOOLS_HARDWARE_GPIO_INPUTS"`
+	GPIOOutputs []int    `json:"gpio_outputs" env:"PICOCLAW_TOOLS_HARDWARE_GPIO_OUTPUTS"`
+	I2CDevices  []string `json:"i2c_devices"  env:"PICOCLAW_TOOLS_HARDWARE_I2C_DEVICES"`
+	SerialPorts []string `json:"serial_ports" env:"PICOCLAW_TOOLS_HARDWARE_SERIAL_PORTS"`
+	SerialBaud  int      `json:"serial_baud"  env:"PICOCLAW_TOOLS_HARDWARE_SERIAL_BAUD"`
+}
+
+// CameraToolConfig enables the capture_photo tool, which takes photos with
+// the V4L2 camera Device at about Width x Height, or with Command when set:
+// a shell command writing a JPEG or PNG to {path}, for cameras V4L2 can't
+// reach, such as "libcamera-still -n -o {path}". Photos are looked at by the
+// model with the vision role, else the agent's own.
+type CameraToolConfig struct {
+	Enabled bool   `json:"enabled" env:"PICOCLAW_TOOLS_CAMERA_ENABLED"`
+	Device  string `json:"device"  env:"PICOCLAW_TOOLS_CAMERA_DEVICE"`
+	Width   int    `json:"width"   env:"PICOCLAW_TOOLS_CAMERA_WIDTH"`
+	Height  int    `json:"height"  env:"PICOCLAW_TOOLS_CAMERA_HEIGHT"`
+	Command string `json:"command" env:"PICOCLAW_TOOLS_CAMERA_COMMAND"`
+}
+
+// ContainersToolConfig enables the containers tool, which lists, starts,
+// stops and restarts the Docker or Podman containers Containers matches, as
+// names or globs such as "media-*", and reads their logs and resource use;
+// none when empty. Socket is the engine's API socket; by default Docker's,
+// else Podman's, rootful then rootless. ReadOnly removes starting, stopping
+// and restarting.
+type ContainersToolConfig struct {
+	Enabled    bool     `json:"enabled"    env:"PICOCLAW_TOOLS_CONTAINERS_ENABLED"`
+	Socket     string   `json:"socket"     env:"PICOCLAW_TOOLS_CONTAINERS_SOCKET"`
+	Containers []string `json:"containers" env:"PICOCLAW_TOOLS_CONTAINERS_CONTAINERS"`
+	ReadOnly   bool     `json:"read_only"  env:"PICOCLAW_TOOLS_CONTAINERS_READ_ONLY"`
+}
+
+// SSHToolConfig enables the ssh tool, which runs commands on Hosts through
+// the system's ssh client. Logins use keys only: KeyFile, or ssh's own
+// keys and agent, and each host must be in KnownHostsFile (default
+// ~/.ssh/known_hosts). TimeoutSeconds (default 30) and MaxOutputChars
+// (default 10000) bound each command.
+type SSHToolConfig struct {
+	Enabled        bool            `json:"enabled"          env:"PICOCLAW_TOOLS_SSH_ENABLED"`
+	KeyFile        string          `json:"key_file"         env:"PICOCLAW_TOOLS_SSH_KEY_FILE"`
+	KnownHostsFile string          `json:"known_hosts_file" env:"PICOCLAW_TOOLS_SSH_KNOWN_HOSTS_FILE"`
+	TimeoutSeconds int             `json:"timeout_seconds"  env:"PICOCLAW_TOOLS_SSH_TIMEOUT_SECONDS"`
+	MaxOutputChars int             `json:"max_output_chars" env:"PICOCLAW_TOOLS_SSH_MAX_OUTPUT_CHARS"`
+	Hosts          []SSHHostConfig `json:"hosts"`
+}
+
+// SSHHostConfig is a machine the ssh tool may log in to, known to the
+// agent as Name, at Address (host or host:port) as User. Commands must
+// match one of AllowCommands, regular expressions matching the whole
+// command, when set, and then can't chain or substitute others; and none of
+// DenyCommands, which match anywhere in it, ignoring case.
+// The copy_to_host tool may copy files into TransferDirs, absolute
+// directories on the host; none when empty.
+type SSHHostConfig struct {
+	Name          string   `json:"name"`
+	Address       string   `json:"address"`
+	User          string   `json:"user"`
+	KeyFile       string   `json:"key_file,omitempty"` // Instead of tools.ssh.key_file
+	AllowCommands []string `json:"allow_commands,omitempty"`
+	DenyCommands  []string `json:"deny_commands,omitempty"`
+	TransferDirs  []string `json:"transfer_dirs,omitempty"`
+}
+
+// GitToolConfig enables the git tool on the repositories in or under the
+// directories of Repos (default: the workspace): status, diffs, the log,
+// branches and commits, made as AuthorName <AuthorEmail> when set. Pushing
+// needs AllowPush. Forges are the GitHub and Gitea instances whose API lists
+// the issues and pull requests of repositories whose remote is on them, and
+// opens pull requests. Pushes and pull requests wait for the user's
+// approval, even when tool approval is off.
+type GitToolConfig struct {
+	Enabled        bool             `json:"enabled"         env:"PICOCLAW_TOOLS_GIT_ENABLED"`
+	Repos          []string         `json:"repos"           env:"PICOCLAW_TOOLS_GIT_REPOS"`
+	AuthorName     string           `json:"author_name"     env:"PICOCLAW_TOOLS_GIT_AUTHOR_NAME"`
+	AuthorEmail    string           `json:"author_email"    env:"PICOCLAW_TOOLS_GIT_AUTHOR_EMAIL"`
+	AllowPush      bool             `json:"allow_push"      env:"PICOCLAW_TOOLS_GIT_ALLOW_PUSH"`
+	TimeoutSeconds int              `json:"timeout_seconds" env:"PICOCLAW_TOOLS_GIT_TIMEOUT_SECONDS"`
+	Forges         []GitForgeConfig `json:"forges"`
+}
+
+// GitForgeConfig is a forge of Type "github" or "gitea" (Gitea or
+// Forgejo) at URL, its web address (default https://github.com for
+// GitHub), with an access token allowed to read issues and open pull
+// requests.
+type GitForgeConfig struct {
+	Type  string `json:"type"`
+	URL   string `json:"url,omitempty"`
+	Token string `json:"token"`
+}
+
+// DownloadToolConfig enables the download tool, which saves files from
+// http(s) URLs where the file tools may write, up to MaxSizeMB (default
+// 200) and within TimeoutSeconds (default 600). AllowTypes limits downloads
+// to these media types, as globs such as "application/*"; any when empty.
+type DownloadToolConfig struct {
+	Enabled        bool     `json:"enabled"         env:"PICOCLAW_TOOLS_DOWNLOAD_ENABLED"`
+	MaxSizeMB      int      `json:"max_size_mb"     env:"PICOCLAW_TOOLS_DOWNLOAD_MAX_SIZE_MB"`
+	AllowTypes     []string `json:"allow_types"     env:"PICOCLAW_TOOLS_DOWNLOAD_ALLOW_TYPES"`
+	TimeoutSeconds int      `json:"timeout_seconds" env:"PICOCLAW_TOOLS_DOWNLOAD_TIMEOUT_SECONDS"`
+}
+
+// ArchiveToolConfig enables the archive tool, which lists, extracts and
+// creates zip and tar archives where the file tools may. An extraction
+// writes at most MaxExtractMB (default 1024) in MaxFiles entries (default
+// 10000).
+type ArchiveToolConfig struct {
+	Enabled      bool `json:"enabled"        env:"PICOCLAW_TOOLS_ARCHIVE_ENABLED"`
+	MaxExtractMB int  `json:"max_extract_mb" env:"PICOCLAW_TOOLS_ARCHIVE_MAX_EXTRACT_MB"`
+	MaxFiles     int  `json:"max_files"      env:"PICOCLAW_TOOLS_ARCHIVE_MAX_FILES"`
+}
+
+// DBToolConfig enables the db tool, which queries Databases: SQLite
+// files, PostgreSQL and MySQL servers. A query returns at most MaxRows
+// rows (default 100) and MaxOutputChars characters (default 10000), and
+// runs for at most TimeoutSeconds (default 30).
+type DBToolConfig struct {
+	Enabled        bool             `json:"enabled"          env:"PICOCLAW_TOOLS_DB_ENABLED"`
+	MaxRows        int              `json:"max_rows"         env:"PICOCLAW_TOOLS_DB_MAX_ROWS"`
+	MaxOutputChars int              `json:"max_output_chars" env:"PICOCLAW_TOOLS_DB_MAX_OUTPUT_CHARS"`
+	TimeoutSeconds int              `json:"timeout_seconds"  env:"PICOCLAW_TOOLS_DB_TIMEOUT_SECONDS"`
+	Databases      []DatabaseConfig `json:"databases"`
+}
+
+// DatabaseConfig is a database the db tool may query, known to the agent
+// as Name: a SQLite file at Path, or a PostgreSQL or MySQL server at URL
+// (postgres://user@host/db or mysql://user@host/db). Password, unlike the
+// URL, may be a ${VAR}, file: or keyring: reference, keeping it out of
+// the config file. It is read-only unless AllowWrites is set.
+type DatabaseConfig struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // sqlite, postgres or mysql
+	Path        string `json:"path,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Password    string `json:"password,omitempty"`
+	AllowWrites bool   `json:"allow_writes,omitempty"`
+}
+
+// DesktopToolsConfig enables, on a desktop (macOS, Windows, or Linux with
+// a Wayland or X11 display), the clipboard tool, reading and setting the
+// clipboard, whose reads are cut at MaxClipboardChars, and the notify tool,
+// posting notifications. The clipboard is off unless turned on, since it
+// may hold passwords.
+type DesktopToolsConfig struct {
+	Enabled           bool `json:"enabled"             env:"PICOCLAW_TOOLS_DESKTOP_ENABLED"`
+	Clipboard         bool `json:"clipboard"           env:"PICOCLAW_TOOLS_DESKTOP_CLIPBOARD"`
+	Notifications     bool `json:"notifications"       env:"PICOCLAW_TOOLS_DESKTOP_NOTIFICATIONS"`
+	MaxClipboardChars int  `json:"max_clipboard_chars" env:"PICOCLAW_TOOLS_DESKTOP_MAX_CLIPBOARD_CHARS"`
+}
+
+// SysInfoToolConfig enables the sysinfo tool, which reports the state of
+// the system: CPU, memory, temperatures, network, processes and the space
+// of Disks (default /). The gateway checks Alerts every CheckSeconds,
+// whether the tool is enabled or not, and notifies Channel and ChatID, or
+// the chat used last, when one starts or stops being broken.
+type SysInfoToolConfig struct {
+	Enabled      bool             `json:"enabled"           env:"PICOCLAW_TOOLS_SYSINFO_ENABLED"`
+	Disks        []string         `json:"disks"             env:"PICOCLAW_TOOLS_SYSINFO_DISKS"`
+	CheckSeconds int              `json:"check_seconds"     env:"PICOCLAW_TOOLS_SYSINFO_CHECK_SECONDS"`
+	Channel      string           `json:"channel,omitempty" env:"PICOCLAW_TOOLS_SYSINFO_CHANNEL"`
+	ChatID       string           `json:"chat_id,omitempty" env:"PICOCLAW_TOOLS_SYSINFO_CHAT_ID"`
+	Alerts       []SysAlertConfig `json:"alerts"`
+}
+
+// SysAlertConfig is an alert rule: Metric (cpu, memory or disk, in percent
+// used; temperature, in °C; or load, the 1-minute load average) staying
+// above Above, or below Below, for ForMinutes. Path is the disk of disk
+// rules (default /) and Zone the thermal zone of temperature rules
+// (default: the hottest).
+type SysAlertConfig struct {
+	Metric     string  `json:"metric"`
+	Above      float64 `json:"above,omitempty"`
+	Below      float64 `json:"below,omitempty"`
+	ForMinutes int     `json:"for_minutes,omitempty"`
+	Path       string  `json:"path,omitempty"`
+	Zone       string  `json:"zone,omitempty"`
+}
+
+// MQTTToolConfig enables the mqtt tool, to publish messages to devices and
+// read what they publish, on Broker (tcp://host:1883 or tls://host:8883).
+// The tool only uses the topics Topics matches, as MQTT filters such as
+// "home/+/set"; none when empty. Over TLS, CAFile trusts a private CA and
+// CertFile and KeyFile log in with a client certificate (PEM files). mqtt
+// triggers without a broker use this one.
+type MQTTToolConfig struct {
+	Enabled  bool     `json:"enabled"   env:"PICOCLAW_TOOLS_MQTT_ENABLED"`
+	Broker   string   `json:"broker"    env:"PICOCLAW_TOOLS_MQTT_BROKER"`
+	Username string   `json:"username"  env:"PICOCLAW_TOOLS_MQTT_USERNAME"`
+	Password string   `json:"password"  env:"PICOCLAW_TOOLS_MQTT_PASSWORD"`
+	CAFile   string   `json:"ca_file"   env:"PICOCLAW_TOOLS_MQTT_CA_FILE"`
+	CertFile string   `json:"cert_file" env:"PICOCLAW_TOOLS_MQTT_CERT_FILE"`
+	KeyFile  string   `json:"key_file"  env:"PICOCLAW_TOOLS_MQTT_KEY_FILE"`
+	Topics   []string `json:"topics"    env:"PICOCLAW_TOOLS_MQTT_TOPICS"`
+}
+
+// HomeAssistantToolConfig enables the homeassistant tool on the instance at
+// URL, with a long-lived access token created in the user's Home Assistant
+// profile. The tool, and homeassistant triggers, only see and control the
+// entities Entities matches, as globs such as "light.*"; none when empty.
+// ReadOnly removes calling services.
+type HomeAssistantToolConfig struct {
+	Enabled  bool     `json:"enabled"   env:"PICOCLAW_TOOLS_HOMEASSISTANT_ENABLED"`
+	URL      string   `json:"url"       env:"PICOCLAW_TOOLS_HOMEASSISTANT_URL"`
+	Token    string   `json:"token"     env:"PICOCLAW_TOOLS_HOMEASSISTANT_TOKEN"`
+	Entities []string `json:"entities"  env:"PICOCLAW_TOOLS_HOMEASSISTANT_ENTITIES"`
+	ReadOnly bool     `json:"read_only" env:"PICOCLAW_TOOLS_HOMEASSISTANT_READ_ONLY"`
+}
+
+// ExternalToolsConfig enables tools provided by executables in Dir, in any
+// language, speaking the protocol described in pkg/tools/external.go. .wasm
+// modules there are run sandboxed inside picoclaw, with at most
+// WASMMaxMemoryMB megabytes of memory. Each call is stopped after
+// TimeoutSeconds.
+type ExternalToolsConfig struct {
+	Enabled         bool   `json:"enabled"            env:"PICOCLAW_TOOLS_EXTERNAL_ENABLED"`
+	Dir             string `json:"dir"                env:"PICOCLAW_TOOLS_EXTERNAL_DIR"`
+	WASMMaxMemoryMB int    `json:"wasm_max_memory_mb" env:"PICOCLAW_TOOLS_EXTERNAL_WASM_MAX_MEMORY_MB"`
+	TimeoutSeconds  int    `json:"timeout_seconds"    env:"PICOCLAW_TOOLS_EXTERNAL_TIMEOUT_SECONDS"`
+}
+
+// HTTPToolConfig enables the http_request tool, a generic REST client for
+// AllowDomains (a domain allows its subdomains too). SecretHeaders maps a
+// domain to headers added to every request to it, such as an API token,
+// which the model never sees. Responses over MaxResponseKB are cut.
+type HTTPToolConfig struct {
+	Enabled        bool                         `json:"enabled"                  env:"PICOCLAW_TOOLS_HTTP_ENABLED"`
+	AllowDomains   []string                     `json:"allow_domains,omitempty"  env:"PICOCLAW_TOOLS_HTTP_ALLOW_DOMAINS"`
+	SecretHeaders  map[string]map[string]string `json:"secret_headers,omitempty"`
+	MaxResponseKB  int                          `json:"max_response_kb"          env:"PICOCLAW_TOOLS_HTTP_MAX_RESPONSE_KB"`
+	TimeoutSeconds int                          `json:"timeout_seconds"          env:"PICOCLAW_TOOLS_HTTP_TIMEOUT_SECONDS"`
+}
+
+// MCPConfig lists the MCP servers whose tools the agent may use, by name,
+// and what `picoclaw mcp serve` offers to MCP clients.
+type MCPConfig struct {
+	Servers map[string]MCPServerConfig `json:"servers,omitempty"`
+	Serve   MCPServeConfig             `json:"serve"`
+}
+
+// MCPServeConfig limits the tools `picoclaw mcp serve` offers to those
+// named in Tools; without it, all of them are offered.
+type MCPServeConfig struct {
+	Tools []string `json:"tools,omitempty" env:"PICOCLAW_TOOLS_MCP_SERVE_TOOLS"`
+}
+
+// MCPServerConfig is one MCP server: either a Command to run, talking
+// over its stdin and stdout, or the URL of its SSE endpoint, sent Headers
+// with each request. If Tools is set, only the server's tools named there
+// are offered to the agent. TimeoutSeconds limits connecting and each tool
+// call (default 60).
+type MCPServerConfig struct {
+	Enabled        bool              `json:"enabled"`
+	Command        string            `json:"command,omitempty"`
+	Args           []string          `json:"args,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	Dir            string            `json:"dir,omitempty"`
+	URL            string            `json:"url,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Tools          []string          `json:"tools,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+}
+
+// DelegateToolsConfig limits the delegate tool, which hands subtasks to
+// subagents.
+type DelegateToolsConfig struct {
+	Enabled       bool `json:"enabled"        env:"PICOCLAW_TOOLS_DELEGATE_ENABLED"`
+	MaxDepth      int  `json:"max_depth"      env:"PICOCLAW_TOOLS_DELEGATE_MAX_DEPTH"`
+	MaxIterations int  `json:"max_iterations" env:"PICOCLAW_TOOLS_DELEGATE_MAX_ITERATIONS"`
+	TokenBudget   int  `json:"token_budget"   env:"PICOCLAW_TOOLS_DELEGATE_TOKEN_BUDGET"` // Per subagent; 0 means no limit
+}
+
+// ApprovalConfig has the user approve risky tool calls before they run.
+// Each call is checked against Rules in order and the first match decides;
+// calls no rule matches run. Without rules, every exec call, and git
+// pushes and pull requests, are asked about. The question goes to the chat
+// the request came from (a y/n prompt in the CLI); one not answered within
+// TimeoutSeconds (default 120) is decided by Default, "deny" (the default)
+// or "allow".
+type ApprovalConfig struct {
+	Enabled        bool           `json:"enabled"                   env:"PICOCLAW_TOOLS_APPROVAL_ENABLED"`
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty" env:"PICOCLAW_TOOLS_APPROVAL_TIMEOUT_SECONDS"`
+	Default        string         `json:"default,omitempty"         env:"PICOCLAW_TOOLS_APPROVAL_DEFAULT"`
+	Rules          []ApprovalRule `json:"rules,omitempty"`
+}
+
+// ApprovalRule matches tool calls by tool name ("*" for any tool) and, if
+// set, by a regular expression Pattern found in one of the call's string
+// arguments, or in argument Arg only. Outside matches calls whose path
+// argument (Arg, or else path or working_dir) is outside all of these
+// directories; a relative path, or none, is taken in the agent's
+// workspace. Users, Channels and Agents limit the rule to calls for those
+// users (configured names or sender profile keys), from those channels and
+// by those agents. Action is "ask", "allow" or "deny".
+type ApprovalRule struct {
+	Tool     string   `json:"tool"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Arg      string   `json:"arg,omitempty"`
+	Outside  []string `json:"outside,omitempty"`
+	Users    []string `json:"users,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+	Agents   []string `json:"agents,omitempty"`
+	Action   string   `json:"action"`
 }
 
 type ToolsConfig struct {
-	Web    WebToolsConfig    `json:"web"`
-	Cron   CronToolsConfig   `json:"cron"`
-	Exec   ExecConfig        `json:"exec"`
-	Skills SkillsToolsConfig `json:"skills"`
+	Web      WebToolsConfig      `json:"web"`
+	Cron     CronToolsConfig     `json:"cron"`
+	Exec     ExecConfig          `json:"exec"`
+	Files    FileToolsConfig     `json:"files"`
+	Browser  BrowserToolConfig   `json:"browser"`
+	HTTP     HTTPToolConfig      `json:"http"`
+	Code     CodeToolConfig      `json:"code"`
+	External ExternalToolsConfig `json:"external"`
+	Calendar CalendarToolConfig  `json:"calendar"`
+	Email    EmailToolConfig     `json:"email"`
+	Feeds    FeedsToolConfig     `json:"feeds"`
+	Notes    NotesToolConfig     `json:"notes"`
+	Tasks    TasksToolConfig     `json:"tasks"`
+
+	HomeAssistant HomeAssistantToolConfig `json:"homeassistant"`
+	MQTT          MQTTToolConfig          `json:"mqtt"`
+	Hardware      HardwareToolsConfig     `json:"hardware"`
+	Camera        CameraToolConfig        `json:"camera"`
+	SysInfo       SysInfoToolConfig       `json:"sysinfo"`
+	Containers    ContainersToolConfig    `json:"containers"`
+	SSH           SSHToolConfig           `json:"ssh"`
+	Git           GitToolConfig           `json:"git"`
+	Download      DownloadToolConfig      `json:"download"`
+	Archive       ArchiveToolConfig       `json:"archive"`
+	DB            DBToolConfig            `json:"db"`
+	Desktop       DesktopToolsConfig      `json:"desktop"`
+	MCP           MCPConfig               `json:"mcp"`
+	Skills        SkillsToolsConfig       `json:"skills"`
+	Delegate      DelegateToolsConfig     `json:"delegate"`
+	Approval      ApprovalConfig          `json:"approval"`
 }
 
 type SkillsToolsConfig struct {
@@ -513,6 +1528,14 @@ func LoadConfig(path string) (*Config, error) {
 		}
 		return nil, err
 	}
+	profile := profileName()
+	data, profiles, err := applyProfile(data, profile)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if data, err = expandReferences(data); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
 
 	// Pre-scan the JSON to check how many model_list entries the user provided.
 	// Go's JSON decoder reuses existing slice backing-array elements rather than
@@ -535,6 +1558,8 @@ func LoadConfig(path string) (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
+	cfg.Profiles = profiles
+	cfg.Profile = profile
 
 	// Auto-migrate: if only legacy providers config exists, convert to model_list
 	if len(cfg.ModelList) == 0 && cfg.HasProvidersConfig() {
@@ -636,13 +1661,93 @@ func (c *Config) GetModelConfig(modelName string) (*ModelConfig, error) {
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("model %q not found in model_list or providers", modelName)
 	}
-	if len(matches) == 1 {
-		return &matches[0], nil
+	selected := &matches[0]
+	if len(matches) > 1 {
+		// Multiple configs - use round-robin for load balancing
+		idx := rrCounter.Add(1) % uint64(len(matches))
+		selected = &matches[idx]
+	}
+
+	selected.FallbackConfigs = c.resolveFallbacks(selected)
+	c.applyProviderAlias(selected)
+	for _, fb := range selected.FallbackConfigs {
+		c.applyProviderAlias(fb)
 	}
+	return selected, nil
+}
 
-	// Multiple configs - use round-robin for load balancing
-	idx := rrCounter.Add(1) % uint64(len(matches))
-	return &matches[idx], nil
+// applyProviderAlias rewrites a model whose protocol prefix names one of
+// ProviderAliases to the alias's protocol, filling in the endpoint settings
+// mc leaves empty.
+func (c *Config) applyProviderAlias(mc *ModelConfig) {
+	prefix, modelID, found := strings.Cut(strings.TrimSpace(mc.Model), "/")
+	if !found {
+		return
+	}
+	alias, ok := c.ProviderAliases[prefix]
+	if !ok {
+		return
+	}
+
+	protocol := alias.Protocol
+	if protocol == "" {
+		protocol = "openai"
+	}
+	mc.Model = protocol + "/" + modelID
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&mc.APIBase, alias.APIBase)
+	fill(&mc.APIKey, alias.APIKey)
+	fill(&mc.Proxy, alias.Proxy)
+	fill(&mc.TLSCert, alias.TLSCert)
+	fill(&mc.TLSKey, alias.TLSKey)
+	fill(&mc.TLSCA, alias.TLSCA)
+
+	if len(alias.ExtraHeaders) > 0 {
+		headers := make(map[string]string, len(alias.ExtraHeaders)+len(mc.ExtraHeaders))
+		for k, v := range alias.ExtraHeaders {
+			headers[k] = v
+		}
+		for k, v := range mc.ExtraHeaders {
+			headers[k] = v
+		}
+		mc.ExtraHeaders = headers
+	}
+}
+
+// resolveFallbacks resolves the Fallbacks of mc into model configs. A fallback
+// naming a model_list entry uses that entry; anything else is treated as a
+// protocol/model reference and inherits mc's credentials when the protocol
+// matches. Fallbacks are not resolved recursively.
+func (c *Config) resolveFallbacks(mc *ModelConfig) []*ModelConfig {
+	if len(mc.Fallbacks) == 0 {
+		return nil
+	}
+
+	primaryProtocol, _, _ := strings.Cut(mc.Model, "/")
+	resolved := make([]*ModelConfig, 0, len(mc.Fallbacks))
+	for _, name := range mc.Fallbacks {
+		var fb ModelConfig
+		if matches := c.findMatches(name); len(matches) > 0 {
+			fb = matches[0]
+		} else {
+			fb = *mc
+			fb.ModelName = name
+			fb.Model = name
+			if protocol, _, _ := strings.Cut(name, "/"); protocol != primaryProtocol {
+				fb.APIKey = ""
+				fb.APIBase = ""
+				fb.MaxTokensField = ""
+			}
+		}
+		fb.Fallbacks = nil
+		fb.FallbackConfigs = nil
+		resolved = append(resolved, &fb)
+	}
+	return resolved
 }
 
 // findMatches finds all ModelConfig entries with the given model_name.
@@ -422,3 +422,65 @@ func TestDefaultConfig_DMScope(t *testing.T) {
 		t.Errorf("Session.DMScope = %q, want 'per-channel-peer'", cfg.Session.DMScope)
 	}
 }
+
+func TestAgentDefaults_ModelForRole(t *testing.T) {
+	d := AgentDefaults{
+		ModelRoles: map[string]string{"chat": "claude", "cheap": "qwen-local"},
+		ImageModel: "gpt-4o",
+	}
+
+	tests := map[string]string{
+		"chat":      "",
+		"cheap":     "qwen-local",
+		"summarize": "qwen-local",
+		"vision":    "gpt-4o",
+		"embed":     "",
+		"":          "",
+	}
+	for role, want := range tests {
+		if got := d.ModelForRole(role); got != want {
+			t.Errorf("ModelForRole(%q) = %q, want %q", role, got, want)
+		}
+	}
+
+	if got := d.GetModelName(); got != "claude" {
+		t.Errorf("GetModelName() = %q, want the chat role", got)
+	}
+	d.ModelRoles["summarize"] = "haiku"
+	if got := d.ModelForRole("summarize"); got != "haiku" {
+		t.Errorf("ModelForRole(summarize) = %q, want haiku", got)
+	}
+}
+
+func TestModelConfig_APIKeyList(t *testing.T) {
+	var cfg ModelConfig
+	if err := json.Unmarshal([]byte(`{"model":"gemini/x","api_key":["k1"," k2 ",""]}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.APIKey != "k1" || len(cfg.APIKeys) != 2 || cfg.APIKeys[1] != "k2" {
+		t.Fatalf("APIKey = %q, APIKeys = %v", cfg.APIKey, cfg.APIKeys)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var back ModelConfig
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", data, err)
+	}
+	if back.APIKey != "k1" || len(back.APIKeys) != 2 {
+		t.Errorf("round trip: APIKey = %q, APIKeys = %v", back.APIKey, back.APIKeys)
+	}
+
+	var single ModelConfig
+	if err := json.Unmarshal([]byte(`{"model":"openai/x","api_key":"sk"}`), &single); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if single.APIKey != "sk" || single.APIKeys != nil {
+		t.Errorf("APIKey = %q, APIKeys = %v", single.APIKey, single.APIKeys)
+	}
+	if err := json.Unmarshal([]byte(`{"api_key":42}`), &single); err == nil {
+		t.Error("expected error for a numeric api_key")
+	}
+}
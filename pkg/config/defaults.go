@@ -113,6 +113,13 @@ func DefaultConfig() *Config {
 				AllowFrom:      FlexibleStringSlice{},
 				ReplyTimeout:   5,
 			},
+			Email: EmailConfig{
+				IMAPPort:    993,
+				SMTPPort:    587,
+				Folders:     []string{"INBOX"},
+				PollSeconds: 60,
+				AllowFrom:   FlexibleStringSlice{},
+			},
 		},
 		Providers: ProvidersConfig{
 			OpenAI: OpenAIProviderConfig{WebSearch: true},
@@ -154,6 +161,14 @@ func DefaultConfig() *Config {
 				APIKey:    "",
 			},
 
+			// xAI Grok - https://console.x.ai/
+			{
+				ModelName: "grok-4",
+				Model:     "xai/grok-4",
+				APIBase:   "https://api.x.ai/v1",
+				APIKey:    "",
+			},
+
 			// Google Gemini - https://ai.google.dev/
 			{
 				ModelName: "gemini-2.0-flash",
@@ -239,11 +254,10 @@ func DefaultConfig() *Config {
 				AuthMethod: "oauth",
 			},
 
-			// GitHub Copilot - https://github.com/settings/tokens
+			// GitHub Copilot - log in with: picoclaw auth github-copilot
 			{
 				ModelName:  "copilot-gpt-5.2",
 				Model:      "github-copilot/gpt-5.2",
-				APIBase:    "http://localhost:4321",
 				AuthMethod: "oauth",
 			},
 
@@ -279,19 +293,30 @@ func DefaultConfig() *Config {
 			Web: WebToolsConfig{
 				Proxy: "",
 				Brave: BraveConfig{
-					Enabled:    false,
-					APIKey:     "",
-					MaxResults: 5,
+					Enabled:           false,
+					APIKey:            "",
+					MaxResults:        5,
+					RequestsPerMinute: 60,
 				},
 				DuckDuckGo: DuckDuckGoConfig{
-					Enabled:    true,
-					MaxResults: 5,
+					Enabled:           true,
+					MaxResults:        5,
+					RequestsPerMinute: 10,
 				},
 				Perplexity: PerplexityConfig{
 					Enabled:    false,
 					APIKey:     "",
 					MaxResults: 5,
 				},
+				SearXNG: SearXNGConfig{
+					MaxResults: 5,
+				},
+				SearchCacheMinutes: 60,
+				Fetch: FetchURLConfig{
+					TimeoutSeconds: 30,
+					MaxSizeKB:      2048,
+					PageChars:      8000,
+				},
 			},
 			Cron: CronToolsConfig{
 				ExecTimeoutMinutes: 5,
@@ -299,6 +324,91 @@ func DefaultConfig() *Config {
 			Exec: ExecConfig{
 				EnableDenyPatterns: true,
 			},
+			Files: FileToolsConfig{
+				MaxFileSizeKB: 1024,
+				Backups:       3,
+			},
+			Browser: BrowserToolConfig{
+				TimeoutSeconds: 30,
+			},
+			HTTP: HTTPToolConfig{
+				MaxResponseKB:  32,
+				TimeoutSeconds: 30,
+			},
+			Code: CodeToolConfig{
+				TimeoutSeconds: 60,
+				CPUSeconds:     30,
+				MemoryMB:       512,
+				MaxFileMB:      20,
+			},
+			Email: EmailToolConfig{
+				IMAPPort: 993,
+				SMTPPort: 587,
+			},
+			Feeds: FeedsToolConfig{
+				PollMinutes:      30,
+				MaxDigestItems:   20,
+				SummarizeDigests: true,
+				MinScore:         0.5,
+			},
+			Notes: NotesToolConfig{
+				Enabled: true,
+				Dir:     "notes",
+			},
+			Tasks: TasksToolConfig{
+				Enabled:  true,
+				NagHours: 4,
+			},
+			Hardware: HardwareToolsConfig{
+				SerialBaud: 115200,
+			},
+			Camera: CameraToolConfig{
+				Device: "/dev/video0",
+				Width:  1280,
+				Height: 720,
+			},
+			SysInfo: SysInfoToolConfig{
+				Enabled:      true,
+				CheckSeconds: 60,
+			},
+			SSH: SSHToolConfig{
+				TimeoutSeconds: 30,
+				MaxOutputChars: 10000,
+			},
+			Git: GitToolConfig{
+				TimeoutSeconds: 60,
+			},
+			Download: DownloadToolConfig{
+				Enabled:        true,
+				MaxSizeMB:      200,
+				TimeoutSeconds: 600,
+			},
+			Archive: ArchiveToolConfig{
+				Enabled:      true,
+				MaxExtractMB: 1024,
+				MaxFiles:     10000,
+			},
+			DB: DBToolConfig{
+				MaxRows:        100,
+				MaxOutputChars: 10000,
+				TimeoutSeconds: 30,
+			},
+			Desktop: DesktopToolsConfig{
+				Enabled:           true,
+				Notifications:     true,
+				MaxClipboardChars: 10000,
+			},
+			External: ExternalToolsConfig{
+				Dir:             "~/.picoclaw/tools.d",
+				WASMMaxMemoryMB: 128,
+				TimeoutSeconds:  60,
+			},
+			Delegate: DelegateToolsConfig{
+				Enabled:       true,
+				MaxDepth:      1,
+				MaxIterations: 15,
+				TokenBudget:   100000,
+			},
 			Skills: SkillsToolsConfig{
 				Registries: SkillsRegistriesConfig{
 					ClawHub: ClawHubRegistryConfig{
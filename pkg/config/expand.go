@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envRef matches ${NAME} references to environment variables.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandReferences resolves references in the credential and proxy values
+// of a config file, so secrets can live outside it:
+//
+//	"api_key": "${OPENAI_API_KEY}"                 environment variable
+//	"token":   "file:/run/secrets/telegram"        file contents, trailing newline removed
+//	"api_key": "keyring:picoclaw/openai"           OS keyring
+//	"api_key": "vault:secret/data/picoclaw#openai" HashiCorp Vault
+//	"api_key": "sops:secrets.enc.json#openai"      SOPS-encrypted file
+//
+// RegisterSecretResolver adds schemes.
+//
+// References may be combined ("file:${CREDENTIALS_DIRECTORY}/key") and
+// embedded ("Bearer ${TOKEN}"). Other values are left alone.
+func expandReferences(data []byte) ([]byte, error) {
+	var doc any
+	if err := decodeNumbers(data, &doc); err != nil {
+		return nil, err
+	}
+	changed, err := expandValue(doc, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(doc)
+}
+
+// expandValue resolves references under v, at path in the document. secret
+// is set for values of credential fields. It reports whether anything
+// changed.
+func expandValue(v any, path string, secret bool) (bool, error) {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := joinPath(path, key)
+			if s, ok := child.(string); ok {
+				if !secret && !secretField(key) {
+					continue
+				}
+				expanded, err := expandString(s)
+				if err != nil {
+					return false, fmt.Errorf("%s: %w", childPath, err)
+				}
+				if expanded != s {
+					v[key] = expanded
+					changed = true
+				}
+				continue
+			}
+			// Every header may carry a credential.
+			c, err := expandValue(child, childPath, secret || secretField(key) || strings.HasSuffix(key, "headers"))
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	case []any:
+		for i, child := range v {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			if s, ok := child.(string); ok && secret {
+				expanded, err := expandString(s)
+				if err != nil {
+					return false, fmt.Errorf("%s: %w", childPath, err)
+				}
+				if expanded != s {
+					v[i] = expanded
+					changed = true
+				}
+				continue
+			}
+			c, err := expandValue(child, childPath, false)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	}
+	return changed, nil
+}
+
+// secretField reports whether values of the field with this JSON name may
+// hold references.
+func secretField(name string) bool {
+	switch name {
+	case "api_key", "proxy", "encrypt_key", "encoding_aes_key", "encryption_key":
+		return true
+	}
+	return strings.HasSuffix(name, "token") ||
+		strings.HasSuffix(name, "secret") ||
+		strings.HasSuffix(name, "password")
+}
+
+// expandString substitutes environment variables in s, then resolves it if
+// it names a secret, as in "file:..." or "vault:...".
+func expandString(s string) (string, error) {
+	var missing string
+	s = envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+
+	return resolveSecret(s)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_ExpandsReferences(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "telegram")
+	if err := os.WriteFile(tokenFile, []byte("123:abc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_OPENAI_KEY", "sk-env")
+	t.Setenv("TEST_SECRETS", dir)
+	t.Setenv("TEST_GATEWAY_TOKEN", "gw")
+
+	path := filepath.Join(dir, "config.json")
+	data := `{
+		"channels": {"telegram": {"token": "file:${TEST_SECRETS}/telegram", "allow_from": [1234567890]}},
+		"model_list": [{
+			"model_name": "gpt", "model": "openai/gpt-4o",
+			"api_key": ["${TEST_OPENAI_KEY}", "sk-literal"],
+			"extra_headers": {"Authorization": "Bearer ${TEST_GATEWAY_TOKEN}"}
+		}],
+		"agents": {"defaults": {"workspace": "${NOT_EXPANDED}"}},
+		"tools": {"http": {"secret_headers": {"api.github.com": {"Authorization": "Bearer ${TEST_GATEWAY_TOKEN}"}}}}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.Channels.Telegram.Token; got != "123:abc" {
+		t.Errorf("telegram token = %q", got)
+	}
+	if got := cfg.Channels.Telegram.AllowFrom; len(got) != 1 || got[0] != "1234567890" {
+		t.Errorf("allow_from = %v", got)
+	}
+	mc := cfg.ModelList[0]
+	if mc.APIKey != "sk-env" || len(mc.APIKeys) != 2 || mc.APIKeys[1] != "sk-literal" {
+		t.Errorf("api_key = %q, %v", mc.APIKey, mc.APIKeys)
+	}
+	if got := mc.ExtraHeaders["Authorization"]; got != "Bearer gw" {
+		t.Errorf("Authorization header = %q", got)
+	}
+	if got := cfg.Tools.HTTP.SecretHeaders["api.github.com"]["Authorization"]; got != "Bearer gw" {
+		t.Errorf("secret header = %q", got)
+	}
+	if got := cfg.Agents.Defaults.Workspace; got != "${NOT_EXPANDED}" {
+		t.Errorf("workspace = %q, only credential fields are expanded", got)
+	}
+}
+
+func TestLoadConfig_UnresolvedReference(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"tools": {"web": {"brave": {"api_key": "${PICOCLAW_TEST_UNSET_VARIABLE}"}}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "tools.web.brave.api_key") ||
+		!strings.Contains(err.Error(), "PICOCLAW_TEST_UNSET_VARIABLE") {
+		t.Errorf("LoadConfig() error = %v, want the field and variable named", err)
+	}
+}
@@ -0,0 +1,1249 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Issue is a problem found in a config, with how to fix it.
+type Issue struct {
+	Field   string // JSON path of the value, e.g. "model_list[1].api_key"
+	Problem string
+	Fix     string
+	Warning bool // PicoClaw still works, but probably not as intended
+}
+
+func (i Issue) String() string {
+	if i.Field == "" {
+		return i.Problem
+	}
+	return i.Field + ": " + i.Problem
+}
+
+// LintJSON checks the raw config file: that it parses, and that every key
+// is one PicoClaw reads. Unknown keys are otherwise ignored silently, so a
+// typo quietly leaves a setting at its default.
+func LintJSON(data []byte) []Issue {
+	var doc any
+	if err := decodeNumbers(data, &doc); err != nil {
+		return []Issue{syntaxIssue(data, err)}
+	}
+
+	var issues []Issue
+	lintKeys(doc, reflect.TypeOf(Config{}), "", &issues)
+	// Profiles hold parts of a config.
+	if obj, ok := doc.(map[string]any); ok {
+		if profiles, ok := obj["profiles"].(map[string]any); ok {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				lintKeys(profiles[name], reflect.TypeOf(Config{}), "profiles."+name, &issues)
+			}
+		}
+	}
+	return issues
+}
+
+// syntaxIssue reports a parse error at its line and column.
+func syntaxIssue(data []byte, err error) Issue {
+	issue := Issue{
+		Problem: "invalid JSON: " + err.Error(),
+		Fix:     "fix the syntax; trailing commas and comments are not allowed",
+	}
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	offset := int64(-1)
+	if errors.As(err, &se) {
+		offset = se.Offset
+	} else if errors.As(err, &te) {
+		offset = te.Offset
+	}
+	// Offsets count the byte the parser stopped at.
+	if offset > 0 {
+		before := data[:min(int(offset)-1, len(data))]
+		line := bytes.Count(before, []byte("\n")) + 1
+		col := len(before) - bytes.LastIndexByte(before, '\n')
+		issue.Field = fmt.Sprintf("line %d, column %d", line, col)
+	}
+	return issue
+}
+
+// lintKeys reports keys of v that t has no field for.
+func lintKeys(v any, t reflect.Type, path string, issues *[]Issue) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			// Types like AgentModelConfig also accept a string.
+			return
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ft, ok := fields[key]
+			if !ok {
+				issue := Issue{
+					Field:   joinPath(path, key),
+					Problem: "unknown key, ignored",
+					Fix:     "remove it",
+					Warning: true,
+				}
+				if s := closestKey(key, fields); s != "" {
+					issue.Fix = fmt.Sprintf("did you mean %q?", s)
+				}
+				*issues = append(*issues, issue)
+				continue
+			}
+			lintKeys(obj[key], ft, joinPath(path, key), issues)
+		}
+	case reflect.Map:
+		if obj, ok := v.(map[string]any); ok {
+			for key, child := range obj {
+				lintKeys(child, t.Elem(), joinPath(path, key), issues)
+			}
+		}
+	case reflect.Slice:
+		if arr, ok := v.([]any); ok {
+			for i, child := range arr {
+				lintKeys(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), issues)
+			}
+		}
+	}
+}
+
+// jsonFields maps the JSON names of t's fields to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// closestKey returns the known key nearest to key, if one is close enough
+// to be a typo.
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDist := "", 3
+	for name := range fields {
+		d := editDistance(strings.ToLower(key), name)
+		if d < bestDist || d == bestDist && name < best {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+var reUserName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Lint checks that the models the config refers to exist and that enabled
+// channels have what they need to connect.
+func (c *Config) Lint() []Issue {
+	var issues []Issue
+
+	known := make(map[string]bool)
+	for _, mc := range c.ModelList {
+		known[mc.ModelName] = true
+	}
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	available := "model_list has no entries; add one"
+	if len(names) > 0 {
+		available = "use one of: " + strings.Join(names, ", ")
+	}
+
+	checkModel := func(field, name string) {
+		if name == "" || known[name] {
+			return
+		}
+		// protocol/model references in fallbacks reuse the entry's credentials.
+		if strings.Contains(field, "fallbacks") && strings.Contains(name, "/") {
+			return
+		}
+		issues = append(issues, Issue{
+			Field:   field,
+			Problem: fmt.Sprintf("model %q is not in model_list", name),
+			Fix:     available,
+		})
+	}
+
+	d := c.Agents.Defaults
+	if d.GetModelName() == "" {
+		issues = append(issues, Issue{
+			Field:   "agents.defaults.model",
+			Problem: "no default model",
+			Fix:     "set it to a model_name from model_list",
+		})
+	}
+	checkModel("agents.defaults.model", d.GetModelName())
+	for i, fb := range d.ModelFallbacks {
+		checkModel(fmt.Sprintf("agents.defaults.model_fallbacks[%d]", i), fb)
+	}
+	roles := make([]string, 0, len(d.ModelRoles))
+	for role := range d.ModelRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		checkModel("agents.defaults.model_roles."+role, d.ModelRoles[role])
+	}
+	checkModel("agents.defaults.budget.model", d.Budget.Model)
+	checkModel("agents.defaults.review.model", d.Review.Model)
+	switch d.Planning.Mode {
+	case "", "off", "auto", "always":
+	default:
+		issues = append(issues, Issue{
+			Field:   "agents.defaults.planning.mode",
+			Problem: fmt.Sprintf("unknown planning mode %q", d.Planning.Mode),
+			Fix:     `use "off", "auto" or "always"`,
+		})
+	}
+	switch d.OnNewMessage {
+	case "", "queue", "steer", "restart":
+	default:
+		issues = append(issues, Issue{
+			Field:   "agents.defaults.on_new_message",
+			Problem: fmt.Sprintf("unknown on_new_message behavior %q", d.OnNewMessage),
+			Fix:     `use "queue", "steer" or "restart"`,
+		})
+	}
+	if d.Memory.Enabled && d.ModelForRole("embed") == "" {
+		issues = append(issues, Issue{
+			Field:   "agents.defaults.memory.enabled",
+			Problem: "long-term memory needs an embedding model",
+			Fix:     `assign a model_list entry to the "embed" role in agents.defaults.model_roles`,
+		})
+	}
+	switch d.Memory.Consolidation {
+	case "", "off", "daily", "weekly":
+	default:
+		issues = append(issues, Issue{
+			Field:   "agents.defaults.memory.consolidation",
+			Problem: fmt.Sprintf("unknown consolidation schedule %q", d.Memory.Consolidation),
+			Fix:     `use "daily", "weekly" or "off"`,
+		})
+	}
+
+	for i, ac := range c.Agents.List {
+		if ac.Review != nil {
+			checkModel(fmt.Sprintf("agents.list[%d].review.model", i), ac.Review.Model)
+		}
+		if ac.Model == nil {
+			continue
+		}
+		checkModel(fmt.Sprintf("agents.list[%d].model", i), ac.Model.Primary)
+		for j, fb := range ac.Model.Fallbacks {
+			checkModel(fmt.Sprintf("agents.list[%d].model.fallbacks[%d]", i, j), fb)
+		}
+	}
+
+	for i, mc := range c.ModelList {
+		for j, fb := range mc.Fallbacks {
+			checkModel(fmt.Sprintf("model_list[%d].fallbacks[%d]", i, j), fb)
+		}
+	}
+
+	tg := c.Channels.Telegram
+	if tg.Enabled && tg.Token == "" {
+		issues = append(issues, Issue{
+			Field:   "channels.telegram.token",
+			Problem: "Telegram is enabled without a bot token",
+			Fix:     "create a bot with @BotFather and set its token",
+		})
+	}
+	wa := c.Channels.WhatsApp
+	if wa.Enabled && wa.BridgeURL == "" {
+		issues = append(issues, Issue{
+			Field:   "channels.whatsapp.bridge_url",
+			Problem: "WhatsApp is enabled without a bridge URL",
+			Fix:     "set it to the WhatsApp bridge's websocket URL, e.g. ws://localhost:3001",
+		})
+	}
+	if em := c.Channels.Email; em.Enabled {
+		if em.Address == "" || em.IMAPHost == "" || em.SMTPHost == "" {
+			issues = append(issues, Issue{
+				Field:   "channels.email",
+				Problem: "the email channel needs an address, an imap_host and an smtp_host",
+				Fix:     "set them from your mail provider's settings for mail apps",
+			})
+		}
+		if len(em.AllowFrom) == 0 {
+			issues = append(issues, Issue{
+				Field:   "channels.email.allow_from",
+				Problem: "anyone who emails the agent's address is answered, spam included",
+				Fix:     "list the addresses the agent serves",
+			})
+		}
+		if em.PollSeconds < 0 {
+			issues = append(issues, Issue{
+				Field:   "channels.email.poll_seconds",
+				Problem: "poll_seconds can't be negative",
+				Fix:     "use 0 for the default",
+			})
+		}
+	}
+
+	userNames := make([]string, 0, len(c.Users))
+	for name := range c.Users {
+		userNames = append(userNames, name)
+	}
+	sort.Strings(userNames)
+	owner := make(map[string]string)
+	for _, name := range userNames {
+		field := "users." + name
+		if !reUserName.MatchString(name) {
+			issues = append(issues, Issue{
+				Field:   field,
+				Problem: fmt.Sprintf("invalid user name %q", name),
+				Fix:     "use letters, digits, - and _ only",
+			})
+		}
+		u := c.Users[name]
+		if len(u.IDs) == 0 {
+			issues = append(issues, Issue{
+				Field:   field + ".ids",
+				Problem: "the user has no sender IDs, so no message is theirs",
+				Fix:     `add IDs like "telegram:123456789"`,
+			})
+		}
+		for i, id := range u.IDs {
+			id = strings.ToLower(strings.TrimSpace(id))
+			if channel, sender, ok := strings.Cut(id, ":"); !ok || channel == "" || sender == "" {
+				issues = append(issues, Issue{
+					Field:   fmt.Sprintf("%s.ids[%d]", field, i),
+					Problem: fmt.Sprintf("%q is not a <channel>:<id> sender ID", id),
+					Fix:     `write it like "telegram:123456789"`,
+				})
+			} else if other, dup := owner[id]; dup {
+				issues = append(issues, Issue{
+					Field:   fmt.Sprintf("%s.ids[%d]", field, i),
+					Problem: fmt.Sprintf("%s is also an ID of user %s", id, other),
+					Fix:     "give each sender ID to one user",
+				})
+			} else {
+				owner[id] = name
+			}
+		}
+	}
+
+	triggerNames := make(map[string]bool, len(c.Triggers))
+	for i, t := range c.Triggers {
+		field := fmt.Sprintf("triggers[%d]", i)
+		if t.Name == "" || strings.ContainsAny(t.Name, "/ ") {
+			issues = append(issues, Issue{
+				Field:   field + ".name",
+				Problem: fmt.Sprintf("%q is not a trigger name", t.Name),
+				Fix:     "give the trigger a name without spaces or slashes",
+			})
+		} else if triggerNames[t.Name] {
+			issues = append(issues, Issue{
+				Field:   field + ".name",
+				Problem: fmt.Sprintf("another trigger is named %q", t.Name),
+				Fix:     "give each trigger its own name",
+			})
+		}
+		triggerNames[t.Name] = true
+
+		var missing string
+		switch t.Type {
+		case TriggerFile:
+			if t.Path == "" {
+				missing = "path"
+			} else if _, err := filepath.Match(t.Pattern, ""); err != nil {
+				issues = append(issues, Issue{
+					Field:   field + ".pattern",
+					Problem: fmt.Sprintf("%q is not a valid glob", t.Pattern),
+					Fix:     `use a pattern like "*.csv"`,
+				})
+			}
+		case TriggerWebhook:
+			if t.Secret == "" {
+				missing = "secret"
+			}
+		case TriggerMQTT:
+			if t.Broker == "" && c.Tools.MQTT.Broker == "" {
+				missing = "broker"
+			} else if t.Topic == "" {
+				missing = "topic"
+			} else if t.Broker != "" {
+				issues = append(issues, lintMQTT(field, t.Broker, t.CertFile, t.KeyFile)...)
+			}
+		case TriggerGPIO:
+			switch t.Edge {
+			case "", "rising", "falling", "both":
+			default:
+				issues = append(issues, Issue{
+					Field:   field + ".edge",
+					Problem: fmt.Sprintf("unknown edge %q", t.Edge),
+					Fix:     `use "rising", "falling" or "both"`,
+				})
+			}
+		case TriggerHomeAssistant:
+			if !c.Tools.HomeAssistant.Enabled {
+				issues = append(issues, Issue{
+					Field:   field + ".type",
+					Problem: "a homeassistant trigger watches the instance of tools.homeassistant, which is disabled",
+					Fix:     "enable tools.homeassistant",
+				})
+			}
+			for _, pattern := range t.Entities {
+				if _, err := path.Match(pattern, ""); err != nil {
+					issues = append(issues, Issue{
+						Field:   field + ".entities",
+						Problem: fmt.Sprintf("%q is not a valid glob", pattern),
+						Fix:     `use entity IDs or patterns like "binary_sensor.*_door"`,
+					})
+				}
+			}
+		default:
+			issues = append(issues, Issue{
+				Field:   field + ".type",
+				Problem: fmt.Sprintf("unknown trigger type %q", t.Type),
+				Fix:     `use "file", "webhook", "mqtt", "gpio" or "homeassistant"`,
+			})
+		}
+		if missing != "" {
+			issues = append(issues, Issue{
+				Field:   field + "." + missing,
+				Problem: fmt.Sprintf("a %s trigger needs %s", t.Type, missing),
+				Fix:     "set " + missing,
+			})
+		}
+	}
+
+	hb := c.Heartbeat
+	if hb.QuietHours != "" {
+		from, to, ok := strings.Cut(hb.QuietHours, "-")
+		_, errFrom := time.Parse("15:04", strings.TrimSpace(from))
+		_, errTo := time.Parse("15:04", strings.TrimSpace(to))
+		if !ok || errFrom != nil || errTo != nil || strings.TrimSpace(from) == strings.TrimSpace(to) {
+			issues = append(issues, Issue{
+				Field:   "heartbeat.quiet_hours",
+				Problem: fmt.Sprintf("%q is not a time window", hb.QuietHours),
+				Fix:     `write it like "22:00-07:00"`,
+			})
+		}
+	}
+	if hb.TZ != "" {
+		if _, err := time.LoadLocation(hb.TZ); err != nil {
+			issues = append(issues, Issue{
+				Field:   "heartbeat.tz",
+				Problem: fmt.Sprintf("unknown time zone %q", hb.TZ),
+				Fix:     `use an IANA name like "Europe/Paris"`,
+			})
+		}
+	}
+	if hb.MaxRunsPerDay < 0 || hb.DailyLimitUSD < 0 {
+		issues = append(issues, Issue{
+			Field:   "heartbeat",
+			Problem: "max_runs_per_day and daily_limit_usd can't be negative",
+			Fix:     "use 0 for no limit",
+		})
+	}
+
+	switch c.Tools.Cron.CatchUp {
+	case "", "once", "skip":
+	default:
+		issues = append(issues, Issue{
+			Field:   "tools.cron.catch_up",
+			Problem: fmt.Sprintf("unknown catch-up policy %q", c.Tools.Cron.CatchUp),
+			Fix:     `use "once" or "skip"`,
+		})
+	}
+
+	web := c.Tools.Web
+	switch web.SearchBackend {
+	case "", SearchBrave, SearchTavily, SearchDuckDuckGo, SearchPerplexity, SearchSearXNG:
+	default:
+		issues = append(issues, Issue{
+			Field:   "tools.web.search_backend",
+			Problem: fmt.Sprintf("unknown search backend %q", web.SearchBackend),
+			Fix:     `use "brave", "tavily", "duckduckgo", "perplexity" or "searxng", or leave it empty`,
+		})
+	}
+	if (web.SearXNG.Enabled || web.SearchBackend == SearchSearXNG) && web.SearXNG.BaseURL == "" {
+		issues = append(issues, Issue{
+			Field:   "tools.web.searxng.base_url",
+			Problem: "SearXNG is used but has no base URL",
+			Fix:     `set it to your instance, like "http://localhost:8888"`,
+		})
+	}
+
+	if web.Fetch.TimeoutSeconds < 0 || web.Fetch.MaxSizeKB < 0 || web.Fetch.PageChars < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.web.fetch",
+			Problem: "timeout_seconds, max_size_kb and page_chars can't be negative",
+			Fix:     "use 0 for the default",
+		})
+	}
+
+	execCfg := c.Tools.Exec
+	for _, list := range []struct {
+		field    string
+		patterns []string
+	}{
+		{"tools.exec.custom_deny_patterns", execCfg.CustomDenyPatterns},
+		{"tools.exec.allow_patterns", execCfg.AllowPatterns},
+	} {
+		for _, pattern := range list.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				issues = append(issues, Issue{
+					Field:   list.field,
+					Problem: fmt.Sprintf("invalid regular expression %q: %v", pattern, err),
+					Fix:     "fix the pattern; in JSON, backslashes are written twice",
+				})
+			}
+		}
+	}
+	if execCfg.TimeoutSeconds < 0 || execCfg.MaxOutputChars < 0 || execCfg.Sandbox.MemoryMB < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.exec",
+			Problem: "timeout_seconds, max_output_chars and sandbox.memory_mb can't be negative",
+			Fix:     "use 0 for the default",
+		})
+	}
+	switch execCfg.Sandbox.Mode {
+	case "", SandboxBwrap, SandboxFirejail, SandboxDocker:
+	default:
+		issues = append(issues, Issue{
+			Field:   "tools.exec.sandbox.mode",
+			Problem: fmt.Sprintf("unknown sandbox %q", execCfg.Sandbox.Mode),
+			Fix:     `use "bwrap", "firejail" or "docker", or leave it empty`,
+		})
+	}
+
+	files := c.Tools.Files
+	for _, path := range files.AllowPaths {
+		if !filepath.IsAbs(expandHome(path)) {
+			issues = append(issues, Issue{
+				Field:   "tools.files.allow_paths",
+				Problem: fmt.Sprintf("%q is not an absolute path", path),
+				Fix:     `write it in full, like "/etc/nginx" or "~/.config/app"`,
+			})
+		}
+	}
+	if files.MaxFileSizeKB < 0 || files.Backups < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.files",
+			Problem: "max_file_size_kb and backups can't be negative",
+			Fix:     "use 0 for no limit or no backups",
+		})
+	}
+
+	browser := c.Tools.Browser
+	if browser.Enabled && len(browser.AllowDomains) == 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.browser.allow_domains",
+			Problem: "the browser is enabled but may open no site",
+			Fix:     `list the domains it may open, like ["example.com"]`,
+		})
+	}
+	for _, domain := range browser.AllowDomains {
+		if domain == "" || strings.ContainsAny(domain, ":/*") {
+			issues = append(issues, Issue{
+				Field:   "tools.browser.allow_domains",
+				Problem: fmt.Sprintf("%q is not a domain", domain),
+				Fix:     `write just the domain, like "example.com"; it allows its subdomains too`,
+			})
+		}
+	}
+	if browser.TimeoutSeconds < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.browser.timeout_seconds",
+			Problem: "negative timeout",
+			Fix:     "use 0 for the default",
+		})
+	}
+
+	httpTool := c.Tools.HTTP
+	if httpTool.Enabled && len(httpTool.AllowDomains) == 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.http.allow_domains",
+			Problem: "http_request is enabled but may call no site",
+			Fix:     `list the domains it may call, like ["api.github.com"]`,
+		})
+	}
+	for _, domain := range httpTool.AllowDomains {
+		if domain == "" || strings.ContainsAny(domain, ":/*") {
+			issues = append(issues, Issue{
+				Field:   "tools.http.allow_domains",
+				Problem: fmt.Sprintf("%q is not a domain", domain),
+				Fix:     `write just the domain, like "api.github.com"; it allows its subdomains too`,
+			})
+		}
+	}
+	secretDomains := make([]string, 0, len(httpTool.SecretHeaders))
+	for domain := range httpTool.SecretHeaders {
+		secretDomains = append(secretDomains, domain)
+	}
+	sort.Strings(secretDomains)
+	for _, domain := range secretDomains {
+		if !domainListed(domain, httpTool.AllowDomains) {
+			issues = append(issues, Issue{
+				Field:   "tools.http.secret_headers." + domain,
+				Problem: fmt.Sprintf("%s is not in allow_domains, so these headers are never sent", domain),
+				Fix:     "add it to allow_domains or remove its headers",
+			})
+		}
+	}
+	if httpTool.MaxResponseKB < 0 || httpTool.TimeoutSeconds < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.http",
+			Problem: "max_response_kb and timeout_seconds can't be negative",
+			Fix:     "use 0 for the default",
+		})
+	}
+
+	code := c.Tools.Code
+	if code.TimeoutSeconds < 0 || code.CPUSeconds < 0 || code.MemoryMB < 0 || code.MaxFileMB < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.code",
+			Problem: "timeout_seconds, cpu_seconds, memory_mb and max_file_mb can't be negative",
+			Fix:     "use 0 for the default",
+		})
+	}
+
+	if cal := c.Tools.Calendar; cal.Enabled {
+		switch cal.Provider {
+		case "caldav":
+			if u, err := url.Parse(cal.CalDAV.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				issues = append(issues, Issue{
+					Field:   "tools.calendar.caldav.url",
+					Problem: "the CalDAV calendar needs an http or https url",
+					Fix:     "set it to the calendar's address, shown in your calendar app's settings",
+				})
+			}
+		case "google":
+			if cal.Google.ClientID == "" || cal.Google.ClientSecret == "" {
+				issues = append(issues, Issue{
+					Field:   "tools.calendar.google",
+					Problem: "Google Calendar needs the client_id and client_secret of an OAuth client",
+					Fix:     `create a "Desktop app" OAuth client in the Google Cloud console`,
+				})
+			}
+		default:
+			issues = append(issues, Issue{
+				Field:   "tools.calendar.provider",
+				Problem: fmt.Sprintf("unknown calendar provider %q", cal.Provider),
+				Fix:     `use "caldav" or "google"`,
+			})
+		}
+		if _, err := time.LoadLocation(cal.Timezone); err != nil {
+			issues = append(issues, Issue{
+				Field:   "tools.calendar.timezone",
+				Problem: fmt.Sprintf("unknown time zone %q", cal.Timezone),
+				Fix:     "use an IANA name such as Europe/Paris",
+			})
+		}
+	}
+
+	if em := c.Tools.Email; em.Enabled && (em.IMAPHost == "" || em.Address == "" ||
+		!em.ReadOnly && em.SMTPHost == "") {
+		issues = append(issues, Issue{
+			Field:   "tools.email",
+			Problem: "the email tool needs an address, an imap_host and, unless read_only, an smtp_host",
+			Fix:     "set them from your mail provider's settings for mail apps",
+		})
+	}
+
+	if f := c.Tools.Feeds; f.Enabled {
+		if f.PollMinutes > 0 && f.PollMinutes < 5 {
+			issues = append(issues, Issue{
+				Field:   "tools.feeds.poll_minutes",
+				Problem: fmt.Sprintf("polling feeds every %d minutes may get picoclaw blocked by their sites", f.PollMinutes),
+				Fix:     "use 5 or more; alerts can't come sooner than feeds are updated anyway",
+			})
+		}
+		if f.MinScore < 0 || f.MinScore >= 1 {
+			issues = append(issues, Issue{
+				Field:   "tools.feeds.min_score",
+				Problem: fmt.Sprintf("min_score %g is not a similarity between 0 and 1", f.MinScore),
+				Fix:     "use a value like 0.5; higher keeps fewer items",
+			})
+		}
+	}
+
+	if tasks := c.Tools.Tasks; tasks.Enabled && tasks.NagHours > 0 && !c.Heartbeat.Enabled {
+		issues = append(issues, Issue{
+			Field:   "tools.tasks.nag_hours",
+			Problem: "overdue tasks are brought up by the heartbeat, which is disabled",
+			Fix:     "enable the heartbeat, or set nag_hours to 0 to only see tasks when asking",
+		})
+	}
+
+	hw := c.Tools.Hardware
+	for field, pins := range map[string][]int{"gpio_inputs": hw.GPIOInputs, "gpio_outputs": hw.GPIOOutputs} {
+		for _, pin := range pins {
+			if pin < 0 {
+				issues = append(issues, Issue{
+					Field:   "tools.hardware." + field,
+					Problem: fmt.Sprintf("%d is not a GPIO number", pin),
+					Fix:     "use sysfs GPIO numbers, as listed in /sys/kernel/debug/gpio",
+				})
+			}
+		}
+	}
+	for _, device := range hw.I2CDevices {
+		bus, addr, ok := strings.Cut(strings.TrimSpace(device), ":")
+		n, err := strconv.ParseInt(addr, 0, 16)
+		validAddr := addr == "*" || (err == nil && n >= 0x03 && n <= 0x77)
+		if _, busErr := strconv.Atoi(bus); !ok || busErr != nil || !validAddr {
+			issues = append(issues, Issue{
+				Field:   "tools.hardware.i2c_devices",
+				Problem: fmt.Sprintf("%q is not an I2C device", device),
+				Fix:     `write it as bus:address, e.g. "1:0x38", or "1:*" for all the devices of bus 1`,
+			})
+		}
+	}
+	for _, port := range hw.SerialPorts {
+		if !strings.HasPrefix(port, "/dev/") {
+			issues = append(issues, Issue{
+				Field:   "tools.hardware.serial_ports",
+				Problem: fmt.Sprintf("%q is not a serial device", port),
+				Fix:     "use device paths such as /dev/ttyS1 or /dev/ttyUSB0",
+			})
+		}
+	}
+	switch hw.SerialBaud {
+	case 0, 9600, 19200, 38400, 57600, 115200, 230400, 460800, 921600:
+	default:
+		issues = append(issues, Issue{
+			Field:   "tools.hardware.serial_baud",
+			Problem: fmt.Sprintf("unsupported baud rate %d", hw.SerialBaud),
+			Fix:     "use 9600, 19200, 38400, 57600, 115200, 230400, 460800 or 921600",
+		})
+	}
+
+	if cam := c.Tools.Camera; cam.Enabled {
+		if cam.Command == "" && !strings.HasPrefix(cam.Device, "/dev/") {
+			issues = append(issues, Issue{
+				Field:   "tools.camera.device",
+				Problem: fmt.Sprintf("%q is not a video device", cam.Device),
+				Fix:     "use the camera's device, such as /dev/video0 (see v4l2-ctl --list-devices), or set a command",
+			})
+		}
+		if cam.Command != "" && !strings.Contains(cam.Command, "{path}") {
+			issues = append(issues, Issue{
+				Field:   "tools.camera.command",
+				Problem: "the command has no {path} to write the photo to",
+				Fix:     `put {path} where the command takes its output file, e.g. "fswebcam --no-banner {path}"`,
+			})
+		}
+		if cam.Width < 0 || cam.Height < 0 || (cam.Width == 0) != (cam.Height == 0) {
+			issues = append(issues, Issue{
+				Field:   "tools.camera.width",
+				Problem: fmt.Sprintf("%dx%d is not a photo size", cam.Width, cam.Height),
+				Fix:     "set both width and height, e.g. 1280 and 720",
+			})
+		}
+	}
+
+	sys := c.Tools.SysInfo
+	if len(sys.Alerts) > 0 && sys.CheckSeconds > 0 && sys.CheckSeconds < 10 {
+		issues = append(issues, Issue{
+			Field:   "tools.sysinfo.check_seconds",
+			Problem: fmt.Sprintf("checking the system every %d seconds keeps a small board busy", sys.CheckSeconds),
+			Fix:     "use 10 or more; for_minutes decides how soon an alert comes anyway",
+		})
+	}
+	for i, alert := range sys.Alerts {
+		field := fmt.Sprintf("tools.sysinfo.alerts[%d]", i)
+		switch {
+		case !slices.Contains([]string{"cpu", "memory", "disk", "temperature", "load"}, alert.Metric):
+			issues = append(issues, Issue{
+				Field:   field + ".metric",
+				Problem: fmt.Sprintf("unknown metric %q", alert.Metric),
+				Fix:     "use cpu, memory, disk, temperature or load",
+			})
+		case alert.Above == 0 && alert.Below == 0:
+			issues = append(issues, Issue{
+				Field:   field,
+				Problem: "the alert has no threshold, so it never fires",
+				Fix:     `set "above" or "below", e.g. "above": 80`,
+			})
+		case alert.Metric != "temperature" && alert.Metric != "load" && (alert.Above >= 100 || alert.Below > 100):
+			issues = append(issues, Issue{
+				Field:   field,
+				Problem: fmt.Sprintf("%s is a percentage, so it never goes past 100", alert.Metric),
+				Fix:     "use a threshold between 0 and 100",
+			})
+		case alert.Path != "" && (alert.Metric != "disk" || !path.IsAbs(alert.Path)):
+			issues = append(issues, Issue{
+				Field:   field + ".path",
+				Problem: fmt.Sprintf("path %q is only for disk alerts, as an absolute path", alert.Path),
+				Fix:     `use the mount point of the disk, e.g. "/" or "/mnt/sd"`,
+			})
+		}
+	}
+
+	if m := c.Tools.MQTT; m.Enabled || m.Broker != "" {
+		if m.Broker == "" {
+			issues = append(issues, Issue{
+				Field:   "tools.mqtt.broker",
+				Problem: "the mqtt tool is enabled without a broker",
+				Fix:     `set "broker", e.g. to tcp://192.168.1.10:1883`,
+			})
+		} else {
+			issues = append(issues, lintMQTT("tools.mqtt", m.Broker, m.CertFile, m.KeyFile)...)
+		}
+		if m.Enabled && len(m.Topics) == 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.mqtt.topics",
+				Problem: "no topics are allowed, so the agent can't publish or read any",
+				Fix:     `list the topic filters the agent may use, e.g. ["home/+/set", "home/sensors/#"]`,
+			})
+		}
+	}
+
+	if ha := c.Tools.HomeAssistant; ha.Enabled {
+		if ha.URL == "" {
+			issues = append(issues, Issue{
+				Field:   "tools.homeassistant.url",
+				Problem: "Home Assistant is enabled without its address",
+				Fix:     `set "url", e.g. to http://homeassistant.local:8123`,
+			})
+		} else if !strings.HasPrefix(ha.URL, "http://") && !strings.HasPrefix(ha.URL, "https://") {
+			issues = append(issues, Issue{
+				Field:   "tools.homeassistant.url",
+				Problem: fmt.Sprintf("%q is not an http(s) address", ha.URL),
+				Fix:     `write it like "http://homeassistant.local:8123"`,
+			})
+		}
+		if ha.Token == "" {
+			issues = append(issues, Issue{
+				Field:   "tools.homeassistant.token",
+				Problem: "Home Assistant is enabled without an access token",
+				Fix:     "create a long-lived access token at the bottom of your Home Assistant profile",
+			})
+		}
+		if len(ha.Entities) == 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.homeassistant.entities",
+				Problem: "no entities are allowed, so the agent can't see or control any",
+				Fix:     `list the entities the agent may use, e.g. ["light.*", "sensor.living_room_temperature"]`,
+			})
+		}
+		for _, pattern := range ha.Entities {
+			if _, err := path.Match(pattern, ""); err != nil {
+				issues = append(issues, Issue{
+					Field:   "tools.homeassistant.entities",
+					Problem: fmt.Sprintf("%q is not a valid glob", pattern),
+					Fix:     `use entity IDs or patterns like "light.*"`,
+				})
+			}
+		}
+	}
+
+	if ct := c.Tools.Containers; ct.Enabled {
+		if ct.Socket != "" && !path.IsAbs(ct.Socket) {
+			issues = append(issues, Issue{
+				Field:   "tools.containers.socket",
+				Problem: fmt.Sprintf("%q is not the path of a socket", ct.Socket),
+				Fix:     "use e.g. /var/run/docker.sock, or leave it empty to find Docker or Podman",
+			})
+		}
+		if len(ct.Containers) == 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.containers.containers",
+				Problem: "no containers are allowed, so the agent can't see or manage any",
+				Fix:     `list the container names the agent may use, e.g. ["jellyfin", "media-*"]`,
+			})
+		}
+		for _, pattern := range ct.Containers {
+			if _, err := path.Match(pattern, ""); err != nil {
+				issues = append(issues, Issue{
+					Field:   "tools.containers.containers",
+					Problem: fmt.Sprintf("%q is not a valid glob", pattern),
+					Fix:     `use container names or patterns like "media-*"`,
+				})
+			}
+		}
+	}
+
+	if ssh := c.Tools.SSH; ssh.Enabled {
+		if len(ssh.Hosts) == 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.ssh.hosts",
+				Problem: "no hosts are listed, so the agent can't log in anywhere",
+				Fix:     `add hosts, e.g. {"name": "nas", "address": "192.168.1.10", "user": "admin"}`,
+			})
+		}
+		for _, key := range []string{ssh.KeyFile, ssh.KnownHostsFile} {
+			if key != "" && !filepath.IsAbs(expandHome(key)) {
+				issues = append(issues, Issue{
+					Field:   "tools.ssh",
+					Problem: fmt.Sprintf("%q is a relative path, which depends on where picoclaw is started", key),
+					Fix:     "use an absolute path, e.g. ~/.ssh/id_ed25519",
+				})
+			}
+		}
+		seen := make(map[string]bool)
+		for i, host := range ssh.Hosts {
+			field := fmt.Sprintf("tools.ssh.hosts[%d]", i)
+			switch {
+			case host.Name == "" || host.Address == "" || host.User == "":
+				issues = append(issues, Issue{
+					Field:   field,
+					Problem: "a host needs a name, an address and a user",
+					Fix:     `set "name" for the agent, "address" (host or host:port) and "user" to log in as`,
+				})
+			case seen[host.Name]:
+				issues = append(issues, Issue{
+					Field:   field + ".name",
+					Problem: fmt.Sprintf("the name %q is used by another host", host.Name),
+					Fix:     "give each host its own name",
+				})
+			}
+			seen[host.Name] = true
+			if host.KeyFile != "" && !filepath.IsAbs(expandHome(host.KeyFile)) {
+				issues = append(issues, Issue{
+					Field:   field + ".key_file",
+					Problem: fmt.Sprintf("%q is a relative path, which depends on where picoclaw is started", host.KeyFile),
+					Fix:     "use an absolute path, e.g. ~/.ssh/id_ed25519",
+				})
+			}
+			for _, list := range []struct {
+				name     string
+				patterns []string
+			}{{"allow_commands", host.AllowCommands}, {"deny_commands", host.DenyCommands}} {
+				for _, pattern := range list.patterns {
+					if _, err := regexp.Compile(pattern); err != nil {
+						issues = append(issues, Issue{
+							Field:   field + "." + list.name,
+							Problem: fmt.Sprintf("%q is not a valid regular expression: %v", pattern, err),
+							Fix:     `use Go regular expressions, e.g. "df( -h)?" or "systemctl status \\S+"`,
+						})
+					}
+				}
+			}
+			for _, dir := range host.TransferDirs {
+				if !path.IsAbs(dir) {
+					issues = append(issues, Issue{
+						Field:   field + ".transfer_dirs",
+						Problem: fmt.Sprintf("%q is not an absolute path on the host", dir),
+						Fix:     "use absolute paths, e.g. /srv/incoming",
+					})
+				}
+			}
+		}
+	}
+
+	if git := c.Tools.Git; git.Enabled {
+		for i, f := range git.Forges {
+			field := fmt.Sprintf("tools.git.forges[%d]", i)
+			switch {
+			case f.Type != "github" && f.Type != "gitea":
+				issues = append(issues, Issue{
+					Field:   field + ".type",
+					Problem: fmt.Sprintf("unknown forge type %q", f.Type),
+					Fix:     `use "github", or "gitea" for Gitea and Forgejo`,
+				})
+			case f.Type == "gitea" && f.URL == "":
+				issues = append(issues, Issue{
+					Field:   field + ".url",
+					Problem: "a Gitea forge needs its address",
+					Fix:     `set "url" to the address of its web interface, e.g. https://git.example.lan`,
+				})
+			case f.URL != "" && !strings.HasPrefix(f.URL, "http://") && !strings.HasPrefix(f.URL, "https://"):
+				issues = append(issues, Issue{
+					Field:   field + ".url",
+					Problem: fmt.Sprintf("%q is not an http(s) address", f.URL),
+					Fix:     "use the address of the forge's web interface, e.g. https://git.example.lan",
+				})
+			}
+			if f.Token == "" {
+				issues = append(issues, Issue{
+					Field:   field + ".token",
+					Problem: "without a token, only public repositories can be read and no pull request opened",
+					Fix:     "create an access token that can read issues and open pull requests",
+				})
+			}
+		}
+	}
+
+	if download := c.Tools.Download; download.Enabled {
+		if download.MaxSizeMB < 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.download.max_size_mb",
+				Problem: "the size limit is negative",
+				Fix:     "set it to the largest download in MB, or 0 for the default of 200",
+			})
+		}
+		for _, pattern := range download.AllowTypes {
+			if _, err := path.Match(pattern, ""); err != nil || !strings.Contains(pattern, "/") {
+				issues = append(issues, Issue{
+					Field:   "tools.download.allow_types",
+					Problem: fmt.Sprintf("%q is not a media type or a pattern of them", pattern),
+					Fix:     `use media types such as "application/zip" or "application/*"`,
+				})
+			}
+		}
+	}
+
+	if db := c.Tools.DB; db.Enabled {
+		if len(db.Databases) == 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.db.databases",
+				Problem: "the db tool is enabled without databases to query",
+				Fix:     `add databases, e.g. {"name": "media", "type": "sqlite", "path": "~/media.db"}`,
+			})
+		}
+		seen := make(map[string]bool)
+		for i, d := range db.Databases {
+			field := fmt.Sprintf("tools.db.databases[%d]", i)
+			switch {
+			case d.Name == "":
+				issues = append(issues, Issue{
+					Field:   field + ".name",
+					Problem: "a database needs a name",
+					Fix:     "set the name the agent knows it by",
+				})
+			case seen[d.Name]:
+				issues = append(issues, Issue{
+					Field:   field + ".name",
+					Problem: fmt.Sprintf("the name %q is used by another database", d.Name),
+					Fix:     "give each database its own name",
+				})
+			}
+			seen[d.Name] = true
+			switch d.Type {
+			case "sqlite":
+				if d.Path == "" {
+					issues = append(issues, Issue{
+						Field:   field + ".path",
+						Problem: "a SQLite database needs the path of its file",
+						Fix:     `set "path", e.g. ~/media.db`,
+					})
+				}
+			case "postgres", "mysql":
+				if u, err := url.Parse(d.URL); err != nil || !strings.HasPrefix(u.Scheme, d.Type) ||
+					u.User.Username() == "" {
+					issues = append(issues, Issue{
+						Field:   field + ".url",
+						Problem: fmt.Sprintf("a %s database needs a %s:// URL with a user", d.Type, d.Type),
+						Fix:     fmt.Sprintf("set \"url\", e.g. %s://picoclaw@localhost/shop", d.Type),
+					})
+				}
+			default:
+				issues = append(issues, Issue{
+					Field:   field + ".type",
+					Problem: fmt.Sprintf("unknown database type %q", d.Type),
+					Fix:     `use "sqlite", "postgres" or "mysql" (also for MariaDB)`,
+				})
+			}
+		}
+	}
+
+	if d := c.Tools.Desktop; d.Enabled {
+		if !d.Clipboard && !d.Notifications {
+			issues = append(issues, Issue{
+				Field:   "tools.desktop",
+				Problem: "the desktop tools are enabled with both the clipboard and notifications off",
+				Fix:     `turn on "clipboard" or "notifications", or set "enabled" to false`,
+			})
+		}
+		if d.MaxClipboardChars < 0 {
+			issues = append(issues, Issue{
+				Field:   "tools.desktop.max_clipboard_chars",
+				Problem: fmt.Sprintf("%d is not a number of characters", d.MaxClipboardChars),
+				Fix:     "use 0 for the default of 10000, or a larger limit",
+			})
+		}
+	}
+
+	if external := c.Tools.External; external.Enabled && external.Dir == "" {
+		issues = append(issues, Issue{
+			Field:   "tools.external.dir",
+			Problem: "external tools are enabled without a directory to load them from",
+			Fix:     `set "dir", e.g. to ~/.picoclaw/tools.d`,
+		})
+	}
+	if m := c.Tools.External.WASMMaxMemoryMB; m < 0 || m > 4096 {
+		issues = append(issues, Issue{
+			Field:   "tools.external.wasm_max_memory_mb",
+			Problem: fmt.Sprintf("%d MB is not a memory limit WebAssembly modules can have", m),
+			Fix:     "use 0 for the default of 128, or up to 4096",
+		})
+	}
+	if c.Tools.External.TimeoutSeconds < 0 {
+		issues = append(issues, Issue{
+			Field:   "tools.external.timeout_seconds",
+			Problem: "timeout_seconds can't be negative",
+			Fix:     "use 0 for the default",
+		})
+	}
+
+	servers := make([]string, 0, len(c.Tools.MCP.Servers))
+	for name := range c.Tools.MCP.Servers {
+		servers = append(servers, name)
+	}
+	sort.Strings(servers)
+	for _, name := range servers {
+		server := c.Tools.MCP.Servers[name]
+		field := "tools.mcp.servers." + name
+		if server.Enabled && (server.Command == "") == (server.URL == "") {
+			issues = append(issues, Issue{
+				Field:   field,
+				Problem: "a server needs either a command or a url",
+				Fix:     `set "command" for a server to run, or "url" for one reached over SSE`,
+			})
+		}
+		if server.URL != "" && !strings.HasPrefix(server.URL, "http://") && !strings.HasPrefix(server.URL, "https://") {
+			issues = append(issues, Issue{
+				Field:   field + ".url",
+				Problem: fmt.Sprintf("%q is not an http(s) URL", server.URL),
+				Fix:     `write it in full, like "http://localhost:8080/sse"`,
+			})
+		}
+		if server.TimeoutSeconds < 0 {
+			issues = append(issues, Issue{
+				Field:   field + ".timeout_seconds",
+				Problem: "negative timeout",
+				Fix:     "use 0 for the default",
+			})
+		}
+	}
+
+	approval := c.Tools.Approval
+	switch approval.Default {
+	case "", "deny", "allow":
+	default:
+		issues = append(issues, Issue{
+			Field:   "tools.approval.default",
+			Problem: fmt.Sprintf("unknown approval default %q", approval.Default),
+			Fix:     `use "deny" or "allow"`,
+		})
+	}
+	for i, rule := range approval.Rules {
+		field := fmt.Sprintf("tools.approval.rules[%d]", i)
+		switch rule.Action {
+		case "ask", "allow", "deny":
+		default:
+			issues = append(issues, Issue{
+				Field:   field + ".action",
+				Problem: fmt.Sprintf("unknown action %q", rule.Action),
+				Fix:     `use "ask", "allow" or "deny"`,
+			})
+		}
+		if rule.Tool == "" {
+			issues = append(issues, Issue{
+				Field:   field + ".tool",
+				Problem: "the rule names no tool",
+				Fix:     `set it to a tool name, or "*" for any tool`,
+			})
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			issues = append(issues, Issue{
+				Field:   field + ".pattern",
+				Problem: fmt.Sprintf("invalid regular expression: %v", err),
+				Fix:     "fix the pattern; in JSON, backslashes are written twice",
+			})
+		}
+		for j, dir := range rule.Outside {
+			if !filepath.IsAbs(expandHome(dir)) {
+				issues = append(issues, Issue{
+					Field:   fmt.Sprintf("%s.outside[%d]", field, j),
+					Problem: fmt.Sprintf("%q is not an absolute path", dir),
+					Fix:     "use a full path, e.g. /home/me/projects or ~/projects",
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// domainListed reports whether domain is one of domains or a subdomain of
+// one.
+func domainListed(domain string, domains []string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(d), ".")
+		if d != "" && (domain == d || strings.HasSuffix(domain, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintMQTT checks the broker address and client certificate of the MQTT
+// settings at field.
+func lintMQTT(field, broker, certFile, keyFile string) []Issue {
+	var issues []Issue
+	if scheme, addr, _ := strings.Cut(broker, "://"); (scheme != "tcp" && scheme != "tls") || addr == "" {
+		issues = append(issues, Issue{
+			Field:   field + ".broker",
+			Problem: fmt.Sprintf("%q is not a broker address", broker),
+			Fix:     `write it like "tcp://192.168.1.10:1883" or "tls://broker.example.com:8883"`,
+		})
+	}
+	if (certFile == "") != (keyFile == "") {
+		issues = append(issues, Issue{
+			Field:   field + ".cert_file",
+			Problem: "a client certificate needs both cert_file and key_file",
+			Fix:     "set both, or neither to log in with a username and password",
+		})
+	}
+	return issues
+}
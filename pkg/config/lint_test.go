@@ -0,0 +1,832 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLintJSON_UnknownKeys(t *testing.T) {
+	data := []byte(`{
+  "agents": {"defaults": {"modle": "gpt4", "max_tokens": 100}},
+  "model_list": [{"model_name": "gpt4", "model": "openai/gpt-4o", "api_kye": "sk"}],
+  "channels": {"telegram": {"enabled": true, "token": "t", "whatever": 1}}
+}`)
+	issues := LintJSON(data)
+
+	want := map[string]string{
+		"agents.defaults.modle":      `did you mean "model"?`,
+		"model_list[0].api_kye":      `did you mean "api_key"?`,
+		"channels.telegram.whatever": "remove it",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for _, issue := range issues {
+		fix, ok := want[issue.Field]
+		if !ok {
+			t.Errorf("unexpected issue %v", issue)
+			continue
+		}
+		if issue.Fix != fix {
+			t.Errorf("%s: fix = %q, want %q", issue.Field, issue.Fix, fix)
+		}
+		if !issue.Warning {
+			t.Errorf("%s: unknown keys should be warnings", issue.Field)
+		}
+	}
+}
+
+func TestLintJSON_SyntaxError(t *testing.T) {
+	issues := LintJSON([]byte("{\n  \"a\": 1,\n  \"b\": 2,\n}\n"))
+	if len(issues) != 1 {
+		t.Fatalf("got %v, want one issue", issues)
+	}
+	if issues[0].Warning {
+		t.Error("a syntax error is not a warning")
+	}
+	if issues[0].Field != "line 4, column 1" {
+		t.Errorf("Field = %q, want line 4, column 1", issues[0].Field)
+	}
+}
+
+func TestConfigLint(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ModelList = []ModelConfig{
+		{ModelName: "fast", Model: "groq/llama", Fallbacks: []string{"groq/mixtral", "slow"}},
+	}
+	cfg.Agents.Defaults.ModelName = "fast"
+	cfg.Agents.Defaults.ModelFallbacks = []string{"smart"}
+	cfg.Agents.Defaults.ModelRoles = map[string]string{"summarize": "fast"}
+	cfg.Channels.Telegram.Enabled = true
+	cfg.Channels.Telegram.Token = ""
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		fields = append(fields, issue.Field)
+	}
+	got := strings.Join(fields, " ")
+	want := "agents.defaults.model_fallbacks[0] model_list[0].fallbacks[1] channels.telegram.token"
+	if got != want {
+		t.Errorf("issues at %q, want %q", got, want)
+	}
+}
+
+func TestLint_PlanningMode(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.Planning.Mode = "sometimes"
+
+	var found bool
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "agents.defaults.planning.mode" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Lint() should report the unknown planning mode")
+	}
+}
+
+func TestLint_OnNewMessage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.OnNewMessage = "drop"
+
+	var found bool
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "agents.defaults.on_new_message" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Lint() should report the unknown on_new_message behavior")
+	}
+}
+
+func TestLint_Triggers(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Triggers = []TriggerConfig{
+		{Name: "inbox", Type: TriggerFile, Path: "/srv/inbox", Pattern: "*.csv"},
+		{Name: "inbox", Type: TriggerWebhook},
+		{Name: "door", Type: TriggerMQTT, Broker: "mqtt://10.0.0.2", Topic: "home/door"},
+		{Name: "button", Type: TriggerGPIO, Pin: 17, Edge: "up"},
+		{Name: "bell", Type: "sms"},
+	}
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	for _, field := range []string{
+		"triggers[1].name", "triggers[1].secret", "triggers[2].broker", "triggers[3].edge", "triggers[4].type",
+	} {
+		if !found[field] {
+			t.Errorf("Lint() should report %s", field)
+		}
+	}
+	if found["triggers[0].name"] || found["triggers[0].pattern"] {
+		t.Error("Lint() reported the valid file trigger")
+	}
+}
+
+func TestLint_Heartbeat(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Heartbeat.QuietHours = "22:00-7"
+	cfg.Heartbeat.TZ = "Mars/Olympus"
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	if !found["heartbeat.quiet_hours"] || !found["heartbeat.tz"] {
+		t.Errorf("Lint() should report the bad quiet hours and time zone, got %v", found)
+	}
+
+	cfg.Heartbeat.QuietHours = "22:00-07:00"
+	cfg.Heartbeat.TZ = "Europe/Paris"
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "heartbeat") {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLint_Exec(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Exec.AllowPatterns = []string{`^git\b`, `(`}
+	cfg.Tools.Exec.TimeoutSeconds = -1
+	cfg.Tools.Exec.Sandbox.Mode = "chroot"
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	for _, field := range []string{"tools.exec.allow_patterns", "tools.exec", "tools.exec.sandbox.mode"} {
+		if !found[field] {
+			t.Errorf("Lint() should report %s, got %v", field, found)
+		}
+	}
+
+	cfg.Tools.Exec.AllowPatterns = []string{`^git\b`}
+	cfg.Tools.Exec.TimeoutSeconds = 30
+	cfg.Tools.Exec.Sandbox.Mode = SandboxDocker
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.exec") {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLint_Files(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Files.AllowPaths = []string{"/etc/nginx", "~/.config/app", "configs"}
+	cfg.Tools.Files.Backups = -1
+
+	var problems []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.files") {
+			problems = append(problems, issue.Problem)
+		}
+	}
+	if len(problems) != 2 || !strings.Contains(problems[0], `"configs"`) {
+		t.Errorf("Lint() = %v, want the relative path and the negative backups", problems)
+	}
+}
+
+func TestLint_WebSearch(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Web.SearchBackend = "bing"
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	if !found["tools.web.search_backend"] {
+		t.Error("Lint() should report the unknown search backend")
+	}
+
+	cfg.Tools.Web.SearchBackend = SearchSearXNG
+	found = map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	if found["tools.web.search_backend"] || !found["tools.web.searxng.base_url"] {
+		t.Errorf("Lint() = %v, want only the missing SearXNG base URL", found)
+	}
+}
+
+func TestLint_Fetch(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Web.Fetch.PageChars = -1
+
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "tools.web.fetch" {
+			return
+		}
+	}
+	t.Error("Lint() should report the negative page_chars")
+}
+
+func TestLint_Browser(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Browser.Enabled = true
+
+	var problems []string
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "tools.browser.allow_domains" {
+			problems = append(problems, issue.Problem)
+		}
+	}
+	if len(problems) != 1 {
+		t.Errorf("Lint() = %v, want the missing allow_domains", problems)
+	}
+
+	cfg.Tools.Browser.AllowDomains = []string{"example.com", "https://app.example.com/"}
+	problems = nil
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "tools.browser.allow_domains" {
+			problems = append(problems, issue.Problem)
+		}
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "https://") {
+		t.Errorf("Lint() = %v, want the URL reported", problems)
+	}
+}
+
+func TestLint_HTTP(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.HTTP.Enabled = true
+	cfg.Tools.HTTP.AllowDomains = []string{"github.com", "https://api.example.com"}
+	cfg.Tools.HTTP.SecretHeaders = map[string]map[string]string{
+		"api.github.com": {"Authorization": "Bearer x"},
+		"other.org":      {"X-Api-Key": "y"},
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.http") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.http.allow_domains", "tools.http.secret_headers.other.org"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Code(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Code.Enabled = true
+	cfg.Tools.Code.CPUSeconds = -1
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.code") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if strings.Join(fields, " ") != "tools.code" {
+		t.Errorf("Lint() fields = %v, want [tools.code]", fields)
+	}
+}
+
+func TestLint_Calendar(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Calendar.Enabled = true
+	cfg.Tools.Calendar.Provider = "caldav"
+	cfg.Tools.Calendar.CalDAV.URL = "cloud.example.com/dav"
+	cfg.Tools.Calendar.Timezone = "Europe/Lyon"
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.calendar") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.calendar.caldav.url", "tools.calendar.timezone"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.Calendar = CalendarToolConfig{Enabled: true, Provider: "google"}
+	fields = nil
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.calendar") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if strings.Join(fields, " ") != "tools.calendar.google" {
+		t.Errorf("Lint() fields = %v, want [tools.calendar.google]", fields)
+	}
+}
+
+func TestLint_Email(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channels.Email.Enabled = true
+	cfg.Channels.Email.Address = "bot@example.com"
+	cfg.Channels.Email.IMAPHost = "imap.example.com"
+	cfg.Tools.Email = EmailToolConfig{Enabled: true, Address: "me@example.com", IMAPHost: "imap.example.com"}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.Contains(issue.Field, "email") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"channels.email", "channels.email.allow_from", "tools.email"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Channels.Email.SMTPHost = "smtp.example.com"
+	cfg.Channels.Email.AllowFrom = FlexibleStringSlice{"me@example.com"}
+	cfg.Tools.Email.ReadOnly = true
+	for _, issue := range cfg.Lint() {
+		if strings.Contains(issue.Field, "email") {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLint_Feeds(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Feeds.Enabled = true
+	cfg.Tools.Feeds.PollMinutes = 1
+	cfg.Tools.Feeds.MinScore = 1.5
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.feeds") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.feeds.poll_minutes", "tools.feeds.min_score"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.Feeds = DefaultConfig().Tools.Feeds
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.feeds") {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLint_TasksNagging(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Heartbeat.Enabled = false
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.tasks") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if len(fields) != 1 || fields[0] != "tools.tasks.nag_hours" {
+		t.Errorf("Lint() fields = %v, want tools.tasks.nag_hours", fields)
+	}
+
+	cfg.Tools.Tasks.NagHours = 0
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.tasks") {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLint_External(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.External.Enabled = true
+	cfg.Tools.External.Dir = ""
+	cfg.Tools.External.WASMMaxMemoryMB = 8192
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.external") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.external.dir", "tools.external.wasm_max_memory_mb"}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.External.WASMMaxMemoryMB = 0
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "tools.external.wasm_max_memory_mb" {
+			t.Errorf("Lint() = %v for the default memory limit", issue)
+		}
+	}
+}
+
+func TestLint_MCP(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.MCP.Servers = map[string]MCPServerConfig{
+		"files":  {Enabled: true, Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem"}},
+		"home":   {Enabled: true, URL: "localhost:8123/mcp_server/sse"},
+		"both":   {Enabled: true, Command: "server", URL: "http://localhost:8080/sse"},
+		"off":    {},
+		"github": {Enabled: true, Command: "github-mcp-server", TimeoutSeconds: -1},
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.mcp") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.mcp.servers.both",
+		"tools.mcp.servers.github.timeout_seconds",
+		"tools.mcp.servers.home.url",
+	}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Memory(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.Memory.Enabled = true
+	cfg.Agents.Defaults.Memory.Consolidation = "hourly"
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	if !found["agents.defaults.memory.enabled"] {
+		t.Error("Lint() should report memory enabled without an embed role")
+	}
+	if !found["agents.defaults.memory.consolidation"] {
+		t.Error("Lint() should report the unknown consolidation schedule")
+	}
+}
+
+func TestLint_ReviewModel(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.Review.Model = "no-such-model"
+	cfg.Agents.List = []AgentConfig{{ID: "coder", Review: &ReviewConfig{Enabled: true, Model: "missing"}}}
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	if !found["agents.defaults.review.model"] || !found["agents.list[0].review.model"] {
+		t.Errorf("Lint() should report unknown review models, got %v", found)
+	}
+}
+
+func TestLint_Approval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Approval = ApprovalConfig{
+		Enabled: true,
+		Default: "maybe",
+		Rules: []ApprovalRule{
+			{Tool: "exec", Pattern: `\brm\b`, Action: "ask"},
+			{Tool: "exec", Pattern: "(", Action: "block"},
+			{Pattern: "x", Action: "deny"},
+			{Tool: "exec", Outside: []string{"~/projects", "projects"}, Action: "deny"},
+		},
+	}
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	for _, field := range []string{
+		"tools.approval.default",
+		"tools.approval.rules[1].action",
+		"tools.approval.rules[1].pattern",
+		"tools.approval.rules[2].tool",
+		"tools.approval.rules[3].outside[1]",
+	} {
+		if !found[field] {
+			t.Errorf("Lint() should report %s", field)
+		}
+	}
+	if found["tools.approval.rules[0].pattern"] || found["tools.approval.rules[0].action"] ||
+		found["tools.approval.rules[3].outside[0]"] {
+		t.Error("Lint() reported a valid rule")
+	}
+}
+
+func TestLint_Users(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Users = map[string]UserConfig{
+		"ada":     {IDs: []string{"telegram:7", "discord"}},
+		"bob":     {IDs: []string{"Telegram:7"}},
+		"carl os": {},
+	}
+
+	found := map[string]bool{}
+	for _, issue := range cfg.Lint() {
+		found[issue.Field] = true
+	}
+	for _, field := range []string{"users.ada.ids[1]", "users.bob.ids[0]", "users.carl os", "users.carl os.ids"} {
+		if !found[field] {
+			t.Errorf("Lint() should report %s, got %v", field, found)
+		}
+	}
+	if found["users.ada.ids[0]"] {
+		t.Error("Lint() reported a valid ID")
+	}
+
+	if name, _, ok := cfg.FindUser("TELEGRAM:7"); !ok || name == "" {
+		t.Error("FindUser() should match IDs ignoring case")
+	}
+}
+
+func TestLint_HomeAssistant(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.HomeAssistant = HomeAssistantToolConfig{Enabled: true, URL: "homeassistant.local:8123"}
+	cfg.Triggers = []TriggerConfig{{Name: "door", Type: TriggerHomeAssistant, Entities: []string{"binary_sensor.[door"}}}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.homeassistant") || strings.HasPrefix(issue.Field, "triggers") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"triggers[0].entities",
+		"tools.homeassistant.url",
+		"tools.homeassistant.token",
+		"tools.homeassistant.entities",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_MQTT(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.MQTT = MQTTToolConfig{Enabled: true, Broker: "tls://broker.example.com:8883", CertFile: "/etc/pico.pem"}
+	cfg.Triggers = []TriggerConfig{
+		{Name: "door", Type: TriggerMQTT, Topic: "home/door"},
+		{Name: "own", Type: TriggerMQTT, Broker: "tcp://", Topic: "x"},
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.mqtt") || strings.HasPrefix(issue.Field, "triggers") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"triggers[1].broker", "tools.mqtt.cert_file", "tools.mqtt.topics"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.MQTT = MQTTToolConfig{}
+	for _, issue := range cfg.Lint() {
+		if issue.Field == "triggers[0].broker" {
+			return
+		}
+	}
+	t.Error("Lint() accepted an mqtt trigger without a broker")
+}
+
+func TestLint_Hardware(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Hardware = HardwareToolsConfig{
+		GPIOInputs:  []int{5},
+		GPIOOutputs: []int{-1},
+		I2CDevices:  []string{"1:0x38", "2:*", "i2c-1:0x76", "1:0x90"},
+		SerialPorts: []string{"/dev/ttyS1", "COM3"},
+		SerialBaud:  100000,
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.hardware") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.hardware.gpio_outputs",
+		"tools.hardware.i2c_devices",
+		"tools.hardware.i2c_devices",
+		"tools.hardware.serial_ports",
+		"tools.hardware.serial_baud",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Camera(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Camera = CameraToolConfig{Enabled: true, Device: "video0", Width: 640}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.camera") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.camera.device", "tools.camera.width"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.Camera = CameraToolConfig{Enabled: true, Command: "libcamera-still -o photo.jpg"}
+	issues := cfg.Lint()
+	if !slices.ContainsFunc(issues, func(i Issue) bool { return i.Field == "tools.camera.command" }) {
+		t.Errorf("a command without {path} passed: %v", issues)
+	}
+}
+
+func TestLint_SysInfoAlerts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.SysInfo.Alerts = []SysAlertConfig{
+		{Metric: "temperature", Above: 80, ForMinutes: 5},
+		{Metric: "disk", Above: 90, Path: "/mnt/sd"},
+		{Metric: "gpu", Above: 90},
+		{Metric: "memory"},
+		{Metric: "cpu", Above: 150},
+		{Metric: "cpu", Above: 90, Path: "/"},
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.sysinfo") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.sysinfo.alerts[2].metric",
+		"tools.sysinfo.alerts[3]",
+		"tools.sysinfo.alerts[4]",
+		"tools.sysinfo.alerts[5].path",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Containers(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Containers = ContainersToolConfig{Enabled: true, Socket: "docker.sock"}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.containers") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.containers.socket", "tools.containers.containers"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.Containers = ContainersToolConfig{Enabled: true, Containers: []string{"media-*", "[bad"}}
+	fields = nil
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.containers") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if want := []string{"tools.containers.containers"}; !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_SSH(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.SSH = SSHToolConfig{Enabled: true, KeyFile: "keys/agent"}
+	cfg.Tools.SSH.Hosts = []SSHHostConfig{
+		{Name: "nas", Address: "192.168.1.10", User: "admin", AllowCommands: []string{`df( -h)?`, "(bad"}},
+		{Name: "nas", Address: "nas2.lan", User: "admin", KeyFile: "~/.ssh/nas",
+			TransferDirs: []string{"/srv/incoming", "incoming"}},
+		{Name: "pi", Address: "pi.lan", DenyCommands: []string{"reboot"}},
+	}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.ssh") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.ssh",
+		"tools.ssh.hosts[0].allow_commands",
+		"tools.ssh.hosts[1].name",
+		"tools.ssh.hosts[1].transfer_dirs",
+		"tools.ssh.hosts[2]",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.SSH = SSHToolConfig{Enabled: true}
+	fields = nil
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.ssh") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if want := []string{"tools.ssh.hosts"}; !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Download(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Download.MaxSizeMB = -1
+	cfg.Tools.Download.AllowTypes = []string{"application/*", "zip", "image/[png"}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.download") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.download.max_size_mb",
+		"tools.download.allow_types",
+		"tools.download.allow_types",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_DB(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.DB = DBToolConfig{Enabled: true, Databases: []DatabaseConfig{
+		{Name: "media", Type: "sqlite", Path: "~/media.db"},
+		{Name: "shop", Type: "postgres", URL: "postgres://pico@localhost/shop", Password: "secret"},
+		{Name: "shop", Type: "mysql", URL: "mysql://localhost/shop"},
+		{Name: "notes", Type: "sqlite"},
+		{Type: "oracle"},
+	}}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.db") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.db.databases[2].name",
+		"tools.db.databases[2].url",
+		"tools.db.databases[3].path",
+		"tools.db.databases[4].name",
+		"tools.db.databases[4].type",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+
+	cfg.Tools.DB.Databases = nil
+	fields = nil
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.db") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if want := []string{"tools.db.databases"}; !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Desktop(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Desktop = DesktopToolsConfig{Enabled: true, MaxClipboardChars: -1}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.desktop") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{"tools.desktop", "tools.desktop.max_clipboard_chars"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLint_Git(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Git = GitToolConfig{Enabled: true, Forges: []GitForgeConfig{
+		{Type: "github", Token: "t"},
+		{Type: "gitlab", URL: "https://gitlab.com", Token: "t"},
+		{Type: "gitea"},
+		{Type: "gitea", URL: "git.lan", Token: "t"},
+	}}
+
+	var fields []string
+	for _, issue := range cfg.Lint() {
+		if strings.HasPrefix(issue.Field, "tools.git") {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"tools.git.forges[1].type",
+		"tools.git.forges[2].url",
+		"tools.git.forges[2].token",
+		"tools.git.forges[3].url",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
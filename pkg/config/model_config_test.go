@@ -53,6 +53,44 @@ func TestGetModelConfig_EmptyList(t *testing.T) {
 	}
 }
 
+func TestGetModelConfig_ResolvesFallbacks(t *testing.T) {
+	cfg := &Config{
+		ModelList: []ModelConfig{
+			{
+				ModelName: "main",
+				Model:     "anthropic/claude-sonnet-4.6",
+				APIKey:    "ant-key",
+				Fallbacks: []string{"gpt", "anthropic/claude-haiku-4.5", "ollama/llama3"},
+			},
+			{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "oai-key", Fallbacks: []string{"main"}},
+		},
+	}
+
+	result, err := cfg.GetModelConfig("main")
+	if err != nil {
+		t.Fatalf("GetModelConfig() error = %v", err)
+	}
+	if len(result.FallbackConfigs) != 3 {
+		t.Fatalf("len(FallbackConfigs) = %d, want 3", len(result.FallbackConfigs))
+	}
+
+	gpt := result.FallbackConfigs[0]
+	if gpt.Model != "openai/gpt-4o" || gpt.APIKey != "oai-key" || len(gpt.Fallbacks) != 0 {
+		t.Errorf("model_list fallback = %+v", gpt)
+	}
+	haiku := result.FallbackConfigs[1]
+	if haiku.Model != "anthropic/claude-haiku-4.5" || haiku.APIKey != "ant-key" {
+		t.Errorf("same-protocol fallback should inherit credentials: %+v", haiku)
+	}
+	ollama := result.FallbackConfigs[2]
+	if ollama.Model != "ollama/llama3" || ollama.APIKey != "" {
+		t.Errorf("cross-protocol fallback should not inherit credentials: %+v", ollama)
+	}
+	if len(cfg.ModelList[0].FallbackConfigs) != 0 {
+		t.Error("GetModelConfig should not modify model_list")
+	}
+}
+
 func TestGetModelConfig_RoundRobin(t *testing.T) {
 	cfg := &Config{
 		ModelList: []ModelConfig{
@@ -400,3 +438,54 @@ func TestModelConfig_RequestTimeoutDefaultZeroValue(t *testing.T) {
 		t.Fatalf("RequestTimeout = %d, want 0", cfg.RequestTimeout)
 	}
 }
+
+func TestGetModelConfig_ProviderAliases(t *testing.T) {
+	cfg := &Config{
+		ProviderAliases: map[string]ProviderAlias{
+			"work": {
+				APIBase:      "https://llm.corp.example/v1",
+				APIKey:       "work-key",
+				ExtraHeaders: map[string]string{"X-Team": "infra", "X-Env": "prod"},
+			},
+			"lab": {Protocol: "anthropic", APIBase: "https://lab.example/v1", APIKey: "lab-key"},
+		},
+		ModelList: []ModelConfig{
+			{
+				ModelName:    "gpt",
+				Model:        "work/gpt-4o",
+				ExtraHeaders: map[string]string{"X-Env": "staging"},
+				Fallbacks:    []string{"work/gpt-4o-mini", "lab/claude-haiku-4.5"},
+			},
+			{ModelName: "plain", Model: "openai/gpt-4o", APIKey: "oai-key"},
+		},
+	}
+
+	result, err := cfg.GetModelConfig("gpt")
+	if err != nil {
+		t.Fatalf("GetModelConfig() error = %v", err)
+	}
+	if result.Model != "openai/gpt-4o" || result.APIBase != "https://llm.corp.example/v1" ||
+		result.APIKey != "work-key" {
+		t.Errorf("aliased model = %+v", result)
+	}
+	if result.ExtraHeaders["X-Team"] != "infra" || result.ExtraHeaders["X-Env"] != "staging" {
+		t.Errorf("ExtraHeaders = %v, want alias headers with the entry's overrides", result.ExtraHeaders)
+	}
+	if cfg.ProviderAliases["work"].ExtraHeaders["X-Env"] != "prod" {
+		t.Error("GetModelConfig should not modify provider_aliases")
+	}
+
+	mini, haiku := result.FallbackConfigs[0], result.FallbackConfigs[1]
+	if mini.Model != "openai/gpt-4o-mini" || mini.APIKey != "work-key" {
+		t.Errorf("aliased fallback = %+v", mini)
+	}
+	if haiku.Model != "anthropic/claude-haiku-4.5" || haiku.APIBase != "https://lab.example/v1" ||
+		haiku.APIKey != "lab-key" {
+		t.Errorf("fallback through another alias = %+v", haiku)
+	}
+
+	plain, err := cfg.GetModelConfig("plain")
+	if err != nil || plain.Model != "openai/gpt-4o" || plain.APIBase != "" {
+		t.Errorf("unaliased model = %+v, %v", plain, err)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProfileEnv names the environment variable that selects a profile. The
+// --profile flag sets it too.
+const ProfileEnv = "PICOCLAW_PROFILE"
+
+// applyProfile merges the named profile from the "profiles" section over
+// the rest of the config file and drops the section. Objects are merged key
+// by key; anything else, including arrays such as model_list, replaces the
+// top-level value:
+//
+//	"profiles": {
+//	  "offline": {
+//	    "agents": {"defaults": {"model": "local"}},
+//	    "channels": {"telegram": {"enabled": false}}
+//	  }
+//	}
+//
+// The section itself is returned unchanged, so that saving the config keeps
+// it. An empty name applies no profile.
+func applyProfile(data []byte, name string) ([]byte, map[string]json.RawMessage, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	raw, ok := doc["profiles"]
+	if !ok {
+		if name != "" {
+			return nil, nil, fmt.Errorf("profile %q not found: the config has no profiles", name)
+		}
+		return data, nil, nil
+	}
+
+	var profiles map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, nil, fmt.Errorf("profiles: %w", err)
+	}
+	if name == "" {
+		delete(doc, "profiles")
+		data, err := json.Marshal(doc)
+		return data, profiles, err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, nil, fmt.Errorf("profile %q not found, the config has: %s", name, strings.Join(names, ", "))
+	}
+
+	var base, overlay any
+	if err := decodeNumbers(data, &base); err != nil {
+		return nil, nil, err
+	}
+	if err := decodeNumbers(profile, &overlay); err != nil {
+		return nil, nil, fmt.Errorf("profiles.%s: %w", name, err)
+	}
+	if _, ok := overlay.(map[string]any); !ok {
+		return nil, nil, fmt.Errorf("profiles.%s: must be an object", name)
+	}
+	merged := mergeJSON(base, overlay).(map[string]any)
+	delete(merged, "profiles")
+
+	data, err := json.Marshal(merged)
+	return data, profiles, err
+}
+
+// mergeJSON returns overlay merged over base.
+func mergeJSON(base, overlay any) any {
+	b, ok := base.(map[string]any)
+	o, ok2 := overlay.(map[string]any)
+	if !ok || !ok2 {
+		return overlay
+	}
+	for key, value := range o {
+		b[key] = mergeJSON(b[key], value)
+	}
+	return b
+}
+
+// decodeNumbers decodes data into v, keeping numbers as written: large IDs
+// don't survive float64.
+func decodeNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// profileName returns the profile selected through the environment.
+func profileName() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
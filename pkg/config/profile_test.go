@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profileConfig = `{
+  "agents": {"defaults": {"model": "cloud", "max_tokens": 4096}},
+  "model_list": [
+    {"model_name": "cloud", "model": "anthropic/claude-sonnet-4", "api_key": "sk", "proxy": "http://proxy.work:3128"}
+  ],
+  "channels": {"telegram": {"enabled": true, "token": "123:abc"}},
+  "profiles": {
+    "offline": {
+      "agents": {"defaults": {"model": "local"}},
+      "model_list": [{"model_name": "local", "model": "ollama/llama3", "api_base": "http://localhost:11434/v1"}],
+      "channels": {"telegram": {"enabled": false}}
+    },
+    "home": {
+      "model_list": [{"model_name": "cloud", "model": "anthropic/claude-sonnet-4", "api_key": "sk"}]
+    }
+  }
+}`
+
+func writeProfileConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(profileConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	t.Setenv(ProfileEnv, "offline")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Profile != "offline" {
+		t.Errorf("Profile = %q, want offline", cfg.Profile)
+	}
+	if cfg.Agents.Defaults.GetModelName() != "local" {
+		t.Errorf("model = %q, want the profile's", cfg.Agents.Defaults.GetModelName())
+	}
+	if cfg.Agents.Defaults.MaxTokens != 4096 {
+		t.Errorf("MaxTokens = %d, want the base value kept", cfg.Agents.Defaults.MaxTokens)
+	}
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].ModelName != "local" {
+		t.Errorf("model_list = %+v, want the profile's list", cfg.ModelList)
+	}
+	if cfg.Channels.Telegram.Enabled || cfg.Channels.Telegram.Token != "123:abc" {
+		t.Errorf("telegram = %+v, want disabled with the base token", cfg.Channels.Telegram)
+	}
+	if len(cfg.Profiles) != 2 {
+		t.Errorf("Profiles = %v, want both kept for saving", cfg.Profiles)
+	}
+
+	t.Setenv(ProfileEnv, "home")
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ModelList[0].Proxy != "" {
+		t.Errorf("Proxy = %q, want none at home", cfg.ModelList[0].Proxy)
+	}
+}
+
+func TestLoadConfig_NoProfile(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	t.Setenv(ProfileEnv, "")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Profile != "" || cfg.Agents.Defaults.GetModelName() != "cloud" || !cfg.Channels.Telegram.Enabled {
+		t.Errorf("profile applied without being selected: %+v", cfg.Agents.Defaults)
+	}
+}
+
+func TestLoadConfig_UnknownProfile(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	t.Setenv(ProfileEnv, "work")
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "home, offline") {
+		t.Errorf("err = %v, want the available profiles listed", err)
+	}
+}
+
+func TestLintJSON_Profiles(t *testing.T) {
+	issues := LintJSON([]byte(`{"profiles": {"work": {"chanels": {}}}}`))
+	if len(issues) != 1 || issues[0].Field != "profiles.work.chanels" {
+		t.Errorf("issues = %v, want the typo in the profile", issues)
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretResolver returns the secret ref points to. ref is the part of the
+// config value after "scheme:".
+type SecretResolver func(ref string) (string, error)
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"file":    resolveFile,
+		"keyring": resolveKeyring,
+		"vault":   resolveVault,
+		"sops":    resolveSOPS,
+	}
+)
+
+// RegisterSecretResolver makes config values of the form "scheme:ref"
+// resolve through r, replacing any resolver registered for scheme.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = r
+}
+
+// resolveSecret resolves s if it starts with a registered scheme, and
+// returns it unchanged otherwise.
+func resolveSecret(s string) (string, error) {
+	scheme, ref, ok := strings.Cut(s, ":")
+	if !ok {
+		return s, nil
+	}
+	secretResolversMu.RLock()
+	r, ok := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !ok {
+		return s, nil
+	}
+	secret, err := r(ref)
+	if err != nil {
+		return "", fmt.Errorf("%s secret: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// resolveFile reads a file, such as a Docker or systemd credential.
+func resolveFile(path string) (string, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// runCommand runs a helper program and returns its output. Tests replace it.
+var runCommand = func(name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// resolveKeyring reads "service/account" from the OS keyring: the login
+// keychain on macOS, the Secret Service (GNOME Keyring, KWallet) elsewhere.
+func resolveKeyring(ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", fmt.Errorf("%q should be service/account", ref)
+	}
+
+	var out []byte
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = runCommand("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "windows":
+		return "", fmt.Errorf("not supported on Windows, use an environment variable instead")
+	default:
+		out, err = runCommand("secret-tool", "lookup", "service", service, "username", account)
+	}
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(string(out), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("no secret stored for %s", ref)
+	}
+	return secret, nil
+}
+
+// vaultClient is used for Vault requests.
+var vaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// resolveVault reads "path#field" from HashiCorp Vault, where path is the
+// secret's API path, e.g. "secret/data/picoclaw#openai" for a KV v2 engine
+// mounted at secret/. The server and token come from VAULT_ADDR and
+// VAULT_TOKEN (or ~/.vault-token), as for the vault CLI.
+func resolveVault(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("%q should be path#field", ref)
+	}
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, _ := os.UserHomeDir()
+		data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return "", fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token can't be read")
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", path, resp.Status)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	// KV v2 nests the secret's fields one level deeper than v1.
+	fields := body.Data
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = inner
+		}
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("%s has no field %q", path, field)
+	}
+	return value, nil
+}
+
+// resolveSOPS decrypts "file#key.path" with the sops program, e.g.
+// "~/.picoclaw/secrets.enc.json#openai.api_key". sops finds the decryption
+// key (age, PGP, cloud KMS) as it usually does.
+func resolveSOPS(ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("%q should be file#key", ref)
+	}
+	var extract strings.Builder
+	for _, part := range strings.Split(key, ".") {
+		fmt.Fprintf(&extract, "[%q]", part)
+	}
+	out, err := runCommand("sops", "--decrypt", "--extract", extract.String(), expandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
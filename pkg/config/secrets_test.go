@@ -0,0 +1,128 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func stubCommand(t *testing.T, out string) *[]string {
+	t.Helper()
+	var got []string
+	orig := runCommand
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		got = append([]string{name}, args...)
+		return []byte(out), nil
+	}
+	t.Cleanup(func() { runCommand = orig })
+	return &got
+}
+
+func TestResolveSecret_Keyring(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no keyring support on Windows")
+	}
+	args := stubCommand(t, "sk-keyring\n")
+
+	got, err := resolveSecret("keyring:picoclaw/openai")
+	if err != nil {
+		t.Fatalf("resolveSecret: %v", err)
+	}
+	if got != "sk-keyring" {
+		t.Errorf("secret = %q, want sk-keyring", got)
+	}
+	if cmd := strings.Join(*args, " "); !strings.Contains(cmd, "picoclaw") || !strings.Contains(cmd, "openai") {
+		t.Errorf("ran %q, want service and account passed", cmd)
+	}
+
+	if _, err := resolveSecret("keyring:picoclaw"); err == nil {
+		t.Error("expected an error for a reference without an account")
+	}
+}
+
+func TestResolveSecret_SOPS(t *testing.T) {
+	args := stubCommand(t, "sk-sops\n")
+
+	got, err := resolveSecret("sops:/etc/picoclaw/secrets.enc.json#openai.api_key")
+	if err != nil {
+		t.Fatalf("resolveSecret: %v", err)
+	}
+	if got != "sk-sops" {
+		t.Errorf("secret = %q, want sk-sops", got)
+	}
+	want := `sops --decrypt --extract ["openai"]["api_key"] /etc/picoclaw/secrets.enc.json`
+	if cmd := strings.Join(*args, " "); cmd != want {
+		t.Errorf("ran %q, want %q", cmd, want)
+	}
+}
+
+func TestResolveSecret_Vault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/picoclaw":
+			w.Write([]byte(`{"data": {"data": {"openai": "sk-v2"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/picoclaw":
+			w.Write([]byte(`{"data": {"openai": "sk-v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	for ref, want := range map[string]string{
+		"vault:secret/data/picoclaw#openai": "sk-v2",
+		"vault:kv/picoclaw#openai":          "sk-v1",
+	} {
+		got, err := resolveSecret(ref)
+		if err != nil {
+			t.Errorf("%s: %v", ref, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", ref, got, want)
+		}
+	}
+
+	for _, ref := range []string{"vault:secret/data/picoclaw#missing", "vault:secret/data/other#openai"} {
+		if _, err := resolveSecret(ref); err == nil {
+			t.Errorf("%s: expected an error", ref)
+		}
+	}
+}
+
+func TestRegisterSecretResolver(t *testing.T) {
+	RegisterSecretResolver("test", func(ref string) (string, error) {
+		return strings.ToUpper(ref), nil
+	})
+	t.Cleanup(func() {
+		secretResolversMu.Lock()
+		delete(secretResolvers, "test")
+		secretResolversMu.Unlock()
+	})
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"model_list": [{"model_name": "m", "model": "groq/llama", "api_key": "test:key", "api_base": "test:base"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ModelList[0].APIKey != "KEY" {
+		t.Errorf("APIKey = %q, want KEY", cfg.ModelList[0].APIKey)
+	}
+	if cfg.ModelList[0].APIBase != "test:base" {
+		t.Errorf("APIBase = %q, want non-secret fields left alone", cfg.ModelList[0].APIBase)
+	}
+}
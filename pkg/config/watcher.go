@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// reloadDelay lets an editor finish writing before the file is read; saves
+// often arrive as several events.
+const reloadDelay = 500 * time.Millisecond
+
+// Watcher reloads the config file when it changes and hands the new config
+// to apply, which should check it fully before changing anything. A file
+// that fails to parse or validate, or that apply rejects, leaves the
+// previous config in effect.
+type Watcher struct {
+	path    string
+	apply   func(cfg *Config) error
+	watcher *fsnotify.Watcher
+}
+
+// NewWatcher watches path. The directory is watched rather than the file,
+// so saves that replace the file (as most editors do) are seen too.
+func NewWatcher(path string, apply func(cfg *Config) error) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.Add(filepath.Dir(path)); err != nil {
+		fw.Close()
+		return nil, err
+	}
+	return &Watcher{path: path, apply: apply, watcher: fw}, nil
+}
+
+// Run applies changes until ctx is done, then stops watching.
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.watcher.Close()
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(w.path) ||
+				!event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WarnCF("config", "Config watcher error", map[string]any{"error": err.Error()})
+		case <-timer.C:
+			if err := w.reload(); err != nil {
+				logger.ErrorCF("config", "Config not reloaded, keeping the previous one",
+					map[string]any{"path": w.path, "error": err.Error()})
+			}
+		}
+	}
+}
+
+// reload reads, validates and applies the config file.
+func (w *Watcher) reload() error {
+	// LoadConfig falls back to the defaults for a missing file, which is
+	// never what a half-finished save means.
+	if _, err := os.Stat(w.path); err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+	if err := w.apply(cfg); err != nil {
+		return err
+	}
+	logger.InfoCF("config", "Config reloaded", map[string]any{"path": w.path})
+	return nil
+}
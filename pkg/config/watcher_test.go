@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReloadsValidChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"agents":{"defaults":{"max_tokens":100}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := make(chan *Config, 4)
+	w, err := NewWatcher(path, func(cfg *Config) error {
+		applied <- cfg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// A file that doesn't parse is never applied.
+	if err := os.WriteFile(path, []byte(`{"agents":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case cfg := <-applied:
+		t.Fatalf("applied a broken config: %+v", cfg.Agents.Defaults)
+	case <-time.After(2 * reloadDelay):
+	}
+
+	if err := os.WriteFile(path, []byte(`{"agents":{"defaults":{"max_tokens":200}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case cfg := <-applied:
+		if cfg.Agents.Defaults.MaxTokens != 200 {
+			t.Errorf("MaxTokens = %d, want 200", cfg.Agents.Defaults.MaxTokens)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change not applied")
+	}
+}
@@ -0,0 +1,382 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package containers manages Docker or Podman containers through the
+// engine's API on its local socket, limited to an allowlist of names.
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// requestTimeout leaves stopping a container its grace period.
+	requestTimeout = 60 * time.Second
+	stopSeconds    = 10
+	maxResponse    = 8 << 20
+	// MaxLogBytes bounds the logs returned, keeping the latest.
+	MaxLogBytes = 32 << 10
+)
+
+// Container is a container, running or not.
+type Container struct {
+	ID      string
+	Name    string
+	Image   string
+	State   string // running, exited, paused, restarting...
+	Status  string // e.g. "Up 3 hours (healthy)"
+	Created time.Time
+}
+
+// Stats is the resource use of a running container.
+type Stats struct {
+	CPUPercent  float64 // Of one CPU, as docker stats shows it
+	MemoryUsage uint64  // Bytes, page cache excluded
+	MemoryLimit uint64
+	NetRx       uint64 // Bytes since the container started
+	NetTx       uint64
+	PIDs        int
+}
+
+// Client calls the Docker Engine API, which Podman serves too, on a unix
+// socket. It only sees and acts on the containers its allowlist matches.
+type Client struct {
+	socket string
+	allow  []string
+	http   *http.Client
+}
+
+// NewClient returns a client of the engine at socket, or of the first
+// engine found when socket is empty. allow holds the container names it may
+// use, as globs such as "media-*"; an empty list allows none.
+func NewClient(socket string, allow []string) *Client {
+	if socket == "" {
+		socket = FindSocket()
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{
+		socket: socket,
+		allow:  allow,
+		http:   &http.Client{Transport: transport, Timeout: requestTimeout},
+	}
+}
+
+// FindSocket returns the socket of Docker, else of rootful Podman, else of
+// the user's rootless Podman, else Docker's default.
+func FindSocket() string {
+	candidates := []string{"/var/run/docker.sock", "/run/podman/podman.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	for _, socket := range candidates {
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return socket
+		}
+	}
+	return candidates[0]
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Allowed reports whether the allowlist matches the container name.
+func (c *Client) Allowed(name string) bool {
+	if !validName.MatchString(name) {
+		return false
+	}
+	for _, pattern := range c.allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the allowed containers, stopped ones included, sorted by
+// name.
+func (c *Client) List(ctx context.Context) ([]Container, error) {
+	all, err := c.listAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []Container
+	for _, ct := range all {
+		if c.Allowed(ct.Name) {
+			ct.ID = shortID(ct.ID)
+			list = append(list, ct)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// listAll returns every container, with its full ID.
+func (c *Client) listAll(ctx context.Context) ([]Container, error) {
+	var all []struct {
+		ID      string   `json:"Id"`
+		Names   []string `json:"Names"`
+		Image   string   `json:"Image"`
+		State   string   `json:"State"`
+		Status  string   `json:"Status"`
+		Created int64    `json:"Created"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/json?all=1", &all); err != nil {
+		return nil, err
+	}
+	var list []Container
+	for _, ct := range all {
+		if len(ct.Names) == 0 {
+			continue
+		}
+		list = append(list, Container{
+			ID:      ct.ID,
+			Name:    strings.TrimPrefix(ct.Names[0], "/"),
+			Image:   ct.Image,
+			State:   ct.State,
+			Status:  ct.Status,
+			Created: time.Unix(ct.Created, 0),
+		})
+	}
+	return list, nil
+}
+
+// lookup returns the full ID of the allowed container named name. The
+// engine takes an ID or the start of one where a name is expected, which
+// the allowlist can't vouch for, so containers are only ever reached by the
+// ID of the one with exactly that name.
+func (c *Client) lookup(ctx context.Context, name string) (string, error) {
+	if !c.Allowed(name) {
+		return "", notAllowed(name)
+	}
+	all, err := c.listAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, ct := range all {
+		if ct.Name == name {
+			return ct.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no container named %s", name)
+}
+
+// Start, Stop and Restart act on an allowed container, and report whether
+// it changed: starting a running container, or stopping a stopped one,
+// does nothing.
+func (c *Client) Start(ctx context.Context, name string) (bool, error) {
+	return c.act(ctx, name, "start")
+}
+
+func (c *Client) Stop(ctx context.Context, name string) (bool, error) {
+	return c.act(ctx, name, "stop")
+}
+
+func (c *Client) Restart(ctx context.Context, name string) (bool, error) {
+	return c.act(ctx, name, "restart")
+}
+
+var errNotModified = errors.New("not modified")
+
+func (c *Client) act(ctx context.Context, name, action string) (bool, error) {
+	id, err := c.lookup(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	apiPath := "/containers/" + id + "/" + action
+	if action != "start" {
+		apiPath += "?t=" + strconv.Itoa(stopSeconds)
+	}
+	err = c.do(ctx, http.MethodPost, apiPath, nil)
+	if errors.Is(err, errNotModified) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Logs returns the latest lines of an allowed container's output, stdout
+// and stderr mixed, at most tail lines and no older than since when set.
+func (c *Client) Logs(ctx context.Context, name string, tail int, since time.Time) (string, error) {
+	id, err := c.lookup(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		Config struct {
+			Tty bool `json:"Tty"`
+		} `json:"Config"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/json", &info); err != nil {
+		return "", err
+	}
+	query := url.Values{"stdout": {"1"}, "stderr": {"1"}, "timestamps": {"1"}, "tail": {strconv.Itoa(tail)}}
+	if !since.IsZero() {
+		query.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+	var raw []byte
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/logs?"+query.Encode(), &raw); err != nil {
+		return "", err
+	}
+	logs := raw
+	if !info.Config.Tty {
+		logs = demux(raw)
+	}
+	if len(logs) > MaxLogBytes {
+		logs = logs[len(logs)-MaxLogBytes:]
+		if i := strings.IndexByte(string(logs), '\n'); i >= 0 {
+			logs = logs[i+1:]
+		}
+	}
+	return string(logs), nil
+}
+
+// demux joins the frames of a log stream of a container without a TTY:
+// each is a byte naming the stream, three zeros, a big-endian length and
+// the data.
+func demux(stream []byte) []byte {
+	var out []byte
+	for len(stream) >= 8 {
+		size := int(stream[4])<<24 | int(stream[5])<<16 | int(stream[6])<<8 | int(stream[7])
+		stream = stream[8:]
+		size = min(size, len(stream))
+		out = append(out, stream[:size]...)
+		stream = stream[size:]
+	}
+	return out
+}
+
+// Stats returns the resource use of an allowed, running container.
+func (c *Client) Stats(ctx context.Context, name string) (*Stats, error) {
+	id, err := c.lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	type cpuStats struct {
+		Usage struct {
+			Total  uint64   `json:"total_usage"`
+			PerCPU []uint64 `json:"percpu_usage"`
+		} `json:"cpu_usage"`
+		System     uint64 `json:"system_cpu_usage"`
+		OnlineCPUs int    `json:"online_cpus"`
+	}
+	var raw struct {
+		CPU    cpuStats `json:"cpu_stats"`
+		PreCPU cpuStats `json:"precpu_stats"`
+		Memory struct {
+			Usage uint64            `json:"usage"`
+			Limit uint64            `json:"limit"`
+			Stats map[string]uint64 `json:"stats"`
+		} `json:"memory_stats"`
+		Networks map[string]struct {
+			Rx uint64 `json:"rx_bytes"`
+			Tx uint64 `json:"tx_bytes"`
+		} `json:"networks"`
+		PIDs struct {
+			Current int `json:"current"`
+		} `json:"pids_stats"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/stats?stream=false", &raw); err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{MemoryLimit: raw.Memory.Limit, PIDs: raw.PIDs.Current}
+	cpuDelta := float64(raw.CPU.Usage.Total) - float64(raw.PreCPU.Usage.Total)
+	systemDelta := float64(raw.CPU.System) - float64(raw.PreCPU.System)
+	cpus := raw.CPU.OnlineCPUs
+	if cpus == 0 {
+		cpus = max(len(raw.CPU.Usage.PerCPU), 1)
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * float64(cpus) * 100
+	}
+	// Page cache is reclaimable, so it isn't counted, as docker stats does:
+	// inactive_file on cgroup v2, cache on v1.
+	cache, ok := raw.Memory.Stats["inactive_file"]
+	if !ok {
+		cache = raw.Memory.Stats["cache"]
+	}
+	stats.MemoryUsage = raw.Memory.Usage - min(cache, raw.Memory.Usage)
+	for _, n := range raw.Networks {
+		stats.NetRx += n.Rx
+		stats.NetTx += n.Tx
+	}
+	return stats, nil
+}
+
+func notAllowed(name string) error {
+	return fmt.Errorf("container %s is not allowed", name)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// do calls the API and decodes the JSON answer into out, or stores it in
+// out as it is when out is a *[]byte.
+func (c *Client) do(ctx context.Context, method, apiPath string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, "http://engine"+apiPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var netErr *net.OpError
+		if errors.As(err, &netErr) && netErr.Op == "dial" {
+			return fmt.Errorf("no Docker or Podman engine at %s (is it running, and may picoclaw use it?): %w",
+				c.socket, err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return errNotModified
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return errors.New(apiErr.Message)
+	}
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*out = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected answer from the container engine: %w", err)
+	}
+	return nil
+}
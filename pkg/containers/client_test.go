@@ -0,0 +1,209 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEngine serves the parts of the Docker Engine API the client uses.
+type fakeEngine struct {
+	mu      sync.Mutex
+	running map[string]bool
+	actions []string
+}
+
+func newFakeEngine(t *testing.T) (*fakeEngine, string) {
+	t.Helper()
+	// Unix socket paths are limited to about 100 bytes, which t.TempDir()
+	// may exceed.
+	dir, err := os.MkdirTemp("", "ctr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "engine.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := &fakeEngine{running: map[string]bool{"media-jellyfin": true, "media-sonarr": false, "db": true}}
+	server := &http.Server{Handler: engine}
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return engine, socket
+}
+
+func (e *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if r.URL.Path == "/containers/json" {
+		var list []map[string]any
+		for name, running := range e.running {
+			state := "exited"
+			if running {
+				state = "running"
+			}
+			list = append(list, map[string]any{
+				"Id": "0123456789abcdef" + name, "Names": []string{"/" + name}, "Image": "img/" + name,
+				"State": state, "Status": "Up 3 hours", "Created": 1700000000,
+			})
+		}
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+	// Containers are reached by their full ID.
+	rest, ok := strings.CutPrefix(r.URL.Path, "/containers/")
+	id, op, _ := strings.Cut(rest, "/")
+	name := strings.TrimPrefix(id, "0123456789abcdef")
+	running, exists := e.running[name]
+	exists = exists && id != name
+	if !ok || !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "No such container: " + name})
+		return
+	}
+	switch op {
+	case "start", "stop", "restart":
+		e.actions = append(e.actions, op+" "+name+" "+r.URL.RawQuery)
+		if (op == "start" && running) || (op == "stop" && !running) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		e.running[name] = op != "stop"
+		w.WriteHeader(http.StatusNoContent)
+	case "json":
+		json.NewEncoder(w).Encode(map[string]any{"Config": map[string]any{"Tty": name == "db"}})
+	case "logs":
+		if r.URL.Query().Get("tail") != "2" {
+			http.Error(w, "bad tail", http.StatusBadRequest)
+			return
+		}
+		if name == "db" {
+			w.Write([]byte("tty line 1\ntty line 2\n"))
+			return
+		}
+		for i, line := range []string{"started\n", "error: disk full\n"} {
+			w.Write([]byte{byte(1 + i), 0, 0, 0, 0, 0, 0, byte(len(line))})
+			w.Write([]byte(line))
+		}
+	case "stats":
+		w.Write([]byte(`{
+			"cpu_stats": {"cpu_usage": {"total_usage": 3000}, "system_cpu_usage": 20000, "online_cpus": 4},
+			"precpu_stats": {"cpu_usage": {"total_usage": 1000}, "system_cpu_usage": 10000},
+			"memory_stats": {"usage": 300, "limit": 1000, "stats": {"inactive_file": 100}},
+			"networks": {"eth0": {"rx_bytes": 10, "tx_bytes": 20}, "eth1": {"rx_bytes": 1, "tx_bytes": 2}},
+			"pids_stats": {"current": 7}
+		}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClient_ListAndAllowlist(t *testing.T) {
+	_, socket := newFakeEngine(t)
+	c := NewClient(socket, []string{"media-*"})
+
+	list, err := c.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "media-jellyfin" || list[1].Name != "media-sonarr" {
+		t.Fatalf("List() = %+v", list)
+	}
+	if list[0].State != "running" || list[0].ID != "0123456789ab" || list[0].Image != "img/media-jellyfin" {
+		t.Errorf("container = %+v", list[0])
+	}
+
+	for _, name := range []string{"db", "media-x/../../db", "", "-media"} {
+		if c.Allowed(name) {
+			t.Errorf("Allowed(%q) = true", name)
+		}
+		if _, err := c.Stop(context.Background(), name); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Stop(%q) error = %v", name, err)
+		}
+	}
+}
+
+func TestClient_Actions(t *testing.T) {
+	engine, socket := newFakeEngine(t)
+	c := NewClient(socket, []string{"media-*"})
+	ctx := context.Background()
+
+	if changed, err := c.Start(ctx, "media-jellyfin"); err != nil || changed {
+		t.Errorf("Start(running) = %v, %v, want false, nil", changed, err)
+	}
+	if changed, err := c.Start(ctx, "media-sonarr"); err != nil || !changed {
+		t.Errorf("Start(stopped) = %v, %v, want true, nil", changed, err)
+	}
+	if changed, err := c.Restart(ctx, "media-jellyfin"); err != nil || !changed {
+		t.Errorf("Restart() = %v, %v", changed, err)
+	}
+	if _, err := c.Stop(ctx, "media-gone"); err == nil || !strings.Contains(err.Error(), "no container named") {
+		t.Errorf("Stop(missing) error = %v", err)
+	}
+	want := []string{"start media-jellyfin ", "start media-sonarr ", "restart media-jellyfin t=10"}
+	if strings.Join(engine.actions, "|") != strings.Join(want, "|") {
+		t.Errorf("actions = %q, want %q", engine.actions, want)
+	}
+}
+
+func TestClient_IDPrefixIsNoName(t *testing.T) {
+	engine, socket := newFakeEngine(t)
+	// The engine would take "0123456789" as the start of any container's
+	// ID, but no container has that name.
+	c := NewClient(socket, []string{"0*"})
+	ctx := context.Background()
+
+	if _, err := c.Stop(ctx, "0123456789"); err == nil || !strings.Contains(err.Error(), "no container named") {
+		t.Errorf("Stop(ID prefix) error = %v", err)
+	}
+	if _, err := c.Logs(ctx, "0123456789", 2, time.Time{}); err == nil {
+		t.Error("Logs(ID prefix) succeeded")
+	}
+	if _, err := c.Stats(ctx, "0123456789"); err == nil {
+		t.Error("Stats(ID prefix) succeeded")
+	}
+	if len(engine.actions) != 0 {
+		t.Errorf("actions = %q, want none", engine.actions)
+	}
+}
+
+func TestClient_LogsAndStats(t *testing.T) {
+	_, socket := newFakeEngine(t)
+	c := NewClient(socket, []string{"media-*", "db"})
+	ctx := context.Background()
+
+	logs, err := c.Logs(ctx, "media-jellyfin", 2, time.Time{})
+	if err != nil || logs != "started\nerror: disk full\n" {
+		t.Errorf("Logs() = %q, %v", logs, err)
+	}
+	logs, err = c.Logs(ctx, "db", 2, time.Now().Add(-time.Hour))
+	if err != nil || logs != "tty line 1\ntty line 2\n" {
+		t.Errorf("Logs(tty) = %q, %v", logs, err)
+	}
+
+	stats, err := c.Stats(ctx, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Stats{CPUPercent: 80, MemoryUsage: 200, MemoryLimit: 1000, NetRx: 11, NetTx: 22, PIDs: 7}
+	if *stats != want {
+		t.Errorf("Stats() = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestClient_NoEngine(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "missing.sock"), []string{"*"})
+	_, err := c.List(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no Docker or Podman engine") {
+		t.Errorf("List() error = %v", err)
+	}
+}
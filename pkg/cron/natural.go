@@ -0,0 +1,313 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adhocore/gronx"
+)
+
+var (
+	reIn     = regexp.MustCompile(`^in (\d+|an?|one) (second|sec|minute|min|hour|hr|day|week)s?$`)
+	reEvery  = regexp.MustCompile(`^every (?:(\d+|other) )?(second|sec|minute|min|hour|hr)s?$`)
+	reEveryN = regexp.MustCompile(`^every (\d+) (day|week)s?$`)
+	reAt     = regexp.MustCompile(`^(?:(.*?) )?at (.+)$`)
+	reClock  = regexp.MustCompile(`^(\d{1,2})(?:[:.h](\d{2}))? ?(am|pm)?$`)
+	reDate   = regexp.MustCompile(`^(?:on )?(\d{4}-\d{2}-\d{2})$`)
+)
+
+var unitDurations = map[string]time.Duration{
+	"second": time.Second, "sec": time.Second,
+	"minute": time.Minute, "min": time.Minute,
+	"hour": time.Hour, "hr": time.Hour,
+	"day": 24 * time.Hour, "week": 7 * 24 * time.Hour,
+}
+
+// periodHours are the times of day "every morning" and the like mean when
+// no time is given.
+var periodHours = map[string]int{"morning": 8, "afternoon": 14, "evening": 18, "night": 21}
+
+var weekdays = map[string]int{
+	"sunday": 0, "sun": 0, "monday": 1, "mon": 1, "tuesday": 2, "tue": 2, "tues": 2,
+	"wednesday": 3, "wed": 3, "thursday": 4, "thu": 4, "thurs": 4, "friday": 5, "fri": 5,
+	"saturday": 6, "sat": 6,
+}
+
+// ParseSchedule turns a schedule written in plain English into a
+// CronSchedule. It understands forms like "in 20 minutes", "every 2 hours",
+// "every day at 8:30", "every morning at 8", "every weekday at 9am",
+// "every monday and friday at 18:00", "tomorrow at 7pm", "at noon" and
+// "2026-12-24 at 18:00", and takes cron expressions as they are. now is the
+// reference for relative times, and times of day are in its location; the
+// returned schedule's TZ is left to the caller.
+func ParseSchedule(text string, now time.Time) (CronSchedule, error) {
+	s := strings.Join(strings.Fields(strings.ToLower(strings.TrimSuffix(strings.TrimSpace(text), "."))), " ")
+	if s == "" {
+		return CronSchedule{}, fmt.Errorf("empty schedule")
+	}
+	if gronx.IsValid(s) {
+		return CronSchedule{Kind: "cron", Expr: s}, nil
+	}
+	switch s {
+	case "hourly":
+		s = "every hour"
+	case "daily":
+		s = "every day"
+	}
+	if strings.HasPrefix(s, "daily ") {
+		s = "every day " + strings.TrimPrefix(s, "daily ")
+	}
+
+	if m := reIn.FindStringSubmatch(s); m != nil {
+		at := now.Add(time.Duration(count(m[1])) * unitDurations[m[2]]).UnixMilli()
+		return CronSchedule{Kind: "at", AtMS: &at}, nil
+	}
+	if m := reEvery.FindStringSubmatch(s); m != nil {
+		every := (time.Duration(count(m[1])) * unitDurations[m[2]]).Milliseconds()
+		return CronSchedule{Kind: "every", EveryMS: &every}, nil
+	}
+	if m := reEveryN.FindStringSubmatch(s); m != nil {
+		every := (time.Duration(count(m[1])) * unitDurations[m[2]]).Milliseconds()
+		return CronSchedule{Kind: "every", EveryMS: &every}, nil
+	}
+
+	when, clock := s, ""
+	if m := reAt.FindStringSubmatch(s); m != nil {
+		when, clock = m[1], m[2]
+	}
+	recurring := false
+	for _, prefix := range []string{"every ", "each "} {
+		if rest, ok := strings.CutPrefix(when, prefix); ok {
+			when, recurring = rest, true
+		}
+	}
+
+	days, period, err := parseDays(when)
+	if err != nil {
+		return CronSchedule{}, err
+	}
+	if days.plural {
+		recurring = true
+	}
+	hour, minute := 0, 0
+	switch {
+	case clock != "":
+		if hour, minute, err = parseClock(clock); err != nil {
+			return CronSchedule{}, err
+		}
+	case period != "":
+		hour = periodHours[period]
+	default:
+		return CronSchedule{}, fmt.Errorf("can't tell when %q is; add a time, e.g. \"every day at 8:00\"", text)
+	}
+
+	if recurring {
+		if days.date != "" || days.relative != 0 {
+			return CronSchedule{}, fmt.Errorf("%q mixes a date with a repeating schedule", text)
+		}
+		dow := "*"
+		if len(days.weekdays) > 0 {
+			dow = weekdayList(days.weekdays)
+		}
+		return CronSchedule{Kind: "cron", Expr: fmt.Sprintf("%d %d * * %s", minute, hour, dow)}, nil
+	}
+
+	var at time.Time
+	switch {
+	case days.date != "":
+		d, err := time.ParseInLocation("2006-01-02", days.date, now.Location())
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("invalid date %q", days.date)
+		}
+		at = time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, now.Location())
+	case len(days.weekdays) == 1:
+		at = time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		for int(at.Weekday()) != days.weekdays[0] || !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+	case len(days.weekdays) > 1:
+		return CronSchedule{}, fmt.Errorf("%q names several days; say \"every\" for a repeating schedule", text)
+	default:
+		at = time.Date(now.Year(), now.Month(), now.Day()+days.relative, hour, minute, 0, 0, now.Location())
+		if !at.After(now) && days.relative == 0 && !days.today {
+			at = at.AddDate(0, 0, 1)
+		}
+	}
+	if !at.After(now) {
+		return CronSchedule{}, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+	}
+	atMS := at.UnixMilli()
+	return CronSchedule{Kind: "at", AtMS: &atMS}, nil
+}
+
+// daySpec is the day part of a schedule.
+type daySpec struct {
+	weekdays []int
+	plural   bool   // "mondays": every week
+	date     string // YYYY-MM-DD
+	relative int    // Days from today, for "tomorrow"
+	today    bool   // "today" was said, so a time already past is an error
+}
+
+// parseDays reads the day part of a schedule, such as "day", "weekday",
+// "monday and friday", "tomorrow" or "2026-12-24". period is set for
+// "morning" and the like, which also stand for every day.
+func parseDays(s string) (days daySpec, period string, err error) {
+	s = strings.TrimPrefix(s, "on ")
+	if m := reDate.FindStringSubmatch(s); m != nil {
+		return daySpec{date: m[1]}, "", nil
+	}
+	switch s {
+	case "", "day":
+		return daySpec{}, "", nil
+	case "today":
+		return daySpec{today: true}, "", nil
+	case "tomorrow":
+		return daySpec{relative: 1}, "", nil
+	case "weekday", "weekdays":
+		return daySpec{weekdays: []int{1, 2, 3, 4, 5}, plural: s == "weekdays"}, "", nil
+	case "weekend", "weekends":
+		return daySpec{weekdays: []int{0, 6}, plural: s == "weekends"}, "", nil
+	}
+	if _, ok := periodHours[s]; ok {
+		return daySpec{}, s, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "tomorrow "); ok {
+		if _, isPeriod := periodHours[rest]; isPeriod {
+			return daySpec{relative: 1}, rest, nil
+		}
+	}
+
+	for _, word := range strings.FieldsFunc(strings.NewReplacer(" and ", ",", "&", ",").Replace(s), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		name := word
+		if n, ok := strings.CutSuffix(word, "s"); ok {
+			if _, known := weekdays[n]; known {
+				name, days.plural = n, true
+			}
+		}
+		d, ok := weekdays[name]
+		if !ok {
+			return daySpec{}, "", fmt.Errorf("unknown day %q", word)
+		}
+		days.weekdays = append(days.weekdays, d)
+	}
+	return days, "", nil
+}
+
+// parseClock reads a time of day: "8", "8am", "8:30", "8:30 pm", "20:15",
+// "noon" or "midnight".
+func parseClock(s string) (hour, minute int, err error) {
+	switch s {
+	case "noon", "midday":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+	m := reClock.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time %q; use e.g. 8:30, 8am or 20:15", s)
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time %q", s)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour, minute, nil
+}
+
+func count(s string) int {
+	switch s {
+	case "", "a", "an", "one":
+		return 1
+	case "other":
+		return 2
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func weekdayList(days []int) string {
+	sorted := append([]int(nil), days...)
+	sort.Ints(sorted)
+	parts := make([]string, 0, len(sorted))
+	for i, d := range sorted {
+		if i > 0 && d == sorted[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.Itoa(d))
+	}
+	return strings.Join(parts, ",")
+}
+
+// DescribeSchedule writes schedule for a person, e.g. "every 2h",
+// "0 8 * * 1-5 (Europe/Paris)" or "once at 2026-10-15 18:00".
+func DescribeSchedule(schedule CronSchedule) string {
+	var s string
+	switch schedule.Kind {
+	case "every":
+		if schedule.EveryMS == nil {
+			return "every ?"
+		}
+		s = "every " + shortDuration(time.Duration(*schedule.EveryMS)*time.Millisecond)
+	case "cron":
+		s = schedule.Expr
+	case "at":
+		if schedule.AtMS == nil {
+			return "once"
+		}
+		at := time.UnixMilli(*schedule.AtMS)
+		if loc := location(schedule.TZ); loc != nil {
+			at = at.In(loc)
+		}
+		return "once at " + at.Format("2006-01-02 15:04")
+	default:
+		return schedule.Kind
+	}
+	if schedule.TZ != "" {
+		s += " (" + schedule.TZ + ")"
+	}
+	return s
+}
+
+// shortDuration writes d like time.Duration.String without trailing zero
+// units: "2h" rather than "2h0m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// location returns the time zone named tz, or nil for the local one.
+func location(tz string) *time.Location {
+	if tz == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Thursday
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	at := func(s string) string { return "at " + s }
+	tests := []struct {
+		text string
+		want string // cron expression, "every <duration>" or "at <time>"
+	}{
+		{"0 9 * * 1-5", "0 9 * * 1-5"},
+		{"every morning at 8", "0 8 * * *"},
+		{"Every day at 8:30pm.", "30 20 * * *"},
+		{"daily at 07:15", "15 7 * * *"},
+		{"every evening", "0 18 * * *"},
+		{"every weekday at 9am", "0 9 * * 1,2,3,4,5"},
+		{"on weekends at noon", "0 12 * * 0,6"},
+		{"every friday and monday at 18:00", "0 18 * * 1,5"},
+		{"on mondays at 9", "0 9 * * 1"},
+		{"every 2 hours", "every 2h0m0s"},
+		{"hourly", "every 1h0m0s"},
+		{"every 3 days", "every 72h0m0s"},
+		{"in 10 minutes", at("2026-10-15 10:10")},
+		{"in an hour", at("2026-10-15 11:00")},
+		{"at 7pm", at("2026-10-15 19:00")},
+		{"at 9", at("2026-10-16 09:00")},
+		{"tomorrow at 7", at("2026-10-16 07:00")},
+		{"tomorrow morning", at("2026-10-16 08:00")},
+		{"on monday at 8:30", at("2026-10-19 08:30")},
+		{"2026-12-24 at 18:00", at("2026-12-24 18:00")},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.text, now)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error = %v", tt.text, err)
+			continue
+		}
+		var got string
+		switch s.Kind {
+		case "cron":
+			got = s.Expr
+		case "every":
+			got = "every " + (time.Duration(*s.EveryMS) * time.Millisecond).String()
+		case "at":
+			got = at(time.UnixMilli(*s.AtMS).In(time.UTC).Format("2006-01-02 15:04"))
+		}
+		if got != tt.want {
+			t.Errorf("ParseSchedule(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "every day", "whenever", "at 25:00", "today at 9", "every tomorrow at 8", "on monday and friday at 8"} {
+		if s, err := ParseSchedule(text, now); err == nil {
+			t.Errorf("ParseSchedule(%q) = %+v, want an error", text, s)
+		}
+	}
+}
+
+func TestDescribeSchedule(t *testing.T) {
+	every := int64(90 * time.Minute / time.Millisecond)
+	at := time.Date(2026, 10, 15, 16, 0, 0, 0, time.UTC).UnixMilli()
+	tests := []struct {
+		schedule CronSchedule
+		want     string
+	}{
+		{CronSchedule{Kind: "every", EveryMS: &every}, "every 1h30m"},
+		{CronSchedule{Kind: "cron", Expr: "0 8 * * *", TZ: "Europe/Paris"}, "0 8 * * * (Europe/Paris)"},
+		{CronSchedule{Kind: "at", AtMS: &at, TZ: "Asia/Tokyo"}, "once at 2026-10-16 01:00"},
+	}
+	for _, tt := range tests {
+		if got := DescribeSchedule(tt.schedule); got != tt.want {
+			t.Errorf("DescribeSchedule(%+v) = %q, want %q", tt.schedule, got, tt.want)
+		}
+	}
+}
@@ -48,8 +48,15 @@ type CronJob struct {
 	CreatedAtMS    int64        `json:"createdAtMs"`
 	UpdatedAtMS    int64        `json:"updatedAtMs"`
 	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	CatchUp        string       `json:"catchUp,omitempty"` // CatchUpOnce or CatchUpSkip; empty uses the service's policy
 }
 
+// Catch-up policies: what happens to runs missed while picoclaw was down.
+const (
+	CatchUpOnce = "once" // Run the job once on start, however many runs were missed
+	CatchUpSkip = "skip" // Wait for the next scheduled run
+)
+
 type CronStore struct {
 	Version int       `json:"version"`
 	Jobs    []CronJob `json:"jobs"`
@@ -61,16 +68,23 @@ type CronService struct {
 	storePath string
 	store     *CronStore
 	onJob     JobHandler
+	catchUp   string // Default catch-up policy
 	mu        sync.RWMutex
 	running   bool
 	stopChan  chan struct{}
 	gronx     *gronx.Gronx
+
+	// The store file as last loaded or saved, to notice when another
+	// process (picoclaw cron, picoclaw mcp serve) changes it.
+	storeMod  time.Time
+	storeSize int64
 }
 
 func NewCronService(storePath string, onJob JobHandler) *CronService {
 	cs := &CronService{
 		storePath: storePath,
 		onJob:     onJob,
+		catchUp:   CatchUpOnce,
 		gronx:     gronx.New(),
 	}
 	// Initialize and load store on creation
@@ -138,6 +152,7 @@ func (cs *CronService) checkJobs() {
 		cs.mu.Unlock()
 		return
 	}
+	cs.syncStoreUnsafe()
 
 	now := time.Now().UnixMilli()
 	var dueJobIDs []string
@@ -243,6 +258,20 @@ func (cs *CronService) executeJobByID(jobID string) {
 }
 
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
+	return nextRun(schedule, nowMS)
+}
+
+// NextRun returns when schedule next runs after now, and false when it
+// never does again.
+func NextRun(schedule CronSchedule, now time.Time) (time.Time, bool) {
+	next := nextRun(&schedule, now.UnixMilli())
+	if next == nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(*next), true
+}
+
+func nextRun(schedule *CronSchedule, nowMS int64) *int64 {
 	if schedule.Kind == "at" {
 		if schedule.AtMS != nil && *schedule.AtMS > nowMS {
 			return schedule.AtMS
@@ -263,8 +292,11 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 			return nil
 		}
 
-		// Use gronx to calculate next run time
+		// Use gronx to calculate next run time, in the schedule's time zone
 		now := time.UnixMilli(nowMS)
+		if loc := location(schedule.TZ); loc != nil {
+			now = now.In(loc)
+		}
 		nextTime, err := gronx.NextTickAfter(schedule.Expr, now, false)
 		if err != nil {
 			log.Printf("[cron] failed to compute next run for expr '%s': %v", schedule.Expr, err)
@@ -278,16 +310,75 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 	return nil
 }
 
+// ValidateSchedule returns why schedule can't run, or nil if it can.
+func ValidateSchedule(schedule CronSchedule) error {
+	switch schedule.Kind {
+	case "at":
+		if schedule.AtMS == nil {
+			return fmt.Errorf("one-time schedule has no time")
+		}
+	case "every":
+		if schedule.EveryMS == nil || *schedule.EveryMS <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+	case "cron":
+		if !gronx.IsValid(schedule.Expr) {
+			return fmt.Errorf("invalid cron expression %q", schedule.Expr)
+		}
+	default:
+		return fmt.Errorf("unknown schedule kind %q", schedule.Kind)
+	}
+	if schedule.TZ != "" {
+		if _, err := time.LoadLocation(schedule.TZ); err != nil {
+			return fmt.Errorf("unknown time zone %q", schedule.TZ)
+		}
+	}
+	return nil
+}
+
+// recomputeNextRuns schedules the enabled jobs from now. A job whose run
+// was due while the service wasn't running keeps it, so it runs right away,
+// when its catch-up policy is CatchUpOnce; otherwise it waits for its next
+// run, and a missed one-time job is disabled.
 func (cs *CronService) recomputeNextRuns() {
 	now := time.Now().UnixMilli()
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled {
-			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if !job.Enabled {
+			continue
+		}
+		missed := job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now
+		if missed && cs.catchUpPolicy(job) == CatchUpOnce {
+			log.Printf("[cron] job %s missed a run while stopped, running it now", job.ID)
+			continue
+		}
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if missed && job.Schedule.Kind == "at" {
+			job.Enabled = false
+			job.State.LastStatus = "skipped"
+			job.State.LastError = "missed while stopped"
 		}
 	}
 }
 
+func (cs *CronService) catchUpPolicy(job *CronJob) string {
+	if job.CatchUp != "" {
+		return job.CatchUp
+	}
+	return cs.catchUp
+}
+
+// SetCatchUp sets the catch-up policy of jobs that don't have their own:
+// CatchUpOnce (the default) or CatchUpSkip. It applies from the next Start.
+func (cs *CronService) SetCatchUp(policy string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if policy == "" {
+		policy = CatchUpOnce
+	}
+	cs.catchUp = policy
+}
+
 func (cs *CronService) getNextWakeMS() *int64 {
 	var nextWake *int64
 	for _, job := range cs.store.Jobs {
@@ -318,17 +409,36 @@ func (cs *CronService) loadStore() error {
 		Jobs:    []CronJob{},
 	}
 
-	data, err := os.ReadFile(cs.storePath)
+	info, err := os.Stat(cs.storePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
 		return err
 	}
+	data, err := os.ReadFile(cs.storePath)
+	if err != nil {
+		return err
+	}
+	cs.storeMod, cs.storeSize = info.ModTime(), info.Size()
 
 	return json.Unmarshal(data, cs.store)
 }
 
+// syncStoreUnsafe reloads the store if another process changed the file
+// since it was last loaded or saved.
+func (cs *CronService) syncStoreUnsafe() {
+	info, err := os.Stat(cs.storePath)
+	if err != nil || (info.ModTime().Equal(cs.storeMod) && info.Size() == cs.storeSize) {
+		return
+	}
+	store := cs.store
+	if err := cs.loadStore(); err != nil {
+		log.Printf("[cron] failed to reload store: %v", err)
+		cs.store = store
+	}
+}
+
 func (cs *CronService) saveStoreUnsafe() error {
 	dir := filepath.Dir(cs.storePath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
@@ -340,7 +450,13 @@ func (cs *CronService) saveStoreUnsafe() error {
 		return err
 	}
 
-	return os.WriteFile(cs.storePath, data, 0o600)
+	if err := os.WriteFile(cs.storePath, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(cs.storePath); err == nil {
+		cs.storeMod, cs.storeSize = info.ModTime(), info.Size()
+	}
+	return nil
 }
 
 func (cs *CronService) AddJob(
@@ -352,6 +468,7 @@ func (cs *CronService) AddJob(
 ) (*CronJob, error) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
+	cs.syncStoreUnsafe()
 
 	now := time.Now().UnixMilli()
 
@@ -389,6 +506,7 @@ func (cs *CronService) AddJob(
 func (cs *CronService) UpdateJob(job *CronJob) error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
+	cs.syncStoreUnsafe()
 
 	for i := range cs.store.Jobs {
 		if cs.store.Jobs[i].ID == job.ID {
@@ -403,6 +521,7 @@ func (cs *CronService) UpdateJob(job *CronJob) error {
 func (cs *CronService) RemoveJob(jobID string) bool {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
+	cs.syncStoreUnsafe()
 
 	return cs.removeJobUnsafe(jobID)
 }
@@ -430,6 +549,7 @@ func (cs *CronService) removeJobUnsafe(jobID string) bool {
 func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
+	cs.syncStoreUnsafe()
 
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
@@ -454,8 +574,9 @@ func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 }
 
 func (cs *CronService) ListJobs(includeDisabled bool) []CronJob {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.syncStoreUnsafe()
 
 	if includeDisabled {
 		return cs.store.Jobs
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 )
 
 func TestSaveStore_FilePermissions(t *testing.T) {
@@ -33,6 +34,115 @@ func TestSaveStore_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestCronService_SharedStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	gateway := NewCronService(storePath, nil)
+	if err := gateway.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer gateway.Stop()
+
+	// Another process, such as picoclaw cron add, adds a job.
+	other := NewCronService(storePath, nil)
+	schedule := CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}
+	job, err := other.AddJob("test", schedule, "hello", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	// The running service picks it up instead of saving over it.
+	gateway.checkJobs()
+	if jobs := gateway.ListJobs(true); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Fatalf("running service has jobs %v, want the added one", jobs)
+	}
+
+	if !other.RemoveJob(job.ID) {
+		t.Fatal("RemoveJob failed")
+	}
+	gateway.checkJobs()
+	if jobs := gateway.ListJobs(true); len(jobs) != 0 {
+		t.Errorf("running service has jobs %v after removal", jobs)
+	}
+}
+
 func int64Ptr(v int64) *int64 {
 	return &v
 }
+
+func TestValidateSchedule(t *testing.T) {
+	every := int64(60000)
+	zero := int64(0)
+	tests := []struct {
+		schedule CronSchedule
+		valid    bool
+	}{
+		{CronSchedule{Kind: "cron", Expr: "0 9 * * 1-5"}, true},
+		{CronSchedule{Kind: "cron", Expr: "0 25 * * *"}, false},
+		{CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Europe/Nowhere"}, false},
+		{CronSchedule{Kind: "every", EveryMS: &every}, true},
+		{CronSchedule{Kind: "every", EveryMS: &zero}, false},
+		{CronSchedule{Kind: "at"}, false},
+		{CronSchedule{Kind: "weekly"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateSchedule(tt.schedule); (err == nil) != tt.valid {
+			t.Errorf("ValidateSchedule(%+v) = %v, want valid %v", tt.schedule, err, tt.valid)
+		}
+	}
+}
+
+func TestComputeNextRun_TimeZone(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	next := cs.computeNextRun(&CronSchedule{Kind: "cron", Expr: "0 8 * * *", TZ: "Asia/Tokyo"}, now.UnixMilli())
+	if next == nil {
+		t.Fatal("no next run")
+	}
+	// 08:00 in Tokyo is 23:00 UTC the day before.
+	if got := time.UnixMilli(*next).UTC(); !got.Equal(time.Date(2026, 10, 15, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("next run = %v", got)
+	}
+}
+
+func TestRecomputeNextRuns_CatchUp(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	hour := int64(3600000)
+	missed := time.Now().Add(-time.Hour).UnixMilli()
+	add := func(kind, catchUp string) string {
+		t.Helper()
+		schedule := CronSchedule{Kind: kind, EveryMS: &hour}
+		if kind == "at" {
+			schedule = CronSchedule{Kind: "at", AtMS: &missed}
+		}
+		job, err := cs.AddJob(kind+catchUp, schedule, "hi", false, "cli", "direct")
+		if err != nil {
+			t.Fatal(err)
+		}
+		job.CatchUp = catchUp
+		job.State.NextRunAtMS = &missed
+		if err := cs.UpdateJob(job); err != nil {
+			t.Fatal(err)
+		}
+		return job.ID
+	}
+	everyOnce, everySkip := add("every", ""), add("every", CatchUpSkip)
+	atOnce, atSkip := add("at", CatchUpOnce), add("at", CatchUpSkip)
+
+	cs.recomputeNextRuns()
+	jobs := map[string]CronJob{}
+	for _, j := range cs.ListJobs(true) {
+		jobs[j.ID] = j
+	}
+	if n := jobs[everyOnce].State.NextRunAtMS; n == nil || *n != missed {
+		t.Error("a missed job with catch-up \"once\" should stay due")
+	}
+	if n := jobs[atOnce].State.NextRunAtMS; n == nil || *n != missed || !jobs[atOnce].Enabled {
+		t.Error("a missed one-time job with catch-up \"once\" should stay due")
+	}
+	if n := jobs[everySkip].State.NextRunAtMS; n == nil || *n <= time.Now().UnixMilli() {
+		t.Error("a missed job with catch-up \"skip\" should wait for its next run")
+	}
+	if j := jobs[atSkip]; j.Enabled || j.State.LastStatus != "skipped" {
+		t.Errorf("a missed one-time job with catch-up \"skip\" should be disabled, got %+v", j)
+	}
+}
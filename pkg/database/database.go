@@ -0,0 +1,394 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package database runs queries on SQLite, PostgreSQL and MySQL (or
+// MariaDB) databases, with their values bound as parameters, and lists
+// their tables and columns. Databases are read-only unless writes are
+// allowed: the database itself is then asked to refuse writes, and only
+// statements that read are sent.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Database types.
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+	MySQL    = "mysql"
+)
+
+// DefaultMaxRows bounds the rows a query returns when no limit is given.
+const DefaultMaxRows = 100
+
+// Options are a database to open.
+type Options struct {
+	Name        string
+	Type        string // sqlite, postgres or mysql
+	Path        string // SQLite: the database file
+	URL         string // postgres://user@host:5432/db or mysql://user@host:3306/db
+	Password    string // Instead of one in URL
+	AllowWrites bool
+}
+
+// Result is what a statement returned: rows, or the number of rows it
+// changed.
+type Result struct {
+	Columns      []string
+	Rows         [][]any // nil, string, []byte, int64, float64 or bool
+	More         bool    // More rows were left unread
+	RowsAffected int64
+}
+
+// DB is a database queries can be run on. It is safe for concurrent use.
+type DB struct {
+	opts Options
+	sql  *sql.DB
+}
+
+// Open checks opts and returns the database; it is only connected to when
+// queried.
+func Open(opts Options) (*DB, error) {
+	var db *sql.DB
+	switch opts.Type {
+	case SQLite:
+		if opts.Path == "" {
+			return nil, fmt.Errorf("database %q: no path to the SQLite file", opts.Name)
+		}
+		// A database opened read-only can't be written to, whatever the
+		// statement; neither kind of opening creates a missing file.
+		mode := "ro"
+		if opts.AllowWrites {
+			mode = "rw"
+		}
+		dsn := "file:" + (&url.URL{Path: opts.Path}).EscapedPath() + "?mode=" + mode +
+			"&_pragma=busy_timeout(5000)"
+		if !opts.AllowWrites {
+			dsn += "&_pragma=query_only(1)"
+		}
+		var err error
+		if db, err = sql.Open("sqlite", dsn); err != nil {
+			return nil, err
+		}
+	case Postgres:
+		cfg, err := postgresConfig(opts.URL, opts.Password)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %w", opts.Name, err)
+		}
+		db = stdlib.OpenDB(*cfg)
+	case MySQL:
+		cfg, err := mysqlConfig(opts.URL, opts.Password)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %w", opts.Name, err)
+		}
+		connector, err := mysql.NewConnector(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %w", opts.Name, err)
+		}
+		db = sql.OpenDB(connector)
+	default:
+		return nil, fmt.Errorf("database %q: unknown type %q; use sqlite, postgres or mysql", opts.Name, opts.Type)
+	}
+	db.SetMaxOpenConns(2)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	return &DB{opts: opts, sql: db}, nil
+}
+
+// postgresConfig reads a postgres:// URL, which must name a user. Its
+// sslmode defaults to prefer.
+func postgresConfig(raw, password string) (*pgx.ConnConfig, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return nil, errors.New("the URL should look like postgres://user@host:5432/db")
+	}
+	if u.User.Username() == "" {
+		return nil, errors.New("no user in the URL")
+	}
+	cfg, err := pgx.ParseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		cfg.Password = password
+	}
+	if cfg.RuntimeParams["application_name"] == "" {
+		cfg.RuntimeParams["application_name"] = "picoclaw"
+	}
+	return cfg, nil
+}
+
+// mysqlConfig reads a mysql://user@host:3306/db URL. Its tls may be false,
+// preferred (the default), true or skip-verify, and socket=/path connects
+// over a unix socket; other query parameters are set as system variables.
+func mysqlConfig(raw, password string) (*mysql.Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "mysql" {
+		return nil, errors.New("the URL should look like mysql://user@host:3306/db")
+	}
+	cfg := mysql.NewConfig()
+	cfg.User = u.User.Username()
+	cfg.Passwd, _ = u.User.Password()
+	if password != "" {
+		cfg.Passwd = password
+	}
+	if cfg.User == "" {
+		return nil, errors.New("no user in the URL")
+	}
+	cfg.DBName = strings.TrimPrefix(u.Path, "/")
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "3306"
+	}
+	cfg.Net, cfg.Addr = "tcp", host+":"+port
+	cfg.TLSConfig = "preferred"
+	for key, values := range u.Query() {
+		value := values[0]
+		switch key {
+		case "tls":
+			switch value {
+			case "false", "preferred", "true", "skip-verify":
+				cfg.TLSConfig = value
+			default:
+				return nil, fmt.Errorf("unsupported tls %q; use false, preferred, true or skip-verify", value)
+			}
+		case "socket":
+			cfg.Net, cfg.Addr = "unix", value
+		default:
+			if cfg.Params == nil {
+				cfg.Params = make(map[string]string)
+			}
+			cfg.Params[key] = value
+		}
+	}
+	return cfg, nil
+}
+
+func (db *DB) Name() string {
+	return db.opts.Name
+}
+
+func (db *DB) Type() string {
+	return db.opts.Type
+}
+
+// ReadOnly reports whether only statements that read may be run.
+func (db *DB) ReadOnly() bool {
+	return !db.opts.AllowWrites
+}
+
+// Close closes the connections kept to the database.
+func (db *DB) Close() error {
+	return db.sql.Close()
+}
+
+// Query runs one statement, with args bound to its placeholders: ? for
+// SQLite and MySQL, $1, $2... for PostgreSQL. At most maxRows rows are
+// returned (DefaultMaxRows when 0 or less). On a read-only database, the
+// statement must be one that reads.
+func (db *DB) Query(ctx context.Context, query string, args []any, maxRows int) (*Result, error) {
+	if maxRows <= 0 {
+		maxRows = DefaultMaxRows
+	}
+	if db.ReadOnly() {
+		if err := CheckReadOnly(db.opts.Type, query); err != nil {
+			return nil, err
+		}
+	}
+	params := make([]any, len(args))
+	for i, arg := range args {
+		params[i] = normalizeArg(arg)
+	}
+	return db.run(ctx, query, params, maxRows, db.ReadOnly())
+}
+
+// normalizeArg turns a value decoded from JSON into one the databases
+// bind: whole numbers become integers.
+func normalizeArg(arg any) any {
+	switch v := arg.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case int:
+		return int64(v)
+	}
+	return arg
+}
+
+// Tables lists the tables and views of the database.
+func (db *DB) Tables(ctx context.Context) (*Result, error) {
+	var query string
+	switch db.opts.Type {
+	case SQLite:
+		query = `SELECT name, type FROM sqlite_master
+			WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY name`
+	case Postgres:
+		query = `SELECT table_schema AS schema, table_name AS name, table_type AS type
+			FROM information_schema.tables
+			WHERE table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY 1, 2`
+	case MySQL:
+		query = `SELECT table_name AS name, table_type AS type FROM information_schema.tables
+			WHERE table_schema = DATABASE() ORDER BY 1`
+	}
+	return db.run(ctx, query, nil, 10000, true)
+}
+
+// Describe lists the columns of a table or view: their names, types,
+// whether they may be NULL, their defaults and whether they are in the
+// primary key. A PostgreSQL table may be given as schema.table.
+func (db *DB) Describe(ctx context.Context, table string) (*Result, error) {
+	if table == "" {
+		return nil, errors.New("no table given")
+	}
+	var query string
+	args := []any{table}
+	switch db.opts.Type {
+	case SQLite:
+		query = `SELECT name, type, CASE WHEN "notnull" THEN 'NO' ELSE 'YES' END AS nullable,
+			dflt_value AS "default", CASE WHEN pk > 0 THEN 'PRI' ELSE '' END AS "key"
+			FROM pragma_table_info(?) ORDER BY cid`
+	case Postgres:
+		where := "c.table_schema = ANY (current_schemas(false)) AND c.table_name = $1"
+		if schema, name, ok := strings.Cut(table, "."); ok {
+			where = "c.table_schema = $1 AND c.table_name = $2"
+			args = []any{schema, name}
+		}
+		query = `SELECT c.column_name AS name, c.data_type AS type, c.is_nullable AS nullable,
+			c.column_default AS "default",
+			CASE WHEN EXISTS (SELECT 1 FROM information_schema.table_constraints t
+				JOIN information_schema.key_column_usage k
+					ON k.constraint_schema = t.constraint_schema AND k.constraint_name = t.constraint_name
+				WHERE t.constraint_type = 'PRIMARY KEY' AND t.table_schema = c.table_schema
+					AND t.table_name = c.table_name AND k.column_name = c.column_name)
+			THEN 'PRI' ELSE '' END AS key
+			FROM information_schema.columns c WHERE ` + where + `
+			ORDER BY c.table_schema, c.ordinal_position`
+	case MySQL:
+		query = `SELECT column_name AS name, column_type AS type, is_nullable AS nullable,
+			column_default AS ` + "`default`" + `, column_key AS ` + "`key`" + `
+			FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`
+	}
+	result, err := db.run(ctx, query, args, 10000, true)
+	if err == nil && len(result.Rows) == 0 {
+		return nil, fmt.Errorf("no table or view named %q", table)
+	}
+	return result, err
+}
+
+// run runs a statement. When readOnly is set, PostgreSQL and MySQL run it
+// in a read-only transaction, rolled back after; SQLite databases are
+// opened read-only instead.
+func (db *DB) run(ctx context.Context, query string, args []any, maxRows int, readOnly bool) (*Result, error) {
+	conn, err := db.sql.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var q interface {
+		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	} = conn
+	if readOnly && db.opts.Type != SQLite {
+		tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		q = tx
+	}
+
+	if !returnsRows(db.opts.Type, query) {
+		res, err := q.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := res.RowsAffected()
+		return &Result{RowsAffected: n}, nil
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Columns: columns}
+	for rows.Next() {
+		if len(result.Rows) == maxRows {
+			result.More = true
+			break
+		}
+		row := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range row {
+			row[i] = cellValue(v, types[i])
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// returnsRows reports whether query is a statement that returns rows,
+// rather than a count of the rows it changed.
+func returnsRows(dbType, query string) bool {
+	keyword, _, err := firstKeyword(dbType, query)
+	if err != nil || readKeywords[keyword] || keyword == "pragma" || keyword == "call" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(query), "returning")
+}
+
+// cellValue turns a scanned value into one of the types of Result.Rows:
+// times become strings, as do bytes of columns that aren't binary.
+func cellValue(v any, column *sql.ColumnType) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999Z07:00")
+	case []byte:
+		name := strings.ToUpper(column.DatabaseTypeName())
+		if !strings.Contains(name, "BLOB") && !strings.Contains(name, "BINARY") && name != "BYTEA" && utf8.Valid(v) {
+			return string(v)
+		}
+		return v
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
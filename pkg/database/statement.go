@@ -0,0 +1,144 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// readKeywords are the first words of statements that only read.
+var readKeywords = map[string]bool{
+	"select":   true,
+	"with":     true, // Writes in a WITH are refused by the read-only transaction
+	"values":   true,
+	"table":    true,
+	"show":     true,
+	"explain":  true,
+	"describe": true,
+	"desc":     true,
+}
+
+// CheckReadOnly returns why query can't be run on a read-only database of
+// type dbType, or nil: it must be a single statement that reads, such as a
+// SELECT. The database refuses writes too; this keeps other statements
+// from being sent at all.
+func CheckReadOnly(dbType, query string) error {
+	keyword, rest, err := firstKeyword(dbType, query)
+	if err != nil {
+		return err
+	}
+	if keyword == "" {
+		return errors.New("the query is empty")
+	}
+	if !readKeywords[keyword] {
+		return fmt.Errorf("the database is read-only, and %s statements may write; only SELECT, WITH, "+
+			"SHOW and EXPLAIN statements can be run", strings.ToUpper(keyword))
+	}
+	if rest != "" {
+		return errors.New("only one statement can be run at a time")
+	}
+	return nil
+}
+
+// firstKeyword returns the first word of query, lowercased, and what
+// follows the end of its first statement, skipping comments and quoted
+// text.
+func firstKeyword(dbType, query string) (keyword, rest string, err error) {
+	s := query
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '-' && strings.HasPrefix(s[i:], "--"), c == '#' && dbType == MySQL:
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return keyword, "", nil
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			if dbType == MySQL && (strings.HasPrefix(s[i:], "/*!") || strings.HasPrefix(s[i:], "/*+")) {
+				// MySQL runs what is in these comments.
+				return "", "", errors.New("MySQL executable comments (/*! ... */) aren't allowed")
+			}
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return "", "", errors.New("a comment is not closed")
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(s[i+1:], c, dbType == MySQL)
+			if end < 0 {
+				return "", "", errors.New("a quote is not closed")
+			}
+			i += end + 2
+		case c == '$' && dbType == Postgres:
+			if tag, ok := dollarTag(s[i:]); ok {
+				end := strings.Index(s[i+len(tag):], tag)
+				if end < 0 {
+					return "", "", errors.New("a dollar-quoted string is not closed")
+				}
+				i += len(tag) + end + len(tag)
+			} else {
+				i++
+			}
+		case c == ';':
+			return keyword, strings.TrimSpace(strings.TrimLeft(s[i:], "; \t\r\n")), nil
+		case keyword == "" && isWordByte(c):
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			keyword = strings.ToLower(s[i:j])
+			i = j
+		default:
+			i++
+		}
+	}
+	return keyword, "", nil
+}
+
+// closingQuote returns the index in s of the quote closing one opened
+// with q, or -1. A doubled quote stands for itself, as does one after a
+// backslash in MySQL.
+func closingQuote(s string, q byte, backslash bool) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case q:
+			if i+1 < len(s) && s[i+1] == q {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// dollarTag returns the $tag$ opening a PostgreSQL dollar-quoted string
+// at the start of s.
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1], true
+		case c >= '0' && c <= '9' && i == 1: // $1 is a parameter
+			return "", false
+		case !isWordByte(c):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
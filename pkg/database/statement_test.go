@@ -0,0 +1,37 @@
+package database
+
+import "testing"
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		dbType, query string
+		ok            bool
+	}{
+		{SQLite, "SELECT * FROM t", true},
+		{SQLite, "  -- recent orders\n  select * from orders;  ", true},
+		{Postgres, "(SELECT 1) UNION (SELECT 2)", true},
+		{Postgres, "WITH recent AS (SELECT * FROM orders) SELECT count(*) FROM recent", true},
+		{MySQL, "SHOW TABLES", true},
+		{Postgres, "EXPLAIN SELECT * FROM t", true},
+		{SQLite, "SELECT 'a;b', \"c;d\" FROM t", true},
+		{Postgres, "SELECT $$;DROP TABLE t$$, $1", true},
+		{MySQL, `SELECT 'it\'s; fine'`, true},
+		{SQLite, "/* note */ SELECT 1", true},
+		{SQLite, "", false},
+		{SQLite, "-- only a comment", false},
+		{SQLite, "DELETE FROM t", false},
+		{Postgres, "update t set x = 1", false},
+		{SQLite, "SELECT 1; DROP TABLE t", false},
+		{Postgres, "SELECT $tag$x$tag$; DROP TABLE t", false},
+		{MySQL, "/*!DELETE FROM t*/ SELECT 1", false},
+		{SQLite, "PRAGMA writable_schema = 1", false},
+		{SQLite, "ATTACH DATABASE '/tmp/x.db' AS x", false},
+		{SQLite, "SELECT 'unclosed", false},
+	}
+	for _, tt := range tests {
+		err := CheckReadOnly(tt.dbType, tt.query)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckReadOnly(%s, %q) = %v, want ok %v", tt.dbType, tt.query, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package desktop reads and sets the clipboard and posts notifications on
+// the desktop picoclaw runs on, with the commands each system comes with:
+// pbpaste, pbcopy and osascript on macOS, PowerShell on Windows, and
+// wl-clipboard, xclip or xsel and notify-send on Linux.
+package desktop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Available reports whether picoclaw runs in a desktop session: always on
+// macOS and Windows, and on Linux when a Wayland or X11 display is set, so
+// not on a headless board or over ssh.
+func Available() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	}
+	return os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") != ""
+}
+
+// clipboardCommand is a pair of commands reading and setting the clipboard.
+type clipboardCommand struct {
+	read  []string
+	write []string // Takes the text on stdin
+}
+
+// clipboardCommands returns the clipboard commands to try, in order.
+func clipboardCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{{read: []string{"pbpaste"}, write: []string{"pbcopy"}}}
+	case "windows":
+		powershell := []string{"powershell", "-NoProfile", "-NonInteractive", "-Command"}
+		return []clipboardCommand{{
+			read:  slices.Concat(powershell, []string{"Get-Clipboard -Raw"}),
+			write: slices.Concat(powershell, []string{"Set-Clipboard -Value ([Console]::In.ReadToEnd())"}),
+		}}
+	}
+	x11 := []clipboardCommand{
+		{read: []string{"xclip", "-selection", "clipboard", "-o"}, write: []string{"xclip", "-selection", "clipboard"}},
+		{read: []string{"xsel", "--clipboard", "--output"}, write: []string{"xsel", "--clipboard", "--input"}},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return x11
+	}
+	wayland := clipboardCommand{
+		read:  []string{"wl-paste", "--no-newline", "--type", "text"},
+		write: []string{"wl-copy"},
+	}
+	// X11 tools still reach the clipboard of XWayland apps.
+	return append([]clipboardCommand{wayland}, x11...)
+}
+
+// findClipboard returns the first clipboard commands installed.
+func findClipboard() (clipboardCommand, error) {
+	commands := clipboardCommands()
+	for _, c := range commands {
+		if _, err := exec.LookPath(c.read[0]); err == nil {
+			return c, nil
+		}
+	}
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.read[0]
+	}
+	return clipboardCommand{}, fmt.Errorf("no clipboard command found; install %s", strings.Join(names, " or "))
+}
+
+// ReadClipboard returns the text on the clipboard.
+func ReadClipboard(ctx context.Context) (string, error) {
+	c, err := findClipboard()
+	if err != nil {
+		return "", err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.read[0], c.read[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", c.read[0], msg)
+		}
+		return "", fmt.Errorf("%s: %w", c.read[0], err)
+	}
+	if !utf8.Valid(out) {
+		return "", errors.New("the clipboard holds something other than text")
+	}
+	return string(out), nil
+}
+
+// WriteClipboard puts text on the clipboard.
+func WriteClipboard(ctx context.Context, text string) error {
+	c, err := findClipboard()
+	if err != nil {
+		return err
+	}
+	// wl-copy, xclip and xsel stay in the background to serve the
+	// clipboard, keeping open any output pipe, so their output is dropped
+	// rather than waited for.
+	cmd := exec.CommandContext(ctx, c.write[0], c.write[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", c.write[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package desktop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeCommands puts scripts with the given names and bodies first on the
+// PATH, and nothing else.
+func fakeCommands(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("the commands faked are Linux ones")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func TestAvailable(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux depends on the display")
+	}
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if Available() {
+		t.Error("Available() = true without a display")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if !Available() {
+		t.Error("Available() = false with a Wayland display")
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	dir := fakeCommands(t, map[string]string{
+		"wl-paste": `printf 'copied\ntext'`,
+		"wl-copy":  `exec /bin/cat > "$0.in"`,
+		"xclip":    `echo xclip`,
+	})
+	ctx := context.Background()
+
+	text, err := ReadClipboard(ctx)
+	if err != nil || text != "copied\ntext" {
+		t.Errorf("ReadClipboard() = %q, %v", text, err)
+	}
+	if err := WriteClipboard(ctx, "new text"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "wl-copy.in")); string(data) != "new text" {
+		t.Errorf("wl-copy got %q", data)
+	}
+
+	// Without Wayland, X11 tools are used.
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if text, err := ReadClipboard(ctx); err != nil || text != "xclip\n" {
+		t.Errorf("ReadClipboard() on X11 = %q, %v", text, err)
+	}
+}
+
+func TestClipboard_Errors(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	fakeCommands(t, nil)
+	ctx := context.Background()
+	if _, err := ReadClipboard(ctx); err == nil || !strings.Contains(err.Error(), "install wl-paste or xclip or xsel") {
+		t.Errorf("ReadClipboard() without commands: %v", err)
+	}
+
+	fakeCommands(t, map[string]string{"wl-paste": "echo 'Nothing is copied' >&2; exit 1"})
+	if _, err := ReadClipboard(ctx); err == nil || err.Error() != "wl-paste: Nothing is copied" {
+		t.Errorf("ReadClipboard() of an empty clipboard: %v", err)
+	}
+
+	fakeCommands(t, map[string]string{"wl-paste": `printf '\211PNG\377'`})
+	if _, err := ReadClipboard(ctx); err == nil || !strings.Contains(err.Error(), "other than text") {
+		t.Errorf("ReadClipboard() of an image: %v", err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	dir := fakeCommands(t, map[string]string{
+		"notify-send": `for a in "$@"; do echo "$a"; done > "$0.args"`,
+	})
+
+	err := Notify(context.Background(), Notification{Title: "Tea", Message: "--is ready"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notify-send.args"))
+	want := "--app-name=picoclaw\n--urgency=normal\n--\nTea\n--is ready\n"
+	if string(data) != want {
+		t.Errorf("notify-send args = %q, want %q", data, want)
+	}
+
+	fakeCommands(t, nil)
+	if err := Notify(context.Background(), Notification{Title: "Tea"}); err == nil {
+		t.Error("Notify() without notify-send succeeded")
+	}
+}
@@ -0,0 +1,65 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package desktop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Urgencies of notifications.
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical" // Stays on screen until dismissed, where the desktop allows
+)
+
+// Notification is a desktop notification.
+type Notification struct {
+	Title   string
+	Message string
+	Urgency string // low, normal (the default) or critical; Linux only
+}
+
+// Notify posts a notification.
+func Notify(ctx context.Context, n Notification) error {
+	if n.Urgency == "" {
+		n.Urgency = UrgencyNormal
+	}
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		// The texts are passed as arguments, so they are never read as
+		// AppleScript.
+		args = []string{"osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			n.Title, n.Message}
+	case "windows":
+		return errors.New("desktop notifications are not supported on Windows")
+	default:
+		args = []string{"notify-send", "--app-name=picoclaw", "--urgency=" + n.Urgency, "--", n.Title, n.Message}
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("%s not found; it is needed to post notifications", args[0])
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("%s: %s", args[0], msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
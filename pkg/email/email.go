@@ -0,0 +1,269 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package email reads mail over IMAP and sends it over SMTP.
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+)
+
+const (
+	// DefaultIMAPPort is IMAP over TLS.
+	DefaultIMAPPort = 993
+	// DefaultSMTPPort is mail submission, secured with STARTTLS.
+	DefaultSMTPPort = 587
+
+	// smtpsPort is mail submission over TLS.
+	smtpsPort = 465
+
+	// dialTimeout bounds connecting and each exchange without a deadline.
+	dialTimeout = time.Minute
+)
+
+// ErrNotFound means there is no message with the UID given.
+var ErrNotFound = errors.New("no such message")
+
+// Account is a mailbox and the servers it is reached through. IMAP on port
+// 993 and SMTP on port 465 use TLS from the start; other ports switch to TLS
+// with STARTTLS, which only servers on the loopback interface, such as a
+// local bridge, may lack. IMAP to those goes without TLS.
+type Account struct {
+	Address  string // The address mail is sent from
+	Username string // Default: Address
+	Password string
+	IMAPHost string
+	IMAPPort int
+	SMTPHost string
+	SMTPPort int
+}
+
+func (a Account) username() string {
+	if a.Username != "" {
+		return a.Username
+	}
+	return a.Address
+}
+
+// Query selects messages in a folder. Empty fields match everything.
+type Query struct {
+	Text    string // In the headers or the body
+	From    string
+	Subject string
+	Since   time.Time // Received on this day or later
+	Unseen  bool
+}
+
+// Summary is what a search shows of a message.
+type Summary struct {
+	UID     uint32
+	From    string
+	To      string
+	Subject string
+	Date    time.Time
+	Size    int
+	Seen    bool
+}
+
+// Mailbox is a mail account that can be searched, read and sent from.
+type Mailbox interface {
+	// Folders returns the names of the account's folders.
+	Folders(ctx context.Context) ([]string, error)
+	// Search returns the newest limit messages in folder matching q, the
+	// newest first.
+	Search(ctx context.Context, folder string, q Query, limit int) ([]Summary, error)
+	// Read returns the message with uid in folder, without marking it read.
+	Read(ctx context.Context, folder string, uid uint32) (*Message, error)
+	// Unseen returns the unread messages in folder with a UID above after,
+	// received since since, without marking them read.
+	Unseen(ctx context.Context, folder string, after uint32, since time.Time) ([]*Message, error)
+	// MarkSeen marks the messages with uids in folder read.
+	MarkSeen(ctx context.Context, folder string, uids []uint32) error
+	// Send sends out from the account's address and returns its Message-ID.
+	Send(ctx context.Context, out Outgoing) (string, error)
+}
+
+// Client is the Mailbox of an Account. Each call opens its own connection.
+type Client struct {
+	account Account
+	now     func() time.Time
+}
+
+// NewClient returns the mailbox of account, filling in the default ports.
+func NewClient(account Account) *Client {
+	if account.IMAPPort == 0 {
+		account.IMAPPort = DefaultIMAPPort
+	}
+	if account.SMTPPort == 0 {
+		account.SMTPPort = DefaultSMTPPort
+	}
+	return &Client{account: account, now: time.Now}
+}
+
+func (c *Client) Folders(ctx context.Context) ([]string, error) {
+	conn, err := c.imap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+	return conn.list()
+}
+
+func (c *Client) Search(ctx context.Context, folder string, q Query, limit int) ([]Summary, error) {
+	conn, err := c.imap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+	if err := conn.selectFolder(folder, true); err != nil {
+		return nil, err
+	}
+	uids, err := conn.search(searchCriteria(q))
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(uids) > limit {
+		uids = uids[len(uids)-limit:]
+	}
+	summaries, err := conn.fetchSummaries(uids)
+	if err != nil {
+		return nil, err
+	}
+	// Newest first
+	for i, j := 0, len(summaries)-1; i < j; i, j = i+1, j-1 {
+		summaries[i], summaries[j] = summaries[j], summaries[i]
+	}
+	return summaries, nil
+}
+
+func (c *Client) Read(ctx context.Context, folder string, uid uint32) (*Message, error) {
+	conn, err := c.imap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+	if err := conn.selectFolder(folder, true); err != nil {
+		return nil, err
+	}
+	messages, err := conn.fetchMessages([]uint32{uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrNotFound
+	}
+	messages[0].Folder = folder
+	return messages[0], nil
+}
+
+func (c *Client) Unseen(ctx context.Context, folder string, after uint32, since time.Time) ([]*Message, error) {
+	conn, err := c.imap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+	if err := conn.selectFolder(folder, true); err != nil {
+		return nil, err
+	}
+	criteria := searchCriteria(Query{Unseen: true, Since: since})
+	// A Stop of 0 is "*".
+	criteria.UID = []imap.UIDSet{{{Start: imap.UID(after + 1)}}}
+	uids, err := conn.search(criteria)
+	if err != nil {
+		return nil, err
+	}
+	// n:* always matches the last message, even below n.
+	newer := uids[:0]
+	for _, uid := range uids {
+		if uid > after {
+			newer = append(newer, uid)
+		}
+	}
+	messages, err := conn.fetchMessages(newer)
+	for _, m := range messages {
+		m.Folder = folder
+	}
+	return messages, err
+}
+
+func (c *Client) MarkSeen(ctx context.Context, folder string, uids []uint32) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	conn, err := c.imap(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.logout()
+	if err := conn.selectFolder(folder, false); err != nil {
+		return err
+	}
+	return conn.markSeen(uids)
+}
+
+// imap connects and logs in to the IMAP server.
+func (c *Client) imap(ctx context.Context) (*imapConn, error) {
+	a := c.account
+	if a.IMAPHost == "" {
+		return nil, errors.New("no IMAP server set")
+	}
+	conn, err := dial(ctx, a.IMAPHost, a.IMAPPort, a.IMAPPort == DefaultIMAPPort)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", a.IMAPHost, err)
+	}
+	ic, err := newIMAPConn(conn, a.IMAPHost)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := ic.login(a.username(), a.Password); err != nil {
+		ic.close()
+		return nil, err
+	}
+	return ic, nil
+}
+
+// dial connects to host:port, with TLS from the start when implicitTLS is
+// set. The connection's deadline is ctx's, or dialTimeout.
+func dial(ctx context.Context, host string, port int, implicitTLS bool) (net.Conn, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+// isLoopback reports whether host is this machine, where a connection
+// without TLS can't be overheard.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
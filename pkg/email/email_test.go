@@ -0,0 +1,226 @@
+package email
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-imap/v2/imapserver"
+	"github.com/emersion/go-imap/v2/imapserver/imapmemserver"
+)
+
+// fakeIMAP serves a mailbox over IMAP without TLS, on the loopback
+// interface, and records the calls that could change it.
+type fakeIMAP struct {
+	user    *imapmemserver.User
+	mu      sync.Mutex
+	changes []string
+}
+
+func (f *fakeIMAP) serve(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	memServer := imapmemserver.New()
+	memServer.AddUser(f.user)
+	server := imapserver.New(&imapserver.Options{
+		NewSession: func(*imapserver.Conn) (imapserver.Session, *imapserver.GreetingData, error) {
+			return &fakeSession{Session: memServer.NewSession(), fake: f}, nil, nil
+		},
+		InsecureAuth: true,
+	})
+	t.Cleanup(func() { server.Close() })
+	go server.Serve(ln)
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeIMAP) record(change string) {
+	f.mu.Lock()
+	f.changes = append(f.changes, change)
+	f.mu.Unlock()
+}
+
+// fakeSession adds a folder that can't be selected, as Gmail has, to the
+// in-memory server's.
+type fakeSession struct {
+	imapserver.Session
+	fake *fakeIMAP
+}
+
+func (s *fakeSession) List(w *imapserver.ListWriter, ref string, patterns []string, options *imap.ListOptions) error {
+	err := w.WriteList(&imap.ListData{
+		Attrs:   []imap.MailboxAttr{imap.MailboxAttrNoSelect, imap.MailboxAttrHasChildren},
+		Delim:   '/',
+		Mailbox: "[Gmail]",
+	})
+	if err != nil {
+		return err
+	}
+	return s.Session.List(w, ref, patterns, options)
+}
+
+func (s *fakeSession) Select(mailbox string, options *imap.SelectOptions) (*imap.SelectData, error) {
+	if !options.ReadOnly {
+		s.fake.record("SELECT " + mailbox)
+	}
+	return s.Session.Select(mailbox, options)
+}
+
+func (s *fakeSession) Store(
+	w *imapserver.FetchWriter, numSet imap.NumSet, flags *imap.StoreFlags, options *imap.StoreOptions,
+) error {
+	s.fake.record("STORE")
+	return s.Session.Store(w, numSet, flags, options)
+}
+
+func testMessage(from, subject, body string) string {
+	return "From: " + from + "\r\nTo: me@example.com\r\nSubject: " + subject +
+		"\r\nDate: Thu, 15 Oct 2026 09:30:00 +0200\r\nMessage-ID: <" + subject + "@example.com>\r\n\r\n" + body + "\r\n"
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeIMAP) {
+	t.Helper()
+	user := imapmemserver.NewUser("me@example.com", `p"ss`)
+	for _, folder := range []string{"INBOX", "[Gmail]/Sent Mail", "Reçus"} {
+		if err := user.Create(folder, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, m := range []struct {
+		raw  string
+		seen bool
+	}{
+		{testMessage("Ann <ann@example.com>", "Lunch", "Noon at the usual place?"), true},
+		{testMessage("Bob <bob@example.com>", "Invoice", "Attached."), false},
+		{testMessage("=?utf-8?q?Bob_M=C3=BCller?= <bob@example.com>", "Re: Invoice", "Paid, thanks."), false},
+	} {
+		var flags []imap.Flag
+		if m.seen {
+			flags = []imap.Flag{imap.FlagSeen}
+		}
+		if _, err := user.Append("INBOX", strings.NewReader(m.raw), &imap.AppendOptions{Flags: flags}); err != nil {
+			t.Fatalf("append message %d: %v", i+1, err)
+		}
+	}
+	fake := &fakeIMAP{user: user}
+	port := fake.serve(t)
+	return NewClient(Account{
+		Address: "me@example.com", Password: `p"ss`, IMAPHost: "127.0.0.1", IMAPPort: port,
+	}), fake
+}
+
+func TestClient_FoldersSearchRead(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	folders, err := client.Folders(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(folders, "|") != "INBOX|Reçus|[Gmail]/Sent Mail" {
+		t.Errorf("folders = %q", folders)
+	}
+
+	summaries, err := client.Search(ctx, "INBOX", Query{From: "bob"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].UID != 3 || summaries[0].From != "Bob Müller <bob@example.com>" ||
+		summaries[1].Subject != "Invoice" || summaries[1].Seen {
+		t.Fatalf("summaries = %+v", summaries)
+	}
+
+	summaries, _ = client.Search(ctx, "INBOX", Query{}, 1)
+	if len(summaries) != 1 || summaries[0].UID != 3 {
+		t.Errorf("limited summaries = %+v", summaries)
+	}
+
+	m, err := client.Read(ctx, "INBOX", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Subject != "Lunch" || m.Text != "Noon at the usual place?" || m.FromAddress != "ann@example.com" ||
+		!m.Seen || m.Folder != "INBOX" {
+		t.Errorf("message = %+v", m)
+	}
+	if _, err := client.Read(ctx, "INBOX", 9); err != ErrNotFound {
+		t.Errorf("Read(9) = %v, want ErrNotFound", err)
+	}
+
+	// Reading doesn't change flags.
+	if len(fake.changes) > 0 {
+		t.Errorf("read-only calls made %q", fake.changes)
+	}
+}
+
+func TestClient_UnseenAndMarkSeen(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	messages, err := client.Unseen(ctx, "INBOX", 2, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].UID != 3 {
+		t.Fatalf("unseen = %+v", messages)
+	}
+	if err := client.MarkSeen(ctx, "INBOX", []uint32{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := fake.user.Status("INBOX", &imap.StatusOptions{NumUnseen: true})
+	if err != nil || *status.NumUnseen != 0 {
+		t.Errorf("unseen messages after marking = %v (%v)", *status.NumUnseen, err)
+	}
+	if messages, _ := client.Unseen(ctx, "INBOX", 0, time.Time{}); len(messages) != 0 {
+		t.Errorf("unseen after marking = %+v", messages)
+	}
+}
+
+func TestClient_LoginRefused(t *testing.T) {
+	client, _ := newTestClient(t)
+	client.account.Password = "wrong"
+	_, err := client.Folders(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Authentication failed") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestClient_RefusesPlainTextToRemoteServer(t *testing.T) {
+	client, _ := newTestClient(t)
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(client.account.IMAPPort)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := newIMAPConn(conn, "imap.example.com"); err == nil || !strings.Contains(err.Error(), "no TLS") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSearchCriteria(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	got := searchCriteria(Query{Unseen: true, From: "ann", Subject: `say "hi"`, Text: "lunch", Since: since})
+	want := &imap.SearchCriteria{
+		Since:   since,
+		NotFlag: []imap.Flag{imap.FlagSeen},
+		Header: []imap.SearchCriteriaHeaderField{
+			{Key: "From", Value: "ann"},
+			{Key: "Subject", Value: `say "hi"`},
+		},
+		Text: []string{"lunch"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchCriteria = %+v, want %+v", got, want)
+	}
+	if got := searchCriteria(Query{}); !reflect.DeepEqual(got, &imap.SearchCriteria{}) {
+		t.Errorf("searchCriteria of an empty query = %+v, want all messages", got)
+	}
+}
@@ -0,0 +1,214 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"slices"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-imap/v2/imapclient"
+)
+
+// summaryHeader is the part of a message a search shows.
+var summaryHeader = &imap.FetchItemBodySection{
+	Specifier:    imap.PartSpecifierHeader,
+	HeaderFields: []string{"From", "To", "Subject", "Date"},
+	Peek:         true,
+}
+
+// wholeMessage is a message as it was received.
+var wholeMessage = &imap.FetchItemBodySection{Peek: true}
+
+// imapConn is a logged-in IMAP session, of the few commands PicoClaw needs.
+type imapConn struct {
+	client *imapclient.Client
+}
+
+// newIMAPConn starts a session on conn. A connection without TLS is
+// switched to TLS with STARTTLS, unless it's to this machine.
+func newIMAPConn(conn net.Conn, host string) (*imapConn, error) {
+	if _, ok := conn.(*tls.Conn); ok || isLoopback(host) {
+		client := imapclient.New(conn, nil)
+		if err := client.WaitGreeting(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("IMAP greeting: %w", err)
+		}
+		return &imapConn{client: client}, nil
+	}
+	client, err := imapclient.NewStartTLS(conn, &imapclient.Options{TLSConfig: &tls.Config{ServerName: host}})
+	if err != nil {
+		return nil, fmt.Errorf("no TLS with the IMAP server at %s (%w); use port %d", host, err, DefaultIMAPPort)
+	}
+	return &imapConn{client: client}, nil
+}
+
+func (c *imapConn) login(username, password string) error {
+	if err := c.client.Login(username, password).Wait(); err != nil {
+		return fmt.Errorf("IMAP login failed: %w", err)
+	}
+	return nil
+}
+
+// logout ends the session, ignoring errors: the work is done.
+func (c *imapConn) logout() {
+	c.client.Logout().Wait()
+	c.close()
+}
+
+func (c *imapConn) close() {
+	c.client.Close()
+}
+
+// list returns the names of the selectable folders.
+func (c *imapConn) list() ([]string, error) {
+	mailboxes, err := c.client.List("", "*", nil).Collect()
+	if err != nil {
+		return nil, err
+	}
+	var folders []string
+	for _, m := range mailboxes {
+		if !slices.Contains(m.Attrs, imap.MailboxAttrNoSelect) {
+			folders = append(folders, m.Mailbox)
+		}
+	}
+	return folders, nil
+}
+
+// selectFolder opens folder, read-only when readOnly is set.
+func (c *imapConn) selectFolder(folder string, readOnly bool) error {
+	if _, err := c.client.Select(folder, &imap.SelectOptions{ReadOnly: readOnly}).Wait(); err != nil {
+		return fmt.Errorf("open folder %q: %w", folder, err)
+	}
+	return nil
+}
+
+// search returns the UIDs of the messages matching criteria, ascending.
+func (c *imapConn) search(criteria *imap.SearchCriteria) ([]uint32, error) {
+	data, err := c.client.UIDSearch(criteria, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	var uids []uint32
+	for _, uid := range data.AllUIDs() {
+		uids = append(uids, uint32(uid))
+	}
+	slices.Sort(uids)
+	return uids, nil
+}
+
+// fetchSummaries returns the summaries of the messages with uids, in the
+// order of uids.
+func (c *imapConn) fetchSummaries(uids []uint32) ([]Summary, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	fetched, err := c.client.Fetch(uidSet(uids), &imap.FetchOptions{
+		UID:         true,
+		Flags:       true,
+		RFC822Size:  true,
+		BodySection: []*imap.FetchItemBodySection{summaryHeader},
+	}).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	byUID := map[uint32]Summary{}
+	for _, f := range fetched {
+		s := Summary{UID: uint32(f.UID), Size: int(f.RFC822Size), Seen: slices.Contains(f.Flags, imap.FlagSeen)}
+		if header := f.FindBodySection(summaryHeader); header != nil {
+			if m, err := mail.ReadMessage(bytes.NewReader(append(header, "\r\n"...))); err == nil {
+				s.From = decodeHeader(m.Header.Get("From"))
+				s.To = decodeHeader(m.Header.Get("To"))
+				s.Subject = decodeHeader(m.Header.Get("Subject"))
+				s.Date, _ = m.Header.Date()
+			}
+		}
+		byUID[s.UID] = s
+	}
+	var summaries []Summary
+	for _, uid := range uids {
+		if s, ok := byUID[uid]; ok {
+			summaries = append(summaries, s)
+		}
+	}
+	return summaries, nil
+}
+
+// fetchMessages returns the messages with uids, parsed, in the order of
+// uids. Messages that can't be parsed are skipped.
+func (c *imapConn) fetchMessages(uids []uint32) ([]*Message, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	fetched, err := c.client.Fetch(uidSet(uids), &imap.FetchOptions{
+		UID:         true,
+		Flags:       true,
+		BodySection: []*imap.FetchItemBodySection{wholeMessage},
+	}).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	byUID := map[uint32]*Message{}
+	for _, f := range fetched {
+		raw := f.FindBodySection(wholeMessage)
+		if raw == nil {
+			continue
+		}
+		m, err := ParseMessage(raw)
+		if err != nil {
+			continue
+		}
+		m.UID = uint32(f.UID)
+		m.Seen = slices.Contains(f.Flags, imap.FlagSeen)
+		byUID[m.UID] = m
+	}
+	var messages []*Message
+	for _, uid := range uids {
+		if m, ok := byUID[uid]; ok {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// markSeen marks the messages with uids in the selected folder read.
+func (c *imapConn) markSeen(uids []uint32) error {
+	return c.client.Store(uidSet(uids), &imap.StoreFlags{
+		Op:     imap.StoreFlagsAdd,
+		Silent: true,
+		Flags:  []imap.Flag{imap.FlagSeen},
+	}, nil).Close()
+}
+
+// searchCriteria turns q into IMAP search criteria.
+func searchCriteria(q Query) *imap.SearchCriteria {
+	criteria := &imap.SearchCriteria{Since: q.Since}
+	if q.Unseen {
+		criteria.NotFlag = []imap.Flag{imap.FlagSeen}
+	}
+	if q.From != "" {
+		criteria.Header = append(criteria.Header, imap.SearchCriteriaHeaderField{Key: "From", Value: q.From})
+	}
+	if q.Subject != "" {
+		criteria.Header = append(criteria.Header, imap.SearchCriteriaHeaderField{Key: "Subject", Value: q.Subject})
+	}
+	if q.Text != "" {
+		criteria.Text = []string{q.Text}
+	}
+	return criteria
+}
+
+func uidSet(uids []uint32) imap.UIDSet {
+	var set imap.UIDSet
+	for _, uid := range uids {
+		set.AddNum(imap.UID(uid))
+	}
+	return set
+}
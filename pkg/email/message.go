@@ -0,0 +1,310 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Message is a parsed email.
+type Message struct {
+	Folder      string
+	UID         uint32
+	MessageID   string
+	InReplyTo   string
+	References  string
+	From        string // As shown, such as "Ann Lee <ann@example.com>"
+	FromAddress string // Lowercased
+	To          []string
+	Cc          []string
+	Subject     string
+	Date        time.Time
+	Text        string // The plain text body, or the HTML one as text
+	Attachments []Attachment
+	Seen        bool
+	// Automatic is set for auto-replies, bounces and list mail, which
+	// mustn't be answered.
+	Automatic bool
+}
+
+// Attachment is a file attached to a message, or an inline image.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// decodeHeader decodes the encoded words of a header, such as
+// "=?utf-8?q?Caf=C3=A9?=".
+func decodeHeader(s string) string {
+	if decoded, err := wordDecoder.DecodeHeader(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+// ParseMessage parses a message in RFC 5322 format.
+func ParseMessage(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parse message: %w", err)
+	}
+	h := msg.Header
+	m := &Message{
+		MessageID:  strings.TrimSpace(h.Get("Message-Id")),
+		InReplyTo:  strings.TrimSpace(h.Get("In-Reply-To")),
+		References: strings.Join(strings.Fields(h.Get("References")), " "),
+		Subject:    decodeHeader(h.Get("Subject")),
+	}
+	m.Date, _ = h.Date()
+
+	parser := &mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(h.Get("From")); err == nil {
+		m.From, m.FromAddress = formatAddress(from), strings.ToLower(from.Address)
+	} else {
+		m.From = decodeHeader(h.Get("From"))
+	}
+	for _, field := range []struct {
+		name string
+		to   *[]string
+	}{{"To", &m.To}, {"Cc", &m.Cc}} {
+		list, _ := parser.ParseList(h.Get(field.name))
+		for _, a := range list {
+			*field.to = append(*field.to, formatAddress(a))
+		}
+	}
+
+	auto := strings.ToLower(h.Get("Auto-Submitted"))
+	precedence := strings.ToLower(h.Get("Precedence"))
+	m.Automatic = auto != "" && auto != "no" ||
+		precedence == "bulk" || precedence == "list" || precedence == "junk" ||
+		h.Get("List-Id") != "" || h.Get("X-Autoreply") != "" ||
+		strings.HasPrefix(m.FromAddress, "mailer-daemon@")
+
+	var plain, htmlText string
+	err = walkPart(textproto.MIMEHeader(h), msg.Body, func(header textproto.MIMEHeader, body []byte) {
+		mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+		if mediaType == "" {
+			mediaType = "text/plain"
+		}
+		disposition, dparams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+		filename := dparams["filename"]
+		if filename == "" {
+			filename = params["name"]
+		}
+		filename = decodeHeader(filename)
+
+		switch {
+		case disposition != "attachment" && filename == "" && mediaType == "text/plain" && plain == "":
+			plain = decodeCharset(body, params["charset"])
+		case disposition != "attachment" && filename == "" && mediaType == "text/html" && htmlText == "":
+			htmlText = HTMLToText(decodeCharset(body, params["charset"]))
+		case disposition == "attachment" || filename != "" || !strings.HasPrefix(mediaType, "text/"):
+			if filename == "" {
+				filename = "attachment"
+				if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+					filename += exts[0]
+				}
+			}
+			m.Attachments = append(m.Attachments, Attachment{Filename: filename, ContentType: mediaType, Data: body})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse message: %w", err)
+	}
+	m.Text = plain
+	if strings.TrimSpace(m.Text) == "" {
+		m.Text = htmlText
+	}
+	m.Text = strings.TrimSpace(strings.ReplaceAll(m.Text, "\r\n", "\n"))
+	return m, nil
+}
+
+const (
+	// maxParts bounds the parts of a message walked, against crafted ones.
+	maxParts = 200
+	// maxPartSize bounds a part's decoded body.
+	maxPartSize = 50 << 20
+)
+
+// walkPart calls leaf with each non-multipart part of a message, its body
+// decoded from its transfer encoding.
+func walkPart(header textproto.MIMEHeader, body io.Reader, leaf func(textproto.MIMEHeader, []byte)) error {
+	parts := 0
+	var walk func(textproto.MIMEHeader, io.Reader, int) error
+	walk = func(header textproto.MIMEHeader, body io.Reader, depth int) error {
+		if parts++; parts > maxParts || depth > 10 {
+			return nil
+		}
+		mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+		if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+			mr := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := mr.NextRawPart()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					// Keep what was read of a truncated message.
+					return nil
+				}
+				if err := walk(part.Header, part, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+
+		var r io.Reader = body
+		switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+		case "base64":
+			r = base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+		case "quoted-printable":
+			r = quotedprintable.NewReader(body)
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxPartSize))
+		if err != nil && len(data) == 0 {
+			return nil
+		}
+		leaf(header, data)
+		return nil
+	}
+	return walk(header, body, 0)
+}
+
+// base64Cleaner drops the line breaks and spaces of base64 text.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (b *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	kept := 0
+	for _, c := range p[:n] {
+		if c != '\r' && c != '\n' && c != ' ' && c != '\t' {
+			p[kept] = c
+			kept++
+		}
+	}
+	if kept == 0 && n > 0 && err == nil {
+		return b.Read(p)
+	}
+	return kept, err
+}
+
+// decodeCharset converts text in charset to UTF-8. Only UTF-8, ASCII and
+// Latin-1 are known; other text is kept if it is valid UTF-8.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252", "iso-8859-15":
+		return latin1ToUTF8(data)
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return strings.ToValidUTF8(string(data), "�")
+}
+
+func latin1ToUTF8(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(decodeCharset(data, charset)), nil
+}
+
+func formatAddress(a *mail.Address) string {
+	if a.Name == "" {
+		return a.Address
+	}
+	return a.Name + " <" + a.Address + ">"
+}
+
+var (
+	htmlHidden  = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlBreak   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6]|blockquote)>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines  = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+	spaceRuns   = regexp.MustCompile(`[ \t]+`)
+	replyPrefix = regexp.MustCompile(`(?i)^(re|aw|sv|antw)\s*:`)
+	quoteHeader = regexp.MustCompile(`^(On .+ wrote:|Le .+ a écrit :|Am .+ schrieb .+:|-+ ?Original Message ?-+)$`)
+)
+
+// HTMLToText returns the text of an HTML body, one paragraph per line.
+func HTMLToText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = spaceRuns.ReplaceAllString(s, " ")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// StripQuoted returns the new text of a reply: the lines before the quote
+// of the message replied to, such as "On Mon, Ann wrote:" and the ">"
+// lines after it.
+func StripQuoted(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if quoteHeader.MatchString(line) || strings.HasPrefix(line, ">") && quotedBelow(lines[i:]) {
+			if kept := strings.TrimSpace(strings.Join(lines[:i], "\n")); kept != "" {
+				return kept
+			}
+			break
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// quotedBelow reports whether the rest of a message is only quoted lines,
+// a signature or blank lines: a reply on top of its quote.
+func quotedBelow(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "--" || line == "-- " {
+			return true
+		}
+		if line != "" && !strings.HasPrefix(line, ">") {
+			return false
+		}
+	}
+	return true
+}
+
+// ReplySubject is the subject of a reply to a message about subject.
+func ReplySubject(subject string) string {
+	if replyPrefix.MatchString(subject) {
+		return subject
+	}
+	if strings.TrimSpace(subject) == "" {
+		return "Re: your message"
+	}
+	return "Re: " + subject
+}
@@ -0,0 +1,216 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const multipartMessage = "From: =?iso-8859-1?q?Ren=E9?= <Rene@Example.com>\r\n" +
+	"To: me@example.com, Ann <ann@example.com>\r\n" +
+	"Subject: =?utf-8?b?UmFwcG9ydCDinIU=?=\r\n" +
+	"Message-ID: <r1@example.com>\r\n" +
+	"References: <a@example.com>\r\n <b@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n\r\n" +
+	"Voil=E0 le rapport.=\r\n Bonne lecture.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html\r\n\r\n" +
+	"<p>Voil&agrave; le rapport.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"report.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"report.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n\r\n" +
+	"JVBERi0x\r\nLjQK\r\n" +
+	"--outer--\r\n"
+
+func TestParseMessage_Multipart(t *testing.T) {
+	m, err := ParseMessage([]byte(multipartMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.From != "René <Rene@Example.com>" || m.FromAddress != "rene@example.com" {
+		t.Errorf("from = %q, %q", m.From, m.FromAddress)
+	}
+	if m.Subject != "Rapport ✅" || len(m.To) != 2 || m.To[1] != "Ann <ann@example.com>" {
+		t.Errorf("subject = %q, to = %q", m.Subject, m.To)
+	}
+	if m.References != "<a@example.com> <b@example.com>" || m.MessageID != "<r1@example.com>" {
+		t.Errorf("references = %q, id = %q", m.References, m.MessageID)
+	}
+	if m.Text != "Voilà le rapport. Bonne lecture." {
+		t.Errorf("text = %q", m.Text)
+	}
+	if len(m.Attachments) != 1 || m.Attachments[0].Filename != "report.pdf" ||
+		string(m.Attachments[0].Data) != "%PDF-1.4\n" {
+		t.Errorf("attachments = %+v", m.Attachments)
+	}
+	if m.Automatic {
+		t.Error("a personal message was taken as automatic")
+	}
+}
+
+func TestParseMessage_HTMLOnlyAndAutomatic(t *testing.T) {
+	raw := "From: noreply@shop.example\r\nSubject: Order\r\nAuto-Submitted: auto-generated\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n\r\n" +
+		"<html><head><style>p{}</style></head><body><p>Your order <b>#42</b></p><p>ships&nbsp;today</p></body></html>"
+	m, err := ParseMessage([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Text != "Your order #42\nships\u00a0today" {
+		t.Errorf("text = %q", m.Text)
+	}
+	if !m.Automatic {
+		t.Error("an auto-generated message wasn't taken as automatic")
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"Yes, noon works.\n\nOn Thu, 15 Oct 2026, Ann <ann@example.com> wrote:\n> Lunch?\n> Ann", "Yes, noon works."},
+		{"Sounds good\n> earlier text\n> more\n-- \nBob", "Sounds good"},
+		{"> Lunch?\nYes!\n> Where?\nThe usual.", "> Lunch?\nYes!\n> Where?\nThe usual."},
+		{"Just text", "Just text"},
+	} {
+		if got := StripQuoted(tt.in); got != tt.want {
+			t.Errorf("StripQuoted(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompose_ParsesBack(t *testing.T) {
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "chart.png")
+	os.WriteFile(chart, []byte(strings.Repeat("\x89PNG", 40)), 0o644)
+
+	from := &mail.Address{Name: "PicoClaw", Address: "bot@example.com"}
+	data, messageID, err := compose(from, Outgoing{
+		To:          []string{"Ann <ann@example.com>"},
+		Subject:     "Re: Café",
+		Text:        "Here is the chart.\nLong line " + strings.Repeat("é", 80),
+		InReplyTo:   "<r1@example.com>",
+		References:  "<a@example.com>",
+		Attachments: []string{chart},
+	}, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(messageID, "@example.com>") {
+		t.Errorf("message id = %q", messageID)
+	}
+
+	m, err := ParseMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Subject != "Re: Café" || m.MessageID != messageID || m.InReplyTo != "<r1@example.com>" ||
+		m.References != "<a@example.com> <r1@example.com>" {
+		t.Errorf("headers = %+v", m)
+	}
+	if m.Text != "Here is the chart.\nLong line "+strings.Repeat("é", 80) {
+		t.Errorf("text = %q", m.Text)
+	}
+	if len(m.Attachments) != 1 || m.Attachments[0].Filename != "chart.png" ||
+		m.Attachments[0].ContentType != "image/png" || len(m.Attachments[0].Data) != 160 {
+		t.Errorf("attachments = %+v", m.Attachments)
+	}
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line of %d bytes", len(line))
+		}
+	}
+}
+
+// fakeSMTP accepts one message without TLS on the loopback interface.
+func fakeSMTP(t *testing.T) (port int, received chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received = make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		var transcript strings.Builder
+		write("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line)
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				write("250-fake\r\n250 AUTH PLAIN")
+			case strings.HasPrefix(cmd, "AUTH"):
+				write("235 ok")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				write("250 ok")
+			case cmd == "DATA":
+				write("354 go on")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					transcript.WriteString(line)
+				}
+				write("250 queued")
+			case cmd == "QUIT":
+				write("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				write("500 what")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestClient_Send(t *testing.T) {
+	port, received := fakeSMTP(t)
+	client := NewClient(Account{
+		Address: "PicoClaw <bot@example.com>", Username: "bot", Password: "pw", SMTPHost: "127.0.0.1", SMTPPort: port,
+	})
+	_, err := client.Send(context.Background(), Outgoing{
+		To: []string{"ann@example.com"}, Cc: []string{"Bob <bob@example.com>"}, Subject: "Hi", Text: "Hello",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript := <-received
+	for _, want := range []string{
+		"AUTH PLAIN", "MAIL FROM:<bot@example.com>", "RCPT TO:<ann@example.com>", "RCPT TO:<bob@example.com>",
+		"Subject: Hi\r\n", "Cc: \"Bob\" <bob@example.com>\r\n", "\r\nHello",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("transcript lacks %q:\n%s", want, transcript)
+		}
+	}
+
+	if _, err := client.Send(context.Background(), Outgoing{To: []string{"not an address"}}); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
@@ -0,0 +1,214 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Outgoing is a message to send.
+type Outgoing struct {
+	To      []string
+	Cc      []string
+	Subject string
+	Text    string
+	// InReplyTo and References thread a reply with the message it
+	// answers: its Message-ID, and its References followed by it.
+	InReplyTo   string
+	References  string
+	Attachments []string // Paths of the files to attach
+}
+
+// Send sends out from the account's address over SMTP.
+func (c *Client) Send(ctx context.Context, out Outgoing) (string, error) {
+	a := c.account
+	if a.SMTPHost == "" {
+		return "", errors.New("no SMTP server set")
+	}
+	from, err := mail.ParseAddress(a.Address)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender address %q: %w", a.Address, err)
+	}
+	var recipients []string
+	for _, list := range [][]string{out.To, out.Cc} {
+		for _, to := range list {
+			addr, err := mail.ParseAddress(to)
+			if err != nil {
+				return "", fmt.Errorf("invalid address %q", to)
+			}
+			recipients = append(recipients, addr.Address)
+		}
+	}
+	if len(recipients) == 0 {
+		return "", errors.New("no recipient")
+	}
+	data, messageID, err := compose(from, out, c.now())
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := dial(ctx, a.SMTPHost, a.SMTPPort, a.SMTPPort == smtpsPort)
+	if err != nil {
+		return "", fmt.Errorf("connect to %s: %w", a.SMTPHost, err)
+	}
+	client, err := smtp.NewClient(conn, a.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("SMTP: %w", err)
+	}
+	defer client.Close()
+
+	if a.SMTPPort != smtpsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: a.SMTPHost}); err != nil {
+				return "", fmt.Errorf("SMTP STARTTLS: %w", err)
+			}
+		} else if !isLoopback(a.SMTPHost) {
+			return "", fmt.Errorf("the SMTP server at %s offers no TLS; use port %d", a.SMTPHost, smtpsPort)
+		}
+	}
+	if a.Password != "" {
+		if err := client.Auth(smtp.PlainAuth("", a.username(), a.Password, a.SMTPHost)); err != nil {
+			return "", fmt.Errorf("SMTP login failed: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return "", fmt.Errorf("SMTP: %w", err)
+	}
+	for _, to := range recipients {
+		if err := client.Rcpt(to); err != nil {
+			return "", fmt.Errorf("SMTP: recipient %s refused: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("SMTP: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", fmt.Errorf("SMTP: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("SMTP: %w", err)
+	}
+	client.Quit()
+	return messageID, nil
+}
+
+// compose returns out as a MIME message from from, and its Message-ID.
+func compose(from *mail.Address, out Outgoing, now time.Time) ([]byte, string, error) {
+	var buf bytes.Buffer
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	id := make([]byte, 12)
+	rand.Read(id)
+	messageID := "<" + hex.EncodeToString(id) + "@" + domain + ">"
+
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", formatAddresses(out.To))
+	if len(out.Cc) > 0 {
+		header("Cc", formatAddresses(out.Cc))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", out.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	if out.InReplyTo != "" {
+		header("In-Reply-To", out.InReplyTo)
+		header("References", strings.TrimSpace(out.References+" "+out.InReplyTo))
+	}
+	header("MIME-Version", "1.0")
+
+	if len(out.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, out.Text); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), messageID, nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := writeQuotedPrintable(part, out.Text); err != nil {
+		return nil, "", err
+	}
+	for _, path := range out.Attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("attach %s: %w", filepath.Base(path), err)
+		}
+		name := filepath.Base(path)
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		encoded := base64.StdEncoding.EncodeToString(data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), messageID, nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func formatAddresses(list []string) string {
+	formatted := make([]string, 0, len(list))
+	for _, s := range list {
+		if a, err := mail.ParseAddress(s); err == nil {
+			formatted = append(formatted, a.String())
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,156 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package encrypt seals files PicoClaw keeps on disk (sessions, memory,
+// transcripts) with AES-256-GCM.
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// magic starts every sealed file, followed by the nonce and the ciphertext.
+var magic = []byte("PICOCLAW-AES1")
+
+// Passphrases are stretched with PBKDF2. The salt is fixed so the same
+// passphrase always opens the same files; a random 32-byte key avoids the
+// derivation altogether.
+const (
+	kdfSalt       = "picoclaw-storage"
+	kdfIterations = 100_000
+)
+
+// ErrNoKey is returned when reading a sealed file without a key.
+var ErrNoKey = errors.New("file is encrypted, but no storage encryption key is configured")
+
+// Key seals and opens data. A nil *Key does neither: Seal returns data as
+// is and Open only accepts unsealed data, so callers need not check whether
+// encryption is enabled.
+type Key struct {
+	aead cipher.AEAD
+}
+
+// ParseKey returns the key for s, which is either a base64-encoded 32-byte
+// key (openssl rand -base64 32) or a passphrase. An empty s returns nil.
+func ParseKey(s string) (*Key, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(raw) != 32 {
+		raw, err = pbkdf2.Key(sha256.New, s, []byte(kdfSalt), kdfIterations, 32)
+		if err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// Seal encrypts data.
+func (k *Key) Seal(data []byte) ([]byte, error) {
+	if k == nil {
+		return data, nil
+	}
+	nonce := make([]byte, k.aead.NonceSize(), len(magic)+k.aead.NonceSize()+len(data)+k.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, magic...), nonce...)
+	return k.aead.Seal(out, nonce, data, nil), nil
+}
+
+// Open decrypts data sealed with Seal. Unsealed data is returned as is, so
+// files written before encryption was enabled stay readable and are sealed
+// the next time they are written.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if k == nil {
+		return nil, ErrNoKey
+	}
+	data = data[len(magic):]
+	if len(data) < k.aead.NonceSize() {
+		return nil, errors.New("encrypted file is truncated")
+	}
+	nonce, ciphertext := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+	plain, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("can't decrypt file: wrong storage encryption key or corrupted file")
+	}
+	return plain, nil
+}
+
+// IsSealed reports whether data was sealed with Seal.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// ReadFile reads and opens the file at path.
+func (k *Key) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := k.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// WriteFile seals data and writes it to path. Sealed files are only
+// readable by the owner.
+func (k *Key) WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, err := k.Seal(data)
+	if err != nil {
+		return err
+	}
+	if k != nil {
+		perm &= 0o600
+	}
+	return os.WriteFile(path, sealed, perm)
+}
+
+// SealString seals s into a single line of text, for line-based files.
+func (k *Key) SealString(s []byte) (string, error) {
+	if k == nil {
+		return string(s), nil
+	}
+	sealed, err := k.Seal(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenString opens a line written by SealString. Lines that aren't sealed
+// are returned as is.
+func (k *Key) OpenString(line string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || !IsSealed(raw) {
+		return []byte(line), nil
+	}
+	return k.Open(raw)
+}
@@ -0,0 +1,97 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+
+func TestSealOpen(t *testing.T) {
+	key, err := ParseKey(testKey)
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	sealed, err := key.Seal([]byte("remember the milk"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("milk")) {
+		t.Fatalf("sealed data = %q", sealed)
+	}
+	plain, err := key.Open(sealed)
+	if err != nil || string(plain) != "remember the milk" {
+		t.Errorf("Open = %q, %v", plain, err)
+	}
+
+	other, _ := ParseKey("a passphrase")
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("opened with the wrong key")
+	}
+	var none *Key
+	if _, err := none.Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Open without a key = %v, want ErrNoKey", err)
+	}
+}
+
+func TestOpen_Plaintext(t *testing.T) {
+	key, _ := ParseKey("a passphrase")
+	plain, err := key.Open([]byte(`{"key": "telegram:1"}`))
+	if err != nil || string(plain) != `{"key": "telegram:1"}` {
+		t.Errorf("Open(plaintext) = %q, %v; want it unchanged", plain, err)
+	}
+}
+
+func TestParseKey_Empty(t *testing.T) {
+	key, err := ParseKey("  ")
+	if key != nil || err != nil {
+		t.Fatalf("ParseKey(\"\") = %v, %v; want nil, nil", key, err)
+	}
+	data, err := key.Seal([]byte("x"))
+	if err != nil || string(data) != "x" {
+		t.Errorf("nil key Seal = %q, %v; want data unchanged", data, err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	key, _ := ParseKey(testKey)
+	path := filepath.Join(t.TempDir(), "MEMORY.md")
+
+	if err := key.WriteFile(path, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("perm = %o, want owner only", perm)
+	}
+	data, err := key.ReadFile(path)
+	if err != nil || string(data) != "secret" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	key, _ := ParseKey(testKey)
+	line, err := key.SealString([]byte(`{"model":"gpt"}`))
+	if err != nil {
+		t.Fatalf("SealString: %v", err)
+	}
+	if strings.ContainsAny(line, "\n{") {
+		t.Errorf("sealed line = %q", line)
+	}
+	data, err := key.OpenString(line)
+	if err != nil || string(data) != `{"model":"gpt"}` {
+		t.Errorf("OpenString = %q, %v", data, err)
+	}
+	data, err = key.OpenString(`{"model":"old"}`)
+	if err != nil || string(data) != `{"model":"old"}` {
+		t.Errorf("OpenString(plaintext) = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,104 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxFeedBytes bounds the size of a feed downloaded.
+	maxFeedBytes = 5 << 20
+	fetchTimeout = 30 * time.Second
+	userAgent    = "PicoClaw-Feeds/1.0 (+https://github.com/sipeed/picoclaw)"
+)
+
+// fetchResult is a feed downloaded, with the validators to send the next
+// time. Feed is nil when the server answered that it hasn't changed.
+type fetchResult struct {
+	Feed         *Feed
+	ETag         string
+	LastModified string
+}
+
+// fetch downloads and parses the feed at rawURL. etag and lastModified,
+// from the previous fetch, let the server answer that it hasn't changed.
+func fetch(ctx context.Context, client *http.Client, rawURL, etag, lastModified string) (*fetchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &fetchResult{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified {
+		result.ETag, result.LastModified = etag, lastModified
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFeedBytes {
+		return nil, fmt.Errorf("the feed is larger than %d MB", maxFeedBytes>>20)
+	}
+	if result.Feed, err = Parse(data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkURL returns why rawURL can't be a feed's address, or nil.
+func checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) address", rawURL)
+	}
+	return nil
+}
+
+// charsetReader reads Latin-1 feeds as UTF-8. Other charsets are read as
+// they are.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252", "iso-8859-15":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return input, nil
+}
@@ -0,0 +1,200 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxSummaryChars bounds the summary kept of an item.
+const maxSummaryChars = 600
+
+// Feed is a parsed RSS or Atom feed.
+type Feed struct {
+	Title string
+	Link  string
+	Items []Item // In the feed's order, usually the newest first
+}
+
+// Item is an entry of a feed.
+type Item struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link,omitempty"`
+	Summary   string    `json:"summary,omitempty"`
+	Published time.Time `json:"published,omitzero"`
+	Feed      string    `json:"feed,omitempty"` // The title of the feed it is from
+}
+
+// Key identifies an item across polls: its GUID or Atom ID, else its link,
+// else a hash of its title.
+func (it Item) Key() string {
+	switch {
+	case it.ID != "":
+		return it.ID
+	case it.Link != "":
+		return it.Link
+	}
+	sum := sha1.Sum([]byte(it.Title + "\x00" + it.Summary))
+	return "sha1:" + hex.EncodeToString(sum[:8])
+}
+
+// xmlLink is an RSS <link>text</link> or an Atom <link href rel/>.
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// xmlEntry holds the fields of an RSS item and an Atom entry; elements are
+// matched by local name, so namespaced ones such as dc:date and
+// content:encoded are found too.
+type xmlEntry struct {
+	Title       string    `xml:"title"`
+	Links       []xmlLink `xml:"link"`
+	GUID        string    `xml:"guid"`
+	ID          string    `xml:"id"`
+	Description string    `xml:"description"`
+	Summary     string    `xml:"summary"`
+	Content     string    `xml:"content"`
+	Encoded     string    `xml:"encoded"`
+	PubDate     string    `xml:"pubDate"`
+	Date        string    `xml:"date"`
+	Published   string    `xml:"published"`
+	Updated     string    `xml:"updated"`
+}
+
+type xmlChannel struct {
+	Title string     `xml:"title"`
+	Links []xmlLink  `xml:"link"`
+	Items []xmlEntry `xml:"item"`
+}
+
+// xmlFeed is the root of an RSS 2.0 (<rss>), RSS 1.0 (<rdf:RDF>) or Atom
+// (<feed>) document.
+type xmlFeed struct {
+	XMLName xml.Name
+	Channel xmlChannel `xml:"channel"`
+	Items   []xmlEntry `xml:"item"`  // RSS 1.0 keeps items beside the channel
+	Title   string     `xml:"title"` // Atom
+	Links   []xmlLink  `xml:"link"`
+	Entries []xmlEntry `xml:"entry"`
+}
+
+// Parse parses an RSS 2.0, RSS 1.0 or Atom feed.
+func Parse(data []byte) (*Feed, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = charsetReader
+
+	var doc xmlFeed
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New("not an RSS or Atom feed: " + err.Error())
+	}
+
+	feed := &Feed{}
+	var entries []xmlEntry
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		feed.Title, feed.Link = doc.Channel.Title, linkOf(doc.Channel.Links)
+		entries = append(doc.Channel.Items, doc.Items...)
+	case "feed":
+		feed.Title, feed.Link = doc.Title, linkOf(doc.Links)
+		entries = doc.Entries
+	default:
+		return nil, errors.New("not an RSS or Atom feed: the document is <" + doc.XMLName.Local + ">")
+	}
+	feed.Title = cleanText(feed.Title)
+
+	for _, e := range entries {
+		item := Item{
+			ID:    strings.TrimSpace(firstOf(e.GUID, e.ID)),
+			Title: cleanText(e.Title),
+			Link:  linkOf(e.Links),
+			Feed:  feed.Title,
+		}
+		summary := cleanText(firstOf(e.Description, e.Summary, e.Encoded, e.Content))
+		if len([]rune(summary)) > maxSummaryChars {
+			summary = string([]rune(summary)[:maxSummaryChars-3]) + "..."
+		}
+		item.Summary = summary
+		item.Published = parseDate(firstOf(e.Published, e.PubDate, e.Date, e.Updated))
+		if item.Title == "" && item.Summary == "" && item.Link == "" {
+			continue
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// linkOf returns the page a feed or item links to: an RSS link's text, or
+// an Atom link whose rel is alternate or unset.
+func linkOf(links []xmlLink) string {
+	for _, l := range links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	for _, l := range links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+var (
+	htmlTag = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaces  = regexp.MustCompile(`\s+`)
+)
+
+// cleanText returns the text of a field that may hold HTML, on one line.
+func cleanText(s string) string {
+	s = htmlTag.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+}
+
+// parseDate parses the date formats feeds use, or returns the zero time.
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,106 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>Go Blog</title>
+  <atom:link href="https://go.dev/blog/feed.xml" rel="self"/>
+  <link>https://go.dev/blog</link>
+  <item>
+    <title>Go 1.26 is released</title>
+    <link>https://go.dev/blog/go1.26</link>
+    <guid isPermaLink="false">go1.26</guid>
+    <pubDate>Tue, 10 Feb 2026 17:00:00 +0000</pubDate>
+    <content:encoded><![CDATA[<p>Today the Go team is <b>very</b> happy&nbsp;to announce</p>]]></content:encoded>
+  </item>
+  <item>
+    <title>Range over &amp; functions</title>
+    <link>https://go.dev/blog/range-functions</link>
+    <description>Iterators &lt;3</description>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="text">Example Releases</title>
+  <link rel="self" href="https://example.com/releases.atom"/>
+  <link href="https://example.com/releases"/>
+  <entry>
+    <id>tag:example.com,2026:v2.0</id>
+    <title>v2.0</title>
+    <link rel="alternate" href="https://example.com/releases/v2.0"/>
+    <updated>2026-10-14T08:00:00Z</updated>
+    <summary type="html">&lt;ul&gt;&lt;li&gt;New parser&lt;/li&gt;&lt;/ul&gt;</summary>
+  </entry>
+</feed>`
+
+const rdfFeed = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>Caf` + "\xe9" + ` News</title><link>https://cafe.example</link></channel>
+  <item>
+    <title>Opening</title>
+    <link>https://cafe.example/opening</link>
+    <dc:date>2026-10-01T09:00:00+02:00</dc:date>
+  </item>
+</rdf:RDF>`
+
+func TestParse_RSS(t *testing.T) {
+	feed, err := Parse([]byte(rssFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Go Blog" || feed.Link != "https://go.dev/blog" || len(feed.Items) != 2 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	it := feed.Items[0]
+	if it.Title != "Go 1.26 is released" || it.Key() != "go1.26" || it.Link != "https://go.dev/blog/go1.26" ||
+		it.Summary != "Today the Go team is very happy to announce" ||
+		!it.Published.Equal(time.Date(2026, 2, 10, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("item = %+v", it)
+	}
+	if it := feed.Items[1]; it.Title != "Range over & functions" || it.Summary != "Iterators <3" ||
+		it.Key() != "https://go.dev/blog/range-functions" {
+		t.Errorf("item = %+v", it)
+	}
+}
+
+func TestParse_Atom(t *testing.T) {
+	feed, err := Parse([]byte(atomFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Example Releases" || feed.Link != "https://example.com/releases" || len(feed.Items) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	it := feed.Items[0]
+	if it.Key() != "tag:example.com,2026:v2.0" || it.Link != "https://example.com/releases/v2.0" ||
+		it.Summary != "New parser" || it.Published.IsZero() {
+		t.Errorf("item = %+v", it)
+	}
+}
+
+func TestParse_RDF(t *testing.T) {
+	feed, err := Parse([]byte(rdfFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Café News" || len(feed.Items) != 1 || feed.Items[0].Link != "https://cafe.example/opening" ||
+		feed.Items[0].Published.IsZero() {
+		t.Fatalf("feed = %+v", feed)
+	}
+}
+
+func TestParse_NotAFeed(t *testing.T) {
+	for _, doc := range []string{"<html><body>Hi</body></html>", "not xml at all"} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Parse(%q) succeeded", doc)
+		}
+	}
+}
@@ -0,0 +1,624 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package feeds follows RSS and Atom feeds for the user: new items are
+// filtered by keywords or by topic, and delivered to a chat as they come or
+// gathered into scheduled digests.
+package feeds
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+)
+
+// Delivery modes of a subscription.
+const (
+	ModeDigest = "digest" // New items are gathered and sent on a schedule
+	ModeAlert  = "alert"  // Each poll's new items are sent right away
+)
+
+const (
+	DefaultPollInterval   = 30 * time.Minute
+	DefaultMinScore       = 0.5
+	DefaultMaxDigestItems = 20
+	// DefaultDigestSchedule is when digests are sent unless told otherwise.
+	DefaultDigestSchedule = "every day at 8:00"
+
+	// maxSeen bounds the item keys remembered per feed to tell new items.
+	maxSeen = 1000
+	// maxPending bounds the items waiting for a digest; older ones are
+	// dropped.
+	maxPending = 200
+	// tickInterval is how often due polls and digests are looked for.
+	tickInterval = time.Minute
+)
+
+// ErrNoEmbeddings means a topic filter was asked for without an embedding
+// model.
+var ErrNoEmbeddings = errors.New("topic filters need an embedding model assigned to the embed role")
+
+// Subscription is a feed followed for a chat.
+type Subscription struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Mode  string `json:"mode"`
+	// Schedule is when digests are sent.
+	Schedule cron.CronSchedule `json:"schedule,omitzero"`
+	// Keywords and Topic filter items: an item is delivered when it
+	// contains one of the keywords, or is about the topic. Without either,
+	// every item is.
+	Keywords []string `json:"keywords,omitempty"`
+	Topic    string   `json:"topic,omitempty"`
+	// The chat items are delivered to
+	Channel   string    `json:"channel"`
+	ChatID    string    `json:"chat_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	NextPoll     time.Time `json:"next_poll,omitzero"`
+	NextDigest   time.Time `json:"next_digest,omitzero"`
+	LastError    string    `json:"last_error,omitempty"`
+	Seen         []string  `json:"seen,omitempty"` // Keys of the items seen, the newest last
+	Pending      []Item    `json:"pending,omitempty"`
+	TopicVector  []float32 `json:"topic_vector,omitempty"`
+}
+
+// Delivery is what a subscription sends to its chat: one poll's items in
+// alert mode, or the items gathered since the last digest.
+type Delivery struct {
+	Subscription Subscription
+	Kind         string // ModeAlert or ModeDigest
+	Items        []Item
+	More         int // Items left out of a digest over its size
+}
+
+// Text is the delivery as a message to the user.
+func (d Delivery) Text() string {
+	var sb strings.Builder
+	if d.Kind == ModeAlert {
+		for i, it := range d.Items {
+			if i > 0 {
+				sb.WriteString("\n\n")
+			}
+			fmt.Fprintf(&sb, "📰 %s: %s", d.Subscription.Title, it.Title)
+			if it.Link != "" {
+				sb.WriteString("\n" + it.Link)
+			}
+		}
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "📰 %s: %d new", d.Subscription.Title, len(d.Items)+d.More)
+	for i, it := range d.Items {
+		fmt.Fprintf(&sb, "\n\n%d. %s", i+1, it.Title)
+		if it.Link != "" {
+			sb.WriteString("\n" + it.Link)
+		}
+	}
+	if d.More > 0 {
+		fmt.Fprintf(&sb, "\n\n…and %d more", d.More)
+	}
+	return sb.String()
+}
+
+// Message asks the agent to write the digest of d for the user.
+func (d Delivery) Message() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Feed digest: %s]\n", d.Subscription.Title)
+	sb.WriteString("Write the user a short digest of these new items from a feed they follow: " +
+		"group related ones, say in a line what each is about, and keep the links.")
+	if d.Subscription.Topic != "" {
+		fmt.Fprintf(&sb, " They follow it for: %s.", d.Subscription.Topic)
+	}
+	for i, it := range d.Items {
+		fmt.Fprintf(&sb, "\n\n%d. %s", i+1, it.Title)
+		if it.Link != "" {
+			sb.WriteString("\n" + it.Link)
+		}
+		if it.Summary != "" {
+			sb.WriteString("\n" + it.Summary)
+		}
+	}
+	if d.More > 0 {
+		fmt.Fprintf(&sb, "\n\n(%d more items are left out.)", d.More)
+	}
+	return sb.String()
+}
+
+// Handler sends a delivery to its subscription's chat.
+type Handler func(ctx context.Context, d Delivery)
+
+// EmbedFunc returns one embedding per input, for topic filters.
+type EmbedFunc func(ctx context.Context, inputs []string) ([][]float32, error)
+
+// Options tunes a Service. Zero values take the defaults.
+type Options struct {
+	PollInterval   time.Duration
+	MinScore       float32 // Similarity to its topic an item needs
+	MaxDigestItems int
+	Embed          EmbedFunc // Nil when there is no embedding model
+	Client         *http.Client
+}
+
+type store struct {
+	Version       int             `json:"version"`
+	Subscriptions []*Subscription `json:"subscriptions"`
+}
+
+// Service polls the subscribed feeds and hands new items to a Handler.
+// Subscriptions are kept in a JSON file, so they survive restarts.
+type Service struct {
+	path    string
+	opts    Options
+	handler Handler
+	now     func() time.Time
+
+	mu     sync.Mutex
+	subs   []*Subscription
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewService creates a Service keeping its subscriptions in path.
+func NewService(path string, opts Options, handler Handler) *Service {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.MinScore <= 0 {
+		opts.MinScore = DefaultMinScore
+	}
+	if opts.MaxDigestItems <= 0 {
+		opts.MaxDigestItems = DefaultMaxDigestItems
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	s := &Service{path: path, opts: opts, handler: handler, now: time.Now}
+	if err := s.load(); err != nil {
+		logger.ErrorCF("feeds", "Failed to load feed subscriptions", map[string]any{"error": err.Error()})
+	}
+	return s
+}
+
+// Start polls the feeds until Stop.
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return nil
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			s.check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.done)
+	return nil
+}
+
+// Stop stops polling and waits for the poll in progress, if any.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Subscribe follows the feed at sub.URL for sub's chat. The feed is fetched
+// once to check it and take its title; the items in it then are taken as
+// seen, so only later ones are delivered. A digest's sub.Schedule is given
+// in plain English or as a cron expression in scheduleText, in the time
+// zone tz.
+func (s *Service) Subscribe(ctx context.Context, sub Subscription, scheduleText, tz string) (*Subscription, error) {
+	sub.URL = strings.TrimSpace(sub.URL)
+	if err := checkURL(sub.URL); err != nil {
+		return nil, err
+	}
+	if sub.Channel == "" || sub.ChatID == "" {
+		return nil, errors.New("no chat to deliver to")
+	}
+	now := s.now()
+
+	switch sub.Mode {
+	case "", ModeDigest:
+		sub.Mode = ModeDigest
+		schedule, next, err := digestSchedule(scheduleText, tz, now)
+		if err != nil {
+			return nil, err
+		}
+		sub.Schedule, sub.NextDigest = schedule, next
+	case ModeAlert:
+	default:
+		return nil, fmt.Errorf("unknown mode %q; use %s or %s", sub.Mode, ModeDigest, ModeAlert)
+	}
+
+	var keywords []string
+	for _, k := range sub.Keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	sub.Keywords, sub.Topic = keywords, strings.TrimSpace(sub.Topic)
+	if sub.Topic != "" {
+		if s.opts.Embed == nil {
+			return nil, ErrNoEmbeddings
+		}
+		vectors, err := s.opts.Embed(ctx, []string{sub.Topic})
+		if err != nil || len(vectors) != 1 {
+			return nil, fmt.Errorf("can't embed the topic: %v", err)
+		}
+		sub.TopicVector = vectors[0]
+	}
+
+	s.mu.Lock()
+	for _, other := range s.subs {
+		if other.URL == sub.URL && other.Channel == sub.Channel && other.ChatID == sub.ChatID {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("this chat already follows %s (subscription %s)", sub.URL, other.ID)
+		}
+	}
+	s.mu.Unlock()
+
+	result, err := fetch(ctx, s.opts.Client, sub.URL, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("can't read the feed: %w", err)
+	}
+	if sub.Title == "" {
+		sub.Title = result.Feed.Title
+	}
+	if sub.Title == "" {
+		sub.Title = sub.URL
+	}
+	sub.ETag, sub.LastModified = result.ETag, result.LastModified
+	sub.Seen, sub.Pending, sub.LastError = nil, nil, ""
+	sub.markSeen(result.Feed.Items)
+	sub.ID = newID()
+	sub.CreatedAt = now
+	sub.NextPoll = now.Add(s.opts.PollInterval)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = append(s.subs, &sub)
+	if err := s.save(); err != nil {
+		s.subs = s.subs[:len(s.subs)-1]
+		return nil, fmt.Errorf("save subscriptions: %w", err)
+	}
+	copied := sub
+	return &copied, nil
+}
+
+// digestSchedule parses when digests are sent, and returns the next time.
+func digestSchedule(text, tz string, now time.Time) (cron.CronSchedule, time.Time, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultDigestSchedule
+	}
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return cron.CronSchedule{}, time.Time{}, fmt.Errorf("unknown time zone %q", tz)
+		}
+		now = now.In(loc)
+	}
+	schedule, err := cron.ParseSchedule(text, now)
+	if err != nil {
+		return cron.CronSchedule{}, time.Time{}, err
+	}
+	if schedule.Kind == "at" {
+		return cron.CronSchedule{}, time.Time{}, fmt.Errorf("%q happens once; digests need a repeating schedule", text)
+	}
+	schedule.TZ = tz
+	next, ok := cron.NextRun(schedule, now)
+	if !ok {
+		return cron.CronSchedule{}, time.Time{}, fmt.Errorf("%q never happens", text)
+	}
+	return schedule, next, nil
+}
+
+// Unsubscribe stops following the subscription with id, and reports
+// whether there was one.
+func (s *Service) Unsubscribe(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subs {
+		if sub.ID == id {
+			s.subs = append(s.subs[:i], s.subs[i+1:]...)
+			if err := s.save(); err != nil {
+				logger.ErrorCF("feeds", "Failed to save feed subscriptions", map[string]any{"error": err.Error()})
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the subscriptions.
+func (s *Service) List() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Subscription, len(s.subs))
+	for i, sub := range s.subs {
+		list[i] = *sub
+	}
+	return list
+}
+
+// Preview fetches the feed at rawURL, to show it before subscribing.
+func (s *Service) Preview(ctx context.Context, rawURL string) (*Feed, error) {
+	if err := checkURL(rawURL); err != nil {
+		return nil, err
+	}
+	result, err := fetch(ctx, s.opts.Client, rawURL, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Feed, nil
+}
+
+// check polls the feeds that are due and sends the digests that are.
+func (s *Service) check(ctx context.Context) {
+	now := s.now()
+	s.mu.Lock()
+	var due []Subscription
+	for _, sub := range s.subs {
+		if !sub.NextPoll.After(now) {
+			due = append(due, *sub)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sub := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.poll(ctx, sub, now)
+	}
+
+	var digests []Delivery
+	changed := len(due) > 0
+	s.mu.Lock()
+	for _, sub := range s.subs {
+		if sub.Mode != ModeDigest || sub.NextDigest.IsZero() || sub.NextDigest.After(now) {
+			continue
+		}
+		if len(sub.Pending) > 0 {
+			d := Delivery{Subscription: *sub, Kind: ModeDigest, Items: sub.Pending}
+			if len(d.Items) > s.opts.MaxDigestItems {
+				// Keep the newest.
+				d.More = len(d.Items) - s.opts.MaxDigestItems
+				d.Items = d.Items[d.More:]
+			}
+			digests = append(digests, d)
+			sub.Pending = nil
+		}
+		sub.NextDigest, _ = cron.NextRun(sub.Schedule, now)
+		changed = true
+	}
+	if changed {
+		if err := s.save(); err != nil {
+			logger.ErrorCF("feeds", "Failed to save feed subscriptions", map[string]any{"error": err.Error()})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, d := range digests {
+		logger.InfoCF("feeds", "Sending feed digest",
+			map[string]any{"subscription": d.Subscription.ID, "items": len(d.Items)})
+		s.handler(ctx, d)
+	}
+}
+
+// poll fetches the feed of sub and delivers or gathers its new items that
+// pass the filters.
+func (s *Service) poll(ctx context.Context, sub Subscription, now time.Time) {
+	result, err := fetch(ctx, s.opts.Client, sub.URL, sub.ETag, sub.LastModified)
+
+	s.mu.Lock()
+	current := s.find(sub.ID)
+	if current == nil {
+		s.mu.Unlock()
+		return
+	}
+	current.NextPoll = now.Add(s.opts.PollInterval)
+	if err != nil {
+		current.LastError = err.Error()
+		s.mu.Unlock()
+		if ctx.Err() == nil {
+			logger.WarnCF("feeds", "Failed to fetch feed", map[string]any{"url": sub.URL, "error": err.Error()})
+		}
+		return
+	}
+	current.LastError = ""
+	current.ETag, current.LastModified = result.ETag, result.LastModified
+	var fresh []Item
+	if result.Feed != nil {
+		fresh = current.markSeen(result.Feed.Items)
+	}
+	title := current.Title
+	s.mu.Unlock()
+
+	for i := range fresh {
+		fresh[i].Feed = title
+	}
+	matched := s.filter(ctx, sub, fresh)
+	if len(matched) == 0 {
+		return
+	}
+
+	if sub.Mode == ModeAlert {
+		logger.InfoCF("feeds", "Sending feed alert", map[string]any{"subscription": sub.ID, "items": len(matched)})
+		s.handler(ctx, Delivery{Subscription: sub, Kind: ModeAlert, Items: matched})
+		return
+	}
+	s.mu.Lock()
+	if current := s.find(sub.ID); current != nil {
+		current.addPending(matched)
+	}
+	s.mu.Unlock()
+}
+
+// filter returns the items that pass sub's keyword and topic filters.
+func (s *Service) filter(ctx context.Context, sub Subscription, items []Item) []Item {
+	if len(items) == 0 || len(sub.Keywords) == 0 && sub.Topic == "" {
+		return items
+	}
+
+	var scores [][]float32
+	if sub.Topic != "" && s.opts.Embed != nil && len(sub.TopicVector) > 0 {
+		texts := make([]string, len(items))
+		for i, it := range items {
+			texts[i] = it.Title + "\n" + it.Summary
+		}
+		vectors, err := s.opts.Embed(ctx, texts)
+		if err != nil || len(vectors) != len(items) {
+			// Better a few items off topic than missing some.
+			logger.WarnCF("feeds", "Can't embed feed items; delivering them unfiltered",
+				map[string]any{"subscription": sub.ID, "error": fmt.Sprint(err)})
+			return items
+		}
+		scores = vectors
+	}
+
+	var matched []Item
+	for i, it := range items {
+		text := strings.ToLower(it.Title + " " + it.Summary)
+		keep := false
+		for _, k := range sub.Keywords {
+			if strings.Contains(text, strings.ToLower(k)) {
+				keep = true
+				break
+			}
+		}
+		if !keep && scores != nil && memory.Cosine(scores[i], sub.TopicVector) >= s.opts.MinScore {
+			keep = true
+		}
+		if keep {
+			matched = append(matched, it)
+		}
+	}
+	return matched
+}
+
+// markSeen remembers the keys of items, and returns those not seen before,
+// the oldest first.
+func (sub *Subscription) markSeen(items []Item) []Item {
+	seen := make(map[string]bool, len(sub.Seen))
+	for _, k := range sub.Seen {
+		seen[k] = true
+	}
+	var fresh []Item
+	// Feeds list the newest first.
+	for i := len(items) - 1; i >= 0; i-- {
+		key := items[i].Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sub.Seen = append(sub.Seen, key)
+		fresh = append(fresh, items[i])
+	}
+	if len(sub.Seen) > maxSeen {
+		sub.Seen = sub.Seen[len(sub.Seen)-maxSeen:]
+	}
+	return fresh
+}
+
+// addPending gathers items for the next digest, leaving out those whose
+// link or title is already there.
+func (sub *Subscription) addPending(items []Item) {
+	have := make(map[string]bool, len(sub.Pending))
+	for _, it := range sub.Pending {
+		have[it.Link] = true
+		have[strings.ToLower(it.Title)] = true
+	}
+	for _, it := range items {
+		if it.Link != "" && have[it.Link] || it.Title != "" && have[strings.ToLower(it.Title)] {
+			continue
+		}
+		have[it.Link] = true
+		have[strings.ToLower(it.Title)] = true
+		sub.Pending = append(sub.Pending, it)
+	}
+	if len(sub.Pending) > maxPending {
+		sub.Pending = sub.Pending[len(sub.Pending)-maxPending:]
+	}
+}
+
+func (s *Service) find(id string) *Subscription {
+	for _, sub := range s.subs {
+		if sub.ID == id {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (s *Service) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var st store
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	s.subs = st.Subscriptions
+	return nil
+}
+
+// save writes the subscriptions to a temporary file renamed over the old
+// one, so a crash doesn't leave them half written. Callers hold s.mu.
+func (s *Service) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store{Version: 1, Subscriptions: s.subs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,214 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testFeed serves an RSS feed whose items can be added to.
+type testFeed struct {
+	mu    sync.Mutex
+	items []string // Titles, the newest last
+	gets  int
+}
+
+func (f *testFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	etag := fmt.Sprintf(`"%d"`, len(f.items))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, `<rss><channel><title>News</title>`)
+	for i := len(f.items) - 1; i >= 0; i-- {
+		fmt.Fprintf(w, `<item><title>%s</title><link>https://news.example/%d</link></item>`, f.items[i], i)
+	}
+	fmt.Fprint(w, `</channel></rss>`)
+}
+
+func (f *testFeed) add(titles ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = append(f.items, titles...)
+}
+
+type deliveries struct {
+	mu   sync.Mutex
+	list []Delivery
+}
+
+func (d *deliveries) handle(_ context.Context, delivery Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.list = append(d.list, delivery)
+}
+
+func newTestService(t *testing.T, opts Options) (*Service, *testFeed, string, *deliveries, *time.Time) {
+	t.Helper()
+	feed := &testFeed{items: []string{"Old news"}}
+	server := httptest.NewServer(feed)
+	t.Cleanup(server.Close)
+	got := &deliveries{}
+	s := NewService(filepath.Join(t.TempDir(), "feeds.json"), opts, got.handle)
+	now := time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, feed, server.URL, got, &now
+}
+
+func TestService_AlertsWithKeywords(t *testing.T) {
+	s, feed, url, got, now := newTestService(t, Options{})
+	ctx := context.Background()
+
+	sub, err := s.Subscribe(ctx, Subscription{
+		URL: url, Mode: ModeAlert, Keywords: []string{"release"}, Channel: "telegram", ChatID: "42",
+	}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Title != "News" || len(sub.Seen) != 1 {
+		t.Fatalf("subscription = %+v", sub)
+	}
+	if _, err := s.Subscribe(ctx, Subscription{URL: url, Channel: "telegram", ChatID: "42"}, "", ""); err == nil {
+		t.Error("subscribing twice to the same feed should fail")
+	}
+
+	// Nothing is polled before its time.
+	feed.add("Go 1.27 Release", "Weather")
+	s.check(ctx)
+	if len(got.list) != 0 {
+		t.Fatalf("delivered early: %+v", got.list)
+	}
+
+	*now = now.Add(DefaultPollInterval)
+	s.check(ctx)
+	if len(got.list) != 1 || len(got.list[0].Items) != 1 || got.list[0].Items[0].Title != "Go 1.27 Release" {
+		t.Fatalf("deliveries = %+v", got.list)
+	}
+	if text := got.list[0].Text(); text != "📰 News: Go 1.27 Release\nhttps://news.example/1" {
+		t.Errorf("text = %q", text)
+	}
+
+	// Seen items and unchanged feeds aren't delivered again.
+	*now = now.Add(DefaultPollInterval)
+	s.check(ctx)
+	if len(got.list) != 1 {
+		t.Errorf("delivered again: %+v", got.list)
+	}
+}
+
+func TestService_Digest(t *testing.T) {
+	s, feed, url, got, now := newTestService(t, Options{MaxDigestItems: 2})
+	ctx := context.Background()
+
+	sub, err := s.Subscribe(ctx, Subscription{URL: url, Channel: "telegram", ChatID: "42"}, "every day at 8:00", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Mode != ModeDigest || !sub.NextDigest.Equal(time.Date(2026, 10, 15, 8, 0, 0, 0, time.Local)) {
+		t.Fatalf("subscription = %+v", sub)
+	}
+
+	feed.add("One", "Two", "Three")
+	*now = now.Add(DefaultPollInterval)
+	s.check(ctx)
+	if len(got.list) != 0 {
+		t.Fatalf("digest sent before its time: %+v", got.list)
+	}
+
+	*now = sub.NextDigest
+	s.check(ctx)
+	if len(got.list) != 1 {
+		t.Fatalf("deliveries = %+v", got.list)
+	}
+	d := got.list[0]
+	if d.Kind != ModeDigest || len(d.Items) != 2 || d.Items[1].Title != "Three" || d.More != 1 {
+		t.Errorf("digest = %+v", d)
+	}
+	if !strings.Contains(d.Text(), "📰 News: 3 new") || !strings.Contains(d.Message(), "2. Three") {
+		t.Errorf("text = %q", d.Text())
+	}
+
+	// An empty digest isn't sent, and the next one is scheduled.
+	*now = now.Add(24 * time.Hour)
+	s.check(ctx)
+	if len(got.list) != 1 {
+		t.Errorf("empty digest sent: %+v", got.list)
+	}
+	if next := s.List()[0].NextDigest; !next.After(*now) {
+		t.Errorf("next digest = %v", next)
+	}
+
+	// Subscriptions survive a restart.
+	reloaded := NewService(s.path, Options{}, got.handle)
+	if list := reloaded.List(); len(list) != 1 || list[0].ID != sub.ID || len(list[0].Seen) != 4 {
+		t.Errorf("reloaded = %+v", list)
+	}
+	if !reloaded.Unsubscribe(sub.ID) || reloaded.Unsubscribe(sub.ID) || len(reloaded.List()) != 0 {
+		t.Error("unsubscribe failed")
+	}
+}
+
+func TestService_TopicFilter(t *testing.T) {
+	// Texts about cats point one way, the rest another.
+	embed := func(_ context.Context, inputs []string) ([][]float32, error) {
+		vectors := make([][]float32, len(inputs))
+		for i, in := range inputs {
+			if in = strings.ToLower(in); strings.Contains(in, "cat") || strings.Contains(in, "kitten") {
+				vectors[i] = []float32{1, 0.1}
+			} else {
+				vectors[i] = []float32{0, 1}
+			}
+		}
+		return vectors, nil
+	}
+
+	s, feed, url, got, now := newTestService(t, Options{})
+	ctx := context.Background()
+	if _, err := s.Subscribe(ctx, Subscription{URL: url, Mode: ModeAlert, Topic: "cats", Channel: "c", ChatID: "1"},
+		"", ""); err != ErrNoEmbeddings {
+		t.Fatalf("topic without embeddings: %v", err)
+	}
+
+	s.opts.Embed = embed
+	if _, err := s.Subscribe(ctx, Subscription{URL: url, Mode: ModeAlert, Topic: "cats", Channel: "c", ChatID: "1"},
+		"", ""); err != nil {
+		t.Fatal(err)
+	}
+	feed.add("Kittens adopted", "Stock market")
+	*now = now.Add(DefaultPollInterval)
+	s.check(ctx)
+	if len(got.list) != 1 || len(got.list[0].Items) != 1 || got.list[0].Items[0].Title != "Kittens adopted" {
+		t.Fatalf("deliveries = %+v", got.list)
+	}
+}
+
+func TestSubscribe_Errors(t *testing.T) {
+	s, _, url, _, _ := newTestService(t, Options{})
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	ctx := context.Background()
+	for _, tc := range []struct {
+		sub      Subscription
+		schedule string
+	}{
+		{Subscription{URL: "ftp://x", Channel: "c", ChatID: "1"}, ""},
+		{Subscription{URL: url}, ""},
+		{Subscription{URL: url, Mode: "weekly", Channel: "c", ChatID: "1"}, ""},
+		{Subscription{URL: url, Channel: "c", ChatID: "1"}, "tomorrow at 8"},
+		{Subscription{URL: missing.URL, Mode: ModeAlert, Channel: "c", ChatID: "1"}, ""},
+	} {
+		if _, err := s.Subscribe(ctx, tc.sub, tc.schedule, ""); err == nil {
+			t.Errorf("Subscribe(%+v, %q) succeeded", tc.sub, tc.schedule)
+		}
+	}
+}
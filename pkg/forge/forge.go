@@ -0,0 +1,314 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package forge calls the REST APIs of GitHub and Gitea (or Forgejo) to
+// list the issues and pull requests of a repository and open pull requests.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Forge kinds.
+const (
+	GitHub = "github"
+	Gitea  = "gitea" // Forgejo serves the same API
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	maxResponse    = 4 << 20
+	// MaxItems bounds the issues or pull requests listed at once.
+	MaxItems = 50
+)
+
+// Issue is an issue of a repository.
+type Issue struct {
+	Number   int
+	Title    string
+	State    string // open or closed
+	URL      string
+	Author   string
+	Labels   []string
+	Comments int
+	Updated  time.Time
+}
+
+// PullRequest is a pull request of a repository.
+type PullRequest struct {
+	Number  int
+	Title   string
+	State   string // open or closed
+	URL     string
+	Author  string
+	Head    string // The branch with the changes
+	Base    string // The branch they are to be merged into
+	Draft   bool
+	Merged  bool
+	Updated time.Time
+}
+
+// NewPullRequest is a pull request to open.
+type NewPullRequest struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string // Default: the repository's default branch
+	Draft bool
+}
+
+// Client calls the API of one GitHub or Gitea instance with a token.
+type Client struct {
+	kind   string
+	host   string // Of the web address, which git remotes use
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewClient returns a client of the forge of the given kind at baseURL, its
+// web address such as https://github.com (the default for GitHub) or
+// https://git.example.lan. For GitHub Enterprise the API is at /api/v3,
+// for Gitea at /api/v1.
+func NewClient(kind, baseURL, token string) (*Client, error) {
+	if baseURL == "" && kind == GitHub {
+		baseURL = "https://github.com"
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not the http(s) address of a forge", baseURL)
+	}
+	c := &Client{kind: kind, host: u.Hostname(), token: token, http: &http.Client{Timeout: requestTimeout}}
+	switch {
+	case kind == GitHub && u.Host == "github.com":
+		c.apiURL = "https://api.github.com"
+	case kind == GitHub:
+		c.apiURL = u.String() + "/api/v3"
+	case kind == Gitea:
+		c.apiURL = u.String() + "/api/v1"
+	default:
+		return nil, fmt.Errorf("unknown forge type %q, want %s or %s", kind, GitHub, Gitea)
+	}
+	return c, nil
+}
+
+// Kind returns GitHub or Gitea.
+func (c *Client) Kind() string {
+	return c.kind
+}
+
+// Host returns the host name of the forge, as found in its git remotes.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// apiIssue holds the fields GitHub and Gitea share for issues and pull
+// requests.
+type apiIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Comments    int             `json:"comments"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	PullRequest json.RawMessage `json:"pull_request"` // Set on GitHub issues that are pull requests
+	Head        struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Draft  bool `json:"draft"`
+	Merged bool `json:"merged"`
+}
+
+// Issues returns the issues of owner/repo in state (open, closed or all;
+// default open). Pull requests are left out.
+func (c *Client) Issues(ctx context.Context, owner, repo, state string, limit int) ([]Issue, error) {
+	q := listQuery(state, limit, c.kind)
+	if c.kind == Gitea {
+		q.Set("type", "issues")
+	} else {
+		q.Set("sort", "updated")
+	}
+	var items []apiIssue
+	if err := c.do(ctx, http.MethodGet, repoPath(owner, repo)+"/issues?"+q.Encode(), nil, &items); err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	for _, it := range items {
+		if len(it.PullRequest) > 0 && string(it.PullRequest) != "null" {
+			continue
+		}
+		issue := Issue{
+			Number:   it.Number,
+			Title:    it.Title,
+			State:    it.State,
+			URL:      it.HTMLURL,
+			Author:   it.User.Login,
+			Comments: it.Comments,
+			Updated:  it.UpdatedAt,
+		}
+		for _, l := range it.Labels {
+			issue.Labels = append(issue.Labels, l.Name)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// PullRequests returns the pull requests of owner/repo in state (open,
+// closed or all; default open).
+func (c *Client) PullRequests(ctx context.Context, owner, repo, state string, limit int) ([]PullRequest, error) {
+	q := listQuery(state, limit, c.kind)
+	var items []apiIssue
+	if err := c.do(ctx, http.MethodGet, repoPath(owner, repo)+"/pulls?"+q.Encode(), nil, &items); err != nil {
+		return nil, err
+	}
+	prs := make([]PullRequest, 0, len(items))
+	for _, it := range items {
+		prs = append(prs, pullRequestOf(it))
+	}
+	return prs, nil
+}
+
+// CreatePullRequest opens a pull request on owner/repo.
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, pr NewPullRequest) (*PullRequest, error) {
+	if pr.Title == "" || pr.Head == "" {
+		return nil, errors.New("a pull request needs a title and a head branch")
+	}
+	if pr.Base == "" {
+		var info struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := c.do(ctx, http.MethodGet, repoPath(owner, repo), nil, &info); err != nil {
+			return nil, err
+		}
+		pr.Base = info.DefaultBranch
+	}
+	body := map[string]any{"title": pr.Title, "body": pr.Body, "head": pr.Head, "base": pr.Base}
+	if pr.Draft {
+		if c.kind == GitHub {
+			body["draft"] = true
+		} else {
+			// Gitea marks work in progress by the title.
+			body["title"] = "WIP: " + pr.Title
+		}
+	}
+	var created apiIssue
+	if err := c.do(ctx, http.MethodPost, repoPath(owner, repo)+"/pulls", body, &created); err != nil {
+		return nil, err
+	}
+	result := pullRequestOf(created)
+	return &result, nil
+}
+
+func pullRequestOf(it apiIssue) PullRequest {
+	return PullRequest{
+		Number:  it.Number,
+		Title:   it.Title,
+		State:   it.State,
+		URL:     it.HTMLURL,
+		Author:  it.User.Login,
+		Head:    it.Head.Ref,
+		Base:    it.Base.Ref,
+		Draft:   it.Draft,
+		Merged:  it.Merged,
+		Updated: it.UpdatedAt,
+	}
+}
+
+func listQuery(state string, limit int, kind string) url.Values {
+	if state == "" {
+		state = "open"
+	}
+	if limit <= 0 || limit > MaxItems {
+		limit = MaxItems
+	}
+	q := url.Values{"state": {state}}
+	if kind == Gitea {
+		q.Set("limit", strconv.Itoa(limit))
+	} else {
+		q.Set("per_page", strconv.Itoa(limit))
+	}
+	return q
+}
+
+func repoPath(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+func (c *Client) do(ctx context.Context, method, apiPath string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+apiPath, reader)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		if c.kind == GitHub {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		} else {
+			req.Header.Set("Authorization", "token "+c.token)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			msg = apiErr.Message
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%s rejected the token", c.host)
+		case http.StatusNotFound:
+			what, _, _ := strings.Cut(strings.TrimPrefix(apiPath, "/repos/"), "?")
+			return fmt.Errorf("%s has no %s, or the token can't see it", c.host, what)
+		}
+		return fmt.Errorf("%s answered %s: %s", c.host, resp.Status, msg)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected answer from %s: %w", c.host, err)
+	}
+	return nil
+}
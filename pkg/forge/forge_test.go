@@ -0,0 +1,149 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testToken = "secret-token"
+
+// fakeForge serves the parts of the GitHub and Gitea APIs the client uses,
+// under prefix.
+type fakeForge struct {
+	prefix string
+	auth   string // The Authorization header expected
+	opened map[string]any
+}
+
+func (f *fakeForge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != f.auth {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method + " " + strings.TrimPrefix(r.URL.Path, f.prefix) {
+	case "GET /repos/alice/lamp/issues":
+		w.Write([]byte(`[
+			{"number": 3, "title": "Flickers at night", "state": "open", "html_url": "https://forge/3",
+			 "user": {"login": "bob"}, "labels": [{"name": "bug"}], "comments": 2},
+			{"number": 4, "title": "Add dimming", "state": "open", "pull_request": {"url": "x"}}
+		]`))
+	case "GET /repos/alice/lamp/pulls":
+		w.Write([]byte(`[{"number": 4, "title": "Add dimming", "state": "open", "user": {"login": "carol"},
+			"head": {"ref": "dimming"}, "base": {"ref": "main"}, "draft": true}]`))
+	case "GET /repos/alice/lamp":
+		w.Write([]byte(`{"default_branch": "trunk"}`))
+	case "POST /repos/alice/lamp/pulls":
+		json.NewDecoder(r.Body).Decode(&f.opened)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 5, "title": "x", "state": "open", "html_url": "https://forge/pull/5",
+			"head": {"ref": "fix"}, "base": {"ref": "trunk"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}
+}
+
+func newTestClient(t *testing.T, kind string) (*Client, *fakeForge) {
+	t.Helper()
+	f := &fakeForge{prefix: "/api/v3", auth: "Bearer " + testToken}
+	if kind == Gitea {
+		f.prefix, f.auth = "/api/v1", "token "+testToken
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	c, err := NewClient(kind, server.URL, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, f
+}
+
+func TestClient_Issues(t *testing.T) {
+	for _, kind := range []string{GitHub, Gitea} {
+		c, _ := newTestClient(t, kind)
+		issues, err := c.Issues(t.Context(), "alice", "lamp", "", 10)
+		if err != nil {
+			t.Fatalf("%s: Issues: %v", kind, err)
+		}
+		if len(issues) != 1 || issues[0].Number != 3 || issues[0].Author != "bob" ||
+			len(issues[0].Labels) != 1 || issues[0].Labels[0] != "bug" {
+			t.Errorf("%s: issues = %+v, want #3 only", kind, issues)
+		}
+	}
+}
+
+func TestClient_PullRequests(t *testing.T) {
+	c, _ := newTestClient(t, GitHub)
+	prs, err := c.PullRequests(t.Context(), "alice", "lamp", "open", 0)
+	if err != nil {
+		t.Fatalf("PullRequests: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Head != "dimming" || prs[0].Base != "main" || !prs[0].Draft {
+		t.Errorf("pull requests = %+v", prs)
+	}
+}
+
+func TestClient_CreatePullRequest(t *testing.T) {
+	c, f := newTestClient(t, Gitea)
+	pr, err := c.CreatePullRequest(t.Context(), "alice", "lamp",
+		NewPullRequest{Title: "Fix flicker", Body: "Fixes #3", Head: "fix", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if pr.Number != 5 || pr.URL != "https://forge/pull/5" {
+		t.Errorf("pull request = %+v", pr)
+	}
+	if f.opened["base"] != "trunk" || f.opened["title"] != "WIP: Fix flicker" || f.opened["head"] != "fix" {
+		t.Errorf("request = %v, want the default branch as base and a WIP title", f.opened)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	c, _ := newTestClient(t, GitHub)
+	_, err := c.Issues(t.Context(), "alice", "other", "", 0)
+	if err == nil || !strings.Contains(err.Error(), "has no alice/other/issues") {
+		t.Errorf("missing repository: err = %v", err)
+	}
+
+	c.token = "wrong"
+	if _, err := c.Issues(t.Context(), "alice", "lamp", "", 0); err == nil ||
+		!strings.Contains(err.Error(), "rejected the token") {
+		t.Errorf("bad token: err = %v", err)
+	}
+
+	if _, err := NewClient("gitlab", "https://gitlab.com", ""); err == nil {
+		t.Error("NewClient accepted an unknown kind")
+	}
+	c, err = NewClient(GitHub, "", "")
+	if err != nil || c.apiURL != "https://api.github.com" || c.Host() != "github.com" {
+		t.Errorf("default GitHub client = %+v, %v", c, err)
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   Remote
+	}{
+		{"https://github.com/alice/lamp.git", Remote{"github.com", "alice", "lamp"}},
+		{"https://git.lan:3000/alice/lamp", Remote{"git.lan", "alice", "lamp"}},
+		{"https://git.lan/gitea/alice/lamp.git/", Remote{"git.lan", "alice", "lamp"}},
+		{"git@github.com:alice/lamp.git", Remote{"github.com", "alice", "lamp"}},
+		{"ssh://git@git.lan:2222/alice/lamp.git", Remote{"git.lan", "alice", "lamp"}},
+		{"git.lan:alice/lamp", Remote{"git.lan", "alice", "lamp"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.remote)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v, want %+v", tt.remote, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "/srv/git/lamp.git", "https://github.com/lamp", "../lamp"} {
+		if got, err := ParseRemote(bad); err == nil {
+			t.Errorf("ParseRemote(%q) = %+v, want an error", bad, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remote is the repository a git remote points to on a forge.
+type Remote struct {
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// ParseRemote reads a git remote URL: https://host/owner/repo.git,
+// ssh://git@host:2222/owner/repo.git or the scp-like git@host:owner/repo.
+// Forges served under a path (https://host/git/owner/repo) are understood
+// too, the last two parts of the path being the owner and the repository.
+func ParseRemote(remote string) (Remote, error) {
+	remote = strings.TrimSpace(remote)
+	var host, repoPath string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return Remote{}, fmt.Errorf("%q is not a remote URL: %w", remote, err)
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else if at, p, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		repoPath = p
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git"), "/")
+	if host == "" || len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return Remote{}, fmt.Errorf("%q is not the remote of a repository on a forge", remote)
+	}
+	return Remote{Host: host, Owner: parts[len(parts)-2], Repo: parts[len(parts)-1]}, nil
+}
+
+// String returns owner/repo.
+func (r Remote) String() string {
+	return r.Owner + "/" + r.Repo
+}
@@ -0,0 +1,87 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package gpio reads and drives pins through the sysfs GPIO interface,
+// which the boards picoclaw runs on (LicheeRV, MaixCAM and the like)
+// provide.
+package gpio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Root is the sysfs GPIO directory, replaced in tests.
+var Root = "/sys/class/gpio"
+
+// Export makes pin available, if it isn't already, and sets its
+// direction: "in" or "out". Some pins are fixed inputs and refuse to
+// change direction, which is ignored for "in". A pin already in that
+// direction is left alone, since setting "out" also drives it low.
+func Export(pin int, direction string) error {
+	dir := filepath.Join(Root, fmt.Sprintf("gpio%d", pin))
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(filepath.Join(Root, "export"), []byte(strconv.Itoa(pin)), 0o200); err != nil {
+			return fmt.Errorf("export GPIO %d: %w", pin, err)
+		}
+		// udev may take a moment to set the new pin up.
+		for range 50 {
+			if _, err := os.Stat(filepath.Join(dir, "value")); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	directionPath := filepath.Join(dir, "direction")
+	if current, err := os.ReadFile(directionPath); err == nil && strings.TrimSpace(string(current)) == direction {
+		return nil
+	}
+	err := os.WriteFile(directionPath, []byte(direction), 0o200)
+	if err != nil && direction != "in" {
+		return fmt.Errorf("set GPIO %d as an output: %w", pin, err)
+	}
+	return nil
+}
+
+// ValuePath returns the file holding the value of pin.
+func ValuePath(pin int) string {
+	return filepath.Join(Root, fmt.Sprintf("gpio%d", pin), "value")
+}
+
+// Read returns the value of pin, 0 or 1.
+func Read(pin int) (int, error) {
+	return ReadFile(ValuePath(pin))
+}
+
+// ReadFile returns the value in a pin's value file, for polling it.
+func ReadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	switch strings.TrimSpace(string(data)) {
+	case "0":
+		return 0, nil
+	case "1":
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unexpected GPIO value %q", data)
+}
+
+// Write sets an output pin to value, 0 or 1.
+func Write(pin, value int) error {
+	if value != 0 && value != 1 {
+		return fmt.Errorf("GPIO value %d is not 0 or 1", value)
+	}
+	if err := os.WriteFile(ValuePath(pin), []byte(strconv.Itoa(value)), 0o200); err != nil {
+		return fmt.Errorf("write GPIO %d: %w", pin, err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportWriteRead(t *testing.T) {
+	Root = t.TempDir()
+	defer func() { Root = "/sys/class/gpio" }()
+	os.MkdirAll(filepath.Join(Root, "gpio4"), 0o755)
+
+	if err := Export(4, "out"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(Root, "gpio4", "direction")); string(data) != "out" {
+		t.Errorf("direction = %q, want out", data)
+	}
+	if err := Write(4, 1); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Read(4); err != nil || v != 1 {
+		t.Errorf("Read() = %d, %v, want 1", v, err)
+	}
+	if err := Write(4, 2); err == nil {
+		t.Error("Write(4, 2) succeeded")
+	}
+	os.WriteFile(ValuePath(4), []byte("high\n"), 0o644)
+	if _, err := Read(4); err == nil {
+		t.Error("Read() of a garbled value succeeded")
+	}
+}
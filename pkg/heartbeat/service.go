@@ -26,21 +26,38 @@ const (
 	defaultIntervalMinutes = 30
 )
 
+// OKToken is the reply of a heartbeat that found nothing needing attention.
+const OKToken = "HEARTBEAT_OK"
+
 // HeartbeatHandler is the function type for handling heartbeat.
 // It returns a ToolResult that can indicate async operations.
 // channel and chatID are derived from the last active user channel.
 type HeartbeatHandler func(prompt, channel, chatID string) *tools.ToolResult
 
+// PromptSection returns text to add to a heartbeat's prompt, or "" for none.
+type PromptSection func() string
+
 // HeartbeatService manages periodic heartbeat checks
 type HeartbeatService struct {
 	workspace string
 	bus       *bus.MessageBus
 	state     *state.Manager
 	handler   HeartbeatHandler
+	sections  []PromptSection
 	interval  time.Duration
 	enabled   bool
 	mu        sync.RWMutex
 	stopChan  chan struct{}
+
+	// Quiet hours, in minutes since midnight in quietLoc; see SetQuietHours.
+	quiet      bool
+	quietStart int
+	quietEnd   int
+	quietLoc   *time.Location
+
+	maxRuns int    // Heartbeats allowed per day; 0 means no limit
+	runs    int    // Heartbeats run on runsDay
+	runsDay string // Local date, YYYY-MM-DD
 }
 
 // NewHeartbeatService creates a new heartbeat service
@@ -76,6 +93,82 @@ func (hs *HeartbeatService) SetHandler(handler HeartbeatHandler) {
 	hs.handler = handler
 }
 
+// AddSection adds the text of section to each heartbeat's prompt, after the
+// tasks of HEARTBEAT.md. A heartbeat runs when a section has text even if
+// HEARTBEAT.md is empty.
+func (hs *HeartbeatService) AddSection(section PromptSection) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.sections = append(hs.sections, section)
+}
+
+// SetQuietHours makes heartbeats skip the daily window spec, like
+// "22:00-07:00", in time zone tz or, when empty, the local one. An empty
+// spec clears the window.
+func (hs *HeartbeatService) SetQuietHours(spec, tz string) error {
+	if spec == "" {
+		hs.mu.Lock()
+		hs.quiet = false
+		hs.mu.Unlock()
+		return nil
+	}
+	start, end, err := ParseQuietHours(spec)
+	if err != nil {
+		return err
+	}
+	loc := time.Local
+	if tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.quiet, hs.quietStart, hs.quietEnd, hs.quietLoc = true, start, end, loc
+	return nil
+}
+
+// SetMaxRunsPerDay limits the heartbeats run per local day; 0 means no limit.
+func (hs *HeartbeatService) SetMaxRunsPerDay(n int) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.maxRuns = n
+}
+
+// ParseQuietHours reads a daily "HH:MM-HH:MM" window into minutes since
+// midnight. The window may wrap past midnight.
+func ParseQuietHours(spec string) (start, end int, err error) {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", spec)
+	}
+	if start, err = minuteOfDay(from); err != nil {
+		return 0, 0, fmt.Errorf("quiet hours %q: %w", spec, err)
+	}
+	if end, err = minuteOfDay(to); err != nil {
+		return 0, 0, fmt.Errorf("quiet hours %q: %w", spec, err)
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("quiet hours %q: the window is empty", spec)
+	}
+	return start, end, nil
+}
+
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", strings.TrimSpace(clock))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsOK reports whether a heartbeat reply only says nothing needs attention.
+// Models sometimes dress the token up in markdown or punctuation.
+func IsOK(response string) bool {
+	return strings.Trim(response, " \t\r\n*`_.!") == OKToken
+}
+
 // Start begins the heartbeat service
 func (hs *HeartbeatService) Start() error {
 	hs.mu.Lock()
@@ -157,11 +250,16 @@ func (hs *HeartbeatService) executeHeartbeat() {
 		return
 	}
 
+	if reason := hs.skipReason(time.Now()); reason != "" {
+		logger.DebugCF("heartbeat", "Heartbeat skipped", map[string]any{"reason": reason})
+		return
+	}
+
 	logger.DebugC("heartbeat", "Executing heartbeat")
 
 	prompt := hs.buildPrompt()
 	if prompt == "" {
-		logger.InfoC("heartbeat", "No heartbeat prompt (HEARTBEAT.md empty or missing)")
+		logger.InfoC("heartbeat", "No heartbeat prompt (HEARTBEAT.md empty or missing, and nothing else to check)")
 		return
 	}
 
@@ -177,6 +275,7 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	// Debug log for channel resolution
 	hs.logInfof("Resolved channel: %s, chatID: %s (from lastChannel: %s)", channel, chatID, lastChannel)
 
+	hs.countRun(time.Now())
 	result := handler(prompt, channel, chatID)
 
 	if result == nil {
@@ -215,7 +314,44 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	hs.logInfof("Heartbeat completed: %s", result.ForLLM)
 }
 
-// buildPrompt builds the heartbeat prompt from HEARTBEAT.md
+// skipReason returns why no heartbeat may run at now, or "": quiet hours, or
+// the day's heartbeats are used up.
+func (hs *HeartbeatService) skipReason(now time.Time) string {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+
+	if hs.quiet {
+		local := now.In(hs.quietLoc)
+		m := local.Hour()*60 + local.Minute()
+		var inside bool
+		if hs.quietStart < hs.quietEnd {
+			inside = m >= hs.quietStart && m < hs.quietEnd
+		} else {
+			inside = m >= hs.quietStart || m < hs.quietEnd
+		}
+		if inside {
+			return "quiet hours"
+		}
+	}
+	if hs.maxRuns > 0 && hs.runsDay == now.Format(time.DateOnly) && hs.runs >= hs.maxRuns {
+		return fmt.Sprintf("%d heartbeats already ran today", hs.runs)
+	}
+	return ""
+}
+
+// countRun counts a heartbeat against the day's limit.
+func (hs *HeartbeatService) countRun(now time.Time) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if day := now.Format(time.DateOnly); hs.runsDay != day {
+		hs.runsDay, hs.runs = day, 0
+	}
+	hs.runs++
+}
+
+// buildPrompt builds the heartbeat prompt from HEARTBEAT.md and the added
+// sections
 func (hs *HeartbeatService) buildPrompt() string {
 	heartbeatPath := filepath.Join(hs.workspace, "HEARTBEAT.md")
 
@@ -223,13 +359,21 @@ func (hs *HeartbeatService) buildPrompt() string {
 	if err != nil {
 		if os.IsNotExist(err) {
 			hs.createDefaultHeartbeatTemplate()
-			return ""
+		} else {
+			hs.logErrorf("Error reading HEARTBEAT.md: %v", err)
 		}
-		hs.logErrorf("Error reading HEARTBEAT.md: %v", err)
-		return ""
 	}
 
 	content := string(data)
+	hs.mu.RLock()
+	sections := hs.sections
+	hs.mu.RUnlock()
+	for _, section := range sections {
+		if text := section(); text != "" {
+			content = strings.TrimRight(content, "\n") + "\n\n" + text
+		}
+	}
+	content = strings.TrimLeft(content, "\n")
 	if len(content) == 0 {
 		return ""
 	}
@@ -241,6 +385,8 @@ Current time: %s
 
 You are a proactive AI assistant. This is a scheduled heartbeat check.
 Review the following tasks and execute any necessary actions using available skills.
+If something needs the user's attention, reply with a short message for them; it is
+sent to the chat they used last.
 If there is nothing that requires attention, respond ONLY with: HEARTBEAT_OK
 
 %s
@@ -3,6 +3,7 @@ package heartbeat
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -219,3 +220,95 @@ func TestHeartbeatFilePath(t *testing.T) {
 		t.Errorf("Expected HEARTBEAT.md at %s, but it doesn't exist", expectedPath)
 	}
 }
+
+func TestExecuteHeartbeat_QuietHoursAndDailyLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Check the greenhouse"), 0o644)
+
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+	calls := 0
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		calls++
+		return tools.SilentResult("Heartbeat OK")
+	})
+
+	// A window covering the whole day but one minute is quiet now.
+	now := time.Now()
+	spec := now.Add(-time.Minute).Format("15:04") + "-" + now.Add(-2*time.Minute).Format("15:04")
+	if err := hs.SetQuietHours(spec, ""); err != nil {
+		t.Fatal(err)
+	}
+	hs.executeHeartbeat()
+	if calls != 0 {
+		t.Fatalf("heartbeat ran during quiet hours %s", spec)
+	}
+
+	hs.SetQuietHours("", "")
+	hs.SetMaxRunsPerDay(2)
+	for range 3 {
+		hs.executeHeartbeat()
+	}
+	if calls != 2 {
+		t.Errorf("heartbeats run = %d, want 2", calls)
+	}
+}
+
+func TestBuildPrompt_Sections(t *testing.T) {
+	tmpDir := t.TempDir()
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	overdue := ""
+	hs.AddSection(func() string { return overdue })
+
+	// An empty HEARTBEAT.md and no overdue tasks: nothing to check.
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), nil, 0o644)
+	if prompt := hs.buildPrompt(); prompt != "" {
+		t.Errorf("prompt with nothing to check = %q", prompt)
+	}
+
+	overdue = "## Overdue Tasks\n\n- [1] Pay rent"
+	prompt := hs.buildPrompt()
+	if !strings.HasSuffix(prompt, "HEARTBEAT_OK\n\n## Overdue Tasks\n\n- [1] Pay rent\n") {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("- Check the greenhouse\n"), 0o644)
+	prompt = hs.buildPrompt()
+	if !strings.Contains(prompt, "- Check the greenhouse\n\n## Overdue Tasks") {
+		t.Errorf("prompt = %q", prompt)
+	}
+}
+
+func TestSkipReason_QuietHours(t *testing.T) {
+	hs := NewHeartbeatService(t.TempDir(), 30, true)
+	if err := hs.SetQuietHours("22:00-07:00", "Europe/Paris"); err != nil {
+		t.Fatal(err)
+	}
+	paris, _ := time.LoadLocation("Europe/Paris")
+	for clock, quiet := range map[string]bool{"21:59": false, "22:00": true, "03:30": true, "06:59": true, "07:00": false} {
+		at, _ := time.ParseInLocation("2006-01-02 15:04", "2026-10-15 "+clock, paris)
+		if got := hs.skipReason(at.UTC()) != ""; got != quiet {
+			t.Errorf("quiet at %s = %v, want %v", clock, got, quiet)
+		}
+	}
+
+	for _, spec := range []string{"22:00", "22-07", "07:00-07:00", "25:00-07:00"} {
+		if err := hs.SetQuietHours(spec, ""); err == nil {
+			t.Errorf("SetQuietHours(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestIsOK(t *testing.T) {
+	for response, ok := range map[string]bool{
+		"HEARTBEAT_OK":                        true,
+		"**HEARTBEAT_OK**\n":                  true,
+		"HEARTBEAT_OK.":                       true,
+		"The basil needs water.":              false,
+		"The basil needs water. HEARTBEAT_OK": false,
+	} {
+		if got := IsOK(response); got != ok {
+			t.Errorf("IsOK(%q) = %v, want %v", response, got, ok)
+		}
+	}
+}
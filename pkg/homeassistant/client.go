@@ -0,0 +1,269 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package homeassistant is a client of the Home Assistant REST and WebSocket
+// APIs, limited to an allowlist of entities.
+package homeassistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	maxResponse    = 8 << 20
+)
+
+// State is the state of an entity.
+type State struct {
+	EntityID    string         `json:"entity_id"`
+	State       string         `json:"state"`
+	Attributes  map[string]any `json:"attributes"`
+	LastChanged time.Time      `json:"last_changed"`
+}
+
+// Name returns the entity's friendly name, or its ID.
+func (s *State) Name() string {
+	if name, ok := s.Attributes["friendly_name"].(string); ok && name != "" {
+		return name
+	}
+	return s.EntityID
+}
+
+// Domain returns the domain of the entity, such as "light".
+func (s *State) Domain() string {
+	domain, _, _ := strings.Cut(s.EntityID, ".")
+	return domain
+}
+
+// Service is a service of a domain, such as light.turn_on.
+type Service struct {
+	Domain      string
+	Name        string
+	Description string
+	Fields      []string // Names of the data it takes
+}
+
+// Client calls a Home Assistant instance with a long-lived access token. It
+// only reads, changes and reports the entities its allowlist matches.
+type Client struct {
+	baseURL string
+	token   string
+	allow   []string
+	http    *http.Client
+}
+
+// NewClient returns a client of the instance at baseURL, such as
+// http://homeassistant.local:8123. allow holds the entity IDs it may use, as
+// globs such as "light.*"; an empty list allows none.
+func NewClient(baseURL, token string, allow []string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		allow:   allow,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Allowed reports whether the allowlist matches entityID.
+func (c *Client) Allowed(entityID string) bool {
+	domain, object, ok := strings.Cut(entityID, ".")
+	if !ok || !validName(domain) || !validName(object) {
+		return false
+	}
+	for _, pattern := range c.allow {
+		if ok, _ := path.Match(pattern, entityID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// States returns the states of the allowed entities, sorted by ID.
+func (c *Client) States(ctx context.Context) ([]State, error) {
+	var all []State
+	if err := c.do(ctx, http.MethodGet, "/api/states", nil, &all); err != nil {
+		return nil, err
+	}
+	var states []State
+	for _, s := range all {
+		if c.Allowed(s.EntityID) {
+			states = append(states, s)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].EntityID < states[j].EntityID })
+	return states, nil
+}
+
+// State returns the state of an allowed entity.
+func (c *Client) State(ctx context.Context, entityID string) (*State, error) {
+	if !c.Allowed(entityID) {
+		return nil, fmt.Errorf("entity %s is not allowed", entityID)
+	}
+	var s State
+	if err := c.do(ctx, http.MethodGet, "/api/states/"+entityID, nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CallService calls domain.service on the entities in data["entity_id"],
+// which must all be allowed, and returns the states it changed.
+func (c *Client) CallService(ctx context.Context, domain, service string, data map[string]any) ([]State, error) {
+	if !validName(domain) || !validName(service) {
+		return nil, fmt.Errorf("invalid service %s.%s", domain, service)
+	}
+	targets := EntityIDs(data["entity_id"])
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("a service call needs entity_id, the entities it acts on")
+	}
+	for _, id := range targets {
+		if !c.Allowed(id) {
+			return nil, fmt.Errorf("entity %s is not allowed", id)
+		}
+	}
+	var changed []State
+	if err := c.do(ctx, http.MethodPost, "/api/services/"+domain+"/"+service, data, &changed); err != nil {
+		return nil, err
+	}
+	var out []State
+	for _, s := range changed {
+		if c.Allowed(s.EntityID) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// Services returns the services of the domains of the allowed entities,
+// or of domain alone when set.
+func (c *Client) Services(ctx context.Context, domain string) ([]Service, error) {
+	states, err := c.States(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domains := make(map[string]bool)
+	for _, s := range states {
+		if domain == "" || s.Domain() == domain {
+			domains[s.Domain()] = true
+		}
+	}
+
+	var all []struct {
+		Domain   string `json:"domain"`
+		Services map[string]struct {
+			Description string         `json:"description"`
+			Fields      map[string]any `json:"fields"`
+		} `json:"services"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/services", nil, &all); err != nil {
+		return nil, err
+	}
+	var services []Service
+	for _, d := range all {
+		if !domains[d.Domain] {
+			continue
+		}
+		for name, svc := range d.Services {
+			fields := make([]string, 0, len(svc.Fields))
+			for f := range svc.Fields {
+				fields = append(fields, f)
+			}
+			sort.Strings(fields)
+			services = append(services, Service{Domain: d.Domain, Name: name, Description: svc.Description, Fields: fields})
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Domain != services[j].Domain {
+			return services[i].Domain < services[j].Domain
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// EntityIDs reads an entity_id value: one ID, IDs separated by commas, or a
+// list of them.
+func EntityIDs(v any) []string {
+	var ids []string
+	switch v := v.(type) {
+	case string:
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if id, ok := item.(string); ok && strings.TrimSpace(id) != "" {
+				ids = append(ids, strings.TrimSpace(id))
+			}
+		}
+	case []string:
+		ids = v
+	}
+	return ids
+}
+
+// validName reports whether s can be a domain or service name.
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) do(ctx context.Context, method, apiPath string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+apiPath, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("home assistant rejected the access token")
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("home assistant has no %s", strings.TrimPrefix(apiPath, "/api/"))
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("home assistant answered %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected answer from home assistant: %w", err)
+	}
+	return nil
+}
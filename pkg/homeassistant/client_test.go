@@ -0,0 +1,203 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+const testToken = "secret-token"
+
+// fakeHA serves the parts of the Home Assistant API the client uses.
+type fakeHA struct {
+	mu     sync.Mutex
+	states map[string]*State
+	calls  []string // "domain.service entity_ids"
+	events []Event  // Sent to websocket subscribers after subscribing
+}
+
+func newFakeHA(t *testing.T) (*fakeHA, *httptest.Server) {
+	t.Helper()
+	ha := &fakeHA{states: map[string]*State{
+		"light.kitchen": {EntityID: "light.kitchen", State: "off",
+			Attributes: map[string]any{"friendly_name": "Kitchen light"}},
+		"sensor.outside_temperature": {EntityID: "sensor.outside_temperature", State: "12.5",
+			Attributes: map[string]any{"friendly_name": "Outside", "unit_of_measurement": "°C"}},
+		"lock.front_door": {EntityID: "lock.front_door", State: "locked"},
+	}}
+	server := httptest.NewServer(ha)
+	t.Cleanup(server.Close)
+	return ha, server
+}
+
+func (ha *fakeHA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/websocket" {
+		ha.serveWebsocket(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	ha.mu.Lock()
+	defer ha.mu.Unlock()
+	switch {
+	case r.URL.Path == "/api/states":
+		var all []*State
+		for _, s := range ha.states {
+			all = append(all, s)
+		}
+		json.NewEncoder(w).Encode(all)
+	case strings.HasPrefix(r.URL.Path, "/api/states/"):
+		s, ok := ha.states[strings.TrimPrefix(r.URL.Path, "/api/states/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(s)
+	case r.URL.Path == "/api/services" && r.Method == http.MethodGet:
+		w.Write([]byte(`[
+			{"domain": "light", "services": {
+				"turn_on": {"description": "Turn a light on", "fields": {"brightness": {}, "color_name": {}}},
+				"turn_off": {"description": "Turn a light off", "fields": {}}}},
+			{"domain": "lock", "services": {"unlock": {"description": "Unlock", "fields": {"code": {}}}}},
+			{"domain": "script", "services": {"reload": {"description": "Reload scripts", "fields": {}}}}]`))
+	case strings.HasPrefix(r.URL.Path, "/api/services/") && r.Method == http.MethodPost:
+		var data map[string]any
+		json.NewDecoder(r.Body).Decode(&data)
+		service := strings.ReplaceAll(strings.TrimPrefix(r.URL.Path, "/api/services/"), "/", ".")
+		ids := EntityIDs(data["entity_id"])
+		ha.calls = append(ha.calls, service+" "+strings.Join(ids, ","))
+		var changed []*State
+		for _, id := range ids {
+			if s, ok := ha.states[id]; ok {
+				if strings.HasSuffix(service, ".turn_on") {
+					s.State = "on"
+				}
+				changed = append(changed, s)
+			}
+		}
+		json.NewEncoder(w).Encode(changed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (ha *fakeHA) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.WriteJSON(map[string]string{"type": "auth_required"})
+	var auth map[string]any
+	if conn.ReadJSON(&auth) != nil {
+		return
+	}
+	if auth["access_token"] != testToken {
+		conn.WriteJSON(map[string]string{"type": "auth_invalid"})
+		return
+	}
+	conn.WriteJSON(map[string]string{"type": "auth_ok"})
+
+	var sub map[string]any
+	if conn.ReadJSON(&sub) != nil {
+		return
+	}
+	if sub["event_type"] == "forbidden" {
+		conn.WriteJSON(map[string]any{"id": sub["id"], "type": "result", "success": false,
+			"error": map[string]string{"message": "Unauthorized"}})
+		return
+	}
+	conn.WriteJSON(map[string]any{"id": sub["id"], "type": "result", "success": true})
+	ha.mu.Lock()
+	events := ha.events
+	ha.mu.Unlock()
+	for _, ev := range events {
+		if sub["event_type"] == nil || sub["event_type"] == ev.Type {
+			conn.WriteJSON(map[string]any{"id": sub["id"], "type": "event", "event": ev})
+		}
+	}
+	conn.ReadJSON(&sub) // Until the client goes
+}
+
+func TestClient_States(t *testing.T) {
+	_, server := newFakeHA(t)
+	c := NewClient(server.URL+"/", testToken, []string{"light.*", "sensor.outside_*"})
+	ctx := context.Background()
+
+	states, err := c.States(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || states[0].EntityID != "light.kitchen" || states[1].Name() != "Outside" {
+		t.Errorf("States() = %+v", states)
+	}
+
+	s, err := c.State(ctx, "sensor.outside_temperature")
+	if err != nil || s.State != "12.5" {
+		t.Errorf("State() = %+v, %v", s, err)
+	}
+	for _, id := range []string{"lock.front_door", "light.missing/../../config", "light"} {
+		if _, err := c.State(ctx, id); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("State(%q) = %v, want not allowed", id, err)
+		}
+	}
+	if _, err := c.State(ctx, "light.missing"); err == nil {
+		t.Error("State() of a missing entity succeeded")
+	}
+
+	if _, err := NewClient(server.URL, "wrong", []string{"*"}).States(ctx); err == nil ||
+		!strings.Contains(err.Error(), "access token") {
+		t.Errorf("States() with a wrong token = %v", err)
+	}
+	if states, _ := NewClient(server.URL, testToken, nil).States(ctx); len(states) != 0 {
+		t.Errorf("States() with an empty allowlist = %+v", states)
+	}
+}
+
+func TestClient_CallService(t *testing.T) {
+	ha, server := newFakeHA(t)
+	c := NewClient(server.URL, testToken, []string{"light.*", "sensor.*"})
+	ctx := context.Background()
+
+	changed, err := c.CallService(ctx, "light", "turn_on", map[string]any{
+		"entity_id": "light.kitchen", "brightness": 200,
+	})
+	if err != nil || len(changed) != 1 || changed[0].State != "on" {
+		t.Fatalf("CallService() = %+v, %v", changed, err)
+	}
+
+	for _, data := range []map[string]any{
+		{"entity_id": []any{"light.kitchen", "lock.front_door"}},
+		{},
+	} {
+		if _, err := c.CallService(ctx, "homeassistant", "turn_off", data); err == nil {
+			t.Errorf("CallService(%v) succeeded", data)
+		}
+	}
+	if _, err := c.CallService(ctx, "light", "../../states", map[string]any{"entity_id": "light.kitchen"}); err == nil {
+		t.Error("CallService() of an invalid service succeeded")
+	}
+	if len(ha.calls) != 1 || ha.calls[0] != "light.turn_on light.kitchen" {
+		t.Errorf("calls = %v", ha.calls)
+	}
+
+	services, err := c.Services(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range services {
+		names = append(names, s.Domain+"."+s.Name+"("+strings.Join(s.Fields, ",")+")")
+	}
+	if got := strings.Join(names, " "); got != "light.turn_off() light.turn_on(brightness,color_name)" {
+		t.Errorf("Services() = %s", got)
+	}
+}
@@ -0,0 +1,160 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event is an event of the Home Assistant event bus.
+type Event struct {
+	Type      string         `json:"event_type"`
+	Data      map[string]any `json:"data"`
+	TimeFired time.Time      `json:"time_fired"`
+}
+
+// EntityIDs returns the entities the event is about: its entity_id, or
+// that of the service call it reports.
+func (e *Event) EntityIDs() []string {
+	if ids := EntityIDs(e.Data["entity_id"]); len(ids) > 0 {
+		return ids
+	}
+	if data, ok := e.Data["service_data"].(map[string]any); ok {
+		return EntityIDs(data["entity_id"])
+	}
+	return nil
+}
+
+// States returns the states before and after a state_changed event; either
+// is nil when the entity was added or removed.
+func (e *Event) States() (before, after *State) {
+	return stateOf(e.Data["old_state"]), stateOf(e.Data["new_state"])
+}
+
+func stateOf(v any) *State {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var s State
+	if json.Unmarshal(data, &s) != nil || s.EntityID == "" {
+		return nil
+	}
+	return &s
+}
+
+type wsMessage struct {
+	ID      int    `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Success bool   `json:"success,omitempty"`
+	Message string `json:"message,omitempty"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Event Event `json:"event"`
+}
+
+// Subscribe reports to fn the events of eventTypes, or of all types when
+// empty, until ctx is done or the connection fails. Events about entities
+// not allowed are left out; events about no entity are reported.
+func (c *Client) Subscribe(ctx context.Context, eventTypes []string, fn func(Event)) error {
+	wsURL := c.baseURL + "/api/websocket"
+	switch {
+	case strings.HasPrefix(wsURL, "https://"):
+		wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+	case strings.HasPrefix(wsURL, "http://"):
+		wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+	default:
+		return fmt.Errorf("invalid home assistant address %q", c.baseURL)
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: requestTimeout}
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		return err
+	}
+	if msg.Type == "auth_required" {
+		if err := conn.WriteJSON(map[string]string{"type": "auth", "access_token": c.token}); err != nil {
+			return err
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+	}
+	if msg.Type != "auth_ok" {
+		return errors.New("home assistant rejected the access token")
+	}
+
+	if len(eventTypes) == 0 {
+		eventTypes = []string{""}
+	}
+	for i, eventType := range eventTypes {
+		req := map[string]any{"id": i + 1, "type": "subscribe_events"}
+		if eventType != "" {
+			req["event_type"] = eventType
+		}
+		if err := conn.WriteJSON(req); err != nil {
+			return err
+		}
+	}
+
+	for {
+		msg = wsMessage{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		switch msg.Type {
+		case "result":
+			if !msg.Success {
+				reason := "unknown error"
+				if msg.Error != nil {
+					reason = msg.Error.Message
+				}
+				return fmt.Errorf("can't subscribe to events: %s", reason)
+			}
+		case "event":
+			if c.allowedEvent(&msg.Event) {
+				fn(msg.Event)
+			}
+		}
+	}
+}
+
+func (c *Client) allowedEvent(e *Event) bool {
+	for _, id := range e.EntityIDs() {
+		if !c.Allowed(id) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package homeassistant
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Subscribe(t *testing.T) {
+	ha, server := newFakeHA(t)
+	ha.events = []Event{
+		{Type: "state_changed", Data: map[string]any{
+			"entity_id": "lock.front_door",
+			"old_state": map[string]any{"entity_id": "lock.front_door", "state": "locked"},
+			"new_state": map[string]any{"entity_id": "lock.front_door", "state": "unlocked"},
+		}},
+		{Type: "state_changed", Data: map[string]any{"entity_id": "light.kitchen"}},
+		{Type: "call_service", Data: map[string]any{"service_data": map[string]any{"entity_id": "light.kitchen"}}},
+		{Type: "doorbell_pressed", Data: map[string]any{}},
+	}
+	c := NewClient(server.URL, testToken, []string{"lock.*"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []Event
+	err := c.Subscribe(ctx, nil, func(ev Event) {
+		got = append(got, ev)
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("Subscribe() = %v, want context.Canceled", err)
+	}
+	if got[0].Type != "state_changed" || got[1].Type != "doorbell_pressed" {
+		t.Fatalf("events = %+v, want those of allowed entities or of none", got)
+	}
+	before, after := got[0].States()
+	if before == nil || after == nil || before.State != "locked" || after.State != "unlocked" {
+		t.Errorf("States() = %+v, %+v", before, after)
+	}
+
+	err = c.Subscribe(context.Background(), []string{"forbidden"}, func(Event) {})
+	if err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Subscribe() to a forbidden event type = %v", err)
+	}
+	err = NewClient(server.URL, "wrong", nil).Subscribe(context.Background(), nil, func(Event) {})
+	if err == nil || !strings.Contains(err.Error(), "access token") {
+		t.Errorf("Subscribe() with a wrong token = %v", err)
+	}
+}
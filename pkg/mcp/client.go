@@ -0,0 +1,326 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package mcp is a client for the Model Context Protocol, which lets the
+// agent use the tools of MCP servers (filesystem, GitHub, Home Assistant
+// and so on). Servers are reached over stdio, as a child process, or over
+// HTTP with server-sent events.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ProtocolVersion is the MCP revision the client speaks.
+const ProtocolVersion = "2024-11-05"
+
+var errClosed = errors.New("connection closed")
+
+// transport carries JSON-RPC messages to and from a server.
+type transport interface {
+	// send delivers one message.
+	send(ctx context.Context, msg []byte) error
+	// messages yields the server's messages; it is closed when the
+	// connection ends, after which err says why.
+	messages() <-chan []byte
+	err() error
+	close() error
+}
+
+// Tool is a tool a server offers.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// Content is one part of a tool's result.
+type Content struct {
+	Type     string `json:"type"` // text, image, audio or resource
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"` // Base64, for images and audio
+	MIMEType string `json:"mimeType,omitempty"`
+	Resource *struct {
+		URI  string `json:"uri"`
+		Text string `json:"text,omitempty"`
+	} `json:"resource,omitempty"`
+}
+
+// CallResult is the result of a tool call. IsError means the tool failed,
+// as opposed to the call.
+type CallResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Text joins the result's content into text, describing what isn't text.
+func (r *CallResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch {
+		case c.Type == "text":
+			parts = append(parts, c.Text)
+		case c.Type == "resource" && c.Resource != nil:
+			parts = append(parts, fmt.Sprintf("[%s]\n%s", c.Resource.URI, c.Resource.Text))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s content, %s, %d bytes]", c.Type, c.MIMEType, len(c.Data)*3/4))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// RPCError is an error answer from a server.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// Client is a connection to one MCP server.
+type Client struct {
+	name   string
+	t      transport
+	nextID atomic.Int64
+
+	mu      sync.Mutex
+	pending map[int64]chan rpcMessage
+	done    chan struct{}
+
+	// ServerName and ServerVersion are what the server calls itself.
+	ServerName    string
+	ServerVersion string
+}
+
+// newClient starts reading the server's messages; call initialize next.
+func newClient(name string, t transport) *Client {
+	c := &Client{name: name, t: t, pending: make(map[int64]chan rpcMessage), done: make(chan struct{})}
+	go c.readLoop()
+	return c
+}
+
+// Name is the server's name in the config.
+func (c *Client) Name() string {
+	return c.name
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	for data := range c.t.messages() {
+		var msg struct {
+			rpcMessage
+			Params json.RawMessage `json:"params,omitempty"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.WarnCF("mcp", "Ignoring a malformed message", map[string]any{"server": c.name, "error": err.Error()})
+			continue
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil:
+			c.answer(msg.ID, msg.Method)
+		case msg.Method != "":
+			logger.DebugCF("mcp", "Notification", map[string]any{"server": c.name, "method": msg.Method})
+		default:
+			var id int64
+			if json.Unmarshal(msg.ID, &id) != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch := c.pending[id]
+			delete(c.pending, id)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg.rpcMessage
+			}
+		}
+	}
+}
+
+// answer replies to a request from the server: pings are answered, and
+// the features the client doesn't offer (sampling, roots) refused.
+func (c *Client) answer(id json.RawMessage, method string) {
+	reply := rpcMessage{JSONRPC: "2.0", ID: id}
+	if method == "ping" {
+		reply.Result = json.RawMessage("{}")
+	} else {
+		reply.Error = &RPCError{Code: -32601, Message: "method not supported: " + method}
+	}
+	data, _ := json.Marshal(reply)
+	go func() {
+		if err := c.t.send(context.Background(), data); err != nil {
+			logger.DebugCF("mcp", "Failed to answer the server", map[string]any{"server": c.name, "error": err.Error()})
+		}
+	}()
+}
+
+// call sends a request and decodes the result into result.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	id := c.nextID.Add(1)
+	ch := make(chan rpcMessage, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	req := rpcMessage{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(id)), Method: method, Params: params}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if err := c.t.send(ctx, data); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		if err := c.t.err(); err != nil {
+			return fmt.Errorf("%s: the connection ended: %w", method, err)
+		}
+		return fmt.Errorf("%s: the connection ended", method)
+	case msg := <-ch:
+		if msg.Error != nil {
+			return fmt.Errorf("%s: %w", method, msg.Error)
+		}
+		if result != nil {
+			return json.Unmarshal(msg.Result, result)
+		}
+		return nil
+	}
+}
+
+func (c *Client) notify(ctx context.Context, method string) error {
+	data, err := json.Marshal(rpcMessage{JSONRPC: "2.0", Method: method})
+	if err != nil {
+		return err
+	}
+	return c.t.send(ctx, data)
+}
+
+// initialize performs the protocol handshake.
+func (c *Client) initialize(ctx context.Context) error {
+	var res struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "picoclaw", "version": "1.0"},
+	}
+	if err := c.call(ctx, "initialize", params, &res); err != nil {
+		return err
+	}
+	c.ServerName, c.ServerVersion = res.ServerInfo.Name, res.ServerInfo.Version
+	return c.notify(ctx, "notifications/initialized")
+}
+
+// ListTools returns the server's tools.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var (
+		tools  []Tool
+		cursor string
+	)
+	for {
+		var res struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		var params map[string]any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		if err := c.call(ctx, "tools/list", params, &res); err != nil {
+			return nil, err
+		}
+		tools = append(tools, res.Tools...)
+		if res.NextCursor == "" || res.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// CallTool runs one of the server's tools.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*CallResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	var res CallResult
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Close ends the connection, stopping the server if the client started it.
+func (c *Client) Close() error {
+	err := c.t.close()
+	<-c.done
+	return err
+}
+
+// ServerConfig says how to reach a server: either a Command to run, or the URL
+// of its SSE endpoint.
+type ServerConfig struct {
+	Command string
+	Args    []string
+	Env     map[string]string
+	Dir     string
+	URL     string
+	Headers map[string]string
+}
+
+// Connect connects to a server and performs the handshake; ctx limits how
+// long that takes.
+func Connect(ctx context.Context, name string, server ServerConfig) (*Client, error) {
+	var (
+		t   transport
+		err error
+	)
+	switch {
+	case server.Command != "":
+		t, err = startStdio(name, server.Command, server.Args, server.Env, server.Dir)
+	case server.URL != "":
+		t, err = startSSE(ctx, server.URL, server.Headers)
+	default:
+		return nil, errors.New("no command or url")
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := newClient(name, t)
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer answers requests the way an MCP server with an "echo" and a
+// "fail" tool would, listing them over two pages.
+func fakeServer(line []byte) []byte {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+			Cursor    string         `json:"cursor"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(line, &req) != nil || req.ID == nil {
+		return nil // Notifications get no answer
+	}
+	var result any
+	switch req.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "fake", "version": "0.1"},
+		}
+	case "tools/list":
+		if req.Params.Cursor == "" {
+			result = map[string]any{"tools": []Tool{{Name: "echo", Description: "Echoes"}}, "nextCursor": "2"}
+		} else {
+			result = map[string]any{"tools": []Tool{{Name: "fail"}}}
+		}
+	case "tools/call":
+		if req.Params.Name == "fail" {
+			result = CallResult{Content: []Content{{Type: "text", Text: "it broke"}}, IsError: true}
+		} else {
+			result = CallResult{Content: []Content{{Type: "text", Text: fmt.Sprint(req.Params.Arguments["text"])}}}
+		}
+	default:
+		data, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0", "id": req.ID, "error": map[string]any{"code": -32601, "message": "no " + req.Method},
+		})
+		return data
+	}
+	data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	return data
+}
+
+// TestMain runs the test binary as a stdio server when asked to.
+func TestMain(m *testing.M) {
+	if os.Getenv("MCP_FAKE_SERVER") == "1" {
+		fmt.Fprintln(os.Stderr, "fake server started")
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if reply := fakeServer(scanner.Bytes()); reply != nil {
+				os.Stdout.Write(append(reply, '\n'))
+			}
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func checkClient(t *testing.T, c *Client) {
+	t.Helper()
+	ctx := context.Background()
+	if c.ServerName != "fake" || c.ServerVersion != "0.1" {
+		t.Errorf("server = %q %q, want fake 0.1", c.ServerName, c.ServerVersion)
+	}
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 2 || tools[0].Name != "echo" || tools[1].Name != "fail" {
+		t.Fatalf("ListTools() = %v, %v, want both pages", tools, err)
+	}
+	res, err := c.CallTool(ctx, "echo", map[string]any{"text": "hello"})
+	if err != nil || res.IsError || res.Text() != "hello" {
+		t.Errorf("CallTool(echo) = %+v, %v", res, err)
+	}
+	res, err = c.CallTool(ctx, "fail", nil)
+	if err != nil || !res.IsError || res.Text() != "it broke" {
+		t.Errorf("CallTool(fail) = %+v, %v, want a tool error", res, err)
+	}
+	if err := c.call(ctx, "resources/list", nil, nil); err == nil || !strings.Contains(err.Error(), "code -32601") {
+		t.Errorf("call(resources/list) = %v, want the server's error", err)
+	}
+}
+
+func TestConnect_Stdio(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Connect(ctx, "fake", ServerConfig{Command: exe, Env: map[string]string{"MCP_FAKE_SERVER": "1"}})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	checkClient(t, c)
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() error: %v", err)
+	}
+	if _, err := c.ListTools(context.Background()); err == nil {
+		t.Error("ListTools() after Close() succeeded")
+	}
+}
+
+func TestConnect_StdioExits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := Connect(ctx, "broken", ServerConfig{Command: "sh", Args: []string{"-c", "exit 3"}})
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Connect() error = %v, want the exit reported", err)
+	}
+}
+
+func TestConnect_SSE(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		stream chan []byte
+		auth   string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth = r.Header.Get("Authorization")
+		stream = make(chan []byte, 8)
+		ch := stream
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": hello\n\nevent: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case msg := <-ch:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session") != "1" {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		if reply := fakeServer(body); reply != nil {
+			mu.Lock()
+			stream <- reply
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	headers := map[string]string{"Authorization": "Bearer t"}
+	c, err := Connect(ctx, "fake", ServerConfig{URL: server.URL + "/sse", Headers: headers})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer c.Close()
+	checkClient(t, c)
+	mu.Lock()
+	defer mu.Unlock()
+	if auth != "Bearer t" {
+		t.Errorf("Authorization = %q, want the configured header", auth)
+	}
+}
+
+func TestConnect_SSENoEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	_, err := Connect(context.Background(), "fake", ServerConfig{URL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Connect() error = %v, want the status reported", err)
+	}
+}
+
+// pipeTransport is a transport whose server is the test.
+type pipeTransport struct {
+	sent chan []byte
+	msgs chan []byte
+}
+
+func (p *pipeTransport) send(ctx context.Context, msg []byte) error {
+	p.sent <- msg
+	return nil
+}
+func (p *pipeTransport) messages() <-chan []byte { return p.msgs }
+func (p *pipeTransport) err() error              { return errClosed }
+func (p *pipeTransport) close() error {
+	close(p.msgs)
+	return nil
+}
+
+func TestClient_ServerRequests(t *testing.T) {
+	p := &pipeTransport{sent: make(chan []byte, 4), msgs: make(chan []byte, 4)}
+	c := newClient("test", p)
+	defer c.Close()
+
+	p.msgs <- []byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)
+	p.msgs <- []byte(`{"jsonrpc":"2.0","id":"a","method":"ping"}`)
+	p.msgs <- []byte(`{"jsonrpc":"2.0","id":"b","method":"sampling/createMessage"}`)
+	got := map[string]string{}
+	for range 2 {
+		select {
+		case msg := <-p.sent:
+			var reply struct {
+				ID     string          `json:"id"`
+				Result json.RawMessage `json:"result"`
+				Error  *RPCError       `json:"error"`
+			}
+			json.Unmarshal(msg, &reply)
+			if reply.Error != nil {
+				got[reply.ID] = "error"
+			} else {
+				got[reply.ID] = string(reply.Result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("answers = %v, want two", got)
+		}
+	}
+	if got["a"] != "{}" || got["b"] != "error" {
+		t.Errorf("answers = %v, want the ping answered and sampling refused", got)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	p := &pipeTransport{sent: make(chan []byte, 4), msgs: make(chan []byte, 4)}
+	c := newClient("test", p)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.CallTool(ctx, "slow", nil); err != context.DeadlineExceeded {
+		t.Errorf("CallTool() error = %v, want the deadline", err)
+	}
+}
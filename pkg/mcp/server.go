@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ServerTool is a tool a Server offers, with the function that runs it.
+type ServerTool struct {
+	Tool
+	Call func(ctx context.Context, args map[string]any) *CallResult
+}
+
+// Server offers tools to MCP clients, such as desktop assistants and
+// editors, over stdio.
+type Server struct {
+	name, version string
+	tools         []ServerTool
+	byName        map[string]ServerTool
+}
+
+// NewServer creates a server introducing itself as name and version.
+func NewServer(name, version string, tools []ServerTool) *Server {
+	byName := make(map[string]ServerTool, len(tools))
+	for _, t := range tools {
+		byName[t.Name] = t
+	}
+	return &Server{name: name, version: version, tools: tools, byName: byName}
+}
+
+// TextResult is a successful result made of text.
+func TextResult(text string) *CallResult {
+	return &CallResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// ErrorResult is a failed result made of text.
+func ErrorResult(text string) *CallResult {
+	return &CallResult{Content: []Content{{Type: "text", Text: text}}, IsError: true}
+}
+
+// Serve reads requests from r, one JSON message per line, and writes the
+// answers to w, until r ends or ctx is done. Tool calls run concurrently;
+// the client can cancel them.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu sync.Mutex
+		wg      sync.WaitGroup
+		callsMu sync.Mutex
+		calls   = make(map[string]context.CancelFunc) // Running tool calls by request ID
+	)
+	defer wg.Wait()
+	reply := func(msg rpcMessage) {
+		msg.JSONRPC = "2.0"
+		data, err := json.Marshal(msg)
+		if err != nil {
+			data, _ = json.Marshal(rpcMessage{JSONRPC: "2.0", ID: msg.ID, Error: &RPCError{Code: -32603, Message: err.Error()}})
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		w.Write(append(data, '\n'))
+	}
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			select {
+			case lines <- bytes.Clone(scanner.Bytes()):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line = <-lines:
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(line, &req); err != nil {
+			reply(rpcMessage{ID: json.RawMessage("null"), Error: &RPCError{Code: -32700, Message: "parse error"}})
+			continue
+		}
+		if req.ID == nil {
+			// A notification; only cancellations need acting on.
+			if req.Method == "notifications/cancelled" {
+				var p struct {
+					RequestID json.RawMessage `json:"requestId"`
+				}
+				json.Unmarshal(req.Params, &p)
+				callsMu.Lock()
+				if stop, ok := calls[string(p.RequestID)]; ok {
+					stop()
+				}
+				callsMu.Unlock()
+			}
+			continue
+		}
+
+		switch req.Method {
+		case "initialize":
+			reply(rpcMessage{ID: req.ID, Result: mustJSON(map[string]any{
+				"protocolVersion": ProtocolVersion,
+				"capabilities":    map[string]any{"tools": map[string]any{}},
+				"serverInfo":      map[string]any{"name": s.name, "version": s.version},
+			})})
+		case "ping":
+			reply(rpcMessage{ID: req.ID, Result: json.RawMessage("{}")})
+		case "tools/list":
+			list := make([]Tool, len(s.tools))
+			for i, t := range s.tools {
+				list[i] = t.Tool
+			}
+			reply(rpcMessage{ID: req.ID, Result: mustJSON(map[string]any{"tools": list})})
+		case "tools/call":
+			var p struct {
+				Name      string         `json:"name"`
+				Arguments map[string]any `json:"arguments"`
+			}
+			if err := json.Unmarshal(req.Params, &p); err != nil {
+				reply(rpcMessage{ID: req.ID, Error: &RPCError{Code: -32602, Message: "invalid params: " + err.Error()}})
+				continue
+			}
+			tool, ok := s.byName[p.Name]
+			if !ok {
+				reply(rpcMessage{ID: req.ID, Error: &RPCError{Code: -32602, Message: "unknown tool: " + p.Name}})
+				continue
+			}
+			callCtx, stop := context.WithCancel(ctx)
+			callsMu.Lock()
+			calls[string(req.ID)] = stop
+			callsMu.Unlock()
+			wg.Add(1)
+			go func(id json.RawMessage) {
+				defer wg.Done()
+				defer func() {
+					callsMu.Lock()
+					delete(calls, string(id))
+					callsMu.Unlock()
+					stop()
+				}()
+				result := s.call(callCtx, tool, p.Arguments)
+				if callCtx.Err() != nil && ctx.Err() == nil {
+					return // Cancelled by the client, which wants no answer
+				}
+				reply(rpcMessage{ID: id, Result: mustJSON(result)})
+			}(req.ID)
+		default:
+			reply(rpcMessage{ID: req.ID, Error: &RPCError{Code: -32601, Message: "method not supported: " + req.Method}})
+		}
+	}
+}
+
+// call runs a tool, turning a panic into a failed result so one bad call
+// doesn't end the session.
+func (s *Server) call(ctx context.Context, tool ServerTool, args map[string]any) (result *CallResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.ErrorCF("mcp", "Tool panicked", map[string]any{"tool": tool.Name, "panic": fmt.Sprint(r)})
+			result = ErrorResult(fmt.Sprintf("%s failed: %v", tool.Name, r))
+		}
+	}()
+	if args == nil {
+		args = map[string]any{}
+	}
+	return tool.Call(ctx, args)
+}
+
+func mustJSON(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
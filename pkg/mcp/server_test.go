@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lineTransport is a client's end of a pipe to a Server.
+type lineTransport struct {
+	w    io.WriteCloser
+	msgs chan []byte
+}
+
+func (l *lineTransport) send(ctx context.Context, msg []byte) error {
+	_, err := l.w.Write(append(msg, '\n'))
+	return err
+}
+func (l *lineTransport) messages() <-chan []byte { return l.msgs }
+func (l *lineTransport) err() error              { return errClosed }
+func (l *lineTransport) close() error            { return l.w.Close() }
+
+// serve connects a client to a server running tools.
+func serve(t *testing.T, tools []ServerTool) *Client {
+	t.Helper()
+	toServer, clientOut := io.Pipe()
+	fromServer, serverOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer("picoclaw", "1.2.3", tools).Serve(context.Background(), toServer, serverOut)
+		serverOut.Close()
+	}()
+
+	l := &lineTransport{w: clientOut, msgs: make(chan []byte)}
+	go func() {
+		defer close(l.msgs)
+		scanner := bufio.NewScanner(fromServer)
+		for scanner.Scan() {
+			l.msgs <- []byte(scanner.Text())
+		}
+	}()
+	c := newClient("picoclaw", l)
+	t.Cleanup(func() {
+		c.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error: %v", err)
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.initialize(ctx); err != nil {
+		t.Fatalf("initialize() error: %v", err)
+	}
+	return c
+}
+
+func TestServer(t *testing.T) {
+	c := serve(t, []ServerTool{
+		{
+			Tool: Tool{Name: "greet", InputSchema: map[string]any{"type": "object"}},
+			Call: func(ctx context.Context, args map[string]any) *CallResult {
+				return TextResult("hello " + args["name"].(string))
+			},
+		},
+		{
+			Tool: Tool{Name: "broken"},
+			Call: func(ctx context.Context, args map[string]any) *CallResult {
+				panic("oops")
+			},
+		},
+	})
+	ctx := context.Background()
+
+	if c.ServerName != "picoclaw" || c.ServerVersion != "1.2.3" {
+		t.Errorf("server = %q %q", c.ServerName, c.ServerVersion)
+	}
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 2 || tools[0].Name != "greet" {
+		t.Fatalf("ListTools() = %v, %v", tools, err)
+	}
+	res, err := c.CallTool(ctx, "greet", map[string]any{"name": "Ada"})
+	if err != nil || res.Text() != "hello Ada" {
+		t.Errorf("CallTool(greet) = %+v, %v", res, err)
+	}
+	res, err = c.CallTool(ctx, "broken", nil)
+	if err != nil || !res.IsError || !strings.Contains(res.Text(), "oops") {
+		t.Errorf("CallTool(broken) = %+v, %v, want the panic as a tool error", res, err)
+	}
+	if _, err := c.CallTool(ctx, "missing", nil); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("CallTool(missing) error = %v", err)
+	}
+	if err := c.call(ctx, "prompts/list", nil, nil); err == nil || !strings.Contains(err.Error(), "-32601") {
+		t.Errorf("call(prompts/list) error = %v", err)
+	}
+}
+
+func TestServer_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	c := serve(t, []ServerTool{{
+		Tool: Tool{Name: "slow"},
+		Call: func(ctx context.Context, args map[string]any) *CallResult {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return TextResult("stopped")
+		},
+	}})
+
+	go c.CallTool(context.Background(), "slow", nil)
+	<-started
+	// The call above is the client's first after initialize.
+	data, _ := json.Marshal(rpcMessage{JSONRPC: "2.0", Method: "notifications/cancelled",
+		Params: map[string]any{"requestId": 2}})
+	if err := c.t.send(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("the tool call wasn't cancelled")
+	}
+}
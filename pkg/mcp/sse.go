@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// sseTransport talks to a server over HTTP: the server sends its messages
+// as events on a long-lived GET, starting with an "endpoint" event that
+// gives the URL to POST messages to.
+type sseTransport struct {
+	client   *http.Client
+	headers  map[string]string
+	endpoint string
+	body     io.ReadCloser
+	msgs     chan []byte
+
+	errMu   sync.Mutex
+	readErr error
+}
+
+func startSSE(ctx context.Context, rawURL string, headers map[string]string) (*sseTransport, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	// The stream lasts as long as the connection, so it can't have ctx's
+	// deadline; ctx only limits waiting for the endpoint.
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s answered %s", rawURL, resp.Status)
+	}
+
+	t := &sseTransport{client: client, headers: headers, body: resp.Body, msgs: make(chan []byte, 16)}
+	endpoint := make(chan string, 1)
+	go t.read(base, endpoint)
+
+	select {
+	case e, ok := <-endpoint:
+		if !ok {
+			return nil, fmt.Errorf("the stream ended before giving an endpoint: %w", t.err())
+		}
+		t.endpoint = e
+		return t, nil
+	case <-ctx.Done():
+		resp.Body.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// read parses the event stream: the endpoint event goes to endpoint, and
+// message events to t.msgs.
+func (t *sseTransport) read(base *url.URL, endpoint chan<- string) {
+	defer close(t.msgs)
+	sentEndpoint := false
+	defer func() {
+		if !sentEndpoint {
+			close(endpoint)
+		}
+	}()
+
+	scanner := bufio.NewScanner(t.body)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	var (
+		event string
+		data  bytes.Buffer
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// The end of an event.
+			switch event {
+			case "endpoint":
+				if u, err := base.Parse(strings.TrimSpace(data.String())); err == nil && !sentEndpoint {
+					endpoint <- u.String()
+					sentEndpoint = true
+				}
+			case "", "message":
+				if data.Len() > 0 {
+					t.msgs <- bytes.Clone(data.Bytes())
+				}
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// A comment, used to keep the connection alive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = errClosed
+	}
+	t.errMu.Lock()
+	t.readErr = err
+	t.errMu.Unlock()
+}
+
+func (t *sseTransport) send(ctx context.Context, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("the server answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (t *sseTransport) messages() <-chan []byte {
+	return t.msgs
+}
+
+func (t *sseTransport) err() error {
+	t.errMu.Lock()
+	defer t.errMu.Unlock()
+	return t.readErr
+}
+
+func (t *sseTransport) close() error {
+	err := t.body.Close()
+	if errors.Is(err, http.ErrBodyReadAfterClose) {
+		return nil
+	}
+	return err
+}
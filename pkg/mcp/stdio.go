@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxMessageSize is the largest message read from a server.
+const maxMessageSize = 16 << 20
+
+// stdioTransport talks to a server it runs as a child process, one JSON
+// message per line on its stdin and stdout. What the server writes to
+// stderr is logged.
+type stdioTransport struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	msgs  chan []byte
+
+	writeMu sync.Mutex
+	errMu   sync.Mutex
+	readErr error
+	exited  chan struct{}
+}
+
+func startStdio(name, command string, args []string, env map[string]string, dir string) (*stdioTransport, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", command, err)
+	}
+
+	t := &stdioTransport{cmd: cmd, stdin: stdin, msgs: make(chan []byte, 16), exited: make(chan struct{})}
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.DebugCF("mcp", scanner.Text(), map[string]any{"server": name})
+		}
+	}()
+	go func() {
+		defer close(t.msgs)
+		reader := bufio.NewScanner(stdout)
+		reader.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for reader.Scan() {
+			if line := bytes.TrimSpace(reader.Bytes()); len(line) > 0 {
+				t.msgs <- bytes.Clone(line)
+			}
+		}
+		err := reader.Err()
+		if waitErr := cmd.Wait(); err == nil {
+			err = waitErr
+		}
+		if err == nil {
+			err = errClosed
+		}
+		t.errMu.Lock()
+		t.readErr = fmt.Errorf("%s exited: %w", command, err)
+		t.errMu.Unlock()
+		close(t.exited)
+	}()
+	return t, nil
+}
+
+func (t *stdioTransport) send(ctx context.Context, msg []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(msg, '\n')); err != nil {
+		// A broken pipe says less than why the server exited.
+		select {
+		case <-t.exited:
+			return t.err()
+		case <-time.After(time.Second):
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *stdioTransport) messages() <-chan []byte {
+	return t.msgs
+}
+
+func (t *stdioTransport) err() error {
+	t.errMu.Lock()
+	defer t.errMu.Unlock()
+	return t.readErr
+}
+
+// close closes the server's stdin, which tells it to exit, and kills it
+// if it hasn't after a few seconds.
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+	select {
+	case <-t.exited:
+	case <-time.After(3 * time.Second):
+		_ = t.cmd.Process.Kill()
+		<-t.exited
+	}
+	return nil
+}
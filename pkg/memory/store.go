@@ -0,0 +1,391 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package memory keeps long-term facts with their embeddings in SQLite, for
+// semantic retrieval.
+package memory
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+// DuplicateScore is the similarity above which a new fact replaces an
+// existing one instead of being added next to it.
+const DuplicateScore = 0.92
+
+const schema = `CREATE TABLE IF NOT EXISTS facts (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	text       TEXT    NOT NULL,
+	source     TEXT    NOT NULL DEFAULT '',
+	embedding  BLOB    NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+)`
+
+// Fact is a remembered piece of information.
+type Fact struct {
+	ID      int64
+	Text    string
+	Source  string // Session the fact was learned in
+	Created time.Time
+	Score   float32 // Similarity to the query; set by Search
+
+	vector []float32
+}
+
+// Store is a database of facts. The facts are also kept in memory, where
+// searches run: a personal assistant remembers hundreds of facts, not
+// millions.
+type Store struct {
+	db    *sql.DB
+	key   *encrypt.Key
+	mu    sync.RWMutex
+	facts []Fact
+}
+
+// Open opens or creates the database at path. With a key, fact texts are
+// encrypted; embeddings are stored as is.
+func Open(path string, key *encrypt.Key) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	os.Chmod(path, 0o600)
+
+	s := &Store{db: db, key: key}
+	if err := s.load(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	rows, err := s.db.Query(`SELECT id, text, source, embedding, created_at FROM facts ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f Fact
+		var text string
+		var blob []byte
+		var created int64
+		if err := rows.Scan(&f.ID, &text, &f.Source, &blob, &created); err != nil {
+			return err
+		}
+		plain, err := s.key.OpenString(text)
+		if err != nil {
+			return err
+		}
+		f.Text = string(plain)
+		f.vector = decodeVector(blob)
+		f.Created = time.Unix(created, 0)
+		s.facts = append(s.facts, f)
+	}
+	return rows.Err()
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add stores a fact and its embedding. A fact nearly identical to one
+// already stored (see DuplicateScore) replaces it, so restated facts don't
+// pile up. It reports whether a new fact was added.
+func (s *Store) Add(text string, vector []float32, source string) (bool, error) {
+	if len(vector) == 0 {
+		return false, errors.New("fact has no embedding")
+	}
+	sealed, err := s.key.SealString([]byte(text))
+	if err != nil {
+		return false, err
+	}
+	now := time.Now().Truncate(time.Second) // as stored
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.facts {
+		if Cosine(s.facts[i].vector, vector) < DuplicateScore {
+			continue
+		}
+		f := &s.facts[i]
+		_, err := s.db.Exec(`UPDATE facts SET text = ?, source = ?, embedding = ?, created_at = ? WHERE id = ?`,
+			sealed, source, encodeVector(vector), now.Unix(), f.ID)
+		if err != nil {
+			return false, err
+		}
+		f.Text, f.Source, f.vector, f.Created = text, source, vector, now
+		return false, nil
+	}
+
+	res, err := s.db.Exec(`INSERT INTO facts (text, source, embedding, created_at) VALUES (?, ?, ?, ?)`,
+		sealed, source, encodeVector(vector), now.Unix())
+	if err != nil {
+		return false, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	s.facts = append(s.facts, Fact{ID: id, Text: text, Source: source, Created: now, vector: vector})
+	return true, nil
+}
+
+// Search returns up to k facts most similar to vector, best first, leaving
+// out those scoring below minScore. Facts embedded by a model with other
+// dimensions never match.
+func (s *Store) Search(vector []float32, k int, minScore float32) []Fact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []Fact
+	for _, f := range s.facts {
+		if score := Cosine(f.vector, vector); score > 0 && score >= minScore {
+			f.Score = score
+			found = append(found, f)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Score > found[j].Score })
+	if len(found) > k {
+		found = found[:k]
+	}
+	return found
+}
+
+// List returns all facts, oldest first.
+func (s *Store) List() []Fact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Fact(nil), s.facts...)
+}
+
+// Len returns the number of facts.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.facts)
+}
+
+// Forget deletes the fact with id, reporting whether it existed.
+func (s *Store) Forget(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.facts {
+		if f.ID != id {
+			continue
+		}
+		if _, err := s.db.Exec(`DELETE FROM facts WHERE id = ?`, id); err != nil {
+			return false, err
+		}
+		s.facts = append(s.facts[:i], s.facts[i+1:]...)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Clear deletes all facts and returns how many there were.
+func (s *Store) Clear() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM facts`); err != nil {
+		return 0, err
+	}
+	n := len(s.facts)
+	s.facts = nil
+	return n, nil
+}
+
+// Merge replaces the facts with ids by one fact. It keeps the newest
+// creation time among them, so merging doesn't make old facts look fresh.
+func (s *Store) Merge(ids []int64, text string, vector []float32, source string) error {
+	if len(vector) == 0 {
+		return errors.New("fact has no embedding")
+	}
+	sealed, err := s.key.SealString([]byte(text))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := make(map[int64]bool, len(ids))
+	var created time.Time
+	for _, f := range s.facts {
+		for _, id := range ids {
+			if f.ID == id {
+				merged[id] = true
+				if f.Created.After(created) {
+					created = f.Created
+				}
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return errors.New("no such facts")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for id := range merged {
+		if _, err := tx.Exec(`DELETE FROM facts WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	res, err := tx.Exec(`INSERT INTO facts (text, source, embedding, created_at) VALUES (?, ?, ?, ?)`,
+		sealed, source, encodeVector(vector), created.Unix())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	kept := s.facts[:0]
+	for _, f := range s.facts {
+		if !merged[f.ID] {
+			kept = append(kept, f)
+		}
+	}
+	s.facts = append(kept, Fact{ID: id, Text: text, Source: source, Created: created, vector: vector})
+	return nil
+}
+
+// Prune deletes the oldest facts beyond max and returns how many it deleted.
+func (s *Store) Prune(max int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if max < 0 || len(s.facts) <= max {
+		return 0, nil
+	}
+	byAge := append([]Fact(nil), s.facts...)
+	sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].Created.Before(byAge[j].Created) })
+	drop := make(map[int64]bool)
+	for _, f := range byAge[:len(byAge)-max] {
+		drop[f.ID] = true
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	for id := range drop {
+		if _, err := tx.Exec(`DELETE FROM facts WHERE id = ?`, id); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	kept := s.facts[:0]
+	for _, f := range s.facts {
+		if !drop[f.ID] {
+			kept = append(kept, f)
+		}
+	}
+	s.facts = kept
+	return len(drop), nil
+}
+
+// Compact gives the space of deleted facts back to the file system.
+func (s *Store) Compact() error {
+	_, err := s.db.Exec(`VACUUM`)
+	return err
+}
+
+// LastConsolidated returns when the facts were last consolidated, or the
+// zero time if they never were.
+func (s *Store) LastConsolidated() time.Time {
+	var value string
+	if err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'consolidated_at'`).Scan(&value); err != nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// SetLastConsolidated records when the facts were consolidated.
+func (s *Store) SetLastConsolidated(t time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO meta (key, value) VALUES ('consolidated_at', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, t.UTC().Format(time.RFC3339))
+	return err
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+// Similarity returns the similarity of two facts' embeddings, from -1 to 1.
+func Similarity(a, b Fact) float32 {
+	return Cosine(a.vector, b.vector)
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 when their lengths
+// differ or either is zero.
+func Cosine(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(na) * math.Sqrt(nb)))
+}
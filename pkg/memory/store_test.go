@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+func TestStore_AddSearchForget(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "facts.db"), nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	for _, f := range []struct {
+		text   string
+		vector []float32
+	}{
+		{"The user's dog is called Rex", []float32{1, 0, 0}},
+		{"The user lives in Berlin", []float32{0, 1, 0}},
+		{"The user prefers tea", []float32{0, 0.2, 1}},
+	} {
+		if added, err := s.Add(f.text, f.vector, "agent:main:main"); err != nil || !added {
+			t.Fatalf("Add(%q) = %v, %v", f.text, added, err)
+		}
+	}
+
+	found := s.Search([]float32{0.1, 1, 0.1}, 2, 0.3)
+	if len(found) != 1 || found[0].Text != "The user lives in Berlin" || found[0].Score < 0.9 {
+		t.Errorf("Search() = %+v", found)
+	}
+	if found := s.Search([]float32{1, 1, 1}, 2, 0); len(found) != 2 || found[0].Score < found[1].Score {
+		t.Errorf("Search() should return the top 2, best first: %+v", found)
+	}
+	if found := s.Search([]float32{1, 0}, 5, 0); len(found) != 0 {
+		t.Errorf("vectors of other dimensions should not match: %+v", found)
+	}
+
+	// A restated fact replaces the old one.
+	if added, err := s.Add("The user's dog is named Rex", []float32{0.99, 0.05, 0}, "other"); err != nil || added {
+		t.Errorf("Add(duplicate) = %v, %v; want it to replace", added, err)
+	}
+	facts := s.List()
+	if len(facts) != 3 || facts[0].Text != "The user's dog is named Rex" || facts[0].Source != "other" {
+		t.Errorf("List() = %+v", facts)
+	}
+
+	if ok, err := s.Forget(facts[1].ID); !ok || err != nil {
+		t.Errorf("Forget() = %v, %v", ok, err)
+	}
+	if ok, _ := s.Forget(facts[1].ID); ok {
+		t.Error("Forget() of a deleted fact should report false")
+	}
+	if n, err := s.Clear(); n != 2 || err != nil || s.Len() != 0 {
+		t.Errorf("Clear() = %d, %v; Len() = %d", n, err, s.Len())
+	}
+}
+
+func TestStore_PersistsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facts.db")
+	key, err := encrypt.ParseKey("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path, key)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := s.Add("The user's bank PIN hint is Zebra", []float32{0.5, 0.5}, "s"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	s.Close()
+
+	for _, name := range []string{"facts.db", "facts.db-wal"} {
+		if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), name)); err == nil &&
+			strings.Contains(string(data), "Zebra") {
+			t.Errorf("%s contains the fact in plain text", name)
+		}
+	}
+
+	s, err = Open(path, key)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	found := s.Search([]float32{0.5, 0.5}, 1, 0.5)
+	if len(found) != 1 || found[0].Text != "The user's bank PIN hint is Zebra" {
+		t.Errorf("Search() after reopening = %+v", found)
+	}
+
+	if _, err := Open(path, nil); err == nil {
+		t.Error("Open() without the key should fail for encrypted facts")
+	}
+}
+
+func TestStore_MergePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facts.db")
+	s, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Add("The user has a dog", []float32{1, 0.5, 0}, "a")
+	s.Add("The dog is called Rex", []float32{1, 0, 0.5}, "b")
+	s.Add("The user prefers tea", []float32{0, 0, 1}, "c")
+	newest := s.List()[1].Created
+
+	if err := s.Merge([]int64{1, 2, 9}, "The user has a dog called Rex", []float32{1, 0, 0}, "a"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if err := s.Merge([]int64{1}, "gone", []float32{1}, "a"); err == nil {
+		t.Error("Merge() of deleted facts should fail")
+	}
+	facts := s.List()
+	if len(facts) != 2 || facts[1].Text != "The user has a dog called Rex" || !facts[1].Created.Equal(newest) {
+		t.Errorf("List() after Merge = %+v", facts)
+	}
+
+	if n, err := s.Prune(1); n != 1 || err != nil || s.List()[0].ID != facts[1].ID {
+		t.Errorf("Prune() = %d, %v; left %+v", n, err, s.List())
+	}
+	if n, _ := s.Prune(5); n != 0 {
+		t.Errorf("Prune() under the limit deleted %d", n)
+	}
+	if err := s.Compact(); err != nil {
+		t.Errorf("Compact() error = %v", err)
+	}
+
+	if !s.LastConsolidated().IsZero() {
+		t.Error("LastConsolidated() should be zero before any consolidation")
+	}
+	now := time.Now().Truncate(time.Second)
+	if err := s.SetLastConsolidated(now); err != nil {
+		t.Fatalf("SetLastConsolidated() error = %v", err)
+	}
+	s.Close()
+
+	s, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	if got := s.LastConsolidated(); !got.Equal(now) {
+		t.Errorf("LastConsolidated() = %v, want %v", got, now)
+	}
+	if facts := s.List(); len(facts) != 1 || facts[0].Text != "The user has a dog called Rex" {
+		t.Errorf("List() after reopening = %+v", facts)
+	}
+}
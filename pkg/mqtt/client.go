@@ -0,0 +1,239 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package mqtt publishes messages and subscribes to topics over MQTT 3.1.1,
+// over TCP or TLS, with a password or a client certificate.
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+const (
+	keepAlive      = 60 * time.Second
+	dialTimeout    = 10 * time.Second
+	writeTimeout   = 10 * time.Second
+	ackTimeout     = 10 * time.Second
+	disconnectWait = 250 * time.Millisecond
+)
+
+// Options are how to reach and log in to a broker.
+type Options struct {
+	Broker   string // tcp://host:1883 or tls://host:8883
+	Username string
+	Password string
+	ClientID string // Default: "picoclaw-" and a random suffix
+
+	// TLS: the CA certificates trusted for the broker (default: the
+	// system's), and a client certificate and key, all PEM files
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// Message is a message published on a topic.
+type Message struct {
+	Topic    string
+	Payload  []byte
+	Retained bool // Kept by the broker and sent to new subscribers
+}
+
+// CheckBroker returns why broker isn't a tcp:// or tls:// address, or nil.
+func CheckBroker(broker string) error {
+	_, _, err := address(broker)
+	return err
+}
+
+func address(broker string) (addr string, useTLS bool, err error) {
+	scheme, addr, ok := strings.Cut(broker, "://")
+	if !ok || addr == "" {
+		return "", false, fmt.Errorf("invalid broker %q", broker)
+	}
+	switch scheme {
+	case "tcp":
+		return addr, false, nil
+	case "tls":
+		return addr, true, nil
+	}
+	return "", false, fmt.Errorf("unsupported broker scheme %q", scheme)
+}
+
+func (o *Options) tlsConfig(host string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", o.CAFile)
+		}
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// connect logs in to the broker, giving up when ctx is done. The client
+// doesn't reconnect; lost receives the error that ends its connection.
+func connect(ctx context.Context, opts Options) (client paho.Client, lost <-chan error, err error) {
+	addr, useTLS, err := address(opts.Broker)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientID := opts.ClientID
+	if clientID == "" {
+		clientID = "picoclaw-" + uuid.NewString()[:8]
+	}
+	lostCh := make(chan error, 1)
+	po := paho.NewClientOptions().
+		AddBroker(opts.Broker).
+		SetClientID(clientID).
+		SetProtocolVersion(4). // MQTT 3.1.1, without falling back to 3.1
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetCleanSession(true).
+		SetKeepAlive(keepAlive).
+		SetConnectTimeout(dialTimeout).
+		SetWriteTimeout(writeTimeout).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ paho.Client, err error) { lostCh <- err })
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		cfg, err := opts.tlsConfig(host)
+		if err != nil {
+			return nil, nil, err
+		}
+		po.SetTLSConfig(cfg)
+	}
+	client = paho.NewClient(po)
+	if err := wait(ctx, client.Connect()); err != nil {
+		client.Disconnect(0)
+		return nil, nil, fmt.Errorf("connect to %s: %w", opts.Broker, err)
+	}
+	return client, lostCh, nil
+}
+
+// wait waits for token to complete, or ctx to be done.
+func wait(ctx context.Context, token paho.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe reports to fn the messages published on topics, which may hold
+// the + and # wildcards, until ctx is done or the connection fails. fn is
+// called on the goroutine of Subscribe.
+func Subscribe(ctx context.Context, opts Options, topics []string, fn func(Message)) error {
+	if len(topics) == 0 {
+		return errors.New("no topics to subscribe to")
+	}
+	client, lost, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(uint(disconnectWait / time.Millisecond))
+
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan Message)
+	filters := make(map[string]byte, len(topics))
+	for _, topic := range topics {
+		filters[topic] = 0
+	}
+	token := client.SubscribeMultiple(filters, func(_ paho.Client, m paho.Message) {
+		// Acknowledged now, not once fn is done, which may end the session.
+		m.Ack()
+		select {
+		case messages <- Message{Topic: m.Topic(), Payload: m.Payload(), Retained: m.Retained()}:
+		case <-done:
+		}
+	})
+	if err := wait(ctx, token); err != nil {
+		return err
+	}
+	for topic, code := range token.(*paho.SubscribeToken).Result() {
+		if code == 0x80 {
+			return fmt.Errorf("broker refused the subscription to %q", topic)
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-lost:
+			return err
+		case msg := <-messages:
+			fn(msg)
+		}
+	}
+}
+
+// Publish connects, publishes msg at qos 0 or 1 and disconnects. At QoS 1,
+// it waits for the broker to acknowledge the message.
+func Publish(ctx context.Context, opts Options, msg Message, qos byte) error {
+	if msg.Topic == "" || strings.ContainsAny(msg.Topic, "+#") {
+		return fmt.Errorf("%q is not a topic to publish on", msg.Topic)
+	}
+	if qos > 1 {
+		return fmt.Errorf("QoS %d is not supported; use 0 or 1", qos)
+	}
+	client, _, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(uint(disconnectWait / time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(ctx, ackTimeout)
+	defer cancel()
+	if err := wait(ctx, client.Publish(msg.Topic, qos, msg.Retained, msg.Payload)); err != nil {
+		return fmt.Errorf("no acknowledgement from the broker: %w", err)
+	}
+	return nil
+}
+
+// Match reports whether topic matches filter, where + stands for one level
+// and a final # for any number of them.
+// Wildcards at the first level don't match topics starting with $, such
+// as $SYS/broker/uptime.
+func Match(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (filter == "#" || strings.HasPrefix(filter, "+") ||
+		strings.HasPrefix(filter, "#/")) {
+		return false
+	}
+	fl, tl := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, f := range fl {
+		if f == "#" {
+			return i == len(fl)-1
+		}
+		if i >= len(tl) {
+			return false
+		}
+		if f != "+" && f != tl[i] {
+			return false
+		}
+	}
+	return len(fl) == len(tl)
+}
@@ -0,0 +1,158 @@
+package mqtt
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// fakeBroker accepts one client, checks its CONNECT, and hands the
+// connection to serve.
+func fakeBroker(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		p, err := packets.ReadPacket(conn)
+		connect, ok := p.(*packets.ConnectPacket)
+		if err != nil || !ok || connect.ProtocolName != "MQTT" || connect.Username != "pico" {
+			t.Errorf("CONNECT = %v, %v", p, err)
+			return
+		}
+		connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+		if string(connect.Password) == "wrong" {
+			connack.ReturnCode = packets.ErrRefusedBadUsernameOrPassword
+			connack.Write(conn)
+			return
+		}
+		connack.Write(conn)
+		serve(conn)
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestSubscribe(t *testing.T) {
+	acked := make(chan uint16, 1)
+	broker := fakeBroker(t, func(conn net.Conn) {
+		p, err := packets.ReadPacket(conn)
+		subscribe, ok := p.(*packets.SubscribePacket)
+		if err != nil || !ok {
+			t.Errorf("SUBSCRIBE = %v, %v", p, err)
+			return
+		}
+		if len(subscribe.Topics) != 1 || subscribe.Topics[0] != "home/door/#" {
+			t.Errorf("subscribed to %q", subscribe.Topics)
+		}
+		suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+		suback.MessageID, suback.ReturnCodes = subscribe.MessageID, []byte{0}
+		suback.Write(conn)
+		publish := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+		publish.Qos, publish.MessageID = 1, 7
+		publish.TopicName, publish.Payload = "home/door/front", []byte(`{"open":true}`)
+		publish.Write(conn)
+
+		if p, err := packets.ReadPacket(conn); err == nil {
+			if puback, ok := p.(*packets.PubackPacket); ok {
+				acked <- puback.MessageID
+			}
+		}
+		io.Copy(io.Discard, conn) // Wait for the client to go
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []Message
+	err := Subscribe(ctx, Options{Broker: broker, Username: "pico", Password: "secret"}, []string{"home/door/#"},
+		func(msg Message) {
+			got = append(got, msg)
+			cancel()
+		})
+	if err != context.Canceled {
+		t.Fatalf("Subscribe() = %v, want context.Canceled", err)
+	}
+	if len(got) != 1 || got[0].Topic != "home/door/front" || string(got[0].Payload) != `{"open":true}` {
+		t.Errorf("messages = %+v", got)
+	}
+	select {
+	case id := <-acked:
+		if id != 7 {
+			t.Errorf("PUBACK for %d, want 7", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the QoS 1 message wasn't acknowledged")
+	}
+}
+
+func TestPublish(t *testing.T) {
+	published := make(chan Message, 1)
+	broker := fakeBroker(t, func(conn net.Conn) {
+		p, err := packets.ReadPacket(conn)
+		publish, ok := p.(*packets.PublishPacket)
+		if err != nil || !ok || publish.Qos != 1 || publish.MessageID == 0 {
+			t.Errorf("PUBLISH = %v, %v", p, err)
+			return
+		}
+		puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+		puback.MessageID = publish.MessageID
+		puback.Write(conn)
+		published <- Message{Topic: publish.TopicName, Payload: publish.Payload, Retained: publish.Retain}
+		io.Copy(io.Discard, conn)
+	})
+
+	msg := Message{Topic: "home/lamp/set", Payload: []byte("ON"), Retained: true}
+	opts := Options{Broker: broker, Username: "pico", Password: "secret"}
+	if err := Publish(context.Background(), opts, msg, 1); err != nil {
+		t.Fatal(err)
+	}
+	got := <-published
+	if got.Topic != msg.Topic || string(got.Payload) != "ON" || !got.Retained {
+		t.Errorf("published %+v", got)
+	}
+
+	if err := Publish(context.Background(), opts, Message{Topic: "home/+/set"}, 0); err == nil {
+		t.Error("Publish() on a wildcard topic succeeded")
+	}
+	opts.Password = "wrong"
+	opts.Broker = fakeBroker(t, func(net.Conn) {})
+	err := Publish(context.Background(), opts, msg, 0)
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("Publish() with a wrong password = %v", err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"home/lamp/set", "home/lamp/set", true},
+		{"home/+/set", "home/lamp/set", true},
+		{"home/+/set", "home/lamp/get", false},
+		{"home/#", "home/lamp/set", true},
+		{"home/#", "home", true},
+		{"home/+", "home/lamp/set", false},
+		{"home/lamp", "home/lamp/set", false},
+		{"#", "$SYS/uptime", false},
+		{"$SYS/#", "$SYS/uptime", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
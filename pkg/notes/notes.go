@@ -0,0 +1,400 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package notes keeps notes as markdown files in a directory, where the user
+// can read and edit them too, and searches them by words and by meaning.
+package notes
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	ext          = ".md"
+	maxNameRunes = 60
+)
+
+// ErrNotFound is returned for a note that doesn't exist.
+var ErrNotFound = errors.New("no such note")
+
+// Note is a markdown file of the notes directory. A file may start with a
+// front matter block giving its title, tags and dates:
+//
+//	---
+//	title: Trip to Lisbon
+//	tags: travel, family
+//	created: 2026-10-15T09:00:00Z
+//	updated: 2026-10-15T09:00:00Z
+//	---
+//
+// Files the user writes without one are read too: their title is their
+// first heading, or their name, and their dates are the file's.
+type Note struct {
+	Name    string // The file name without .md; identifies the note
+	Title   string
+	Tags    []string
+	Created time.Time
+	Updated time.Time
+	Body    string
+	Score   float32 // Relevance to the query; set by Search
+}
+
+// HasTags reports whether the note has all of tags, ignoring case.
+func (n *Note) HasTags(tags []string) bool {
+	for _, t := range tags {
+		if !hasTag(n.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// EmbedFunc embeds texts for semantic search.
+type EmbedFunc func(ctx context.Context, inputs []string) ([][]float32, error)
+
+// Store is a directory of notes. Notes are read from disk on each call, so
+// edits made outside picoclaw are seen at once; a personal knowledge base
+// holds hundreds of notes, not millions.
+type Store struct {
+	dir   string
+	embed EmbedFunc // nil when searching by meaning is off
+	now   func() time.Time
+
+	mu    sync.Mutex
+	index *index // Embeddings of the notes, loaded on first use
+}
+
+// NewStore returns the store of the notes in dir, which is created when the
+// first note is saved. embed may be nil; then notes are only searched by
+// words.
+func NewStore(dir string, embed EmbedFunc) *Store {
+	return &Store{dir: dir, embed: embed, now: time.Now}
+}
+
+// Dir returns the directory of the notes.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save writes a note titled title, replacing the body of the note of that
+// title, or name, if there is one. tags replace the note's tags unless nil. It reports
+// whether the note is new.
+func (s *Store) Save(title, body string, tags []string) (*Note, bool, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, false, errors.New("a note needs a title")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC().Truncate(time.Second)
+	note, err := s.find(title)
+	if err == nil && !strings.EqualFold(note.Title, title) && note.Name != title {
+		err = ErrNotFound // Another note whose title has the same slug
+	}
+	created := errors.Is(err, ErrNotFound)
+	switch {
+	case created:
+		note = &Note{Name: s.freeName(Slug(title)), Created: now}
+	case err != nil:
+		return nil, false, err
+	}
+	note.Title, note.Body, note.Updated = title, strings.TrimSpace(body), now
+	if tags != nil {
+		note.Tags = cleanTags(tags)
+	}
+	if err := s.write(note); err != nil {
+		return nil, false, err
+	}
+	return note, created, nil
+}
+
+// Append adds text at the end of the note named or titled name.
+func (s *Store) Append(name, text string) (*Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	note, err := s.find(name)
+	if err != nil {
+		return nil, err
+	}
+	if note.Body != "" {
+		note.Body += "\n\n"
+	}
+	note.Body += strings.TrimSpace(text)
+	note.Updated = s.now().UTC().Truncate(time.Second)
+	if err := s.write(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Tag adds and removes tags of the note named or titled name.
+func (s *Store) Tag(name string, add, remove []string) (*Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	note, err := s.find(name)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, t := range note.Tags {
+		if !hasTag(remove, t) {
+			tags = append(tags, t)
+		}
+	}
+	note.Tags = cleanTags(append(tags, add...))
+	note.Updated = s.now().UTC().Truncate(time.Second)
+	if err := s.write(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Get returns the note named or titled name.
+func (s *Store) Get(name string) (*Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(name)
+}
+
+// Delete removes the note named or titled name.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	note, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(s.path(note.Name))
+}
+
+// List returns the notes having all of tags, the last updated first.
+func (s *Store) List(tags []string) ([]Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, n := range all {
+		if n.HasTags(tags) {
+			notes = append(notes, n)
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Updated.After(notes[j].Updated) })
+	return notes, nil
+}
+
+// find returns the note whose name is name, or whose name or title is that
+// of title name.
+func (s *Store) find(name string) (*Note, error) {
+	name = strings.TrimSpace(strings.TrimSuffix(name, ext))
+	if name == "" {
+		return nil, ErrNotFound
+	}
+	if filepath.Base(name) == name && !strings.HasPrefix(name, ".") {
+		if note, err := s.read(name); err == nil || !errors.Is(err, os.ErrNotExist) {
+			return note, err
+		}
+	}
+	if note, err := s.read(Slug(name)); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return note, err
+	}
+	all, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if strings.EqualFold(all[i].Title, name) {
+			return &all[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// freeName returns base, or base with a number added if a note has it.
+func (s *Store) freeName(base string) string {
+	name := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.path(name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+ext)
+}
+
+func (s *Store) readAll() ([]Note, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ext)
+		if !ok || e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		note, err := s.read(name)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *note)
+	}
+	return notes, nil
+}
+
+func (s *Store) read(name string) (*Note, error) {
+	path := s.path(name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	note := parse(name, string(data))
+	if note.Updated.IsZero() || note.Created.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			if note.Updated.IsZero() {
+				note.Updated = info.ModTime().UTC().Truncate(time.Second)
+			}
+			if note.Created.IsZero() {
+				note.Created = note.Updated
+			}
+		}
+	}
+	return note, nil
+}
+
+func (s *Store) write(note *Note) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp := s.path(note.Name) + ".tmp"
+	if err := os.WriteFile(tmp, []byte(format(note)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(note.Name))
+}
+
+// parse reads a note file.
+func parse(name, data string) *Note {
+	note := &Note{Name: name}
+	body := data
+	if rest, ok := strings.CutPrefix(data, "---\n"); ok {
+		if header, after, ok := strings.Cut(rest, "\n---\n"); ok {
+			body = after
+			scanner := bufio.NewScanner(strings.NewReader(header))
+			for scanner.Scan() {
+				key, value, ok := strings.Cut(scanner.Text(), ":")
+				if !ok {
+					continue
+				}
+				value = strings.TrimSpace(value)
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "title":
+					note.Title = value
+				case "tags":
+					note.Tags = cleanTags(strings.Split(strings.Trim(value, "[]"), ","))
+				case "created":
+					note.Created, _ = time.Parse(time.RFC3339, value)
+				case "updated":
+					note.Updated, _ = time.Parse(time.RFC3339, value)
+				}
+			}
+		}
+	}
+	note.Body = strings.TrimSpace(body)
+	if note.Title == "" {
+		note.Title = name
+		for _, line := range strings.Split(note.Body, "\n") {
+			if heading, ok := strings.CutPrefix(line, "# "); ok {
+				note.Title = strings.TrimSpace(heading)
+				break
+			}
+		}
+	}
+	return note
+}
+
+// format returns the file of a note.
+func format(note *Note) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "title: %s\n", strings.ReplaceAll(note.Title, "\n", " "))
+	if len(note.Tags) > 0 {
+		fmt.Fprintf(&sb, "tags: %s\n", strings.Join(note.Tags, ", "))
+	}
+	fmt.Fprintf(&sb, "created: %s\n", note.Created.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "updated: %s\n", note.Updated.Format(time.RFC3339))
+	sb.WriteString("---\n\n")
+	sb.WriteString(note.Body)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// cleanTags trims tags, drops empty ones and duplicates, and lowercases them.
+func cleanTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#")))
+		if t != "" && !hasTag(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Slug returns the file name of a note titled title: its letters and
+// digits in lower case, the rest turned into dashes.
+func Slug(title string) string {
+	var sb strings.Builder
+	dash := false
+	n := 0
+	for _, r := range strings.ToLower(title) {
+		if n == maxNameRunes {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+				n++
+			}
+			sb.WriteRune(r)
+			n++
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if sb.Len() == 0 {
+		return "note"
+	}
+	return sb.String()
+}
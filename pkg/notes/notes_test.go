@@ -0,0 +1,151 @@
+package notes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, embed EmbedFunc) *Store {
+	t.Helper()
+	s := NewStore(filepath.Join(t.TempDir(), "notes"), embed)
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return s
+}
+
+func TestStore_SaveGetAppend(t *testing.T) {
+	s := newTestStore(t, nil)
+
+	note, created, err := s.Save("Trip to Lisbon", "Flights on the 3rd.", []string{"Travel", "#family", "travel"})
+	if err != nil || !created {
+		t.Fatalf("Save() = %v, %v, %v", note, created, err)
+	}
+	if note.Name != "trip-to-lisbon" || strings.Join(note.Tags, ",") != "travel,family" {
+		t.Errorf("note = %+v", note)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir(), "trip-to-lisbon.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "---\ntitle: Trip to Lisbon\ntags: travel, family\n") ||
+		!strings.HasSuffix(string(data), "---\n\nFlights on the 3rd.\n") {
+		t.Errorf("file = %q", data)
+	}
+
+	if _, err := s.Append("trip to lisbon", "Hotel: Alfama."); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get("trip-to-lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "Flights on the 3rd.\n\nHotel: Alfama." || !got.Updated.After(got.Created) {
+		t.Errorf("after Append, note = %+v", got)
+	}
+
+	// Saving the same title replaces the body and keeps the tags.
+	note, created, err = s.Save("trip to Lisbon", "Cancelled.", nil)
+	if err != nil || created {
+		t.Fatalf("Save() again = %v, %v, %v", note, created, err)
+	}
+	if note.Body != "Cancelled." || len(note.Tags) != 2 || !note.Created.Equal(got.Created) {
+		t.Errorf("replaced note = %+v", note)
+	}
+
+	note, err = s.Tag("trip-to-lisbon", []string{"done"}, []string{"FAMILY"})
+	if err != nil || strings.Join(note.Tags, ",") != "travel,done" {
+		t.Errorf("Tag() = %+v, %v", note, err)
+	}
+
+	if err := s.Delete("Trip to Lisbon"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("trip-to-lisbon"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Append("../secrets", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Append() outside the notes = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_SameSlug(t *testing.T) {
+	s := newTestStore(t, nil)
+	a, _, _ := s.Save("C++", "", nil)
+	b, created, err := s.Save("C", "", nil)
+	if err != nil || !created || a.Name != "c" || b.Name != "c-2" {
+		t.Fatalf("names = %q, %q (%v, %v)", a.Name, b.Name, created, err)
+	}
+	if got, err := s.Get("C++"); err != nil || got.Name != "c" {
+		t.Errorf("Get(C++) = %+v, %v", got, err)
+	}
+}
+
+func TestStore_UserFiles(t *testing.T) {
+	s := newTestStore(t, nil)
+	if err := os.MkdirAll(s.Dir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"recipes.md":  "# Grandma's recipes\n\nApple pie.",
+		"plain.md":    "Just text.",
+		".index.json": "{}",
+		"ignored.txt": "Not a note.",
+		"tagged.md":   "---\ntags: [home, diy]\n---\nFix the shelf.",
+		"unclosed.md": "---\ntitle: broken\nNo end to the header.",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(s.Dir(), name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes, err := s.List(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := map[string]string{}
+	for _, n := range notes {
+		titles[n.Name] = n.Title
+		if n.Updated.IsZero() {
+			t.Errorf("%s has no date", n.Name)
+		}
+	}
+	want := map[string]string{
+		"recipes": "Grandma's recipes", "plain": "plain", "tagged": "tagged", "unclosed": "unclosed",
+	}
+	if len(titles) != len(want) {
+		t.Fatalf("titles = %v, want %v", titles, want)
+	}
+	for name, title := range want {
+		if titles[name] != title {
+			t.Errorf("title of %s = %q, want %q", name, titles[name], title)
+		}
+	}
+
+	tagged, err := s.List([]string{"DIY"})
+	if err != nil || len(tagged) != 1 || tagged[0].Body != "Fix the shelf." {
+		t.Errorf("List(diy) = %+v, %v", tagged, err)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Trip to Lisbon", "trip-to-lisbon"},
+		{"  Ideas: 2026!  ", "ideas-2026"},
+		{"Café crème", "café-crème"},
+		{"../../etc/passwd", "etc-passwd"},
+		{"???", "note"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.in); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
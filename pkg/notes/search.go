@@ -0,0 +1,289 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package notes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/memory"
+)
+
+const (
+	// indexFile keeps the embeddings of the notes, beside them.
+	indexFile = ".index.json"
+	// minMeaningScore is the similarity under which a note isn't taken to
+	// be about the query.
+	minMeaningScore = 0.3
+	// maxEmbedChars bounds the text of a note that is embedded.
+	maxEmbedChars = 4000
+	// titleWeight counts a word of the title or tags as this many of the
+	// body.
+	titleWeight = 3
+	// rrfK damps the ranks merged by reciprocal rank fusion.
+	rrfK = 60
+)
+
+// index is the embedding of each note, by name, with a hash of the text
+// embedded so notes edited since are embedded again.
+type index struct {
+	Dims  int                   `json:"dims"` // Of the vectors, to notice a change of model
+	Notes map[string]indexEntry `json:"notes"`
+}
+
+type indexEntry struct {
+	Hash   string `json:"hash"`
+	Vector []byte `json:"vector"` // Little-endian float32s
+}
+
+type ranked struct {
+	note  int // Index in the notes searched
+	score float64
+}
+
+// Search returns up to k notes having all of tags that match query, the
+// best first. Notes are ranked by the words of the query they contain and,
+// when the store has an embed function, by how close they are in meaning to
+// the query; the two rankings are merged. With an empty query it returns
+// the last updated notes.
+func (s *Store) Search(ctx context.Context, query string, tags []string, k int) ([]Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, n := range all {
+		if n.HasTags(tags) {
+			notes = append(notes, n)
+		}
+	}
+	if strings.TrimSpace(query) == "" {
+		sort.SliceStable(notes, func(i, j int) bool { return notes[i].Updated.After(notes[j].Updated) })
+		return notes[:min(k, len(notes))], nil
+	}
+
+	rankings := [][]ranked{rankByWords(notes, query)}
+	if s.embed != nil && len(notes) > 0 {
+		byMeaning, err := s.rankByMeaning(ctx, notes, query)
+		if err != nil {
+			logger.WarnCF("notes", "Can't search notes by meaning", map[string]any{"error": err.Error()})
+		}
+		rankings = append(rankings, byMeaning)
+	}
+
+	// Reciprocal rank fusion: a note ranked high by either search comes
+	// first, and one found by both comes before one found by only one.
+	fused := make(map[int]float64)
+	for _, ranking := range rankings {
+		for rank, r := range ranking {
+			fused[r.note] += 1 / float64(rrfK+rank+1)
+		}
+	}
+	found := make([]Note, 0, len(fused))
+	for i, score := range fused {
+		n := notes[i]
+		n.Score = float32(score)
+		found = append(found, n)
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].Updated.After(found[j].Updated)
+	})
+	return found[:min(k, len(found))], nil
+}
+
+// rankByWords ranks the notes containing words of query, BM25-style: rare
+// words weigh more, and a word repeated counts less each time. A query word
+// matches the words it starts, so "plan" finds "plans".
+func rankByWords(notes []Note, query string) []ranked {
+	terms := words(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	counts := make([]map[string]float64, len(notes))
+	docFreq := make(map[string]int)
+	for i, n := range notes {
+		counts[i] = make(map[string]float64)
+		head := words(n.Title + " " + strings.Join(n.Tags, " "))
+		body := words(n.Body)
+		for _, term := range terms {
+			c := float64(titleWeight*countPrefixed(head, term) + countPrefixed(body, term))
+			if c > 0 {
+				counts[i][term] = c
+				docFreq[term]++
+			}
+		}
+	}
+
+	var out []ranked
+	for i := range notes {
+		score := 0.0
+		for term, c := range counts[i] {
+			idf := math.Log(1 + float64(len(notes))/float64(docFreq[term]))
+			score += idf * c / (c + 1.2)
+		}
+		if score > 0 {
+			out = append(out, ranked{note: i, score: score})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	return out
+}
+
+// words returns the words of s in lower case, leaving out one-letter ones.
+func words(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := fields[:0]
+	for _, w := range fields {
+		if len([]rune(w)) > 1 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func countPrefixed(words []string, prefix string) int {
+	n := 0
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// rankByMeaning ranks the notes close in meaning to query. Notes not yet
+// embedded, or changed since, are embedded with the query, and the index is
+// saved.
+func (s *Store) rankByMeaning(ctx context.Context, notes []Note, query string) ([]ranked, error) {
+	if s.index == nil {
+		s.index = s.loadIndex()
+	}
+	texts := []string{query}
+	var stale []int
+	hashes := make([]string, len(notes))
+	for i, n := range notes {
+		text := embedText(&n)
+		sum := sha256.Sum256([]byte(text))
+		hashes[i] = hex.EncodeToString(sum[:])
+		if s.index.Notes[n.Name].Hash != hashes[i] {
+			stale = append(stale, i)
+			texts = append(texts, text)
+		}
+	}
+	vectors, err := s.embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedded %d texts of %d", len(vectors), len(texts))
+	}
+	if dims := len(vectors[0]); dims != s.index.Dims {
+		if s.index.Dims != 0 {
+			// Another model: embed all the notes again.
+			s.index = &index{Notes: make(map[string]indexEntry)}
+			return s.rankByMeaning(ctx, notes, query)
+		}
+		s.index.Dims = dims
+	}
+	for j, i := range stale {
+		s.index.Notes[notes[i].Name] = indexEntry{Hash: hashes[i], Vector: encodeVector(vectors[j+1])}
+	}
+	if len(stale) > 0 {
+		if err := s.saveIndex(); err != nil {
+			logger.WarnCF("notes", "Can't save the notes index", map[string]any{"error": err.Error()})
+		}
+	}
+
+	var out []ranked
+	for i, n := range notes {
+		score := memory.Cosine(vectors[0], decodeVector(s.index.Notes[n.Name].Vector))
+		if score >= minMeaningScore {
+			out = append(out, ranked{note: i, score: float64(score)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	return out, nil
+}
+
+// embedText is what a note's embedding is computed from.
+func embedText(n *Note) string {
+	text := n.Title + "\n" + strings.Join(n.Tags, ", ") + "\n" + n.Body
+	if runes := []rune(text); len(runes) > maxEmbedChars {
+		text = string(runes[:maxEmbedChars])
+	}
+	return text
+}
+
+// loadIndex reads the index, or returns an empty one when it is missing or
+// unreadable: the notes are then embedded again.
+func (s *Store) loadIndex() *index {
+	idx := &index{}
+	data, err := os.ReadFile(filepath.Join(s.dir, indexFile))
+	if err == nil {
+		err = json.Unmarshal(data, idx)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.WarnCF("notes", "Rebuilding the notes index", map[string]any{"error": err.Error()})
+	}
+	if idx.Notes == nil {
+		idx.Notes = make(map[string]indexEntry)
+	}
+	return idx
+}
+
+// saveIndex writes the index, dropping the entries of deleted notes.
+func (s *Store) saveIndex() error {
+	for name := range s.index.Notes {
+		if _, err := os.Stat(s.path(name)); errors.Is(err, os.ErrNotExist) {
+			delete(s.index.Notes, name)
+		}
+	}
+	data, err := json.Marshal(s.index)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, indexFile)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
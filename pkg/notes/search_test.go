@@ -0,0 +1,133 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// topicEmbedder embeds texts on three topics, by the words they contain.
+type topicEmbedder struct {
+	calls  int
+	inputs int
+	fail   bool
+}
+
+func (e *topicEmbedder) embed(_ context.Context, inputs []string) ([][]float32, error) {
+	if e.fail {
+		return nil, errors.New("embedding service down")
+	}
+	e.calls++
+	e.inputs += len(inputs)
+	topics := [][]string{{"trip", "flight", "holiday", "hotel"}, {"recipe", "dinner", "bake"}, {"code", "bug"}}
+	var out [][]float32
+	for _, in := range inputs {
+		v := make([]float32, len(topics))
+		for i, words := range topics {
+			for _, w := range words {
+				if strings.Contains(strings.ToLower(in), w) {
+					v[i]++
+				}
+			}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func saveAll(t *testing.T, s *Store) {
+	t.Helper()
+	for _, n := range []struct {
+		title, body string
+		tags        []string
+	}{
+		{"Lisbon", "Flight on the 3rd, hotel in Alfama.", []string{"travel"}},
+		{"Pie", "Bake at 180C for 40 minutes. A recipe from grandma.", []string{"food"}},
+		{"Planning", "Plans for the holiday: book the trip early.", nil},
+		{"Parser bug", "The code fails on empty input.", []string{"work"}},
+	} {
+		if _, _, err := s.Save(n.title, n.body, n.tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func names(notes []Note) string {
+	var out []string
+	for _, n := range notes {
+		out = append(out, n.Name)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestSearch_Words(t *testing.T) {
+	s := newTestStore(t, nil)
+	saveAll(t, s)
+	ctx := context.Background()
+
+	tests := []struct{ query, want string }{
+		{"plan", "planning"}, // a prefix of "plans" and "planning"
+		{"alfama hotel", "lisbon"},
+		{"travel", "lisbon"}, // tags are searched
+		{"the", "planning lisbon parser-bug"},
+		{"nothing like this", ""},
+	}
+	for _, tt := range tests {
+		found, err := s.Search(ctx, tt.query, nil, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(found); got != tt.want {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	found, _ := s.Search(ctx, "the", []string{"work"}, 10)
+	if names(found) != "parser-bug" {
+		t.Errorf("Search(the, work) = %q", names(found))
+	}
+	found, _ = s.Search(ctx, "", nil, 2)
+	if names(found) != "parser-bug planning" {
+		t.Errorf("Search('') = %q, want the last updated", names(found))
+	}
+}
+
+func TestSearch_Meaning(t *testing.T) {
+	embedder := &topicEmbedder{}
+	s := newTestStore(t, embedder.embed)
+	saveAll(t, s)
+	ctx := context.Background()
+
+	// Lisbon has none of the words, but is about a trip too.
+	found, err := s.Search(ctx, "a holiday trip abroad", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(found); got != "planning lisbon" && got != "lisbon planning" {
+		t.Errorf("Search by meaning = %q", got)
+	}
+	if embedder.inputs != 5 {
+		t.Errorf("embedded %d texts, want the query and 4 notes", embedder.inputs)
+	}
+
+	// The index is kept: only the query and the edited note are embedded.
+	if _, err := s.Append("pie", "Serve for dinner."); err != nil {
+		t.Fatal(err)
+	}
+	reopened := NewStore(s.Dir(), embedder.embed)
+	found, err = reopened.Search(ctx, "dinner ideas", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names(found) != "pie" || embedder.inputs != 7 {
+		t.Errorf("Search(dinner) = %q after %d inputs, want pie after 7", names(found), embedder.inputs)
+	}
+
+	// When embedding fails, notes are still found by words.
+	embedder.fail = true
+	found, err = reopened.Search(ctx, "alfama", nil, 10)
+	if err != nil || names(found) != "lisbon" {
+		t.Errorf("Search without embeddings = %q, %v", names(found), err)
+	}
+}
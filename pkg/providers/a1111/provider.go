@@ -0,0 +1,177 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package a1111
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ImageRequest   = protocoltypes.ImageRequest
+	GeneratedImage = protocoltypes.GeneratedImage
+)
+
+const (
+	DefaultAPIBase = "http://127.0.0.1:7860"
+
+	defaultRequestTimeout = 600 * time.Second
+
+	defaultSize = 512
+)
+
+// Provider generates images with a local Stable Diffusion server exposing
+// the AUTOMATIC1111 web UI API (started with --api; Forge and SD.Next
+// serve the same API).
+type Provider struct {
+	apiKey     string // "user:password" for servers started with --api-auth
+	apiBase    string
+	steps      int
+	httpClient *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithSteps sets the number of sampling steps; 0 keeps the server default.
+func WithSteps(steps int) Option {
+	return func(p *Provider) {
+		p.steps = steps
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("a1111: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/sdapi/v1"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+// GenerateImages runs txt2img. model names a checkpoint to switch to for
+// this request; empty or "default" keeps the one loaded.
+func (p *Provider) GenerateImages(ctx context.Context, req ImageRequest, model string) ([]GeneratedImage, error) {
+	width, height := parseSize(req.Size)
+	requestBody := map[string]any{
+		"prompt":          req.Prompt,
+		"negative_prompt": req.NegativePrompt,
+		"width":           width,
+		"height":          height,
+		"batch_size":      max(req.N, 1),
+	}
+	if p.steps > 0 {
+		requestBody["steps"] = p.steps
+	}
+	if model != "" && model != "default" {
+		requestBody["override_settings"] = map[string]any{"sd_model_checkpoint": model}
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/sdapi/v1/txt2img", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if user, password, ok := strings.Cut(p.apiKey, ":"); ok {
+		httpReq.SetBasicAuth(user, password)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	var out struct {
+		Images []string `json:"images"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(out.Images) == 0 {
+		return nil, fmt.Errorf("no images in response")
+	}
+
+	images := make([]GeneratedImage, 0, len(out.Images))
+	for i, encoded := range out.Images {
+		// Some forks prefix the data URL scheme.
+		if _, data, ok := strings.Cut(encoded, ";base64,"); ok {
+			encoded = data
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+		}
+		images = append(images, GeneratedImage{Data: data, MIMEType: http.DetectContentType(data)})
+	}
+	return images, nil
+}
+
+// parseSize splits "WIDTHxHEIGHT", defaulting to 512x512.
+func parseSize(size string) (int, int) {
+	w, h, ok := strings.Cut(size, "x")
+	if !ok {
+		return defaultSize, defaultSize
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return defaultSize, defaultSize
+	}
+	return width, height
+}
@@ -0,0 +1,61 @@
+package a1111
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdapi/v1/txt2img" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if user, password, _ := r.BasicAuth(); user != "me" || password != "secret" {
+			t.Errorf("basic auth = %q:%q", user, password)
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		encoded := base64.StdEncoding.EncodeToString(png)
+		json.NewEncoder(w).Encode(map[string]any{
+			"images": []string{encoded, "data:image/png;base64," + encoded},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("me:secret", server.URL+"/sdapi/v1", "", WithSteps(20))
+	images, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "a cat", Size: "768x512", N: 2}, "sdxl_base.safetensors")
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 2 || images[1].MIMEType != "image/png" {
+		t.Errorf("images = %+v", images)
+	}
+	if requestBody["width"] != float64(768) || requestBody["height"] != float64(512) ||
+		requestBody["batch_size"] != float64(2) || requestBody["steps"] != float64(20) {
+		t.Errorf("request = %v", requestBody)
+	}
+	override, _ := requestBody["override_settings"].(map[string]any)
+	if override["sd_model_checkpoint"] != "sdxl_base.safetensors" {
+		t.Errorf("override_settings = %v", requestBody["override_settings"])
+	}
+}
+
+func TestGenerateImages_DefaultModel(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Write([]byte(`{"images":["iVBORw0KGgo="]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewProvider("", server.URL, "").GenerateImages(t.Context(), ImageRequest{Prompt: "x"}, "default"); err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if _, ok := requestBody["override_settings"]; ok || requestBody["width"] != float64(512) {
+		t.Errorf("request = %v", requestBody)
+	}
+}
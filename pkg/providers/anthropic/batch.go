@@ -0,0 +1,211 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	BatchRequest = protocoltypes.BatchRequest
+	BatchStatus  = protocoltypes.BatchStatus
+	BatchResult  = protocoltypes.BatchResult
+)
+
+// SubmitBatch creates a Message Batch for requests and returns its ID.
+// Batches finish within 24 hours at half the synchronous price.
+// See: https://docs.anthropic.com/en/docs/build-with-claude/batch-processing
+func (p *Provider) SubmitBatch(ctx context.Context, requests []BatchRequest, model string) (string, error) {
+	if len(requests) == 0 {
+		return "", fmt.Errorf("batch is empty")
+	}
+
+	type batchItem struct {
+		CustomID string           `json:"custom_id"`
+		Params   *messagesRequest `json:"params"`
+	}
+	items := make([]batchItem, 0, len(requests))
+	for _, r := range requests {
+		params := buildRequest(r.Messages, r.Tools, model, r.Options)
+		applyThinking(params, p.thinkingBudget)
+		p.applyServerTools(params)
+		items = append(items, batchItem{CustomID: r.CustomID, Params: params})
+	}
+
+	jsonData, err := json.Marshal(map[string]any{"requests": items})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/messages/batches", bytes.NewReader(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+	var batch apiBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return batch.ID, nil
+}
+
+// BatchStatus reports the progress of batch id.
+func (p *Provider) BatchStatus(ctx context.Context, id string) (*BatchStatus, error) {
+	batch, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return batch.status(), nil
+}
+
+// BatchResults returns the results of a finished batch, successes and
+// failures alike, in no particular order.
+func (p *Provider) BatchResults(ctx context.Context, id string) ([]BatchResult, error) {
+	batch, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.ResultsURL == "" {
+		return nil, fmt.Errorf("batch %s is still %s", id, batch.ProcessingStatus)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", batch.ResultsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	body, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchResults(body)
+}
+
+type apiBatch struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"` // "in_progress", "canceling" or "ended"
+	ResultsURL       string `json:"results_url"`
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+}
+
+func (b *apiBatch) status() *BatchStatus {
+	c := b.RequestCounts
+	return &BatchStatus{
+		ID:        b.ID,
+		Status:    b.ProcessingStatus,
+		Done:      b.ProcessingStatus == "ended",
+		Total:     c.Processing + c.Succeeded + c.Errored + c.Canceled + c.Expired,
+		Succeeded: c.Succeeded,
+		Failed:    c.Errored + c.Canceled + c.Expired,
+	}
+}
+
+func (p *Provider) getBatch(ctx context.Context, id string) (*apiBatch, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+"/messages/batches/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	body, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var batch apiBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &batch, nil
+}
+
+// parseBatchResults decodes the JSONL results of a batch: one line per
+// request with the message, or why there is none.
+func parseBatchResults(content []byte) ([]BatchResult, error) {
+	var results []BatchResult
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Result   struct {
+				Type    string          `json:"type"` // "succeeded", "errored", "canceled" or "expired"
+				Message json.RawMessage `json:"message"`
+				Error   struct {
+					Error struct {
+						Type    string `json:"type"`
+						Message string `json:"message"`
+					} `json:"error"`
+				} `json:"error"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch result: %w", err)
+		}
+
+		result := BatchResult{CustomID: line.CustomID}
+		switch line.Result.Type {
+		case "succeeded":
+			resp, err := parseResponse(line.Result.Message)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Response = resp
+			}
+		case "errored":
+			result.Error = fmt.Sprintf("%s: %s", line.Result.Error.Error.Type, line.Result.Error.Error.Message)
+		default:
+			result.Error = line.Result.Type
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch results: %w", err)
+	}
+	return results, nil
+}
+
+// do sends req with the API headers and returns the body of a 200 response.
+func (p *Provider) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("anthropic-version", apiVersion)
+	if p.apiKey != "" {
+		req.Header.Set("x-api-key", p.apiKey)
+	}
+	p.setBetas(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	return body, nil
+}
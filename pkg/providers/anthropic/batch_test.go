@@ -0,0 +1,94 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderBatch_SubmitStatusResults(t *testing.T) {
+	var submitted struct {
+		Requests []struct {
+			CustomID string         `json:"custom_id"`
+			Params   map[string]any `json:"params"`
+		} `json:"requests"`
+	}
+	mux := http.NewServeMux()
+	var serverURL string
+	mux.HandleFunc("POST /messages/batches", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&submitted)
+		w.Write([]byte(`{"id":"msgbatch_1","processing_status":"in_progress","request_counts":{"processing":2}}`))
+	})
+	mux.HandleFunc("GET /messages/batches/msgbatch_1", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"msgbatch_1","processing_status":"ended","results_url":"`+serverURL+`/results/msgbatch_1",`+
+			`"request_counts":{"processing":0,"succeeded":1,"errored":1,"canceled":0,"expired":0}}`)
+	})
+	mux.HandleFunc("GET /results/msgbatch_1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" {
+			t.Error("results fetched without the API key")
+		}
+		io.WriteString(w, `{"custom_id":"note-1","result":{"type":"succeeded","message":`+
+			`{"content":[{"type":"text","text":"Summary 1"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":3}}}}`+"\n"+
+			`{"custom_id":"note-2","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"too long"}}}}`+"\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+
+	p := NewProvider("key", server.URL, "")
+	ctx := t.Context()
+	id, err := p.SubmitBatch(ctx, []BatchRequest{
+		{CustomID: "note-1", Messages: []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "summarize 1"}}},
+		{CustomID: "note-2", Messages: []Message{{Role: "user", Content: "summarize 2"}}},
+	}, "claude-haiku-4.5")
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if id != "msgbatch_1" || len(submitted.Requests) != 2 {
+		t.Fatalf("id = %q, submitted = %+v", id, submitted)
+	}
+	params := submitted.Requests[0].Params
+	if submitted.Requests[0].CustomID != "note-1" || params["model"] == nil || params["system"] == nil || params["max_tokens"] == nil {
+		t.Errorf("params = %v", params)
+	}
+
+	status, err := p.BatchStatus(ctx, id)
+	if err != nil {
+		t.Fatalf("BatchStatus() error = %v", err)
+	}
+	if !status.Done || status.Total != 2 || status.Succeeded != 1 || status.Failed != 1 {
+		t.Errorf("status = %+v", status)
+	}
+
+	results, err := p.BatchResults(ctx, id)
+	if err != nil {
+		t.Fatalf("BatchResults() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Response == nil || results[0].Response.Content != "Summary 1" || results[0].Response.Usage.PromptTokens != 10 {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Response != nil || !strings.Contains(results[1].Error, "too long") {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
+
+func TestProviderBatchResults_InProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"msgbatch_1","processing_status":"in_progress"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.BatchResults(t.Context(), "msgbatch_1"); err == nil || !strings.Contains(err.Error(), "in_progress") {
+		t.Errorf("BatchResults() error = %v", err)
+	}
+}
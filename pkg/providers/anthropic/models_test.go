@@ -0,0 +1,32 @@
+package anthropic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderListModels(t *testing.T) {
+	var apiKey, version string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		apiKey, version = r.Header.Get("x-api-key"), r.Header.Get("anthropic-version")
+		w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-5"}],"has_more":false}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	models, err := p.ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 1 || models[0] != "claude-sonnet-4-5" {
+		t.Errorf("models = %v", models)
+	}
+	if apiKey != "key" || version != apiVersion {
+		t.Errorf("x-api-key = %q, anthropic-version = %q", apiKey, version)
+	}
+}
@@ -0,0 +1,641 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+	ContentBlock   = protocoltypes.ContentBlock
+	CacheControl   = protocoltypes.CacheControl
+	ImagePart      = protocoltypes.ImagePart
+	ResponseSchema = protocoltypes.ResponseSchema
+
+	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+)
+
+const (
+	DefaultAPIBase = "https://api.anthropic.com/v1"
+
+	// apiVersion is the value sent in the anthropic-version header.
+	apiVersion = "2023-06-01"
+
+	// defaultMaxTokens is used when the caller does not pass max_tokens.
+	// The Messages API rejects requests without it.
+	defaultMaxTokens = 4096
+
+	// minThinkingBudget is the smallest budget_tokens the API accepts.
+	minThinkingBudget = 1024
+
+	defaultRequestTimeout = 120 * time.Second
+)
+
+// Provider talks to the Anthropic Messages API natively (not through an
+// OpenAI-compatible shim), so that prompt caching via cache_control works.
+type Provider struct {
+	apiKey         string
+	apiBase        string
+	thinkingBudget int
+	serverTools    []map[string]any
+	betas          []string
+	httpClient     *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithThinkingBudget enables extended thinking with up to tokens thinking
+// tokens per request (at least 1024). Zero leaves thinking off.
+func WithThinkingBudget(tokens int) Option {
+	return func(p *Provider) {
+		p.thinkingBudget = tokens
+	}
+}
+
+// WithServerTools offers tools that Anthropic runs itself, such as
+// {"type": "web_search_20250305", "name": "web_search"}, with every request.
+// Their results are folded into the reply; only the text is returned.
+// See: https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/overview
+func WithServerTools(tools []map[string]any) Option {
+	return func(p *Provider) {
+		p.serverTools = tools
+	}
+}
+
+// WithBetas sends the anthropic-beta header with the given features, for
+// server tools that are still in beta (e.g. "code-execution-2025-08-25").
+func WithBetas(betas ...string) Option {
+	return func(p *Provider) {
+		p.betas = append(p.betas, betas...)
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("anthropic: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	requestBody := buildRequest(messages, tools, model, options)
+	applyThinking(requestBody, p.thinkingBudget)
+	p.applyServerTools(requestBody)
+
+	resp, err := p.post(ctx, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	parsed, err := parseResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	return applyResponseSchema(parsed, options), nil
+}
+
+// post sends a Messages API request. The caller owns the response body.
+func (p *Provider) post(ctx context.Context, requestBody *messagesRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/messages", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", apiVersion)
+	if p.apiKey != "" {
+		req.Header.Set("x-api-key", p.apiKey)
+	}
+	p.setBetas(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// setBetas adds the anthropic-beta header for the configured beta features.
+func (p *Provider) setBetas(req *http.Request) {
+	if len(p.betas) > 0 {
+		req.Header.Set("anthropic-beta", strings.Join(p.betas, ","))
+	}
+}
+
+// applyServerTools puts the configured server tools ahead of the client
+// tools, so the cache breakpoint on the last client tool still covers them.
+// Forced tool use (structured output) is left alone.
+func (p *Provider) applyServerTools(req *messagesRequest) {
+	if len(p.serverTools) == 0 || req.ToolChoice != nil {
+		return
+	}
+	tools := make([]apiTool, 0, len(p.serverTools)+len(req.Tools))
+	for _, st := range p.serverTools {
+		t := apiTool{Settings: map[string]any{}}
+		for k, v := range st {
+			switch k {
+			case "type":
+				t.Type, _ = v.(string)
+			case "name":
+				t.Name, _ = v.(string)
+			default:
+				t.Settings[k] = v
+			}
+		}
+		tools = append(tools, t)
+	}
+	req.Tools = append(tools, req.Tools...)
+}
+
+// Wire types for the Messages API. Only the fields picoclaw uses are modelled.
+
+type cacheControl struct {
+	Type string `json:"type"`
+}
+
+type textBlock struct {
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	CacheControl *cacheControl `json:"cache_control,omitempty"`
+}
+
+type contentBlock struct {
+	Type      string       `json:"type"`
+	Text      string       `json:"text,omitempty"`
+	Thinking  string       `json:"thinking,omitempty"`
+	Signature string       `json:"signature,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	Name      string       `json:"name,omitempty"`
+	Input     any          `json:"input,omitempty"` // any so an empty object is still sent
+	ToolUseID string       `json:"tool_use_id,omitempty"`
+	Content   string       `json:"content,omitempty"`
+	Source    *imageSource `json:"source,omitempty"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type apiMessage struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type apiTool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	InputSchema  map[string]any `json:"input_schema,omitempty"`
+	CacheControl *cacheControl  `json:"cache_control,omitempty"`
+
+	// Server tools: Type is the versioned tool type and Settings its other
+	// fields (max_uses, allowed_domains, ...), sent alongside the ones above.
+	Type     string         `json:"type,omitempty"`
+	Settings map[string]any `json:"-"`
+}
+
+func (t apiTool) MarshalJSON() ([]byte, error) {
+	type plain apiTool
+	data, err := json.Marshal(plain(t))
+	if err != nil || len(t.Settings) == 0 {
+		return data, err
+	}
+	fields := map[string]any{}
+	for k, v := range t.Settings {
+		fields[k] = v
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+type messagesRequest struct {
+	Model         string       `json:"model"`
+	MaxTokens     int          `json:"max_tokens"`
+	System        []textBlock  `json:"system,omitempty"`
+	Messages      []apiMessage `json:"messages"`
+	Tools         []apiTool    `json:"tools,omitempty"`
+	ToolChoice    *toolChoice  `json:"tool_choice,omitempty"`
+	Temperature   *float64     `json:"temperature,omitempty"`
+	StopSequences []string     `json:"stop_sequences,omitempty"`
+	Thinking      *thinking    `json:"thinking,omitempty"`
+	Stream        bool         `json:"stream,omitempty"`
+}
+
+type thinking struct {
+	Type         string `json:"type"` // "enabled"
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// buildRequest converts picoclaw messages and tools into a Messages API request.
+//
+// Prompt caching: the static system block (marked ephemeral by the context
+// builder via SystemParts) and the last tool definition carry cache_control,
+// so the tools + system prefix is cached across turns of a long session.
+// See: https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching
+func buildRequest(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) *messagesRequest {
+	req := &messagesRequest{
+		Model:     normalizeModel(model),
+		MaxTokens: defaultMaxTokens,
+	}
+
+	if maxTokens, ok := asInt(options["max_tokens"]); ok && maxTokens > 0 {
+		req.MaxTokens = maxTokens
+	}
+	if temperature, ok := asFloat(options["temperature"]); ok {
+		req.Temperature = &temperature
+	}
+	req.StopSequences = protocoltypes.StopOption(options)
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			req.System = append(req.System, buildSystemBlocks(msg)...)
+
+		case "user":
+			// Images go before the text, as recommended for Claude.
+			blocks := imageBlocks(msg.Images)
+			if msg.Content != "" || len(blocks) == 0 {
+				blocks = append(blocks, contentBlock{Type: "text", Text: msg.Content})
+			}
+			req.Messages = appendBlocks(req.Messages, "user", blocks...)
+
+		case "assistant":
+			var blocks []contentBlock
+			// Signed thinking must be echoed back before the tool_use blocks
+			// it led to, or the API rejects the tool result turn.
+			if msg.ReasoningSignature != "" {
+				blocks = append(blocks, contentBlock{
+					Type:      "thinking",
+					Thinking:  msg.ReasoningContent,
+					Signature: msg.ReasoningSignature,
+				})
+			}
+			if msg.Content != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				blocks = append(blocks, toolUseBlock(tc))
+			}
+			if len(blocks) == 0 {
+				continue
+			}
+			req.Messages = appendBlocks(req.Messages, "assistant", blocks...)
+
+		case "tool":
+			// Tool results are sent back as user turns containing tool_result blocks.
+			req.Messages = appendBlocks(req.Messages, "user", contentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		}
+	}
+
+	if len(tools) > 0 {
+		req.Tools = make([]apiTool, 0, len(tools))
+		for _, t := range tools {
+			schema := t.Function.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			req.Tools = append(req.Tools, apiTool{
+				Name:        t.Function.Name,
+				Description: t.Function.Description,
+				InputSchema: schema,
+			})
+		}
+		// A breakpoint on the last tool caches the whole tool list.
+		req.Tools[len(req.Tools)-1].CacheControl = &cacheControl{Type: "ephemeral"}
+	}
+
+	// Structured output: the Messages API has no JSON mode, so the schema is
+	// offered as a tool the model is forced to call; its input is the reply.
+	if schema := protocoltypes.ResponseSchemaOption(options); schema != nil {
+		req.Tools = append(req.Tools, apiTool{
+			Name:        schema.Name,
+			Description: schema.Description,
+			InputSchema: schema.Schema,
+		})
+		req.ToolChoice = &toolChoice{Type: "tool", Name: schema.Name}
+	}
+
+	return req
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// applyThinking enables extended thinking on req. max_tokens must exceed the
+// budget, so the budget is added on top of the caller's answer allowance.
+// Thinking requires the default temperature and is incompatible with forced
+// tool use, so structured output requests are sent without it.
+func applyThinking(req *messagesRequest, budget int) {
+	if budget <= 0 || req.ToolChoice != nil {
+		return
+	}
+	budget = max(budget, minThinkingBudget)
+	req.Thinking = &thinking{Type: "enabled", BudgetTokens: budget}
+	req.MaxTokens += budget
+	req.Temperature = nil
+}
+
+// applyResponseSchema turns the forced schema tool call back into a plain
+// JSON reply, so callers see the same response shape as with providers that
+// support structured output natively.
+func applyResponseSchema(resp *LLMResponse, options map[string]any) *LLMResponse {
+	schema := protocoltypes.ResponseSchemaOption(options)
+	if schema == nil || resp == nil {
+		return resp
+	}
+	for _, tc := range resp.ToolCalls {
+		if tc.Name != schema.Name {
+			continue
+		}
+		data, err := json.Marshal(tc.Arguments)
+		if err != nil {
+			return resp
+		}
+		resp.Content = string(data)
+		resp.ToolCalls = nil
+		resp.FinishReason = "stop"
+		return resp
+	}
+	return resp
+}
+
+func imageBlocks(images []ImagePart) []contentBlock {
+	var blocks []contentBlock
+	for _, img := range images {
+		switch {
+		case img.Data != "":
+			blocks = append(blocks, contentBlock{Type: "image", Source: &imageSource{
+				Type:      "base64",
+				MediaType: img.MIMEType,
+				Data:      img.Data,
+			}})
+		case img.URL != "":
+			blocks = append(blocks, contentBlock{Type: "image", Source: &imageSource{Type: "url", URL: img.URL}})
+		}
+	}
+	return blocks
+}
+
+// buildSystemBlocks maps a system message to top-level system blocks.
+// Structured SystemParts keep their per-block cache_control; a plain system
+// message is sent as one cached block.
+func buildSystemBlocks(msg Message) []textBlock {
+	if len(msg.SystemParts) == 0 {
+		if msg.Content == "" {
+			return nil
+		}
+		return []textBlock{{Type: "text", Text: msg.Content, CacheControl: &cacheControl{Type: "ephemeral"}}}
+	}
+
+	blocks := make([]textBlock, 0, len(msg.SystemParts))
+	for _, part := range msg.SystemParts {
+		if part.Text == "" {
+			continue
+		}
+		block := textBlock{Type: "text", Text: part.Text}
+		if part.CacheControl != nil && part.CacheControl.Type != "" {
+			block.CacheControl = &cacheControl{Type: part.CacheControl.Type}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// appendBlocks adds blocks to the conversation, merging into the previous
+// message when it has the same role (the API requires alternating turns).
+func appendBlocks(msgs []apiMessage, role string, blocks ...contentBlock) []apiMessage {
+	if n := len(msgs); n > 0 && msgs[n-1].Role == role {
+		msgs[n-1].Content = append(msgs[n-1].Content, blocks...)
+		return msgs
+	}
+	return append(msgs, apiMessage{Role: role, Content: blocks})
+}
+
+func toolUseBlock(tc ToolCall) contentBlock {
+	name := tc.Name
+	input := tc.Arguments
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		if input == nil && tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &input); err != nil {
+				log.Printf("anthropic: failed to decode tool call arguments for %q: %v", name, err)
+			}
+		}
+	}
+	if input == nil {
+		input = map[string]any{}
+	}
+	return contentBlock{Type: "tool_use", ID: tc.ID, Name: name, Input: input}
+}
+
+func parseResponse(body []byte) (*LLMResponse, error) {
+	var apiResponse struct {
+		Content []struct {
+			Type      string         `json:"type"`
+			Text      string         `json:"text"`
+			Thinking  string         `json:"thinking"`
+			Signature string         `json:"signature"`
+			ID        string         `json:"id"`
+			Name      string         `json:"name"`
+			Input     map[string]any `json:"input"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var content, reasoning strings.Builder
+	var signature string
+	var toolCalls []ToolCall
+	for _, block := range apiResponse.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+			signature = block.Signature
+		case "tool_use":
+			args := block.Input
+			if args == nil {
+				args = map[string]any{}
+			}
+			argsJSON, _ := json.Marshal(args)
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        block.ID,
+				Type:      "function",
+				Name:      block.Name,
+				Arguments: args,
+				Function: &FunctionCall{
+					Name:      block.Name,
+					Arguments: string(argsJSON),
+				},
+			})
+		}
+	}
+
+	u := apiResponse.Usage
+	promptTokens := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+
+	return &LLMResponse{
+		Content:            content.String(),
+		ReasoningContent:   reasoning.String(),
+		ReasoningSignature: signature,
+		ToolCalls:          toolCalls,
+		FinishReason:       mapStopReason(apiResponse.StopReason),
+		Usage: &UsageInfo{
+			PromptTokens:        promptTokens,
+			CompletionTokens:    u.OutputTokens,
+			TotalTokens:         promptTokens + u.OutputTokens,
+			CacheCreationTokens: u.CacheCreationInputTokens,
+			CacheReadTokens:     u.CacheReadInputTokens,
+		},
+	}, nil
+}
+
+// mapStopReason translates Anthropic stop reasons to OpenAI-style finish reasons
+// used throughout the agent loop.
+func mapStopReason(reason string) string {
+	switch reason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "end_turn", "stop_sequence", "":
+		return "stop"
+	default:
+		return reason
+	}
+}
+
+// normalizeModel converts dotted version aliases used in picoclaw configs
+// (e.g. "claude-sonnet-4.6") to Anthropic's dashed model IDs ("claude-sonnet-4-6").
+func normalizeModel(model string) string {
+	return strings.ReplaceAll(strings.TrimSpace(model), ".", "-")
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+func asFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,324 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderChat_SendsCacheControlOnSystemAndTools(t *testing.T) {
+	var requestBody map[string]any
+	var headers http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		headers = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn",` +
+			`"usage":{"input_tokens":10,"output_tokens":2,"cache_read_input_tokens":100}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	resp, err := p.Chat(
+		t.Context(),
+		[]Message{
+			{
+				Role:    "system",
+				Content: "static\n\ndynamic",
+				SystemParts: []ContentBlock{
+					{Type: "text", Text: "static", CacheControl: &CacheControl{Type: "ephemeral"}},
+					{Type: "text", Text: "dynamic"},
+				},
+			},
+			{Role: "user", Content: "hi"},
+		},
+		[]ToolDefinition{
+			{Type: "function", Function: protocolFunction("read_file")},
+			{Type: "function", Function: protocolFunction("write_file")},
+		},
+		"claude-sonnet-4.6",
+		map[string]any{"max_tokens": 1234},
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if got := headers.Get("x-api-key"); got != "key" {
+		t.Errorf("x-api-key = %q, want %q", got, "key")
+	}
+	if got := headers.Get("anthropic-version"); got == "" {
+		t.Error("expected anthropic-version header")
+	}
+	if got := requestBody["model"]; got != "claude-sonnet-4-6" {
+		t.Errorf("model = %v, want claude-sonnet-4-6", got)
+	}
+	if got := requestBody["max_tokens"]; got != float64(1234) {
+		t.Errorf("max_tokens = %v, want 1234", got)
+	}
+
+	system := requestBody["system"].([]any)
+	if len(system) != 2 {
+		t.Fatalf("len(system) = %d, want 2", len(system))
+	}
+	if _, ok := system[0].(map[string]any)["cache_control"]; !ok {
+		t.Error("expected cache_control on static system block")
+	}
+	if _, ok := system[1].(map[string]any)["cache_control"]; ok {
+		t.Error("did not expect cache_control on dynamic system block")
+	}
+
+	tools := requestBody["tools"].([]any)
+	if _, ok := tools[0].(map[string]any)["cache_control"]; ok {
+		t.Error("did not expect cache_control on first tool")
+	}
+	if _, ok := tools[1].(map[string]any)["cache_control"]; !ok {
+		t.Error("expected cache_control on last tool")
+	}
+
+	if resp.Content != "ok" || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Usage.PromptTokens != 110 || resp.Usage.CacheReadTokens != 100 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestBuildRequest_ToolRoundTrip(t *testing.T) {
+	req := buildRequest([]Message{
+		{Role: "user", Content: "weather?"},
+		{
+			Role: "assistant",
+			ToolCalls: []ToolCall{
+				{ID: "toolu_1", Name: "get_weather", Arguments: map[string]any{"city": "SF"}},
+				{ID: "toolu_2", Function: &FunctionCall{Name: "get_time", Arguments: `{"tz":"PST"}`}},
+			},
+		},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "sunny"},
+		{Role: "tool", ToolCallID: "toolu_2", Content: "noon"},
+	}, nil, "claude", nil)
+
+	if len(req.Messages) != 3 {
+		t.Fatalf("len(messages) = %d, want 3 (tool results merged into one user turn)", len(req.Messages))
+	}
+	assistant := req.Messages[1]
+	if assistant.Content[1].Name != "get_time" || assistant.Content[1].Input.(map[string]any)["tz"] != "PST" {
+		t.Errorf("tool_use from Function not decoded: %+v", assistant.Content[1])
+	}
+	results := req.Messages[2]
+	if results.Role != "user" || len(results.Content) != 2 || results.Content[0].Type != "tool_result" {
+		t.Errorf("tool results = %+v", results)
+	}
+	if req.MaxTokens != defaultMaxTokens {
+		t.Errorf("MaxTokens = %d, want default %d", req.MaxTokens, defaultMaxTokens)
+	}
+}
+
+func TestBuildRequest_UserImagesPrecedeText(t *testing.T) {
+	req := buildRequest([]Message{{
+		Role:    "user",
+		Content: "describe",
+		Images: []ImagePart{
+			{MIMEType: "image/jpeg", Data: "/9j/4AAQ"},
+			{URL: "https://example.com/a.png"},
+		},
+	}}, nil, "claude", nil)
+
+	blocks := req.Messages[0].Content
+	if len(blocks) != 3 {
+		t.Fatalf("blocks = %+v, want 2 images + text", blocks)
+	}
+	if b := blocks[0]; b.Type != "image" || b.Source.Type != "base64" ||
+		b.Source.MediaType != "image/jpeg" || b.Source.Data != "/9j/4AAQ" {
+		t.Errorf("inline image block = %+v", b)
+	}
+	if b := blocks[1]; b.Type != "image" || b.Source.Type != "url" || b.Source.URL != "https://example.com/a.png" {
+		t.Errorf("url image block = %+v", b)
+	}
+	if blocks[2].Type != "text" || blocks[2].Text != "describe" {
+		t.Errorf("text block = %+v", blocks[2])
+	}
+}
+
+func TestParseResponse_ToolUse(t *testing.T) {
+	body := `{"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"checking"},` +
+		`{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"SF"}}],` +
+		`"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":3}}`
+
+	resp, err := parseResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.ReasoningContent != "hmm" || resp.Content != "checking" {
+		t.Errorf("content = %q reasoning = %q", resp.Content, resp.ReasoningContent)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["city"] != "SF" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if !strings.Contains(resp.ToolCalls[0].Function.Arguments, `"city":"SF"`) {
+		t.Errorf("Function.Arguments = %q", resp.ToolCalls[0].Function.Arguments)
+	}
+}
+
+func TestProviderChat_HTTPErrorIncludesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"type":"error","error":{"type":"overloaded_error"}}`, 529)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "claude", nil)
+	if err == nil || !strings.Contains(err.Error(), "Status: 529") {
+		t.Fatalf("Chat() error = %v, want status 529", err)
+	}
+}
+
+func protocolFunction(name string) ToolFunctionDefinition {
+	return ToolFunctionDefinition{
+		Name:        name,
+		Description: name + " tool",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+	}
+}
+
+func TestBuildRequest_ResponseSchemaForcesTool(t *testing.T) {
+	schema := ResponseSchema{
+		Name:   "verdict",
+		Schema: map[string]any{"type": "object", "properties": map[string]any{"ok": map[string]any{"type": "boolean"}}},
+	}
+	req := buildRequest([]Message{{Role: "user", Content: "hi"}}, nil, "claude",
+		map[string]any{"response_schema": schema})
+
+	if len(req.Tools) != 1 || req.Tools[0].Name != "verdict" {
+		t.Fatalf("Tools = %+v", req.Tools)
+	}
+	if req.ToolChoice == nil || req.ToolChoice.Type != "tool" || req.ToolChoice.Name != "verdict" {
+		t.Errorf("ToolChoice = %+v", req.ToolChoice)
+	}
+}
+
+func TestApplyResponseSchema_ConvertsToolCallToContent(t *testing.T) {
+	body := `{"content":[{"type":"tool_use","id":"toolu_1","name":"verdict","input":{"ok":true}}],` +
+		`"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":3}}`
+	resp, err := parseResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+
+	options := map[string]any{"response_schema": &ResponseSchema{Name: "verdict", Schema: map[string]any{}}}
+	resp = applyResponseSchema(resp, options)
+	if resp.Content != `{"ok":true}` || len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestApplyThinking_RaisesMaxTokensAndDropsTemperature(t *testing.T) {
+	req := buildRequest([]Message{{Role: "user", Content: "hi"}}, nil, "claude",
+		map[string]any{"max_tokens": 2000, "temperature": 0.7})
+	applyThinking(req, 500)
+
+	if req.Thinking == nil || req.Thinking.Type != "enabled" || req.Thinking.BudgetTokens != minThinkingBudget {
+		t.Fatalf("Thinking = %+v", req.Thinking)
+	}
+	if req.MaxTokens != 2000+minThinkingBudget {
+		t.Errorf("MaxTokens = %d, want %d", req.MaxTokens, 2000+minThinkingBudget)
+	}
+	if req.Temperature != nil {
+		t.Errorf("Temperature = %v, want unset", *req.Temperature)
+	}
+
+	forced := buildRequest([]Message{{Role: "user", Content: "hi"}}, nil, "claude",
+		map[string]any{"response_schema": ResponseSchema{Name: "verdict", Schema: map[string]any{}}})
+	applyThinking(forced, 4096)
+	if forced.Thinking != nil {
+		t.Error("thinking enabled alongside forced tool choice")
+	}
+}
+
+func TestThinkingSignature_RoundTrip(t *testing.T) {
+	body := `{"content":[{"type":"thinking","thinking":"hmm","signature":"sig"},` +
+		`{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"SF"}}],` +
+		`"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":3}}`
+	resp, err := parseResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if resp.ReasoningSignature != "sig" {
+		t.Fatalf("ReasoningSignature = %q, want sig", resp.ReasoningSignature)
+	}
+
+	req := buildRequest([]Message{
+		{Role: "user", Content: "weather?"},
+		{
+			Role:               "assistant",
+			ReasoningContent:   resp.ReasoningContent,
+			ReasoningSignature: resp.ReasoningSignature,
+			ToolCalls:          resp.ToolCalls,
+		},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "sunny"},
+	}, nil, "claude", nil)
+
+	blocks := req.Messages[1].Content
+	if len(blocks) != 2 || blocks[0].Type != "thinking" || blocks[0].Signature != "sig" || blocks[1].Type != "tool_use" {
+		t.Errorf("assistant blocks = %+v", blocks)
+	}
+}
+
+func TestProviderChat_SendsServerToolsAndBetas(t *testing.T) {
+	var requestBody map[string]any
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"content":[` +
+			`{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"picoclaw"}},` +
+			`{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[]},` +
+			`{"type":"text","text":"Found it."}],"stop_reason":"end_turn","usage":{}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "",
+		WithServerTools([]map[string]any{
+			{"type": "web_search_20250305", "name": "web_search", "max_uses": 3},
+		}),
+		WithBetas("code-execution-2025-08-25"))
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "search"}},
+		[]ToolDefinition{{Type: "function", Function: protocolFunction("read_file")}}, "claude", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if beta != "code-execution-2025-08-25" {
+		t.Errorf("anthropic-beta = %q", beta)
+	}
+	tools := requestBody["tools"].([]any)
+	if len(tools) != 2 {
+		t.Fatalf("tools = %v", tools)
+	}
+	search := tools[0].(map[string]any)
+	if search["type"] != "web_search_20250305" || search["name"] != "web_search" ||
+		search["max_uses"] != float64(3) || search["input_schema"] != nil {
+		t.Errorf("server tool = %v", search)
+	}
+	if tools[1].(map[string]any)["cache_control"] == nil {
+		t.Error("expected the cache breakpoint to stay on the last tool")
+	}
+	if resp.Content != "Found it." || len(resp.ToolCalls) != 0 {
+		t.Errorf("resp = %+v, want only the text", resp)
+	}
+}
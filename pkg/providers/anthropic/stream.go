@@ -0,0 +1,212 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/sse"
+)
+
+// StreamChat performs a streaming Messages API call. onDelta is called with
+// each text delta as it arrives; the returned response is the fully
+// assembled message, including any tool_use blocks.
+func (p *Provider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(delta string),
+) (*LLMResponse, error) {
+	requestBody := buildRequest(messages, tools, model, options)
+	applyThinking(requestBody, p.thinkingBudget)
+	p.applyServerTools(requestBody)
+	requestBody.Stream = true
+
+	resp, err := p.post(ctx, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	parsed, err := readStream(resp.Body, onDelta, protocoltypes.ToolCallDeltas(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return applyResponseSchema(parsed, options), nil
+}
+
+// streamBlock accumulates one content block of a streamed message.
+type streamBlock struct {
+	Type      string
+	Text      string
+	Thinking  string
+	Signature string
+	ID        string
+	Name      string
+	InputJSON string
+}
+
+// readStream consumes the Messages API event stream and reassembles the
+// final message, which is then decoded by parseResponse so the streaming and
+// non-streaming paths share the same block handling. onToolCall, if set,
+// sees each tool_use fragment and may abort the stream.
+func readStream(
+	r io.Reader,
+	onDelta func(delta string),
+	onToolCall func(protocoltypes.ToolCallDelta) error,
+) (*LLMResponse, error) {
+	var (
+		blocks     []*streamBlock
+		stopReason string
+		usage      = map[string]int{}
+	)
+
+	blockAt := func(index int) *streamBlock {
+		for len(blocks) <= index {
+			blocks = append(blocks, &streamBlock{})
+		}
+		return blocks[index]
+	}
+
+	err := sse.Read(r, func(event, data string) error {
+		var ev struct {
+			Type    string `json:"type"`
+			Index   int    `json:"index"`
+			Message struct {
+				Usage map[string]int `json:"usage"`
+			} `json:"message"`
+			ContentBlock struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"content_block"`
+			Delta struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				Thinking    string `json:"thinking"`
+				Signature   string `json:"signature"`
+				PartialJSON string `json:"partial_json"`
+				StopReason  string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage map[string]int `json:"usage"`
+			Error *struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("failed to unmarshal stream event %q: %w", event, err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			for k, v := range ev.Message.Usage {
+				usage[k] = v
+			}
+		case "content_block_start":
+			b := blockAt(ev.Index)
+			b.Type = ev.ContentBlock.Type
+			b.ID = ev.ContentBlock.ID
+			b.Name = ev.ContentBlock.Name
+			if onToolCall != nil && b.Type == "tool_use" {
+				if err := onToolCall(protocoltypes.ToolCallDelta{Index: ev.Index, ID: b.ID, Name: b.Name}); err != nil {
+					return err
+				}
+			}
+		case "content_block_delta":
+			b := blockAt(ev.Index)
+			switch ev.Delta.Type {
+			case "text_delta":
+				b.Text += ev.Delta.Text
+				if onDelta != nil && ev.Delta.Text != "" {
+					onDelta(ev.Delta.Text)
+				}
+			case "thinking_delta":
+				b.Thinking += ev.Delta.Thinking
+			case "signature_delta":
+				b.Signature += ev.Delta.Signature
+			case "input_json_delta":
+				b.InputJSON += ev.Delta.PartialJSON
+				// server_tool_use inputs stream too, but Anthropic runs those.
+				if onToolCall != nil && b.Type == "tool_use" {
+					if err := onToolCall(protocoltypes.ToolCallDelta{
+						Index:     ev.Index,
+						Arguments: ev.Delta.PartialJSON,
+					}); err != nil {
+						return err
+					}
+				}
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				stopReason = ev.Delta.StopReason
+			}
+			for k, v := range ev.Usage {
+				usage[k] = v
+			}
+		case "error":
+			if ev.Error != nil {
+				return protocoltypes.WithKind(fmt.Errorf("stream error: %s: %s", ev.Error.Type, ev.Error.Message),
+					protocoltypes.ClassifyError(0, ev.Error.Type+" "+ev.Error.Message))
+			}
+			return fmt.Errorf("stream error: %s", data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	content := make([]map[string]any, 0, len(blocks))
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			content = append(content, map[string]any{"type": "text", "text": b.Text})
+		case "thinking":
+			content = append(content, map[string]any{
+				"type":      "thinking",
+				"thinking":  b.Thinking,
+				"signature": b.Signature,
+			})
+		case "tool_use":
+			input := map[string]any{}
+			if b.InputJSON != "" {
+				if err := json.Unmarshal([]byte(b.InputJSON), &input); err != nil {
+					return nil, fmt.Errorf("failed to decode tool input for %q: %w", b.Name, err)
+				}
+			}
+			content = append(content, map[string]any{
+				"type":  "tool_use",
+				"id":    b.ID,
+				"name":  b.Name,
+				"input": input,
+			})
+		}
+	}
+
+	assembled, err := json.Marshal(map[string]any{
+		"content":     content,
+		"stop_reason": stopReason,
+		"usage":       usage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble stream response: %w", err)
+	}
+
+	return parseResponse(assembled)
+}
@@ -0,0 +1,109 @@
+package anthropic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestProviderStreamChat_AssemblesMessage(t *testing.T) {
+	events := []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12}}}",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0," +
+			"\"content_block\":{\"type\":\"text\",\"text\":\"\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0," +
+			"\"delta\":{\"type\":\"text_delta\",\"text\":\"Let me \"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0," +
+			"\"delta\":{\"type\":\"text_delta\",\"text\":\"check.\"}}",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1," +
+			"\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"get_weather\",\"input\":{}}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1," +
+			"\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1," +
+			"\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"SF\\\"}\"}}",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"}," +
+			"\"usage\":{\"output_tokens\":9}}",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(strings.Join(events, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	var sb strings.Builder
+	resp, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "weather?"}}, nil, "claude", nil,
+		func(delta string) { sb.WriteString(delta) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+
+	if sb.String() != "Let me check." || resp.Content != "Let me check." {
+		t.Errorf("streamed = %q, content = %q", sb.String(), resp.Content)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["city"] != "SF" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 9 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderStreamChat_ErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\"," +
+			"\"message\":\"Overloaded\"}}\n\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "claude", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+}
+
+func TestProviderStreamChat_ToolCallDeltas(t *testing.T) {
+	events := []string{
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0," +
+			"\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"get_weather\",\"input\":{}}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0," +
+			"\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0," +
+			"\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"SF\\\"}\"}}",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(strings.Join(events, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	var got []protocoltypes.ToolCallDelta
+	ctx := protocoltypes.WithToolCallDeltas(t.Context(), func(d protocoltypes.ToolCallDelta) error {
+		got = append(got, d)
+		return nil
+	})
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.StreamChat(ctx, []Message{{Role: "user", Content: "weather?"}}, nil, "claude", nil, nil); err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+
+	want := []protocoltypes.ToolCallDelta{
+		{Index: 0, ID: "toolu_1", Name: "get_weather"},
+		{Index: 0, Arguments: `{"city":`},
+		{Index: 0, Arguments: `"SF"}`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deltas = %+v, want %+v", got, want)
+	}
+}
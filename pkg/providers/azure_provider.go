@@ -0,0 +1,68 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+// defaultAzureAPIVersion is the Azure OpenAI data-plane API version used when
+// the model config does not set api_version.
+const defaultAzureAPIVersion = "2024-10-21"
+
+// NewAzureProvider creates an HTTPProvider for an Azure OpenAI deployment.
+// Requests go to {endpoint}/openai/deployments/{deployment}/chat/completions
+// with the api-version query parameter and the key in the api-key header.
+func NewAzureProvider(
+	apiKey, endpoint, deployment, apiVersion, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+	opts ...openai_compat.Option,
+) *HTTPProvider {
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	return &HTTPProvider{
+		delegate: openai_compat.NewProvider(
+			apiKey,
+			azureDeploymentBase(endpoint, deployment),
+			proxy,
+			append([]openai_compat.Option{
+				openai_compat.WithMaxTokensField(maxTokensField),
+				openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+				openai_compat.WithAPIKeyHeader("api-key"),
+				openai_compat.WithQueryParams(url.Values{"api-version": {apiVersion}}),
+			}, opts...)...,
+		),
+	}
+}
+
+// azureEndpoint returns the resource endpoint for an Azure model config:
+// api_base when set, otherwise derived from the resource name.
+func azureEndpoint(apiBase, resource string) (string, error) {
+	if apiBase != "" {
+		return apiBase, nil
+	}
+	if resource == "" {
+		return "", fmt.Errorf("api_base or resource is required for protocol \"azure\"")
+	}
+	return fmt.Sprintf("https://%s.openai.azure.com", resource), nil
+}
+
+// azureDeploymentBase builds the deployment base URL. An endpoint that already
+// names a deployment is used as-is.
+func azureDeploymentBase(endpoint, deployment string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if strings.Contains(endpoint, "/deployments/") {
+		return endpoint
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/openai")
+	return endpoint + "/openai/deployments/" + url.PathEscape(deployment)
+}
@@ -0,0 +1,77 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestAzureProvider_DeploymentURLAndAPIKeyHeader(t *testing.T) {
+	var path, apiVersion, apiKey, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		apiVersion = r.URL.Query().Get("api-version")
+		apiKey = r.Header.Get("api-key")
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:  "azure-gpt",
+		Model:      "azure/my-gpt4o",
+		APIKey:     "azure-key",
+		APIBase:    server.URL,
+		APIVersion: "2025-01-01-preview",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != "my-gpt4o" {
+		t.Errorf("modelID = %q, want my-gpt4o", modelID)
+	}
+
+	resp, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if path != "/openai/deployments/my-gpt4o/chat/completions" {
+		t.Errorf("path = %q", path)
+	}
+	if apiVersion != "2025-01-01-preview" {
+		t.Errorf("api-version = %q", apiVersion)
+	}
+	if apiKey != "azure-key" || authorization != "" {
+		t.Errorf("api-key = %q, Authorization = %q", apiKey, authorization)
+	}
+}
+
+func TestAzureEndpoint(t *testing.T) {
+	if _, err := azureEndpoint("", ""); err == nil {
+		t.Error("expected error without api_base or resource")
+	}
+	endpoint, err := azureEndpoint("", "myres")
+	if err != nil || endpoint != "https://myres.openai.azure.com" {
+		t.Errorf("azureEndpoint() = %q, %v", endpoint, err)
+	}
+
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"https://r.openai.azure.com", "https://r.openai.azure.com/openai/deployments/dep"},
+		{"https://r.openai.azure.com/openai/", "https://r.openai.azure.com/openai/deployments/dep"},
+		{"https://r.openai.azure.com/openai/deployments/other", "https://r.openai.azure.com/openai/deployments/other"},
+	}
+	for _, tt := range tests {
+		if got := azureDeploymentBase(tt.endpoint, "dep"); got != tt.want {
+			t.Errorf("azureDeploymentBase(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	BatchRequest = protocoltypes.BatchRequest
+	BatchStatus  = protocoltypes.BatchStatus
+	BatchResult  = protocoltypes.BatchResult
+)
+
+// defaultBatchPollInterval is how often WaitBatch checks a batch. Batches
+// take minutes to hours, so there's no point polling faster.
+const defaultBatchPollInterval = time.Minute
+
+// BatchProvider runs chat requests through a provider's asynchronous batch
+// API, which answers within 24 hours at about half the price of Chat. It
+// suits bulk jobs that don't need an immediate answer, such as nightly
+// summarization. The model is fixed when the provider is created (see
+// CreateBatchProviderFromConfig).
+type BatchProvider interface {
+	// Submit starts a batch and returns its ID. Keep the ID to collect the
+	// results later, possibly from another process.
+	Submit(ctx context.Context, requests []BatchRequest) (string, error)
+	Status(ctx context.Context, id string) (*BatchStatus, error)
+	// Results returns the results of a finished batch, in no particular
+	// order; match them to requests by CustomID.
+	Results(ctx context.Context, id string) ([]BatchResult, error)
+}
+
+// batchAPI is the batch interface of the protocol adapters, which take the
+// model per call.
+type batchAPI interface {
+	SubmitBatch(ctx context.Context, requests []BatchRequest, model string) (string, error)
+	BatchStatus(ctx context.Context, id string) (*BatchStatus, error)
+	BatchResults(ctx context.Context, id string) ([]BatchResult, error)
+}
+
+// modelBatcher binds a batchAPI to a model.
+type modelBatcher struct {
+	api   batchAPI
+	model string
+}
+
+func (b *modelBatcher) Submit(ctx context.Context, requests []BatchRequest) (string, error) {
+	return b.api.SubmitBatch(ctx, requests, b.model)
+}
+
+func (b *modelBatcher) Status(ctx context.Context, id string) (*BatchStatus, error) {
+	return b.api.BatchStatus(ctx, id)
+}
+
+func (b *modelBatcher) Results(ctx context.Context, id string) ([]BatchResult, error) {
+	return b.api.BatchResults(ctx, id)
+}
+
+// CreateBatchProviderFromConfig creates a batch provider for the model_list
+// entry cfg.
+// Supported protocols: openai, anthropic
+func CreateBatchProviderFromConfig(cfg *config.ModelConfig) (BatchProvider, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	protocol, modelID := ExtractProtocol(cfg.Model)
+	timeout := time.Duration(cfg.RequestTimeout) * time.Second
+
+	switch protocol {
+	case "openai":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = "https://api.openai.com/v1"
+		}
+		compatOpts, err := compatOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		p := openai_compat.NewProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			append([]openai_compat.Option{
+				openai_compat.WithMaxTokensField(cfg.MaxTokensField),
+				openai_compat.WithRequestTimeout(timeout),
+			}, compatOpts...)...,
+		)
+		return &modelBatcher{api: p, model: modelID}, nil
+
+	case "anthropic":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		opts := []anthropicprovider.Option{anthropicprovider.WithRequestTimeout(timeout)}
+		if budget, ok := thinkingBudget(cfg); ok && budget > 0 {
+			opts = append(opts, anthropicprovider.WithThinkingBudget(budget))
+		}
+		p := anthropicprovider.NewProvider(cfg.APIKey, apiBase, cfg.Proxy, opts...)
+		return &modelBatcher{api: p, model: modelID}, nil
+
+	default:
+		return nil, fmt.Errorf("protocol %q does not support batches (model %q)", protocol, cfg.Model)
+	}
+}
+
+// WaitBatch polls batch id every interval (a minute when <= 0) until it is
+// done, then returns its results. It returns early with ctx's error; the
+// batch keeps running and can be waited for again.
+func WaitBatch(ctx context.Context, bp BatchProvider, id string, interval time.Duration) ([]BatchResult, error) {
+	if interval <= 0 {
+		interval = defaultBatchPollInterval
+	}
+	for {
+		status, err := bp.Status(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("batch %s: %w", id, err)
+		}
+		if status.Done {
+			results, err := bp.Results(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("batch %s: %w", id, err)
+			}
+			return results, nil
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// RunBatch submits requests and waits for their results (see WaitBatch).
+// The batch ID is returned even when waiting fails, so the caller can
+// resume.
+func RunBatch(
+	ctx context.Context,
+	bp BatchProvider,
+	requests []BatchRequest,
+	interval time.Duration,
+) (string, []BatchResult, error) {
+	id, err := bp.Submit(ctx, requests)
+	if err != nil {
+		return "", nil, err
+	}
+	results, err := WaitBatch(ctx, bp, id, interval)
+	return id, results, err
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type fakeBatchProvider struct {
+	submitted []BatchRequest
+	polls     int
+	doneAfter int
+}
+
+func (f *fakeBatchProvider) Submit(ctx context.Context, requests []BatchRequest) (string, error) {
+	f.submitted = requests
+	return "batch_1", nil
+}
+
+func (f *fakeBatchProvider) Status(ctx context.Context, id string) (*BatchStatus, error) {
+	f.polls++
+	return &BatchStatus{ID: id, Done: f.doneAfter > 0 && f.polls >= f.doneAfter}, nil
+}
+
+func (f *fakeBatchProvider) Results(ctx context.Context, id string) ([]BatchResult, error) {
+	results := make([]BatchResult, len(f.submitted))
+	for i, r := range f.submitted {
+		results[i] = BatchResult{CustomID: r.CustomID, Response: &LLMResponse{Content: "done"}}
+	}
+	return results, nil
+}
+
+func TestRunBatch_PollsUntilDone(t *testing.T) {
+	bp := &fakeBatchProvider{doneAfter: 3}
+	id, results, err := RunBatch(context.Background(), bp, []BatchRequest{{CustomID: "a"}, {CustomID: "b"}}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+	if id != "batch_1" || bp.polls != 3 {
+		t.Errorf("id = %q, polls = %d", id, bp.polls)
+	}
+	if len(results) != 2 || results[1].CustomID != "b" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestWaitBatch_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	bp := &fakeBatchProvider{}
+	if _, err := WaitBatch(ctx, bp, "batch_1", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitBatch() error = %v, want deadline exceeded", err)
+	}
+	if bp.polls == 0 {
+		t.Error("expected at least one status poll")
+	}
+}
+
+func TestCreateBatchProviderFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.ModelConfig
+		wantErr bool
+	}{
+		{"openai", &config.ModelConfig{Model: "openai/gpt-4o-mini", APIKey: "k"}, false},
+		{"anthropic", &config.ModelConfig{Model: "anthropic/claude-haiku-4.5", APIKey: "k"}, false},
+		{"openai without key", &config.ModelConfig{Model: "openai/gpt-4o-mini"}, true},
+		{"unsupported protocol", &config.ModelConfig{Model: "ollama/llama3", APIKey: "k"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp, err := CreateBatchProviderFromConfig(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateBatchProviderFromConfig() error = %v", err)
+			}
+			if bp == nil {
+				t.Fatal("expected provider")
+			}
+		})
+	}
+}
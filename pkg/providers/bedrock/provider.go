@@ -0,0 +1,462 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+
+	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+)
+
+const (
+	service = "bedrock"
+
+	defaultRegion         = "us-east-1"
+	defaultRequestTimeout = 120 * time.Second
+)
+
+// Provider calls the Bedrock Runtime Converse API. Requests are SigV4-signed
+// with AWS credentials, or authenticated with a Bedrock API key when one is
+// configured.
+type Provider struct {
+	region     string
+	profile    string
+	apiKey     string
+	apiBase    string
+	httpClient *http.Client
+
+	loadCredentials func(profile string) (Credentials, error)
+	now             func() time.Time
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithProfile selects a named profile from the shared credentials file.
+func WithProfile(profile string) Option {
+	return func(p *Provider) {
+		p.profile = profile
+	}
+}
+
+// WithAPIKey authenticates with a Bedrock API key (bearer token) instead of
+// SigV4 signing.
+func WithAPIKey(apiKey string) Option {
+	return func(p *Provider) {
+		p.apiKey = apiKey
+	}
+}
+
+// NewProvider creates a Bedrock provider for region. An empty region falls
+// back to AWS_REGION, AWS_DEFAULT_REGION and then us-east-1; an empty apiBase
+// uses the regional bedrock-runtime endpoint.
+func NewProvider(region, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("bedrock: invalid proxy %q: %v", proxy, err)
+	}
+
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+	if apiBase == "" {
+		apiBase = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+
+	p := &Provider{
+		region:          region,
+		apiBase:         strings.TrimRight(apiBase, "/"),
+		httpClient:      client,
+		loadCredentials: LoadCredentials,
+		now:             time.Now,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	jsonData, err := json.Marshal(buildRequest(messages, tools, options))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint, err := url.Parse(fmt.Sprintf("%s/model/%s/converse", p.apiBase, uriEncode(model)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.String(), bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	} else {
+		creds, err := p.loadCredentials(p.profile)
+		if err != nil {
+			return nil, err
+		}
+		signV4(req, jsonData, creds, p.region, service, p.now())
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	return parseResponse(body)
+}
+
+// Wire types for the Converse API. Only the fields picoclaw uses are modelled.
+
+type textBlock struct {
+	Text string `json:"text"`
+}
+
+type toolUse struct {
+	ToolUseID string `json:"toolUseId"`
+	Name      string `json:"name"`
+	Input     any    `json:"input"`
+}
+
+type toolResult struct {
+	ToolUseID string      `json:"toolUseId"`
+	Content   []textBlock `json:"content"`
+}
+
+type imageSource struct {
+	Bytes string `json:"bytes"`
+}
+
+type image struct {
+	Format string      `json:"format"`
+	Source imageSource `json:"source"`
+}
+
+type contentBlock struct {
+	Text       string      `json:"text,omitempty"`
+	Image      *image      `json:"image,omitempty"`
+	ToolUse    *toolUse    `json:"toolUse,omitempty"`
+	ToolResult *toolResult `json:"toolResult,omitempty"`
+}
+
+type apiMessage struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type toolSpec struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type apiTool struct {
+	ToolSpec toolSpec `json:"toolSpec"`
+}
+
+type toolConfig struct {
+	Tools []apiTool `json:"tools"`
+}
+
+type inferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type converseRequest struct {
+	Messages        []apiMessage     `json:"messages"`
+	System          []textBlock      `json:"system,omitempty"`
+	ToolConfig      *toolConfig      `json:"toolConfig,omitempty"`
+	InferenceConfig *inferenceConfig `json:"inferenceConfig,omitempty"`
+}
+
+// buildRequest converts picoclaw messages and tools into a Converse request.
+// Tool results are sent as user turns and consecutive same-role turns are
+// merged, since Converse requires alternating roles.
+func buildRequest(messages []Message, tools []ToolDefinition, options map[string]any) *converseRequest {
+	req := &converseRequest{}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			if msg.Content != "" {
+				req.System = append(req.System, textBlock{Text: msg.Content})
+			}
+
+		case "user":
+			var blocks []contentBlock
+			for _, img := range msg.Images {
+				if img.Data == "" {
+					continue // Converse only accepts inline bytes or S3 locations
+				}
+				blocks = append(blocks, contentBlock{Image: &image{
+					Format: strings.TrimPrefix(img.MIMEType, "image/"),
+					Source: imageSource{Bytes: img.Data},
+				}})
+			}
+			if msg.Content != "" || len(blocks) == 0 {
+				blocks = append(blocks, contentBlock{Text: msg.Content})
+			}
+			req.Messages = appendMessage(req.Messages, "user", blocks)
+
+		case "assistant":
+			var blocks []contentBlock
+			if msg.Content != "" {
+				blocks = append(blocks, contentBlock{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				blocks = append(blocks, contentBlock{ToolUse: toToolUse(tc)})
+			}
+			if len(blocks) > 0 {
+				req.Messages = appendMessage(req.Messages, "assistant", blocks)
+			}
+
+		case "tool":
+			req.Messages = appendMessage(req.Messages, "user", []contentBlock{{
+				ToolResult: &toolResult{
+					ToolUseID: msg.ToolCallID,
+					Content:   []textBlock{{Text: msg.Content}},
+				},
+			}})
+		}
+	}
+
+	if len(tools) > 0 {
+		tc := &toolConfig{}
+		for _, t := range tools {
+			schema := t.Function.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			tc.Tools = append(tc.Tools, apiTool{ToolSpec: toolSpec{
+				Name:        t.Function.Name,
+				Description: t.Function.Description,
+				InputSchema: map[string]any{"json": schema},
+			}})
+		}
+		req.ToolConfig = tc
+	}
+
+	ic := &inferenceConfig{}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		ic.MaxTokens = maxTokens
+	}
+	if temperature, ok := asFloat(options["temperature"]); ok {
+		ic.Temperature = &temperature
+	}
+	ic.StopSequences = protocoltypes.StopOption(options)
+	if ic.MaxTokens > 0 || ic.Temperature != nil || len(ic.StopSequences) > 0 {
+		req.InferenceConfig = ic
+	}
+
+	return req
+}
+
+func appendMessage(messages []apiMessage, role string, blocks []contentBlock) []apiMessage {
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, apiMessage{Role: role, Content: blocks})
+}
+
+func toToolUse(tc ToolCall) *toolUse {
+	name := tc.Name
+	var input any = tc.Arguments
+	if tc.Arguments == nil {
+		args := map[string]any{}
+		if tc.Function != nil && tc.Function.Arguments != "" {
+			_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
+		}
+		input = args
+	}
+	if name == "" && tc.Function != nil {
+		name = tc.Function.Name
+	}
+	return &toolUse{ToolUseID: tc.ID, Name: name, Input: input}
+}
+
+func parseResponse(body []byte) (*LLMResponse, error) {
+	var apiResponse struct {
+		Output struct {
+			Message struct {
+				Content []struct {
+					Text             string `json:"text"`
+					ReasoningContent *struct {
+						ReasoningText struct {
+							Text string `json:"text"`
+						} `json:"reasoningText"`
+					} `json:"reasoningContent"`
+					ToolUse *struct {
+						ToolUseID string         `json:"toolUseId"`
+						Name      string         `json:"name"`
+						Input     map[string]any `json:"input"`
+					} `json:"toolUse"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"output"`
+		StopReason string `json:"stopReason"`
+		Usage      struct {
+			InputTokens           int `json:"inputTokens"`
+			OutputTokens          int `json:"outputTokens"`
+			TotalTokens           int `json:"totalTokens"`
+			CacheReadInputTokens  int `json:"cacheReadInputTokens"`
+			CacheWriteInputTokens int `json:"cacheWriteInputTokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var content, reasoning strings.Builder
+	var toolCalls []ToolCall
+	for _, block := range apiResponse.Output.Message.Content {
+		switch {
+		case block.ToolUse != nil:
+			args := block.ToolUse.Input
+			if args == nil {
+				args = map[string]any{}
+			}
+			argsJSON, _ := json.Marshal(args)
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        block.ToolUse.ToolUseID,
+				Type:      "function",
+				Function:  &FunctionCall{Name: block.ToolUse.Name, Arguments: string(argsJSON)},
+				Name:      block.ToolUse.Name,
+				Arguments: args,
+			})
+		case block.ReasoningContent != nil:
+			reasoning.WriteString(block.ReasoningContent.ReasoningText.Text)
+		default:
+			content.WriteString(block.Text)
+		}
+	}
+
+	u := apiResponse.Usage
+	return &LLMResponse{
+		Content:          content.String(),
+		ReasoningContent: reasoning.String(),
+		ToolCalls:        toolCalls,
+		FinishReason:     mapStopReason(apiResponse.StopReason),
+		Usage: &UsageInfo{
+			PromptTokens:        u.InputTokens + u.CacheReadInputTokens + u.CacheWriteInputTokens,
+			CompletionTokens:    u.OutputTokens,
+			TotalTokens:         u.TotalTokens,
+			CacheCreationTokens: u.CacheWriteInputTokens,
+			CacheReadTokens:     u.CacheReadInputTokens,
+		},
+	}, nil
+}
+
+func mapStopReason(reason string) string {
+	switch reason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+func asFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
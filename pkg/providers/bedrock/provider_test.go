@@ -0,0 +1,142 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProviderChat_SignsConverseRequest(t *testing.T) {
+	var requestBody map[string]any
+	var rawPath, authorization, amzDate string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawPath = r.URL.EscapedPath()
+		authorization = r.Header.Get("Authorization")
+		amzDate = r.Header.Get("X-Amz-Date")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"checking"},` +
+			`{"toolUse":{"toolUseId":"tu_1","name":"get_weather","input":{"city":"SF"}}}]}},` +
+			`"stopReason":"tool_use","usage":{"inputTokens":10,"outputTokens":5,"totalTokens":15}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("eu-west-1", server.URL, "")
+	p.loadCredentials = func(string) (Credentials, error) {
+		return Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	}
+	p.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	resp, err := p.Chat(t.Context(), []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "weather?"},
+	}, []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{
+		Name:       "get_weather",
+		Parameters: map[string]any{"type": "object"},
+	}}}, "anthropic.claude-3-5-sonnet-20240620-v1:0", map[string]any{"max_tokens": 256})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if rawPath != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/converse" {
+		t.Errorf("path = %q", rawPath)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/20260102/eu-west-1/bedrock/aws4_request") {
+		t.Errorf("Authorization = %q", authorization)
+	}
+	if amzDate != "20260102T030405Z" {
+		t.Errorf("X-Amz-Date = %q", amzDate)
+	}
+	if _, ok := requestBody["system"]; !ok {
+		t.Error("expected system blocks")
+	}
+	tools := requestBody["toolConfig"].(map[string]any)["tools"].([]any)
+	spec := tools[0].(map[string]any)["toolSpec"].(map[string]any)
+	if _, ok := spec["inputSchema"].(map[string]any)["json"]; !ok {
+		t.Errorf("toolSpec = %v", spec)
+	}
+	if requestBody["inferenceConfig"].(map[string]any)["maxTokens"] != float64(256) {
+		t.Errorf("inferenceConfig = %v", requestBody["inferenceConfig"])
+	}
+
+	if resp.FinishReason != "tool_calls" || resp.Content != "checking" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["city"] != "SF" {
+		t.Errorf("ToolCalls = %+v", resp.ToolCalls)
+	}
+}
+
+func TestProviderChat_APIKeyUsesBearer(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"output":{"message":{"content":[{"text":"ok"}]}},"stopReason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("us-east-1", server.URL, "", WithAPIKey("bedrock-key"))
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if authorization != "Bearer bedrock-key" {
+		t.Errorf("Authorization = %q", authorization)
+	}
+}
+
+func TestBuildRequest_ToolResultsMergedIntoUserTurn(t *testing.T) {
+	req := buildRequest([]Message{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{ID: "a", Name: "one", Arguments: map[string]any{}},
+			{ID: "b", Name: "two", Arguments: map[string]any{}},
+		}},
+		{Role: "tool", ToolCallID: "a", Content: "1"},
+		{Role: "tool", ToolCallID: "b", Content: "2"},
+	}, nil, nil)
+
+	if len(req.Messages) != 3 {
+		t.Fatalf("len(messages) = %d, want 3", len(req.Messages))
+	}
+	results := req.Messages[2]
+	if results.Role != "user" || len(results.Content) != 2 || results.Content[1].ToolResult.ToolUseID != "b" {
+		t.Errorf("tool results = %+v", results)
+	}
+}
+
+func TestLoadCredentials_Profile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = DEF\naws_secret_access_key = defsecret\n\n" +
+		"[work]\naws_access_key_id = WORK\naws_secret_access_key = worksecret\naws_session_token = tok\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	creds, err := LoadCredentials("work")
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if creds.AccessKeyID != "WORK" || creds.SessionToken != "tok" {
+		t.Errorf("creds = %+v", creds)
+	}
+
+	creds, err = LoadCredentials("")
+	if err != nil || creds.AccessKeyID != "DEF" {
+		t.Errorf("default profile: creds = %+v, err = %v", creds, err)
+	}
+
+	if _, err := LoadCredentials("missing"); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
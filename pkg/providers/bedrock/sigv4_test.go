@@ -0,0 +1,35 @@
+package bedrock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSignV4_GetVanilla checks the signer against the "get-vanilla" case of
+// the AWS SigV4 test suite.
+func TestSignV4_GetVanilla(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+func TestCanonicalURI_DoubleEncodesModelID(t *testing.T) {
+	got := canonicalURI("/model/anthropic.claude-v2%3A1/converse")
+	if got != "/model/anthropic.claude-v2%253A1/converse" {
+		t.Errorf("canonicalURI() = %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"time"
+
+	bedrockprovider "github.com/sipeed/picoclaw/pkg/providers/bedrock"
+)
+
+// BedrockProvider calls the AWS Bedrock Runtime Converse API.
+type BedrockProvider struct {
+	delegate *bedrockprovider.Provider
+}
+
+// NewBedrockProvider creates a Bedrock provider. Requests are SigV4-signed
+// with credentials from the environment or the named shared-credentials
+// profile, unless apiKey (a Bedrock API key) is set.
+func NewBedrockProvider(region, profile, apiKey, apiBase, proxy string, requestTimeoutSeconds int) *BedrockProvider {
+	return &BedrockProvider{
+		delegate: bedrockprovider.NewProvider(
+			region,
+			apiBase,
+			proxy,
+			bedrockprovider.WithProfile(profile),
+			bedrockprovider.WithAPIKey(apiKey),
+			bedrockprovider.WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+		),
+	}
+}
+
+func (p *BedrockProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *BedrockProvider) GetDefaultModel() string {
+	return ""
+}
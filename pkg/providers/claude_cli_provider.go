@@ -0,0 +1,116 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// ClaudeCliProvider runs the Claude Code CLI ("claude -p") for each request,
+// using the user's own Claude login instead of an API key. Conversations
+// resume their CLI session between turns (see cliSessions), so only new
+// messages are sent.
+type ClaudeCliProvider struct {
+	command      string // executable, "claude" unless overridden in tests
+	workspace    string
+	allowedTools []string
+	extraArgs    []string
+	sessions     cliSessions
+}
+
+// NewClaudeCliProvider runs claude in workspace. allowedTools is passed as
+// --allowedTools (e.g. "Read", "Bash(git:*)"); extraArgs are appended to
+// every invocation.
+func NewClaudeCliProvider(workspace string, allowedTools, extraArgs []string) *ClaudeCliProvider {
+	return &ClaudeCliProvider{
+		command:      "claude",
+		workspace:    workspace,
+		allowedTools: allowedTools,
+		extraArgs:    extraArgs,
+	}
+}
+
+// claudeCliResult is the --output-format json reply. Only the fields
+// picoclaw uses are modelled.
+type claudeCliResult struct {
+	Type         string  `json:"type"`
+	Subtype      string  `json:"subtype"`
+	IsError      bool    `json:"is_error"`
+	Result       string  `json:"result"`
+	SessionID    string  `json:"session_id"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+func (p *ClaudeCliProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	sessionID, pending := p.sessions.resume(messages)
+
+	args := p.buildArgs(model, sessionID, buildCLISystemPrompt(messages, tools))
+	out, err := runCLI(ctx, p.command, args, p.workspace, buildCLIPrompt(pending))
+	if err != nil {
+		return nil, err
+	}
+
+	var result claudeCliResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse claude output: %w", err)
+	}
+	if result.IsError {
+		return nil, protocoltypes.WithKind(fmt.Errorf("claude: %s: %s", result.Subtype, result.Result),
+			protocoltypes.ClassifyError(0, result.Result))
+	}
+
+	// Anthropic reports cached tokens separately from input tokens.
+	prompt := result.Usage.InputTokens + result.Usage.CacheCreationInputTokens + result.Usage.CacheReadInputTokens
+	resp := cliResponse(result.Result, &UsageInfo{
+		PromptTokens:        prompt,
+		CompletionTokens:    result.Usage.OutputTokens,
+		TotalTokens:         prompt + result.Usage.OutputTokens,
+		CacheCreationTokens: result.Usage.CacheCreationInputTokens,
+		CacheReadTokens:     result.Usage.CacheReadInputTokens,
+		Cost:                result.TotalCostUSD,
+	})
+	p.sessions.remember(messages, resp, result.SessionID)
+	return resp, nil
+}
+
+// buildArgs assembles the claude command line. A resumed session keeps the
+// system prompt it was started with.
+func (p *ClaudeCliProvider) buildArgs(model, sessionID, systemPrompt string) []string {
+	args := []string{"-p", "--output-format", "json"}
+	if model != "" && model != "claude-code" {
+		args = append(args, "--model", model)
+	}
+	if sessionID != "" {
+		args = append(args, "--resume", sessionID)
+	} else if systemPrompt != "" {
+		args = append(args, "--system-prompt", systemPrompt)
+	}
+	if len(p.allowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(p.allowedTools, ","))
+	}
+	return append(args, p.extraArgs...)
+}
+
+func (p *ClaudeCliProvider) GetDefaultModel() string {
+	return "claude-code"
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const claudeCliReply = `{"type":"result","subtype":"success","is_error":false,` +
+	`"result":"Hello there","session_id":"sess-1","total_cost_usd":0.002,` +
+	`"usage":{"input_tokens":10,"cache_read_input_tokens":90,"output_tokens":5}}`
+
+func TestClaudeCliProvider_SessionReuse(t *testing.T) {
+	cli := newFakeCLI(t)
+	workspace := t.TempDir()
+	p := NewClaudeCliProvider(workspace, []string{"Read", "Bash(git:*)"}, []string{"--verbose"})
+	p.command = cli.path
+	ctx := context.Background()
+
+	cli.reply(t, claudeCliReply)
+	msgs := []Message{
+		{Role: "system", Content: "You are picoclaw."},
+		{Role: "user", Content: "hi"},
+	}
+	resp, err := p.Chat(ctx, msgs, nil, "claude-sonnet-4.6", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "Hello there" || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if u := resp.Usage; u.PromptTokens != 100 || u.CacheReadTokens != 90 || u.TotalTokens != 105 || u.Cost != 0.002 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	args := cli.args(t)
+	want := []string{
+		"-p", "--output-format", "json", "--model", "claude-sonnet-4.6",
+		"--system-prompt", "You are picoclaw.", "--allowedTools", "Read,Bash(git:*)", "--verbose",
+	}
+	if !slices.Equal(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if got := cli.read(t, "stdin"); got != "hi" {
+		t.Errorf("stdin = %q", got)
+	}
+	if got, _ := filepath.EvalSymlinks(cli.read(t, "pwd")); got != mustEvalSymlinks(t, workspace) {
+		t.Errorf("working dir = %q, want %q", got, workspace)
+	}
+
+	// The next turn resumes the session and sends only the new message.
+	msgs = append(msgs,
+		Message{Role: "assistant", Content: resp.Content},
+		Message{Role: "user", Content: "and now?"},
+	)
+	if _, err := p.Chat(ctx, msgs, nil, "claude-sonnet-4.6", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	args = cli.args(t)
+	if i := slices.Index(args, "--resume"); i < 0 || args[i+1] != "sess-1" {
+		t.Errorf("args = %q, want --resume sess-1", args)
+	}
+	if slices.Contains(args, "--system-prompt") {
+		t.Errorf("resumed session got a system prompt: %q", args)
+	}
+	if got := cli.read(t, "stdin"); got != "and now?" {
+		t.Errorf("stdin = %q", got)
+	}
+}
+
+func TestClaudeCliProvider_ToolCalls(t *testing.T) {
+	cli := newFakeCLI(t)
+	p := NewClaudeCliProvider(t.TempDir(), nil, nil)
+	p.command = cli.path
+
+	cli.reply(t, `{"type":"result","subtype":"success","session_id":"s",`+
+		`"result":"Let me look. {\"tool_calls\":[{\"function\":{\"name\":\"read_file\",\"arguments\":\"{\\\"path\\\":\\\"a.txt\\\"}\"}}]}"}`)
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "read_file"}}}
+	resp, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "read a.txt"}}, tools, "", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || resp.Content != "Let me look." || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "call_1" || tc.Name != "read_file" || tc.Arguments["path"] != "a.txt" {
+		t.Errorf("tool call = %+v", tc)
+	}
+
+	args := cli.args(t)
+	i := slices.Index(args, "--system-prompt")
+	if i < 0 || !strings.Contains(args[i+1], `"read_file"`) {
+		t.Errorf("tools not described in system prompt: %q", args)
+	}
+}
+
+func TestClaudeCliProvider_Errors(t *testing.T) {
+	cli := newFakeCLI(t)
+	p := NewClaudeCliProvider(t.TempDir(), nil, nil)
+	p.command = cli.path
+
+	cli.reply(t, `{"type":"result","subtype":"error_max_turns","is_error":true,"result":"too many turns"}`)
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "too many turns") {
+		t.Errorf("Chat() error = %v", err)
+	}
+
+	p.command = filepath.Join(t.TempDir(), "missing")
+	if _, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "", nil); err == nil {
+		t.Error("Chat() with missing executable succeeded")
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,72 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"time"
+
+	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
+)
+
+// ClaudeProvider speaks the native Anthropic Messages API with prompt caching.
+type ClaudeProvider struct {
+	delegate *anthropicprovider.Provider
+}
+
+func NewClaudeProvider(
+	apiKey, apiBase, proxy string,
+	requestTimeoutSeconds int,
+	opts ...anthropicprovider.Option,
+) *ClaudeProvider {
+	return &ClaudeProvider{
+		delegate: anthropicprovider.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			append([]anthropicprovider.Option{
+				anthropicprovider.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+			}, opts...)...,
+		),
+	}
+}
+
+func (p *ClaudeProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *ClaudeProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+}
+
+func (p *ClaudeProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *ClaudeProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *ClaudeProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *ClaudeProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
@@ -0,0 +1,235 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// maxCLISessions bounds how many resumable conversations a CLI provider
+// remembers; the oldest are forgotten first.
+const maxCLISessions = 256
+
+// cliSessions maps conversations to the CLI session that holds them, so a
+// follow-up turn resumes that session and sends only the new messages
+// instead of replaying the whole history into a cold process.
+//
+// A conversation is identified by a hash of its non-system messages. The
+// agent resends the full history on every turn; the longest remembered
+// prefix of it names the session to resume. A session is forgotten once
+// resumed, since it then holds more than that prefix: two conversations
+// that happen to share a prefix never resume the same session.
+type cliSessions struct {
+	mu    sync.Mutex
+	ids   map[string]string // conversation key -> session ID
+	order []string          // keys in insertion order, for eviction
+}
+
+// resume returns the session holding the longest remembered prefix of
+// messages and the messages after it. With no match, it returns "" and all
+// messages. At least one message is always left to send.
+func (s *cliSessions) resume(messages []Message) (string, []Message) {
+	keys := conversationKeys(messages)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(messages) - 1; i > 0; i-- {
+		if keys[i-1] == "" {
+			continue
+		}
+		if id, ok := s.ids[keys[i-1]]; ok {
+			delete(s.ids, keys[i-1])
+			return id, messages[i:]
+		}
+	}
+	return "", messages
+}
+
+// remember records that sessionID now holds messages followed by reply.
+func (s *cliSessions) remember(messages []Message, reply *LLMResponse, sessionID string) {
+	if sessionID == "" || reply == nil {
+		return
+	}
+	conv := append(messages[:len(messages):len(messages)], Message{
+		Role:      "assistant",
+		Content:   reply.Content,
+		ToolCalls: reply.ToolCalls,
+	})
+	keys := conversationKeys(conv)
+	key := keys[len(keys)-1]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids == nil {
+		s.ids = make(map[string]string)
+	}
+	if _, ok := s.ids[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.ids[key] = sessionID
+	for len(s.ids) > maxCLISessions && len(s.order) > 0 {
+		delete(s.ids, s.order[0])
+		s.order = s.order[1:]
+	}
+	if len(s.order) > 2*maxCLISessions {
+		// Drop keys already forgotten by resume.
+		live := s.order[:0]
+		for _, k := range s.order {
+			if _, ok := s.ids[k]; ok {
+				live = append(live, k)
+			}
+		}
+		s.order = live
+	}
+}
+
+// conversationKeys returns one key per message: keys[i] identifies
+// messages[:i+1]. System messages are left out, since the system prompt is
+// rebuilt every turn and the session keeps the one it started with; their
+// keys are empty so a conversation never ends on one.
+func conversationKeys(messages []Message) []string {
+	keys := make([]string, len(messages))
+	h := sha256.New()
+	for i, m := range messages {
+		if m.Role == "system" {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00", m.Role, m.Content, m.ToolCallID)
+		for _, tc := range m.ToolCalls {
+			fmt.Fprintf(h, "%s\x00%s\x00", tc.ID, toolCallName(tc))
+		}
+		h.Write([]byte{'\n'})
+		keys[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return keys
+}
+
+func toolCallName(tc ToolCall) string {
+	if tc.Name != "" {
+		return tc.Name
+	}
+	if tc.Function != nil {
+		return tc.Function.Name
+	}
+	return ""
+}
+
+// buildCLISystemPrompt joins the system messages and, when tools are
+// offered, describes them along with the JSON format the model must reply
+// in to call one (see extractToolCallsFromText).
+func buildCLISystemPrompt(messages []Message, tools []ToolDefinition) string {
+	var parts []string
+	for _, m := range messages {
+		if m.Role == "system" && m.Content != "" {
+			parts = append(parts, m.Content)
+		}
+	}
+	if len(tools) > 0 {
+		defs, _ := json.MarshalIndent(tools, "", "  ")
+		parts = append(parts, "## Available Tools\n\n"+
+			"To call tools, reply with a JSON object of this exact form and nothing else:\n"+
+			`{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"<tool>","arguments":"<JSON-encoded arguments>"}}]}`+
+			"\n\nTool results arrive in the next message. Tool definitions:\n\n"+string(defs))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// buildCLIPrompt renders the non-system messages as the text sent to the
+// CLI. A lone user message is sent as is.
+func buildCLIPrompt(messages []Message) string {
+	var nonSystem []Message
+	for _, m := range messages {
+		if m.Role != "system" {
+			nonSystem = append(nonSystem, m)
+		}
+	}
+	if len(nonSystem) == 1 && nonSystem[0].Role == "user" {
+		return nonSystem[0].Content
+	}
+
+	var sb strings.Builder
+	for _, m := range nonSystem {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		switch m.Role {
+		case "user":
+			sb.WriteString("User: " + m.Content)
+		case "assistant":
+			sb.WriteString("Assistant: " + m.Content)
+			for _, tc := range m.ToolCalls {
+				args := "{}"
+				if tc.Function != nil && tc.Function.Arguments != "" {
+					args = tc.Function.Arguments
+				}
+				fmt.Fprintf(&sb, "\n[Called tool %s (%s) with %s]", toolCallName(tc), tc.ID, args)
+			}
+		case "tool":
+			fmt.Fprintf(&sb, "[Tool result for %s]: %s", m.ToolCallID, m.Content)
+		default:
+			sb.WriteString(m.Content)
+		}
+	}
+	return sb.String()
+}
+
+// cliResponse turns the CLI's final text into a response, extracting any
+// tool calls the model wrote into it.
+func cliResponse(text string, usage *UsageInfo) *LLMResponse {
+	resp := &LLMResponse{Content: strings.TrimSpace(text), FinishReason: "stop", Usage: usage}
+	if calls := extractToolCallsFromText(text); len(calls) > 0 {
+		for i := range calls {
+			if calls[i].ID == "" {
+				calls[i].ID = fmt.Sprintf("call_%d", i+1)
+			}
+			if calls[i].Type == "" {
+				calls[i].Type = "function"
+			}
+			if calls[i].Arguments == nil {
+				calls[i].Arguments = map[string]any{}
+			}
+		}
+		resp.ToolCalls = calls
+		resp.Content = stripToolCallsFromText(text)
+		resp.FinishReason = "tool_calls"
+	}
+	return resp
+}
+
+// runCLI runs command in dir with prompt on stdin and returns its stdout.
+// Failures include the CLI's stderr, which is where it explains itself.
+func runCLI(ctx context.Context, command string, args []string, dir, prompt string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(prompt)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, protocoltypes.WithKind(fmt.Errorf("%s: %w: %s", command, err, msg), protocoltypes.ClassifyError(0, msg))
+		}
+		if msg := strings.TrimSpace(stdout.String()); msg != "" {
+			return nil, protocoltypes.WithKind(fmt.Errorf("%s: %w: %s", command, err, msg), protocoltypes.ClassifyError(0, msg))
+		}
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCLI is a stand-in executable that records its arguments, stdin and
+// working directory and prints the contents of its "out" file.
+type fakeCLI struct {
+	path string
+	dir  string
+}
+
+func newFakeCLI(t *testing.T) *fakeCLI {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cli")
+	script := "#!/bin/sh\n" +
+		"printf '%s\\0' \"$@\" > " + dir + "/args\n" +
+		"cat > " + dir + "/stdin\n" +
+		"pwd > " + dir + "/pwd\n" +
+		"cat " + dir + "/out\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return &fakeCLI{path: path, dir: dir}
+}
+
+func (f *fakeCLI) reply(t *testing.T, out string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(f.dir, "out"), []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fakeCLI) read(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(f.dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (f *fakeCLI) args(t *testing.T) []string {
+	// NUL-separated, since arguments such as the system prompt span lines.
+	return strings.Split(strings.TrimSuffix(f.read(t, "args"), "\x00"), "\x00")
+}
+
+func TestCLISessions_ResumeLongestPrefixOnce(t *testing.T) {
+	var s cliSessions
+	first := []Message{
+		{Role: "system", Content: "v1"},
+		{Role: "user", Content: "hi"},
+	}
+	s.remember(first, &LLMResponse{Content: "hello"}, "sess-1")
+
+	// The system prompt may change between turns without losing the session.
+	next := []Message{
+		{Role: "system", Content: "v2"},
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+		{Role: "user", Content: "again"},
+	}
+	id, pending := s.resume(next)
+	if id != "sess-1" || len(pending) != 1 || pending[0].Content != "again" {
+		t.Fatalf("resume() = %q, %+v", id, pending)
+	}
+
+	// Once resumed the session has moved on: a conversation sharing the
+	// same prefix starts a new one.
+	if id, pending := s.resume(next); id != "" || len(pending) != len(next) {
+		t.Errorf("second resume() = %q, %d messages", id, len(pending))
+	}
+}
+
+func TestCLISessions_DivergedHistoryStartsCold(t *testing.T) {
+	var s cliSessions
+	s.remember([]Message{{Role: "user", Content: "hi"}}, &LLMResponse{Content: "hello"}, "sess-1")
+
+	id, pending := s.resume([]Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "edited"},
+		{Role: "user", Content: "again"},
+	})
+	if id != "" || len(pending) != 3 {
+		t.Errorf("resume() = %q, %d messages", id, len(pending))
+	}
+}
+
+func TestCLISessions_Eviction(t *testing.T) {
+	var s cliSessions
+	for i := 0; i < maxCLISessions+10; i++ {
+		msgs := []Message{{Role: "user", Content: strings.Repeat("x", i+1)}}
+		s.remember(msgs, &LLMResponse{Content: "ok"}, "sess")
+	}
+	if len(s.ids) != maxCLISessions {
+		t.Errorf("remembered %d sessions, want %d", len(s.ids), maxCLISessions)
+	}
+}
+
+func TestBuildCLIPrompt(t *testing.T) {
+	if got := buildCLIPrompt([]Message{{Role: "system", Content: "s"}, {Role: "user", Content: "hi"}}); got != "hi" {
+		t.Errorf("single user message = %q", got)
+	}
+
+	got := buildCLIPrompt([]Message{
+		{Role: "assistant", ToolCalls: []ToolCall{{
+			ID:       "call_1",
+			Function: &FunctionCall{Name: "read_file", Arguments: `{"path":"a"}`},
+		}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "contents"},
+	})
+	want := "Assistant: \n[Called tool read_file (call_1) with {\"path\":\"a\"}]\n\n[Tool result for call_1]: contents"
+	if got != want {
+		t.Errorf("buildCLIPrompt() = %q, want %q", got, want)
+	}
+}
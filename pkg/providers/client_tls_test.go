@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// writeClientCert writes a self-signed client certificate and key as PEM
+// files and returns their paths and the parsed certificate.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "picoclaw-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateProviderFromConfig_MutualTLSAndExtraHeaders(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := writeClientCert(t, dir)
+
+	var orgID string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID = r.Header.Get("X-Org-Id")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	// The gateway's certificate is trusted through tls_ca.
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	cfg := &config.ModelConfig{
+		ModelName:    "gateway",
+		Model:        "vllm/test-model",
+		APIBase:      server.URL,
+		ExtraHeaders: map[string]string{"X-Org-Id": "org-42"},
+		TLSCert:      certFile,
+		TLSKey:       keyFile,
+		TLSCA:        caFile,
+	}
+	provider, _, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	resp, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok" || orgID != "org-42" {
+		t.Errorf("content = %q, X-Org-Id = %q", resp.Content, orgID)
+	}
+
+	// Without the client certificate the gateway rejects the handshake.
+	cfg.TLSCert, cfg.TLSKey = "", ""
+	provider, _, err = CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil); err == nil {
+		t.Error("Chat() without client certificate succeeded")
+	}
+}
+
+func TestClientTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _, _ := writeClientCert(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.ModelConfig
+		want string
+	}{
+		{"cert without key", config.ModelConfig{TLSCert: certFile}, "must be set together"},
+		{"missing ca", config.ModelConfig{TLSCA: filepath.Join(dir, "missing.pem")}, "read tls_ca"},
+		{"ca without certificates", config.ModelConfig{TLSCA: notPEM}, "no PEM certificates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := clientTLSConfig(&tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("clientTLSConfig() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if tlsCfg, err := clientTLSConfig(&config.ModelConfig{}); tlsCfg != nil || err != nil {
+		t.Errorf("clientTLSConfig() with nothing set = %v, %v", tlsCfg, err)
+	}
+}
@@ -0,0 +1,148 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// CodexCliProvider runs the OpenAI Codex CLI ("codex exec") for each
+// request, using the user's own Codex login instead of an API key.
+// Conversations resume their Codex thread between turns (see cliSessions),
+// so only new messages are sent.
+type CodexCliProvider struct {
+	command   string // executable, "codex" unless overridden in tests
+	workspace string
+	extraArgs []string
+	sessions  cliSessions
+}
+
+// NewCodexCliProvider runs codex in workspace. extraArgs are passed to
+// "codex exec" on every invocation, e.g. ["--sandbox", "read-only"] to
+// limit what Codex's own tools may do.
+func NewCodexCliProvider(workspace string, extraArgs []string) *CodexCliProvider {
+	return &CodexCliProvider{
+		command:   "codex",
+		workspace: workspace,
+		extraArgs: extraArgs,
+	}
+}
+
+// codexEvent is one line of "codex exec --json" output. Only the events and
+// fields picoclaw uses are modelled.
+type codexEvent struct {
+	Type     string `json:"type"`
+	ThreadID string `json:"thread_id"`
+	Message  string `json:"message"`
+	Item     struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"item"`
+	Usage struct {
+		InputTokens       int `json:"input_tokens"`
+		CachedInputTokens int `json:"cached_input_tokens"`
+		OutputTokens      int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *CodexCliProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	sessionID, pending := p.sessions.resume(messages)
+
+	// Codex has no system prompt flag: a new thread gets it ahead of the
+	// first prompt, a resumed thread already has it.
+	prompt := buildCLIPrompt(pending)
+	if sessionID == "" {
+		if system := buildCLISystemPrompt(messages, tools); system != "" {
+			prompt = system + "\n\n" + prompt
+		}
+	}
+
+	out, err := runCLI(ctx, p.command, p.buildArgs(model, sessionID), p.workspace, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		threadID string
+		texts    []string
+		usage    *UsageInfo
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var ev codexEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			continue // progress output that isn't an event
+		}
+		switch ev.Type {
+		case "thread.started":
+			threadID = ev.ThreadID
+		case "item.completed":
+			if ev.Item.Type == "agent_message" && ev.Item.Text != "" {
+				texts = append(texts, ev.Item.Text)
+			}
+		case "turn.completed":
+			usage = &UsageInfo{
+				PromptTokens:     ev.Usage.InputTokens,
+				CompletionTokens: ev.Usage.OutputTokens,
+				TotalTokens:      ev.Usage.InputTokens + ev.Usage.OutputTokens,
+				CacheReadTokens:  ev.Usage.CachedInputTokens,
+			}
+		case "turn.failed":
+			return nil, protocoltypes.WithKind(fmt.Errorf("codex: %s", ev.Error.Message), protocoltypes.ClassifyError(0, ev.Error.Message))
+		case "error":
+			return nil, protocoltypes.WithKind(fmt.Errorf("codex: %s", ev.Message), protocoltypes.ClassifyError(0, ev.Message))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read codex output: %w", err)
+	}
+	if len(texts) == 0 && usage == nil {
+		return nil, errors.New("codex: no response in output")
+	}
+
+	// A resumed thread keeps its ID.
+	if threadID == "" {
+		threadID = sessionID
+	}
+	resp := cliResponse(strings.Join(texts, "\n\n"), usage)
+	p.sessions.remember(messages, resp, threadID)
+	return resp, nil
+}
+
+// buildArgs assembles the codex command line; the prompt is read from stdin.
+func (p *CodexCliProvider) buildArgs(model, sessionID string) []string {
+	args := []string{"exec", "--json", "--skip-git-repo-check"}
+	if model != "" && model != "codex" {
+		args = append(args, "--model", model)
+	}
+	args = append(args, p.extraArgs...)
+	if sessionID != "" {
+		args = append(args, "resume", sessionID)
+	}
+	return append(args, "-")
+}
+
+func (p *CodexCliProvider) GetDefaultModel() string {
+	return "codex"
+}
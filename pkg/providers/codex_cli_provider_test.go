@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const codexCliReply = `{"type":"thread.started","thread_id":"thread-1"}
+{"type":"turn.started"}
+{"type":"item.completed","item":{"id":"item_0","type":"reasoning","text":"thinking"}}
+{"type":"item.completed","item":{"id":"item_1","type":"agent_message","text":"Hi from Codex"}}
+{"type":"turn.completed","usage":{"input_tokens":120,"cached_input_tokens":100,"output_tokens":8}}
+`
+
+func TestCodexCliProvider_SessionReuse(t *testing.T) {
+	cli := newFakeCLI(t)
+	p := NewCodexCliProvider(t.TempDir(), []string{"--sandbox", "read-only"})
+	p.command = cli.path
+	ctx := context.Background()
+
+	cli.reply(t, codexCliReply)
+	msgs := []Message{
+		{Role: "system", Content: "You are picoclaw."},
+		{Role: "user", Content: "hi"},
+	}
+	resp, err := p.Chat(ctx, msgs, nil, "codex", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "Hi from Codex" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if u := resp.Usage; u.PromptTokens != 120 || u.CacheReadTokens != 100 || u.TotalTokens != 128 {
+		t.Errorf("usage = %+v", u)
+	}
+	want := []string{"exec", "--json", "--skip-git-repo-check", "--sandbox", "read-only", "-"}
+	if args := cli.args(t); !slices.Equal(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if got := cli.read(t, "stdin"); got != "You are picoclaw.\n\nhi" {
+		t.Errorf("stdin = %q", got)
+	}
+
+	// Resumed threads don't announce themselves again.
+	cli.reply(t, `{"type":"item.completed","item":{"type":"agent_message","text":"Sure"}}`+"\n"+
+		`{"type":"turn.completed","usage":{"input_tokens":1,"output_tokens":1}}`)
+	msgs = append(msgs,
+		Message{Role: "assistant", Content: resp.Content},
+		Message{Role: "user", Content: "more"},
+	)
+	resp, err = p.Chat(ctx, msgs, nil, "gpt-5-codex", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	want = []string{
+		"exec", "--json", "--skip-git-repo-check", "--model", "gpt-5-codex",
+		"--sandbox", "read-only", "resume", "thread-1", "-",
+	}
+	if args := cli.args(t); !slices.Equal(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if got := cli.read(t, "stdin"); got != "more" {
+		t.Errorf("stdin = %q", got)
+	}
+
+	// The thread ID carries over to the turn after.
+	msgs = append(msgs,
+		Message{Role: "assistant", Content: resp.Content},
+		Message{Role: "user", Content: "again"},
+	)
+	if _, err := p.Chat(ctx, msgs, nil, "codex", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if args := cli.args(t); !slices.Contains(args, "thread-1") {
+		t.Errorf("args = %q, want resume of thread-1", args)
+	}
+}
+
+func TestCodexCliProvider_TurnFailed(t *testing.T) {
+	cli := newFakeCLI(t)
+	p := NewCodexCliProvider(t.TempDir(), nil)
+	p.command = cli.path
+
+	cli.reply(t, `{"type":"thread.started","thread_id":"t"}`+"\n"+
+		`{"type":"turn.failed","error":{"message":"usage limit reached"}}`)
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "codex", nil)
+	if err == nil || !strings.Contains(err.Error(), "usage limit reached") {
+		t.Errorf("Chat() error = %v", err)
+	}
+}
@@ -65,6 +65,22 @@ func (ct *CooldownTracker) MarkFailure(provider string, reason FailoverReason) {
 	}
 }
 
+// ExtendCooldown ensures the provider stays in cooldown for at least d,
+// e.g. to honor a Retry-After hint longer than the standard backoff.
+func (ct *CooldownTracker) ExtendCooldown(provider string, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	entry := ct.getOrCreate(provider)
+	if end := ct.nowFunc().Add(d); end.After(entry.CooldownEnd) {
+		entry.CooldownEnd = end
+	}
+}
+
 // MarkSuccess resets all counters and cooldowns for a provider.
 func (ct *CooldownTracker) MarkSuccess(provider string) {
 	ct.mu.Lock()
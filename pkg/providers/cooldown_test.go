@@ -267,3 +267,28 @@ func TestCooldown_MultipleProviders(t *testing.T) {
 		t.Error("groq should be available")
 	}
 }
+
+func TestCooldown_ExtendCooldownHonorsLongerHint(t *testing.T) {
+	now := time.Now()
+	ct, current := newTestTracker(now)
+
+	ct.MarkFailure("groq", FailoverRateLimit) // 1 min standard cooldown
+	ct.ExtendCooldown("groq", 10*time.Minute)
+
+	*current = now.Add(2 * time.Minute)
+	if ct.IsAvailable("groq") {
+		t.Error("should still be in cooldown from the retry-after hint")
+	}
+
+	// A shorter hint must not shorten the cooldown.
+	ct.ExtendCooldown("groq", time.Second)
+	*current = now.Add(9 * time.Minute)
+	if ct.IsAvailable("groq") {
+		t.Error("shorter hint should not shorten the cooldown")
+	}
+
+	*current = now.Add(11 * time.Minute)
+	if !ct.IsAvailable("groq") {
+		t.Error("should be available after the hinted cooldown")
+	}
+}
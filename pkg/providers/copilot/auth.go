@@ -0,0 +1,285 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+const (
+	// clientID is the OAuth app GitHub's own Copilot editor plugins log in
+	// with; Copilot tokens are only issued to OAuth tokens from it.
+	clientID = "Iv1.b507a08c87ecfe98"
+
+	defaultGitHubURL = "https://github.com"
+	defaultAPIURL    = "https://api.github.com"
+
+	// refreshMargin renews the Copilot token this long before it expires, so
+	// a request never starts with a token about to lapse.
+	refreshMargin = 60 * time.Second
+)
+
+// ErrNotLoggedIn means there is no GitHub token: run "picoclaw auth
+// github-copilot" or set api_key.
+var ErrNotLoggedIn = errors.New(`not logged in to GitHub Copilot; run "picoclaw auth github-copilot"`)
+
+// DeviceCode is what the user needs to authorize picoclaw: open
+// VerificationURI and enter UserCode.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// Auth holds the GitHub OAuth token and exchanges it for the short-lived
+// Copilot API token, refreshing that whenever it is about to expire.
+// Thread-safe.
+type Auth struct {
+	store       *TokenStore
+	githubToken string // fixed token from api_key; not persisted
+	httpClient  *http.Client
+	githubURL   string
+	apiURL      string
+	nowFunc     func() time.Time    // for testing
+	sleepFunc   func(time.Duration) // for testing
+
+	mu    sync.Mutex
+	creds *Credentials
+}
+
+// NewAuth uses githubToken when set (e.g. the output of "gh auth token") and
+// otherwise the token saved in store by Login.
+func NewAuth(store *TokenStore, githubToken string, httpClient *http.Client) *Auth {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Auth{
+		store:       store,
+		githubToken: githubToken,
+		httpClient:  httpClient,
+		githubURL:   defaultGitHubURL,
+		apiURL:      defaultAPIURL,
+		nowFunc:     time.Now,
+		sleepFunc:   time.Sleep,
+	}
+}
+
+// Login runs GitHub's device flow: it requests a device code, passes it to
+// prompt for display, then waits until the user authorizes it and saves the
+// resulting token.
+func (a *Auth) Login(ctx context.Context, prompt func(DeviceCode)) error {
+	dc, err := a.requestDeviceCode(ctx)
+	if err != nil {
+		return err
+	}
+	prompt(*dc)
+
+	token, err := a.pollAccessToken(ctx, dc)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.creds = &Credentials{GitHubToken: token}
+	if err := a.refreshLocked(ctx); err != nil {
+		return fmt.Errorf("logged in to GitHub, but Copilot is not available for this account: %w", err)
+	}
+	return nil
+}
+
+// Token returns a valid Copilot API token, exchanging the GitHub token for a
+// new one when the cached token is missing or about to expire.
+func (a *Auth) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.creds == nil {
+		creds, err := a.loadLocked()
+		if err != nil {
+			return "", err
+		}
+		a.creds = creds
+	}
+	if a.creds.CopilotToken == "" || a.nowFunc().Add(refreshMargin).After(a.creds.ExpiresAt) {
+		if err := a.refreshLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return a.creds.CopilotToken, nil
+}
+
+// Invalidate drops the cached Copilot token, so the next Token call fetches
+// a new one. Used when the API rejects a token before its expiry.
+func (a *Auth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.creds != nil {
+		a.creds.CopilotToken = ""
+	}
+}
+
+func (a *Auth) loadLocked() (*Credentials, error) {
+	creds, err := a.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if a.githubToken != "" {
+		if creds == nil || creds.GitHubToken != a.githubToken {
+			creds = &Credentials{}
+		}
+		creds.GitHubToken = a.githubToken
+	}
+	if creds == nil || creds.GitHubToken == "" {
+		return nil, ErrNotLoggedIn
+	}
+	return creds, nil
+}
+
+// refreshLocked exchanges the GitHub token for a Copilot token and saves
+// both. A configured api_key is never written to disk.
+func (a *Auth) refreshLocked(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.apiURL+"/copilot_internal/v2/token", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+a.creds.GitHubToken)
+	req.Header.Set("Accept", "application/json")
+	setEditorHeaders(req)
+
+	var out struct {
+		Token     string `json:"token"`
+		ExpiresAt int64  `json:"expires_at"`
+	}
+	if err := a.do(req, &out); err != nil {
+		return fmt.Errorf("copilot token: %w", err)
+	}
+	if out.Token == "" {
+		return fmt.Errorf("copilot token: empty token in response")
+	}
+	a.creds.CopilotToken = out.Token
+	a.creds.ExpiresAt = time.Unix(out.ExpiresAt, 0)
+
+	if a.githubToken != "" {
+		return nil
+	}
+	return a.store.Save(a.creds)
+}
+
+func (a *Auth) requestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{"client_id": {clientID}, "scope": {"read:user"}}
+	req, err := a.newFormRequest(ctx, a.githubURL+"/login/device/code", form)
+	if err != nil {
+		return nil, err
+	}
+	var dc DeviceCode
+	if err := a.do(req, &dc); err != nil {
+		return nil, fmt.Errorf("device code: %w", err)
+	}
+	if dc.DeviceCode == "" || dc.UserCode == "" {
+		return nil, fmt.Errorf("device code: incomplete response")
+	}
+	return &dc, nil
+}
+
+// pollAccessToken polls until the user authorizes the device code, honoring
+// the server's interval and slow_down requests.
+func (a *Auth) pollAccessToken(ctx context.Context, dc *DeviceCode) (string, error) {
+	interval := time.Duration(max(dc.Interval, 1)) * time.Second
+	deadline := a.nowFunc().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	form := url.Values{
+		"client_id":   {clientID},
+		"device_code": {dc.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+
+	for {
+		if dc.ExpiresIn > 0 && a.nowFunc().After(deadline) {
+			return "", fmt.Errorf("device code expired before it was authorized")
+		}
+		a.sleepFunc(interval)
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		req, err := a.newFormRequest(ctx, a.githubURL+"/login/oauth/access_token", form)
+		if err != nil {
+			return "", err
+		}
+		var out struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Interval    int    `json:"interval"`
+		}
+		if err := a.do(req, &out); err != nil {
+			return "", fmt.Errorf("access token: %w", err)
+		}
+
+		switch out.Error {
+		case "":
+			if out.AccessToken == "" {
+				return "", fmt.Errorf("access token: empty token in response")
+			}
+			return out.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+			if out.Interval > 0 {
+				interval = time.Duration(out.Interval) * time.Second
+			}
+		default:
+			if out.Description != "" {
+				return "", fmt.Errorf("access token: %s: %s", out.Error, out.Description)
+			}
+			return "", fmt.Errorf("access token: %s", out.Error)
+		}
+	}
+}
+
+func (a *Auth) newFormRequest(ctx context.Context, endpoint string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends req and decodes a 200 JSON response into out.
+func (a *Auth) do(req *http.Request, out any) error {
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
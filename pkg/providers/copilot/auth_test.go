@@ -0,0 +1,166 @@
+package copilot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestAuth(t *testing.T, server *httptest.Server, githubToken string) (*Auth, *TokenStore) {
+	t.Helper()
+	store := NewTokenStore(filepath.Join(t.TempDir(), "auth", "github-copilot.json"))
+	a := NewAuth(store, githubToken, server.Client())
+	a.githubURL = server.URL
+	a.apiURL = server.URL
+	a.sleepFunc = func(time.Duration) {}
+	return a, store
+}
+
+func TestAuthLogin_DeviceFlow(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login/device/code":
+			r.ParseForm()
+			if r.Form.Get("client_id") != clientID {
+				t.Errorf("client_id = %q", r.Form.Get("client_id"))
+			}
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-1234",` +
+				`"verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`))
+		case "/login/oauth/access_token":
+			r.ParseForm()
+			if r.Form.Get("device_code") != "dev" {
+				t.Errorf("device_code = %q", r.Form.Get("device_code"))
+			}
+			switch polls.Add(1) {
+			case 1:
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+			case 2:
+				w.Write([]byte(`{"error":"slow_down","interval":10}`))
+			default:
+				w.Write([]byte(`{"access_token":"gho_user","token_type":"bearer"}`))
+			}
+		case "/copilot_internal/v2/token":
+			if got := r.Header.Get("Authorization"); got != "token gho_user" {
+				t.Errorf("Authorization = %q", got)
+			}
+			w.Write([]byte(`{"token":"tid=copilot","expires_at":4102444800}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	a, store := newTestAuth(t, server, "")
+	var slept []time.Duration
+	a.sleepFunc = func(d time.Duration) { slept = append(slept, d) }
+
+	var shown DeviceCode
+	if err := a.Login(t.Context(), func(dc DeviceCode) { shown = dc }); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if shown.UserCode != "ABCD-1234" {
+		t.Errorf("prompted with %+v", shown)
+	}
+	if len(slept) != 3 || slept[0] != 5*time.Second || slept[2] != 10*time.Second {
+		t.Errorf("poll intervals = %v", slept)
+	}
+
+	creds, err := store.Load()
+	if err != nil || creds == nil {
+		t.Fatalf("Load() = %v, %v", creds, err)
+	}
+	if creds.GitHubToken != "gho_user" || creds.CopilotToken != "tid=copilot" {
+		t.Errorf("saved credentials = %+v", creds)
+	}
+	info, _ := os.Stat(store.Path())
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("credentials file mode = %v, want 0600", perm)
+	}
+}
+
+func TestAuthLogin_Denied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login/device/code" {
+			w.Write([]byte(`{"device_code":"dev","user_code":"X","verification_uri":"u","interval":1}`))
+			return
+		}
+		w.Write([]byte(`{"error":"access_denied","error_description":"The user has denied your application access."}`))
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuth(t, server, "")
+	err := a.Login(t.Context(), func(DeviceCode) {})
+	if err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Fatalf("Login() error = %v", err)
+	}
+}
+
+func TestAuthToken_RefreshesBeforeExpiry(t *testing.T) {
+	var exchanges atomic.Int32
+	now := time.Unix(1_700_000_000, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := exchanges.Add(1)
+		// Each token is valid for 30 minutes from the fake clock.
+		fmt.Fprintf(w, `{"token":"copilot-%d","expires_at":%d}`, n, now.Add(30*time.Minute).Unix())
+	}))
+	defer server.Close()
+
+	a, store := newTestAuth(t, server, "")
+	a.nowFunc = func() time.Time { return now }
+	if err := store.Save(&Credentials{GitHubToken: "gho_saved"}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := a.Token(t.Context())
+	if err != nil || first != "copilot-1" {
+		t.Fatalf("Token() = %q, %v", first, err)
+	}
+	now = now.Add(10 * time.Minute)
+	if again, _ := a.Token(t.Context()); again != "copilot-1" {
+		t.Errorf("valid token refreshed: %q", again)
+	}
+	now = now.Add(19*time.Minute + 30*time.Second)
+	if renewed, _ := a.Token(t.Context()); renewed != "copilot-2" {
+		t.Errorf("token about to expire not refreshed: %q", renewed)
+	}
+
+	a.Invalidate()
+	if forced, _ := a.Token(t.Context()); forced != "copilot-3" {
+		t.Errorf("invalidated token reused: %q", forced)
+	}
+}
+
+func TestAuthToken_ConfiguredTokenIsNotSaved(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token ghp_configured" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(`{"token":"copilot","expires_at":4102444800}`))
+	}))
+	defer server.Close()
+
+	a, store := newTestAuth(t, server, "ghp_configured")
+	if _, err := a.Token(t.Context()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Errorf("configured token written to disk (stat error = %v)", err)
+	}
+}
+
+func TestAuthToken_NotLoggedIn(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	a, _ := newTestAuth(t, server, "")
+	if _, err := a.Token(t.Context()); err != ErrNotLoggedIn {
+		t.Errorf("Token() error = %v, want ErrNotLoggedIn", err)
+	}
+}
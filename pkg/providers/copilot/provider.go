@@ -0,0 +1,112 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package copilot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	LLMResponse    = protocoltypes.LLMResponse
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+const DefaultAPIBase = "https://api.githubcopilot.com"
+
+// Copilot only serves requests from recognized editor integrations.
+var editorHeaders = map[string]string{
+	"Editor-Version":         "vscode/1.99.0",
+	"Editor-Plugin-Version":  "copilot-chat/0.26.0",
+	"Copilot-Integration-Id": "vscode-chat",
+	"User-Agent":             "GitHubCopilotChat/0.26.0",
+}
+
+func setEditorHeaders(req *http.Request) {
+	for k, v := range editorHeaders {
+		req.Header.Set(k, v)
+	}
+}
+
+// Provider is the OpenAI-compatible Copilot chat API, authenticated with a
+// Copilot token from Auth. A request rejected with 401 (the token was
+// revoked or expired early) is retried once with a fresh token.
+type Provider struct {
+	auth     *Auth
+	delegate *openai_compat.Provider
+}
+
+func NewProvider(auth *Auth, apiBase, proxy string, opts ...openai_compat.Option) *Provider {
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+	opts = append([]openai_compat.Option{
+		openai_compat.WithTokenSource(auth.Token),
+		openai_compat.WithExtraHeaders(editorHeaders),
+	}, opts...)
+	return &Provider{
+		auth:     auth,
+		delegate: openai_compat.NewProvider("", apiBase, proxy, opts...),
+	}
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.retryUnauthorized(func() (*LLMResponse, error) {
+		return p.delegate.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+func (p *Provider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(string),
+) (*LLMResponse, error) {
+	// A 401 arrives before any delta, so retrying can't repeat output.
+	return p.retryUnauthorized(func() (*LLMResponse, error) {
+		return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+	})
+}
+
+// Ping checks that the account is logged in and entitled to Copilot by
+// fetching a Copilot token.
+func (p *Provider) Ping(ctx context.Context) error {
+	_, err := p.auth.Token(ctx)
+	return err
+}
+
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	_, err := p.retryUnauthorized(func() (*LLMResponse, error) {
+		var err error
+		models, err = p.delegate.ListModels(ctx)
+		return nil, err
+	})
+	return models, err
+}
+
+func (p *Provider) retryUnauthorized(call func() (*LLMResponse, error)) (*LLMResponse, error) {
+	resp, err := call()
+	var httpErr *openai_compat.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized {
+		p.auth.Invalidate()
+		return call()
+	}
+	return resp, err
+}
@@ -0,0 +1,57 @@
+package copilot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProviderChat_RetriesWithFreshTokenOn401(t *testing.T) {
+	var exchanges, chats atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/copilot_internal/v2/token":
+			if exchanges.Add(1) == 1 {
+				w.Write([]byte(`{"token":"revoked","expires_at":4102444800}`))
+			} else {
+				w.Write([]byte(`{"token":"fresh","expires_at":4102444800}`))
+			}
+		case "/chat/completions":
+			chats.Add(1)
+			if r.Header.Get("Copilot-Integration-Id") == "" || r.Header.Get("Editor-Version") == "" {
+				t.Error("editor headers missing")
+			}
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`unauthorized: token expired`))
+				return
+			}
+			w.Write([]byte(`{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuth(t, server, "ghp_token")
+	p := NewProvider(a, server.URL, "")
+
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hello"}}, nil, "gpt-4.1", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hi" || chats.Load() != 2 || exchanges.Load() != 2 {
+		t.Errorf("content = %q, chats = %d, exchanges = %d", resp.Content, chats.Load(), exchanges.Load())
+	}
+}
+
+func TestProviderPing_NotLoggedIn(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	a, _ := newTestAuth(t, server, "")
+	if err := NewProvider(a, server.URL, "").Ping(t.Context()); err != ErrNotLoggedIn {
+		t.Errorf("Ping() error = %v, want ErrNotLoggedIn", err)
+	}
+}
@@ -0,0 +1,86 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package copilot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Credentials are the saved GitHub OAuth token and the most recent Copilot
+// token issued for it.
+type Credentials struct {
+	GitHubToken  string    `json:"github_token"`
+	CopilotToken string    `json:"copilot_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// TokenStore keeps Credentials in a JSON file readable only by the owner.
+type TokenStore struct {
+	path string
+}
+
+func NewTokenStore(path string) *TokenStore {
+	return &TokenStore{path: path}
+}
+
+// DefaultTokenPath is ~/.picoclaw/auth/github-copilot.json.
+func DefaultTokenPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".picoclaw", "auth", "github-copilot.json")
+}
+
+func (s *TokenStore) Path() string {
+	return s.path
+}
+
+// Load returns the saved credentials, or nil when there are none.
+func (s *TokenStore) Load() (*Credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("parse credentials %s: %w", s.path, err)
+	}
+	return &creds, nil
+}
+
+// Save writes creds atomically.
+func (s *TokenStore) Save(creds *Credentials) error {
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode credentials: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the saved credentials. Deleting nothing is not an error.
+func (s *TokenStore) Delete() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete credentials: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	geminiprovider "github.com/sipeed/picoclaw/pkg/providers/gemini"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+const (
+	defaultEmbeddingBatchSize = 64
+
+	// maxGeminiEmbeddingBatch is the batchEmbedContents limit.
+	maxGeminiEmbeddingBatch = 100
+)
+
+// EmbeddingsProvider turns text into vectors for semantic retrieval. The
+// model is fixed when the provider is created (see
+// CreateEmbeddingsProviderFromConfig).
+type EmbeddingsProvider interface {
+	// Embed returns one vector per input, in input order.
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+	// Dimensions is the configured vector size, or 0 for the model default.
+	Dimensions() int
+}
+
+// embedFunc embeds a single batch of inputs.
+type embedFunc func(ctx context.Context, inputs []string, model string, dimensions int) ([][]float32, error)
+
+// batchingEmbedder splits inputs into batches of at most batchSize and
+// concatenates the results.
+type batchingEmbedder struct {
+	embed      embedFunc
+	model      string
+	dimensions int
+	batchSize  int
+}
+
+func newBatchingEmbedder(embed embedFunc, model string, dimensions, batchSize int) *batchingEmbedder {
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatchSize
+	}
+	return &batchingEmbedder{
+		embed:      embed,
+		model:      model,
+		dimensions: dimensions,
+		batchSize:  batchSize,
+	}
+}
+
+func (e *batchingEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(inputs))
+	for start := 0; start < len(inputs); start += e.batchSize {
+		end := min(start+e.batchSize, len(inputs))
+		batch, err := e.embed(ctx, inputs[start:end], e.model, e.dimensions)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("got %d embeddings for %d inputs", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *batchingEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// CreateEmbeddingsProviderFromConfig creates an embeddings provider for the
+// model_list entry cfg.
+// Supported protocols: openai, ollama, gemini
+func CreateEmbeddingsProviderFromConfig(cfg *config.ModelConfig) (EmbeddingsProvider, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	protocol, modelID := ExtractProtocol(cfg.Model)
+	timeout := time.Duration(cfg.RequestTimeout) * time.Second
+
+	switch protocol {
+	case "openai", "ollama":
+		if protocol == "openai" && cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, fmt.Errorf("api_key or api_base is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		if apiBase == "" {
+			apiBase = "https://api.openai.com/v1"
+		}
+		p := openai_compat.NewProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			openai_compat.WithRequestTimeout(timeout),
+			openai_compat.WithMaxAttempts(cfg.MaxAttempts),
+		)
+		return newBatchingEmbedder(p.Embed, modelID, cfg.Dimensions, cfg.EmbeddingBatchSize), nil
+
+	case "gemini":
+		if cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, fmt.Errorf("api_key or api_base is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		batchSize := cfg.EmbeddingBatchSize
+		if batchSize <= 0 || batchSize > maxGeminiEmbeddingBatch {
+			batchSize = maxGeminiEmbeddingBatch
+		}
+		p := geminiprovider.NewProvider(cfg.APIKey, apiBase, cfg.Proxy, geminiprovider.WithRequestTimeout(timeout))
+		return newBatchingEmbedder(p.Embed, modelID, cfg.Dimensions, batchSize), nil
+
+	default:
+		return nil, fmt.Errorf("protocol %q does not support embeddings (model %q)", protocol, cfg.Model)
+	}
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestBatchingEmbedder_SplitsInputs(t *testing.T) {
+	var batches [][]string
+	embed := func(ctx context.Context, inputs []string, model string, dimensions int) ([][]float32, error) {
+		batches = append(batches, inputs)
+		out := make([][]float32, len(inputs))
+		for i := range inputs {
+			out[i] = []float32{float32(len(batches))}
+		}
+		return out, nil
+	}
+
+	e := newBatchingEmbedder(embed, "m", 8, 2)
+	vectors, err := e.Embed(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Errorf("batches = %v", batches)
+	}
+	if len(vectors) != 5 || vectors[4][0] != 3 {
+		t.Errorf("vectors = %v", vectors)
+	}
+	if e.Dimensions() != 8 {
+		t.Errorf("Dimensions() = %d", e.Dimensions())
+	}
+}
+
+func TestCreateEmbeddingsProviderFromConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *config.ModelConfig
+		batchSize int
+		wantErr   bool
+	}{
+		{"openai", &config.ModelConfig{Model: "openai/text-embedding-3-small", APIKey: "k"}, defaultEmbeddingBatchSize, false},
+		{"ollama without key", &config.ModelConfig{Model: "ollama/nomic-embed-text", EmbeddingBatchSize: 16}, 16, false},
+		{"gemini caps batch", &config.ModelConfig{Model: "gemini/text-embedding-004", APIKey: "k", EmbeddingBatchSize: 500}, maxGeminiEmbeddingBatch, false},
+		{"openai without credentials", &config.ModelConfig{Model: "openai/text-embedding-3-small"}, 0, true},
+		{"unsupported protocol", &config.ModelConfig{Model: "anthropic/claude", APIKey: "k"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := CreateEmbeddingsProviderFromConfig(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateEmbeddingsProviderFromConfig() error = %v", err)
+			}
+			if got := p.(*batchingEmbedder).batchSize; got != tt.batchSize {
+				t.Errorf("batchSize = %d, want %d", got, tt.batchSize)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // errorPattern defines a single pattern (string or regex) for error classification.
@@ -94,9 +96,26 @@ var (
 	}
 )
 
+// retryAfterHinter is implemented by provider errors that carry a server
+// back-off hint (e.g. openai_compat.HTTPError).
+type retryAfterHinter interface {
+	RetryAfterHint() time.Duration
+}
+
 // ClassifyError classifies an error into a FailoverError with reason.
 // Returns nil if the error is not classifiable (unknown errors should not trigger fallback).
 func ClassifyError(err error, provider, model string) *FailoverError {
+	failErr := classifyError(err, provider, model)
+	if failErr != nil {
+		var hinter retryAfterHinter
+		if errors.As(err, &hinter) {
+			failErr.RetryAfter = hinter.RetryAfterHint()
+		}
+	}
+	return failErr
+}
+
+func classifyError(err error, provider, model string) *FailoverError {
 	if err == nil {
 		return nil
 	}
@@ -116,6 +135,21 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Typed provider errors need no message matching.
+	if reason := classifyByKind(err); reason != "" {
+		fe := &FailoverError{
+			Reason:   reason,
+			Provider: provider,
+			Model:    model,
+			Wrapped:  err,
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			fe.Status = apiErr.StatusCode
+		}
+		return fe
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Image dimension/size errors: non-retriable, non-fallback.
@@ -154,6 +188,21 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 	return nil
 }
 
+// classifyByKind maps the error kinds providers return to FailoverReason.
+// A context that is too long or filtered content would fail the same way on
+// another candidate, so they are format errors, which don't fall back.
+func classifyByKind(err error) FailoverReason {
+	switch {
+	case errors.Is(err, ErrContextTooLong), errors.Is(err, ErrContentFiltered):
+		return FailoverFormat
+	case errors.Is(err, ErrAuth):
+		return FailoverAuth
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrOverloaded):
+		return FailoverRateLimit // Overloaded treated as rate_limit
+	}
+	return ""
+}
+
 // classifyByStatus maps HTTP status codes to FailoverReason.
 func classifyByStatus(status int) FailoverReason {
 	switch {
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestClassifyError_Nil(t *testing.T) {
@@ -335,3 +338,73 @@ func TestIsImageSizeError(t *testing.T) {
 		t.Error("should not match normal error")
 	}
 }
+
+type hintedError struct{ after time.Duration }
+
+func (e hintedError) Error() string                 { return "API request failed:\n  Status: 429\n  Body:   slow" }
+func (e hintedError) RetryAfterHint() time.Duration { return e.after }
+
+func TestClassifyError_CarriesRetryAfterHint(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", hintedError{after: 7 * time.Second})
+	result := ClassifyError(err, "groq", "llama")
+	if result == nil || result.Reason != FailoverRateLimit {
+		t.Fatalf("result = %+v, want rate_limit", result)
+	}
+	if result.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", result.RetryAfter)
+	}
+}
+
+func TestNewAPIError_Kinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		kind   error
+	}{
+		{401, `{"error":"bad key"}`, ErrAuth},
+		{403, `forbidden`, ErrAuth},
+		{429, `slow down`, ErrRateLimited},
+		{529, `{"type":"overloaded_error"}`, ErrOverloaded},
+		{503, `unavailable`, ErrOverloaded},
+		{400, `This model's maximum context length is 8192 tokens`, ErrContextTooLong},
+		{400, `InvalidParameter: Total tokens of image and text exceed max message tokens`, ErrContextTooLong},
+		{413, `payload too large`, ErrContextTooLong},
+		{400, `{"code":"content_filter"}`, ErrContentFiltered},
+		{400, `invalid tool schema`, nil},
+	}
+	for _, tt := range tests {
+		err := NewAPIError(tt.status, tt.body)
+		if tt.kind == nil {
+			if err.Kind != nil {
+				t.Errorf("NewAPIError(%d, %q) kind = %v, want none", tt.status, tt.body, err.Kind)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("NewAPIError(%d, %q) kind = %v, want %v", tt.status, tt.body, err.Kind, tt.kind)
+		}
+	}
+}
+
+func TestClassifyError_ByKind(t *testing.T) {
+	err := fmt.Errorf("claude cli: %w", protocoltypes.WithKind(errors.New("Overloaded"), ErrOverloaded))
+	result := ClassifyError(err, "claude-cli", "sonnet")
+	if result == nil || result.Reason != FailoverRateLimit {
+		t.Fatalf("expected rate_limit for overloaded kind, got %+v", result)
+	}
+
+	result = ClassifyError(NewAPIError(400, "prompt is too long"), "anthropic", "claude")
+	if result == nil || result.Reason != FailoverFormat || result.Status != 400 {
+		t.Fatalf("expected format/400 for context too long, got %+v", result)
+	}
+}
+
+func TestFallbackExhaustedError_UnwrapsAttempts(t *testing.T) {
+	err := &FallbackExhaustedError{Attempts: []FallbackAttempt{
+		{Provider: "openai", Model: "gpt-4", Error: NewAPIError(429, "quota")},
+		{Provider: "anthropic", Model: "claude", Error: NewAPIError(529, "overloaded")},
+	}}
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrOverloaded) {
+		t.Errorf("expected exhausted error to match its attempts' kinds")
+	}
+}
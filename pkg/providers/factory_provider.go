@@ -7,9 +7,17 @@ package providers
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
+	geminiprovider "github.com/sipeed/picoclaw/pkg/providers/gemini"
+	ollamaprovider "github.com/sipeed/picoclaw/pkg/providers/ollama"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
 )
 
 // ExtractProtocol extracts the protocol prefix and model identifier from a model string.
@@ -18,6 +26,9 @@ import (
 //   - "openai/gpt-4o" -> ("openai", "gpt-4o")
 //   - "anthropic/claude-sonnet-4.6" -> ("anthropic", "claude-sonnet-4.6")
 //   - "gpt-4o" -> ("openai", "gpt-4o")  // default protocol
+//
+// Prefixes naming a provider_aliases entry are rewritten to the alias's
+// protocol by config.GetModelConfig before models reach this function.
 func ExtractProtocol(model string) (protocol, modelID string) {
 	model = strings.TrimSpace(model)
 	protocol, modelID, found := strings.Cut(model, "/")
@@ -29,7 +40,7 @@ func ExtractProtocol(model string) (protocol, modelID string) {
 
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
-// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, anthropic, azure, bedrock, gemini, openrouter, groq, cerebras, deepseek, xai, llamacpp, ollama-native, mock, replay, antigravity, claude-cli, codex-cli, github-copilot
 // Returns the provider, the model ID (without protocol prefix), and any error.
 func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	if cfg == nil {
@@ -40,10 +51,239 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		return nil, "", fmt.Errorf("model is required")
 	}
 
+	if len(cfg.FallbackConfigs) > 0 {
+		return createFailoverProvider(cfg)
+	}
+
+	provider, modelID, err := createKeyedProvider(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.PromptProfile != nil {
+		provider = NewPromptProfileProvider(provider, *cfg.PromptProfile)
+	}
+	if cfg.RPM > 0 || cfg.TPM > 0 {
+		provider = NewRateLimitedProvider(provider, rateLimiterFor(rateLimitKey(cfg), cfg.RPM, cfg.TPM))
+	}
+	if cfg.Record == "" && cfg.Cache == "" {
+		return provider, modelID, nil
+	}
+	key, err := encrypt.ParseKey(cfg.EncryptionKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("storage encryption key: %w", err)
+	}
+	if cfg.Record != "" {
+		rp := NewRecordingProvider(provider, workspacePath(cfg, cfg.Record), append([]string{cfg.APIKey}, cfg.APIKeys...)...)
+		rp.SetEncryptionKey(key)
+		provider = rp
+	}
+	if cfg.Cache != "" {
+		ttl := time.Duration(cfg.CacheTTL) * time.Second
+		cp := NewCachingProvider(provider, workspacePath(cfg, cfg.Cache), ttl)
+		cp.SetEncryptionKey(key)
+		provider = cp
+	}
+	return provider, modelID, nil
+}
+
+// createKeyedProvider builds the provider for a model_list entry, rotating
+// through its keys when api_key lists several.
+func createKeyedProvider(cfg *config.ModelConfig) (LLMProvider, string, error) {
+	if len(cfg.APIKeys) < 2 {
+		return createProvider(cfg)
+	}
+
+	members := make([]LLMProvider, 0, len(cfg.APIKeys))
+	var modelID string
+	for _, key := range cfg.APIKeys {
+		keyed := *cfg
+		keyed.APIKey = key
+		p, id, err := createProvider(&keyed)
+		if err != nil {
+			return nil, "", err
+		}
+		members = append(members, p)
+		modelID = id
+	}
+	cooldown := time.Duration(cfg.KeyCooldown) * time.Second
+	return NewKeyRotationProvider(members, cooldown), modelID, nil
+}
+
+// workspacePath resolves a path from a model_list entry relative to the
+// entry's workspace.
+func workspacePath(cfg *config.ModelConfig, path string) string {
+	if !filepath.IsAbs(path) && cfg.Workspace != "" {
+		return filepath.Join(cfg.Workspace, path)
+	}
+	return path
+}
+
+// rateLimitKey identifies the endpoint a model_list entry talks to, so that
+// entries (and fallbacks) sharing an endpoint share one rate limit.
+func rateLimitKey(cfg *config.ModelConfig) string {
+	protocol, _ := ExtractProtocol(cfg.Model)
+	apiBase := cfg.APIBase
+	if apiBase == "" {
+		// Azure resources and Bedrock regions have separate quotas.
+		apiBase = cfg.Resource + cfg.Region
+	}
+	if apiBase == "" {
+		apiBase = getDefaultAPIBase(protocol)
+	}
+	return protocol + " " + strings.TrimRight(apiBase, "/")
+}
+
+// createProvider builds the provider for a single model_list entry.
+func createProvider(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	protocol, modelID := ExtractProtocol(cfg.Model)
 
+	// An endpoint on the no_proxy list ignores both proxy and the environment.
+	if len(cfg.NoProxy) > 0 {
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		if transport.Bypass(apiBase, cfg.NoProxy) {
+			direct := *cfg
+			direct.Proxy = transport.Direct
+			cfg = &direct
+		}
+	}
+
+	if len(cfg.NativeTools) > 0 && (nativeToolsUnsupported[protocol] ||
+		protocol == "gemini" && !strings.HasSuffix(strings.TrimRight(cfg.APIBase, "/"), "/openai")) {
+		return nil, "", fmt.Errorf("model %q: native_tools is not supported for protocol %q", cfg.Model, protocol)
+	}
+
+	compatOpts, err := compatOptions(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("model %q: %w", cfg.Model, err)
+	}
+
 	switch protocol {
-	case "gemini", "ollama", "vllm", "mistral":
+	case "anthropic":
+		// Native Messages API (supports cache_control prompt caching)
+		if cfg.APIKey == "" {
+			return nil, "", fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		opts, err := anthropicNativeTools(cfg)
+		if err != nil {
+			return nil, "", fmt.Errorf("model %q: %w", cfg.Model, err)
+		}
+		if budget, ok := thinkingBudget(cfg); ok && budget > 0 {
+			opts = append(opts, anthropicprovider.WithThinkingBudget(budget))
+		}
+		return NewClaudeProvider(cfg.APIKey, apiBase, cfg.Proxy, cfg.RequestTimeout, opts...), modelID, nil
+
+	case "azure":
+		// Azure OpenAI: the model ID is the deployment name
+		if cfg.APIKey == "" {
+			return nil, "", fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		endpoint, err := azureEndpoint(cfg.APIBase, cfg.Resource)
+		if err != nil {
+			return nil, "", err
+		}
+		return NewAzureProvider(
+			cfg.APIKey,
+			endpoint,
+			modelID,
+			cfg.APIVersion,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			cfg.RequestTimeout,
+			compatOpts...,
+		), modelID, nil
+
+	case "bedrock":
+		// Converse API; credentials come from AWS env/profile, not api_key
+		return NewBedrockProvider(
+			cfg.Region,
+			cfg.Profile,
+			cfg.APIKey,
+			cfg.APIBase,
+			cfg.Proxy,
+			cfg.RequestTimeout,
+		), modelID, nil
+
+	case "openrouter":
+		if cfg.APIKey == "" {
+			return nil, "", fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return NewOpenRouterProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			cfg.RequestTimeout,
+			compatOpts...,
+		), modelID, nil
+
+	case "llamacpp":
+		// Local llama.cpp server; api_key only if started with --api-key
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return NewLlamaCppProvider(cfg.APIKey, apiBase, cfg.Proxy, cfg.Grammar, cfg.RequestTimeout), modelID, nil
+
+	case "ollama-native":
+		// Native /api/chat with keep_alive and auto-pull; "ollama" uses the /v1 shim
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return NewOllamaProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			cfg.RequestTimeout,
+			ollamaprovider.WithKeepAlive(cfg.KeepAlive),
+			ollamaprovider.WithAutoPull(cfg.AutoPull),
+		), modelID, nil
+
+	case "gemini":
+		// Native generateContent API. An api_base pointing at the
+		// OpenAI-compatible endpoint keeps using the compat shim.
+		if cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, "", fmt.Errorf("api_key or api_base is required for protocol %q", protocol)
+		}
+		if strings.HasSuffix(strings.TrimRight(cfg.APIBase, "/"), "/openai") {
+			return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+				cfg.APIKey,
+				cfg.APIBase,
+				cfg.Proxy,
+				cfg.MaxTokensField,
+				cfg.RequestTimeout,
+				compatOpts...,
+			), modelID, nil
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		var opts []geminiprovider.Option
+		if budget, ok := thinkingBudget(cfg); ok {
+			opts = append(opts, geminiprovider.WithThinkingBudget(budget))
+		}
+		return NewGeminiProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			cfg.SafetyThreshold,
+			cfg.RequestTimeout,
+			opts...,
+		), modelID, nil
+
+	case "ollama", "vllm", "mistral", "groq", "cerebras", "deepseek", "xai":
 		// All other OpenAI-compatible HTTP providers
 		if cfg.APIKey == "" && cfg.APIBase == "" {
 			return nil, "", fmt.Errorf("api_key or api_base is required for HTTP-based protocol %q", protocol)
@@ -58,24 +298,143 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.Proxy,
 			cfg.MaxTokensField,
 			cfg.RequestTimeout,
+			compatOpts...,
+		), modelID, nil
+
+	case "github-copilot":
+		// Copilot chat API; api_key is an optional GitHub token, otherwise
+		// the login saved by "picoclaw auth github-copilot" is used
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return NewGitHubCopilotProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			append([]openai_compat.Option{
+				openai_compat.WithMaxTokensField(cfg.MaxTokensField),
+				openai_compat.WithRequestTimeout(time.Duration(cfg.RequestTimeout) * time.Second),
+			}, compatOpts...)...,
 		), modelID, nil
 
+	case "claude-cli":
+		// Local Claude Code CLI with the user's own login; no api_key
+		return NewClaudeCliProvider(cfg.Workspace, cfg.AllowedTools, cfg.CLIArgs), modelID, nil
+
+	case "codex-cli":
+		// Local Codex CLI with the user's own login; no api_key
+		return NewCodexCliProvider(cfg.Workspace, cfg.CLIArgs), modelID, nil
+
+	case "mock":
+		// Scripted offline responses; the model ID is the script path
+		provider, err := LoadMockProvider(modelID)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
+
+	case "replay":
+		// Serves a transcript recorded with "record"; the model ID is its path
+		key, err := encrypt.ParseKey(cfg.EncryptionKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("storage encryption key: %w", err)
+		}
+		provider, err := NewEncryptedReplayProvider(modelID, key)
+		if err != nil {
+			return nil, "", err
+		}
+		return provider, modelID, nil
+
 	default:
 		return nil, "", fmt.Errorf("unknown protocol %q in model %q", protocol, cfg.Model)
 	}
 }
 
+// compatOptions are the options shared by every OpenAI-compatible provider:
+// retries, reasoning effort, gateway headers and TLS, scoring settings and
+// native tools.
+func compatOptions(cfg *config.ModelConfig) ([]openai_compat.Option, error) {
+	tlsCfg, err := clientTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts := []openai_compat.Option{
+		openai_compat.WithMaxAttempts(cfg.MaxAttempts),
+		openai_compat.WithReasoningEffort(reasoningEffort(cfg)),
+		openai_compat.WithExtraHeaders(cfg.ExtraHeaders),
+		openai_compat.WithTLSConfig(tlsCfg),
+		openai_compat.WithLogitBias(cfg.LogitBias),
+	}
+	if cfg.Logprobs || cfg.TopLogprobs > 0 {
+		opts = append(opts, openai_compat.WithLogprobs(cfg.TopLogprobs))
+	}
+	if protocol, _ := ExtractProtocol(cfg.Model); len(cfg.NativeTools) > 0 && protocol != "anthropic" {
+		nativeOpts, err := compatNativeTools(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nativeOpts...)
+	}
+	return opts, nil
+}
+
+// createFailoverProvider builds a FailoverProvider from cfg and its resolved
+// fallbacks. The returned model ID is the primary's.
+func createFailoverProvider(cfg *config.ModelConfig) (LLMProvider, string, error) {
+	primary := *cfg
+	primary.FallbackConfigs = nil
+	chain := append([]*config.ModelConfig{&primary}, cfg.FallbackConfigs...)
+
+	keys := make([]string, 0, len(chain))
+	members := make([]LLMProvider, 0, len(chain))
+	models := make([]string, 0, len(chain))
+	for _, mc := range chain {
+		if mc.Workspace == "" {
+			mc.Workspace = cfg.Workspace
+		}
+		mc.EncryptionKey = cfg.EncryptionKey
+		provider, modelID, err := CreateProviderFromConfig(mc)
+		if err != nil {
+			return nil, "", fmt.Errorf("fallback %q: %w", mc.Model, err)
+		}
+		keys = append(keys, mc.Model)
+		members = append(members, provider)
+		models = append(models, modelID)
+	}
+
+	return NewFailoverProvider(keys, members, models), models[0], nil
+}
+
 // getDefaultAPIBase returns the default API base URL for a given protocol.
 func getDefaultAPIBase(protocol string) string {
 	switch protocol {
+	case "anthropic":
+		return "https://api.anthropic.com/v1"
 	case "gemini":
 		return "https://generativelanguage.googleapis.com/v1beta"
 	case "ollama":
 		return "http://localhost:11434/v1"
+	case "ollama-native":
+		return "http://localhost:11434"
+	case "github-copilot":
+		return "https://api.githubcopilot.com"
+	case "openrouter":
+		return "https://openrouter.ai/api/v1"
+	case "groq":
+		return "https://api.groq.com/openai/v1"
+	case "llamacpp":
+		return "http://localhost:8080"
+	case "cerebras":
+		return "https://api.cerebras.ai/v1"
 	case "vllm":
 		return "http://localhost:8000/v1"
 	case "mistral":
 		return "https://api.mistral.ai/v1"
+	case "deepseek":
+		return "https://api.deepseek.com/v1"
+	case "xai":
+		return "https://api.x.ai/v1"
 	default:
 		return ""
 	}
@@ -6,6 +6,8 @@
 package providers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -290,3 +292,174 @@ func TestCreateProviderFromConfig_RequestTimeoutPropagation(t *testing.T) {
 		t.Fatalf("Chat() error = %q, want timeout-related error", errMsg)
 	}
 }
+
+func TestCreateProviderFromConfig_DeepSeekAndXAI(t *testing.T) {
+	for _, protocol := range []string{"deepseek", "xai"} {
+		t.Run(protocol, func(t *testing.T) {
+			provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+				ModelName: "test-" + protocol,
+				Model:     protocol + "/some-model",
+				APIKey:    "test-key",
+			})
+			if err != nil {
+				t.Fatalf("CreateProviderFromConfig() error = %v", err)
+			}
+			if _, ok := provider.(*HTTPProvider); !ok {
+				t.Fatalf("expected *HTTPProvider, got %T", provider)
+			}
+			if modelID != "some-model" {
+				t.Errorf("modelID = %q", modelID)
+			}
+			if getDefaultAPIBase(protocol) == "" {
+				t.Errorf("no default api_base for %q", protocol)
+			}
+		})
+	}
+}
+
+func TestCreateProviderFromConfig_OllamaNative(t *testing.T) {
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "test-ollama-native",
+		Model:     "ollama-native/llama3.2:3b",
+		KeepAlive: "30m",
+		AutoPull:  true,
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*OllamaProvider); !ok {
+		t.Fatalf("expected *OllamaProvider, got %T", provider)
+	}
+	if modelID != "llama3.2:3b" {
+		t.Errorf("modelID = %q, want %q", modelID, "llama3.2:3b")
+	}
+}
+
+func TestCreateProviderFromConfig_GitHubCopilot(t *testing.T) {
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "copilot",
+		Model:     "github-copilot/gpt-4.1",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*GitHubCopilotProvider); !ok {
+		t.Fatalf("expected *GitHubCopilotProvider, got %T", provider)
+	}
+	if modelID != "gpt-4.1" {
+		t.Errorf("modelID = %q, want %q", modelID, "gpt-4.1")
+	}
+}
+
+func TestCreateProviderFromConfig_NoProxyBypass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	// Nothing listens on the proxy's port.
+	cfg := &config.ModelConfig{
+		ModelName:   "local",
+		Model:       "vllm/test-model",
+		APIBase:     server.URL,
+		Proxy:       "socks5://127.0.0.1:1",
+		MaxAttempts: 1,
+	}
+	chat := func() error {
+		provider, _, err := CreateProviderFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("CreateProviderFromConfig() error = %v", err)
+		}
+		_, err = provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "test-model", nil)
+		return err
+	}
+
+	if err := chat(); err == nil {
+		t.Fatal("Chat() through the unreachable proxy succeeded")
+	}
+	cfg.NoProxy = []string{"127.0.0.0/8"}
+	if err := chat(); err != nil {
+		t.Errorf("Chat() with no_proxy bypass error = %v", err)
+	}
+}
+
+func TestCreateProviderFromConfig_Logprobs(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"no"},"finish_reason":"stop",` +
+			`"logprobs":{"content":[{"token":"no","logprob":-0.2}]}}]}`))
+	}))
+	defer server.Close()
+
+	provider, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:   "classifier",
+		Model:       "vllm/test-model",
+		APIBase:     server.URL,
+		TopLogprobs: 2,
+		LogitBias:   map[string]float64{"3763": 10},
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	resp, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "spam?"}}, nil, "test-model", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if body["logprobs"] != true || body["top_logprobs"] != float64(2) {
+		t.Errorf("request logprobs = %v, top_logprobs = %v", body["logprobs"], body["top_logprobs"])
+	}
+	if bias, _ := body["logit_bias"].(map[string]any); bias["3763"] != float64(10) {
+		t.Errorf("request logit_bias = %v", body["logit_bias"])
+	}
+	if len(resp.Logprobs) != 1 || resp.Logprobs[0].Logprob != -0.2 {
+		t.Errorf("Logprobs = %+v", resp.Logprobs)
+	}
+}
+
+func TestCreateProviderFromConfig_NativeTools(t *testing.T) {
+	var body map[string]any
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{}}`))
+	}))
+	defer server.Close()
+
+	provider, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:   "claude",
+		Model:       "anthropic/claude-sonnet-4.6",
+		APIKey:      "key",
+		APIBase:     server.URL,
+		NativeTools: []any{"web_search", "code_execution"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil,
+		"claude-sonnet-4.6", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if tools, _ := body["tools"].([]any); len(tools) != 2 {
+		t.Errorf("request tools = %v", body["tools"])
+	}
+	if beta != "code-execution-2025-08-25" {
+		t.Errorf("anthropic-beta = %q", beta)
+	}
+
+	invalid := []config.ModelConfig{
+		{ModelName: "a", Model: "anthropic/claude", APIKey: "key", NativeTools: []any{"computer_use"}},
+		{ModelName: "b", Model: "groq/llama", APIKey: "key", NativeTools: []any{"code_execution"}},
+		{ModelName: "c", Model: "bedrock/claude", NativeTools: []any{"web_search"}},
+		{ModelName: "d", Model: "gemini/gemini-2.5-flash", APIKey: "key", NativeTools: []any{"web_search"}},
+	}
+	for _, cfg := range invalid {
+		if _, _, err := CreateProviderFromConfig(&cfg); err == nil {
+			t.Errorf("CreateProviderFromConfig(%s, %v) succeeded, want an error", cfg.Model, cfg.NativeTools)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// FailoverProvider wraps an ordered list of providers and transparently moves
+// on to the next one when a call fails with a retriable error (rate limit,
+// overload, 5xx, timeout). Failing providers are put in cooldown so that
+// subsequent calls skip them until they recover.
+type FailoverProvider struct {
+	members []failoverMember
+	chain   *FallbackChain
+}
+
+type failoverMember struct {
+	key      string // unique cooldown key, e.g. "anthropic/claude-sonnet-4.6"
+	provider LLMProvider
+	model    string
+}
+
+// NewFailoverProvider creates a FailoverProvider. keys identify each member
+// for cooldown tracking and logging; providers and models are the member
+// providers and the model ID each one is called with.
+func NewFailoverProvider(keys []string, providers []LLMProvider, models []string) *FailoverProvider {
+	members := make([]failoverMember, 0, len(providers))
+	seen := make(map[string]int)
+	for i, p := range providers {
+		key := keys[i]
+		if n := seen[key]; n > 0 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		seen[keys[i]]++
+		members = append(members, failoverMember{key: key, provider: p, model: models[i]})
+	}
+	return &FailoverProvider{
+		members: members,
+		chain:   NewFallbackChain(NewCooldownTracker()),
+	}
+}
+
+func (p *FailoverProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.execute(ctx, model, func(ctx context.Context, m failoverMember, model string) (*LLMResponse, error) {
+		return m.provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+// StreamChat streams from the first available member. Members that don't
+// support streaming are called without it.
+func (p *FailoverProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.execute(ctx, model, func(ctx context.Context, m failoverMember, model string) (*LLMResponse, error) {
+		if sp, ok := m.provider.(StreamingProvider); ok {
+			return sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+		}
+		return m.provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+func (p *FailoverProvider) GetDefaultModel() string {
+	return ""
+}
+
+// Ping checks the primary member.
+func (p *FailoverProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.members[0].provider)
+}
+
+// ListModels lists the primary member's models.
+func (p *FailoverProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.members[0].provider)
+}
+
+// Close releases members that hold resources.
+func (p *FailoverProvider) Close() {
+	for _, m := range p.members {
+		if sp, ok := m.provider.(StatefulProvider); ok {
+			sp.Close()
+		}
+	}
+}
+
+// execute runs call against each member in order. The requested model only
+// applies to the primary member; fallbacks always use their own model.
+func (p *FailoverProvider) execute(
+	ctx context.Context,
+	model string,
+	call func(ctx context.Context, m failoverMember, model string) (*LLMResponse, error),
+) (*LLMResponse, error) {
+	candidates := make([]FallbackCandidate, len(p.members))
+	byKey := make(map[string]failoverMember, len(p.members))
+	for i, m := range p.members {
+		memberModel := m.model
+		if i == 0 && model != "" {
+			memberModel = model
+		}
+		candidates[i] = FallbackCandidate{Provider: m.key, Model: memberModel}
+		byKey[m.key] = m
+	}
+
+	result, err := p.chain.Execute(ctx, candidates,
+		func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+			return call(ctx, byKey[provider], model)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Attempts) > 0 {
+		logger.InfoCF("provider", "Failover: request served by fallback provider", map[string]any{
+			"provider": result.Provider,
+			"model":    result.Model,
+			"attempts": len(result.Attempts) + 1,
+		})
+	}
+	return result.Response, nil
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type scriptedProvider struct {
+	err    error
+	calls  int
+	models []string
+}
+
+func (p *scriptedProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	p.calls++
+	p.models = append(p.models, model)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &LLMResponse{Content: "ok from " + model}, nil
+}
+
+func (p *scriptedProvider) GetDefaultModel() string { return "" }
+
+func TestFailoverProvider_FailsOverAndCoolsDown(t *testing.T) {
+	primary := &scriptedProvider{err: errors.New("API request failed:\n  Status: 429\n  Body:   slow down")}
+	secondary := &scriptedProvider{}
+	fp := NewFailoverProvider(
+		[]string{"anthropic/claude", "openai/gpt-4o"},
+		[]LLMProvider{primary, secondary},
+		[]string{"claude", "gpt-4o"},
+	)
+
+	resp, err := fp.Chat(context.Background(), nil, nil, "claude-override", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok from gpt-4o" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if primary.models[0] != "claude-override" {
+		t.Errorf("primary model = %q, want requested model", primary.models[0])
+	}
+
+	// The primary is now in cooldown and must be skipped.
+	if _, err := fp.Chat(context.Background(), nil, nil, "", nil); err != nil {
+		t.Fatalf("second Chat() error = %v", err)
+	}
+	if primary.calls != 1 || secondary.calls != 2 {
+		t.Errorf("calls primary=%d secondary=%d, want 1 and 2", primary.calls, secondary.calls)
+	}
+}
+
+func TestFailoverProvider_NonRetriableErrorStops(t *testing.T) {
+	primary := &scriptedProvider{err: errors.New("API request failed:\n  Status: 400\n  Body:   invalid request format")}
+	secondary := &scriptedProvider{}
+	fp := NewFailoverProvider(
+		[]string{"a/x", "b/y"},
+		[]LLMProvider{primary, secondary},
+		[]string{"x", "y"},
+	)
+
+	if _, err := fp.Chat(context.Background(), nil, nil, "", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if secondary.calls != 0 {
+		t.Errorf("secondary called %d times on format error", secondary.calls)
+	}
+}
+
+func TestCreateProviderFromConfig_Fallbacks(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "main",
+		Model:     "anthropic/claude-sonnet-4.6",
+		APIKey:    "key",
+		FallbackConfigs: []*config.ModelConfig{
+			{ModelName: "local", Model: "ollama/llama3", APIBase: "http://localhost:11434/v1"},
+		},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*FailoverProvider); !ok {
+		t.Fatalf("provider = %T, want *FailoverProvider", provider)
+	}
+	if modelID != "claude-sonnet-4.6" {
+		t.Errorf("modelID = %q, want claude-sonnet-4.6", modelID)
+	}
+}
@@ -173,6 +173,7 @@ func (fc *FallbackChain) Execute(
 
 		// Retriable error: mark failure and continue to next candidate.
 		fc.cooldown.MarkFailure(candidate.Provider, failErr.Reason)
+		fc.cooldown.ExtendCooldown(candidate.Provider, failErr.RetryAfter)
 		result.Attempts = append(result.Attempts, FallbackAttempt{
 			Provider: candidate.Provider,
 			Model:    candidate.Model,
@@ -272,6 +273,18 @@ type FallbackExhaustedError struct {
 	Attempts []FallbackAttempt
 }
 
+// Unwrap returns the errors of the candidates that were tried, so errors.Is
+// finds their kinds (ErrRateLimited etc.).
+func (e *FallbackExhaustedError) Unwrap() []error {
+	var errs []error
+	for _, a := range e.Attempts {
+		if a.Error != nil {
+			errs = append(errs, a.Error)
+		}
+	}
+	return errs
+}
+
 func (e *FallbackExhaustedError) Error() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("fallback: all %d candidates failed:", len(e.Attempts)))
@@ -0,0 +1,69 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type embedRequest struct {
+	Model                string  `json:"model"`
+	Content              content `json:"content"`
+	OutputDimensionality int     `json:"outputDimensionality,omitempty"`
+}
+
+// Embed returns one embedding per input using batchEmbedContents, in input
+// order. dimensions > 0 truncates vectors via outputDimensionality. The API
+// accepts at most 100 inputs per call; batching is up to the caller.
+func (p *Provider) Embed(ctx context.Context, inputs []string, model string, dimensions int) ([][]float32, error) {
+	requests := make([]embedRequest, 0, len(inputs))
+	for _, input := range inputs {
+		requests = append(requests, embedRequest{
+			Model:                "models/" + strings.TrimPrefix(model, "models/"),
+			Content:              content{Parts: []part{{Text: input}}},
+			OutputDimensionality: dimensions,
+		})
+	}
+
+	resp, err := p.post(ctx, model, "batchEmbedContents", map[string]any{"requests": requests})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	var out struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(out.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(out.Embeddings), len(inputs))
+	}
+
+	vectors := make([][]float32, len(out.Embeddings))
+	for i, e := range out.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
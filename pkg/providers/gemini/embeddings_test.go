@@ -0,0 +1,44 @@
+package gemini
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderEmbed_BatchEmbedContents(t *testing.T) {
+	var requestBody struct {
+		Requests []struct {
+			Model                string  `json:"model"`
+			Content              content `json:"content"`
+			OutputDimensionality int     `json:"outputDimensionality"`
+		} `json:"requests"`
+	}
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Write([]byte(`{"embeddings":[{"values":[0.5,0.5]},{"values":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	vectors, err := p.Embed(t.Context(), []string{"a", "b"}, "text-embedding-004", 2)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if path != "/models/text-embedding-004:batchEmbedContents" {
+		t.Errorf("path = %q", path)
+	}
+	if len(requestBody.Requests) != 2 || requestBody.Requests[1].Content.Parts[0].Text != "b" ||
+		requestBody.Requests[0].Model != "models/text-embedding-004" ||
+		requestBody.Requests[0].OutputDimensionality != 2 {
+		t.Errorf("request = %+v", requestBody)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 {
+		t.Errorf("vectors = %v", vectors)
+	}
+}
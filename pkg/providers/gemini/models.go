@@ -0,0 +1,81 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// ListModels returns the IDs (without the "models/" prefix) of models that
+// support generateContent or embedContent.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	pageToken := ""
+	for {
+		endpoint := p.apiBase + "/models?pageSize=1000"
+		if pageToken != "" {
+			endpoint += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if p.apiKey != "" {
+			req.Header.Set("x-goog-api-key", p.apiKey)
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+		}
+
+		var out struct {
+			Models []struct {
+				Name    string   `json:"name"`
+				Methods []string `json:"supportedGenerationMethods"`
+			} `json:"models"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(body, &out); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		for _, m := range out.Models {
+			for _, method := range m.Methods {
+				if method == "generateContent" || method == "embedContent" {
+					models = append(models, strings.TrimPrefix(m.Name, "models/"))
+					break
+				}
+			}
+		}
+
+		if out.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = out.NextPageToken
+	}
+}
+
+// Ping checks that the API is reachable and the key is valid.
+func (p *Provider) Ping(ctx context.Context) error {
+	_, err := p.ListModels(ctx)
+	return err
+}
@@ -0,0 +1,30 @@
+package gemini
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderListModels_PagesAndFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"models":[
+				{"name":"models/gemini-2.5-flash","supportedGenerationMethods":["generateContent","countTokens"]},
+				{"name":"models/aqa","supportedGenerationMethods":["generateAnswer"]}
+			],"nextPageToken":"p2"}`))
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"models/text-embedding-004","supportedGenerationMethods":["embedContent"]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	models, err := p.ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0] != "gemini-2.5-flash" || models[1] != "text-embedding-004" {
+		t.Errorf("models = %v", models)
+	}
+}
@@ -0,0 +1,533 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+	ImagePart      = protocoltypes.ImagePart
+	ExtraContent   = protocoltypes.ExtraContent
+	GoogleExtra    = protocoltypes.GoogleExtra
+
+	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+)
+
+const (
+	DefaultAPIBase = "https://generativelanguage.googleapis.com/v1beta"
+
+	defaultRequestTimeout = 120 * time.Second
+)
+
+// harmCategories are the categories a safety threshold is applied to.
+var harmCategories = []string{
+	"HARM_CATEGORY_HARASSMENT",
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+}
+
+// Provider talks to the Gemini API natively through generateContent and
+// streamGenerateContent, instead of the OpenAI-compatible endpoint.
+type Provider struct {
+	apiKey          string
+	apiBase         string
+	safetyThreshold string
+	thinkingBudget  *int
+	httpClient      *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithSafetyThreshold applies threshold (e.g. "BLOCK_ONLY_HIGH", "BLOCK_NONE")
+// to all harm categories. An empty threshold keeps the API defaults.
+func WithSafetyThreshold(threshold string) Option {
+	return func(p *Provider) {
+		p.safetyThreshold = threshold
+	}
+}
+
+// WithThinkingBudget caps thinking tokens per request on thinking models: 0
+// turns thinking off where the model allows it, -1 lets the model decide.
+// Thoughts are returned as reasoning content whenever thinking is on.
+func WithThinkingBudget(tokens int) Option {
+	return func(p *Provider) {
+		p.thinkingBudget = &tokens
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("gemini: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.post(ctx, model, "generateContent", p.buildRequest(messages, tools, options))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	var out generateResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return out.toLLMResponse(), nil
+}
+
+// post calls a model method (generateContent or streamGenerateContent).
+// The caller owns the response body.
+func (p *Provider) post(ctx context.Context, model, method string, requestBody any) (*http.Response, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:%s", p.apiBase, url.PathEscape(model), method)
+	if method == "streamGenerateContent" {
+		endpoint += "?alt=sse"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("x-goog-api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// Wire types for the Gemini API. Only the fields picoclaw uses are modelled.
+
+type inlineData struct {
+	MIMEType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type functionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type functionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type part struct {
+	Text             string            `json:"text,omitempty"`
+	Thought          bool              `json:"thought,omitempty"`
+	InlineData       *inlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+	ThoughtSignature string            `json:"thoughtSignature,omitempty"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type functionDeclaration struct {
+	Name                 string         `json:"name"`
+	Description          string         `json:"description,omitempty"`
+	ParametersJSONSchema map[string]any `json:"parametersJsonSchema,omitempty"`
+}
+
+type tool struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+type safetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+type generationConfig struct {
+	MaxOutputTokens    int             `json:"maxOutputTokens,omitempty"`
+	Temperature        *float64        `json:"temperature,omitempty"`
+	StopSequences      []string        `json:"stopSequences,omitempty"`
+	ResponseMIMEType   string          `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema map[string]any  `json:"responseJsonSchema,omitempty"`
+	ThinkingConfig     *thinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+type thinkingConfig struct {
+	ThinkingBudget  int  `json:"thinkingBudget"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+}
+
+type generateRequest struct {
+	SystemInstruction *content          `json:"systemInstruction,omitempty"`
+	Contents          []content         `json:"contents"`
+	Tools             []tool            `json:"tools,omitempty"`
+	SafetySettings    []safetySetting   `json:"safetySettings,omitempty"`
+	GenerationConfig  *generationConfig `json:"generationConfig,omitempty"`
+}
+
+type candidate struct {
+	Content      content `json:"content"`
+	FinishReason string  `json:"finishReason"`
+}
+
+type generateResponse struct {
+	Candidates    []candidate `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount        int `json:"promptTokenCount"`
+		CandidatesTokenCount    int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+		TotalTokenCount         int `json:"totalTokenCount"`
+		CachedContentTokenCount int `json:"cachedContentTokenCount"`
+	} `json:"usageMetadata"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// buildRequest converts picoclaw messages and tools into a generateContent
+// request. System messages become the system instruction, assistant turns
+// use the "model" role and tool results become functionResponse parts.
+func (p *Provider) buildRequest(messages []Message, tools []ToolDefinition, options map[string]any) *generateRequest {
+	req := &generateRequest{}
+
+	// functionResponse parts must carry the function name, which picoclaw
+	// only records on the assistant tool call.
+	callNames := make(map[string]string)
+
+	var system []part
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			if msg.Content != "" {
+				system = append(system, part{Text: msg.Content})
+			}
+
+		case "user":
+			parts := make([]part, 0, 1+len(msg.Images))
+			for _, img := range msg.Images {
+				if img.Data == "" {
+					continue // URL images would need the Files API
+				}
+				parts = append(parts, part{InlineData: &inlineData{MIMEType: img.MIMEType, Data: img.Data}})
+			}
+			if msg.Content != "" || len(parts) == 0 {
+				parts = append(parts, part{Text: msg.Content})
+			}
+			req.Contents = appendContent(req.Contents, "user", parts)
+
+		case "assistant":
+			var parts []part
+			if msg.Content != "" {
+				parts = append(parts, part{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				fc := toFunctionCall(tc)
+				callNames[tc.ID] = fc.Name
+				parts = append(parts, part{FunctionCall: fc, ThoughtSignature: thoughtSignature(tc)})
+			}
+			if len(parts) > 0 {
+				req.Contents = appendContent(req.Contents, "model", parts)
+			}
+
+		case "tool":
+			req.Contents = appendContent(req.Contents, "user", []part{{
+				FunctionResponse: &functionResponse{
+					ID:       functionCallID(msg.ToolCallID),
+					Name:     callNames[msg.ToolCallID],
+					Response: map[string]any{"result": msg.Content},
+				},
+			}})
+		}
+	}
+	if len(system) > 0 {
+		req.SystemInstruction = &content{Parts: system}
+	}
+
+	if len(tools) > 0 {
+		decls := make([]functionDeclaration, 0, len(tools))
+		for _, t := range tools {
+			decls = append(decls, functionDeclaration{
+				Name:                 t.Function.Name,
+				Description:          t.Function.Description,
+				ParametersJSONSchema: t.Function.Parameters,
+			})
+		}
+		req.Tools = []tool{{FunctionDeclarations: decls}}
+	}
+
+	if p.safetyThreshold != "" {
+		for _, category := range harmCategories {
+			req.SafetySettings = append(req.SafetySettings, safetySetting{
+				Category:  category,
+				Threshold: p.safetyThreshold,
+			})
+		}
+	}
+
+	gc := &generationConfig{}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		gc.MaxOutputTokens = maxTokens
+	}
+	if temperature, ok := asFloat(options["temperature"]); ok {
+		gc.Temperature = &temperature
+	}
+	gc.StopSequences = protocoltypes.StopOption(options)
+	if schema := protocoltypes.ResponseSchemaOption(options); schema != nil {
+		gc.ResponseMIMEType = "application/json"
+		gc.ResponseJSONSchema = schema.Schema
+	}
+	if p.thinkingBudget != nil {
+		budget := *p.thinkingBudget
+		gc.ThinkingConfig = &thinkingConfig{ThinkingBudget: budget, IncludeThoughts: budget != 0}
+	}
+	if gc.MaxOutputTokens > 0 || gc.Temperature != nil || len(gc.StopSequences) > 0 ||
+		gc.ResponseMIMEType != "" || gc.ThinkingConfig != nil {
+		req.GenerationConfig = gc
+	}
+
+	return req
+}
+
+// appendContent appends parts as a new turn, merging into the previous turn
+// when it has the same role (Gemini expects alternating turns).
+func appendContent(contents []content, role string, parts []part) []content {
+	if n := len(contents); n > 0 && contents[n-1].Role == role {
+		contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+		return contents
+	}
+	return append(contents, content{Role: role, Parts: parts})
+}
+
+func toFunctionCall(tc ToolCall) *functionCall {
+	name := tc.Name
+	args := tc.Arguments
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		if args == nil && tc.Function.Arguments != "" {
+			_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
+		}
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return &functionCall{ID: functionCallID(tc.ID), Name: name, Args: args}
+}
+
+func thoughtSignature(tc ToolCall) string {
+	switch {
+	case tc.ThoughtSignature != "":
+		return tc.ThoughtSignature
+	case tc.ExtraContent != nil && tc.ExtraContent.Google != nil:
+		return tc.ExtraContent.Google.ThoughtSignature
+	case tc.Function != nil:
+		return tc.Function.ThoughtSignature
+	}
+	return ""
+}
+
+// generatedIDPrefix marks tool call IDs picoclaw made up because the API
+// returned a function call without one; they are not sent back.
+const generatedIDPrefix = "gemini_call_"
+
+func functionCallID(id string) string {
+	if strings.HasPrefix(id, generatedIDPrefix) {
+		return ""
+	}
+	return id
+}
+
+func (r *generateResponse) toLLMResponse() *LLMResponse {
+	out := &LLMResponse{FinishReason: "stop"}
+
+	if r.UsageMetadata != nil {
+		u := r.UsageMetadata
+		out.Usage = &UsageInfo{
+			PromptTokens:     u.PromptTokenCount,
+			CompletionTokens: u.CandidatesTokenCount + u.ThoughtsTokenCount,
+			TotalTokens:      u.TotalTokenCount,
+			CacheReadTokens:  u.CachedContentTokenCount,
+			ThinkingTokens:   u.ThoughtsTokenCount,
+		}
+	}
+
+	if len(r.Candidates) == 0 {
+		if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+			out.Content = fmt.Sprintf("[prompt blocked by Gemini: %s]", r.PromptFeedback.BlockReason)
+			out.FinishReason = "content_filter"
+		}
+		return out
+	}
+
+	cand := r.Candidates[0]
+	var text, reasoning strings.Builder
+	for i, pt := range cand.Content.Parts {
+		switch {
+		case pt.FunctionCall != nil:
+			out.ToolCalls = append(out.ToolCalls, toToolCall(pt, i))
+		case pt.Thought:
+			reasoning.WriteString(pt.Text)
+		default:
+			text.WriteString(pt.Text)
+		}
+	}
+	out.Content = text.String()
+	out.ReasoningContent = reasoning.String()
+	out.FinishReason = mapFinishReason(cand.FinishReason, len(out.ToolCalls) > 0)
+	return out
+}
+
+func toToolCall(pt part, index int) ToolCall {
+	fc := pt.FunctionCall
+	id := fc.ID
+	if id == "" {
+		id = fmt.Sprintf("%s%d_%s", generatedIDPrefix, index, fc.Name)
+	}
+	args := fc.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+	argsJSON, _ := json.Marshal(args)
+
+	tc := ToolCall{
+		ID:   id,
+		Type: "function",
+		Function: &FunctionCall{
+			Name:             fc.Name,
+			Arguments:        string(argsJSON),
+			ThoughtSignature: pt.ThoughtSignature,
+		},
+		Name:             fc.Name,
+		Arguments:        args,
+		ThoughtSignature: pt.ThoughtSignature,
+	}
+	if pt.ThoughtSignature != "" {
+		tc.ExtraContent = &ExtraContent{Google: &GoogleExtra{ThoughtSignature: pt.ThoughtSignature}}
+	}
+	return tc
+}
+
+func mapFinishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+func asFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
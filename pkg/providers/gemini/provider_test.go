@@ -0,0 +1,171 @@
+package gemini
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderChat_BuildsNativeRequest(t *testing.T) {
+	var requestBody map[string]any
+	var path, apiKey string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		apiKey = r.Header.Get("x-goog-api-key")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},` +
+			`"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":8,"candidatesTokenCount":1,` +
+			`"totalTokenCount":9}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithSafetyThreshold("BLOCK_ONLY_HIGH"))
+	resp, err := p.Chat(t.Context(), []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "what is this?", Images: []ImagePart{{MIMEType: "image/png", Data: "aGk="}}},
+		{Role: "assistant", ToolCalls: []ToolCall{{
+			ID:               "call_1",
+			Name:             "lookup",
+			Arguments:        map[string]any{"q": "x"},
+			ThoughtSignature: "sig",
+		}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "result"},
+	}, []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{
+		Name:       "lookup",
+		Parameters: map[string]any{"type": "object"},
+	}}}, "gemini-2.5-flash", map[string]any{"max_tokens": 100, "temperature": 0.2})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if path != "/models/gemini-2.5-flash:generateContent" {
+		t.Errorf("path = %q", path)
+	}
+	if apiKey != "key" {
+		t.Errorf("x-goog-api-key = %q", apiKey)
+	}
+	if _, ok := requestBody["systemInstruction"]; !ok {
+		t.Error("expected systemInstruction")
+	}
+
+	contents := requestBody["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("len(contents) = %d, want 3", len(contents))
+	}
+	userParts := contents[0].(map[string]any)["parts"].([]any)
+	if _, ok := userParts[0].(map[string]any)["inlineData"]; !ok {
+		t.Errorf("expected inlineData part, got %v", userParts[0])
+	}
+	model := contents[1].(map[string]any)
+	if model["role"] != "model" {
+		t.Errorf("assistant role = %v, want model", model["role"])
+	}
+	callPart := model["parts"].([]any)[0].(map[string]any)
+	if callPart["thoughtSignature"] != "sig" {
+		t.Errorf("thoughtSignature not forwarded: %v", callPart)
+	}
+	fr := contents[2].(map[string]any)["parts"].([]any)[0].(map[string]any)["functionResponse"].(map[string]any)
+	if fr["name"] != "lookup" {
+		t.Errorf("functionResponse name = %v, want lookup", fr["name"])
+	}
+
+	if len(requestBody["safetySettings"].([]any)) != len(harmCategories) {
+		t.Errorf("safetySettings = %v", requestBody["safetySettings"])
+	}
+	gc := requestBody["generationConfig"].(map[string]any)
+	if gc["maxOutputTokens"] != float64(100) {
+		t.Errorf("generationConfig = %v", gc)
+	}
+
+	if resp.Content != "ok" || resp.FinishReason != "stop" || resp.Usage.TotalTokens != 9 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestProviderChat_ParsesFunctionCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[` +
+			`{"text":"thinking...","thought":true},` +
+			`{"functionCall":{"name":"get_weather","args":{"city":"SF"}},"thoughtSignature":"abc"}]},` +
+			`"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "weather"}}, nil, "gemini", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || resp.ReasoningContent != "thinking..." {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "get_weather" || tc.Arguments["city"] != "SF" || tc.ThoughtSignature != "abc" {
+		t.Errorf("tool call = %+v", tc)
+	}
+
+	// Generated IDs must not be echoed back to the API.
+	req := p.buildRequest([]Message{{Role: "assistant", ToolCalls: resp.ToolCalls}}, nil, nil)
+	if id := req.Contents[0].Parts[0].FunctionCall.ID; id != "" {
+		t.Errorf("generated ID sent back: %q", id)
+	}
+}
+
+func TestProviderChat_HTTPErrorIncludesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"status":"RESOURCE_EXHAUSTED"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini", nil)
+	if err == nil || !strings.Contains(err.Error(), "Status: 429") {
+		t.Fatalf("Chat() error = %v, want status 429", err)
+	}
+}
+
+func TestBuildRequest_ThinkingConfig(t *testing.T) {
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	if req := NewProvider("key", "", "").buildRequest(msgs, nil, nil); req.GenerationConfig != nil {
+		t.Errorf("GenerationConfig = %+v, want nil without options", req.GenerationConfig)
+	}
+
+	req := NewProvider("key", "", "", WithThinkingBudget(2048)).buildRequest(msgs, nil, nil)
+	tc := req.GenerationConfig.ThinkingConfig
+	if tc == nil || tc.ThinkingBudget != 2048 || !tc.IncludeThoughts {
+		t.Errorf("ThinkingConfig = %+v", tc)
+	}
+
+	data, _ := json.Marshal(NewProvider("key", "", "", WithThinkingBudget(0)).buildRequest(msgs, nil, nil))
+	if !strings.Contains(string(data), `"thinkingConfig":{"thinkingBudget":0}`) {
+		t.Errorf("request = %s, want thinking disabled", data)
+	}
+}
+
+func TestProviderChat_ReportsThoughtTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}],` +
+			`"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"thoughtsTokenCount":40,"totalTokenCount":55}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini-2.5-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Usage == nil || resp.Usage.CompletionTokens != 45 || resp.Usage.ThinkingTokens != 40 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
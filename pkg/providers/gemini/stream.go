@@ -0,0 +1,91 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/sse"
+)
+
+// StreamChat performs a streamGenerateContent call. onDelta is called with
+// each text delta as it arrives; the returned response is the fully
+// assembled candidate, including any function calls.
+func (p *Provider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(delta string),
+) (*LLMResponse, error) {
+	resp, err := p.post(ctx, model, "streamGenerateContent", p.buildRequest(messages, tools, options))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	return readStream(resp.Body, onDelta)
+}
+
+// readStream merges the streamed GenerateContentResponse chunks into a single
+// response. Each chunk carries new parts; the last finish reason and usage
+// metadata win.
+func readStream(r io.Reader, onDelta func(delta string)) (*LLMResponse, error) {
+	var merged generateResponse
+	var parts []part
+	var finishReason string
+
+	err := sse.Read(r, func(_, data string) error {
+		var chunk generateResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+
+		if chunk.UsageMetadata != nil {
+			merged.UsageMetadata = chunk.UsageMetadata
+		}
+		if chunk.PromptFeedback != nil {
+			merged.PromptFeedback = chunk.PromptFeedback
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+
+		cand := chunk.Candidates[0]
+		for _, pt := range cand.Content.Parts {
+			if onDelta != nil && pt.Text != "" && !pt.Thought && pt.FunctionCall == nil {
+				onDelta(pt.Text)
+			}
+			parts = append(parts, pt)
+		}
+		if cand.FinishReason != "" {
+			finishReason = cand.FinishReason
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	if len(parts) > 0 || finishReason != "" {
+		merged.Candidates = []candidate{{
+			Content:      content{Role: "model", Parts: parts},
+			FinishReason: finishReason,
+		}}
+	}
+	return merged.toLLMResponse(), nil
+}
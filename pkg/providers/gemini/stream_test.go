@@ -0,0 +1,39 @@
+package gemini
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderStreamChat_MergesChunks(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(
+			`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}` + "\n\n" +
+				`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]}}]}` + "\n\n" +
+				`data: {"candidates":[{"content":{"role":"model","parts":[{"text":""}]},"finishReason":"MAX_TOKENS"}],` +
+				`"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	var deltas []string
+	resp, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini", nil,
+		func(delta string) { deltas = append(deltas, delta) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if query != "alt=sse" {
+		t.Errorf("query = %q, want alt=sse", query)
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("deltas = %v", deltas)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != "length" || resp.Usage.TotalTokens != 5 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
@@ -0,0 +1,73 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"time"
+
+	geminiprovider "github.com/sipeed/picoclaw/pkg/providers/gemini"
+)
+
+// GeminiProvider speaks the native Gemini generateContent API.
+type GeminiProvider struct {
+	delegate *geminiprovider.Provider
+}
+
+func NewGeminiProvider(
+	apiKey, apiBase, proxy, safetyThreshold string,
+	requestTimeoutSeconds int,
+	opts ...geminiprovider.Option,
+) *GeminiProvider {
+	return &GeminiProvider{
+		delegate: geminiprovider.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			append([]geminiprovider.Option{
+				geminiprovider.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+				geminiprovider.WithSafetyThreshold(safetyThreshold),
+			}, opts...)...,
+		),
+	}
+}
+
+func (p *GeminiProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *GeminiProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+}
+
+func (p *GeminiProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *GeminiProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *GeminiProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
@@ -0,0 +1,86 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"sync"
+
+	copilotprovider "github.com/sipeed/picoclaw/pkg/providers/copilot"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+// GitHubCopilotProvider talks to the Copilot chat API with the login saved
+// by "picoclaw auth github-copilot", or with a GitHub token from api_key.
+type GitHubCopilotProvider struct {
+	delegate *copilotprovider.Provider
+}
+
+func NewGitHubCopilotProvider(
+	githubToken, apiBase, proxy string,
+	opts ...openai_compat.Option,
+) *GitHubCopilotProvider {
+	return &GitHubCopilotProvider{
+		delegate: copilotprovider.NewProvider(copilotAuthFor(githubToken), apiBase, proxy, opts...),
+	}
+}
+
+var (
+	copilotAuthsMu sync.Mutex
+	copilotAuths   = make(map[string]*copilotprovider.Auth)
+)
+
+// copilotAuthFor returns the process-wide Auth for githubToken (empty for
+// the saved login), so model_list entries share one Copilot token and
+// refresh it once.
+func copilotAuthFor(githubToken string) *copilotprovider.Auth {
+	copilotAuthsMu.Lock()
+	defer copilotAuthsMu.Unlock()
+	if a, ok := copilotAuths[githubToken]; ok {
+		return a
+	}
+	store := copilotprovider.NewTokenStore(copilotprovider.DefaultTokenPath())
+	a := copilotprovider.NewAuth(store, githubToken, nil)
+	copilotAuths[githubToken] = a
+	return a
+}
+
+func (p *GitHubCopilotProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *GitHubCopilotProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+}
+
+func (p *GitHubCopilotProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *GitHubCopilotProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *GitHubCopilotProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *GitHubCopilotProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
@@ -0,0 +1,82 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// ErrHealthCheckUnsupported is returned by Ping and ListModels when the
+// provider has no way to check its endpoint (e.g. CLI-based providers).
+var ErrHealthCheckUnsupported = errors.New("provider does not support health checks")
+
+// Ping checks provider's endpoint and credentials.
+func Ping(ctx context.Context, provider LLMProvider) error {
+	if hp, ok := provider.(HealthCheckProvider); ok {
+		return hp.Ping(ctx)
+	}
+	return ErrHealthCheckUnsupported
+}
+
+// ListModels returns the models provider's endpoint serves.
+func ListModels(ctx context.Context, provider LLMProvider) ([]string, error) {
+	if hp, ok := provider.(HealthCheckProvider); ok {
+		return hp.ListModels(ctx)
+	}
+	return nil, ErrHealthCheckUnsupported
+}
+
+// ModelCheck is the result of checking one model_list entry.
+type ModelCheck struct {
+	ModelName string
+	Model     string
+	Latency   time.Duration
+	Err       error // nil when reachable; ErrHealthCheckUnsupported when skipped
+	// Listed reports whether the model ID appeared in the endpoint's model
+	// list; false also when the endpoint can't list models.
+	Listed bool
+	Models []string
+}
+
+// CheckModel creates the provider for a model_list entry and pings it. The
+// entry's fallbacks are not checked; they are separate entries.
+func CheckModel(ctx context.Context, mc *config.ModelConfig) ModelCheck {
+	check := ModelCheck{ModelName: mc.ModelName, Model: mc.Model}
+
+	single := *mc
+	single.FallbackConfigs = nil
+	single.Record = ""
+	provider, modelID, err := CreateProviderFromConfig(&single)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	if sp, ok := provider.(StatefulProvider); ok {
+		defer sp.Close()
+	}
+
+	start := time.Now()
+	if err := Ping(ctx, provider); err != nil {
+		check.Err = err
+		if !errors.Is(err, ErrHealthCheckUnsupported) {
+			check.Err = fmt.Errorf("ping: %w", err)
+		}
+		return check
+	}
+	check.Latency = time.Since(start)
+
+	if models, err := ListModels(ctx, provider); err == nil {
+		check.Models = models
+		check.Listed = slices.Contains(models, modelID)
+	}
+	return check
+}
@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestCheckModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			http.Error(w, "invalid key", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"llama3"}]}`))
+	}))
+	defer server.Close()
+
+	ok := CheckModel(context.Background(), &config.ModelConfig{
+		ModelName: "local", Model: "vllm/llama3", APIBase: server.URL, APIKey: "good", RPM: 10,
+	})
+	if ok.Err != nil || !ok.Listed || len(ok.Models) != 1 {
+		t.Errorf("check = %+v", ok)
+	}
+
+	bad := CheckModel(context.Background(), &config.ModelConfig{
+		ModelName: "local", Model: "vllm/llama3", APIBase: server.URL, APIKey: "bad",
+	})
+	if bad.Err == nil || errors.Is(bad.Err, ErrHealthCheckUnsupported) {
+		t.Errorf("check with bad key = %+v", bad)
+	}
+
+	unsupported := CheckModel(context.Background(), &config.ModelConfig{
+		ModelName: "m", Model: "mock/" + writeMockScript(t),
+	})
+	if !errors.Is(unsupported.Err, ErrHealthCheckUnsupported) {
+		t.Errorf("mock check err = %v, want ErrHealthCheckUnsupported", unsupported.Err)
+	}
+}
@@ -27,17 +27,22 @@ func NewHTTPProviderWithMaxTokensField(apiKey, apiBase, proxy, maxTokensField st
 	return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(apiKey, apiBase, proxy, maxTokensField, 0)
 }
 
+// NewHTTPProviderWithMaxTokensFieldAndRequestTimeout creates an HTTPProvider.
+// Extra options (e.g. openai_compat.WithMaxAttempts) are applied last.
 func NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
 	apiKey, apiBase, proxy, maxTokensField string,
 	requestTimeoutSeconds int,
+	opts ...openai_compat.Option,
 ) *HTTPProvider {
 	return &HTTPProvider{
 		delegate: openai_compat.NewProvider(
 			apiKey,
 			apiBase,
 			proxy,
-			openai_compat.WithMaxTokensField(maxTokensField),
-			openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+			append([]openai_compat.Option{
+				openai_compat.WithMaxTokensField(maxTokensField),
+				openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+			}, opts...)...,
 		),
 	}
 }
@@ -52,6 +57,29 @@ func (p *HTTPProvider) Chat(
 	return p.delegate.Chat(ctx, messages, tools, model, options)
 }
 
+func (p *HTTPProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+}
+
 func (p *HTTPProvider) GetDefaultModel() string {
 	return ""
 }
+
+func (p *HTTPProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *HTTPProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *HTTPProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
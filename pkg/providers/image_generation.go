@@ -0,0 +1,91 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/a1111"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/stability"
+)
+
+type (
+	ImageRequest   = protocoltypes.ImageRequest
+	GeneratedImage = protocoltypes.GeneratedImage
+)
+
+// ImageGenerationProvider creates images from text prompts. The model is
+// fixed when the provider is created (see
+// CreateImageGenerationProviderFromConfig).
+type ImageGenerationProvider interface {
+	GenerateImages(ctx context.Context, req ImageRequest) ([]GeneratedImage, error)
+}
+
+// imageFunc generates images with an explicit model.
+type imageFunc func(ctx context.Context, req ImageRequest, model string) ([]GeneratedImage, error)
+
+// modelImager binds an imageFunc to a model.
+type modelImager struct {
+	generate imageFunc
+	model    string
+}
+
+func (m *modelImager) GenerateImages(ctx context.Context, req ImageRequest) ([]GeneratedImage, error) {
+	return m.generate(ctx, req, m.model)
+}
+
+// CreateImageGenerationProviderFromConfig creates an image generation
+// provider for the model_list entry cfg.
+// Supported protocols: openai, stability, a1111
+func CreateImageGenerationProviderFromConfig(cfg *config.ModelConfig) (ImageGenerationProvider, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	protocol, modelID := ExtractProtocol(cfg.Model)
+	timeout := time.Duration(cfg.RequestTimeout) * time.Second
+
+	switch protocol {
+	case "openai":
+		if cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, fmt.Errorf("api_key or api_base is required for protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = "https://api.openai.com/v1"
+		}
+		p := openai_compat.NewProvider(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			openai_compat.WithRequestTimeout(timeout),
+			openai_compat.WithMaxAttempts(cfg.MaxAttempts),
+		)
+		return &modelImager{generate: p.GenerateImages, model: modelID}, nil
+
+	case "stability":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api_key is required for protocol %q", protocol)
+		}
+		p := stability.NewProvider(cfg.APIKey, cfg.APIBase, cfg.Proxy, stability.WithRequestTimeout(timeout))
+		return &modelImager{generate: p.GenerateImages, model: modelID}, nil
+
+	case "a1111":
+		p := a1111.NewProvider(cfg.APIKey, cfg.APIBase, cfg.Proxy, a1111.WithRequestTimeout(timeout))
+		return &modelImager{generate: p.GenerateImages, model: modelID}, nil
+
+	default:
+		return nil, fmt.Errorf("protocol %q does not support image generation (model %q)", protocol, cfg.Model)
+	}
+}
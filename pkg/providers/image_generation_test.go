@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestCreateImageGenerationProviderFromConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *config.ModelConfig
+		wantModel string
+		wantErr   bool
+	}{
+		{"openai", &config.ModelConfig{Model: "openai/gpt-image-1", APIKey: "k"}, "gpt-image-1", false},
+		{"stability", &config.ModelConfig{Model: "stability/sd3.5-large", APIKey: "k"}, "sd3.5-large", false},
+		{"a1111 without key", &config.ModelConfig{Model: "a1111/default"}, "default", false},
+		{"openai without credentials", &config.ModelConfig{Model: "openai/dall-e-3"}, "", true},
+		{"stability without key", &config.ModelConfig{Model: "stability/core"}, "", true},
+		{"unsupported protocol", &config.ModelConfig{Model: "anthropic/claude", APIKey: "k"}, "", true},
+		{"nil config", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := CreateImageGenerationProviderFromConfig(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateImageGenerationProviderFromConfig() error = %v", err)
+			}
+			if m, ok := p.(*modelImager); !ok || m.model != tt.wantModel {
+				t.Errorf("provider = %#v, want model %q", p, tt.wantModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultKeyCooldown = time.Minute
+
+// KeyRotationProvider spreads requests over providers that differ only in
+// their API key, round-robin. A key answered with a rate limit or an auth
+// error is parked for a cooldown window and the request moves on to the
+// next key, so free-tier keys with small per-key quotas add up.
+type KeyRotationProvider struct {
+	mu       sync.Mutex
+	keys     []rotatingKey
+	next     int
+	cooldown time.Duration
+	nowFunc  func() time.Time // for testing
+}
+
+type rotatingKey struct {
+	provider    LLMProvider
+	parkedUntil time.Time
+}
+
+// NewKeyRotationProvider creates a KeyRotationProvider over providers, one
+// per key. A cooldown of zero uses the default of one minute.
+func NewKeyRotationProvider(providers []LLMProvider, cooldown time.Duration) *KeyRotationProvider {
+	if cooldown <= 0 {
+		cooldown = defaultKeyCooldown
+	}
+	keys := make([]rotatingKey, len(providers))
+	for i, p := range providers {
+		keys[i] = rotatingKey{provider: p}
+	}
+	return &KeyRotationProvider{keys: keys, cooldown: cooldown, nowFunc: time.Now}
+}
+
+func (p *KeyRotationProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.execute(func(provider LLMProvider) (*LLMResponse, error) {
+		return provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+// StreamChat streams when the key's provider supports it and otherwise
+// falls back to Chat.
+func (p *KeyRotationProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.execute(func(provider LLMProvider) (*LLMResponse, error) {
+		if sp, ok := provider.(StreamingProvider); ok {
+			return sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+		}
+		return provider.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+func (p *KeyRotationProvider) GetDefaultModel() string {
+	return p.keys[0].provider.GetDefaultModel()
+}
+
+func (p *KeyRotationProvider) SupportsStructuredOutput() bool {
+	sp, ok := p.keys[0].provider.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+// Ping checks the first key.
+func (p *KeyRotationProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.keys[0].provider)
+}
+
+// ListModels lists the models served to the first key.
+func (p *KeyRotationProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.keys[0].provider)
+}
+
+// Close releases the per-key providers that hold resources.
+func (p *KeyRotationProvider) Close() {
+	for _, k := range p.keys {
+		if sp, ok := k.provider.(StatefulProvider); ok {
+			sp.Close()
+		}
+	}
+}
+
+// execute calls the next available key, moving on to the following one
+// while keys are rate limited or rejected. When every key is parked, the
+// one whose cooldown ends first is tried anyway rather than failing
+// without a request.
+func (p *KeyRotationProvider) execute(call func(provider LLMProvider) (*LLMResponse, error)) (*LLMResponse, error) {
+	var lastErr error
+	for attempt := 0; attempt < len(p.keys); attempt++ {
+		i := p.pick(attempt == 0)
+		if i < 0 {
+			break
+		}
+		resp, err := call(p.keys[i].provider)
+		if err == nil {
+			return resp, nil
+		}
+		fe := ClassifyError(err, "", "")
+		if fe == nil || (fe.Reason != FailoverRateLimit && fe.Reason != FailoverAuth) {
+			return nil, err
+		}
+		p.park(i, fe.RetryAfter)
+		logger.WarnCF("provider", "API key parked", map[string]any{
+			"key":    i + 1,
+			"keys":   len(p.keys),
+			"reason": string(fe.Reason),
+		})
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// pick returns the next key in round-robin order that is not parked. With
+// fallback set it returns the key whose cooldown ends first when all are
+// parked; otherwise it returns -1.
+func (p *KeyRotationProvider) pick(fallback bool) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.nowFunc()
+	soonest := -1
+	for n := 0; n < len(p.keys); n++ {
+		i := (p.next + n) % len(p.keys)
+		if !p.keys[i].parkedUntil.After(now) {
+			p.next = (i + 1) % len(p.keys)
+			return i
+		}
+		if soonest < 0 || p.keys[i].parkedUntil.Before(p.keys[soonest].parkedUntil) {
+			soonest = i
+		}
+	}
+	if !fallback {
+		return -1
+	}
+	p.next = (soonest + 1) % len(p.keys)
+	return soonest
+}
+
+// park takes key i out of rotation for the cooldown window, or for the
+// server's Retry-After hint when that is longer.
+func (p *KeyRotationProvider) park(i int, retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d := max(p.cooldown, retryAfter)
+	p.keys[i].parkedUntil = p.nowFunc().Add(d)
+}
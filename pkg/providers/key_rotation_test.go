@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestKeyRotationProvider_RoundRobin(t *testing.T) {
+	a, b := &scriptedProvider{}, &scriptedProvider{}
+	kp := NewKeyRotationProvider([]LLMProvider{a, b}, 0)
+
+	for i := 0; i < 4; i++ {
+		if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if a.calls != 2 || b.calls != 2 {
+		t.Errorf("calls a=%d b=%d, want 2 and 2", a.calls, b.calls)
+	}
+}
+
+func TestKeyRotationProvider_ParksRateLimitedKey(t *testing.T) {
+	limited := &scriptedProvider{err: NewAPIError(429, "quota exceeded")}
+	ok := &scriptedProvider{}
+	kp := NewKeyRotationProvider([]LLMProvider{limited, ok}, time.Minute)
+	now := time.Unix(1000, 0)
+	kp.nowFunc = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if limited.calls != 1 || ok.calls != 3 {
+		t.Errorf("calls limited=%d ok=%d, want 1 and 3", limited.calls, ok.calls)
+	}
+
+	// Once the cooldown ends the key is back in rotation.
+	now = now.Add(time.Minute)
+	limited.err = nil
+	for i := 0; i < 2; i++ {
+		if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if limited.calls != 2 {
+		t.Errorf("limited calls = %d after cooldown, want 2", limited.calls)
+	}
+}
+
+func TestKeyRotationProvider_AllKeysRejected(t *testing.T) {
+	a := &scriptedProvider{err: NewAPIError(401, "invalid api key")}
+	b := &scriptedProvider{err: NewAPIError(401, "invalid api key")}
+	kp := NewKeyRotationProvider([]LLMProvider{a, b}, time.Minute)
+
+	if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err == nil {
+		t.Fatal("expected error when every key is rejected")
+	}
+	if a.calls != 1 || b.calls != 1 {
+		t.Errorf("calls a=%d b=%d, want 1 and 1", a.calls, b.calls)
+	}
+
+	// With every key parked the request still goes out on one of them.
+	if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if a.calls+b.calls != 3 {
+		t.Errorf("total calls = %d, want 3", a.calls+b.calls)
+	}
+}
+
+func TestKeyRotationProvider_OtherErrorsReturned(t *testing.T) {
+	a := &scriptedProvider{err: NewAPIError(400, "invalid request format")}
+	b := &scriptedProvider{}
+	kp := NewKeyRotationProvider([]LLMProvider{a, b}, 0)
+
+	if _, err := kp.Chat(context.Background(), nil, nil, "m", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if b.calls != 0 {
+		t.Errorf("second key called %d times on a request error", b.calls)
+	}
+}
+
+func TestCreateProviderFromConfig_APIKeyList(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Header.Get("Authorization") == "Bearer key-1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"rate limit"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	var cfg config.ModelConfig
+	data := `{"model_name":"m","model":"groq/llama-3.3-70b","api_base":"` + server.URL +
+		`","api_key":["key-1","key-2"],"max_attempts":1}`
+	if err := cfg.UnmarshalJSON([]byte(data)); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	provider, _, err := CreateProviderFromConfig(&cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*KeyRotationProvider); !ok {
+		t.Fatalf("provider = %T, want *KeyRotationProvider", provider)
+	}
+	resp, err := provider.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "llama-3.3-70b", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hi" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if len(seen) != 2 || seen[0] != "Bearer key-1" || seen[1] != "Bearer key-2" {
+		t.Errorf("Authorization headers = %v", seen)
+	}
+}
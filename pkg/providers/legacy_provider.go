@@ -7,8 +7,10 @@ package providers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
 )
 
 // CreateProvider creates a provider based on the configuration.
@@ -18,22 +20,8 @@ import (
 func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 	model := cfg.Agents.Defaults.GetModelName()
 
-	// Ensure model_list is populated from providers config if needed
-	// This handles two cases:
-	// 1. ModelList is empty - convert all providers
-	// 2. ModelList has some entries but not all providers - merge missing ones
-	if cfg.HasProvidersConfig() {
-		providerModels := config.ConvertProvidersToModelList(cfg)
-		existingModelNames := make(map[string]bool)
-		for _, m := range cfg.ModelList {
-			existingModelNames[m.ModelName] = true
-		}
-		for _, pm := range providerModels {
-			if !existingModelNames[pm.ModelName] {
-				cfg.ModelList = append(cfg.ModelList, pm)
-			}
-		}
-	}
+	ConfigureHTTP(cfg.HTTP)
+	MergeLegacyProviders(cfg)
 
 	// Must have model_list at this point
 	if len(cfg.ModelList) == 0 {
@@ -50,6 +38,7 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 	if modelCfg.Workspace == "" {
 		modelCfg.Workspace = cfg.WorkspacePath()
 	}
+	modelCfg.EncryptionKey = cfg.Storage.EncryptionKey
 
 	// Use factory to create provider
 	provider, modelID, err := CreateProviderFromConfig(modelCfg)
@@ -59,3 +48,34 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 
 	return provider, modelID, nil
 }
+
+// ConfigureHTTP applies the http section of the config to the connection
+// pool providers share. Providers created earlier keep their transports.
+func ConfigureHTTP(cfg config.HTTPConfig) {
+	transport.Configure(transport.Pool{
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleConnTimeout) * time.Second,
+		DisableHTTP2:        cfg.DisableHTTP2,
+	})
+}
+
+// MergeLegacyProviders ensures cfg.ModelList is populated from the old
+// providers config if needed.
+func MergeLegacyProviders(cfg *config.Config) {
+	// This handles two cases:
+	// 1. ModelList is empty - convert all providers
+	// 2. ModelList has some entries but not all providers - merge missing ones
+	if cfg.HasProvidersConfig() {
+		providerModels := config.ConvertProvidersToModelList(cfg)
+		existingModelNames := make(map[string]bool)
+		for _, m := range cfg.ModelList {
+			existingModelNames[m.ModelName] = true
+		}
+		for _, pm := range providerModels {
+			if !existingModelNames[pm.ModelName] {
+				cfg.ModelList = append(cfg.ModelList, pm)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package llamacpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonRules is the GBNF for generic JSON values, shared by the tool-call
+// grammar. Tool arguments are constrained to valid JSON objects; their
+// per-tool schemas are described to the model in the system prompt.
+const jsonRules = `object ::= "{" ws ( string ws ":" ws value ( ws "," ws string ws ":" ws value )* )? ws "}"
+array ::= "[" ws ( value ( ws "," ws value )* )? ws "]"
+value ::= object | array | string | number | "true" | "false" | "null"
+string ::= "\"" ( [^"\\\x7F\x00-\x1F] | "\\" ( ["\\/bfnrt] | "u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] ) )* "\""
+number ::= "-"? ( "0" | [1-9] [0-9]* ) ( "." [0-9]+ )? ( [eE] [-+]? [0-9]+ )?
+ws ::= ( [ \t\n] ws )?
+`
+
+// toolCallGrammar builds a GBNF grammar that forces the model to answer with
+// either {"content": "..."} or {"tool_calls": [{"name": ..., "arguments": {...}}]},
+// where name is one of the declared tools.
+func toolCallGrammar(tools []ToolDefinition) string {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		quoted, _ := json.Marshal(t.Function.Name)
+		names = append(names, gbnfLiteral(string(quoted)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`root ::= "{" ws ( calls | answer ) ws "}"` + "\n")
+	sb.WriteString(`answer ::= "\"content\"" ws ":" ws string` + "\n")
+	sb.WriteString(`calls ::= "\"tool_calls\"" ws ":" ws "[" ws call ( ws "," ws call )* ws "]"` + "\n")
+	sb.WriteString(`call ::= "{" ws "\"name\"" ws ":" ws name ws "," ws "\"arguments\"" ws ":" ws object ws "}"` + "\n")
+	sb.WriteString("name ::= " + strings.Join(names, " | ") + "\n")
+	sb.WriteString(jsonRules)
+	return sb.String()
+}
+
+// gbnfLiteral quotes s as a GBNF string literal.
+func gbnfLiteral(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// toolPrompt describes the available tools and the response format enforced
+// by toolCallGrammar. It is appended to the system prompt.
+func toolPrompt(tools []ToolDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("\n\n## Tool calling\n\n")
+	sb.WriteString("You can call these tools. Arguments must match the JSON schema.\n\n")
+	for _, t := range tools {
+		schema, _ := json.Marshal(t.Function.Parameters)
+		fmt.Fprintf(&sb, "- %s: %s\n  parameters: %s\n", t.Function.Name, t.Function.Description, schema)
+	}
+	sb.WriteString("\nAlways reply with a single JSON object, either\n")
+	sb.WriteString(`{"tool_calls": [{"name": "<tool>", "arguments": {...}}]}` + "\n")
+	sb.WriteString("to call tools, or\n")
+	sb.WriteString(`{"content": "<your answer>"}` + "\n")
+	sb.WriteString("to answer the user.")
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+package llamacpp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderPing_LoadingModel(t *testing.T) {
+	loading := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if loading {
+				http.Error(w, `{"error":{"message":"Loading model"}}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"status":"ok"}`))
+		case "/v1/models":
+			w.Write([]byte(`{"data":[{"id":"qwen2.5-1.5b-instruct-q4_k_m.gguf"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	if err := p.Ping(t.Context()); err == nil {
+		t.Fatal("Ping() expected error while the model is loading")
+	}
+	loading = false
+	if err := p.Ping(t.Context()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	models, err := p.ListModels(t.Context())
+	if err != nil || len(models) != 1 {
+		t.Errorf("ListModels() = %v, %v", models, err)
+	}
+}
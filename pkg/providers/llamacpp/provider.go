@@ -0,0 +1,353 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package llamacpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+
+	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+)
+
+const (
+	DefaultAPIBase = "http://localhost:8080"
+
+	defaultRequestTimeout = 300 * time.Second
+)
+
+// Provider talks to the llama.cpp server's native API: /apply-template renders
+// the model's chat template and /completion generates, which accepts a GBNF
+// grammar. When tools are passed and no explicit grammar is configured, a
+// grammar constraining output to well-formed tool calls is generated, so small
+// local models can't emit malformed JSON.
+type Provider struct {
+	apiKey     string
+	apiBase    string
+	grammar    string
+	httpClient *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithGrammar sets a GBNF grammar applied to every completion. It overrides
+// the generated tool-call grammar.
+func WithGrammar(grammar string) Option {
+	return func(p *Provider) {
+		p.grammar = grammar
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("llamacpp: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/v1"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+// Chat renders messages with the server's chat template and runs a
+// completion. The model argument is ignored: llama.cpp serves one model.
+// A per-call grammar may be passed as options["grammar"]; without one, a
+// response schema (options["response_schema"]) is sent as json_schema.
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	grammar := p.grammar
+	if g, ok := options["grammar"].(string); ok && g != "" {
+		grammar = g
+	}
+	useToolGrammar := len(tools) > 0 && grammar == ""
+	if useToolGrammar {
+		grammar = toolCallGrammar(tools)
+	}
+
+	var applied struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := p.post(ctx, "/apply-template", map[string]any{
+		"messages": templateMessages(messages, tools),
+	}, &applied); err != nil {
+		return nil, err
+	}
+
+	req := map[string]any{
+		"prompt":       applied.Prompt,
+		"cache_prompt": true,
+	}
+	if grammar != "" {
+		req["grammar"] = grammar
+	} else if schema := protocoltypes.ResponseSchemaOption(options); schema != nil {
+		req["json_schema"] = schema.Schema
+	}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		req["n_predict"] = maxTokens
+	}
+	if temperature, ok := asFloat(options["temperature"]); ok {
+		req["temperature"] = temperature
+	}
+	if stop := protocoltypes.StopOption(options); len(stop) > 0 {
+		req["stop"] = stop
+	}
+
+	var completion struct {
+		Content         string `json:"content"`
+		StopType        string `json:"stop_type"`
+		TokensPredicted int    `json:"tokens_predicted"`
+		TokensEvaluated int    `json:"tokens_evaluated"`
+	}
+	if err := p.post(ctx, "/completion", req, &completion); err != nil {
+		return nil, err
+	}
+
+	resp := &LLMResponse{
+		Content:      completion.Content,
+		FinishReason: "stop",
+		Usage: &UsageInfo{
+			PromptTokens:     completion.TokensEvaluated,
+			CompletionTokens: completion.TokensPredicted,
+			TotalTokens:      completion.TokensEvaluated + completion.TokensPredicted,
+		},
+	}
+	if completion.StopType == "limit" {
+		resp.FinishReason = "length"
+	}
+	if useToolGrammar {
+		parseToolOutput(resp)
+	}
+	return resp, nil
+}
+
+// post sends a JSON request to path and decodes the JSON response into out.
+func (p *Provider) post(ctx context.Context, path string, body any, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return protocoltypes.NewAPIError(resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+type templateMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// templateMessages flattens picoclaw messages into plain role/content pairs,
+// since chat templates differ in (or lack) native tool support. Tool calls
+// are rendered in the same JSON format the tool grammar produces, and tool
+// results become user turns.
+func templateMessages(messages []Message, tools []ToolDefinition) []templateMessage {
+	out := make([]templateMessage, 0, len(messages))
+	callNames := make(map[string]string)
+	systemDone := false
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			content := msg.Content
+			if len(tools) > 0 && !systemDone {
+				content += toolPrompt(tools)
+			}
+			systemDone = true
+			out = append(out, templateMessage{Role: "system", Content: content})
+
+		case "assistant":
+			content := msg.Content
+			if len(msg.ToolCalls) > 0 {
+				content = renderToolCalls(msg.ToolCalls, callNames)
+			}
+			out = append(out, templateMessage{Role: "assistant", Content: content})
+
+		case "tool":
+			out = append(out, templateMessage{
+				Role:    "user",
+				Content: fmt.Sprintf("[tool result: %s]\n%s", callNames[msg.ToolCallID], msg.Content),
+			})
+
+		default:
+			out = append(out, templateMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+
+	if len(tools) > 0 && !systemDone {
+		out = append([]templateMessage{{Role: "system", Content: strings.TrimSpace(toolPrompt(tools))}}, out...)
+	}
+	return out
+}
+
+func renderToolCalls(calls []ToolCall, callNames map[string]string) string {
+	type renderedCall struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	rendered := make([]renderedCall, 0, len(calls))
+	for _, tc := range calls {
+		name, args := tc.Name, tc.Arguments
+		if tc.Function != nil {
+			if name == "" {
+				name = tc.Function.Name
+			}
+			if args == nil && tc.Function.Arguments != "" {
+				_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
+			}
+		}
+		if args == nil {
+			args = map[string]any{}
+		}
+		callNames[tc.ID] = name
+		rendered = append(rendered, renderedCall{Name: name, Arguments: args})
+	}
+	data, _ := json.Marshal(map[string]any{"tool_calls": rendered})
+	return string(data)
+}
+
+// parseToolOutput decodes grammar-constrained output into content or tool
+// calls. Output that doesn't parse (e.g. truncated at the token limit) is
+// left as plain content.
+func parseToolOutput(resp *LLMResponse) {
+	var out struct {
+		Content   *string `json:"content"`
+		ToolCalls []struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		} `json:"tool_calls"`
+	}
+	if err := json.Unmarshal([]byte(resp.Content), &out); err != nil {
+		return
+	}
+
+	if len(out.ToolCalls) == 0 {
+		if out.Content != nil {
+			resp.Content = *out.Content
+		}
+		return
+	}
+
+	resp.Content = ""
+	resp.FinishReason = "tool_calls"
+	for i, call := range out.ToolCalls {
+		args := call.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		argsJSON, _ := json.Marshal(args)
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d_%d", time.Now().UnixNano(), i),
+			Type:      "function",
+			Function:  &FunctionCall{Name: call.Name, Arguments: string(argsJSON)},
+			Name:      call.Name,
+			Arguments: args,
+		})
+	}
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+func asFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
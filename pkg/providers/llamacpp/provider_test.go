@@ -0,0 +1,130 @@
+package llamacpp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, completion string, requests map[string]map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests[r.URL.Path] = body
+		switch r.URL.Path {
+		case "/apply-template":
+			w.Write([]byte(`{"prompt":"<rendered>"}`))
+		case "/completion":
+			resp, _ := json.Marshal(map[string]any{
+				"content":          completion,
+				"stop_type":        "eos",
+				"tokens_predicted": 5,
+				"tokens_evaluated": 20,
+			})
+			w.Write(resp)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func TestProviderChat_ToolGrammarAndParsing(t *testing.T) {
+	requests := map[string]map[string]any{}
+	server := newTestServer(t, `{"tool_calls": [{"name": "get_weather", "arguments": {"city": "SF"}}]}`, requests)
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", "")
+	resp, err := p.Chat(t.Context(), []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "weather?"},
+	}, []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{
+		Name:        "get_weather",
+		Description: "Get the weather",
+		Parameters:  map[string]any{"type": "object"},
+	}}}, "", map[string]any{"max_tokens": 64})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	completion := requests["/completion"]
+	if completion["prompt"] != "<rendered>" || completion["n_predict"] != float64(64) {
+		t.Errorf("completion request = %v", completion)
+	}
+	grammar, _ := completion["grammar"].(string)
+	if !strings.Contains(grammar, `name ::= "\"get_weather\""`) {
+		t.Errorf("grammar does not restrict tool names:\n%s", grammar)
+	}
+	msgs := requests["/apply-template"]["messages"].([]any)
+	system := msgs[0].(map[string]any)["content"].(string)
+	if !strings.Contains(system, "get_weather") {
+		t.Errorf("system prompt does not describe tools: %q", system)
+	}
+
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.ToolCalls[0].Name != "get_weather" || resp.ToolCalls[0].Arguments["city"] != "SF" {
+		t.Errorf("tool call = %+v", resp.ToolCalls[0])
+	}
+	if resp.Usage.TotalTokens != 25 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderChat_ContentAnswerUnwrapped(t *testing.T) {
+	requests := map[string]map[string]any{}
+	server := newTestServer(t, `{"content": "It is sunny."}`, requests)
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "weather?"}},
+		[]ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "get_weather"}}}, "", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "It is sunny." || len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestProviderChat_ExplicitGrammarWithoutTools(t *testing.T) {
+	requests := map[string]map[string]any{}
+	server := newTestServer(t, "yes", requests)
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "", WithGrammar(`root ::= "yes" | "no"`))
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "ok?"}}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if requests["/completion"]["grammar"] != `root ::= "yes" | "no"` {
+		t.Errorf("grammar = %v", requests["/completion"]["grammar"])
+	}
+	if resp.Content != "yes" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+}
+
+func TestTemplateMessages_RendersToolRoundTrip(t *testing.T) {
+	msgs := templateMessages([]Message{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "get_weather", Arguments: map[string]any{"city": "SF"}}}},
+		{Role: "tool", ToolCallID: "c1", Content: "sunny"},
+	}, []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "get_weather"}}})
+
+	if len(msgs) != 4 || msgs[0].Role != "system" {
+		t.Fatalf("messages = %+v", msgs)
+	}
+	if !strings.Contains(msgs[2].Content, `"tool_calls"`) {
+		t.Errorf("assistant tool call not rendered: %q", msgs[2].Content)
+	}
+	if msgs[3].Role != "user" || !strings.Contains(msgs[3].Content, "get_weather") {
+		t.Errorf("tool result = %+v", msgs[3])
+	}
+}
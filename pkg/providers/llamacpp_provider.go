@@ -0,0 +1,57 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"time"
+
+	llamacppprovider "github.com/sipeed/picoclaw/pkg/providers/llamacpp"
+)
+
+// LlamaCppProvider talks to a llama.cpp server through its native
+// /completion API, with GBNF grammar-constrained tool calls.
+type LlamaCppProvider struct {
+	delegate *llamacppprovider.Provider
+}
+
+func NewLlamaCppProvider(apiKey, apiBase, proxy, grammar string, requestTimeoutSeconds int) *LlamaCppProvider {
+	return &LlamaCppProvider{
+		delegate: llamacppprovider.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			llamacppprovider.WithGrammar(grammar),
+			llamacppprovider.WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+		),
+	}
+}
+
+func (p *LlamaCppProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *LlamaCppProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *LlamaCppProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *LlamaCppProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *LlamaCppProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
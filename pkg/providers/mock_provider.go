@@ -0,0 +1,181 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+)
+
+// MockScript drives a MockProvider. Rules are tried in order against the
+// latest message; the first match answers.
+//
+//	{
+//	  "rules": [
+//	    {"pattern": "(?i)weather in (\\w+)", "tool_calls": [{"name": "web_search", "arguments": {"query": "weather $1"}}]},
+//	    {"on": "tool", "pattern": ".", "response": "Here is what I found."},
+//	    {"pattern": "(?i)^hi|hello", "response": "Hello! I'm running offline."}
+//	  ],
+//	  "default": "Sorry, I don't know that one."
+//	}
+type MockScript struct {
+	Rules   []MockRule `json:"rules"`
+	Default string     `json:"default,omitempty"`
+}
+
+// MockRule answers messages matching Pattern (a Go regexp). Response and
+// string tool arguments may reference capture groups as $1 or ${name}.
+type MockRule struct {
+	// On selects which message the rule matches: "user" (default) for a new
+	// user message, "tool" for a tool result following a tool call.
+	On        string         `json:"on,omitempty"`
+	Pattern   string         `json:"pattern"`
+	Response  string         `json:"response,omitempty"`
+	ToolCalls []MockToolCall `json:"tool_calls,omitempty"`
+
+	re *regexp.Regexp
+}
+
+type MockToolCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+const defaultMockResponse = "mock: no rule matched"
+
+// MockProvider answers from a MockScript instead of a model, for offline
+// demos and deterministic agent-loop tests. Used with the "mock/<script>"
+// model.
+type MockProvider struct {
+	script MockScript
+	calls  atomic.Int64
+}
+
+// NewMockProvider compiles script's patterns.
+func NewMockProvider(script MockScript) (*MockProvider, error) {
+	for i := range script.Rules {
+		r := &script.Rules[i]
+		switch r.On {
+		case "":
+			r.On = "user"
+		case "user", "tool":
+		default:
+			return nil, fmt.Errorf("mock rule %d: on must be \"user\" or \"tool\", got %q", i, r.On)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("mock rule %d: %w", i, err)
+		}
+		r.re = re
+	}
+	if script.Default == "" {
+		script.Default = defaultMockResponse
+	}
+	return &MockProvider{script: script}, nil
+}
+
+// LoadMockProvider reads a JSON MockScript from path.
+func LoadMockProvider(path string) (*MockProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mock script: %w", err)
+	}
+	var script MockScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("parse mock script %s: %w", path, err)
+	}
+	return NewMockProvider(script)
+}
+
+func (p *MockProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	on, input := "user", ""
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		input = last.Content
+		if last.Role == "tool" {
+			on = "tool"
+		}
+	}
+
+	for _, r := range p.script.Rules {
+		if r.On != on {
+			continue
+		}
+		match := r.re.FindStringSubmatchIndex(input)
+		if match == nil {
+			continue
+		}
+		return p.respond(r, messages, input, match), nil
+	}
+
+	return &LLMResponse{
+		Content:      p.script.Default,
+		FinishReason: "stop",
+		Usage:        mockUsage(messages, p.script.Default),
+	}, nil
+}
+
+func (p *MockProvider) respond(r MockRule, messages []Message, input string, match []int) *LLMResponse {
+	expand := func(template string) string {
+		return string(r.re.ExpandString(nil, template, input, match))
+	}
+
+	resp := &LLMResponse{
+		Content:      expand(r.Response),
+		FinishReason: "stop",
+	}
+	for _, tc := range r.ToolCalls {
+		args := make(map[string]any, len(tc.Arguments))
+		for k, v := range tc.Arguments {
+			if s, ok := v.(string); ok {
+				v = expand(s)
+			}
+			args[k] = v
+		}
+		argsJSON, _ := json.Marshal(args)
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_mock_%d", p.calls.Add(1)),
+			Type:      "function",
+			Function:  &FunctionCall{Name: tc.Name, Arguments: string(argsJSON)},
+			Name:      tc.Name,
+			Arguments: args,
+		})
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	resp.Usage = mockUsage(messages, resp.Content)
+	return resp
+}
+
+func (p *MockProvider) GetDefaultModel() string {
+	return ""
+}
+
+// mockUsage reports rough token counts (4 characters per token) so usage
+// tracking and context budgeting behave as with a real model.
+func mockUsage(messages []Message, reply string) *UsageInfo {
+	prompt := 0
+	for _, m := range messages {
+		prompt += len(m.Content) / 4
+	}
+	completion := len(reply) / 4
+	return &UsageInfo{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const weatherScript = `{
+  "rules": [
+    {"pattern": "(?i)weather in (?P<city>\\w+)", "response": "Checking ${city}.",
+     "tool_calls": [{"name": "web_search", "arguments": {"query": "weather $1", "count": 3}}]},
+    {"on": "tool", "pattern": "(?s)(.+)", "response": "Result: $1"},
+    {"pattern": "(?i)^(hi|hello)", "response": "Hello!"}
+  ]
+}`
+
+func writeMockScript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(path, []byte(weatherScript), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMockProvider_ToolRoundTrip(t *testing.T) {
+	p, err := LoadMockProvider(writeMockScript(t))
+	if err != nil {
+		t.Fatalf("LoadMockProvider() error = %v", err)
+	}
+	ctx := context.Background()
+
+	msgs := []Message{{Role: "user", Content: "What's the weather in Paris?"}}
+	resp, err := p.Chat(ctx, msgs, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || resp.Content != "Checking Paris." || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "web_search" || tc.Arguments["query"] != "weather Paris" || tc.Arguments["count"] != float64(3) {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tc.Function.Arguments != `{"count":3,"query":"weather Paris"}` {
+		t.Errorf("Function.Arguments = %s", tc.Function.Arguments)
+	}
+
+	// The tool result is answered by the "tool" rule, ending the loop.
+	msgs = append(msgs,
+		Message{Role: "assistant", ToolCalls: resp.ToolCalls},
+		Message{Role: "tool", ToolCallID: tc.ID, Content: "sunny"},
+	)
+	resp, err = p.Chat(ctx, msgs, nil, "", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "Result: sunny" || len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestMockProvider_DefaultAndErrors(t *testing.T) {
+	p, err := NewMockProvider(MockScript{Rules: []MockRule{{Pattern: "^hi$", Response: "hey"}}})
+	if err != nil {
+		t.Fatalf("NewMockProvider() error = %v", err)
+	}
+	resp, _ := p.Chat(context.Background(), []Message{{Role: "user", Content: "something else"}}, nil, "", nil)
+	if resp.Content != defaultMockResponse {
+		t.Errorf("Content = %q, want default", resp.Content)
+	}
+
+	if _, err := NewMockProvider(MockScript{Rules: []MockRule{{Pattern: "("}}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if _, err := NewMockProvider(MockScript{Rules: []MockRule{{On: "assistant", Pattern: "."}}}); err == nil {
+		t.Error("expected error for invalid on")
+	}
+}
+
+func TestCreateProviderFromConfig_Mock(t *testing.T) {
+	path := writeMockScript(t)
+	p, modelID, err := CreateProviderFromConfig(&config.ModelConfig{Model: "mock/" + path})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := p.(*MockProvider); !ok || modelID != path {
+		t.Errorf("provider = %T, modelID = %q", p, modelID)
+	}
+
+	if _, _, err := CreateProviderFromConfig(&config.ModelConfig{Model: "mock/does-not-exist.json"}); err == nil {
+		t.Error("expected error for missing script")
+	}
+}
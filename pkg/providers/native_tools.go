@@ -0,0 +1,106 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+// anthropicServerTools are the native_tools names accepted for the
+// anthropic protocol, with the beta feature each needs, if any.
+var anthropicServerTools = map[string]struct {
+	tool map[string]any
+	beta string
+}{
+	"web_search": {
+		tool: map[string]any{"type": "web_search_20250305", "name": "web_search"},
+	},
+	"web_fetch": {
+		tool: map[string]any{"type": "web_fetch_20250910", "name": "web_fetch"},
+		beta: "web-fetch-2025-09-10",
+	},
+	"code_execution": {
+		tool: map[string]any{"type": "code_execution_20250825", "name": "code_execution"},
+		beta: "code-execution-2025-08-25",
+	},
+}
+
+// nativeToolsUnsupported lists protocols whose APIs take no server tools
+// (or that aren't HTTP APIs at all).
+var nativeToolsUnsupported = map[string]bool{
+	"bedrock":       true,
+	"llamacpp":      true,
+	"ollama-native": true,
+	"claude-cli":    true,
+	"codex-cli":     true,
+	"mock":          true,
+	"replay":        true,
+}
+
+// anthropicNativeTools converts a model_list entry's native_tools into
+// anthropic provider options.
+func anthropicNativeTools(cfg *config.ModelConfig) ([]anthropicprovider.Option, error) {
+	if len(cfg.NativeTools) == 0 {
+		return nil, nil
+	}
+	var tools []map[string]any
+	var betas []string
+	for _, nt := range cfg.NativeTools {
+		switch v := nt.(type) {
+		case string:
+			st, ok := anthropicServerTools[v]
+			if !ok {
+				return nil, fmt.Errorf("unknown native tool %q for protocol anthropic "+
+					"(use web_search, web_fetch, code_execution or a tool object)", v)
+			}
+			tools = append(tools, st.tool)
+			if st.beta != "" {
+				betas = append(betas, st.beta)
+			}
+		case map[string]any:
+			tools = append(tools, v)
+		default:
+			return nil, fmt.Errorf("native tool %v must be a name or an object", nt)
+		}
+	}
+	return []anthropicprovider.Option{
+		anthropicprovider.WithServerTools(tools),
+		anthropicprovider.WithBetas(betas...),
+	}, nil
+}
+
+// compatNativeTools converts a model_list entry's native_tools into options
+// for OpenAI-compatible providers. "web_search" uses the Chat Completions
+// web_search_options; objects are added to the request's tools.
+func compatNativeTools(cfg *config.ModelConfig) ([]openai_compat.Option, error) {
+	if len(cfg.NativeTools) == 0 {
+		return nil, nil
+	}
+	var opts []openai_compat.Option
+	var tools []map[string]any
+	for _, nt := range cfg.NativeTools {
+		switch v := nt.(type) {
+		case string:
+			if v != "web_search" {
+				return nil, fmt.Errorf("unknown native tool %q for OpenAI-compatible protocols "+
+					"(use web_search or a tool object)", v)
+			}
+			opts = append(opts, openai_compat.WithWebSearch(map[string]any{}))
+		case map[string]any:
+			tools = append(tools, v)
+		default:
+			return nil, fmt.Errorf("native tool %v must be a name or an object", nt)
+		}
+	}
+	if len(tools) > 0 {
+		opts = append(opts, openai_compat.WithServerTools(tools))
+	}
+	return opts, nil
+}
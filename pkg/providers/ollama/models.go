@@ -0,0 +1,70 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// get sends a GET request to path and decodes the JSON response into out.
+func (p *Provider) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiBase+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// Ping checks /api/version.
+func (p *Provider) Ping(ctx context.Context) error {
+	var version struct {
+		Version string `json:"version"`
+	}
+	return p.get(ctx, "/api/version", &version)
+}
+
+// ListModels returns the models installed on the server.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	var out struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := p.get(ctx, "/api/tags", &out); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(out.Models))
+	for _, m := range out.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
@@ -0,0 +1,33 @@
+package ollama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderPingAndListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/version":
+			w.Write([]byte(`{"version":"0.12.0"}`))
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3.2:3b"},{"name":"qwen3:0.6b"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	if err := p.Ping(t.Context()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	models, err := p.ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0] != "llama3.2:3b" {
+		t.Errorf("models = %v", models)
+	}
+}
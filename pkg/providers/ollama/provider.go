@@ -0,0 +1,391 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+const (
+	DefaultAPIBase = "http://localhost:11434"
+
+	defaultRequestTimeout = 300 * time.Second
+)
+
+// Provider talks to Ollama's native API (/api/chat) rather than its
+// OpenAI-compatible /v1 shim, which ignores keep_alive and cannot pull
+// models. With auto-pull enabled, a request for a model that isn't installed
+// downloads it first, reporting progress through the request context (see
+// protocoltypes.WithProgress).
+type Provider struct {
+	apiKey     string
+	apiBase    string
+	keepAlive  string
+	autoPull   bool
+	httpClient *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithKeepAlive sets how long Ollama keeps the model loaded after a request:
+// a duration such as "30m", "0" to unload immediately, or "-1" to keep it
+// loaded indefinitely. Empty keeps the server default (5 minutes).
+func WithKeepAlive(keepAlive string) Option {
+	return func(p *Provider) {
+		p.keepAlive = keepAlive
+	}
+}
+
+// WithAutoPull makes requests for a model that isn't installed pull it and
+// retry, instead of failing.
+func WithAutoPull(enabled bool) Option {
+	return func(p *Provider) {
+		p.autoPull = enabled
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("ollama: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/v1"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.chat(ctx, p.buildRequest(messages, tools, model, options))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var out chatResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return out.toLLMResponse(), nil
+}
+
+// chat sends a /api/chat request. When the model is missing and auto-pull is
+// enabled, it pulls the model and sends the request again. The caller owns
+// the response body.
+func (p *Provider) chat(ctx context.Context, req *chatRequest) (*http.Response, error) {
+	resp, err := p.post(ctx, "/api/chat", req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !p.autoPull || !isModelNotFound(resp.StatusCode, body) {
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+
+	if err := p.Pull(ctx, req.Model); err != nil {
+		return nil, err
+	}
+
+	resp, err = p.post(ctx, "/api/chat", req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, protocoltypes.NewAPIError(resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
+
+// post sends a JSON request to path. The caller owns the response body.
+func (p *Provider) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	return p.postWith(ctx, p.httpClient, path, body)
+}
+
+func (p *Provider) postWith(ctx context.Context, client *http.Client, path string, body any) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// isModelNotFound recognizes Ollama's reply for a model that isn't installed:
+// 404 with {"error":"model \"x\" not found, try pulling it first"}.
+func isModelNotFound(status int, body []byte) bool {
+	return status == http.StatusNotFound && bytes.Contains(body, []byte("not found"))
+}
+
+// Wire types for /api/chat. Only the fields picoclaw uses are modelled.
+
+type chatRequest struct {
+	Model     string           `json:"model"`
+	Messages  []chatMessage    `json:"messages"`
+	Tools     []ToolDefinition `json:"tools,omitempty"`
+	Stream    bool             `json:"stream"`
+	Format    map[string]any   `json:"format,omitempty"`
+	Options   map[string]any   `json:"options,omitempty"`
+	KeepAlive any              `json:"keep_alive,omitempty"`
+}
+
+type chatMessage struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	Images    []string       `json:"images,omitempty"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+	ToolName  string         `json:"tool_name,omitempty"`
+}
+
+type chatToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatResponse struct {
+	Message struct {
+		Content   string         `json:"content"`
+		Thinking  string         `json:"thinking"`
+		ToolCalls []chatToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+// buildRequest converts picoclaw messages into a /api/chat request. Tool
+// results carry the tool name, which Ollama uses in place of call IDs.
+func (p *Provider) buildRequest(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) *chatRequest {
+	req := &chatRequest{
+		Model:     model,
+		Messages:  make([]chatMessage, 0, len(messages)),
+		Tools:     tools,
+		KeepAlive: keepAliveValue(p.keepAlive),
+	}
+
+	callNames := make(map[string]string)
+	for _, msg := range messages {
+		m := chatMessage{Role: msg.Role, Content: msg.Content}
+		for _, img := range msg.Images {
+			// Only inline images: Ollama does not fetch URLs.
+			if img.Data != "" {
+				m.Images = append(m.Images, img.Data)
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			call := toChatToolCall(tc)
+			callNames[tc.ID] = call.Function.Name
+			m.ToolCalls = append(m.ToolCalls, call)
+		}
+		if msg.Role == "tool" {
+			m.ToolName = callNames[msg.ToolCallID]
+		}
+		req.Messages = append(req.Messages, m)
+	}
+
+	opts := map[string]any{}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		opts["num_predict"] = maxTokens
+	}
+	if temperature, ok := asFloat(options["temperature"]); ok {
+		opts["temperature"] = temperature
+	}
+	if stop := protocoltypes.StopOption(options); len(stop) > 0 {
+		opts["stop"] = stop
+	}
+	if len(opts) > 0 {
+		req.Options = opts
+	}
+
+	if schema := protocoltypes.ResponseSchemaOption(options); schema != nil {
+		req.Format = schema.Schema
+	}
+
+	return req
+}
+
+// keepAliveValue sends bare numbers (e.g. "-1") as seconds, since Ollama only
+// parses strings as Go durations.
+func keepAliveValue(keepAlive string) any {
+	if keepAlive == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(keepAlive); err == nil {
+		return n
+	}
+	return keepAlive
+}
+
+func toChatToolCall(tc ToolCall) chatToolCall {
+	var call chatToolCall
+	call.Function.Name = tc.Name
+	call.Function.Arguments = tc.Arguments
+	if tc.Function != nil {
+		if call.Function.Name == "" {
+			call.Function.Name = tc.Function.Name
+		}
+		if call.Function.Arguments == nil && tc.Function.Arguments != "" {
+			_ = json.Unmarshal([]byte(tc.Function.Arguments), &call.Function.Arguments)
+		}
+	}
+	if call.Function.Arguments == nil {
+		call.Function.Arguments = map[string]any{}
+	}
+	return call
+}
+
+func (r *chatResponse) toLLMResponse() *LLMResponse {
+	out := &LLMResponse{
+		Content:          r.Message.Content,
+		ReasoningContent: r.Message.Thinking,
+		FinishReason:     "stop",
+		Usage: &UsageInfo{
+			PromptTokens:     r.PromptEvalCount,
+			CompletionTokens: r.EvalCount,
+			TotalTokens:      r.PromptEvalCount + r.EvalCount,
+		},
+	}
+	if r.DoneReason == "length" {
+		out.FinishReason = "length"
+	}
+
+	// Ollama doesn't assign call IDs; tool results are matched by name.
+	for i, call := range r.Message.ToolCalls {
+		args := call.Function.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		argsJSON, _ := json.Marshal(args)
+		out.ToolCalls = append(out.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d_%d", time.Now().UnixNano(), i),
+			Type:      "function",
+			Function:  &FunctionCall{Name: call.Function.Name, Arguments: string(argsJSON)},
+			Name:      call.Function.Name,
+			Arguments: args,
+		})
+	}
+	if len(out.ToolCalls) > 0 {
+		out.FinishReason = "tool_calls"
+	}
+	return out
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
+
+func asFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,183 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestProviderChat_BuildsNativeRequest(t *testing.T) {
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"ok"},"done":true,` +
+			`"done_reason":"stop","prompt_eval_count":12,"eval_count":3}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", "", WithKeepAlive("-1"))
+	resp, err := p.Chat(t.Context(), []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "look", Images: []protocoltypes.ImagePart{
+			{MIMEType: "image/png", Data: "aGk="},
+			{URL: "https://example.com/cat.png"},
+		}},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "a"}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "contents"},
+	}, nil, "llama3.2:3b", map[string]any{"max_tokens": 256, "temperature": 0.2})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if requestBody["model"] != "llama3.2:3b" || requestBody["stream"] != false {
+		t.Errorf("model/stream = %v/%v", requestBody["model"], requestBody["stream"])
+	}
+	if requestBody["keep_alive"] != float64(-1) {
+		t.Errorf("keep_alive = %#v, want -1 as a number", requestBody["keep_alive"])
+	}
+	opts := requestBody["options"].(map[string]any)
+	if opts["num_predict"] != float64(256) || opts["temperature"] != 0.2 {
+		t.Errorf("options = %v", opts)
+	}
+
+	msgs := requestBody["messages"].([]any)
+	user := msgs[1].(map[string]any)
+	if images := user["images"].([]any); len(images) != 1 || images[0] != "aGk=" {
+		t.Errorf("images = %v, want only the inline image", images)
+	}
+	assistant := msgs[2].(map[string]any)
+	call := assistant["tool_calls"].([]any)[0].(map[string]any)["function"].(map[string]any)
+	if call["name"] != "read_file" || call["arguments"].(map[string]any)["path"] != "a" {
+		t.Errorf("tool call = %v", call)
+	}
+	if tool := msgs[3].(map[string]any); tool["tool_name"] != "read_file" {
+		t.Errorf("tool message = %v, want tool_name read_file", tool)
+	}
+
+	if resp.Content != "ok" || resp.Usage.PromptTokens != 12 || resp.Usage.TotalTokens != 15 {
+		t.Errorf("resp = %+v usage = %+v", resp, resp.Usage)
+	}
+}
+
+func TestProviderChat_ParsesToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":{"role":"assistant","content":"","thinking":"hmm",` +
+			`"tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"SF"}}}]},"done":true}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "weather"}}, nil, "qwen3", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || resp.ReasoningContent != "hmm" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID == "" || tc.Name != "get_weather" || tc.Function.Arguments != `{"city":"SF"}` {
+		t.Errorf("tool call = %+v", tc)
+	}
+}
+
+func TestProviderChat_AutoPullsMissingModel(t *testing.T) {
+	pulled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			if !pulled {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"model \"tinyllama\" not found, try pulling it first"}`))
+				return
+			}
+			w.Write([]byte(`{"message":{"role":"assistant","content":"hi"},"done":true}`))
+		case "/api/pull":
+			pulled = true
+			for _, line := range []string{
+				`{"status":"pulling manifest"}`,
+				`{"status":"pulling abc","digest":"abc","total":1000,"completed":0}`,
+				`{"status":"pulling abc","digest":"abc","total":1000,"completed":300}`,
+				`{"status":"pulling abc","digest":"abc","total":1000,"completed":320}`,
+				`{"status":"pulling abc","digest":"abc","total":1000,"completed":800}`,
+				`{"status":"pulling abc","digest":"abc","total":1000,"completed":1000}`,
+				`{"status":"success"}`,
+			} {
+				w.Write([]byte(line + "\n"))
+			}
+		}
+	}))
+	defer server.Close()
+
+	var progress []string
+	ctx := protocoltypes.WithProgress(t.Context(), func(status string) { progress = append(progress, status) })
+
+	p := NewProvider("", server.URL, "", WithAutoPull(true))
+	resp, err := p.Chat(ctx, []Message{{Role: "user", Content: "hi"}}, nil, "tinyllama", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hi" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+
+	want := []string{
+		"Model tinyllama is not installed, downloading it...",
+		"Downloading tinyllama: 25%",
+		"Downloading tinyllama: 75%",
+		"Model tinyllama downloaded.",
+	}
+	if strings.Join(progress, "|") != strings.Join(want, "|") {
+		t.Errorf("progress = %q, want %q", progress, want)
+	}
+}
+
+func TestProviderChat_MissingModelWithoutAutoPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/pull" {
+			t.Error("pull attempted with auto-pull disabled")
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"tinyllama\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "tinyllama", nil)
+	if err == nil || !strings.Contains(err.Error(), "Status: 404") {
+		t.Fatalf("Chat() error = %v, want status 404", err)
+	}
+}
+
+func TestProviderPull_ReportsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"pulling manifest"}` + "\n" + `{"error":"pull model manifest: file does not exist"}` + "\n"))
+	}))
+	defer server.Close()
+
+	err := NewProvider("", server.URL, "").Pull(t.Context(), "nope")
+	if err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Fatalf("Pull() error = %v", err)
+	}
+}
+
+func TestKeepAliveValue(t *testing.T) {
+	if v := keepAliveValue(""); v != nil {
+		t.Errorf("keepAliveValue(\"\") = %v, want nil", v)
+	}
+	if v := keepAliveValue("0"); v != 0 {
+		t.Errorf("keepAliveValue(\"0\") = %#v, want 0", v)
+	}
+	if v := keepAliveValue("30m"); v != "30m" {
+		t.Errorf("keepAliveValue(\"30m\") = %#v, want \"30m\"", v)
+	}
+}
@@ -0,0 +1,91 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// progressStep is the download percentage between progress reports, so a
+// multi-gigabyte pull doesn't flood the chat.
+const progressStep = 25
+
+// Pull downloads model to the Ollama server, reporting progress through
+// protocoltypes.ReportProgress on ctx. The request timeout doesn't apply:
+// pulls can take far longer than a chat request, so only ctx bounds them.
+func (p *Provider) Pull(ctx context.Context, model string) error {
+	protocoltypes.ReportProgress(ctx, fmt.Sprintf("Model %s is not installed, downloading it...", model))
+
+	client := *p.httpClient
+	client.Timeout = 0
+	resp, err := p.postWith(ctx, &client, "/api/pull", map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", model, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pull %s: %w", model, protocoltypes.NewAPIError(resp.StatusCode, string(body)))
+	}
+
+	// Layers are listed as they start, so totals grow during the pull;
+	// reports are only made when the overall percentage passes a new step.
+	totals := map[string]int64{}
+	completed := map[string]int64{}
+	reported := 0
+	success := false
+
+	err = readLines(resp.Body, func(line []byte) error {
+		var status struct {
+			Status    string `json:"status"`
+			Digest    string `json:"digest"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &status); err != nil {
+			return fmt.Errorf("failed to unmarshal pull status: %w", err)
+		}
+		if status.Error != "" {
+			return fmt.Errorf("%s", status.Error)
+		}
+		if status.Status == "success" {
+			success = true
+			return nil
+		}
+		if status.Digest == "" || status.Total == 0 {
+			return nil
+		}
+
+		totals[status.Digest] = status.Total
+		completed[status.Digest] = status.Completed
+		var total, done int64
+		for digest, t := range totals {
+			total += t
+			done += completed[digest]
+		}
+		if step := int(done*100/total) / progressStep * progressStep; step > reported && step < 100 {
+			reported = step
+			protocoltypes.ReportProgress(ctx, fmt.Sprintf("Downloading %s: %d%%", model, step))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", model, err)
+	}
+	if !success {
+		return fmt.Errorf("pull %s: stream ended before success", model)
+	}
+
+	protocoltypes.ReportProgress(ctx, fmt.Sprintf("Model %s downloaded.", model))
+	return nil
+}
@@ -0,0 +1,97 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamChat performs a streaming /api/chat call. onDelta is called with each
+// content delta; the returned response is the assembled message.
+func (p *Provider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(delta string),
+) (*LLMResponse, error) {
+	req := p.buildRequest(messages, tools, model, options)
+	req.Stream = true
+
+	resp, err := p.chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return readStream(resp.Body, onDelta)
+}
+
+// readStream merges the NDJSON chunks of a streamed chat: content and
+// thinking accumulate, tool calls arrive whole, and the final chunk (done)
+// carries the stop reason and token counts.
+func readStream(r io.Reader, onDelta func(delta string)) (*LLMResponse, error) {
+	var merged chatResponse
+	var content, thinking bytes.Buffer
+
+	err := readLines(r, func(line []byte) error {
+		var chunk chatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("stream error: %s", chunk.Error)
+		}
+
+		content.WriteString(chunk.Message.Content)
+		thinking.WriteString(chunk.Message.Thinking)
+		merged.Message.ToolCalls = append(merged.Message.ToolCalls, chunk.Message.ToolCalls...)
+		if onDelta != nil && chunk.Message.Content != "" {
+			onDelta(chunk.Message.Content)
+		}
+
+		if chunk.Done {
+			merged.Done = true
+			merged.DoneReason = chunk.DoneReason
+			merged.PromptEvalCount = chunk.PromptEvalCount
+			merged.EvalCount = chunk.EvalCount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !merged.Done {
+		return nil, errors.New("failed to read stream: ended before done")
+	}
+
+	merged.Message.Content = content.String()
+	merged.Message.Thinking = thinking.String()
+	return merged.toLLMResponse(), nil
+}
+
+// readLines calls fn for each non-empty line of an NDJSON stream.
+func readLines(r io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,48 @@
+package ollama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderStreamChat_MergesChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"message":{"role":"assistant","content":"lo"},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"length","prompt_eval_count":4,"eval_count":2}`,
+		} {
+			w.Write([]byte(line + "\n"))
+		}
+	}))
+	defer server.Close()
+
+	var deltas []string
+	p := NewProvider("", server.URL, "")
+	resp, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.2",
+		nil, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if strings.Join(deltas, "") != "Hello" || resp.Content != "Hello" {
+		t.Errorf("deltas = %q, content = %q", deltas, resp.Content)
+	}
+	if resp.FinishReason != "length" || resp.Usage.TotalTokens != 6 {
+		t.Errorf("resp = %+v usage = %+v", resp, resp.Usage)
+	}
+}
+
+func TestProviderStreamChat_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"model runner has unexpectedly stopped"}` + "\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	_, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.2", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpectedly stopped") {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+}
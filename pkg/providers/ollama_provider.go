@@ -0,0 +1,73 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"time"
+
+	ollamaprovider "github.com/sipeed/picoclaw/pkg/providers/ollama"
+)
+
+// OllamaProvider talks to Ollama's native /api/chat, with keep_alive control
+// and optional pulling of missing models.
+type OllamaProvider struct {
+	delegate *ollamaprovider.Provider
+}
+
+func NewOllamaProvider(
+	apiKey, apiBase, proxy string,
+	requestTimeoutSeconds int,
+	opts ...ollamaprovider.Option,
+) *OllamaProvider {
+	return &OllamaProvider{
+		delegate: ollamaprovider.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			append([]ollamaprovider.Option{
+				ollamaprovider.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+			}, opts...)...,
+		),
+	}
+}
+
+func (p *OllamaProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *OllamaProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.delegate.StreamChat(ctx, messages, tools, model, options, onDelta)
+}
+
+func (p *OllamaProvider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *OllamaProvider) SupportsStructuredOutput() bool {
+	return true
+}
+
+func (p *OllamaProvider) Ping(ctx context.Context) error {
+	return p.delegate.Ping(ctx)
+}
+
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.delegate.ListModels(ctx)
+}
@@ -0,0 +1,284 @@
+package openai_compat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	BatchRequest = protocoltypes.BatchRequest
+	BatchStatus  = protocoltypes.BatchStatus
+	BatchResult  = protocoltypes.BatchResult
+)
+
+// batchEndpoint is the only chat endpoint the Batch API accepts.
+const batchEndpoint = "/v1/chat/completions"
+
+// SubmitBatch uploads requests as a JSONL file and creates a Batch API job
+// for them, returning the batch ID. Batches finish within 24 hours at half
+// the synchronous price.
+// See: https://platform.openai.com/docs/guides/batch
+func (p *Provider) SubmitBatch(ctx context.Context, requests []BatchRequest, model string) (string, error) {
+	if p.apiBase == "" {
+		return "", fmt.Errorf("API base not configured")
+	}
+	if len(requests) == 0 {
+		return "", fmt.Errorf("batch is empty")
+	}
+
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for _, r := range requests {
+		line := map[string]any{
+			"custom_id": r.CustomID,
+			"method":    "POST",
+			"url":       batchEndpoint,
+			"body":      p.buildRequestBody(r.Messages, r.Tools, model, r.Options),
+		}
+		if err := enc.Encode(line); err != nil {
+			return "", fmt.Errorf("failed to marshal batch request %q: %w", r.CustomID, err)
+		}
+	}
+
+	fileID, err := p.uploadBatchFile(ctx, input.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.post(ctx, "/batches", map[string]any{
+		"input_file_id":     fileID,
+		"endpoint":          batchEndpoint,
+		"completion_window": "24h",
+	})
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+	var batch apiBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return batch.ID, nil
+}
+
+// BatchStatus reports the progress of batch id.
+func (p *Provider) BatchStatus(ctx context.Context, id string) (*BatchStatus, error) {
+	batch, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return batch.status(), nil
+}
+
+// BatchResults returns the results of a finished batch, successes and
+// failures alike, in no particular order.
+func (p *Provider) BatchResults(ctx context.Context, id string) ([]BatchResult, error) {
+	batch, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !batch.status().Done {
+		return nil, fmt.Errorf("batch %s is still %s", id, batch.Status)
+	}
+
+	var results []BatchResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		content, err := p.get(ctx, "/files/"+url.PathEscape(fileID)+"/content")
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseBatchOutput(content)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, parsed...)
+	}
+	return results, nil
+}
+
+type apiBatch struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	OutputFileID  string `json:"output_file_id"`
+	ErrorFileID   string `json:"error_file_id"`
+	RequestCounts struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	} `json:"request_counts"`
+}
+
+func (b *apiBatch) status() *BatchStatus {
+	done := false
+	switch b.Status {
+	case "completed", "failed", "expired", "cancelled":
+		done = true
+	}
+	return &BatchStatus{
+		ID:        b.ID,
+		Status:    b.Status,
+		Done:      done,
+		Total:     b.RequestCounts.Total,
+		Succeeded: b.RequestCounts.Completed,
+		Failed:    b.RequestCounts.Failed,
+	}
+}
+
+func (p *Provider) getBatch(ctx context.Context, id string) (*apiBatch, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+	body, err := p.get(ctx, "/batches/"+url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	var batch apiBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &batch, nil
+}
+
+// parseBatchOutput decodes a batch output or error file: one JSON line per
+// request, carrying either the chat completion or an error.
+func parseBatchOutput(content []byte) ([]BatchResult, error) {
+	var results []BatchResult
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Response *struct {
+				StatusCode int             `json:"status_code"`
+				Body       json.RawMessage `json:"body"`
+			} `json:"response"`
+			Error *struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch output: %w", err)
+		}
+
+		result := BatchResult{CustomID: line.CustomID}
+		switch {
+		case line.Error != nil:
+			result.Error = line.Error.Message
+		case line.Response == nil:
+			result.Error = "no response"
+		case line.Response.StatusCode != http.StatusOK:
+			result.Error = fmt.Sprintf("status %d: %s", line.Response.StatusCode, string(line.Response.Body))
+		default:
+			resp, err := parseResponse(line.Response.Body)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Response = resp
+			}
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch output: %w", err)
+	}
+	return results, nil
+}
+
+// uploadBatchFile uploads the JSONL input of a batch and returns its file ID.
+func (p *Provider) uploadBatchFile(ctx context.Context, content []byte) (string, error) {
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	if err := mw.WriteField("purpose", "batch"); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	part, err := mw.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint("/files"), &form)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	body, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &file); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return file.ID, nil
+}
+
+// get fetches path and returns the response body.
+func (p *Provider) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.endpoint(path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return p.do(req)
+}
+
+// do sends req with the API key and returns the body of a 200 response.
+func (p *Provider) do(req *http.Request) ([]byte, error) {
+	if err := p.setAuthHeaders(req); err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return readBody(resp)
+}
+
+// readBody reads and closes resp's body, turning non-200 responses into an
+// HTTPError.
+func readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body)
+	}
+	return body, nil
+}
+
+func (p *Provider) endpoint(path string) string {
+	endpoint := p.apiBase + path
+	if len(p.queryParams) > 0 {
+		endpoint += "?" + p.queryParams.Encode()
+	}
+	return endpoint
+}
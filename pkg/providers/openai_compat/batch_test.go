@@ -0,0 +1,114 @@
+package openai_compat
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderBatch_SubmitStatusResults(t *testing.T) {
+	var (
+		uploaded []map[string]any
+		created  map[string]any
+		polls    int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("purpose") != "batch" {
+			t.Errorf("purpose = %q", r.FormValue("purpose"))
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile() error = %v", err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var line map[string]any
+			json.Unmarshal(scanner.Bytes(), &line)
+			uploaded = append(uploaded, line)
+		}
+		w.Write([]byte(`{"id":"file-in"}`))
+	})
+	mux.HandleFunc("POST /batches", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.Write([]byte(`{"id":"batch_1","status":"validating"}`))
+	})
+	mux.HandleFunc("GET /batches/batch_1", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 1 {
+			w.Write([]byte(`{"id":"batch_1","status":"in_progress","request_counts":{"total":2,"completed":1,"failed":0}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"batch_1","status":"completed","output_file_id":"file-out","error_file_id":"file-err",` +
+			`"request_counts":{"total":2,"completed":1,"failed":1}}`))
+	})
+	mux.HandleFunc("GET /files/file-out/content", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"custom_id":"note-1","response":{"status_code":200,"body":`+
+			`{"choices":[{"message":{"content":"Summary 1"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}}}`+"\n")
+	})
+	mux.HandleFunc("GET /files/file-err/content", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"custom_id":"note-2","response":{"status_code":400,"body":{"error":{"message":"bad"}}}}`+"\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	ctx := t.Context()
+	id, err := p.SubmitBatch(ctx, []BatchRequest{
+		{CustomID: "note-1", Messages: []Message{{Role: "user", Content: "summarize 1"}}},
+		{CustomID: "note-2", Messages: []Message{{Role: "user", Content: "summarize 2"}}, Options: map[string]any{"max_tokens": 100}},
+	}, "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if id != "batch_1" {
+		t.Errorf("id = %q", id)
+	}
+	if len(uploaded) != 2 || uploaded[0]["custom_id"] != "note-1" || uploaded[0]["url"] != "/v1/chat/completions" {
+		t.Fatalf("uploaded = %v", uploaded)
+	}
+	if body, _ := uploaded[1]["body"].(map[string]any); body["model"] != "gpt-4o-mini" || body["max_tokens"] != float64(100) {
+		t.Errorf("request body = %v", uploaded[1]["body"])
+	}
+	if created["input_file_id"] != "file-in" || created["completion_window"] != "24h" {
+		t.Errorf("batch = %v", created)
+	}
+
+	status, err := p.BatchStatus(ctx, id)
+	if err != nil {
+		t.Fatalf("BatchStatus() error = %v", err)
+	}
+	if status.Done || status.Total != 2 || status.Succeeded != 1 {
+		t.Errorf("status = %+v", status)
+	}
+	results, err := p.BatchResults(ctx, id)
+	if err != nil {
+		t.Fatalf("BatchResults() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].CustomID != "note-1" || results[0].Response == nil || results[0].Response.Content != "Summary 1" {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].CustomID != "note-2" || results[1].Response != nil || !strings.Contains(results[1].Error, "status 400") {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
+
+func TestProviderBatchResults_NotDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"batch_1","status":"in_progress"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.BatchResults(t.Context(), "batch_1"); err == nil || !strings.Contains(err.Error(), "in_progress") {
+		t.Errorf("BatchResults() error = %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package openai_compat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Embed returns one embedding per input from the /embeddings endpoint, in
+// input order. dimensions > 0 asks models that support it (e.g.
+// text-embedding-3-*) for shortened vectors. Inputs are sent in a single
+// request; batching is up to the caller.
+func (p *Provider) Embed(ctx context.Context, inputs []string, model string, dimensions int) ([][]float32, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	requestBody := map[string]any{
+		"model": normalizeModel(model, p.apiBase),
+		"input": inputs,
+	}
+	if dimensions > 0 {
+		requestBody["dimensions"] = dimensions
+	}
+
+	resp, err := p.post(ctx, "/embeddings", requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body)
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(out.Data) != len(inputs) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(out.Data), len(inputs))
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
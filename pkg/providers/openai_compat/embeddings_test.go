@@ -0,0 +1,54 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderEmbed_OrdersByIndex(t *testing.T) {
+	var requestBody map[string]any
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"index": 1, "embedding": []float32{0, 1}},
+				{"index": 0, "embedding": []float32{1, 0}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	vectors, err := p.Embed(t.Context(), []string{"a", "b"}, "text-embedding-3-small", 2)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if path != "/embeddings" {
+		t.Errorf("path = %q", path)
+	}
+	if requestBody["dimensions"] != float64(2) || requestBody["model"] != "text-embedding-3-small" {
+		t.Errorf("request = %v", requestBody)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v", vectors)
+	}
+}
+
+func TestProviderEmbed_CountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[1]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.Embed(t.Context(), []string{"a", "b"}, "m", 0); err == nil {
+		t.Fatal("Embed() expected error for missing embeddings")
+	}
+}
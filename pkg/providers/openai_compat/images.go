@@ -0,0 +1,109 @@
+package openai_compat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ImageRequest   = protocoltypes.ImageRequest
+	GeneratedImage = protocoltypes.GeneratedImage
+)
+
+// GenerateImages creates images with the /images/generations endpoint
+// (DALL·E, gpt-image-1 and compatible servers).
+func (p *Provider) GenerateImages(ctx context.Context, req ImageRequest, model string) ([]GeneratedImage, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	model = normalizeModel(model, p.apiBase)
+	requestBody := map[string]any{
+		"model":  model,
+		"prompt": req.Prompt,
+	}
+	if req.N > 1 {
+		requestBody["n"] = req.N
+	}
+	if req.Size != "" {
+		requestBody["size"] = req.Size
+	}
+	// gpt-image models always return base64 and reject response_format;
+	// DALL·E returns short-lived URLs unless asked otherwise.
+	if strings.HasPrefix(model, "dall-e") {
+		requestBody["response_format"] = "b64_json"
+	}
+
+	resp, err := p.post(ctx, "/images/generations", requestBody)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Data []struct {
+			B64JSON       string `json:"b64_json"`
+			URL           string `json:"url"`
+			RevisedPrompt string `json:"revised_prompt"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(out.Data) == 0 {
+		return nil, fmt.Errorf("no images in response")
+	}
+
+	images := make([]GeneratedImage, 0, len(out.Data))
+	for i, d := range out.Data {
+		var data []byte
+		switch {
+		case d.B64JSON != "":
+			data, err = base64.StdEncoding.DecodeString(d.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+			}
+		case d.URL != "":
+			data, err = p.download(ctx, d.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image %d: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("image %d has no data", i)
+		}
+		images = append(images, GeneratedImage{
+			Data:          data,
+			MIMEType:      http.DetectContentType(data),
+			RevisedPrompt: d.RevisedPrompt,
+		})
+	}
+	return images, nil
+}
+
+// download fetches a generated image URL. The URLs are pre-signed, so no
+// API key is sent.
+func (p *Provider) download(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,85 @@
+package openai_compat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+func TestProviderGenerateImages_Base64(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/generations" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{
+				"b64_json":       base64.StdEncoding.EncodeToString(pngHeader),
+				"revised_prompt": "a red fox in snow",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	images, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "fox", Size: "1024x1024"}, "dall-e-3")
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if requestBody["response_format"] != "b64_json" || requestBody["size"] != "1024x1024" {
+		t.Errorf("request = %v", requestBody)
+	}
+	if len(images) != 1 || images[0].MIMEType != "image/png" || images[0].RevisedPrompt != "a red fox in snow" {
+		t.Errorf("images = %+v", images)
+	}
+}
+
+func TestProviderGenerateImages_GPTImageOmitsResponseFormat(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"b64_json": base64.StdEncoding.EncodeToString(pngHeader)}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "fox", N: 2}, "gpt-image-1"); err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if _, ok := requestBody["response_format"]; ok {
+		t.Errorf("response_format sent to gpt-image-1: %v", requestBody)
+	}
+	if requestBody["n"] != float64(2) {
+		t.Errorf("n = %v", requestBody["n"])
+	}
+}
+
+func TestProviderGenerateImages_DownloadsURL(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files/img.png" {
+			w.Write(pngHeader)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"url": server.URL + "/files/img.png"}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	images, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "fox"}, "sdxl")
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 1 || string(images[0].Data) != string(pngHeader) {
+		t.Errorf("images = %+v", images)
+	}
+}
@@ -0,0 +1,69 @@
+package openai_compat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ListModels returns the model IDs served by the /models endpoint.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	base := p.apiBase
+	// Azure deployment URLs list models at the resource level.
+	if i := strings.Index(base, "/openai/deployments/"); i >= 0 {
+		base = base[:i] + "/openai"
+	}
+	endpoint := base + "/models"
+	if len(p.queryParams) > 0 {
+		endpoint += "?" + p.queryParams.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := p.setAuthHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body)
+	}
+
+	var out struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	models := make([]string, 0, len(out.Data))
+	for _, m := range out.Data {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
+
+// Ping checks that the endpoint is reachable and accepts the API key by
+// listing models, which is free on every OpenAI-compatible API.
+func (p *Provider) Ping(ctx context.Context) error {
+	_, err := p.ListModels(ctx)
+	return err
+}
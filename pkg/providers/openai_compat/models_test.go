@@ -0,0 +1,70 @@
+package openai_compat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProviderListModels(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/models" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	models, err := p.ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0] != "gpt-4o" {
+		t.Errorf("models = %v", models)
+	}
+	if auth != "Bearer key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if err := p.Ping(t.Context()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func TestProviderListModels_AzureResourceLevel(t *testing.T) {
+	var path, apiKey, version string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey, version = r.URL.Path, r.Header.Get("api-key"), r.URL.Query().Get("api-version")
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL+"/openai/deployments/gpt4", "",
+		WithAPIKeyHeader("api-key"),
+		WithQueryParams(url.Values{"api-version": {"2024-10-21"}}),
+	)
+	if err := p.Ping(t.Context()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if path != "/openai/models" || apiKey != "key" || version != "2024-10-21" {
+		t.Errorf("path = %q, api-key = %q, api-version = %q", path, apiKey, version)
+	}
+}
+
+func TestProviderPing_InvalidKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	p := NewProvider("bad", server.URL, "")
+	err := p.Ping(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Ping() error = %v, want 401", err)
+	}
+}
@@ -3,6 +3,7 @@ package openai_compat
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
 )
 
 type (
@@ -25,13 +27,30 @@ type (
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
 	ExtraContent           = protocoltypes.ExtraContent
 	GoogleExtra            = protocoltypes.GoogleExtra
+	ImagePart              = protocoltypes.ImagePart
+	ResponseSchema         = protocoltypes.ResponseSchema
+	TokenLogprob           = protocoltypes.TokenLogprob
+	TopLogprob             = protocoltypes.TopLogprob
 )
 
 type Provider struct {
-	apiKey         string
-	apiBase        string
-	maxTokensField string // Field name for max tokens (e.g., "max_completion_tokens" for o1/glm models)
-	httpClient     *http.Client
+	apiKey          string
+	apiBase         string
+	maxTokensField  string // Field name for max tokens (e.g., "max_completion_tokens" for o1/glm models)
+	apiKeyHeader    string // Header carrying the raw API key; empty means "Authorization: Bearer"
+	tokenSource     func(ctx context.Context) (string, error)
+	queryParams     url.Values
+	extraHeaders    map[string]string
+	extraBody       map[string]any
+	reasoningEffort string
+	logprobs        bool
+	topLogprobs     int
+	logitBias       map[string]float64
+	serverTools     []map[string]any
+	webSearch       map[string]any
+	maxAttempts     int
+	retryBaseDelay  time.Duration
+	httpClient      *http.Client
 }
 
 type Option func(*Provider)
@@ -44,6 +63,127 @@ func WithMaxTokensField(maxTokensField string) Option {
 	}
 }
 
+// WithAPIKeyHeader sends the API key verbatim in the named header instead of
+// as an Authorization bearer token (Azure OpenAI uses "api-key").
+func WithAPIKeyHeader(header string) Option {
+	return func(p *Provider) {
+		p.apiKeyHeader = header
+	}
+}
+
+// WithTokenSource fetches the API key for every request instead of using a
+// fixed one, for short-lived credentials that are refreshed (GitHub Copilot).
+func WithTokenSource(source func(ctx context.Context) (string, error)) Option {
+	return func(p *Provider) {
+		p.tokenSource = source
+	}
+}
+
+// WithQueryParams adds query parameters to every request URL.
+func WithQueryParams(params url.Values) Option {
+	return func(p *Provider) {
+		p.queryParams = params
+	}
+}
+
+// WithExtraHeaders sets additional headers on every request. Repeated
+// options add to the headers of earlier ones, overriding duplicates.
+func WithExtraHeaders(headers map[string]string) Option {
+	return func(p *Provider) {
+		if len(headers) == 0 {
+			return
+		}
+		merged := make(map[string]string, len(p.extraHeaders)+len(headers))
+		for k, v := range p.extraHeaders {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		p.extraHeaders = merged
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the HTTP transport, e.g. a
+// client certificate for gateways that require mutual TLS or a private CA.
+// A configured proxy is kept.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(p *Provider) {
+		if cfg == nil {
+			return
+		}
+		transport, ok := p.httpClient.Transport.(*http.Transport)
+		if ok {
+			transport = transport.Clone()
+		} else {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+		transport.TLSClientConfig = cfg
+		p.httpClient.Transport = transport
+	}
+}
+
+// WithExtraBody merges additional top-level fields into every request body.
+func WithExtraBody(fields map[string]any) Option {
+	return func(p *Provider) {
+		p.extraBody = fields
+	}
+}
+
+// WithServerTools adds tool objects the endpoint runs itself to every
+// request's tools, as given (e.g. {"type": "web_search"} on gateways that
+// support it).
+func WithServerTools(tools []map[string]any) Option {
+	return func(p *Provider) {
+		p.serverTools = tools
+	}
+}
+
+// WithWebSearch sends web_search_options with every request, so search
+// models (e.g. gpt-4o-search-preview) look things up before answering. nil
+// leaves it off.
+// See: https://platform.openai.com/docs/guides/tools-web-search
+func WithWebSearch(options map[string]any) Option {
+	return func(p *Provider) {
+		p.webSearch = options
+	}
+}
+
+// WithReasoningEffort sets reasoning_effort ("minimal", "low", "medium",
+// "high") on every request, for reasoning models. Empty keeps the model default.
+func WithReasoningEffort(effort string) Option {
+	return func(p *Provider) {
+		p.reasoningEffort = effort
+	}
+}
+
+// WithLogprobs requests token log probabilities on every request, with up
+// to top alternatives per token (0-20; 0 returns only the chosen tokens).
+func WithLogprobs(top int) Option {
+	return func(p *Provider) {
+		p.logprobs = true
+		p.topLogprobs = top
+	}
+}
+
+// WithLogitBias biases the sampling of tokens, by token ID, on every
+// request: -100 bans a token, 100 forces it.
+func WithLogitBias(bias map[string]float64) Option {
+	return func(p *Provider) {
+		p.logitBias = bias
+	}
+}
+
+// WithMaxAttempts sets how many times a request is sent before a transient
+// failure is returned. 1 disables retries; values below 1 keep the default.
+func WithMaxAttempts(n int) Option {
+	return func(p *Provider) {
+		if n > 0 {
+			p.maxAttempts = n
+		}
+	}
+}
+
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(p *Provider) {
 		if timeout > 0 {
@@ -57,21 +197,18 @@ func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
 		Timeout: defaultRequestTimeout,
 	}
 
-	if proxy != "" {
-		parsed, err := url.Parse(proxy)
-		if err == nil {
-			client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(parsed),
-			}
-		} else {
-			log.Printf("openai_compat: invalid proxy URL %q: %v", proxy, err)
-		}
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("openai_compat: invalid proxy %q: %v", proxy, err)
 	}
 
 	p := &Provider{
-		apiKey:     apiKey,
-		apiBase:    strings.TrimRight(apiBase, "/"),
-		httpClient: client,
+		apiKey:         apiKey,
+		apiBase:        strings.TrimRight(apiBase, "/"),
+		maxAttempts:    defaultMaxAttempts,
+		retryBaseDelay: defaultRetryBaseDelay,
+		httpClient:     client,
 	}
 
 	for _, opt := range opts {
@@ -111,17 +248,57 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("API base not configured")
 	}
 
+	resp, err := p.post(ctx, "/chat/completions", p.buildRequestBody(messages, tools, model, options))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body)
+	}
+
+	return parseResponse(body)
+}
+
+func (p *Provider) buildRequestBody(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) map[string]any {
 	model = normalizeModel(model, p.apiBase)
 
 	requestBody := map[string]any{
 		"model":    model,
 		"messages": stripSystemParts(messages),
 	}
+	for k, v := range p.extraBody {
+		requestBody[k] = v
+	}
 
 	if len(tools) > 0 {
 		requestBody["tools"] = tools
 		requestBody["tool_choice"] = "auto"
 	}
+	if len(p.serverTools) > 0 {
+		all := make([]any, 0, len(tools)+len(p.serverTools))
+		for _, t := range tools {
+			all = append(all, t)
+		}
+		for _, t := range p.serverTools {
+			all = append(all, t)
+		}
+		requestBody["tools"] = all
+	}
+	if p.webSearch != nil {
+		requestBody["web_search_options"] = p.webSearch
+	}
 
 	if maxTokens, ok := asInt(options["max_tokens"]); ok {
 		// Use configured maxTokensField if specified, otherwise fallback to model-based detection
@@ -149,6 +326,30 @@ func (p *Provider) Chat(
 		}
 	}
 
+	if stop := protocoltypes.StopOption(options); len(stop) > 0 {
+		requestBody["stop"] = stop
+	}
+
+	// Token log probabilities, for scoring and classification. Per-request
+	// options override the configured defaults.
+	logprobs, topLogprobs := p.logprobs, p.topLogprobs
+	if v, ok := options["logprobs"].(bool); ok {
+		logprobs = v
+	}
+	if n, ok := asInt(options["top_logprobs"]); ok {
+		topLogprobs = n
+		logprobs = logprobs || n > 0
+	}
+	if logprobs {
+		requestBody["logprobs"] = true
+		if topLogprobs > 0 {
+			requestBody["top_logprobs"] = topLogprobs
+		}
+	}
+	if bias := logitBias(p.logitBias, options["logit_bias"]); len(bias) > 0 {
+		requestBody["logit_bias"] = bias
+	}
+
 	// Prompt caching: pass a stable cache key so OpenAI can bucket requests
 	// with the same key and reuse prefix KV cache across calls.
 	// The key is typically the agent ID — stable per agent, shared across requests.
@@ -161,37 +362,108 @@ func (p *Provider) Chat(
 		}
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	// OpenRouter normalizes reasoning controls under its own "reasoning" object.
+	// See: https://openrouter.ai/docs/use-cases/reasoning-tokens
+	if p.reasoningEffort != "" {
+		if strings.Contains(strings.ToLower(p.apiBase), "openrouter.ai") {
+			requestBody["reasoning"] = map[string]any{"effort": p.reasoningEffort}
+		} else {
+			requestBody["reasoning_effort"] = p.reasoningEffort
+		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Structured output: constrain the reply to a JSON schema.
+	// See: https://platform.openai.com/docs/guides/structured-outputs
+	if schema := protocoltypes.ResponseSchemaOption(options); schema != nil {
+		jsonSchema := map[string]any{
+			"name":   schema.Name,
+			"schema": schema.Schema,
+			"strict": schema.Strict,
+		}
+		if schema.Description != "" {
+			jsonSchema["description"] = schema.Description
+		}
+		requestBody["response_format"] = map[string]any{
+			"type":        "json_schema",
+			"json_schema": jsonSchema,
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	if p.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	}
+	return requestBody
+}
 
-	resp, err := p.httpClient.Do(req)
+// post sends a request to path (e.g. "/chat/completions"), retrying
+// transient failures (see retry.go). The caller owns the response body.
+func (p *Provider) post(ctx context.Context, path string, requestBody map[string]any) (*http.Response, error) {
+	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	endpoint := p.apiBase + path
+	if len(p.queryParams) > 0 {
+		endpoint += "?" + p.queryParams.Encode()
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if err := p.setAuthHeaders(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := p.httpClient.Do(req)
+		last := attempt >= p.maxAttempts
+		if err != nil {
+			if last || !isRetryableError(ctx, err) {
+				return nil, fmt.Errorf("failed to send request: %w", err)
+			}
+			if err := sleepContext(ctx, p.backoff(attempt)); err != nil {
+				return nil, fmt.Errorf("failed to send request: %w", err)
+			}
+			continue
+		}
+		if last || !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		delay, ok := p.retryDelay(resp, attempt)
+		if !ok {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
 	}
+}
 
-	return parseResponse(body)
+// setAuthHeaders adds the API key and any configured extra headers.
+func (p *Provider) setAuthHeaders(req *http.Request) error {
+	for k, v := range p.extraHeaders {
+		req.Header.Set(k, v)
+	}
+	apiKey := p.apiKey
+	if p.tokenSource != nil {
+		token, err := p.tokenSource(req.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get API token: %w", err)
+		}
+		apiKey = token
+	}
+	if apiKey != "" {
+		if p.apiKeyHeader != "" {
+			req.Header.Set(p.apiKeyHeader, apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	}
+	return nil
 }
 
 func parseResponse(body []byte) (*LLMResponse, error) {
@@ -214,9 +486,11 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 					} `json:"extra_content"`
 				} `json:"tool_calls"`
 			} `json:"message"`
-			FinishReason string `json:"finish_reason"`
+			FinishReason string       `json:"finish_reason"`
+			Logprobs     *apiLogprobs `json:"logprobs"`
 		} `json:"choices"`
-		Usage *UsageInfo `json:"usage"`
+		Usage    *apiUsage `json:"usage"`
+		Provider string    `json:"provider"` // OpenRouter: upstream that served the request
 	}
 
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
@@ -227,6 +501,8 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 		return &LLMResponse{
 			Content:      "",
 			FinishReason: "stop",
+			Usage:        apiResponse.Usage.info(),
+			Upstream:     apiResponse.Provider,
 		}, nil
 	}
 
@@ -271,28 +547,110 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 		toolCalls = append(toolCalls, toolCall)
 	}
 
+	content, reasoning := choice.Message.Content, choice.Message.ReasoningContent
+	if reasoning == "" {
+		content, reasoning = splitThinkTags(content)
+	}
+
 	return &LLMResponse{
-		Content:          choice.Message.Content,
-		ReasoningContent: choice.Message.ReasoningContent,
+		Content:          content,
+		ReasoningContent: reasoning,
 		ToolCalls:        toolCalls,
 		FinishReason:     choice.FinishReason,
-		Usage:            apiResponse.Usage,
+		Usage:            apiResponse.Usage.info(),
+		Upstream:         apiResponse.Provider,
+		Logprobs:         choice.Logprobs.tokens(),
 	}, nil
 }
 
+// apiLogprobs is the wire-format logprobs block of a choice.
+type apiLogprobs struct {
+	Content []TokenLogprob `json:"content"`
+}
+
+func (l *apiLogprobs) tokens() []TokenLogprob {
+	if l == nil || len(l.Content) == 0 {
+		return nil
+	}
+	return l.Content
+}
+
+// logitBias merges the per-request options["logit_bias"] (token ID to bias,
+// as map[string]float64 or the map[string]any a JSON round trip produces)
+// over the configured defaults.
+func logitBias(defaults map[string]float64, option any) map[string]float64 {
+	var perRequest map[string]float64
+	switch v := option.(type) {
+	case map[string]float64:
+		perRequest = v
+	case map[string]any:
+		perRequest = make(map[string]float64, len(v))
+		for token, bias := range v {
+			if f, ok := asFloat(bias); ok {
+				perRequest[token] = f
+			}
+		}
+	}
+	if len(perRequest) == 0 {
+		return defaults
+	}
+	if len(defaults) == 0 {
+		return perRequest
+	}
+	merged := make(map[string]float64, len(defaults)+len(perRequest))
+	for token, bias := range defaults {
+		merged[token] = bias
+	}
+	for token, bias := range perRequest {
+		merged[token] = bias
+	}
+	return merged
+}
+
+// apiUsage is the wire-format usage block. Reasoning models report their
+// thinking tokens, already counted in completion_tokens, in the details.
+type apiUsage struct {
+	UsageInfo
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details,omitempty"`
+}
+
+func (u *apiUsage) info() *UsageInfo {
+	if u == nil {
+		return nil
+	}
+	info := u.UsageInfo
+	if u.CompletionTokensDetails != nil {
+		info.ThinkingTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return &info
+}
+
 // openaiMessage is the wire-format message for OpenAI-compatible APIs.
 // It mirrors protocoltypes.Message but omits SystemParts, which is an
 // internal field that would be unknown to third-party endpoints.
 type openaiMessage struct {
 	Role       string     `json:"role"`
-	Content    string     `json:"content"`
+	Content    any        `json:"content"` // string, or []contentPart when images are attached
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
 
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
 // stripSystemParts converts []Message to []openaiMessage, dropping the
 // SystemParts field so it doesn't leak into the JSON payload sent to
 // OpenAI-compatible APIs (some strict endpoints reject unknown fields).
+// Messages with images use the multi-part content format.
 func stripSystemParts(messages []Message) []openaiMessage {
 	out := make([]openaiMessage, len(messages))
 	for i, m := range messages {
@@ -302,10 +660,31 @@ func stripSystemParts(messages []Message) []openaiMessage {
 			ToolCalls:  m.ToolCalls,
 			ToolCallID: m.ToolCallID,
 		}
+		if len(m.Images) > 0 {
+			out[i].Content = contentParts(m)
+		}
 	}
 	return out
 }
 
+func contentParts(m Message) []contentPart {
+	parts := make([]contentPart, 0, 1+len(m.Images))
+	if m.Content != "" {
+		parts = append(parts, contentPart{Type: "text", Text: m.Content})
+	}
+	for _, img := range m.Images {
+		url := img.URL
+		if img.Data != "" {
+			url = "data:" + img.MIMEType + ";base64," + img.Data
+		}
+		if url == "" {
+			continue
+		}
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+	}
+	return parts
+}
+
 func normalizeModel(model, apiBase string) string {
 	idx := strings.Index(model, "/")
 	if idx == -1 {
@@ -318,7 +697,7 @@ func normalizeModel(model, apiBase string) string {
 
 	prefix := strings.ToLower(model[:idx])
 	switch prefix {
-	case "moonshot", "nvidia", "groq", "ollama", "deepseek", "google", "openrouter", "zhipu", "mistral":
+	case "moonshot", "nvidia", "groq", "ollama", "deepseek", "xai", "google", "openrouter", "zhipu", "mistral":
 		return model[idx+1:]
 	default:
 		return model
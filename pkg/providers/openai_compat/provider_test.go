@@ -1,6 +1,7 @@
 package openai_compat
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -146,6 +147,36 @@ func TestProviderChat_ParsesReasoningContent(t *testing.T) {
 	}
 }
 
+func TestStripSystemParts_ImagesUseContentParts(t *testing.T) {
+	msgs := stripSystemParts([]Message{
+		{Role: "system", Content: "sys"},
+		{
+			Role:    "user",
+			Content: "what is this?",
+			Images: []ImagePart{
+				{MIMEType: "image/png", Data: "aGVsbG8="},
+				{URL: "https://example.com/cat.jpg"},
+			},
+		},
+	})
+
+	if _, ok := msgs[0].Content.(string); !ok {
+		t.Errorf("system content = %#v, want plain string", msgs[0].Content)
+	}
+
+	data, err := json.Marshal(msgs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"user","content":[` +
+		`{"type":"text","text":"what is this?"},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,aGVsbG8="}},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/cat.jpg"}}]}`
+	if string(data) != want {
+		t.Errorf("user message =\n%s\nwant\n%s", data, want)
+	}
+}
+
 func TestProviderChat_HTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad request", http.StatusBadRequest)
@@ -277,6 +308,50 @@ func TestProvider_ProxyConfigured(t *testing.T) {
 	}
 }
 
+func TestProvider_TLSConfigKeepsProxy(t *testing.T) {
+	proxyURL := "http://127.0.0.1:8080"
+	tlsCfg := &tls.Config{ServerName: "gateway.internal"}
+	p := NewProvider("key", "https://example.com", proxyURL, WithTLSConfig(tlsCfg))
+
+	transport, ok := p.httpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig != tlsCfg {
+		t.Fatalf("transport = %#v", p.httpClient.Transport)
+	}
+	req := &http.Request{URL: &url.URL{Scheme: "https", Host: "api.example.com"}}
+	if gotProxy, _ := transport.Proxy(req); gotProxy == nil || gotProxy.String() != proxyURL {
+		t.Errorf("proxy = %v, want %s", gotProxy, proxyURL)
+	}
+
+	// Without a proxy, the default transport is cloned rather than modified.
+	p = NewProvider("key", "https://example.com", "", WithTLSConfig(tlsCfg))
+	if p.httpClient.Transport == http.DefaultTransport || http.DefaultTransport.(*http.Transport).TLSClientConfig == tlsCfg {
+		t.Error("WithTLSConfig modified http.DefaultTransport")
+	}
+}
+
+func TestProviderChat_ExtraHeadersMerge(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "",
+		WithExtraHeaders(map[string]string{"X-Title": "picoclaw", "X-Org-Id": "old"}),
+		WithExtraHeaders(map[string]string{"X-Org-Id": "org-42", "Traceparent": "00-abc-def-01"}),
+	)
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	for k, want := range map[string]string{"X-Title": "picoclaw", "X-Org-Id": "org-42", "Traceparent": "00-abc-def-01"} {
+		if got.Get(k) != want {
+			t.Errorf("header %s = %q, want %q", k, got.Get(k), want)
+		}
+	}
+}
+
 func TestProviderChat_AcceptsNumericOptionTypes(t *testing.T) {
 	var requestBody map[string]any
 
@@ -361,3 +436,174 @@ func TestProvider_FunctionalOptionRequestTimeoutNonPositive(t *testing.T) {
 		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, defaultRequestTimeout)
 	}
 }
+
+func TestProviderChat_SendsResponseFormatForSchema(t *testing.T) {
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": `{"ok":true}`}, "finish_reason": "stop"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	schema := &ResponseSchema{
+		Name:   "verdict",
+		Schema: map[string]any{"type": "object", "properties": map[string]any{"ok": map[string]any{"type": "boolean"}}},
+		Strict: true,
+	}
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o",
+		map[string]any{"response_schema": schema})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	format, ok := requestBody["response_format"].(map[string]any)
+	if !ok || format["type"] != "json_schema" {
+		t.Fatalf("response_format = %v", requestBody["response_format"])
+	}
+	js := format["json_schema"].(map[string]any)
+	if js["name"] != "verdict" || js["strict"] != true || js["schema"] == nil {
+		t.Errorf("json_schema = %v", js)
+	}
+}
+
+func TestProviderChat_ReasoningEffortAndThinkingTokens(t *testing.T) {
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"42"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":300,"total_tokens":310,` +
+			`"completion_tokens_details":{"reasoning_tokens":256}}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithReasoningEffort("high"))
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "o4-mini", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if requestBody["reasoning_effort"] != "high" {
+		t.Errorf("reasoning_effort = %v, want high", requestBody["reasoning_effort"])
+	}
+	if resp.Usage == nil || resp.Usage.CompletionTokens != 300 || resp.Usage.ThinkingTokens != 256 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestBuildRequestBody_OpenRouterReasoningObject(t *testing.T) {
+	p := NewProvider("key", "https://openrouter.ai/api/v1", "", WithReasoningEffort("low"))
+	body := p.buildRequestBody([]Message{{Role: "user", Content: "hi"}}, nil, "openai/o3", nil)
+
+	if _, ok := body["reasoning_effort"]; ok {
+		t.Error("reasoning_effort sent to OpenRouter")
+	}
+	reasoning, ok := body["reasoning"].(map[string]any)
+	if !ok || reasoning["effort"] != "low" {
+		t.Errorf("reasoning = %v", body["reasoning"])
+	}
+}
+
+func TestBuildRequestBody_StopSequences(t *testing.T) {
+	p := NewProvider("key", "https://api.example.com/v1", "")
+	body := p.buildRequestBody([]Message{{Role: "user", Content: "hi"}}, nil, "local-model",
+		map[string]any{"stop": []any{"<|im_end|>", ""}})
+
+	stop, ok := body["stop"].([]string)
+	if !ok || len(stop) != 1 || stop[0] != "<|im_end|>" {
+		t.Errorf("stop = %#v", body["stop"])
+	}
+}
+
+func TestBuildRequestBody_LogprobsAndLogitBias(t *testing.T) {
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	p := NewProvider("key", "https://api.example.com/v1", "")
+	body := p.buildRequestBody(msgs, nil, "gpt-4o", nil)
+	if _, ok := body["logprobs"]; ok {
+		t.Errorf("logprobs sent without being requested: %v", body)
+	}
+
+	p = NewProvider("key", "https://api.example.com/v1", "",
+		WithLogprobs(3),
+		WithLogitBias(map[string]float64{"9642": 5, "2822": 5}),
+	)
+	body = p.buildRequestBody(msgs, nil, "gpt-4o", nil)
+	if body["logprobs"] != true || body["top_logprobs"] != 3 {
+		t.Errorf("logprobs = %v, top_logprobs = %v", body["logprobs"], body["top_logprobs"])
+	}
+
+	// Per-request options override the configured defaults.
+	body = p.buildRequestBody(msgs, nil, "gpt-4o", map[string]any{
+		"top_logprobs": 5,
+		"logit_bias":   map[string]any{"2822": -100.0},
+	})
+	if body["top_logprobs"] != 5 {
+		t.Errorf("top_logprobs = %v, want 5", body["top_logprobs"])
+	}
+	bias, _ := body["logit_bias"].(map[string]float64)
+	if bias["9642"] != 5 || bias["2822"] != -100 {
+		t.Errorf("logit_bias = %v", body["logit_bias"])
+	}
+
+	body = p.buildRequestBody(msgs, nil, "gpt-4o", map[string]any{"logprobs": false})
+	if _, ok := body["logprobs"]; ok {
+		t.Errorf("logprobs sent after being disabled per request")
+	}
+}
+
+func TestProviderChat_ParsesLogprobs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"yes"},"finish_reason":"stop",` +
+			`"logprobs":{"content":[{"token":"yes","logprob":-0.05,"bytes":[121,101,115],` +
+			`"top_logprobs":[{"token":"yes","logprob":-0.05},{"token":"no","logprob":-3.2}]}]}}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithLogprobs(2))
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(resp.Logprobs) != 1 {
+		t.Fatalf("Logprobs = %+v", resp.Logprobs)
+	}
+	lp := resp.Logprobs[0]
+	if lp.Token != "yes" || lp.Logprob != -0.05 || len(lp.TopLogprobs) != 2 || lp.TopLogprobs[1].Token != "no" {
+		t.Errorf("Logprobs[0] = %+v", lp)
+	}
+}
+
+func TestBuildRequestBody_ServerToolsAndWebSearch(t *testing.T) {
+	p := NewProvider("key", "https://api.example.com/v1", "",
+		WithServerTools([]map[string]any{{"type": "file_search", "vector_store_ids": []string{"vs_1"}}}),
+		WithWebSearch(map[string]any{}))
+	tools := []ToolDefinition{{Type: "function", Function: ToolFunctionDefinition{Name: "read_file"}}}
+	body := p.buildRequestBody([]Message{{Role: "user", Content: "hi"}}, tools, "gpt-4o", nil)
+
+	all, ok := body["tools"].([]any)
+	if !ok || len(all) != 2 {
+		t.Fatalf("tools = %#v", body["tools"])
+	}
+	if st, ok := all[1].(map[string]any); !ok || st["type"] != "file_search" {
+		t.Errorf("server tool = %#v", all[1])
+	}
+	if _, ok := body["web_search_options"]; !ok {
+		t.Error("expected web_search_options")
+	}
+
+	body = NewProvider("key", "https://api.example.com/v1", "").
+		buildRequestBody([]Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if _, ok := body["web_search_options"]; ok {
+		t.Error("web_search_options sent without WithWebSearch")
+	}
+}
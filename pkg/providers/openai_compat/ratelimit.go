@@ -0,0 +1,114 @@
+package openai_compat
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// HTTPError is returned for non-2xx API responses. Its message keeps the
+// "API request failed" format that the error classifier parses.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the server's hint for when to retry, from Retry-After or
+	// provider-specific rate-limit reset headers. Zero when unknown.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API request failed:\n  Status: %d\n  Body:   %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the error's kind (protocoltypes.ErrAuth etc.), if
+// recognized.
+func (e *HTTPError) Unwrap() error {
+	return protocoltypes.ClassifyError(e.StatusCode, e.Body)
+}
+
+// RetryAfterHint reports how long the server asked callers to wait.
+func (e *HTTPError) RetryAfterHint() time.Duration {
+	return e.RetryAfter
+}
+
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	err := &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter = retryAfter(resp.Header, time.Now())
+	}
+	return err
+}
+
+// rateLimitBuckets pairs the non-standard reset headers with the header
+// telling whether that bucket is exhausted.
+//
+// Groq (and OpenAI) send durations such as "2m59.56s" or "120ms"; Cerebras
+// sends seconds per day/minute bucket.
+var rateLimitBuckets = []struct {
+	reset     string
+	remaining string
+}{
+	{"x-ratelimit-reset-requests", "x-ratelimit-remaining-requests"},
+	{"x-ratelimit-reset-tokens", "x-ratelimit-remaining-tokens"},
+	{"x-ratelimit-reset-requests-day", "x-ratelimit-remaining-requests-day"},
+	{"x-ratelimit-reset-tokens-minute", "x-ratelimit-remaining-tokens-minute"},
+}
+
+// retryAfter derives a retry delay from response headers. The standard
+// Retry-After header wins; otherwise the reset time of an exhausted rate-limit
+// bucket is used, falling back to the soonest reset.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			if d := t.Sub(now); d > 0 {
+				return d
+			}
+			return 0
+		}
+	}
+
+	var exhausted, soonest time.Duration
+	for _, b := range rateLimitBuckets {
+		d, ok := parseResetDuration(h.Get(b.reset))
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(h.Get(b.remaining)) == "0" && d > exhausted {
+			exhausted = d
+		}
+		if soonest == 0 || d < soonest {
+			soonest = d
+		}
+	}
+	if exhausted > 0 {
+		return exhausted
+	}
+	return soonest
+}
+
+// parseResetDuration accepts Go-style durations ("1m30.5s", "120ms") and bare
+// seconds ("7.66").
+func parseResetDuration(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,83 @@
+package openai_compat
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{"none", nil, 0},
+		{"retry-after seconds", map[string]string{"Retry-After": "3"}, 3 * time.Second},
+		{"retry-after date", map[string]string{"Retry-After": "Thu, 01 Jan 2026 00:00:10 GMT"}, 10 * time.Second},
+		{
+			"groq exhausted tokens",
+			map[string]string{
+				"x-ratelimit-reset-requests":     "2m59.56s",
+				"x-ratelimit-remaining-requests": "10",
+				"x-ratelimit-reset-tokens":       "7.66s",
+				"x-ratelimit-remaining-tokens":   "0",
+			},
+			7660 * time.Millisecond,
+		},
+		{
+			"cerebras seconds",
+			map[string]string{
+				"x-ratelimit-reset-requests-day":      "33011.4",
+				"x-ratelimit-reset-tokens-minute":     "11.4",
+				"x-ratelimit-remaining-tokens-minute": "0",
+			},
+			11400 * time.Millisecond,
+		},
+		{
+			"nothing exhausted uses soonest reset",
+			map[string]string{"x-ratelimit-reset-requests": "1s", "x-ratelimit-reset-tokens": "250ms"},
+			250 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			if got := retryAfter(h, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderChat_RateLimitErrorCarriesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-reset-tokens", "2s")
+		w.Header().Set("x-ratelimit-remaining-tokens", "0")
+		http.Error(w, `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithMaxAttempts(1))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama", nil)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("error = %v, want *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests || httpErr.RetryAfter != 2*time.Second {
+		t.Errorf("HTTPError = %+v", httpErr)
+	}
+	if !errors.Is(err, protocoltypes.ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,74 @@
+package openai_compat
+
+import "strings"
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// splitThinkTags separates a leading <think>...</think> block, which
+// DeepSeek-R1 style models emit inline when served without a reasoning
+// parser (e.g. by Ollama or vLLM), from the visible answer. An unterminated
+// block is all reasoning: the model ran out of tokens while thinking.
+func splitThinkTags(content string) (answer, reasoning string) {
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+	if !strings.HasPrefix(trimmed, thinkOpen) {
+		return content, ""
+	}
+	rest := trimmed[len(thinkOpen):]
+	end := strings.Index(rest, thinkClose)
+	if end == -1 {
+		return "", strings.TrimSpace(rest)
+	}
+	return strings.TrimLeft(rest[end+len(thinkClose):], " \t\r\n"), strings.TrimSpace(rest[:end])
+}
+
+// thinkFilter hides a leading <think> block from streamed deltas, matching
+// what splitThinkTags does to the assembled content.
+type thinkFilter struct {
+	state int // 0: undecided, 1: inside <think>, 2: trimming after </think>, 3: pass through
+	buf   string
+}
+
+// visible returns the part of delta that should be shown to the user.
+func (f *thinkFilter) visible(delta string) string {
+	switch f.state {
+	case 0:
+		f.buf += delta
+		trimmed := strings.TrimLeft(f.buf, " \t\r\n")
+		if len(trimmed) < len(thinkOpen) && strings.HasPrefix(thinkOpen, trimmed) {
+			return ""
+		}
+		if !strings.HasPrefix(trimmed, thinkOpen) {
+			f.state = 3
+			out := f.buf
+			f.buf = ""
+			return out
+		}
+		f.state = 1
+		f.buf = trimmed[len(thinkOpen):]
+		return f.visible("")
+
+	case 1:
+		f.buf += delta
+		end := strings.Index(f.buf, thinkClose)
+		if end == -1 {
+			return ""
+		}
+		f.state = 2
+		rest := f.buf[end+len(thinkClose):]
+		f.buf = ""
+		return f.visible(rest)
+
+	case 2:
+		out := strings.TrimLeft(delta, " \t\r\n")
+		if out != "" {
+			f.state = 3
+		}
+		return out
+
+	default:
+		return delta
+	}
+}
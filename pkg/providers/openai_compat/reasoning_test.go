@@ -0,0 +1,85 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitThinkTags(t *testing.T) {
+	tests := []struct {
+		name, content, answer, reasoning string
+	}{
+		{"no block", "Hello", "Hello", ""},
+		{"block", "<think>\nuser greets me\n</think>\n\nHello!", "Hello!", "user greets me"},
+		{"leading whitespace", "\n <think>x</think>y", "y", "x"},
+		{"unterminated", "<think>still thinking", "", "still thinking"},
+		{"tag later in text", "Use <think> tags", "Use <think> tags", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, reasoning := splitThinkTags(tt.content)
+			if answer != tt.answer || reasoning != tt.reasoning {
+				t.Errorf("splitThinkTags(%q) = (%q, %q), want (%q, %q)",
+					tt.content, answer, reasoning, tt.answer, tt.reasoning)
+			}
+		})
+	}
+}
+
+func TestThinkFilter_SplitTags(t *testing.T) {
+	var f thinkFilter
+	var out strings.Builder
+	for _, d := range []string{"<th", "ink>plan", "ning</thi", "nk>\n\nHel", "lo"} {
+		out.WriteString(f.visible(d))
+	}
+	if out.String() != "Hello" {
+		t.Errorf("visible = %q, want %q", out.String(), "Hello")
+	}
+
+	var plain thinkFilter
+	if got := plain.visible("<"); got != "" {
+		t.Errorf("ambiguous prefix should be held back, got %q", got)
+	}
+	if got := plain.visible("b>bold"); got != "<b>bold" {
+		t.Errorf("visible = %q, want %q", got, "<b>bold")
+	}
+}
+
+func TestProviderChat_DeepSeekInlineThinking(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message":       map[string]any{"content": "<think>2+2 is 4</think>\n\nIt's 4."},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	out, err := p.Chat(t.Context(), []Message{
+		{Role: "user", Content: "1+1?"},
+		{Role: "assistant", Content: "2", ReasoningContent: "simple"},
+		{Role: "user", Content: "2+2?"},
+	}, nil, "deepseek/deepseek-r1:1.5b", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if out.Content != "It's 4." || out.ReasoningContent != "2+2 is 4" {
+		t.Errorf("Content = %q, ReasoningContent = %q", out.Content, out.ReasoningContent)
+	}
+
+	// DeepSeek rejects reasoning_content in input messages.
+	msgs := requestBody["messages"].([]any)
+	if _, ok := msgs[1].(map[string]any)["reasoning_content"]; ok {
+		t.Error("reasoning_content must not be sent back to the API")
+	}
+	if requestBody["model"] != "deepseek-r1:1.5b" {
+		t.Errorf("model = %v", requestBody["model"])
+	}
+}
@@ -0,0 +1,82 @@
+package openai_compat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+
+	// maxRetryDelay caps a single wait. A longer Retry-After means the
+	// provider is out of quota for a while, so the error is returned and
+	// left to the fallback chain instead of stalling the agent turn.
+	maxRetryDelay = 30 * time.Second
+)
+
+// isRetryableStatus reports whether a response status is worth retrying:
+// rate limits and transient upstream failures.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether a transport error is a dropped connection.
+// Timeouts and cancellation are not retried: the request budget is spent.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryDelay picks the wait before retrying resp. A server-provided
+// Retry-After (or rate-limit reset) hint wins over backoff; ok is false when
+// that hint exceeds maxRetryDelay.
+func (p *Provider) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if hint := retryAfter(resp.Header, time.Now()); hint > 0 {
+			return hint, hint <= maxRetryDelay
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+// backoff returns an exponential delay with jitter for the given attempt
+// (1-based): a random duration in [d/2, d] where d = base * 2^(attempt-1).
+func (p *Provider) backoff(attempt int) time.Duration {
+	d := p.retryBaseDelay << (attempt - 1)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
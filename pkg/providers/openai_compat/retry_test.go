@@ -0,0 +1,129 @@
+package openai_compat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const okResponse = `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`
+
+func TestProviderChat_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "upstream unavailable", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(okResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	p.retryBaseDelay = time.Millisecond
+
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok" || calls.Load() != 3 {
+		t.Errorf("Content = %q after %d calls, want %q after 3", resp.Content, calls.Load(), "ok")
+	}
+}
+
+func TestProviderChat_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithMaxAttempts(2))
+	p.retryBaseDelay = time.Millisecond
+
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if err == nil {
+		t.Fatal("Chat() error = nil, want error")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestProviderChat_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	p.retryBaseDelay = time.Millisecond
+
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil); err == nil {
+		t.Fatal("Chat() error = nil, want error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestProviderChat_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	var waited time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "0.2")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		waited = time.Since(first)
+		w.Write([]byte(okResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	p.retryBaseDelay = time.Millisecond
+
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if waited < 200*time.Millisecond {
+		t.Errorf("retried after %v, want >= 200ms", waited)
+	}
+}
+
+func TestProviderChat_LongRetryAfterIsReturned(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "quota exhausted", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if err == nil {
+		t.Fatal("Chat() error = nil, want error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestBackoff_GrowsAndIsCapped(t *testing.T) {
+	p := &Provider{retryBaseDelay: 100 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 20: maxRetryDelay} {
+		got := p.backoff(attempt)
+		if got < want/2 || got > want {
+			t.Errorf("backoff(%d) = %v, want in [%v, %v]", attempt, got, want/2, want)
+		}
+	}
+}
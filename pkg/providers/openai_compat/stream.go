@@ -0,0 +1,224 @@
+package openai_compat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/sse"
+)
+
+// StreamChat performs a streaming chat completion. onDelta is called with
+// each content delta as it arrives; the returned response is the fully
+// assembled completion, including any tool calls.
+func (p *Provider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(delta string),
+) (*LLMResponse, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	requestBody := p.buildRequestBody(messages, tools, model, options)
+	requestBody["stream"] = true
+	requestBody["stream_options"] = map[string]any{"include_usage": true}
+
+	resp, err := p.post(ctx, "/chat/completions", requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newHTTPError(resp, body)
+	}
+
+	return readStream(resp.Body, onDelta, protocoltypes.ToolCallDeltas(ctx))
+}
+
+// streamToolCall accumulates the fragments of one streamed tool call.
+type streamToolCall struct {
+	ID               string
+	Type             string
+	Name             string
+	Arguments        string
+	ThoughtSignature string
+}
+
+// readStream consumes an OpenAI-style SSE stream and reassembles it into the
+// non-streaming response shape, which is then decoded by parseResponse so
+// both paths share the same tool-call handling. onToolCall, if set, sees each
+// tool-call fragment and may abort the stream.
+func readStream(
+	r io.Reader,
+	onDelta func(delta string),
+	onToolCall func(protocoltypes.ToolCallDelta) error,
+) (*LLMResponse, error) {
+	var (
+		content      []byte
+		reasoning    []byte
+		finishReason string
+		upstream     string
+		usage        *apiUsage
+		toolCalls    = map[int]*streamToolCall{}
+		logprobs     []TokenLogprob
+		think        thinkFilter
+	)
+
+	err := sse.Read(r, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content          string `json:"content"`
+					ReasoningContent string `json:"reasoning_content"`
+					ToolCalls        []struct {
+						Index    int    `json:"index"`
+						ID       string `json:"id"`
+						Type     string `json:"type"`
+						Function *struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+						ExtraContent *struct {
+							Google *struct {
+								ThoughtSignature string `json:"thought_signature"`
+							} `json:"google"`
+						} `json:"extra_content"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason *string      `json:"finish_reason"`
+				Logprobs     *apiLogprobs `json:"logprobs"`
+			} `json:"choices"`
+			Usage    *apiUsage `json:"usage"`
+			Provider string    `json:"provider"`
+			Error    *struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+				Code    any    `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if e := chunk.Error; e != nil {
+			// Errors after the response started come in-band, e.g. OpenRouter
+			// reporting an upstream failure.
+			return protocoltypes.WithKind(fmt.Errorf("stream error: %s", e.Message),
+				protocoltypes.ClassifyError(0, fmt.Sprintf("%s %v %s", e.Type, e.Code, e.Message)))
+		}
+
+		if chunk.Provider != "" {
+			upstream = chunk.Provider
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content = append(content, choice.Delta.Content...)
+				if visible := think.visible(choice.Delta.Content); visible != "" && onDelta != nil {
+					onDelta(visible)
+				}
+			}
+			reasoning = append(reasoning, choice.Delta.ReasoningContent...)
+			if choice.Logprobs != nil {
+				logprobs = append(logprobs, choice.Logprobs.Content...)
+			}
+
+			for _, tc := range choice.Delta.ToolCalls {
+				acc, ok := toolCalls[tc.Index]
+				if !ok {
+					acc = &streamToolCall{}
+					toolCalls[tc.Index] = acc
+				}
+				if tc.ID != "" {
+					acc.ID = tc.ID
+				}
+				if tc.Type != "" {
+					acc.Type = tc.Type
+				}
+				if tc.Function != nil {
+					acc.Name += tc.Function.Name
+					acc.Arguments += tc.Function.Arguments
+				}
+				if tc.ExtraContent != nil && tc.ExtraContent.Google != nil {
+					acc.ThoughtSignature = tc.ExtraContent.Google.ThoughtSignature
+				}
+				if onToolCall != nil && tc.Function != nil {
+					err := onToolCall(protocoltypes.ToolCallDelta{
+						Index:     tc.Index,
+						ID:        tc.ID,
+						Name:      tc.Function.Name,
+						Arguments: tc.Function.Arguments,
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				finishReason = *choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	indexes := make([]int, 0, len(toolCalls))
+	for idx := range toolCalls {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	assembledCalls := make([]map[string]any, 0, len(indexes))
+	for _, idx := range indexes {
+		tc := toolCalls[idx]
+		call := map[string]any{
+			"id":       tc.ID,
+			"type":     tc.Type,
+			"function": map[string]any{"name": tc.Name, "arguments": tc.Arguments},
+		}
+		if tc.ThoughtSignature != "" {
+			call["extra_content"] = map[string]any{
+				"google": map[string]any{"thought_signature": tc.ThoughtSignature},
+			}
+		}
+		assembledCalls = append(assembledCalls, call)
+	}
+
+	assembled, err := json.Marshal(map[string]any{
+		"choices": []map[string]any{{
+			"message": map[string]any{
+				"content":           string(content),
+				"reasoning_content": string(reasoning),
+				"tool_calls":        assembledCalls,
+			},
+			"finish_reason": finishReason,
+			"logprobs":      apiLogprobs{Content: logprobs},
+		}},
+		"usage":    usage,
+		"provider": upstream,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble stream response: %w", err)
+	}
+
+	return parseResponse(assembled)
+}
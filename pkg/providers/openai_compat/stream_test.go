@@ -0,0 +1,166 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestProviderStreamChat_AssemblesContentAndToolCalls(t *testing.T) {
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"choices":[{"delta":{"content":"Hel"}}]}`,
+			`{"choices":[{"delta":{"content":"lo"}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function",` +
+				`"function":{"name":"get_weather","arguments":"{\"ci"}}]}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ty\":\"SF\"}"}}]},` +
+				`"finish_reason":"tool_calls"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3,"total_tokens":10}}`,
+			`[DONE]`,
+		}
+		for _, c := range chunks {
+			w.Write([]byte("data: " + c + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	var deltas []string
+	resp, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil,
+		func(delta string) { deltas = append(deltas, delta) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+
+	if requestBody["stream"] != true {
+		t.Errorf("stream = %v, want true", requestBody["stream"])
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("deltas = %v", deltas)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != "tool_calls" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "get_weather" ||
+		resp.ToolCalls[0].Arguments["city"] != "SF" {
+		t.Fatalf("ToolCalls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 10 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderStreamChat_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "", WithMaxAttempts(1))
+	_, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "Status: 429") {
+		t.Fatalf("StreamChat() error = %v, want status 429", err)
+	}
+}
+
+func TestProviderStreamChat_HidesInlineThinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, d := range []string{"<think>", "hmm", "</think>", "\n\nHi", " there"} {
+			chunk, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]any{"content": d}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var deltas []string
+	p := NewProvider("", server.URL, "")
+	out, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil,
+		func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if strings.Join(deltas, "") != "Hi there" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if out.Content != "Hi there" || out.ReasoningContent != "hmm" {
+		t.Errorf("Content = %q, ReasoningContent = %q", out.Content, out.ReasoningContent)
+	}
+}
+
+func TestProviderStreamChat_AccumulatesLogprobs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"choices":[{"delta":{"content":"Y"},"logprobs":{"content":[{"token":"Y","logprob":-0.1}]}}]}`,
+			`{"choices":[{"delta":{"content":"es"},"logprobs":{"content":[{"token":"es","logprob":-0.01}]},` +
+				`"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		}
+		for _, c := range chunks {
+			w.Write([]byte("data: " + c + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	resp, err := p.StreamChat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o",
+		map[string]any{"logprobs": true}, nil)
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if len(resp.Logprobs) != 2 || resp.Logprobs[0].Token != "Y" || resp.Logprobs[1].Logprob != -0.01 {
+		t.Errorf("Logprobs = %+v", resp.Logprobs)
+	}
+}
+
+func TestProviderStreamChat_ToolCallDeltasCanAbort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function",` +
+				`"function":{"name":"get_weather","arguments":"{\"city\" "}}]}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"SF}"}}]}}]}`,
+			`[DONE]`,
+		}
+		for _, c := range chunks {
+			w.Write([]byte("data: " + c + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	abort := errors.New("malformed")
+	var got []protocoltypes.ToolCallDelta
+	ctx := protocoltypes.WithToolCallDeltas(t.Context(), func(d protocoltypes.ToolCallDelta) error {
+		got = append(got, d)
+		if strings.Contains(d.Arguments, "SF}") {
+			return abort
+		}
+		return nil
+	})
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.StreamChat(ctx, []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil, func(string) {})
+	if !errors.Is(err, abort) {
+		t.Fatalf("StreamChat() error = %v, want the callback's error", err)
+	}
+	if len(got) != 2 || got[0].ID != "call_1" || got[0].Name != "get_weather" || got[0].Arguments != `{"city" ` {
+		t.Errorf("deltas = %+v", got)
+	}
+}
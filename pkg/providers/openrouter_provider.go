@@ -0,0 +1,46 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+// OpenRouter app attribution headers, see https://openrouter.ai/docs/api-reference/overview
+const (
+	openRouterReferer = "https://github.com/sipeed/picoclaw"
+	openRouterTitle   = "PicoClaw"
+)
+
+// NewOpenRouterProvider creates an HTTPProvider for OpenRouter. It sends the
+// app attribution headers and enables usage accounting, so responses report
+// the upstream provider that served them and the request cost.
+func NewOpenRouterProvider(
+	apiKey, apiBase, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+	opts ...openai_compat.Option,
+) *HTTPProvider {
+	return &HTTPProvider{
+		delegate: openai_compat.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			append([]openai_compat.Option{
+				openai_compat.WithMaxTokensField(maxTokensField),
+				openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second),
+				openai_compat.WithExtraHeaders(map[string]string{
+					"HTTP-Referer": openRouterReferer,
+					"X-Title":      openRouterTitle,
+				}),
+				openai_compat.WithExtraBody(map[string]any{
+					"usage": map[string]any{"include": true},
+				}),
+			}, opts...)...,
+		),
+	}
+}
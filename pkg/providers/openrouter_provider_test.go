@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenRouterProvider_HeadersAndRoutingMetadata(t *testing.T) {
+	var headers http.Header
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"provider":"Anthropic","choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12,"cost":0.00042}}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenRouterProvider("key", server.URL, "", "", 0)
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "anthropic/claude-sonnet-4.6", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if headers.Get("HTTP-Referer") == "" || headers.Get("X-Title") == "" {
+		t.Errorf("missing attribution headers: %v", headers)
+	}
+	if headers.Get("Authorization") != "Bearer key" {
+		t.Errorf("Authorization = %q", headers.Get("Authorization"))
+	}
+	if requestBody["model"] != "anthropic/claude-sonnet-4.6" {
+		t.Errorf("model = %v, want the full OpenRouter model ID", requestBody["model"])
+	}
+	if usage, ok := requestBody["usage"].(map[string]any); !ok || usage["include"] != true {
+		t.Errorf("usage accounting not requested: %v", requestBody["usage"])
+	}
+	if resp.Upstream != "Anthropic" {
+		t.Errorf("Upstream = %q, want Anthropic", resp.Upstream)
+	}
+	if resp.Usage == nil || resp.Usage.Cost != 0.00042 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
@@ -0,0 +1,147 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// PromptProfileProvider applies a model's prompt profile (system prompt
+// prefix and suffix, stop sequences, temperature) to every request before
+// passing it to the wrapped provider.
+type PromptProfileProvider struct {
+	delegate LLMProvider
+	profile  config.PromptProfile
+}
+
+func NewPromptProfileProvider(delegate LLMProvider, profile config.PromptProfile) *PromptProfileProvider {
+	return &PromptProfileProvider{delegate: delegate, profile: profile}
+}
+
+func (p *PromptProfileProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, p.applyMessages(messages), tools, model, p.applyOptions(options))
+}
+
+// StreamChat streams when the wrapped provider supports it and otherwise
+// falls back to Chat.
+func (p *PromptProfileProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	messages, options = p.applyMessages(messages), p.applyOptions(options)
+	if sp, ok := p.delegate.(StreamingProvider); ok {
+		return sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+	}
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *PromptProfileProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
+
+func (p *PromptProfileProvider) SupportsStructuredOutput() bool {
+	sp, ok := p.delegate.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+func (p *PromptProfileProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.delegate)
+}
+
+func (p *PromptProfileProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.delegate)
+}
+
+// Close releases the wrapped provider if it holds resources.
+func (p *PromptProfileProvider) Close() {
+	if sp, ok := p.delegate.(StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// applyMessages wraps the first system message in the profile's prefix and
+// suffix, adding a system message when there is none. The caller's slice is
+// not modified.
+func (p *PromptProfileProvider) applyMessages(messages []Message) []Message {
+	prefix, suffix := p.profile.SystemPrefix, p.profile.SystemSuffix
+	if prefix == "" && suffix == "" {
+		return messages
+	}
+
+	i := slices.IndexFunc(messages, func(m Message) bool { return m.Role == "system" })
+	if i == -1 {
+		return append([]Message{{Role: "system", Content: joinNonEmpty(prefix, suffix)}}, messages...)
+	}
+
+	out := slices.Clone(messages)
+	sys := out[i]
+	sys.Content = joinNonEmpty(prefix, sys.Content, suffix)
+	if len(sys.SystemParts) > 0 {
+		parts := make([]ContentBlock, 0, len(sys.SystemParts)+2)
+		if prefix != "" {
+			parts = append(parts, ContentBlock{Type: "text", Text: prefix})
+		}
+		parts = append(parts, sys.SystemParts...)
+		if suffix != "" {
+			parts = append(parts, ContentBlock{Type: "text", Text: suffix})
+		}
+		sys.SystemParts = parts
+	}
+	out[i] = sys
+	return out
+}
+
+// applyOptions adds the profile's stop sequences to any the request already
+// has and overrides its temperature. The caller's map is not modified.
+func (p *PromptProfileProvider) applyOptions(options map[string]any) map[string]any {
+	if len(p.profile.Stop) == 0 && p.profile.Temperature == nil {
+		return options
+	}
+
+	out := maps.Clone(options)
+	if out == nil {
+		out = make(map[string]any)
+	}
+	if len(p.profile.Stop) > 0 {
+		stop := slices.Clone(protocoltypes.StopOption(options))
+		for _, s := range p.profile.Stop {
+			if !slices.Contains(stop, s) {
+				stop = append(stop, s)
+			}
+		}
+		out["stop"] = stop
+	}
+	if p.profile.Temperature != nil {
+		out["temperature"] = *p.profile.Temperature
+	}
+	return out
+}
+
+func joinNonEmpty(parts ...string) string {
+	var nonEmpty []string
+	for _, s := range parts {
+		if s != "" {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
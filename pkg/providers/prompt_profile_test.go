@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestPromptProfileProvider_WrapsSystemPrompt(t *testing.T) {
+	delegate := &replyProvider{replies: []string{"ok"}}
+	p := NewPromptProfileProvider(delegate, config.PromptProfile{
+		SystemPrefix: "/no_think",
+		SystemSuffix: "Answer in English.",
+	})
+
+	messages := []Message{
+		{Role: "system", Content: "You are picoclaw.", SystemParts: []ContentBlock{{Type: "text", Text: "You are picoclaw."}}},
+		{Role: "user", Content: "hi"},
+	}
+	if _, err := p.Chat(t.Context(), messages, nil, "m", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	sent := delegate.messages[0][0]
+	if want := "/no_think\n\nYou are picoclaw.\n\nAnswer in English."; sent.Content != want {
+		t.Errorf("system content = %q, want %q", sent.Content, want)
+	}
+	if len(sent.SystemParts) != 3 || sent.SystemParts[0].Text != "/no_think" || sent.SystemParts[2].Text != "Answer in English." {
+		t.Errorf("system parts = %+v", sent.SystemParts)
+	}
+	if messages[0].Content != "You are picoclaw." || len(messages[0].SystemParts) != 1 {
+		t.Error("caller's messages were modified")
+	}
+}
+
+func TestPromptProfileProvider_AddsSystemMessage(t *testing.T) {
+	delegate := &replyProvider{replies: []string{"ok"}}
+	p := NewPromptProfileProvider(delegate, config.PromptProfile{SystemPrefix: "Be terse."})
+
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	sent := delegate.messages[0]
+	if len(sent) != 2 || sent[0].Role != "system" || sent[0].Content != "Be terse." {
+		t.Errorf("messages = %+v", sent)
+	}
+}
+
+func TestPromptProfileProvider_Options(t *testing.T) {
+	delegate := &replyProvider{replies: []string{"ok"}}
+	temperature := 0.1
+	p := NewPromptProfileProvider(delegate, config.PromptProfile{
+		Stop:        []string{"<|im_end|>", "</s>"},
+		Temperature: &temperature,
+	})
+
+	options := map[string]any{"temperature": 0.7, "max_tokens": 100, "stop": []string{"</s>"}}
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", options); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	sent := delegate.options[0]
+	if sent["temperature"] != 0.1 || sent["max_tokens"] != 100 {
+		t.Errorf("options = %v", sent)
+	}
+	if stop := sent["stop"].([]string); !slices.Equal(stop, []string{"</s>", "<|im_end|>"}) {
+		t.Errorf("stop = %v", stop)
+	}
+	if options["temperature"] != 0.7 || len(options["stop"].([]string)) != 1 {
+		t.Errorf("caller's options were modified: %v", options)
+	}
+}
+
+func TestCreateProviderFromConfig_PromptProfile(t *testing.T) {
+	provider, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:     "local",
+		Model:         "ollama/qwen3",
+		APIBase:       "http://localhost:11434/v1",
+		PromptProfile: &config.PromptProfile{Stop: []string{"<|im_end|>"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*PromptProfileProvider); !ok {
+		t.Fatalf("expected *PromptProfileProvider, got %T", provider)
+	}
+}
@@ -0,0 +1,31 @@
+package protocoltypes
+
+// BatchRequest is one chat request in a batch. CustomID identifies its
+// result and must be unique within the batch (Anthropic limits it to 64
+// letters, digits, '-' and '_').
+type BatchRequest struct {
+	CustomID string           `json:"custom_id"`
+	Messages []Message        `json:"messages"`
+	Tools    []ToolDefinition `json:"tools,omitempty"`
+	Options  map[string]any   `json:"options,omitempty"`
+}
+
+// BatchStatus is the progress of a submitted batch. Status is the
+// provider's own state name; Done is set once no more requests will be
+// processed, whether the batch completed, failed, expired or was cancelled.
+type BatchStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Done      bool   `json:"done"`
+	Total     int    `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// BatchResult is the outcome of one BatchRequest: a response, or an error
+// message when the request failed, expired or was cancelled.
+type BatchResult struct {
+	CustomID string       `json:"custom_id"`
+	Response *LLMResponse `json:"response,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
@@ -0,0 +1,101 @@
+package protocoltypes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Provider errors wrap one of these kinds when the failure is recognized,
+// so callers can react with errors.Is instead of matching messages.
+var (
+	// ErrAuth: the API key or token was rejected (401/403).
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimited: too many requests or quota exhausted (429).
+	ErrRateLimited = errors.New("rate limited")
+	// ErrContextTooLong: the prompt doesn't fit the model's context window.
+	ErrContextTooLong = errors.New("context too long")
+	// ErrContentFiltered: the provider's safety filter blocked the request.
+	ErrContentFiltered = errors.New("content filtered")
+	// ErrOverloaded: the provider is temporarily out of capacity (503/529).
+	ErrOverloaded = errors.New("provider overloaded")
+)
+
+var (
+	reContextTooLong = regexp.MustCompile(`(?i)context[_ ]length|maximum context|context window|context size|` +
+		`prompt is too long|input is too long|too many (input )?tokens|exceeds the maximum number of tokens|` +
+		`reduce the length|exceeds? (the )?max(imum)?( message)? tokens`)
+	reContentFiltered = regexp.MustCompile(`(?i)content[_ ]?filter|content[_ ]policy|responsibleaipolicyviolation|` +
+		`safety system|prohibited[_ ]content|blocked by (the )?(safety|moderation)`)
+	reOverloaded   = regexp.MustCompile(`(?i)overloaded`)
+	reRateLimited  = regexp.MustCompile(`(?i)rate[_ ]limit|too many requests|resource[_ ]exhausted`)
+	reAuthRejected = regexp.MustCompile(`(?i)authentication_error|permission_error|invalid[_ ]?api[_ ]?key|incorrect api key`)
+)
+
+// ClassifyError returns the kind of a failed API call from its HTTP status
+// (0 when there is none, e.g. a stream error event or CLI output) and
+// message body, or nil when it isn't recognized.
+func ClassifyError(status int, body string) error {
+	// Body first: context and content filter errors come as plain 400s.
+	switch {
+	case reContextTooLong.MatchString(body) || status == http.StatusRequestEntityTooLarge:
+		return ErrContextTooLong
+	case reContentFiltered.MatchString(body):
+		return ErrContentFiltered
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrAuth
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusServiceUnavailable || status == 529 || reOverloaded.MatchString(body):
+		return ErrOverloaded
+	case status == 0 && reRateLimited.MatchString(body):
+		return ErrRateLimited
+	case status == 0 && reAuthRejected.MatchString(body):
+		return ErrAuth
+	}
+	return nil
+}
+
+// APIError is a non-2xx API response, formatted the way the failover
+// classifier parses. It unwraps to its kind (ErrAuth etc.), if recognized.
+type APIError struct {
+	StatusCode int
+	Body       string
+	Kind       error
+}
+
+// NewAPIError classifies a failed response.
+func NewAPIError(status int, body string) *APIError {
+	return &APIError{StatusCode: status, Body: body, Kind: ClassifyError(status, body)}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed:\n  Status: %d\n  Body:   %s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// WithKind makes err match kind with errors.Is, keeping its message. It
+// returns err unchanged when kind is nil.
+func WithKind(err, kind error) error {
+	if err == nil || kind == nil {
+		return err
+	}
+	return &kindError{err: err, kind: kind}
+}
+
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
@@ -0,0 +1,23 @@
+package protocoltypes
+
+// ImageRequest asks an image generation model for pictures.
+type ImageRequest struct {
+	Prompt string `json:"prompt"`
+	// NegativePrompt describes what to keep out of the image. Models
+	// without negative prompts (OpenAI) ignore it.
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	// Size is "WIDTHxHEIGHT", e.g. "1024x1024"; empty uses the model
+	// default. Backends that only take aspect ratios use the closest one.
+	Size string `json:"size,omitempty"`
+	// N is the number of images; 0 means 1.
+	N int `json:"n,omitempty"`
+}
+
+// GeneratedImage is one generated picture.
+type GeneratedImage struct {
+	Data     []byte `json:"-"`
+	MIMEType string `json:"mime_type"`
+	// RevisedPrompt is the prompt the model actually drew, when it rewrote
+	// the request (DALL·E 3 does).
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
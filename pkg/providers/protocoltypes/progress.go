@@ -0,0 +1,19 @@
+package protocoltypes
+
+import "context"
+
+type progressKey struct{}
+
+// WithProgress returns a context whose provider calls report slow setup
+// steps, such as downloading a model, to fn. fn may be called from the
+// request goroutine at any point before the call returns.
+func WithProgress(ctx context.Context, fn func(status string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress passes status to the callback set by WithProgress, if any.
+func ReportProgress(ctx context.Context, status string) {
+	if fn, ok := ctx.Value(progressKey{}).(func(string)); ok && fn != nil {
+		fn(status)
+	}
+}
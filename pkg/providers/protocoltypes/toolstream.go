@@ -0,0 +1,28 @@
+package protocoltypes
+
+import "context"
+
+// ToolCallDelta is a fragment of a tool call as the model streams it. Index
+// identifies the call within the response; ID and Name arrive with its first
+// fragment, and Arguments are appended in order to build its JSON arguments.
+type ToolCallDelta struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+type toolCallDeltaKey struct{}
+
+// WithToolCallDeltas returns a context on which streaming calls pass each
+// tool-call fragment to fn as it arrives. An error from fn aborts the
+// stream, and the call returns it wrapped.
+func WithToolCallDeltas(ctx context.Context, fn func(ToolCallDelta) error) context.Context {
+	return context.WithValue(ctx, toolCallDeltaKey{}, fn)
+}
+
+// ToolCallDeltas returns the callback set by WithToolCallDeltas, or nil.
+func ToolCallDeltas(ctx context.Context) func(ToolCallDelta) error {
+	fn, _ := ctx.Value(toolCallDeltaKey{}).(func(ToolCallDelta) error)
+	return fn
+}
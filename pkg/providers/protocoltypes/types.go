@@ -30,12 +30,50 @@ type LLMResponse struct {
 	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
 	FinishReason     string     `json:"finish_reason"`
 	Usage            *UsageInfo `json:"usage,omitempty"`
+
+	// ReasoningSignature authenticates ReasoningContent when it is sent back
+	// in a later turn (Anthropic extended thinking); empty otherwise.
+	ReasoningSignature string `json:"reasoning_signature,omitempty"`
+
+	// Upstream is the provider that actually served the request when the
+	// endpoint is a router (e.g. OpenRouter); empty otherwise.
+	Upstream string `json:"upstream,omitempty"`
+
+	// Logprobs has one entry per generated token when log probabilities
+	// were requested (options["logprobs"] or ["top_logprobs"]) and the
+	// provider reports them; empty otherwise.
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// TokenLogprob is the natural log probability of one generated token, with
+// the most likely alternatives at its position when they were requested.
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// TopLogprob is one alternative token considered at a position.
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
 }
 
 type UsageInfo struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+
+	// Prompt caching breakdown (Anthropic). Both are included in PromptTokens.
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int `json:"cache_read_tokens,omitempty"`
+
+	// ThinkingTokens is the reasoning share of CompletionTokens, when the API
+	// reports it separately (OpenAI, Gemini).
+	ThinkingTokens int `json:"thinking_tokens,omitempty"`
+
+	// Cost is the request cost in USD when the API reports it (OpenRouter).
+	Cost float64 `json:"cost,omitempty"`
 }
 
 // CacheControl marks a content block for LLM-side prefix caching.
@@ -53,13 +91,25 @@ type ContentBlock struct {
 	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
+// ImagePart is an image attached to a message, either inline (MIMEType and
+// Data) or by reference (URL). Providers that can't fetch URLs skip
+// URL-only images.
+type ImagePart struct {
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     string `json:"data,omitempty"` // base64-encoded image bytes
+	URL      string `json:"url,omitempty"`  // http(s) URL, used when Data is empty
+}
+
 type Message struct {
 	Role             string         `json:"role"`
 	Content          string         `json:"content"`
+	Images           []ImagePart    `json:"images,omitempty"`
 	ReasoningContent string         `json:"reasoning_content,omitempty"`
 	SystemParts      []ContentBlock `json:"system_parts,omitempty"` // structured system blocks for cache-aware adapters
 	ToolCalls        []ToolCall     `json:"tool_calls,omitempty"`
 	ToolCallID       string         `json:"tool_call_id,omitempty"`
+
+	ReasoningSignature string `json:"reasoning_signature,omitempty"` // see LLMResponse.ReasoningSignature
 }
 
 type ToolDefinition struct {
@@ -72,3 +122,48 @@ type ToolFunctionDefinition struct {
 	Description string         `json:"description"`
 	Parameters  map[string]any `json:"parameters"`
 }
+
+// ResponseSchema asks for a reply that is a single JSON value matching
+// Schema. It is passed to Chat as options["response_schema"]; providers
+// that can't enforce it natively ignore it (see providers.ChatJSON).
+type ResponseSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema"`
+	Strict      bool           `json:"strict,omitempty"`
+}
+
+// ResponseSchemaOption returns the schema set in options["response_schema"],
+// accepting either a value or a pointer. It returns nil when none is set.
+func ResponseSchemaOption(options map[string]any) *ResponseSchema {
+	switch v := options["response_schema"].(type) {
+	case *ResponseSchema:
+		if v != nil && v.Schema != nil {
+			return v
+		}
+	case ResponseSchema:
+		if v.Schema != nil {
+			return &v
+		}
+	}
+	return nil
+}
+
+// StopOption returns the stop sequences set in options["stop"], accepting a
+// []string or the []any a JSON round trip produces. It returns nil when none
+// are set.
+func StopOption(options map[string]any) []string {
+	switch v := options["stop"].(type) {
+	case []string:
+		return v
+	case []any:
+		var stop []string
+		for _, s := range v {
+			if str, ok := s.(string); ok && str != "" {
+				stop = append(stop, str)
+			}
+		}
+		return stop
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests to one provider endpoint with two token
+// buckets: requests per minute and tokens per minute. A zero limit disables
+// that bucket. Thread-safe; shared by all providers created for the same
+// protocol and api_base (see rateLimiterFor).
+type RateLimiter struct {
+	mu        sync.Mutex
+	requests  *bucket
+	tokens    *bucket
+	nowFunc   func() time.Time // for testing
+	sleepFunc func(ctx context.Context, d time.Duration) error
+}
+
+// bucket refills continuously at rate per second up to capacity. Its level
+// may go negative when actual usage exceeds what was reserved.
+type bucket struct {
+	capacity float64
+	level    float64
+	rate     float64
+	last     time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		level:    float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = min(b.capacity, b.level+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns how long until n units are available.
+func (b *bucket) wait(n float64) time.Duration {
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.rate * float64(time.Second))
+}
+
+// NewRateLimiter creates a limiter allowing rpm requests and tpm tokens per
+// minute, starting with full buckets.
+func NewRateLimiter(rpm, tpm int) *RateLimiter {
+	now := time.Now()
+	return &RateLimiter{
+		requests:  newBucket(rpm, now),
+		tokens:    newBucket(tpm, now),
+		nowFunc:   time.Now,
+		sleepFunc: sleepContext,
+	}
+}
+
+// Wait blocks until a request estimated at tokens tokens may be sent, then
+// reserves it and returns the tokens it reserved. It returns ctx's error if
+// ctx ends first. Estimates above the per-minute token limit only wait for,
+// and reserve, a full bucket.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) (int, error) {
+	for {
+		l.mu.Lock()
+		now := l.nowFunc()
+		var delay time.Duration
+		if l.requests != nil {
+			l.requests.refill(now)
+			delay = max(delay, l.requests.wait(1))
+		}
+		need := float64(tokens)
+		if l.tokens != nil {
+			need = min(need, l.tokens.capacity)
+			l.tokens.refill(now)
+			delay = max(delay, l.tokens.wait(need))
+		}
+		if delay == 0 {
+			if l.requests != nil {
+				l.requests.level--
+			}
+			if l.tokens != nil {
+				l.tokens.level -= need
+			}
+			l.mu.Unlock()
+			return int(need), nil
+		}
+		l.mu.Unlock()
+
+		if err := l.sleepFunc(ctx, delay); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Adjust corrects the token bucket once actual usage is known: delta is
+// actual minus reserved tokens, and may be negative.
+func (l *RateLimiter) Adjust(delta int) {
+	if l.tokens == nil || delta == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.refill(l.nowFunc())
+	l.tokens.level = min(l.tokens.capacity, l.tokens.level-float64(delta))
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = make(map[string]*RateLimiter)
+)
+
+// rateLimiterFor returns the process-wide limiter for key, creating it on
+// first use. Later calls with different limits reuse the first limiter, so
+// model_list entries sharing an endpoint share one budget.
+func rateLimiterFor(key string, rpm, tpm int) *RateLimiter {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	if l, ok := rateLimiters[key]; ok {
+		return l
+	}
+	l := NewRateLimiter(rpm, tpm)
+	rateLimiters[key] = l
+	return l
+}
+
+// RateLimitedProvider waits on a RateLimiter before every request to the
+// wrapped provider.
+type RateLimitedProvider struct {
+	delegate LLMProvider
+	limiter  *RateLimiter
+}
+
+func NewRateLimitedProvider(delegate LLMProvider, limiter *RateLimiter) *RateLimitedProvider {
+	return &RateLimitedProvider{delegate: delegate, limiter: limiter}
+}
+
+func (p *RateLimitedProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return p.limit(ctx, messages, options, func() (*LLMResponse, error) {
+		return p.delegate.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+// StreamChat streams when the wrapped provider supports it and otherwise
+// falls back to Chat.
+func (p *RateLimitedProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	return p.limit(ctx, messages, options, func() (*LLMResponse, error) {
+		if sp, ok := p.delegate.(StreamingProvider); ok {
+			return sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+		}
+		return p.delegate.Chat(ctx, messages, tools, model, options)
+	})
+}
+
+func (p *RateLimitedProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
+
+func (p *RateLimitedProvider) SupportsStructuredOutput() bool {
+	sp, ok := p.delegate.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+// Ping is not rate limited: health checks don't count against model quotas.
+func (p *RateLimitedProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.delegate)
+}
+
+func (p *RateLimitedProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.delegate)
+}
+
+// Close releases the wrapped provider if it holds resources.
+func (p *RateLimitedProvider) Close() {
+	if sp, ok := p.delegate.(StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// limit reserves an estimate of the request's tokens (prompt plus
+// max_tokens), runs call, then settles the difference with reported usage.
+func (p *RateLimitedProvider) limit(
+	ctx context.Context,
+	messages []Message,
+	options map[string]any,
+	call func() (*LLMResponse, error),
+) (*LLMResponse, error) {
+	reserved, err := p.limiter.Wait(ctx, estimateRequestTokens(messages, options))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := call()
+	if err == nil && resp != nil && resp.Usage != nil && resp.Usage.TotalTokens > 0 {
+		p.limiter.Adjust(resp.Usage.TotalTokens - reserved)
+	}
+	return resp, err
+}
+
+// estimateRequestTokens is a rough pre-request estimate: about 4 characters
+// per token of message content, plus the requested completion budget.
+func estimateRequestTokens(messages []Message, options map[string]any) int {
+	chars := 0
+	for _, m := range messages {
+		chars += len(m.Content)
+		for _, tc := range m.ToolCalls {
+			if tc.Function != nil {
+				chars += len(tc.Function.Arguments)
+			}
+		}
+	}
+	tokens := chars / 4
+	switch v := options["max_tokens"].(type) {
+	case int:
+		tokens += v
+	case float64:
+		tokens += int(v)
+	}
+	return tokens
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeClock lets RateLimiter tests advance time instead of sleeping.
+type fakeClock struct {
+	now    time.Time
+	slept  []time.Duration
+	cancel bool
+}
+
+func (c *fakeClock) limiter(rpm, tpm int) *RateLimiter {
+	l := NewRateLimiter(rpm, tpm)
+	if l.requests != nil {
+		l.requests.last = c.now
+	}
+	if l.tokens != nil {
+		l.tokens.last = c.now
+	}
+	l.nowFunc = func() time.Time { return c.now }
+	l.sleepFunc = func(ctx context.Context, d time.Duration) error {
+		if c.cancel {
+			return context.Canceled
+		}
+		c.slept = append(c.slept, d)
+		c.now = c.now.Add(d)
+		return nil
+	}
+	return l
+}
+
+func TestRateLimiter_RequestsPerMinute(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := clock.limiter(2, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Wait(context.Background(), 100); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if len(clock.slept) != 0 {
+		t.Fatalf("burst should not wait, slept %v", clock.slept)
+	}
+
+	if _, err := l.Wait(context.Background(), 100); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if len(clock.slept) != 1 || clock.slept[0] != 30*time.Second {
+		t.Errorf("slept = %v, want [30s]", clock.slept)
+	}
+}
+
+func TestRateLimiter_TokensPerMinuteAndAdjust(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := clock.limiter(0, 600)
+
+	if _, err := l.Wait(context.Background(), 100); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	// The request actually used 700 tokens: the bucket is now 100 short.
+	l.Adjust(600)
+
+	if _, err := l.Wait(context.Background(), 50); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	// 150 tokens at 10 tokens/s.
+	if len(clock.slept) != 1 || clock.slept[0] != 15*time.Second {
+		t.Errorf("slept = %v, want [15s]", clock.slept)
+	}
+}
+
+func TestRateLimiter_OversizedRequestWaitsForFullBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := clock.limiter(0, 100)
+
+	reserved, err := l.Wait(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if reserved != 100 {
+		t.Errorf("reserved = %d, want the 100 tokens of a full bucket", reserved)
+	}
+	if len(clock.slept) != 0 {
+		t.Errorf("slept = %v, want none", clock.slept)
+	}
+}
+
+func TestRateLimitedProvider_SettlesOversizedRequest(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := clock.limiter(0, 100)
+	p := NewRateLimitedProvider(&usageProvider{total: 80}, l)
+
+	// max_tokens above tpm only reserves the 100 tokens the bucket holds.
+	msgs := []Message{{Role: "user", Content: "hi"}}
+	if _, err := p.Chat(context.Background(), msgs, nil, "m", map[string]any{"max_tokens": 1000}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := l.tokens.level; got != 20 {
+		t.Errorf("token level = %v, want 20 after settling 80 used", got)
+	}
+}
+
+func TestRateLimiter_ContextCancel(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0), cancel: true}
+	l := clock.limiter(1, 0)
+
+	l.Wait(context.Background(), 0)
+	if _, err := l.Wait(context.Background(), 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimitedProvider_SettlesUsage(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	l := clock.limiter(0, 1000)
+	inner := &usageProvider{total: 900}
+	p := NewRateLimitedProvider(inner, l)
+
+	msgs := []Message{{Role: "user", Content: "12345678"}} // ~2 tokens
+	if _, err := p.Chat(context.Background(), msgs, nil, "m", map[string]any{"max_tokens": 98}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := l.tokens.level; got != 100 {
+		t.Errorf("token level = %v, want 100 after settling 900 used", got)
+	}
+
+	// Streaming falls back to Chat for non-streaming providers.
+	if _, err := p.StreamChat(context.Background(), msgs, nil, "m", nil, func(string) {}); err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("calls = %d, want 2", inner.calls)
+	}
+}
+
+type usageProvider struct {
+	total int
+	calls int
+}
+
+func (p *usageProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	p.calls++
+	return &LLMResponse{Content: "ok", Usage: &UsageInfo{TotalTokens: p.total}}, nil
+}
+
+func (p *usageProvider) GetDefaultModel() string { return "" }
+
+func TestCreateProviderFromConfig_RateLimitSharedPerEndpoint(t *testing.T) {
+	a := &config.ModelConfig{Model: "groq/llama-a", APIKey: "k", RPM: 30, TPM: 6000}
+	b := &config.ModelConfig{Model: "groq/llama-b", APIKey: "k", RPM: 30}
+	c := &config.ModelConfig{Model: "groq/llama-c", APIKey: "k", APIBase: "http://proxy.local/v1", RPM: 30}
+
+	pa, _, err := CreateProviderFromConfig(a)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	pb, _, _ := CreateProviderFromConfig(b)
+	pc, _, _ := CreateProviderFromConfig(c)
+
+	ra, ok := pa.(*RateLimitedProvider)
+	if !ok {
+		t.Fatalf("provider = %T, want *RateLimitedProvider", pa)
+	}
+	if ra.limiter != pb.(*RateLimitedProvider).limiter {
+		t.Error("entries with the same endpoint should share a limiter")
+	}
+	if ra.limiter == pc.(*RateLimitedProvider).limiter {
+		t.Error("entries with different api_base should not share a limiter")
+	}
+	if _, ok := ra.delegate.(*HTTPProvider); !ok {
+		t.Errorf("delegate = %T, want *HTTPProvider", ra.delegate)
+	}
+
+	plain, _, _ := CreateProviderFromConfig(&config.ModelConfig{Model: "groq/llama-a", APIKey: "k"})
+	if _, ok := plain.(*RateLimitedProvider); ok {
+		t.Error("provider without limits should not be wrapped")
+	}
+}
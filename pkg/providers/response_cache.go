@@ -0,0 +1,204 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+const defaultCacheTTL = 24 * time.Hour
+
+// cacheEntry is one cached response, stored as <dir>/<key>.json.
+type cacheEntry struct {
+	Time     time.Time    `json:"time"`
+	Model    string       `json:"model"`
+	Response *LLMResponse `json:"response"`
+}
+
+// CachingProvider answers requests identical to an earlier one (same model,
+// messages, tools and options) from a disk cache instead of calling the
+// wrapped provider. It is meant for repeated internal calls such as
+// summarizing unchanged content; cache hits report no usage, since nothing
+// was spent. Errors are never cached.
+type CachingProvider struct {
+	delegate LLMProvider
+	dir      string
+	ttl      time.Duration
+	key      *encrypt.Key
+	nowFunc  func() time.Time // for testing
+}
+
+// NewCachingProvider caches delegate's responses in dir for ttl (24 hours
+// when ttl is not positive).
+func NewCachingProvider(delegate LLMProvider, dir string, ttl time.Duration) *CachingProvider {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &CachingProvider{delegate: delegate, dir: dir, ttl: ttl, nowFunc: time.Now}
+}
+
+// SetEncryptionKey makes the cache store its entries encrypted with key.
+func (p *CachingProvider) SetEncryptionKey(key *encrypt.Key) {
+	p.key = key
+}
+
+func (p *CachingProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	key := cacheKey(messages, tools, model, options)
+	if resp := p.load(key); resp != nil {
+		return resp, nil
+	}
+	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
+	if err == nil {
+		p.store(key, model, resp)
+	}
+	return resp, err
+}
+
+// StreamChat delivers a cached response as a single delta. Misses stream
+// when the wrapped provider supports it and otherwise fall back to Chat.
+func (p *CachingProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	sp, ok := p.delegate.(StreamingProvider)
+	if !ok {
+		return p.Chat(ctx, messages, tools, model, options)
+	}
+
+	key := cacheKey(messages, tools, model, options)
+	if resp := p.load(key); resp != nil {
+		if resp.Content != "" && onDelta != nil {
+			onDelta(resp.Content)
+		}
+		return resp, nil
+	}
+	resp, err := sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+	if err == nil {
+		p.store(key, model, resp)
+	}
+	return resp, err
+}
+
+func (p *CachingProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
+
+func (p *CachingProvider) SupportsStructuredOutput() bool {
+	sp, ok := p.delegate.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+func (p *CachingProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.delegate)
+}
+
+func (p *CachingProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.delegate)
+}
+
+// Close releases the wrapped provider if it holds resources.
+func (p *CachingProvider) Close() {
+	if sp, ok := p.delegate.(StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// cacheKey hashes the request. Requests that can't be encoded (e.g. an
+// option holding a func) get an empty key and bypass the cache.
+func cacheKey(messages []Message, tools []ToolDefinition, model string, options map[string]any) string {
+	data, err := json.Marshal(struct {
+		Model    string           `json:"model"`
+		Messages []Message        `json:"messages"`
+		Tools    []ToolDefinition `json:"tools,omitempty"`
+		Options  map[string]any   `json:"options,omitempty"`
+	}{model, messages, tools, options})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// load returns the cached response for key, or nil on a miss or an expired
+// or unreadable entry.
+func (p *CachingProvider) load(key string) *LLMResponse {
+	if key == "" {
+		return nil
+	}
+	data, err := p.key.ReadFile(filepath.Join(p.dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil
+	}
+	if p.nowFunc().Sub(entry.Time) > p.ttl {
+		return nil
+	}
+	resp := *entry.Response
+	resp.Usage = nil
+	return &resp
+}
+
+// store writes resp under key. Failures are logged to stderr rather than
+// failing the request: the response is still good.
+func (p *CachingProvider) store(key, model string, resp *LLMResponse) {
+	if key == "" || resp == nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{Time: p.nowFunc().UTC(), Model: model, Response: resp})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "response cache: failed to encode entry: %v\n", err)
+		return
+	}
+	if data, err = p.key.Seal(data); err != nil {
+		fmt.Fprintf(os.Stderr, "response cache: %v\n", err)
+		return
+	}
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "response cache: %v\n", err)
+		return
+	}
+
+	// Write then rename, so concurrent readers never see a partial entry.
+	path := filepath.Join(p.dir, key+".json")
+	tmp, err := os.CreateTemp(p.dir, key+".*.tmp")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "response cache: %v\n", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		fmt.Fprintf(os.Stderr, "response cache: %v\n", err)
+	}
+}
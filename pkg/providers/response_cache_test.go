@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCachingProvider_ServesRepeatedRequests(t *testing.T) {
+	delegate := &usageReplyProvider{replyProvider{replies: []string{"first", "second"}}}
+	p := NewCachingProvider(delegate, t.TempDir(), time.Hour)
+
+	messages := []Message{{Role: "user", Content: "summarize this"}}
+	options := map[string]any{"temperature": 0.3}
+
+	first, err := p.Chat(t.Context(), messages, nil, "m", options)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	again, err := p.Chat(t.Context(), messages, nil, "m", options)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if first.Content != "first" || again.Content != "first" || len(delegate.messages) != 1 {
+		t.Fatalf("got %q then %q after %d calls", first.Content, again.Content, len(delegate.messages))
+	}
+	if first.Usage == nil || again.Usage != nil {
+		t.Errorf("usage = %+v then %+v, want none for the cache hit", first.Usage, again.Usage)
+	}
+
+	// Any difference in the request is a miss.
+	other, _ := p.Chat(t.Context(), messages, nil, "m", map[string]any{"temperature": 0.4})
+	if other.Content != "second" {
+		t.Errorf("different options served %q from cache", other.Content)
+	}
+}
+
+func TestCachingProvider_Expiry(t *testing.T) {
+	delegate := &replyProvider{replies: []string{"old", "new"}}
+	p := NewCachingProvider(delegate, t.TempDir(), time.Minute)
+	now := time.Now()
+	p.nowFunc = func() time.Time { return now }
+
+	messages := []Message{{Role: "user", Content: "hi"}}
+	p.Chat(t.Context(), messages, nil, "m", nil)
+	now = now.Add(2 * time.Minute)
+	resp, _ := p.Chat(t.Context(), messages, nil, "m", nil)
+	if resp.Content != "new" {
+		t.Errorf("expired entry served: %q", resp.Content)
+	}
+}
+
+func TestCachingProvider_StreamHitDeliversContent(t *testing.T) {
+	delegate := &streamingReplyProvider{replyProvider{replies: []string{"cached reply"}}}
+	dir := t.TempDir()
+	p := NewCachingProvider(delegate, dir, 0)
+
+	messages := []Message{{Role: "user", Content: "hi"}}
+	if _, err := p.StreamChat(t.Context(), messages, nil, "m", nil, nil); err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	var deltas []string
+	resp, err := p.StreamChat(t.Context(), messages, nil, "m", nil, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("StreamChat() error = %v", err)
+	}
+	if len(delegate.messages) != 1 || resp.Content != "cached reply" || len(deltas) != 1 || deltas[0] != "cached reply" {
+		t.Errorf("calls = %d, resp = %q, deltas = %q", len(delegate.messages), resp.Content, deltas)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("cache dir has %d entries, want 1", len(entries))
+	}
+}
+
+type usageReplyProvider struct{ replyProvider }
+
+func (p *usageReplyProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.replyProvider.Chat(ctx, messages, tools, model, options)
+	if resp != nil {
+		resp.Usage = &UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	}
+	return resp, err
+}
+
+type streamingReplyProvider struct{ replyProvider }
+
+func (p *streamingReplyProvider) StreamChat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	resp, err := p.Chat(ctx, messages, tools, model, options)
+	if err == nil && onDelta != nil {
+		onDelta(resp.Content)
+	}
+	return resp, err
+}
@@ -0,0 +1,157 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Model roles name the jobs subsystems need a model for. Which model_list
+// entry serves each role is configured in agents.defaults.model_roles.
+const (
+	RoleChat      = "chat"      // conversations; the agent's own model
+	RoleSummarize = "summarize" // history compaction
+	RoleEmbed     = "embed"     // semantic retrieval
+	RoleVision    = "vision"    // turns with attached images
+	RoleCheap     = "cheap"     // background checks such as heartbeats
+	RoleImageGen  = "image_gen" // the image_generation tool
+)
+
+type roleProvider struct {
+	provider LLMProvider
+	modelID  string
+}
+
+// ModelRoles creates and caches the providers serving model roles. Entries
+// are created on first use, so unused roles cost nothing. Thread-safe.
+type ModelRoles struct {
+	cfg *config.Config
+
+	mu         sync.Mutex
+	providers  map[string]roleProvider // by model_name
+	embeddings EmbeddingsProvider
+	imageGen   ImageGenerationProvider
+}
+
+func NewModelRoles(cfg *config.Config) *ModelRoles {
+	return &ModelRoles{cfg: cfg, providers: make(map[string]roleProvider)}
+}
+
+// Provider returns the provider and model ID serving role. It returns a nil
+// provider and no error when role resolves to the agent's own chat model;
+// callers then use the agent's provider.
+func (r *ModelRoles) Provider(role string) (LLMProvider, string, error) {
+	name := r.cfg.Agents.Defaults.ModelForRole(role)
+	if name == "" {
+		return nil, "", nil
+	}
+	provider, modelID, err := r.Model(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("model role %q: %w", role, err)
+	}
+	return provider, modelID, nil
+}
+
+// Model returns the provider and model ID for the model_list entry name,
+// sharing providers with the roles it serves. Used for per-session and
+// per-message model overrides.
+func (r *ModelRoles) Model(name string) (LLMProvider, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rp, ok := r.providers[name]; ok {
+		return rp.provider, rp.modelID, nil
+	}
+	modelCfg, err := r.modelConfig(name)
+	if err != nil {
+		return nil, "", err
+	}
+	provider, modelID, err := CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create provider for model %q: %w", name, err)
+	}
+	r.providers[name] = roleProvider{provider: provider, modelID: modelID}
+	return provider, modelID, nil
+}
+
+// Embeddings returns the provider serving the embed role, or an error when
+// no embedding model is assigned.
+func (r *ModelRoles) Embeddings() (EmbeddingsProvider, error) {
+	name := r.cfg.Agents.Defaults.ModelForRole(RoleEmbed)
+	if name == "" {
+		return nil, fmt.Errorf("no model assigned to role %q in agents.defaults.model_roles", RoleEmbed)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.embeddings != nil {
+		return r.embeddings, nil
+	}
+	modelCfg, err := r.modelConfig(name)
+	if err != nil {
+		return nil, fmt.Errorf("model role %q: %w", RoleEmbed, err)
+	}
+	embeddings, err := CreateEmbeddingsProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, fmt.Errorf("model role %q: %w", RoleEmbed, err)
+	}
+	r.embeddings = embeddings
+	return embeddings, nil
+}
+
+// ImageGenerator returns the provider serving the image_gen role, or an
+// error when no image model is assigned.
+func (r *ModelRoles) ImageGenerator() (ImageGenerationProvider, error) {
+	name := r.cfg.Agents.Defaults.ModelForRole(RoleImageGen)
+	if name == "" {
+		return nil, fmt.Errorf("no model assigned to role %q in agents.defaults.model_roles", RoleImageGen)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.imageGen != nil {
+		return r.imageGen, nil
+	}
+	modelCfg, err := r.modelConfig(name)
+	if err != nil {
+		return nil, fmt.Errorf("model role %q: %w", RoleImageGen, err)
+	}
+	imageGen, err := CreateImageGenerationProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, fmt.Errorf("model role %q: %w", RoleImageGen, err)
+	}
+	r.imageGen = imageGen
+	return imageGen, nil
+}
+
+// Close releases providers that hold resources.
+func (r *ModelRoles) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, rp := range r.providers {
+		if sp, ok := rp.provider.(StatefulProvider); ok {
+			sp.Close()
+		}
+		delete(r.providers, name)
+	}
+}
+
+func (r *ModelRoles) modelConfig(name string) (*config.ModelConfig, error) {
+	modelCfg, err := r.cfg.GetModelConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	if modelCfg.Workspace == "" {
+		modelCfg.Workspace = r.cfg.WorkspacePath()
+	}
+	modelCfg.EncryptionKey = r.cfg.Storage.EncryptionKey
+	return modelCfg, nil
+}
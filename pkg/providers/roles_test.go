@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestModelRoles_Provider(t *testing.T) {
+	cheapScript := writeMockScript(t)
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{
+			ModelRoles: map[string]string{"cheap": "local"},
+		}},
+		ModelList: []config.ModelConfig{{ModelName: "local", Model: "mock/" + cheapScript}},
+	}
+	roles := NewModelRoles(cfg)
+
+	provider, modelID, err := roles.Provider(RoleSummarize)
+	if err != nil {
+		t.Fatalf("Provider(summarize) error = %v", err)
+	}
+	if provider == nil || modelID != cheapScript {
+		t.Fatalf("Provider(summarize) = %T, %q", provider, modelID)
+	}
+	again, _, _ := roles.Provider(RoleCheap)
+	if again != provider {
+		t.Error("roles sharing a model got different providers")
+	}
+
+	if chat, _, err := roles.Provider(RoleChat); chat != nil || err != nil {
+		t.Errorf("Provider(chat) = %v, %v; want the agent's own model", chat, err)
+	}
+	if _, err := roles.Embeddings(); err == nil {
+		t.Error("Embeddings() without an embed role succeeded")
+	}
+}
+
+func TestModelRoles_UnknownModel(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{
+			ModelRoles: map[string]string{"vision": "missing"},
+		}},
+	}
+	if _, _, err := NewModelRoles(cfg).Provider(RoleVision); err == nil {
+		t.Error("Provider(vision) with an unknown model succeeded")
+	}
+}
+
+func TestModelRoles_ImageGenerator(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{
+			ModelRoles: map[string]string{"chat": "gpt", "image_gen": "sd"},
+		}},
+		ModelList: []config.ModelConfig{
+			{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "k"},
+			{ModelName: "sd", Model: "a1111/default"},
+		},
+	}
+	roles := NewModelRoles(cfg)
+	imageGen, err := roles.ImageGenerator()
+	if err != nil {
+		t.Fatalf("ImageGenerator() error = %v", err)
+	}
+	if again, _ := roles.ImageGenerator(); again != imageGen {
+		t.Error("ImageGenerator() was not cached")
+	}
+
+	delete(cfg.Agents.Defaults.ModelRoles, "image_gen")
+	if _, err := NewModelRoles(cfg).ImageGenerator(); err == nil {
+		t.Error("ImageGenerator() fell back to the chat model")
+	}
+}
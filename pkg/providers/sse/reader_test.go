@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRead_EventsAndData(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"event: message_start\ndata: {\"a\":1}\n\n" +
+		"data: line1\ndata: line2\n\n" +
+		"data: [DONE]"
+
+	type ev struct{ event, data string }
+	var got []ev
+	err := Read(strings.NewReader(stream), func(event, data string) error {
+		got = append(got, ev{event, data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := []ev{
+		{"message_start", `{"a":1}`},
+		{"", "line1\nline2"},
+		{"", "[DONE]"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRead_StopsOnCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := Read(strings.NewReader("data: 1\n\ndata: 2\n\n"), func(_, _ string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+}
@@ -0,0 +1,194 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package stability
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+	"github.com/sipeed/picoclaw/pkg/providers/transport"
+)
+
+type (
+	ImageRequest   = protocoltypes.ImageRequest
+	GeneratedImage = protocoltypes.GeneratedImage
+)
+
+const (
+	DefaultAPIBase = "https://api.stability.ai"
+
+	defaultRequestTimeout = 120 * time.Second
+)
+
+// aspectRatios are the ratios the Stable Image API accepts.
+var aspectRatios = []string{"21:9", "16:9", "3:2", "5:4", "1:1", "4:5", "2:3", "9:16", "9:21"}
+
+// Provider generates images with the Stability AI Stable Image API
+// (v2beta). The model is the endpoint: "core", "ultra", or an SD3 model
+// such as "sd3.5-large".
+type Provider struct {
+	apiKey     string
+	apiBase    string
+	httpClient *http.Client
+}
+
+type Option func(*Provider)
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	client := &http.Client{
+		Timeout: defaultRequestTimeout,
+	}
+
+	if t, err := transport.Shared(proxy); err == nil {
+		client.Transport = t
+	} else {
+		log.Printf("stability: invalid proxy %q: %v", proxy, err)
+	}
+
+	if apiBase == "" {
+		apiBase = DefaultAPIBase
+	}
+
+	p := &Provider{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/v2beta"),
+		httpClient: client,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+// GenerateImages makes one request per image: the API returns a single
+// image per call.
+func (p *Provider) GenerateImages(ctx context.Context, req ImageRequest, model string) ([]GeneratedImage, error) {
+	n := max(req.N, 1)
+	images := make([]GeneratedImage, 0, n)
+	for range n {
+		img, err := p.generate(ctx, req, model)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+func (p *Provider) generate(ctx context.Context, req ImageRequest, model string) (GeneratedImage, error) {
+	endpoint, sd3Model := model, ""
+	if strings.HasPrefix(model, "sd3") {
+		endpoint, sd3Model = "sd3", model
+	}
+	if endpoint == "" {
+		endpoint = "core"
+	}
+
+	fields := map[string]string{
+		"prompt":          req.Prompt,
+		"negative_prompt": req.NegativePrompt,
+		"aspect_ratio":    AspectRatio(req.Size),
+		"model":           sd3Model,
+		"output_format":   "png",
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := w.WriteField(name, value); err != nil {
+			return GeneratedImage{}, fmt.Errorf("failed to write form: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return GeneratedImage{}, fmt.Errorf("failed to write form: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST",
+		p.apiBase+"/v2beta/stable-image/generate/"+endpoint, &body)
+	if err != nil {
+		return GeneratedImage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+	httpReq.Header.Set("Accept", "image/*")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return GeneratedImage{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return GeneratedImage{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return GeneratedImage{}, protocoltypes.NewAPIError(resp.StatusCode, string(data))
+	}
+	// Filtered prompts still return 200, with a blurred image.
+	if reason := resp.Header.Get("Finish-Reason"); reason == "CONTENT_FILTERED" {
+		return GeneratedImage{}, fmt.Errorf("image was blocked by the content filter")
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	return GeneratedImage{Data: data, MIMEType: mimeType}, nil
+}
+
+// AspectRatio returns the supported aspect ratio closest to size
+// ("WIDTHxHEIGHT"), or "" for the API default when size is empty or
+// malformed.
+func AspectRatio(size string) string {
+	w, h, ok := strings.Cut(size, "x")
+	if !ok {
+		return ""
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return ""
+	}
+
+	want := math.Log(float64(width) / float64(height))
+	best, bestDiff := "", math.Inf(1)
+	for _, ratio := range aspectRatios {
+		a, b, _ := strings.Cut(ratio, ":")
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		if diff := math.Abs(math.Log(float64(x)/float64(y)) - want); diff < bestDiff {
+			best, bestDiff = ratio, diff
+		}
+	}
+	return best
+}
@@ -0,0 +1,82 @@
+package stability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateImages(t *testing.T) {
+	var paths []string
+	var fields []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-stab" || r.Header.Get("Accept") != "image/*" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		paths = append(paths, r.URL.Path)
+		fields = append(fields, map[string]string{
+			"prompt":       r.FormValue("prompt"),
+			"aspect_ratio": r.FormValue("aspect_ratio"),
+			"model":        r.FormValue("model"),
+		})
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("sk-stab", server.URL+"/v2beta", "")
+	images, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "a lighthouse", Size: "1792x1024", N: 2}, "sd3.5-large")
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 2 || images[0].MIMEType != "image/png" {
+		t.Errorf("images = %+v", images)
+	}
+	if paths[0] != "/v2beta/stable-image/generate/sd3" {
+		t.Errorf("path = %q", paths[0])
+	}
+	if fields[0]["prompt"] != "a lighthouse" || fields[0]["aspect_ratio"] != "16:9" || fields[0]["model"] != "sd3.5-large" {
+		t.Errorf("fields = %v", fields[0])
+	}
+}
+
+func TestGenerateImages_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/ultra") {
+			w.Header().Set("Finish-Reason", "CONTENT_FILTERED")
+			w.Write([]byte("blurred"))
+			return
+		}
+		http.Error(w, `{"errors":["insufficient credits"]}`, http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	p := NewProvider("sk", server.URL, "")
+	if _, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "x"}, "core"); err == nil || !strings.Contains(err.Error(), "402") {
+		t.Errorf("error = %v, want status 402", err)
+	}
+	if _, err := p.GenerateImages(t.Context(), ImageRequest{Prompt: "x"}, "ultra"); err == nil || !strings.Contains(err.Error(), "content filter") {
+		t.Errorf("error = %v, want content filter", err)
+	}
+}
+
+func TestAspectRatio(t *testing.T) {
+	tests := map[string]string{
+		"1024x1024": "1:1",
+		"1792x1024": "16:9",
+		"1024x1536": "2:3",
+		"768x1365":  "9:16",
+		"":          "",
+		"big":       "",
+		"0x10":      "",
+	}
+	for size, want := range tests {
+		if got := AspectRatio(size); got != want {
+			t.Errorf("AspectRatio(%q) = %q, want %q", size, got, want)
+		}
+	}
+}
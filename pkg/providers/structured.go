@@ -0,0 +1,247 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxStructuredRetries is how many times ChatJSON asks the model to correct
+// a reply that doesn't match the schema.
+const maxStructuredRetries = 2
+
+// ChatJSON asks provider for a reply matching schema and decodes it into out.
+// The schema is passed as options["response_schema"], which providers
+// implementing StructuredOutputProvider enforce natively. For the rest, the
+// schema is described in an extra system message. Either way the reply is
+// validated, and on mismatch the model is shown the error and asked again.
+func ChatJSON(
+	ctx context.Context,
+	provider LLMProvider,
+	messages []Message,
+	model string,
+	schema ResponseSchema,
+	options map[string]any,
+	out any,
+) error {
+	if schema.Name == "" {
+		schema.Name = "response"
+	}
+
+	opts := make(map[string]any, len(options)+1)
+	for k, v := range options {
+		opts[k] = v
+	}
+	opts["response_schema"] = &schema
+
+	msgs := append([]Message(nil), messages...)
+	if !supportsStructuredOutput(provider) {
+		msgs = append(msgs, Message{Role: "system", Content: schemaInstruction(schema)})
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxStructuredRetries; attempt++ {
+		resp, err := provider.Chat(ctx, msgs, nil, model, opts)
+		if err != nil {
+			return err
+		}
+
+		raw := extractJSON(resp.Content)
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			lastErr = fmt.Errorf("reply is not valid JSON: %w", err)
+		} else if err := validateSchema(value, schema.Schema, "$"); err != nil {
+			lastErr = err
+		} else {
+			if out == nil {
+				return nil
+			}
+			return json.Unmarshal([]byte(raw), out)
+		}
+
+		msgs = append(msgs,
+			Message{Role: "assistant", Content: resp.Content},
+			Message{Role: "user", Content: fmt.Sprintf(
+				"Your reply did not match the required JSON schema: %v\n"+
+					"Reply again with only the corrected JSON, no other text.", lastErr)},
+		)
+	}
+
+	return fmt.Errorf("structured output %q: %w", schema.Name, lastErr)
+}
+
+func supportsStructuredOutput(provider LLMProvider) bool {
+	sp, ok := provider.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+func schemaInstruction(schema ResponseSchema) string {
+	data, _ := json.Marshal(schema.Schema)
+	var sb strings.Builder
+	sb.WriteString("Reply with a single JSON value and nothing else: no prose, no code fences.\n")
+	if schema.Description != "" {
+		sb.WriteString(schema.Description + "\n")
+	}
+	sb.WriteString("It must match this JSON schema:\n")
+	sb.Write(data)
+	return sb.String()
+}
+
+// extractJSON strips the Markdown code fences and surrounding prose models
+// tend to add around JSON replies.
+func extractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:]
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+	if json.Valid([]byte(text)) {
+		return text
+	}
+	start := strings.IndexAny(text, "{[")
+	end := strings.LastIndexAny(text, "}]")
+	if start >= 0 && end > start {
+		return text[start : end+1]
+	}
+	return text
+}
+
+// validateSchema checks value against the subset of JSON Schema used for
+// structured output: type, enum, properties, required, items and
+// additionalProperties: false.
+func validateSchema(value any, schema map[string]any, path string) error {
+	if schema == nil {
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(value, t) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, jsonTypeName(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]any)
+			if !ok {
+				if extra, set := schema["additionalProperties"].(bool); set && !extra {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+				continue
+			}
+			if err := validateSchema(v[k], sub, path+"."+k); err != nil {
+				return err
+			}
+		}
+
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			if err := validateSchema(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType reports whether value has the JSON type t, which may be a
+// single type name or a list of them.
+func matchesType(value any, t any) bool {
+	names := stringList(t)
+	if s, ok := t.(string); ok {
+		names = []string{s}
+	}
+	actual := jsonTypeName(value)
+	for _, name := range names {
+		if name == actual {
+			return true
+		}
+		if name == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return len(names) == 0
+}
+
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
+
+// stringList accepts both []string (schemas built in Go) and []any
+// (schemas decoded from JSON).
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type replyProvider struct {
+	replies  []string
+	messages [][]Message
+	options  []map[string]any
+	native   bool
+}
+
+func (p *replyProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	p.messages = append(p.messages, messages)
+	p.options = append(p.options, options)
+	reply := p.replies[0]
+	if len(p.replies) > 1 {
+		p.replies = p.replies[1:]
+	}
+	return &LLMResponse{Content: reply}, nil
+}
+
+func (p *replyProvider) GetDefaultModel() string { return "" }
+
+type nativeReplyProvider struct{ replyProvider }
+
+func (p *nativeReplyProvider) SupportsStructuredOutput() bool { return true }
+
+var verdictSchema = ResponseSchema{
+	Name: "verdict",
+	Schema: map[string]any{
+		"type":     "object",
+		"required": []string{"ok", "reason"},
+		"properties": map[string]any{
+			"ok":     map[string]any{"type": "boolean"},
+			"reason": map[string]any{"type": "string"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	},
+}
+
+func TestChatJSON_RetriesUntilValid(t *testing.T) {
+	p := &replyProvider{replies: []string{
+		"Sure! Here you go.",
+		`{"ok": true}`,
+		"```json\n{\"ok\": true, \"reason\": \"fine\"}\n```",
+	}}
+
+	var out struct {
+		OK     bool   `json:"ok"`
+		Reason string `json:"reason"`
+	}
+	err := ChatJSON(context.Background(), p, []Message{{Role: "user", Content: "judge"}}, "m", verdictSchema, nil, &out)
+	if err != nil {
+		t.Fatalf("ChatJSON() error = %v", err)
+	}
+	if !out.OK || out.Reason != "fine" {
+		t.Errorf("out = %+v", out)
+	}
+	if len(p.messages) != 3 {
+		t.Fatalf("calls = %d, want 3", len(p.messages))
+	}
+
+	first := p.messages[0]
+	if last := first[len(first)-1]; last.Role != "system" || !strings.Contains(last.Content, "JSON schema") {
+		t.Errorf("non-native provider should get a schema instruction, got %+v", last)
+	}
+	third := p.messages[2]
+	if last := third[len(third)-1]; !strings.Contains(last.Content, `missing required property "reason"`) {
+		t.Errorf("retry message = %q", last.Content)
+	}
+	if p.options[0]["response_schema"] == nil {
+		t.Error("response_schema not passed in options")
+	}
+}
+
+func TestChatJSON_GivesUpAfterRetries(t *testing.T) {
+	p := &replyProvider{replies: []string{`{"ok": "yes", "reason": "x"}`}}
+
+	err := ChatJSON(context.Background(), p, nil, "m", verdictSchema, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "$.ok: expected boolean") {
+		t.Fatalf("ChatJSON() error = %v", err)
+	}
+	if len(p.messages) != maxStructuredRetries+1 {
+		t.Errorf("calls = %d, want %d", len(p.messages), maxStructuredRetries+1)
+	}
+}
+
+func TestChatJSON_NativeProviderSkipsInstruction(t *testing.T) {
+	p := &nativeReplyProvider{replyProvider{replies: []string{`{"ok": false, "reason": "no"}`}}}
+	msgs := []Message{{Role: "user", Content: "judge"}}
+
+	if err := ChatJSON(context.Background(), p, msgs, "m", verdictSchema, nil, nil); err != nil {
+		t.Fatalf("ChatJSON() error = %v", err)
+	}
+	if len(p.messages[0]) != 1 {
+		t.Errorf("native provider messages = %+v", p.messages[0])
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		schema  map[string]any
+		wantErr string
+	}{
+		{"integer as number", 3.0, map[string]any{"type": "number"}, ""},
+		{"fraction not integer", 1.5, map[string]any{"type": "integer"}, "expected integer"},
+		{"nullable", nil, map[string]any{"type": []any{"string", "null"}}, ""},
+		{"enum", "maybe", map[string]any{"enum": []any{"yes", "no"}}, "not one of"},
+		{
+			"array items",
+			[]any{"a", 1.0},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"$[1]: expected string",
+		},
+		{
+			"no additional properties",
+			map[string]any{"x": 1.0},
+			map[string]any{"type": "object", "additionalProperties": false},
+			`unexpected property "x"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchema(tt.value, tt.schema, "$")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSchema() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSchema() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
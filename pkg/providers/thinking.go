@@ -0,0 +1,54 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// effortBudgets translates reasoning_effort into thinking tokens for
+// protocols that budget thinking instead of taking an effort level.
+var effortBudgets = map[string]int{
+	"minimal": 1024,
+	"low":     2048,
+	"medium":  8192,
+	"high":    24576,
+}
+
+// thinkingBudget returns the thinking token budget for cfg, and false when
+// the model config leaves thinking at the model default. A zero budget with
+// true means thinking is turned off (reasoning_effort "none").
+func thinkingBudget(cfg *config.ModelConfig) (int, bool) {
+	if cfg.ThinkingBudget > 0 {
+		return cfg.ThinkingBudget, true
+	}
+	effort := strings.ToLower(cfg.ReasoningEffort)
+	if effort == "none" {
+		return 0, true
+	}
+	budget, ok := effortBudgets[effort]
+	return budget, ok
+}
+
+// reasoningEffort returns the reasoning_effort for cfg, deriving the closest
+// level from thinking_budget when no effort is configured.
+func reasoningEffort(cfg *config.ModelConfig) string {
+	if cfg.ReasoningEffort != "" {
+		return strings.ToLower(cfg.ReasoningEffort)
+	}
+	switch budget := cfg.ThinkingBudget; {
+	case budget <= 0:
+		return ""
+	case budget <= effortBudgets["low"]:
+		return "low"
+	case budget <= effortBudgets["medium"]:
+		return "medium"
+	default:
+		return "high"
+	}
+}
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestThinkingBudgetAndReasoningEffort(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.ModelConfig
+		wantBudget int
+		wantSet    bool
+		wantEffort string
+	}{
+		{"unset", config.ModelConfig{}, 0, false, ""},
+		{"effort only", config.ModelConfig{ReasoningEffort: "Medium"}, 8192, true, "medium"},
+		{"budget only", config.ModelConfig{ThinkingBudget: 3000}, 3000, true, "medium"},
+		{"budget wins for budgets", config.ModelConfig{ReasoningEffort: "low", ThinkingBudget: 16000}, 16000, true, "low"},
+		{"none disables", config.ModelConfig{ReasoningEffort: "none"}, 0, true, "none"},
+		{"large budget", config.ModelConfig{ThinkingBudget: 50000}, 50000, true, "high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget, ok := thinkingBudget(&tt.cfg)
+			if budget != tt.wantBudget || ok != tt.wantSet {
+				t.Errorf("thinkingBudget() = %d, %v; want %d, %v", budget, ok, tt.wantBudget, tt.wantSet)
+			}
+			if got := reasoningEffort(&tt.cfg); got != tt.wantEffort {
+				t.Errorf("reasoningEffort() = %q, want %q", got, tt.wantEffort)
+			}
+		})
+	}
+}
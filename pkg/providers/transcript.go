@@ -0,0 +1,300 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+// TranscriptEntry is one request/response pair in a transcript file. A
+// transcript is JSONL: one entry per line, in request order.
+type TranscriptEntry struct {
+	Time     time.Time        `json:"time"`
+	Model    string           `json:"model"`
+	Messages []Message        `json:"messages"`
+	Tools    []ToolDefinition `json:"tools,omitempty"`
+	Options  map[string]any   `json:"options,omitempty"`
+	Response *LLMResponse     `json:"response,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// secretPatterns match common credential formats that may end up in
+// prompts or tool output (API keys, bearer tokens).
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9\-]{10,}`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`(?i)bearer [A-Za-z0-9._\-]{16,}`),
+}
+
+const redacted = "[REDACTED]"
+
+// RecordingProvider appends every request to the wrapped provider, with its
+// response or error, to a transcript file. Known secrets and anything that
+// looks like a credential are redacted before writing.
+type RecordingProvider struct {
+	delegate LLMProvider
+	path     string
+	secrets  []string
+	key      *encrypt.Key
+
+	mu sync.Mutex
+}
+
+// NewRecordingProvider records delegate's traffic to path. secrets are
+// literal values (e.g. the configured API key) to redact in addition to the
+// built-in patterns.
+func NewRecordingProvider(delegate LLMProvider, path string, secrets ...string) *RecordingProvider {
+	var nonEmpty []string
+	for _, s := range secrets {
+		if s != "" {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	return &RecordingProvider{delegate: delegate, path: path, secrets: nonEmpty}
+}
+
+// SetEncryptionKey makes the transcript's entries encrypted with key, one
+// line each. ReplayProvider reads them back given the same key.
+func (p *RecordingProvider) SetEncryptionKey(key *encrypt.Key) {
+	p.key = key
+}
+
+func (p *RecordingProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
+	p.record(messages, tools, model, options, resp, err)
+	return resp, err
+}
+
+// StreamChat streams when the wrapped provider supports it and otherwise
+// falls back to Chat. The assembled response is recorded.
+func (p *RecordingProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	sp, ok := p.delegate.(StreamingProvider)
+	if !ok {
+		return p.Chat(ctx, messages, tools, model, options)
+	}
+	resp, err := sp.StreamChat(ctx, messages, tools, model, options, onDelta)
+	p.record(messages, tools, model, options, resp, err)
+	return resp, err
+}
+
+func (p *RecordingProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
+
+func (p *RecordingProvider) SupportsStructuredOutput() bool {
+	sp, ok := p.delegate.(StructuredOutputProvider)
+	return ok && sp.SupportsStructuredOutput()
+}
+
+func (p *RecordingProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.delegate)
+}
+
+func (p *RecordingProvider) ListModels(ctx context.Context) ([]string, error) {
+	return ListModels(ctx, p.delegate)
+}
+
+// Close releases the wrapped provider if it holds resources.
+func (p *RecordingProvider) Close() {
+	if sp, ok := p.delegate.(StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// record appends one entry. Failures are logged to stderr rather than
+// failing the request: recording is a debugging aid.
+func (p *RecordingProvider) record(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	resp *LLMResponse,
+	callErr error,
+) {
+	entry := TranscriptEntry{
+		Time:     time.Now().UTC(),
+		Model:    model,
+		Messages: messages,
+		Tools:    tools,
+		Options:  options,
+		Response: resp,
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "transcript: failed to encode entry: %v\n", err)
+		return
+	}
+	line, err := p.key.SealString([]byte(p.redact(string(data))))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "transcript: %v\n", err)
+		return
+	}
+	line += "\n"
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "transcript: %v\n", err)
+		return
+	}
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "transcript: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		fmt.Fprintf(os.Stderr, "transcript: %v\n", err)
+	}
+}
+
+// redact operates on the encoded JSON line. Secrets are matched in their
+// JSON-escaped form; the patterns contain no characters JSON escapes.
+func (p *RecordingProvider) redact(s string) string {
+	for _, secret := range p.secrets {
+		quoted, _ := json.Marshal(secret)
+		s = strings.ReplaceAll(s, strings.Trim(string(quoted), `"`), redacted)
+	}
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// ReplayProvider serves the responses of a recorded transcript in order,
+// ignoring the requests it receives. Recorded errors are returned as errors.
+// Used with the "replay/<file>" model to run the agent loop offline.
+type ReplayProvider struct {
+	mu      sync.Mutex
+	entries []TranscriptEntry
+	next    int
+}
+
+// NewReplayProvider loads the transcript at path.
+func NewReplayProvider(path string) (*ReplayProvider, error) {
+	return NewEncryptedReplayProvider(path, nil)
+}
+
+// NewEncryptedReplayProvider loads the transcript at path, decrypting
+// entries recorded with an encryption key.
+func NewEncryptedReplayProvider(path string, key *encrypt.Key) (*ReplayProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open transcript: %w", err)
+	}
+	defer f.Close()
+
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		data, err := key.OpenString(strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			return nil, fmt.Errorf("transcript %s line %d: %w", path, line, err)
+		}
+		var e TranscriptEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("transcript %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("transcript %s is empty", path)
+	}
+	return &ReplayProvider{entries: entries}, nil
+}
+
+func (p *ReplayProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.next >= len(p.entries) {
+		return nil, fmt.Errorf("replay: transcript exhausted after %d responses", len(p.entries))
+	}
+	e := p.entries[p.next]
+	p.next++
+
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+	if e.Response == nil {
+		return nil, fmt.Errorf("replay: entry %d has no response", p.next)
+	}
+	resp := *e.Response
+	return &resp, nil
+}
+
+// StreamChat delivers the recorded content as a single delta.
+func (p *ReplayProvider) StreamChat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta StreamCallback,
+) (*LLMResponse, error) {
+	resp, err := p.Chat(ctx, messages, tools, model, options)
+	if err == nil && resp.Content != "" && onDelta != nil {
+		onDelta(resp.Content)
+	}
+	return resp, err
+}
+
+func (p *ReplayProvider) GetDefaultModel() string {
+	return ""
+}
+
+// Remaining reports how many recorded responses have not been served yet.
+func (p *ReplayProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries) - p.next
+}
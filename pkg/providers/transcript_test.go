@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+type transcriptSource struct {
+	replies []*LLMResponse
+	errs    []error
+}
+
+func (p *transcriptSource) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.replies[0], p.errs[0]
+	p.replies, p.errs = p.replies[1:], p.errs[1:]
+	return resp, err
+}
+
+func (p *transcriptSource) GetDefaultModel() string { return "" }
+
+func TestRecordingProvider_RecordsAndRedacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "transcript.jsonl")
+	source := &transcriptSource{
+		replies: []*LLMResponse{
+			{Content: "", ToolCalls: []ToolCall{{ID: "call_1", Type: "function",
+				Function: &FunctionCall{Name: "exec", Arguments: `{"cmd":"ls"}`}}}, FinishReason: "tool_calls"},
+			nil,
+		},
+		errs: []error{nil, errors.New("API request failed:\n  Status: 429\n  Body:   slow down")},
+	}
+	rec := NewRecordingProvider(source, path, "my-literal-key")
+
+	msgs := []Message{{Role: "user", Content: "my key is sk-abcdefghijklmnopqrstuvwx and my-literal-key"}}
+	if _, err := rec.Chat(context.Background(), msgs, nil, "gpt-4o", map[string]any{"max_tokens": 10}); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, err := rec.Chat(context.Background(), msgs, nil, "gpt-4o", nil); err == nil {
+		t.Fatal("Chat() expected recorded error to pass through")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	text := string(data)
+	if strings.Count(text, "\n") != 2 {
+		t.Fatalf("want 2 entries, got:\n%s", text)
+	}
+	if strings.Contains(text, "sk-abcdefghijklmnopqrstuvwx") || strings.Contains(text, "my-literal-key") {
+		t.Errorf("secrets not redacted:\n%s", text)
+	}
+	if !strings.Contains(text, redacted) {
+		t.Errorf("expected redaction marker:\n%s", text)
+	}
+
+	// The recorded transcript replays in order, errors included.
+	replay, err := NewReplayProvider(path)
+	if err != nil {
+		t.Fatalf("NewReplayProvider() error = %v", err)
+	}
+	resp, err := replay.Chat(context.Background(), nil, nil, "", nil)
+	if err != nil {
+		t.Fatalf("replay Chat() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "exec" || resp.FinishReason != "tool_calls" {
+		t.Errorf("replayed response = %+v", resp)
+	}
+	if _, err := replay.Chat(context.Background(), nil, nil, "", nil); err == nil ||
+		ClassifyError(err, "openai", "gpt-4o").Reason != FailoverRateLimit {
+		t.Errorf("replayed error = %v, want a classifiable rate limit", err)
+	}
+	if _, err := replay.Chat(context.Background(), nil, nil, "", nil); err == nil {
+		t.Error("expected exhausted transcript error")
+	}
+}
+
+func TestCreateProviderFromConfig_RecordAndReplay(t *testing.T) {
+	workspace := t.TempDir()
+	transcript := filepath.Join(workspace, "t.jsonl")
+	if err := os.WriteFile(transcript, []byte(`{"model":"m","messages":[],"response":{"content":"hi","finish_reason":"stop"}}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		Model:     "replay/" + transcript,
+		Record:    "state/rerecord.jsonl",
+		Workspace: workspace,
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != transcript {
+		t.Errorf("modelID = %q", modelID)
+	}
+	rec, ok := p.(*RecordingProvider)
+	if !ok {
+		t.Fatalf("provider = %T, want *RecordingProvider", p)
+	}
+	if rec.path != filepath.Join(workspace, "state", "rerecord.jsonl") {
+		t.Errorf("record path = %q", rec.path)
+	}
+
+	resp, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hello"}}, nil, modelID, nil)
+	if err != nil || resp.Content != "hi" {
+		t.Fatalf("Chat() = %+v, %v", resp, err)
+	}
+}
+
+func TestCreateProviderFromConfig_EncryptedRecordAndReplay(t *testing.T) {
+	workspace := t.TempDir()
+	source := filepath.Join(workspace, "source.jsonl")
+	if err := os.WriteFile(source, []byte(`{"model":"m","messages":[],"response":{"content":"hi","finish_reason":"stop"}}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		Model:         "replay/" + source,
+		Record:        "encrypted.jsonl",
+		Workspace:     workspace,
+		EncryptionKey: "correct horse battery staple",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "my PIN is 4711"}}, nil, "m", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	recorded := filepath.Join(workspace, "encrypted.jsonl")
+	data, err := os.ReadFile(recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "4711") || strings.Count(string(data), "\n") != 1 {
+		t.Errorf("transcript not encrypted line by line:\n%s", data)
+	}
+
+	if _, err := NewReplayProvider(recorded); err == nil {
+		t.Error("replayed an encrypted transcript without the key")
+	}
+	replay, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		Model:         "replay/" + recorded,
+		EncryptionKey: "correct horse battery staple",
+	})
+	if err != nil {
+		t.Fatalf("replay with key: %v", err)
+	}
+	resp, err := replay.Chat(context.Background(), nil, nil, "", nil)
+	if err != nil || resp.Content != "hi" {
+		t.Errorf("replayed = %+v, %v", resp, err)
+	}
+}
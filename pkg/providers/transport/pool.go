@@ -0,0 +1,97 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pool tunes connection reuse. Zero fields keep the net/http defaults.
+type Pool struct {
+	MaxIdleConns        int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // Idle connections kept per host
+	IdleConnTimeout     time.Duration // How long an idle connection stays open
+	DisableHTTP2        bool          // Speak HTTP/1.1 only, which uses less memory
+}
+
+func (p Pool) apply(t *http.Transport) {
+	if p.MaxIdleConns > 0 {
+		t.MaxIdleConns = p.MaxIdleConns
+	}
+	if p.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = p.MaxIdleConnsPerHost
+	}
+	if p.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = p.IdleConnTimeout
+	}
+	if p.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		// A non-nil empty map turns off the built-in HTTP/2 support.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	// Resume TLS sessions when a connection has to be reopened; a full
+	// handshake is the slowest part of a request on small boards.
+	t.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)}
+}
+
+var (
+	poolMu sync.Mutex
+	pool   Pool
+	shared = map[string]*http.Transport{}
+)
+
+// Configure sets the pool settings of transports built afterwards and
+// drops the shared transports built with the previous settings. Call it at
+// startup, before creating providers.
+func Configure(p Pool) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	pool = p
+	for _, t := range shared {
+		t.CloseIdleConnections()
+	}
+	shared = map[string]*http.Transport{}
+}
+
+func currentPool() Pool {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	return pool
+}
+
+// Shared returns the transport for proxy (see ProxyFunc), shared by every
+// provider with the same proxy setting. Providers talking to one endpoint
+// then reuse each other's keep-alive connections and TLS sessions instead
+// of each opening its own. The transport must not be modified.
+func Shared(proxy string) (*http.Transport, error) {
+	key := strings.TrimSpace(proxy)
+	if lower := strings.ToLower(key); lower == Direct || lower == "none" {
+		key = Direct
+	}
+
+	poolMu.Lock()
+	t, ok := shared[key]
+	poolMu.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	t, err := New(proxy)
+	if err != nil {
+		return nil, err
+	}
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if existing, ok := shared[key]; ok {
+		return existing, nil
+	}
+	shared[key] = t
+	return t, nil
+}
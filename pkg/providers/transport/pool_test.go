@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShared_ReusesTransportPerProxy(t *testing.T) {
+	Configure(Pool{})
+
+	env1, err := Shared("")
+	if err != nil {
+		t.Fatalf("Shared() error = %v", err)
+	}
+	env2, _ := Shared("")
+	direct1, _ := Shared("direct")
+	direct2, _ := Shared("NONE")
+	proxied, _ := Shared("socks5://127.0.0.1:1080")
+
+	if env1 != env2 || direct1 != direct2 {
+		t.Error("expected the same transport for the same proxy setting")
+	}
+	if env1 == direct1 || env1 == proxied || direct1 == proxied {
+		t.Error("expected separate transports for different proxy settings")
+	}
+	if direct1.Proxy != nil {
+		t.Error("direct transport has a proxy")
+	}
+	if env1.TLSClientConfig == nil || env1.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("expected a TLS session cache")
+	}
+	if _, err := Shared("ftp://proxy"); err == nil {
+		t.Error("expected an error for an invalid proxy")
+	}
+}
+
+func TestConfigure_TunesTransports(t *testing.T) {
+	before, _ := Shared("")
+	Configure(Pool{
+		MaxIdleConns:        8,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     5 * time.Minute,
+		DisableHTTP2:        true,
+	})
+	defer Configure(Pool{})
+
+	tr, err := Shared("")
+	if err != nil {
+		t.Fatalf("Shared() error = %v", err)
+	}
+	if tr == before {
+		t.Fatal("Configure should replace the shared transports")
+	}
+	if tr.MaxIdleConns != 8 || tr.MaxIdleConnsPerHost != 4 || tr.IdleConnTimeout != 5*time.Minute {
+		t.Errorf("pool = %d/%d/%v", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if tr.ForceAttemptHTTP2 || tr.TLSNextProto == nil || len(tr.TLSNextProto) != 0 {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+
+	fresh, _ := New("")
+	if fresh == tr || fresh.MaxIdleConnsPerHost != 4 {
+		t.Error("New should build a separate transport with the pool settings")
+	}
+}
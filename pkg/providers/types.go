@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
@@ -19,8 +20,29 @@ type (
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ContentBlock           = protocoltypes.ContentBlock
 	CacheControl           = protocoltypes.CacheControl
+	ImagePart              = protocoltypes.ImagePart
+	ResponseSchema         = protocoltypes.ResponseSchema
+	TokenLogprob           = protocoltypes.TokenLogprob
+	TopLogprob             = protocoltypes.TopLogprob
+	APIError               = protocoltypes.APIError
+	ToolCallDelta          = protocoltypes.ToolCallDelta
 )
 
+// Error kinds wrapped by provider errors; test with errors.Is.
+var (
+	ErrAuth            = protocoltypes.ErrAuth
+	ErrRateLimited     = protocoltypes.ErrRateLimited
+	ErrContextTooLong  = protocoltypes.ErrContextTooLong
+	ErrContentFiltered = protocoltypes.ErrContentFiltered
+	ErrOverloaded      = protocoltypes.ErrOverloaded
+)
+
+// NewAPIError builds the error returned for a non-2xx provider response,
+// classified by status code and body.
+func NewAPIError(statusCode int, body string) *APIError {
+	return protocoltypes.NewAPIError(statusCode, body)
+}
+
 type LLMProvider interface {
 	Chat(
 		ctx context.Context,
@@ -37,6 +59,55 @@ type StatefulProvider interface {
 	Close()
 }
 
+// StreamCallback receives incremental text deltas of a completion.
+type StreamCallback func(delta string)
+
+// WithProgress returns a context on which providers report slow setup steps
+// (e.g. pulling a missing model) to fn, so they can be relayed to the user.
+func WithProgress(ctx context.Context, fn func(status string)) context.Context {
+	return protocoltypes.WithProgress(ctx, fn)
+}
+
+// WithToolCallDeltas returns a context on which streaming calls pass each
+// tool-call fragment to fn as it arrives (openai-compatible and anthropic
+// protocols). An error from fn aborts the stream.
+func WithToolCallDeltas(ctx context.Context, fn func(ToolCallDelta) error) context.Context {
+	return protocoltypes.WithToolCallDeltas(ctx, fn)
+}
+
+// StreamingProvider is implemented by providers that can stream partial
+// completions. The returned response is the fully assembled completion,
+// identical in shape to what Chat would return.
+type StreamingProvider interface {
+	LLMProvider
+	StreamChat(
+		ctx context.Context,
+		messages []Message,
+		tools []ToolDefinition,
+		model string,
+		options map[string]any,
+		onDelta StreamCallback,
+	) (*LLMResponse, error)
+}
+
+// StructuredOutputProvider is implemented by providers that enforce
+// options["response_schema"] natively. ChatJSON still validates their
+// output, but skips prompting for the format.
+type StructuredOutputProvider interface {
+	LLMProvider
+	SupportsStructuredOutput() bool
+}
+
+// HealthCheckProvider is implemented by providers that can verify their
+// endpoint and credentials without spending tokens.
+type HealthCheckProvider interface {
+	LLMProvider
+	// Ping returns nil when the endpoint is reachable and accepts the key.
+	Ping(ctx context.Context) error
+	// ListModels returns the model IDs the endpoint serves.
+	ListModels(ctx context.Context) ([]string, error)
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
@@ -57,6 +128,10 @@ type FailoverError struct {
 	Model    string
 	Status   int
 	Wrapped  error
+
+	// RetryAfter is the provider's hint for how long to back off, when it
+	// sent one (Retry-After or rate-limit reset headers).
+	RetryAfter time.Duration
 }
 
 func (e *FailoverError) Error() string {
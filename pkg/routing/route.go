@@ -14,6 +14,7 @@ type RouteInput struct {
 	ParentPeer *RoutePeer
 	GuildID    string
 	TeamID     string
+	AgentID    string // Agent the message addresses by name, e.g. "@coder"
 }
 
 // ResolvedRoute is the result of agent routing.
@@ -23,7 +24,7 @@ type ResolvedRoute struct {
 	AccountID      string
 	SessionKey     string
 	MainSessionKey string
-	MatchedBy      string // "binding.peer", "binding.peer.parent", "binding.guild", "binding.team", "binding.account", "binding.channel", "default"
+	MatchedBy      string // "mention", "binding.peer", "binding.peer.parent", "binding.guild", "binding.team", "binding.account", "binding.channel", "default"
 }
 
 // RouteResolver determines which agent handles a message based on config bindings.
@@ -37,7 +38,8 @@ func NewRouteResolver(cfg *config.Config) *RouteResolver {
 }
 
 // ResolveRoute determines which agent handles the message and constructs session keys.
-// Implements the 7-level priority cascade:
+// An agent the message addresses by name wins; otherwise it implements the
+// 7-level priority cascade:
 // peer > parent_peer > guild > team > account > channel_wildcard > default
 func (r *RouteResolver) ResolveRoute(input RouteInput) ResolvedRoute {
 	channel := strings.ToLower(strings.TrimSpace(input.Channel))
@@ -73,6 +75,11 @@ func (r *RouteResolver) ResolveRoute(input RouteInput) ResolvedRoute {
 		}
 	}
 
+	// Addressed by name
+	if id, ok := r.FindAgent(input.AgentID); ok {
+		return choose(id, "mention")
+	}
+
 	// Priority 1: Peer binding
 	if peer != nil && strings.TrimSpace(peer.ID) != "" {
 		if match := r.findPeerMatch(bindings, peer); match != nil {
@@ -214,6 +221,22 @@ func (r *RouteResolver) findChannelWildcardMatch(bindings []config.AgentBinding)
 	return nil
 }
 
+// FindAgent returns the ID of the agent in agents.list whose ID or name is
+// name, ignoring case, with spaces in names written as dashes.
+func (r *RouteResolver) FindAgent(name string) (string, bool) {
+	if strings.TrimSpace(name) == "" {
+		return "", false
+	}
+	normalized := NormalizeAgentID(name)
+	for _, a := range r.cfg.Agents.List {
+		if NormalizeAgentID(a.ID) == normalized ||
+			(strings.TrimSpace(a.Name) != "" && NormalizeAgentID(a.Name) == normalized) {
+			return NormalizeAgentID(a.ID), true
+		}
+	}
+	return "", false
+}
+
 func (r *RouteResolver) pickAgentID(agentID string) string {
 	trimmed := strings.TrimSpace(agentID)
 	if trimmed == "" {
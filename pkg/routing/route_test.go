@@ -295,3 +295,37 @@ func TestResolveRoute_NoDefaultUsesFirst(t *testing.T) {
 		t.Errorf("AgentID = %q, want 'alpha' (first in list)", route.AgentID)
 	}
 }
+
+func TestResolveRoute_MentionBeatsBindings(t *testing.T) {
+	agents := []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "sales"},
+		{ID: "writer", Name: "Copy Writer"},
+	}
+	bindings := []config.AgentBinding{
+		{
+			AgentID: "sales",
+			Match: config.BindingMatch{
+				Channel:   "telegram",
+				AccountID: "*",
+				Peer:      &config.PeerMatch{Kind: "direct", ID: "user1"},
+			},
+		},
+	}
+	r := NewRouteResolver(testConfig(agents, bindings))
+	input := RouteInput{
+		Channel: "telegram",
+		Peer:    &RoutePeer{Kind: "direct", ID: "user1"},
+		AgentID: "copy-writer",
+	}
+
+	route := r.ResolveRoute(input)
+	if route.AgentID != "writer" || route.MatchedBy != "mention" {
+		t.Errorf("route = %q by %q, want writer by mention", route.AgentID, route.MatchedBy)
+	}
+
+	input.AgentID = "nobody"
+	if route := r.ResolveRoute(input); route.AgentID != "sales" {
+		t.Errorf("unknown mention: AgentID = %q, want the bound agent", route.AgentID)
+	}
+}
@@ -0,0 +1,229 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// MaxArchived is how many archived conversations are kept per session; the
+// oldest go first.
+const MaxArchived = 20
+
+// ErrNotArchived is returned by Resume for an unknown archive ID.
+var ErrNotArchived = errors.New("no such archived conversation")
+
+// Archived describes a saved conversation: one put aside with Reset, or a
+// checkpoint.
+type Archived struct {
+	ID       string
+	Updated  time.Time
+	Messages int
+	Preview  string // The first user message
+}
+
+// snapshotDir returns the directory holding the saved conversations of
+// kind ("archive" or "checkpoints") for session key, or "" when sessions
+// aren't stored on disk.
+func (sm *SessionManager) snapshotDir(kind, key string) string {
+	if sm.storage == "" {
+		return ""
+	}
+	name := sanitizeFilename(key)
+	if name == "." || !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+	return filepath.Join(sm.storage, kind, name)
+}
+
+func (sm *SessionManager) archiveDir(key string) string {
+	return sm.snapshotDir("archive", key)
+}
+
+// Reset starts a new conversation in session key, archiving the current one
+// so it can be resumed later. It returns the archive ID, or "" when there
+// was nothing to archive. The session's model choice is kept.
+func (sm *SessionManager) Reset(key string) (string, error) {
+	id, err := sm.archive(key)
+	if err != nil {
+		return "", err
+	}
+	sm.mu.Lock()
+	if session, ok := sm.sessions[key]; ok {
+		session.Messages = []providers.Message{}
+		session.Summary = ""
+		session.Updated = time.Now()
+	}
+	sm.mu.Unlock()
+	return id, sm.Save(key)
+}
+
+// Resume replaces the conversation in session key by the archived one with
+// id. The conversation it replaces is archived in turn; its ID is returned,
+// or "" when it was empty.
+func (sm *SessionManager) Resume(key, id string) (string, error) {
+	dir := sm.archiveDir(key)
+	if dir == "" || id == "" || !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+		return "", ErrNotArchived
+	}
+	path := filepath.Join(dir, id+".json")
+	archived, err := sm.loadSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotArchived
+	}
+	if err != nil {
+		return "", err
+	}
+
+	current, err := sm.archive(key)
+	if err != nil {
+		return "", err
+	}
+	if err := sm.restore(key, archived); err != nil {
+		return "", err
+	}
+	os.Remove(path)
+	return current, nil
+}
+
+// ListArchived returns the archived conversations of session key, newest
+// first.
+func (sm *SessionManager) ListArchived(key string) []Archived {
+	list := sm.listSnapshots(sm.archiveDir(key))
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	return list
+}
+
+// archive writes the conversation in session key to its archive directory
+// and returns its ID, or "" when the conversation is empty or sessions
+// aren't stored on disk.
+func (sm *SessionManager) archive(key string) (string, error) {
+	dir := sm.archiveDir(key)
+	if dir == "" {
+		return "", nil
+	}
+	snapshot, ok := sm.snapshot(key)
+	if !ok {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// IDs sort by time; a second reset within the same second gets a suffix.
+	base := time.Now().Format("20060102-150405")
+	id := base
+	for n := 2; fileExists(filepath.Join(dir, id+".json")); n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	if err := sm.writeSnapshot(filepath.Join(dir, id+".json"), snapshot); err != nil {
+		return "", err
+	}
+
+	if list := sm.ListArchived(key); len(list) > MaxArchived {
+		for _, a := range list[MaxArchived:] {
+			os.Remove(filepath.Join(dir, a.ID+".json"))
+		}
+	}
+	return id, nil
+}
+
+// snapshot returns a copy of the conversation in session key, or false
+// when it is empty.
+func (sm *SessionManager) snapshot(key string) (*Session, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	stored, ok := sm.sessions[key]
+	if !ok || (len(stored.Messages) == 0 && stored.Summary == "") {
+		return nil, false
+	}
+	return &Session{
+		Key:      stored.Key,
+		Messages: append([]providers.Message(nil), stored.Messages...),
+		Summary:  stored.Summary,
+		Created:  stored.Created,
+		Updated:  stored.Updated,
+	}, true
+}
+
+// restore replaces the conversation in session key by s and saves it.
+func (sm *SessionManager) restore(key string, s *Session) error {
+	sm.mu.Lock()
+	session, ok := sm.sessions[key]
+	if !ok {
+		session = &Session{Key: key, Created: s.Created}
+		sm.sessions[key] = session
+	}
+	session.Messages = s.Messages
+	if session.Messages == nil {
+		session.Messages = []providers.Message{}
+	}
+	session.Summary = s.Summary
+	session.Updated = time.Now()
+	sm.mu.Unlock()
+	return sm.Save(key)
+}
+
+func (sm *SessionManager) writeSnapshot(path string, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return sm.key.WriteFile(path, data, 0o644)
+}
+
+func (sm *SessionManager) loadSnapshot(path string) (*Session, error) {
+	data, err := sm.key.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// listSnapshots describes the conversations saved in dir, named by their
+// file names.
+func (sm *SessionManager) listSnapshots(dir string) []Archived {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var list []Archived
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		s, err := sm.loadSnapshot(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		a := Archived{ID: id, Updated: s.Updated, Messages: len(s.Messages)}
+		for _, m := range s.Messages {
+			if m.Role == "user" {
+				a.Preview = m.Content
+				break
+			}
+		}
+		list = append(list, a)
+	}
+	return list
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+func TestResetAndResume(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := encrypt.ParseKey("secret")
+	sm := NewEncryptedSessionManager(dir, key)
+	const sk = "agent:main:telegram:direct:42"
+
+	sm.AddMessage(sk, "user", "plan my trip to Rome")
+	sm.AddMessage(sk, "assistant", "Sure")
+	sm.SetSummary(sk, "trip planning")
+	sm.SetModel(sk, "fast")
+
+	first, err := sm.Reset(sk)
+	if err != nil || first == "" {
+		t.Fatalf("Reset() = %q, %v", first, err)
+	}
+	if len(sm.GetHistory(sk)) != 0 || sm.GetSummary(sk) != "" || sm.GetModel(sk) != "fast" {
+		t.Error("Reset() should clear the conversation and keep the model")
+	}
+	if id, err := sm.Reset(sk); id != "" || err != nil {
+		t.Errorf("Reset() of an empty conversation = %q, %v", id, err)
+	}
+
+	sm.AddMessage(sk, "user", "what's the weather?")
+	sm.Save(sk)
+	archived := sm.ListArchived(sk)
+	if len(archived) != 1 || archived[0].ID != first || archived[0].Messages != 2 ||
+		archived[0].Preview != "plan my trip to Rome" {
+		t.Fatalf("ListArchived() = %+v", archived)
+	}
+	if len(sm.ListArchived("agent:main:telegram:direct:7")) != 0 {
+		t.Error("archives of one session should not show in another")
+	}
+
+	// A restart in between loses nothing.
+	sm = NewEncryptedSessionManager(dir, key)
+	second, err := sm.Resume(sk, first)
+	if err != nil || second == "" || second == first {
+		t.Fatalf("Resume() = %q, %v", second, err)
+	}
+	if h := sm.GetHistory(sk); len(h) != 2 || h[0].Content != "plan my trip to Rome" || sm.GetSummary(sk) != "trip planning" {
+		t.Errorf("history after Resume() = %+v", h)
+	}
+	archived = sm.ListArchived(sk)
+	if len(archived) != 1 || archived[0].ID != second || archived[0].Preview != "what's the weather?" {
+		t.Errorf("ListArchived() after Resume() = %+v", archived)
+	}
+
+	if _, err := sm.Resume(sk, first); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("resuming a conversation twice: %v", err)
+	}
+	if _, err := sm.Resume(sk, "../../"+second); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("Resume() with a path: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "archive", sanitizeFilename(sk), second+".json"))
+	if err != nil || !encrypt.IsSealed(data) {
+		t.Errorf("archive should be encrypted: %v", err)
+	}
+}
+
+func TestReset_KeepsMaxArchived(t *testing.T) {
+	sm := NewSessionManager(t.TempDir())
+	for range MaxArchived + 3 {
+		sm.AddMessage("s", "user", "hi")
+		if _, err := sm.Reset("s"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(sm.ListArchived("s")); n != MaxArchived {
+		t.Errorf("archived = %d, want %d", n, MaxArchived)
+	}
+}
+
+func TestReset_WithoutStorage(t *testing.T) {
+	sm := NewSessionManager("")
+	sm.AddMessage("s", "user", "hi")
+	if id, err := sm.Reset("s"); id != "" || err != nil || len(sm.GetHistory("s")) != 0 {
+		t.Errorf("Reset() = %q, %v", id, err)
+	}
+	if _, err := sm.Resume("s", "x"); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("Resume() = %v", err)
+	}
+}
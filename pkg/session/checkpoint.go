@@ -0,0 +1,94 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// ErrNoCheckpoint is returned for an unknown checkpoint name.
+var ErrNoCheckpoint = errors.New("no such checkpoint")
+
+var reCheckpointName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,39}$`)
+
+// ValidCheckpointName reports whether name can name a checkpoint: up to 40
+// letters, digits, '_', '.' and '-', starting with a letter or digit.
+func ValidCheckpointName(name string) bool {
+	return reCheckpointName.MatchString(name)
+}
+
+func (sm *SessionManager) checkpointPath(key, name string) (string, error) {
+	if !ValidCheckpointName(name) {
+		return "", fmt.Errorf("invalid checkpoint name %q", name)
+	}
+	dir := sm.snapshotDir("checkpoints", key)
+	if dir == "" {
+		return "", errors.New("sessions are not stored on disk")
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveCheckpoint saves the conversation in session key under name,
+// replacing an earlier checkpoint with that name. The conversation goes on
+// unchanged. It reports whether a checkpoint was replaced.
+func (sm *SessionManager) SaveCheckpoint(key, name string) (bool, error) {
+	path, err := sm.checkpointPath(key, name)
+	if err != nil {
+		return false, err
+	}
+	snapshot, ok := sm.snapshot(key)
+	if !ok {
+		return false, errors.New("the conversation is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	replaced := fileExists(path)
+	return replaced, sm.writeSnapshot(path, snapshot)
+}
+
+// Branch replaces the conversation in session key by a copy of checkpoint
+// name, which stays as it is. The conversation it replaces is archived (see
+// Reset); its ID is returned, or "" when it was empty.
+func (sm *SessionManager) Branch(key, name string) (string, error) {
+	path, err := sm.checkpointPath(key, name)
+	if err != nil {
+		return "", err
+	}
+	checkpoint, err := sm.loadSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoCheckpoint
+	}
+	if err != nil {
+		return "", err
+	}
+	archived, err := sm.archive(key)
+	if err != nil {
+		return "", err
+	}
+	return archived, sm.restore(key, checkpoint)
+}
+
+// DeleteCheckpoint deletes checkpoint name of session key.
+func (sm *SessionManager) DeleteCheckpoint(key, name string) error {
+	path, err := sm.checkpointPath(key, name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNoCheckpoint
+	}
+	return err
+}
+
+// ListCheckpoints returns the checkpoints of session key by name; their ID
+// is the name.
+func (sm *SessionManager) ListCheckpoints(key string) []Archived {
+	list := sm.listSnapshots(sm.snapshotDir("checkpoints", key))
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckpoints(t *testing.T) {
+	sm := NewSessionManager(t.TempDir())
+	const sk = "agent:main:cli:direct"
+
+	if _, err := sm.SaveCheckpoint(sk, "start"); err == nil {
+		t.Error("SaveCheckpoint() of an empty conversation should fail")
+	}
+	sm.AddMessage(sk, "user", "write a parser")
+	sm.AddMessage(sk, "assistant", "Which approach?")
+	if replaced, err := sm.SaveCheckpoint(sk, "start"); replaced || err != nil {
+		t.Fatalf("SaveCheckpoint() = %v, %v", replaced, err)
+	}
+	if len(sm.GetHistory(sk)) != 2 {
+		t.Error("SaveCheckpoint() should not change the conversation")
+	}
+
+	// Explore one approach, then branch off for another.
+	sm.AddMessage(sk, "user", "use a recursive descent parser")
+	archived, err := sm.Branch(sk, "start")
+	if err != nil || archived == "" {
+		t.Fatalf("Branch() = %q, %v", archived, err)
+	}
+	if h := sm.GetHistory(sk); len(h) != 2 || h[1].Content != "Which approach?" {
+		t.Errorf("history after Branch() = %+v", h)
+	}
+	if a := sm.ListArchived(sk); len(a) != 1 || a[0].Messages != 3 {
+		t.Errorf("the first branch should be archived: %+v", a)
+	}
+
+	// The checkpoint stays intact for another branch.
+	sm.AddMessage(sk, "user", "use a parser generator")
+	if _, err := sm.Branch(sk, "start"); err != nil {
+		t.Fatalf("second Branch() error = %v", err)
+	}
+	if h := sm.GetHistory(sk); len(h) != 2 {
+		t.Errorf("history after second Branch() = %+v", h)
+	}
+
+	sm.AddMessage(sk, "user", "more")
+	if replaced, _ := sm.SaveCheckpoint(sk, "start"); !replaced {
+		t.Error("saving under an existing name should replace it")
+	}
+	if list := sm.ListCheckpoints(sk); len(list) != 1 || list[0].ID != "start" || list[0].Messages != 3 {
+		t.Errorf("ListCheckpoints() = %+v", list)
+	}
+
+	if _, err := sm.Branch(sk, "missing"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Branch(missing) error = %v", err)
+	}
+	if _, err := sm.SaveCheckpoint(sk, "../escape"); err == nil {
+		t.Error("SaveCheckpoint() should reject names with paths")
+	}
+	if err := sm.DeleteCheckpoint(sk, "start"); err != nil {
+		t.Errorf("DeleteCheckpoint() error = %v", err)
+	}
+	if err := sm.DeleteCheckpoint(sk, "start"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("second DeleteCheckpoint() error = %v", err)
+	}
+}
+
+func TestValidCheckpointName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"x":          true,
+		"before-fix": true,
+		"v1.2_a":     true,
+		"":           false,
+		".hidden":    false,
+		"a/b":        false,
+		"has space":  false,
+	} {
+		if got := ValidCheckpointName(name); got != want {
+			t.Errorf("ValidCheckpointName(%q) = %v", name, got)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -15,6 +17,7 @@ type Session struct {
 	Key      string              `json:"key"`
 	Messages []providers.Message `json:"messages"`
 	Summary  string              `json:"summary,omitempty"`
+	Model    string              `json:"model,omitempty"` // model_name chosen with /model; empty uses the agent's
 	Created  time.Time           `json:"created"`
 	Updated  time.Time           `json:"updated"`
 }
@@ -23,12 +26,21 @@ type SessionManager struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
 	storage  string
+	key      *encrypt.Key
 }
 
 func NewSessionManager(storage string) *SessionManager {
+	return NewEncryptedSessionManager(storage, nil)
+}
+
+// NewEncryptedSessionManager stores sessions encrypted with key. Sessions
+// saved without encryption are still loaded, and encrypted on their next
+// save.
+func NewEncryptedSessionManager(storage string, key *encrypt.Key) *SessionManager {
 	sm := &SessionManager{
 		sessions: make(map[string]*Session),
 		storage:  storage,
+		key:      key,
 	}
 
 	if storage != "" {
@@ -122,6 +134,37 @@ func (sm *SessionManager) SetSummary(key string, summary string) {
 	}
 }
 
+// GetModel returns the model override of a session, or "" for none.
+func (sm *SessionManager) GetModel(key string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return ""
+	}
+	return session.Model
+}
+
+// SetModel sets the model override of a session, creating the session if
+// needed; "" clears it.
+func (sm *SessionManager) SetModel(key string, model string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		session = &Session{
+			Key:      key,
+			Messages: []providers.Message{},
+			Created:  time.Now(),
+		}
+		sm.sessions[key] = session
+	}
+	session.Model = model
+	session.Updated = time.Now()
+}
+
 func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -180,6 +223,7 @@ func (sm *SessionManager) Save(key string) error {
 	snapshot := Session{
 		Key:     stored.Key,
 		Summary: stored.Summary,
+		Model:   stored.Model,
 		Created: stored.Created,
 		Updated: stored.Updated,
 	}
@@ -195,6 +239,13 @@ func (sm *SessionManager) Save(key string) error {
 	if err != nil {
 		return err
 	}
+	if data, err = sm.key.Seal(data); err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if sm.key != nil {
+		perm = 0o600
+	}
 
 	sessionPath := filepath.Join(sm.storage, filename+".json")
 	tmpFile, err := os.CreateTemp(sm.storage, "session-*.tmp")
@@ -214,7 +265,7 @@ func (sm *SessionManager) Save(key string) error {
 		_ = tmpFile.Close()
 		return err
 	}
-	if err := tmpFile.Chmod(0o644); err != nil {
+	if err := tmpFile.Chmod(perm); err != nil {
 		_ = tmpFile.Close()
 		return err
 	}
@@ -249,8 +300,9 @@ func (sm *SessionManager) loadSessions() error {
 		}
 
 		sessionPath := filepath.Join(sm.storage, file.Name())
-		data, err := os.ReadFile(sessionPath)
+		data, err := sm.key.ReadFile(sessionPath)
 		if err != nil {
+			logger.WarnCF("session", "Session not loaded", map[string]any{"path": sessionPath, "error": err.Error()})
 			continue
 		}
 
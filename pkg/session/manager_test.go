@@ -3,7 +3,10 @@ package session
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -72,3 +75,63 @@ func TestSave_RejectsPathTraversal(t *testing.T) {
 		}
 	}
 }
+
+func TestModelOverride_Persists(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewSessionManager(tmpDir)
+
+	key := "telegram:42"
+	sm.SetModel(key, "gpt-4o")
+	if err := sm.Save(key); err != nil {
+		t.Fatalf("Save(%q) failed: %v", key, err)
+	}
+
+	sm2 := NewSessionManager(tmpDir)
+	if got := sm2.GetModel(key); got != "gpt-4o" {
+		t.Errorf("GetModel() after reload = %q, want %q", got, "gpt-4o")
+	}
+	sm2.SetModel(key, "")
+	if got := sm2.GetModel(key); got != "" {
+		t.Errorf("GetModel() after clearing = %q", got)
+	}
+}
+
+func TestEncryptedSessionManager(t *testing.T) {
+	tmpDir := t.TempDir()
+	key, err := encrypt.ParseKey("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A session saved before encryption was enabled.
+	plain := NewSessionManager(tmpDir)
+	plain.AddMessage("cli:old", "user", "from before")
+	if err := plain.Save("cli:old"); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewEncryptedSessionManager(tmpDir, key)
+	sm.AddMessage("telegram:1", "user", "my address is 1 Main St")
+	if err := sm.Save("telegram:1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "telegram_1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypt.IsSealed(data) || strings.Contains(string(data), "Main St") {
+		t.Errorf("session stored in plaintext: %q", data)
+	}
+
+	reloaded := NewEncryptedSessionManager(tmpDir, key)
+	if h := reloaded.GetHistory("telegram:1"); len(h) != 1 || h[0].Content != "my address is 1 Main St" {
+		t.Errorf("encrypted session history = %+v", h)
+	}
+	if h := reloaded.GetHistory("cli:old"); len(h) != 1 {
+		t.Errorf("plaintext session not loaded: %+v", h)
+	}
+
+	if h := NewSessionManager(tmpDir).GetHistory("telegram:1"); len(h) != 0 {
+		t.Errorf("encrypted session loaded without the key: %+v", h)
+	}
+}
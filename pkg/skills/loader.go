@@ -25,15 +25,20 @@ const (
 )
 
 type SkillMetadata struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords,omitempty"` // Words in a request that bring the skill into context
+	Tools       []string `json:"tools,omitempty"`    // The only tools to offer while the skill is active
 }
 
 type SkillInfo struct {
-	Name        string `json:"name"`
-	Path        string `json:"path"`
-	Source      string `json:"source"`
-	Description string `json:"description"`
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Source      string   `json:"source"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Tools       []string `json:"tools,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"` // See SetEnabled
 }
 
 func (info SkillInfo) validate() error {
@@ -73,9 +78,11 @@ func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string
 	}
 }
 
+// ListSkills returns the installed skills, disabled ones included.
 func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	skills := make([]SkillInfo, 0)
 	seen := make(map[string]bool)
+	disabled := sl.disabledSkills()
 
 	addSkills := func(dir, source string) {
 		if dir == "" {
@@ -102,7 +109,10 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 			if metadata != nil {
 				info.Description = metadata.Description
 				info.Name = metadata.Name
+				info.Keywords = metadata.Keywords
+				info.Tools = metadata.Tools
 			}
+			info.Disabled = disabled[info.Name]
 			if err := info.validate(); err != nil {
 				slog.Warn("invalid skill from "+source, "name", info.Name, "error", err)
 				continue
@@ -176,6 +186,9 @@ func (sl *SkillsLoader) BuildSkillsSummary() string {
 	var lines []string
 	lines = append(lines, "<skills>")
 	for _, s := range allSkills {
+		if s.Disabled {
+			continue
+		}
 		escapedName := escapeXML(s.Name)
 		escapedDesc := escapeXML(s.Description)
 		escapedPath := escapeXML(s.Path)
@@ -211,15 +224,9 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	}
 
 	// Try JSON first (for backward compatibility)
-	var jsonMeta struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
+	var jsonMeta SkillMetadata
 	if err := json.Unmarshal([]byte(frontmatter), &jsonMeta); err == nil {
-		return &SkillMetadata{
-			Name:        jsonMeta.Name,
-			Description: jsonMeta.Description,
-		}
+		return &jsonMeta
 	}
 
 	// Fall back to simple YAML parsing
@@ -227,7 +234,21 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	return &SkillMetadata{
 		Name:        yamlMeta["name"],
 		Description: yamlMeta["description"],
+		Keywords:    parseList(yamlMeta["keywords"]),
+		Tools:       parseList(yamlMeta["tools"]),
+	}
+}
+
+// parseList parses a one-line YAML list, "[a, b]" or "a, b".
+func parseList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.Trim(strings.TrimSpace(item), "\"'"); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
 // parseSimpleYAML parses simple key: value YAML format
@@ -326,3 +326,72 @@ func TestStripFrontmatter(t *testing.T) {
 		})
 	}
 }
+
+func TestListSkillsKeywordsAndTools(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "skills", "deploy")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	content := "---\nname: deploy\ndescription: Deploys\nkeywords: [deploy, \"ship it\"]\ntools: exec, read_file\n---\n\n# deploy"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+
+	skills := NewSkillsLoader(tmp, "", "").ListSkills()
+
+	require.Len(t, skills, 1)
+	assert.Equal(t, []string{"deploy", "ship it"}, skills[0].Keywords)
+	assert.Equal(t, []string{"exec", "read_file"}, skills[0].Tools)
+}
+
+func TestSetEnabled(t *testing.T) {
+	tmp := t.TempDir()
+	global := filepath.Join(tmp, "global")
+	createSkillDir(t, global, "weather", "weather", "forecasts")
+	sl := NewSkillsLoader(filepath.Join(tmp, "workspace"), global, "")
+
+	require.NoError(t, sl.SetEnabled("weather", false))
+	skills := sl.ListSkills()
+	require.Len(t, skills, 1)
+	assert.True(t, skills[0].Disabled)
+	assert.NotContains(t, sl.BuildSkillsSummary(), "weather")
+
+	require.NoError(t, sl.SetEnabled("weather", true))
+	assert.False(t, sl.ListSkills()[0].Disabled)
+	assert.Contains(t, sl.BuildSkillsSummary(), "weather")
+
+	assert.Error(t, sl.SetEnabled("missing", false))
+}
+
+func TestLoadExamples(t *testing.T) {
+	tmp := t.TempDir()
+	createSkillDir(t, filepath.Join(tmp, "skills"), "weather", "weather", "forecasts")
+	createSkillDir(t, filepath.Join(tmp, "skills"), "plain", "plain", "no examples")
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "skills", "weather", "EXAMPLES.md"),
+		[]byte("\nUser: rain?\nAssistant: No.\n"), 0o644))
+	sl := NewSkillsLoader(tmp, "", "")
+
+	examples, ok := sl.LoadExamples("weather")
+	assert.True(t, ok)
+	assert.Equal(t, "User: rain?\nAssistant: No.", examples)
+	_, ok = sl.LoadExamples("plain")
+	assert.False(t, ok)
+}
+
+func TestMatchKeywords(t *testing.T) {
+	candidates := []SkillInfo{
+		{Name: "deploy", Keywords: []string{"deploy", "ship it"}},
+		{Name: "git", Keywords: []string{"git"}},
+		{Name: "off", Keywords: []string{"deploy"}, Disabled: true},
+		{Name: "none"},
+	}
+	names := func(message string) []string {
+		var out []string
+		for _, s := range MatchKeywords(candidates, message) {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"deploy"}, names("Please DEPLOY the site"))
+	assert.Equal(t, []string{"deploy"}, names("ok, ship it!"))
+	assert.Empty(t, names("the redeployment and digital stuff"))
+	assert.Equal(t, []string{"deploy", "git"}, names("git push, then deploy"))
+}
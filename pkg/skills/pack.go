@@ -0,0 +1,141 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// disabledFile lists the skills turned off with `picoclaw skills disable`,
+// one name per line. It lives in the workspace skills directory, so it
+// covers global and builtin skills too.
+const disabledFile = ".disabled"
+
+// examplesFile holds a skill's optional few-shot examples, next to its
+// SKILL.md.
+const examplesFile = "EXAMPLES.md"
+
+func (sl *SkillsLoader) disabledSkills() map[string]bool {
+	disabled := make(map[string]bool)
+	if sl.workspaceSkills == "" {
+		return disabled
+	}
+	data, err := os.ReadFile(filepath.Join(sl.workspaceSkills, disabledFile))
+	if err != nil {
+		return disabled
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name := strings.TrimSpace(line); name != "" && !strings.HasPrefix(name, "#") {
+			disabled[name] = true
+		}
+	}
+	return disabled
+}
+
+// SetEnabled turns the named skill on or off. A disabled skill stays
+// installed but is left out of the skills summary and never matched to a
+// request.
+func (sl *SkillsLoader) SetEnabled(name string, enabled bool) error {
+	found := false
+	for _, s := range sl.ListSkills() {
+		if s.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("skill %q not found", name)
+	}
+
+	disabled := sl.disabledSkills()
+	if enabled {
+		delete(disabled, name)
+	} else {
+		disabled[name] = true
+	}
+	path := filepath.Join(sl.workspaceSkills, disabledFile)
+	if len(disabled) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	names := make([]string, 0, len(disabled))
+	for n := range disabled {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if err := os.MkdirAll(sl.workspaceSkills, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(names, "\n")+"\n"), 0o644)
+}
+
+// LoadExamples returns the few-shot examples of the named skill, from the
+// EXAMPLES.md next to its SKILL.md.
+func (sl *SkillsLoader) LoadExamples(name string) (string, bool) {
+	for _, s := range sl.ListSkills() {
+		if s.Name != name {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(s.Path), examplesFile))
+		if err != nil {
+			return "", false
+		}
+		examples := strings.TrimSpace(string(data))
+		return examples, examples != ""
+	}
+	return "", false
+}
+
+// MatchKeywords returns the skills among candidates with a keyword that
+// occurs in message as a whole word or phrase, ignoring case. Disabled
+// skills never match.
+func MatchKeywords(candidates []SkillInfo, message string) []SkillInfo {
+	message = strings.ToLower(message)
+	var matched []SkillInfo
+	for _, s := range candidates {
+		if s.Disabled {
+			continue
+		}
+		for _, kw := range s.Keywords {
+			if containsWord(message, strings.ToLower(strings.TrimSpace(kw))) {
+				matched = append(matched, s)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// containsWord reports whether word occurs in s without a letter or digit
+// directly before or after it.
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for start := 0; ; {
+		i := strings.Index(s[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,247 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package sysinfo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Metrics alert rules watch.
+const (
+	MetricCPU         = "cpu"         // Busy share of the CPUs, in percent
+	MetricMemory      = "memory"      // RAM used, in percent
+	MetricTemperature = "temperature" // The hottest thermal zone, or Target, in °C
+	MetricDisk        = "disk"        // Space used of Target (default /), in percent
+	MetricLoad        = "load"        // 1-minute load average
+)
+
+const defaultCheckInterval = time.Minute
+
+// Rule alerts when Metric stays above Above, or below Below, for For.
+type Rule struct {
+	Metric string
+	Target string  // The disk's path or the thermal zone
+	Above  float64 // None when 0
+	Below  float64 // None when 0
+	For    time.Duration
+}
+
+// value reads the rule's metric from s; false when s doesn't have it.
+func (r Rule) value(s *Snapshot) (float64, bool) {
+	switch r.Metric {
+	case MetricCPU:
+		return s.CPUPercent, true
+	case MetricMemory:
+		return s.Memory.UsedPercent(), s.Memory.Total > 0
+	case MetricLoad:
+		return s.Load[0], true
+	case MetricDisk:
+		for _, d := range s.Disks {
+			if d.Path == r.diskPath() {
+				return d.UsedPercent(), d.Total > 0
+			}
+		}
+	case MetricTemperature:
+		hottest, found := 0.0, false
+		for _, t := range s.Temperatures {
+			if (r.Target == "" || t.Zone == r.Target) && (!found || t.Celsius > hottest) {
+				hottest, found = t.Celsius, true
+			}
+		}
+		return hottest, found
+	}
+	return 0, false
+}
+
+func (r Rule) diskPath() string {
+	if r.Target == "" {
+		return "/"
+	}
+	return r.Target
+}
+
+func (r Rule) broken(v float64) bool {
+	return (r.Above != 0 && v > r.Above) || (r.Below != 0 && v < r.Below)
+}
+
+// Name describes what the rule watches, e.g. "disk use of /data".
+func (r Rule) Name() string {
+	switch r.Metric {
+	case MetricCPU:
+		return "CPU use"
+	case MetricMemory:
+		return "memory use"
+	case MetricLoad:
+		return "load"
+	case MetricDisk:
+		return "disk use of " + r.diskPath()
+	case MetricTemperature:
+		if r.Target != "" {
+			return r.Target + " temperature"
+		}
+		return "temperature"
+	}
+	return r.Metric
+}
+
+// format writes v in the metric's unit.
+func (r Rule) format(v float64) string {
+	switch r.Metric {
+	case MetricTemperature:
+		return fmt.Sprintf("%.1f°C", v)
+	case MetricLoad:
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprintf("%.0f%%", v)
+}
+
+// Limit describes the rule's threshold, e.g. "above 80°C for 5m".
+func (r Rule) Limit() string {
+	var parts []string
+	if r.Above != 0 {
+		parts = append(parts, "above "+r.format(r.Above))
+	}
+	if r.Below != 0 {
+		parts = append(parts, "below "+r.format(r.Below))
+	}
+	limit := strings.Join(parts, " or ")
+	if r.For > 0 {
+		limit += " for " + strings.TrimSuffix(r.For.String(), "0s")
+	}
+	return limit
+}
+
+// Alert is a rule starting or ceasing to be broken.
+type Alert struct {
+	Rule     Rule
+	Value    float64
+	Resolved bool
+}
+
+// Message is the notification sent to the user.
+func (a Alert) Message() string {
+	name := strings.ToUpper(a.Rule.Name()[:1]) + a.Rule.Name()[1:]
+	if a.Resolved {
+		return fmt.Sprintf("✅ %s is back to %s (alert: %s)", name, a.Rule.format(a.Value), a.Rule.Limit())
+	}
+	return fmt.Sprintf("⚠️ %s is %s, %s", name, a.Rule.format(a.Value), a.Rule.Limit())
+}
+
+type watch struct {
+	rule   Rule
+	since  time.Time // When the rule began to be broken; zero while it isn't
+	firing bool
+}
+
+// Monitor checks the system against its rules at an interval, and
+// notifies once when a rule has been broken for its duration, and once
+// when it no longer is.
+type Monitor struct {
+	watches  []*watch
+	interval time.Duration
+	disks    []string
+	notify   func(Alert)
+
+	// collect measures the system; replaced in tests.
+	collect func(ctx context.Context, opts Options) (*Snapshot, error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMonitor creates a Monitor checking rules every interval (a minute
+// when 0), and calling notify with the alerts.
+func NewMonitor(rules []Rule, interval time.Duration, notify func(Alert)) *Monitor {
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+	m := &Monitor{interval: interval, notify: notify, collect: Collect}
+	for _, rule := range rules {
+		m.watches = append(m.watches, &watch{rule: rule})
+		if rule.Metric == MetricDisk {
+			m.disks = append(m.disks, rule.diskPath())
+		}
+	}
+	return m
+}
+
+// Start checks the rules in the background until ctx is done or Stop is
+// called.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil || len(m.watches) == 0 {
+		return nil
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.check(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel = nil
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// check measures the system and notifies of the rules that started or
+// ceased to be broken.
+func (m *Monitor) check(ctx context.Context, now time.Time) {
+	snap, err := m.collect(ctx, Options{Disks: m.disks})
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.WarnCF("sysinfo", "Can't check the system for alerts", map[string]any{"error": err.Error()})
+		}
+		return
+	}
+	for _, w := range m.watches {
+		value, ok := w.rule.value(snap)
+		if !ok {
+			continue
+		}
+		if !w.rule.broken(value) {
+			w.since = time.Time{}
+			if w.firing {
+				w.firing = false
+				m.notify(Alert{Rule: w.rule, Value: value, Resolved: true})
+			}
+			continue
+		}
+		if w.since.IsZero() {
+			w.since = now
+		}
+		if !w.firing && now.Sub(w.since) >= w.rule.For {
+			w.firing = true
+			logger.InfoCF("sysinfo", "Alert", map[string]any{"rule": w.rule.Name(), "value": value})
+			m.notify(Alert{Rule: w.rule, Value: value})
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package sysinfo
+
+import "syscall"
+
+func statDisk(path string) (Disk, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return Disk{}, err
+	}
+	size := uint64(st.Bsize)
+	return Disk{Path: path, Total: uint64(st.Blocks) * size, Free: uint64(st.Bavail) * size}, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package sysinfo
+
+import "errors"
+
+func statDisk(path string) (Disk, error) {
+	return Disk{}, errors.New("disk space is only read on Linux")
+}
@@ -0,0 +1,385 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package sysinfo reads the state of the system picoclaw runs on (CPU,
+// memory, temperatures, disks, network and processes) from /proc and /sys,
+// and watches it for alert rules.
+package sysinfo
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProcRoot and SysRoot are where procfs and sysfs are mounted, replaced in
+// tests.
+var (
+	ProcRoot = "/proc"
+	SysRoot  = "/sys"
+)
+
+// defaultSample is how long CPU and network use are measured over.
+const defaultSample = 500 * time.Millisecond
+
+// Snapshot is the state of the system at one time.
+type Snapshot struct {
+	CPUPercent   float64 // Busy share of all the CPUs during the sample
+	CPUs         int
+	Load         [3]float64 // 1, 5 and 15-minute load averages
+	Uptime       time.Duration
+	Memory       Memory
+	Temperatures []Temperature
+	Disks        []Disk
+	Network      []Interface
+	Processes    []Process // The busiest first
+}
+
+// Memory is the use of RAM and swap, in bytes.
+type Memory struct {
+	Total     uint64
+	Available uint64
+	SwapTotal uint64
+	SwapFree  uint64
+}
+
+// UsedPercent is the share of RAM not available to new programs.
+func (m Memory) UsedPercent() float64 {
+	return percent(m.Total-m.Available, m.Total)
+}
+
+// Temperature is the reading of a thermal zone, such as "cpu-thermal".
+type Temperature struct {
+	Zone    string
+	Celsius float64
+}
+
+// Disk is the space of the filesystem holding Path, in bytes.
+type Disk struct {
+	Path  string
+	Total uint64
+	Free  uint64 // Available to unprivileged users
+}
+
+// UsedPercent is the share of the filesystem not free.
+func (d Disk) UsedPercent() float64 {
+	return percent(d.Total-d.Free, d.Total)
+}
+
+// Interface is a network interface's traffic: totals since boot, in bytes,
+// and rates during the sample, in bytes per second.
+type Interface struct {
+	Name    string
+	RxBytes uint64
+	TxBytes uint64
+	RxRate  float64
+	TxRate  float64
+}
+
+// Process is a running program.
+type Process struct {
+	PID        int
+	Name       string
+	CPUPercent float64 // Of one CPU, as top shows it
+	RSS        uint64  // Resident memory, in bytes
+}
+
+// Options say what to collect.
+type Options struct {
+	Disks        []string      // Filesystems to report; default /
+	TopProcesses int           // The number of busiest processes to report; none when 0
+	Sample       time.Duration // Default 500ms
+}
+
+// Collect measures the system over opts.Sample. Only the CPU is required;
+// sections the system can't report, such as temperatures on boards
+// without thermal zones, are left empty.
+func Collect(ctx context.Context, opts Options) (*Snapshot, error) {
+	if opts.Sample <= 0 {
+		opts.Sample = defaultSample
+	}
+	if len(opts.Disks) == 0 {
+		opts.Disks = []string{"/"}
+	}
+
+	cpu1, cpus, err := readCPU()
+	if err != nil {
+		return nil, err
+	}
+	net1 := readNetwork()
+	var procs1 map[int]Process
+	var ticks1 map[int]uint64
+	if opts.TopProcesses > 0 {
+		procs1, ticks1 = readProcesses()
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(opts.Sample):
+	}
+	cpu2, _, err := readCPU()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{CPUs: cpus}
+	total, idle := cpu2.total-cpu1.total, cpu2.idle-cpu1.idle
+	snap.CPUPercent = percent(total-idle, total)
+
+	seconds := opts.Sample.Seconds()
+	for _, iface := range readNetwork() {
+		for _, before := range net1 {
+			if before.Name == iface.Name {
+				iface.RxRate = float64(iface.RxBytes-before.RxBytes) / seconds
+				iface.TxRate = float64(iface.TxBytes-before.TxBytes) / seconds
+			}
+		}
+		snap.Network = append(snap.Network, iface)
+	}
+
+	if opts.TopProcesses > 0 {
+		procs2, ticks2 := readProcesses()
+		// Jiffies of one CPU during the sample.
+		perCPU := float64(total) / float64(max(cpus, 1))
+		for pid, p := range procs2 {
+			if _, ok := procs1[pid]; ok && perCPU > 0 {
+				p.CPUPercent = float64(ticks2[pid]-ticks1[pid]) / perCPU * 100
+			}
+			snap.Processes = append(snap.Processes, p)
+		}
+		slices.SortFunc(snap.Processes, func(a, b Process) int {
+			if c := cmp.Compare(b.CPUPercent, a.CPUPercent); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.RSS, a.RSS)
+		})
+		if len(snap.Processes) > opts.TopProcesses {
+			snap.Processes = snap.Processes[:opts.TopProcesses]
+		}
+	}
+
+	snap.Load = readLoad()
+	snap.Uptime = readUptime()
+	snap.Memory = readMemory()
+	snap.Temperatures = readTemperatures()
+	for _, path := range opts.Disks {
+		if disk, err := statDisk(path); err == nil {
+			snap.Disks = append(snap.Disks, disk)
+		}
+	}
+	return snap, nil
+}
+
+type cpuTimes struct {
+	total, idle uint64
+}
+
+// readCPU reads the jiffies all the CPUs have spent, and idle, since boot,
+// and the number of CPUs.
+func readCPU() (cpuTimes, int, error) {
+	f, err := os.Open(filepath.Join(ProcRoot, "stat"))
+	if err != nil {
+		return cpuTimes{}, 0, fmt.Errorf("can't read the CPU's use (system information needs Linux): %w", err)
+	}
+	defer f.Close()
+
+	var times cpuTimes
+	cpus := 0
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpus++
+			continue
+		}
+		found = true
+		// user nice system idle iowait irq softirq steal; guests are
+		// already counted in user and nice.
+		for i, field := range fields[1:min(len(fields), 9)] {
+			n, _ := strconv.ParseUint(field, 10, 64)
+			times.total += n
+			if i == 3 || i == 4 {
+				times.idle += n
+			}
+		}
+	}
+	if !found {
+		return cpuTimes{}, 0, errors.New("no CPU line in /proc/stat")
+	}
+	return times, cpus, nil
+}
+
+func readLoad() [3]float64 {
+	var load [3]float64
+	data, err := os.ReadFile(filepath.Join(ProcRoot, "loadavg"))
+	if err != nil {
+		return load
+	}
+	for i, field := range strings.Fields(string(data)) {
+		if i == 3 {
+			break
+		}
+		load[i], _ = strconv.ParseFloat(field, 64)
+	}
+	return load
+}
+
+func readUptime() time.Duration {
+	data, err := os.ReadFile(filepath.Join(ProcRoot, "uptime"))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	seconds, _ := strconv.ParseFloat(fields[0], 64)
+	return time.Duration(seconds) * time.Second
+}
+
+func readMemory() Memory {
+	var mem Memory
+	f, err := os.Open(filepath.Join(ProcRoot, "meminfo"))
+	if err != nil {
+		return mem
+	}
+	defer f.Close()
+
+	free, buffers, cached := uint64(0), uint64(0), uint64(0)
+	hasAvailable := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		kb, _ := strconv.ParseUint(fields[0], 10, 64)
+		switch key {
+		case "MemTotal":
+			mem.Total = kb << 10
+		case "MemAvailable":
+			mem.Available, hasAvailable = kb<<10, true
+		case "MemFree":
+			free = kb << 10
+		case "Buffers":
+			buffers = kb << 10
+		case "Cached":
+			cached = kb << 10
+		case "SwapTotal":
+			mem.SwapTotal = kb << 10
+		case "SwapFree":
+			mem.SwapFree = kb << 10
+		}
+	}
+	// Kernels before 3.14 don't report MemAvailable.
+	if !hasAvailable {
+		mem.Available = min(free+buffers+cached, mem.Total)
+	}
+	return mem
+}
+
+func readTemperatures() []Temperature {
+	zones, _ := filepath.Glob(filepath.Join(SysRoot, "class", "thermal", "thermal_zone*"))
+	var temps []Temperature
+	for _, zone := range zones {
+		data, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		milli, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(zone)
+		if data, err := os.ReadFile(filepath.Join(zone, "type")); err == nil && strings.TrimSpace(string(data)) != "" {
+			name = strings.TrimSpace(string(data))
+		}
+		temps = append(temps, Temperature{Zone: name, Celsius: float64(milli) / 1000})
+	}
+	return temps
+}
+
+// readNetwork reads the traffic of the interfaces but loopback.
+func readNetwork() []Interface {
+	f, err := os.Open(filepath.Join(ProcRoot, "net", "dev"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var ifaces []Interface
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		name = strings.TrimSpace(name)
+		fields := strings.Fields(counters)
+		if !ok || name == "lo" || len(fields) < 9 {
+			continue
+		}
+		iface := Interface{Name: name}
+		iface.RxBytes, _ = strconv.ParseUint(fields[0], 10, 64)
+		iface.TxBytes, _ = strconv.ParseUint(fields[8], 10, 64)
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces
+}
+
+// readProcesses reads the running processes, and the jiffies each has
+// spent on the CPUs.
+func readProcesses() (map[int]Process, map[int]uint64) {
+	entries, _ := os.ReadDir(ProcRoot)
+	procs := make(map[int]Process, len(entries))
+	ticks := make(map[int]uint64, len(entries))
+	pageSize := uint64(os.Getpagesize())
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(ProcRoot, entry.Name(), "stat"))
+		if err != nil {
+			continue // The process exited
+		}
+		// pid (comm) state ...: comm may hold spaces and parentheses.
+		stat := string(data)
+		open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+		if open < 0 || end < open {
+			continue
+		}
+		fields := strings.Fields(stat[end+1:])
+		if len(fields) < 22 {
+			continue
+		}
+		utime, _ := strconv.ParseUint(fields[11], 10, 64)
+		stime, _ := strconv.ParseUint(fields[12], 10, 64)
+		rss, _ := strconv.ParseUint(fields[21], 10, 64)
+		procs[pid] = Process{PID: pid, Name: stat[open+1 : end], RSS: rss * pageSize}
+		ticks[pid] = utime + stime
+	}
+	return procs, ticks
+}
+
+func percent(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
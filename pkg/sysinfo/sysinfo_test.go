@@ -0,0 +1,132 @@
+package sysinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fakeSystem(t *testing.T) {
+	t.Helper()
+	proc, sys := t.TempDir(), t.TempDir()
+	oldProc, oldSys := ProcRoot, SysRoot
+	ProcRoot, SysRoot = proc, sys
+	t.Cleanup(func() { ProcRoot, SysRoot = oldProc, oldSys })
+
+	writeFile(t, filepath.Join(proc, "stat"), "cpu  300 0 100 600 0 0 0 0 0 0\n"+
+		"cpu0 150 0 50 300 0 0 0 0 0 0\ncpu1 150 0 50 300 0 0 0 0 0 0\nintr 1 2 3\n")
+	writeFile(t, filepath.Join(proc, "loadavg"), "0.52 0.40 0.31 1/123 4567\n")
+	writeFile(t, filepath.Join(proc, "uptime"), "3725.50 7000.00\n")
+	writeFile(t, filepath.Join(proc, "meminfo"), "MemTotal:        1000 kB\nMemFree:          100 kB\n"+
+		"MemAvailable:     250 kB\nSwapTotal:        512 kB\nSwapFree:         512 kB\n")
+	writeFile(t, filepath.Join(proc, "net", "dev"), "Inter-|   Receive\n face |bytes packets\n"+
+		"    lo: 999 1 0 0 0 0 0 0 999 1 0 0 0 0 0 0\n"+
+		"  eth0: 2048 10 0 0 0 0 0 0 1024 5 0 0 0 0 0 0\n")
+	writeFile(t, filepath.Join(proc, "42", "stat"),
+		"42 (my (odd) app) S 1 42 42 0 -1 4194560 100 0 0 0 70 30 0 0 20 0 1 0 100 1000 25 0 0\n")
+	writeFile(t, filepath.Join(sys, "class", "thermal", "thermal_zone0", "temp"), "52100\n")
+	writeFile(t, filepath.Join(sys, "class", "thermal", "thermal_zone0", "type"), "cpu-thermal\n")
+	writeFile(t, filepath.Join(sys, "class", "thermal", "thermal_zone1", "temp"), "48000\n")
+}
+
+func TestCollect(t *testing.T) {
+	fakeSystem(t)
+	snap, err := Collect(context.Background(), Options{TopProcesses: 5, Sample: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.CPUs != 2 || snap.Load != [3]float64{0.52, 0.40, 0.31} || snap.Uptime != 3725*time.Second {
+		t.Errorf("cpus, load, uptime = %d, %v, %v", snap.CPUs, snap.Load, snap.Uptime)
+	}
+	if snap.Memory.Total != 1000<<10 || snap.Memory.UsedPercent() != 75 || snap.Memory.SwapFree != 512<<10 {
+		t.Errorf("memory = %+v", snap.Memory)
+	}
+	if len(snap.Network) != 1 || snap.Network[0].Name != "eth0" || snap.Network[0].RxBytes != 2048 ||
+		snap.Network[0].TxBytes != 1024 {
+		t.Errorf("network = %+v", snap.Network)
+	}
+	if len(snap.Processes) != 1 || snap.Processes[0].Name != "my (odd) app" ||
+		snap.Processes[0].RSS != 25*uint64(os.Getpagesize()) {
+		t.Errorf("processes = %+v", snap.Processes)
+	}
+	want := []Temperature{{"cpu-thermal", 52.1}, {"thermal_zone1", 48}}
+	if len(snap.Temperatures) != 2 || snap.Temperatures[0] != want[0] || snap.Temperatures[1] != want[1] {
+		t.Errorf("temperatures = %+v, want %+v", snap.Temperatures, want)
+	}
+}
+
+func TestReadCPU(t *testing.T) {
+	fakeSystem(t)
+	times, cpus, err := readCPU()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if times.total != 1000 || times.idle != 600 || cpus != 2 {
+		t.Errorf("readCPU() = %+v, %d", times, cpus)
+	}
+
+	ProcRoot = t.TempDir()
+	if _, err := Collect(context.Background(), Options{}); err == nil {
+		t.Error("Collect succeeded without /proc/stat")
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	temp := 70.0
+	var alerts []Alert
+	m := NewMonitor([]Rule{
+		{Metric: MetricTemperature, Above: 80, For: 5 * time.Minute},
+		{Metric: MetricDisk, Target: "/data", Above: 90},
+	}, time.Minute, func(a Alert) { alerts = append(alerts, a) })
+	m.collect = func(_ context.Context, opts Options) (*Snapshot, error) {
+		if len(opts.Disks) != 1 || opts.Disks[0] != "/data" {
+			t.Errorf("disks = %v, want [/data]", opts.Disks)
+		}
+		return &Snapshot{
+			Temperatures: []Temperature{{"cpu-thermal", temp}, {"gpu-thermal", temp - 5}},
+			Disks:        []Disk{{Path: "/data", Total: 100, Free: 20}},
+		}, nil
+	}
+
+	start := time.Now()
+	m.check(context.Background(), start)
+	if len(alerts) != 0 {
+		t.Fatalf("alerts = %+v, want none", alerts)
+	}
+
+	temp = 85
+	m.check(context.Background(), start.Add(time.Minute))
+	m.check(context.Background(), start.Add(4*time.Minute))
+	if len(alerts) != 0 {
+		t.Fatalf("alert before 5 minutes: %+v", alerts)
+	}
+	m.check(context.Background(), start.Add(6*time.Minute))
+	m.check(context.Background(), start.Add(7*time.Minute))
+	if len(alerts) != 1 || alerts[0].Resolved || alerts[0].Value != 85 {
+		t.Fatalf("alerts = %+v, want one firing", alerts)
+	}
+	if msg := alerts[0].Message(); msg != "⚠️ Temperature is 85.0°C, above 80.0°C for 5m" {
+		t.Errorf("message = %q", msg)
+	}
+
+	temp = 60
+	m.check(context.Background(), start.Add(8*time.Minute))
+	if len(alerts) != 2 || !alerts[1].Resolved {
+		t.Fatalf("alerts = %+v, want resolved", alerts)
+	}
+	if msg := alerts[1].Message(); msg != "✅ Temperature is back to 60.0°C (alert: above 80.0°C for 5m)" {
+		t.Errorf("message = %q", msg)
+	}
+}
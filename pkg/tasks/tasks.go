@@ -0,0 +1,470 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package tasks keeps the user's to-do list: tasks with priorities, due
+// times and repeating rules, stored in a JSON file.
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+// Priorities, from the most urgent.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// keepDone is how long completed tasks are kept.
+const keepDone = 90 * 24 * time.Hour
+
+// ErrNotFound is returned for a task that doesn't exist.
+var ErrNotFound = errors.New("no such task")
+
+// Task is an item of the to-do list.
+type Task struct {
+	ID       string             `json:"id"`
+	Title    string             `json:"title"`
+	Notes    string             `json:"notes,omitempty"`
+	Priority string             `json:"priority"`
+	Due      time.Time          `json:"due,omitzero"`
+	TZ       string             `json:"tz,omitempty"`     // Of Due and Repeat, as the user gave them
+	Repeat   *cron.CronSchedule `json:"repeat,omitempty"` // When set, completing the task moves Due to the next time
+	Snoozed  time.Time          `json:"snoozed,omitzero"` // Not overdue before then
+	Created  time.Time          `json:"created"`
+	Done     time.Time          `json:"done,omitzero"`      // Completed; for repeating tasks, the last time
+	DoneRuns int                `json:"done_runs,omitzero"` // Times a repeating task was completed
+	Nagged   time.Time          `json:"nagged,omitzero"`    // Last reminded of being overdue
+}
+
+// IsOpen reports whether the task is still to do.
+func (t *Task) IsOpen() bool {
+	return t.Repeat != nil || t.Done.IsZero()
+}
+
+// IsOverdue reports whether the task is open, past due and not snoozed at
+// now.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.IsOpen() && !t.Due.IsZero() && !t.Due.After(now) && !t.Snoozed.After(now)
+}
+
+// Describe returns the task on one line: its ID, title, priority, due time
+// and rule, as of now.
+func (t *Task) Describe(now time.Time) string {
+	loc := now.Location()
+	if l, err := time.LoadLocation(t.TZ); err == nil && t.TZ != "" {
+		loc = l
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s", t.ID, t.Title)
+	if t.Priority != PriorityNormal {
+		fmt.Fprintf(&sb, " (%s priority)", t.Priority)
+	}
+	if t.IsOpen() && !t.Due.IsZero() {
+		fmt.Fprintf(&sb, ", due %s", t.Due.In(loc).Format("Mon 2006-01-02 15:04"))
+		if t.IsOverdue(now) {
+			fmt.Fprintf(&sb, ", overdue by %s", roughDuration(now.Sub(t.Due)))
+		}
+	}
+	if t.Repeat != nil {
+		fmt.Fprintf(&sb, ", repeats %s", cron.DescribeSchedule(*t.Repeat))
+		switch {
+		case t.DoneRuns == 1:
+			sb.WriteString(", done once")
+		case t.DoneRuns > 1:
+			fmt.Fprintf(&sb, ", done %d times", t.DoneRuns)
+		}
+	}
+	if t.IsOpen() && t.Snoozed.After(now) {
+		fmt.Fprintf(&sb, ", snoozed until %s", t.Snoozed.In(loc).Format("Mon 2006-01-02 15:04"))
+	}
+	if !t.IsOpen() {
+		fmt.Fprintf(&sb, ", done %s", t.Done.In(loc).Format("2006-01-02 15:04"))
+	}
+	if t.Notes != "" {
+		sb.WriteString(": " + strings.Join(strings.Fields(t.Notes), " "))
+	}
+	return sb.String()
+}
+
+func roughDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
+
+// Filters of List.
+const (
+	FilterOpen    = "open"
+	FilterOverdue = "overdue"
+	FilterDone    = "done"
+	FilterAll     = "all"
+)
+
+type file struct {
+	NextID int    `json:"next_id"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// Store is the to-do list in a JSON file. The file is read on each call,
+// so stores of the same file, such as the agent's tool and the heartbeat's,
+// see each other's changes.
+type Store struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// NewStore returns the store of the tasks in the file at path, which is
+// created when the first task is added.
+func NewStore(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// Add adds a task, giving it an ID. A repeating task without a due time is
+// first due at the rule's next time.
+func (s *Store) Add(task Task) (*Task, error) {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return nil, errors.New("a task needs a title")
+	}
+	var err error
+	if task.Priority, err = checkPriority(task.Priority); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if task.Repeat != nil {
+		if err := cron.ValidateSchedule(*task.Repeat); err != nil {
+			return nil, err
+		}
+		if task.Due.IsZero() {
+			next, ok := cron.NextRun(*task.Repeat, now)
+			if !ok {
+				return nil, errors.New("the repeating rule never runs")
+			}
+			task.Due = next
+		}
+	}
+
+	var added Task
+	err = s.update(func(f *file) error {
+		f.NextID++
+		task.ID = strconv.Itoa(f.NextID)
+		task.Created = now.Truncate(time.Second)
+		task.Done, task.DoneRuns, task.Nagged = time.Time{}, 0, time.Time{}
+		f.Tasks = append(f.Tasks, task)
+		added = task
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+// Complete marks a task done. A repeating task stays open, due at the next
+// time of its rule after now.
+func (s *Store) Complete(id string) (*Task, error) {
+	now := s.now()
+	return s.change(id, func(t *Task) error {
+		if !t.IsOpen() {
+			return fmt.Errorf("task %s is already done", id)
+		}
+		t.Done = now.Truncate(time.Second)
+		t.Snoozed, t.Nagged = time.Time{}, time.Time{}
+		if t.Repeat != nil {
+			t.DoneRuns++
+			t.Due = nextDue(*t.Repeat, t.Due, now)
+		}
+		return nil
+	})
+}
+
+// nextDue returns the first time of rule after both due and now, so a task
+// done late isn't due again at once, or the zero time if there is none.
+func nextDue(rule cron.CronSchedule, due, now time.Time) time.Time {
+	if rule.Kind == "every" && !due.IsZero() {
+		// Keep the task's time of day: step from the due time.
+		for next, ok := cron.NextRun(rule, due); ok; next, ok = cron.NextRun(rule, next) {
+			if next.After(now) {
+				return next
+			}
+		}
+		return time.Time{}
+	}
+	next, _ := cron.NextRun(rule, now)
+	return next
+}
+
+// Snooze keeps a task from being overdue until until.
+func (s *Store) Snooze(id string, until time.Time) (*Task, error) {
+	return s.change(id, func(t *Task) error {
+		if !t.IsOpen() {
+			return fmt.Errorf("task %s is done", id)
+		}
+		t.Snoozed = until
+		return nil
+	})
+}
+
+// Update applies edit to a task, then checks it. A repeating task left
+// without a due time is due at the rule's next time.
+func (s *Store) Update(id string, edit func(t *Task)) (*Task, error) {
+	return s.change(id, func(t *Task) error {
+		edit(t)
+		t.Title = strings.TrimSpace(t.Title)
+		if t.Title == "" {
+			return errors.New("a task needs a title")
+		}
+		var err error
+		if t.Priority, err = checkPriority(t.Priority); err != nil {
+			return err
+		}
+		if t.Repeat == nil {
+			return nil
+		}
+		if err := cron.ValidateSchedule(*t.Repeat); err != nil {
+			return err
+		}
+		if t.Due.IsZero() {
+			t.Due = nextDue(*t.Repeat, t.Due, s.now())
+		}
+		return nil
+	})
+}
+
+// Delete removes a task.
+func (s *Store) Delete(id string) error {
+	return s.update(func(f *file) error {
+		for i := range f.Tasks {
+			if f.Tasks[i].ID == id {
+				f.Tasks = append(f.Tasks[:i], f.Tasks[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	})
+}
+
+// Get returns a task.
+func (s *Store) Get(id string) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range f.Tasks {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// List returns the tasks matching filter. Open tasks come by priority,
+// then by due time; done tasks the last done first.
+func (s *Store) List(filter string) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var out []Task
+	for _, t := range f.Tasks {
+		var ok bool
+		switch filter {
+		case FilterOpen, "":
+			ok = t.IsOpen()
+		case FilterOverdue:
+			ok = t.IsOverdue(now)
+		case FilterDone:
+			ok = !t.Done.IsZero()
+		case FilterAll:
+			ok = true
+		default:
+			return nil, fmt.Errorf("unknown filter %q", filter)
+		}
+		if ok {
+			out = append(out, t)
+		}
+	}
+	if filter == FilterDone {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Done.After(out[j].Done) })
+	} else {
+		sortOpen(out)
+	}
+	return out, nil
+}
+
+// Nag returns the overdue tasks not reminded of in the last every, and
+// records that they are being reminded of now.
+func (s *Store) Nag(every time.Duration) ([]Task, error) {
+	now := s.now()
+	var due []Task
+	err := s.update(func(f *file) error {
+		for i := range f.Tasks {
+			t := &f.Tasks[i]
+			if t.IsOverdue(now) && now.Sub(t.Nagged) >= every {
+				t.Nagged = now.Truncate(time.Second)
+				due = append(due, *t)
+			}
+		}
+		if len(due) == 0 {
+			return errUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortOpen(due)
+	return due, nil
+}
+
+// NagPrompt returns a section for the heartbeat prompt listing the overdue
+// tasks not reminded of in the last every (see Nag), or "" when there are
+// none.
+func (s *Store) NagPrompt(every time.Duration) (string, error) {
+	due, err := s.Nag(every)
+	if err != nil || len(due) == 0 {
+		return "", err
+	}
+	now := s.now()
+	var sb strings.Builder
+	sb.WriteString("## Overdue Tasks\n\n")
+	sb.WriteString("These tasks of the user's to-do list are overdue. Remind the user of them in a short message, " +
+		"the most important first; they can complete or snooze them with the tasks tool.\n\n")
+	for _, t := range due {
+		sb.WriteString("- " + t.Describe(now) + "\n")
+	}
+	return sb.String(), nil
+}
+
+// sortOpen orders tasks by priority, then those due first, then those
+// without a due time.
+func sortOpen(tasks []Task) {
+	rank := map[string]int{PriorityHigh: 0, PriorityNormal: 1, PriorityLow: 2}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if rank[a.Priority] != rank[b.Priority] {
+			return rank[a.Priority] < rank[b.Priority]
+		}
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return !a.Due.IsZero()
+		}
+		return a.Due.Before(b.Due)
+	})
+}
+
+func checkPriority(p string) (string, error) {
+	switch p = strings.ToLower(strings.TrimSpace(p)); p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityHigh, PriorityNormal, PriorityLow:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown priority %q; use high, normal or low", p)
+}
+
+// errUnchanged makes update skip saving.
+var errUnchanged = errors.New("unchanged")
+
+// change applies fn to the task id and saves it.
+func (s *Store) change(id string, fn func(t *Task) error) (*Task, error) {
+	var changed Task
+	err := s.update(func(f *file) error {
+		for i := range f.Tasks {
+			if f.Tasks[i].ID == id {
+				t := f.Tasks[i]
+				if err := fn(&t); err != nil {
+					return err
+				}
+				f.Tasks[i], changed = t, t
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &changed, nil
+}
+
+// update loads the tasks, applies fn and saves them, dropping the tasks
+// done long ago.
+func (s *Store) update(fn func(f *file) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		if errors.Is(err, errUnchanged) {
+			return nil
+		}
+		return err
+	}
+	cutoff := s.now().Add(-keepDone)
+	kept := f.Tasks[:0]
+	for _, t := range f.Tasks {
+		if t.IsOpen() || t.Done.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	f.Tasks = kept
+	return s.save(f)
+}
+
+func (s *Store) load() (*file, error) {
+	f := &file{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return f, nil
+}
+
+func (s *Store) save(f *file) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,179 @@
+package tasks
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	s := NewStore(filepath.Join(t.TempDir(), "tasks.json"))
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func ids(tasks []Task) string {
+	var out []string
+	for _, t := range tasks {
+		out = append(out, t.ID)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestStore_AddListComplete(t *testing.T) {
+	s, now := newTestStore(t)
+	at := func(h int) time.Time { return time.Date(2026, 10, 15, h, 0, 0, 0, time.UTC) }
+
+	for _, task := range []Task{
+		{Title: "Water plants", Due: at(18)},
+		{Title: "Pay rent", Priority: "HIGH", Due: at(12)},
+		{Title: "Read a book", Priority: PriorityLow},
+		{Title: "Call mum", Due: at(9)},
+	} {
+		if _, err := s.Add(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Add(Task{Title: "  "}); err == nil {
+		t.Error("Add() without a title succeeded")
+	}
+	if _, err := s.Add(Task{Title: "x", Priority: "urgent"}); err == nil {
+		t.Error("Add() with an unknown priority succeeded")
+	}
+
+	open, err := s.List(FilterOpen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(open); got != "2 4 1 3" {
+		t.Errorf("open tasks = %s, want by priority then due time", got)
+	}
+	overdue, _ := s.List(FilterOverdue)
+	if ids(overdue) != "4" {
+		t.Errorf("overdue tasks = %s, want 4", ids(overdue))
+	}
+
+	if _, err := s.Complete("4"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Complete("4"); err == nil {
+		t.Error("completing a task twice succeeded")
+	}
+	done, _ := s.List(FilterDone)
+	if ids(done) != "4" || !strings.HasSuffix(done[0].Describe(*now), "done 2026-10-15 10:00") {
+		t.Errorf("done tasks = %+v", done)
+	}
+
+	if err := s.Delete("3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete = %v", err)
+	}
+
+	// Done tasks are dropped after a while.
+	*now = now.Add(keepDone + time.Hour)
+	if _, err := s.Add(Task{Title: "Later"}); err != nil {
+		t.Fatal(err)
+	}
+	all, _ := s.List(FilterAll)
+	if ids(all) != "2 1 5" {
+		t.Errorf("all tasks = %s", ids(all))
+	}
+}
+
+func TestStore_Repeat(t *testing.T) {
+	s, now := newTestStore(t)
+
+	daily, err := cron.ParseSchedule("every day at 8:00", *now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.Add(Task{Title: "Take vitamins", Repeat: &daily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC); !task.Due.Equal(want) {
+		t.Errorf("first due = %v, want %v", task.Due, want)
+	}
+
+	// Done late, two days after: next due is the next 8:00 to come.
+	*now = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	task, err = s.Complete(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC); !task.Due.Equal(want) || !task.IsOpen() ||
+		task.DoneRuns != 1 {
+		t.Errorf("after Complete, task = %+v, want due %v", task, want)
+	}
+
+	// An interval keeps the time of day of the due time.
+	every := int64((72 * time.Hour).Milliseconds())
+	task, err = s.Add(Task{
+		Title: "Change the filter", Due: time.Date(2026, 10, 17, 7, 30, 0, 0, time.UTC),
+		Repeat: &cron.CronSchedule{Kind: "every", EveryMS: &every},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, _ = s.Complete(task.ID)
+	if want := time.Date(2026, 10, 20, 7, 30, 0, 0, time.UTC); !task.Due.Equal(want) {
+		t.Errorf("interval task due %v, want %v", task.Due, want)
+	}
+	if !strings.Contains(task.Describe(*now), ", repeats every 72h, done once") {
+		t.Errorf("Describe() = %q", task.Describe(*now))
+	}
+}
+
+func TestStore_SnoozeAndNag(t *testing.T) {
+	s, now := newTestStore(t)
+	for _, task := range []Task{
+		{Title: "Pay rent", Priority: PriorityHigh, Due: now.Add(-50 * time.Hour)},
+		{Title: "Book dentist", Due: now.Add(-time.Hour)},
+		{Title: "Renew passport", Due: now.Add(time.Hour)},
+	} {
+		if _, err := s.Add(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prompt, err := s.NagPrompt(4 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Overdue Tasks",
+		"- [1] Pay rent (high priority), due Tue 2026-10-13 08:00, overdue by 2 days\n- [2] Book dentist",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("NagPrompt() = %q, lacks %q", prompt, want)
+		}
+	}
+	if prompt, _ := s.NagPrompt(4 * time.Hour); prompt != "" {
+		t.Errorf("NagPrompt() right after = %q, want nothing", prompt)
+	}
+
+	if _, err := s.Snooze("1", now.Add(24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(5 * time.Hour)
+	nagged, _ := s.Nag(4 * time.Hour)
+	if ids(nagged) != "2 3" {
+		t.Errorf("nagged = %s, want the snoozed task left out", ids(nagged))
+	}
+
+	task, err := s.Update("2", func(t *Task) { t.Priority = "low"; t.Due = time.Time{} })
+	if err != nil || task.IsOverdue(*now) {
+		t.Errorf("Update() = %+v, %v", task, err)
+	}
+	if _, err := s.Update("2", func(t *Task) { t.Title = "" }); err == nil {
+		t.Error("Update() removing the title succeeded")
+	}
+}
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Approval policy actions, see config.ApprovalRule.
+const (
+	ApprovalAllow = "allow"
+	ApprovalAsk   = "ask"
+	ApprovalDeny  = "deny"
+)
+
+// defaultApprovalRules apply when approval is enabled without rules.
+var defaultApprovalRules = []config.ApprovalRule{
+	{Tool: "exec", Action: ApprovalAsk},
+	{Tool: "git", Arg: "action", Pattern: "^(push|open_pr)$", Action: ApprovalAsk},
+}
+
+// publishRule matches the calls that publish work beyond the machine: git
+// pushes and pull requests. They're put to the user whatever the rules say,
+// unless a rule denies them.
+var publishRule = approvalRule{
+	tool:    "git",
+	arg:     "action",
+	pattern: regexp.MustCompile("^(push|open_pr)$"),
+	action:  ApprovalAsk,
+}
+
+// MustAsk reports whether the call to tool name with args needs the user's
+// approval however the policy decides.
+func MustAsk(name string, args map[string]any) bool {
+	return publishRule.matches(ApprovalScope{}, name, args)
+}
+
+// pathArgs are the arguments Outside rules check when they name none.
+var pathArgs = []string{"path", "working_dir"}
+
+type approvalRule struct {
+	tool     string
+	pattern  *regexp.Regexp // nil matches any arguments
+	arg      string         // Argument pattern and outside look at; empty for any
+	outside  []string       // Cleaned absolute directories
+	users    []string
+	channels []string
+	agents   []string
+	action   string
+}
+
+// ApprovalScope is who a tool call is made for, and where.
+type ApprovalScope struct {
+	User      string
+	Channel   string
+	Agent     string
+	Workspace string // Relative paths are taken in it
+}
+
+// ApprovalPolicy decides which tool calls run, which need the user's
+// approval and which are refused.
+type ApprovalPolicy struct {
+	rules []approvalRule
+}
+
+// NewApprovalPolicy compiles the rules of cfg.
+func NewApprovalPolicy(cfg config.ApprovalConfig) (*ApprovalPolicy, error) {
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = defaultApprovalRules
+	}
+	p := &ApprovalPolicy{}
+	for i, r := range rules {
+		switch r.Action {
+		case ApprovalAllow, ApprovalAsk, ApprovalDeny:
+		default:
+			return nil, fmt.Errorf("approval rule %d: unknown action %q", i, r.Action)
+		}
+		rule := approvalRule{
+			tool:     r.Tool,
+			arg:      r.Arg,
+			users:    r.Users,
+			channels: r.Channels,
+			agents:   r.Agents,
+			action:   r.Action,
+		}
+		for _, dir := range r.Outside {
+			dir = expandHomeDir(dir)
+			if !filepath.IsAbs(dir) {
+				return nil, fmt.Errorf("approval rule %d: %q in outside isn't an absolute path", i, dir)
+			}
+			rule.outside = append(rule.outside, filepath.Clean(dir))
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("approval rule %d: %w", i, err)
+			}
+			rule.pattern = re
+		}
+		p.rules = append(p.rules, rule)
+	}
+	return p, nil
+}
+
+// Decide returns the action for a call to tool name with args, made with
+// no scope.
+func (p *ApprovalPolicy) Decide(name string, args map[string]any) string {
+	action, _ := p.DecideFor(ApprovalScope{}, name, args)
+	return action
+}
+
+// DecideFor returns the action for a call to tool name with args in scope:
+// that of the first matching rule, whose index is returned too, or
+// ApprovalAllow and -1 when none matches.
+func (p *ApprovalPolicy) DecideFor(scope ApprovalScope, name string, args map[string]any) (string, int) {
+	for i, r := range p.rules {
+		if r.matches(scope, name, args) {
+			return r.action, i
+		}
+	}
+	return ApprovalAllow, -1
+}
+
+func (r *approvalRule) matches(scope ApprovalScope, name string, args map[string]any) bool {
+	if r.tool != "*" && r.tool != name {
+		return false
+	}
+	if (len(r.users) > 0 && !slices.Contains(r.users, scope.User)) ||
+		(len(r.channels) > 0 && !slices.Contains(r.channels, scope.Channel)) ||
+		(len(r.agents) > 0 && !slices.Contains(r.agents, scope.Agent)) {
+		return false
+	}
+	if r.pattern != nil {
+		var v any = args
+		if r.arg != "" {
+			v = args[r.arg]
+		}
+		if !matchesStringArg(r.pattern, v) {
+			return false
+		}
+	}
+	return len(r.outside) == 0 || r.pathOutside(scope.Workspace, args)
+}
+
+// pathOutside reports whether the call's path argument is outside every
+// directory of the rule.
+func (r *approvalRule) pathOutside(workspace string, args map[string]any) bool {
+	path := workspace
+	names := pathArgs
+	if r.arg != "" {
+		names = []string{r.arg}
+	}
+	for _, name := range names {
+		if p, ok := args[name].(string); ok && p != "" {
+			path = expandHomeDir(p)
+			break
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workspace, path)
+	}
+	path = filepath.Clean(path)
+	for _, dir := range r.outside {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandHomeDir expands a leading ~ in path to the home directory.
+func expandHomeDir(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// matchesStringArg reports whether re matches one of the string values in
+// args, including those nested in lists and objects.
+func matchesStringArg(re *regexp.Regexp, v any) bool {
+	switch v := v.(type) {
+	case string:
+		return re.MatchString(v)
+	case map[string]any:
+		for _, item := range v {
+			if matchesStringArg(re, item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if matchesStringArg(re, item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Approver is consulted before each tool call runs. It returns nil to let
+// the call run, or an error saying why it may not.
+type Approver func(ctx context.Context, name string, args map[string]any) error
+
+type approverKey struct{}
+
+// WithApprover returns a context whose tool calls, including those of
+// subagents it starts, are first put to approve.
+func WithApprover(ctx context.Context, approve Approver) context.Context {
+	return context.WithValue(ctx, approverKey{}, approve)
+}
+
+func approverFrom(ctx context.Context) Approver {
+	approve, _ := ctx.Value(approverKey{}).(Approver)
+	return approve
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestApprovalPolicy_Decide(t *testing.T) {
+	policy, err := NewApprovalPolicy(config.ApprovalConfig{Rules: []config.ApprovalRule{
+		{Tool: "exec", Pattern: `^(ls|cat)\b`, Action: ApprovalAllow},
+		{Tool: "exec", Pattern: `\brm\s+-rf\s+/`, Action: ApprovalDeny},
+		{Tool: "exec", Action: ApprovalAsk},
+		{Tool: "*", Pattern: `(?i)-X\s*POST`, Action: ApprovalAsk},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"exec", map[string]any{"command": "ls -la"}, ApprovalAllow},
+		{"exec", map[string]any{"command": "sudo rm -rf /"}, ApprovalDeny},
+		{"exec", map[string]any{"command": "make build"}, ApprovalAsk},
+		{"web_fetch", map[string]any{"url": "https://example.com"}, ApprovalAllow},
+		{"other", map[string]any{"flags": []any{"-x post"}}, ApprovalAsk},
+	}
+	for _, tt := range tests {
+		if got := policy.Decide(tt.name, tt.args); got != tt.want {
+			t.Errorf("Decide(%s, %v) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestApprovalPolicy_DefaultRules(t *testing.T) {
+	policy, err := NewApprovalPolicy(config.ApprovalConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Decide("exec", map[string]any{"command": "ls"}); got != ApprovalAsk {
+		t.Errorf("exec = %q, want ask", got)
+	}
+	if got := policy.Decide("git", map[string]any{"action": "push"}); got != ApprovalAsk {
+		t.Errorf("git push = %q, want ask", got)
+	}
+	if got := policy.Decide("git", map[string]any{"action": "commit", "message": "push it"}); got != ApprovalAllow {
+		t.Errorf("git commit = %q, want allow", got)
+	}
+	if got := policy.Decide("read_file", map[string]any{"path": "a"}); got != ApprovalAllow {
+		t.Errorf("read_file = %q, want allow", got)
+	}
+}
+
+func TestMustAsk(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want bool
+	}{
+		{"git", map[string]any{"action": "push"}, true},
+		{"git", map[string]any{"action": "open_pr", "title": "Fix"}, true},
+		{"git", map[string]any{"action": "commit", "message": "push it"}, false},
+		{"exec", map[string]any{"command": "git push"}, false},
+	}
+	for _, tt := range tests {
+		if got := MustAsk(tt.name, tt.args); got != tt.want {
+			t.Errorf("MustAsk(%s, %v) = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestApprovalPolicy_DecideFor(t *testing.T) {
+	policy, err := NewApprovalPolicy(config.ApprovalConfig{Rules: []config.ApprovalRule{
+		{Tool: "*", Users: []string{"guest"}, Action: ApprovalDeny},
+		{Tool: "exec", Outside: []string{"/home/me/projects"}, Action: ApprovalDeny},
+		{Tool: "exec", Channels: []string{"telegram"}, Agents: []string{"main"}, Action: ApprovalAsk},
+		{Tool: "write_file", Arg: "path", Pattern: `\.env$`, Action: ApprovalAsk},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inProjects := ApprovalScope{User: "me", Channel: "telegram", Agent: "main", Workspace: "/home/me/projects/ws"}
+
+	tests := []struct {
+		scope ApprovalScope
+		name  string
+		args  map[string]any
+		want  string
+		rule  int
+	}{
+		{ApprovalScope{User: "guest"}, "read_file", map[string]any{"path": "a"}, ApprovalDeny, 0},
+		{inProjects, "exec", map[string]any{"command": "make"}, ApprovalAsk, 2},
+		{inProjects, "exec", map[string]any{"command": "make", "working_dir": "../app"}, ApprovalAsk, 2},
+		{inProjects, "exec", map[string]any{"command": "ls", "working_dir": "/etc"}, ApprovalDeny, 1},
+		{inProjects, "exec", map[string]any{"command": "ls", "working_dir": "../../.."}, ApprovalDeny, 1},
+		{ApprovalScope{Workspace: "/home/me/projects-old"}, "exec", map[string]any{}, ApprovalDeny, 1},
+		{ApprovalScope{Channel: "cli", Workspace: "/home/me/projects"}, "exec", map[string]any{}, ApprovalAllow, -1},
+		{inProjects, "write_file", map[string]any{"path": "a.txt", "content": "x.env"}, ApprovalAllow, -1},
+		{inProjects, "write_file", map[string]any{"path": "app/.env"}, ApprovalAsk, 3},
+	}
+	for _, tt := range tests {
+		if got, rule := policy.DecideFor(tt.scope, tt.name, tt.args); got != tt.want || rule != tt.rule {
+			t.Errorf("DecideFor(%+v, %s, %v) = %q, %d, want %q, %d",
+				tt.scope, tt.name, tt.args, got, rule, tt.want, tt.rule)
+		}
+	}
+}
+
+func TestNewApprovalPolicy_Invalid(t *testing.T) {
+	for _, rule := range []config.ApprovalRule{
+		{Tool: "exec", Action: "maybe"},
+		{Tool: "exec", Pattern: "(", Action: ApprovalAsk},
+		{Tool: "exec", Outside: []string{"projects"}, Action: ApprovalDeny},
+	} {
+		if _, err := NewApprovalPolicy(config.ApprovalConfig{Rules: []config.ApprovalRule{rule}}); err == nil {
+			t.Errorf("NewApprovalPolicy(%+v) should fail", rule)
+		}
+	}
+}
+
+func TestToolRegistry_ExecuteWithContext_Approver(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("exec", "runs commands"))
+
+	var asked []string
+	ctx := WithApprover(context.Background(), func(_ context.Context, name string, _ map[string]any) error {
+		asked = append(asked, name)
+		if len(asked) > 1 {
+			return errors.New("the user denied it")
+		}
+		return nil
+	})
+
+	if result := r.ExecuteWithContext(ctx, "exec", nil, "", "", nil); result.IsError {
+		t.Errorf("approved call failed: %s", result.ForLLM)
+	}
+	result := r.ExecuteWithContext(ctx, "exec", nil, "", "", nil)
+	if !result.IsError || !strings.Contains(result.ForLLM, "the user denied it") {
+		t.Errorf("denied call = %+v", result)
+	}
+	if len(asked) != 2 {
+		t.Errorf("approver asked %d times", len(asked))
+	}
+}
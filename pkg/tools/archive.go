@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/archive"
+)
+
+const (
+	maxArchiveListed  = 200
+	maxArchiveSkipped = 10
+)
+
+// ArchiveToolOptions configures an ArchiveTool.
+type ArchiveToolOptions struct {
+	Workspace  string
+	Restrict   bool
+	AllowPaths []string // Outside the workspace, as for the file tools
+	Limits     archive.Limits
+}
+
+// ArchiveTool lists, extracts and creates zip and tar archives.
+type ArchiveTool struct {
+	opts ArchiveToolOptions
+}
+
+func NewArchiveTool(opts ArchiveToolOptions) *ArchiveTool {
+	return &ArchiveTool{opts: opts}
+}
+
+func (t *ArchiveTool) Name() string {
+	return "archive"
+}
+
+func (t *ArchiveTool) Description() string {
+	return "List, extract or create archives: zip, tar, tar.gz/tgz and single .gz files; tar.bz2 can be " +
+		"listed and extracted. 'extract' keeps executable bits and writes nothing outside 'dest' (default: a " +
+		"directory named after the archive, beside it). 'create' archives 'sources' into 'path', its " +
+		"extension choosing the format."
+}
+
+func (t *ArchiveTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "extract", "create"},
+				"description": "What to do",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The archive",
+			},
+			"dest": map[string]any{
+				"type":        "string",
+				"description": "extract: the directory to extract into",
+			},
+			"sources": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "create: the files and directories to archive",
+			},
+			"overwrite": map[string]any{
+				"type":        "boolean",
+				"description": "create: replace the archive if it exists",
+			},
+		},
+		"required": []string{"action", "path"},
+	}
+}
+
+func (t *ArchiveTool) resolve(path string) (string, error) {
+	return resolveAllowedPath(expandHomeDir(path), t.opts.Workspace, t.opts.Restrict, t.opts.AllowPaths)
+}
+
+func (t *ArchiveTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	p, _ := args["path"].(string)
+	if p == "" {
+		return ErrorResult("path is required")
+	}
+	path, err := t.resolve(p)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if _, err := archive.DetectFormat(path); err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	switch action {
+	case "list":
+		return t.list(path)
+	case "extract":
+		return t.extract(path, args)
+	case "create":
+		return t.create(path, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *ArchiveTool) list(path string) *ToolResult {
+	entries, err := archive.List(path)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't read %s: %v", path, err)).WithError(err)
+	}
+	var total int64
+	var sb strings.Builder
+	for i, e := range entries {
+		total += e.Size
+		if i == maxArchiveListed {
+			fmt.Fprintf(&sb, "\n... and %d more", len(entries)-maxArchiveListed)
+		}
+		if i >= maxArchiveListed {
+			continue
+		}
+		switch {
+		case e.Mode.IsDir():
+			fmt.Fprintf(&sb, "\n%s", e.Name)
+		case e.Link != "":
+			fmt.Fprintf(&sb, "\n%s -> %s", e.Name, e.Link)
+		default:
+			fmt.Fprintf(&sb, "\n%s (%s%s)", e.Name, formatBytes(uint64(e.Size)), executableMark(e.Mode))
+		}
+	}
+	return SilentResult(fmt.Sprintf("%s: %d entries, %s uncompressed:%s",
+		filepath.Base(path), len(entries), formatBytes(uint64(total)), sb.String()))
+}
+
+func executableMark(mode fs.FileMode) string {
+	if mode&0o111 != 0 {
+		return ", executable"
+	}
+	return ""
+}
+
+func (t *ArchiveTool) extract(path string, args map[string]any) *ToolResult {
+	destArg, _ := args["dest"].(string)
+	if destArg == "" {
+		destArg = archive.TrimExtension(path)
+	}
+	dest, err := t.resolve(destArg)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	result, err := archive.Extract(path, dest, t.opts.Limits)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Extracting %s into %s failed: %v", filepath.Base(path), dest, err)).
+			WithError(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Extracted %s into %s: %d files (%s), %d directories, %d links",
+		filepath.Base(path), dest, result.Files, formatBytes(uint64(result.Bytes)), result.Dirs, result.Links)
+	if top, err := os.ReadDir(dest); err == nil {
+		names := make([]string, 0, len(top))
+		for _, e := range top {
+			name := e.Name()
+			if e.IsDir() {
+				name += "/"
+			}
+			names = append(names, name)
+		}
+		if len(names) > 20 {
+			names = append(names[:20], "...")
+		}
+		sb.WriteString("\nTop level: " + strings.Join(names, " "))
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(&sb, "\nSkipped %d entries:", len(result.Skipped))
+		for i, s := range result.Skipped {
+			if i == maxArchiveSkipped {
+				sb.WriteString("\n- ...")
+				break
+			}
+			sb.WriteString("\n- " + s)
+		}
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *ArchiveTool) create(path string, args map[string]any) *ToolResult {
+	var sources []string
+	for _, s := range listArg(args["sources"]) {
+		src, err := t.resolve(s)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("%s: %v", s, err))
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 {
+		return ErrorResult("sources is required to create an archive")
+	}
+	if overwrite, _ := args["overwrite"].(bool); !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return ErrorResult(fmt.Sprintf("%s already exists; set overwrite to replace it", path))
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	result, err := archive.Create(path, sources)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Creating %s failed: %v", path, err)).WithError(err)
+	}
+	size := ""
+	if info, err := os.Stat(path); err == nil {
+		size = ", " + formatBytes(uint64(info.Size()))
+	}
+	return SilentResult(fmt.Sprintf("Created %s%s: %d files (%s uncompressed), %d directories, %d links",
+		path, size, result.Files, formatBytes(uint64(result.Bytes)), result.Dirs, result.Links))
+}
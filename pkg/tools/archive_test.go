@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveTool_CreateListExtract(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "app", "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "app", "bin", "run"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "app", "README"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewArchiveTool(ArchiveToolOptions{Workspace: workspace, Restrict: true})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "create", "path": "out/app.tar.gz", "sources": []any{"app"}})
+	if result.IsError {
+		t.Fatalf("create: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "2 files") || !strings.Contains(result.ForLLM, "2 directories") {
+		t.Errorf("create result = %s", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "create", "path": "out/app.tar.gz", "sources": []any{"app"}})
+	if !result.IsError || !strings.Contains(result.ForLLM, "already exists") {
+		t.Errorf("create over an existing archive: %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list", "path": "out/app.tar.gz"})
+	if result.IsError || !strings.Contains(result.ForLLM, "app/bin/run (10 B, executable)") {
+		t.Errorf("list = %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "extract", "path": "out/app.tar.gz"})
+	if result.IsError {
+		t.Fatalf("extract: %s", result.ForLLM)
+	}
+	info, err := os.Stat(filepath.Join(workspace, "out", "app", "app", "bin", "run"))
+	if err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("extracted program = %v, %v", info, err)
+	}
+	if !strings.Contains(result.ForLLM, "Top level: app/") {
+		t.Errorf("extract result = %s", result.ForLLM)
+	}
+}
+
+func TestArchiveTool_Refusals(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewArchiveTool(ArchiveToolOptions{Workspace: workspace, Restrict: true})
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"format", map[string]any{"action": "list", "path": "notes.txt"}, "not a supported archive"},
+		{"outside", map[string]any{"action": "list", "path": "/etc/app.zip"}, "outside"},
+		{"dest outside", map[string]any{"action": "extract", "path": "app.zip", "dest": "/tmp/x"}, "outside"},
+		{"no sources", map[string]any{"action": "create", "path": "app.zip"}, "sources is required"},
+		{"bz2", map[string]any{"action": "create", "path": "app.tar.bz2", "sources": []any{"."}},
+			"can be extracted but not created"},
+		{"action", map[string]any{"action": "shred", "path": "app.zip"}, "unknown action"},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(context.Background(), tt.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("%s: %q, want %q", tt.name, result.ForLLM, tt.want)
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultBrowserTimeout = 30 * time.Second
+	// The browser is shut down after this long without use.
+	browserIdleTimeout = 5 * time.Minute
+	maxBrowserText     = 20000
+)
+
+// BrowserTool drives a headless Chrome or Chromium, for sites that don't
+// work without JavaScript: it opens pages, clicks, types, reads the page's
+// text and takes screenshots. It only loads pages on the allowed domains;
+// other pages, including those a click or a redirect leads to, are
+// blocked. The browser starts on first use and stops when left idle.
+type BrowserTool struct {
+	allow      []string
+	chromePath string
+	timeout    time.Duration
+	workspace  string
+
+	// launch starts a browser and returns its DevTools URL; replaced in
+	// tests.
+	launch func(ctx context.Context, chromePath string) (string, func(), error)
+
+	sendCallback   SendMediaCallback
+	ctxMu          sync.Mutex
+	defaultChannel string
+	defaultChatID  string
+
+	mu      sync.Mutex // Held for the whole of each action
+	conn    *cdpConn
+	session string
+	stop    func()
+	idle    *time.Timer
+	used    time.Time
+}
+
+// BrowserToolOptions configures a BrowserTool.
+type BrowserToolOptions struct {
+	AllowDomains []string
+	ChromePath   string
+	Timeout      time.Duration // Per action; 0 for the default
+	Workspace    string        // Screenshots go to its screenshots/ directory
+}
+
+func NewBrowserTool(opts BrowserToolOptions) *BrowserTool {
+	t := &BrowserTool{
+		allow:      opts.AllowDomains,
+		chromePath: opts.ChromePath,
+		timeout:    opts.Timeout,
+		workspace:  opts.Workspace,
+		launch:     launchBrowser,
+	}
+	if t.timeout <= 0 {
+		t.timeout = defaultBrowserTimeout
+	}
+	return t
+}
+
+func (t *BrowserTool) Name() string {
+	return "browser"
+}
+
+func (t *BrowserTool) Description() string {
+	return fmt.Sprintf("Use a real (headless) web browser, for sites that need JavaScript or where you must "+
+		"fill in forms; prefer fetch_url for plain pages. Actions: navigate to a URL, click an element, type "+
+		"into a field, text to read the page, screenshot, close. Elements are CSS selectors. "+
+		"Only these domains can be opened: %s.", strings.Join(t.allow, ", "))
+}
+
+func (t *BrowserTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"navigate", "click", "type", "text", "screenshot", "close"},
+				"description": "What to do",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The page to open (navigate)",
+			},
+			"selector": map[string]any{
+				"type":        "string",
+				"description": "CSS selector of the element (click, type; text reads only this element)",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The text to type, replacing the field's content (type)",
+			},
+			"submit": map[string]any{
+				"type":        "boolean",
+				"description": "Press Enter after typing (type)",
+			},
+			"full_page": map[string]any{
+				"type":        "boolean",
+				"description": "Capture the whole page rather than the visible part (screenshot)",
+			},
+			"send": map[string]any{
+				"type":        "boolean",
+				"description": "Send the screenshot to the user too (screenshot)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *BrowserTool) SetContext(channel, chatID string) {
+	t.ctxMu.Lock()
+	defer t.ctxMu.Unlock()
+	t.defaultChannel = channel
+	t.defaultChatID = chatID
+}
+
+func (t *BrowserTool) SetSendCallback(callback SendMediaCallback) {
+	t.sendCallback = callback
+}
+
+func (t *BrowserTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if action == "close" {
+		t.shutdown()
+		return SilentResult("Browser closed.")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	if err := t.ensureStarted(ctx); err != nil {
+		return ErrorResult(fmt.Sprintf("browser unavailable: %v", err)).WithError(err)
+	}
+	t.used = time.Now()
+	t.idle.Reset(browserIdleTimeout)
+
+	var (
+		result string
+		err    error
+	)
+	switch action {
+	case "navigate":
+		result, err = t.navigate(ctx, args)
+	case "click":
+		result, err = t.click(ctx, args)
+	case "type":
+		result, err = t.typeText(ctx, args)
+	case "text":
+		result, err = t.text(ctx, args)
+	case "screenshot":
+		result, err = t.screenshot(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%s took longer than %s", action, t.timeout)
+		}
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	return SilentResult(result)
+}
+
+func (t *BrowserTool) navigate(ctx context.Context, args map[string]any) (string, error) {
+	raw, _ := args["url"].(string)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("navigate needs an http or https url")
+	}
+	if !domainAllowed(u.Hostname(), t.allow) {
+		return "", fmt.Errorf("%s isn't an allowed domain (allowed: %s)", u.Hostname(), strings.Join(t.allow, ", "))
+	}
+	var nav struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := t.conn.call(ctx, t.session, "Page.navigate", map[string]any{"url": u.String()}, &nav); err != nil {
+		return "", err
+	}
+	if nav.ErrorText != "" {
+		return "", fmt.Errorf("couldn't open %s: %s", u, nav.ErrorText)
+	}
+	if err := t.waitLoaded(ctx); err != nil {
+		return "", err
+	}
+	return t.pageSummary(ctx, 2000)
+}
+
+func (t *BrowserTool) click(ctx context.Context, args map[string]any) (string, error) {
+	selector, _ := args["selector"].(string)
+	if selector == "" {
+		return "", errors.New("click needs a selector")
+	}
+	if _, err := t.withElement(ctx, selector, `el.scrollIntoView({block: "center"}); el.click();`); err != nil {
+		return "", err
+	}
+	// Give a navigation the click started time to begin.
+	time.Sleep(300 * time.Millisecond)
+	if err := t.waitLoaded(ctx); err != nil {
+		return "", err
+	}
+	return t.pageSummary(ctx, 1000)
+}
+
+func (t *BrowserTool) typeText(ctx context.Context, args map[string]any) (string, error) {
+	selector, _ := args["selector"].(string)
+	text, ok := args["text"].(string)
+	if selector == "" || !ok {
+		return "", errors.New("type needs a selector and text")
+	}
+	// Select the field's content, so the typed text replaces it.
+	focus := `el.scrollIntoView({block: "center"}); el.focus(); if (el.select) el.select();`
+	if _, err := t.withElement(ctx, selector, focus); err != nil {
+		return "", err
+	}
+	if err := t.conn.call(ctx, t.session, "Input.insertText", map[string]any{"text": text}, nil); err != nil {
+		return "", err
+	}
+	if submit, _ := args["submit"].(bool); !submit {
+		return fmt.Sprintf("Typed into %s.", selector), nil
+	}
+	for _, typ := range []string{"keyDown", "keyUp"} {
+		key := map[string]any{"type": typ, "key": "Enter", "code": "Enter", "windowsVirtualKeyCode": 13}
+		if typ == "keyDown" {
+			key["text"] = "\r"
+		}
+		if err := t.conn.call(ctx, t.session, "Input.dispatchKeyEvent", key, nil); err != nil {
+			return "", err
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+	if err := t.waitLoaded(ctx); err != nil {
+		return "", err
+	}
+	return t.pageSummary(ctx, 1000)
+}
+
+func (t *BrowserTool) text(ctx context.Context, args map[string]any) (string, error) {
+	selector, _ := args["selector"].(string)
+	if selector == "" {
+		return t.pageSummary(ctx, maxBrowserText)
+	}
+	value, err := t.withElement(ctx, selector, `return el.innerText;`)
+	if err != nil {
+		return "", err
+	}
+	text, _ := value.(string)
+	return truncateText(text, maxBrowserText), nil
+}
+
+func (t *BrowserTool) screenshot(ctx context.Context, args map[string]any) (string, error) {
+	fullPage, _ := args["full_page"].(bool)
+	var shot struct {
+		Data string `json:"data"`
+	}
+	params := map[string]any{"format": "png", "captureBeyondViewport": fullPage}
+	if err := t.conn.call(ctx, t.session, "Page.captureScreenshot", params, &shot); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(shot.Data)
+	if err != nil {
+		return "", fmt.Errorf("bad screenshot from the browser: %w", err)
+	}
+	dir := filepath.Join(t.workspace, "screenshots")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405.000")+".png")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Screenshot saved to %s.", path)
+	if send, _ := args["send"].(bool); send {
+		t.ctxMu.Lock()
+		channel, chatID := t.defaultChannel, t.defaultChatID
+		t.ctxMu.Unlock()
+		switch {
+		case t.sendCallback == nil || channel == "" || chatID == "":
+			result += " No chat to send it to."
+		case t.sendCallback(channel, chatID, "", []string{path}) != nil:
+			result += " Sending it to the user failed."
+		default:
+			result += " It was sent to the user."
+		}
+	}
+	return result, nil
+}
+
+// withElement runs body, JavaScript with the element matching selector as
+// el, and returns what it returns.
+func (t *BrowserTool) withElement(ctx context.Context, selector, body string) (any, error) {
+	quoted, _ := json.Marshal(selector)
+	expr := fmt.Sprintf(`(() => { const el = document.querySelector(%s); `+
+		`if (!el) return {missing: true}; return {value: (() => { %s })()}; })()`, quoted, body)
+	var result struct {
+		Missing bool `json:"missing"`
+		Value   any  `json:"value"`
+	}
+	if err := t.evaluate(ctx, expr, &result); err != nil {
+		return nil, err
+	}
+	if result.Missing {
+		return nil, fmt.Errorf("no element matches %s on this page", selector)
+	}
+	return result.Value, nil
+}
+
+// evaluate runs a JavaScript expression in the page and decodes its value.
+func (t *BrowserTool) evaluate(ctx context.Context, expr string, value any) error {
+	var res struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	params := map[string]any{"expression": expr, "returnByValue": true, "awaitPromise": true}
+	if err := t.conn.call(ctx, t.session, "Runtime.evaluate", params, &res); err != nil {
+		return err
+	}
+	if d := res.ExceptionDetails; d != nil {
+		if d.Exception.Description != "" {
+			return fmt.Errorf("script error: %s", d.Exception.Description)
+		}
+		return fmt.Errorf("script error: %s", d.Text)
+	}
+	if value == nil || len(res.Result.Value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Result.Value, value)
+}
+
+// waitLoaded waits for the page to finish loading.
+func (t *BrowserTool) waitLoaded(ctx context.Context) error {
+	for {
+		var state string
+		if err := t.evaluate(ctx, "document.readyState", &state); err == nil && state == "complete" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// pageSummary describes the current page and up to maxChars of its text.
+func (t *BrowserTool) pageSummary(ctx context.Context, maxChars int) (string, error) {
+	var page struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		Text  string `json:"text"`
+	}
+	expr := `({title: document.title, url: location.href, text: document.body ? document.body.innerText : ""})`
+	if err := t.evaluate(ctx, expr, &page); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Page: %s\nURL: %s\n\n%s", page.Title, page.URL, truncateText(page.Text, maxChars)), nil
+}
+
+func truncateText(text string, maxChars int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= maxChars {
+		return text
+	}
+	cut := maxChars
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + fmt.Sprintf("\n\n[%d more characters; use text with a selector to read a part]",
+		len(text)-cut)
+}
+
+// ensureStarted starts the browser and opens a tab, unless that is done.
+// Must be called with the lock held.
+func (t *BrowserTool) ensureStarted(ctx context.Context) error {
+	if t.conn != nil {
+		select {
+		case <-t.conn.done:
+			t.shutdown() // The browser died; start another.
+		default:
+			return nil
+		}
+	}
+
+	wsURL, stop, err := t.launch(ctx, t.chromePath)
+	if err != nil {
+		return err
+	}
+	conn, err := dialCDP(ctx, wsURL, func(c *cdpConn, session, method string, params json.RawMessage) {
+		if method == "Fetch.requestPaused" {
+			go t.filterRequest(c, session, params)
+		}
+	})
+	if err != nil {
+		stop()
+		return err
+	}
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	err = conn.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank"}, &target)
+	if err == nil {
+		err = conn.call(ctx, "", "Target.attachToTarget",
+			map[string]any{"targetId": target.TargetID, "flatten": true}, &attached)
+	}
+	if err == nil {
+		// Every page and frame load goes by filterRequest.
+		err = conn.call(ctx, attached.SessionID, "Fetch.enable", map[string]any{
+			"patterns": []map[string]any{{"urlPattern": "*", "resourceType": "Document"}},
+		}, nil)
+	}
+	if err == nil {
+		err = conn.call(ctx, attached.SessionID, "Page.enable", nil, nil)
+	}
+	if err != nil {
+		conn.close()
+		stop()
+		return err
+	}
+
+	t.conn, t.session, t.stop = conn, attached.SessionID, stop
+	t.idle = time.AfterFunc(browserIdleTimeout, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// An action may have run while this waited for the lock.
+		if t.conn == conn && time.Since(t.used) >= browserIdleTimeout {
+			t.shutdown()
+		}
+	})
+	return nil
+}
+
+// filterRequest lets a page load go on if it is on an allowed domain and
+// blocks it otherwise.
+func (t *BrowserTool) filterRequest(conn *cdpConn, session string, params json.RawMessage) {
+	var paused struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL string `json:"url"`
+		} `json:"request"`
+	}
+	if json.Unmarshal(params, &paused) != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	u, err := url.Parse(paused.Request.URL)
+	if err == nil && (u.Scheme == "about" || u.Scheme == "data" || domainAllowed(u.Hostname(), t.allow)) {
+		_ = conn.call(ctx, session, "Fetch.continueRequest", map[string]any{"requestId": paused.RequestID}, nil)
+		return
+	}
+	_ = conn.call(ctx, session, "Fetch.failRequest",
+		map[string]any{"requestId": paused.RequestID, "errorReason": "BlockedByClient"}, nil)
+}
+
+// Close stops the browser, if it is running.
+func (t *BrowserTool) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.shutdown()
+}
+
+// shutdown stops the browser, if it is running. Must be called with the
+// lock held.
+func (t *BrowserTool) shutdown() {
+	if t.conn == nil {
+		return
+	}
+	t.idle.Stop()
+	t.conn.close()
+	t.stop()
+	t.conn, t.session, t.stop, t.idle = nil, "", nil, nil
+}
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// browserNames are the programs tried, in order, when no browser is
+// configured.
+var browserNames = []string{
+	"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome", "headless_shell",
+}
+
+var reDevToolsURL = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+
+// cdpConn is a connection to a browser over the Chrome DevTools Protocol:
+// calls wait for their answer, and events go to onEvent, which mustn't
+// block.
+type cdpConn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+	onEvent cdpEventHandler
+
+	mu      sync.Mutex
+	pending map[int64]chan cdpMessage
+	err     error // Why the connection ended
+	done    chan struct{}
+}
+
+// cdpEventHandler receives the events of a session ("" for the browser).
+type cdpEventHandler func(c *cdpConn, session, method string, params json.RawMessage)
+
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    any             `json:"params,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func dialCDP(ctx context.Context, wsURL string, onEvent cdpEventHandler) (*cdpConn, error) {
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the browser: %w", err)
+	}
+	c := &cdpConn{ws: ws, onEvent: onEvent, pending: make(map[int64]chan cdpMessage), done: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *cdpConn) readLoop() {
+	defer close(c.done)
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("the browser connection closed: %w", err)
+			c.mu.Unlock()
+			return
+		}
+		var msg struct {
+			cdpMessage
+			Params json.RawMessage `json:"params,omitempty"`
+		}
+		if json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		if msg.ID == 0 {
+			if c.onEvent != nil && msg.Method != "" {
+				c.onEvent(c, msg.SessionID, msg.Method, msg.Params)
+			}
+			continue
+		}
+		c.mu.Lock()
+		ch := c.pending[msg.ID]
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- msg.cdpMessage
+		}
+	}
+}
+
+// call sends a command to the page session (or the browser, with no
+// session) and decodes its result into result, if not nil.
+func (c *cdpConn) call(ctx context.Context, session, method string, params, result any) error {
+	id := c.nextID.Add(1)
+	ch := make(chan cdpMessage, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(cdpMessage{ID: id, Method: method, Params: params, SessionID: session})
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	err = c.ws.WriteMessage(websocket.TextMessage, data)
+	c.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	case msg := <-ch:
+		if msg.Error != nil {
+			return fmt.Errorf("%s: %s", method, msg.Error.Message)
+		}
+		if result != nil && len(msg.Result) > 0 {
+			return json.Unmarshal(msg.Result, result)
+		}
+		return nil
+	}
+}
+
+func (c *cdpConn) close() {
+	c.ws.Close()
+	<-c.done
+}
+
+// launchBrowser starts a headless browser with a fresh profile and returns
+// its DevTools URL and a function that stops it and removes the profile.
+func launchBrowser(ctx context.Context, chromePath string) (string, func(), error) {
+	if chromePath == "" {
+		for _, name := range browserNames {
+			if path, err := exec.LookPath(name); err == nil {
+				chromePath = path
+				break
+			}
+		}
+		if chromePath == "" {
+			return "", nil, errors.New("no Chrome or Chromium found; install one or set tools.browser.chrome_path")
+		}
+	}
+	profile, err := os.MkdirTemp("", "picoclaw-browser-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := []string{
+		"--headless=new", "--remote-debugging-port=0", "--user-data-dir=" + profile,
+		"--no-first-run", "--no-default-browser-check", "--disable-extensions", "--disable-gpu",
+		"--disable-background-networking", "--disable-sync", "--mute-audio", "--hide-scrollbars",
+		"--window-size=1280,800",
+	}
+	if os.Geteuid() == 0 {
+		// Chrome's sandbox refuses to run as root.
+		args = append(args, "--no-sandbox")
+	}
+	cmd := exec.Command(chromePath, append(args, "about:blank")...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		os.RemoveAll(profile)
+		return "", nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(profile)
+		return "", nil, fmt.Errorf("starting %s: %w", chromePath, err)
+	}
+	stop := func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		os.RemoveAll(profile)
+	}
+
+	found := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if m := reDevToolsURL.FindStringSubmatch(scanner.Text()); m != nil {
+				found <- m[1]
+				break
+			}
+		}
+		close(found)
+		_, _ = io.Copy(io.Discard, stderr)
+	}()
+
+	select {
+	case wsURL, ok := <-found:
+		if !ok {
+			stop()
+			return "", nil, fmt.Errorf("%s exited without starting", chromePath)
+		}
+		return wsURL, stop, nil
+	case <-time.After(20 * time.Second):
+		stop()
+		return "", nil, fmt.Errorf("%s didn't start within 20s", chromePath)
+	case <-ctx.Done():
+		stop()
+		return "", nil, ctx.Err()
+	}
+}
+
+// domainAllowed reports whether host is one of domains or a subdomain of
+// one.
+func domainAllowed(host string, domains []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
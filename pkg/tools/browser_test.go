@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeCDP is a browser's DevTools endpoint that answers the commands the
+// browser tool sends and records them.
+type fakeCDP struct {
+	mu       sync.Mutex
+	calls    []string          // Methods, in order
+	params   map[string][]byte // Last params of each method
+	redirect string            // A page load to report after each navigation
+}
+
+func (f *fakeCDP) record(method string, params json.RawMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+	f.params[method] = params
+}
+
+func (f *fakeCDP) called(method string) (json.RawMessage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.params[method]
+	return p, ok
+}
+
+func (f *fakeCDP) serve(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		var writeMu sync.Mutex
+		send := func(v any) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			ws.WriteJSON(v)
+		}
+		for {
+			var msg struct {
+				ID        int64           `json:"id"`
+				Method    string          `json:"method"`
+				Params    json.RawMessage `json:"params"`
+				SessionID string          `json:"sessionId"`
+			}
+			if ws.ReadJSON(&msg) != nil {
+				return
+			}
+			f.record(msg.Method, msg.Params)
+			result := map[string]any{}
+			switch msg.Method {
+			case "Target.createTarget":
+				result["targetId"] = "T1"
+			case "Target.attachToTarget":
+				result["sessionId"] = "S1"
+			case "Page.navigate":
+				var p struct{ URL string }
+				json.Unmarshal(msg.Params, &p)
+				for i, u := range []string{p.URL, f.redirect} {
+					if u != "" {
+						send(map[string]any{"method": "Fetch.requestPaused", "sessionId": "S1",
+							"params": map[string]any{"requestId": []string{"r1", "r2"}[i], "request": map[string]any{"url": u}}})
+					}
+				}
+				result["frameId"] = "F1"
+			case "Runtime.evaluate":
+				var p struct{ Expression string }
+				json.Unmarshal(msg.Params, &p)
+				switch {
+				case p.Expression == "document.readyState":
+					result["result"] = map[string]any{"value": "complete"}
+				case strings.Contains(p.Expression, "document.title"):
+					result["result"] = map[string]any{"value": map[string]any{
+						"title": "Dashboard", "url": "https://app.example.com/", "text": "Sales: 42",
+					}}
+				case strings.Contains(p.Expression, `"#missing"`):
+					result["result"] = map[string]any{"value": map[string]any{"missing": true}}
+				case strings.Contains(p.Expression, "innerText"):
+					result["result"] = map[string]any{"value": map[string]any{"value": "Row 1"}}
+				default:
+					result["result"] = map[string]any{"value": map[string]any{}}
+				}
+			case "Page.captureScreenshot":
+				result["data"] = base64.StdEncoding.EncodeToString([]byte("\x89PNG"))
+			}
+			send(map[string]any{"id": msg.ID, "result": result})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func newTestBrowser(t *testing.T, f *fakeCDP) (*BrowserTool, *bool) {
+	f.params = make(map[string][]byte)
+	wsURL := f.serve(t)
+	stopped := new(bool)
+	tool := NewBrowserTool(BrowserToolOptions{
+		AllowDomains: []string{"example.com"},
+		Timeout:      5 * time.Second,
+		Workspace:    t.TempDir(),
+	})
+	tool.launch = func(ctx context.Context, chromePath string) (string, func(), error) {
+		return wsURL, func() { *stopped = true }, nil
+	}
+	t.Cleanup(tool.Close)
+	return tool, stopped
+}
+
+func TestBrowserTool_Actions(t *testing.T) {
+	f := &fakeCDP{}
+	tool, stopped := newTestBrowser(t, f)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "navigate", "url": "https://app.example.com/"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Page: Dashboard") ||
+		!strings.Contains(result.ForLLM, "Sales: 42") {
+		t.Fatalf("navigate = %q", result.ForLLM)
+	}
+	if p, _ := f.called("Fetch.enable"); !strings.Contains(string(p), `"Document"`) {
+		t.Errorf("Fetch.enable params = %s, want page loads intercepted", p)
+	}
+
+	if result := tool.Execute(ctx, map[string]any{"action": "click", "selector": "#missing"}); !result.IsError ||
+		!strings.Contains(result.ForLLM, "no element matches #missing") {
+		t.Errorf("click on a missing element = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "type", "selector": "#q", "text": "sales", "submit": true})
+	if result.IsError {
+		t.Fatalf("type = %q", result.ForLLM)
+	}
+	if p, _ := f.called("Input.insertText"); string(p) != `{"text":"sales"}` {
+		t.Errorf("Input.insertText params = %s", p)
+	}
+	if p, _ := f.called("Input.dispatchKeyEvent"); !strings.Contains(string(p), "Enter") {
+		t.Errorf("Input.dispatchKeyEvent params = %s, want Enter", p)
+	}
+
+	if result := tool.Execute(ctx, map[string]any{"action": "text", "selector": "table"}); result.ForLLM != "Row 1" {
+		t.Errorf("text = %q, want %q", result.ForLLM, "Row 1")
+	}
+
+	var sent []string
+	tool.SetSendCallback(func(channel, chatID, caption string, paths []string) error {
+		sent = paths
+		return nil
+	})
+	tool.SetContext("telegram", "42")
+	result = tool.Execute(ctx, map[string]any{"action": "screenshot", "send": true})
+	if result.IsError || len(sent) != 1 || !strings.Contains(result.ForLLM, "sent to the user") {
+		t.Fatalf("screenshot = %q, sent %v", result.ForLLM, sent)
+	}
+	if data, err := os.ReadFile(sent[0]); err != nil || string(data) != "\x89PNG" {
+		t.Errorf("screenshot file = %q, %v", data, err)
+	}
+
+	tool.Execute(ctx, map[string]any{"action": "close"})
+	if !*stopped {
+		t.Error("close didn't stop the browser")
+	}
+}
+
+func TestBrowserTool_AllowList(t *testing.T) {
+	f := &fakeCDP{redirect: "https://evil.test/phish"}
+	tool, _ := newTestBrowser(t, f)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "navigate", "url": "https://other.org/"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "isn't an allowed domain") {
+		t.Errorf("navigate to another domain = %q", result.ForLLM)
+	}
+	if _, ok := f.called("Page.navigate"); ok {
+		t.Error("the browser was sent to a domain that isn't allowed")
+	}
+
+	// A page load elsewhere, as from a redirect, is blocked.
+	if result := tool.Execute(ctx, map[string]any{"action": "navigate", "url": "https://example.com/"}); result.IsError {
+		t.Fatalf("navigate = %q", result.ForLLM)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		cont, ok1 := f.called("Fetch.continueRequest")
+		fail, ok2 := f.called("Fetch.failRequest")
+		if ok1 && ok2 {
+			if !strings.Contains(string(cont), "r1") || !strings.Contains(string(fail), "r2") {
+				t.Errorf("continued %s and failed %s, want r1 and r2", cont, fail)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("the page loads weren't both answered")
+}
+
+func TestBrowserTool_NoBrowser(t *testing.T) {
+	tool := NewBrowserTool(BrowserToolOptions{AllowDomains: []string{"example.com"}, ChromePath: "/nonexistent/chrome"})
+	result := tool.Execute(context.Background(), map[string]any{"action": "navigate", "url": "https://example.com"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "browser unavailable") {
+		t.Errorf("Execute() = %q, want the browser reported unavailable", result.ForLLM)
+	}
+}
+
+func TestDomainAllowed(t *testing.T) {
+	domains := []string{"example.com", ".Intranet.local"}
+	tests := map[string]bool{
+		"example.com":         true,
+		"app.example.com":     true,
+		"EXAMPLE.COM.":        true,
+		"badexample.com":      false,
+		"example.com.evil.io": false,
+		"wiki.intranet.local": true,
+		"other.org":           false,
+	}
+	for host, want := range tests {
+		if got := domainAllowed(host, domains); got != want {
+			t.Errorf("domainAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/calendar"
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// defaultEventLength is the length of an event created without an end.
+const defaultEventLength = time.Hour
+
+// maxListedEvents bounds how many events a list shows.
+const maxListedEvents = 100
+
+// CalendarTool lists, creates, moves and deletes the events of the user's
+// calendar.
+type CalendarTool struct {
+	cal calendar.Calendar
+	loc *time.Location // Default time zone
+	now func() time.Time
+}
+
+// NewCalendarTool uses cal, reading times without a zone in loc.
+func NewCalendarTool(cal calendar.Calendar, loc *time.Location) *CalendarTool {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &CalendarTool{cal: cal, loc: loc, now: time.Now}
+}
+
+func (t *CalendarTool) Name() string {
+	return "calendar"
+}
+
+func (t *CalendarTool) Description() string {
+	return "Read and change the user's calendar: list events in a period ('what's on tomorrow'), create events, " +
+		"update them ('move my 3pm to 4pm') and delete them. List first to find an event's id. " +
+		"Pass the user's time zone as 'tz' when you know it."
+}
+
+func (t *CalendarTool) Parameters() map[string]any {
+	timeHelp := "an ISO time like 2026-10-16T15:00, a date like 2026-10-16, or 'tomorrow at 3pm'"
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "create", "update", "delete"},
+				"description": "What to do",
+			},
+			"from": map[string]any{
+				"type":        "string",
+				"description": "Start of the period to list: " + timeHelp + ". Default: today",
+			},
+			"to": map[string]any{
+				"type": "string",
+				"description": "End of the period to list; a date includes that whole day. " +
+					"Default: a week after 'from'",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "Event ID, from list (for update and delete)",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "Event title (for create and update)",
+			},
+			"start": map[string]any{
+				"type": "string",
+				"description": "Event start (for create and update): " + timeHelp +
+					"; a date makes an all-day event. Moving an event keeps its length unless 'end' is given",
+			},
+			"end": map[string]any{
+				"type":        "string",
+				"description": "Event end (for create and update). Default: an hour after start, or the same day",
+			},
+			"location": map[string]any{
+				"type":        "string",
+				"description": "Event location (for create and update)",
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "Event notes (for create and update)",
+			},
+			"tz": map[string]any{
+				"type": "string",
+				"description": "IANA time zone of the times given and shown (e.g. 'Europe/Paris'). " +
+					"Default: the calendar's",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *CalendarTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	loc := t.loc
+	if tz, _ := args["tz"].(string); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return ErrorResult(fmt.Sprintf("unknown time zone %q", tz))
+		}
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "list":
+		return t.list(ctx, args, loc)
+	case "create":
+		return t.create(ctx, args, loc)
+	case "update":
+		return t.update(ctx, args, loc)
+	case "delete":
+		id, _ := args["id"].(string)
+		if id == "" {
+			return ErrorResult("id is required to delete an event")
+		}
+		if err := t.cal.Delete(ctx, id); err != nil {
+			return calendarError("Error deleting the event", err)
+		}
+		return SilentResult("Event deleted")
+	}
+	return ErrorResult(fmt.Sprintf("unknown action %q: use list, create, update or delete", action))
+}
+
+func (t *CalendarTool) list(ctx context.Context, args map[string]any, loc *time.Location) *ToolResult {
+	now := t.now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if s, _ := args["from"].(string); s != "" {
+		at, _, err := parseEventTime(s, now)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("can't understand from: %v", err))
+		}
+		from = at
+	}
+	to := from.AddDate(0, 0, 7)
+	if s, _ := args["to"].(string); s != "" {
+		at, dateOnly, err := parseEventTime(s, now)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("can't understand to: %v", err))
+		}
+		to = at
+		if dateOnly {
+			to = at.AddDate(0, 0, 1)
+		}
+	}
+	if !to.After(from) {
+		return ErrorResult("'to' must be after 'from'")
+	}
+
+	events, err := t.cal.Events(ctx, from, to)
+	if err != nil {
+		return calendarError("Error listing events", err)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Events from %s to %s (%s):", from.Format("Mon 2006-01-02 15:04"),
+		to.Format("Mon 2006-01-02 15:04"), loc)
+	if len(events) == 0 {
+		sb.WriteString("\nNone")
+	}
+	for i, e := range events {
+		if i == maxListedEvents {
+			fmt.Fprintf(&sb, "\n... and %d more; list a shorter period", len(events)-i)
+			break
+		}
+		sb.WriteString("\n- " + formatEvent(e, loc))
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *CalendarTool) create(ctx context.Context, args map[string]any, loc *time.Location) *ToolResult {
+	title, _ := args["title"].(string)
+	start, _ := args["start"].(string)
+	if title == "" || start == "" {
+		return ErrorResult("title and start are required to create an event")
+	}
+	event := calendar.Event{Title: title}
+	event.Location, _ = args["location"].(string)
+	event.Description, _ = args["description"].(string)
+	end, _ := args["end"].(string)
+	if err := t.setTimes(&event, start, end, loc, false); err != nil {
+		return ErrorResult(err.Error())
+	}
+	created, err := t.cal.Create(ctx, event)
+	if err != nil {
+		return calendarError("Error creating the event", err)
+	}
+	return SilentResult("Event created: " + formatEvent(*created, loc))
+}
+
+func (t *CalendarTool) update(ctx context.Context, args map[string]any, loc *time.Location) *ToolResult {
+	id, _ := args["id"].(string)
+	if id == "" {
+		return ErrorResult("id is required to update an event")
+	}
+	event, err := t.cal.Event(ctx, id)
+	if err != nil {
+		return calendarError("Error reading the event", err)
+	}
+	if title, ok := args["title"].(string); ok && title != "" {
+		event.Title = title
+	}
+	if location, ok := args["location"].(string); ok {
+		event.Location = location
+	}
+	if description, ok := args["description"].(string); ok {
+		event.Description = description
+	}
+	start, _ := args["start"].(string)
+	end, _ := args["end"].(string)
+	switch {
+	case start != "":
+		if err := t.setTimes(event, start, end, loc, true); err != nil {
+			return ErrorResult(err.Error())
+		}
+	case end != "":
+		at, _, err := parseEventTime(end, t.now().In(loc))
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("can't understand end: %v", err))
+		}
+		if !at.After(event.Start) {
+			return ErrorResult("the end must be after the start")
+		}
+		event.End = at
+	}
+
+	updated, err := t.cal.Update(ctx, *event)
+	if err != nil {
+		return calendarError("Error updating the event", err)
+	}
+	return SilentResult("Event updated: " + formatEvent(*updated, loc))
+}
+
+// setTimes sets the start and end of event from the arguments. Without an
+// end, the event keeps its length when keepLength is set, and otherwise
+// lasts an hour, or a day.
+func (t *CalendarTool) setTimes(event *calendar.Event, start, end string, loc *time.Location, keepLength bool) error {
+	now := t.now().In(loc)
+	at, allDay, err := parseEventTime(start, now)
+	if err != nil {
+		return fmt.Errorf("can't understand start: %v", err)
+	}
+	length := defaultEventLength
+	if allDay {
+		length = 24 * time.Hour
+	}
+	if keepLength && event.AllDay == allDay && event.End.After(event.Start) {
+		length = event.End.Sub(event.Start)
+	}
+	event.Start, event.AllDay = at, allDay
+	if allDay {
+		event.End = at.AddDate(0, 0, max(int(length.Hours()/24), 1))
+	} else {
+		event.End = at.Add(length)
+	}
+	if end == "" {
+		return nil
+	}
+
+	endAt, endDate, err := parseEventTime(end, now)
+	if err != nil {
+		return fmt.Errorf("can't understand end: %v", err)
+	}
+	if allDay && endDate {
+		// The day given is the last day of the event.
+		endAt = endAt.AddDate(0, 0, 1)
+	}
+	if !endAt.After(at) {
+		return errors.New("the end must be after the start")
+	}
+	event.End = endAt
+	return nil
+}
+
+// parseEventTime reads an ISO time or date, or a time in plain English
+// (see cron.ParseSchedule), with now giving the time zone. dateOnly is
+// set for a date without a time.
+func parseEventTime(s string, now time.Time) (at time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+	if at, err := time.Parse(time.RFC3339, s); err == nil {
+		return at.In(now.Location()), false, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if at, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return at, false, nil
+		}
+	}
+	if at, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return at, true, nil
+	}
+	switch strings.ToLower(s) {
+	case "today":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), true, nil
+	case "tomorrow":
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()), true, nil
+	}
+	schedule, err := cron.ParseSchedule(s, now)
+	if err != nil || schedule.Kind != "at" {
+		return time.Time{}, false, fmt.Errorf("%q is neither a time nor a date", s)
+	}
+	return time.UnixMilli(*schedule.AtMS).In(now.Location()), false, nil
+}
+
+// formatEvent shows an event on one line, in loc.
+func formatEvent(e calendar.Event, loc *time.Location) string {
+	var when string
+	if e.AllDay {
+		when = e.Start.Format("Mon 2006-01-02")
+		if last := e.End.AddDate(0, 0, -1); last.After(e.Start) {
+			when += " to " + last.Format("Mon 2006-01-02")
+		}
+		when += ", all day"
+	} else {
+		start, end := e.Start.In(loc), e.End.In(loc)
+		when = start.Format("Mon 2006-01-02 15:04")
+		if end.YearDay() == start.YearDay() && end.Year() == start.Year() {
+			when += "-" + end.Format("15:04")
+		} else if end.After(start) {
+			when += " to " + end.Format("Mon 2006-01-02 15:04")
+		}
+	}
+
+	line := when + ": " + e.Title
+	if e.Location != "" {
+		line += " @ " + e.Location
+	}
+	if e.Recurring {
+		line += " (repeating)"
+	}
+	line += " [id: " + e.ID + "]"
+	if e.Description != "" {
+		line += "\n  " + strings.ReplaceAll(utils.Truncate(e.Description, 200), "\n", " ")
+	}
+	return line
+}
+
+func calendarError(what string, err error) *ToolResult {
+	switch {
+	case errors.Is(err, calendar.ErrNotFound):
+		return ErrorResult(what + ": there is no event with this id; list the events to find it")
+	case errors.Is(err, calendar.ErrRecurring), errors.Is(err, calendar.ErrNotLoggedIn):
+		return ErrorResult(what + ": " + err.Error())
+	}
+	return ErrorResult(fmt.Sprintf("%s: %v", what, err)).WithError(err)
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/calendar"
+)
+
+// memoryCalendar is a calendar.Calendar kept in memory.
+type memoryCalendar struct {
+	events map[string]calendar.Event
+	next   int
+}
+
+func (m *memoryCalendar) Events(_ context.Context, from, to time.Time) ([]calendar.Event, error) {
+	var out []calendar.Event
+	for _, e := range m.events {
+		if e.End.After(from) && e.Start.Before(to) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryCalendar) Event(_ context.Context, id string) (*calendar.Event, error) {
+	e, ok := m.events[id]
+	if !ok {
+		return nil, calendar.ErrNotFound
+	}
+	return &e, nil
+}
+
+func (m *memoryCalendar) Create(_ context.Context, e calendar.Event) (*calendar.Event, error) {
+	m.next++
+	e.ID = fmt.Sprintf("e%d", m.next)
+	m.events[e.ID] = e
+	return &e, nil
+}
+
+func (m *memoryCalendar) Update(_ context.Context, e calendar.Event) (*calendar.Event, error) {
+	if _, ok := m.events[e.ID]; !ok {
+		return nil, calendar.ErrNotFound
+	}
+	m.events[e.ID] = e
+	return &e, nil
+}
+
+func (m *memoryCalendar) Delete(_ context.Context, id string) error {
+	if _, ok := m.events[id]; !ok {
+		return calendar.ErrNotFound
+	}
+	delete(m.events, id)
+	return nil
+}
+
+func newTestCalendarTool(t *testing.T) (*CalendarTool, *memoryCalendar) {
+	t.Helper()
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	cal := &memoryCalendar{events: map[string]calendar.Event{}}
+	tool := NewCalendarTool(cal, paris)
+	tool.now = func() time.Time { return time.Date(2026, 10, 15, 9, 30, 0, 0, paris) }
+	return tool, cal
+}
+
+func TestCalendarTool_CreateMoveListDelete(t *testing.T) {
+	tool, cal := newTestCalendarTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "create", "title": "Dentist", "start": "tomorrow at 3pm", "location": "Rue X",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "Fri 2026-10-16 15:00-16:00: Dentist @ Rue X [id: e1]") {
+		t.Fatalf("create = %+v", result)
+	}
+
+	// Moving keeps the length.
+	result = tool.Execute(ctx, map[string]any{"action": "update", "id": "e1", "start": "2026-10-16T16:00"})
+	if result.IsError || !strings.Contains(result.ForLLM, "16:00-17:00") {
+		t.Fatalf("update = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list", "from": "tomorrow", "to": "2026-10-16"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Dentist") {
+		t.Fatalf("list = %+v", result)
+	}
+	// Shown in another zone when asked.
+	result = tool.Execute(ctx, map[string]any{"action": "list", "tz": "UTC"})
+	if !strings.Contains(result.ForLLM, "14:00-15:00") {
+		t.Errorf("list in UTC = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "list", "from": "2026-10-17"})
+	if !strings.Contains(result.ForLLM, "None") {
+		t.Errorf("list later = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "delete", "id": "e1"})
+	if result.IsError || len(cal.events) != 0 {
+		t.Fatalf("delete = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "delete", "id": "e1"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no event with this id") {
+		t.Errorf("delete again = %+v", result)
+	}
+}
+
+func TestCalendarTool_AllDayEvents(t *testing.T) {
+	tool, cal := newTestCalendarTool(t)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "create", "title": "Holiday", "start": "2026-10-20", "end": "2026-10-22",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "Tue 2026-10-20 to Thu 2026-10-22, all day") {
+		t.Fatalf("create = %+v", result)
+	}
+	e := cal.events["e1"]
+	if !e.AllDay || e.End.Sub(e.Start) != 72*time.Hour {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestCalendarTool_Errors(t *testing.T) {
+	tool, _ := newTestCalendarTool(t)
+	ctx := context.Background()
+	for _, args := range []map[string]any{
+		{"action": "create", "title": "x"},
+		{"action": "create", "title": "x", "start": "someday"},
+		{"action": "create", "title": "x", "start": "2026-10-16T15:00", "end": "2026-10-16T14:00"},
+		{"action": "update"},
+		{"action": "list", "tz": "Mars/Olympus"},
+		{"action": "list", "from": "2026-10-16", "to": "2026-10-15"},
+		{"action": "rename"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("Execute(%v) = %+v, want an error", args, result)
+		}
+	}
+}
+
+func TestParseEventTime(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	for in, want := range map[string]struct {
+		at       time.Time
+		dateOnly bool
+	}{
+		"2026-10-16T15:00":          {time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC), false},
+		"2026-10-16T15:00:00+02:00": {time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC), false},
+		"2026-10-16":                {time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), true},
+		"Today":                     {time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), true},
+		"in 2 hours":                {now.Add(2 * time.Hour), false},
+	} {
+		at, dateOnly, err := parseEventTime(in, now)
+		if err != nil || !at.Equal(want.at) || dateOnly != want.dateOnly {
+			t.Errorf("parseEventTime(%q) = %v, %v, %v; want %v, %v", in, at, dateOnly, err, want.at, want.dateOnly)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/camera"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const defaultPhotoQuestion = "Describe what you see in this photo, briefly and factually."
+
+// CapturePhotoTool takes a photo with the device's camera, saves it under
+// the workspace's photos/ directory and has a vision model describe it or
+// answer a question about it. It can send the photo to the user as well.
+type CapturePhotoTool struct {
+	camera    camera.Options
+	workspace string
+	vision    func() (providers.LLMProvider, string)
+
+	// capture takes the photo; replaced in tests.
+	capture func(ctx context.Context, opts camera.Options) (*camera.Photo, error)
+
+	sendCallback   SendMediaCallback
+	ctxMu          sync.Mutex
+	defaultChannel string
+	defaultChatID  string
+
+	mu sync.Mutex // The camera takes one photo at a time
+}
+
+// CapturePhotoToolOptions configures a CapturePhotoTool.
+type CapturePhotoToolOptions struct {
+	Camera    camera.Options
+	Workspace string // Photos go to its photos/ directory
+	// Vision returns the model that looks at the photos, which must accept
+	// images.
+	Vision func() (providers.LLMProvider, string)
+}
+
+func NewCapturePhotoTool(opts CapturePhotoToolOptions) *CapturePhotoTool {
+	return &CapturePhotoTool{
+		camera:    opts.Camera,
+		workspace: opts.Workspace,
+		vision:    opts.Vision,
+		capture:   camera.Capture,
+	}
+}
+
+func (t *CapturePhotoTool) Name() string {
+	return "capture_photo"
+}
+
+func (t *CapturePhotoTool) Description() string {
+	return "Take a photo with the device's camera, to see what is in front of it right now. The photo is " +
+		"looked at by a vision model, which answers 'question' about it (by default, describes it); set " +
+		"'send' to send the photo to the user too. Each call takes a new photo."
+}
+
+func (t *CapturePhotoTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"question": map[string]any{
+				"type":        "string",
+				"description": "What to find out from the photo, e.g. 'Is the garage door open?'",
+			},
+			"send": map[string]any{
+				"type":        "boolean",
+				"description": "Send the photo to the user; without a question, it isn't looked at",
+			},
+		},
+	}
+}
+
+func (t *CapturePhotoTool) SetContext(channel, chatID string) {
+	t.ctxMu.Lock()
+	defer t.ctxMu.Unlock()
+	t.defaultChannel = channel
+	t.defaultChatID = chatID
+}
+
+func (t *CapturePhotoTool) SetSendCallback(callback SendMediaCallback) {
+	t.sendCallback = callback
+}
+
+func (t *CapturePhotoTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	question, _ := args["question"].(string)
+	send, _ := args["send"].(bool)
+
+	t.mu.Lock()
+	photo, err := t.capture(ctx, t.camera)
+	t.mu.Unlock()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't take a photo: %v", err)).WithError(err)
+	}
+	path, err := t.save(photo)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't save the photo: %v", err)).WithError(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Photo (%dx%d) saved to %s.", photo.Width, photo.Height, path)
+	if send {
+		t.ctxMu.Lock()
+		channel, chatID := t.defaultChannel, t.defaultChatID
+		t.ctxMu.Unlock()
+		switch {
+		case t.sendCallback == nil || channel == "" || chatID == "":
+			sb.WriteString(" No chat to send it to.")
+		case t.sendCallback(channel, chatID, "", []string{path}) != nil:
+			sb.WriteString(" Sending it to the user failed.")
+		default:
+			sb.WriteString(" It was sent to the user; do not send it again.")
+		}
+		if strings.TrimSpace(question) == "" {
+			return SilentResult(sb.String())
+		}
+	}
+
+	answer, err := t.look(ctx, photo, question)
+	if err != nil {
+		fmt.Fprintf(&sb, " Looking at it failed: %v", err)
+		return ErrorResult(sb.String()).WithError(err)
+	}
+	sb.WriteString("\nWhat the photo shows: " + answer)
+	return SilentResult(sb.String())
+}
+
+// look asks the vision model question about photo.
+func (t *CapturePhotoTool) look(ctx context.Context, photo *camera.Photo, question string) (string, error) {
+	if t.vision == nil {
+		return "", errors.New("no vision model")
+	}
+	if strings.TrimSpace(question) == "" {
+		question = defaultPhotoQuestion
+	}
+	provider, model := t.vision()
+	resp, err := provider.Chat(ctx, []providers.Message{{
+		Role: "user",
+		Content: "This photo was just taken by the camera of the device you run on. " + question +
+			" If the photo is too dark or blurry to tell, say so.",
+		Images: []providers.ImagePart{{
+			MIMEType: photo.MIMEType,
+			Data:     base64.StdEncoding.EncodeToString(photo.Data),
+		}},
+	}}, nil, model, map[string]any{"max_tokens": 1024, "temperature": 0.2})
+	if err != nil {
+		return "", err
+	}
+	answer := strings.TrimSpace(resp.Content)
+	if answer == "" {
+		return "", errors.New("the model gave no answer (it may not accept images)")
+	}
+	return answer, nil
+}
+
+// save writes photo to <workspace>/photos and returns its path.
+func (t *CapturePhotoTool) save(photo *camera.Photo) (string, error) {
+	dir := filepath.Join(t.workspace, "photos")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405.000")+imageExtension(photo.MIMEType))
+	return path, os.WriteFile(path, photo.Data, 0o644)
+}
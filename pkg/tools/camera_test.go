@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/camera"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// visionProvider answers with a fixed text, recording the messages sent.
+type visionProvider struct {
+	answer   string
+	messages []providers.Message
+}
+
+func (p *visionProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.messages = messages
+	return &providers.LLMResponse{Content: p.answer}, nil
+}
+
+func (p *visionProvider) GetDefaultModel() string { return "vision-model" }
+
+func newTestCapturePhotoTool(t *testing.T, provider providers.LLMProvider) *CapturePhotoTool {
+	tool := NewCapturePhotoTool(CapturePhotoToolOptions{
+		Workspace: t.TempDir(),
+		Vision:    func() (providers.LLMProvider, string) { return provider, "vision-model" },
+	})
+	tool.capture = func(context.Context, camera.Options) (*camera.Photo, error) {
+		return &camera.Photo{Data: []byte("\xff\xd8photo"), MIMEType: "image/jpeg", Width: 640, Height: 480}, nil
+	}
+	return tool
+}
+
+func TestCapturePhotoTool_AsksVisionModel(t *testing.T) {
+	provider := &visionProvider{answer: "The garage door is open."}
+	tool := newTestCapturePhotoTool(t, provider)
+
+	result := tool.Execute(context.Background(), map[string]any{"question": "Is the garage door open?"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "The garage door is open.") || !strings.Contains(result.ForLLM, "640x480") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+	msg := provider.messages[0]
+	if !strings.Contains(msg.Content, "Is the garage door open?") {
+		t.Errorf("question not asked: %q", msg.Content)
+	}
+	if len(msg.Images) != 1 || msg.Images[0].MIMEType != "image/jpeg" || msg.Images[0].Data != "/9hwaG90bw==" {
+		t.Errorf("images = %+v", msg.Images)
+	}
+	entries, _ := os.ReadDir(tool.workspace + "/photos")
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".jpg") {
+		t.Errorf("photos saved = %v", entries)
+	}
+}
+
+func TestCapturePhotoTool_SendsWithoutLooking(t *testing.T) {
+	provider := &visionProvider{answer: "a cat"}
+	tool := newTestCapturePhotoTool(t, provider)
+	var sent []string
+	tool.SetSendCallback(func(channel, chatID, caption string, paths []string) error {
+		sent = append(sent, channel+":"+chatID)
+		sent = append(sent, paths...)
+		return nil
+	})
+	tool.SetContext("telegram", "42")
+
+	result := tool.Execute(context.Background(), map[string]any{"send": true})
+	if result.IsError || !strings.Contains(result.ForLLM, "sent to the user") {
+		t.Fatalf("result = %q", result.ForLLM)
+	}
+	if len(sent) != 2 || sent[0] != "telegram:42" || !strings.HasSuffix(sent[1], ".jpg") {
+		t.Errorf("sent = %v", sent)
+	}
+	if provider.messages != nil {
+		t.Error("the photo was looked at without a question")
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"send": true, "question": "What animal?"})
+	if !strings.Contains(result.ForLLM, "a cat") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+}
+
+func TestCapturePhotoTool_CameraError(t *testing.T) {
+	tool := newTestCapturePhotoTool(t, &visionProvider{})
+	tool.capture = func(context.Context, camera.Options) (*camera.Photo, error) {
+		return nil, errors.New("open /dev/video0: no such file or directory")
+	}
+	result := tool.Execute(context.Background(), map[string]any{})
+	if !result.IsError || !strings.Contains(result.ForLLM, "/dev/video0") {
+		t.Errorf("result = %+v", result)
+	}
+
+	tool = newTestCapturePhotoTool(t, &visionProvider{})
+	result = tool.Execute(context.Background(), map[string]any{})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no answer") {
+		t.Errorf("empty answer: result = %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/containers"
+)
+
+const (
+	defaultLogLines = 50
+	maxLogLines     = 500
+)
+
+// ContainersTool manages the Docker or Podman containers its client
+// allows: lists them, starts, stops and restarts them, and reads their
+// logs and resource use.
+type ContainersTool struct {
+	client   *containers.Client
+	readOnly bool
+}
+
+// NewContainersTool returns the tool of client. With readOnly, containers
+// can't be started, stopped or restarted.
+func NewContainersTool(client *containers.Client, readOnly bool) *ContainersTool {
+	return &ContainersTool{client: client, readOnly: readOnly}
+}
+
+func (t *ContainersTool) Name() string {
+	return "containers"
+}
+
+func (t *ContainersTool) Description() string {
+	desc := "Docker/Podman containers on this host. 'list' shows them with their state; 'logs' reads a " +
+		"container's latest output; 'stats' shows CPU, memory and network use of one or all running ones."
+	if !t.readOnly {
+		desc += " 'start', 'stop' and 'restart' act on a container."
+	}
+	return desc + " Only the containers the user allowed are visible."
+}
+
+func (t *ContainersTool) Parameters() map[string]any {
+	actions := []string{"list", "logs", "stats"}
+	if !t.readOnly {
+		actions = append(actions, "start", "stop", "restart")
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        actions,
+				"description": "What to do",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "The container's name (all but list; optional for stats)",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("How many of the latest log lines to read (default %d)", defaultLogLines),
+			},
+			"since": map[string]any{
+				"type":        "string",
+				"description": "Only logs this recent, as a duration, e.g. '30m' or '2h' (logs)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ContainersTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	switch action {
+	case "list":
+		return t.list(ctx)
+	case "logs":
+		if name == "" {
+			return ErrorResult("name is required to read logs")
+		}
+		return t.logs(ctx, name, args)
+	case "stats":
+		return t.stats(ctx, name)
+	case "start", "stop", "restart":
+		if t.readOnly {
+			return ErrorResult("acting on containers is turned off")
+		}
+		if name == "" {
+			return ErrorResult(fmt.Sprintf("name is required to %s a container", action))
+		}
+		return t.act(ctx, action, name)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *ContainersTool) list(ctx context.Context) *ToolResult {
+	list, err := t.client.List(ctx)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if len(list) == 0 {
+		return SilentResult("No containers are allowed, or none of them exist")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d containers:", len(list))
+	for _, c := range list {
+		fmt.Fprintf(&sb, "\n- %s (%s): %s, %s", c.Name, c.Image, c.State, c.Status)
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *ContainersTool) logs(ctx context.Context, name string, args map[string]any) *ToolResult {
+	lines := defaultLogLines
+	if n, ok := args["lines"].(float64); ok {
+		lines = min(max(int(n), 1), maxLogLines)
+	}
+	var since time.Time
+	if s, _ := args["since"].(string); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return ErrorResult(fmt.Sprintf("since %q is not a duration such as 30m or 2h", s))
+		}
+		since = time.Now().Add(-d)
+	}
+	logs, err := t.client.Logs(ctx, name, lines, since)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't read the logs of %s: %v", name, err)).WithError(err)
+	}
+	if strings.TrimSpace(logs) == "" {
+		return SilentResult(fmt.Sprintf("%s logged nothing", name))
+	}
+	return SilentResult(fmt.Sprintf("Latest logs of %s:\n%s", name, strings.TrimRight(logs, "\n")))
+}
+
+func (t *ContainersTool) stats(ctx context.Context, name string) *ToolResult {
+	names := []string{name}
+	if name == "" {
+		list, err := t.client.List(ctx)
+		if err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		names = names[:0]
+		for _, c := range list {
+			if c.State == "running" {
+				names = append(names, c.Name)
+			}
+		}
+		if len(names) == 0 {
+			return SilentResult("No allowed container is running")
+		}
+	}
+
+	// The engine takes a second or two to measure each container.
+	lines := make([]string, len(names))
+	var wg sync.WaitGroup
+	for i, n := range names {
+		wg.Go(func() {
+			s, err := t.client.Stats(ctx, n)
+			if err != nil {
+				lines[i] = fmt.Sprintf("- %s: %v", n, err)
+				return
+			}
+			mem := formatBytes(s.MemoryUsage)
+			if s.MemoryLimit > 0 {
+				mem += " of " + formatBytes(s.MemoryLimit)
+			}
+			lines[i] = fmt.Sprintf("- %s: %.1f%% CPU, %s memory, %d processes, network %s in, %s out",
+				n, s.CPUPercent, mem, s.PIDs, formatBytes(s.NetRx), formatBytes(s.NetTx))
+		})
+	}
+	wg.Wait()
+	return SilentResult("Resource use:\n" + strings.Join(lines, "\n"))
+}
+
+func (t *ContainersTool) act(ctx context.Context, action, name string) *ToolResult {
+	act := map[string]func(context.Context, string) (bool, error){
+		"start":   t.client.Start,
+		"stop":    t.client.Stop,
+		"restart": t.client.Restart,
+	}[action]
+	changed, err := act(ctx, name)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't %s %s: %v", action, name, err)).WithError(err)
+	}
+	if !changed {
+		if action == "start" {
+			return SilentResult(fmt.Sprintf("%s was already running", name))
+		}
+		return SilentResult(fmt.Sprintf("%s was already stopped", name))
+	}
+	past := map[string]string{"start": "Started", "stop": "Stopped", "restart": "Restarted"}[action]
+	return SilentResult(fmt.Sprintf("%s %s", past, name))
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/containers"
+)
+
+func newTestContainersTool(t *testing.T, readOnly bool) (*ContainersTool, *[]string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ctr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "engine.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posts []string
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/containers/json":
+			io.WriteString(w, `[
+				{"Id": "aaa", "Names": ["/web"], "Image": "nginx", "State": "running", "Status": "Up 2 days"},
+				{"Id": "bbb", "Names": ["/backup"], "Image": "restic", "State": "exited", "Status": "Exited (0)"},
+				{"Id": "ccc", "Names": ["/vault"], "Image": "vault", "State": "running", "Status": "Up 1 hour"}]`)
+		case r.URL.Path == "/containers/aaa/stats":
+			io.WriteString(w, `{"cpu_stats": {"cpu_usage": {"total_usage": 200}, "system_cpu_usage": 2000,
+				"online_cpus": 2}, "precpu_stats": {"cpu_usage": {"total_usage": 100}, "system_cpu_usage": 1000},
+				"memory_stats": {"usage": 52428800, "limit": 1073741824}, "pids_stats": {"current": 3}}`)
+		case r.Method == http.MethodPost:
+			posts = append(posts, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})}
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return NewContainersTool(containers.NewClient(socket, []string{"web", "backup"}), readOnly), &posts
+}
+
+func TestContainersTool_ListAndStats(t *testing.T) {
+	tool, _ := newTestContainersTool(t, false)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "list"})
+	want := "2 containers:\n- backup (restic): exited, Exited (0)\n- web (nginx): running, Up 2 days"
+	if result.ForLLM != want {
+		t.Errorf("list = %q, want %q", result.ForLLM, want)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "stats"})
+	want = "Resource use:\n- web: 20.0% CPU, 50.0 MB of 1.0 GB memory, 3 processes, network 0 B in, 0 B out"
+	if result.ForLLM != want {
+		t.Errorf("stats = %q, want %q", result.ForLLM, want)
+	}
+}
+
+func TestContainersTool_Actions(t *testing.T) {
+	tool, posts := newTestContainersTool(t, false)
+	ctx := context.Background()
+
+	if result := tool.Execute(ctx, map[string]any{"action": "restart", "name": "web"}); result.ForLLM != "Restarted web" {
+		t.Errorf("restart = %q", result.ForLLM)
+	}
+	result := tool.Execute(ctx, map[string]any{"action": "stop", "name": "vault"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not allowed") {
+		t.Errorf("stop vault = %q", result.ForLLM)
+	}
+	if len(*posts) != 1 || (*posts)[0] != "/containers/aaa/restart" {
+		t.Errorf("posts = %v", *posts)
+	}
+
+	readOnly, posts := newTestContainersTool(t, true)
+	if result := readOnly.Execute(ctx, map[string]any{"action": "start", "name": "backup"}); !result.IsError {
+		t.Error("start allowed in read-only mode")
+	}
+	if len(*posts) != 0 {
+		t.Errorf("posts = %v", *posts)
+	}
+	result = readOnly.Execute(ctx, map[string]any{"action": "logs", "name": "web", "since": "yesterday"})
+	if !result.IsError {
+		t.Error("bad since accepted")
+	}
+}
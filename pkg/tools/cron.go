@@ -51,7 +51,7 @@ func (t *CronTool) Name() string {
 
 // Description returns the tool description
 func (t *CronTool) Description() string {
-	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600). Use 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200). Use 'cron_expr' for complex recurring schedules. Use 'command' to execute shell commands directly."
+	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Use 'schedule' with the user's own words for when it runs (e.g., 'every morning at 8', 'every weekday at 9:30am', 'tomorrow at 7pm', 'in 10 minutes'). Alternatively use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600), 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200), or 'cron_expr' for complex recurring schedules. Set 'tz' to the user's time zone when you know it. Use 'command' to execute shell commands directly. Use 'list' to show jobs and 'remove' to cancel one."
 }
 
 // Parameters returns the tool parameters schema
@@ -72,6 +72,19 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Optional: Shell command to execute directly (e.g., 'df -h'). If set, the agent will run this command and report output instead of just showing the message. 'deliver' will be forced to false for commands.",
 			},
+			"schedule": map[string]any{
+				"type":        "string",
+				"description": "When the job runs, in plain English (e.g., 'every day at 8:00', 'every monday and friday at 18:00', 'every 2 hours', 'tomorrow at 7pm', 'in 20 minutes') or as a cron expression.",
+			},
+			"tz": map[string]any{
+				"type":        "string",
+				"description": "IANA time zone for the times in 'schedule' and 'cron_expr' (e.g., 'Europe/Paris'). Default: the server's.",
+			},
+			"catch_up": map[string]any{
+				"type":        "string",
+				"enum":        []string{cron.CatchUpOnce, cron.CatchUpSkip},
+				"description": "If runs are missed while the assistant is down: 'once' runs the job once when it's back, 'skip' waits for the next run. Default: the configured policy.",
+			},
 			"at_seconds": map[string]any{
 				"type":        "integer",
 				"description": "One-time reminder: seconds from now when to trigger (e.g., 600 for 10 minutes later). Use this for one-time reminders like 'remind me in 10 minutes'.",
@@ -145,13 +158,29 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 
 	var schedule cron.CronSchedule
 
-	// Check for at_seconds (one-time), every_seconds (recurring), or cron_expr
+	tz, _ := args["tz"].(string)
+	loc := time.Local
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return ErrorResult(fmt.Sprintf("unknown time zone %q", tz))
+		}
+	}
+
+	// Check for schedule (plain English), at_seconds (one-time),
+	// every_seconds (recurring), or cron_expr
+	text, hasText := args["schedule"].(string)
 	atSeconds, hasAt := args["at_seconds"].(float64)
 	everySeconds, hasEvery := args["every_seconds"].(float64)
 	cronExpr, hasCron := args["cron_expr"].(string)
 
-	// Priority: at_seconds > every_seconds > cron_expr
-	if hasAt {
+	// Priority: schedule > at_seconds > every_seconds > cron_expr
+	if hasText && text != "" {
+		var err error
+		if schedule, err = cron.ParseSchedule(text, time.Now().In(loc)); err != nil {
+			return ErrorResult(fmt.Sprintf("can't understand schedule: %v", err))
+		}
+	} else if hasAt {
 		atMS := time.Now().UnixMilli() + int64(atSeconds)*1000
 		schedule = cron.CronSchedule{
 			Kind: "at",
@@ -169,7 +198,18 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 			Expr: cronExpr,
 		}
 	} else {
-		return ErrorResult("one of at_seconds, every_seconds, or cron_expr is required")
+		return ErrorResult("one of schedule, at_seconds, every_seconds, or cron_expr is required")
+	}
+	if schedule.Kind == "cron" {
+		schedule.TZ = tz
+	}
+	if err := cron.ValidateSchedule(schedule); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid schedule: %v", err))
+	}
+
+	catchUp, _ := args["catch_up"].(string)
+	if catchUp != "" && catchUp != cron.CatchUpOnce && catchUp != cron.CatchUpSkip {
+		return ErrorResult("catch_up must be 'once' or 'skip'")
 	}
 
 	// Read deliver parameter, default to true
@@ -202,13 +242,15 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
 	}
 
-	if command != "" {
+	if command != "" || catchUp != "" {
 		job.Payload.Command = command
+		job.CatchUp = catchUp
 		// Need to save the updated payload
 		t.cronService.UpdateJob(job)
 	}
 
-	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
+	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s, %s%s)",
+		job.Name, job.ID, cron.DescribeSchedule(job.Schedule), nextRun(job, loc)))
 }
 
 func (t *CronTool) listJobs() *ToolResult {
@@ -220,22 +262,25 @@ func (t *CronTool) listJobs() *ToolResult {
 
 	result := "Scheduled jobs:\n"
 	for _, j := range jobs {
-		var scheduleInfo string
-		if j.Schedule.Kind == "every" && j.Schedule.EveryMS != nil {
-			scheduleInfo = fmt.Sprintf("every %ds", *j.Schedule.EveryMS/1000)
-		} else if j.Schedule.Kind == "cron" {
-			scheduleInfo = j.Schedule.Expr
-		} else if j.Schedule.Kind == "at" {
-			scheduleInfo = "one-time"
-		} else {
-			scheduleInfo = "unknown"
+		loc := time.Local
+		if l, err := time.LoadLocation(j.Schedule.TZ); err == nil && j.Schedule.TZ != "" {
+			loc = l
 		}
-		result += fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo)
+		result += fmt.Sprintf("- %s (id: %s, %s%s)\n", j.Name, j.ID, cron.DescribeSchedule(j.Schedule), nextRun(&j, loc))
 	}
 
 	return SilentResult(result)
 }
 
+// nextRun returns ", next run <time>" for job, in loc, or "" when it has
+// no next run.
+func nextRun(job *cron.CronJob, loc *time.Location) string {
+	if job.State.NextRunAtMS == nil {
+		return ""
+	}
+	return ", next run " + time.UnixMilli(*job.State.NextRunAtMS).In(loc).Format("Mon 2006-01-02 15:04")
+}
+
 func (t *CronTool) removeJob(args map[string]any) *ToolResult {
 	jobID, ok := args["job_id"].(string)
 	if !ok || jobID == "" {
@@ -266,6 +311,26 @@ func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
+// lateNote returns " (due at <time>)" for a reminder delivered well after
+// its time, because picoclaw was down, or "".
+func lateNote(job *cron.CronJob, now time.Time) string {
+	if job.Payload.Kind != ReminderKind || job.Schedule.AtMS == nil {
+		return ""
+	}
+	due := time.UnixMilli(*job.Schedule.AtMS)
+	if now.Sub(due) < time.Minute {
+		return ""
+	}
+	if loc, err := time.LoadLocation(job.Schedule.TZ); err == nil && job.Schedule.TZ != "" {
+		due = due.In(loc)
+	}
+	layout := "15:04"
+	if now.In(due.Location()).YearDay() != due.YearDay() {
+		layout = "Mon 2 Jan 15:04"
+	}
+	return " (due at " + due.Format(layout) + ")"
+}
+
 // ExecuteJob executes a cron job through the agent
 func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	// Get channel/chatID from job payload
@@ -307,7 +372,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 		t.msgBus.PublishOutbound(bus.OutboundMessage{
 			Channel: channel,
 			ChatID:  chatID,
-			Content: job.Payload.Message,
+			Content: job.Payload.Message + lateNote(job, time.Now()),
 		})
 		return "ok"
 	}
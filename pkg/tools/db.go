@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/database"
+)
+
+const (
+	defaultDBTimeout = 30 * time.Second
+	maxDBCellChars   = 200
+)
+
+// DBToolOptions configures a DBTool; zero values take the defaults.
+type DBToolOptions struct {
+	MaxRows   int
+	MaxOutput int // Characters
+	Timeout   time.Duration
+}
+
+// DBTool runs queries on the configured databases, lists their tables and
+// describes their columns. Values are bound as parameters, and databases
+// are read-only unless writes are allowed.
+type DBTool struct {
+	dbs  []*database.DB
+	opts DBToolOptions
+}
+
+func NewDBTool(dbs []*database.DB, opts DBToolOptions) *DBTool {
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = database.DefaultMaxRows
+	}
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = defaultExecMaxOutput
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultDBTimeout
+	}
+	return &DBTool{dbs: dbs, opts: opts}
+}
+
+func (t *DBTool) Name() string {
+	return "db"
+}
+
+func (t *DBTool) Description() string {
+	dbs := make([]string, len(t.dbs))
+	for i, db := range t.dbs {
+		access := "read-only"
+		if !db.ReadOnly() {
+			access = "read-write"
+		}
+		dbs[i] = fmt.Sprintf("%s (%s, %s)", db.Name(), db.Type(), access)
+	}
+	return "Query the user's databases with SQL: 'tables' lists the tables, 'describe' the columns of one, " +
+		"and 'query' runs a single statement. Put values in 'args' and refer to them with placeholders, " +
+		"? for sqlite and mysql, $1, $2... for postgres, rather than writing them into the SQL. Read-only " +
+		fmt.Sprintf("databases only run SELECT, WITH, SHOW and EXPLAIN. At most %d rows are returned. ", t.opts.MaxRows) +
+		"Databases: " + strings.Join(dbs, "; ") + "."
+}
+
+func (t *DBTool) Parameters() map[string]any {
+	names := make([]string, len(t.dbs))
+	for i, db := range t.dbs {
+		names[i] = db.Name()
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"query", "tables", "describe"},
+				"description": "What to do",
+			},
+			"database": map[string]any{
+				"type":        "string",
+				"enum":        names,
+				"description": "The database",
+			},
+			"sql": map[string]any{
+				"type":        "string",
+				"description": "query: the statement to run",
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{},
+				"description": "query: the values of the statement's placeholders, in order",
+			},
+			"table": map[string]any{
+				"type":        "string",
+				"description": "describe: the table or view (schema.table in postgres)",
+			},
+		},
+		"required": []string{"action", "database"},
+	}
+}
+
+func (t *DBTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	name, _ := args["database"].(string)
+	var db *database.DB
+	for _, d := range t.dbs {
+		if d.Name() == name {
+			db = d
+		}
+	}
+	if db == nil {
+		return ErrorResult(fmt.Sprintf("unknown database %q", name))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	defer cancel()
+	var result *database.Result
+	var err error
+	action, _ := args["action"].(string)
+	switch action {
+	case "query":
+		query, _ := args["sql"].(string)
+		if strings.TrimSpace(query) == "" {
+			return ErrorResult("sql is required to run a query")
+		}
+		params, _ := args["args"].([]any)
+		for i, p := range params {
+			switch p.(type) {
+			case map[string]any, []any:
+				encoded, _ := json.Marshal(p)
+				params[i] = string(encoded)
+			}
+		}
+		result, err = db.Query(ctx, query, params, t.opts.MaxRows)
+	case "tables":
+		result, err = db.Tables(ctx)
+	case "describe":
+		table, _ := args["table"].(string)
+		result, err = db.Describe(ctx, table)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorResult(fmt.Sprintf("The query on %s timed out after %v", name, t.opts.Timeout))
+	case err != nil:
+		return ErrorResult(fmt.Sprintf("%s: %v", name, err)).WithError(err)
+	}
+	return SilentResult(t.format(result))
+}
+
+// format renders a result as a table with a header line, one row per
+// line and cells separated by " | ".
+func (t *DBTool) format(result *database.Result) string {
+	if len(result.Columns) == 0 {
+		return fmt.Sprintf("OK, %d rows affected", result.RowsAffected)
+	}
+	var sb strings.Builder
+	switch {
+	case result.More:
+		fmt.Fprintf(&sb, "The first %d rows (more were not fetched; narrow the query or add a LIMIT):\n",
+			len(result.Rows))
+	case len(result.Rows) == 1:
+		sb.WriteString("1 row:\n")
+	default:
+		fmt.Fprintf(&sb, "%d rows:\n", len(result.Rows))
+	}
+	sb.WriteString(strings.Join(result.Columns, " | "))
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = formatCell(v)
+		}
+		sb.WriteString("\n" + strings.Join(cells, " | "))
+	}
+
+	output := sb.String()
+	if len(output) > t.opts.MaxOutput {
+		cut := t.opts.MaxOutput
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		output = output[:cut] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-cut)
+	}
+	return output
+}
+
+func formatCell(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		s = v
+	case []byte:
+		if !utf8.Valid(v) {
+			if len(v) > 32 {
+				return fmt.Sprintf("<%d bytes>", len(v))
+			}
+			return "0x" + hex.EncodeToString(v)
+		}
+		s = string(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.NewReplacer("\r", `\r`, "\n", `\n`, "|", `\|`).Replace(s)
+	if utf8.RuneCountInString(s) > maxDBCellChars {
+		s = string([]rune(s)[:maxDBCellChars]) + "..."
+	}
+	return s
+}
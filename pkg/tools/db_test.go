@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/database"
+)
+
+func newTestDBTool(t *testing.T, opts DBToolOptions) (*DBTool, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "shop.db")
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = setup.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY, customer TEXT NOT NULL, note TEXT, data BLOB);
+		INSERT INTO orders VALUES (1, 'ada', 'first|order' || char(10) || 'rush', x'00ff');
+		INSERT INTO orders VALUES (2, 'bob', NULL, NULL);
+		INSERT INTO orders VALUES (3, 'ada', NULL, NULL);`)
+	setup.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dbs []*database.DB
+	for _, o := range []database.Options{
+		{Name: "shop", Type: database.SQLite, Path: path},
+		{Name: "shop_rw", Type: database.SQLite, Path: path, AllowWrites: true},
+	} {
+		db, err := database.Open(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		dbs = append(dbs, db)
+	}
+	return NewDBTool(dbs, opts), path
+}
+
+func TestDBTool_Query(t *testing.T) {
+	tool, _ := newTestDBTool(t, DBToolOptions{})
+	ctx := context.Background()
+
+	if desc := tool.Description(); !strings.Contains(desc, "shop (sqlite, read-only); shop_rw (sqlite, read-write)") {
+		t.Errorf("Description() = %q", desc)
+	}
+
+	result := tool.Execute(ctx, map[string]any{
+		"action":   "query",
+		"database": "shop",
+		"sql":      "SELECT id, customer, note, data FROM orders WHERE customer = ? ORDER BY id",
+		"args":     []any{"ada"},
+	})
+	want := "2 rows:\nid | customer | note | data\n1 | ada | first\\|order\\nrush | 0x00ff\n3 | ada | NULL | NULL"
+	if result.IsError || result.ForLLM != want {
+		t.Errorf("query = %q, want %q", result.ForLLM, want)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action":   "query",
+		"database": "shop",
+		"sql":      "SELECT id FROM orders WHERE id >= ?",
+		"args":     []any{float64(2)},
+	})
+	if result.IsError || !strings.HasPrefix(result.ForLLM, "2 rows:\nid\n2\n3") {
+		t.Errorf("query with a JSON number = %q", result.ForLLM)
+	}
+}
+
+func TestDBTool_Limits(t *testing.T) {
+	tool, _ := newTestDBTool(t, DBToolOptions{MaxRows: 2})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "query", "database": "shop", "sql": "SELECT id FROM orders"})
+	if result.IsError || !strings.HasPrefix(result.ForLLM, "The first 2 rows (more were not fetched") {
+		t.Errorf("row limit = %q", result.ForLLM)
+	}
+
+	tool.opts.MaxOutput = 30
+	result = tool.Execute(ctx, map[string]any{"action": "query", "database": "shop", "sql": "SELECT * FROM orders"})
+	if !strings.Contains(result.ForLLM, "... (truncated,") {
+		t.Errorf("output limit = %q", result.ForLLM)
+	}
+}
+
+func TestDBTool_ReadOnly(t *testing.T) {
+	tool, _ := newTestDBTool(t, DBToolOptions{})
+	ctx := context.Background()
+
+	for _, query := range []string{
+		"DELETE FROM orders",
+		"SELECT 1; DROP TABLE orders",
+	} {
+		result := tool.Execute(ctx, map[string]any{"action": "query", "database": "shop", "sql": query})
+		if !result.IsError {
+			t.Errorf("%q on a read-only database = %q, want an error", query, result.ForLLM)
+		}
+	}
+
+	result := tool.Execute(ctx, map[string]any{
+		"action":   "query",
+		"database": "shop_rw",
+		"sql":      "UPDATE orders SET note = ? WHERE customer = ?",
+		"args":     []any{"paid", "ada"},
+	})
+	if result.IsError || result.ForLLM != "OK, 2 rows affected" {
+		t.Errorf("update = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "query", "database": "stock", "sql": "SELECT 1"})
+	if !result.IsError || !strings.Contains(result.ForLLM, `unknown database "stock"`) {
+		t.Errorf("unknown database = %q", result.ForLLM)
+	}
+}
+
+func TestDBTool_Schema(t *testing.T) {
+	tool, _ := newTestDBTool(t, DBToolOptions{})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "tables", "database": "shop"})
+	if result.IsError || result.ForLLM != "1 row:\nname | type\norders | table" {
+		t.Errorf("tables = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "describe", "database": "shop", "table": "orders"})
+	want := "\nid | INTEGER | YES | NULL | PRI\ncustomer | TEXT | NO | NULL | \n"
+	if result.IsError || !strings.Contains(result.ForLLM, want) {
+		t.Errorf("describe = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "describe", "database": "shop", "table": "customers"})
+	if !result.IsError {
+		t.Errorf("describe of a missing table = %q, want an error", result.ForLLM)
+	}
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Tools a delegated subagent doesn't get unless asked for by name: it
+// reports to its parent rather than the user, and doesn't start background
+// work the parent can't wait for.
+var delegateExcludedTools = []string{"message", "spawn", "subagent"}
+
+const delegateSystemPrompt = `You are a subagent working on one subtask for another agent.
+Complete the task with the tools you have, then reply with a concise summary of what you found or did.
+The summary is all the other agent sees: include the facts, file paths and sources it needs, and say plainly if something could not be done.`
+
+// DelegateOptions limits delegation.
+type DelegateOptions struct {
+	MaxDepth      int // Nesting levels: 1 lets the agent delegate, but not its subagents
+	MaxIterations int // LLM calls per subagent
+	TokenBudget   int // Tokens per subagent; 0 means no limit
+	LLMOptions    map[string]any
+	MaxParallel   int
+}
+
+type delegateDepthKey struct{}
+
+// delegateDepth returns how many delegations deep ctx is.
+func delegateDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(delegateDepthKey{}).(int)
+	return depth
+}
+
+// DelegateTool runs a subtask in a subagent with its own context and a
+// subset of the parent's tools, and returns the subagent's summary. Unlike
+// spawn, it waits for the result.
+type DelegateTool struct {
+	provider      providers.LLMProvider
+	model         string
+	parent        *ToolRegistry
+	opts          DelegateOptions
+	originChannel string
+	originChatID  string
+}
+
+func NewDelegateTool(
+	provider providers.LLMProvider,
+	model string,
+	parent *ToolRegistry,
+	opts DelegateOptions,
+) *DelegateTool {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 1
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 10
+	}
+	return &DelegateTool{
+		provider:      provider,
+		model:         model,
+		parent:        parent,
+		opts:          opts,
+		originChannel: "cli",
+		originChatID:  "direct",
+	}
+}
+
+func (t *DelegateTool) Name() string {
+	return "delegate"
+}
+
+func (t *DelegateTool) Description() string {
+	return "Hand a self-contained subtask (e.g. in-depth research) to a subagent with its own context and only the tools it needs. Waits for the subagent and returns its summary, keeping the intermediate steps out of your context."
+}
+
+func (t *DelegateTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"task": map[string]any{
+				"type":        "string",
+				"description": "The subtask, with everything the subagent needs to know: it can't see this conversation",
+			},
+			"tools": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Names of your tools the subagent may use. Defaults to all of them except message and spawn",
+			},
+		},
+		"required": []string{"task"},
+	}
+}
+
+func (t *DelegateTool) SetContext(channel, chatID string) {
+	t.originChannel = channel
+	t.originChatID = chatID
+}
+
+func (t *DelegateTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	task, ok := args["task"].(string)
+	if !ok || strings.TrimSpace(task) == "" {
+		return ErrorResult("task is required and must be a non-empty string")
+	}
+
+	depth := delegateDepth(ctx)
+	if depth >= t.opts.MaxDepth {
+		return ErrorResult(fmt.Sprintf(
+			"delegation depth limit reached (%d); do this task yourself", t.opts.MaxDepth))
+	}
+
+	var names []string
+	if raw, ok := args["tools"].([]any); ok {
+		for _, v := range raw {
+			if name, ok := v.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	toolset, err := t.toolset(names, depth+1)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	messages := []providers.Message{
+		{Role: "system", Content: delegateSystemPrompt},
+		{Role: "user", Content: task},
+	}
+	loopResult, err := RunToolLoop(context.WithValue(ctx, delegateDepthKey{}, depth+1), ToolLoopConfig{
+		Provider:         t.provider,
+		Model:            t.model,
+		Tools:            toolset,
+		MaxIterations:    t.opts.MaxIterations,
+		LLMOptions:       t.opts.LLMOptions,
+		MaxParallelTools: t.opts.MaxParallel,
+		TokenBudget:      t.opts.TokenBudget,
+	}, messages, t.originChannel, t.originChatID)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Subagent failed: %v", err)).WithError(err)
+	}
+
+	summary := strings.TrimSpace(loopResult.Content)
+	switch {
+	case loopResult.OverBudget:
+		summary = fmt.Sprintf("The subagent used up its budget of %d tokens before finishing. Its last note:\n%s",
+			t.opts.TokenBudget, orNone(summary))
+	case summary == "":
+		summary = fmt.Sprintf("The subagent did not finish within %d steps.", loopResult.Iterations)
+	}
+	return SilentResult(fmt.Sprintf("Subagent result (%d steps, %d tokens):\n%s",
+		loopResult.Iterations, loopResult.Tokens, summary))
+}
+
+// toolset returns the subagent's tools: the named tools of the parent, or
+// all but delegateExcludedTools. delegate itself is only included while the
+// subagent at depth may delegate further.
+func (t *DelegateTool) toolset(names []string, depth int) (*ToolRegistry, error) {
+	if len(names) == 0 {
+		for _, name := range t.parent.List() {
+			if !slices.Contains(delegateExcludedTools, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	toolset := NewToolRegistry()
+	var unknown []string
+	for _, name := range names {
+		if name == t.Name() && depth >= t.opts.MaxDepth {
+			continue
+		}
+		tool, ok := t.parent.Get(name)
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		toolset.Register(tool)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown tools: %s; available: %s",
+			strings.Join(unknown, ", "), strings.Join(t.parent.List(), ", "))
+	}
+	return toolset, nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// scriptedProvider returns its responses in order, recording the tools
+// offered on each call.
+type scriptedProvider struct {
+	responses []*providers.LLMResponse
+	toolSets  [][]string
+}
+
+func (p *scriptedProvider) Chat(
+	_ context.Context,
+	_ []providers.Message,
+	tools []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	var names []string
+	for _, def := range tools {
+		names = append(names, def.Function.Name)
+	}
+	p.toolSets = append(p.toolSets, names)
+	resp := p.responses[0]
+	if len(p.responses) > 1 {
+		p.responses = p.responses[1:]
+	}
+	return resp, nil
+}
+
+func (p *scriptedProvider) GetDefaultModel() string { return "test-model" }
+
+func callTool(name string, tokens int) *providers.LLMResponse {
+	return &providers.LLMResponse{
+		Content:   "looking into it",
+		ToolCalls: []providers.ToolCall{{ID: "call_1", Name: name, Arguments: map[string]any{}}},
+		Usage:     &providers.UsageInfo{TotalTokens: tokens},
+	}
+}
+
+func newDelegateParent() *ToolRegistry {
+	r := NewToolRegistry()
+	for _, name := range []string{"web_fetch", "read_file", "message", "spawn"} {
+		r.Register(newMockTool(name, name))
+	}
+	return r
+}
+
+func TestDelegateTool_ReturnsSummaryWithRestrictedTools(t *testing.T) {
+	parent := newDelegateParent()
+	provider := &scriptedProvider{responses: []*providers.LLMResponse{
+		callTool("web_fetch", 100),
+		{Content: "Go 1.24 adds generic type aliases.", Usage: &providers.UsageInfo{TotalTokens: 50}},
+	}}
+	tool := NewDelegateTool(provider, "test-model", parent, DelegateOptions{})
+	parent.Register(tool)
+
+	result := tool.Execute(context.Background(), map[string]any{"task": "What's new in Go 1.24?"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !result.Silent || !strings.Contains(result.ForLLM, "generic type aliases") ||
+		!strings.Contains(result.ForLLM, "150 tokens") {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Neither message, spawn nor (at the depth limit) delegate is offered.
+	if got := strings.Join(provider.toolSets[0], ","); got != "read_file,web_fetch" {
+		t.Errorf("subagent tools = %s, want read_file,web_fetch", got)
+	}
+}
+
+func TestDelegateTool_NamedTools(t *testing.T) {
+	parent := newDelegateParent()
+	provider := &scriptedProvider{responses: []*providers.LLMResponse{{Content: "done"}}}
+	tool := NewDelegateTool(provider, "test-model", parent, DelegateOptions{MaxDepth: 2})
+	parent.Register(tool)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"task":  "read the notes",
+		"tools": []any{"read_file", "delegate"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if got := strings.Join(provider.toolSets[0], ","); got != "delegate,read_file" {
+		t.Errorf("subagent tools = %s, want delegate,read_file", got)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"task": "x", "tools": []any{"exec"}})
+	if !result.IsError || !strings.Contains(result.ForLLM, "unknown tools: exec") {
+		t.Errorf("unknown tool: result = %+v", result)
+	}
+}
+
+func TestDelegateTool_DepthLimit(t *testing.T) {
+	parent := newDelegateParent()
+	// The subagent tries to delegate again.
+	provider := &scriptedProvider{responses: []*providers.LLMResponse{
+		{
+			ToolCalls: []providers.ToolCall{{
+				ID: "call_1", Name: "delegate", Arguments: map[string]any{"task": "deeper"},
+			}},
+		},
+		{Content: "did it myself"},
+	}}
+	tool := NewDelegateTool(provider, "test-model", parent, DelegateOptions{MaxDepth: 1})
+	parent.Register(tool)
+
+	ctx := context.WithValue(context.Background(), delegateDepthKey{}, 1)
+	result := tool.Execute(ctx, map[string]any{"task": "anything"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "depth limit") {
+		t.Errorf("result = %+v, want the depth limit error", result)
+	}
+	if len(provider.toolSets) != 0 {
+		t.Error("no subagent should run past the depth limit")
+	}
+}
+
+func TestDelegateTool_TokenBudget(t *testing.T) {
+	parent := newDelegateParent()
+	provider := &scriptedProvider{responses: []*providers.LLMResponse{callTool("web_fetch", 600)}}
+	tool := NewDelegateTool(provider, "test-model", parent, DelegateOptions{TokenBudget: 1000})
+
+	result := tool.Execute(context.Background(), map[string]any{"task": "research forever"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if len(provider.toolSets) != 2 {
+		t.Errorf("LLM calls = %d, want 2 before the budget runs out", len(provider.toolSets))
+	}
+	if !strings.Contains(result.ForLLM, "used up its budget of 1000 tokens") ||
+		!strings.Contains(result.ForLLM, "looking into it") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+}
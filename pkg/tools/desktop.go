@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/desktop"
+)
+
+// ClipboardTool reads and sets the clipboard of the desktop picoclaw runs
+// on.
+type ClipboardTool struct {
+	maxChars int
+}
+
+func NewClipboardTool(maxChars int) *ClipboardTool {
+	if maxChars <= 0 {
+		maxChars = defaultExecMaxOutput
+	}
+	return &ClipboardTool{maxChars: maxChars}
+}
+
+func (t *ClipboardTool) Name() string {
+	return "clipboard"
+}
+
+func (t *ClipboardTool) Description() string {
+	return "Read the text on the user's desktop clipboard, e.g. to summarize or translate what they copied, " +
+		"or put text on it for them to paste."
+}
+
+func (t *ClipboardTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"read", "write"},
+				"description": "read the clipboard or write text to it",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The text to put on the clipboard (for write)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ClipboardTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "read":
+		text, err := desktop.ReadClipboard(ctx)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Error reading the clipboard: %v", err)).WithError(err)
+		}
+		if strings.TrimSpace(text) == "" {
+			return SilentResult("The clipboard is empty.")
+		}
+		if len(text) > t.maxChars {
+			cut := t.maxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + fmt.Sprintf("\n... (truncated, %d more chars)", len(text)-cut)
+		}
+		return SilentResult(text)
+	case "write":
+		text, _ := args["text"].(string)
+		if text == "" {
+			return ErrorResult("text is required for write")
+		}
+		if err := desktop.WriteClipboard(ctx, text); err != nil {
+			return ErrorResult(fmt.Sprintf("Error setting the clipboard: %v", err)).WithError(err)
+		}
+		return SilentResult(fmt.Sprintf("Copied %d characters to the clipboard", utf8.RuneCountInString(text)))
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// NotifyTool posts notifications on the desktop picoclaw runs on.
+type NotifyTool struct{}
+
+func NewNotifyTool() *NotifyTool {
+	return &NotifyTool{}
+}
+
+func (t *NotifyTool) Name() string {
+	return "notify"
+}
+
+func (t *NotifyTool) Description() string {
+	return "Post a notification on the user's desktop, for something they should see even when not looking " +
+		"at the chat. To nudge them later, add a cron job with deliver=false whose message asks to post the " +
+		"notification."
+}
+
+func (t *NotifyTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title": map[string]any{
+				"type":        "string",
+				"description": "A short title",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "The text of the notification",
+			},
+			"urgency": map[string]any{
+				"type": "string",
+				"enum": []string{desktop.UrgencyLow, desktop.UrgencyNormal, desktop.UrgencyCritical},
+				"description": "critical notifications stay on screen until dismissed; use it sparingly. " +
+					"Default: normal",
+			},
+		},
+		"required": []string{"title"},
+	}
+}
+
+func (t *NotifyTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	title, _ := args["title"].(string)
+	message, _ := args["message"].(string)
+	urgency, _ := args["urgency"].(string)
+	if strings.TrimSpace(title) == "" {
+		return ErrorResult("title is required")
+	}
+	switch urgency {
+	case "", desktop.UrgencyLow, desktop.UrgencyNormal, desktop.UrgencyCritical:
+	default:
+		return ErrorResult(fmt.Sprintf("unknown urgency %q; use low, normal or critical", urgency))
+	}
+
+	err := desktop.Notify(ctx, desktop.Notification{Title: title, Message: message, Urgency: urgency})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error posting the notification: %v", err)).WithError(err)
+	}
+	return SilentResult("Notification posted")
+}
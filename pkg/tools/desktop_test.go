@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func fakeDesktopCommands(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("the commands faked are Linux ones")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("WAYLAND_DISPLAY", "")
+	return dir
+}
+
+func TestClipboardTool(t *testing.T) {
+	dir := fakeDesktopCommands(t, map[string]string{
+		"xclip": `case "$*" in *-o*) printf 'héllo wörld';; *) exec /bin/cat > "$0.in";; esac`,
+	})
+	ctx := context.Background()
+
+	result := NewClipboardTool(0).Execute(ctx, map[string]any{"action": "read"})
+	if result.IsError || result.ForLLM != "héllo wörld" {
+		t.Errorf("read = %q", result.ForLLM)
+	}
+	result = NewClipboardTool(2).Execute(ctx, map[string]any{"action": "read"})
+	if result.ForLLM != "h\n... (truncated, 12 more chars)" {
+		t.Errorf("truncated read = %q", result.ForLLM)
+	}
+
+	result = NewClipboardTool(0).Execute(ctx, map[string]any{"action": "write", "text": "summary"})
+	if result.IsError || result.ForLLM != "Copied 7 characters to the clipboard" {
+		t.Errorf("write = %q", result.ForLLM)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "xclip.in")); string(data) != "summary" {
+		t.Errorf("xclip got %q", data)
+	}
+
+	result = NewClipboardTool(0).Execute(ctx, map[string]any{"action": "write"})
+	if !result.IsError {
+		t.Errorf("write without text = %q, want an error", result.ForLLM)
+	}
+}
+
+func TestNotifyTool(t *testing.T) {
+	dir := fakeDesktopCommands(t, map[string]string{
+		"notify-send": `echo "$@" > "$0.args"`,
+	})
+	tool := NewNotifyTool()
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"title": "Stretch", "message": "Time for a break", "urgency": "low"})
+	if result.IsError {
+		t.Fatalf("notify: %s", result.ForLLM)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notify-send.args"))
+	if got := strings.TrimSpace(string(data)); got != "--app-name=picoclaw --urgency=low -- Stretch Time for a break" {
+		t.Errorf("notify-send args = %q", got)
+	}
+
+	for _, args := range []map[string]any{
+		{"message": "no title"},
+		{"title": "Stretch", "urgency": "urgent"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("notify %v = %q, want an error", args, result.ForLLM)
+		}
+	}
+}
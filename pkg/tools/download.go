@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultDownloadMaxSize = 200 << 20
+	defaultDownloadTimeout = 10 * time.Minute
+	defaultDownloadDir     = "downloads"
+)
+
+// DownloadToolOptions configures a DownloadTool; zero values take the
+// defaults.
+type DownloadToolOptions struct {
+	Workspace  string
+	Restrict   bool
+	AllowPaths []string // Outside the workspace, as for the file tools
+	MaxSize    int64    // Bytes
+	AllowTypes []string // Media type globs; any when empty
+	Timeout    time.Duration
+	Proxy      string
+}
+
+// DownloadTool saves files from http(s) URLs, such as release archives, up
+// to a size and of the allowed media types, optionally checking their
+// SHA-256.
+type DownloadTool struct {
+	opts DownloadToolOptions
+}
+
+func NewDownloadTool(opts DownloadToolOptions) *DownloadTool {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultDownloadMaxSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultDownloadTimeout
+	}
+	return &DownloadTool{opts: opts}
+}
+
+func (t *DownloadTool) Name() string {
+	return "download"
+}
+
+func (t *DownloadTool) Description() string {
+	desc := fmt.Sprintf("Download a file from an http(s) URL, e.g. a release archive, up to %s. It is saved to "+
+		"'path', or to %s/ in the workspace. Give 'sha256' when the checksum is published, to check the file.",
+		formatBytes(uint64(t.opts.MaxSize)), defaultDownloadDir)
+	if len(t.opts.AllowTypes) > 0 {
+		desc += " Only these types can be downloaded: " + strings.Join(t.opts.AllowTypes, ", ") + "."
+	}
+	return desc
+}
+
+func (t *DownloadTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The http or https URL of the file",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Where to save it: a file, or a directory ending in / to keep the file's name",
+			},
+			"sha256": map[string]any{
+				"type":        "string",
+				"description": "The file's expected SHA-256, in hex; the download fails if it differs",
+			},
+			"overwrite": map[string]any{
+				"type":        "boolean",
+				"description": "Replace the file if it exists",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *DownloadTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	rawURL, _ := args["url"].(string)
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrorResult(fmt.Sprintf("%q is not an http(s) URL", rawURL))
+	}
+	wantSum, _ := args["sha256"].(string)
+	wantSum = strings.ToLower(strings.TrimSpace(wantSum))
+	overwrite, _ := args["overwrite"].(bool)
+
+	client, err := createHTTPClient(t.opts.Proxy, t.opts.Timeout)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't download %s: %v", u, err)).WithError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ErrorResult(fmt.Sprintf("Can't download %s: the server answered %s", u, resp.Status))
+	}
+	if resp.ContentLength > t.opts.MaxSize {
+		return ErrorResult(fmt.Sprintf("The file is %s, more than the %s allowed",
+			formatBytes(uint64(resp.ContentLength)), formatBytes(uint64(t.opts.MaxSize))))
+	}
+
+	// The type is that the server declares or, for servers that only say
+	// octet-stream, that of the content.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return ErrorResult(fmt.Sprintf("Can't download %s: %v", u, err)).WithError(err)
+	}
+	head = head[:n]
+	declared, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !t.typeAllowed(declared) && !t.typeAllowed(sniffed) {
+		return ErrorResult(fmt.Sprintf("Files of type %s can't be downloaded; allowed: %s",
+			firstOf(declared, sniffed), strings.Join(t.opts.AllowTypes, ", ")))
+	}
+
+	dest, err := t.destination(args, resp)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if _, err := os.Stat(dest); err == nil && !overwrite {
+		return ErrorResult(fmt.Sprintf("%s already exists; set overwrite to replace it", dest))
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	// Written beside the destination, then renamed, so a failed download
+	// leaves no partial file under the final name.
+	part, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	defer os.Remove(part.Name())
+	hash := sha256.New()
+	body := io.MultiReader(bytes.NewReader(head), resp.Body)
+	written, err := io.Copy(io.MultiWriter(part, hash), io.LimitReader(body, t.opts.MaxSize+1))
+	if cerr := part.Close(); err == nil {
+		err = cerr
+	}
+	switch {
+	case err != nil:
+		return ErrorResult(fmt.Sprintf("Download of %s failed: %v", u, err)).WithError(err)
+	case written > t.opts.MaxSize:
+		return ErrorResult(fmt.Sprintf("The file is larger than the %s allowed",
+			formatBytes(uint64(t.opts.MaxSize))))
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if wantSum != "" && sum != wantSum {
+		return ErrorResult(fmt.Sprintf("The SHA-256 of the file is %s, not %s; it was not saved", sum, wantSum))
+	}
+	if err := os.Rename(part.Name(), dest); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	msg := fmt.Sprintf("Downloaded %s to %s: %s, %s, SHA-256 %s", u, dest, formatBytes(uint64(written)),
+		firstOf(declared, sniffed), sum)
+	if wantSum != "" {
+		msg += " (checked)"
+	}
+	return SilentResult(msg)
+}
+
+func (t *DownloadTool) typeAllowed(mediaType string) bool {
+	if len(t.opts.AllowTypes) == 0 {
+		return true
+	}
+	for _, pattern := range t.opts.AllowTypes {
+		if ok, _ := path.Match(strings.ToLower(pattern), mediaType); ok && mediaType != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// destination returns where the file is saved: the path given, or a
+// directory given (ending in /, or existing) or the downloads directory,
+// with the name the server gives the file or the last part of its URL.
+func (t *DownloadTool) destination(args map[string]any, resp *http.Response) (string, error) {
+	name := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		name = path.Base(resp.Request.URL.Path)
+	}
+	name = utils.SanitizeFilename(name)
+	if name == "" || name == "." || name == "/" {
+		name = "download"
+	}
+
+	p, _ := args["path"].(string)
+	if p == "" {
+		p = defaultDownloadDir + "/"
+	}
+	dirOnly := strings.HasSuffix(p, "/") || strings.HasSuffix(p, string(filepath.Separator))
+	dest, err := resolveAllowedPath(expandHomeDir(p), t.opts.Workspace, t.opts.Restrict, t.opts.AllowPaths)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dest); dirOnly || (err == nil && info.IsDir()) {
+		return resolveAllowedPath(filepath.Join(dest, name), t.opts.Workspace, t.opts.Restrict, t.opts.AllowPaths)
+	}
+	return dest, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newDownloadServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases/app-1.2.tar.gz":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("\x1f\x8b\x08\x00release"))
+		case "/get":
+			w.Header().Set("Content-Disposition", `attachment; filename="../notes.txt"`)
+			w.Write([]byte("plain text"))
+		case "/big":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		case "/stream": // No Content-Length, so the size is only known while saving
+			w.Header().Set("Content-Type", "application/zip")
+			for range 4 {
+				w.Write([]byte(strings.Repeat("x", 512)))
+				w.(http.Flusher).Flush()
+			}
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>hi</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadTool_SavesToDownloads(t *testing.T) {
+	server := newDownloadServer(t)
+	workspace := t.TempDir()
+	tool := NewDownloadTool(DownloadToolOptions{Workspace: workspace, Restrict: true})
+	ctx := context.Background()
+
+	sum := sha256.Sum256([]byte("\x1f\x8b\x08\x00release"))
+	result := tool.Execute(ctx, map[string]any{
+		"url": server.URL + "/releases/app-1.2.tar.gz", "sha256": strings.ToUpper(hex.EncodeToString(sum[:])),
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	saved := filepath.Join(workspace, "downloads", "app-1.2.tar.gz")
+	if data, err := os.ReadFile(saved); err != nil || string(data) != "\x1f\x8b\x08\x00release" {
+		t.Fatalf("saved file = %q, %v", data, err)
+	}
+	if !strings.Contains(result.ForLLM, "application/octet-stream") || !strings.Contains(result.ForLLM, "(checked)") {
+		t.Errorf("result = %s", result.ForLLM)
+	}
+
+	// The name the server gives is kept, without its directories.
+	result = tool.Execute(ctx, map[string]any{"url": server.URL + "/get", "path": "docs/"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "docs", "notes.txt")); err != nil {
+		t.Errorf("content-disposition name not used: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"url": server.URL + "/get", "path": "docs/"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "already exists") {
+		t.Errorf("existing file: %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"url": server.URL + "/get", "path": "docs/", "overwrite": true})
+	if result.IsError {
+		t.Errorf("overwrite: %s", result.ForLLM)
+	}
+}
+
+func TestDownloadTool_Refusals(t *testing.T) {
+	server := newDownloadServer(t)
+	workspace := t.TempDir()
+	tool := NewDownloadTool(DownloadToolOptions{
+		Workspace:  workspace,
+		Restrict:   true,
+		MaxSize:    1024,
+		AllowTypes: []string{"application/*"},
+	})
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"scheme", map[string]any{"url": "file:///etc/passwd"}, "not an http(s) URL"},
+		{"status", map[string]any{"url": server.URL + "/missing"}, "404"},
+		{"size", map[string]any{"url": server.URL + "/big"}, "is 2 KB, more than the 1 KB allowed"},
+		{"streamed size", map[string]any{"url": server.URL + "/stream"}, "larger than the 1 KB allowed"},
+		{"type", map[string]any{"url": server.URL + "/page"}, "type text/html can't be downloaded"},
+		{"checksum", map[string]any{"url": server.URL + "/releases/app-1.2.tar.gz", "sha256": "00ff"},
+			"it was not saved"},
+		{"outside", map[string]any{"url": server.URL + "/releases/app-1.2.tar.gz", "path": "/tmp/app.tar.gz"},
+			"outside"},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(context.Background(), tt.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("%s: %q, want %q", tt.name, result.ForLLM, tt.want)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(workspace, "downloads"))
+	if len(entries) != 0 {
+		t.Errorf("refused downloads left files: %v", entries)
+	}
+}
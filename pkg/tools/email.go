@@ -0,0 +1,381 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/email"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultEmailLimit = 10
+	maxEmailLimit     = 50
+	// maxEmailChars bounds the body shown of a message.
+	maxEmailChars = 20000
+)
+
+// EmailToolOptions configures an EmailTool.
+type EmailToolOptions struct {
+	Folders   []string // The folders that can be read; all when empty
+	ReadOnly  bool     // No send action
+	Workspace string   // Where attachments are saved and sent from
+	Restrict  bool     // Only send files in the workspace
+}
+
+// EmailTool searches, reads and sends the mail of a mailbox.
+type EmailTool struct {
+	mailbox email.Mailbox
+	opts    EmailToolOptions
+}
+
+func NewEmailTool(mailbox email.Mailbox, opts EmailToolOptions) *EmailTool {
+	return &EmailTool{mailbox: mailbox, opts: opts}
+}
+
+func (t *EmailTool) Name() string {
+	return "email"
+}
+
+func (t *EmailTool) Description() string {
+	desc := "Search and read the user's email: list the folders, search a folder for messages " +
+		"(by text, sender, subject, date or unread), and read one in full, saving its attachments if asked. " +
+		"Search first to find a message's id."
+	if !t.opts.ReadOnly {
+		desc += " Also sends email, with attachments from the workspace, including replies to a message " +
+			"('reply_to' its id). Only send when the user asked for it."
+	}
+	return desc
+}
+
+func (t *EmailTool) Parameters() map[string]any {
+	actions := []string{"folders", "search", "read"}
+	if !t.opts.ReadOnly {
+		actions = append(actions, "send")
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        actions,
+				"description": "What to do",
+			},
+			"folder": map[string]any{
+				"type":        "string",
+				"description": "Folder to search or read in (default INBOX)",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Search: text in the message",
+			},
+			"from": map[string]any{
+				"type":        "string",
+				"description": "Search: part of the sender's name or address",
+			},
+			"subject": map[string]any{
+				"type":        "string",
+				"description": "Search: part of the subject",
+			},
+			"since": map[string]any{
+				"type":        "string",
+				"description": "Search: messages received on this date (YYYY-MM-DD) or later",
+			},
+			"unread": map[string]any{
+				"type":        "boolean",
+				"description": "Search: only unread messages",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Search: how many of the newest messages to show (default %d)", defaultEmailLimit),
+			},
+			"id": map[string]any{
+				"type":        "integer",
+				"description": "Read: the message's id, from search",
+			},
+			"save_attachments": map[string]any{
+				"type":        "boolean",
+				"description": "Read: save the attachments to the workspace, to open them with other tools",
+			},
+			"to": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Send: recipient addresses",
+			},
+			"cc": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Send: addresses to copy",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Send: the text of the message",
+			},
+			"attachments": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Send: paths of workspace files to attach",
+			},
+			"reply_to": map[string]any{
+				"type": "integer",
+				"description": "Send: id of the message in 'folder' answered; " +
+					"the reply is threaded with it, and 'to' and 'subject' default to its sender and subject",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *EmailTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	folder, _ := args["folder"].(string)
+	if folder == "" {
+		folder = "INBOX"
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "folders":
+		return t.folders(ctx)
+	case "search":
+		if err := t.checkFolder(folder); err != nil {
+			return ErrorResult(err.Error())
+		}
+		return t.search(ctx, folder, args)
+	case "read":
+		if err := t.checkFolder(folder); err != nil {
+			return ErrorResult(err.Error())
+		}
+		return t.read(ctx, folder, args)
+	case "send":
+		if !t.opts.ReadOnly {
+			return t.send(ctx, folder, args)
+		}
+	}
+	return ErrorResult(fmt.Sprintf("unknown action %q", action))
+}
+
+// checkFolder refuses folders outside the configured ones.
+func (t *EmailTool) checkFolder(folder string) error {
+	if len(t.opts.Folders) == 0 {
+		return nil
+	}
+	for _, f := range t.opts.Folders {
+		if strings.EqualFold(f, folder) {
+			return nil
+		}
+	}
+	return fmt.Errorf("folder %q can't be read; the folders allowed are: %s", folder, strings.Join(t.opts.Folders, ", "))
+}
+
+func (t *EmailTool) folders(ctx context.Context) *ToolResult {
+	folders, err := t.mailbox.Folders(ctx)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error listing folders: %v", err)).WithError(err)
+	}
+	var allowed []string
+	for _, f := range folders {
+		if t.checkFolder(f) == nil {
+			allowed = append(allowed, f)
+		}
+	}
+	if len(allowed) == 0 {
+		return SilentResult("No folders")
+	}
+	return SilentResult("Folders:\n- " + strings.Join(allowed, "\n- "))
+}
+
+func (t *EmailTool) search(ctx context.Context, folder string, args map[string]any) *ToolResult {
+	var q email.Query
+	q.Text, _ = args["query"].(string)
+	q.From, _ = args["from"].(string)
+	q.Subject, _ = args["subject"].(string)
+	q.Unseen, _ = args["unread"].(bool)
+	if since, _ := args["since"].(string); since != "" {
+		at, err := time.Parse(time.DateOnly, since)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("since must be a date like 2026-10-15, not %q", since))
+		}
+		q.Since = at
+	}
+	limit := defaultEmailLimit
+	if n, ok := args["limit"].(float64); ok && n > 0 {
+		limit = min(int(n), maxEmailLimit)
+	}
+
+	summaries, err := t.mailbox.Search(ctx, folder, q, limit)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error searching %s: %v", folder, err)).WithError(err)
+	}
+	if len(summaries) == 0 {
+		return SilentResult("No messages found in " + folder)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Newest messages in %s:", folder)
+	for _, s := range summaries {
+		unread := ""
+		if !s.Seen {
+			unread = " (unread)"
+		}
+		fmt.Fprintf(&sb, "\n- [id: %d] %s | %s | %s%s", s.UID, s.Date.Format("2006-01-02 15:04"), s.From,
+			s.Subject, unread)
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *EmailTool) read(ctx context.Context, folder string, args map[string]any) *ToolResult {
+	uid, ok := emailID(args["id"])
+	if !ok {
+		return ErrorResult("id is required to read a message")
+	}
+	m, err := t.mailbox.Read(ctx, folder, uid)
+	if errors.Is(err, email.ErrNotFound) {
+		return ErrorResult(fmt.Sprintf("there is no message %d in %s; search to find it", uid, folder))
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error reading the message: %v", err)).WithError(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\nTo: %s\n", m.From, strings.Join(m.To, ", "))
+	if len(m.Cc) > 0 {
+		fmt.Fprintf(&sb, "Cc: %s\n", strings.Join(m.Cc, ", "))
+	}
+	fmt.Fprintf(&sb, "Date: %s\nSubject: %s\n\n%s", m.Date.Format(time.RFC1123Z), m.Subject,
+		utils.Truncate(m.Text, maxEmailChars))
+
+	if len(m.Attachments) > 0 {
+		save, _ := args["save_attachments"].(bool)
+		sb.WriteString("\n\nAttachments:")
+		dir := filepath.Join("email", fmt.Sprintf("%s-%d", safeFolderName(folder), uid))
+		for _, a := range m.Attachments {
+			name := utils.SanitizeFilename(a.Filename)
+			fmt.Fprintf(&sb, "\n- %s (%s, %s)", name, a.ContentType, formatSize(len(a.Data)))
+			if !save {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if err := t.saveAttachment(path, a.Data); err != nil {
+				fmt.Fprintf(&sb, ": not saved: %v", err)
+			} else {
+				sb.WriteString(": saved as " + path)
+			}
+		}
+		if !save {
+			sb.WriteString("\nRead again with save_attachments to open them.")
+		}
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *EmailTool) saveAttachment(rel string, data []byte) error {
+	if t.opts.Workspace == "" {
+		return errors.New("no workspace")
+	}
+	path := filepath.Join(t.opts.Workspace, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (t *EmailTool) send(ctx context.Context, folder string, args map[string]any) *ToolResult {
+	out := email.Outgoing{To: listArg(args["to"]), Cc: listArg(args["cc"])}
+	out.Subject, _ = args["subject"].(string)
+	out.Text, _ = args["body"].(string)
+	if out.Text == "" {
+		return ErrorResult("body is required to send a message")
+	}
+
+	if uid, ok := emailID(args["reply_to"]); ok {
+		if err := t.checkFolder(folder); err != nil {
+			return ErrorResult(err.Error())
+		}
+		original, err := t.mailbox.Read(ctx, folder, uid)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Error reading the message replied to: %v", err))
+		}
+		if len(out.To) == 0 && original.FromAddress != "" {
+			out.To = []string{original.From}
+		}
+		if out.Subject == "" {
+			out.Subject = email.ReplySubject(original.Subject)
+		}
+		out.InReplyTo, out.References = original.MessageID, original.References
+	}
+	if len(out.To) == 0 {
+		return ErrorResult("to is required to send a message")
+	}
+	if out.Subject == "" {
+		return ErrorResult("subject is required to send a message")
+	}
+
+	for _, p := range listArg(args["attachments"]) {
+		path, err := validatePath(p, t.opts.Workspace, t.opts.Restrict)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("can't attach %s: %v", p, err))
+		}
+		out.Attachments = append(out.Attachments, path)
+	}
+
+	if _, err := t.mailbox.Send(ctx, out); err != nil {
+		return ErrorResult(fmt.Sprintf("Error sending the message: %v", err)).WithError(err)
+	}
+	recipients := strings.Join(append(append([]string{}, out.To...), out.Cc...), ", ")
+	return SilentResult(fmt.Sprintf("Message %q sent to %s", out.Subject, recipients))
+}
+
+// emailID reads a message id given as a number or a string.
+func emailID(v any) (uint32, bool) {
+	switch id := v.(type) {
+	case float64:
+		return uint32(id), id > 0
+	case string:
+		n, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32)
+		return uint32(n), err == nil && n > 0
+	}
+	return 0, false
+}
+
+// listArg reads a list of strings, given as an array or as one string
+// of comma-separated items.
+func listArg(v any) []string {
+	var list []string
+	switch items := v.(type) {
+	case []any:
+		for _, item := range items {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				list = append(list, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		for _, s := range strings.Split(items, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+var unsafeFolderChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func safeFolderName(folder string) string {
+	return strings.Trim(unsafeFolderChars.ReplaceAllString(folder, "_"), "_")
+}
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/email"
+)
+
+// memoryMailbox is an email.Mailbox kept in memory.
+type memoryMailbox struct {
+	messages map[string][]*email.Message // By folder
+	sent     []email.Outgoing
+}
+
+func (m *memoryMailbox) Folders(context.Context) ([]string, error) {
+	return []string{"INBOX", "Sent", "Work"}, nil
+}
+
+func (m *memoryMailbox) Search(_ context.Context, folder string, q email.Query, limit int) ([]email.Summary, error) {
+	var out []email.Summary
+	list := m.messages[folder]
+	for i := len(list) - 1; i >= 0 && len(out) < limit; i-- {
+		msg := list[i]
+		if q.From != "" && !strings.Contains(strings.ToLower(msg.From), strings.ToLower(q.From)) {
+			continue
+		}
+		out = append(out, email.Summary{UID: msg.UID, From: msg.From, Subject: msg.Subject, Date: msg.Date, Seen: msg.Seen})
+	}
+	return out, nil
+}
+
+func (m *memoryMailbox) Read(_ context.Context, folder string, uid uint32) (*email.Message, error) {
+	for _, msg := range m.messages[folder] {
+		if msg.UID == uid {
+			return msg, nil
+		}
+	}
+	return nil, email.ErrNotFound
+}
+
+func (m *memoryMailbox) Unseen(context.Context, string, uint32, time.Time) ([]*email.Message, error) {
+	return nil, nil
+}
+
+func (m *memoryMailbox) MarkSeen(context.Context, string, []uint32) error {
+	return nil
+}
+
+func (m *memoryMailbox) Send(_ context.Context, out email.Outgoing) (string, error) {
+	m.sent = append(m.sent, out)
+	return "<sent@example.com>", nil
+}
+
+func newTestMailbox() *memoryMailbox {
+	date := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	return &memoryMailbox{messages: map[string][]*email.Message{
+		"INBOX": {
+			{UID: 1, From: "Ann <ann@example.com>", FromAddress: "ann@example.com", Subject: "Lunch",
+				Date: date, Seen: true, Text: "Noon?", MessageID: "<l@example.com>"},
+			{UID: 2, From: "Bob <bob@example.com>", FromAddress: "bob@example.com", Subject: "Invoice",
+				Date: date.Add(time.Hour), Text: "Attached.", MessageID: "<i@example.com>",
+				References: "<a@example.com>", To: []string{"me@example.com"},
+				Attachments: []email.Attachment{
+					{Filename: "../invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF")},
+				}},
+		},
+	}}
+}
+
+func TestEmailTool_SearchAndRead(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewEmailTool(newTestMailbox(), EmailToolOptions{Folders: []string{"INBOX", "Work"}, Workspace: workspace})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "folders"})
+	if result.ForLLM != "Folders:\n- INBOX\n- Work" {
+		t.Errorf("folders = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "search"})
+	want := "Newest messages in INBOX:\n" +
+		"- [id: 2] 2026-10-15 10:30 | Bob <bob@example.com> | Invoice (unread)\n" +
+		"- [id: 1] 2026-10-15 09:30 | Ann <ann@example.com> | Lunch"
+	if result.IsError || result.ForLLM != want {
+		t.Errorf("search = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "read", "id": float64(2)})
+	if result.IsError || !strings.Contains(result.ForLLM, "Subject: Invoice\n\nAttached.") ||
+		!strings.Contains(result.ForLLM, "- invoice.pdf (application/pdf, 4 bytes)") {
+		t.Fatalf("read = %q", result.ForLLM)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "email")); !os.IsNotExist(err) {
+		t.Error("attachments saved without save_attachments")
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "read", "id": "2", "save_attachments": true})
+	if !strings.Contains(result.ForLLM, "saved as email/INBOX-2/invoice.pdf") {
+		t.Fatalf("read with save = %q", result.ForLLM)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspace, "email", "INBOX-2", "invoice.pdf")); string(data) != "%PDF" {
+		t.Errorf("saved attachment = %q", data)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "read", "id": float64(9)},
+		{"action": "read"},
+		{"action": "search", "folder": "Sent"},
+		{"action": "search", "since": "yesterday"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v: expected an error, got %q", args, result.ForLLM)
+		}
+	}
+}
+
+func TestEmailTool_Send(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "chart.png"), []byte("png"), 0o644)
+	mailbox := newTestMailbox()
+	tool := NewEmailTool(mailbox, EmailToolOptions{Workspace: workspace, Restrict: true})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "send", "reply_to": float64(2), "body": "Paid.", "attachments": []any{"chart.png"},
+	})
+	if result.IsError {
+		t.Fatalf("send = %q", result.ForLLM)
+	}
+	out := mailbox.sent[0]
+	if out.Subject != "Re: Invoice" || out.To[0] != "Bob <bob@example.com>" || out.InReplyTo != "<i@example.com>" ||
+		out.References != "<a@example.com>" || out.Attachments[0] != filepath.Join(workspace, "chart.png") {
+		t.Errorf("sent = %+v", out)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action": "send", "to": "ann@example.com", "subject": "Hi", "body": "x", "attachments": []any{"/etc/passwd"},
+	})
+	if !result.IsError || len(mailbox.sent) != 1 {
+		t.Errorf("sending a file outside the workspace = %q", result.ForLLM)
+	}
+
+	readOnly := NewEmailTool(mailbox, EmailToolOptions{ReadOnly: true})
+	result = readOnly.Execute(ctx, map[string]any{"action": "send", "to": "ann@example.com", "subject": "Hi", "body": "x"})
+	if !result.IsError || len(mailbox.sent) != 1 {
+		t.Errorf("read-only send = %q", result.ForLLM)
+	}
+	if strings.Contains(readOnly.Description(), "sends") {
+		t.Error("the read-only tool's description offers sending")
+	}
+}
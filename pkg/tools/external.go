@@ -0,0 +1,331 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExternalProtocolVersion is the version of the protocol sent in calls.
+const ExternalProtocolVersion = 1
+
+const (
+	defaultExternalTimeout  = 60 * time.Second
+	externalDescribeTimeout = 10 * time.Second
+)
+
+var externalToolName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// externalToolSpec is a tool as an executable describes it.
+type externalToolSpec struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+// externalRequest is what a call sends to the executable.
+type externalRequest struct {
+	Protocol  int            `json:"protocol"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	Channel   string         `json:"channel,omitempty"`
+	ChatID    string         `json:"chat_id,omitempty"`
+	Workspace string         `json:"workspace"`
+}
+
+// externalResponse is what the executable answers.
+type externalResponse struct {
+	Content *string `json:"content"`
+	ForUser string  `json:"for_user"`
+	IsError bool    `json:"is_error"`
+}
+
+// ExternalTool is a tool provided by an executable, written in any
+// language, that may provide several. The executable is asked for its
+// tools with
+//
+//	<executable> describe
+//
+// and prints a JSON object, or an array of them, one per tool:
+//
+//	{"name": "weather", "description": "Current weather for a city",
+//	 "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}
+//
+// To run a tool it's started as
+//
+//	<executable> call
+//
+// in the workspace, and reads one JSON object from stdin:
+//
+//	{"protocol": 1, "tool": "weather", "arguments": {"city": "Lyon"},
+//	 "channel": "telegram", "chat_id": "42", "workspace": "/home/me/.picoclaw/workspace"}
+//
+// It answers on stdout with
+//
+//	{"content": "...", "for_user": "...", "is_error": false}
+//
+// where content goes to the model, for_user (optional) straight to the
+// user, and is_error marks a failure. Output that isn't such an object is
+// taken as the content. Exiting with a non-zero status is a failure too,
+// with stderr added to the content.
+//
+// A .wasm file is a WebAssembly module run in a WASI sandbox instead; see
+// wasm_tools.go.
+type ExternalTool struct {
+	path         string
+	wasmMemoryMB int // For .wasm modules
+	spec         externalToolSpec
+	workspace    string
+	timeout      time.Duration
+
+	ctxMu   sync.Mutex
+	channel string
+	chatID  string
+}
+
+// LoadExternalTools describes every executable and WebAssembly module in
+// dir and returns their tools, in name order, to be given a workspace with
+// ForWorkspace. Modules get at most wasmMemoryMB megabytes of memory.
+// Executables that fail to describe themselves are reported in the errors
+// and left out, as are tools whose name another executable took first. A
+// missing dir has no tools.
+func LoadExternalTools(dir string, wasmMemoryMB int, timeout time.Duration) ([]*ExternalTool, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+	if timeout <= 0 {
+		timeout = defaultExternalTimeout
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// Follow links, which is how tools installed elsewhere are added.
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if !isWASMModule(path) && info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if wasmMemoryMB <= 0 {
+		wasmMemoryMB = defaultWASMMaxMemoryMB
+	}
+
+	specs := make([][]externalToolSpec, len(paths))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			specs[i], errs[i] = describeExternal(path, wasmMemoryMB)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		tools    []*ExternalTool
+		problems []error
+		seen     = map[string]string{}
+	)
+	for i, path := range paths {
+		if errs[i] != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", filepath.Base(path), errs[i]))
+			continue
+		}
+		for _, spec := range specs[i] {
+			if other, ok := seen[spec.Name]; ok {
+				problems = append(problems, fmt.Errorf("%s: tool %s is already provided by %s",
+					filepath.Base(path), spec.Name, other))
+				continue
+			}
+			seen[spec.Name] = filepath.Base(path)
+			tool := &ExternalTool{path: path, spec: spec, timeout: timeout}
+			if isWASMModule(path) {
+				tool.wasmMemoryMB = wasmMemoryMB
+			}
+			tools = append(tools, tool)
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].spec.Name < tools[j].spec.Name })
+	return tools, problems
+}
+
+// describeExternal asks the executable at path for its tools.
+func describeExternal(path string, wasmMemoryMB int) ([]externalToolSpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalDescribeTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	var err error
+	if isWASMModule(path) {
+		err = runWASM(ctx, path, wasmMemoryMB, "", "describe", nil, &stdout, &stderr)
+	} else {
+		cmd := exec.CommandContext(ctx, path, "describe")
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		err = cmd.Run()
+	}
+	out := stdout.Bytes()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("describe failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("describe failed: %w", err)
+	}
+
+	out = bytes.TrimSpace(out)
+	var specs []externalToolSpec
+	if bytes.HasPrefix(out, []byte("[")) {
+		err = json.Unmarshal(out, &specs)
+	} else {
+		specs = make([]externalToolSpec, 1)
+		err = json.Unmarshal(out, &specs[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("describe printed invalid JSON: %w", err)
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("describe listed no tools")
+	}
+	for _, spec := range specs {
+		if !externalToolName.MatchString(spec.Name) {
+			return nil, fmt.Errorf("invalid tool name %q: use letters, digits, _ and -", spec.Name)
+		}
+	}
+	return specs, nil
+}
+
+// ForWorkspace returns a copy of the tool that runs in workspace.
+func (t *ExternalTool) ForWorkspace(workspace string) *ExternalTool {
+	return &ExternalTool{
+		path:         t.path,
+		wasmMemoryMB: t.wasmMemoryMB,
+		spec:         t.spec,
+		workspace:    workspace,
+		timeout:      t.timeout,
+	}
+}
+
+func (t *ExternalTool) Name() string {
+	return t.spec.Name
+}
+
+func (t *ExternalTool) Description() string {
+	if t.spec.Description == "" {
+		return fmt.Sprintf("%s (external tool)", t.spec.Name)
+	}
+	return t.spec.Description
+}
+
+func (t *ExternalTool) Parameters() map[string]any {
+	params := make(map[string]any, len(t.spec.Parameters)+2)
+	for k, v := range t.spec.Parameters {
+		params[k] = v
+	}
+	params["type"] = "object"
+	if _, ok := params["properties"]; !ok {
+		params["properties"] = map[string]any{}
+	}
+	return params
+}
+
+func (t *ExternalTool) SetContext(channel, chatID string) {
+	t.ctxMu.Lock()
+	defer t.ctxMu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *ExternalTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	t.ctxMu.Lock()
+	request := externalRequest{
+		Protocol:  ExternalProtocolVersion,
+		Tool:      t.spec.Name,
+		Arguments: args,
+		Channel:   t.channel,
+		ChatID:    t.chatID,
+		Workspace: t.workspace,
+	}
+	t.ctxMu.Unlock()
+	if request.Arguments == nil {
+		request.Arguments = map[string]any{}
+	}
+	if t.wasmMemoryMB > 0 {
+		request.Workspace = wasmWorkspace
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid arguments: %v", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	stdout := &limitedBuffer{max: defaultExecMaxOutput}
+	stderr := &limitedBuffer{max: 4000}
+	var runErr error
+	if t.wasmMemoryMB > 0 {
+		runErr = runWASM(ctx, t.path, t.wasmMemoryMB, t.workspace, "call", bytes.NewReader(input), stdout, stderr)
+	} else {
+		cmd := exec.CommandContext(ctx, t.path, "call")
+		cmd.Dir = t.workspace
+		cmd.Stdin = bytes.NewReader(input)
+		prepareCommandForTermination(cmd)
+		cmd.Cancel = func() error { return terminateProcessTree(cmd) }
+		cmd.WaitDelay = time.Second
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		runErr = cmd.Run()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err := fmt.Errorf("%s timed out after %s", t.spec.Name, t.timeout)
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	var response externalResponse
+	if json.Unmarshal([]byte(output), &response) != nil || response.Content == nil {
+		response = externalResponse{Content: &output}
+	}
+	content := *response.Content
+	if stdout.dropped > 0 {
+		content += fmt.Sprintf("\n... (truncated, %d more chars)", stdout.dropped)
+	}
+
+	if runErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			content = strings.TrimSpace(content + "\n" + msg)
+		}
+		if content == "" {
+			content = fmt.Sprintf("%s failed: %v", t.spec.Name, runErr)
+		}
+		return ErrorResult(content).WithError(runErr)
+	}
+	if response.IsError {
+		return ErrorResult(content)
+	}
+	if response.ForUser != "" {
+		result := NewToolResult(content)
+		result.ForUser = response.ForUser
+		return result
+	}
+	return SilentResult(content)
+}
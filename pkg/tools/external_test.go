@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeExternalTool writes an executable shell script to dir.
+func writeExternalTool(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadExternalTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test tools are shell scripts")
+	}
+	dir := t.TempDir()
+	writeExternalTool(t, dir, "greet", `
+if [ "$1" = describe ]; then
+  echo '{"name": "greet", "description": "Greets someone",
+         "parameters": {"type": "object", "properties": {"name": {"type": "string"}}}}'
+  exit
+fi
+input=$(cat)
+case "$input" in
+  *'"name":"fail"'*) echo "no such person" >&2; exit 3 ;;
+  *'"name":"user"'*) echo '{"content": "greeted", "for_user": "Hello!"}' ;;
+  *) echo "$input" ;;
+esac
+`)
+	writeExternalTool(t, dir, "units", `
+[ "$1" = describe ] && echo '[{"name": "to_celsius"}, {"name": "greet"}]' && exit
+cat >/dev/null
+echo '{"content": "unknown unit", "is_error": true}'
+`)
+	writeExternalTool(t, dir, "broken", `echo nonsense`)
+	writeExternalTool(t, dir, "slow", `
+[ "$1" = describe ] && echo '{"name": "slow"}' && exit
+sleep 30
+`)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("not a tool"), 0o644)
+
+	loaded, problems := LoadExternalTools(dir, 0, time.Second)
+	var names []string
+	for _, tool := range loaded {
+		names = append(names, tool.Name())
+	}
+	if got := strings.Join(names, " "); got != "greet slow to_celsius" {
+		t.Fatalf("LoadExternalTools() = %s, want greet slow to_celsius", got)
+	}
+	if len(problems) != 2 {
+		t.Errorf("LoadExternalTools() problems = %v, want broken and the second greet", problems)
+	}
+
+	workspace := t.TempDir()
+	greet := loaded[0].ForWorkspace(workspace)
+	greet.SetContext("telegram", "42")
+	ctx := context.Background()
+
+	result := greet.Execute(ctx, map[string]any{"name": "Ada"})
+	for _, want := range []string{`"protocol":1`, `"tool":"greet"`, `"arguments":{"name":"Ada"}`,
+		`"chat_id":"42"`, `"workspace":"` + workspace + `"`} {
+		if result.IsError || !strings.Contains(result.ForLLM, want) {
+			t.Errorf("Execute() = %q, want %s in the request", result.ForLLM, want)
+		}
+	}
+	if result = greet.Execute(ctx, map[string]any{"name": "user"}); result.ForLLM != "greeted" ||
+		result.ForUser != "Hello!" {
+		t.Errorf("Execute() = %+v, want content and for_user", result)
+	}
+	if result = greet.Execute(ctx, map[string]any{"name": "fail"}); !result.IsError ||
+		!strings.Contains(result.ForLLM, "no such person") {
+		t.Errorf("Execute() of a failing call = %q, want its stderr", result.ForLLM)
+	}
+	if result = loaded[2].ForWorkspace(workspace).Execute(ctx, nil); !result.IsError ||
+		result.ForLLM != "unknown unit" {
+		t.Errorf("Execute() answering is_error = %+v", result)
+	}
+	if result = loaded[1].ForWorkspace(workspace).Execute(ctx, nil); !result.IsError ||
+		!strings.Contains(result.ForLLM, "timed out") {
+		t.Errorf("Execute() of a slow tool = %q, want a timeout", result.ForLLM)
+	}
+}
+
+func TestLoadExternalTools_MissingDir(t *testing.T) {
+	tools, problems := LoadExternalTools(filepath.Join(t.TempDir(), "tools.d"), 0, 0)
+	if len(tools) != 0 || len(problems) != 0 {
+		t.Errorf("LoadExternalTools() of a missing dir = %v, %v", tools, problems)
+	}
+}
@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/feeds"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const defaultFeedPreviewItems = 5
+
+// FeedsTool subscribes chats to RSS and Atom feeds, delivered as digests or
+// alerts by a feeds.Service.
+type FeedsTool struct {
+	service *feeds.Service
+	channel string
+	chatID  string
+	mu      sync.RWMutex
+}
+
+func NewFeedsTool(service *feeds.Service) *FeedsTool {
+	return &FeedsTool{service: service}
+}
+
+func (t *FeedsTool) Name() string {
+	return "feeds"
+}
+
+func (t *FeedsTool) Description() string {
+	return "Follow RSS/Atom feeds for the user. 'subscribe' to a feed's URL as a digest, sent on a schedule " +
+		"('every day at 8:00' by default), or as alerts, sent as soon as new items appear. Only items with one " +
+		"of the 'keywords', or about the 'topic', are delivered when either is set. Items already in the feed " +
+		"when subscribing aren't sent. 'preview' shows a feed's latest items; 'list' and 'unsubscribe' manage " +
+		"the subscriptions."
+}
+
+func (t *FeedsTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"subscribe", "list", "unsubscribe", "preview"},
+				"description": "What to do",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The feed's address (subscribe, preview)",
+			},
+			"mode": map[string]any{
+				"type":        "string",
+				"enum":        []string{feeds.ModeDigest, feeds.ModeAlert},
+				"description": "digest (default) gathers new items and sends them on the schedule; alert sends each right away",
+			},
+			"schedule": map[string]any{
+				"type":        "string",
+				"description": "When digests are sent, e.g. 'every day at 8:00', 'every monday at 9am', 'every 6 hours'",
+			},
+			"tz": map[string]any{
+				"type":        "string",
+				"description": "IANA time zone of 'schedule' (e.g. 'Europe/Paris'). Default: the server's.",
+			},
+			"keywords": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Only deliver items containing one of these words",
+			},
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "Only deliver items about this, e.g. 'new Raspberry Pi hardware' (needs an embedding model)",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "A name for the subscription; default: the feed's title",
+			},
+			"channel": map[string]any{
+				"type":        "string",
+				"description": "Deliver to this channel instead of this chat (with chat_id)",
+			},
+			"chat_id": map[string]any{
+				"type":        "string",
+				"description": "The chat of 'channel' to deliver to",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "Subscription ID (unsubscribe)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *FeedsTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *FeedsTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "subscribe":
+		return t.subscribe(ctx, args)
+	case "list":
+		return t.list()
+	case "unsubscribe":
+		id, _ := args["id"].(string)
+		if id == "" {
+			return ErrorResult("id is required to unsubscribe")
+		}
+		if !t.service.Unsubscribe(id) {
+			return ErrorResult(fmt.Sprintf("no subscription %s", id))
+		}
+		return SilentResult(fmt.Sprintf("Subscription %s removed", id))
+	case "preview":
+		return t.preview(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *FeedsTool) subscribe(ctx context.Context, args map[string]any) *ToolResult {
+	sub := feeds.Subscription{}
+	sub.URL, _ = args["url"].(string)
+	sub.Mode, _ = args["mode"].(string)
+	sub.Title, _ = args["title"].(string)
+	sub.Topic, _ = args["topic"].(string)
+	sub.Keywords = listArg(args["keywords"])
+	if sub.URL == "" {
+		return ErrorResult("url is required to subscribe")
+	}
+
+	sub.Channel, _ = args["channel"].(string)
+	sub.ChatID, _ = args["chat_id"].(string)
+	if sub.Channel == "" || sub.ChatID == "" {
+		t.mu.RLock()
+		sub.Channel, sub.ChatID = t.channel, t.chatID
+		t.mu.RUnlock()
+	}
+	if sub.Channel == "" || sub.ChatID == "" {
+		return ErrorResult("no chat to deliver to; set channel and chat_id, or use this tool in a conversation")
+	}
+
+	schedule, _ := args["schedule"].(string)
+	tz, _ := args["tz"].(string)
+	created, err := t.service.Subscribe(ctx, sub, schedule, tz)
+	if errors.Is(err, feeds.ErrNoEmbeddings) {
+		return ErrorResult(err.Error() + "; use keywords instead")
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't subscribe: %v", err))
+	}
+	return SilentResult("Subscribed: " + describeSubscription(*created))
+}
+
+func (t *FeedsTool) list() *ToolResult {
+	subs := t.service.List()
+	if len(subs) == 0 {
+		return SilentResult("No feed subscriptions")
+	}
+	var sb strings.Builder
+	sb.WriteString("Feed subscriptions:")
+	for _, sub := range subs {
+		sb.WriteString("\n- " + describeSubscription(sub))
+		if sub.LastError != "" {
+			sb.WriteString(" (last fetch failed: " + sub.LastError + ")")
+		}
+	}
+	return SilentResult(sb.String())
+}
+
+func describeSubscription(sub feeds.Subscription) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s (%s), ", sub.ID, sub.Title, sub.URL)
+	if sub.Mode == feeds.ModeAlert {
+		sb.WriteString("alerts")
+	} else {
+		fmt.Fprintf(&sb, "digest %s, next at %s", cron.DescribeSchedule(sub.Schedule),
+			sub.NextDigest.Format("2006-01-02 15:04"))
+	}
+	if len(sub.Keywords) > 0 {
+		fmt.Fprintf(&sb, ", keywords: %s", strings.Join(sub.Keywords, ", "))
+	}
+	if sub.Topic != "" {
+		fmt.Fprintf(&sb, ", topic: %s", sub.Topic)
+	}
+	fmt.Fprintf(&sb, ", to %s:%s", sub.Channel, sub.ChatID)
+	return sb.String()
+}
+
+func (t *FeedsTool) preview(ctx context.Context, args map[string]any) *ToolResult {
+	url, _ := args["url"].(string)
+	if url == "" {
+		return ErrorResult("url is required to preview a feed")
+	}
+	feed, err := t.service.Preview(ctx, url)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't read the feed: %v", err))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d items", feed.Title, len(feed.Items))
+	for i, it := range feed.Items {
+		if i == defaultFeedPreviewItems {
+			break
+		}
+		fmt.Fprintf(&sb, "\n- %s", it.Title)
+		if !it.Published.IsZero() {
+			fmt.Fprintf(&sb, " (%s)", it.Published.Format("2006-01-02"))
+		}
+		if it.Link != "" {
+			sb.WriteString("\n  " + it.Link)
+		}
+		if it.Summary != "" {
+			sb.WriteString("\n  " + utils.Truncate(it.Summary, 200))
+		}
+	}
+	return SilentResult(sb.String())
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/feeds"
+)
+
+func newTestFeedsTool(t *testing.T) (*FeedsTool, string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<rss><channel><title>Pi News</title>`+
+			`<item><title>Pi 6 announced</title><link>https://pi.example/6</link>`+
+			`<pubDate>Tue, 13 Oct 2026 09:00:00 +0000</pubDate></item>`+
+			`<item><title>Pico 3 in stock</title><link>https://pi.example/pico3</link></item>`+
+			`</channel></rss>`)
+	}))
+	t.Cleanup(server.Close)
+	service := feeds.NewService(filepath.Join(t.TempDir(), "feeds.json"), feeds.Options{},
+		func(context.Context, feeds.Delivery) {})
+	return NewFeedsTool(service), server.URL
+}
+
+func TestFeedsTool_SubscribeListUnsubscribe(t *testing.T) {
+	tool, url := newTestFeedsTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "subscribe", "url": url})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no chat") {
+		t.Fatalf("subscribe without a chat = %+v", result)
+	}
+
+	tool.SetContext("telegram", "42")
+	result = tool.Execute(ctx, map[string]any{
+		"action": "subscribe", "url": url, "keywords": "pico, zero", "schedule": "every monday at 9am",
+	})
+	if result.IsError {
+		t.Fatalf("subscribe: %s", result.ForLLM)
+	}
+	for _, want := range []string{"Pi News", "digest", "keywords: pico, zero", "to telegram:42"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("subscribe result %q lacks %q", result.ForLLM, want)
+		}
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "subscribe", "url": url, "mode": "alert"})
+	if !result.IsError {
+		t.Errorf("subscribing twice to a feed = %q, want an error", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "subscribe", "url": url + "/x", "topic": "new boards"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "use keywords instead") {
+		t.Errorf("topic without embeddings = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if strings.Count(result.ForLLM, "\n- ") != 1 {
+		t.Fatalf("list = %q", result.ForLLM)
+	}
+	id := tool.service.List()[0].ID
+	if !strings.Contains(result.ForLLM, "["+id+"]") {
+		t.Errorf("list %q lacks the ID %s", result.ForLLM, id)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "unsubscribe", "id": id})
+	if result.IsError {
+		t.Fatalf("unsubscribe: %s", result.ForLLM)
+	}
+	if result = tool.Execute(ctx, map[string]any{"action": "unsubscribe", "id": id}); !result.IsError {
+		t.Errorf("unsubscribing twice = %q, want an error", result.ForLLM)
+	}
+	if result = tool.Execute(ctx, map[string]any{"action": "list"}); result.ForLLM != "No feed subscriptions" {
+		t.Errorf("list after unsubscribing = %q", result.ForLLM)
+	}
+}
+
+func TestFeedsTool_Preview(t *testing.T) {
+	tool, url := newTestFeedsTool(t)
+	result := tool.Execute(context.Background(), map[string]any{"action": "preview", "url": url})
+	if result.IsError {
+		t.Fatalf("preview: %s", result.ForLLM)
+	}
+	for _, want := range []string{"Pi News: 2 items", "Pi 6 announced (2026-10-13)", "https://pi.example/pico3"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("preview %q lacks %q", result.ForLLM, want)
+		}
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "preview", "url": "ftp://pi.example"})
+	if !result.IsError {
+		t.Errorf("preview of ftp URL = %q, want an error", result.ForLLM)
+	}
+}
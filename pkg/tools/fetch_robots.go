@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// checkRobots returns an error if the site's robots.txt disallows u for
+// PicoClaw. A site without one, or whose robots.txt can't be reached,
+// allows everything; one that fails with a server error allows nothing
+// for now, as RFC 9309 asks.
+func (t *FetchURLTool) checkRobots(ctx context.Context, u *url.URL) error {
+	if t.ignoreRobots {
+		return nil
+	}
+	site := u.Scheme + "://" + u.Host
+
+	t.mu.Lock()
+	entry, ok := t.robots[site]
+	t.mu.Unlock()
+	if !ok || time.Since(entry.fetched) >= robotsTTL {
+		entry = &robotsEntry{rules: t.fetchRobots(ctx, site), fetched: time.Now()}
+		t.mu.Lock()
+		t.robots[site] = entry
+		t.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !entry.rules.allowed(path) {
+		return fmt.Errorf("%s's robots.txt doesn't allow reading %s", u.Host, path)
+	}
+	return nil
+}
+
+func (t *FetchURLTool) fetchRobots(ctx context.Context, site string) robotsRules {
+	ctx, cancel := context.WithTimeout(ctx, min(t.timeout, 10*time.Second))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", site+"/robots.txt", nil)
+	if err != nil {
+		return robotsRules{}
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+	client, err := createHTTPClient(t.proxy, t.timeout)
+	if err != nil {
+		return robotsRules{}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return robotsRules{}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return robotsRules{disallow: []string{"/"}}
+	case resp.StatusCode != http.StatusOK:
+		return robotsRules{}
+	}
+	// RFC 9309 requires reading at least 500 KiB.
+	return parseRobots(io.LimitReader(resp.Body, 512<<10), robotsAgent)
+}
+
+// robotsRules are the Allow and Disallow paths of the robots.txt group
+// that applies to an agent.
+type robotsRules struct {
+	allow, disallow []string
+}
+
+// parseRobots reads the rules for agent from a robots.txt: those of the
+// groups naming it, else those for "*".
+func parseRobots(r io.Reader, agent string) robotsRules {
+	var (
+		mine, all robotsRules
+		hasMine   bool
+		inGroup   bool // Reading a group's rules rather than its user agents
+		forMe     bool
+		forAll    bool
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inGroup {
+				inGroup, forMe, forAll = false, false, false
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				forAll = true
+			case name != "" && (strings.Contains(agent, name) || strings.Contains(name, agent)):
+				forMe, hasMine = true, true
+			}
+		case "allow", "disallow":
+			inGroup = true
+			if value == "" {
+				continue // "Disallow:" alone allows everything
+			}
+			for _, target := range []struct {
+				applies bool
+				rules   *robotsRules
+			}{{forMe, &mine}, {forAll, &all}} {
+				if !target.applies {
+					continue
+				}
+				if key == "allow" {
+					target.rules.allow = append(target.rules.allow, value)
+				} else {
+					target.rules.disallow = append(target.rules.disallow, value)
+				}
+			}
+		default:
+			// Crawl-delay, Sitemap and the like end no group.
+		}
+	}
+	if hasMine {
+		return mine
+	}
+	return all
+}
+
+// allowed reports whether path may be fetched: the longest matching rule
+// decides, and Allow wins a tie.
+func (r robotsRules) allowed(path string) bool {
+	longest := func(patterns []string) int {
+		best := -1
+		for _, p := range patterns {
+			if len(p) > best && robotsMatch(p, path) {
+				best = len(p)
+			}
+		}
+		return best
+	}
+	disallow := longest(r.disallow)
+	return disallow < 0 || longest(r.allow) >= disallow
+}
+
+// robotsMatch matches a robots.txt path pattern, in which * is any text
+// and a final $ ends the path.
+func robotsMatch(pattern, path string) bool {
+	if !strings.ContainsAny(pattern, "*$") {
+		return strings.HasPrefix(path, pattern)
+	}
+	anchored := strings.HasSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(path)
+}
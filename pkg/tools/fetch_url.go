@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// fetchUserAgent names PicoClaw to the sites it reads, as robots.txt
+	// rules are written for a user agent.
+	fetchUserAgent = "Mozilla/5.0 (compatible; PicoClaw/1.0; +https://github.com/sipeed/picoclaw)"
+	robotsAgent    = "picoclaw"
+
+	defaultFetchTimeout   = 30 * time.Second
+	defaultFetchMaxSize   = 2 << 20
+	defaultFetchPageChars = 8000
+
+	// Fetched pages are kept this long, so reading on to the next part
+	// doesn't download the page again.
+	fetchedPageTTL = 15 * time.Minute
+	fetchedPages   = 16
+	robotsTTL      = time.Hour
+)
+
+// FetchURLTool reads a web page as markdown, keeping only its main content,
+// in parts small enough for small context windows. It obeys robots.txt.
+type FetchURLTool struct {
+	proxy        string
+	timeout      time.Duration
+	maxSize      int64
+	pageChars    int
+	ignoreRobots bool
+
+	mu     sync.Mutex
+	pages  map[string]*fetchedPage
+	robots map[string]*robotsEntry // By scheme://host
+}
+
+// FetchURLToolOptions configures a FetchURLTool; zero values take the
+// defaults.
+type FetchURLToolOptions struct {
+	Proxy        string
+	Timeout      time.Duration
+	MaxSize      int64 // Bytes downloaded at most
+	PageChars    int   // Characters per part
+	IgnoreRobots bool
+}
+
+type fetchedPage struct {
+	title   string
+	parts   []string
+	cut     bool // The download stopped at the size limit
+	fetched time.Time
+}
+
+type robotsEntry struct {
+	rules   robotsRules
+	fetched time.Time
+}
+
+func NewFetchURLTool(opts FetchURLToolOptions) *FetchURLTool {
+	t := &FetchURLTool{
+		proxy:        opts.Proxy,
+		timeout:      opts.Timeout,
+		maxSize:      opts.MaxSize,
+		pageChars:    opts.PageChars,
+		ignoreRobots: opts.IgnoreRobots,
+		pages:        make(map[string]*fetchedPage),
+		robots:       make(map[string]*robotsEntry),
+	}
+	if t.timeout <= 0 {
+		t.timeout = defaultFetchTimeout
+	}
+	if t.maxSize <= 0 {
+		t.maxSize = defaultFetchMaxSize
+	}
+	if t.pageChars <= 0 {
+		t.pageChars = defaultFetchPageChars
+	}
+	return t
+}
+
+func (t *FetchURLTool) Name() string {
+	return "fetch_url"
+}
+
+func (t *FetchURLTool) Description() string {
+	return "Read a web page as markdown: its main text, headings, lists and links, without menus, ads and " +
+		"scripts. Long pages come in parts; ask for the next part with page. Use it to read articles, " +
+		"documentation and search results."
+}
+
+func (t *FetchURLTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The http or https URL to read",
+			},
+			"page": map[string]any{
+				"type":        "integer",
+				"description": "Which part of a long page to read, from 1 (default 1)",
+				"minimum":     1.0,
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *FetchURLTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	rawURL, _ := args["url"].(string)
+	if rawURL == "" {
+		return ErrorResult("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid URL: %v", err))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrorResult("only http/https URLs are allowed")
+	}
+	if u.Host == "" {
+		return ErrorResult("missing domain in URL")
+	}
+	part := 1
+	if p, ok := args["page"].(float64); ok && p >= 1 {
+		part = int(p)
+	}
+
+	page, err := t.page(ctx, u)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if part > len(page.parts) {
+		return ErrorResult(fmt.Sprintf("%s has only %d part(s)", rawURL, len(page.parts)))
+	}
+
+	var sb strings.Builder
+	if page.title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n", page.title)
+	}
+	fmt.Fprintf(&sb, "URL: %s\n", rawURL)
+	if len(page.parts) > 1 {
+		fmt.Fprintf(&sb, "Part %d of %d\n", part, len(page.parts))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(page.parts[part-1])
+	switch {
+	case part < len(page.parts):
+		fmt.Fprintf(&sb, "\n\n[Part %d of %d. Call fetch_url with page=%d to read on.]",
+			part, len(page.parts), part+1)
+	case page.cut:
+		fmt.Fprintf(&sb, "\n\n[The page was cut off at %d KB.]", t.maxSize/1024)
+	}
+	return SilentResult(sb.String())
+}
+
+// page returns the page at u, split into parts, from those fetched lately
+// or else by downloading it.
+func (t *FetchURLTool) page(ctx context.Context, u *url.URL) (*fetchedPage, error) {
+	key := u.String()
+	t.mu.Lock()
+	page, ok := t.pages[key]
+	t.mu.Unlock()
+	if ok && time.Since(page.fetched) < fetchedPageTTL {
+		return page, nil
+	}
+
+	page, err := t.fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, p := range t.pages {
+		if time.Since(p.fetched) >= fetchedPageTTL {
+			delete(t.pages, k)
+		}
+	}
+	if len(t.pages) >= fetchedPages {
+		var oldest string
+		for k, p := range t.pages {
+			if oldest == "" || p.fetched.Before(t.pages[oldest].fetched) {
+				oldest = k
+			}
+		}
+		delete(t.pages, oldest)
+	}
+	t.pages[key] = page
+	return page, nil
+}
+
+func (t *FetchURLTool) fetch(ctx context.Context, u *url.URL) (*fetchedPage, error) {
+	if err := t.checkRobots(ctx, u); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+
+	client, err := createHTTPClient(t.proxy, t.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("stopped after 5 redirects")
+		}
+		return t.checkRobots(req.Context(), req.URL)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s didn't answer within %s", u.Host, t.timeout)
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%s answered %s", u, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	page := &fetchedPage{fetched: time.Now()}
+	if int64(len(body)) > t.maxSize {
+		body, page.cut = body[:t.maxSize], true
+	}
+
+	var text string
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	switch {
+	case strings.Contains(contentType, "html") || contentType == "" && looksLikeHTML(body):
+		page.title, text = readableMarkdown(string(body), resp.Request.URL)
+	case strings.Contains(contentType, "json"):
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			body = indented.Bytes()
+		}
+		text = "```json\n" + string(body) + "\n```"
+	case strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "xml") ||
+		contentType == "" && utf8.Valid(body):
+		text = string(body)
+	default:
+		return nil, fmt.Errorf("%s is %s, not a page that can be read as text", u, contentType)
+	}
+	if strings.TrimSpace(text) == "" {
+		text = "(The page has no readable text; it may need JavaScript.)"
+	}
+	page.parts = splitParts(strings.ToValidUTF8(text, ""), t.pageChars)
+	return page, nil
+}
+
+func looksLikeHTML(body []byte) bool {
+	head := strings.ToLower(string(body[:min(len(body), 512)]))
+	return strings.Contains(head, "<!doctype html") || strings.Contains(head, "<html")
+}
+
+// splitParts splits text into parts of at most size bytes, between
+// paragraphs where it can, else between lines or words.
+func splitParts(text string, size int) []string {
+	var (
+		parts []string
+		cur   strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, para := range strings.Split(text, "\n\n") {
+		for len(para) > size {
+			flush()
+			cut := strings.LastIndex(para[:size], "\n")
+			if cut <= 0 {
+				cut = strings.LastIndex(para[:size], " ")
+			}
+			if cut <= 0 {
+				cut = size
+				for cut > 0 && !utf8.RuneStart(para[cut]) {
+					cut--
+				}
+			}
+			parts = append(parts, para[:cut])
+			para = strings.TrimLeft(para[cut:], " \n")
+		}
+		if cur.Len() > 0 && cur.Len()+2+len(para) > size {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(para)
+	}
+	flush()
+	if len(parts) == 0 {
+		parts = []string{""}
+	}
+	return parts
+}
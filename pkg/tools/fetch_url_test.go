@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head><title>Tide &amp; Time</title><script>var x = 1;</script></head>
+<body>
+<header><a href="/">Home</a> <a href="/about">About</a></header>
+<nav><ul><li>Menu item</li></ul></nav>
+<article>
+  <header><h1>Why  the tide
+    turns</h1></header>
+  <p>The moon <b>pulls</b> the sea, see <a href="/moon?x=1&amp;y=2">the moon</a>.</p>
+  <div class="share-buttons"><a href="https://social.example">Share</a></div>
+  <ol><li>Spring tides</li><li>Neap tides</li></ol>
+  <pre><code>high  = 2
+low   = 0</code></pre>
+</article>
+<aside>Related posts</aside>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestReadableMarkdown(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/tides")
+	title, md := readableMarkdown(articlePage, base)
+	if title != "Tide & Time" {
+		t.Errorf("title = %q", title)
+	}
+	want := "# Why the tide turns\n\n" +
+		"The moon **pulls** the sea, see [the moon](https://example.com/moon?x=1&y=2).\n\n" +
+		"1. Spring tides\n2. Neap tides\n\n" +
+		"```\nhigh  = 2\nlow   = 0\n```"
+	if md != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", md, want)
+	}
+}
+
+func TestReadableMarkdown_WholeBody(t *testing.T) {
+	page := `<html><body><header>Site name</header><div id="sidebar">Links</div>` +
+		`<h2>Notes</h2><ul><li>One<ul><li>Nested</li></ul></li><li>Two</li></ul></body></html>`
+	_, md := readableMarkdown(page, nil)
+	want := "## Notes\n\n- One\n  - Nested\n- Two"
+	if md != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", md, want)
+	}
+}
+
+// newFetchServer serves a robots.txt and pages, counting page requests.
+func newFetchServer(t *testing.T, robots string, pages map[string]string) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			if robots == "" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, robots)
+			return
+		}
+		hits.Add(1)
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasPrefix(body, "<") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestFetchURLTool_Parts(t *testing.T) {
+	var paras []string
+	for i := 1; i <= 20; i++ {
+		paras = append(paras, fmt.Sprintf("<p>Paragraph %d %s</p>", i, strings.Repeat("word ", 10)))
+	}
+	server, hits := newFetchServer(t, "", map[string]string{
+		"/long": "<html><body><main>" + strings.Join(paras, "") + "</main></body></html>",
+	})
+	tool := NewFetchURLTool(FetchURLToolOptions{PageChars: 300})
+	ctx := context.Background()
+
+	first := tool.Execute(ctx, map[string]any{"url": server.URL + "/long"})
+	if first.IsError {
+		t.Fatalf("Execute() error: %s", first.ForLLM)
+	}
+	if !strings.Contains(first.ForLLM, "Part 1 of 5") || !strings.Contains(first.ForLLM, "page=2") ||
+		!strings.Contains(first.ForLLM, "Paragraph 1 ") || strings.Contains(first.ForLLM, "Paragraph 5 ") {
+		t.Errorf("first part =\n%s", first.ForLLM)
+	}
+
+	last := tool.Execute(ctx, map[string]any{"url": server.URL + "/long", "page": float64(5)})
+	if last.IsError || !strings.Contains(last.ForLLM, "Paragraph 20 ") || strings.Contains(last.ForLLM, "page=6") {
+		t.Errorf("last part =\n%s", last.ForLLM)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("page downloaded %d times, want once", hits.Load())
+	}
+
+	if result := tool.Execute(ctx, map[string]any{"url": server.URL + "/long", "page": float64(6)}); !result.IsError {
+		t.Errorf("part 6 = %q, want an error", result.ForLLM)
+	}
+}
+
+func TestFetchURLTool_Robots(t *testing.T) {
+	robots := "User-agent: *\nDisallow: /\n\nUser-agent: PicoClaw\nDisallow: /private\nAllow: /private/ok\n"
+	pages := map[string]string{"/public": "hello", "/private/x": "secret", "/private/ok": "fine"}
+	server, hits := newFetchServer(t, robots, pages)
+	ctx := context.Background()
+
+	tool := NewFetchURLTool(FetchURLToolOptions{})
+	for path, allowed := range map[string]bool{"/public": true, "/private/x": false, "/private/ok": true} {
+		result := tool.Execute(ctx, map[string]any{"url": server.URL + path})
+		if result.IsError == allowed {
+			t.Errorf("%s: IsError = %v (%s), want allowed = %v", path, result.IsError, result.ForLLM, allowed)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("pages downloaded = %d, want 2", hits.Load())
+	}
+
+	tool = NewFetchURLTool(FetchURLToolOptions{IgnoreRobots: true})
+	if result := tool.Execute(ctx, map[string]any{"url": server.URL + "/private/x"}); result.IsError {
+		t.Errorf("with ignore_robots: %s", result.ForLLM)
+	}
+}
+
+func TestFetchURLTool_SizeLimit(t *testing.T) {
+	server, _ := newFetchServer(t, "", map[string]string{"/big": strings.Repeat("x", 5000)})
+	tool := NewFetchURLTool(FetchURLToolOptions{MaxSize: 2048})
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/big"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if strings.Count(result.ForLLM, "x") != 2048 || !strings.Contains(result.ForLLM, "cut off at 2 KB") {
+		t.Errorf("result has %d x's:\n%.200s", strings.Count(result.ForLLM, "x"), result.ForLLM)
+	}
+}
+
+func TestFetchURLTool_InvalidURL(t *testing.T) {
+	tool := NewFetchURLTool(FetchURLToolOptions{})
+	for _, u := range []string{"", "ftp://example.com/file", "http://"} {
+		if result := tool.Execute(context.Background(), map[string]any{"url": u}); !result.IsError {
+			t.Errorf("url %q: want an error, got %q", u, result.ForLLM)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	robots := `# comment
+User-agent: Googlebot
+User-agent: *
+Disallow: /tmp/
+Disallow: /*.pdf$
+Allow: /tmp/public
+Crawl-delay: 5
+
+User-agent: otherbot
+Disallow: /
+`
+	rules := parseRobots(strings.NewReader(robots), robotsAgent)
+	tests := map[string]bool{
+		"/":                true,
+		"/tmp/file":        false,
+		"/tmp/public/page": true,
+		"/docs/a.pdf":      false,
+		"/docs/a.pdf?x=1":  true,
+		"/other":           true,
+	}
+	for path, want := range tests {
+		if got := rules.allowed(path); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if !parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), robotsAgent).allowed("/x") {
+		t.Error(`an empty "Disallow:" should allow everything`)
+	}
+}
+
+func TestSplitParts(t *testing.T) {
+	text := "aaaa bbbb\n\ncccc\n\n" + strings.Repeat("dddd ", 5)
+	parts := splitParts(text, 12)
+	want := []string{"aaaa bbbb", "cccc", "dddd dddd", "dddd dddd", "dddd "}
+	if fmt.Sprint(parts) != fmt.Sprint(want) {
+		t.Errorf("splitParts() = %q, want %q", parts, want)
+	}
+	for _, p := range splitParts(strings.Repeat("é", 10), 5) {
+		if !strings.HasPrefix(p, "é") || len(p) > 5 {
+			t.Errorf("part %q splits a character or is too long", p)
+		}
+	}
+}
@@ -63,6 +63,22 @@ func validatePath(path, workspace string, restrict bool) (string, error) {
 	return absPath, nil
 }
 
+// resolveAllowedPath is validatePath for the tools that use files without
+// going through the file tools' fileSystem: it also accepts absolute paths
+// in one of allowPaths, as GuardFiles does.
+func resolveAllowedPath(path, workspace string, restrict bool, allowPaths []string) (string, error) {
+	resolved, err := validatePath(path, workspace, restrict)
+	if err == nil || !filepath.IsAbs(path) {
+		return resolved, err
+	}
+	for _, allowed := range allowPaths {
+		if p, aerr := validatePath(path, allowed, true); aerr == nil {
+			return p, nil
+		}
+	}
+	return "", err
+}
+
 func resolveExistingAncestor(path string) (string, error) {
 	for current := filepath.Clean(path); ; current = filepath.Dir(current) {
 		if resolved, err := filepath.EvalSymlinks(current); err == nil {
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/encrypt"
+)
+
+// fileTool is implemented by the tools that use files.
+type fileTool interface {
+	wrapFS(wrap func(fileSystem) fileSystem)
+}
+
+func (t *ReadFileTool) wrapFS(wrap func(fileSystem) fileSystem)   { t.fs = wrap(t.fs) }
+func (t *WriteFileTool) wrapFS(wrap func(fileSystem) fileSystem)  { t.fs = wrap(t.fs) }
+func (t *EditFileTool) wrapFS(wrap func(fileSystem) fileSystem)   { t.fs = wrap(t.fs) }
+func (t *AppendFileTool) wrapFS(wrap func(fileSystem) fileSystem) { t.fs = wrap(t.fs) }
+func (t *PatchFileTool) wrapFS(wrap func(fileSystem) fileSystem)  { t.fs = wrap(t.fs) }
+func (t *ListDirTool) wrapFS(wrap func(fileSystem) fileSystem)    { t.fs = wrap(t.fs) }
+
+// EncryptFiles makes the file tools among ts decrypt encrypted files when
+// reading, and encrypt with key what they write under dirs, so the agent can
+// keep editing its memory when it is stored encrypted. Relative paths are
+// resolved against workspace. Other tools are left alone.
+func EncryptFiles(key *encrypt.Key, workspace string, dirs []string, ts ...Tool) {
+	if key == nil {
+		return
+	}
+	for _, t := range ts {
+		if ft, ok := t.(fileTool); ok {
+			ft.wrapFS(func(inner fileSystem) fileSystem {
+				return &encryptedFs{fileSystem: inner, key: key, workspace: workspace, dirs: dirs}
+			})
+		}
+	}
+}
+
+// encryptedFs seals files written under dirs and opens sealed files on read.
+type encryptedFs struct {
+	fileSystem
+	key       *encrypt.Key
+	workspace string
+	dirs      []string
+}
+
+func (e *encryptedFs) ReadFile(path string) ([]byte, error) {
+	data, err := e.fileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return e.key.Open(data)
+}
+
+func (e *encryptedFs) WriteFile(path string, data []byte) error {
+	if e.sealed(path) {
+		var err error
+		if data, err = e.key.Seal(data); err != nil {
+			return err
+		}
+	}
+	return e.fileSystem.WriteFile(path, data)
+}
+
+// sealed reports whether path is under one of the encrypted directories.
+func (e *encryptedFs) sealed(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.workspace, path)
+	}
+	for _, dir := range e.dirs {
+		if isWithinWorkspace(path, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupDir is the workspace directory the file tools keep the previous
+// versions of the files they change in.
+const BackupDir = ".backups"
+
+// FileGuardOptions configures GuardFiles.
+type FileGuardOptions struct {
+	AllowPaths []string // Absolute files and directories outside the workspace
+	MaxSize    int64    // Largest file read or written, in bytes; 0 means no limit
+	Backups    int      // Previous versions kept per file; 0 means none
+}
+
+// GuardFiles makes the file tools among ts also reach opts.AllowPaths,
+// refuse files larger than opts.MaxSize and back files up before changing
+// them. Call it before EncryptFiles, so backups of encrypted files stay
+// encrypted. Other tools are left alone.
+func GuardFiles(workspace string, opts FileGuardOptions, ts ...Tool) {
+	if len(opts.AllowPaths) == 0 && opts.MaxSize <= 0 && opts.Backups <= 0 {
+		return
+	}
+	for _, t := range ts {
+		if ft, ok := t.(fileTool); ok {
+			ft.wrapFS(func(inner fileSystem) fileSystem {
+				return &guardedFs{fileSystem: inner, outside: &hostFs{}, workspace: workspace, opts: opts}
+			})
+		}
+	}
+}
+
+// guardedFs enforces FileGuardOptions around another fileSystem.
+type guardedFs struct {
+	fileSystem
+	outside   fileSystem // Used for the allowed paths outside the workspace
+	workspace string
+	opts      FileGuardOptions
+}
+
+// route picks the fileSystem for path: the allowed paths outside the
+// workspace bypass the inner one, which may be confined to the workspace.
+func (g *guardedFs) route(path string) fileSystem {
+	if !filepath.IsAbs(path) || isWithinWorkspace(path, g.workspace) {
+		return g.fileSystem
+	}
+	for _, allowed := range g.opts.AllowPaths {
+		if _, err := validatePath(path, allowed, true); err == nil {
+			return g.outside
+		}
+	}
+	return g.fileSystem
+}
+
+func (g *guardedFs) ReadFile(path string) ([]byte, error) {
+	data, err := g.route(path).ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkSize(path, len(data)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (g *guardedFs) WriteFile(path string, data []byte) error {
+	if err := g.checkSize(path, len(data)); err != nil {
+		return err
+	}
+	target := g.route(path)
+	if g.opts.Backups > 0 {
+		// Relative paths are relative to the workspace, except on the
+		// unrestricted host filesystem.
+		abs := path
+		if _, host := target.(*hostFs); host {
+			abs, _ = filepath.Abs(path)
+		} else if !filepath.IsAbs(path) {
+			abs = filepath.Join(g.workspace, path)
+		}
+		old, err := target.ReadFile(path)
+		switch {
+		case isWithinWorkspace(abs, filepath.Join(g.workspace, BackupDir)):
+		case err == nil:
+			if err := g.backup(abs, old); err != nil {
+				return fmt.Errorf("not written, the backup failed: %w", err)
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+	}
+	return target.WriteFile(path, data)
+}
+
+func (g *guardedFs) ReadDir(path string) ([]os.DirEntry, error) {
+	return g.route(path).ReadDir(path)
+}
+
+func (g *guardedFs) checkSize(path string, size int) error {
+	if g.opts.MaxSize > 0 && int64(size) > g.opts.MaxSize {
+		return fmt.Errorf("%s is %d KB, over the %d KB limit for file tools; use exec with head, tail or grep",
+			path, size/1024, g.opts.MaxSize/1024)
+	}
+	return nil
+}
+
+// backup saves data, the current content of the file at abs, as its
+// newest backup and deletes the oldest beyond the number kept.
+func (g *guardedFs) backup(abs string, data []byte) error {
+	name := backupName(g.workspace, abs)
+	dir := filepath.Join(g.workspace, BackupDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	stamp := time.Now().UTC().Format("20060102-150405.000000000")
+	if err := os.WriteFile(filepath.Join(dir, name+"@"+stamp), data, 0o600); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var versions []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), name+"@") {
+			versions = append(versions, e.Name())
+		}
+	}
+	sort.Strings(versions)
+	for len(versions) > g.opts.Backups {
+		os.Remove(filepath.Join(dir, versions[0]))
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// backupName flattens the path of a file into the name of its backups,
+// e.g. "notes%todo.md" in the workspace or "%etc%hosts" outside it.
+func backupName(workspace, abs string) string {
+	if rel, err := filepath.Rel(workspace, abs); err == nil && filepath.IsLocal(rel) {
+		abs = rel
+	}
+	return strings.NewReplacer("/", "%", ":", "%").Replace(filepath.ToSlash(abs))
+}
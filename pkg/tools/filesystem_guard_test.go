@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGuardFiles_AllowPaths(t *testing.T) {
+	workspace := t.TempDir()
+	etc := t.TempDir()
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(etc, "app.conf"), []byte("debug = false\n"), 0o644)
+	os.WriteFile(filepath.Join(other, "secret"), []byte("hunter2"), 0o644)
+
+	read := NewReadFileTool(workspace, true)
+	edit := NewEditFileTool(workspace, true)
+	GuardFiles(workspace, FileGuardOptions{AllowPaths: []string{etc}}, read, edit)
+
+	result := edit.Execute(context.Background(), map[string]any{
+		"path": filepath.Join(etc, "app.conf"), "old_text": "false", "new_text": "true",
+	})
+	if result.IsError {
+		t.Fatalf("editing an allowed path failed: %s", result.ForLLM)
+	}
+	if got, _ := os.ReadFile(filepath.Join(etc, "app.conf")); string(got) != "debug = true\n" {
+		t.Errorf("app.conf = %q", got)
+	}
+
+	for _, path := range []string{
+		filepath.Join(other, "secret"),
+		filepath.Join(etc, "..", filepath.Base(other), "secret"),
+	} {
+		if result := read.Execute(context.Background(), map[string]any{"path": path}); !result.IsError {
+			t.Errorf("read %s, outside the workspace and allowlist: %q", path, result.ForLLM)
+		}
+	}
+}
+
+func TestGuardFiles_MaxSize(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "big.log"), []byte(strings.Repeat("x", 4096)), 0o644)
+	read := NewReadFileTool(workspace, true)
+	write := NewWriteFileTool(workspace, true)
+	GuardFiles(workspace, FileGuardOptions{MaxSize: 2048}, read, write)
+
+	result := read.Execute(context.Background(), map[string]any{"path": "big.log"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "over the 2 KB limit") {
+		t.Errorf("read = %q, want the size limit", result.ForLLM)
+	}
+	result = write.Execute(context.Background(), map[string]any{
+		"path": "out.txt", "content": strings.Repeat("y", 3000),
+	})
+	if !result.IsError {
+		t.Error("wrote a file over the limit")
+	}
+	result = write.Execute(context.Background(), map[string]any{"path": "out.txt", "content": "small"})
+	if result.IsError {
+		t.Errorf("small write failed: %s", result.ForLLM)
+	}
+}
+
+func TestGuardFiles_Backups(t *testing.T) {
+	workspace := t.TempDir()
+	write := NewWriteFileTool(workspace, true)
+	GuardFiles(workspace, FileGuardOptions{Backups: 2}, write)
+
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		result := write.Execute(context.Background(), map[string]any{"path": "conf/app.ini", "content": content})
+		if result.IsError {
+			t.Fatalf("write %s: %s", content, result.ForLLM)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(workspace, BackupDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "conf%app.ini@") {
+			t.Errorf("unexpected backup %s", e.Name())
+		}
+		data, _ := os.ReadFile(filepath.Join(workspace, BackupDir, e.Name()))
+		kept = append(kept, string(data))
+	}
+	if strings.Join(kept, ",") != "v2,v3" {
+		t.Errorf("backups = %v, want the last two previous versions", kept)
+	}
+}
+
+func TestBackupName(t *testing.T) {
+	if got := backupName("/ws", "/ws/notes/todo.md"); got != "notes%todo.md" {
+		t.Errorf("backupName(in workspace) = %q", got)
+	}
+	if got := backupName("/ws", "/etc/hosts"); got != "%etc%hosts" {
+		t.Errorf("backupName(outside) = %q", got)
+	}
+}
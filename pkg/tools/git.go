@@ -0,0 +1,535 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/forge"
+)
+
+const (
+	defaultGitTimeout  = 60 * time.Second
+	defaultGitLogLimit = 10
+	maxGitLogLimit     = 100
+	defaultGitRemote   = "origin"
+)
+
+// GitToolOptions configures a GitTool.
+type GitToolOptions struct {
+	Repos       []string // Directories the repositories must be in; default: the workspace
+	Workspace   string   // Relative repository paths are taken in it
+	AuthorName  string   // Of commits; default: git's configuration
+	AuthorEmail string
+	AllowPush   bool
+	Timeout     time.Duration
+	Forges      []*forge.Client
+}
+
+// GitTool runs git in the allowed repositories: status, diffs, the log,
+// branches, commits and, when allowed, pushes. With forges configured it
+// also lists the issues and pull requests of a repository's remote and
+// opens pull requests.
+type GitTool struct {
+	opts  GitToolOptions
+	roots []string // Repos, absolute with symlinks resolved
+
+	// gitPath is the git run; replaced in tests.
+	gitPath string
+}
+
+func NewGitTool(opts GitToolOptions) *GitTool {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultGitTimeout
+	}
+	if len(opts.Repos) == 0 {
+		opts.Repos = []string{opts.Workspace}
+	}
+	t := &GitTool{opts: opts, gitPath: "git"}
+	for _, dir := range opts.Repos {
+		dir = expandHomeDir(dir)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(opts.Workspace, dir)
+		}
+		t.roots = append(t.roots, realPath(dir))
+	}
+	return t
+}
+
+// realPath returns path with its symlinks resolved, or cleaned when it
+// can't be.
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
+
+func (t *GitTool) Name() string {
+	return "git"
+}
+
+func (t *GitTool) actions() []string {
+	actions := []string{"status", "diff", "log", "branch", "commit"}
+	if t.opts.AllowPush {
+		actions = append(actions, "push")
+	}
+	if len(t.opts.Forges) > 0 {
+		actions = append(actions, "issues", "pull_requests", "open_pr")
+	}
+	return actions
+}
+
+func (t *GitTool) Description() string {
+	var sb strings.Builder
+	sb.WriteString("Work with git repositories in " + strings.Join(t.opts.Repos, ", ") + ": 'status', 'diff' " +
+		"(of the working tree, the staged changes or against a ref), 'log', 'branch' (list, switch to or create " +
+		"one), 'commit' (the files given, or all changes).")
+	if t.opts.AllowPush {
+		sb.WriteString(" 'push' sends the current branch to its remote, once the user approves.")
+	}
+	if len(t.opts.Forges) > 0 {
+		hosts := make([]string, 0, len(t.opts.Forges))
+		for _, f := range t.opts.Forges {
+			hosts = append(hosts, f.Host())
+		}
+		sb.WriteString(" For repositories on " + strings.Join(hosts, ", ") + ": 'issues' and 'pull_requests' " +
+			"list those of the remote's repository, and 'open_pr' opens a pull request from the current branch, " +
+			"once pushed and the user approves.")
+	}
+	return sb.String()
+}
+
+func (t *GitTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        t.actions(),
+				"description": "What to do",
+			},
+			"repo": map[string]any{
+				"type":        "string",
+				"description": "Path of the repository, relative to the workspace or absolute. Default: " + t.opts.Repos[0],
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "diff: the commit or branch to compare with; log: the branch or range to show",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "diff, log: only this file or directory",
+			},
+			"staged": map[string]any{
+				"type":        "boolean",
+				"description": "diff: show the changes staged for the next commit",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "log, issues, pull_requests: how many to show",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "branch: the branch to switch to; without it, branches are listed",
+			},
+			"create": map[string]any{
+				"type":        "boolean",
+				"description": "branch: create the branch from the current commit",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "commit: the commit message",
+			},
+			"files": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "commit: the files to commit; without them, the changes already staged",
+			},
+			"all": map[string]any{
+				"type":        "boolean",
+				"description": "commit: commit every change, new files included",
+			},
+			"remote": map[string]any{
+				"type":        "string",
+				"description": "push, issues, pull_requests, open_pr: the remote (default origin)",
+			},
+			"state": map[string]any{
+				"type":        "string",
+				"enum":        []string{"open", "closed", "all"},
+				"description": "issues, pull_requests: which to list (default open)",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "open_pr: the title",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "open_pr: the description",
+			},
+			"base": map[string]any{
+				"type":        "string",
+				"description": "open_pr: the branch to merge into (default: the repository's default branch)",
+			},
+			"draft": map[string]any{
+				"type":        "boolean",
+				"description": "open_pr: open it as a draft",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GitTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	repoArg, _ := args["repo"].(string)
+	repo, err := t.repoDir(ctx, repoArg)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	switch action {
+	case "status":
+		return t.output(t.git(ctx, repo, "status", "--short", "--branch"))
+	case "diff":
+		return t.diff(ctx, repo, args)
+	case "log":
+		return t.log(ctx, repo, args)
+	case "branch":
+		return t.branch(ctx, repo, args)
+	case "commit":
+		return t.commit(ctx, repo, args)
+	case "push":
+		if !t.opts.AllowPush {
+			return ErrorResult("pushing is not allowed")
+		}
+		return t.push(ctx, repo, args)
+	case "issues", "pull_requests", "open_pr":
+		if len(t.opts.Forges) == 0 {
+			return ErrorResult("no forge is configured")
+		}
+		return t.forgeAction(ctx, repo, action, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// repoDir returns the top directory of the repository at path, which must
+// be in one of the allowed directories.
+func (t *GitTool) repoDir(ctx context.Context, path string) (string, error) {
+	if path == "" {
+		path = t.opts.Repos[0]
+	}
+	path = expandHomeDir(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.opts.Workspace, path)
+	}
+	if !t.allowed(realPath(path)) {
+		return "", fmt.Errorf("%s is outside the directories git may be used in (%s)", path,
+			strings.Join(t.opts.Repos, ", "))
+	}
+	top, err := t.git(ctx, path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git repository", path)
+	}
+	top = realPath(strings.TrimSpace(top))
+	if !t.allowed(top) {
+		return "", fmt.Errorf("the repository of %s, %s, is outside the directories git may be used in (%s)",
+			path, top, strings.Join(t.opts.Repos, ", "))
+	}
+	return top, nil
+}
+
+func (t *GitTool) allowed(path string) bool {
+	for _, root := range t.roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// git runs git in dir and returns its output, or an error holding what it
+// wrote on stderr.
+func (t *GitTool) git(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	defer cancel()
+
+	global := []string{"-C", dir, "--no-pager", "-c", "color.ui=false", "-c", "core.quotepath=off"}
+	if t.opts.AuthorName != "" {
+		global = append(global, "-c", "user.name="+t.opts.AuthorName)
+	}
+	if t.opts.AuthorEmail != "" {
+		global = append(global, "-c", "user.email="+t.opts.AuthorEmail)
+	}
+	cmd := exec.CommandContext(ctx, t.gitPath, append(global, args...)...)
+	// Never wait for a password or passphrase nobody will type.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true", "LC_ALL=C")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	stdout := &limitedBuffer{max: defaultExecMaxOutput}
+	stderr := &limitedBuffer{max: defaultExecMaxOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = 2 * time.Second
+	err := cmd.Run()
+
+	output := stdout.String()
+	if stdout.dropped > 0 {
+		output += fmt.Sprintf("\n... (truncated, %d more chars)", stdout.dropped)
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", fmt.Errorf("git %s timed out after %v", args[0], t.opts.Timeout)
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, errors.New(msg)
+		}
+		return output, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	// Some commands, push for one, report on stderr.
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		output = strings.TrimRight(output, "\n") + "\n" + msg
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// output turns the result of t.git into a tool result.
+func (t *GitTool) output(out string, err error) *ToolResult {
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if out == "" {
+		out = "(no output)"
+	}
+	return SilentResult(out)
+}
+
+// checkRef returns an error if name can't be a ref or remote given to git,
+// such as one that would be read as an option.
+func checkRef(kind, name string) error {
+	if strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n\x00") {
+		return fmt.Errorf("%q is not a valid %s", name, kind)
+	}
+	return nil
+}
+
+func (t *GitTool) diff(ctx context.Context, repo string, args map[string]any) *ToolResult {
+	gitArgs := []string{"diff", "--stat", "--patch"}
+	if staged, _ := args["staged"].(bool); staged {
+		gitArgs = append(gitArgs, "--staged")
+	}
+	if ref, _ := args["ref"].(string); ref != "" {
+		if err := checkRef("ref", ref); err != nil {
+			return ErrorResult(err.Error())
+		}
+		gitArgs = append(gitArgs, ref)
+	}
+	gitArgs = append(gitArgs, "--")
+	if path, _ := args["path"].(string); path != "" {
+		gitArgs = append(gitArgs, path)
+	}
+	out, err := t.git(ctx, repo, gitArgs...)
+	if err == nil && out == "" {
+		return SilentResult("No changes")
+	}
+	return t.output(out, err)
+}
+
+func (t *GitTool) log(ctx context.Context, repo string, args map[string]any) *ToolResult {
+	limit := defaultGitLogLimit
+	if n, ok := args["limit"].(float64); ok && n > 0 {
+		limit = min(int(n), maxGitLogLimit)
+	}
+	gitArgs := []string{"log", "--date=short", "--format=%h %ad %an: %s%d", fmt.Sprintf("-n%d", limit)}
+	if ref, _ := args["ref"].(string); ref != "" {
+		if err := checkRef("ref", ref); err != nil {
+			return ErrorResult(err.Error())
+		}
+		gitArgs = append(gitArgs, ref)
+	}
+	gitArgs = append(gitArgs, "--")
+	if path, _ := args["path"].(string); path != "" {
+		gitArgs = append(gitArgs, path)
+	}
+	return t.output(t.git(ctx, repo, gitArgs...))
+}
+
+func (t *GitTool) branch(ctx context.Context, repo string, args map[string]any) *ToolResult {
+	name, _ := args["name"].(string)
+	if name == "" {
+		return t.output(t.git(ctx, repo, "branch", "-vv", "--all"))
+	}
+	if err := checkRef("branch name", name); err != nil {
+		return ErrorResult(err.Error())
+	}
+	if create, _ := args["create"].(bool); create {
+		if _, err := t.git(ctx, repo, "check-ref-format", "--branch", name); err != nil {
+			return ErrorResult(fmt.Sprintf("%q is not a valid branch name", name))
+		}
+		return t.output(t.git(ctx, repo, "switch", "--create", name))
+	}
+	return t.output(t.git(ctx, repo, "switch", name))
+}
+
+func (t *GitTool) commit(ctx context.Context, repo string, args map[string]any) *ToolResult {
+	message, _ := args["message"].(string)
+	if strings.TrimSpace(message) == "" {
+		return ErrorResult("message is required to commit")
+	}
+	files := listArg(args["files"])
+	all, _ := args["all"].(bool)
+	switch {
+	case all:
+		if _, err := t.git(ctx, repo, "add", "--all"); err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+	case len(files) > 0:
+		if _, err := t.git(ctx, repo, append([]string{"add", "--"}, files...)...); err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+	}
+	staged, err := t.git(ctx, repo, "diff", "--staged", "--name-only")
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if staged == "" {
+		return ErrorResult("nothing to commit: no changes are staged; give files, or set all")
+	}
+	return t.output(t.git(ctx, repo, "commit", "--message", message))
+}
+
+func (t *GitTool) push(ctx context.Context, repo string, args map[string]any) *ToolResult {
+	remote, _ := args["remote"].(string)
+	if remote == "" {
+		remote = defaultGitRemote
+	}
+	if err := checkRef("remote", remote); err != nil {
+		return ErrorResult(err.Error())
+	}
+	branch, err := t.git(ctx, repo, "branch", "--show-current")
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if branch == "" {
+		return ErrorResult("no branch is checked out; switch to one first")
+	}
+	return t.output(t.git(ctx, repo, "push", "--set-upstream", remote, "HEAD:refs/heads/"+branch))
+}
+
+// forgeAction lists the issues or pull requests of the repository the
+// remote points to, or opens a pull request there.
+func (t *GitTool) forgeAction(ctx context.Context, repo, action string, args map[string]any) *ToolResult {
+	remoteName, _ := args["remote"].(string)
+	if remoteName == "" {
+		remoteName = defaultGitRemote
+	}
+	if err := checkRef("remote", remoteName); err != nil {
+		return ErrorResult(err.Error())
+	}
+	remoteURL, err := t.git(ctx, repo, "remote", "get-url", remoteName)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	remote, err := forge.ParseRemote(remoteURL)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	var client *forge.Client
+	for _, f := range t.opts.Forges {
+		if strings.EqualFold(f.Host(), remote.Host) {
+			client = f
+			break
+		}
+	}
+	if client == nil {
+		return ErrorResult(fmt.Sprintf("no forge is configured for %s, where %s is", remote.Host, remote))
+	}
+
+	state, _ := args["state"].(string)
+	limit := 0
+	if n, ok := args["limit"].(float64); ok {
+		limit = int(n)
+	}
+	switch action {
+	case "issues":
+		issues, err := client.Issues(ctx, remote.Owner, remote.Repo, state, limit)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Can't list issues: %v", err)).WithError(err)
+		}
+		return SilentResult(formatIssues(remote, issues))
+	case "pull_requests":
+		prs, err := client.PullRequests(ctx, remote.Owner, remote.Repo, state, limit)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Can't list pull requests: %v", err)).WithError(err)
+		}
+		return SilentResult(formatPullRequests(remote, prs))
+	}
+
+	pr := forge.NewPullRequest{}
+	pr.Title, _ = args["title"].(string)
+	pr.Body, _ = args["body"].(string)
+	pr.Base, _ = args["base"].(string)
+	pr.Draft, _ = args["draft"].(bool)
+	if pr.Title == "" {
+		return ErrorResult("title is required to open a pull request")
+	}
+	if pr.Head, err = t.git(ctx, repo, "branch", "--show-current"); err != nil || pr.Head == "" {
+		return ErrorResult("no branch is checked out; switch to the branch to open a pull request from")
+	}
+	created, err := client.CreatePullRequest(ctx, remote.Owner, remote.Repo, pr)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't open the pull request: %v", err)).WithError(err)
+	}
+	return SilentResult(fmt.Sprintf("Opened pull request #%d (%s into %s): %s",
+		created.Number, created.Head, created.Base, created.URL))
+}
+
+func formatIssues(remote forge.Remote, issues []forge.Issue) string {
+	if len(issues) == 0 {
+		return "No issues in " + remote.String()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Issues of %s:", remote)
+	for _, it := range issues {
+		fmt.Fprintf(&sb, "\n- #%d %s", it.Number, it.Title)
+		if len(it.Labels) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(it.Labels, ", "))
+		}
+		fmt.Fprintf(&sb, " (%s, by %s", it.State, it.Author)
+		if it.Comments > 0 {
+			fmt.Fprintf(&sb, ", %d comments", it.Comments)
+		}
+		if !it.Updated.IsZero() {
+			fmt.Fprintf(&sb, ", updated %s", it.Updated.Format("2006-01-02"))
+		}
+		sb.WriteString(")\n  " + it.URL)
+	}
+	return sb.String()
+}
+
+func formatPullRequests(remote forge.Remote, prs []forge.PullRequest) string {
+	if len(prs) == 0 {
+		return "No pull requests in " + remote.String()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Pull requests of %s:", remote)
+	for _, pr := range prs {
+		state := pr.State
+		switch {
+		case pr.Merged:
+			state = "merged"
+		case pr.Draft:
+			state += ", draft"
+		}
+		fmt.Fprintf(&sb, "\n- #%d %s (%s into %s, %s, by %s)\n  %s",
+			pr.Number, pr.Title, pr.Head, pr.Base, state, pr.Author, pr.URL)
+	}
+	return sb.String()
+}
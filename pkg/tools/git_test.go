@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/forge"
+)
+
+// newTestRepo creates a workspace holding the repository "lamp", with one
+// commit on main, and returns the workspace.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	workspace := t.TempDir()
+	repo := filepath.Join(workspace, "lamp")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "init", "--quiet", "--initial-branch=main")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Lamp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "README.md")
+	runGit(t, repo, "commit", "--quiet", "-m", "Initial commit")
+	return workspace
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=t@example.com"},
+		args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitTool_StatusCommitLogDiff(t *testing.T) {
+	workspace := newTestRepo(t)
+	repo := filepath.Join(workspace, "lamp")
+	tool := NewGitTool(GitToolOptions{
+		Repos: []string{repo}, Workspace: workspace,
+		AuthorName: "PicoClaw", AuthorEmail: "picoclaw@example.com",
+	})
+	ctx := context.Background()
+
+	os.WriteFile(filepath.Join(repo, "wiring.md"), []byte("red to 5V\n"), 0o644)
+	result := tool.Execute(ctx, map[string]any{"action": "status"})
+	if result.IsError || !strings.Contains(result.ForLLM, "?? wiring.md") {
+		t.Fatalf("status = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "commit", "message": "Add wiring"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "nothing to commit") {
+		t.Errorf("commit without changes staged = %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "commit", "message": "Add wiring", "files": []any{"wiring.md"}})
+	if result.IsError {
+		t.Fatalf("commit: %s", result.ForLLM)
+	}
+	if author := runGit(t, repo, "log", "-1", "--format=%an <%ae>"); author != "PicoClaw <picoclaw@example.com>" {
+		t.Errorf("author = %q", author)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "log", "limit": float64(1)})
+	if !strings.Contains(result.ForLLM, "PicoClaw: Add wiring") || strings.Contains(result.ForLLM, "Initial commit") {
+		t.Errorf("log = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "diff"})
+	if result.ForLLM != "No changes" {
+		t.Errorf("diff of a clean tree = %q", result.ForLLM)
+	}
+	os.WriteFile(filepath.Join(repo, "wiring.md"), []byte("red to 3.3V\n"), 0o644)
+	result = tool.Execute(ctx, map[string]any{"action": "diff", "repo": "lamp"})
+	if !strings.Contains(result.ForLLM, "-red to 5V") || !strings.Contains(result.ForLLM, "+red to 3.3V") {
+		t.Errorf("diff = %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "diff", "ref": "--output=/tmp/x"})
+	if !result.IsError {
+		t.Errorf("diff with an option as ref ran: %q", result.ForLLM)
+	}
+}
+
+func TestGitTool_OnlyAllowedRepos(t *testing.T) {
+	workspace := newTestRepo(t)
+	other := newTestRepo(t)
+	tool := NewGitTool(GitToolOptions{Workspace: workspace})
+
+	for _, repo := range []string{filepath.Join(other, "lamp"), "../", "/"} {
+		result := tool.Execute(context.Background(), map[string]any{"action": "status", "repo": repo})
+		if !result.IsError || !strings.Contains(result.ForLLM, "outside") {
+			t.Errorf("status of %s = %q, want it refused", repo, result.ForLLM)
+		}
+	}
+	result := tool.Execute(context.Background(), map[string]any{"action": "status", "repo": "lamp"})
+	if result.IsError {
+		t.Errorf("status of the workspace's repository: %s", result.ForLLM)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"action": "status"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not in a git repository") {
+		t.Errorf("status of the workspace = %q", result.ForLLM)
+	}
+}
+
+func TestGitTool_BranchAndPush(t *testing.T) {
+	workspace := newTestRepo(t)
+	repo := filepath.Join(workspace, "lamp")
+	remote := filepath.Join(t.TempDir(), "lamp.git")
+	runGit(t, workspace, "init", "--quiet", "--bare", remote)
+	runGit(t, repo, "remote", "add", "origin", remote)
+	ctx := context.Background()
+
+	tool := NewGitTool(GitToolOptions{Repos: []string{repo}, Workspace: workspace})
+	if result := tool.Execute(ctx, map[string]any{"action": "push"}); !result.IsError {
+		t.Errorf("push without allow_push = %q", result.ForLLM)
+	}
+
+	tool = NewGitTool(GitToolOptions{Repos: []string{repo}, Workspace: workspace, AllowPush: true})
+	result := tool.Execute(ctx, map[string]any{"action": "branch", "name": "dimming", "create": true})
+	if result.IsError {
+		t.Fatalf("create branch: %s", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "branch"})
+	if !strings.Contains(result.ForLLM, "* dimming") {
+		t.Errorf("branches = %q", result.ForLLM)
+	}
+	if result := tool.Execute(ctx, map[string]any{"action": "branch", "name": "-D"}); !result.IsError {
+		t.Errorf("branch -D = %q", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "push"})
+	if result.IsError {
+		t.Fatalf("push: %s", result.ForLLM)
+	}
+	if got, want := runGit(t, remote, "rev-parse", "dimming"), runGit(t, repo, "rev-parse", "HEAD"); got != want {
+		t.Errorf("remote dimming = %s, want %s", got, want)
+	}
+	if upstream := runGit(t, repo, "rev-parse", "--abbrev-ref", "@{upstream}"); upstream != "origin/dimming" {
+		t.Errorf("upstream = %q", upstream)
+	}
+}
+
+func TestGitTool_Forge(t *testing.T) {
+	var opened map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/repos/alice/lamp/issues":
+			w.Write([]byte(`[{"number": 3, "title": "Flickers at night", "state": "open",
+				"html_url": "https://git.lan/alice/lamp/issues/3", "user": {"login": "bob"},
+				"labels": [{"name": "bug"}]}]`))
+		case "GET /api/v1/repos/alice/lamp":
+			w.Write([]byte(`{"default_branch": "main"}`))
+		case "POST /api/v1/repos/alice/lamp/pulls":
+			json.NewDecoder(r.Body).Decode(&opened)
+			w.Write([]byte(`{"number": 4, "html_url": "https://git.lan/alice/lamp/pulls/4",
+				"head": {"ref": "fix"}, "base": {"ref": "main"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := forge.NewClient(forge.Gitea, server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := newTestRepo(t)
+	repo := filepath.Join(workspace, "lamp")
+	runGit(t, repo, "remote", "add", "origin", "ssh://git@127.0.0.1:2222/alice/lamp.git")
+	runGit(t, repo, "remote", "add", "mirror", "https://github.com/alice/lamp.git")
+	runGit(t, repo, "switch", "--quiet", "-c", "fix")
+	tool := NewGitTool(GitToolOptions{Repos: []string{repo}, Workspace: workspace, Forges: []*forge.Client{client}})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "issues"})
+	if result.IsError || !strings.Contains(result.ForLLM, "#3 Flickers at night [bug] (open, by bob") {
+		t.Errorf("issues = %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "issues", "remote": "mirror"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no forge is configured for github.com") {
+		t.Errorf("issues of a remote without a forge = %q", result.ForLLM)
+	}
+
+	args := map[string]any{"action": "open_pr", "title": "Fix flicker", "body": "Fixes #3"}
+	result = tool.Execute(ctx, args)
+	if result.IsError || !strings.Contains(result.ForLLM, "#4") {
+		t.Fatalf("open_pr = %q", result.ForLLM)
+	}
+	if opened["head"] != "fix" || opened["base"] != "main" || opened["title"] != "Fix flicker" {
+		t.Errorf("pull request opened = %v", opened)
+	}
+}
@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/gpio"
+)
+
+const (
+	defaultBlinkTimes    = 3
+	maxBlinkTimes        = 20
+	defaultBlinkInterval = 500 * time.Millisecond
+)
+
+// GPIOTool reads and drives the GPIO pins the user allowed: inputs are only
+// read, outputs are also set.
+type GPIOTool struct {
+	inputs   []int
+	outputs  []int
+	mu       sync.Mutex
+	exported map[int]bool
+}
+
+func NewGPIOTool(inputs, outputs []int) *GPIOTool {
+	return &GPIOTool{inputs: inputs, outputs: outputs, exported: make(map[int]bool)}
+}
+
+func (t *GPIOTool) Name() string {
+	return "gpio"
+}
+
+func (t *GPIOTool) Description() string {
+	return fmt.Sprintf("Read and set the board's GPIO pins, by sysfs GPIO number. 'list' shows the pins with "+
+		"their values; 'read' reads a pin; 'write' sets an output pin to 0 or 1, 'toggle' flips it and 'blink' "+
+		"flips it a few times, e.g. to blink an LED. Input pins: %s. Output pins: %s.",
+		describePins(t.inputs), describePins(t.outputs))
+}
+
+func describePins(pins []int) string {
+	if len(pins) == 0 {
+		return "none"
+	}
+	s := make([]string, len(pins))
+	for i, pin := range pins {
+		s[i] = fmt.Sprint(pin)
+	}
+	return strings.Join(s, ", ")
+}
+
+func (t *GPIOTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "read", "write", "toggle", "blink"},
+				"description": "What to do",
+			},
+			"pin": map[string]any{
+				"type":        "integer",
+				"description": "The sysfs GPIO number of the pin",
+			},
+			"value": map[string]any{
+				"type":        "integer",
+				"enum":        []int{0, 1},
+				"description": "The value to set (write)",
+			},
+			"times": map[string]any{
+				"type":        "integer",
+				"description": "How many times to blink; default 3, at most 20",
+			},
+			"interval_ms": map[string]any{
+				"type":        "integer",
+				"description": "Milliseconds the pin stays on, then off, when blinking; default 500, at most 1000",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GPIOTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	if action == "list" {
+		return t.list()
+	}
+	p, ok := args["pin"].(float64)
+	if !ok {
+		return ErrorResult("pin is required")
+	}
+	pin := int(p)
+	output := slices.Contains(t.outputs, pin)
+	if !output && !slices.Contains(t.inputs, pin) {
+		return ErrorResult(fmt.Sprintf("GPIO %d is not allowed; allowed pins: inputs %s, outputs %s", pin,
+			describePins(t.inputs), describePins(t.outputs)))
+	}
+	if action != "read" && !output {
+		return ErrorResult(fmt.Sprintf("GPIO %d is an input; it can only be read", pin))
+	}
+	if err := t.export(pin, output); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	switch action {
+	case "read":
+		value, err := gpio.Read(pin)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Can't read GPIO %d: %v", pin, err)).WithError(err)
+		}
+		return SilentResult(fmt.Sprintf("GPIO %d is %d", pin, value))
+	case "write":
+		v, ok := args["value"].(float64)
+		if !ok || (v != 0 && v != 1) {
+			return ErrorResult("value must be 0 or 1")
+		}
+		if err := gpio.Write(pin, int(v)); err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		return SilentResult(fmt.Sprintf("GPIO %d set to %d", pin, int(v)))
+	case "toggle":
+		value, err := t.toggle(pin)
+		if err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		return SilentResult(fmt.Sprintf("GPIO %d set to %d", pin, value))
+	case "blink":
+		return t.blink(ctx, pin, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// export exports pin the first time it is used.
+func (t *GPIOTool) export(pin int, output bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.exported[pin] {
+		return nil
+	}
+	direction := "in"
+	if output {
+		direction = "out"
+	}
+	if err := gpio.Export(pin, direction); err != nil {
+		return err
+	}
+	t.exported[pin] = true
+	return nil
+}
+
+func (t *GPIOTool) toggle(pin int) (int, error) {
+	value, err := gpio.Read(pin)
+	if err != nil {
+		return 0, fmt.Errorf("read GPIO %d: %w", pin, err)
+	}
+	return 1 - value, gpio.Write(pin, 1-value)
+}
+
+// blink toggles pin twice per blink, so it ends as it started.
+func (t *GPIOTool) blink(ctx context.Context, pin int, args map[string]any) *ToolResult {
+	times := defaultBlinkTimes
+	if n, ok := args["times"].(float64); ok && n >= 1 {
+		times = min(int(n), maxBlinkTimes)
+	}
+	interval := defaultBlinkInterval
+	if ms, ok := args["interval_ms"].(float64); ok && ms > 0 {
+		interval = min(max(time.Duration(ms)*time.Millisecond, 20*time.Millisecond), time.Second)
+	}
+	for i := range 2 * times {
+		if _, err := t.toggle(pin); err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		if i == 2*times-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			if i%2 == 0 {
+				t.toggle(pin)
+			}
+			return ErrorResult("blinking was interrupted")
+		case <-time.After(interval):
+		}
+	}
+	return SilentResult(fmt.Sprintf("GPIO %d blinked %d times", pin, times))
+}
+
+func (t *GPIOTool) list() *ToolResult {
+	var sb strings.Builder
+	sb.WriteString("GPIO pins:")
+	for _, group := range []struct {
+		kind   string
+		pins   []int
+		output bool
+	}{{"input", t.inputs, false}, {"output", t.outputs, true}} {
+		for _, pin := range group.pins {
+			fmt.Fprintf(&sb, "\n- GPIO %d (%s): ", pin, group.kind)
+			if err := t.export(pin, group.output); err != nil {
+				sb.WriteString(err.Error())
+				continue
+			}
+			if value, err := gpio.Read(pin); err != nil {
+				sb.WriteString("can't read: " + err.Error())
+			} else {
+				fmt.Fprint(&sb, value)
+			}
+		}
+	}
+	return SilentResult(sb.String())
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/gpio"
+)
+
+// fakeGPIO sets up sysfs pins with the given values.
+func fakeGPIO(t *testing.T, values map[int]string) {
+	t.Helper()
+	gpio.Root = t.TempDir()
+	t.Cleanup(func() { gpio.Root = "/sys/class/gpio" })
+	for pin, value := range values {
+		os.MkdirAll(filepath.Dir(gpio.ValuePath(pin)), 0o755)
+		os.WriteFile(gpio.ValuePath(pin), []byte(value+"\n"), 0o644)
+	}
+}
+
+func TestGPIOTool(t *testing.T) {
+	fakeGPIO(t, map[int]string{5: "1", 17: "0"})
+	tool := NewGPIOTool([]int{5}, []int{17})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "pin": 5.0})
+	if result.IsError || result.ForLLM != "GPIO 5 is 1" {
+		t.Errorf("read = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "write", "pin": 17.0, "value": 1.0})
+	if v, _ := gpio.Read(17); result.IsError || v != 1 {
+		t.Errorf("write = %+v, pin is %d", result, v)
+	}
+	tool.Execute(ctx, map[string]any{"action": "toggle", "pin": 17.0})
+	if v, _ := gpio.Read(17); v != 0 {
+		t.Errorf("after toggle, pin is %d, want 0", v)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "blink", "pin": 17.0, "times": 2.0, "interval_ms": 20.0})
+	if v, _ := gpio.Read(17); result.IsError || v != 0 {
+		t.Errorf("blink = %+v, pin ends at %d, want 0", result, v)
+	}
+	if data, _ := os.ReadFile(filepath.Join(gpio.Root, "gpio17", "direction")); string(data) != "out" {
+		t.Errorf("direction of the output = %q", data)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if !strings.Contains(result.ForLLM, "GPIO 5 (input): 1") || !strings.Contains(result.ForLLM, "GPIO 17 (output): 0") {
+		t.Errorf("list = %q", result.ForLLM)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "write", "pin": 5.0, "value": 1.0},
+		{"action": "read", "pin": 6.0},
+		{"action": "write", "pin": 17.0, "value": 2.0},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+)
+
+// maxHAStates bounds the states listed at once.
+const maxHAStates = 100
+
+// HomeAssistantTool reads and controls the entities of a Home Assistant
+// instance that its client allows.
+type HomeAssistantTool struct {
+	client   *homeassistant.Client
+	readOnly bool
+}
+
+// NewHomeAssistantTool returns the tool of client. With readOnly, services
+// can't be called.
+func NewHomeAssistantTool(client *homeassistant.Client, readOnly bool) *HomeAssistantTool {
+	return &HomeAssistantTool{client: client, readOnly: readOnly}
+}
+
+func (t *HomeAssistantTool) Name() string {
+	return "homeassistant"
+}
+
+func (t *HomeAssistantTool) Description() string {
+	desc := "Home Assistant smart home. 'states' lists devices and sensors (entities) with their state, " +
+		"optionally filtered; 'get' shows one entity's state and attributes."
+	if !t.readOnly {
+		desc += " 'call' calls a service on entities, e.g. service='light.turn_on', entity_id='light.kitchen', " +
+			"data={\"brightness_pct\": 50}; 'services' lists the services available and the data they take."
+	}
+	return desc + " Only the entities the user allowed are visible."
+}
+
+func (t *HomeAssistantTool) Parameters() map[string]any {
+	actions := []string{"states", "get"}
+	if !t.readOnly {
+		actions = append(actions, "call", "services")
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        actions,
+				"description": "What to do",
+			},
+			"filter": map[string]any{
+				"type": "string",
+				"description": "Entities to list (states): a domain ('light'), a glob ('sensor.*_temperature') " +
+					"or words of their names ('kitchen')",
+			},
+			"entity_id": map[string]any{
+				"type":        "string",
+				"description": "Entity ID, e.g. 'light.kitchen' (get, call); several are separated by commas (call)",
+			},
+			"service": map[string]any{
+				"type":        "string",
+				"description": "Service to call, as domain.service, e.g. 'light.turn_on' (call), or a domain (services)",
+			},
+			"data": map[string]any{
+				"type":        "object",
+				"description": "Service data besides entity_id, e.g. {\"temperature\": 21} (call)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *HomeAssistantTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "states":
+		filter, _ := args["filter"].(string)
+		return t.states(ctx, strings.TrimSpace(filter))
+	case "get":
+		entityID, _ := args["entity_id"].(string)
+		if entityID == "" {
+			return ErrorResult("entity_id is required to get a state")
+		}
+		state, err := t.client.State(ctx, strings.TrimSpace(entityID))
+		if err != nil {
+			return ErrorResult(err.Error()).WithError(err)
+		}
+		return SilentResult(describeHAState(state, true))
+	case "call":
+		if t.readOnly {
+			return ErrorResult("calling services is turned off")
+		}
+		return t.call(ctx, args)
+	case "services":
+		if t.readOnly {
+			return ErrorResult("calling services is turned off")
+		}
+		domain, _ := args["service"].(string)
+		domain, _, _ = strings.Cut(strings.TrimSpace(domain), ".")
+		return t.services(ctx, domain)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *HomeAssistantTool) states(ctx context.Context, filter string) *ToolResult {
+	states, err := t.client.States(ctx)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	var matched []homeassistant.State
+	for _, s := range states {
+		if matchesHAFilter(&s, filter) {
+			matched = append(matched, s)
+		}
+	}
+	if len(matched) == 0 {
+		if filter == "" {
+			return SilentResult("No entities are allowed")
+		}
+		return SilentResult(fmt.Sprintf("No entities match %q", filter))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d entities:", len(matched))
+	for i, s := range matched {
+		if i == maxHAStates {
+			fmt.Fprintf(&sb, "\n... and %d more; use a filter", len(matched)-i)
+			break
+		}
+		sb.WriteString("\n- " + describeHAState(&s, false))
+	}
+	return SilentResult(sb.String())
+}
+
+// matchesHAFilter reports whether filter, a domain, a glob or words, matches
+// an entity.
+func matchesHAFilter(s *homeassistant.State, filter string) bool {
+	switch {
+	case filter == "":
+		return true
+	case filter == s.Domain():
+		return true
+	case strings.ContainsAny(filter, "*?["):
+		ok, _ := path.Match(filter, s.EntityID)
+		return ok
+	}
+	text := strings.ToLower(s.EntityID + " " + s.Name())
+	for _, word := range strings.Fields(strings.ToLower(filter)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *HomeAssistantTool) call(ctx context.Context, args map[string]any) *ToolResult {
+	service, _ := args["service"].(string)
+	domain, name, ok := strings.Cut(strings.TrimSpace(service), ".")
+	if !ok {
+		return ErrorResult("service is required to call one, as domain.service, e.g. light.turn_on")
+	}
+	data := map[string]any{}
+	if extra, ok := args["data"].(map[string]any); ok {
+		for k, v := range extra {
+			data[k] = v
+		}
+	}
+	if entityID, ok := args["entity_id"].(string); ok && entityID != "" {
+		data["entity_id"] = homeassistant.EntityIDs(entityID)
+	}
+
+	changed, err := t.client.CallService(ctx, domain, name, data)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't call %s: %v", service, err)).WithError(err)
+	}
+	if len(changed) == 0 {
+		return SilentResult(fmt.Sprintf("Called %s; no state changed", service))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Called %s. Now:", service)
+	for _, s := range changed {
+		sb.WriteString("\n- " + describeHAState(&s, false))
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *HomeAssistantTool) services(ctx context.Context, domain string) *ToolResult {
+	services, err := t.client.Services(ctx, domain)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if len(services) == 0 {
+		return SilentResult("No services for the allowed entities")
+	}
+	var sb strings.Builder
+	sb.WriteString("Services:")
+	for _, s := range services {
+		fmt.Fprintf(&sb, "\n- %s.%s", s.Domain, s.Name)
+		if len(s.Fields) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(s.Fields, ", "))
+		}
+		if s.Description != "" {
+			sb.WriteString(": " + s.Description)
+		}
+	}
+	return SilentResult(sb.String())
+}
+
+// describeHAState returns an entity's ID, name and state with its unit and,
+// with attributes, its other attributes and when it last changed.
+func describeHAState(s *homeassistant.State, attributes bool) string {
+	var sb strings.Builder
+	sb.WriteString(s.EntityID)
+	if name := s.Name(); name != s.EntityID {
+		fmt.Fprintf(&sb, " (%s)", name)
+	}
+	sb.WriteString(": " + s.State)
+	if unit, ok := s.Attributes["unit_of_measurement"].(string); ok {
+		sb.WriteString(" " + unit)
+	}
+	if !attributes {
+		return sb.String()
+	}
+	if !s.LastChanged.IsZero() {
+		fmt.Fprintf(&sb, "\nLast changed: %s", s.LastChanged.Local().Format("2006-01-02 15:04:05"))
+	}
+	keys := make([]string, 0, len(s.Attributes))
+	for k := range s.Attributes {
+		if k != "friendly_name" && k != "unit_of_measurement" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "\n%s: %v", k, s.Attributes[k])
+	}
+	return sb.String()
+}
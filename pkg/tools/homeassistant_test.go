@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+)
+
+func newTestHomeAssistant(t *testing.T, readOnly bool) (*HomeAssistantTool, *[]string) {
+	t.Helper()
+	states := `[
+		{"entity_id": "light.kitchen", "state": "off", "attributes": {"friendly_name": "Kitchen light"}},
+		{"entity_id": "light.porch", "state": "on", "attributes": {"friendly_name": "Porch", "brightness": 120}},
+		{"entity_id": "sensor.kitchen_temperature", "state": "21.5",
+			"attributes": {"friendly_name": "Kitchen", "unit_of_measurement": "°C"}},
+		{"entity_id": "lock.front_door", "state": "locked", "attributes": {}}]`
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/states":
+			io.WriteString(w, states)
+		case r.URL.Path == "/api/states/light.porch":
+			io.WriteString(w, `{"entity_id": "light.porch", "state": "on",
+				"attributes": {"friendly_name": "Porch", "brightness": 120}, "last_changed": "2026-10-15T08:00:00Z"}`)
+		case r.URL.Path == "/api/services/light/turn_on":
+			body, _ := io.ReadAll(r.Body)
+			calls = append(calls, string(body))
+			io.WriteString(w, `[{"entity_id": "light.kitchen", "state": "on",
+				"attributes": {"friendly_name": "Kitchen light"}}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := homeassistant.NewClient(server.URL, "token", []string{"light.*", "sensor.*"})
+	return NewHomeAssistantTool(client, readOnly), &calls
+}
+
+func TestHomeAssistantTool_States(t *testing.T) {
+	tool, _ := newTestHomeAssistant(t, false)
+	ctx := context.Background()
+
+	tests := []struct{ filter, want string }{
+		{"", "3 entities:\n- light.kitchen (Kitchen light): off\n- light.porch (Porch): on\n" +
+			"- sensor.kitchen_temperature (Kitchen): 21.5 °C"},
+		{"light", "2 entities:\n- light.kitchen (Kitchen light): off\n- light.porch (Porch): on"},
+		{"*temperature", "1 entities:\n- sensor.kitchen_temperature (Kitchen): 21.5 °C"},
+		{"kitchen LIGHT", "1 entities:\n- light.kitchen (Kitchen light): off"},
+		{"front door", `No entities match "front door"`},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(ctx, map[string]any{"action": "states", "filter": tt.filter})
+		if result.IsError || result.ForLLM != tt.want {
+			t.Errorf("states %q = %q, want %q", tt.filter, result.ForLLM, tt.want)
+		}
+	}
+
+	result := tool.Execute(ctx, map[string]any{"action": "get", "entity_id": "light.porch"})
+	if !strings.HasPrefix(result.ForLLM, "light.porch (Porch): on\nLast changed: ") ||
+		!strings.HasSuffix(result.ForLLM, "\nbrightness: 120") {
+		t.Errorf("get = %q", result.ForLLM)
+	}
+	if result := tool.Execute(ctx, map[string]any{"action": "get", "entity_id": "lock.front_door"}); !result.IsError {
+		t.Errorf("get of an entity not allowed = %q", result.ForLLM)
+	}
+}
+
+func TestHomeAssistantTool_Call(t *testing.T) {
+	tool, calls := newTestHomeAssistant(t, false)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "call", "service": "light.turn_on", "entity_id": "light.kitchen",
+		"data": map[string]any{"brightness_pct": 50},
+	})
+	if result.IsError || result.ForLLM != "Called light.turn_on. Now:\n- light.kitchen (Kitchen light): on" {
+		t.Fatalf("call = %q", result.ForLLM)
+	}
+	var sent map[string]any
+	if len(*calls) != 1 || json.Unmarshal([]byte((*calls)[0]), &sent) != nil || sent["brightness_pct"] != 50.0 {
+		t.Errorf("service data sent = %v", *calls)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "call", "service": "lock.unlock", "entity_id": "lock.front_door"},
+		{"action": "call", "service": "light.turn_on"},
+		{"action": "call", "service": "turn_on", "entity_id": "light.kitchen"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v = %q, want an error", args, result.ForLLM)
+		}
+	}
+	if len(*calls) != 1 {
+		t.Errorf("calls = %v, want none refused to reach home assistant", *calls)
+	}
+
+	readOnly, _ := newTestHomeAssistant(t, true)
+	if strings.Contains(readOnly.Description(), "'call'") {
+		t.Error("read-only description offers calling services")
+	}
+	result = readOnly.Execute(ctx, map[string]any{
+		"action":    "call",
+		"service":   "light.turn_on",
+		"entity_id": "light.kitchen",
+	})
+	if !result.IsError {
+		t.Errorf("read-only call = %q", result.ForLLM)
+	}
+}
@@ -0,0 +1,314 @@
+package tools
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reHTMLTitle   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	reHTMLComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	reHTMLTag     = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	reHTMLAttr    = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	reSpaces      = regexp.MustCompile(`\s+`)
+	reManyLines   = regexp.MustCompile(`\n{3,}`)
+
+	// Classes and ids of page furniture rather than content.
+	reBoilerplate = regexp.MustCompile(`(?i)\b(nav|navbar|menu|footer|sidebar|breadcrumbs?|cookies?|banner|` +
+		`share|sharing|social|related|comments?|ads?|advert\w*|promo\w*|newsletter|subscribe|popup|modal)\b`)
+)
+
+// Elements that are never content, and those that have no end tag.
+var (
+	skippedElements = map[string]bool{
+		"script": true, "style": true, "noscript": true, "svg": true, "iframe": true, "form": true,
+		"nav": true, "footer": true, "aside": true, "template": true, "button": true, "select": true,
+		"head": true, "title": true,
+	}
+	voidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+		"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+	}
+	blockElements = map[string]bool{
+		"p": true, "div": true, "section": true, "article": true, "main": true, "header": true,
+		"table": true, "tr": true, "figure": true, "figcaption": true, "dl": true, "dt": true, "dd": true,
+		"blockquote": true, "address": true, "details": true, "summary": true,
+	}
+)
+
+// readableMarkdown extracts the main content of an HTML page, in the
+// manner of browsers' reader views, as markdown: the longest <article>,
+// else <main>, else the whole body, without navigation, sidebars, scripts
+// and the like. Links are made absolute against base.
+func readableMarkdown(page string, base *url.URL) (title, markdown string) {
+	if m := reHTMLTitle.FindStringSubmatch(page); m != nil {
+		title = strings.TrimSpace(reSpaces.ReplaceAllString(html.UnescapeString(m[1]), " "))
+	}
+	page = reHTMLComment.ReplaceAllString(page, "")
+
+	content, whole := page, true
+	for _, tag := range []string{"article", "main", "body"} {
+		if inner := longestElement(page, tag); inner != "" {
+			// The page's own header is furniture; an article's holds its title.
+			content, whole = inner, tag == "body"
+			break
+		}
+	}
+	w := &markdownWriter{base: base, skipHeader: whole}
+	w.convert(content)
+	return title, w.String()
+}
+
+// longestElement returns the inner HTML of the outermost tag element with
+// the most text, or "".
+func longestElement(page, tag string) string {
+	var best string
+	bestLen, depth, start := -1, 0, 0
+	for _, m := range reHTMLTag.FindAllStringSubmatchIndex(page, -1) {
+		if !strings.EqualFold(page[m[4]:m[5]], tag) || strings.HasSuffix(page[m[6]:m[7]], "/") {
+			continue
+		}
+		if m[3] > m[2] { // End tag
+			if depth == 0 {
+				continue
+			}
+			if depth--; depth == 0 {
+				inner := page[start:m[0]]
+				if n := len(strings.TrimSpace(stripTags(inner))); n > bestLen {
+					best, bestLen = inner, n
+				}
+			}
+			continue
+		}
+		if depth == 0 {
+			start = m[1]
+		}
+		depth++
+	}
+	if depth > 0 && bestLen < 0 {
+		// Unclosed, as browsers allow.
+		best = page[start:]
+	}
+	return best
+}
+
+// markdownWriter turns HTML into markdown, one tag or text run at a time.
+type markdownWriter struct {
+	buf        []byte
+	base       *url.URL
+	skipHeader bool
+
+	skip      string // Element being left out, and how deeply nested
+	skipDepth int
+	pre       int
+	lists     []int // Open lists: 0 for <ul>, else the next <ol> number
+	links     []mdLink
+}
+
+type mdLink struct {
+	start int // Where the link text starts in buf
+	href  string
+}
+
+func (w *markdownWriter) String() string {
+	lines := strings.Split(string(w.buf), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(reManyLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func (w *markdownWriter) convert(page string) {
+	last := 0
+	for _, m := range reHTMLTag.FindAllStringSubmatchIndex(page, -1) {
+		w.text(page[last:m[0]])
+		last = m[1]
+		name := strings.ToLower(page[m[4]:m[5]])
+		attrs := page[m[6]:m[7]]
+		if m[3] > m[2] {
+			w.close(name)
+		} else {
+			w.open(name, attrs)
+		}
+	}
+	w.text(page[last:])
+}
+
+func (w *markdownWriter) open(name, attrs string) {
+	selfClosing := voidElements[name] || strings.HasSuffix(attrs, "/")
+	if w.skip != "" {
+		if name == w.skip && !selfClosing {
+			w.skipDepth++
+		}
+		return
+	}
+	if !selfClosing && (skippedElements[name] || name == "header" && w.skipHeader ||
+		reBoilerplate.MatchString(attr(attrs, "class")+" "+attr(attrs, "id")) && name != "body") {
+		w.skip, w.skipDepth = name, 1
+		return
+	}
+
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block()
+		level, _ := strconv.Atoi(name[1:])
+		w.write(strings.Repeat("#", level) + " ")
+	case "br":
+		w.write("\n")
+	case "hr":
+		w.block()
+		w.write("---")
+		w.block()
+	case "ul", "ol":
+		w.newline()
+		next := 0
+		if name == "ol" {
+			next = 1
+		}
+		w.lists = append(w.lists, next)
+	case "li":
+		w.newline()
+		marker := "- "
+		if n := len(w.lists); n > 0 {
+			w.write(strings.Repeat("  ", n-1))
+			if w.lists[n-1] > 0 {
+				marker = strconv.Itoa(w.lists[n-1]) + ". "
+				w.lists[n-1]++
+			}
+		}
+		w.write(marker)
+	case "a":
+		w.links = append(w.links, mdLink{start: len(w.buf), href: attr(attrs, "href")})
+	case "strong", "b":
+		w.inline("**")
+	case "em", "i":
+		w.inline("*")
+	case "code":
+		w.inline("`")
+	case "pre":
+		w.block()
+		w.write("```\n")
+		w.pre++
+	case "td", "th":
+		w.write(" ")
+	default:
+		if blockElements[name] {
+			w.block()
+		}
+	}
+}
+
+func (w *markdownWriter) close(name string) {
+	if w.skip != "" {
+		if name == w.skip {
+			if w.skipDepth--; w.skipDepth == 0 {
+				w.skip = ""
+			}
+		}
+		return
+	}
+
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block()
+	case "ul", "ol":
+		if len(w.lists) > 0 {
+			w.lists = w.lists[:len(w.lists)-1]
+		}
+		w.newline()
+	case "a":
+		if len(w.links) == 0 {
+			return
+		}
+		link := w.links[len(w.links)-1]
+		w.links = w.links[:len(w.links)-1]
+		w.link(link)
+	case "strong", "b":
+		w.inline("**")
+	case "em", "i":
+		w.inline("*")
+	case "code":
+		w.inline("`")
+	case "pre":
+		if w.pre > 0 {
+			w.pre--
+			w.newline()
+			w.write("```")
+			w.block()
+		}
+	default:
+		if blockElements[name] {
+			w.block()
+		}
+	}
+}
+
+// link turns the text written since the <a> into a markdown link, if it
+// has text and leads somewhere.
+func (w *markdownWriter) link(l mdLink) {
+	if l.start > len(w.buf) {
+		return
+	}
+	text := strings.TrimSpace(string(w.buf[l.start:]))
+	href := strings.TrimSpace(html.UnescapeString(l.href))
+	if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return
+	}
+	if u, err := url.Parse(href); err == nil && w.base != nil {
+		href = w.base.ResolveReference(u).String()
+	}
+	w.buf = append(w.buf[:l.start], "["+text+"]("+href+")"...)
+}
+
+func (w *markdownWriter) text(s string) {
+	if w.skip != "" || s == "" {
+		return
+	}
+	s = html.UnescapeString(s)
+	if w.pre > 0 {
+		w.write(s)
+		return
+	}
+	s = reSpaces.ReplaceAllString(s, " ")
+	if n := len(w.buf); n == 0 || w.buf[n-1] == ' ' || w.buf[n-1] == '\n' {
+		s = strings.TrimLeft(s, " ")
+	}
+	w.write(s)
+}
+
+func (w *markdownWriter) inline(mark string) {
+	if w.pre == 0 {
+		w.write(mark)
+	}
+}
+
+func (w *markdownWriter) write(s string) {
+	w.buf = append(w.buf, s...)
+}
+
+// newline ends the current line, if any; block also leaves a blank line.
+func (w *markdownWriter) newline() {
+	if n := len(w.buf); n > 0 && w.buf[n-1] != '\n' {
+		w.write("\n")
+	}
+}
+
+func (w *markdownWriter) block() {
+	w.newline()
+	if n := len(w.buf); n > 1 && w.buf[n-2] != '\n' {
+		w.write("\n")
+	}
+}
+
+// attr returns the value of the named attribute, or "".
+func attr(attrs, name string) string {
+	for _, m := range reHTMLAttr.FindAllStringSubmatch(attrs, -1) {
+		if strings.EqualFold(m[1], name) {
+			return m[2] + m[3] + m[4]
+		}
+	}
+	return ""
+}
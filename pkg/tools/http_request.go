@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultHTTPTimeout     = 30 * time.Second
+	defaultHTTPMaxResponse = 32 << 10
+	maxHTTPRedirects       = 5
+)
+
+// HTTPRequestTool calls web APIs on allowed domains. Headers configured for
+// a domain, such as its API token, are added to requests to it without the
+// model seeing them, and are blanked out of responses that echo them.
+type HTTPRequestTool struct {
+	allowDomains  []string
+	secretHeaders map[string]map[string]string // By domain
+	maxResponse   int64
+	client        *http.Client
+}
+
+// HTTPRequestToolOptions configures an HTTPRequestTool; zero values take
+// the defaults.
+type HTTPRequestToolOptions struct {
+	AllowDomains  []string
+	SecretHeaders map[string]map[string]string
+	MaxResponse   int64 // Bytes of the response body shown at most
+	Timeout       time.Duration
+	Proxy         string
+}
+
+func NewHTTPRequestTool(opts HTTPRequestToolOptions) *HTTPRequestTool {
+	t := &HTTPRequestTool{
+		allowDomains:  opts.AllowDomains,
+		secretHeaders: opts.SecretHeaders,
+		maxResponse:   opts.MaxResponse,
+	}
+	if t.maxResponse <= 0 {
+		t.maxResponse = defaultHTTPMaxResponse
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	client, err := createHTTPClient(opts.Proxy, timeout)
+	if err != nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	client.CheckRedirect = t.checkRedirect
+	t.client = client
+	return t
+}
+
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+func (t *HTTPRequestTool) Description() string {
+	return fmt.Sprintf("Call a web API with any HTTP method and return the status, headers and body "+
+		"(JSON pretty-printed). Only these domains and their subdomains are allowed: %s. "+
+		"Authentication is added automatically where configured; don't send credentials yourself.",
+		strings.Join(t.allowDomains, ", "))
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+				"description": "HTTP method (default GET)",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Full URL, with any query string",
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Request headers, e.g. {\"Accept\": \"application/json\"}",
+			},
+			"body": map[string]any{
+				"description": "Request body: a string as is, or a JSON object or array, sent as JSON",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	rawURL, _ := args["url"].(string)
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrorResult("url must be a full http or https URL")
+	}
+	if !domainAllowed(u.Hostname(), t.allowDomains) {
+		return ErrorResult(fmt.Sprintf("%s isn't an allowed domain; allowed: %s",
+			u.Hostname(), strings.Join(t.allowDomains, ", ")))
+	}
+
+	method := "GET"
+	if m, ok := args["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	var (
+		body        io.Reader
+		contentType string
+	)
+	switch b := args["body"].(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid body: %v", err))
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid request: %v", err))
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers, ok := args["headers"].(map[string]any); ok {
+		for k, v := range headers {
+			req.Header.Set(k, fmt.Sprint(v))
+		}
+	}
+	t.addSecrets(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		var denied *redirectDenied
+		if errors.As(err, &denied) {
+			return ErrorResult(denied.Error())
+		}
+		return ErrorResult(t.redact(fmt.Sprintf("request failed: %v", err))).WithError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, t.maxResponse+1))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("reading the response failed: %v", err)).WithError(err)
+	}
+	cut := int64(len(data)) > t.maxResponse
+	if cut {
+		data = data[:t.maxResponse]
+	}
+	return SilentResult(t.redact(formatHTTPResponse(resp, data, cut)))
+}
+
+// addSecrets sets the headers configured for req's host, over any the
+// model set. Those of a subdomain win over its parent's.
+func (t *HTTPRequestTool) addSecrets(req *http.Request) {
+	domains := make([]string, 0, len(t.secretHeaders))
+	for domain := range t.secretHeaders {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return len(domains[i]) < len(domains[j]) })
+	for _, domain := range domains {
+		if domainAllowed(req.URL.Hostname(), []string{domain}) {
+			for k, v := range t.secretHeaders[domain] {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+}
+
+type redirectDenied struct{ host string }
+
+func (e *redirectDenied) Error() string {
+	return fmt.Sprintf("the server redirected to %s, which isn't an allowed domain", e.host)
+}
+
+// checkRedirect follows redirects within the allowed domains only, and
+// gives each request the secret headers of its own host, not the first's.
+func (t *HTTPRequestTool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHTTPRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+	}
+	if !domainAllowed(req.URL.Hostname(), t.allowDomains) {
+		return &redirectDenied{host: req.URL.Hostname()}
+	}
+	for _, headers := range t.secretHeaders {
+		for k := range headers {
+			req.Header.Del(k)
+		}
+	}
+	t.addSecrets(req)
+	return nil
+}
+
+// redact blanks out the secret header values in s.
+func (t *HTTPRequestTool) redact(s string) string {
+	for _, headers := range t.secretHeaders {
+		for _, v := range headers {
+			if len(v) >= 4 {
+				s = strings.ReplaceAll(s, v, "[secret]")
+			}
+			// A bearer token may be echoed on its own.
+			if _, token, ok := strings.Cut(v, " "); ok && len(token) >= 4 {
+				s = strings.ReplaceAll(s, token, "[secret]")
+			}
+		}
+	}
+	return s
+}
+
+// shownResponseHeaders are the response headers worth the model's attention.
+var shownResponseHeaders = []string{
+	"Content-Type", "Location", "Retry-After", "Link", "ETag", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+}
+
+func formatHTTPResponse(resp *http.Response, data []byte, cut bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP %s\n", resp.Status)
+	for _, name := range shownResponseHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", name, v)
+		}
+	}
+	sb.WriteString("\n")
+
+	if cut {
+		// Don't count a character split by the cut as binary.
+		for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case len(data) == 0:
+		sb.WriteString("(empty body)")
+		return sb.String()
+	case !cut && (strings.HasSuffix(mediaType, "json") || json.Valid(data)):
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, data, "", "  ") == nil {
+			sb.Write(pretty.Bytes())
+			break
+		}
+		sb.Write(data)
+	case utf8.Valid(data):
+		sb.Write(data)
+	default:
+		fmt.Fprintf(&sb, "(%d bytes of binary data)", len(data))
+	}
+	if cut {
+		fmt.Fprintf(&sb, "\n\n[Response cut at %d bytes]", len(data))
+	}
+	return sb.String()
+}
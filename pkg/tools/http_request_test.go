@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEchoAPI serves /echo, which answers with the request as JSON, and
+// /to?url=, which redirects.
+func newEchoAPI(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"method":        r.Method,
+				"host":          r.Host,
+				"authorization": r.Header.Get("Authorization"),
+				"content_type":  r.Header.Get("Content-Type"),
+				"body":          string(body),
+			})
+		case "/to":
+			http.Redirect(w, r, r.URL.Query().Get("url"), http.StatusFound)
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 5000)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPRequestTool(t *testing.T) {
+	server := newEchoAPI(t)
+	tool := NewHTTPRequestTool(HTTPRequestToolOptions{
+		AllowDomains:  []string{"127.0.0.1"},
+		SecretHeaders: map[string]map[string]string{"127.0.0.1": {"Authorization": "Bearer s3cr3t-token"}},
+	})
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"method":  "post",
+		"url":     server.URL + "/echo",
+		"headers": map[string]any{"Authorization": "Bearer guessed"},
+		"body":    map[string]any{"name": "lamp", "on": true},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() = %q", result.ForLLM)
+	}
+	for _, want := range []string{
+		"HTTP 200 OK",
+		"Content-Type: application/json",
+		`  "method": "POST"`,
+		`"authorization": "[secret]"`,
+		`"content_type": "application/json"`,
+		`"body": "{\"name\":\"lamp\",\"on\":true}"`,
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("Execute() = %q, want %q in it", result.ForLLM, want)
+		}
+	}
+	if strings.Contains(result.ForLLM, "s3cr3t") {
+		t.Error("the secret header was shown to the model")
+	}
+
+	result = tool.Execute(ctx, map[string]any{"url": server.URL + "/missing"})
+	if result.IsError || !strings.HasPrefix(result.ForLLM, "HTTP 404") {
+		t.Errorf("Execute() of a missing page = %q, want the 404 shown", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_Domains(t *testing.T) {
+	server := newEchoAPI(t)
+	local := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	tool := NewHTTPRequestTool(HTTPRequestToolOptions{
+		AllowDomains:  []string{"127.0.0.1"},
+		SecretHeaders: map[string]map[string]string{"127.0.0.1": {"Authorization": "Bearer s3cr3t-token"}},
+	})
+	ctx := context.Background()
+
+	if result := tool.Execute(ctx, map[string]any{"url": "https://example.com/"}); !result.IsError ||
+		!strings.Contains(result.ForLLM, "isn't an allowed domain") {
+		t.Errorf("Execute() on another domain = %q", result.ForLLM)
+	}
+	result := tool.Execute(ctx, map[string]any{"url": server.URL + "/to?url=" + local + "/echo"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "redirected to localhost") {
+		t.Errorf("Execute() redirected to another domain = %q", result.ForLLM)
+	}
+
+	// Allowed elsewhere, the redirect is followed without the secret.
+	tool.allowDomains = append(tool.allowDomains, "localhost")
+	result = tool.Execute(ctx, map[string]any{"url": server.URL + "/to?url=" + local + "/echo"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"authorization": ""`) {
+		t.Errorf("Execute() redirected to an allowed domain = %q, want no Authorization sent", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_MaxResponse(t *testing.T) {
+	server := newEchoAPI(t)
+	tool := NewHTTPRequestTool(HTTPRequestToolOptions{AllowDomains: []string{"127.0.0.1"}, MaxResponse: 1000})
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/big"})
+	shown := strings.Repeat("x", 1000)
+	if result.IsError || !strings.Contains(result.ForLLM, "[Response cut at 1000 bytes]") ||
+		!strings.Contains(result.ForLLM, shown) || strings.Contains(result.ForLLM, shown+"x") {
+		t.Errorf("Execute() of a big response = %d bytes, want it cut", len(result.ForLLM))
+	}
+}
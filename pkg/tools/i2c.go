@@ -7,13 +7,18 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 )
 
 // I2CTool provides I2C bus interaction for reading sensors and controlling peripherals.
-type I2CTool struct{}
+// Buses can always be listed and scanned; only the devices allowed can be read and written.
+type I2CTool struct {
+	devices []string // "bus:address", e.g. "1:0x38", or "bus:*" for all of a bus
+}
 
-func NewI2CTool() *I2CTool {
-	return &I2CTool{}
+func NewI2CTool(devices []string) *I2CTool {
+	return &I2CTool{devices: devices}
 }
 
 func (t *I2CTool) Name() string {
@@ -21,7 +26,11 @@ func (t *I2CTool) Name() string {
 }
 
 func (t *I2CTool) Description() string {
-	return "Interact with I2C bus devices for reading sensors and controlling peripherals. Actions: detect (list buses), scan (find devices on a bus), read (read bytes from device), write (send bytes to device). Linux only."
+	allowed := "none"
+	if len(t.devices) > 0 {
+		allowed = strings.Join(t.devices, ", ")
+	}
+	return "Interact with I2C bus devices for reading sensors and controlling peripherals. Actions: detect (list buses), scan (find devices on a bus), read (read bytes from device), write (send bytes to device). Devices that can be read and written (bus:address): " + allowed + ". Linux only."
 }
 
 func (t *I2CTool) Parameters() map[string]any {
@@ -142,6 +151,34 @@ func parseI2CAddress(args map[string]any) (int, *ToolResult) {
 	return addr, nil
 }
 
+// allowed reports whether the device at addr on bus may be read and written
+//
+//nolint:unused // Used by i2c_linux.go
+func (t *I2CTool) allowed(bus string, addr int) bool {
+	for _, device := range t.devices {
+		b, a, ok := strings.Cut(strings.TrimSpace(device), ":")
+		if !ok || b != bus {
+			continue
+		}
+		if a == "*" {
+			return true
+		}
+		if n, err := strconv.ParseInt(a, 0, 16); err == nil && int(n) == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// i2cNotAllowed is the error for a device the user hasn't allowed
+//
+//nolint:unused // Used by i2c_linux.go
+func i2cNotAllowed(bus string, addr int) *ToolResult {
+	return ErrorResult(fmt.Sprintf(
+		"I2C device 0x%02x on bus %s is not allowed; the user can add \"%s:0x%02x\" to tools.hardware.i2c_devices",
+		addr, bus, bus, addr))
+}
+
 // parseI2CBus extracts and validates an I2C bus from args
 //
 //nolint:unused // Used by i2c_linux.go
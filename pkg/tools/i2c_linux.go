@@ -154,6 +154,9 @@ func (t *I2CTool) readDevice(args map[string]any) *ToolResult {
 	if errResult != nil {
 		return errResult
 	}
+	if !t.allowed(bus, addr) {
+		return i2cNotAllowed(bus, addr)
+	}
 
 	length := 1
 	if l, ok := args["length"].(float64); ok {
@@ -231,6 +234,9 @@ func (t *I2CTool) writeDevice(args map[string]any) *ToolResult {
 	if errResult != nil {
 		return errResult
 	}
+	if !t.allowed(bus, addr) {
+		return i2cNotAllowed(bus, addr)
+	}
 
 	dataRaw, ok := args["data"].([]any)
 	if !ok || len(dataRaw) == 0 {
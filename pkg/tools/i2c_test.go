@@ -0,0 +1,26 @@
+package tools
+
+import "testing"
+
+func TestI2CTool_Allowed(t *testing.T) {
+	tool := NewI2CTool([]string{"1:0x38", "2:*", " 3:118"})
+	tests := []struct {
+		bus  string
+		addr int
+		want bool
+	}{
+		{"1", 0x38, true},
+		{"1", 0x39, false},
+		{"0", 0x38, false},
+		{"2", 0x76, true},
+		{"3", 0x76, true},
+	}
+	for _, tt := range tests {
+		if got := tool.allowed(tt.bus, tt.addr); got != tt.want {
+			t.Errorf("allowed(%s, 0x%02x) = %v, want %v", tt.bus, tt.addr, got, tt.want)
+		}
+	}
+	if NewI2CTool(nil).allowed("1", 0x38) {
+		t.Error("a device is allowed without an allowlist")
+	}
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const maxGeneratedImages = 4
+
+// SendMediaCallback delivers files to a chat, with caption as the message
+// text.
+type SendMediaCallback func(channel, chatID, caption string, paths []string) error
+
+// ImageGenerationTool creates images with the model serving the image_gen
+// role, saves them under the workspace's images/ directory and sends them
+// to the current chat.
+type ImageGenerationTool struct {
+	generator      func() (providers.ImageGenerationProvider, error)
+	workspace      string
+	sendCallback   SendMediaCallback
+	defaultChannel string
+	defaultChatID  string
+}
+
+// NewImageGenerationTool creates the tool. generator is called on each use,
+// so a misconfigured model is reported to the agent rather than at startup.
+func NewImageGenerationTool(generator func() (providers.ImageGenerationProvider, error), workspace string) *ImageGenerationTool {
+	return &ImageGenerationTool{generator: generator, workspace: workspace}
+}
+
+func (t *ImageGenerationTool) Name() string {
+	return "image_generation"
+}
+
+func (t *ImageGenerationTool) Description() string {
+	return "Generate images from a text description. The images are sent to the user in the current chat " +
+		"and saved in the workspace; describe the subject, style and composition in detail."
+}
+
+func (t *ImageGenerationTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"prompt": map[string]any{
+				"type":        "string",
+				"description": "Detailed description of the image to create",
+			},
+			"negative_prompt": map[string]any{
+				"type":        "string",
+				"description": "Optional: what to keep out of the image (not supported by every model)",
+			},
+			"size": map[string]any{
+				"type":        "string",
+				"description": "Optional: WIDTHxHEIGHT, e.g. 1024x1024 or 1792x1024",
+			},
+			"n": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Optional: number of images, 1-%d (default 1)", maxGeneratedImages),
+			},
+		},
+		"required": []string{"prompt"},
+	}
+}
+
+func (t *ImageGenerationTool) SetContext(channel, chatID string) {
+	t.defaultChannel = channel
+	t.defaultChatID = chatID
+}
+
+func (t *ImageGenerationTool) SetSendCallback(callback SendMediaCallback) {
+	t.sendCallback = callback
+}
+
+func (t *ImageGenerationTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	prompt, _ := args["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		return ErrorResult("prompt is required")
+	}
+	req := providers.ImageRequest{Prompt: prompt, N: 1}
+	req.NegativePrompt, _ = args["negative_prompt"].(string)
+	req.Size, _ = args["size"].(string)
+	if n, ok := args["n"].(float64); ok {
+		req.N = min(max(int(n), 1), maxGeneratedImages)
+	}
+
+	generator, err := t.generator()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("image generation is not available: %v", err)).WithError(err)
+	}
+	images, err := generator.GenerateImages(ctx, req)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("image generation failed: %v", err)).WithError(err)
+	}
+
+	paths, err := t.save(images)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to save images: %v", err)).WithError(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Generated %d image(s):\n", len(paths))
+	for i, path := range paths {
+		fmt.Fprintf(&sb, "- %s\n", path)
+		if revised := images[i].RevisedPrompt; revised != "" {
+			fmt.Fprintf(&sb, "  revised prompt: %s\n", revised)
+		}
+	}
+
+	if t.sendCallback == nil || t.defaultChannel == "" || t.defaultChatID == "" {
+		sb.WriteString("No chat to deliver them to; they were only saved.")
+		return SilentResult(sb.String())
+	}
+	if err := t.sendCallback(t.defaultChannel, t.defaultChatID, "", paths); err != nil {
+		fmt.Fprintf(&sb, "Sending them to the user failed: %v", err)
+		return SilentResult(sb.String())
+	}
+	sb.WriteString("They were sent to the user; do not send them again.")
+	return SilentResult(sb.String())
+}
+
+// save writes images to <workspace>/images and returns their paths.
+func (t *ImageGenerationTool) save(images []providers.GeneratedImage) ([]string, error) {
+	dir := filepath.Join(t.workspace, "images")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	stamp := time.Now().Format("20060102-150405")
+	paths := make([]string, 0, len(images))
+	for i, img := range images {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%d%s", stamp, i+1, imageExtension(img.MIMEType)))
+		if err := os.WriteFile(path, img.Data, 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func imageExtension(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type fakeImageGenerator struct {
+	req providers.ImageRequest
+	err error
+}
+
+func (g *fakeImageGenerator) GenerateImages(ctx context.Context, req providers.ImageRequest) ([]providers.GeneratedImage, error) {
+	g.req = req
+	if g.err != nil {
+		return nil, g.err
+	}
+	images := make([]providers.GeneratedImage, req.N)
+	for i := range images {
+		images[i] = providers.GeneratedImage{Data: []byte("img"), MIMEType: "image/jpeg"}
+	}
+	return images, nil
+}
+
+func newTestImageTool(t *testing.T, gen providers.ImageGenerationProvider) (*ImageGenerationTool, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	tool := NewImageGenerationTool(func() (providers.ImageGenerationProvider, error) { return gen, nil }, workspace)
+	return tool, workspace
+}
+
+func TestImageGenerationTool_SavesAndSends(t *testing.T) {
+	gen := &fakeImageGenerator{}
+	tool, workspace := newTestImageTool(t, gen)
+
+	var sentChannel, sentChat string
+	var sentPaths []string
+	tool.SetSendCallback(func(channel, chatID, caption string, paths []string) error {
+		sentChannel, sentChat, sentPaths = channel, chatID, paths
+		return nil
+	})
+	tool.SetContext("telegram", "42")
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"prompt": "a watercolor fox",
+		"size":   "1024x1024",
+		"n":      float64(9),
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if !result.Silent {
+		t.Error("result should be silent; the images were sent")
+	}
+	if gen.req.N != maxGeneratedImages || gen.req.Size != "1024x1024" {
+		t.Errorf("request = %+v", gen.req)
+	}
+	if sentChannel != "telegram" || sentChat != "42" || len(sentPaths) != maxGeneratedImages {
+		t.Fatalf("sent to %s:%s paths %v", sentChannel, sentChat, sentPaths)
+	}
+	if filepath.Dir(sentPaths[0]) != filepath.Join(workspace, "images") || filepath.Ext(sentPaths[0]) != ".jpg" {
+		t.Errorf("path = %q", sentPaths[0])
+	}
+	if data, err := os.ReadFile(sentPaths[0]); err != nil || string(data) != "img" {
+		t.Errorf("saved image = %q, %v", data, err)
+	}
+	if !strings.Contains(result.ForLLM, sentPaths[0]) {
+		t.Errorf("ForLLM = %q, want the saved paths", result.ForLLM)
+	}
+}
+
+func TestImageGenerationTool_Errors(t *testing.T) {
+	tool, _ := newTestImageTool(t, &fakeImageGenerator{err: errors.New("quota exceeded")})
+	if result := tool.Execute(context.Background(), map[string]any{}); !result.IsError {
+		t.Error("missing prompt should fail")
+	}
+	if result := tool.Execute(context.Background(), map[string]any{"prompt": "x"}); !result.IsError ||
+		!strings.Contains(result.ForLLM, "quota exceeded") {
+		t.Errorf("result = %+v", result)
+	}
+
+	unavailable := NewImageGenerationTool(func() (providers.ImageGenerationProvider, error) {
+		return nil, errors.New("no model assigned")
+	}, t.TempDir())
+	if result := unavailable.Execute(context.Background(), map[string]any{"prompt": "x"}); !result.IsError {
+		t.Error("unavailable generator should fail")
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+const defaultMCPTimeout = 60 * time.Second
+
+// mcpCaller is the part of an MCP client a tool uses.
+type mcpCaller interface {
+	CallTool(ctx context.Context, name string, args map[string]any) (*mcp.CallResult, error)
+}
+
+// MCPTool is a tool of an MCP server, offered to the agent as its own.
+type MCPTool struct {
+	name    string
+	server  string
+	tool    mcp.Tool
+	client  mcpCaller
+	timeout time.Duration
+}
+
+var unsafeToolName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// NewMCPTool wraps the tool of the server named server. Its name is
+// mcp_<server>_<tool>, cut to the 64 characters providers allow.
+func NewMCPTool(server string, tool mcp.Tool, client mcpCaller, timeout time.Duration) *MCPTool {
+	if timeout <= 0 {
+		timeout = defaultMCPTimeout
+	}
+	return &MCPTool{
+		name:    MCPToolName(server, tool.Name),
+		server:  server,
+		tool:    tool,
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+// MCPToolName is the name the agent knows a server's tool by.
+func MCPToolName(server, tool string) string {
+	name := unsafeToolName.ReplaceAllString("mcp_"+server+"_"+tool, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+func (t *MCPTool) Name() string {
+	return t.name
+}
+
+func (t *MCPTool) Description() string {
+	if t.tool.Description == "" {
+		return fmt.Sprintf("%s (from the %s MCP server)", t.tool.Name, t.server)
+	}
+	return fmt.Sprintf("[%s] %s", t.server, t.tool.Description)
+}
+
+func (t *MCPTool) Parameters() map[string]any {
+	params := make(map[string]any, len(t.tool.InputSchema)+2)
+	for k, v := range t.tool.InputSchema {
+		params[k] = v
+	}
+	// Providers want an object schema with properties, which servers
+	// taking no arguments often leave out.
+	params["type"] = "object"
+	if _, ok := params["properties"]; !ok {
+		params["properties"] = map[string]any{}
+	}
+	return params
+}
+
+func (t *MCPTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	result, err := t.client.CallTool(ctx, t.tool.Name, args)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("%s failed: %v", t.name, err)).WithError(err)
+	}
+	text := result.Text()
+	if result.IsError {
+		return ErrorResult(fmt.Sprintf("%s failed: %s", t.name, text))
+	}
+	if text == "" {
+		text = "(no output)"
+	}
+	return SilentResult(text)
+}
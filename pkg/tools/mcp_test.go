@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/mcp"
+)
+
+type fakeMCPCaller struct {
+	name   string
+	args   map[string]any
+	result *mcp.CallResult
+	err    error
+}
+
+func (f *fakeMCPCaller) CallTool(ctx context.Context, name string, args map[string]any) (*mcp.CallResult, error) {
+	f.name, f.args = name, args
+	return f.result, f.err
+}
+
+func TestMCPTool(t *testing.T) {
+	caller := &fakeMCPCaller{result: &mcp.CallResult{Content: []mcp.Content{{Type: "text", Text: "3 files"}}}}
+	tool := NewMCPTool("files", mcp.Tool{
+		Name:        "list_directory",
+		Description: "Lists a directory.",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{"path": map[string]any{}}},
+	}, caller, 0)
+
+	if tool.Name() != "mcp_files_list_directory" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+	if tool.Description() != "[files] Lists a directory." {
+		t.Errorf("Description() = %q", tool.Description())
+	}
+	result := tool.Execute(context.Background(), map[string]any{"path": "/tmp"})
+	if result.IsError || result.ForLLM != "3 files" || caller.name != "list_directory" || caller.args["path"] != "/tmp" {
+		t.Errorf("Execute() = %q, called %s(%v)", result.ForLLM, caller.name, caller.args)
+	}
+
+	caller.result = &mcp.CallResult{Content: []mcp.Content{{Type: "text", Text: "no such directory"}}, IsError: true}
+	if result := tool.Execute(context.Background(), nil); !result.IsError ||
+		!strings.Contains(result.ForLLM, "no such directory") {
+		t.Errorf("Execute() of a failing tool = %q", result.ForLLM)
+	}
+
+	caller.err = errors.New("connection ended")
+	if result := tool.Execute(context.Background(), nil); !result.IsError || result.Err == nil {
+		t.Errorf("Execute() with a broken connection = %+v", result)
+	}
+}
+
+func TestMCPTool_Parameters(t *testing.T) {
+	tool := NewMCPTool("home", mcp.Tool{Name: "get_state"}, &fakeMCPCaller{}, 0)
+	params := tool.Parameters()
+	if params["type"] != "object" || params["properties"] == nil {
+		t.Errorf("Parameters() = %v, want an object schema", params)
+	}
+}
+
+func TestMCPToolName(t *testing.T) {
+	if got := MCPToolName("home assistant", "light.turn_on"); got != "mcp_home_assistant_light_turn_on" {
+		t.Errorf("MCPToolName() = %q", got)
+	}
+	if got := MCPToolName("github", strings.Repeat("x", 80)); len(got) != 64 {
+		t.Errorf("MCPToolName() is %d characters, want 64", len(got))
+	}
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultMQTTWait = 3 * time.Second
+	maxMQTTWait     = 30 * time.Second
+	// maxMQTTMessages bounds the messages a read returns.
+	maxMQTTMessages = 50
+)
+
+// MQTTTool publishes messages to devices through an MQTT broker and reads
+// what they publish, on the topics allowed.
+type MQTTTool struct {
+	topics []string // Filters of the topics allowed
+
+	// The broker, replaced in tests
+	publish   func(ctx context.Context, msg mqtt.Message, qos byte) error
+	subscribe func(ctx context.Context, topics []string, fn func(mqtt.Message)) error
+}
+
+// NewMQTTTool returns the tool of the broker of opts, limited to the topics
+// the filters of topics match.
+func NewMQTTTool(opts mqtt.Options, topics []string) *MQTTTool {
+	return &MQTTTool{
+		topics: topics,
+		publish: func(ctx context.Context, msg mqtt.Message, qos byte) error {
+			return mqtt.Publish(ctx, opts, msg, qos)
+		},
+		subscribe: func(ctx context.Context, filters []string, fn func(mqtt.Message)) error {
+			return mqtt.Subscribe(ctx, opts, filters, fn)
+		},
+	}
+}
+
+func (t *MQTTTool) Name() string {
+	return "mqtt"
+}
+
+func (t *MQTTTool) Description() string {
+	return "MQTT messaging with devices. 'publish' sends a payload on a topic, e.g. topic='home/lamp/set', " +
+		"payload='ON'; 'read' listens on a topic filter (+ and # wildcards) for a few seconds and returns the " +
+		"messages, starting with those the broker retains, such as a device's last state. Allowed topics: " +
+		strings.Join(t.topics, ", ")
+}
+
+func (t *MQTTTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"publish", "read"},
+				"description": "What to do",
+			},
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "The topic to publish on, or the topic filter to read",
+			},
+			"payload": map[string]any{
+				"type":        "string",
+				"description": "The message (publish), e.g. 'ON' or '{\"brightness\": 80}'",
+			},
+			"qos": map[string]any{
+				"type":        "integer",
+				"enum":        []int{0, 1},
+				"description": "1 (default) waits for the broker to acknowledge the message; 0 doesn't (publish)",
+			},
+			"retain": map[string]any{
+				"type":        "boolean",
+				"description": "Have the broker keep the message for future subscribers (publish)",
+			},
+			"wait_seconds": map[string]any{
+				"type":        "integer",
+				"description": "How long to listen (read); default 3, at most 30",
+			},
+		},
+		"required": []string{"action", "topic"},
+	}
+}
+
+func (t *MQTTTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	topic, _ := args["topic"].(string)
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return ErrorResult("topic is required")
+	}
+	switch action {
+	case "publish":
+		return t.doPublish(ctx, topic, args)
+	case "read":
+		return t.read(ctx, topic, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// allowed reports whether one of the tool's filters matches topic.
+func (t *MQTTTool) allowed(topic string) bool {
+	for _, filter := range t.topics {
+		if mqtt.Match(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *MQTTTool) doPublish(ctx context.Context, topic string, args map[string]any) *ToolResult {
+	if strings.ContainsAny(topic, "+#") {
+		return ErrorResult("wildcards can only be read; publish on a topic without + or #")
+	}
+	if !t.allowed(topic) {
+		return ErrorResult(fmt.Sprintf("publishing on %s is not allowed; allowed topics: %s", topic,
+			strings.Join(t.topics, ", ")))
+	}
+	var payload []byte
+	switch p := args["payload"].(type) {
+	case string:
+		payload = []byte(p)
+	case nil:
+	default:
+		data, err := json.Marshal(p)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("can't encode the payload: %v", err))
+		}
+		payload = data
+	}
+	qos := byte(1)
+	if q, ok := args["qos"].(float64); ok && q == 0 {
+		qos = 0
+	}
+	retain, _ := args["retain"].(bool)
+
+	err := t.publish(ctx, mqtt.Message{Topic: topic, Payload: payload, Retained: retain}, qos)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't publish on %s: %v", topic, err)).WithError(err)
+	}
+	return SilentResult(fmt.Sprintf("Published %d bytes on %s", len(payload), topic))
+}
+
+func (t *MQTTTool) read(ctx context.Context, filter string, args map[string]any) *ToolResult {
+	wait := defaultMQTTWait
+	if s, ok := args["wait_seconds"].(float64); ok && s > 0 {
+		wait = min(time.Duration(s)*time.Second, maxMQTTWait)
+	}
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		messages []mqtt.Message
+		dropped  int
+	)
+	err := t.subscribe(ctx, []string{filter}, func(msg mqtt.Message) {
+		if !t.allowed(msg.Topic) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(messages) == maxMQTTMessages {
+			dropped++
+			return
+		}
+		messages = append(messages, msg)
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return ErrorResult(fmt.Sprintf("Can't read %s: %v", filter, err)).WithError(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) == 0 {
+		return SilentResult(fmt.Sprintf("No messages on allowed topics matching %s within %s", filter, wait))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d messages on %s within %s:", len(messages)+dropped, filter, wait)
+	for _, msg := range messages {
+		fmt.Fprintf(&sb, "\n- %s", msg.Topic)
+		if msg.Retained {
+			sb.WriteString(" (retained)")
+		}
+		sb.WriteString(": " + describeMQTTPayload(msg.Payload))
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&sb, "\n(%d more not shown)", dropped)
+	}
+	return SilentResult(sb.String())
+}
+
+// describeMQTTPayload returns a payload as text, or its size when binary.
+func describeMQTTPayload(payload []byte) string {
+	if !utf8.Valid(payload) {
+		return fmt.Sprintf("[%d bytes of binary data]", len(payload))
+	}
+	return utils.Truncate(string(payload), 500)
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+)
+
+// fakeMQTTTool returns a tool whose broker records what is published and
+// has retained the given messages.
+func fakeMQTTTool(retained ...mqtt.Message) (*MQTTTool, *[]mqtt.Message) {
+	tool := NewMQTTTool(mqtt.Options{}, []string{"home/+/set", "home/sensors/#"})
+	var published []mqtt.Message
+	tool.publish = func(_ context.Context, msg mqtt.Message, _ byte) error {
+		published = append(published, msg)
+		return nil
+	}
+	tool.subscribe = func(ctx context.Context, filters []string, fn func(mqtt.Message)) error {
+		for _, msg := range retained {
+			if mqtt.Match(filters[0], msg.Topic) {
+				fn(msg)
+			}
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return tool, &published
+}
+
+func TestMQTTTool_Publish(t *testing.T) {
+	tool, published := fakeMQTTTool()
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "publish", "topic": "home/lamp/set", "payload": "ON"})
+	if result.IsError || len(*published) != 1 || string((*published)[0].Payload) != "ON" {
+		t.Fatalf("publish = %+v, published %+v", result, *published)
+	}
+	tool.Execute(ctx, map[string]any{
+		"action":  "publish",
+		"topic":   "home/lamp/set",
+		"payload": map[string]any{"brightness": 80},
+		"retain":  true,
+	})
+	if msg := (*published)[1]; string(msg.Payload) != `{"brightness":80}` || !msg.Retained {
+		t.Errorf("published %+v", msg)
+	}
+
+	for _, topic := range []string{"home/lamp/get", "office/lamp/set", "home/+/set"} {
+		result := tool.Execute(ctx, map[string]any{"action": "publish", "topic": topic, "payload": "ON"})
+		if !result.IsError {
+			t.Errorf("publish on %s succeeded", topic)
+		}
+	}
+	if len(*published) != 2 {
+		t.Errorf("published %d messages, want 2", len(*published))
+	}
+}
+
+func TestMQTTTool_Read(t *testing.T) {
+	tool, _ := fakeMQTTTool(
+		mqtt.Message{Topic: "home/sensors/kitchen", Payload: []byte(`{"temperature":21.5}`), Retained: true},
+		mqtt.Message{Topic: "home/sensors/camera", Payload: []byte{0xff, 0xd8, 0xff}},
+		mqtt.Message{Topic: "home/alarm/code", Payload: []byte("1234"), Retained: true},
+	)
+	result := tool.Execute(context.Background(), map[string]any{"action": "read", "topic": "home/#", "wait_seconds": 1.0})
+	if result.IsError {
+		t.Fatal(result.ForLLM)
+	}
+	for _, want := range []string{
+		`home/sensors/kitchen (retained): {"temperature":21.5}`,
+		"home/sensors/camera: [3 bytes of binary data]",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("read = %q, want %q in it", result.ForLLM, want)
+		}
+	}
+	if strings.Contains(result.ForLLM, "1234") {
+		t.Errorf("read = %q, shows a topic not allowed", result.ForLLM)
+	}
+}
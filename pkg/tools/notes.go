@@ -0,0 +1,217 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/notes"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultNotesLimit = 10
+	maxNotesLimit     = 50
+	noteSnippetChars  = 240
+)
+
+// NotesTool is the agent's knowledge base: notes kept as markdown files in
+// the workspace, which the user can browse and edit too.
+type NotesTool struct {
+	store *notes.Store
+}
+
+func NewNotesTool(store *notes.Store) *NotesTool {
+	return &NotesTool{store: store}
+}
+
+func (t *NotesTool) Name() string {
+	return "notes"
+}
+
+func (t *NotesTool) Description() string {
+	return "Keep notes for the user and yourself: facts, lists, plans, research findings, anything worth finding " +
+		"again. 'save' writes a note (replacing the note of the same title), 'append' adds to one, 'get' reads " +
+		"one, 'search' finds notes by words and meaning, 'list' shows the latest notes, 'tag' changes tags, " +
+		"'delete' removes one. Notes are markdown files in the workspace's notes folder; the user can read " +
+		"and edit them there. Search notes before answering questions about things the user asked you to note."
+}
+
+func (t *NotesTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"save", "append", "get", "search", "list", "tag", "delete"},
+				"description": "What to do",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "Title of the note to save",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "The note's name, as shown by search and list, or its title (append, get, tag, delete)",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "Markdown text of the note (save), or to add to it (append)",
+			},
+			"tags": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Tags of the note (save), to add (tag), or that notes must have (search, list)",
+			},
+			"remove_tags": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Tags to remove (tag)",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "What to look for (search)",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Most notes to return (search, list); default 10",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *NotesTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	name, _ := args["name"].(string)
+	content, _ := args["content"].(string)
+	tags := listArg(args["tags"])
+
+	switch action {
+	case "save":
+		title, _ := args["title"].(string)
+		if title == "" {
+			title = name
+		}
+		if _, ok := args["tags"]; !ok {
+			tags = nil // Keep the tags of a note replaced
+		} else if tags == nil {
+			tags = []string{}
+		}
+		note, created, err := t.store.Save(title, content, tags)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Can't save the note: %v", err)).WithError(err)
+		}
+		verb := "Updated"
+		if created {
+			verb = "Saved"
+		}
+		return SilentResult(fmt.Sprintf("%s note %s", verb, describeNote(note)))
+	case "append":
+		if content == "" {
+			return ErrorResult("content is required to append to a note")
+		}
+		note, err := t.store.Append(name, content)
+		if err != nil {
+			return notesError(err)
+		}
+		return SilentResult("Added to note " + describeNote(note))
+	case "get":
+		note, err := t.store.Get(name)
+		if err != nil {
+			return notesError(err)
+		}
+		return SilentResult(fmt.Sprintf("%s\nUpdated %s\n\n%s",
+			describeNote(note), note.Updated.Local().Format("2006-01-02 15:04"), note.Body))
+	case "search", "list":
+		query, _ := args["query"].(string)
+		if action == "search" && strings.TrimSpace(query) == "" {
+			return ErrorResult("query is required to search notes")
+		}
+		if action == "list" {
+			query = ""
+		}
+		return t.search(ctx, query, tags, args)
+	case "tag":
+		remove := listArg(args["remove_tags"])
+		if len(tags) == 0 && len(remove) == 0 {
+			return ErrorResult("tags or remove_tags is required to tag a note")
+		}
+		note, err := t.store.Tag(name, tags, remove)
+		if err != nil {
+			return notesError(err)
+		}
+		return SilentResult("Tagged note " + describeNote(note))
+	case "delete":
+		if err := t.store.Delete(name); err != nil {
+			return notesError(err)
+		}
+		return SilentResult(fmt.Sprintf("Note %s deleted", name))
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *NotesTool) search(ctx context.Context, query string, tags []string, args map[string]any) *ToolResult {
+	limit := defaultNotesLimit
+	if n, ok := args["limit"].(float64); ok && n > 0 {
+		limit = min(int(n), maxNotesLimit)
+	}
+	found, err := t.store.Search(ctx, query, tags, limit)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't search notes: %v", err)).WithError(err)
+	}
+	if len(found) == 0 {
+		if query == "" {
+			return SilentResult("No notes")
+		}
+		return SilentResult(fmt.Sprintf("No notes match %q", query))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d notes:", len(found))
+	for _, n := range found {
+		fmt.Fprintf(&sb, "\n- %s, updated %s", describeNote(&n), n.Updated.Local().Format("2006-01-02"))
+		if snippet := noteSnippet(n.Body, query); snippet != "" {
+			sb.WriteString("\n  " + snippet)
+		}
+	}
+	return SilentResult(sb.String())
+}
+
+func notesError(err error) *ToolResult {
+	if errors.Is(err, notes.ErrNotFound) {
+		return ErrorResult(err.Error() + "; search or list the notes for its name")
+	}
+	return ErrorResult(err.Error()).WithError(err)
+}
+
+func describeNote(n *notes.Note) string {
+	s := fmt.Sprintf("[%s] %s", n.Name, n.Title)
+	if len(n.Tags) > 0 {
+		s += " #" + strings.Join(n.Tags, " #")
+	}
+	return s
+}
+
+// noteSnippet returns the part of body around the first word of query it
+// contains, or its beginning, on one line.
+func noteSnippet(body, query string) string {
+	text := strings.Join(strings.Fields(body), " ")
+	lower := strings.ToLower(text)
+	start := 0
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if i := strings.Index(lower, w); len(w) > 2 && i >= 0 {
+			start = min(len(text), max(0, i-noteSnippetChars/4))
+			for start > 0 && !strings.HasPrefix(text[start:], " ") {
+				start-- // Don't cut a word or a UTF-8 sequence
+			}
+			break
+		}
+	}
+	snippet := utils.Truncate(strings.TrimSpace(text[start:]), noteSnippetChars)
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	return snippet
+}
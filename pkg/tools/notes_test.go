@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/notes"
+)
+
+func TestNotesTool(t *testing.T) {
+	tool := NewNotesTool(notes.NewStore(filepath.Join(t.TempDir(), "notes"), nil))
+	ctx := context.Background()
+	run := func(args map[string]any) *ToolResult {
+		t.Helper()
+		result := tool.Execute(ctx, args)
+		if result.IsError {
+			t.Fatalf("%v: %s", args, result.ForLLM)
+		}
+		return result
+	}
+
+	result := run(map[string]any{
+		"action": "save", "title": "Wi-Fi at the cabin", "content": "Network: cabin, password: pinecone", "tags": "home",
+	})
+	if result.ForLLM != "Saved note [wi-fi-at-the-cabin] Wi-Fi at the cabin #home" {
+		t.Errorf("save = %q", result.ForLLM)
+	}
+	run(map[string]any{"action": "append", "name": "wi-fi-at-the-cabin", "content": "Router in the attic."})
+	run(map[string]any{"action": "save", "title": "Books", "content": "Dune, then Hyperion."})
+
+	result = run(map[string]any{"action": "search", "query": "cabin password"})
+	if !strings.HasPrefix(result.ForLLM, "1 notes:\n- [wi-fi-at-the-cabin]") ||
+		!strings.Contains(result.ForLLM, "password: pinecone Router in the attic.") {
+		t.Errorf("search = %q", result.ForLLM)
+	}
+
+	result = run(map[string]any{"action": "get", "name": "Wi-Fi at the cabin"})
+	if !strings.HasSuffix(result.ForLLM, "\n\nNetwork: cabin, password: pinecone\n\nRouter in the attic.") {
+		t.Errorf("get = %q", result.ForLLM)
+	}
+
+	// Saving again without tags keeps them.
+	result = run(map[string]any{"action": "save", "title": "wi-fi at the cabin", "content": "Moved to the shed."})
+	if !strings.HasPrefix(result.ForLLM, "Updated note") || !strings.HasSuffix(result.ForLLM, "#home") {
+		t.Errorf("save again = %q", result.ForLLM)
+	}
+
+	run(map[string]any{"action": "tag", "name": "books", "tags": []any{"reading", "fun"}})
+	result = run(map[string]any{"action": "list", "tags": []any{"reading"}})
+	if !strings.Contains(result.ForLLM, "[books] Books #reading #fun") || strings.Contains(result.ForLLM, "cabin") {
+		t.Errorf("list = %q", result.ForLLM)
+	}
+
+	run(map[string]any{"action": "delete", "name": "books"})
+	for _, args := range []map[string]any{
+		{"action": "get", "name": "books"},
+		{"action": "search"},
+		{"action": "append", "name": "wi-fi-at-the-cabin"},
+		{"action": "save", "content": "no title"},
+		{"action": "tag", "name": "wi-fi-at-the-cabin"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v = %q, want an error", args, result.ForLLM)
+		}
+	}
+}
+
+func TestNoteSnippet(t *testing.T) {
+	body := strings.Repeat("filler words here ", 20) + "the answer is 42.\n\nMore text."
+	got := noteSnippet(body, "what is the ANSWER")
+	if !strings.HasPrefix(got, "...") || !strings.Contains(got, "the answer is 42. More text.") {
+		t.Errorf("noteSnippet() = %q", got)
+	}
+	if got := noteSnippet("Short note.", ""); got != "Short note." {
+		t.Errorf("noteSnippet() without query = %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// ExecuteAll runs the tool calls of one model turn, at most limit at a time,
+// and returns their results in call order. A limit of 1 or less runs them
+// sequentially. asyncCallback, if non-nil, supplies the callback for each
+// call to an AsyncTool.
+//
+// Tools that hold per-call state (ContextualTool, AsyncTool) are never run
+// concurrently with themselves, since their context is set on the shared
+// instance before each call.
+func (r *ToolRegistry) ExecuteAll(
+	ctx context.Context,
+	calls []providers.ToolCall,
+	channel, chatID string,
+	limit int,
+	asyncCallback func(tc providers.ToolCall) AsyncCallback,
+) []*ToolResult {
+	results := make([]*ToolResult, len(calls))
+	execute := func(i int) {
+		tc := calls[i]
+		var cb AsyncCallback
+		if asyncCallback != nil {
+			cb = asyncCallback(tc)
+		}
+		if lock := r.exclusiveLock(tc.Name); lock != nil {
+			lock.Lock()
+			defer lock.Unlock()
+		}
+		results[i] = r.ExecuteWithContext(ctx, tc.Name, tc.Arguments, channel, chatID, cb)
+	}
+
+	if limit <= 1 || len(calls) <= 1 {
+		for i := range calls {
+			execute(i)
+		}
+		return results
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			execute(i)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// exclusiveLock returns the lock serializing calls to a stateful tool, or
+// nil for tools that are safe to run concurrently.
+func (r *ToolRegistry) exclusiveLock(name string) *sync.Mutex {
+	tool, ok := r.Get(name)
+	if !ok {
+		return nil
+	}
+	_, contextual := tool.(ContextualTool)
+	_, async := tool.(AsyncTool)
+	if !contextual && !async {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.exclusive == nil {
+		r.exclusive = make(map[string]*sync.Mutex)
+	}
+	lock, ok := r.exclusive[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.exclusive[name] = lock
+	}
+	return lock
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// concurrencyTool echoes its "id" argument after a short delay and records
+// the highest number of overlapping calls.
+type concurrencyTool struct {
+	name string
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *concurrencyTool) Name() string               { return c.name }
+func (c *concurrencyTool) Description() string        { return "records concurrency" }
+func (c *concurrencyTool) Parameters() map[string]any { return map[string]any{"type": "object"} }
+
+func (c *concurrencyTool) Execute(_ context.Context, args map[string]any) *ToolResult {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return SilentResult(fmt.Sprint(args["id"]))
+}
+
+type contextualConcurrencyTool struct {
+	concurrencyTool
+}
+
+func (c *contextualConcurrencyTool) SetContext(channel, chatID string) {}
+
+func toolCalls(name string, n int) []providers.ToolCall {
+	calls := make([]providers.ToolCall, n)
+	for i := range calls {
+		calls[i] = providers.ToolCall{ID: fmt.Sprintf("call_%d", i), Name: name, Arguments: map[string]any{"id": i}}
+	}
+	return calls
+}
+
+func TestToolRegistry_ExecuteAll_BoundedAndOrdered(t *testing.T) {
+	r := NewToolRegistry()
+	tool := &concurrencyTool{name: "slow"}
+	r.Register(tool)
+
+	results := r.ExecuteAll(context.Background(), toolCalls("slow", 6), "cli", "direct", 3, nil)
+
+	for i, res := range results {
+		if res.ForLLM != fmt.Sprint(i) {
+			t.Errorf("results[%d] = %q, want %d", i, res.ForLLM, i)
+		}
+	}
+	if tool.peak < 2 || tool.peak > 3 {
+		t.Errorf("peak concurrency = %d, want 2..3", tool.peak)
+	}
+}
+
+func TestToolRegistry_ExecuteAll_SequentialLimit(t *testing.T) {
+	r := NewToolRegistry()
+	tool := &concurrencyTool{name: "slow"}
+	r.Register(tool)
+
+	r.ExecuteAll(context.Background(), toolCalls("slow", 3), "", "", 1, nil)
+	if tool.peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1", tool.peak)
+	}
+}
+
+func TestToolRegistry_ExecuteAll_SerializesContextualTools(t *testing.T) {
+	r := NewToolRegistry()
+	tool := &contextualConcurrencyTool{concurrencyTool{name: "message"}}
+	r.Register(tool)
+
+	calls := append(toolCalls("message", 3), providers.ToolCall{ID: "missing", Name: "nope"})
+	results := r.ExecuteAll(context.Background(), calls, "cli", "direct", 4, nil)
+
+	if tool.peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1 for a contextual tool", tool.peak)
+	}
+	if !results[3].IsError {
+		t.Errorf("results[3] = %+v, want error for unknown tool", results[3])
+	}
+}
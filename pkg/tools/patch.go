@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PatchFileTool changes part of a file with a unified diff or a list of
+// search/replace edits, so the model needn't rewrite the whole file. Either
+// every change applies or the file is left as it was.
+type PatchFileTool struct {
+	fs fileSystem
+}
+
+// NewPatchFileTool creates a new PatchFileTool with optional directory restriction.
+func NewPatchFileTool(workspace string, restrict bool) *PatchFileTool {
+	var fs fileSystem
+	if restrict {
+		fs = &sandboxFs{workspace: workspace}
+	} else {
+		fs = &hostFs{}
+	}
+	return &PatchFileTool{fs: fs}
+}
+
+func (t *PatchFileTool) Name() string {
+	return "patch_file"
+}
+
+func (t *PatchFileTool) Description() string {
+	return "Change parts of a file without rewriting it: give either a unified diff (with @@ hunks and a few " +
+		"lines of context) or a list of edits, each replacing an exact old_text that occurs once. " +
+		"All changes apply or none do. Prefer this to write_file for changes to existing files."
+}
+
+func (t *PatchFileTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The file to change",
+			},
+			"diff": map[string]any{
+				"type":        "string",
+				"description": "A unified diff of the file; line numbers in @@ headers may be approximate",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "Search/replace edits, applied in order",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"old_text": map[string]any{"type": "string"},
+						"new_text": map[string]any{"type": "string"},
+					},
+					"required": []string{"old_text", "new_text"},
+				},
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *PatchFileTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	path, ok := args["path"].(string)
+	if !ok {
+		return ErrorResult("path is required")
+	}
+	diff, _ := args["diff"].(string)
+	edits, _ := args["edits"].([]any)
+	if (diff == "") == (len(edits) == 0) {
+		return ErrorResult("give either diff or edits")
+	}
+
+	content, err := t.fs.ReadFile(path)
+	if err != nil && !(errors.Is(err, fs.ErrNotExist) && diff != "") {
+		return ErrorResult(err.Error())
+	}
+	existed := err == nil
+
+	var (
+		patched      string
+		added, taken int
+	)
+	if diff != "" {
+		hunks, err := parseUnifiedDiff(diff)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		if !existed {
+			for _, h := range hunks {
+				if len(h.old) > 0 {
+					return ErrorResult(fmt.Sprintf("%s doesn't exist, and the diff changes existing lines", path))
+				}
+			}
+		}
+		if patched, err = applyHunks(string(content), hunks); err != nil {
+			return ErrorResult(err.Error())
+		}
+		for _, h := range hunks {
+			added, taken = added+h.added, taken+h.taken
+		}
+		if !existed && !strings.HasSuffix(patched, "\n") {
+			patched += "\n"
+		}
+	} else {
+		patched = string(content)
+		for i, raw := range edits {
+			edit, _ := raw.(map[string]any)
+			oldText, ok1 := edit["old_text"].(string)
+			newText, ok2 := edit["new_text"].(string)
+			if !ok1 || !ok2 || oldText == "" {
+				return ErrorResult(fmt.Sprintf("edit %d needs old_text and new_text", i+1))
+			}
+			next, err := replaceEditContent([]byte(patched), oldText, newText)
+			if err != nil {
+				return ErrorResult(fmt.Sprintf("edit %d: %v", i+1, err))
+			}
+			patched = string(next)
+			added += strings.Count(newText, "\n") + 1
+			taken += strings.Count(oldText, "\n") + 1
+		}
+	}
+
+	if err := t.fs.WriteFile(path, []byte(patched)); err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(fmt.Sprintf("Patched %s (+%d -%d lines)", path, added, taken))
+}
+
+// hunk is a change from a unified diff: the old lines, context included,
+// are replaced by the new ones. start is the old line number (from 1) the
+// header gave, or 0.
+type hunk struct {
+	start        int
+	old, new     []string
+	added, taken int // The + and - lines
+}
+
+var reHunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// parseUnifiedDiff reads the hunks of a single-file unified diff. Headers
+// without line numbers ("@@ ... @@") are accepted, as models often write
+// them; such hunks are found by their content alone.
+func parseUnifiedDiff(diff string) ([]hunk, error) {
+	var (
+		hunks []hunk
+		cur   *hunk
+	)
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks = append(hunks, hunk{})
+			cur = &hunks[len(hunks)-1]
+			if m := reHunkHeader.FindStringSubmatch(line); m != nil {
+				cur.start, _ = strconv.Atoi(m[1])
+			}
+		case cur == nil:
+			// Headers before the first hunk: diff, index, --- and +++.
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "-"):
+			cur.old = append(cur.old, line[1:])
+			cur.taken++
+		case strings.HasPrefix(line, "+"):
+			cur.new = append(cur.new, line[1:])
+			cur.added++
+		case strings.HasPrefix(line, " "):
+			cur.old = append(cur.old, line[1:])
+			cur.new = append(cur.new, line[1:])
+		case line == "":
+			// An empty context line whose leading space was lost.
+			cur.old = append(cur.old, "")
+			cur.new = append(cur.new, "")
+		default:
+			return nil, fmt.Errorf("not a unified diff line: %q", line)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, errors.New("the diff has no @@ hunks")
+	}
+	return hunks, nil
+}
+
+// applyHunks applies hunks in order. Each is looked for after the previous
+// one, nearest to the line its header gave, first exactly and then
+// ignoring trailing whitespace.
+func applyHunks(content string, hunks []hunk) (string, error) {
+	lines := strings.Split(content, "\n")
+	if content == "" {
+		lines = nil
+	}
+	from, shift := 0, 0
+	for i, h := range hunks {
+		var at int
+		switch {
+		case len(h.old) == 0:
+			at = min(max(h.start+shift, from), len(lines))
+		default:
+			at = findHunk(lines, h, from, shift, func(a, b string) bool { return a == b })
+			if at < 0 {
+				at = findHunk(lines, h, from, shift, func(a, b string) bool {
+					return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t")
+				})
+			}
+		}
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d doesn't match the file; read it again and make the context and - lines "+
+				"match exactly, starting with %q", i+1, h.old[0])
+		}
+		lines = append(lines[:at], append(append([]string{}, h.new...), lines[at+len(h.old):]...)...)
+		from = at + len(h.new)
+		shift += len(h.new) - len(h.old)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// findHunk returns where h's old lines are in lines at or after from,
+// nearest to its header's line, or -1.
+func findHunk(lines []string, h hunk, from, shift int, equal func(a, b string) bool) int {
+	want := h.start - 1 + shift
+	best := -1
+	for at := from; at+len(h.old) <= len(lines); at++ {
+		match := true
+		for j, old := range h.old {
+			if !equal(lines[at+j], old) {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if best < 0 || h.start > 0 && distance(at, want) < distance(best, want) {
+			best = at
+		}
+		if h.start == 0 {
+			break
+		}
+	}
+	return best
+}
+
+func distance(a, b int) int {
+	if a < b {
+		return b - a
+	}
+	return a - b
+}
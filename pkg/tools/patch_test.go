@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nginxConf = `server {
+    listen 80;
+    server_name example.com;
+
+    location / {
+        root /var/www;
+    }
+}
+`
+
+func TestPatchFileTool_UnifiedDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "site.conf")
+	os.WriteFile(path, []byte(nginxConf), 0o644)
+	tool := NewPatchFileTool(dir, true)
+
+	// The line numbers are off by two, and the blank context line lost its space.
+	diff := `--- a/site.conf
++++ b/site.conf
+@@ -4,3 +4,4 @@ server {
+     server_name example.com;
+
+     location / {
++        index index.html;
+@@ -8,2 +9,3 @@
+     }
++    gzip on;
+ }
+`
+	result := tool.Execute(context.Background(), map[string]any{"path": "site.conf", "diff": diff})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if result.ForLLM != "Patched site.conf (+2 -0 lines)" {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+	got, _ := os.ReadFile(path)
+	want := strings.Replace(nginxConf, "location / {\n", "location / {\n        index index.html;\n", 1)
+	want = strings.Replace(want, "    }\n}", "    }\n    gzip on;\n}", 1)
+	if string(got) != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPatchFileTool_DiffMismatchLeavesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "site.conf")
+	os.WriteFile(path, []byte(nginxConf), 0o644)
+	tool := NewPatchFileTool(dir, true)
+
+	diff := "@@ -1,2 +1,2 @@\n server {\n-    listen 80;\n+    listen 8080;\n" +
+		"@@ -20 +20 @@\n-    listen 443;\n+    listen 8443;\n"
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "diff": diff})
+	if !result.IsError || !strings.Contains(result.ForLLM, `hunk 2 doesn't match the file`) {
+		t.Errorf("result = %q, want hunk 2 to fail", result.ForLLM)
+	}
+	if got, _ := os.ReadFile(path); string(got) != nginxConf {
+		t.Errorf("the file changed although a hunk failed:\n%s", got)
+	}
+}
+
+func TestPatchFileTool_NewFile(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewPatchFileTool(dir, true)
+
+	diff := "--- /dev/null\n+++ b/notes.md\n@@ -0,0 +1,2 @@\n+# Notes\n+- buy milk\n"
+	if result := tool.Execute(context.Background(), map[string]any{"path": "notes.md", "diff": diff}); result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "notes.md")); string(got) != "# Notes\n- buy milk\n" {
+		t.Errorf("file = %q", got)
+	}
+
+	diff = "@@ -1 +1 @@\n-old\n+new\n"
+	if result := tool.Execute(context.Background(), map[string]any{"path": "missing.md", "diff": diff}); !result.IsError {
+		t.Error("changing lines of a missing file succeeded")
+	}
+}
+
+func TestPatchFileTool_Edits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "site.conf")
+	os.WriteFile(path, []byte(nginxConf), 0o644)
+	tool := NewPatchFileTool(dir, true)
+
+	result := tool.Execute(context.Background(), map[string]any{"path": path, "edits": []any{
+		map[string]any{"old_text": "listen 80;", "new_text": "listen 8080;"},
+		map[string]any{"old_text": "root /var/www;", "new_text": "root /srv/www;"},
+	}})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), "listen 8080;") || !strings.Contains(string(got), "root /srv/www;") {
+		t.Errorf("file =\n%s", got)
+	}
+
+	// The second edit fails, so neither is written.
+	result = tool.Execute(context.Background(), map[string]any{"path": path, "edits": []any{
+		map[string]any{"old_text": "listen 8080;", "new_text": "listen 80;"},
+		map[string]any{"old_text": "not there", "new_text": "x"},
+	}})
+	if !result.IsError || !strings.Contains(result.ForLLM, "edit 2: old_text not found") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+	if again, _ := os.ReadFile(path); string(again) != string(got) {
+		t.Error("the file changed although an edit failed")
+	}
+
+	for _, args := range []map[string]any{
+		{"path": path},
+		{"path": path, "diff": "@@\n-a\n+b", "edits": []any{map[string]any{"old_text": "a", "new_text": "b"}}},
+		{"path": path, "diff": "not a diff"},
+	} {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Execute(%v) succeeded", args)
+		}
+	}
+}
+
+func TestApplyHunks_NearestMatch(t *testing.T) {
+	content := "x = 1\ny = 2\nx = 1\ny = 2\n"
+	hunks, err := parseUnifiedDiff("@@ -3,1 +3,1 @@\n-x = 1\n+x = 3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := applyHunks(content, hunks)
+	if err != nil || got != "x = 1\ny = 2\nx = 3\ny = 2\n" {
+		t.Errorf("applyHunks() = %q, %v; want the second x changed", got, err)
+	}
+
+	// Without line numbers, the first match after the previous hunk is used.
+	hunks, _ = parseUnifiedDiff("@@ @@\n-x = 1\n+x = 3\n@@ @@\n-x = 1  \n+x = 4\n")
+	got, err = applyHunks(content, hunks)
+	if err != nil || got != "x = 3\ny = 2\nx = 4\ny = 2\n" {
+		t.Errorf("applyHunks() = %q, %v", got, err)
+	}
+}
@@ -12,8 +12,10 @@ import (
 )
 
 type ToolRegistry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
+	tools     map[string]Tool
+	allowed   map[string]bool        // see SetAllowed
+	exclusive map[string]*sync.Mutex // see exclusiveLock
+	mu        sync.RWMutex
 }
 
 func NewToolRegistry() *ToolRegistry {
@@ -25,9 +27,32 @@ func NewToolRegistry() *ToolRegistry {
 func (r *ToolRegistry) Register(tool Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.allowed != nil && !r.allowed[tool.Name()] {
+		return
+	}
 	r.tools[tool.Name()] = tool
 }
 
+// SetAllowed limits the registry to the named tools: others are removed,
+// and ignored when registered later. An empty list allows every tool.
+func (r *ToolRegistry) SetAllowed(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(names) == 0 {
+		r.allowed = nil
+		return
+	}
+	r.allowed = make(map[string]bool, len(names))
+	for _, name := range names {
+		r.allowed[name] = true
+	}
+	for name := range r.tools {
+		if !r.allowed[name] {
+			delete(r.tools, name)
+		}
+	}
+}
+
 func (r *ToolRegistry) Get(name string) (Tool, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -64,6 +89,30 @@ func (r *ToolRegistry) ExecuteWithContext(
 		return ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
 	}
 
+	// Reject malformed calls before the tool sees them; the model reads the
+	// error and can call again with corrected arguments.
+	if err := ValidateArgs(tool.Parameters(), args); err != nil {
+		logger.WarnCF("tool", "Invalid tool arguments",
+			map[string]any{
+				"tool":  name,
+				"error": err.Error(),
+			})
+		return ErrorResult(fmt.Sprintf("invalid arguments for tool %q: %v. Call it again with arguments matching its parameters.",
+			name, err)).WithError(err)
+	}
+
+	if approve := approverFrom(ctx); approve != nil {
+		if err := approve(ctx, name, args); err != nil {
+			logger.WarnCF("tool", "Tool call not approved",
+				map[string]any{
+					"tool":  name,
+					"error": err.Error(),
+				})
+			return ErrorResult(fmt.Sprintf("Not run: %v. Don't retry this call; "+
+				"tell the user or find another way.", err)).WithError(err)
+		}
+	}
+
 	// If tool implements ContextualTool, set context
 	if contextualTool, ok := tool.(ContextualTool); ok && channel != "" && chatID != "" {
 		contextualTool.SetContext(channel, chatID)
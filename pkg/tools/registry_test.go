@@ -104,6 +104,28 @@ func TestToolRegistry_RegisterOverwrite(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_SetAllowed(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("read_file", "reads"))
+	r.Register(newMockTool("exec", "runs"))
+
+	r.SetAllowed([]string{"read_file", "web_search"})
+	r.Register(newMockTool("web_search", "searches"))
+	r.Register(newMockTool("write_file", "writes"))
+
+	for name, want := range map[string]bool{"read_file": true, "web_search": true, "exec": false, "write_file": false} {
+		if _, ok := r.Get(name); ok != want {
+			t.Errorf("Get(%q) ok = %v, want %v", name, ok, want)
+		}
+	}
+
+	r.SetAllowed(nil)
+	r.Register(newMockTool("exec", "runs"))
+	if _, ok := r.Get("exec"); !ok {
+		t.Error("exec should register once every tool is allowed")
+	}
+}
+
 func TestToolRegistry_Execute_Success(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(&mockRegistryTool{
@@ -348,3 +370,24 @@ func TestToolRegistry_ConcurrentAccess(t *testing.T) {
 		t.Error("expected tools to be registered after concurrent access")
 	}
 }
+
+func TestToolRegistry_RejectsInvalidArgs(t *testing.T) {
+	r := NewToolRegistry()
+	tool := newMockTool("write", "writes a file")
+	tool.params = map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"path": map[string]any{"type": "string"}},
+		"required":   []string{"path"},
+	}
+	tool.result = nil // Execute must not be reached
+	r.Register(tool)
+
+	result := r.Execute(context.Background(), "write", map[string]any{"raw": `{"path": `})
+	if !result.IsError || !strings.Contains(result.ForLLM, "Call it again") {
+		t.Errorf("result = %+v, want an error asking for a corrected call", result)
+	}
+	result = r.Execute(context.Background(), "write", map[string]any{})
+	if !result.IsError || !strings.Contains(result.ForLLM, `missing required parameter "path"`) {
+		t.Errorf("result = %+v, want a missing parameter error", result)
+	}
+}
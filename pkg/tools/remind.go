@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// ReminderKind is the payload kind of the jobs RemindTool creates.
+const ReminderKind = "reminder"
+
+// RemindTool sets one-shot reminders. They are kept as cron jobs, so they
+// survive restarts, and are delivered as is to the chat they were set in.
+type RemindTool struct {
+	cronService *cron.CronService
+	channel     string
+	chatID      string
+	mu          sync.RWMutex
+}
+
+// NewRemindTool creates a RemindTool storing its reminders in cronService.
+func NewRemindTool(cronService *cron.CronService) *RemindTool {
+	return &RemindTool{cronService: cronService}
+}
+
+func (t *RemindTool) Name() string {
+	return "remind"
+}
+
+func (t *RemindTool) Description() string {
+	return "Set a one-time reminder that is sent to this chat at the given time, e.g. 'remind me in 45 minutes to " +
+		"flip the laundry' → when='in 45 minutes', text='Flip the laundry'. Pass the user's time zone as 'tz' " +
+		"when you know it. Also lists and cancels this chat's reminders. For repeating tasks, use the cron tool."
+}
+
+func (t *RemindTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"add", "list", "cancel"},
+				"description": "add a reminder, list this chat's pending reminders, or cancel one",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "What to remind the user of (for add)",
+			},
+			"when": map[string]any{
+				"type": "string",
+				"description": "When to send it (for add): 'in 45 minutes', 'at 18:30', 'tomorrow at 7am', " +
+					"'on friday at 9', '2026-12-24 at 18:00' or an ISO time like 2026-12-24T18:00",
+			},
+			"tz": map[string]any{
+				"type":        "string",
+				"description": "IANA time zone of 'when' (e.g. 'Europe/Paris'). Default: the server's.",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "Reminder ID (for cancel)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *RemindTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *RemindTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	t.mu.RLock()
+	channel, chatID := t.channel, t.chatID
+	t.mu.RUnlock()
+	if channel == "" || chatID == "" {
+		return ErrorResult("no chat to remind (channel/chat_id not set). Use this tool in an active conversation.")
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		return t.add(args, channel, chatID)
+	case "list":
+		return t.list(channel, chatID)
+	case "cancel":
+		id, _ := args["id"].(string)
+		if id == "" {
+			return ErrorResult("id is required for cancel")
+		}
+		if job := t.find(id, channel, chatID); job == nil || !t.cronService.RemoveJob(id) {
+			return ErrorResult(fmt.Sprintf("no reminder %s in this chat", id))
+		}
+		return SilentResult(fmt.Sprintf("Reminder %s cancelled", id))
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *RemindTool) add(args map[string]any, channel, chatID string) *ToolResult {
+	text, _ := args["text"].(string)
+	when, _ := args["when"].(string)
+	text, when = strings.TrimSpace(text), strings.TrimSpace(when)
+	if text == "" || when == "" {
+		return ErrorResult("text and when are required for add")
+	}
+	tz, _ := args["tz"].(string)
+	loc := time.Local
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return ErrorResult(fmt.Sprintf("unknown time zone %q", tz))
+		}
+	}
+
+	at, err := reminderTime(when, time.Now().In(loc))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	atMS := at.UnixMilli()
+	schedule := cron.CronSchedule{Kind: "at", AtMS: &atMS, TZ: tz}
+
+	job, err := t.cronService.AddJob(utils.Truncate(text, 30), schedule, "⏰ Reminder: "+text, true, channel, chatID)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving reminder: %v", err))
+	}
+	job.Payload.Kind = ReminderKind
+	// A late reminder is better than none.
+	job.CatchUp = cron.CatchUpOnce
+	if err := t.cronService.UpdateJob(job); err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving reminder: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Reminder set for %s (id: %s)", formatReminderTime(at, tz), job.ID))
+}
+
+// reminderTime reads when: an ISO time, or a one-time schedule in plain
+// English (see cron.ParseSchedule), with now giving the time zone.
+func reminderTime(when string, now time.Time) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, when); err == nil {
+		return checkFuture(at, now)
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if at, err := time.ParseInLocation(layout, when, now.Location()); err == nil {
+			return checkFuture(at, now)
+		}
+	}
+	schedule, err := cron.ParseSchedule(when, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't understand when: %v", err)
+	}
+	if schedule.Kind != "at" {
+		return time.Time{}, fmt.Errorf("%q repeats; reminders are one-time, use the cron tool for repeating tasks", when)
+	}
+	return time.UnixMilli(*schedule.AtMS).In(now.Location()), nil
+}
+
+func checkFuture(at, now time.Time) (time.Time, error) {
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+	}
+	return at.In(now.Location()), nil
+}
+
+func formatReminderTime(at time.Time, tz string) string {
+	s := at.Format("Mon 2006-01-02 15:04")
+	if tz != "" {
+		s += " " + tz
+	}
+	return s
+}
+
+func (t *RemindTool) list(channel, chatID string) *ToolResult {
+	var reminders []cron.CronJob
+	for _, job := range t.cronService.ListJobs(false) {
+		if isReminderFor(&job, channel, chatID) {
+			reminders = append(reminders, job)
+		}
+	}
+	if len(reminders) == 0 {
+		return SilentResult("No pending reminders in this chat")
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		return *reminders[i].Schedule.AtMS < *reminders[j].Schedule.AtMS
+	})
+	var sb strings.Builder
+	sb.WriteString("Pending reminders:\n")
+	for _, r := range reminders {
+		at := time.UnixMilli(*r.Schedule.AtMS)
+		if loc, err := time.LoadLocation(r.Schedule.TZ); err == nil && r.Schedule.TZ != "" {
+			at = at.In(loc)
+		}
+		fmt.Fprintf(&sb, "- %s: %s (id: %s)\n", formatReminderTime(at, r.Schedule.TZ),
+			strings.TrimPrefix(r.Payload.Message, "⏰ Reminder: "), r.ID)
+	}
+	return SilentResult(sb.String())
+}
+
+// find returns the reminder id of the chat, or nil.
+func (t *RemindTool) find(id, channel, chatID string) *cron.CronJob {
+	for _, job := range t.cronService.ListJobs(true) {
+		if job.ID == id && isReminderFor(&job, channel, chatID) {
+			return &job
+		}
+	}
+	return nil
+}
+
+func isReminderFor(job *cron.CronJob, channel, chatID string) bool {
+	return job.Payload.Kind == ReminderKind && job.Schedule.AtMS != nil &&
+		job.Payload.Channel == channel && job.Payload.To == chatID
+}
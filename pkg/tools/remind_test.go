@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func TestRemindTool_AddListCancel(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	tool := NewRemindTool(cron.NewCronService(store, nil))
+	tool.SetContext("telegram", "42")
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "add", "text": "Flip the laundry", "when": "in 45 minutes", "tz": "Europe/Paris",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "Europe/Paris") {
+		t.Fatalf("add = %+v", result)
+	}
+	id := strings.TrimSuffix(result.ForLLM[strings.Index(result.ForLLM, "id: ")+4:], ")")
+
+	// The reminder is on disk, for the chat it was set in.
+	jobs := cron.NewCronService(store, nil).ListJobs(false)
+	if len(jobs) != 1 {
+		t.Fatalf("jobs after restart = %+v", jobs)
+	}
+	job := jobs[0]
+	if !job.Payload.Deliver || job.Payload.Channel != "telegram" || job.Payload.To != "42" ||
+		job.Payload.Message != "⏰ Reminder: Flip the laundry" || job.Schedule.Kind != "at" {
+		t.Errorf("job = %+v", job)
+	}
+	if due := time.Until(time.UnixMilli(*job.Schedule.AtMS)); due < 44*time.Minute || due > 46*time.Minute {
+		t.Errorf("due in %v", due)
+	}
+
+	if list := tool.Execute(ctx, map[string]any{"action": "list"}); !strings.Contains(list.ForLLM, "Flip the laundry (id: "+id+")") {
+		t.Errorf("list = %q", list.ForLLM)
+	}
+
+	other := NewRemindTool(cron.NewCronService(store, nil))
+	other.SetContext("telegram", "43")
+	if list := other.Execute(ctx, map[string]any{"action": "list"}); list.ForLLM != "No pending reminders in this chat" {
+		t.Errorf("other chat's list = %q", list.ForLLM)
+	}
+	if r := other.Execute(ctx, map[string]any{"action": "cancel", "id": id}); !r.IsError {
+		t.Error("cancelled another chat's reminder")
+	}
+	if r := tool.Execute(ctx, map[string]any{"action": "cancel", "id": id}); r.IsError {
+		t.Errorf("cancel = %+v", r)
+	}
+}
+
+func TestRemindTool_RejectsBadTimes(t *testing.T) {
+	tool := NewRemindTool(cron.NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil))
+	tool.SetContext("telegram", "42")
+	for _, when := range []string{"every day at 8", "2020-01-01T09:00", "someday"} {
+		result := tool.Execute(context.Background(), map[string]any{"action": "add", "text": "x", "when": when})
+		if !result.IsError {
+			t.Errorf("when %q was accepted: %s", when, result.ForLLM)
+		}
+	}
+}
+
+func TestLateNote(t *testing.T) {
+	due := time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)
+	dueMS := due.UnixMilli()
+	job := &cron.CronJob{
+		Schedule: cron.CronSchedule{Kind: "at", AtMS: &dueMS, TZ: "UTC"},
+		Payload:  cron.CronPayload{Kind: ReminderKind},
+	}
+	if got := lateNote(job, due.Add(10*time.Second)); got != "" {
+		t.Errorf("on-time note = %q", got)
+	}
+	if got := lateNote(job, due.Add(2*time.Hour)); got != " (due at 18:00)" {
+		t.Errorf("late note = %q", got)
+	}
+	job.Payload.Kind = "agent_turn"
+	if got := lateNote(job, due.Add(2*time.Hour)); got != "" {
+		t.Errorf("note for a job that isn't a reminder = %q", got)
+	}
+}
@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	defaultCodeTimeout  = 60 * time.Second
+	defaultCodeCPU      = 30
+	defaultCodeMemoryMB = 512
+	defaultCodeMaxFile  = 20 << 20
+
+	// maxCodeFiles is how many files one run sends at most.
+	maxCodeFiles = 10
+)
+
+// RunCodeTool runs Python and JavaScript snippets, each in a directory of
+// its own under code/ in the workspace, with limits on time, CPU and
+// memory. Files a snippet writes there are sent to the chat.
+type RunCodeTool struct {
+	workspace string
+	opts      RunCodeToolOptions
+
+	sendCallback SendMediaCallback
+	ctxMu        sync.Mutex
+	channel      string
+	chatID       string
+}
+
+// RunCodeToolOptions configures a RunCodeTool; zero values take the
+// defaults.
+type RunCodeToolOptions struct {
+	Python      string                   // Default python3
+	Node        string                   // Default node
+	Sandbox     config.ExecSandboxConfig // Empty Mode runs snippets directly
+	Timeout     time.Duration
+	CPUSeconds  int
+	MemoryMB    int
+	MaxFileSize int64 // Bytes; larger files aren't sent
+	MaxOutput   int   // Characters of output shown
+}
+
+func NewRunCodeTool(workspace string, opts RunCodeToolOptions) *RunCodeTool {
+	if opts.Python == "" {
+		opts.Python = "python3"
+	}
+	if opts.Node == "" {
+		opts.Node = "node"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultCodeTimeout
+	}
+	if opts.CPUSeconds <= 0 {
+		opts.CPUSeconds = defaultCodeCPU
+	}
+	if opts.MemoryMB <= 0 {
+		opts.MemoryMB = defaultCodeMemoryMB
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = defaultCodeMaxFile
+	}
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = defaultExecMaxOutput
+	}
+	return &RunCodeTool{workspace: workspace, opts: opts}
+}
+
+func (t *RunCodeTool) Name() string {
+	return "run_code"
+}
+
+func (t *RunCodeTool) Description() string {
+	return fmt.Sprintf("Run a Python or JavaScript (Node) snippet and return what it prints, for calculations, "+
+		"data processing and charts. Each run starts in a new, empty working directory; files the code writes "+
+		"there (charts, CSVs) are sent to the user, so write scratch files to /tmp. Read workspace files by "+
+		"absolute path. Limits: %s, %d CPU seconds, %d MB of memory. matplotlib must save figures to files.",
+		t.opts.Timeout, t.opts.CPUSeconds, t.opts.MemoryMB)
+}
+
+func (t *RunCodeTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"language": map[string]any{
+				"type":        "string",
+				"enum":        []string{"python", "javascript"},
+				"description": "Language of the code",
+			},
+			"code": map[string]any{
+				"type":        "string",
+				"description": "The program to run",
+			},
+		},
+		"required": []string{"language", "code"},
+	}
+}
+
+func (t *RunCodeTool) SetContext(channel, chatID string) {
+	t.ctxMu.Lock()
+	defer t.ctxMu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *RunCodeTool) SetSendCallback(callback SendMediaCallback) {
+	t.sendCallback = callback
+}
+
+func (t *RunCodeTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	language, _ := args["language"].(string)
+	code, _ := args["code"].(string)
+	if strings.TrimSpace(code) == "" {
+		return ErrorResult("code is required")
+	}
+
+	var file, command string
+	switch language {
+	case "python":
+		file = "main.py"
+		command = fmt.Sprintf("ulimit -v %d && exec %s main.py", t.opts.MemoryMB<<10, shellQuote(t.opts.Python))
+	case "javascript":
+		// V8 reserves far more address space than it uses, so its heap is
+		// limited instead.
+		file = "main.js"
+		command = fmt.Sprintf("exec %s --max-old-space-size=%d main.js", shellQuote(t.opts.Node), t.opts.MemoryMB)
+	default:
+		return ErrorResult(`language must be "python" or "javascript"`)
+	}
+	command = fmt.Sprintf("export MPLBACKEND=Agg PYTHONDONTWRITEBYTECODE=1 && ulimit -t %d && %s",
+		t.opts.CPUSeconds, command)
+
+	dir := filepath.Join(t.workspace, "code", time.Now().Format("20060102-150405")+"-"+uuid.NewString()[:8])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return ErrorResult(fmt.Sprintf("can't create the working directory: %v", err)).WithError(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(code), 0o644); err != nil {
+		return ErrorResult(fmt.Sprintf("can't write the code: %v", err)).WithError(err)
+	}
+
+	output, runErr := t.run(ctx, dir, file, command)
+	files := codeOutputFiles(dir, file, t.opts.MaxFileSize)
+
+	var sb strings.Builder
+	sb.WriteString(output)
+	if len(files) > 0 {
+		sb.WriteString("\n\nFiles written:")
+		for _, f := range files {
+			rel, _ := filepath.Rel(t.workspace, f)
+			sb.WriteString("\n- " + rel)
+		}
+		t.ctxMu.Lock()
+		channel, chatID := t.channel, t.chatID
+		t.ctxMu.Unlock()
+		switch {
+		case t.sendCallback == nil || channel == "" || chatID == "":
+		case t.sendCallback(channel, chatID, "", files) != nil:
+			sb.WriteString("\nSending them to the user failed.")
+		default:
+			sb.WriteString("\nThey were sent to the user.")
+		}
+	}
+	if runErr != nil {
+		return ErrorResult(sb.String()).WithError(runErr)
+	}
+	return SilentResult(sb.String())
+}
+
+// run runs command in dir, in the sandbox if one is set, and returns its
+// output.
+func (t *RunCodeTool) run(ctx context.Context, dir, file, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	defer cancel()
+
+	var (
+		cmd     *exec.Cmd
+		cleanup func()
+	)
+	switch {
+	case t.opts.Sandbox.Mode != "":
+		argv, undo, err := sandboxCommand(t.opts.Sandbox, command, dir, t.workspace)
+		if err != nil {
+			return err.Error(), err
+		}
+		cmd, cleanup = exec.CommandContext(ctx, argv[0], argv[1:]...), undo
+	case runtime.GOOS == "windows":
+		// No ulimit; only the timeout applies.
+		interpreter := t.opts.Python
+		if strings.HasSuffix(file, ".js") {
+			interpreter = t.opts.Node
+		}
+		cmd = exec.CommandContext(ctx, interpreter, file)
+		cmd.Env = append(os.Environ(), "MPLBACKEND=Agg", "PYTHONDONTWRITEBYTECODE=1")
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	prepareCommandForTermination(cmd)
+	out := &limitedBuffer{max: t.opts.MaxOutput}
+	cmd.Stdout, cmd.Stderr = out, out
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Sprintf("failed to start: %v", err), err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = terminateProcessTree(cmd)
+		err = <-done
+		if cleanup != nil {
+			cleanup()
+		}
+	}
+
+	output := out.String()
+	if out.dropped > 0 {
+		output += fmt.Sprintf("\n... (truncated, %d more chars)", out.dropped)
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		output += fmt.Sprintf("\nStopped after %s, the time limit.", t.opts.Timeout)
+		return strings.TrimLeft(output, "\n"), ctx.Err()
+	case err != nil:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ProcessState.String() == "signal: killed" {
+			output += "\nKilled, probably for going over the CPU time limit."
+		} else {
+			output += fmt.Sprintf("\nExit code: %v", err)
+		}
+		return strings.TrimLeft(output, "\n"), err
+	case output == "":
+		output = "(no output)"
+	}
+	return output, nil
+}
+
+// codeOutputFiles returns the files a run wrote to dir, other than its
+// code, leaving out those over maxSize.
+func codeOutputFiles(dir, code string, maxSize int64) []string {
+	var files []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == "__pycache__" || d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == filepath.Join(dir, code) || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() > 0 && info.Size() <= maxSize {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	if len(files) > maxCodeFiles {
+		files = files[:maxCodeFiles]
+	}
+	return files
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
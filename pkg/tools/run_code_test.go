@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRunCodeTool(t *testing.T, opts RunCodeToolOptions) *RunCodeTool {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the limits need sh")
+	}
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 isn't installed")
+	}
+	return NewRunCodeTool(t.TempDir(), opts)
+}
+
+func TestRunCodeTool_Python(t *testing.T) {
+	tool := newTestRunCodeTool(t, RunCodeToolOptions{})
+	var sent []string
+	tool.SetSendCallback(func(channel, chatID, caption string, paths []string) error {
+		sent = append(sent, paths...)
+		return nil
+	})
+	tool.SetContext("telegram", "42")
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"language": "python",
+		"code":     "print(6 * 7)\nopen('out.csv', 'w').write('a,b\\n1,2\\n')\n",
+	})
+	if result.IsError {
+		t.Fatalf("Execute() = %q", result.ForLLM)
+	}
+	if !strings.HasPrefix(result.ForLLM, "42\n") || !strings.Contains(result.ForLLM, "out.csv") ||
+		!strings.Contains(result.ForLLM, "sent to the user") {
+		t.Errorf("Execute() = %q, want the output and the file", result.ForLLM)
+	}
+	if len(sent) != 1 || filepath.Base(sent[0]) != "out.csv" {
+		t.Errorf("sent %v, want out.csv", sent)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"language": "python", "code": "1/0"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "ZeroDivisionError") {
+		t.Errorf("Execute() of failing code = %q, want the traceback", result.ForLLM)
+	}
+}
+
+func TestRunCodeTool_Limits(t *testing.T) {
+	tool := newTestRunCodeTool(t, RunCodeToolOptions{Timeout: time.Second, MemoryMB: 256})
+	ctx := context.Background()
+
+	start := time.Now()
+	result := tool.Execute(ctx, map[string]any{"language": "python", "code": "import time\ntime.sleep(30)"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "time limit") || time.Since(start) > 10*time.Second {
+		t.Errorf("Execute() of a sleeping snippet = %q after %s", result.ForLLM, time.Since(start))
+	}
+
+	result = tool.Execute(ctx, map[string]any{"language": "python", "code": "x = bytearray(1 << 30)"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "MemoryError") {
+		t.Errorf("Execute() over the memory limit = %q, want a MemoryError", result.ForLLM)
+	}
+
+	if result := tool.Execute(ctx, map[string]any{"language": "ruby", "code": "puts 1"}); !result.IsError {
+		t.Errorf("Execute() in another language = %q, want an error", result.ForLLM)
+	}
+}
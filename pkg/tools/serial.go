@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultSerialBaud = 115200
+	defaultSerialWait = time.Second
+	maxSerialWait     = 10 * time.Second
+	// serialQuiet ends a read early once a reply has come and the line
+	// has then been quiet this long.
+	serialQuiet = 300 * time.Millisecond
+	// maxSerialRead bounds the bytes a read returns.
+	maxSerialRead = 4096
+)
+
+// serialBauds are the baud rates a port can be set to.
+var serialBauds = []int{9600, 19200, 38400, 57600, 115200, 230400, 460800, 921600}
+
+// serialPort is an open serial port, in raw mode. Read returns no data
+// (0, nil or io.EOF) after a short silence rather than blocking.
+type serialPort interface {
+	io.ReadWriteCloser
+}
+
+// SerialTool talks to devices on the serial ports the user allowed, such as
+// a microcontroller or a modem: it sends a line and returns the reply.
+type SerialTool struct {
+	ports []string
+	baud  int
+	open  func(port string, baud int) (serialPort, error) // Replaced in tests
+}
+
+// NewSerialTool returns the tool of ports, opened at baud by default.
+func NewSerialTool(ports []string, baud int) *SerialTool {
+	if baud <= 0 {
+		baud = defaultSerialBaud
+	}
+	return &SerialTool{ports: ports, baud: baud, open: openSerial}
+}
+
+func (t *SerialTool) Name() string {
+	return "serial"
+}
+
+func (t *SerialTool) Description() string {
+	return fmt.Sprintf("Talk to devices on serial ports (UART), such as a microcontroller, a GPS or a modem. "+
+		"'send' writes data, as text or hex bytes, then returns what the device answers within wait_ms; 'read' "+
+		"only listens; 'list' shows the ports. Ports: %s. Default baud rate: %d.",
+		strings.Join(t.ports, ", "), t.baud)
+}
+
+func (t *SerialTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "send", "read"},
+				"description": "What to do",
+			},
+			"port": map[string]any{
+				"type":        "string",
+				"description": "The serial port, e.g. /dev/ttyS1; default: the first one allowed",
+			},
+			"data": map[string]any{
+				"type":        "string",
+				"description": "Text to send (send)",
+			},
+			"hex": map[string]any{
+				"type":        "string",
+				"description": "Bytes to send instead of text, in hex, e.g. 'AA 55 01' (send)",
+			},
+			"line_ending": map[string]any{
+				"type":        "string",
+				"enum":        []string{"none", "lf", "crlf", "cr"},
+				"description": "Appended to the text sent; default lf",
+			},
+			"baud": map[string]any{
+				"type":        "integer",
+				"enum":        serialBauds,
+				"description": "Baud rate, if not the default",
+			},
+			"wait_ms": map[string]any{
+				"type":        "integer",
+				"description": "How long to wait for data, in milliseconds; default 1000, at most 10000",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *SerialTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	if action == "list" {
+		return t.list()
+	}
+	if action != "send" && action != "read" {
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+
+	port, _ := args["port"].(string)
+	if port == "" && len(t.ports) > 0 {
+		port = t.ports[0]
+	}
+	if !slices.Contains(t.ports, port) {
+		return ErrorResult(fmt.Sprintf("serial port %q is not allowed; allowed ports: %s", port,
+			strings.Join(t.ports, ", ")))
+	}
+	baud := t.baud
+	if b, ok := args["baud"].(float64); ok {
+		baud = int(b)
+		if !slices.Contains(serialBauds, baud) {
+			return ErrorResult(fmt.Sprintf("unsupported baud rate %d", baud))
+		}
+	}
+	wait := defaultSerialWait
+	if ms, ok := args["wait_ms"].(float64); ok && ms >= 0 {
+		wait = min(time.Duration(ms)*time.Millisecond, maxSerialWait)
+	}
+
+	var data []byte
+	if action == "send" {
+		var err error
+		if data, err = serialData(args); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	p, err := t.open(port, baud)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't open %s: %v", port, err)).WithError(err)
+	}
+	defer p.Close()
+	if len(data) > 0 {
+		if _, err := p.Write(data); err != nil {
+			return ErrorResult(fmt.Sprintf("Can't write to %s: %v", port, err)).WithError(err)
+		}
+	}
+	reply, err := readSerial(ctx, p, wait)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't read %s: %v", port, err)).WithError(err)
+	}
+
+	var sb strings.Builder
+	if action == "send" {
+		fmt.Fprintf(&sb, "Sent %d bytes to %s. ", len(data), port)
+	}
+	if len(reply) == 0 {
+		fmt.Fprintf(&sb, "No data within %s.", wait)
+	} else {
+		fmt.Fprintf(&sb, "Received %d bytes:\n%s", len(reply), describeSerialData(reply))
+	}
+	return SilentResult(sb.String())
+}
+
+// serialData returns the bytes a send writes: hex, or data and its line
+// ending.
+func serialData(args map[string]any) ([]byte, error) {
+	if h, _ := args["hex"].(string); h != "" {
+		clean := strings.NewReplacer(" ", "", ":", "", "0x", "", ",", "").Replace(h)
+		data, err := hex.DecodeString(clean)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not hex bytes", h)
+		}
+		return data, nil
+	}
+	text, _ := args["data"].(string)
+	if text == "" {
+		return nil, errors.New("data or hex is required to send")
+	}
+	ending, _ := args["line_ending"].(string)
+	switch ending {
+	case "", "lf":
+		text += "\n"
+	case "crlf":
+		text += "\r\n"
+	case "cr":
+		text += "\r"
+	}
+	return []byte(text), nil
+}
+
+// readSerial reads what comes within wait, stopping early once data has
+// come and the line has been quiet for serialQuiet.
+func readSerial(ctx context.Context, p serialPort, wait time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(wait)
+	var data []byte
+	var lastData time.Time
+	buf := make([]byte, 512)
+	for time.Now().Before(deadline) && len(data) < maxSerialRead && ctx.Err() == nil {
+		n, err := p.Read(buf)
+		if n > 0 {
+			data = append(data, buf[:n]...)
+			lastData = time.Now()
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return data, err
+		}
+		if len(data) > 0 && time.Since(lastData) >= serialQuiet {
+			break
+		}
+	}
+	return data[:min(len(data), maxSerialRead)], nil
+}
+
+// describeSerialData returns data as text, or as hex when it isn't text.
+func describeSerialData(data []byte) string {
+	if utf8.Valid(data) && !strings.ContainsFunc(string(data), func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+	}) {
+		return string(data)
+	}
+	return "hex: " + hex.EncodeToString(data)
+}
+
+func (t *SerialTool) list() *ToolResult {
+	var sb strings.Builder
+	sb.WriteString("Serial ports:")
+	for _, port := range t.ports {
+		fmt.Fprintf(&sb, "\n- %s", port)
+		if _, err := os.Stat(port); err != nil {
+			sb.WriteString(" (not present)")
+		}
+	}
+	if len(t.ports) == 0 {
+		sb.WriteString(" none allowed")
+	}
+	return SilentResult(sb.String())
+}
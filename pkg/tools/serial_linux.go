@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// termiosCBAUD masks the baud rate bits of c_cflag (<asm-generic/termbits.h>).
+const termiosCBAUD = 0o10017
+
+var serialBaudFlags = map[int]uint32{
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+	230400: syscall.B230400,
+	460800: syscall.B460800,
+	921600: syscall.B921600,
+}
+
+// openSerial opens port in raw 8N1 mode at baud. Reads return after
+// 100 ms without data.
+func openSerial(port string, baud int) (serialPort, error) {
+	speed, ok := serialBaudFlags[baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baud)
+	}
+	// O_NONBLOCK keeps the open from waiting for the modem's carrier.
+	fd, err := syscall.Open(port, syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	var tio syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCGETS,
+		uintptr(unsafe.Pointer(&tio))); errno != 0 {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("not a serial port: %w", errno)
+	}
+	tio.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR |
+		syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF
+	tio.Oflag &^= syscall.OPOST
+	tio.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	tio.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.CSTOPB | termiosCBAUD
+	tio.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL | speed
+	tio.Cc[syscall.VMIN] = 0
+	tio.Cc[syscall.VTIME] = 1 // Tenths of a second
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCSETS,
+		uintptr(unsafe.Pointer(&tio))); errno != 0 {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("configure the port: %w", errno)
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), port), nil
+}
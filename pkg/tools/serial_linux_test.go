@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// openPTY returns the master of a new pseudo-terminal and the path of its
+// end, which stands in for a serial port.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminals: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK,
+		uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skipf("unlock the pseudo-terminal: %v", errno)
+	}
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN,
+		uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Skipf("number of the pseudo-terminal: %v", errno)
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func TestSerialTool_SendOverPTY(t *testing.T) {
+	master, port := openPTY(t)
+	tool := NewSerialTool([]string{port}, 0)
+
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := master.Read(buf)
+		if string(buf[:n]) == "AT\r\n" {
+			master.Write([]byte("OK\r\n"))
+		}
+	}()
+	result := tool.Execute(context.Background(), map[string]any{
+		"action":      "send",
+		"data":        "AT",
+		"line_ending": "crlf",
+		"wait_ms":     2000.0,
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "Received 4 bytes:\nOK") {
+		t.Errorf("send = %+v", result)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package tools
+
+import "errors"
+
+// openSerial is a stub for non-Linux platforms.
+func openSerial(port string, baud int) (serialPort, error) {
+	return nil, errors.New("serial ports are only supported on Linux")
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSerialPort records what is written and answers with reply.
+type fakeSerialPort struct {
+	written bytes.Buffer
+	reply   *bytes.Reader
+}
+
+func (p *fakeSerialPort) Read(b []byte) (int, error) { return p.reply.Read(b) }
+
+func (p *fakeSerialPort) Write(b []byte) (int, error) { return p.written.Write(b) }
+
+func (p *fakeSerialPort) Close() error { return nil }
+
+func TestSerialTool(t *testing.T) {
+	tool := NewSerialTool([]string{"/dev/ttyS1"}, 9600)
+	port := &fakeSerialPort{reply: bytes.NewReader([]byte{0xaa, 0x01, 0x00})}
+	var openedAt int
+	tool.open = func(name string, baud int) (serialPort, error) {
+		openedAt = baud
+		return port, nil
+	}
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "send", "hex": "AA 55 01", "wait_ms": 100.0})
+	if result.IsError || !strings.Contains(result.ForLLM, "hex: aa0100") {
+		t.Errorf("send = %+v", result)
+	}
+	if port.written.String() != "\xaa\x55\x01" || openedAt != 9600 {
+		t.Errorf("wrote %q at %d baud", port.written.String(), openedAt)
+	}
+
+	port.written.Reset()
+	port.reply = bytes.NewReader(nil)
+	result = tool.Execute(ctx, map[string]any{"action": "send", "data": "status", "baud": 115200.0, "wait_ms": 50.0})
+	if port.written.String() != "status\n" || openedAt != 115200 || !strings.Contains(result.ForLLM, "No data") {
+		t.Errorf("send = %+v, wrote %q at %d baud", result, port.written.String(), openedAt)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "read", "port": "/dev/ttyUSB0"},
+		{"action": "send", "data": "x", "baud": 1234.0},
+		{"action": "send", "hex": "zz"},
+		{"action": "send"},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v succeeded", args)
+		}
+	}
+}
+
+func TestReadSerial_StopsWhenQuiet(t *testing.T) {
+	start := time.Now()
+	data, err := readSerial(context.Background(), &fakeSerialPort{reply: bytes.NewReader([]byte("hello"))}, 5*time.Second)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("readSerial() = %q, %v", data, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("readSerial() waited %s after the reply", elapsed)
+	}
+}
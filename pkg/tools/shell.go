@@ -19,11 +19,18 @@ import (
 type ExecTool struct {
 	workingDir          string
 	timeout             time.Duration
+	maxOutput           int
 	denyPatterns        []*regexp.Regexp
 	allowPatterns       []*regexp.Regexp
 	restrictToWorkspace bool
+	sandbox             config.ExecSandboxConfig
 }
 
+const (
+	defaultExecTimeout   = 60 * time.Second
+	defaultExecMaxOutput = 10000
+)
+
 var defaultDenyPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`\brm\s+-[rf]{1,2}\b`),
 	regexp.MustCompile(`\bdel\s+/[fq]\b`),
@@ -69,12 +76,38 @@ var defaultDenyPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`\bsource\s+.*\.sh\b`),
 }
 
+// shellChaining matches what makes a shell run more than the command an
+// allow pattern matched: ;, &, |, newlines, and command or process
+// substitution.
+var shellChaining = regexp.MustCompile("[;&|\n\r`]|\\$\\(|[<>]\\(")
+
+// fdRedirect matches redirections between file descriptors, such as 2>&1,
+// whose & chains nothing.
+var fdRedirect = regexp.MustCompile(`[0-9]*[<>]&[0-9-]`)
+
+// chainsCommands reports whether command may run other commands than its
+// first, which an allowlist can't vouch for.
+func chainsCommands(command string) bool {
+	return shellChaining.MatchString(fdRedirect.ReplaceAllString(command, ""))
+}
+
+// compileAllowPattern compiles an allow pattern to match whole commands.
+func compileAllowPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
 func NewExecTool(workingDir string, restrict bool) *ExecTool {
 	return NewExecToolWithConfig(workingDir, restrict, nil)
 }
 
 func NewExecToolWithConfig(workingDir string, restrict bool, config *config.Config) *ExecTool {
 	denyPatterns := make([]*regexp.Regexp, 0)
+	tool := &ExecTool{
+		workingDir:          workingDir,
+		timeout:             defaultExecTimeout,
+		maxOutput:           defaultExecMaxOutput,
+		restrictToWorkspace: restrict,
+	}
 
 	if config != nil {
 		execConfig := config.Tools.Exec
@@ -84,7 +117,8 @@ func NewExecToolWithConfig(workingDir string, restrict bool, config *config.Conf
 			if len(execConfig.CustomDenyPatterns) > 0 {
 				fmt.Printf("Using custom deny patterns: %v\n", execConfig.CustomDenyPatterns)
 				for _, pattern := range execConfig.CustomDenyPatterns {
-					re, err := regexp.Compile(pattern)
+					// Commands are matched lowercased, so the pattern must ignore case too.
+					re, err := regexp.Compile(`(?i)` + pattern)
 					if err != nil {
 						fmt.Printf("Invalid custom deny pattern %q: %v\n", pattern, err)
 						continue
@@ -96,17 +130,30 @@ func NewExecToolWithConfig(workingDir string, restrict bool, config *config.Conf
 			// If deny patterns are disabled, we won't add any patterns, allowing all commands.
 			fmt.Println("Warning: deny patterns are disabled. All commands will be allowed.")
 		}
+		for _, pattern := range execConfig.AllowPatterns {
+			re, err := compileAllowPattern(pattern)
+			if err != nil {
+				fmt.Printf("Invalid allow pattern %q: %v\n", pattern, err)
+				continue
+			}
+			tool.allowPatterns = append(tool.allowPatterns, re)
+		}
+		if len(execConfig.AllowPatterns) > 0 && len(tool.allowPatterns) == 0 {
+			// Allow nothing rather than everything.
+			tool.allowPatterns = []*regexp.Regexp{regexp.MustCompile(`[^\s\S]`)}
+		}
+		if execConfig.TimeoutSeconds > 0 {
+			tool.timeout = time.Duration(execConfig.TimeoutSeconds) * time.Second
+		}
+		if execConfig.MaxOutputChars > 0 {
+			tool.maxOutput = execConfig.MaxOutputChars
+		}
+		tool.sandbox = execConfig.Sandbox
 	} else {
 		denyPatterns = append(denyPatterns, defaultDenyPatterns...)
 	}
-
-	return &ExecTool{
-		workingDir:          workingDir,
-		timeout:             60 * time.Second,
-		denyPatterns:        denyPatterns,
-		allowPatterns:       nil,
-		restrictToWorkspace: restrict,
-	}
+	tool.denyPatterns = denyPatterns
+	return tool
 }
 
 func (t *ExecTool) Name() string {
@@ -142,7 +189,8 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 
 	cwd := t.workingDir
 	if wd, ok := args["working_dir"].(string); ok && wd != "" {
-		if t.restrictToWorkspace && t.workingDir != "" {
+		// A sandboxed command can only work in the workspace it is given.
+		if (t.restrictToWorkspace || t.sandbox.Mode != "") && t.workingDir != "" {
 			resolvedWD, err := validatePath(wd, t.workingDir, true)
 			if err != nil {
 				return ErrorResult("Command blocked by safety guard (" + err.Error() + ")")
@@ -175,9 +223,21 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	defer cancel()
 
 	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
+	var cleanup func()
+	switch {
+	case t.sandbox.Mode != "":
+		jail := t.workingDir
+		if jail == "" {
+			jail = cwd
+		}
+		argv, undo, err := sandboxCommand(t.sandbox, command, cwd, jail)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		cmd, cleanup = exec.CommandContext(cmdCtx, argv[0], argv[1:]...), undo
+	case runtime.GOOS == "windows":
 		cmd = exec.CommandContext(cmdCtx, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
-	} else {
+	default:
 		cmd = exec.CommandContext(cmdCtx, "sh", "-c", command)
 	}
 	if cwd != "" {
@@ -186,9 +246,10 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 
 	prepareCommandForTermination(cmd)
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stdout := &limitedBuffer{max: t.maxOutput}
+	stderr := &limitedBuffer{max: t.maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	if err := cmd.Start(); err != nil {
 		return ErrorResult(fmt.Sprintf("failed to start command: %v", err))
@@ -212,14 +273,26 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 			}
 			err = <-done
 		}
+		if cleanup != nil {
+			cleanup()
+		}
 	}
 
 	output := stdout.String()
 	if stderr.Len() > 0 {
 		output += "\nSTDERR:\n" + stderr.String()
 	}
+	dropped := stdout.dropped + stderr.dropped
 
 	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			msg := "Command cancelled"
+			return &ToolResult{
+				ForLLM:  msg,
+				ForUser: msg,
+				IsError: true,
+			}
+		}
 		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
 			msg := fmt.Sprintf("Command timed out after %v", t.timeout)
 			return &ToolResult{
@@ -235,9 +308,9 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		output = "(no output)"
 	}
 
-	maxLen := 10000
-	if len(output) > maxLen {
-		output = output[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxLen)
+	if len(output) > t.maxOutput || dropped > 0 {
+		cut := min(len(output), t.maxOutput)
+		output = output[:cut] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-cut+dropped)
 	}
 
 	if err != nil {
@@ -266,9 +339,12 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 	}
 
 	if len(t.allowPatterns) > 0 {
+		if chainsCommands(cmd) {
+			return "Command blocked by safety guard (chaining or substitution isn't allowed with an allowlist)"
+		}
 		allowed := false
 		for _, pattern := range t.allowPatterns {
-			if pattern.MatchString(lower) {
+			if pattern.MatchString(cmd) {
 				allowed = true
 				break
 			}
@@ -311,6 +387,21 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 	return ""
 }
 
+// limitedBuffer keeps the first max bytes written to it and counts the
+// rest, so a chatty command can't fill the memory.
+type limitedBuffer struct {
+	bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	keep := min(len(p), max(b.max-b.Len(), 0))
+	b.Buffer.Write(p[:keep])
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
 func (t *ExecTool) SetTimeout(timeout time.Duration) {
 	t.timeout = timeout
 }
@@ -322,7 +413,7 @@ func (t *ExecTool) SetRestrictToWorkspace(restrict bool) {
 func (t *ExecTool) SetAllowPatterns(patterns []string) error {
 	t.allowPatterns = make([]*regexp.Regexp, 0, len(patterns))
 	for _, p := range patterns {
-		re, err := regexp.Compile(p)
+		re, err := compileAllowPattern(p)
 		if err != nil {
 			return fmt.Errorf("invalid allow pattern %q: %w", p, err)
 		}
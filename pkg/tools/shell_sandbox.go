@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const defaultSandboxImage = "alpine:3"
+
+// sandboxCommand returns the command line that runs command in cwd inside
+// the sandbox, with jail the only writable directory, and for Docker a
+// function that removes the container when the command is killed. Without
+// the sandbox program installed the command doesn't run at all.
+func sandboxCommand(sandbox config.ExecSandboxConfig, command, cwd, jail string) ([]string, func(), error) {
+	if runtime.GOOS == "windows" {
+		return nil, nil, fmt.Errorf("the %s sandbox isn't available on Windows", sandbox.Mode)
+	}
+	switch sandbox.Mode {
+	case config.SandboxBwrap, config.SandboxFirejail, config.SandboxDocker:
+	default:
+		return nil, nil, fmt.Errorf("unknown sandbox %q", sandbox.Mode)
+	}
+	if _, err := exec.LookPath(sandbox.Mode); err != nil {
+		return nil, nil, fmt.Errorf("command not run: the %s sandbox isn't installed", sandbox.Mode)
+	}
+
+	var cleanup func()
+	name := "picoclaw-exec-" + uuid.NewString()[:8]
+	if sandbox.Mode == config.SandboxDocker {
+		cleanup = func() { _ = exec.Command("docker", "rm", "-f", name).Run() }
+	}
+	return sandboxArgs(sandbox, command, cwd, jail, name), cleanup, nil
+}
+
+// sandboxArgs builds the command line for sandboxCommand. name names the
+// Docker container.
+func sandboxArgs(sandbox config.ExecSandboxConfig, command, cwd, jail, name string) []string {
+	var argv []string
+	switch sandbox.Mode {
+	case config.SandboxBwrap:
+		argv = []string{
+			"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp",
+			"--bind", jail, jail, "--chdir", cwd,
+			"--unshare-all", "--die-with-parent", "--new-session",
+		}
+		if sandbox.Network {
+			argv = append(argv, "--share-net")
+		}
+		argv = append(argv, "--")
+	case config.SandboxFirejail:
+		argv = []string{
+			"firejail", "--quiet", "--noprofile", "--read-only=/", "--read-write=" + jail,
+			"--private-dev", "--nonewprivs", "--caps.drop=all", "--seccomp",
+		}
+		if !sandbox.Network {
+			argv = append(argv, "--net=none")
+		}
+		argv = append(argv, "--")
+	case config.SandboxDocker:
+		argv = []string{
+			"docker", "run", "--rm", "--name", name,
+			"--mount", "type=bind,source=" + jail + ",target=" + jail, "--workdir", cwd,
+			"--cap-drop", "ALL", "--security-opt", "no-new-privileges", "--pids-limit", "256",
+		}
+		if !sandbox.Network {
+			argv = append(argv, "--network", "none")
+		}
+		if sandbox.MemoryMB > 0 {
+			argv = append(argv, "--memory", strconv.Itoa(sandbox.MemoryMB)+"m")
+		}
+		// Files the command writes stay ours.
+		if uid := os.Getuid(); uid >= 0 {
+			argv = append(argv, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+		}
+		image := sandbox.Image
+		if image == "" {
+			image = defaultSandboxImage
+		}
+		argv = append(argv, image)
+	}
+	return append(argv, "sh", "-c", command)
+}
@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	defaultSSHTimeout   = 30 * time.Second
+	sshConnectTimeout   = 10 // Seconds
+	sshExitConnectError = 255
+)
+
+// sshHost is a machine the ssh tool may run commands on, with its command
+// policy.
+type sshHost struct {
+	cfg   config.SSHHostConfig
+	host  string
+	port  string
+	allow []*regexp.Regexp // Match the whole command; any command when empty
+	deny  []*regexp.Regexp
+}
+
+// SSHTool runs commands on the configured machines with the system's ssh
+// client, logging in with keys only, to hosts already known.
+type SSHTool struct {
+	hosts      []*sshHost
+	keyFile    string
+	knownHosts string
+	timeout    time.Duration
+	maxOutput  int
+
+	// sshPath is the ssh client run; replaced in tests.
+	sshPath string
+}
+
+func NewSSHTool(cfg config.SSHToolConfig) *SSHTool {
+	t := &SSHTool{
+		keyFile:    cfg.KeyFile,
+		knownHosts: cfg.KnownHostsFile,
+		timeout:    time.Duration(cfg.TimeoutSeconds) * time.Second,
+		maxOutput:  cfg.MaxOutputChars,
+		sshPath:    "ssh",
+	}
+	if t.timeout <= 0 {
+		t.timeout = defaultSSHTimeout
+	}
+	if t.maxOutput <= 0 {
+		t.maxOutput = defaultExecMaxOutput
+	}
+	for _, hc := range cfg.Hosts {
+		h := &sshHost{cfg: hc, host: hc.Address}
+		if host, port, err := net.SplitHostPort(hc.Address); err == nil {
+			h.host, h.port = host, port
+		}
+		for _, pattern := range hc.AllowCommands {
+			// An invalid pattern allows nothing rather than everything.
+			re, err := compileAllowPattern(pattern)
+			if err != nil {
+				re = regexp.MustCompile(`[^\s\S]`)
+			}
+			h.allow = append(h.allow, re)
+		}
+		for _, pattern := range hc.DenyCommands {
+			// Case-insensitive, as the exec tool's deny patterns.
+			re, err := regexp.Compile(`(?i)` + pattern)
+			if err != nil {
+				re = regexp.MustCompile(`[\s\S]`)
+			}
+			h.deny = append(h.deny, re)
+		}
+		t.hosts = append(t.hosts, h)
+	}
+	return t
+}
+
+func (t *SSHTool) Name() string {
+	return "ssh"
+}
+
+func (t *SSHTool) Description() string {
+	hosts := make([]string, len(t.hosts))
+	for i, h := range t.hosts {
+		hosts[i] = fmt.Sprintf("%s (%s@%s)", h.cfg.Name, h.cfg.User, h.cfg.Address)
+		if len(h.cfg.AllowCommands) > 0 {
+			hosts[i] += " allowing only commands matching: " + strings.Join(h.cfg.AllowCommands, " | ")
+		}
+	}
+	return "Run a shell command on another machine of the user's network over SSH, e.g. to check its disks, " +
+		"services or logs. Prefer read-only diagnostics. Hosts: " + strings.Join(hosts, "; ") + "."
+}
+
+func (t *SSHTool) Parameters() map[string]any {
+	names := make([]string, len(t.hosts))
+	for i, h := range t.hosts {
+		names[i] = h.cfg.Name
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"host": map[string]any{
+				"type":        "string",
+				"enum":        names,
+				"description": "The machine to run the command on",
+			},
+			"command": map[string]any{
+				"type":        "string",
+				"description": "The command, run by the remote user's shell",
+			},
+		},
+		"required": []string{"host", "command"},
+	}
+}
+
+func (t *SSHTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	name, _ := args["host"].(string)
+	command, _ := args["command"].(string)
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return ErrorResult("command is required")
+	}
+	host := t.host(name)
+	if host == nil {
+		return ErrorResult(fmt.Sprintf("unknown host %q", name))
+	}
+	if reason := host.guard(command); reason != "" {
+		return ErrorResult(fmt.Sprintf("Command blocked on %s: %s", host.cfg.Name, reason))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, t.sshPath, t.args(host, command)...)
+	stdout := &limitedBuffer{max: t.maxOutput}
+	stderr := &limitedBuffer{max: t.maxOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = 2 * time.Second
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorResult(fmt.Sprintf("Command on %s timed out after %v", host.cfg.Name, t.timeout))
+	case ctx.Err() != nil:
+		return ErrorResult("Command cancelled")
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == sshExitConnectError:
+		return ErrorResult(fmt.Sprintf("Can't log in to %s: %s", host.cfg.Name,
+			strings.TrimSpace(stderr.String()))).WithError(err)
+	case err != nil && exitErr == nil:
+		return ErrorResult(fmt.Sprintf("Can't run ssh: %v", err)).WithError(err)
+	}
+
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\nSTDERR:\n" + stderr.String()
+	}
+	if exitErr != nil {
+		output += fmt.Sprintf("\nExit code: %d", exitErr.ExitCode())
+	}
+	if output == "" {
+		output = "(no output)"
+	}
+	if dropped := stdout.dropped + stderr.dropped; len(output) > t.maxOutput || dropped > 0 {
+		cut := min(len(output), t.maxOutput)
+		output = output[:cut] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-cut+dropped)
+	}
+	if exitErr != nil {
+		return &ToolResult{ForLLM: output, ForUser: output, IsError: true}
+	}
+	return &ToolResult{ForLLM: output, ForUser: output}
+}
+
+// host returns the host named name, or nil.
+func (t *SSHTool) host(name string) *sshHost {
+	for _, h := range t.hosts {
+		if strings.EqualFold(h.cfg.Name, strings.TrimSpace(name)) {
+			return h
+		}
+	}
+	return nil
+}
+
+// guard returns why the host's policy refuses command, or "".
+func (h *sshHost) guard(command string) string {
+	for _, re := range h.deny {
+		if re.MatchString(command) {
+			return "it matches a denied pattern"
+		}
+	}
+	if len(h.allow) == 0 {
+		return ""
+	}
+	// The remote shell runs the whole command, of which a pattern only
+	// vouches for the first.
+	if chainsCommands(command) {
+		return "commands can't be chained or substituted (;, &, |, `, $( or newlines) on this host"
+	}
+	for _, re := range h.allow {
+		if re.MatchString(command) {
+			return ""
+		}
+	}
+	return "only commands matching " + strings.Join(h.cfg.AllowCommands, " | ") + " may run"
+}
+
+// args returns the ssh arguments running command on host, without a
+// terminal.
+func (t *SSHTool) args(h *sshHost, command string) []string {
+	args := append([]string{"-T"}, t.options(h)...)
+	if h.port != "" {
+		args = append(args, "-p", h.port)
+	}
+	if h.cfg.User != "" {
+		args = append(args, "-l", h.cfg.User)
+	}
+	return append(args, "--", h.host, command)
+}
+
+// options returns the options ssh, scp and rsync's ssh connect to host
+// with: no password or keyboard prompts, no unknown host keys.
+func (t *SSHTool) options(h *sshHost) []string {
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "PasswordAuthentication=no",
+		"-o", "KbdInteractiveAuthentication=no",
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", sshConnectTimeout),
+		"-o", "LogLevel=ERROR",
+	}
+	if t.knownHosts != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+t.knownHosts)
+	}
+	keyFile := h.cfg.KeyFile
+	if keyFile == "" {
+		keyFile = t.keyFile
+	}
+	if keyFile != "" {
+		args = append(args, "-i", keyFile, "-o", "IdentitiesOnly=yes")
+	}
+	return args
+}
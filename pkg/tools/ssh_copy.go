@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// sshTransferTimeout bounds a copy, which takes longer than a command.
+const sshTransferTimeout = 30 * time.Minute
+
+// remotePathUnsafe are characters the remote shell of older scp and rsync
+// could interpret in a path.
+const remotePathUnsafe = "`$;&|<>*?()[]{}\\'\"!~\n\r\t"
+
+// CopyToHostToolOptions configures a CopyToHostTool.
+type CopyToHostToolOptions struct {
+	Workspace  string
+	Restrict   bool
+	AllowPaths []string // Outside the workspace, as for the file tools
+}
+
+// CopyToHostTool copies local files and directories to the transfer
+// directories of the ssh tool's hosts, with rsync when it is installed and
+// scp otherwise, over the same key-only connections.
+type CopyToHostTool struct {
+	ssh   *SSHTool
+	opts  CopyToHostToolOptions
+	hosts []*sshHost // Those with transfer directories
+
+	// scpPath and rsyncPath are the programs run; replaced in tests.
+	scpPath   string
+	rsyncPath string
+}
+
+func NewCopyToHostTool(cfg config.SSHToolConfig, opts CopyToHostToolOptions) *CopyToHostTool {
+	t := &CopyToHostTool{ssh: NewSSHTool(cfg), opts: opts, scpPath: "scp"}
+	if rsync, err := exec.LookPath("rsync"); err == nil {
+		t.rsyncPath = rsync
+	}
+	for _, h := range t.ssh.hosts {
+		if len(h.cfg.TransferDirs) > 0 {
+			t.hosts = append(t.hosts, h)
+		}
+	}
+	return t
+}
+
+func (t *CopyToHostTool) Name() string {
+	return "copy_to_host"
+}
+
+func (t *CopyToHostTool) Description() string {
+	hosts := make([]string, len(t.hosts))
+	for i, h := range t.hosts {
+		hosts[i] = fmt.Sprintf("%s (into %s)", h.cfg.Name, strings.Join(h.cfg.TransferDirs, ", "))
+	}
+	return "Copy a local file or directory to another machine of the user's network over SSH, e.g. a " +
+		"downloaded release or a built program. Hosts: " + strings.Join(hosts, "; ") + "."
+}
+
+func (t *CopyToHostTool) Parameters() map[string]any {
+	names := make([]string, len(t.hosts))
+	for i, h := range t.hosts {
+		names[i] = h.cfg.Name
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"host": map[string]any{
+				"type":        "string",
+				"enum":        names,
+				"description": "The machine to copy to",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The local file or directory",
+			},
+			"remote_path": map[string]any{
+				"type":        "string",
+				"description": "An absolute path on the host, in its transfer directories; end it with / to copy into a directory",
+			},
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        []string{"rsync", "scp"},
+				"description": "How to copy (default rsync when installed, which only sends what changed)",
+			},
+		},
+		"required": []string{"host", "path", "remote_path"},
+	}
+}
+
+func (t *CopyToHostTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	name, _ := args["host"].(string)
+	host := t.ssh.host(name)
+	if host == nil || len(host.cfg.TransferDirs) == 0 {
+		return ErrorResult(fmt.Sprintf("unknown host %q, or no files may be copied to it", name))
+	}
+	localArg, _ := args["path"].(string)
+	if localArg == "" {
+		return ErrorResult("path is required")
+	}
+	local, err := resolveAllowedPath(expandHomeDir(localArg), t.opts.Workspace, t.opts.Restrict, t.opts.AllowPaths)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	remote, _ := args["remote_path"].(string)
+	if reason := checkRemotePath(remote, host.cfg.TransferDirs); reason != "" {
+		return ErrorResult(fmt.Sprintf("Can't copy to %s on %s: %s", remote, host.cfg.Name, reason))
+	}
+
+	method, _ := args["method"].(string)
+	switch {
+	case method == "":
+		method = "scp"
+		if t.rsyncPath != "" {
+			method = "rsync"
+		}
+	case method == "rsync" && t.rsyncPath == "":
+		return ErrorResult("rsync is not installed here; use scp")
+	case method != "rsync" && method != "scp":
+		return ErrorResult(fmt.Sprintf("unknown method %q", method))
+	}
+
+	target := host.host
+	if strings.Contains(target, ":") {
+		target = "[" + target + "]" // An IPv6 address
+	}
+	if host.cfg.User != "" {
+		target = host.cfg.User + "@" + target
+	}
+	target += ":" + remote
+
+	var cmd *exec.Cmd
+	ctx, cancel := context.WithTimeout(ctx, sshTransferTimeout)
+	defer cancel()
+	if method == "rsync" {
+		shell := append([]string{t.ssh.sshPath}, t.ssh.options(host)...)
+		if host.port != "" {
+			shell = append(shell, "-p", host.port)
+		}
+		// --protect-args keeps the remote shell from reading the path.
+		cmd = exec.CommandContext(ctx, t.rsyncPath, "-a", "--partial", "--protect-args", "--stats",
+			"-e", rsyncShell(shell), "--", local, target)
+	} else {
+		scpArgs := append([]string{"-q", "-p"}, t.ssh.options(host)...)
+		if host.port != "" {
+			scpArgs = append(scpArgs, "-P", host.port)
+		}
+		if info.IsDir() {
+			scpArgs = append(scpArgs, "-r")
+		}
+		cmd = exec.CommandContext(ctx, t.scpPath, append(scpArgs, "--", local, target)...)
+	}
+	output := &limitedBuffer{max: defaultExecMaxOutput}
+	cmd.Stdout, cmd.Stderr = output, output
+	cmd.WaitDelay = 2 * time.Second
+	err = cmd.Run()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorResult(fmt.Sprintf("Copying to %s timed out after %v", host.cfg.Name, sshTransferTimeout))
+	case ctx.Err() != nil:
+		return ErrorResult("Copy cancelled")
+	case err != nil:
+		return ErrorResult(fmt.Sprintf("Copying %s to %s failed: %v\n%s", local, host.cfg.Name, err,
+			strings.TrimSpace(output.String()))).WithError(err)
+	}
+	msg := fmt.Sprintf("Copied %s to %s:%s with %s", local, host.cfg.Name, remote, method)
+	if !info.IsDir() {
+		msg += fmt.Sprintf(" (%s)", formatBytes(uint64(info.Size())))
+	}
+	if stats := strings.TrimSpace(output.String()); method == "rsync" && stats != "" {
+		msg += "\n" + stats
+	}
+	return SilentResult(msg)
+}
+
+// checkRemotePath returns why remote can't be copied to, or "": it must be
+// an absolute path in one of dirs, without characters a remote shell would
+// read.
+func checkRemotePath(remote string, dirs []string) string {
+	if remote == "" || !path.IsAbs(remote) {
+		return "remote_path must be an absolute path"
+	}
+	if strings.ContainsAny(remote, remotePathUnsafe) {
+		return "the path has characters that aren't allowed"
+	}
+	clean := path.Clean(remote)
+	for _, dir := range dirs {
+		dir = path.Clean(dir)
+		if clean == dir || strings.HasPrefix(clean, strings.TrimSuffix(dir, "/")+"/") {
+			return ""
+		}
+	}
+	return "files may only be copied into " + strings.Join(dirs, ", ")
+}
+
+// rsyncShell joins the ssh command rsync runs, quoting arguments with
+// spaces as rsync's -e expects.
+func rsyncShell(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t") {
+			a = "'" + a + "'"
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
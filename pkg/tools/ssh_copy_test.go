@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeCopier writes a script standing in for scp or rsync that prints its
+// arguments and saves them beside it, in name.args.
+func fakeCopier(t *testing.T, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\" | tee \"$0.args\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestCopyTool(t *testing.T) (*CopyToHostTool, string) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "app.tar.gz"), []byte("release"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(workspace, "site"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewCopyToHostTool(config.SSHToolConfig{
+		KeyFile: "/keys/agent",
+		Hosts: []config.SSHHostConfig{
+			{Name: "nas", Address: "192.168.1.10:2222", User: "admin", TransferDirs: []string{"/srv/incoming"}},
+			{Name: "pi", Address: "pi.lan", User: "pi"},
+		},
+	}, CopyToHostToolOptions{Workspace: workspace, Restrict: true})
+	tool.ssh.sshPath = "ssh"
+	tool.scpPath = fakeCopier(t, "scp")
+	tool.rsyncPath = fakeCopier(t, "rsync")
+	return tool, workspace
+}
+
+func TestCopyToHostTool_OnlyHostsWithTransferDirs(t *testing.T) {
+	tool, _ := newTestCopyTool(t)
+	if len(tool.hosts) != 1 || tool.hosts[0].cfg.Name != "nas" {
+		t.Fatalf("hosts = %v", tool.hosts)
+	}
+	result := tool.Execute(context.Background(), map[string]any{
+		"host": "pi", "path": "app.tar.gz", "remote_path": "/home/pi/",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no files may be copied") {
+		t.Errorf("pi: %q", result.ForLLM)
+	}
+}
+
+func TestCopyToHostTool_Methods(t *testing.T) {
+	tool, workspace := newTestCopyTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{
+		"host": "nas", "path": "app.tar.gz", "remote_path": "/srv/incoming/",
+	})
+	if result.IsError {
+		t.Fatalf("rsync: %s", result.ForLLM)
+	}
+	for _, want := range []string{"with rsync", "-a --partial --protect-args", "-p 2222",
+		"-i /keys/agent", filepath.Join(workspace, "app.tar.gz") + " admin@192.168.1.10:/srv/incoming/"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("rsync result lacks %q: %s", want, result.ForLLM)
+		}
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"host": "nas", "path": "site", "remote_path": "/srv/incoming/www", "method": "scp",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "with scp") {
+		t.Fatalf("scp: %s", result.ForLLM)
+	}
+	scpArgs, err := os.ReadFile(tool.scpPath + ".args")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-i /keys/agent", "-P 2222 -r --", "admin@192.168.1.10:/srv/incoming/www"} {
+		if !strings.Contains(string(scpArgs), want) {
+			t.Errorf("scp args lack %q: %s", want, scpArgs)
+		}
+	}
+
+	tool.rsyncPath = ""
+	result = tool.Execute(ctx, map[string]any{
+		"host": "nas", "path": "app.tar.gz", "remote_path": "/srv/incoming/",
+	})
+	if result.IsError || !strings.Contains(result.ForLLM, "with scp") {
+		t.Errorf("without rsync: %q", result.ForLLM)
+	}
+}
+
+func TestCopyToHostTool_Refusals(t *testing.T) {
+	tool, _ := newTestCopyTool(t)
+	tests := []struct {
+		path, remote, want string
+	}{
+		{"app.tar.gz", "/etc/", "may only be copied into /srv/incoming"},
+		{"app.tar.gz", "/srv/incoming/../../etc", "may only be copied into"},
+		{"app.tar.gz", "/srv/incoming-other/x", "may only be copied into"},
+		{"app.tar.gz", "incoming/", "must be an absolute path"},
+		{"app.tar.gz", "/srv/incoming/$(reboot)", "characters that aren't allowed"},
+		{"/etc/passwd", "/srv/incoming/", "outside"},
+		{"missing.zip", "/srv/incoming/", "no such file"},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(context.Background(), map[string]any{
+			"host": "nas", "path": tt.path, "remote_path": tt.remote,
+		})
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("%s -> %s: %q, want %q", tt.path, tt.remote, result.ForLLM, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeSSH writes a script standing in for ssh: it prints its arguments,
+// and fails as ssh does for the host "down".
+func fakeSSH(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "ssh")
+	script := `#!/bin/sh
+for last; do :; done
+case "$*" in
+*" -- down "*) echo "ssh: connect to host down port 22: Connection refused" >&2; exit 255 ;;
+esac
+case "$last" in
+fail) echo "no such unit" >&2; exit 3 ;;
+big) i=0; while [ $i -lt 100 ]; do echo 0123456789; i=$((i+1)); done; exit 0 ;;
+esac
+echo "$@"
+`
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestSSHTool(t *testing.T) *SSHTool {
+	tool := NewSSHTool(config.SSHToolConfig{
+		KeyFile:        "/keys/agent",
+		MaxOutputChars: 400,
+		Hosts: []config.SSHHostConfig{
+			{Name: "nas", Address: "192.168.1.10:2222", User: "admin",
+				AllowCommands: []string{`df .*`, "df", `systemctl status \w+`, "fail", "big"}},
+			{Name: "pi", Address: "pi.lan", User: "pi", KeyFile: "/keys/pi", DenyCommands: []string{`\breboot\b`}},
+			{Name: "down", Address: "down", User: "x"},
+		},
+	})
+	tool.sshPath = fakeSSH(t)
+	return tool
+}
+
+func TestSSHTool_RunsWithKeyOnlyLogin(t *testing.T) {
+	tool := newTestSSHTool(t)
+	result := tool.Execute(context.Background(), map[string]any{"host": "nas", "command": "df -h"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	for _, want := range []string{"BatchMode=yes", "StrictHostKeyChecking=yes", "-i /keys/agent",
+		"-p 2222", "-l admin", "-- 192.168.1.10 df -h"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("ssh args lack %q: %s", want, result.ForLLM)
+		}
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"host": "pi", "command": "uptime"})
+	if !strings.Contains(result.ForLLM, "-i /keys/pi") || strings.Contains(result.ForLLM, "-p ") {
+		t.Errorf("pi args = %s", result.ForLLM)
+	}
+}
+
+func TestSSHTool_CommandPolicy(t *testing.T) {
+	tool := newTestSSHTool(t)
+	tests := []struct {
+		host, command string
+		blocked       bool
+	}{
+		{"nas", "df", false},
+		{"nas", "systemctl status nginx", false},
+		{"nas", "df -h /mnt", false},
+		{"nas", "df -h; rm -rf /", true},
+		{"nas", "df -h && rm -rf ~", true},
+		{"nas", "df -h | mail me", true},
+		{"nas", "df $(rm -rf ~)", true},
+		{"nas", "df `rm -rf ~`", true},
+		{"nas", "df -h\nrm -rf ~", true},
+		{"nas", "systemctl stop nginx", true},
+		{"pi", "sudo reboot", true},
+		{"pi", "sudo REBOOT", true},
+		{"pi", "uptime; reboot", true},
+		{"pi", "uptime; date", false}, // No allowlist, so only the deny patterns apply
+		{"pi", "journalctl -n 20", false},
+		{"other", "df", true},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(context.Background(), map[string]any{"host": tt.host, "command": tt.command})
+		blocked := result.IsError && (strings.Contains(result.ForLLM, "blocked") ||
+			strings.Contains(result.ForLLM, "unknown host"))
+		if blocked != tt.blocked {
+			t.Errorf("%s: %q blocked = %v, want %v (%s)", tt.host, tt.command, blocked, tt.blocked, result.ForLLM)
+		}
+	}
+}
+
+func TestSSHTool_Failures(t *testing.T) {
+	tool := newTestSSHTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"host": "down", "command": "uptime"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "Can't log in to down: ssh: connect to host down") {
+		t.Errorf("unreachable host: %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"host": "nas", "command": "fail"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no such unit") ||
+		!strings.Contains(result.ForLLM, "Exit code: 3") {
+		t.Errorf("failing command: %q", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"host": "nas", "command": "big"})
+	if result.IsError || !strings.Contains(result.ForLLM, "(truncated, 700 more chars)") {
+		t.Errorf("big output: %q", result.ForLLM)
+	}
+}
@@ -35,6 +35,7 @@ type SubagentManager struct {
 	temperature    float64
 	hasMaxTokens   bool
 	hasTemperature bool
+	maxParallel    int
 	nextID         int
 }
 
@@ -65,6 +66,13 @@ func (sm *SubagentManager) SetLLMOptions(maxTokens int, temperature float64) {
 	sm.hasTemperature = true
 }
 
+// SetMaxParallelTools caps concurrent tool calls within one subagent turn.
+func (sm *SubagentManager) SetMaxParallelTools(n int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxParallel = n
+}
+
 // SetTools sets the tool registry for subagent execution.
 // If not set, subagent will have access to the provided tools.
 func (sm *SubagentManager) SetTools(tools *ToolRegistry) {
@@ -151,6 +159,7 @@ After completing the task, provide a clear summary of what was done.`
 	temperature := sm.temperature
 	hasMaxTokens := sm.hasMaxTokens
 	hasTemperature := sm.hasTemperature
+	maxParallel := sm.maxParallel
 	sm.mu.RUnlock()
 
 	var llmOptions map[string]any
@@ -165,11 +174,12 @@ After completing the task, provide a clear summary of what was done.`
 	}
 
 	loopResult, err := RunToolLoop(ctx, ToolLoopConfig{
-		Provider:      sm.provider,
-		Model:         sm.defaultModel,
-		Tools:         tools,
-		MaxIterations: maxIter,
-		LLMOptions:    llmOptions,
+		Provider:         sm.provider,
+		Model:            sm.defaultModel,
+		Tools:            tools,
+		MaxIterations:    maxIter,
+		LLMOptions:       llmOptions,
+		MaxParallelTools: maxParallel,
 	}, messages, task.OriginChannel, task.OriginChatID)
 
 	sm.mu.Lock()
@@ -326,6 +336,7 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	temperature := sm.temperature
 	hasMaxTokens := sm.hasMaxTokens
 	hasTemperature := sm.hasTemperature
+	maxParallel := sm.maxParallel
 	sm.mu.RUnlock()
 
 	var llmOptions map[string]any
@@ -340,11 +351,12 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 
 	loopResult, err := RunToolLoop(ctx, ToolLoopConfig{
-		Provider:      sm.provider,
-		Model:         sm.defaultModel,
-		Tools:         tools,
-		MaxIterations: maxIter,
-		LLMOptions:    llmOptions,
+		Provider:         sm.provider,
+		Model:            sm.defaultModel,
+		Tools:            tools,
+		MaxIterations:    maxIter,
+		LLMOptions:       llmOptions,
+		MaxParallelTools: maxParallel,
 	}, messages, t.originChannel, t.originChatID)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("Subagent execution failed: %v", err)).WithError(err)
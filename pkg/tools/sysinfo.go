@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/sysinfo"
+)
+
+const defaultTopProcesses = 5
+
+var sysinfoSections = []string{"cpu", "memory", "temperature", "disk", "network", "processes"}
+
+// SysInfoTool reports the state of the system the agent runs on: CPU,
+// memory, temperatures, disks, network and the busiest processes.
+type SysInfoTool struct {
+	disks []string
+
+	// collect measures the system; replaced in tests.
+	collect func(ctx context.Context, opts sysinfo.Options) (*sysinfo.Snapshot, error)
+}
+
+// NewSysInfoTool creates the tool, reporting the space of disks (the root
+// filesystem when empty).
+func NewSysInfoTool(disks []string) *SysInfoTool {
+	return &SysInfoTool{disks: disks, collect: sysinfo.Collect}
+}
+
+func (t *SysInfoTool) Name() string {
+	return "sysinfo"
+}
+
+func (t *SysInfoTool) Description() string {
+	return "Check the system you run on: CPU use and load, memory, temperatures, disk space, network " +
+		"traffic and the busiest processes. Use it when asked how the device is doing, or why it is slow or hot."
+}
+
+func (t *SysInfoTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"sections": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string", "enum": sysinfoSections},
+				"description": "What to report; default: all",
+			},
+			"top": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("How many of the busiest processes to list (default %d)", defaultTopProcesses),
+			},
+		},
+	}
+}
+
+func (t *SysInfoTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	sections := listArg(args["sections"])
+	for _, s := range sections {
+		if !slices.Contains(sysinfoSections, s) {
+			return ErrorResult(fmt.Sprintf("unknown section %q; use %s", s, strings.Join(sysinfoSections, ", ")))
+		}
+	}
+	if len(sections) == 0 {
+		sections = sysinfoSections
+	}
+	opts := sysinfo.Options{Disks: t.disks}
+	if slices.Contains(sections, "processes") {
+		opts.TopProcesses = defaultTopProcesses
+		if top, ok := args["top"].(float64); ok {
+			opts.TopProcesses = min(max(int(top), 1), 50)
+		}
+	}
+
+	snap, err := t.collect(ctx, opts)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Can't read the system's state: %v", err)).WithError(err)
+	}
+	var sb strings.Builder
+	for _, section := range sections {
+		switch section {
+		case "cpu":
+			cores := "cores"
+			if snap.CPUs == 1 {
+				cores = "core"
+			}
+			fmt.Fprintf(&sb, "CPU: %.0f%% of %d %s, load %.2f %.2f %.2f, up %s\n", snap.CPUPercent,
+				snap.CPUs, cores, snap.Load[0], snap.Load[1], snap.Load[2], formatUptime(snap.Uptime))
+		case "memory":
+			mem := snap.Memory
+			fmt.Fprintf(&sb, "Memory: %s of %s used (%.0f%%)", formatBytes(mem.Total-mem.Available),
+				formatBytes(mem.Total), mem.UsedPercent())
+			if mem.SwapTotal > 0 {
+				fmt.Fprintf(&sb, ", swap %s of %s used", formatBytes(mem.SwapTotal-mem.SwapFree),
+					formatBytes(mem.SwapTotal))
+			}
+			sb.WriteString("\n")
+		case "temperature":
+			if len(snap.Temperatures) == 0 {
+				sb.WriteString("Temperature: no sensors\n")
+				continue
+			}
+			temps := make([]string, len(snap.Temperatures))
+			for i, temp := range snap.Temperatures {
+				temps[i] = fmt.Sprintf("%s %.1f°C", temp.Zone, temp.Celsius)
+			}
+			sb.WriteString("Temperature: " + strings.Join(temps, ", ") + "\n")
+		case "disk":
+			for _, d := range snap.Disks {
+				fmt.Fprintf(&sb, "Disk %s: %s free of %s (%.0f%% used)\n", d.Path, formatBytes(d.Free),
+					formatBytes(d.Total), d.UsedPercent())
+			}
+		case "network":
+			for _, iface := range snap.Network {
+				if iface.RxBytes == 0 && iface.TxBytes == 0 {
+					continue // Unused, such as a bridge without members
+				}
+				fmt.Fprintf(&sb, "Network %s: receiving %s/s, sending %s/s (since boot: %s in, %s out)\n",
+					iface.Name, formatBytes(uint64(iface.RxRate)), formatBytes(uint64(iface.TxRate)),
+					formatBytes(iface.RxBytes), formatBytes(iface.TxBytes))
+			}
+		case "processes":
+			sb.WriteString("Busiest processes:\n")
+			for _, p := range snap.Processes {
+				fmt.Fprintf(&sb, "- %d %s: %.1f%% CPU, %s\n", p.PID, p.Name, p.CPUPercent, formatBytes(p.RSS))
+			}
+		}
+	}
+	return SilentResult(strings.TrimRight(sb.String(), "\n"))
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, int(d.Minutes())%60)
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/sysinfo"
+)
+
+func TestSysInfoTool(t *testing.T) {
+	tool := NewSysInfoTool(nil)
+	var got sysinfo.Options
+	tool.collect = func(_ context.Context, opts sysinfo.Options) (*sysinfo.Snapshot, error) {
+		got = opts
+		return &sysinfo.Snapshot{
+			CPUPercent:   42,
+			CPUs:         4,
+			Load:         [3]float64{1.5, 1, 0.5},
+			Uptime:       50 * time.Hour,
+			Memory:       sysinfo.Memory{Total: 2 << 30, Available: 1 << 30},
+			Temperatures: []sysinfo.Temperature{{Zone: "cpu-thermal", Celsius: 61.5}},
+			Disks:        []sysinfo.Disk{{Path: "/", Total: 32 << 30, Free: 8 << 30}},
+			Network:      []sysinfo.Interface{{Name: "wlan0", RxRate: 2048, RxBytes: 3 << 20}},
+			Processes:    []sysinfo.Process{{PID: 7, Name: "picoclaw", CPUPercent: 12.5, RSS: 40 << 20}},
+		}, nil
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{})
+	for _, want := range []string{
+		"CPU: 42% of 4 cores, load 1.50 1.00 0.50, up 2d 2h",
+		"Memory: 1.0 GB of 2.0 GB used (50%)",
+		"Temperature: cpu-thermal 61.5°C",
+		"Disk /: 8.0 GB free of 32.0 GB (75% used)",
+		"Network wlan0: receiving 2 KB/s",
+		"- 7 picoclaw: 12.5% CPU, 40.0 MB",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("result lacks %q:\n%s", want, result.ForLLM)
+		}
+	}
+	if got.TopProcesses != defaultTopProcesses {
+		t.Errorf("TopProcesses = %d, want %d", got.TopProcesses, defaultTopProcesses)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"sections": []any{"memory"}})
+	if strings.Contains(result.ForLLM, "CPU") || got.TopProcesses != 0 {
+		t.Errorf("sections ignored: %q, TopProcesses = %d", result.ForLLM, got.TopProcesses)
+	}
+	if result := tool.Execute(context.Background(), map[string]any{"sections": []any{"gpu"}}); !result.IsError {
+		t.Error("unknown section accepted")
+	}
+}
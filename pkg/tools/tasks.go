@@ -0,0 +1,257 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/tasks"
+)
+
+// taskMorning is the hour a task due on a day, without a time, is due at.
+const taskMorning = 9
+
+// TasksTool manages the user's to-do list. Overdue tasks are brought up by
+// the heartbeat.
+type TasksTool struct {
+	store *tasks.Store
+}
+
+func NewTasksTool(store *tasks.Store) *TasksTool {
+	return &TasksTool{store: store}
+}
+
+func (t *TasksTool) Name() string {
+	return "tasks"
+}
+
+func (t *TasksTool) Description() string {
+	return "The user's to-do list. 'add' a task with an optional 'due' time ('friday', 'tomorrow at 17:00'), " +
+		"'priority' and 'repeat' rule ('every monday at 9am', 'every 2 weeks'); completing a repeating task " +
+		"makes it due at the next time. 'list' open, overdue or done tasks; 'complete', 'snooze' (until a time), " +
+		"'update' or 'delete' one by ID. Overdue tasks are brought up to the user regularly until done or snoozed."
+}
+
+func (t *TasksTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"add", "list", "complete", "snooze", "update", "delete"},
+				"description": "What to do",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "Task ID (complete, snooze, update, delete)",
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "What is to be done (add, update)",
+			},
+			"notes": map[string]any{
+				"type":        "string",
+				"description": "Details (add, update)",
+			},
+			"priority": map[string]any{
+				"type":        "string",
+				"enum":        []string{tasks.PriorityHigh, tasks.PriorityNormal, tasks.PriorityLow},
+				"description": "Default: normal (add, update)",
+			},
+			"due": map[string]any{
+				"type": "string",
+				"description": "When it is due (add, update): 'friday', 'tomorrow at 17:00', 'in 3 days', " +
+					"'2026-12-24' or an ISO time; 'none' removes it",
+			},
+			"repeat": map[string]any{
+				"type": "string",
+				"description": "Repeating rule (add, update): 'every day at 8:00', 'every weekday at 9am', " +
+					"'every 2 weeks'; 'none' removes it",
+			},
+			"until": map[string]any{
+				"type":        "string",
+				"description": "Snooze until: 'in 2 hours', 'tomorrow', 'monday at 9'",
+			},
+			"tz": map[string]any{
+				"type":        "string",
+				"description": "IANA time zone of the times given (e.g. 'Europe/Paris'). Default: the server's.",
+			},
+			"filter": map[string]any{
+				"type":        "string",
+				"enum":        []string{tasks.FilterOpen, tasks.FilterOverdue, tasks.FilterDone, tasks.FilterAll},
+				"description": "Tasks to list; default: open",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TasksTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	tz, _ := args["tz"].(string)
+	loc := time.Local
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return ErrorResult(fmt.Sprintf("unknown time zone %q", tz))
+		}
+	}
+	now := time.Now().In(loc)
+	id, _ := args["id"].(string)
+
+	var task *tasks.Task
+	var err error
+	switch action, _ := args["action"].(string); action {
+	case "add":
+		task = &tasks.Task{TZ: tz}
+		if err = applyTaskArgs(task, args, now); err != nil {
+			return ErrorResult(err.Error())
+		}
+		if task, err = t.store.Add(*task); err == nil {
+			return SilentResult("Added task " + task.Describe(now))
+		}
+	case "list":
+		filter, _ := args["filter"].(string)
+		return t.list(filter, now)
+	case "complete":
+		if task, err = t.store.Complete(id); err == nil {
+			if task.Repeat != nil {
+				return SilentResult("Done; next time: " + task.Describe(now))
+			}
+			return SilentResult("Completed task " + task.Describe(now))
+		}
+	case "snooze":
+		until, _ := args["until"].(string)
+		if until == "" {
+			return ErrorResult("until is required to snooze a task")
+		}
+		at, err := taskTime(until, now)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		if !at.After(now) {
+			return ErrorResult(fmt.Sprintf("%s is in the past", at.Format("2006-01-02 15:04")))
+		}
+		if task, err = t.store.Snooze(id, at); err == nil {
+			return SilentResult("Snoozed task " + task.Describe(now))
+		}
+		return tasksError(err)
+	case "update":
+		var argErr error
+		task, err = t.store.Update(id, func(task *tasks.Task) {
+			if tz != "" {
+				task.TZ = tz
+			}
+			argErr = applyTaskArgs(task, args, now)
+		})
+		if argErr != nil {
+			return ErrorResult(argErr.Error())
+		}
+		if err == nil {
+			return SilentResult("Updated task " + task.Describe(now))
+		}
+	case "delete":
+		if err = t.store.Delete(id); err == nil {
+			return SilentResult(fmt.Sprintf("Task %s deleted", id))
+		}
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+	return tasksError(err)
+}
+
+func (t *TasksTool) list(filter string, now time.Time) *ToolResult {
+	list, err := t.store.List(filter)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if filter == "" {
+		filter = tasks.FilterOpen
+	}
+	if len(list) == 0 {
+		return SilentResult(fmt.Sprintf("No %s tasks", filter))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d %s tasks:", len(list), filter)
+	for _, task := range list {
+		sb.WriteString("\n- " + task.Describe(now))
+	}
+	return SilentResult(sb.String())
+}
+
+// applyTaskArgs sets the fields of task given in args.
+func applyTaskArgs(task *tasks.Task, args map[string]any, now time.Time) error {
+	if title, ok := args["title"].(string); ok {
+		task.Title = title
+	}
+	if notes, ok := args["notes"].(string); ok {
+		task.Notes = strings.TrimSpace(notes)
+	}
+	if priority, ok := args["priority"].(string); ok {
+		task.Priority = priority
+	}
+	if due, ok := args["due"].(string); ok {
+		if due = strings.TrimSpace(due); due == "" || strings.EqualFold(due, "none") {
+			task.Due = time.Time{}
+		} else {
+			at, err := taskTime(due, now)
+			if err != nil {
+				return err
+			}
+			task.Due = at
+		}
+	}
+	if repeat, ok := args["repeat"].(string); ok {
+		if repeat = strings.TrimSpace(repeat); repeat == "" || strings.EqualFold(repeat, "none") {
+			task.Repeat = nil
+		} else {
+			rule, err := cron.ParseSchedule(repeat, now)
+			if err != nil {
+				return fmt.Errorf("can't understand repeat: %v", err)
+			}
+			if rule.Kind == "at" {
+				return fmt.Errorf("%q doesn't repeat; pass it as 'due'", repeat)
+			}
+			rule.TZ = task.TZ
+			task.Repeat = &rule
+		}
+	}
+	return nil
+}
+
+// taskTime reads a due or snooze time: an ISO time, a one-time schedule in
+// plain English (see cron.ParseSchedule), or a day alone, such as "friday"
+// or "2026-12-24", meaning that morning. now gives the time zone.
+func taskTime(when string, now time.Time) (time.Time, error) {
+	when = strings.TrimSpace(when)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if at, err := time.ParseInLocation(layout, when, now.Location()); err == nil {
+			return at.In(now.Location()), nil
+		}
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, when, now.Location()); err == nil {
+		return time.Date(day.Year(), day.Month(), day.Day(), taskMorning, 0, 0, 0, now.Location()), nil
+	}
+	schedule, err := cron.ParseSchedule(when, now)
+	if err != nil {
+		if s, dayErr := cron.ParseSchedule(fmt.Sprintf("%s at %d:00", when, taskMorning), now); dayErr == nil {
+			schedule, err = s, nil
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't understand %q: %v", when, err)
+	}
+	if schedule.Kind != "at" {
+		return time.Time{}, fmt.Errorf("%q repeats; pass it as 'repeat'", when)
+	}
+	return time.UnixMilli(*schedule.AtMS).In(now.Location()), nil
+}
+
+func tasksError(err error) *ToolResult {
+	if errors.Is(err, tasks.ErrNotFound) {
+		return ErrorResult(err.Error() + "; list the tasks for their IDs")
+	}
+	return ErrorResult(err.Error()).WithError(err)
+}
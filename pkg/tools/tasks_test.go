@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/tasks"
+)
+
+func TestTasksTool(t *testing.T) {
+	store := tasks.NewStore(filepath.Join(t.TempDir(), "tasks.json"))
+	tool := NewTasksTool(store)
+	ctx := context.Background()
+	run := func(args map[string]any) string {
+		t.Helper()
+		result := tool.Execute(ctx, args)
+		if result.IsError {
+			t.Fatalf("%v: %s", args, result.ForLLM)
+		}
+		return result.ForLLM
+	}
+
+	got := run(map[string]any{"action": "add", "title": "Pay rent", "priority": "high", "due": "2020-01-01"})
+	if !strings.HasPrefix(got, "Added task [1] Pay rent (high priority), due Wed 2020-01-01 09:00, overdue by ") ||
+		!strings.HasSuffix(got, " days") {
+		t.Errorf("add = %q", got)
+	}
+	got = run(map[string]any{
+		"action": "add", "title": "Take out the bins", "repeat": "every tuesday at 19:00", "tz": "Europe/Paris",
+	})
+	if !strings.Contains(got, "[2] Take out the bins, due Tue ") || !strings.Contains(got, "19:00, repeats ") {
+		t.Errorf("add repeating = %q", got)
+	}
+	run(map[string]any{"action": "add", "title": "Someday", "priority": "low"})
+
+	got = run(map[string]any{"action": "list", "filter": "overdue"})
+	if !strings.HasPrefix(got, "1 overdue tasks:\n- [1] Pay rent") {
+		t.Errorf("list overdue = %q", got)
+	}
+
+	got = run(map[string]any{"action": "snooze", "id": "1", "until": "in 2 hours"})
+	if !strings.Contains(got, "snoozed until") {
+		t.Errorf("snooze = %q", got)
+	}
+	if got = run(map[string]any{"action": "list", "filter": "overdue"}); got != "No overdue tasks" {
+		t.Errorf("list overdue after snooze = %q", got)
+	}
+
+	got = run(map[string]any{"action": "complete", "id": "2"})
+	if !strings.HasPrefix(got, "Done; next time: [2]") || !strings.Contains(got, "done once") {
+		t.Errorf("complete repeating = %q", got)
+	}
+	run(map[string]any{"action": "complete", "id": "1"})
+	got = run(map[string]any{"action": "update", "id": "3", "notes": "Learn the cello", "due": "tomorrow"})
+	if !strings.Contains(got, "09:00: Learn the cello") {
+		t.Errorf("update = %q", got)
+	}
+	run(map[string]any{"action": "delete", "id": "3"})
+
+	got = run(map[string]any{"action": "list"})
+	if !strings.HasPrefix(got, "1 open tasks:\n- [2] Take out the bins") {
+		t.Errorf("list = %q", got)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "add", "title": "x", "due": "every day at 9"},
+		{"action": "add", "title": "x", "repeat": "tomorrow at 9"},
+		{"action": "add", "title": "x", "due": "when pigs fly"},
+		{"action": "add", "title": "x", "tz": "Mars/Olympus"},
+		{"action": "complete", "id": "1"},
+		{"action": "snooze", "id": "2", "until": "2020-01-01"},
+		{"action": "delete", "id": "42"},
+		{"action": "update", "id": "2", "title": ""},
+	} {
+		if result := tool.Execute(ctx, args); !result.IsError {
+			t.Errorf("%v = %q, want an error", args, result.ForLLM)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// A WebAssembly tool for the tests: it describes one tool, hello, whose
+// calls answer with what they can see, or, given {"spin": true}, run until
+// stopped or, given {"grow": true}, take all the memory they can.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if os.Args[1] == "describe" {
+		fmt.Println(`{"name": "hello"}`)
+		return
+	}
+	var request struct {
+		Workspace string         `json:"workspace"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	switch {
+	case request.Arguments["spin"] == true:
+		for {
+		}
+	case request.Arguments["grow"] == true:
+		var chunks [][]byte
+		for {
+			chunks = append(chunks, make([]byte, 1<<20))
+		}
+	}
+	note, err := os.ReadFile(request.Workspace + "/note.txt")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	_, err = os.ReadFile("/etc/passwd")
+	fmt.Printf("workspace %s: %s; /etc/passwd: %v\n", request.Workspace, note, err != nil)
+}
@@ -23,12 +23,22 @@ type ToolLoopConfig struct {
 	Tools         *ToolRegistry
 	MaxIterations int
 	LLMOptions    map[string]any
+
+	// MaxParallelTools caps concurrent tool calls within one model turn;
+	// 0 or 1 runs them sequentially.
+	MaxParallelTools int
+
+	// TokenBudget stops the loop once its LLM calls have used this many
+	// tokens; 0 means no limit.
+	TokenBudget int
 }
 
 // ToolLoopResult contains the result of running the tool loop.
 type ToolLoopResult struct {
 	Content    string
 	Iterations int
+	Tokens     int  // Total tokens reported by the provider
+	OverBudget bool // Stopped by TokenBudget before a final answer
 }
 
 // RunToolLoop executes the LLM + tool call iteration loop.
@@ -40,6 +50,8 @@ func RunToolLoop(
 	channel, chatID string,
 ) (*ToolLoopResult, error) {
 	iteration := 0
+	tokens := 0
+	overBudget := false
 	var finalContent string
 
 	for iteration < config.MaxIterations {
@@ -73,6 +85,10 @@ func RunToolLoop(
 			return nil, fmt.Errorf("LLM call failed: %w", err)
 		}
 
+		if response.Usage != nil {
+			tokens += response.Usage.TotalTokens
+		}
+
 		// 4. If no tool calls, we're done
 		if len(response.ToolCalls) == 0 {
 			finalContent = response.Content
@@ -84,6 +100,18 @@ func RunToolLoop(
 			break
 		}
 
+		if config.TokenBudget > 0 && tokens >= config.TokenBudget {
+			finalContent = response.Content
+			overBudget = true
+			logger.WarnCF("toolloop", "Token budget used up",
+				map[string]any{
+					"iteration": iteration,
+					"tokens":    tokens,
+					"budget":    config.TokenBudget,
+				})
+			break
+		}
+
 		normalizedToolCalls := make([]providers.ToolCall, 0, len(response.ToolCalls))
 		for _, tc := range response.ToolCalls {
 			normalizedToolCalls = append(normalizedToolCalls, providers.NormalizeToolCall(tc))
@@ -103,8 +131,10 @@ func RunToolLoop(
 
 		// 6. Build assistant message with tool calls
 		assistantMsg := providers.Message{
-			Role:    "assistant",
-			Content: response.Content,
+			Role:               "assistant",
+			Content:            response.Content,
+			ReasoningContent:   response.ReasoningContent,
+			ReasoningSignature: response.ReasoningSignature,
 		}
 		for _, tc := range normalizedToolCalls {
 			argumentsJSON, _ := json.Marshal(tc.Arguments)
@@ -121,7 +151,7 @@ func RunToolLoop(
 		}
 		messages = append(messages, assistantMsg)
 
-		// 7. Execute tool calls
+		// 7. Execute tool calls (no async callback for subagents - they run independently)
 		for _, tc := range normalizedToolCalls {
 			argsJSON, _ := json.Marshal(tc.Arguments)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
@@ -130,13 +160,17 @@ func RunToolLoop(
 					"tool":      tc.Name,
 					"iteration": iteration,
 				})
+		}
+
+		var toolResults []*ToolResult
+		if config.Tools != nil {
+			toolResults = config.Tools.ExecuteAll(ctx, normalizedToolCalls, channel, chatID, config.MaxParallelTools, nil)
+		}
 
-			// Execute tool (no async callback for subagents - they run independently)
-			var toolResult *ToolResult
-			if config.Tools != nil {
-				toolResult = config.Tools.ExecuteWithContext(ctx, tc.Name, tc.Arguments, channel, chatID, nil)
-			} else {
-				toolResult = ErrorResult("No tools available")
+		for i, tc := range normalizedToolCalls {
+			toolResult := ErrorResult("No tools available")
+			if toolResults != nil {
+				toolResult = toolResults[i]
 			}
 
 			// Determine content for LLM
@@ -158,5 +192,7 @@ func RunToolLoop(
 	return &ToolLoopResult{
 		Content:    finalContent,
 		Iterations: iteration,
+		Tokens:     tokens,
+		OverBudget: overBudget,
 	}, nil
 }
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+// UsageTool reports token usage and estimated LLM spend.
+type UsageTool struct {
+	tracker *usage.Tracker
+	now     func() time.Time
+}
+
+func NewUsageTool(tracker *usage.Tracker) *UsageTool {
+	return &UsageTool{tracker: tracker, now: time.Now}
+}
+
+func (t *UsageTool) Name() string {
+	return "usage"
+}
+
+func (t *UsageTool) Description() string {
+	return "Report LLM token usage and estimated cost (USD) by day, model and session. " +
+		"Use this when the user asks how much they have spent or how many tokens were used."
+}
+
+func (t *UsageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"period": map[string]any{
+				"type":        "string",
+				"enum":        []string{"today", "week", "month", "all"},
+				"description": "Reporting period: today, week (last 7 days), month (last 30 days) or all. Defaults to today.",
+			},
+		},
+	}
+}
+
+func (t *UsageTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	period, _ := args["period"].(string)
+	since, err := usage.PeriodStart(period, t.now())
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if period == "" {
+		period = "today"
+	}
+
+	report := t.tracker.Summary(since)
+	return SilentResult(report.Format("Usage (" + period + ")"))
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/usage"
+)
+
+func TestUsageTool_Execute(t *testing.T) {
+	tracker := usage.NewTracker(t.TempDir())
+	now := time.Now()
+	tracker.Add(usage.Record{Time: now, Model: "openai/gpt-4o", PromptTokens: 100, CompletionTokens: 50, Cost: 0.75})
+	tracker.Add(usage.Record{Time: now.AddDate(0, 0, -5), Model: "openai/gpt-4o", PromptTokens: 100, CompletionTokens: 50, Cost: 1})
+
+	tool := NewUsageTool(tracker)
+
+	result := tool.Execute(context.Background(), map[string]any{})
+	if result.IsError || !strings.Contains(result.ForLLM, "Usage (today): 1 calls") {
+		t.Errorf("today result = %+v", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"period": "week"})
+	if result.IsError || !strings.Contains(result.ForLLM, "2 calls") || !strings.Contains(result.ForLLM, "$1.7500") {
+		t.Errorf("week result = %+v", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"period": "decade"})
+	if !result.IsError {
+		t.Errorf("unknown period result = %+v, want error", result)
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// ValidateArgs checks tool call arguments against the tool's parameter
+// schema: arguments that didn't parse as JSON, missing required properties,
+// and the type and enum of each property present. Properties the schema
+// doesn't describe are left to the tool.
+func ValidateArgs(schema, args map[string]any) error {
+	props, _ := schema["properties"].(map[string]any)
+
+	// Providers pass unparsable arguments through as {"raw": "..."}.
+	if raw, ok := args["raw"].(string); ok && len(args) == 1 && props["raw"] == nil {
+		return fmt.Errorf("arguments are not a valid JSON object: %s", utils.Truncate(raw, 200))
+	}
+
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := args[name]; !ok {
+			return fmt.Errorf("missing required parameter %q", name)
+		}
+	}
+
+	for name, value := range args {
+		prop, ok := props[name].(map[string]any)
+		if !ok || value == nil {
+			continue
+		}
+		if typ, _ := prop["type"].(string); typ != "" && !hasJSONType(value, typ) {
+			return fmt.Errorf("parameter %q must be of type %s, got %s", name, typ, utils.Truncate(fmt.Sprint(value), 200))
+		}
+		if enum := stringList(prop["enum"]); len(enum) > 0 {
+			if s, ok := value.(string); ok && !slices.Contains(enum, s) {
+				return fmt.Errorf("parameter %q must be one of %v, got %q", name, enum, s)
+			}
+		}
+	}
+	return nil
+}
+
+// hasJSONType reports whether value decodes from a JSON value of the schema
+// type typ. Numbers may be any Go numeric type, as tools are also called
+// directly with Go values.
+func hasJSONType(value any, typ string) bool {
+	v := reflect.ValueOf(value)
+	switch typ {
+	case "string":
+		return v.Kind() == reflect.String
+	case "boolean":
+		return v.Kind() == reflect.Bool
+	case "integer":
+		if v.CanInt() || v.CanUint() {
+			return true
+		}
+		return v.CanFloat() && v.Float() == math.Trunc(v.Float())
+	case "number":
+		return v.CanInt() || v.CanUint() || v.CanFloat()
+	case "array":
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	case "object":
+		return v.Kind() == reflect.Map || v.Kind() == reflect.Struct
+	}
+	return true
+}
+
+// stringList reads a schema keyword holding strings, declared in Go as
+// []string or decoded from JSON as []any.
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
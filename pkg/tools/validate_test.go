@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateArgs(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{"type": "string", "enum": []string{"add", "list"}},
+			"count":  map[string]any{"type": "integer"},
+			"ratio":  map[string]any{"type": "number"},
+			"tags":   map[string]any{"type": "array"},
+			"force":  map[string]any{"type": "boolean"},
+		},
+		"required": []any{"action"},
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"valid", map[string]any{"action": "add", "count": float64(3), "ratio": 0.5, "force": true}, ""},
+		{"go ints", map[string]any{"action": "list", "count": 3, "ratio": 2}, ""},
+		{"unknown property", map[string]any{"action": "add", "extra": "x"}, ""},
+		{"null property", map[string]any{"action": "add", "count": nil}, ""},
+		{"missing required", map[string]any{"count": 1}, `missing required parameter "action"`},
+		{"wrong type", map[string]any{"action": "add", "tags": "a,b"}, `"tags" must be of type array`},
+		{"fractional integer", map[string]any{"action": "add", "count": 1.5}, `"count" must be of type integer`},
+		{"not in enum", map[string]any{"action": "delete"}, `"action" must be one of [add list]`},
+		{"unparsable", map[string]any{"raw": `{"action": add}`}, "not a valid JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArgs(schema, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateArgs() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateArgs() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
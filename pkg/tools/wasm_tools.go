@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// A WebAssembly tool is a .wasm module, built for WASI, that speaks the
+// same protocol as an executable: it's given "describe" or "call" as its
+// argument, and the call's request on stdin. It runs inside picoclaw, in
+// wazero, so one module serves every architecture with nothing to install,
+// and it can't reach the host beyond the agent's workspace, mounted at
+// /workspace during calls, and its standard streams. Its memory is capped,
+// and it's stopped when the call times out.
+
+const (
+	defaultWASMMaxMemoryMB = 128
+
+	// wasmWorkspace is where modules see the workspace.
+	wasmWorkspace = "/workspace"
+)
+
+// wasmCache keeps modules compiled, so that each call only instantiates
+// them.
+var wasmCache = wazero.NewCompilationCache()
+
+func isWASMModule(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".wasm")
+}
+
+// runWASM runs the module at path with arg, with at most maxMemoryMB
+// megabytes of memory, and workspace mounted if it's set. It returns when
+// the module exits, or ctx is done; a non-zero exit status is an error.
+func runWASM(ctx context.Context, path string, maxMemoryMB int, workspace, arg string,
+	stdin io.Reader, stdout, stderr io.Writer,
+) error {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	if maxMemoryMB <= 0 {
+		maxMemoryMB = defaultWASMMaxMemoryMB
+	}
+	// WebAssembly pages are 64 KiB.
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCompilationCache(wasmCache).
+		WithMemoryLimitPages(uint32(maxMemoryMB)*16).
+		WithCloseOnContextDone(true))
+	defer runtime.Close(context.WithoutCancel(ctx))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return err
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return fmt.Errorf("invalid WebAssembly module: %w", err)
+	}
+	fs := wazero.NewFSConfig()
+	if workspace != "" {
+		fs = fs.WithDirMount(workspace, wasmWorkspace)
+	}
+	config := wazero.NewModuleConfig().
+		WithArgs(filepath.Base(path), arg).
+		WithStdin(stdin).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithFSConfig(fs).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	_, err = runtime.InstantiateModule(ctx, compiled, config)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() == 0 {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("exit status %d", exitErr.ExitCode())
+	}
+	return err
+}
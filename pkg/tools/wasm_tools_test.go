@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildWASMTool builds testdata/wasmtool for WASI into dir.
+func buildWASMTool(t *testing.T, dir string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building a WebAssembly module takes a while")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't installed to build the module")
+	}
+	cmd := exec.Command(goTool, "build", "-o", filepath.Join(dir, "hello.wasm"), ".")
+	cmd.Dir = filepath.Join("testdata", "wasmtool")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the module: %v\n%s", err, out)
+	}
+}
+
+func TestLoadExternalTools_WASM(t *testing.T) {
+	dir := t.TempDir()
+	buildWASMTool(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "broken.wasm"), []byte("\x00asm"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, problems := LoadExternalTools(dir, 64, 5*time.Second)
+	if len(loaded) != 1 || loaded[0].Name() != "hello" {
+		t.Fatalf("LoadExternalTools() = %v, want the module's tool", loaded)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "broken.wasm") {
+		t.Errorf("LoadExternalTools() problems = %v, want broken.wasm", problems)
+	}
+
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "note.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tool := loaded[0].ForWorkspace(workspace)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{})
+	if result.IsError || result.ForLLM != "workspace /workspace: hi; /etc/passwd: true" {
+		t.Errorf("Execute() = %q", result.ForLLM)
+	}
+
+	start := time.Now()
+	result = tool.Execute(ctx, map[string]any{"grow": true})
+	if !result.IsError {
+		t.Errorf("Execute() taking all the memory = %q, want an error", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"spin": true})
+	if !result.IsError || !strings.Contains(result.ForLLM, "timed out") {
+		t.Errorf("Execute() running forever = %q, want it timed out", result.ForLLM)
+	}
+	if elapsed := time.Since(start); elapsed > 15*time.Second {
+		t.Errorf("the calls took %s", elapsed)
+	}
+}
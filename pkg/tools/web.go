@@ -8,9 +8,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 const (
@@ -71,18 +74,57 @@ type SearchProvider interface {
 	Search(ctx context.Context, query string, count int) (string, error)
 }
 
+// SearchResult is one web search result, in the same form whatever the
+// backend.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// formatWebResults lists results for the model. via names the backend,
+// if it should be mentioned.
+func formatWebResults(query, via string, results []SearchResult, count int) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("No results for: %s", query)
+	}
+	header := fmt.Sprintf("Results for: %s", query)
+	if via != "" {
+		header += fmt.Sprintf(" (via %s)", via)
+	}
+	lines := []string{header}
+	for i, item := range results {
+		if i >= count {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, item.Title, item.URL))
+		if item.Snippet != "" {
+			lines = append(lines, fmt.Sprintf("   %s", item.Snippet))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 type BraveSearchProvider struct {
 	apiKey string
 	proxy  string
 }
 
 func (p *BraveSearchProvider) Search(ctx context.Context, query string, count int) (string, error) {
+	results, err := p.SearchResults(ctx, query, count)
+	if err != nil {
+		return "", err
+	}
+	return formatWebResults(query, "", results, count), nil
+}
+
+func (p *BraveSearchProvider) SearchResults(ctx context.Context, query string, count int) ([]SearchResult, error) {
 	searchURL := fmt.Sprintf("https://api.search.brave.com/res/v1/web/search?q=%s&count=%d",
 		url.QueryEscape(query), count)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
@@ -90,17 +132,17 @@ func (p *BraveSearchProvider) Search(ctx context.Context, query string, count in
 
 	client, err := createHTTPClient(p.proxy, 10*time.Second)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP client: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var searchResp struct {
@@ -116,27 +158,14 @@ func (p *BraveSearchProvider) Search(ctx context.Context, query string, count in
 	if err := json.Unmarshal(body, &searchResp); err != nil {
 		// Log error body for debugging
 		fmt.Printf("Brave API Error Body: %s\n", string(body))
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	results := searchResp.Web.Results
-	if len(results) == 0 {
-		return fmt.Sprintf("No results for: %s", query), nil
-	}
-
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Results for: %s", query))
-	for i, item := range results {
-		if i >= count {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, item.Title, item.URL))
-		if item.Description != "" {
-			lines = append(lines, fmt.Sprintf("   %s", item.Description))
-		}
+	results := make([]SearchResult, 0, len(searchResp.Web.Results))
+	for _, item := range searchResp.Web.Results {
+		results = append(results, SearchResult{Title: item.Title, URL: item.URL, Snippet: item.Description})
 	}
-
-	return strings.Join(lines, "\n"), nil
+	return results, nil
 }
 
 type TavilySearchProvider struct {
@@ -146,6 +175,14 @@ type TavilySearchProvider struct {
 }
 
 func (p *TavilySearchProvider) Search(ctx context.Context, query string, count int) (string, error) {
+	results, err := p.SearchResults(ctx, query, count)
+	if err != nil {
+		return "", err
+	}
+	return formatWebResults(query, "Tavily", results, count), nil
+}
+
+func (p *TavilySearchProvider) SearchResults(ctx context.Context, query string, count int) ([]SearchResult, error) {
 	searchURL := p.baseURL
 	if searchURL == "" {
 		searchURL = "https://api.tavily.com/search"
@@ -163,12 +200,12 @@ func (p *TavilySearchProvider) Search(ctx context.Context, query string, count i
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", searchURL, bytes.NewBuffer(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -176,21 +213,21 @@ func (p *TavilySearchProvider) Search(ctx context.Context, query string, count i
 
 	client, err := createHTTPClient(p.proxy, 10*time.Second)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP client: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("tavily api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("tavily api error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var searchResp struct {
@@ -202,27 +239,14 @@ func (p *TavilySearchProvider) Search(ctx context.Context, query string, count i
 	}
 
 	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	results := searchResp.Results
-	if len(results) == 0 {
-		return fmt.Sprintf("No results for: %s", query), nil
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Results for: %s (via Tavily)", query))
-	for i, item := range results {
-		if i >= count {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, item.Title, item.URL))
-		if item.Content != "" {
-			lines = append(lines, fmt.Sprintf("   %s", item.Content))
-		}
+	results := make([]SearchResult, 0, len(searchResp.Results))
+	for _, item := range searchResp.Results {
+		results = append(results, SearchResult{Title: item.Title, URL: item.URL, Snippet: item.Content})
 	}
-
-	return strings.Join(lines, "\n"), nil
+	return results, nil
 }
 
 type DuckDuckGoSearchProvider struct {
@@ -230,34 +254,45 @@ type DuckDuckGoSearchProvider struct {
 }
 
 func (p *DuckDuckGoSearchProvider) Search(ctx context.Context, query string, count int) (string, error) {
+	results, err := p.SearchResults(ctx, query, count)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return fmt.Sprintf("No results found or extraction failed. Query: %s", query), nil
+	}
+	return formatWebResults(query, "DuckDuckGo", results, count), nil
+}
+
+func (p *DuckDuckGoSearchProvider) SearchResults(ctx context.Context, query string, count int) ([]SearchResult, error) {
 	searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", url.QueryEscape(query))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", userAgent)
 
 	client, err := createHTTPClient(p.proxy, 10*time.Second)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP client: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return p.extractResults(string(body), count, query)
+	return p.extractResults(string(body), count), nil
 }
 
-func (p *DuckDuckGoSearchProvider) extractResults(html string, count int, query string) (string, error) {
+func (p *DuckDuckGoSearchProvider) extractResults(html string, count int) []SearchResult {
 	// Simple regex based extraction for DDG HTML
 	// Strategy: Find all result containers or key anchors directly
 
@@ -265,25 +300,16 @@ func (p *DuckDuckGoSearchProvider) extractResults(html string, count int, query
 	// Pattern: <a class="result__a" href="...">Title</a>
 	// The previous regex was a bit strict. Let's make it more flexible for attributes order/content
 	matches := reDDGLink.FindAllStringSubmatch(html, count+5)
-
 	if len(matches) == 0 {
-		return fmt.Sprintf("No results found or extraction failed. Query: %s", query), nil
+		return nil
 	}
 
-	var lines []string
-	lines = append(lines, fmt.Sprintf("Results for: %s (via DuckDuckGo)", query))
-
-	// Pre-compile snippet regex to run inside the loop
-	// We'll search for snippets relative to the link position or just globally if needed
-	// But simple global search for snippets might mismatch order.
-	// Since we only have the raw HTML string, let's just extract snippets globally and assume order matches (risky but simple for regex)
-	// Or better: Let's assume the snippet follows the link in the HTML
-
-	// A better regex approach: iterate through text and find matches in order
-	// But for now, let's grab all snippets too
+	// Snippets are extracted globally and assumed to be in the same order
+	// as the links, which holds for DDG's HTML results page.
 	snippetMatches := reDDGSnippet.FindAllStringSubmatch(html, count+5)
 
 	maxItems := min(len(matches), count)
+	results := make([]SearchResult, 0, maxItems)
 
 	for i := 0; i < maxItems; i++ {
 		urlStr := matches[i][1]
@@ -300,19 +326,80 @@ func (p *DuckDuckGoSearchProvider) extractResults(html string, count int, query
 			}
 		}
 
-		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, title, urlStr))
-
+		result := SearchResult{Title: title, URL: urlStr}
 		// Attempt to attach snippet if available and index aligns
 		if i < len(snippetMatches) {
-			snippet := stripTags(snippetMatches[i][1])
-			snippet = strings.TrimSpace(snippet)
-			if snippet != "" {
-				lines = append(lines, fmt.Sprintf("   %s", snippet))
-			}
+			result.Snippet = strings.TrimSpace(stripTags(snippetMatches[i][1]))
 		}
+		results = append(results, result)
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return results
+}
+
+// SearXNGSearchProvider searches a SearXNG instance through its JSON API,
+// which must be enabled in the instance's settings (search.formats).
+type SearXNGSearchProvider struct {
+	baseURL string
+	proxy   string
+}
+
+func (p *SearXNGSearchProvider) Search(ctx context.Context, query string, count int) (string, error) {
+	results, err := p.SearchResults(ctx, query, count)
+	if err != nil {
+		return "", err
+	}
+	return formatWebResults(query, "SearXNG", results, count), nil
+}
+
+func (p *SearXNGSearchProvider) SearchResults(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	searchURL := fmt.Sprintf("%s/search?q=%s&format=json",
+		strings.TrimRight(p.baseURL, "/"), url.QueryEscape(query))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	client, err := createHTTPClient(p.proxy, 15*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("SearXNG refused the request; enable the json format in its settings.yml")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SearXNG error: %s", resp.Status)
+	}
+
+	var searchResp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(searchResp.Results))
+	for _, item := range searchResp.Results {
+		results = append(results, SearchResult{Title: item.Title, URL: item.URL, Snippet: item.Content})
+	}
+	return results, nil
 }
 
 func stripTags(content string) string {
@@ -396,62 +483,98 @@ func (p *PerplexitySearchProvider) Search(ctx context.Context, query string, cou
 
 type WebSearchTool struct {
 	provider   SearchProvider
+	backend    string
 	maxResults int
+	limiter    *rateLimiter
+	cache      *searchCache
 }
 
 type WebSearchToolOptions struct {
-	BraveAPIKey          string
-	BraveMaxResults      int
-	BraveEnabled         bool
-	TavilyAPIKey         string
-	TavilyBaseURL        string
-	TavilyMaxResults     int
-	TavilyEnabled        bool
-	DuckDuckGoMaxResults int
-	DuckDuckGoEnabled    bool
-	PerplexityAPIKey     string
-	PerplexityMaxResults int
-	PerplexityEnabled    bool
-	Proxy                string
+	BraveAPIKey                 string
+	BraveMaxResults             int
+	BraveEnabled                bool
+	BraveRequestsPerMinute      int
+	TavilyAPIKey                string
+	TavilyBaseURL               string
+	TavilyMaxResults            int
+	TavilyEnabled               bool
+	TavilyRequestsPerMinute     int
+	DuckDuckGoMaxResults        int
+	DuckDuckGoEnabled           bool
+	DuckDuckGoRequestsPerMinute int
+	PerplexityAPIKey            string
+	PerplexityMaxResults        int
+	PerplexityEnabled           bool
+	PerplexityRequestsPerMinute int
+	SearXNGBaseURL              string
+	SearXNGMaxResults           int
+	SearXNGEnabled              bool
+	SearXNGRequestsPerMinute    int
+	Proxy                       string
+	// Backend names the one backend to use (see config.WebToolsConfig);
+	// empty, the first enabled one is.
+	Backend string
+	// CacheDir is where results are cached, for CacheTTL; an empty
+	// CacheDir or zero CacheTTL turns the cache off.
+	CacheDir string
+	CacheTTL time.Duration
 }
 
 func NewWebSearchTool(opts WebSearchToolOptions) *WebSearchTool {
-	var provider SearchProvider
-	maxResults := 5
-
-	// Priority: Perplexity > Brave > Tavily > DuckDuckGo
-	if opts.PerplexityEnabled && opts.PerplexityAPIKey != "" {
-		provider = &PerplexitySearchProvider{apiKey: opts.PerplexityAPIKey, proxy: opts.Proxy}
-		if opts.PerplexityMaxResults > 0 {
-			maxResults = opts.PerplexityMaxResults
-		}
-	} else if opts.BraveEnabled && opts.BraveAPIKey != "" {
-		provider = &BraveSearchProvider{apiKey: opts.BraveAPIKey, proxy: opts.Proxy}
-		if opts.BraveMaxResults > 0 {
-			maxResults = opts.BraveMaxResults
+	// Priority: Perplexity > Brave > Tavily > SearXNG > DuckDuckGo
+	backends := []struct {
+		name       string
+		enabled    bool
+		usable     bool
+		provider   SearchProvider
+		maxResults int
+		perMinute  int
+	}{
+		{
+			config.SearchPerplexity, opts.PerplexityEnabled, opts.PerplexityAPIKey != "",
+			&PerplexitySearchProvider{apiKey: opts.PerplexityAPIKey, proxy: opts.Proxy},
+			opts.PerplexityMaxResults, opts.PerplexityRequestsPerMinute,
+		},
+		{
+			config.SearchBrave, opts.BraveEnabled, opts.BraveAPIKey != "",
+			&BraveSearchProvider{apiKey: opts.BraveAPIKey, proxy: opts.Proxy},
+			opts.BraveMaxResults, opts.BraveRequestsPerMinute,
+		},
+		{
+			config.SearchTavily, opts.TavilyEnabled, opts.TavilyAPIKey != "",
+			&TavilySearchProvider{apiKey: opts.TavilyAPIKey, baseURL: opts.TavilyBaseURL, proxy: opts.Proxy},
+			opts.TavilyMaxResults, opts.TavilyRequestsPerMinute,
+		},
+		{
+			config.SearchSearXNG, opts.SearXNGEnabled, opts.SearXNGBaseURL != "",
+			&SearXNGSearchProvider{baseURL: opts.SearXNGBaseURL, proxy: opts.Proxy},
+			opts.SearXNGMaxResults, opts.SearXNGRequestsPerMinute,
+		},
+		{
+			config.SearchDuckDuckGo, opts.DuckDuckGoEnabled, true,
+			&DuckDuckGoSearchProvider{proxy: opts.Proxy},
+			opts.DuckDuckGoMaxResults, opts.DuckDuckGoRequestsPerMinute,
+		},
+	}
+
+	for _, b := range backends {
+		// A backend named in Backend needn't also be enabled.
+		if opts.Backend != "" && b.name != opts.Backend || opts.Backend == "" && !b.enabled || !b.usable {
+			continue
 		}
-	} else if opts.TavilyEnabled && opts.TavilyAPIKey != "" {
-		provider = &TavilySearchProvider{
-			apiKey:  opts.TavilyAPIKey,
-			baseURL: opts.TavilyBaseURL,
-			proxy:   opts.Proxy,
+		tool := &WebSearchTool{provider: b.provider, backend: b.name, maxResults: 5}
+		if b.maxResults > 0 {
+			tool.maxResults = b.maxResults
 		}
-		if opts.TavilyMaxResults > 0 {
-			maxResults = opts.TavilyMaxResults
+		if b.perMinute > 0 {
+			tool.limiter = newRateLimiter(b.perMinute, time.Minute)
 		}
-	} else if opts.DuckDuckGoEnabled {
-		provider = &DuckDuckGoSearchProvider{proxy: opts.Proxy}
-		if opts.DuckDuckGoMaxResults > 0 {
-			maxResults = opts.DuckDuckGoMaxResults
+		if opts.CacheDir != "" && opts.CacheTTL > 0 {
+			tool.cache = newSearchCache(filepath.Join(opts.CacheDir, searchCacheFile), opts.CacheTTL)
 		}
-	} else {
-		return nil
-	}
-
-	return &WebSearchTool{
-		provider:   provider,
-		maxResults: maxResults,
+		return tool
 	}
+	return nil
 }
 
 func (t *WebSearchTool) Name() string {
@@ -494,7 +617,7 @@ func (t *WebSearchTool) Execute(ctx context.Context, args map[string]any) *ToolR
 		}
 	}
 
-	result, err := t.provider.Search(ctx, query, count)
+	result, err := t.search(ctx, query, count)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("search failed: %v", err))
 	}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// searchCacheFile is the search cache, relative to
+	// WebSearchToolOptions.CacheDir.
+	searchCacheFile = "cache/web_search.json"
+	// searchCacheEntries caps the searches kept; the oldest go first.
+	searchCacheEntries = 200
+	// maxSearchWait is the longest a search waits for the backend's rate
+	// limit before failing (or falling back to the cache).
+	maxSearchWait = 10 * time.Second
+)
+
+// search answers from the cache while its entry is fresh, and otherwise
+// asks the backend, falling back to an outdated entry when that fails.
+func (t *WebSearchTool) search(ctx context.Context, query string, count int) (string, error) {
+	key := searchCacheKey(t.backend, query, count)
+	var stale *searchCacheEntry
+	if t.cache != nil {
+		entry, fresh := t.cache.get(key)
+		if fresh {
+			return entry.Text, nil
+		}
+		stale = entry
+	}
+
+	result, err := t.fetch(ctx, query, count)
+	if err != nil {
+		if stale == nil || ctx.Err() != nil {
+			return "", err
+		}
+		age := time.Since(stale.Time).Round(time.Minute)
+		return fmt.Sprintf("%s\n\n(Cached results from %s ago; searching again failed: %v)", stale.Text, age, err), nil
+	}
+	if t.cache != nil {
+		t.cache.put(key, result)
+	}
+	return result, nil
+}
+
+func (t *WebSearchTool) fetch(ctx context.Context, query string, count int) (string, error) {
+	if t.limiter != nil {
+		if err := t.limiter.wait(ctx, maxSearchWait); err != nil {
+			return "", fmt.Errorf("%s: %w", t.backend, err)
+		}
+	}
+	return t.provider.Search(ctx, query, count)
+}
+
+// searchCacheKey identifies a search: the same query in other words of
+// case or spacing is the same search.
+func searchCacheKey(backend, query string, count int) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	return fmt.Sprintf("%s|%d|%s", backend, count, query)
+}
+
+// rateLimiter allows at most limit requests in any window of time.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   []time.Time // Requests within the last window, oldest first
+	now    func() time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, now: time.Now}
+}
+
+// reserve records a request and returns 0 if one may be sent now, or
+// else how long until one may.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for len(l.sent) > 0 && now.Sub(l.sent[0]) >= l.window {
+		l.sent = l.sent[1:]
+	}
+	if len(l.sent) < l.limit {
+		l.sent = append(l.sent, now)
+		return 0
+	}
+	return l.sent[0].Add(l.window).Sub(now)
+}
+
+// wait blocks until a request may be sent, unless that takes longer than
+// maxWait.
+func (l *rateLimiter) wait(ctx context.Context, maxWait time.Duration) error {
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if delay > maxWait {
+			return fmt.Errorf("rate limit of %d searches a minute reached, try again in %s",
+				l.limit, delay.Round(time.Second))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// searchCache keeps search results in a JSON file, so they survive
+// restarts and can stand in when the backend is unreachable.
+type searchCache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]*searchCacheEntry // Loaded on first use
+}
+
+type searchCacheEntry struct {
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}
+
+func newSearchCache(path string, ttl time.Duration) *searchCache {
+	return &searchCache{path: path, ttl: ttl}
+}
+
+// get returns the entry for key, if any, and whether it is still fresh.
+func (c *searchCache) get(key string) (*searchCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return entry, time.Since(entry.Time) < c.ttl
+}
+
+func (c *searchCache) put(key, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	c.entries[key] = &searchCacheEntry{Text: text, Time: time.Now()}
+	if len(c.entries) > searchCacheEntries {
+		keys := make([]string, 0, len(c.entries))
+		for k := range c.entries {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].Time.Before(c.entries[keys[j]].Time) })
+		for _, k := range keys[:len(keys)-searchCacheEntries] {
+			delete(c.entries, k)
+		}
+	}
+	if err := c.save(); err != nil {
+		logger.WarnCF("tool", "Failed to save the web search cache", map[string]any{"error": err.Error()})
+	}
+}
+
+// load reads the cache file once; a missing or broken file is an empty
+// cache. Must be called with the lock held.
+func (c *searchCache) load() {
+	if c.entries != nil {
+		return
+	}
+	c.entries = make(map[string]*searchCacheEntry)
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+}
+
+// save writes the cache through a temporary file, so a crash can't leave
+// it half written. Must be called with the lock held.
+func (c *searchCache) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
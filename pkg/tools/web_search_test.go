@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSearXNGServer serves one result per query and counts the searches.
+func newSearXNGServer(t *testing.T, fail *atomic.Bool) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" {
+			t.Errorf("request = %s, want /search?format=json", r.URL)
+		}
+		if fail != nil && fail.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"results": []map[string]any{
+				{"title": "About " + r.URL.Query().Get("q"), "url": "https://example.com/a", "content": "Snippet"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestWebTool_SearXNGSearch_Success(t *testing.T) {
+	server, _ := newSearXNGServer(t, nil)
+	tool := NewWebSearchTool(WebSearchToolOptions{SearXNGEnabled: true, SearXNGBaseURL: server.URL + "/"})
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "picoclaw"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	for _, want := range []string{"via SearXNG", "1. About picoclaw", "https://example.com/a", "Snippet"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("result missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+}
+
+func TestNewWebSearchTool_Backend(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WebSearchToolOptions
+		want string // Backend chosen, or "" for none
+	}{
+		{
+			name: "priority",
+			opts: WebSearchToolOptions{
+				DuckDuckGoEnabled: true, SearXNGEnabled: true, SearXNGBaseURL: "http://searx",
+				TavilyEnabled: true, TavilyAPIKey: "k",
+			},
+			want: "tavily",
+		},
+		{
+			name: "searxng before duckduckgo",
+			opts: WebSearchToolOptions{DuckDuckGoEnabled: true, SearXNGEnabled: true, SearXNGBaseURL: "http://searx"},
+			want: "searxng",
+		},
+		{
+			name: "named backend needn't be enabled",
+			opts: WebSearchToolOptions{BraveEnabled: true, BraveAPIKey: "k", Backend: "searxng", SearXNGBaseURL: "http://s"},
+			want: "searxng",
+		},
+		{
+			name: "named backend without credentials",
+			opts: WebSearchToolOptions{DuckDuckGoEnabled: true, Backend: "brave"},
+			want: "",
+		},
+		{
+			name: "searxng without base url",
+			opts: WebSearchToolOptions{SearXNGEnabled: true},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := NewWebSearchTool(tt.opts)
+			got := ""
+			if tool != nil {
+				got = tool.backend
+			}
+			if got != tt.want {
+				t.Errorf("backend = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWebSearchTool_RateLimit(t *testing.T) {
+	tool := NewWebSearchTool(WebSearchToolOptions{DuckDuckGoEnabled: true, DuckDuckGoRequestsPerMinute: 10})
+	if tool.limiter == nil || tool.limiter.limit != 10 {
+		t.Fatalf("limiter = %+v, want a limit of 10", tool.limiter)
+	}
+	tool = NewWebSearchTool(WebSearchToolOptions{DuckDuckGoEnabled: true})
+	if tool.limiter != nil {
+		t.Errorf("limiter = %+v, want none", tool.limiter)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("first reserve() = %v, want 0", d)
+	}
+	now = now.Add(20 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("second reserve() = %v, want 0", d)
+	}
+	if d := l.reserve(); d != 40*time.Second {
+		t.Fatalf("third reserve() = %v, want 40s", d)
+	}
+	if err := l.wait(context.Background(), 10*time.Second); err == nil ||
+		!strings.Contains(err.Error(), "2 searches a minute") {
+		t.Fatalf("wait() = %v, want the rate limit error", err)
+	}
+
+	now = now.Add(40 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("reserve() after the window = %v, want 0", d)
+	}
+}
+
+func TestWebSearchTool_Cache(t *testing.T) {
+	var fail atomic.Bool
+	server, calls := newSearXNGServer(t, &fail)
+	dir := t.TempDir()
+	opts := WebSearchToolOptions{
+		SearXNGEnabled: true,
+		SearXNGBaseURL: server.URL,
+		CacheDir:       dir,
+		CacheTTL:       time.Hour,
+	}
+	tool := NewWebSearchTool(opts)
+	ctx := context.Background()
+
+	first := tool.Execute(ctx, map[string]any{"query": "Go  generics"})
+	if first.IsError {
+		t.Fatalf("Execute() error: %s", first.ForLLM)
+	}
+	// The same query, differently spaced and cased, by a new tool reading
+	// the cache file.
+	again := NewWebSearchTool(opts).Execute(ctx, map[string]any{"query": "go generics "})
+	if again.ForLLM != first.ForLLM || calls.Load() != 1 {
+		t.Fatalf("cached search = %q after %d requests, want %q after 1", again.ForLLM, calls.Load(), first.ForLLM)
+	}
+
+	// Outdated, the entry is searched again, and stands in when that fails.
+	tool.cache.mu.Lock()
+	for _, e := range tool.cache.entries {
+		e.Time = time.Now().Add(-2 * time.Hour)
+	}
+	tool.cache.mu.Unlock()
+	fail.Store(true)
+	stale := tool.Execute(ctx, map[string]any{"query": "go generics"})
+	if calls.Load() != 2 {
+		t.Errorf("requests = %d, want 2", calls.Load())
+	}
+	if stale.IsError || !strings.HasPrefix(stale.ForLLM, first.ForLLM) ||
+		!strings.Contains(stale.ForLLM, "Cached results from 2h0m0s ago") {
+		t.Errorf("stale fallback = %q", stale.ForLLM)
+	}
+
+	// Without a cached entry the failure is reported.
+	if result := tool.Execute(ctx, map[string]any{"query": "other"}); !result.IsError {
+		t.Errorf("uncached failed search = %q, want an error", result.ForLLM)
+	}
+}
+
+func TestSearchCache_Cap(t *testing.T) {
+	c := newSearchCache(t.TempDir()+"/cache.json", time.Hour)
+	for i := 0; i <= searchCacheEntries; i++ {
+		c.put(searchCacheKey("b", strings.Repeat("q", i+1), 5), "text")
+	}
+	if len(c.entries) != searchCacheEntries {
+		t.Fatalf("entries = %d, want %d", len(c.entries), searchCacheEntries)
+	}
+	if _, ok := c.entries[searchCacheKey("b", "q", 5)]; ok {
+		t.Errorf("the oldest entry was kept")
+	}
+}
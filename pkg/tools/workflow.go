@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/workflows"
+)
+
+// WorkflowTool runs the workflows saved in the workspace: fixed sequences of
+// tool calls and prompts (see package workflows). Its tool steps use the
+// agent's tools, under the approver of the call that started the run.
+type WorkflowTool struct {
+	dir        string
+	registry   *ToolRegistry
+	provider   providers.LLMProvider
+	model      string
+	llmOptions map[string]any
+
+	mu      sync.RWMutex
+	channel string
+	chatID  string
+}
+
+// NewWorkflowTool creates a WorkflowTool for the workflows in workspace,
+// running tool steps with registry and prompt steps with provider and model.
+func NewWorkflowTool(
+	workspace string,
+	registry *ToolRegistry,
+	provider providers.LLMProvider,
+	model string,
+	llmOptions map[string]any,
+) *WorkflowTool {
+	return &WorkflowTool{
+		dir:        filepath.Join(workspace, workflows.Dir),
+		registry:   registry,
+		provider:   provider,
+		model:      model,
+		llmOptions: llmOptions,
+		channel:    "cli",
+		chatID:     "direct",
+	}
+}
+
+func (t *WorkflowTool) Name() string {
+	return "workflow"
+}
+
+func (t *WorkflowTool) Description() string {
+	return "Run one of the user's saved workflows by name: a fixed sequence of tool calls and prompts " +
+		"they defined for a repetitive task. Use it when they ask for one of their workflows or routines " +
+		"instead of doing the steps yourself. action=list shows the workflows and their inputs."
+}
+
+func (t *WorkflowTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"run", "list"},
+				"description": "run a workflow, or list them",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Workflow name (for run)",
+			},
+			"inputs": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Values of the workflow's inputs (for run)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *WorkflowTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+func (t *WorkflowTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "list":
+		return SilentResult(t.list())
+	case "run":
+		name, _ := args["name"].(string)
+		if name == "" {
+			return ErrorResult("name is required for run")
+		}
+		inputs := make(map[string]string)
+		if raw, ok := args["inputs"].(map[string]any); ok {
+			for k, v := range raw {
+				inputs[k] = fmt.Sprint(v)
+			}
+		}
+		return t.run(ctx, name, inputs)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+func (t *WorkflowTool) list() string {
+	list, errs := workflows.List(t.dir)
+	if len(list) == 0 && len(errs) == 0 {
+		return fmt.Sprintf("No workflows yet. Save them as YAML files in %s.", t.dir)
+	}
+	var sb strings.Builder
+	sb.WriteString("Workflows:\n")
+	for _, wf := range list {
+		fmt.Fprintf(&sb, "- %s", wf.Usage())
+		if wf.Description != "" {
+			fmt.Fprintf(&sb, ": %s", wf.Description)
+		}
+		sb.WriteString("\n")
+	}
+	for _, err := range errs {
+		fmt.Fprintf(&sb, "- (broken) %v\n", err)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (t *WorkflowTool) run(ctx context.Context, name string, inputs map[string]string) *ToolResult {
+	wf, err := workflows.Load(t.dir, name)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	t.mu.RLock()
+	exec := &workflowExecutor{tool: t, channel: t.channel, chatID: t.chatID}
+	t.mu.RUnlock()
+
+	output, steps, err := workflows.Run(ctx, wf, inputs, exec)
+	var skipped int
+	for _, s := range steps {
+		if s.Skipped {
+			skipped++
+		}
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Workflow %s stopped after %d of %d steps: %v",
+			name, len(steps), len(wf.Steps), err)).WithError(err)
+	}
+	summary := fmt.Sprintf("Workflow %s finished (%d steps", name, len(steps))
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	summary += ")."
+	if output != "" {
+		summary += "\n\n" + output
+	}
+	return NewToolResult(summary)
+}
+
+// workflowExecutor runs workflow steps for the chat the workflow was started
+// from.
+type workflowExecutor struct {
+	tool    *WorkflowTool
+	channel string
+	chatID  string
+}
+
+func (e *workflowExecutor) CallTool(ctx context.Context, name string, args map[string]any) (string, error) {
+	result := e.tool.registry.ExecuteWithContext(ctx, name, args, e.channel, e.chatID, nil)
+	if result.IsError {
+		return "", errors.New(result.ForLLM)
+	}
+	return result.ForLLM, nil
+}
+
+func (e *workflowExecutor) Prompt(ctx context.Context, prompt string) (string, error) {
+	resp, err := e.tool.provider.Chat(ctx, []providers.Message{{Role: "user", Content: prompt}},
+		nil, e.tool.model, e.tool.llmOptions)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/workflows"
+)
+
+func newWorkflowTestTool(t *testing.T, defs map[string]string) (*WorkflowTool, *ToolRegistry) {
+	t.Helper()
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, workflows.Dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range defs {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(def), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := NewToolRegistry()
+	fetch := newMockTool("web_fetch", "fetch")
+	fetch.result = NewToolResult("22°C and sunny")
+	registry.Register(fetch)
+	provider := &scriptedProvider{responses: []*providers.LLMResponse{{Content: " A warm day. "}}}
+	tool := NewWorkflowTool(workspace, registry, provider, "test-model", nil)
+	registry.Register(tool)
+	return tool, registry
+}
+
+const weatherWorkflow = `
+description: Today's weather
+inputs:
+  city: Paris
+steps:
+  - name: weather
+    tool: web_fetch
+    args: {url: "https://wttr.in/{{city}}"}
+  - name: summary
+    prompt: "Summarize {{weather}}"
+output: "{{city}}: {{summary}}"
+`
+
+func TestWorkflowTool_Run(t *testing.T) {
+	tool, _ := newWorkflowTestTool(t, map[string]string{"weather": weatherWorkflow})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "run",
+		"name":   "weather",
+		"inputs": map[string]any{"city": "Oslo"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "Workflow weather finished (2 steps)") ||
+		!strings.HasSuffix(result.ForLLM, "Oslo: A warm day.") {
+		t.Errorf("result = %q", result.ForLLM)
+	}
+}
+
+func TestWorkflowTool_List(t *testing.T) {
+	tool, _ := newWorkflowTestTool(t, map[string]string{"weather": weatherWorkflow, "broken": "steps: []"})
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "list"})
+	if !strings.Contains(result.ForLLM, "- weather [city=Paris]: Today's weather") ||
+		!strings.Contains(result.ForLLM, "(broken) workflow broken: no steps") {
+		t.Errorf("list = %q", result.ForLLM)
+	}
+
+	empty := NewWorkflowTool(t.TempDir(), NewToolRegistry(), nil, "", nil)
+	if result := empty.Execute(context.Background(), map[string]any{"action": "list"}); !strings.Contains(
+		result.ForLLM, "No workflows yet") {
+		t.Errorf("empty list = %q", result.ForLLM)
+	}
+}
+
+func TestWorkflowTool_StepsAreApproved(t *testing.T) {
+	_, registry := newWorkflowTestTool(t, map[string]string{"weather": weatherWorkflow})
+	var asked []string
+	ctx := WithApprover(context.Background(), func(_ context.Context, name string, _ map[string]any) error {
+		asked = append(asked, name)
+		if name == "web_fetch" {
+			return errors.New("web_fetch is not allowed")
+		}
+		return nil
+	})
+
+	result := registry.ExecuteWithContext(ctx, "workflow",
+		map[string]any{"action": "run", "name": "weather"}, "telegram", "42", nil)
+	if !result.IsError || !strings.Contains(result.ForLLM, "stopped after 1 of 2 steps") ||
+		!strings.Contains(result.ForLLM, "not allowed") {
+		t.Errorf("result = %q, want the denied step to stop the run", result.ForLLM)
+	}
+	if strings.Join(asked, ",") != "workflow,web_fetch" {
+		t.Errorf("approver asked about %v", asked)
+	}
+}
+
+func TestWorkflowTool_Errors(t *testing.T) {
+	tool, _ := newWorkflowTestTool(t, nil)
+	for _, args := range []map[string]any{
+		{"action": "run"},
+		{"action": "run", "name": "missing"},
+		{"action": "delete"},
+	} {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Execute(%v) = %q, want an error", args, result.ForLLM)
+		}
+	}
+}
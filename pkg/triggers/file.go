@@ -0,0 +1,123 @@
+package triggers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// fileSettle is how long a new file must go unwritten before it fires, so
+// a file still being copied in isn't reported half-written.
+const fileSettle = time.Second
+
+// fileSource fires for files appearing in a directory.
+type fileSource struct {
+	dir     string
+	pattern string // Glob the file names must match; empty matches all
+	settle  time.Duration
+}
+
+func newFileSource(dir, pattern string) *fileSource {
+	return &fileSource{dir: dir, pattern: pattern, settle: fileSettle}
+}
+
+func (f *fileSource) start(ctx context.Context, emit func(from, payload string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(f.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watch %s: %w", f.dir, err)
+	}
+	go f.run(ctx, watcher, emit)
+	return nil
+}
+
+func (f *fileSource) run(ctx context.Context, watcher *fsnotify.Watcher, emit func(from, payload string)) {
+	defer watcher.Close()
+	ticker := time.NewTicker(f.settle / 4)
+	defer ticker.Stop()
+
+	pending := make(map[string]time.Time) // New files by the time they were last written
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			switch {
+			case ev.Has(fsnotify.Create):
+				if f.matches(ev.Name) {
+					pending[ev.Name] = time.Now()
+				}
+			case ev.Has(fsnotify.Write):
+				if _, ok := pending[ev.Name]; ok {
+					pending[ev.Name] = time.Now()
+				}
+			case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+				delete(pending, ev.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WarnCF("triggers", "File watch error", map[string]any{"dir": f.dir, "error": err.Error()})
+		case now := <-ticker.C:
+			for path, written := range pending {
+				if now.Sub(written) < f.settle {
+					continue
+				}
+				delete(pending, path)
+				if payload, ok := describeFile(path); ok {
+					emit(path, payload)
+				}
+			}
+		}
+	}
+}
+
+func (f *fileSource) matches(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return false // Hidden and editor temp files
+	}
+	if f.pattern == "" {
+		return true
+	}
+	ok, _ := filepath.Match(f.pattern, name)
+	return ok
+}
+
+// describeFile returns the payload for a new file: its path and size, and
+// its content when it's short text. ok is false for directories and files
+// gone already.
+func describeFile(path string) (payload string, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	header := fmt.Sprintf("New file: %s (%d bytes)", path, info.Size())
+	if info.Size() > maxPayload {
+		return header + "\nIt's too large to include here.", true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return header, true
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return header + "\nIt isn't a text file.", true
+	}
+	return header + "\n\n" + string(data), true
+}
@@ -0,0 +1,50 @@
+package triggers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSource_NewMatchingFile(t *testing.T) {
+	dir := t.TempDir()
+	src := newFileSource(dir, "*.csv")
+	src.settle = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fired := make(chan string, 10)
+	if err := src.start(ctx, func(from, payload string) { fired <- payload }); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".report.csv.swp"), []byte("ignored"), 0o644)
+	os.WriteFile(filepath.Join(dir, "report.csv"), []byte("day,total\nmon,3\n"), 0o644)
+
+	select {
+	case payload := <-fired:
+		if !strings.Contains(payload, "report.csv (16 bytes)") || !strings.HasSuffix(payload, "mon,3\n") {
+			t.Errorf("payload = %q", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the new file")
+	}
+	select {
+	case payload := <-fired:
+		t.Errorf("unexpected event %q", payload)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestDescribeFile_Binary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	os.WriteFile(path, []byte{0xff, 0xd8, 0x00, 0x10}, 0o644)
+	payload, ok := describeFile(path)
+	if !ok || !strings.Contains(payload, "isn't a text file") {
+		t.Errorf("describeFile = %q, %v", payload, ok)
+	}
+}
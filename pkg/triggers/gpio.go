@@ -0,0 +1,71 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/gpio"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// gpioPoll is how often a pin is read. It also debounces: a bounce shorter
+// than this is missed.
+const gpioPoll = 20 * time.Millisecond
+
+// gpioSource fires on edges of an input pin.
+type gpioSource struct {
+	pin  int
+	edge string // rising, falling or both (empty)
+	poll time.Duration
+}
+
+func newGPIOSource(pin int, edge string) *gpioSource {
+	return &gpioSource{pin: pin, edge: edge, poll: gpioPoll}
+}
+
+func (g *gpioSource) start(ctx context.Context, emit func(from, payload string)) error {
+	if err := gpio.Export(g.pin, "in"); err != nil {
+		return err
+	}
+	valuePath := gpio.ValuePath(g.pin)
+	value, err := gpio.ReadFile(valuePath)
+	if err != nil {
+		return fmt.Errorf("read GPIO %d: %w", g.pin, err)
+	}
+	go g.run(ctx, valuePath, value, emit)
+	return nil
+}
+
+func (g *gpioSource) run(ctx context.Context, valuePath string, value int, emit func(from, payload string)) {
+	ticker := time.NewTicker(g.poll)
+	defer ticker.Stop()
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := gpio.ReadFile(valuePath)
+		if err != nil {
+			if !failing {
+				logger.WarnCF("triggers", "Can't read GPIO", map[string]any{"pin": g.pin, "error": err.Error()})
+			}
+			failing = true
+			continue
+		}
+		failing = false
+		if next == value {
+			continue
+		}
+		value = next
+		edge := "falling"
+		if value == 1 {
+			edge = "rising"
+		}
+		if g.edge == "" || g.edge == "both" || g.edge == edge {
+			emit(fmt.Sprintf("GPIO %d", g.pin), fmt.Sprintf("GPIO %d: %s edge, the pin is now %d", g.pin, edge, value))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package triggers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/gpio"
+)
+
+func TestGPIOSource_Edges(t *testing.T) {
+	gpio.Root = t.TempDir()
+	defer func() { gpio.Root = "/sys/class/gpio" }()
+	pinDir := filepath.Join(gpio.Root, "gpio17")
+	os.MkdirAll(pinDir, 0o755)
+	value := filepath.Join(pinDir, "value")
+	os.WriteFile(value, []byte("0\n"), 0o644)
+
+	src := newGPIOSource(17, "rising")
+	src.poll = 5 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fired := make(chan string, 10)
+	if err := src.start(ctx, func(from, payload string) { fired <- payload }); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"1\n", "0\n", "1\n"} {
+		os.WriteFile(value, []byte(v), 0o644)
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+
+	if len(fired) != 2 {
+		t.Fatalf("events = %d, want 2 rising edges", len(fired))
+	}
+	if got := <-fired; got != "GPIO 17: rising edge, the pin is now 1" {
+		t.Errorf("payload = %q", got)
+	}
+}
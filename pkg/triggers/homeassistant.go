@@ -0,0 +1,113 @@
+package triggers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const haMaxBackoff = 5 * time.Minute
+
+// haSource fires for the events of a Home Assistant instance, over its
+// WebSocket API, and reconnects when the connection drops. The instance and
+// its entity allowlist are those of the homeassistant tool.
+type haSource struct {
+	client   *homeassistant.Client // Set by Service.SetHomeAssistant
+	events   []string              // Event types; default: state_changed
+	entities []string              // Globs of the entities that fire; default: all allowed
+}
+
+func newHASource(events, entities []string) *haSource {
+	if len(events) == 0 {
+		events = []string{"state_changed"}
+	}
+	return &haSource{events: events, entities: entities}
+}
+
+func (h *haSource) start(ctx context.Context, emit func(from, payload string)) error {
+	if h.client == nil {
+		return errors.New("home assistant is not configured in tools.homeassistant")
+	}
+	go h.run(ctx, emit)
+	return nil
+}
+
+func (h *haSource) run(ctx context.Context, emit func(from, payload string)) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := h.client.Subscribe(ctx, h.events, func(ev homeassistant.Event) {
+			if from, payload, ok := h.describe(&ev); ok {
+				emit(from, payload)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > haMaxBackoff {
+			backoff = time.Second
+		}
+		logger.WarnCF("triggers", "Home Assistant connection lost, reconnecting",
+			map[string]any{"error": err.Error(), "retry_in": backoff.String()})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, haMaxBackoff)
+	}
+}
+
+// describe returns what fired an event and its payload, or false for an
+// event about other entities, or a state change of attributes only, such as
+// a sensor's reading time.
+func (h *haSource) describe(ev *homeassistant.Event) (from, payload string, ok bool) {
+	ids := ev.EntityIDs()
+	if len(h.entities) > 0 && !h.matches(ids) {
+		return "", "", false
+	}
+	from = ev.Type
+	if len(ids) > 0 {
+		from = strings.Join(ids, ", ")
+	}
+
+	if ev.Type == "state_changed" {
+		before, after := ev.States()
+		switch {
+		case after == nil && before == nil:
+			return "", "", false
+		case after == nil:
+			return from, fmt.Sprintf("%s (%s) was removed", before.Name(), before.EntityID), true
+		case before == nil:
+			return from, fmt.Sprintf("%s (%s) was added: %s", after.Name(), after.EntityID, after.State), true
+		case before.State == after.State:
+			return "", "", false
+		}
+		return from, fmt.Sprintf("%s (%s) changed from %s to %s", after.Name(), after.EntityID, before.State,
+			after.State), true
+	}
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return "", "", false
+	}
+	return from, fmt.Sprintf("Home Assistant event %s: %s", ev.Type, data), true
+}
+
+// matches reports whether one of ids matches the source's entity globs.
+func (h *haSource) matches(ids []string) bool {
+	for _, id := range ids {
+		for _, pattern := range h.entities {
+			if ok, _ := path.Match(pattern, id); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
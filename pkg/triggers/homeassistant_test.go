@@ -0,0 +1,61 @@
+package triggers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+)
+
+func haStateChange(id, before, after string) homeassistant.Event {
+	state := func(s string) any {
+		if s == "" {
+			return nil
+		}
+		return map[string]any{
+			"entity_id":  id,
+			"state":      s,
+			"attributes": map[string]any{"friendly_name": "Front door"},
+		}
+	}
+	return homeassistant.Event{Type: "state_changed", Data: map[string]any{
+		"entity_id": id,
+		"old_state": state(before),
+		"new_state": state(after),
+	}}
+}
+
+func TestHASource_Describe(t *testing.T) {
+	src := newHASource(nil, []string{"binary_sensor.*_door"})
+	tests := []struct {
+		name    string
+		event   homeassistant.Event
+		payload string // Empty when the event doesn't fire
+	}{
+		{"state change", haStateChange("binary_sensor.front_door", "off", "on"),
+			"Front door (binary_sensor.front_door) changed from off to on"},
+		{"attributes only", haStateChange("binary_sensor.front_door", "on", "on"), ""},
+		{"added", haStateChange("binary_sensor.front_door", "", "off"),
+			"Front door (binary_sensor.front_door) was added: off"},
+		{"other entity", haStateChange("light.kitchen", "off", "on"), ""},
+		{"other event", homeassistant.Event{Type: "call_service", Data: map[string]any{
+			"service_data": map[string]any{"entity_id": "binary_sensor.back_door"},
+		}}, `Home Assistant event call_service: {"service_data":{"entity_id":"binary_sensor.back_door"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, payload, ok := src.describe(&tt.event)
+			if payload != tt.payload || ok != (tt.payload != "") {
+				t.Errorf("describe() = %q, %v, want %q", payload, ok, tt.payload)
+			}
+		})
+	}
+	if src.events[0] != "state_changed" {
+		t.Errorf("default events = %v", src.events)
+	}
+}
+
+func TestHASource_NeedsClient(t *testing.T) {
+	if err := newHASource(nil, nil).start(t.Context(), func(string, string) {}); err == nil {
+		t.Error("start() without a client succeeded")
+	}
+}
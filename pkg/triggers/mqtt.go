@@ -0,0 +1,65 @@
+package triggers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+)
+
+const mqttMaxBackoff = 5 * time.Minute
+
+// mqttSource fires for the messages published on a topic, and reconnects
+// when the connection drops. Retained messages, which the broker sends on
+// each connection, are old news and don't fire.
+type mqttSource struct {
+	opts  mqtt.Options
+	topic string
+}
+
+func newMQTTSource(name string, opts mqtt.Options, topic string) *mqttSource {
+	opts.ClientID = "picoclaw-" + name + "-" + uuid.NewString()[:8]
+	return &mqttSource{opts: opts, topic: topic}
+}
+
+func (m *mqttSource) start(ctx context.Context, emit func(from, payload string)) error {
+	if m.opts.Broker == "" {
+		return errors.New("no broker; set one, or that of tools.mqtt")
+	}
+	if err := mqtt.CheckBroker(m.opts.Broker); err != nil {
+		return err
+	}
+	go m.run(ctx, emit)
+	return nil
+}
+
+func (m *mqttSource) run(ctx context.Context, emit func(from, payload string)) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		logger.InfoCF("triggers", "MQTT subscribing", map[string]any{"broker": m.opts.Broker, "topic": m.topic})
+		err := mqtt.Subscribe(ctx, m.opts, []string{m.topic}, func(msg mqtt.Message) {
+			if !msg.Retained {
+				emit(msg.Topic, string(msg.Payload))
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > mqttMaxBackoff {
+			backoff = time.Second
+		}
+		logger.WarnCF("triggers", "MQTT connection lost, reconnecting",
+			map[string]any{"broker": m.opts.Broker, "error": err.Error(), "retry_in": backoff.String()})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, mqttMaxBackoff)
+	}
+}
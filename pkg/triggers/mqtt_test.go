@@ -0,0 +1,34 @@
+package triggers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+)
+
+func TestMQTTSource_Broker(t *testing.T) {
+	handler, _ := collect()
+	svc := NewService([]config.TriggerConfig{
+		{Name: "door", Type: config.TriggerMQTT, Topic: "home/door/#"},
+		{Name: "own", Type: config.TriggerMQTT, Broker: "tcp://10.0.0.2:1883", Topic: "x"},
+	}, nil, handler)
+
+	door := svc.triggers["door"].src.(*mqttSource)
+	if err := door.start(t.Context(), func(string, string) {}); err == nil {
+		t.Error("start() without a broker succeeded")
+	}
+	svc.SetMQTT(mqtt.Options{Broker: "tls://broker.example.com:8883", Username: "pico", CAFile: "/etc/ca.pem"})
+	if door.opts.Broker != "tls://broker.example.com:8883" || door.opts.Username != "pico" ||
+		door.opts.CAFile != "/etc/ca.pem" {
+		t.Errorf("options after SetMQTT() = %+v", door.opts)
+	}
+	if own := svc.triggers["own"].src.(*mqttSource); own.opts.Broker != "tcp://10.0.0.2:1883" {
+		t.Errorf("SetMQTT() changed a trigger's own broker to %q", own.opts.Broker)
+	}
+
+	bad := newMQTTSource("bad", mqtt.Options{Broker: "http://broker"}, "x")
+	if err := bad.start(t.Context(), func(string, string) {}); err == nil {
+		t.Error("start() with an http broker succeeded")
+	}
+}
@@ -0,0 +1,258 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package triggers starts agent runs from outside events: a new file in a
+// watched directory, a webhook call, an MQTT message, a GPIO edge or a Home
+// Assistant event.
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/homeassistant"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mqtt"
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// maxPayload bounds the payload of an event added to the user message, in
+// characters.
+const maxPayload = 16 * 1024
+
+// queueSize bounds the events waiting for the run of an earlier one; more
+// are dropped.
+const queueSize = 16
+
+// Event is one firing of a trigger.
+type Event struct {
+	Trigger string // Name of the trigger
+	Type    string // file, webhook, mqtt, gpio or homeassistant
+	Source  string // What fired it: the file, caller address, topic, pin or entity
+	Payload string
+	Prompt  string // The trigger's instructions, if any
+
+	// The chat the run answers in
+	Channel string
+	ChatID  string
+}
+
+// Message returns the user message of the run ev starts.
+func (ev Event) Message() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Trigger %s (%s): %s]\n", ev.Trigger, ev.Type, ev.Source)
+	if ev.Prompt != "" {
+		sb.WriteString(ev.Prompt)
+		sb.WriteString("\n")
+	}
+	if ev.Payload != "" {
+		sb.WriteString("\n")
+		sb.WriteString(ev.Payload)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Handler runs the agent for an event. Events are handled one at a time.
+type Handler func(ctx context.Context, ev Event)
+
+// source watches for the events of one trigger and reports them with emit
+// until ctx is done.
+type source interface {
+	start(ctx context.Context, emit func(from, payload string)) error
+}
+
+type trigger struct {
+	cfg config.TriggerConfig
+	src source // nil for webhooks, which are fired by ServeHTTP
+
+	mu       sync.Mutex
+	lastFire time.Time
+}
+
+// Service runs the configured triggers and hands their events to a Handler.
+type Service struct {
+	triggers map[string]*trigger
+	state    *state.Manager
+	handler  Handler
+	events   chan Event
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewService creates a Service for cfgs. Triggers of unknown types are
+// logged and left out. stateMgr gives the chat used last, for triggers
+// that don't name one.
+func NewService(cfgs []config.TriggerConfig, stateMgr *state.Manager, handler Handler) *Service {
+	s := &Service{
+		triggers: make(map[string]*trigger, len(cfgs)),
+		state:    stateMgr,
+		handler:  handler,
+		events:   make(chan Event, queueSize),
+	}
+	for _, cfg := range cfgs {
+		t := &trigger{cfg: cfg}
+		switch cfg.Type {
+		case config.TriggerFile:
+			t.src = newFileSource(cfg.Path, cfg.Pattern)
+		case config.TriggerWebhook:
+		case config.TriggerMQTT:
+			t.src = newMQTTSource(cfg.Name, mqtt.Options{
+				Broker:   cfg.Broker,
+				Username: cfg.Username,
+				Password: cfg.Password,
+				CAFile:   cfg.CAFile,
+				CertFile: cfg.CertFile,
+				KeyFile:  cfg.KeyFile,
+			}, cfg.Topic)
+		case config.TriggerGPIO:
+			t.src = newGPIOSource(cfg.Pin, cfg.Edge)
+		case config.TriggerHomeAssistant:
+			t.src = newHASource(cfg.Events, cfg.Entities)
+		default:
+			logger.WarnCF("triggers", "Skipping trigger of unknown type",
+				map[string]any{"trigger": cfg.Name, "type": cfg.Type})
+			continue
+		}
+		s.triggers[cfg.Name] = t
+	}
+	return s
+}
+
+// SetMQTT sets the broker of mqtt triggers that name none, before Start.
+func (s *Service) SetMQTT(opts mqtt.Options) {
+	for _, t := range s.triggers {
+		if src, ok := t.src.(*mqttSource); ok && src.opts.Broker == "" {
+			src.opts.Broker, src.opts.Username, src.opts.Password = opts.Broker, opts.Username, opts.Password
+			src.opts.CAFile, src.opts.CertFile, src.opts.KeyFile = opts.CAFile, opts.CertFile, opts.KeyFile
+		}
+	}
+}
+
+// SetHomeAssistant sets the instance homeassistant triggers watch, before
+// Start. Without one, they fail to start.
+func (s *Service) SetHomeAssistant(client *homeassistant.Client) {
+	for _, t := range s.triggers {
+		if src, ok := t.src.(*haSource); ok {
+			src.client = client
+		}
+	}
+}
+
+// Start starts watching for events. Triggers that fail to start are logged
+// and skipped.
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil || len(s.triggers) == 0 {
+		return nil
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+
+	for name, t := range s.triggers {
+		if t.src == nil {
+			continue
+		}
+		emit := func(from, payload string) { s.fire(name, from, payload) }
+		if err := t.src.start(ctx, emit); err != nil {
+			logger.ErrorCF("triggers", "Failed to start trigger",
+				map[string]any{"trigger": name, "type": t.cfg.Type, "error": err.Error()})
+			continue
+		}
+		logger.InfoCF("triggers", "Trigger started", map[string]any{"trigger": name, "type": t.cfg.Type})
+	}
+	return nil
+}
+
+// Stop stops watching and waits for the run in progress, if any.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (s *Service) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-s.events:
+			logger.InfoCF("triggers", "Trigger fired",
+				map[string]any{"trigger": ev.Trigger, "source": ev.Source})
+			s.handler(ctx, ev)
+		}
+	}
+}
+
+// fire queues an event of trigger name and reports whether it was
+// accepted: it isn't within the trigger's cooldown or over the queue's
+// bound.
+func (s *Service) fire(name, from, payload string) bool {
+	t := s.triggers[name]
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	cooldown := time.Duration(t.cfg.CooldownSeconds) * time.Second
+	if !t.lastFire.IsZero() && now.Sub(t.lastFire) < cooldown {
+		t.mu.Unlock()
+		logger.DebugCF("triggers", "Event dropped during cooldown", map[string]any{"trigger": name})
+		return false
+	}
+	t.lastFire = now
+	t.mu.Unlock()
+
+	ev := Event{
+		Trigger: name,
+		Type:    t.cfg.Type,
+		Source:  from,
+		Payload: utils.Truncate(payload, maxPayload),
+		Prompt:  t.cfg.Prompt,
+		Channel: t.cfg.Channel,
+		ChatID:  t.cfg.ChatID,
+	}
+	if ev.Channel == "" || ev.ChatID == "" {
+		ev.Channel, ev.ChatID = s.lastChat()
+	}
+
+	select {
+	case s.events <- ev:
+		return true
+	default:
+		logger.WarnCF("triggers", "Event dropped, too many waiting", map[string]any{"trigger": name})
+		return false
+	}
+}
+
+// lastChat returns the chat used last, or cli:direct.
+func (s *Service) lastChat() (channel, chatID string) {
+	if s.state != nil {
+		channel, chatID, ok := strings.Cut(s.state.GetLastChannel(), ":")
+		if ok && channel != "" && chatID != "" && !constants.IsInternalChannel(channel) {
+			return channel, chatID
+		}
+	}
+	return "cli", "direct"
+}
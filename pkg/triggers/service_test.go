@@ -0,0 +1,91 @@
+package triggers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// collect returns a handler that sends the events it gets to the returned
+// channel.
+func collect() (Handler, chan Event) {
+	events := make(chan Event, 10)
+	return func(_ context.Context, ev Event) { events <- ev }, events
+}
+
+func nextEvent(t *testing.T, events chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return Event{}
+	}
+}
+
+func TestEvent_Message(t *testing.T) {
+	ev := Event{Trigger: "door", Type: "mqtt", Source: "home/door", Payload: `{"open":true}`, Prompt: "Tell me if it's open."}
+	want := "[Trigger door (mqtt): home/door]\nTell me if it's open.\n\n{\"open\":true}"
+	if got := ev.Message(); got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+func TestService_Webhook(t *testing.T) {
+	handler, events := collect()
+	s := NewService([]config.TriggerConfig{
+		{Name: "deploy", Type: config.TriggerWebhook, Secret: "s3cret", Channel: "telegram", ChatID: "42", CooldownSeconds: 60},
+		{Name: "inbox", Type: config.TriggerFile, Path: t.TempDir()},
+	}, nil, handler)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	post := func(path, secret string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("build 118 failed"))
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post("/triggers/deploy", "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status %d", code)
+	}
+	if code := post("/triggers/inbox", "s3cret"); code != http.StatusNotFound {
+		t.Errorf("file trigger: status %d", code)
+	}
+	if code := post("/triggers/deploy", "s3cret"); code != http.StatusAccepted {
+		t.Fatalf("status %d", code)
+	}
+	ev := nextEvent(t, events)
+	if ev.Trigger != "deploy" || ev.Payload != "build 118 failed" || ev.Channel != "telegram" || ev.ChatID != "42" {
+		t.Errorf("event = %+v", ev)
+	}
+
+	// A second call within the cooldown is turned away.
+	if code := post("/triggers/deploy", "s3cret"); code != http.StatusTooManyRequests {
+		t.Errorf("call during cooldown: status %d", code)
+	}
+}
+
+func TestService_DefaultsToLastChat(t *testing.T) {
+	handler, events := collect()
+	s := NewService([]config.TriggerConfig{{Name: "hook", Type: config.TriggerWebhook, Secret: "x"}}, nil, handler)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	s.fire("hook", "test", "hi")
+	if ev := nextEvent(t, events); ev.Channel != "cli" || ev.ChatID != "direct" {
+		t.Errorf("event without a chat = %+v", ev)
+	}
+}
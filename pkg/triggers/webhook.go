@@ -0,0 +1,53 @@
+package triggers
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// WebhookPath is where the gateway serves webhook triggers, followed by
+// the trigger's name.
+const WebhookPath = "/triggers/"
+
+// maxWebhookBody bounds the body of a webhook call, in bytes.
+const maxWebhookBody = 64 * 1024
+
+// ServeHTTP fires the webhook trigger named by the path with the request
+// body as the payload. Callers authenticate with the trigger's secret, as
+// a bearer token or the X-Trigger-Secret header.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, WebhookPath)
+	t := s.triggers[name]
+	if t == nil || t.cfg.Type != config.TriggerWebhook {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := r.Header.Get("X-Trigger-Secret")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		secret = bearer
+	}
+	if t.cfg.Secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(t.cfg.Secret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !s.fire(name, "webhook from "+r.RemoteAddr, string(body)) {
+		http.Error(w, "busy, try again later", http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,76 @@
+package usage
+
+import "strings"
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Cost returns the estimated cost in USD of a call.
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6
+}
+
+// defaultPrices holds list prices for common models, keyed by normalized
+// model ID prefix. Prices change; model_list entries can override them with
+// a "pricing" block.
+var defaultPrices = map[string]Price{
+	"gpt-4o":       {Input: 2.50, Output: 10},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4-1":      {Input: 2, Output: 8},
+	"gpt-4-1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4-1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-5":        {Input: 1.25, Output: 10},
+	"gpt-5-mini":   {Input: 0.25, Output: 2},
+	"gpt-5-nano":   {Input: 0.05, Output: 0.40},
+	"o3":           {Input: 2, Output: 8},
+	"o4-mini":      {Input: 1.10, Output: 4.40},
+
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4-6":   {Input: 5, Output: 25},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-haiku-4":    {Input: 1, Output: 5},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+
+	"gemini-2-5-pro":        {Input: 1.25, Output: 10},
+	"gemini-2-5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2-5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2-0-flash":      {Input: 0.10, Output: 0.40},
+
+	"deepseek-chat":     {Input: 0.27, Output: 1.10},
+	"deepseek-reasoner": {Input: 0.55, Output: 2.19},
+}
+
+// LookupPrice finds the list price of a model. The model may carry protocol
+// or vendor prefixes ("openrouter/anthropic/claude-sonnet-4.6") and dated or
+// versioned suffixes; the longest matching table entry wins.
+func LookupPrice(model string) (Price, bool) {
+	id := normalizeModel(model)
+
+	best := ""
+	for prefix := range defaultPrices {
+		if len(prefix) <= len(best) {
+			continue
+		}
+		if id == prefix || strings.HasPrefix(id, prefix+"-") {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return defaultPrices[best], true
+}
+
+// normalizeModel strips prefixes up to the last "/" and lowercases the ID,
+// treating "." as "-" so "claude-sonnet-4.6" and "claude-sonnet-4-6" match.
+func normalizeModel(model string) string {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(model)), ".", "-")
+}
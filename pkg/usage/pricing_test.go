@@ -0,0 +1,38 @@
+package usage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		model string
+		want  Price
+		ok    bool
+	}{
+		{"gpt-4o", defaultPrices["gpt-4o"], true},
+		{"openai/gpt-4o-mini-2024-07-18", defaultPrices["gpt-4o-mini"], true},
+		{"anthropic/claude-sonnet-4.6", defaultPrices["claude-sonnet-4"], true},
+		{"openrouter/anthropic/claude-opus-4.5", defaultPrices["claude-opus-4-5"], true},
+		{"gemini/gemini-2.5-flash-lite", defaultPrices["gemini-2-5-flash-lite"], true},
+		{"ollama/llama3", Price{}, false},
+		{"gpt-4oo", Price{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := LookupPrice(tt.model)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("LookupPrice(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	p := Price{Input: 3, Output: 15}
+	if got := p.Cost(1_000_000, 100_000); math.Abs(got-4.5) > 1e-9 {
+		t.Errorf("Cost() = %v, want 4.5", got)
+	}
+}
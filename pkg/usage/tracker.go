@@ -0,0 +1,358 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dayLayout = "2006-01-02"
+
+// Record is one LLM call.
+type Record struct {
+	Time             time.Time `json:"time"`
+	Agent            string    `json:"agent,omitempty"`
+	Session          string    `json:"session,omitempty"`
+	User             string    `json:"user,omitempty"` // Name from the users config, when the call was for one
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	ThinkingTokens   int       `json:"thinking_tokens,omitempty"` // Share of CompletionTokens spent reasoning, when reported
+	Cost             float64   `json:"cost_usd"`                  // Estimated from the pricing table unless reported by the provider
+}
+
+// Totals aggregates token counts and cost over a set of calls.
+type Totals struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	ThinkingTokens   int
+	Cost             float64
+}
+
+func (t *Totals) add(r Record) {
+	t.Calls++
+	t.PromptTokens += r.PromptTokens
+	t.CompletionTokens += r.CompletionTokens
+	t.ThinkingTokens += r.ThinkingTokens
+	t.Cost += r.Cost
+}
+
+func (t *Totals) merge(o Totals) {
+	t.Calls += o.Calls
+	t.PromptTokens += o.PromptTokens
+	t.CompletionTokens += o.CompletionTokens
+	t.ThinkingTokens += o.ThinkingTokens
+	t.Cost += o.Cost
+}
+
+type dayTotals struct {
+	Totals
+	byModel   map[string]*Totals
+	bySession map[string]*Totals
+	byUser    map[string]*Totals
+}
+
+// Tracker records LLM usage to an append-only JSON Lines file and keeps
+// per-day aggregates (by model, session and user) in memory.
+type Tracker struct {
+	mu   sync.RWMutex
+	path string
+	days map[string]*dayTotals
+}
+
+// NewTracker opens the usage log at {workspace}/state/usage.jsonl, loading
+// any existing records.
+func NewTracker(workspace string) *Tracker {
+	stateDir := filepath.Join(workspace, "state")
+	os.MkdirAll(stateDir, 0o755)
+
+	t := &Tracker{
+		path: filepath.Join(stateDir, "usage.jsonl"),
+		days: make(map[string]*dayTotals),
+	}
+	if err := t.load(); err != nil {
+		log.Printf("[WARN] usage: failed to load %s: %v", t.path, err)
+	}
+	return t
+}
+
+// Add records a call and appends it to the log.
+func (t *Tracker) Add(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.aggregate(r)
+
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+	return nil
+}
+
+// Report summarizes usage over a period.
+type Report struct {
+	Since     time.Time
+	Total     Totals
+	ByDay     map[string]Totals // Keyed by local date, YYYY-MM-DD
+	ByModel   map[string]Totals
+	BySession map[string]Totals
+}
+
+// Summary aggregates usage from the local day containing since onwards.
+func (t *Tracker) Summary(since time.Time) Report {
+	from := since.Format(dayLayout)
+	rep := Report{
+		Since:     since,
+		ByDay:     make(map[string]Totals),
+		ByModel:   make(map[string]Totals),
+		BySession: make(map[string]Totals),
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for day, d := range t.days {
+		if day < from {
+			continue
+		}
+		rep.Total.merge(d.Totals)
+		rep.ByDay[day] = d.Totals
+		for model, m := range d.byModel {
+			tot := rep.ByModel[model]
+			tot.merge(*m)
+			rep.ByModel[model] = tot
+		}
+		for session, s := range d.bySession {
+			tot := rep.BySession[session]
+			tot.merge(*s)
+			rep.BySession[session] = tot
+		}
+	}
+	return rep
+}
+
+// Day returns the usage of the local day containing day.
+func (t *Tracker) Day(day time.Time) Totals {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if d, ok := t.days[day.Local().Format(dayLayout)]; ok {
+		return d.Totals
+	}
+	return Totals{}
+}
+
+// UserDay returns the usage of user on the local day containing day.
+func (t *Tracker) UserDay(user string, day time.Time) Totals {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if d, ok := t.days[day.Local().Format(dayLayout)]; ok {
+		if u, ok := d.byUser[user]; ok {
+			return *u
+		}
+	}
+	return Totals{}
+}
+
+// SessionDay returns the usage of session key on the local day containing day.
+func (t *Tracker) SessionDay(key string, day time.Time) Totals {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if d, ok := t.days[day.Local().Format(dayLayout)]; ok {
+		if s, ok := d.bySession[key]; ok {
+			return *s
+		}
+	}
+	return Totals{}
+}
+
+// Session returns the all-time usage of a session.
+func (t *Tracker) Session(key string) Totals {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var tot Totals
+	for _, d := range t.days {
+		if s, ok := d.bySession[key]; ok {
+			tot.merge(*s)
+		}
+	}
+	return tot
+}
+
+// aggregate adds r to the in-memory totals. Must be called with the lock held.
+func (t *Tracker) aggregate(r Record) {
+	day := r.Time.Local().Format(dayLayout)
+	d, ok := t.days[day]
+	if !ok {
+		d = &dayTotals{
+			byModel:   make(map[string]*Totals),
+			bySession: make(map[string]*Totals),
+			byUser:    make(map[string]*Totals),
+		}
+		t.days[day] = d
+	}
+	d.add(r)
+
+	m, ok := d.byModel[r.Model]
+	if !ok {
+		m = &Totals{}
+		d.byModel[r.Model] = m
+	}
+	m.add(r)
+
+	if r.Session != "" {
+		s, ok := d.bySession[r.Session]
+		if !ok {
+			s = &Totals{}
+			d.bySession[r.Session] = s
+		}
+		s.add(r)
+	}
+
+	if r.User != "" {
+		u, ok := d.byUser[r.User]
+		if !ok {
+			u = &Totals{}
+			d.byUser[r.User] = u
+		}
+		u.add(r)
+	}
+}
+
+// load rebuilds the aggregates from the log. Malformed lines (e.g. a write
+// cut short by a crash) are skipped.
+func (t *Tracker) load() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		t.aggregate(r)
+	}
+	return scanner.Err()
+}
+
+// PeriodStart returns the start of a named reporting period: "today",
+// "week" (the last 7 days), "month" (the last 30 days) or "all".
+func PeriodStart(period string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case "", "today":
+		return today, nil
+	case "week":
+		return today.AddDate(0, 0, -6), nil
+	case "month":
+		return today.AddDate(0, 0, -29), nil
+	case "all":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown period %q (want today, week, month or all)", period)
+	}
+}
+
+// Format renders the report as plain text, listing models and the most
+// expensive sessions.
+func (r Report) Format(title string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d calls, %s prompt + %s completion tokens",
+		title, r.Total.Calls, formatTokens(r.Total.PromptTokens), formatTokens(r.Total.CompletionTokens))
+	if r.Total.ThinkingTokens > 0 {
+		fmt.Fprintf(&sb, " (%s thinking)", formatTokens(r.Total.ThinkingTokens))
+	}
+	fmt.Fprintf(&sb, ", $%.4f\n", r.Total.Cost)
+	if r.Total.Calls == 0 {
+		return sb.String()
+	}
+
+	days := sortedKeys(r.ByDay, func(a, b string) bool { return a < b })
+	if len(days) > 1 {
+		fmt.Fprintf(&sb, "\nBy day:\n")
+		for _, day := range days {
+			tot := r.ByDay[day]
+			fmt.Fprintf(&sb, "  %s  %5d calls  $%.4f\n", day, tot.Calls, tot.Cost)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nBy model:\n")
+	for _, model := range sortedByCost(r.ByModel) {
+		tot := r.ByModel[model]
+		fmt.Fprintf(&sb, "  %-40s %5d calls  %8s tokens  $%.4f\n", model, tot.Calls,
+			formatTokens(tot.PromptTokens+tot.CompletionTokens), tot.Cost)
+	}
+
+	if len(r.BySession) > 0 {
+		fmt.Fprintf(&sb, "\nTop sessions:\n")
+		sessions := sortedByCost(r.BySession)
+		if len(sessions) > 5 {
+			sessions = sessions[:5]
+		}
+		for _, session := range sessions {
+			tot := r.BySession[session]
+			fmt.Fprintf(&sb, "  %-40s %5d calls  $%.4f\n", session, tot.Calls, tot.Cost)
+		}
+	}
+	return sb.String()
+}
+
+func sortedByCost(m map[string]Totals) []string {
+	return sortedKeys(m, func(a, b string) bool {
+		if m[a].Cost != m[b].Cost {
+			return m[a].Cost > m[b].Cost
+		}
+		return a < b
+	})
+}
+
+func sortedKeys(m map[string]Totals, less func(a, b string) bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
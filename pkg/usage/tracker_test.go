@@ -0,0 +1,163 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker_SummaryAggregatesByDayModelAndSession(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	now := time.Now()
+
+	records := []Record{
+		{Time: now, Session: "s1", Model: "openai/gpt-4o", PromptTokens: 100, CompletionTokens: 10, Cost: 0.5},
+		{Time: now, Session: "s2", Model: "openai/gpt-4o", PromptTokens: 200, CompletionTokens: 20, Cost: 1},
+		{Time: now.AddDate(0, 0, -3), Session: "s1", Model: "anthropic/claude-sonnet-4.6", PromptTokens: 50, CompletionTokens: 5, Cost: 2},
+		{Time: now.AddDate(0, 0, -40), Session: "s1", Model: "openai/gpt-4o", PromptTokens: 1, CompletionTokens: 1, Cost: 100},
+	}
+	for _, r := range records {
+		if err := tr.Add(r); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	today, _ := PeriodStart("today", now)
+	rep := tr.Summary(today)
+	if rep.Total.Calls != 2 || rep.Total.PromptTokens != 300 || math.Abs(rep.Total.Cost-1.5) > 1e-9 {
+		t.Errorf("today Total = %+v", rep.Total)
+	}
+
+	week, _ := PeriodStart("week", now)
+	rep = tr.Summary(week)
+	if rep.Total.Calls != 3 || math.Abs(rep.Total.Cost-3.5) > 1e-9 {
+		t.Errorf("week Total = %+v", rep.Total)
+	}
+	if len(rep.ByDay) != 2 {
+		t.Errorf("week ByDay = %+v, want 2 days", rep.ByDay)
+	}
+	if got := rep.ByModel["openai/gpt-4o"]; got.Calls != 2 {
+		t.Errorf("week ByModel[gpt-4o] = %+v", got)
+	}
+	if got := rep.BySession["s1"]; got.Calls != 2 || math.Abs(got.Cost-2.5) > 1e-9 {
+		t.Errorf("week BySession[s1] = %+v", got)
+	}
+
+	if got := tr.Session("s1"); got.Calls != 3 || math.Abs(got.Cost-102.5) > 1e-9 {
+		t.Errorf("Session(s1) = %+v", got)
+	}
+}
+
+func TestTracker_ReloadsLogAndSkipsMalformedLines(t *testing.T) {
+	workspace := t.TempDir()
+	tr := NewTracker(workspace)
+	if err := tr.Add(Record{Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, Cost: 0.25}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(workspace, "state", "usage.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-01-0`)
+	f.Close()
+
+	rep := NewTracker(workspace).Summary(time.Time{})
+	if rep.Total.Calls != 1 || rep.Total.CompletionTokens != 5 || rep.Total.Cost != 0.25 {
+		t.Errorf("reloaded Total = %+v", rep.Total)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"today": time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		"week":  time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		"month": time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+		"all":   {},
+	}
+	for period, want := range tests {
+		got, err := PeriodStart(period, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("PeriodStart(%q) = %v, %v; want %v", period, got, err, want)
+		}
+	}
+	if _, err := PeriodStart("year", now); err == nil {
+		t.Error("PeriodStart(year) error = nil, want error")
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	tr.Add(Record{Session: "agent:main:main", Model: "openai/gpt-4o", PromptTokens: 1500, CompletionTokens: 200, Cost: 0.0058})
+
+	out := tr.Summary(time.Time{}).Format("Usage (all)")
+	for _, want := range []string{"Usage (all): 1 calls", "1.5k prompt", "openai/gpt-4o", "agent:main:main", "$0.0058"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Format() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReportFormat_ThinkingTokens(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	tr.Add(Record{Model: "openai/o4-mini", PromptTokens: 100, CompletionTokens: 3000, ThinkingTokens: 2500})
+	tr.Add(Record{Model: "openai/o4-mini", PromptTokens: 100, CompletionTokens: 100})
+
+	rep := tr.Summary(time.Time{})
+	if rep.Total.ThinkingTokens != 2500 {
+		t.Errorf("ThinkingTokens = %d, want 2500", rep.Total.ThinkingTokens)
+	}
+	if out := rep.Format("Usage"); !strings.Contains(out, "3.1k completion tokens (2.5k thinking)") {
+		t.Errorf("Format() = %q, want thinking breakdown", out)
+	}
+}
+
+func TestTracker_Day(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	now := time.Now()
+	tr.Add(Record{Time: now, Model: "openai/gpt-4o", Cost: 0.25})
+	tr.Add(Record{Time: now, Model: "openai/gpt-4o-mini", Cost: 0.5})
+	tr.Add(Record{Time: now.AddDate(0, 0, -1), Model: "openai/gpt-4o", Cost: 10})
+
+	if got := tr.Day(now); got.Calls != 2 || math.Abs(got.Cost-0.75) > 1e-9 {
+		t.Errorf("Day(today) = %+v", got)
+	}
+	if got := tr.Day(now.AddDate(0, 0, -2)); got.Calls != 0 {
+		t.Errorf("Day(2 days ago) = %+v, want none", got)
+	}
+}
+
+func TestTracker_UserDay(t *testing.T) {
+	workspace := t.TempDir()
+	tr := NewTracker(workspace)
+	now := time.Now()
+	tr.Add(Record{Time: now, User: "ada", Model: "openai/gpt-4o", PromptTokens: 100, Cost: 0.25})
+	tr.Add(Record{Time: now, User: "bob", Model: "openai/gpt-4o", PromptTokens: 50, Cost: 0.5})
+	tr.Add(Record{Time: now, Model: "openai/gpt-4o", Cost: 1})
+	tr.Add(Record{Time: now.AddDate(0, 0, -1), User: "ada", Model: "openai/gpt-4o", Cost: 10})
+
+	// Aggregates survive a reload.
+	tr = NewTracker(workspace)
+	if got := tr.UserDay("ada", now); got.Calls != 1 || got.PromptTokens != 100 || math.Abs(got.Cost-0.25) > 1e-9 {
+		t.Errorf("UserDay(ada) = %+v", got)
+	}
+	if got := tr.UserDay("carol", now); got.Calls != 0 {
+		t.Errorf("UserDay(carol) = %+v, want none", got)
+	}
+}
+
+func TestTracker_SessionDay(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	now := time.Now()
+	tr.Add(Record{Time: now, Session: "heartbeat", Model: "openai/gpt-4o", Cost: 0.25})
+	tr.Add(Record{Time: now, Session: "agent:main:main", Model: "openai/gpt-4o", Cost: 1})
+	tr.Add(Record{Time: now.AddDate(0, 0, -1), Session: "heartbeat", Model: "openai/gpt-4o", Cost: 10})
+
+	if got := tr.SessionDay("heartbeat", now); got.Calls != 1 || math.Abs(got.Cost-0.25) > 1e-9 {
+		t.Errorf("SessionDay(heartbeat) = %+v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"encoding/base64"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -33,6 +35,32 @@ func IsAudioFile(filename, contentType string) bool {
 	return false
 }
 
+// MaxImageBytes caps images attached to LLM requests. It matches the
+// strictest common provider limit (Anthropic, 5 MB per image).
+const MaxImageBytes = 5 << 20
+
+// ImageDataURL reads an image file and returns it as a base64 data: URL,
+// the form in which images are attached to inbound messages.
+func ImageDataURL(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > MaxImageBytes {
+		return "", fmt.Errorf("image %s is too large (%d bytes, max %d)", path, info.Size(), MaxImageBytes)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("%s is not an image (detected %s)", path, mimeType)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
 // SanitizeFilename removes potentially dangerous characters from a filename
 // and returns a safe version for local filesystem storage.
 func SanitizeFilename(filename string) string {
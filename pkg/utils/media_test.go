@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 1x1 transparent PNG
+const tinyPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func TestImageDataURL(t *testing.T) {
+	dir := t.TempDir()
+	png, _ := base64.StdEncoding.DecodeString(tinyPNG)
+	imgPath := filepath.Join(dir, "pixel")
+	if err := os.WriteFile(imgPath, png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ImageDataURL(imgPath)
+	if err != nil {
+		t.Fatalf("ImageDataURL() error = %v", err)
+	}
+	if want := "data:image/png;base64," + tinyPNG; got != want {
+		t.Errorf("ImageDataURL() = %q, want %q", got, want)
+	}
+
+	txtPath := filepath.Join(dir, "notes.txt")
+	os.WriteFile(txtPath, []byte("just text"), 0o644)
+	if _, err := ImageDataURL(txtPath); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Errorf("ImageDataURL(text) error = %v, want not an image", err)
+	}
+
+	if _, err := ImageDataURL(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("ImageDataURL(missing) error = nil, want error")
+	}
+}
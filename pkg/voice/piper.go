@@ -0,0 +1,77 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultPiperTimeout = 2 * time.Minute
+
+// PiperSpeaker synthesizes speech locally with piper. Its WAV output is
+// encoded to OGG/Opus with ffmpeg, as Telegram voice messages require.
+type PiperSpeaker struct {
+	command string
+	model   string
+	ffmpeg  string
+	timeout time.Duration
+}
+
+// NewPiperSpeaker creates a speaker running command with the .onnx voice
+// model at model.
+func NewPiperSpeaker(command, model, ffmpeg string) *PiperSpeaker {
+	return &PiperSpeaker{
+		command: command,
+		model:   model,
+		ffmpeg:  ffmpeg,
+		timeout: defaultPiperTimeout,
+	}
+}
+
+// SetTimeout bounds each synthesis, including encoding; 0 keeps the default.
+func (s *PiperSpeaker) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+}
+
+func (s *PiperSpeaker) Synthesize(ctx context.Context, text string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tmpDir, err := os.MkdirTemp("", "picoclaw-piper-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	wavPath := filepath.Join(tmpDir, "speech.wav")
+	if _, err := runWithInput(ctx, strings.NewReader(text), s.command,
+		"--model", s.model, "--output_file", wavPath,
+	); err != nil {
+		logger.ErrorCF("voice", "piper failed", map[string]any{"error": err})
+		return "", fmt.Errorf("piper failed: %w", err)
+	}
+
+	out, err := os.CreateTemp("", "picoclaw-speech-*.ogg")
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	out.Close()
+	if _, err := run(ctx, s.ffmpeg,
+		"-nostdin", "-y", "-loglevel", "error",
+		"-i", wavPath,
+		"-c:a", "libopus", "-b:a", "32k",
+		out.Name(),
+	); err != nil {
+		os.Remove(out.Name())
+		logger.ErrorCF("voice", "Audio encoding failed", map[string]any{"error": err})
+		return "", fmt.Errorf("failed to encode audio: %w", err)
+	}
+	return out.Name(), nil
+}
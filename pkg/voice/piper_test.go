@@ -0,0 +1,36 @@
+//go:build !windows
+
+package voice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPiperSpeaker_Synthesize(t *testing.T) {
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin")
+	// The fake piper saves its input; the fake ffmpeg writes the last argument.
+	piper := writeScript(t, dir, "piper",
+		`cat > `+stdinFile+"\n"+`while [ $# -gt 0 ]; do [ "$1" = --output_file ] && echo RIFF > "$2"; shift; done`+"\n")
+	ffmpeg := writeScript(t, dir, "ffmpeg", `for a; do out="$a"; done; echo OggS > "$out"`+"\n")
+
+	path, err := NewPiperSpeaker(piper, "/voices/amy.onnx", ffmpeg).Synthesize(context.Background(), "Good morning")
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	defer os.Remove(path)
+
+	if !strings.HasSuffix(path, ".ogg") {
+		t.Errorf("path = %q, want .ogg", path)
+	}
+	if data, _ := os.ReadFile(path); strings.TrimSpace(string(data)) != "OggS" {
+		t.Errorf("audio = %q", data)
+	}
+	if data, _ := os.ReadFile(stdinFile); string(data) != "Good morning" {
+		t.Errorf("piper input = %q", data)
+	}
+}
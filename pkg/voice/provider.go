@@ -0,0 +1,166 @@
+package voice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	openAIAPIBase     = "https://api.openai.com/v1"
+	groqAPIBase       = "https://api.groq.com/openai/v1"
+	elevenLabsAPIBase = "https://api.elevenlabs.io/v1"
+)
+
+// NewTranscriptionProvider creates the transcription backend selected by
+// the transcription config section. It returns nil, nil when transcription
+// is disabled or, with no provider set, no Groq API key is configured.
+func NewTranscriptionProvider(cfg *config.Config) (TranscriptionProvider, error) {
+	tc := cfg.Transcription
+	timeout := time.Duration(tc.Timeout) * time.Second
+
+	switch tc.Provider {
+	case "":
+		apiKey := apiKeyFor(cfg, tc.APIKey, "groq")
+		if apiKey == "" {
+			return nil, nil
+		}
+		t := NewGroqTranscriber(apiKey)
+		t.language = tc.Language
+		t.SetTimeout(timeout)
+		return t, nil
+
+	case "none":
+		return nil, nil
+
+	case "openai", "groq":
+		apiKey := apiKeyFor(cfg, tc.APIKey, tc.Provider)
+		if apiKey == "" {
+			return nil, fmt.Errorf("transcription: api_key is required for provider %q", tc.Provider)
+		}
+		apiBase, model := openAIAPIBase, "whisper-1"
+		if tc.Provider == "groq" {
+			apiBase, model = groqAPIBase, "whisper-large-v3"
+		}
+		if tc.APIBase != "" {
+			apiBase = tc.APIBase
+		}
+		if tc.Model != "" {
+			model = tc.Model
+		}
+		t := NewOpenAITranscriber(apiKey, apiBase, model, tc.Language)
+		t.SetTimeout(timeout)
+		return t, nil
+
+	case "whisper-cpp":
+		if tc.Model == "" {
+			return nil, fmt.Errorf("transcription: model (path to a ggml model file) is required for whisper-cpp")
+		}
+		command, ffmpeg := tc.Command, tc.FFmpeg
+		if command == "" {
+			command = "whisper-cli"
+		}
+		if ffmpeg == "" {
+			ffmpeg = "ffmpeg"
+		}
+		t := NewWhisperCppTranscriber(command, tc.Model, tc.Language, ffmpeg)
+		t.SetTimeout(timeout)
+		return t, nil
+
+	default:
+		return nil, fmt.Errorf("transcription: unknown provider %q", tc.Provider)
+	}
+}
+
+// NewSpeechProvider creates the text-to-speech backend selected by the tts
+// config section. It returns nil, nil when voice replies are disabled.
+func NewSpeechProvider(cfg *config.Config) (SpeechProvider, error) {
+	tc := cfg.TTS
+	timeout := time.Duration(tc.Timeout) * time.Second
+
+	switch tc.Provider {
+	case "":
+		return nil, nil
+
+	case "openai":
+		apiKey := apiKeyFor(cfg, tc.APIKey, "openai")
+		if apiKey == "" {
+			return nil, fmt.Errorf("tts: api_key is required for provider %q", tc.Provider)
+		}
+		apiBase, model, voice := openAIAPIBase, "gpt-4o-mini-tts", "alloy"
+		if tc.APIBase != "" {
+			apiBase = tc.APIBase
+		}
+		if tc.Model != "" {
+			model = tc.Model
+		}
+		if tc.Voice != "" {
+			voice = tc.Voice
+		}
+		s := NewOpenAISpeaker(apiKey, apiBase, model, voice)
+		s.SetTimeout(timeout)
+		return s, nil
+
+	case "elevenlabs":
+		if tc.APIKey == "" {
+			return nil, fmt.Errorf("tts: api_key is required for provider %q", tc.Provider)
+		}
+		if tc.Voice == "" {
+			return nil, fmt.Errorf("tts: voice (an ElevenLabs voice ID) is required for provider %q", tc.Provider)
+		}
+		apiBase, model := elevenLabsAPIBase, "eleven_multilingual_v2"
+		if tc.APIBase != "" {
+			apiBase = tc.APIBase
+		}
+		if tc.Model != "" {
+			model = tc.Model
+		}
+		s := NewElevenLabsSpeaker(tc.APIKey, apiBase, model, tc.Voice)
+		s.SetTimeout(timeout)
+		return s, nil
+
+	case "piper":
+		if tc.Model == "" {
+			return nil, fmt.Errorf("tts: model (path to a piper .onnx voice) is required for piper")
+		}
+		command, ffmpeg := tc.Command, tc.FFmpeg
+		if command == "" {
+			command = "piper"
+		}
+		if ffmpeg == "" {
+			ffmpeg = "ffmpeg"
+		}
+		s := NewPiperSpeaker(command, tc.Model, ffmpeg)
+		s.SetTimeout(timeout)
+		return s, nil
+
+	default:
+		return nil, fmt.Errorf("tts: unknown provider %q", tc.Provider)
+	}
+}
+
+// apiKeyFor returns explicit if set, falling back to the legacy providers
+// section and then to a model_list entry of the same protocol.
+func apiKeyFor(cfg *config.Config, explicit, protocol string) string {
+	if explicit != "" {
+		return explicit
+	}
+	switch protocol {
+	case "groq":
+		if cfg.Providers.Groq.APIKey != "" {
+			return cfg.Providers.Groq.APIKey
+		}
+	case "openai":
+		if cfg.Providers.OpenAI.APIKey != "" {
+			return cfg.Providers.OpenAI.APIKey
+		}
+	}
+	for _, mc := range cfg.ModelList {
+		if strings.HasPrefix(mc.Model, protocol+"/") && mc.APIKey != "" {
+			return mc.APIKey
+		}
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// SpeechProvider turns a reply into a spoken audio file.
+type SpeechProvider interface {
+	// Synthesize writes text as speech to a new temporary file and returns
+	// its path; the caller removes it. The format (OGG/Opus or MP3) is
+	// accepted by Telegram as a voice message.
+	Synthesize(ctx context.Context, text string) (string, error)
+}
+
+const defaultSpeechTimeout = 60 * time.Second
+
+var (
+	reSpokenCodeBlock = regexp.MustCompile("(?s)```.*?```")
+	reSpokenLink      = regexp.MustCompile(`\[([^\]]+)\]\([^)]+\)`)
+	reSpokenURL       = regexp.MustCompile(`https?://\S+`)
+	reSpokenMarkup    = regexp.MustCompile("[*_`#>|~]+")
+	reSpokenSpace     = regexp.MustCompile(`[ \t]+`)
+
+	reReadAloud = regexp.MustCompile(`(?i)\b(read (it|this|that|them)?\s*(out )?(aloud|out loud)|` +
+		`say (it|this|that) out loud|(reply|respond|answer) (with|in|by) (a )?voice|voice (reply|message|note) please)\b`)
+)
+
+// SpeakableText strips markdown, code blocks and URLs, which read badly.
+func SpeakableText(text string) string {
+	text = reSpokenCodeBlock.ReplaceAllString(text, " ")
+	text = reSpokenLink.ReplaceAllString(text, "$1")
+	text = reSpokenURL.ReplaceAllString(text, "")
+	text = reSpokenMarkup.ReplaceAllString(text, "")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(reSpokenSpace.ReplaceAllString(line, " ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WantsSpokenReply reports whether a message asks for the answer to be read
+// aloud.
+func WantsSpokenReply(text string) bool {
+	return reReadAloud.MatchString(text)
+}
+
+// OpenAISpeaker calls an OpenAI-compatible /audio/speech endpoint.
+type OpenAISpeaker struct {
+	apiKey     string
+	apiBase    string
+	model      string
+	voice      string
+	httpClient *http.Client
+}
+
+// NewOpenAISpeaker creates a speaker for the API at apiBase.
+func NewOpenAISpeaker(apiKey, apiBase, model, voice string) *OpenAISpeaker {
+	return &OpenAISpeaker{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		model:      model,
+		voice:      voice,
+		httpClient: &http.Client{Timeout: defaultSpeechTimeout},
+	}
+}
+
+// SetTimeout bounds each synthesis request; 0 keeps the default.
+func (s *OpenAISpeaker) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.httpClient.Timeout = timeout
+	}
+}
+
+func (s *OpenAISpeaker) Synthesize(ctx context.Context, text string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":           s.model,
+		"voice":           s.voice,
+		"input":           text,
+		"response_format": "opus",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBase+"/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	return saveAudio(s.httpClient, req, ".ogg")
+}
+
+// ElevenLabsSpeaker calls the ElevenLabs text-to-speech API.
+type ElevenLabsSpeaker struct {
+	apiKey     string
+	apiBase    string
+	model      string
+	voiceID    string
+	httpClient *http.Client
+}
+
+// NewElevenLabsSpeaker creates a speaker using the voice voiceID.
+func NewElevenLabsSpeaker(apiKey, apiBase, model, voiceID string) *ElevenLabsSpeaker {
+	return &ElevenLabsSpeaker{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		model:      model,
+		voiceID:    voiceID,
+		httpClient: &http.Client{Timeout: defaultSpeechTimeout},
+	}
+}
+
+// SetTimeout bounds each synthesis request; 0 keeps the default.
+func (s *ElevenLabsSpeaker) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.httpClient.Timeout = timeout
+	}
+}
+
+func (s *ElevenLabsSpeaker) Synthesize(ctx context.Context, text string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"text":     text,
+		"model_id": s.model,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	endpoint := s.apiBase + "/text-to-speech/" + url.PathEscape(s.voiceID) + "?output_format=mp3_44100_128"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("xi-api-key", s.apiKey)
+	return saveAudio(s.httpClient, req, ".mp3")
+}
+
+// saveAudio sends req and writes the audio it returns to a temporary file
+// with extension ext.
+func saveAudio(client *http.Client, req *http.Request, ext string) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	f, err := os.CreateTemp("", "picoclaw-speech-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to save audio: %w", err)
+	}
+
+	logger.DebugCF("voice", "Speech synthesized", map[string]any{"bytes": n, "path": f.Name()})
+	return f.Name(), nil
+}
@@ -0,0 +1,134 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestOpenAISpeaker_Synthesize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("path = %q, auth = %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["input"] != "Hello" || body["voice"] != "nova" || body["response_format"] != "opus" {
+			t.Errorf("body = %v", body)
+		}
+		w.Write([]byte("OggS audio"))
+	}))
+	defer server.Close()
+
+	path, err := NewOpenAISpeaker("sk-test", server.URL+"/v1", "gpt-4o-mini-tts", "nova").
+		Synthesize(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	defer os.Remove(path)
+	if filepath.Ext(path) != ".ogg" {
+		t.Errorf("path = %q, want .ogg", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "OggS audio" {
+		t.Errorf("audio = %q", data)
+	}
+}
+
+func TestElevenLabsSpeaker_Synthesize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/text-to-speech/voice-123" || r.URL.Query().Get("output_format") == "" {
+			t.Errorf("url = %q", r.URL)
+		}
+		if r.Header.Get("xi-api-key") != "el-key" {
+			t.Errorf("xi-api-key = %q", r.Header.Get("xi-api-key"))
+		}
+		w.Write([]byte("ID3 audio"))
+	}))
+	defer server.Close()
+
+	path, err := NewElevenLabsSpeaker("el-key", server.URL+"/v1", "eleven_multilingual_v2", "voice-123").
+		Synthesize(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	defer os.Remove(path)
+	if filepath.Ext(path) != ".mp3" {
+		t.Errorf("path = %q, want .mp3", path)
+	}
+}
+
+func TestSpeaker_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	if _, err := NewOpenAISpeaker("sk", server.URL, "tts-1", "alloy").Synthesize(context.Background(), "Hi"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestSpeakableText(t *testing.T) {
+	in := "## Result\n\n**Done!** See [the docs](https://example.com) or https://x.y/z\n\n```go\nfmt.Println()\n```\nUse `make`."
+	want := "Result\nDone! See the docs or\nUse make."
+	if got := SpeakableText(in); got != want {
+		t.Errorf("SpeakableText() = %q, want %q", got, want)
+	}
+}
+
+func TestWantsSpokenReply(t *testing.T) {
+	tests := map[string]bool{
+		"Can you read this aloud?":          true,
+		"read it out loud please":           true,
+		"Reply with a voice message":        true,
+		"please answer in voice":            true,
+		"What's the weather?":               false,
+		"I read the article about loudness": false,
+	}
+	for text, want := range tests {
+		if got := WantsSpokenReply(text); got != want {
+			t.Errorf("WantsSpokenReply(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestNewSpeechProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		tts     config.TTSConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{"disabled", config.TTSConfig{}, true, false},
+		{"openai", config.TTSConfig{Provider: "openai", APIKey: "sk"}, false, false},
+		{"openai without key", config.TTSConfig{Provider: "openai"}, false, true},
+		{"elevenlabs", config.TTSConfig{Provider: "elevenlabs", APIKey: "k", Voice: "v"}, false, false},
+		{"elevenlabs without voice", config.TTSConfig{Provider: "elevenlabs", APIKey: "k"}, false, true},
+		{"piper", config.TTSConfig{Provider: "piper", Model: "/voices/en_US-amy.onnx"}, false, false},
+		{"piper without model", config.TTSConfig{Provider: "piper"}, false, true},
+		{"unknown", config.TTSConfig{Provider: "festival"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewSpeechProvider(&config.Config{TTS: tt.tts})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSpeechProvider() error = %v", err)
+			}
+			if (p == nil) != tt.wantNil {
+				t.Errorf("provider = %v, want nil: %v", p, tt.wantNil)
+			}
+		})
+	}
+}
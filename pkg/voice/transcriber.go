@@ -10,15 +10,30 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
-type GroqTranscriber struct {
+// TranscriptionProvider turns a recorded voice message into text.
+type TranscriptionProvider interface {
+	Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error)
+	// IsAvailable reports whether the backend is configured well enough to
+	// try a transcription.
+	IsAvailable() bool
+}
+
+const defaultTranscriptionTimeout = 60 * time.Second
+
+// OpenAITranscriber calls an OpenAI-compatible /audio/transcriptions
+// endpoint, such as OpenAI Whisper or Groq.
+type OpenAITranscriber struct {
 	apiKey     string
 	apiBase    string
+	model      string
+	language   string
 	httpClient *http.Client
 }
 
@@ -28,20 +43,39 @@ type TranscriptionResponse struct {
 	Duration float64 `json:"duration,omitempty"`
 }
 
-func NewGroqTranscriber(apiKey string) *GroqTranscriber {
-	logger.DebugCF("voice", "Creating Groq transcriber", map[string]any{"has_api_key": apiKey != ""})
+// NewOpenAITranscriber creates a transcriber for the API at apiBase. language
+// is an optional ISO-639-1 hint; empty lets the model detect it.
+func NewOpenAITranscriber(apiKey, apiBase, model, language string) *OpenAITranscriber {
+	logger.DebugCF("voice", "Creating transcriber", map[string]any{
+		"api_base":    apiBase,
+		"model":       model,
+		"has_api_key": apiKey != "",
+	})
 
-	apiBase := "https://api.groq.com/openai/v1"
-	return &GroqTranscriber{
-		apiKey:  apiKey,
-		apiBase: apiBase,
+	return &OpenAITranscriber{
+		apiKey:   apiKey,
+		apiBase:  strings.TrimRight(apiBase, "/"),
+		model:    model,
+		language: language,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: defaultTranscriptionTimeout,
 		},
 	}
 }
 
-func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error) {
+// NewGroqTranscriber creates a transcriber for Groq's hosted Whisper.
+func NewGroqTranscriber(apiKey string) *OpenAITranscriber {
+	return NewOpenAITranscriber(apiKey, groqAPIBase, "whisper-large-v3", "")
+}
+
+// SetTimeout bounds each transcription request; 0 keeps the default.
+func (t *OpenAITranscriber) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		t.httpClient.Timeout = timeout
+	}
+}
+
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error) {
 	logger.InfoCF("voice", "Starting transcription", map[string]any{"audio_file": audioFilePath})
 
 	audioFile, err := os.Open(audioFilePath)
@@ -79,11 +113,18 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 
 	logger.DebugCF("voice", "File copied to request", map[string]any{"bytes_copied": copied})
 
-	if err = writer.WriteField("model", "whisper-large-v3"); err != nil {
+	if err = writer.WriteField("model", t.model); err != nil {
 		logger.ErrorCF("voice", "Failed to write model field", map[string]any{"error": err})
 		return nil, fmt.Errorf("failed to write model field: %w", err)
 	}
 
+	if t.language != "" {
+		if err = writer.WriteField("language", t.language); err != nil {
+			logger.ErrorCF("voice", "Failed to write language field", map[string]any{"error": err})
+			return nil, fmt.Errorf("failed to write language field: %w", err)
+		}
+	}
+
 	if err = writer.WriteField("response_format", "json"); err != nil {
 		logger.ErrorCF("voice", "Failed to write response_format field", map[string]any{"error": err})
 		return nil, fmt.Errorf("failed to write response_format field: %w", err)
@@ -104,7 +145,7 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+t.apiKey)
 
-	logger.DebugCF("voice", "Sending transcription request to Groq API", map[string]any{
+	logger.DebugCF("voice", "Sending transcription request", map[string]any{
 		"url":                url,
 		"request_size_bytes": requestBody.Len(),
 		"file_size_bytes":    fileInfo.Size(),
@@ -131,7 +172,7 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	logger.DebugCF("voice", "Received response from Groq API", map[string]any{
+	logger.DebugCF("voice", "Received transcription response", map[string]any{
 		"status_code":         resp.StatusCode,
 		"response_size_bytes": len(body),
 	})
@@ -152,7 +193,7 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 	return &result, nil
 }
 
-func (t *GroqTranscriber) IsAvailable() bool {
+func (t *OpenAITranscriber) IsAvailable() bool {
 	available := t.apiKey != ""
 	logger.DebugCF("voice", "Checking transcriber availability", map[string]any{"available": available})
 	return available
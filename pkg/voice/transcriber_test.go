@@ -0,0 +1,125 @@
+package voice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeAudio(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("fake audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenAITranscriber_Transcribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.FormValue("model") != "whisper-1" || r.FormValue("language") != "de" {
+			t.Errorf("model = %q, language = %q", r.FormValue("model"), r.FormValue("language"))
+		}
+		if _, header, err := r.FormFile("file"); err != nil || header.Filename != "note.ogg" {
+			t.Errorf("file = %v, %v", header, err)
+		}
+		w.Write([]byte(`{"text":"Hallo Welt","language":"de"}`))
+	}))
+	defer server.Close()
+
+	tr := NewOpenAITranscriber("sk-test", server.URL+"/v1/", "whisper-1", "de")
+	result, err := tr.Transcribe(context.Background(), writeAudio(t, "note.ogg"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if result.Text != "Hallo Welt" {
+		t.Errorf("Text = %q", result.Text)
+	}
+}
+
+func TestOpenAITranscriber_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"bad key"}}`))
+	}))
+	defer server.Close()
+
+	tr := NewOpenAITranscriber("sk-bad", server.URL, "whisper-1", "")
+	if _, err := tr.Transcribe(context.Background(), writeAudio(t, "note.ogg")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestNewTranscriptionProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     func(*config.Config)
+		want    string // concrete type, "" for nil
+		wantErr bool
+	}{
+		{"disabled without groq key", func(c *config.Config) {}, "", false},
+		{"groq key from model_list", func(c *config.Config) {
+			c.ModelList = []config.ModelConfig{{ModelName: "llama", Model: "groq/llama-3.3-70b", APIKey: "gsk"}}
+		}, "openai", false},
+		{"none", func(c *config.Config) {
+			c.Providers.Groq.APIKey = "gsk"
+			c.Transcription.Provider = "none"
+		}, "", false},
+		{"openai", func(c *config.Config) {
+			c.Transcription = config.TranscriptionConfig{Provider: "openai", APIKey: "sk"}
+		}, "openai", false},
+		{"openai without key", func(c *config.Config) {
+			c.Transcription.Provider = "openai"
+		}, "", true},
+		{"whisper-cpp", func(c *config.Config) {
+			c.Transcription = config.TranscriptionConfig{Provider: "whisper-cpp", Model: "/models/ggml-base.bin"}
+		}, "whisper-cpp", false},
+		{"whisper-cpp without model", func(c *config.Config) {
+			c.Transcription.Provider = "whisper-cpp"
+		}, "", true},
+		{"unknown", func(c *config.Config) {
+			c.Transcription.Provider = "vosk"
+		}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			tt.cfg(cfg)
+			p, err := NewTranscriptionProvider(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTranscriptionProvider() error = %v", err)
+			}
+			switch p.(type) {
+			case nil:
+				if tt.want != "" {
+					t.Errorf("got nil, want %s", tt.want)
+				}
+			case *OpenAITranscriber:
+				if tt.want != "openai" {
+					t.Errorf("got OpenAITranscriber, want %q", tt.want)
+				}
+			case *WhisperCppTranscriber:
+				if tt.want != "whisper-cpp" {
+					t.Errorf("got WhisperCppTranscriber, want %q", tt.want)
+				}
+			}
+		})
+	}
+}
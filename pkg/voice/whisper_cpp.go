@@ -0,0 +1,135 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const defaultWhisperCppTimeout = 5 * time.Minute
+
+// WhisperCppTranscriber transcribes locally with the whisper.cpp CLI, so
+// audio never leaves the machine. whisper.cpp only reads 16 kHz WAV, so
+// other formats (Telegram sends OGG/Opus) are converted with ffmpeg first.
+type WhisperCppTranscriber struct {
+	command  string
+	model    string
+	language string
+	ffmpeg   string
+	timeout  time.Duration
+}
+
+// NewWhisperCppTranscriber creates a transcriber running command (e.g.
+// "whisper-cli") with the ggml model file at model. language is an optional
+// ISO-639-1 hint; empty lets whisper detect it.
+func NewWhisperCppTranscriber(command, model, language, ffmpeg string) *WhisperCppTranscriber {
+	logger.DebugCF("voice", "Creating whisper.cpp transcriber", map[string]any{
+		"command": command,
+		"model":   model,
+	})
+
+	return &WhisperCppTranscriber{
+		command:  command,
+		model:    model,
+		language: language,
+		ffmpeg:   ffmpeg,
+		timeout:  defaultWhisperCppTimeout,
+	}
+}
+
+// SetTimeout bounds each transcription, including conversion; 0 keeps the
+// default.
+func (t *WhisperCppTranscriber) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		t.timeout = timeout
+	}
+}
+
+func (t *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error) {
+	logger.InfoCF("voice", "Starting local transcription", map[string]any{"audio_file": audioFilePath})
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	wavPath := audioFilePath
+	if !strings.EqualFold(filepath.Ext(audioFilePath), ".wav") {
+		tmpDir, err := os.MkdirTemp("", "picoclaw-whisper-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		wavPath = filepath.Join(tmpDir, "audio.wav")
+		if _, err := run(ctx, t.ffmpeg,
+			"-nostdin", "-y", "-loglevel", "error",
+			"-i", audioFilePath,
+			"-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le",
+			wavPath,
+		); err != nil {
+			logger.ErrorCF("voice", "Audio conversion failed", map[string]any{"error": err})
+			return nil, fmt.Errorf("failed to convert audio: %w", err)
+		}
+	}
+
+	args := []string{"-m", t.model, "-f", wavPath, "--no-timestamps", "--no-prints"}
+	if t.language != "" {
+		args = append(args, "-l", t.language)
+	}
+	out, err := run(ctx, t.command, args...)
+	if err != nil {
+		logger.ErrorCF("voice", "whisper.cpp failed", map[string]any{"error": err})
+		return nil, fmt.Errorf("whisper.cpp failed: %w", err)
+	}
+
+	result := &TranscriptionResponse{
+		Text:     strings.Join(strings.Fields(out), " "),
+		Language: t.language,
+	}
+
+	logger.InfoCF("voice", "Transcription completed successfully", map[string]any{
+		"text_length":           len(result.Text),
+		"transcription_preview": utils.Truncate(result.Text, 50),
+	})
+
+	return result, nil
+}
+
+// IsAvailable reports whether the model file and the whisper.cpp binary
+// can be found.
+func (t *WhisperCppTranscriber) IsAvailable() bool {
+	_, modelErr := os.Stat(t.model)
+	_, commandErr := exec.LookPath(t.command)
+	available := modelErr == nil && commandErr == nil
+	logger.DebugCF("voice", "Checking transcriber availability", map[string]any{"available": available})
+	return available
+}
+
+// run executes name and returns its stdout, or an error carrying stderr.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	return runWithInput(ctx, nil, name, args...)
+}
+
+// runWithInput is like run, feeding stdin to the command.
+func runWithInput(ctx context.Context, stdin io.Reader, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", filepath.Base(name), err, utils.Truncate(msg, 500))
+		}
+		return "", fmt.Errorf("%s: %w", filepath.Base(name), err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,65 @@
+//go:build !windows
+
+package voice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeScript writes an executable shell script to dir.
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWhisperCppTranscriber_ConvertsAndTranscribes(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	// The fake ffmpeg copies its input to the last argument, the WAV path.
+	ffmpeg := writeScript(t, dir, "ffmpeg", `for a; do out="$a"; done; echo converted > "$out"`+"\n")
+	whisper := writeScript(t, dir, "whisper-cli",
+		`echo "$@" > `+argsFile+"\n"+`printf ' Hello there,\n general Kenobi.\n'`+"\n")
+	model := filepath.Join(dir, "ggml-base.bin")
+	os.WriteFile(model, []byte("model"), 0o644)
+
+	tr := NewWhisperCppTranscriber(whisper, model, "en", ffmpeg)
+	if !tr.IsAvailable() {
+		t.Fatal("IsAvailable() = false")
+	}
+	result, err := tr.Transcribe(context.Background(), writeAudio(t, "voice.ogg"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if result.Text != "Hello there, general Kenobi." {
+		t.Errorf("Text = %q", result.Text)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	for _, want := range []string{"-m " + model, ".wav", "--no-timestamps", "-l en"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestWhisperCppTranscriber_Failure(t *testing.T) {
+	dir := t.TempDir()
+	whisper := writeScript(t, dir, "whisper-cli", "echo 'failed to load model' >&2\nexit 1\n")
+
+	tr := NewWhisperCppTranscriber(whisper, filepath.Join(dir, "missing.bin"), "", "ffmpeg")
+	if tr.IsAvailable() {
+		t.Error("IsAvailable() = true with a missing model")
+	}
+	_, err := tr.Transcribe(context.Background(), writeAudio(t, "voice.wav"))
+	if err == nil || !strings.Contains(err.Error(), "failed to load model") {
+		t.Errorf("Transcribe() error = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Executor carries out the steps of a workflow.
+type Executor interface {
+	// CallTool runs a tool and returns its output for the model, or an
+	// error when the call failed.
+	CallTool(ctx context.Context, name string, args map[string]any) (string, error)
+	// Prompt asks the LLM prompt, without tools, and returns its answer.
+	Prompt(ctx context.Context, prompt string) (string, error)
+}
+
+// StepResult is what became of one step of a run.
+type StepResult struct {
+	Name     string
+	Skipped  bool // Its condition was false
+	Attempts int
+	Output   string
+	Err      error
+}
+
+// Run runs wf with inputs, which fill in and override the workflow's
+// defaults, and returns its output and what each step did. It stops at the
+// first step that fails for good, unless the step's on_error is continue.
+func Run(ctx context.Context, wf *Workflow, inputs map[string]string, exec Executor) (string, []StepResult, error) {
+	vars := make(map[string]string, len(wf.Inputs)+len(wf.Steps))
+	for name, def := range wf.Inputs {
+		vars[name] = def
+	}
+	for name, value := range inputs {
+		if _, ok := wf.Inputs[name]; !ok {
+			return "", nil, fmt.Errorf("workflow %s has no input %q", wf.Name, name)
+		}
+		vars[name] = value
+	}
+	for name := range wf.Inputs {
+		if vars[name] == "" {
+			return "", nil, fmt.Errorf("workflow %s needs input %q", wf.Name, name)
+		}
+	}
+
+	var (
+		results []StepResult
+		last    string
+	)
+	for i, step := range wf.Steps {
+		res := StepResult{Name: step.Name}
+		if res.Name == "" {
+			res.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if step.If != "" && !evalCondition(step.If, vars) {
+			res.Skipped = true
+			results = append(results, res)
+			if step.Name != "" {
+				vars[step.Name] = ""
+			}
+			continue
+		}
+
+		for res.Attempts = 1; ; res.Attempts++ {
+			res.Output, res.Err = runStep(ctx, step, vars, exec)
+			if res.Err == nil || res.Attempts > step.Retries || ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(step.RetryDelay) * time.Second):
+			}
+		}
+		results = append(results, res)
+		if res.Err != nil {
+			if err := ctx.Err(); err != nil {
+				return "", results, err
+			}
+			if step.OnError != "continue" {
+				return "", results, fmt.Errorf("%s failed: %w", res.Name, res.Err)
+			}
+		}
+		if step.Name != "" {
+			vars[step.Name] = res.Output
+		}
+		last = res.Output
+	}
+
+	if wf.Output != "" {
+		return expand(wf.Output, vars), results, nil
+	}
+	return last, results, nil
+}
+
+func runStep(ctx context.Context, step Step, vars map[string]string, exec Executor) (string, error) {
+	if step.Tool != "" {
+		args := walkStrings(step.Args, func(s string) string { return expand(s, vars) })
+		argMap, _ := args.(map[string]any)
+		if argMap == nil {
+			argMap = map[string]any{}
+		}
+		return exec.CallTool(ctx, step.Tool, argMap)
+	}
+	return exec.Prompt(ctx, expand(step.Prompt, vars))
+}
+
+// expand replaces {{name}} in s with vars[name].
+func expand(s string, vars map[string]string) string {
+	return reVar.ReplaceAllStringFunc(s, func(m string) string {
+		return vars[reVar.FindStringSubmatch(m)[1]]
+	})
+}
+
+// walkStrings returns a copy of v, a value decoded from YAML, with f applied
+// to every string in it.
+func walkStrings(v any, f func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return f(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = walkStrings(item, f)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = walkStrings(item, f)
+		}
+		return out
+	}
+	return v
+}
+
+// evalCondition evaluates a step's if: "A contains B" (ignoring case),
+// "A == B", "A != B", "not X", or X alone, which is true unless it is
+// empty, "false", "no" or "0". The operators are found before templates
+// are expanded, so step outputs containing them don't confuse it.
+func evalCondition(cond string, vars map[string]string) bool {
+	cond = strings.TrimSpace(cond)
+	if rest, ok := strings.CutPrefix(cond, "not "); ok {
+		return !evalCondition(rest, vars)
+	}
+	side := func(s string) string {
+		return strings.TrimSpace(strings.Trim(strings.TrimSpace(expand(s, vars)), `"'`))
+	}
+	if a, b, ok := strings.Cut(cond, " contains "); ok {
+		return strings.Contains(strings.ToLower(side(a)), strings.ToLower(side(b)))
+	}
+	if a, b, ok := strings.Cut(cond, " != "); ok {
+		return side(a) != side(b)
+	}
+	if a, b, ok := strings.Cut(cond, " == "); ok {
+		return side(a) == side(b)
+	}
+	switch strings.ToLower(side(cond)) {
+	case "", "false", "no", "0":
+		return false
+	}
+	return true
+}
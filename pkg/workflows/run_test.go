@@ -0,0 +1,192 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeExecutor answers tool calls from tools and prompts with "LLM: <prompt>".
+type fakeExecutor struct {
+	tools   map[string]func(args map[string]any) (string, error)
+	calls   []string
+	prompts []string
+}
+
+func (e *fakeExecutor) CallTool(_ context.Context, name string, args map[string]any) (string, error) {
+	e.calls = append(e.calls, name)
+	f, ok := e.tools[name]
+	if !ok {
+		return "", errors.New("no such tool")
+	}
+	return f(args)
+}
+
+func (e *fakeExecutor) Prompt(_ context.Context, prompt string) (string, error) {
+	e.prompts = append(e.prompts, prompt)
+	return "LLM: " + prompt, nil
+}
+
+func mustParse(t *testing.T, data string) *Workflow {
+	t.Helper()
+	wf, err := Parse("test", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	return wf
+}
+
+func TestRun_ExpandsInputsAndOutputs(t *testing.T) {
+	wf := mustParse(t, morningReport)
+	exec := &fakeExecutor{tools: map[string]func(map[string]any) (string, error){
+		"web_fetch": func(args map[string]any) (string, error) { return "weather at " + args["url"].(string), nil },
+	}}
+
+	out, steps, err := Run(context.Background(), wf, map[string]string{"city": "Oslo"}, exec)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	want := "Oslo: LLM: Summarize: weather at https://wttr.in/Oslo?format=3"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if len(steps) != 2 || steps[0].Attempts != 1 {
+		t.Errorf("steps = %+v", steps)
+	}
+	// The definition itself is left alone.
+	if wf.Steps[0].Args["url"] != "https://wttr.in/{{city}}?format=3" {
+		t.Errorf("args changed to %v", wf.Steps[0].Args)
+	}
+}
+
+func TestRun_Inputs(t *testing.T) {
+	wf := mustParse(t, "inputs: {to: ''}\nsteps:\n  - prompt: 'hi {{to}}'")
+	exec := &fakeExecutor{}
+	if _, _, err := Run(context.Background(), wf, nil, exec); err == nil || !strings.Contains(err.Error(), `needs input "to"`) {
+		t.Errorf("missing input: error = %v", err)
+	}
+	if _, _, err := Run(context.Background(), wf, map[string]string{"to": "a", "cc": "b"}, exec); err == nil ||
+		!strings.Contains(err.Error(), `no input "cc"`) {
+		t.Errorf("unknown input: error = %v", err)
+	}
+	if len(exec.prompts) != 0 {
+		t.Errorf("steps ran despite bad inputs: %v", exec.prompts)
+	}
+	out, _, err := Run(context.Background(), wf, map[string]string{"to": "Ann"}, exec)
+	if err != nil || out != "LLM: hi Ann" {
+		t.Errorf("Run() = %q, %v; want the last step's output", out, err)
+	}
+}
+
+func TestRun_Conditions(t *testing.T) {
+	wf := mustParse(t, `
+steps:
+  - name: status
+    tool: check
+  - name: alert
+    if: "{{status}} contains ERROR"
+    tool: notify
+  - if: "not {{alert}}"
+    prompt: all good
+`)
+	for _, tt := range []struct {
+		status string
+		calls  string
+		out    string
+	}{
+		{"disk error on sda", "check,notify", "sent"},
+		{"ok", "check", "LLM: all good"},
+	} {
+		exec := &fakeExecutor{tools: map[string]func(map[string]any) (string, error){
+			"check":  func(map[string]any) (string, error) { return tt.status, nil },
+			"notify": func(map[string]any) (string, error) { return "sent", nil },
+		}}
+		out, steps, err := Run(context.Background(), wf, nil, exec)
+		if err != nil {
+			t.Fatalf("Run(%q) error: %v", tt.status, err)
+		}
+		if got := strings.Join(exec.calls, ","); got != tt.calls {
+			t.Errorf("Run(%q) called %s, want %s", tt.status, got, tt.calls)
+		}
+		if len(steps) != 3 {
+			t.Errorf("Run(%q) steps = %+v", tt.status, steps)
+		}
+		if out != tt.out {
+			t.Errorf("Run(%q) output = %q, want %q", tt.status, out, tt.out)
+		}
+	}
+}
+
+func TestRun_RetriesAndErrors(t *testing.T) {
+	failures := 2
+	flaky := func(map[string]any) (string, error) {
+		if failures > 0 {
+			failures--
+			return "", errors.New("timeout")
+		}
+		return "fetched", nil
+	}
+	broken := func(map[string]any) (string, error) { return "", errors.New("boom") }
+	tools := map[string]func(map[string]any) (string, error){"flaky": flaky, "broken": broken}
+
+	wf := mustParse(t, "steps:\n  - tool: flaky\n    retries: 2\n  - tool: broken\n    on_error: continue\n  - prompt: done")
+	out, steps, err := Run(context.Background(), wf, nil, &fakeExecutor{tools: tools})
+	if err != nil || out != "LLM: done" {
+		t.Fatalf("Run() = %q, %v", out, err)
+	}
+	if steps[0].Attempts != 3 || steps[0].Output != "fetched" || steps[1].Err == nil {
+		t.Errorf("steps = %+v", steps)
+	}
+
+	wf = mustParse(t, "steps:\n  - name: fetch\n    tool: broken\n    retries: 1\n  - prompt: never")
+	exec := &fakeExecutor{tools: tools}
+	_, steps, err = Run(context.Background(), wf, nil, exec)
+	if err == nil || !strings.Contains(err.Error(), "fetch failed: boom") {
+		t.Errorf("error = %v, want the failed step", err)
+	}
+	if len(steps) != 1 || steps[0].Attempts != 2 || len(exec.prompts) != 0 {
+		t.Errorf("steps = %+v, prompts = %v", steps, exec.prompts)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wf := mustParse(t, "steps:\n  - tool: slow\n    retries: 5\n    retry_delay: 60\n    on_error: continue\n  - prompt: never")
+	exec := &fakeExecutor{tools: map[string]func(map[string]any) (string, error){
+		"slow": func(map[string]any) (string, error) {
+			cancel()
+			return "", errors.New("interrupted")
+		},
+	}}
+	_, steps, err := Run(ctx, wf, nil, exec)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if len(steps) != 1 || steps[0].Attempts != 1 {
+		t.Errorf("steps = %+v", steps)
+	}
+}
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]string{"a": "Hello World", "empty": "", "n": "0", "x": "yes == no"}
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"{{a}} contains world", true},
+		{"{{a}} contains moon", false},
+		{"{{a}} == 'Hello World'", true},
+		{"{{a}} != Hello World", false},
+		{"{{empty}}", false},
+		{"not {{empty}}", true},
+		{"{{n}}", false},
+		{"{{a}}", true},
+		{"{{x}}", true}, // An operator in a value doesn't count
+	}
+	for _, tt := range tests {
+		if got := evalCondition(tt.cond, vars); got != tt.want {
+			t.Errorf("evalCondition(%q) = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+}
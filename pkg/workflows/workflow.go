@@ -0,0 +1,203 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package workflows runs deterministic multi-step automations defined in
+// YAML: a list of tool calls and LLM prompts, with conditions and retries,
+// so a repetitive task runs the same way every time instead of being
+// replanned by the model.
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is the workspace directory workflows are kept in, one per
+// <name>.yaml file.
+const Dir = "workflows"
+
+// Workflow is a named list of steps.
+type Workflow struct {
+	Name        string            `yaml:"-"`
+	Description string            `yaml:"description"`
+	Inputs      map[string]string `yaml:"inputs"` // Input names and their defaults; an empty default makes it required
+	Steps       []Step            `yaml:"steps"`
+	Output      string            `yaml:"output"` // Template of the result; default: the last step's output
+}
+
+// Step is a tool call or an LLM prompt. Strings in Args, Prompt and If may
+// use {{name}} to insert an input or the output of an earlier step.
+type Step struct {
+	Name       string         `yaml:"name"` // Optional; lets later steps use the output
+	Tool       string         `yaml:"tool"`
+	Args       map[string]any `yaml:"args"`
+	Prompt     string         `yaml:"prompt"`
+	If         string         `yaml:"if"`          // Condition; see evalCondition
+	Retries    int            `yaml:"retries"`     // Extra attempts after a failure
+	RetryDelay int            `yaml:"retry_delay"` // Seconds between attempts
+	OnError    string         `yaml:"on_error"`    // "stop" (default) or "continue"
+}
+
+var (
+	reName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	reVar  = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+)
+
+// Parse reads a workflow definition and checks it.
+func Parse(name string, data []byte) (*Workflow, error) {
+	var wf Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", name, err)
+	}
+	wf.Name = name
+	if err := wf.validate(); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", name, err)
+	}
+	return &wf, nil
+}
+
+func (wf *Workflow) validate() error {
+	if len(wf.Steps) == 0 {
+		return errors.New("no steps")
+	}
+	known := make(map[string]bool)
+	for input := range wf.Inputs {
+		if !reName.MatchString(input) {
+			return fmt.Errorf("invalid input name %q", input)
+		}
+		known[input] = true
+	}
+	for i, step := range wf.Steps {
+		label := fmt.Sprintf("step %d", i+1)
+		if step.Name != "" {
+			label += " (" + step.Name + ")"
+		}
+		switch {
+		case step.Tool != "" && step.Prompt != "":
+			return fmt.Errorf("%s has both a tool and a prompt", label)
+		case step.Tool == "" && step.Prompt == "":
+			return fmt.Errorf("%s needs a tool or a prompt", label)
+		case step.Tool == "workflow":
+			return fmt.Errorf("%s: workflows can't run other workflows", label)
+		}
+		switch step.OnError {
+		case "", "stop", "continue":
+		default:
+			return fmt.Errorf("%s: on_error must be stop or continue, not %q", label, step.OnError)
+		}
+		if step.Retries < 0 || step.RetryDelay < 0 {
+			return fmt.Errorf("%s: retries and retry_delay can't be negative", label)
+		}
+		for _, ref := range references(step) {
+			if !known[ref] {
+				return fmt.Errorf("%s uses {{%s}}, which isn't an input or an earlier step", label, ref)
+			}
+		}
+		if step.Name != "" {
+			if !reName.MatchString(step.Name) {
+				return fmt.Errorf("invalid step name %q", step.Name)
+			}
+			if known[step.Name] {
+				return fmt.Errorf("%s: the name %q is already used", label, step.Name)
+			}
+			known[step.Name] = true
+		}
+	}
+	for _, m := range reVar.FindAllStringSubmatch(wf.Output, -1) {
+		if !known[m[1]] {
+			return fmt.Errorf("output uses {{%s}}, which isn't an input or a step", m[1])
+		}
+	}
+	return nil
+}
+
+// references returns the names step's templates use.
+func references(step Step) []string {
+	var refs []string
+	collect := func(s string) {
+		for _, m := range reVar.FindAllStringSubmatch(s, -1) {
+			refs = append(refs, m[1])
+		}
+	}
+	collect(step.Prompt)
+	collect(step.If)
+	walkStrings(step.Args, func(s string) string {
+		collect(s)
+		return s
+	})
+	return refs
+}
+
+// Load reads the workflow name from dir.
+func Load(dir, name string) (*Workflow, error) {
+	if !reName.MatchString(name) {
+		return nil, fmt.Errorf("invalid workflow name %q", name)
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		data, err := os.ReadFile(filepath.Join(dir, name+ext))
+		if err == nil {
+			return Parse(name, data)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no workflow named %q", name)
+}
+
+// List reads the workflows in dir, sorted by name. Definitions that don't
+// parse are returned as errors alongside the others.
+func List(dir string) ([]*Workflow, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+	var (
+		list []*Workflow
+		errs []error
+	)
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		wf, err := Load(dir, strings.TrimSuffix(e.Name(), ext))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		list = append(list, wf)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, errs
+}
+
+// Usage describes how to run wf, e.g. "morning-report [city=Paris]".
+func (wf *Workflow) Usage() string {
+	names := make([]string, 0, len(wf.Inputs))
+	for name := range wf.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := []string{wf.Name}
+	for _, name := range names {
+		if def := wf.Inputs[name]; def != "" {
+			parts = append(parts, fmt.Sprintf("[%s=%s]", name, def))
+		} else {
+			parts = append(parts, name+"=…")
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,98 @@
+package workflows
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const morningReport = `
+description: Weather and news for the morning
+inputs:
+  city: Paris
+steps:
+  - name: weather
+    tool: web_fetch
+    args:
+      url: "https://wttr.in/{{city}}?format=3"
+    retries: 2
+  - name: summary
+    prompt: "Summarize: {{weather}}"
+output: "{{city}}: {{summary}}"
+`
+
+func TestParse(t *testing.T) {
+	wf, err := Parse("morning", []byte(morningReport))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if wf.Name != "morning" || len(wf.Steps) != 2 || wf.Inputs["city"] != "Paris" {
+		t.Errorf("wf = %+v", wf)
+	}
+	if wf.Steps[0].Retries != 2 || wf.Steps[0].Args["url"] != "https://wttr.in/{{city}}?format=3" {
+		t.Errorf("step 1 = %+v", wf.Steps[0])
+	}
+	if got := wf.Usage(); got != "morning [city=Paris]" {
+		t.Errorf("Usage() = %q", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"no steps", "description: x", "no steps"},
+		{"tool and prompt", "steps:\n  - tool: a\n    prompt: b", "both a tool and a prompt"},
+		{"empty step", "steps:\n  - name: a", "needs a tool or a prompt"},
+		{"nested", "steps:\n  - tool: workflow", "can't run other workflows"},
+		{"on_error", "steps:\n  - tool: a\n    on_error: retry", "on_error"},
+		{"unknown ref", "steps:\n  - prompt: '{{later}}'\n  - name: later\n    tool: a", "{{later}}"},
+		{"duplicate", "steps:\n  - name: a\n    tool: a\n  - name: a\n    tool: b", "already used"},
+		{"output ref", "steps:\n  - tool: a\noutput: '{{nope}}'", "{{nope}}"},
+		{"bad yaml", "steps: [", "workflow bad yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.name, []byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAndList(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("morning.yaml", morningReport)
+	write("backup.yml", "steps:\n  - tool: exec\n    args: {command: make backup}")
+	write("broken.yaml", "steps: []")
+	write("notes.txt", "not a workflow")
+
+	if wf, err := Load(dir, "backup"); err != nil || wf.Steps[0].Tool != "exec" {
+		t.Errorf("Load(backup) = %+v, %v", wf, err)
+	}
+	if _, err := Load(dir, "missing"); err == nil || !strings.Contains(err.Error(), "no workflow named") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+	if _, err := Load(dir, "../morning"); err == nil {
+		t.Error("Load(../morning) succeeded, want an invalid name error")
+	}
+
+	list, errs := List(dir)
+	if len(list) != 2 || list[0].Name != "backup" || list[1].Name != "morning" {
+		t.Errorf("List() = %v", list)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken") {
+		t.Errorf("List() errors = %v", errs)
+	}
+
+	if list, errs := List(filepath.Join(dir, "none")); list != nil || errs != nil {
+		t.Errorf("List(missing dir) = %v, %v", list, errs)
+	}
+}